@@ -0,0 +1,122890 @@
+package brotli
+
+/* Copyright 2013 Google Inc. All Rights Reserved.
+
+   Distributed under MIT license.
+   See file LICENSE for detail or copy at https://opensource.org/licenses/MIT
+*/
+
+/* Collection of static dictionary words. */
+type dictionary struct {
+	size_bits_by_length [32]byte
+	offsets_by_length   [32]uint32
+	data_size           uint
+	data                []byte
+}
+
+const minDictionaryWordLength = 4
+
+const maxDictionaryWordLength = 24
+
+var kBrotliDictionaryData = []byte{
+	116,
+	105,
+	109,
+	101,
+	100,
+	111,
+	119,
+	110,
+	108,
+	105,
+	102,
+	101,
+	108,
+	101,
+	102,
+	116,
+	98,
+	97,
+	99,
+	107,
+	99,
+	111,
+	100,
+	101,
+	100,
+	97,
+	116,
+	97,
+	115,
+	104,
+	111,
+	119,
+	111,
+	110,
+	108,
+	121,
+	115,
+	105,
+	116,
+	101,
+	99,
+	105,
+	116,
+	121,
+	111,
+	112,
+	101,
+	110,
+	106,
+	117,
+	115,
+	116,
+	108,
+	105,
+	107,
+	101,
+	102,
+	114,
+	101,
+	101,
+	119,
+	111,
+	114,
+	107,
+	116,
+	101,
+	120,
+	116,
+	121,
+	101,
+	97,
+	114,
+	111,
+	118,
+	101,
+	114,
+	98,
+	111,
+	100,
+	121,
+	108,
+	111,
+	118,
+	101,
+	102,
+	111,
+	114,
+	109,
+	98,
+	111,
+	111,
+	107,
+	112,
+	108,
+	97,
+	121,
+	108,
+	105,
+	118,
+	101,
+	108,
+	105,
+	110,
+	101,
+	104,
+	101,
+	108,
+	112,
+	104,
+	111,
+	109,
+	101,
+	115,
+	105,
+	100,
+	101,
+	109,
+	111,
+	114,
+	101,
+	119,
+	111,
+	114,
+	100,
+	108,
+	111,
+	110,
+	103,
+	116,
+	104,
+	101,
+	109,
+	118,
+	105,
+	101,
+	119,
+	102,
+	105,
+	110,
+	100,
+	112,
+	97,
+	103,
+	101,
+	100,
+	97,
+	121,
+	115,
+	102,
+	117,
+	108,
+	108,
+	104,
+	101,
+	97,
+	100,
+	116,
+	101,
+	114,
+	109,
+	101,
+	97,
+	99,
+	104,
+	97,
+	114,
+	101,
+	97,
+	102,
+	114,
+	111,
+	109,
+	116,
+	114,
+	117,
+	101,
+	109,
+	97,
+	114,
+	107,
+	97,
+	98,
+	108,
+	101,
+	117,
+	112,
+	111,
+	110,
+	104,
+	105,
+	103,
+	104,
+	100,
+	97,
+	116,
+	101,
+	108,
+	97,
+	110,
+	100,
+	110,
+	101,
+	119,
+	115,
+	101,
+	118,
+	101,
+	110,
+	110,
+	101,
+	120,
+	116,
+	99,
+	97,
+	115,
+	101,
+	98,
+	111,
+	116,
+	104,
+	112,
+	111,
+	115,
+	116,
+	117,
+	115,
+	101,
+	100,
+	109,
+	97,
+	100,
+	101,
+	104,
+	97,
+	110,
+	100,
+	104,
+	101,
+	114,
+	101,
+	119,
+	104,
+	97,
+	116,
+	110,
+	97,
+	109,
+	101,
+	76,
+	105,
+	110,
+	107,
+	98,
+	108,
+	111,
+	103,
+	115,
+	105,
+	122,
+	101,
+	98,
+	97,
+	115,
+	101,
+	104,
+	101,
+	108,
+	100,
+	109,
+	97,
+	107,
+	101,
+	109,
+	97,
+	105,
+	110,
+	117,
+	115,
+	101,
+	114,
+	39,
+	41,
+	32,
+	43,
+	104,
+	111,
+	108,
+	100,
+	101,
+	110,
+	100,
+	115,
+	119,
+	105,
+	116,
+	104,
+	78,
+	101,
+	119,
+	115,
+	114,
+	101,
+	97,
+	100,
+	119,
+	101,
+	114,
+	101,
+	115,
+	105,
+	103,
+	110,
+	116,
+	97,
+	107,
+	101,
+	104,
+	97,
+	118,
+	101,
+	103,
+	97,
+	109,
+	101,
+	115,
+	101,
+	101,
+	110,
+	99,
+	97,
+	108,
+	108,
+	112,
+	97,
+	116,
+	104,
+	119,
+	101,
+	108,
+	108,
+	112,
+	108,
+	117,
+	115,
+	109,
+	101,
+	110,
+	117,
+	102,
+	105,
+	108,
+	109,
+	112,
+	97,
+	114,
+	116,
+	106,
+	111,
+	105,
+	110,
+	116,
+	104,
+	105,
+	115,
+	108,
+	105,
+	115,
+	116,
+	103,
+	111,
+	111,
+	100,
+	110,
+	101,
+	101,
+	100,
+	119,
+	97,
+	121,
+	115,
+	119,
+	101,
+	115,
+	116,
+	106,
+	111,
+	98,
+	115,
+	109,
+	105,
+	110,
+	100,
+	97,
+	108,
+	115,
+	111,
+	108,
+	111,
+	103,
+	111,
+	114,
+	105,
+	99,
+	104,
+	117,
+	115,
+	101,
+	115,
+	108,
+	97,
+	115,
+	116,
+	116,
+	101,
+	97,
+	109,
+	97,
+	114,
+	109,
+	121,
+	102,
+	111,
+	111,
+	100,
+	107,
+	105,
+	110,
+	103,
+	119,
+	105,
+	108,
+	108,
+	101,
+	97,
+	115,
+	116,
+	119,
+	97,
+	114,
+	100,
+	98,
+	101,
+	115,
+	116,
+	102,
+	105,
+	114,
+	101,
+	80,
+	97,
+	103,
+	101,
+	107,
+	110,
+	111,
+	119,
+	97,
+	119,
+	97,
+	121,
+	46,
+	112,
+	110,
+	103,
+	109,
+	111,
+	118,
+	101,
+	116,
+	104,
+	97,
+	110,
+	108,
+	111,
+	97,
+	100,
+	103,
+	105,
+	118,
+	101,
+	115,
+	101,
+	108,
+	102,
+	110,
+	111,
+	116,
+	101,
+	109,
+	117,
+	99,
+	104,
+	102,
+	101,
+	101,
+	100,
+	109,
+	97,
+	110,
+	121,
+	114,
+	111,
+	99,
+	107,
+	105,
+	99,
+	111,
+	110,
+	111,
+	110,
+	99,
+	101,
+	108,
+	111,
+	111,
+	107,
+	104,
+	105,
+	100,
+	101,
+	100,
+	105,
+	101,
+	100,
+	72,
+	111,
+	109,
+	101,
+	114,
+	117,
+	108,
+	101,
+	104,
+	111,
+	115,
+	116,
+	97,
+	106,
+	97,
+	120,
+	105,
+	110,
+	102,
+	111,
+	99,
+	108,
+	117,
+	98,
+	108,
+	97,
+	119,
+	115,
+	108,
+	101,
+	115,
+	115,
+	104,
+	97,
+	108,
+	102,
+	115,
+	111,
+	109,
+	101,
+	115,
+	117,
+	99,
+	104,
+	122,
+	111,
+	110,
+	101,
+	49,
+	48,
+	48,
+	37,
+	111,
+	110,
+	101,
+	115,
+	99,
+	97,
+	114,
+	101,
+	84,
+	105,
+	109,
+	101,
+	114,
+	97,
+	99,
+	101,
+	98,
+	108,
+	117,
+	101,
+	102,
+	111,
+	117,
+	114,
+	119,
+	101,
+	101,
+	107,
+	102,
+	97,
+	99,
+	101,
+	104,
+	111,
+	112,
+	101,
+	103,
+	97,
+	118,
+	101,
+	104,
+	97,
+	114,
+	100,
+	108,
+	111,
+	115,
+	116,
+	119,
+	104,
+	101,
+	110,
+	112,
+	97,
+	114,
+	107,
+	107,
+	101,
+	112,
+	116,
+	112,
+	97,
+	115,
+	115,
+	115,
+	104,
+	105,
+	112,
+	114,
+	111,
+	111,
+	109,
+	72,
+	84,
+	77,
+	76,
+	112,
+	108,
+	97,
+	110,
+	84,
+	121,
+	112,
+	101,
+	100,
+	111,
+	110,
+	101,
+	115,
+	97,
+	118,
+	101,
+	107,
+	101,
+	101,
+	112,
+	102,
+	108,
+	97,
+	103,
+	108,
+	105,
+	110,
+	107,
+	115,
+	111,
+	108,
+	100,
+	102,
+	105,
+	118,
+	101,
+	116,
+	111,
+	111,
+	107,
+	114,
+	97,
+	116,
+	101,
+	116,
+	111,
+	119,
+	110,
+	106,
+	117,
+	109,
+	112,
+	116,
+	104,
+	117,
+	115,
+	100,
+	97,
+	114,
+	107,
+	99,
+	97,
+	114,
+	100,
+	102,
+	105,
+	108,
+	101,
+	102,
+	101,
+	97,
+	114,
+	115,
+	116,
+	97,
+	121,
+	107,
+	105,
+	108,
+	108,
+	116,
+	104,
+	97,
+	116,
+	102,
+	97,
+	108,
+	108,
+	97,
+	117,
+	116,
+	111,
+	101,
+	118,
+	101,
+	114,
+	46,
+	99,
+	111,
+	109,
+	116,
+	97,
+	108,
+	107,
+	115,
+	104,
+	111,
+	112,
+	118,
+	111,
+	116,
+	101,
+	100,
+	101,
+	101,
+	112,
+	109,
+	111,
+	100,
+	101,
+	114,
+	101,
+	115,
+	116,
+	116,
+	117,
+	114,
+	110,
+	98,
+	111,
+	114,
+	110,
+	98,
+	97,
+	110,
+	100,
+	102,
+	101,
+	108,
+	108,
+	114,
+	111,
+	115,
+	101,
+	117,
+	114,
+	108,
+	40,
+	115,
+	107,
+	105,
+	110,
+	114,
+	111,
+	108,
+	101,
+	99,
+	111,
+	109,
+	101,
+	97,
+	99,
+	116,
+	115,
+	97,
+	103,
+	101,
+	115,
+	109,
+	101,
+	101,
+	116,
+	103,
+	111,
+	108,
+	100,
+	46,
+	106,
+	112,
+	103,
+	105,
+	116,
+	101,
+	109,
+	118,
+	97,
+	114,
+	121,
+	102,
+	101,
+	108,
+	116,
+	116,
+	104,
+	101,
+	110,
+	115,
+	101,
+	110,
+	100,
+	100,
+	114,
+	111,
+	112,
+	86,
+	105,
+	101,
+	119,
+	99,
+	111,
+	112,
+	121,
+	49,
+	46,
+	48,
+	34,
+	60,
+	47,
+	97,
+	62,
+	115,
+	116,
+	111,
+	112,
+	101,
+	108,
+	115,
+	101,
+	108,
+	105,
+	101,
+	115,
+	116,
+	111,
+	117,
+	114,
+	112,
+	97,
+	99,
+	107,
+	46,
+	103,
+	105,
+	102,
+	112,
+	97,
+	115,
+	116,
+	99,
+	115,
+	115,
+	63,
+	103,
+	114,
+	97,
+	121,
+	109,
+	101,
+	97,
+	110,
+	38,
+	103,
+	116,
+	59,
+	114,
+	105,
+	100,
+	101,
+	115,
+	104,
+	111,
+	116,
+	108,
+	97,
+	116,
+	101,
+	115,
+	97,
+	105,
+	100,
+	114,
+	111,
+	97,
+	100,
+	118,
+	97,
+	114,
+	32,
+	102,
+	101,
+	101,
+	108,
+	106,
+	111,
+	104,
+	110,
+	114,
+	105,
+	99,
+	107,
+	112,
+	111,
+	114,
+	116,
+	102,
+	97,
+	115,
+	116,
+	39,
+	85,
+	65,
+	45,
+	100,
+	101,
+	97,
+	100,
+	60,
+	47,
+	98,
+	62,
+	112,
+	111,
+	111,
+	114,
+	98,
+	105,
+	108,
+	108,
+	116,
+	121,
+	112,
+	101,
+	85,
+	46,
+	83,
+	46,
+	119,
+	111,
+	111,
+	100,
+	109,
+	117,
+	115,
+	116,
+	50,
+	112,
+	120,
+	59,
+	73,
+	110,
+	102,
+	111,
+	114,
+	97,
+	110,
+	107,
+	119,
+	105,
+	100,
+	101,
+	119,
+	97,
+	110,
+	116,
+	119,
+	97,
+	108,
+	108,
+	108,
+	101,
+	97,
+	100,
+	91,
+	48,
+	93,
+	59,
+	112,
+	97,
+	117,
+	108,
+	119,
+	97,
+	118,
+	101,
+	115,
+	117,
+	114,
+	101,
+	36,
+	40,
+	39,
+	35,
+	119,
+	97,
+	105,
+	116,
+	109,
+	97,
+	115,
+	115,
+	97,
+	114,
+	109,
+	115,
+	103,
+	111,
+	101,
+	115,
+	103,
+	97,
+	105,
+	110,
+	108,
+	97,
+	110,
+	103,
+	112,
+	97,
+	105,
+	100,
+	33,
+	45,
+	45,
+	32,
+	108,
+	111,
+	99,
+	107,
+	117,
+	110,
+	105,
+	116,
+	114,
+	111,
+	111,
+	116,
+	119,
+	97,
+	108,
+	107,
+	102,
+	105,
+	114,
+	109,
+	119,
+	105,
+	102,
+	101,
+	120,
+	109,
+	108,
+	34,
+	115,
+	111,
+	110,
+	103,
+	116,
+	101,
+	115,
+	116,
+	50,
+	48,
+	112,
+	120,
+	107,
+	105,
+	110,
+	100,
+	114,
+	111,
+	119,
+	115,
+	116,
+	111,
+	111,
+	108,
+	102,
+	111,
+	110,
+	116,
+	109,
+	97,
+	105,
+	108,
+	115,
+	97,
+	102,
+	101,
+	115,
+	116,
+	97,
+	114,
+	109,
+	97,
+	112,
+	115,
+	99,
+	111,
+	114,
+	101,
+	114,
+	97,
+	105,
+	110,
+	102,
+	108,
+	111,
+	119,
+	98,
+	97,
+	98,
+	121,
+	115,
+	112,
+	97,
+	110,
+	115,
+	97,
+	121,
+	115,
+	52,
+	112,
+	120,
+	59,
+	54,
+	112,
+	120,
+	59,
+	97,
+	114,
+	116,
+	115,
+	102,
+	111,
+	111,
+	116,
+	114,
+	101,
+	97,
+	108,
+	119,
+	105,
+	107,
+	105,
+	104,
+	101,
+	97,
+	116,
+	115,
+	116,
+	101,
+	112,
+	116,
+	114,
+	105,
+	112,
+	111,
+	114,
+	103,
+	47,
+	108,
+	97,
+	107,
+	101,
+	119,
+	101,
+	97,
+	107,
+	116,
+	111,
+	108,
+	100,
+	70,
+	111,
+	114,
+	109,
+	99,
+	97,
+	115,
+	116,
+	102,
+	97,
+	110,
+	115,
+	98,
+	97,
+	110,
+	107,
+	118,
+	101,
+	114,
+	121,
+	114,
+	117,
+	110,
+	115,
+	106,
+	117,
+	108,
+	121,
+	116,
+	97,
+	115,
+	107,
+	49,
+	112,
+	120,
+	59,
+	103,
+	111,
+	97,
+	108,
+	103,
+	114,
+	101,
+	119,
+	115,
+	108,
+	111,
+	119,
+	101,
+	100,
+	103,
+	101,
+	105,
+	100,
+	61,
+	34,
+	115,
+	101,
+	116,
+	115,
+	53,
+	112,
+	120,
+	59,
+	46,
+	106,
+	115,
+	63,
+	52,
+	48,
+	112,
+	120,
+	105,
+	102,
+	32,
+	40,
+	115,
+	111,
+	111,
+	110,
+	115,
+	101,
+	97,
+	116,
+	110,
+	111,
+	110,
+	101,
+	116,
+	117,
+	98,
+	101,
+	122,
+	101,
+	114,
+	111,
+	115,
+	101,
+	110,
+	116,
+	114,
+	101,
+	101,
+	100,
+	102,
+	97,
+	99,
+	116,
+	105,
+	110,
+	116,
+	111,
+	103,
+	105,
+	102,
+	116,
+	104,
+	97,
+	114,
+	109,
+	49,
+	56,
+	112,
+	120,
+	99,
+	97,
+	109,
+	101,
+	104,
+	105,
+	108,
+	108,
+	98,
+	111,
+	108,
+	100,
+	122,
+	111,
+	111,
+	109,
+	118,
+	111,
+	105,
+	100,
+	101,
+	97,
+	115,
+	121,
+	114,
+	105,
+	110,
+	103,
+	102,
+	105,
+	108,
+	108,
+	112,
+	101,
+	97,
+	107,
+	105,
+	110,
+	105,
+	116,
+	99,
+	111,
+	115,
+	116,
+	51,
+	112,
+	120,
+	59,
+	106,
+	97,
+	99,
+	107,
+	116,
+	97,
+	103,
+	115,
+	98,
+	105,
+	116,
+	115,
+	114,
+	111,
+	108,
+	108,
+	101,
+	100,
+	105,
+	116,
+	107,
+	110,
+	101,
+	119,
+	110,
+	101,
+	97,
+	114,
+	60,
+	33,
+	45,
+	45,
+	103,
+	114,
+	111,
+	119,
+	74,
+	83,
+	79,
+	78,
+	100,
+	117,
+	116,
+	121,
+	78,
+	97,
+	109,
+	101,
+	115,
+	97,
+	108,
+	101,
+	121,
+	111,
+	117,
+	32,
+	108,
+	111,
+	116,
+	115,
+	112,
+	97,
+	105,
+	110,
+	106,
+	97,
+	122,
+	122,
+	99,
+	111,
+	108,
+	100,
+	101,
+	121,
+	101,
+	115,
+	102,
+	105,
+	115,
+	104,
+	119,
+	119,
+	119,
+	46,
+	114,
+	105,
+	115,
+	107,
+	116,
+	97,
+	98,
+	115,
+	112,
+	114,
+	101,
+	118,
+	49,
+	48,
+	112,
+	120,
+	114,
+	105,
+	115,
+	101,
+	50,
+	53,
+	112,
+	120,
+	66,
+	108,
+	117,
+	101,
+	100,
+	105,
+	110,
+	103,
+	51,
+	48,
+	48,
+	44,
+	98,
+	97,
+	108,
+	108,
+	102,
+	111,
+	114,
+	100,
+	101,
+	97,
+	114,
+	110,
+	119,
+	105,
+	108,
+	100,
+	98,
+	111,
+	120,
+	46,
+	102,
+	97,
+	105,
+	114,
+	108,
+	97,
+	99,
+	107,
+	118,
+	101,
+	114,
+	115,
+	112,
+	97,
+	105,
+	114,
+	106,
+	117,
+	110,
+	101,
+	116,
+	101,
+	99,
+	104,
+	105,
+	102,
+	40,
+	33,
+	112,
+	105,
+	99,
+	107,
+	101,
+	118,
+	105,
+	108,
+	36,
+	40,
+	34,
+	35,
+	119,
+	97,
+	114,
+	109,
+	108,
+	111,
+	114,
+	100,
+	100,
+	111,
+	101,
+	115,
+	112,
+	117,
+	108,
+	108,
+	44,
+	48,
+	48,
+	48,
+	105,
+	100,
+	101,
+	97,
+	100,
+	114,
+	97,
+	119,
+	104,
+	117,
+	103,
+	101,
+	115,
+	112,
+	111,
+	116,
+	102,
+	117,
+	110,
+	100,
+	98,
+	117,
+	114,
+	110,
+	104,
+	114,
+	101,
+	102,
+	99,
+	101,
+	108,
+	108,
+	107,
+	101,
+	121,
+	115,
+	116,
+	105,
+	99,
+	107,
+	104,
+	111,
+	117,
+	114,
+	108,
+	111,
+	115,
+	115,
+	102,
+	117,
+	101,
+	108,
+	49,
+	50,
+	112,
+	120,
+	115,
+	117,
+	105,
+	116,
+	100,
+	101,
+	97,
+	108,
+	82,
+	83,
+	83,
+	34,
+	97,
+	103,
+	101,
+	100,
+	103,
+	114,
+	101,
+	121,
+	71,
+	69,
+	84,
+	34,
+	101,
+	97,
+	115,
+	101,
+	97,
+	105,
+	109,
+	115,
+	103,
+	105,
+	114,
+	108,
+	97,
+	105,
+	100,
+	115,
+	56,
+	112,
+	120,
+	59,
+	110,
+	97,
+	118,
+	121,
+	103,
+	114,
+	105,
+	100,
+	116,
+	105,
+	112,
+	115,
+	35,
+	57,
+	57,
+	57,
+	119,
+	97,
+	114,
+	115,
+	108,
+	97,
+	100,
+	121,
+	99,
+	97,
+	114,
+	115,
+	41,
+	59,
+	32,
+	125,
+	112,
+	104,
+	112,
+	63,
+	104,
+	101,
+	108,
+	108,
+	116,
+	97,
+	108,
+	108,
+	119,
+	104,
+	111,
+	109,
+	122,
+	104,
+	58,
+	229,
+	42,
+	47,
+	13,
+	10,
+	32,
+	49,
+	48,
+	48,
+	104,
+	97,
+	108,
+	108,
+	46,
+	10,
+	10,
+	65,
+	55,
+	112,
+	120,
+	59,
+	112,
+	117,
+	115,
+	104,
+	99,
+	104,
+	97,
+	116,
+	48,
+	112,
+	120,
+	59,
+	99,
+	114,
+	101,
+	119,
+	42,
+	47,
+	60,
+	47,
+	104,
+	97,
+	115,
+	104,
+	55,
+	53,
+	112,
+	120,
+	102,
+	108,
+	97,
+	116,
+	114,
+	97,
+	114,
+	101,
+	32,
+	38,
+	38,
+	32,
+	116,
+	101,
+	108,
+	108,
+	99,
+	97,
+	109,
+	112,
+	111,
+	110,
+	116,
+	111,
+	108,
+	97,
+	105,
+	100,
+	109,
+	105,
+	115,
+	115,
+	115,
+	107,
+	105,
+	112,
+	116,
+	101,
+	110,
+	116,
+	102,
+	105,
+	110,
+	101,
+	109,
+	97,
+	108,
+	101,
+	103,
+	101,
+	116,
+	115,
+	112,
+	108,
+	111,
+	116,
+	52,
+	48,
+	48,
+	44,
+	13,
+	10,
+	13,
+	10,
+	99,
+	111,
+	111,
+	108,
+	102,
+	101,
+	101,
+	116,
+	46,
+	112,
+	104,
+	112,
+	60,
+	98,
+	114,
+	62,
+	101,
+	114,
+	105,
+	99,
+	109,
+	111,
+	115,
+	116,
+	103,
+	117,
+	105,
+	100,
+	98,
+	101,
+	108,
+	108,
+	100,
+	101,
+	115,
+	99,
+	104,
+	97,
+	105,
+	114,
+	109,
+	97,
+	116,
+	104,
+	97,
+	116,
+	111,
+	109,
+	47,
+	105,
+	109,
+	103,
+	38,
+	35,
+	56,
+	50,
+	108,
+	117,
+	99,
+	107,
+	99,
+	101,
+	110,
+	116,
+	48,
+	48,
+	48,
+	59,
+	116,
+	105,
+	110,
+	121,
+	103,
+	111,
+	110,
+	101,
+	104,
+	116,
+	109,
+	108,
+	115,
+	101,
+	108,
+	108,
+	100,
+	114,
+	117,
+	103,
+	70,
+	82,
+	69,
+	69,
+	110,
+	111,
+	100,
+	101,
+	110,
+	105,
+	99,
+	107,
+	63,
+	105,
+	100,
+	61,
+	108,
+	111,
+	115,
+	101,
+	110,
+	117,
+	108,
+	108,
+	118,
+	97,
+	115,
+	116,
+	119,
+	105,
+	110,
+	100,
+	82,
+	83,
+	83,
+	32,
+	119,
+	101,
+	97,
+	114,
+	114,
+	101,
+	108,
+	121,
+	98,
+	101,
+	101,
+	110,
+	115,
+	97,
+	109,
+	101,
+	100,
+	117,
+	107,
+	101,
+	110,
+	97,
+	115,
+	97,
+	99,
+	97,
+	112,
+	101,
+	119,
+	105,
+	115,
+	104,
+	103,
+	117,
+	108,
+	102,
+	84,
+	50,
+	51,
+	58,
+	104,
+	105,
+	116,
+	115,
+	115,
+	108,
+	111,
+	116,
+	103,
+	97,
+	116,
+	101,
+	107,
+	105,
+	99,
+	107,
+	98,
+	108,
+	117,
+	114,
+	116,
+	104,
+	101,
+	121,
+	49,
+	53,
+	112,
+	120,
+	39,
+	39,
+	41,
+	59,
+	41,
+	59,
+	34,
+	62,
+	109,
+	115,
+	105,
+	101,
+	119,
+	105,
+	110,
+	115,
+	98,
+	105,
+	114,
+	100,
+	115,
+	111,
+	114,
+	116,
+	98,
+	101,
+	116,
+	97,
+	115,
+	101,
+	101,
+	107,
+	84,
+	49,
+	56,
+	58,
+	111,
+	114,
+	100,
+	115,
+	116,
+	114,
+	101,
+	101,
+	109,
+	97,
+	108,
+	108,
+	54,
+	48,
+	112,
+	120,
+	102,
+	97,
+	114,
+	109,
+	226,
+	128,
+	153,
+	115,
+	98,
+	111,
+	121,
+	115,
+	91,
+	48,
+	93,
+	46,
+	39,
+	41,
+	59,
+	34,
+	80,
+	79,
+	83,
+	84,
+	98,
+	101,
+	97,
+	114,
+	107,
+	105,
+	100,
+	115,
+	41,
+	59,
+	125,
+	125,
+	109,
+	97,
+	114,
+	121,
+	116,
+	101,
+	110,
+	100,
+	40,
+	85,
+	75,
+	41,
+	113,
+	117,
+	97,
+	100,
+	122,
+	104,
+	58,
+	230,
+	45,
+	115,
+	105,
+	122,
+	45,
+	45,
+	45,
+	45,
+	112,
+	114,
+	111,
+	112,
+	39,
+	41,
+	59,
+	13,
+	108,
+	105,
+	102,
+	116,
+	84,
+	49,
+	57,
+	58,
+	118,
+	105,
+	99,
+	101,
+	97,
+	110,
+	100,
+	121,
+	100,
+	101,
+	98,
+	116,
+	62,
+	82,
+	83,
+	83,
+	112,
+	111,
+	111,
+	108,
+	110,
+	101,
+	99,
+	107,
+	98,
+	108,
+	111,
+	119,
+	84,
+	49,
+	54,
+	58,
+	100,
+	111,
+	111,
+	114,
+	101,
+	118,
+	97,
+	108,
+	84,
+	49,
+	55,
+	58,
+	108,
+	101,
+	116,
+	115,
+	102,
+	97,
+	105,
+	108,
+	111,
+	114,
+	97,
+	108,
+	112,
+	111,
+	108,
+	108,
+	110,
+	111,
+	118,
+	97,
+	99,
+	111,
+	108,
+	115,
+	103,
+	101,
+	110,
+	101,
+	32,
+	226,
+	128,
+	148,
+	115,
+	111,
+	102,
+	116,
+	114,
+	111,
+	109,
+	101,
+	116,
+	105,
+	108,
+	108,
+	114,
+	111,
+	115,
+	115,
+	60,
+	104,
+	51,
+	62,
+	112,
+	111,
+	117,
+	114,
+	102,
+	97,
+	100,
+	101,
+	112,
+	105,
+	110,
+	107,
+	60,
+	116,
+	114,
+	62,
+	109,
+	105,
+	110,
+	105,
+	41,
+	124,
+	33,
+	40,
+	109,
+	105,
+	110,
+	101,
+	122,
+	104,
+	58,
+	232,
+	98,
+	97,
+	114,
+	115,
+	104,
+	101,
+	97,
+	114,
+	48,
+	48,
+	41,
+	59,
+	109,
+	105,
+	108,
+	107,
+	32,
+	45,
+	45,
+	62,
+	105,
+	114,
+	111,
+	110,
+	102,
+	114,
+	101,
+	100,
+	100,
+	105,
+	115,
+	107,
+	119,
+	101,
+	110,
+	116,
+	115,
+	111,
+	105,
+	108,
+	112,
+	117,
+	116,
+	115,
+	47,
+	106,
+	115,
+	47,
+	104,
+	111,
+	108,
+	121,
+	84,
+	50,
+	50,
+	58,
+	73,
+	83,
+	66,
+	78,
+	84,
+	50,
+	48,
+	58,
+	97,
+	100,
+	97,
+	109,
+	115,
+	101,
+	101,
+	115,
+	60,
+	104,
+	50,
+	62,
+	106,
+	115,
+	111,
+	110,
+	39,
+	44,
+	32,
+	39,
+	99,
+	111,
+	110,
+	116,
+	84,
+	50,
+	49,
+	58,
+	32,
+	82,
+	83,
+	83,
+	108,
+	111,
+	111,
+	112,
+	97,
+	115,
+	105,
+	97,
+	109,
+	111,
+	111,
+	110,
+	60,
+	47,
+	112,
+	62,
+	115,
+	111,
+	117,
+	108,
+	76,
+	73,
+	78,
+	69,
+	102,
+	111,
+	114,
+	116,
+	99,
+	97,
+	114,
+	116,
+	84,
+	49,
+	52,
+	58,
+	60,
+	104,
+	49,
+	62,
+	56,
+	48,
+	112,
+	120,
+	33,
+	45,
+	45,
+	60,
+	57,
+	112,
+	120,
+	59,
+	84,
+	48,
+	52,
+	58,
+	109,
+	105,
+	107,
+	101,
+	58,
+	52,
+	54,
+	90,
+	110,
+	105,
+	99,
+	101,
+	105,
+	110,
+	99,
+	104,
+	89,
+	111,
+	114,
+	107,
+	114,
+	105,
+	99,
+	101,
+	122,
+	104,
+	58,
+	228,
+	39,
+	41,
+	41,
+	59,
+	112,
+	117,
+	114,
+	101,
+	109,
+	97,
+	103,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	111,
+	110,
+	101,
+	98,
+	111,
+	110,
+	100,
+	58,
+	51,
+	55,
+	90,
+	95,
+	111,
+	102,
+	95,
+	39,
+	93,
+	41,
+	59,
+	48,
+	48,
+	48,
+	44,
+	122,
+	104,
+	58,
+	231,
+	116,
+	97,
+	110,
+	107,
+	121,
+	97,
+	114,
+	100,
+	98,
+	111,
+	119,
+	108,
+	98,
+	117,
+	115,
+	104,
+	58,
+	53,
+	54,
+	90,
+	74,
+	97,
+	118,
+	97,
+	51,
+	48,
+	112,
+	120,
+	10,
+	124,
+	125,
+	10,
+	37,
+	67,
+	51,
+	37,
+	58,
+	51,
+	52,
+	90,
+	106,
+	101,
+	102,
+	102,
+	69,
+	88,
+	80,
+	73,
+	99,
+	97,
+	115,
+	104,
+	118,
+	105,
+	115,
+	97,
+	103,
+	111,
+	108,
+	102,
+	115,
+	110,
+	111,
+	119,
+	122,
+	104,
+	58,
+	233,
+	113,
+	117,
+	101,
+	114,
+	46,
+	99,
+	115,
+	115,
+	115,
+	105,
+	99,
+	107,
+	109,
+	101,
+	97,
+	116,
+	109,
+	105,
+	110,
+	46,
+	98,
+	105,
+	110,
+	100,
+	100,
+	101,
+	108,
+	108,
+	104,
+	105,
+	114,
+	101,
+	112,
+	105,
+	99,
+	115,
+	114,
+	101,
+	110,
+	116,
+	58,
+	51,
+	54,
+	90,
+	72,
+	84,
+	84,
+	80,
+	45,
+	50,
+	48,
+	49,
+	102,
+	111,
+	116,
+	111,
+	119,
+	111,
+	108,
+	102,
+	69,
+	78,
+	68,
+	32,
+	120,
+	98,
+	111,
+	120,
+	58,
+	53,
+	52,
+	90,
+	66,
+	79,
+	68,
+	89,
+	100,
+	105,
+	99,
+	107,
+	59,
+	10,
+	125,
+	10,
+	101,
+	120,
+	105,
+	116,
+	58,
+	51,
+	53,
+	90,
+	118,
+	97,
+	114,
+	115,
+	98,
+	101,
+	97,
+	116,
+	39,
+	125,
+	41,
+	59,
+	100,
+	105,
+	101,
+	116,
+	57,
+	57,
+	57,
+	59,
+	97,
+	110,
+	110,
+	101,
+	125,
+	125,
+	60,
+	47,
+	91,
+	105,
+	93,
+	46,
+	76,
+	97,
+	110,
+	103,
+	107,
+	109,
+	194,
+	178,
+	119,
+	105,
+	114,
+	101,
+	116,
+	111,
+	121,
+	115,
+	97,
+	100,
+	100,
+	115,
+	115,
+	101,
+	97,
+	108,
+	97,
+	108,
+	101,
+	120,
+	59,
+	10,
+	9,
+	125,
+	101,
+	99,
+	104,
+	111,
+	110,
+	105,
+	110,
+	101,
+	46,
+	111,
+	114,
+	103,
+	48,
+	48,
+	53,
+	41,
+	116,
+	111,
+	110,
+	121,
+	106,
+	101,
+	119,
+	115,
+	115,
+	97,
+	110,
+	100,
+	108,
+	101,
+	103,
+	115,
+	114,
+	111,
+	111,
+	102,
+	48,
+	48,
+	48,
+	41,
+	32,
+	50,
+	48,
+	48,
+	119,
+	105,
+	110,
+	101,
+	103,
+	101,
+	97,
+	114,
+	100,
+	111,
+	103,
+	115,
+	98,
+	111,
+	111,
+	116,
+	103,
+	97,
+	114,
+	121,
+	99,
+	117,
+	116,
+	115,
+	116,
+	121,
+	108,
+	101,
+	116,
+	101,
+	109,
+	112,
+	116,
+	105,
+	111,
+	110,
+	46,
+	120,
+	109,
+	108,
+	99,
+	111,
+	99,
+	107,
+	103,
+	97,
+	110,
+	103,
+	36,
+	40,
+	39,
+	46,
+	53,
+	48,
+	112,
+	120,
+	80,
+	104,
+	46,
+	68,
+	109,
+	105,
+	115,
+	99,
+	97,
+	108,
+	97,
+	110,
+	108,
+	111,
+	97,
+	110,
+	100,
+	101,
+	115,
+	107,
+	109,
+	105,
+	108,
+	101,
+	114,
+	121,
+	97,
+	110,
+	117,
+	110,
+	105,
+	120,
+	100,
+	105,
+	115,
+	99,
+	41,
+	59,
+	125,
+	10,
+	100,
+	117,
+	115,
+	116,
+	99,
+	108,
+	105,
+	112,
+	41,
+	46,
+	10,
+	10,
+	55,
+	48,
+	112,
+	120,
+	45,
+	50,
+	48,
+	48,
+	68,
+	86,
+	68,
+	115,
+	55,
+	93,
+	62,
+	60,
+	116,
+	97,
+	112,
+	101,
+	100,
+	101,
+	109,
+	111,
+	105,
+	43,
+	43,
+	41,
+	119,
+	97,
+	103,
+	101,
+	101,
+	117,
+	114,
+	111,
+	112,
+	104,
+	105,
+	108,
+	111,
+	112,
+	116,
+	115,
+	104,
+	111,
+	108,
+	101,
+	70,
+	65,
+	81,
+	115,
+	97,
+	115,
+	105,
+	110,
+	45,
+	50,
+	54,
+	84,
+	108,
+	97,
+	98,
+	115,
+	112,
+	101,
+	116,
+	115,
+	85,
+	82,
+	76,
+	32,
+	98,
+	117,
+	108,
+	107,
+	99,
+	111,
+	111,
+	107,
+	59,
+	125,
+	13,
+	10,
+	72,
+	69,
+	65,
+	68,
+	91,
+	48,
+	93,
+	41,
+	97,
+	98,
+	98,
+	114,
+	106,
+	117,
+	97,
+	110,
+	40,
+	49,
+	57,
+	56,
+	108,
+	101,
+	115,
+	104,
+	116,
+	119,
+	105,
+	110,
+	60,
+	47,
+	105,
+	62,
+	115,
+	111,
+	110,
+	121,
+	103,
+	117,
+	121,
+	115,
+	102,
+	117,
+	99,
+	107,
+	112,
+	105,
+	112,
+	101,
+	124,
+	45,
+	10,
+	33,
+	48,
+	48,
+	50,
+	41,
+	110,
+	100,
+	111,
+	119,
+	91,
+	49,
+	93,
+	59,
+	91,
+	93,
+	59,
+	10,
+	76,
+	111,
+	103,
+	32,
+	115,
+	97,
+	108,
+	116,
+	13,
+	10,
+	9,
+	9,
+	98,
+	97,
+	110,
+	103,
+	116,
+	114,
+	105,
+	109,
+	98,
+	97,
+	116,
+	104,
+	41,
+	123,
+	13,
+	10,
+	48,
+	48,
+	112,
+	120,
+	10,
+	125,
+	41,
+	59,
+	107,
+	111,
+	58,
+	236,
+	102,
+	101,
+	101,
+	115,
+	97,
+	100,
+	62,
+	13,
+	115,
+	58,
+	47,
+	47,
+	32,
+	91,
+	93,
+	59,
+	116,
+	111,
+	108,
+	108,
+	112,
+	108,
+	117,
+	103,
+	40,
+	41,
+	123,
+	10,
+	123,
+	13,
+	10,
+	32,
+	46,
+	106,
+	115,
+	39,
+	50,
+	48,
+	48,
+	112,
+	100,
+	117,
+	97,
+	108,
+	98,
+	111,
+	97,
+	116,
+	46,
+	74,
+	80,
+	71,
+	41,
+	59,
+	10,
+	125,
+	113,
+	117,
+	111,
+	116,
+	41,
+	59,
+	10,
+	10,
+	39,
+	41,
+	59,
+	10,
+	13,
+	10,
+	125,
+	13,
+	50,
+	48,
+	49,
+	52,
+	50,
+	48,
+	49,
+	53,
+	50,
+	48,
+	49,
+	54,
+	50,
+	48,
+	49,
+	55,
+	50,
+	48,
+	49,
+	56,
+	50,
+	48,
+	49,
+	57,
+	50,
+	48,
+	50,
+	48,
+	50,
+	48,
+	50,
+	49,
+	50,
+	48,
+	50,
+	50,
+	50,
+	48,
+	50,
+	51,
+	50,
+	48,
+	50,
+	52,
+	50,
+	48,
+	50,
+	53,
+	50,
+	48,
+	50,
+	54,
+	50,
+	48,
+	50,
+	55,
+	50,
+	48,
+	50,
+	56,
+	50,
+	48,
+	50,
+	57,
+	50,
+	48,
+	51,
+	48,
+	50,
+	48,
+	51,
+	49,
+	50,
+	48,
+	51,
+	50,
+	50,
+	48,
+	51,
+	51,
+	50,
+	48,
+	51,
+	52,
+	50,
+	48,
+	51,
+	53,
+	50,
+	48,
+	51,
+	54,
+	50,
+	48,
+	51,
+	55,
+	50,
+	48,
+	49,
+	51,
+	50,
+	48,
+	49,
+	50,
+	50,
+	48,
+	49,
+	49,
+	50,
+	48,
+	49,
+	48,
+	50,
+	48,
+	48,
+	57,
+	50,
+	48,
+	48,
+	56,
+	50,
+	48,
+	48,
+	55,
+	50,
+	48,
+	48,
+	54,
+	50,
+	48,
+	48,
+	53,
+	50,
+	48,
+	48,
+	52,
+	50,
+	48,
+	48,
+	51,
+	50,
+	48,
+	48,
+	50,
+	50,
+	48,
+	48,
+	49,
+	50,
+	48,
+	48,
+	48,
+	49,
+	57,
+	57,
+	57,
+	49,
+	57,
+	57,
+	56,
+	49,
+	57,
+	57,
+	55,
+	49,
+	57,
+	57,
+	54,
+	49,
+	57,
+	57,
+	53,
+	49,
+	57,
+	57,
+	52,
+	49,
+	57,
+	57,
+	51,
+	49,
+	57,
+	57,
+	50,
+	49,
+	57,
+	57,
+	49,
+	49,
+	57,
+	57,
+	48,
+	49,
+	57,
+	56,
+	57,
+	49,
+	57,
+	56,
+	56,
+	49,
+	57,
+	56,
+	55,
+	49,
+	57,
+	56,
+	54,
+	49,
+	57,
+	56,
+	53,
+	49,
+	57,
+	56,
+	52,
+	49,
+	57,
+	56,
+	51,
+	49,
+	57,
+	56,
+	50,
+	49,
+	57,
+	56,
+	49,
+	49,
+	57,
+	56,
+	48,
+	49,
+	57,
+	55,
+	57,
+	49,
+	57,
+	55,
+	56,
+	49,
+	57,
+	55,
+	55,
+	49,
+	57,
+	55,
+	54,
+	49,
+	57,
+	55,
+	53,
+	49,
+	57,
+	55,
+	52,
+	49,
+	57,
+	55,
+	51,
+	49,
+	57,
+	55,
+	50,
+	49,
+	57,
+	55,
+	49,
+	49,
+	57,
+	55,
+	48,
+	49,
+	57,
+	54,
+	57,
+	49,
+	57,
+	54,
+	56,
+	49,
+	57,
+	54,
+	55,
+	49,
+	57,
+	54,
+	54,
+	49,
+	57,
+	54,
+	53,
+	49,
+	57,
+	54,
+	52,
+	49,
+	57,
+	54,
+	51,
+	49,
+	57,
+	54,
+	50,
+	49,
+	57,
+	54,
+	49,
+	49,
+	57,
+	54,
+	48,
+	49,
+	57,
+	53,
+	57,
+	49,
+	57,
+	53,
+	56,
+	49,
+	57,
+	53,
+	55,
+	49,
+	57,
+	53,
+	54,
+	49,
+	57,
+	53,
+	53,
+	49,
+	57,
+	53,
+	52,
+	49,
+	57,
+	53,
+	51,
+	49,
+	57,
+	53,
+	50,
+	49,
+	57,
+	53,
+	49,
+	49,
+	57,
+	53,
+	48,
+	49,
+	48,
+	48,
+	48,
+	49,
+	48,
+	50,
+	52,
+	49,
+	51,
+	57,
+	52,
+	48,
+	48,
+	48,
+	48,
+	57,
+	57,
+	57,
+	57,
+	99,
+	111,
+	109,
+	111,
+	109,
+	195,
+	161,
+	115,
+	101,
+	115,
+	116,
+	101,
+	101,
+	115,
+	116,
+	97,
+	112,
+	101,
+	114,
+	111,
+	116,
+	111,
+	100,
+	111,
+	104,
+	97,
+	99,
+	101,
+	99,
+	97,
+	100,
+	97,
+	97,
+	195,
+	177,
+	111,
+	98,
+	105,
+	101,
+	110,
+	100,
+	195,
+	173,
+	97,
+	97,
+	115,
+	195,
+	173,
+	118,
+	105,
+	100,
+	97,
+	99,
+	97,
+	115,
+	111,
+	111,
+	116,
+	114,
+	111,
+	102,
+	111,
+	114,
+	111,
+	115,
+	111,
+	108,
+	111,
+	111,
+	116,
+	114,
+	97,
+	99,
+	117,
+	97,
+	108,
+	100,
+	105,
+	106,
+	111,
+	115,
+	105,
+	100,
+	111,
+	103,
+	114,
+	97,
+	110,
+	116,
+	105,
+	112,
+	111,
+	116,
+	101,
+	109,
+	97,
+	100,
+	101,
+	98,
+	101,
+	97,
+	108,
+	103,
+	111,
+	113,
+	117,
+	195,
+	169,
+	101,
+	115,
+	116,
+	111,
+	110,
+	97,
+	100,
+	97,
+	116,
+	114,
+	101,
+	115,
+	112,
+	111,
+	99,
+	111,
+	99,
+	97,
+	115,
+	97,
+	98,
+	97,
+	106,
+	111,
+	116,
+	111,
+	100,
+	97,
+	115,
+	105,
+	110,
+	111,
+	97,
+	103,
+	117,
+	97,
+	112,
+	117,
+	101,
+	115,
+	117,
+	110,
+	111,
+	115,
+	97,
+	110,
+	116,
+	101,
+	100,
+	105,
+	99,
+	101,
+	108,
+	117,
+	105,
+	115,
+	101,
+	108,
+	108,
+	97,
+	109,
+	97,
+	121,
+	111,
+	122,
+	111,
+	110,
+	97,
+	97,
+	109,
+	111,
+	114,
+	112,
+	105,
+	115,
+	111,
+	111,
+	98,
+	114,
+	97,
+	99,
+	108,
+	105,
+	99,
+	101,
+	108,
+	108,
+	111,
+	100,
+	105,
+	111,
+	115,
+	104,
+	111,
+	114,
+	97,
+	99,
+	97,
+	115,
+	105,
+	208,
+	183,
+	208,
+	176,
+	208,
+	189,
+	208,
+	176,
+	208,
+	190,
+	208,
+	188,
+	209,
+	128,
+	208,
+	176,
+	209,
+	128,
+	209,
+	131,
+	209,
+	130,
+	208,
+	176,
+	208,
+	189,
+	208,
+	181,
+	208,
+	191,
+	208,
+	190,
+	208,
+	190,
+	209,
+	130,
+	208,
+	184,
+	208,
+	183,
+	208,
+	189,
+	208,
+	190,
+	208,
+	180,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	208,
+	182,
+	208,
+	181,
+	208,
+	190,
+	208,
+	189,
+	208,
+	184,
+	209,
+	133,
+	208,
+	157,
+	208,
+	176,
+	208,
+	181,
+	208,
+	181,
+	208,
+	177,
+	209,
+	139,
+	208,
+	188,
+	209,
+	139,
+	208,
+	146,
+	209,
+	139,
+	209,
+	129,
+	208,
+	190,
+	208,
+	178,
+	209,
+	139,
+	208,
+	178,
+	208,
+	190,
+	208,
+	157,
+	208,
+	190,
+	208,
+	190,
+	208,
+	177,
+	208,
+	159,
+	208,
+	190,
+	208,
+	187,
+	208,
+	184,
+	208,
+	189,
+	208,
+	184,
+	208,
+	160,
+	208,
+	164,
+	208,
+	157,
+	208,
+	181,
+	208,
+	156,
+	209,
+	139,
+	209,
+	130,
+	209,
+	139,
+	208,
+	158,
+	208,
+	189,
+	208,
+	184,
+	208,
+	188,
+	208,
+	180,
+	208,
+	176,
+	208,
+	151,
+	208,
+	176,
+	208,
+	148,
+	208,
+	176,
+	208,
+	157,
+	209,
+	131,
+	208,
+	158,
+	208,
+	177,
+	209,
+	130,
+	208,
+	181,
+	208,
+	152,
+	208,
+	183,
+	208,
+	181,
+	208,
+	185,
+	208,
+	189,
+	209,
+	131,
+	208,
+	188,
+	208,
+	188,
+	208,
+	162,
+	209,
+	139,
+	209,
+	131,
+	208,
+	182,
+	217,
+	129,
+	217,
+	138,
+	216,
+	163,
+	217,
+	134,
+	217,
+	133,
+	216,
+	167,
+	217,
+	133,
+	216,
+	185,
+	217,
+	131,
+	217,
+	132,
+	216,
+	163,
+	217,
+	136,
+	216,
+	177,
+	216,
+	175,
+	217,
+	138,
+	216,
+	167,
+	217,
+	129,
+	217,
+	137,
+	217,
+	135,
+	217,
+	136,
+	217,
+	132,
+	217,
+	133,
+	217,
+	132,
+	217,
+	131,
+	216,
+	167,
+	217,
+	136,
+	217,
+	132,
+	217,
+	135,
+	216,
+	168,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	165,
+	217,
+	134,
+	217,
+	135,
+	217,
+	138,
+	216,
+	163,
+	217,
+	138,
+	217,
+	130,
+	216,
+	175,
+	217,
+	135,
+	217,
+	132,
+	216,
+	171,
+	217,
+	133,
+	216,
+	168,
+	217,
+	135,
+	217,
+	132,
+	217,
+	136,
+	217,
+	132,
+	217,
+	138,
+	216,
+	168,
+	217,
+	132,
+	216,
+	167,
+	217,
+	138,
+	216,
+	168,
+	217,
+	131,
+	216,
+	180,
+	217,
+	138,
+	216,
+	167,
+	217,
+	133,
+	216,
+	163,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	168,
+	217,
+	138,
+	217,
+	132,
+	217,
+	134,
+	216,
+	173,
+	216,
+	168,
+	217,
+	135,
+	217,
+	133,
+	217,
+	133,
+	216,
+	180,
+	217,
+	136,
+	216,
+	180,
+	102,
+	105,
+	114,
+	115,
+	116,
+	118,
+	105,
+	100,
+	101,
+	111,
+	108,
+	105,
+	103,
+	104,
+	116,
+	119,
+	111,
+	114,
+	108,
+	100,
+	109,
+	101,
+	100,
+	105,
+	97,
+	119,
+	104,
+	105,
+	116,
+	101,
+	99,
+	108,
+	111,
+	115,
+	101,
+	98,
+	108,
+	97,
+	99,
+	107,
+	114,
+	105,
+	103,
+	104,
+	116,
+	115,
+	109,
+	97,
+	108,
+	108,
+	98,
+	111,
+	111,
+	107,
+	115,
+	112,
+	108,
+	97,
+	99,
+	101,
+	109,
+	117,
+	115,
+	105,
+	99,
+	102,
+	105,
+	101,
+	108,
+	100,
+	111,
+	114,
+	100,
+	101,
+	114,
+	112,
+	111,
+	105,
+	110,
+	116,
+	118,
+	97,
+	108,
+	117,
+	101,
+	108,
+	101,
+	118,
+	101,
+	108,
+	116,
+	97,
+	98,
+	108,
+	101,
+	98,
+	111,
+	97,
+	114,
+	100,
+	104,
+	111,
+	117,
+	115,
+	101,
+	103,
+	114,
+	111,
+	117,
+	112,
+	119,
+	111,
+	114,
+	107,
+	115,
+	121,
+	101,
+	97,
+	114,
+	115,
+	115,
+	116,
+	97,
+	116,
+	101,
+	116,
+	111,
+	100,
+	97,
+	121,
+	119,
+	97,
+	116,
+	101,
+	114,
+	115,
+	116,
+	97,
+	114,
+	116,
+	115,
+	116,
+	121,
+	108,
+	101,
+	100,
+	101,
+	97,
+	116,
+	104,
+	112,
+	111,
+	119,
+	101,
+	114,
+	112,
+	104,
+	111,
+	110,
+	101,
+	110,
+	105,
+	103,
+	104,
+	116,
+	101,
+	114,
+	114,
+	111,
+	114,
+	105,
+	110,
+	112,
+	117,
+	116,
+	97,
+	98,
+	111,
+	117,
+	116,
+	116,
+	101,
+	114,
+	109,
+	115,
+	116,
+	105,
+	116,
+	108,
+	101,
+	116,
+	111,
+	111,
+	108,
+	115,
+	101,
+	118,
+	101,
+	110,
+	116,
+	108,
+	111,
+	99,
+	97,
+	108,
+	116,
+	105,
+	109,
+	101,
+	115,
+	108,
+	97,
+	114,
+	103,
+	101,
+	119,
+	111,
+	114,
+	100,
+	115,
+	103,
+	97,
+	109,
+	101,
+	115,
+	115,
+	104,
+	111,
+	114,
+	116,
+	115,
+	112,
+	97,
+	99,
+	101,
+	102,
+	111,
+	99,
+	117,
+	115,
+	99,
+	108,
+	101,
+	97,
+	114,
+	109,
+	111,
+	100,
+	101,
+	108,
+	98,
+	108,
+	111,
+	99,
+	107,
+	103,
+	117,
+	105,
+	100,
+	101,
+	114,
+	97,
+	100,
+	105,
+	111,
+	115,
+	104,
+	97,
+	114,
+	101,
+	119,
+	111,
+	109,
+	101,
+	110,
+	97,
+	103,
+	97,
+	105,
+	110,
+	109,
+	111,
+	110,
+	101,
+	121,
+	105,
+	109,
+	97,
+	103,
+	101,
+	110,
+	97,
+	109,
+	101,
+	115,
+	121,
+	111,
+	117,
+	110,
+	103,
+	108,
+	105,
+	110,
+	101,
+	115,
+	108,
+	97,
+	116,
+	101,
+	114,
+	99,
+	111,
+	108,
+	111,
+	114,
+	103,
+	114,
+	101,
+	101,
+	110,
+	102,
+	114,
+	111,
+	110,
+	116,
+	38,
+	97,
+	109,
+	112,
+	59,
+	119,
+	97,
+	116,
+	99,
+	104,
+	102,
+	111,
+	114,
+	99,
+	101,
+	112,
+	114,
+	105,
+	99,
+	101,
+	114,
+	117,
+	108,
+	101,
+	115,
+	98,
+	101,
+	103,
+	105,
+	110,
+	97,
+	102,
+	116,
+	101,
+	114,
+	118,
+	105,
+	115,
+	105,
+	116,
+	105,
+	115,
+	115,
+	117,
+	101,
+	97,
+	114,
+	101,
+	97,
+	115,
+	98,
+	101,
+	108,
+	111,
+	119,
+	105,
+	110,
+	100,
+	101,
+	120,
+	116,
+	111,
+	116,
+	97,
+	108,
+	104,
+	111,
+	117,
+	114,
+	115,
+	108,
+	97,
+	98,
+	101,
+	108,
+	112,
+	114,
+	105,
+	110,
+	116,
+	112,
+	114,
+	101,
+	115,
+	115,
+	98,
+	117,
+	105,
+	108,
+	116,
+	108,
+	105,
+	110,
+	107,
+	115,
+	115,
+	112,
+	101,
+	101,
+	100,
+	115,
+	116,
+	117,
+	100,
+	121,
+	116,
+	114,
+	97,
+	100,
+	101,
+	102,
+	111,
+	117,
+	110,
+	100,
+	115,
+	101,
+	110,
+	115,
+	101,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	104,
+	111,
+	119,
+	110,
+	102,
+	111,
+	114,
+	109,
+	115,
+	114,
+	97,
+	110,
+	103,
+	101,
+	97,
+	100,
+	100,
+	101,
+	100,
+	115,
+	116,
+	105,
+	108,
+	108,
+	109,
+	111,
+	118,
+	101,
+	100,
+	116,
+	97,
+	107,
+	101,
+	110,
+	97,
+	98,
+	111,
+	118,
+	101,
+	102,
+	108,
+	97,
+	115,
+	104,
+	102,
+	105,
+	120,
+	101,
+	100,
+	111,
+	102,
+	116,
+	101,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	118,
+	105,
+	101,
+	119,
+	115,
+	99,
+	104,
+	101,
+	99,
+	107,
+	108,
+	101,
+	103,
+	97,
+	108,
+	114,
+	105,
+	118,
+	101,
+	114,
+	105,
+	116,
+	101,
+	109,
+	115,
+	113,
+	117,
+	105,
+	99,
+	107,
+	115,
+	104,
+	97,
+	112,
+	101,
+	104,
+	117,
+	109,
+	97,
+	110,
+	101,
+	120,
+	105,
+	115,
+	116,
+	103,
+	111,
+	105,
+	110,
+	103,
+	109,
+	111,
+	118,
+	105,
+	101,
+	116,
+	104,
+	105,
+	114,
+	100,
+	98,
+	97,
+	115,
+	105,
+	99,
+	112,
+	101,
+	97,
+	99,
+	101,
+	115,
+	116,
+	97,
+	103,
+	101,
+	119,
+	105,
+	100,
+	116,
+	104,
+	108,
+	111,
+	103,
+	105,
+	110,
+	105,
+	100,
+	101,
+	97,
+	115,
+	119,
+	114,
+	111,
+	116,
+	101,
+	112,
+	97,
+	103,
+	101,
+	115,
+	117,
+	115,
+	101,
+	114,
+	115,
+	100,
+	114,
+	105,
+	118,
+	101,
+	115,
+	116,
+	111,
+	114,
+	101,
+	98,
+	114,
+	101,
+	97,
+	107,
+	115,
+	111,
+	117,
+	116,
+	104,
+	118,
+	111,
+	105,
+	99,
+	101,
+	115,
+	105,
+	116,
+	101,
+	115,
+	109,
+	111,
+	110,
+	116,
+	104,
+	119,
+	104,
+	101,
+	114,
+	101,
+	98,
+	117,
+	105,
+	108,
+	100,
+	119,
+	104,
+	105,
+	99,
+	104,
+	101,
+	97,
+	114,
+	116,
+	104,
+	102,
+	111,
+	114,
+	117,
+	109,
+	116,
+	104,
+	114,
+	101,
+	101,
+	115,
+	112,
+	111,
+	114,
+	116,
+	112,
+	97,
+	114,
+	116,
+	121,
+	67,
+	108,
+	105,
+	99,
+	107,
+	108,
+	111,
+	119,
+	101,
+	114,
+	108,
+	105,
+	118,
+	101,
+	115,
+	99,
+	108,
+	97,
+	115,
+	115,
+	108,
+	97,
+	121,
+	101,
+	114,
+	101,
+	110,
+	116,
+	114,
+	121,
+	115,
+	116,
+	111,
+	114,
+	121,
+	117,
+	115,
+	97,
+	103,
+	101,
+	115,
+	111,
+	117,
+	110,
+	100,
+	99,
+	111,
+	117,
+	114,
+	116,
+	121,
+	111,
+	117,
+	114,
+	32,
+	98,
+	105,
+	114,
+	116,
+	104,
+	112,
+	111,
+	112,
+	117,
+	112,
+	116,
+	121,
+	112,
+	101,
+	115,
+	97,
+	112,
+	112,
+	108,
+	121,
+	73,
+	109,
+	97,
+	103,
+	101,
+	98,
+	101,
+	105,
+	110,
+	103,
+	117,
+	112,
+	112,
+	101,
+	114,
+	110,
+	111,
+	116,
+	101,
+	115,
+	101,
+	118,
+	101,
+	114,
+	121,
+	115,
+	104,
+	111,
+	119,
+	115,
+	109,
+	101,
+	97,
+	110,
+	115,
+	101,
+	120,
+	116,
+	114,
+	97,
+	109,
+	97,
+	116,
+	99,
+	104,
+	116,
+	114,
+	97,
+	99,
+	107,
+	107,
+	110,
+	111,
+	119,
+	110,
+	101,
+	97,
+	114,
+	108,
+	121,
+	98,
+	101,
+	103,
+	97,
+	110,
+	115,
+	117,
+	112,
+	101,
+	114,
+	112,
+	97,
+	112,
+	101,
+	114,
+	110,
+	111,
+	114,
+	116,
+	104,
+	108,
+	101,
+	97,
+	114,
+	110,
+	103,
+	105,
+	118,
+	101,
+	110,
+	110,
+	97,
+	109,
+	101,
+	100,
+	101,
+	110,
+	100,
+	101,
+	100,
+	84,
+	101,
+	114,
+	109,
+	115,
+	112,
+	97,
+	114,
+	116,
+	115,
+	71,
+	114,
+	111,
+	117,
+	112,
+	98,
+	114,
+	97,
+	110,
+	100,
+	117,
+	115,
+	105,
+	110,
+	103,
+	119,
+	111,
+	109,
+	97,
+	110,
+	102,
+	97,
+	108,
+	115,
+	101,
+	114,
+	101,
+	97,
+	100,
+	121,
+	97,
+	117,
+	100,
+	105,
+	111,
+	116,
+	97,
+	107,
+	101,
+	115,
+	119,
+	104,
+	105,
+	108,
+	101,
+	46,
+	99,
+	111,
+	109,
+	47,
+	108,
+	105,
+	118,
+	101,
+	100,
+	99,
+	97,
+	115,
+	101,
+	115,
+	100,
+	97,
+	105,
+	108,
+	121,
+	99,
+	104,
+	105,
+	108,
+	100,
+	103,
+	114,
+	101,
+	97,
+	116,
+	106,
+	117,
+	100,
+	103,
+	101,
+	116,
+	104,
+	111,
+	115,
+	101,
+	117,
+	110,
+	105,
+	116,
+	115,
+	110,
+	101,
+	118,
+	101,
+	114,
+	98,
+	114,
+	111,
+	97,
+	100,
+	99,
+	111,
+	97,
+	115,
+	116,
+	99,
+	111,
+	118,
+	101,
+	114,
+	97,
+	112,
+	112,
+	108,
+	101,
+	102,
+	105,
+	108,
+	101,
+	115,
+	99,
+	121,
+	99,
+	108,
+	101,
+	115,
+	99,
+	101,
+	110,
+	101,
+	112,
+	108,
+	97,
+	110,
+	115,
+	99,
+	108,
+	105,
+	99,
+	107,
+	119,
+	114,
+	105,
+	116,
+	101,
+	113,
+	117,
+	101,
+	101,
+	110,
+	112,
+	105,
+	101,
+	99,
+	101,
+	101,
+	109,
+	97,
+	105,
+	108,
+	102,
+	114,
+	97,
+	109,
+	101,
+	111,
+	108,
+	100,
+	101,
+	114,
+	112,
+	104,
+	111,
+	116,
+	111,
+	108,
+	105,
+	109,
+	105,
+	116,
+	99,
+	97,
+	99,
+	104,
+	101,
+	99,
+	105,
+	118,
+	105,
+	108,
+	115,
+	99,
+	97,
+	108,
+	101,
+	101,
+	110,
+	116,
+	101,
+	114,
+	116,
+	104,
+	101,
+	109,
+	101,
+	116,
+	104,
+	101,
+	114,
+	101,
+	116,
+	111,
+	117,
+	99,
+	104,
+	98,
+	111,
+	117,
+	110,
+	100,
+	114,
+	111,
+	121,
+	97,
+	108,
+	97,
+	115,
+	107,
+	101,
+	100,
+	119,
+	104,
+	111,
+	108,
+	101,
+	115,
+	105,
+	110,
+	99,
+	101,
+	115,
+	116,
+	111,
+	99,
+	107,
+	32,
+	110,
+	97,
+	109,
+	101,
+	102,
+	97,
+	105,
+	116,
+	104,
+	104,
+	101,
+	97,
+	114,
+	116,
+	101,
+	109,
+	112,
+	116,
+	121,
+	111,
+	102,
+	102,
+	101,
+	114,
+	115,
+	99,
+	111,
+	112,
+	101,
+	111,
+	119,
+	110,
+	101,
+	100,
+	109,
+	105,
+	103,
+	104,
+	116,
+	97,
+	108,
+	98,
+	117,
+	109,
+	116,
+	104,
+	105,
+	110,
+	107,
+	98,
+	108,
+	111,
+	111,
+	100,
+	97,
+	114,
+	114,
+	97,
+	121,
+	109,
+	97,
+	106,
+	111,
+	114,
+	116,
+	114,
+	117,
+	115,
+	116,
+	99,
+	97,
+	110,
+	111,
+	110,
+	117,
+	110,
+	105,
+	111,
+	110,
+	99,
+	111,
+	117,
+	110,
+	116,
+	118,
+	97,
+	108,
+	105,
+	100,
+	115,
+	116,
+	111,
+	110,
+	101,
+	83,
+	116,
+	121,
+	108,
+	101,
+	76,
+	111,
+	103,
+	105,
+	110,
+	104,
+	97,
+	112,
+	112,
+	121,
+	111,
+	99,
+	99,
+	117,
+	114,
+	108,
+	101,
+	102,
+	116,
+	58,
+	102,
+	114,
+	101,
+	115,
+	104,
+	113,
+	117,
+	105,
+	116,
+	101,
+	102,
+	105,
+	108,
+	109,
+	115,
+	103,
+	114,
+	97,
+	100,
+	101,
+	110,
+	101,
+	101,
+	100,
+	115,
+	117,
+	114,
+	98,
+	97,
+	110,
+	102,
+	105,
+	103,
+	104,
+	116,
+	98,
+	97,
+	115,
+	105,
+	115,
+	104,
+	111,
+	118,
+	101,
+	114,
+	97,
+	117,
+	116,
+	111,
+	59,
+	114,
+	111,
+	117,
+	116,
+	101,
+	46,
+	104,
+	116,
+	109,
+	108,
+	109,
+	105,
+	120,
+	101,
+	100,
+	102,
+	105,
+	110,
+	97,
+	108,
+	89,
+	111,
+	117,
+	114,
+	32,
+	115,
+	108,
+	105,
+	100,
+	101,
+	116,
+	111,
+	112,
+	105,
+	99,
+	98,
+	114,
+	111,
+	119,
+	110,
+	97,
+	108,
+	111,
+	110,
+	101,
+	100,
+	114,
+	97,
+	119,
+	110,
+	115,
+	112,
+	108,
+	105,
+	116,
+	114,
+	101,
+	97,
+	99,
+	104,
+	82,
+	105,
+	103,
+	104,
+	116,
+	100,
+	97,
+	116,
+	101,
+	115,
+	109,
+	97,
+	114,
+	99,
+	104,
+	113,
+	117,
+	111,
+	116,
+	101,
+	103,
+	111,
+	111,
+	100,
+	115,
+	76,
+	105,
+	110,
+	107,
+	115,
+	100,
+	111,
+	117,
+	98,
+	116,
+	97,
+	115,
+	121,
+	110,
+	99,
+	116,
+	104,
+	117,
+	109,
+	98,
+	97,
+	108,
+	108,
+	111,
+	119,
+	99,
+	104,
+	105,
+	101,
+	102,
+	121,
+	111,
+	117,
+	116,
+	104,
+	110,
+	111,
+	118,
+	101,
+	108,
+	49,
+	48,
+	112,
+	120,
+	59,
+	115,
+	101,
+	114,
+	118,
+	101,
+	117,
+	110,
+	116,
+	105,
+	108,
+	104,
+	97,
+	110,
+	100,
+	115,
+	67,
+	104,
+	101,
+	99,
+	107,
+	83,
+	112,
+	97,
+	99,
+	101,
+	113,
+	117,
+	101,
+	114,
+	121,
+	106,
+	97,
+	109,
+	101,
+	115,
+	101,
+	113,
+	117,
+	97,
+	108,
+	116,
+	119,
+	105,
+	99,
+	101,
+	48,
+	44,
+	48,
+	48,
+	48,
+	83,
+	116,
+	97,
+	114,
+	116,
+	112,
+	97,
+	110,
+	101,
+	108,
+	115,
+	111,
+	110,
+	103,
+	115,
+	114,
+	111,
+	117,
+	110,
+	100,
+	101,
+	105,
+	103,
+	104,
+	116,
+	115,
+	104,
+	105,
+	102,
+	116,
+	119,
+	111,
+	114,
+	116,
+	104,
+	112,
+	111,
+	115,
+	116,
+	115,
+	108,
+	101,
+	97,
+	100,
+	115,
+	119,
+	101,
+	101,
+	107,
+	115,
+	97,
+	118,
+	111,
+	105,
+	100,
+	116,
+	104,
+	101,
+	115,
+	101,
+	109,
+	105,
+	108,
+	101,
+	115,
+	112,
+	108,
+	97,
+	110,
+	101,
+	115,
+	109,
+	97,
+	114,
+	116,
+	97,
+	108,
+	112,
+	104,
+	97,
+	112,
+	108,
+	97,
+	110,
+	116,
+	109,
+	97,
+	114,
+	107,
+	115,
+	114,
+	97,
+	116,
+	101,
+	115,
+	112,
+	108,
+	97,
+	121,
+	115,
+	99,
+	108,
+	97,
+	105,
+	109,
+	115,
+	97,
+	108,
+	101,
+	115,
+	116,
+	101,
+	120,
+	116,
+	115,
+	115,
+	116,
+	97,
+	114,
+	115,
+	119,
+	114,
+	111,
+	110,
+	103,
+	60,
+	47,
+	104,
+	51,
+	62,
+	116,
+	104,
+	105,
+	110,
+	103,
+	46,
+	111,
+	114,
+	103,
+	47,
+	109,
+	117,
+	108,
+	116,
+	105,
+	104,
+	101,
+	97,
+	114,
+	100,
+	80,
+	111,
+	119,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	116,
+	111,
+	107,
+	101,
+	110,
+	115,
+	111,
+	108,
+	105,
+	100,
+	40,
+	116,
+	104,
+	105,
+	115,
+	98,
+	114,
+	105,
+	110,
+	103,
+	115,
+	104,
+	105,
+	112,
+	115,
+	115,
+	116,
+	97,
+	102,
+	102,
+	116,
+	114,
+	105,
+	101,
+	100,
+	99,
+	97,
+	108,
+	108,
+	115,
+	102,
+	117,
+	108,
+	108,
+	121,
+	102,
+	97,
+	99,
+	116,
+	115,
+	97,
+	103,
+	101,
+	110,
+	116,
+	84,
+	104,
+	105,
+	115,
+	32,
+	47,
+	47,
+	45,
+	45,
+	62,
+	97,
+	100,
+	109,
+	105,
+	110,
+	101,
+	103,
+	121,
+	112,
+	116,
+	69,
+	118,
+	101,
+	110,
+	116,
+	49,
+	53,
+	112,
+	120,
+	59,
+	69,
+	109,
+	97,
+	105,
+	108,
+	116,
+	114,
+	117,
+	101,
+	34,
+	99,
+	114,
+	111,
+	115,
+	115,
+	115,
+	112,
+	101,
+	110,
+	116,
+	98,
+	108,
+	111,
+	103,
+	115,
+	98,
+	111,
+	120,
+	34,
+	62,
+	110,
+	111,
+	116,
+	101,
+	100,
+	108,
+	101,
+	97,
+	118,
+	101,
+	99,
+	104,
+	105,
+	110,
+	97,
+	115,
+	105,
+	122,
+	101,
+	115,
+	103,
+	117,
+	101,
+	115,
+	116,
+	60,
+	47,
+	104,
+	52,
+	62,
+	114,
+	111,
+	98,
+	111,
+	116,
+	104,
+	101,
+	97,
+	118,
+	121,
+	116,
+	114,
+	117,
+	101,
+	44,
+	115,
+	101,
+	118,
+	101,
+	110,
+	103,
+	114,
+	97,
+	110,
+	100,
+	99,
+	114,
+	105,
+	109,
+	101,
+	115,
+	105,
+	103,
+	110,
+	115,
+	97,
+	119,
+	97,
+	114,
+	101,
+	100,
+	97,
+	110,
+	99,
+	101,
+	112,
+	104,
+	97,
+	115,
+	101,
+	62,
+	60,
+	33,
+	45,
+	45,
+	101,
+	110,
+	95,
+	85,
+	83,
+	38,
+	35,
+	51,
+	57,
+	59,
+	50,
+	48,
+	48,
+	112,
+	120,
+	95,
+	110,
+	97,
+	109,
+	101,
+	108,
+	97,
+	116,
+	105,
+	110,
+	101,
+	110,
+	106,
+	111,
+	121,
+	97,
+	106,
+	97,
+	120,
+	46,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	109,
+	105,
+	116,
+	104,
+	85,
+	46,
+	83,
+	46,
+	32,
+	104,
+	111,
+	108,
+	100,
+	115,
+	112,
+	101,
+	116,
+	101,
+	114,
+	105,
+	110,
+	100,
+	105,
+	97,
+	110,
+	97,
+	118,
+	34,
+	62,
+	99,
+	104,
+	97,
+	105,
+	110,
+	115,
+	99,
+	111,
+	114,
+	101,
+	99,
+	111,
+	109,
+	101,
+	115,
+	100,
+	111,
+	105,
+	110,
+	103,
+	112,
+	114,
+	105,
+	111,
+	114,
+	83,
+	104,
+	97,
+	114,
+	101,
+	49,
+	57,
+	57,
+	48,
+	115,
+	114,
+	111,
+	109,
+	97,
+	110,
+	108,
+	105,
+	115,
+	116,
+	115,
+	106,
+	97,
+	112,
+	97,
+	110,
+	102,
+	97,
+	108,
+	108,
+	115,
+	116,
+	114,
+	105,
+	97,
+	108,
+	111,
+	119,
+	110,
+	101,
+	114,
+	97,
+	103,
+	114,
+	101,
+	101,
+	60,
+	47,
+	104,
+	50,
+	62,
+	97,
+	98,
+	117,
+	115,
+	101,
+	97,
+	108,
+	101,
+	114,
+	116,
+	111,
+	112,
+	101,
+	114,
+	97,
+	34,
+	45,
+	47,
+	47,
+	87,
+	99,
+	97,
+	114,
+	100,
+	115,
+	104,
+	105,
+	108,
+	108,
+	115,
+	116,
+	101,
+	97,
+	109,
+	115,
+	80,
+	104,
+	111,
+	116,
+	111,
+	116,
+	114,
+	117,
+	116,
+	104,
+	99,
+	108,
+	101,
+	97,
+	110,
+	46,
+	112,
+	104,
+	112,
+	63,
+	115,
+	97,
+	105,
+	110,
+	116,
+	109,
+	101,
+	116,
+	97,
+	108,
+	108,
+	111,
+	117,
+	105,
+	115,
+	109,
+	101,
+	97,
+	110,
+	116,
+	112,
+	114,
+	111,
+	111,
+	102,
+	98,
+	114,
+	105,
+	101,
+	102,
+	114,
+	111,
+	119,
+	34,
+	62,
+	103,
+	101,
+	110,
+	114,
+	101,
+	116,
+	114,
+	117,
+	99,
+	107,
+	108,
+	111,
+	111,
+	107,
+	115,
+	86,
+	97,
+	108,
+	117,
+	101,
+	70,
+	114,
+	97,
+	109,
+	101,
+	46,
+	110,
+	101,
+	116,
+	47,
+	45,
+	45,
+	62,
+	10,
+	60,
+	116,
+	114,
+	121,
+	32,
+	123,
+	10,
+	118,
+	97,
+	114,
+	32,
+	109,
+	97,
+	107,
+	101,
+	115,
+	99,
+	111,
+	115,
+	116,
+	115,
+	112,
+	108,
+	97,
+	105,
+	110,
+	97,
+	100,
+	117,
+	108,
+	116,
+	113,
+	117,
+	101,
+	115,
+	116,
+	116,
+	114,
+	97,
+	105,
+	110,
+	108,
+	97,
+	98,
+	111,
+	114,
+	104,
+	101,
+	108,
+	112,
+	115,
+	99,
+	97,
+	117,
+	115,
+	101,
+	109,
+	97,
+	103,
+	105,
+	99,
+	109,
+	111,
+	116,
+	111,
+	114,
+	116,
+	104,
+	101,
+	105,
+	114,
+	50,
+	53,
+	48,
+	112,
+	120,
+	108,
+	101,
+	97,
+	115,
+	116,
+	115,
+	116,
+	101,
+	112,
+	115,
+	67,
+	111,
+	117,
+	110,
+	116,
+	99,
+	111,
+	117,
+	108,
+	100,
+	103,
+	108,
+	97,
+	115,
+	115,
+	115,
+	105,
+	100,
+	101,
+	115,
+	102,
+	117,
+	110,
+	100,
+	115,
+	104,
+	111,
+	116,
+	101,
+	108,
+	97,
+	119,
+	97,
+	114,
+	100,
+	109,
+	111,
+	117,
+	116,
+	104,
+	109,
+	111,
+	118,
+	101,
+	115,
+	112,
+	97,
+	114,
+	105,
+	115,
+	103,
+	105,
+	118,
+	101,
+	115,
+	100,
+	117,
+	116,
+	99,
+	104,
+	116,
+	101,
+	120,
+	97,
+	115,
+	102,
+	114,
+	117,
+	105,
+	116,
+	110,
+	117,
+	108,
+	108,
+	44,
+	124,
+	124,
+	91,
+	93,
+	59,
+	116,
+	111,
+	112,
+	34,
+	62,
+	10,
+	60,
+	33,
+	45,
+	45,
+	80,
+	79,
+	83,
+	84,
+	34,
+	111,
+	99,
+	101,
+	97,
+	110,
+	60,
+	98,
+	114,
+	47,
+	62,
+	102,
+	108,
+	111,
+	111,
+	114,
+	115,
+	112,
+	101,
+	97,
+	107,
+	100,
+	101,
+	112,
+	116,
+	104,
+	32,
+	115,
+	105,
+	122,
+	101,
+	98,
+	97,
+	110,
+	107,
+	115,
+	99,
+	97,
+	116,
+	99,
+	104,
+	99,
+	104,
+	97,
+	114,
+	116,
+	50,
+	48,
+	112,
+	120,
+	59,
+	97,
+	108,
+	105,
+	103,
+	110,
+	100,
+	101,
+	97,
+	108,
+	115,
+	119,
+	111,
+	117,
+	108,
+	100,
+	53,
+	48,
+	112,
+	120,
+	59,
+	117,
+	114,
+	108,
+	61,
+	34,
+	112,
+	97,
+	114,
+	107,
+	115,
+	109,
+	111,
+	117,
+	115,
+	101,
+	77,
+	111,
+	115,
+	116,
+	32,
+	46,
+	46,
+	46,
+	60,
+	47,
+	97,
+	109,
+	111,
+	110,
+	103,
+	98,
+	114,
+	97,
+	105,
+	110,
+	98,
+	111,
+	100,
+	121,
+	32,
+	110,
+	111,
+	110,
+	101,
+	59,
+	98,
+	97,
+	115,
+	101,
+	100,
+	99,
+	97,
+	114,
+	114,
+	121,
+	100,
+	114,
+	97,
+	102,
+	116,
+	114,
+	101,
+	102,
+	101,
+	114,
+	112,
+	97,
+	103,
+	101,
+	95,
+	104,
+	111,
+	109,
+	101,
+	46,
+	109,
+	101,
+	116,
+	101,
+	114,
+	100,
+	101,
+	108,
+	97,
+	121,
+	100,
+	114,
+	101,
+	97,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	106,
+	111,
+	105,
+	110,
+	116,
+	60,
+	47,
+	116,
+	114,
+	62,
+	100,
+	114,
+	117,
+	103,
+	115,
+	60,
+	33,
+	45,
+	45,
+	32,
+	97,
+	112,
+	114,
+	105,
+	108,
+	105,
+	100,
+	101,
+	97,
+	108,
+	97,
+	108,
+	108,
+	101,
+	110,
+	101,
+	120,
+	97,
+	99,
+	116,
+	102,
+	111,
+	114,
+	116,
+	104,
+	99,
+	111,
+	100,
+	101,
+	115,
+	108,
+	111,
+	103,
+	105,
+	99,
+	86,
+	105,
+	101,
+	119,
+	32,
+	115,
+	101,
+	101,
+	109,
+	115,
+	98,
+	108,
+	97,
+	110,
+	107,
+	112,
+	111,
+	114,
+	116,
+	115,
+	32,
+	40,
+	50,
+	48,
+	48,
+	115,
+	97,
+	118,
+	101,
+	100,
+	95,
+	108,
+	105,
+	110,
+	107,
+	103,
+	111,
+	97,
+	108,
+	115,
+	103,
+	114,
+	97,
+	110,
+	116,
+	103,
+	114,
+	101,
+	101,
+	107,
+	104,
+	111,
+	109,
+	101,
+	115,
+	114,
+	105,
+	110,
+	103,
+	115,
+	114,
+	97,
+	116,
+	101,
+	100,
+	51,
+	48,
+	112,
+	120,
+	59,
+	119,
+	104,
+	111,
+	115,
+	101,
+	112,
+	97,
+	114,
+	115,
+	101,
+	40,
+	41,
+	59,
+	34,
+	32,
+	66,
+	108,
+	111,
+	99,
+	107,
+	108,
+	105,
+	110,
+	117,
+	120,
+	106,
+	111,
+	110,
+	101,
+	115,
+	112,
+	105,
+	120,
+	101,
+	108,
+	39,
+	41,
+	59,
+	34,
+	62,
+	41,
+	59,
+	105,
+	102,
+	40,
+	45,
+	108,
+	101,
+	102,
+	116,
+	100,
+	97,
+	118,
+	105,
+	100,
+	104,
+	111,
+	114,
+	115,
+	101,
+	70,
+	111,
+	99,
+	117,
+	115,
+	114,
+	97,
+	105,
+	115,
+	101,
+	98,
+	111,
+	120,
+	101,
+	115,
+	84,
+	114,
+	97,
+	99,
+	107,
+	101,
+	109,
+	101,
+	110,
+	116,
+	60,
+	47,
+	101,
+	109,
+	62,
+	98,
+	97,
+	114,
+	34,
+	62,
+	46,
+	115,
+	114,
+	99,
+	61,
+	116,
+	111,
+	119,
+	101,
+	114,
+	97,
+	108,
+	116,
+	61,
+	34,
+	99,
+	97,
+	98,
+	108,
+	101,
+	104,
+	101,
+	110,
+	114,
+	121,
+	50,
+	52,
+	112,
+	120,
+	59,
+	115,
+	101,
+	116,
+	117,
+	112,
+	105,
+	116,
+	97,
+	108,
+	121,
+	115,
+	104,
+	97,
+	114,
+	112,
+	109,
+	105,
+	110,
+	111,
+	114,
+	116,
+	97,
+	115,
+	116,
+	101,
+	119,
+	97,
+	110,
+	116,
+	115,
+	116,
+	104,
+	105,
+	115,
+	46,
+	114,
+	101,
+	115,
+	101,
+	116,
+	119,
+	104,
+	101,
+	101,
+	108,
+	103,
+	105,
+	114,
+	108,
+	115,
+	47,
+	99,
+	115,
+	115,
+	47,
+	49,
+	48,
+	48,
+	37,
+	59,
+	99,
+	108,
+	117,
+	98,
+	115,
+	115,
+	116,
+	117,
+	102,
+	102,
+	98,
+	105,
+	98,
+	108,
+	101,
+	118,
+	111,
+	116,
+	101,
+	115,
+	32,
+	49,
+	48,
+	48,
+	48,
+	107,
+	111,
+	114,
+	101,
+	97,
+	125,
+	41,
+	59,
+	13,
+	10,
+	98,
+	97,
+	110,
+	100,
+	115,
+	113,
+	117,
+	101,
+	117,
+	101,
+	61,
+	32,
+	123,
+	125,
+	59,
+	56,
+	48,
+	112,
+	120,
+	59,
+	99,
+	107,
+	105,
+	110,
+	103,
+	123,
+	13,
+	10,
+	9,
+	9,
+	97,
+	104,
+	101,
+	97,
+	100,
+	99,
+	108,
+	111,
+	99,
+	107,
+	105,
+	114,
+	105,
+	115,
+	104,
+	108,
+	105,
+	107,
+	101,
+	32,
+	114,
+	97,
+	116,
+	105,
+	111,
+	115,
+	116,
+	97,
+	116,
+	115,
+	70,
+	111,
+	114,
+	109,
+	34,
+	121,
+	97,
+	104,
+	111,
+	111,
+	41,
+	91,
+	48,
+	93,
+	59,
+	65,
+	98,
+	111,
+	117,
+	116,
+	102,
+	105,
+	110,
+	100,
+	115,
+	60,
+	47,
+	104,
+	49,
+	62,
+	100,
+	101,
+	98,
+	117,
+	103,
+	116,
+	97,
+	115,
+	107,
+	115,
+	85,
+	82,
+	76,
+	32,
+	61,
+	99,
+	101,
+	108,
+	108,
+	115,
+	125,
+	41,
+	40,
+	41,
+	59,
+	49,
+	50,
+	112,
+	120,
+	59,
+	112,
+	114,
+	105,
+	109,
+	101,
+	116,
+	101,
+	108,
+	108,
+	115,
+	116,
+	117,
+	114,
+	110,
+	115,
+	48,
+	120,
+	54,
+	48,
+	48,
+	46,
+	106,
+	112,
+	103,
+	34,
+	115,
+	112,
+	97,
+	105,
+	110,
+	98,
+	101,
+	97,
+	99,
+	104,
+	116,
+	97,
+	120,
+	101,
+	115,
+	109,
+	105,
+	99,
+	114,
+	111,
+	97,
+	110,
+	103,
+	101,
+	108,
+	45,
+	45,
+	62,
+	60,
+	47,
+	103,
+	105,
+	102,
+	116,
+	115,
+	115,
+	116,
+	101,
+	118,
+	101,
+	45,
+	108,
+	105,
+	110,
+	107,
+	98,
+	111,
+	100,
+	121,
+	46,
+	125,
+	41,
+	59,
+	10,
+	9,
+	109,
+	111,
+	117,
+	110,
+	116,
+	32,
+	40,
+	49,
+	57,
+	57,
+	70,
+	65,
+	81,
+	60,
+	47,
+	114,
+	111,
+	103,
+	101,
+	114,
+	102,
+	114,
+	97,
+	110,
+	107,
+	67,
+	108,
+	97,
+	115,
+	115,
+	50,
+	56,
+	112,
+	120,
+	59,
+	102,
+	101,
+	101,
+	100,
+	115,
+	60,
+	104,
+	49,
+	62,
+	60,
+	115,
+	99,
+	111,
+	116,
+	116,
+	116,
+	101,
+	115,
+	116,
+	115,
+	50,
+	50,
+	112,
+	120,
+	59,
+	100,
+	114,
+	105,
+	110,
+	107,
+	41,
+	32,
+	124,
+	124,
+	32,
+	108,
+	101,
+	119,
+	105,
+	115,
+	115,
+	104,
+	97,
+	108,
+	108,
+	35,
+	48,
+	51,
+	57,
+	59,
+	32,
+	102,
+	111,
+	114,
+	32,
+	108,
+	111,
+	118,
+	101,
+	100,
+	119,
+	97,
+	115,
+	116,
+	101,
+	48,
+	48,
+	112,
+	120,
+	59,
+	106,
+	97,
+	58,
+	227,
+	130,
+	115,
+	105,
+	109,
+	111,
+	110,
+	60,
+	102,
+	111,
+	110,
+	116,
+	114,
+	101,
+	112,
+	108,
+	121,
+	109,
+	101,
+	101,
+	116,
+	115,
+	117,
+	110,
+	116,
+	101,
+	114,
+	99,
+	104,
+	101,
+	97,
+	112,
+	116,
+	105,
+	103,
+	104,
+	116,
+	66,
+	114,
+	97,
+	110,
+	100,
+	41,
+	32,
+	33,
+	61,
+	32,
+	100,
+	114,
+	101,
+	115,
+	115,
+	99,
+	108,
+	105,
+	112,
+	115,
+	114,
+	111,
+	111,
+	109,
+	115,
+	111,
+	110,
+	107,
+	101,
+	121,
+	109,
+	111,
+	98,
+	105,
+	108,
+	109,
+	97,
+	105,
+	110,
+	46,
+	78,
+	97,
+	109,
+	101,
+	32,
+	112,
+	108,
+	97,
+	116,
+	101,
+	102,
+	117,
+	110,
+	110,
+	121,
+	116,
+	114,
+	101,
+	101,
+	115,
+	99,
+	111,
+	109,
+	47,
+	34,
+	49,
+	46,
+	106,
+	112,
+	103,
+	119,
+	109,
+	111,
+	100,
+	101,
+	112,
+	97,
+	114,
+	97,
+	109,
+	83,
+	84,
+	65,
+	82,
+	84,
+	108,
+	101,
+	102,
+	116,
+	32,
+	105,
+	100,
+	100,
+	101,
+	110,
+	44,
+	32,
+	50,
+	48,
+	49,
+	41,
+	59,
+	10,
+	125,
+	10,
+	102,
+	111,
+	114,
+	109,
+	46,
+	118,
+	105,
+	114,
+	117,
+	115,
+	99,
+	104,
+	97,
+	105,
+	114,
+	116,
+	114,
+	97,
+	110,
+	115,
+	119,
+	111,
+	114,
+	115,
+	116,
+	80,
+	97,
+	103,
+	101,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	112,
+	97,
+	116,
+	99,
+	104,
+	60,
+	33,
+	45,
+	45,
+	10,
+	111,
+	45,
+	99,
+	97,
+	99,
+	102,
+	105,
+	114,
+	109,
+	115,
+	116,
+	111,
+	117,
+	114,
+	115,
+	44,
+	48,
+	48,
+	48,
+	32,
+	97,
+	115,
+	105,
+	97,
+	110,
+	105,
+	43,
+	43,
+	41,
+	123,
+	97,
+	100,
+	111,
+	98,
+	101,
+	39,
+	41,
+	91,
+	48,
+	93,
+	105,
+	100,
+	61,
+	49,
+	48,
+	98,
+	111,
+	116,
+	104,
+	59,
+	109,
+	101,
+	110,
+	117,
+	32,
+	46,
+	50,
+	46,
+	109,
+	105,
+	46,
+	112,
+	110,
+	103,
+	34,
+	107,
+	101,
+	118,
+	105,
+	110,
+	99,
+	111,
+	97,
+	99,
+	104,
+	67,
+	104,
+	105,
+	108,
+	100,
+	98,
+	114,
+	117,
+	99,
+	101,
+	50,
+	46,
+	106,
+	112,
+	103,
+	85,
+	82,
+	76,
+	41,
+	43,
+	46,
+	106,
+	112,
+	103,
+	124,
+	115,
+	117,
+	105,
+	116,
+	101,
+	115,
+	108,
+	105,
+	99,
+	101,
+	104,
+	97,
+	114,
+	114,
+	121,
+	49,
+	50,
+	48,
+	34,
+	32,
+	115,
+	119,
+	101,
+	101,
+	116,
+	116,
+	114,
+	62,
+	13,
+	10,
+	110,
+	97,
+	109,
+	101,
+	61,
+	100,
+	105,
+	101,
+	103,
+	111,
+	112,
+	97,
+	103,
+	101,
+	32,
+	115,
+	119,
+	105,
+	115,
+	115,
+	45,
+	45,
+	62,
+	10,
+	10,
+	35,
+	102,
+	102,
+	102,
+	59,
+	34,
+	62,
+	76,
+	111,
+	103,
+	46,
+	99,
+	111,
+	109,
+	34,
+	116,
+	114,
+	101,
+	97,
+	116,
+	115,
+	104,
+	101,
+	101,
+	116,
+	41,
+	32,
+	38,
+	38,
+	32,
+	49,
+	52,
+	112,
+	120,
+	59,
+	115,
+	108,
+	101,
+	101,
+	112,
+	110,
+	116,
+	101,
+	110,
+	116,
+	102,
+	105,
+	108,
+	101,
+	100,
+	106,
+	97,
+	58,
+	227,
+	131,
+	105,
+	100,
+	61,
+	34,
+	99,
+	78,
+	97,
+	109,
+	101,
+	34,
+	119,
+	111,
+	114,
+	115,
+	101,
+	115,
+	104,
+	111,
+	116,
+	115,
+	45,
+	98,
+	111,
+	120,
+	45,
+	100,
+	101,
+	108,
+	116,
+	97,
+	10,
+	38,
+	108,
+	116,
+	59,
+	98,
+	101,
+	97,
+	114,
+	115,
+	58,
+	52,
+	56,
+	90,
+	60,
+	100,
+	97,
+	116,
+	97,
+	45,
+	114,
+	117,
+	114,
+	97,
+	108,
+	60,
+	47,
+	97,
+	62,
+	32,
+	115,
+	112,
+	101,
+	110,
+	100,
+	98,
+	97,
+	107,
+	101,
+	114,
+	115,
+	104,
+	111,
+	112,
+	115,
+	61,
+	32,
+	34,
+	34,
+	59,
+	112,
+	104,
+	112,
+	34,
+	62,
+	99,
+	116,
+	105,
+	111,
+	110,
+	49,
+	51,
+	112,
+	120,
+	59,
+	98,
+	114,
+	105,
+	97,
+	110,
+	104,
+	101,
+	108,
+	108,
+	111,
+	115,
+	105,
+	122,
+	101,
+	61,
+	111,
+	61,
+	37,
+	50,
+	70,
+	32,
+	106,
+	111,
+	105,
+	110,
+	109,
+	97,
+	121,
+	98,
+	101,
+	60,
+	105,
+	109,
+	103,
+	32,
+	105,
+	109,
+	103,
+	34,
+	62,
+	44,
+	32,
+	102,
+	106,
+	115,
+	105,
+	109,
+	103,
+	34,
+	32,
+	34,
+	41,
+	91,
+	48,
+	93,
+	77,
+	84,
+	111,
+	112,
+	66,
+	84,
+	121,
+	112,
+	101,
+	34,
+	110,
+	101,
+	119,
+	108,
+	121,
+	68,
+	97,
+	110,
+	115,
+	107,
+	99,
+	122,
+	101,
+	99,
+	104,
+	116,
+	114,
+	97,
+	105,
+	108,
+	107,
+	110,
+	111,
+	119,
+	115,
+	60,
+	47,
+	104,
+	53,
+	62,
+	102,
+	97,
+	113,
+	34,
+	62,
+	122,
+	104,
+	45,
+	99,
+	110,
+	49,
+	48,
+	41,
+	59,
+	10,
+	45,
+	49,
+	34,
+	41,
+	59,
+	116,
+	121,
+	112,
+	101,
+	61,
+	98,
+	108,
+	117,
+	101,
+	115,
+	116,
+	114,
+	117,
+	108,
+	121,
+	100,
+	97,
+	118,
+	105,
+	115,
+	46,
+	106,
+	115,
+	39,
+	59,
+	62,
+	13,
+	10,
+	60,
+	33,
+	115,
+	116,
+	101,
+	101,
+	108,
+	32,
+	121,
+	111,
+	117,
+	32,
+	104,
+	50,
+	62,
+	13,
+	10,
+	102,
+	111,
+	114,
+	109,
+	32,
+	106,
+	101,
+	115,
+	117,
+	115,
+	49,
+	48,
+	48,
+	37,
+	32,
+	109,
+	101,
+	110,
+	117,
+	46,
+	13,
+	10,
+	9,
+	13,
+	10,
+	119,
+	97,
+	108,
+	101,
+	115,
+	114,
+	105,
+	115,
+	107,
+	115,
+	117,
+	109,
+	101,
+	110,
+	116,
+	100,
+	100,
+	105,
+	110,
+	103,
+	98,
+	45,
+	108,
+	105,
+	107,
+	116,
+	101,
+	97,
+	99,
+	104,
+	103,
+	105,
+	102,
+	34,
+	32,
+	118,
+	101,
+	103,
+	97,
+	115,
+	100,
+	97,
+	110,
+	115,
+	107,
+	101,
+	101,
+	115,
+	116,
+	105,
+	115,
+	104,
+	113,
+	105,
+	112,
+	115,
+	117,
+	111,
+	109,
+	105,
+	115,
+	111,
+	98,
+	114,
+	101,
+	100,
+	101,
+	115,
+	100,
+	101,
+	101,
+	110,
+	116,
+	114,
+	101,
+	116,
+	111,
+	100,
+	111,
+	115,
+	112,
+	117,
+	101,
+	100,
+	101,
+	97,
+	195,
+	177,
+	111,
+	115,
+	101,
+	115,
+	116,
+	195,
+	161,
+	116,
+	105,
+	101,
+	110,
+	101,
+	104,
+	97,
+	115,
+	116,
+	97,
+	111,
+	116,
+	114,
+	111,
+	115,
+	112,
+	97,
+	114,
+	116,
+	101,
+	100,
+	111,
+	110,
+	100,
+	101,
+	110,
+	117,
+	101,
+	118,
+	111,
+	104,
+	97,
+	99,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	97,
+	109,
+	105,
+	115,
+	109,
+	111,
+	109,
+	101,
+	106,
+	111,
+	114,
+	109,
+	117,
+	110,
+	100,
+	111,
+	97,
+	113,
+	117,
+	195,
+	173,
+	100,
+	195,
+	173,
+	97,
+	115,
+	115,
+	195,
+	179,
+	108,
+	111,
+	97,
+	121,
+	117,
+	100,
+	97,
+	102,
+	101,
+	99,
+	104,
+	97,
+	116,
+	111,
+	100,
+	97,
+	115,
+	116,
+	97,
+	110,
+	116,
+	111,
+	109,
+	101,
+	110,
+	111,
+	115,
+	100,
+	97,
+	116,
+	111,
+	115,
+	111,
+	116,
+	114,
+	97,
+	115,
+	115,
+	105,
+	116,
+	105,
+	111,
+	109,
+	117,
+	99,
+	104,
+	111,
+	97,
+	104,
+	111,
+	114,
+	97,
+	108,
+	117,
+	103,
+	97,
+	114,
+	109,
+	97,
+	121,
+	111,
+	114,
+	101,
+	115,
+	116,
+	111,
+	115,
+	104,
+	111,
+	114,
+	97,
+	115,
+	116,
+	101,
+	110,
+	101,
+	114,
+	97,
+	110,
+	116,
+	101,
+	115,
+	102,
+	111,
+	116,
+	111,
+	115,
+	101,
+	115,
+	116,
+	97,
+	115,
+	112,
+	97,
+	195,
+	173,
+	115,
+	110,
+	117,
+	101,
+	118,
+	97,
+	115,
+	97,
+	108,
+	117,
+	100,
+	102,
+	111,
+	114,
+	111,
+	115,
+	109,
+	101,
+	100,
+	105,
+	111,
+	113,
+	117,
+	105,
+	101,
+	110,
+	109,
+	101,
+	115,
+	101,
+	115,
+	112,
+	111,
+	100,
+	101,
+	114,
+	99,
+	104,
+	105,
+	108,
+	101,
+	115,
+	101,
+	114,
+	195,
+	161,
+	118,
+	101,
+	99,
+	101,
+	115,
+	100,
+	101,
+	99,
+	105,
+	114,
+	106,
+	111,
+	115,
+	195,
+	169,
+	101,
+	115,
+	116,
+	97,
+	114,
+	118,
+	101,
+	110,
+	116,
+	97,
+	103,
+	114,
+	117,
+	112,
+	111,
+	104,
+	101,
+	99,
+	104,
+	111,
+	101,
+	108,
+	108,
+	111,
+	115,
+	116,
+	101,
+	110,
+	103,
+	111,
+	97,
+	109,
+	105,
+	103,
+	111,
+	99,
+	111,
+	115,
+	97,
+	115,
+	110,
+	105,
+	118,
+	101,
+	108,
+	103,
+	101,
+	110,
+	116,
+	101,
+	109,
+	105,
+	115,
+	109,
+	97,
+	97,
+	105,
+	114,
+	101,
+	115,
+	106,
+	117,
+	108,
+	105,
+	111,
+	116,
+	101,
+	109,
+	97,
+	115,
+	104,
+	97,
+	99,
+	105,
+	97,
+	102,
+	97,
+	118,
+	111,
+	114,
+	106,
+	117,
+	110,
+	105,
+	111,
+	108,
+	105,
+	98,
+	114,
+	101,
+	112,
+	117,
+	110,
+	116,
+	111,
+	98,
+	117,
+	101,
+	110,
+	111,
+	97,
+	117,
+	116,
+	111,
+	114,
+	97,
+	98,
+	114,
+	105,
+	108,
+	98,
+	117,
+	101,
+	110,
+	97,
+	116,
+	101,
+	120,
+	116,
+	111,
+	109,
+	97,
+	114,
+	122,
+	111,
+	115,
+	97,
+	98,
+	101,
+	114,
+	108,
+	105,
+	115,
+	116,
+	97,
+	108,
+	117,
+	101,
+	103,
+	111,
+	99,
+	195,
+	179,
+	109,
+	111,
+	101,
+	110,
+	101,
+	114,
+	111,
+	106,
+	117,
+	101,
+	103,
+	111,
+	112,
+	101,
+	114,
+	195,
+	186,
+	104,
+	97,
+	98,
+	101,
+	114,
+	101,
+	115,
+	116,
+	111,
+	121,
+	110,
+	117,
+	110,
+	99,
+	97,
+	109,
+	117,
+	106,
+	101,
+	114,
+	118,
+	97,
+	108,
+	111,
+	114,
+	102,
+	117,
+	101,
+	114,
+	97,
+	108,
+	105,
+	98,
+	114,
+	111,
+	103,
+	117,
+	115,
+	116,
+	97,
+	105,
+	103,
+	117,
+	97,
+	108,
+	118,
+	111,
+	116,
+	111,
+	115,
+	99,
+	97,
+	115,
+	111,
+	115,
+	103,
+	117,
+	195,
+	173,
+	97,
+	112,
+	117,
+	101,
+	100,
+	111,
+	115,
+	111,
+	109,
+	111,
+	115,
+	97,
+	118,
+	105,
+	115,
+	111,
+	117,
+	115,
+	116,
+	101,
+	100,
+	100,
+	101,
+	98,
+	101,
+	110,
+	110,
+	111,
+	99,
+	104,
+	101,
+	98,
+	117,
+	115,
+	99,
+	97,
+	102,
+	97,
+	108,
+	116,
+	97,
+	101,
+	117,
+	114,
+	111,
+	115,
+	115,
+	101,
+	114,
+	105,
+	101,
+	100,
+	105,
+	99,
+	104,
+	111,
+	99,
+	117,
+	114,
+	115,
+	111,
+	99,
+	108,
+	97,
+	118,
+	101,
+	99,
+	97,
+	115,
+	97,
+	115,
+	108,
+	101,
+	195,
+	179,
+	110,
+	112,
+	108,
+	97,
+	122,
+	111,
+	108,
+	97,
+	114,
+	103,
+	111,
+	111,
+	98,
+	114,
+	97,
+	115,
+	118,
+	105,
+	115,
+	116,
+	97,
+	97,
+	112,
+	111,
+	121,
+	111,
+	106,
+	117,
+	110,
+	116,
+	111,
+	116,
+	114,
+	97,
+	116,
+	97,
+	118,
+	105,
+	115,
+	116,
+	111,
+	99,
+	114,
+	101,
+	97,
+	114,
+	99,
+	97,
+	109,
+	112,
+	111,
+	104,
+	101,
+	109,
+	111,
+	115,
+	99,
+	105,
+	110,
+	99,
+	111,
+	99,
+	97,
+	114,
+	103,
+	111,
+	112,
+	105,
+	115,
+	111,
+	115,
+	111,
+	114,
+	100,
+	101,
+	110,
+	104,
+	97,
+	99,
+	101,
+	110,
+	195,
+	161,
+	114,
+	101,
+	97,
+	100,
+	105,
+	115,
+	99,
+	111,
+	112,
+	101,
+	100,
+	114,
+	111,
+	99,
+	101,
+	114,
+	99,
+	97,
+	112,
+	117,
+	101,
+	100,
+	97,
+	112,
+	97,
+	112,
+	101,
+	108,
+	109,
+	101,
+	110,
+	111,
+	114,
+	195,
+	186,
+	116,
+	105,
+	108,
+	99,
+	108,
+	97,
+	114,
+	111,
+	106,
+	111,
+	114,
+	103,
+	101,
+	99,
+	97,
+	108,
+	108,
+	101,
+	112,
+	111,
+	110,
+	101,
+	114,
+	116,
+	97,
+	114,
+	100,
+	101,
+	110,
+	97,
+	100,
+	105,
+	101,
+	109,
+	97,
+	114,
+	99,
+	97,
+	115,
+	105,
+	103,
+	117,
+	101,
+	101,
+	108,
+	108,
+	97,
+	115,
+	115,
+	105,
+	103,
+	108,
+	111,
+	99,
+	111,
+	99,
+	104,
+	101,
+	109,
+	111,
+	116,
+	111,
+	115,
+	109,
+	97,
+	100,
+	114,
+	101,
+	99,
+	108,
+	97,
+	115,
+	101,
+	114,
+	101,
+	115,
+	116,
+	111,
+	110,
+	105,
+	195,
+	177,
+	111,
+	113,
+	117,
+	101,
+	100,
+	97,
+	112,
+	97,
+	115,
+	97,
+	114,
+	98,
+	97,
+	110,
+	99,
+	111,
+	104,
+	105,
+	106,
+	111,
+	115,
+	118,
+	105,
+	97,
+	106,
+	101,
+	112,
+	97,
+	98,
+	108,
+	111,
+	195,
+	169,
+	115,
+	116,
+	101,
+	118,
+	105,
+	101,
+	110,
+	101,
+	114,
+	101,
+	105,
+	110,
+	111,
+	100,
+	101,
+	106,
+	97,
+	114,
+	102,
+	111,
+	110,
+	100,
+	111,
+	99,
+	97,
+	110,
+	97,
+	108,
+	110,
+	111,
+	114,
+	116,
+	101,
+	108,
+	101,
+	116,
+	114,
+	97,
+	99,
+	97,
+	117,
+	115,
+	97,
+	116,
+	111,
+	109,
+	97,
+	114,
+	109,
+	97,
+	110,
+	111,
+	115,
+	108,
+	117,
+	110,
+	101,
+	115,
+	97,
+	117,
+	116,
+	111,
+	115,
+	118,
+	105,
+	108,
+	108,
+	97,
+	118,
+	101,
+	110,
+	100,
+	111,
+	112,
+	101,
+	115,
+	97,
+	114,
+	116,
+	105,
+	112,
+	111,
+	115,
+	116,
+	101,
+	110,
+	103,
+	97,
+	109,
+	97,
+	114,
+	99,
+	111,
+	108,
+	108,
+	101,
+	118,
+	97,
+	112,
+	97,
+	100,
+	114,
+	101,
+	117,
+	110,
+	105,
+	100,
+	111,
+	118,
+	97,
+	109,
+	111,
+	115,
+	122,
+	111,
+	110,
+	97,
+	115,
+	97,
+	109,
+	98,
+	111,
+	115,
+	98,
+	97,
+	110,
+	100,
+	97,
+	109,
+	97,
+	114,
+	105,
+	97,
+	97,
+	98,
+	117,
+	115,
+	111,
+	109,
+	117,
+	99,
+	104,
+	97,
+	115,
+	117,
+	98,
+	105,
+	114,
+	114,
+	105,
+	111,
+	106,
+	97,
+	118,
+	105,
+	118,
+	105,
+	114,
+	103,
+	114,
+	97,
+	100,
+	111,
+	99,
+	104,
+	105,
+	99,
+	97,
+	97,
+	108,
+	108,
+	195,
+	173,
+	106,
+	111,
+	118,
+	101,
+	110,
+	100,
+	105,
+	99,
+	104,
+	97,
+	101,
+	115,
+	116,
+	97,
+	110,
+	116,
+	97,
+	108,
+	101,
+	115,
+	115,
+	97,
+	108,
+	105,
+	114,
+	115,
+	117,
+	101,
+	108,
+	111,
+	112,
+	101,
+	115,
+	111,
+	115,
+	102,
+	105,
+	110,
+	101,
+	115,
+	108,
+	108,
+	97,
+	109,
+	97,
+	98,
+	117,
+	115,
+	99,
+	111,
+	195,
+	169,
+	115,
+	116,
+	97,
+	108,
+	108,
+	101,
+	103,
+	97,
+	110,
+	101,
+	103,
+	114,
+	111,
+	112,
+	108,
+	97,
+	122,
+	97,
+	104,
+	117,
+	109,
+	111,
+	114,
+	112,
+	97,
+	103,
+	97,
+	114,
+	106,
+	117,
+	110,
+	116,
+	97,
+	100,
+	111,
+	98,
+	108,
+	101,
+	105,
+	115,
+	108,
+	97,
+	115,
+	98,
+	111,
+	108,
+	115,
+	97,
+	98,
+	97,
+	195,
+	177,
+	111,
+	104,
+	97,
+	98,
+	108,
+	97,
+	108,
+	117,
+	99,
+	104,
+	97,
+	195,
+	129,
+	114,
+	101,
+	97,
+	100,
+	105,
+	99,
+	101,
+	110,
+	106,
+	117,
+	103,
+	97,
+	114,
+	110,
+	111,
+	116,
+	97,
+	115,
+	118,
+	97,
+	108,
+	108,
+	101,
+	97,
+	108,
+	108,
+	195,
+	161,
+	99,
+	97,
+	114,
+	103,
+	97,
+	100,
+	111,
+	108,
+	111,
+	114,
+	97,
+	98,
+	97,
+	106,
+	111,
+	101,
+	115,
+	116,
+	195,
+	169,
+	103,
+	117,
+	115,
+	116,
+	111,
+	109,
+	101,
+	110,
+	116,
+	101,
+	109,
+	97,
+	114,
+	105,
+	111,
+	102,
+	105,
+	114,
+	109,
+	97,
+	99,
+	111,
+	115,
+	116,
+	111,
+	102,
+	105,
+	99,
+	104,
+	97,
+	112,
+	108,
+	97,
+	116,
+	97,
+	104,
+	111,
+	103,
+	97,
+	114,
+	97,
+	114,
+	116,
+	101,
+	115,
+	108,
+	101,
+	121,
+	101,
+	115,
+	97,
+	113,
+	117,
+	101,
+	108,
+	109,
+	117,
+	115,
+	101,
+	111,
+	98,
+	97,
+	115,
+	101,
+	115,
+	112,
+	111,
+	99,
+	111,
+	115,
+	109,
+	105,
+	116,
+	97,
+	100,
+	99,
+	105,
+	101,
+	108,
+	111,
+	99,
+	104,
+	105,
+	99,
+	111,
+	109,
+	105,
+	101,
+	100,
+	111,
+	103,
+	97,
+	110,
+	97,
+	114,
+	115,
+	97,
+	110,
+	116,
+	111,
+	101,
+	116,
+	97,
+	112,
+	97,
+	100,
+	101,
+	98,
+	101,
+	115,
+	112,
+	108,
+	97,
+	121,
+	97,
+	114,
+	101,
+	100,
+	101,
+	115,
+	115,
+	105,
+	101,
+	116,
+	101,
+	99,
+	111,
+	114,
+	116,
+	101,
+	99,
+	111,
+	114,
+	101,
+	97,
+	100,
+	117,
+	100,
+	97,
+	115,
+	100,
+	101,
+	115,
+	101,
+	111,
+	118,
+	105,
+	101,
+	106,
+	111,
+	100,
+	101,
+	115,
+	101,
+	97,
+	97,
+	103,
+	117,
+	97,
+	115,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	100,
+	111,
+	109,
+	97,
+	105,
+	110,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	115,
+	116,
+	97,
+	116,
+	117,
+	115,
+	101,
+	118,
+	101,
+	110,
+	116,
+	115,
+	109,
+	97,
+	115,
+	116,
+	101,
+	114,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	98,
+	97,
+	110,
+	110,
+	101,
+	114,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	115,
+	99,
+	114,
+	111,
+	108,
+	108,
+	117,
+	112,
+	100,
+	97,
+	116,
+	101,
+	103,
+	108,
+	111,
+	98,
+	97,
+	108,
+	109,
+	101,
+	100,
+	105,
+	117,
+	109,
+	102,
+	105,
+	108,
+	116,
+	101,
+	114,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	115,
+	99,
+	114,
+	101,
+	101,
+	110,
+	99,
+	104,
+	111,
+	111,
+	115,
+	101,
+	110,
+	111,
+	114,
+	109,
+	97,
+	108,
+	116,
+	114,
+	97,
+	118,
+	101,
+	108,
+	105,
+	115,
+	115,
+	117,
+	101,
+	115,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	115,
+	112,
+	114,
+	105,
+	110,
+	103,
+	109,
+	111,
+	100,
+	117,
+	108,
+	101,
+	109,
+	111,
+	98,
+	105,
+	108,
+	101,
+	115,
+	119,
+	105,
+	116,
+	99,
+	104,
+	112,
+	104,
+	111,
+	116,
+	111,
+	115,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	105,
+	116,
+	115,
+	101,
+	108,
+	102,
+	115,
+	111,
+	99,
+	105,
+	97,
+	108,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	99,
+	111,
+	108,
+	117,
+	109,
+	110,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	101,
+	105,
+	116,
+	104,
+	101,
+	114,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	102,
+	97,
+	109,
+	105,
+	108,
+	121,
+	102,
+	114,
+	105,
+	101,
+	110,
+	100,
+	108,
+	97,
+	121,
+	111,
+	117,
+	116,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	114,
+	101,
+	118,
+	105,
+	101,
+	119,
+	115,
+	117,
+	109,
+	109,
+	101,
+	114,
+	115,
+	101,
+	114,
+	118,
+	101,
+	114,
+	112,
+	108,
+	97,
+	121,
+	101,
+	100,
+	112,
+	108,
+	97,
+	121,
+	101,
+	114,
+	101,
+	120,
+	112,
+	97,
+	110,
+	100,
+	112,
+	111,
+	108,
+	105,
+	99,
+	121,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	100,
+	111,
+	117,
+	98,
+	108,
+	101,
+	112,
+	111,
+	105,
+	110,
+	116,
+	115,
+	115,
+	101,
+	114,
+	105,
+	101,
+	115,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	108,
+	105,
+	118,
+	105,
+	110,
+	103,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	109,
+	111,
+	110,
+	116,
+	104,
+	115,
+	102,
+	111,
+	114,
+	99,
+	101,
+	115,
+	117,
+	110,
+	105,
+	113,
+	117,
+	101,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	101,
+	110,
+	101,
+	114,
+	103,
+	121,
+	110,
+	97,
+	116,
+	117,
+	114,
+	101,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	102,
+	105,
+	103,
+	117,
+	114,
+	101,
+	104,
+	97,
+	118,
+	105,
+	110,
+	103,
+	99,
+	117,
+	115,
+	116,
+	111,
+	109,
+	111,
+	102,
+	102,
+	115,
+	101,
+	116,
+	108,
+	101,
+	116,
+	116,
+	101,
+	114,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	114,
+	101,
+	110,
+	100,
+	101,
+	114,
+	103,
+	114,
+	111,
+	117,
+	112,
+	115,
+	117,
+	112,
+	108,
+	111,
+	97,
+	100,
+	104,
+	101,
+	97,
+	108,
+	116,
+	104,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	118,
+	105,
+	100,
+	101,
+	111,
+	115,
+	115,
+	99,
+	104,
+	111,
+	111,
+	108,
+	102,
+	117,
+	116,
+	117,
+	114,
+	101,
+	115,
+	104,
+	97,
+	100,
+	111,
+	119,
+	100,
+	101,
+	98,
+	97,
+	116,
+	101,
+	118,
+	97,
+	108,
+	117,
+	101,
+	115,
+	79,
+	98,
+	106,
+	101,
+	99,
+	116,
+	111,
+	116,
+	104,
+	101,
+	114,
+	115,
+	114,
+	105,
+	103,
+	104,
+	116,
+	115,
+	108,
+	101,
+	97,
+	103,
+	117,
+	101,
+	99,
+	104,
+	114,
+	111,
+	109,
+	101,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	110,
+	111,
+	116,
+	105,
+	99,
+	101,
+	115,
+	104,
+	97,
+	114,
+	101,
+	100,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	115,
+	101,
+	97,
+	115,
+	111,
+	110,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	111,
+	110,
+	108,
+	105,
+	110,
+	101,
+	115,
+	113,
+	117,
+	97,
+	114,
+	101,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	105,
+	109,
+	97,
+	103,
+	101,
+	115,
+	101,
+	110,
+	97,
+	98,
+	108,
+	101,
+	109,
+	111,
+	118,
+	105,
+	110,
+	103,
+	108,
+	97,
+	116,
+	101,
+	115,
+	116,
+	119,
+	105,
+	110,
+	116,
+	101,
+	114,
+	70,
+	114,
+	97,
+	110,
+	99,
+	101,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	114,
+	101,
+	112,
+	101,
+	97,
+	116,
+	76,
+	111,
+	110,
+	100,
+	111,
+	110,
+	100,
+	101,
+	116,
+	97,
+	105,
+	108,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	100,
+	101,
+	109,
+	97,
+	110,
+	100,
+	115,
+	101,
+	99,
+	117,
+	114,
+	101,
+	112,
+	97,
+	115,
+	115,
+	101,
+	100,
+	116,
+	111,
+	103,
+	103,
+	108,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	115,
+	100,
+	101,
+	118,
+	105,
+	99,
+	101,
+	115,
+	116,
+	97,
+	116,
+	105,
+	99,
+	99,
+	105,
+	116,
+	105,
+	101,
+	115,
+	115,
+	116,
+	114,
+	101,
+	97,
+	109,
+	121,
+	101,
+	108,
+	108,
+	111,
+	119,
+	97,
+	116,
+	116,
+	97,
+	99,
+	107,
+	115,
+	116,
+	114,
+	101,
+	101,
+	116,
+	102,
+	108,
+	105,
+	103,
+	104,
+	116,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	105,
+	110,
+	102,
+	111,
+	34,
+	62,
+	111,
+	112,
+	101,
+	110,
+	101,
+	100,
+	117,
+	115,
+	101,
+	102,
+	117,
+	108,
+	118,
+	97,
+	108,
+	108,
+	101,
+	121,
+	99,
+	97,
+	117,
+	115,
+	101,
+	115,
+	108,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	101,
+	99,
+	114,
+	101,
+	116,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	100,
+	97,
+	109,
+	97,
+	103,
+	101,
+	115,
+	112,
+	111,
+	114,
+	116,
+	115,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	114,
+	97,
+	116,
+	105,
+	110,
+	103,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	116,
+	104,
+	105,
+	110,
+	103,
+	115,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	102,
+	105,
+	101,
+	108,
+	100,
+	115,
+	115,
+	116,
+	97,
+	116,
+	101,
+	115,
+	111,
+	102,
+	102,
+	105,
+	99,
+	101,
+	118,
+	105,
+	115,
+	117,
+	97,
+	108,
+	101,
+	100,
+	105,
+	116,
+	111,
+	114,
+	118,
+	111,
+	108,
+	117,
+	109,
+	101,
+	82,
+	101,
+	112,
+	111,
+	114,
+	116,
+	109,
+	117,
+	115,
+	101,
+	117,
+	109,
+	109,
+	111,
+	118,
+	105,
+	101,
+	115,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	109,
+	111,
+	115,
+	116,
+	108,
+	121,
+	109,
+	111,
+	116,
+	104,
+	101,
+	114,
+	34,
+	32,
+	105,
+	100,
+	61,
+	34,
+	109,
+	97,
+	114,
+	107,
+	101,
+	116,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	99,
+	104,
+	97,
+	110,
+	99,
+	101,
+	115,
+	117,
+	114,
+	118,
+	101,
+	121,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	115,
+	121,
+	109,
+	98,
+	111,
+	108,
+	109,
+	111,
+	109,
+	101,
+	110,
+	116,
+	115,
+	112,
+	101,
+	101,
+	99,
+	104,
+	109,
+	111,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	115,
+	105,
+	100,
+	101,
+	109,
+	97,
+	116,
+	116,
+	101,
+	114,
+	67,
+	101,
+	110,
+	116,
+	101,
+	114,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	101,
+	120,
+	105,
+	115,
+	116,
+	115,
+	109,
+	105,
+	100,
+	100,
+	108,
+	101,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	103,
+	114,
+	111,
+	119,
+	116,
+	104,
+	108,
+	101,
+	103,
+	97,
+	99,
+	121,
+	109,
+	97,
+	110,
+	110,
+	101,
+	114,
+	101,
+	110,
+	111,
+	117,
+	103,
+	104,
+	99,
+	97,
+	114,
+	101,
+	101,
+	114,
+	97,
+	110,
+	115,
+	119,
+	101,
+	114,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	112,
+	111,
+	114,
+	116,
+	97,
+	108,
+	99,
+	108,
+	105,
+	101,
+	110,
+	116,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	97,
+	110,
+	100,
+	111,
+	109,
+	99,
+	108,
+	111,
+	115,
+	101,
+	100,
+	116,
+	111,
+	112,
+	105,
+	99,
+	115,
+	99,
+	111,
+	109,
+	105,
+	110,
+	103,
+	102,
+	97,
+	116,
+	104,
+	101,
+	114,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	109,
+	112,
+	108,
+	121,
+	114,
+	97,
+	105,
+	115,
+	101,
+	100,
+	101,
+	115,
+	99,
+	97,
+	112,
+	101,
+	99,
+	104,
+	111,
+	115,
+	101,
+	110,
+	99,
+	104,
+	117,
+	114,
+	99,
+	104,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	99,
+	111,
+	114,
+	110,
+	101,
+	114,
+	111,
+	117,
+	116,
+	112,
+	117,
+	116,
+	109,
+	101,
+	109,
+	111,
+	114,
+	121,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	112,
+	111,
+	108,
+	105,
+	99,
+	101,
+	109,
+	111,
+	100,
+	101,
+	108,
+	115,
+	78,
+	117,
+	109,
+	98,
+	101,
+	114,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	111,
+	102,
+	102,
+	101,
+	114,
+	115,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	107,
+	105,
+	108,
+	108,
+	101,
+	100,
+	108,
+	105,
+	115,
+	116,
+	101,
+	100,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	115,
+	105,
+	108,
+	118,
+	101,
+	114,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	100,
+	101,
+	108,
+	101,
+	116,
+	101,
+	98,
+	101,
+	116,
+	116,
+	101,
+	114,
+	98,
+	114,
+	111,
+	119,
+	115,
+	101,
+	108,
+	105,
+	109,
+	105,
+	116,
+	115,
+	71,
+	108,
+	111,
+	98,
+	97,
+	108,
+	115,
+	105,
+	110,
+	103,
+	108,
+	101,
+	119,
+	105,
+	100,
+	103,
+	101,
+	116,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	98,
+	117,
+	100,
+	103,
+	101,
+	116,
+	110,
+	111,
+	119,
+	114,
+	97,
+	112,
+	99,
+	114,
+	101,
+	100,
+	105,
+	116,
+	99,
+	108,
+	97,
+	105,
+	109,
+	115,
+	101,
+	110,
+	103,
+	105,
+	110,
+	101,
+	115,
+	97,
+	102,
+	101,
+	116,
+	121,
+	99,
+	104,
+	111,
+	105,
+	99,
+	101,
+	115,
+	112,
+	105,
+	114,
+	105,
+	116,
+	45,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	112,
+	114,
+	101,
+	97,
+	100,
+	109,
+	97,
+	107,
+	105,
+	110,
+	103,
+	110,
+	101,
+	101,
+	100,
+	101,
+	100,
+	114,
+	117,
+	115,
+	115,
+	105,
+	97,
+	112,
+	108,
+	101,
+	97,
+	115,
+	101,
+	101,
+	120,
+	116,
+	101,
+	110,
+	116,
+	83,
+	99,
+	114,
+	105,
+	112,
+	116,
+	98,
+	114,
+	111,
+	107,
+	101,
+	110,
+	97,
+	108,
+	108,
+	111,
+	119,
+	115,
+	99,
+	104,
+	97,
+	114,
+	103,
+	101,
+	100,
+	105,
+	118,
+	105,
+	100,
+	101,
+	102,
+	97,
+	99,
+	116,
+	111,
+	114,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	45,
+	98,
+	97,
+	115,
+	101,
+	100,
+	116,
+	104,
+	101,
+	111,
+	114,
+	121,
+	99,
+	111,
+	110,
+	102,
+	105,
+	103,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	119,
+	111,
+	114,
+	107,
+	101,
+	100,
+	104,
+	101,
+	108,
+	112,
+	101,
+	100,
+	67,
+	104,
+	117,
+	114,
+	99,
+	104,
+	105,
+	109,
+	112,
+	97,
+	99,
+	116,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	97,
+	108,
+	119,
+	97,
+	121,
+	115,
+	108,
+	111,
+	103,
+	111,
+	34,
+	32,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	108,
+	105,
+	115,
+	116,
+	34,
+	62,
+	41,
+	123,
+	118,
+	97,
+	114,
+	32,
+	112,
+	114,
+	101,
+	102,
+	105,
+	120,
+	111,
+	114,
+	97,
+	110,
+	103,
+	101,
+	72,
+	101,
+	97,
+	100,
+	101,
+	114,
+	46,
+	112,
+	117,
+	115,
+	104,
+	40,
+	99,
+	111,
+	117,
+	112,
+	108,
+	101,
+	103,
+	97,
+	114,
+	100,
+	101,
+	110,
+	98,
+	114,
+	105,
+	100,
+	103,
+	101,
+	108,
+	97,
+	117,
+	110,
+	99,
+	104,
+	82,
+	101,
+	118,
+	105,
+	101,
+	119,
+	116,
+	97,
+	107,
+	105,
+	110,
+	103,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	108,
+	105,
+	116,
+	116,
+	108,
+	101,
+	100,
+	97,
+	116,
+	105,
+	110,
+	103,
+	66,
+	117,
+	116,
+	116,
+	111,
+	110,
+	98,
+	101,
+	97,
+	117,
+	116,
+	121,
+	116,
+	104,
+	101,
+	109,
+	101,
+	115,
+	102,
+	111,
+	114,
+	103,
+	111,
+	116,
+	83,
+	101,
+	97,
+	114,
+	99,
+	104,
+	97,
+	110,
+	99,
+	104,
+	111,
+	114,
+	97,
+	108,
+	109,
+	111,
+	115,
+	116,
+	108,
+	111,
+	97,
+	100,
+	101,
+	100,
+	67,
+	104,
+	97,
+	110,
+	103,
+	101,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	115,
+	116,
+	114,
+	105,
+	110,
+	103,
+	114,
+	101,
+	108,
+	111,
+	97,
+	100,
+	77,
+	111,
+	98,
+	105,
+	108,
+	101,
+	105,
+	110,
+	99,
+	111,
+	109,
+	101,
+	115,
+	117,
+	112,
+	112,
+	108,
+	121,
+	83,
+	111,
+	117,
+	114,
+	99,
+	101,
+	111,
+	114,
+	100,
+	101,
+	114,
+	115,
+	118,
+	105,
+	101,
+	119,
+	101,
+	100,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	99,
+	111,
+	117,
+	114,
+	115,
+	101,
+	65,
+	98,
+	111,
+	117,
+	116,
+	32,
+	105,
+	115,
+	108,
+	97,
+	110,
+	100,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	97,
+	109,
+	97,
+	122,
+	111,
+	110,
+	109,
+	111,
+	100,
+	101,
+	114,
+	110,
+	97,
+	100,
+	118,
+	105,
+	99,
+	101,
+	105,
+	110,
+	60,
+	47,
+	97,
+	62,
+	58,
+	32,
+	84,
+	104,
+	101,
+	32,
+	100,
+	105,
+	97,
+	108,
+	111,
+	103,
+	104,
+	111,
+	117,
+	115,
+	101,
+	115,
+	66,
+	69,
+	71,
+	73,
+	78,
+	32,
+	77,
+	101,
+	120,
+	105,
+	99,
+	111,
+	115,
+	116,
+	97,
+	114,
+	116,
+	115,
+	99,
+	101,
+	110,
+	116,
+	114,
+	101,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	73,
+	115,
+	108,
+	97,
+	110,
+	100,
+	97,
+	115,
+	115,
+	101,
+	116,
+	115,
+	69,
+	109,
+	112,
+	105,
+	114,
+	101,
+	83,
+	99,
+	104,
+	111,
+	111,
+	108,
+	101,
+	102,
+	102,
+	111,
+	114,
+	116,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	110,
+	101,
+	97,
+	114,
+	108,
+	121,
+	109,
+	97,
+	110,
+	117,
+	97,
+	108,
+	83,
+	101,
+	108,
+	101,
+	99,
+	116,
+	46,
+	10,
+	10,
+	79,
+	110,
+	101,
+	106,
+	111,
+	105,
+	110,
+	101,
+	100,
+	109,
+	101,
+	110,
+	117,
+	34,
+	62,
+	80,
+	104,
+	105,
+	108,
+	105,
+	112,
+	97,
+	119,
+	97,
+	114,
+	100,
+	115,
+	104,
+	97,
+	110,
+	100,
+	108,
+	101,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	79,
+	102,
+	102,
+	105,
+	99,
+	101,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	115,
+	107,
+	105,
+	108,
+	108,
+	115,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	83,
+	112,
+	111,
+	114,
+	116,
+	115,
+	100,
+	101,
+	103,
+	114,
+	101,
+	101,
+	119,
+	101,
+	101,
+	107,
+	108,
+	121,
+	32,
+	40,
+	101,
+	46,
+	103,
+	46,
+	98,
+	101,
+	104,
+	105,
+	110,
+	100,
+	100,
+	111,
+	99,
+	116,
+	111,
+	114,
+	108,
+	111,
+	103,
+	103,
+	101,
+	100,
+	117,
+	110,
+	105,
+	116,
+	101,
+	100,
+	60,
+	47,
+	98,
+	62,
+	60,
+	47,
+	98,
+	101,
+	103,
+	105,
+	110,
+	115,
+	112,
+	108,
+	97,
+	110,
+	116,
+	115,
+	97,
+	115,
+	115,
+	105,
+	115,
+	116,
+	97,
+	114,
+	116,
+	105,
+	115,
+	116,
+	105,
+	115,
+	115,
+	117,
+	101,
+	100,
+	51,
+	48,
+	48,
+	112,
+	120,
+	124,
+	99,
+	97,
+	110,
+	97,
+	100,
+	97,
+	97,
+	103,
+	101,
+	110,
+	99,
+	121,
+	115,
+	99,
+	104,
+	101,
+	109,
+	101,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	66,
+	114,
+	97,
+	122,
+	105,
+	108,
+	115,
+	97,
+	109,
+	112,
+	108,
+	101,
+	108,
+	111,
+	103,
+	111,
+	34,
+	62,
+	98,
+	101,
+	121,
+	111,
+	110,
+	100,
+	45,
+	115,
+	99,
+	97,
+	108,
+	101,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	115,
+	101,
+	114,
+	118,
+	101,
+	100,
+	109,
+	97,
+	114,
+	105,
+	110,
+	101,
+	70,
+	111,
+	111,
+	116,
+	101,
+	114,
+	99,
+	97,
+	109,
+	101,
+	114,
+	97,
+	60,
+	47,
+	104,
+	49,
+	62,
+	10,
+	95,
+	102,
+	111,
+	114,
+	109,
+	34,
+	108,
+	101,
+	97,
+	118,
+	101,
+	115,
+	115,
+	116,
+	114,
+	101,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	13,
+	10,
+	46,
+	103,
+	105,
+	102,
+	34,
+	32,
+	111,
+	110,
+	108,
+	111,
+	97,
+	100,
+	108,
+	111,
+	97,
+	100,
+	101,
+	114,
+	79,
+	120,
+	102,
+	111,
+	114,
+	100,
+	115,
+	105,
+	115,
+	116,
+	101,
+	114,
+	115,
+	117,
+	114,
+	118,
+	105,
+	118,
+	108,
+	105,
+	115,
+	116,
+	101,
+	110,
+	102,
+	101,
+	109,
+	97,
+	108,
+	101,
+	68,
+	101,
+	115,
+	105,
+	103,
+	110,
+	115,
+	105,
+	122,
+	101,
+	61,
+	34,
+	97,
+	112,
+	112,
+	101,
+	97,
+	108,
+	116,
+	101,
+	120,
+	116,
+	34,
+	62,
+	108,
+	101,
+	118,
+	101,
+	108,
+	115,
+	116,
+	104,
+	97,
+	110,
+	107,
+	115,
+	104,
+	105,
+	103,
+	104,
+	101,
+	114,
+	102,
+	111,
+	114,
+	99,
+	101,
+	100,
+	97,
+	110,
+	105,
+	109,
+	97,
+	108,
+	97,
+	110,
+	121,
+	111,
+	110,
+	101,
+	65,
+	102,
+	114,
+	105,
+	99,
+	97,
+	97,
+	103,
+	114,
+	101,
+	101,
+	100,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	80,
+	101,
+	111,
+	112,
+	108,
+	101,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	119,
+	111,
+	110,
+	100,
+	101,
+	114,
+	112,
+	114,
+	105,
+	99,
+	101,
+	115,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	124,
+	124,
+	32,
+	123,
+	125,
+	59,
+	109,
+	97,
+	105,
+	110,
+	34,
+	62,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	115,
+	117,
+	110,
+	100,
+	97,
+	121,
+	119,
+	114,
+	97,
+	112,
+	34,
+	62,
+	102,
+	97,
+	105,
+	108,
+	101,
+	100,
+	99,
+	101,
+	110,
+	115,
+	117,
+	115,
+	109,
+	105,
+	110,
+	117,
+	116,
+	101,
+	98,
+	101,
+	97,
+	99,
+	111,
+	110,
+	113,
+	117,
+	111,
+	116,
+	101,
+	115,
+	49,
+	53,
+	48,
+	112,
+	120,
+	124,
+	101,
+	115,
+	116,
+	97,
+	116,
+	101,
+	114,
+	101,
+	109,
+	111,
+	116,
+	101,
+	101,
+	109,
+	97,
+	105,
+	108,
+	34,
+	108,
+	105,
+	110,
+	107,
+	101,
+	100,
+	114,
+	105,
+	103,
+	104,
+	116,
+	59,
+	115,
+	105,
+	103,
+	110,
+	97,
+	108,
+	102,
+	111,
+	114,
+	109,
+	97,
+	108,
+	49,
+	46,
+	104,
+	116,
+	109,
+	108,
+	115,
+	105,
+	103,
+	110,
+	117,
+	112,
+	112,
+	114,
+	105,
+	110,
+	99,
+	101,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	46,
+	112,
+	110,
+	103,
+	34,
+	32,
+	102,
+	111,
+	114,
+	117,
+	109,
+	46,
+	65,
+	99,
+	99,
+	101,
+	115,
+	115,
+	112,
+	97,
+	112,
+	101,
+	114,
+	115,
+	115,
+	111,
+	117,
+	110,
+	100,
+	115,
+	101,
+	120,
+	116,
+	101,
+	110,
+	100,
+	72,
+	101,
+	105,
+	103,
+	104,
+	116,
+	115,
+	108,
+	105,
+	100,
+	101,
+	114,
+	85,
+	84,
+	70,
+	45,
+	56,
+	34,
+	38,
+	97,
+	109,
+	112,
+	59,
+	32,
+	66,
+	101,
+	102,
+	111,
+	114,
+	101,
+	46,
+	32,
+	87,
+	105,
+	116,
+	104,
+	115,
+	116,
+	117,
+	100,
+	105,
+	111,
+	111,
+	119,
+	110,
+	101,
+	114,
+	115,
+	109,
+	97,
+	110,
+	97,
+	103,
+	101,
+	112,
+	114,
+	111,
+	102,
+	105,
+	116,
+	106,
+	81,
+	117,
+	101,
+	114,
+	121,
+	97,
+	110,
+	110,
+	117,
+	97,
+	108,
+	112,
+	97,
+	114,
+	97,
+	109,
+	115,
+	98,
+	111,
+	117,
+	103,
+	104,
+	116,
+	102,
+	97,
+	109,
+	111,
+	117,
+	115,
+	103,
+	111,
+	111,
+	103,
+	108,
+	101,
+	108,
+	111,
+	110,
+	103,
+	101,
+	114,
+	105,
+	43,
+	43,
+	41,
+	32,
+	123,
+	105,
+	115,
+	114,
+	97,
+	101,
+	108,
+	115,
+	97,
+	121,
+	105,
+	110,
+	103,
+	100,
+	101,
+	99,
+	105,
+	100,
+	101,
+	104,
+	111,
+	109,
+	101,
+	34,
+	62,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	101,
+	110,
+	115,
+	117,
+	114,
+	101,
+	98,
+	114,
+	97,
+	110,
+	99,
+	104,
+	112,
+	105,
+	101,
+	99,
+	101,
+	115,
+	98,
+	108,
+	111,
+	99,
+	107,
+	59,
+	115,
+	116,
+	97,
+	116,
+	101,
+	100,
+	116,
+	111,
+	112,
+	34,
+	62,
+	60,
+	114,
+	97,
+	99,
+	105,
+	110,
+	103,
+	114,
+	101,
+	115,
+	105,
+	122,
+	101,
+	45,
+	45,
+	38,
+	103,
+	116,
+	59,
+	112,
+	97,
+	99,
+	105,
+	116,
+	121,
+	115,
+	101,
+	120,
+	117,
+	97,
+	108,
+	98,
+	117,
+	114,
+	101,
+	97,
+	117,
+	46,
+	106,
+	112,
+	103,
+	34,
+	32,
+	49,
+	48,
+	44,
+	48,
+	48,
+	48,
+	111,
+	98,
+	116,
+	97,
+	105,
+	110,
+	116,
+	105,
+	116,
+	108,
+	101,
+	115,
+	97,
+	109,
+	111,
+	117,
+	110,
+	116,
+	44,
+	32,
+	73,
+	110,
+	99,
+	46,
+	99,
+	111,
+	109,
+	101,
+	100,
+	121,
+	109,
+	101,
+	110,
+	117,
+	34,
+	32,
+	108,
+	121,
+	114,
+	105,
+	99,
+	115,
+	116,
+	111,
+	100,
+	97,
+	121,
+	46,
+	105,
+	110,
+	100,
+	101,
+	101,
+	100,
+	99,
+	111,
+	117,
+	110,
+	116,
+	121,
+	95,
+	108,
+	111,
+	103,
+	111,
+	46,
+	70,
+	97,
+	109,
+	105,
+	108,
+	121,
+	108,
+	111,
+	111,
+	107,
+	101,
+	100,
+	77,
+	97,
+	114,
+	107,
+	101,
+	116,
+	108,
+	115,
+	101,
+	32,
+	105,
+	102,
+	80,
+	108,
+	97,
+	121,
+	101,
+	114,
+	116,
+	117,
+	114,
+	107,
+	101,
+	121,
+	41,
+	59,
+	118,
+	97,
+	114,
+	32,
+	102,
+	111,
+	114,
+	101,
+	115,
+	116,
+	103,
+	105,
+	118,
+	105,
+	110,
+	103,
+	101,
+	114,
+	114,
+	111,
+	114,
+	115,
+	68,
+	111,
+	109,
+	97,
+	105,
+	110,
+	125,
+	101,
+	108,
+	115,
+	101,
+	123,
+	105,
+	110,
+	115,
+	101,
+	114,
+	116,
+	66,
+	108,
+	111,
+	103,
+	60,
+	47,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	108,
+	111,
+	103,
+	105,
+	110,
+	46,
+	102,
+	97,
+	115,
+	116,
+	101,
+	114,
+	97,
+	103,
+	101,
+	110,
+	116,
+	115,
+	60,
+	98,
+	111,
+	100,
+	121,
+	32,
+	49,
+	48,
+	112,
+	120,
+	32,
+	48,
+	112,
+	114,
+	97,
+	103,
+	109,
+	97,
+	102,
+	114,
+	105,
+	100,
+	97,
+	121,
+	106,
+	117,
+	110,
+	105,
+	111,
+	114,
+	100,
+	111,
+	108,
+	108,
+	97,
+	114,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	99,
+	111,
+	118,
+	101,
+	114,
+	115,
+	112,
+	108,
+	117,
+	103,
+	105,
+	110,
+	53,
+	44,
+	48,
+	48,
+	48,
+	32,
+	112,
+	97,
+	103,
+	101,
+	34,
+	62,
+	98,
+	111,
+	115,
+	116,
+	111,
+	110,
+	46,
+	116,
+	101,
+	115,
+	116,
+	40,
+	97,
+	118,
+	97,
+	116,
+	97,
+	114,
+	116,
+	101,
+	115,
+	116,
+	101,
+	100,
+	95,
+	99,
+	111,
+	117,
+	110,
+	116,
+	102,
+	111,
+	114,
+	117,
+	109,
+	115,
+	115,
+	99,
+	104,
+	101,
+	109,
+	97,
+	105,
+	110,
+	100,
+	101,
+	120,
+	44,
+	102,
+	105,
+	108,
+	108,
+	101,
+	100,
+	115,
+	104,
+	97,
+	114,
+	101,
+	115,
+	114,
+	101,
+	97,
+	100,
+	101,
+	114,
+	97,
+	108,
+	101,
+	114,
+	116,
+	40,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	83,
+	117,
+	98,
+	109,
+	105,
+	116,
+	108,
+	105,
+	110,
+	101,
+	34,
+	62,
+	98,
+	111,
+	100,
+	121,
+	34,
+	62,
+	10,
+	42,
+	32,
+	84,
+	104,
+	101,
+	84,
+	104,
+	111,
+	117,
+	103,
+	104,
+	115,
+	101,
+	101,
+	105,
+	110,
+	103,
+	106,
+	101,
+	114,
+	115,
+	101,
+	121,
+	78,
+	101,
+	119,
+	115,
+	60,
+	47,
+	118,
+	101,
+	114,
+	105,
+	102,
+	121,
+	101,
+	120,
+	112,
+	101,
+	114,
+	116,
+	105,
+	110,
+	106,
+	117,
+	114,
+	121,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	67,
+	111,
+	111,
+	107,
+	105,
+	101,
+	83,
+	84,
+	65,
+	82,
+	84,
+	32,
+	97,
+	99,
+	114,
+	111,
+	115,
+	115,
+	95,
+	105,
+	109,
+	97,
+	103,
+	101,
+	116,
+	104,
+	114,
+	101,
+	97,
+	100,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	112,
+	111,
+	99,
+	107,
+	101,
+	116,
+	98,
+	111,
+	120,
+	34,
+	62,
+	10,
+	83,
+	121,
+	115,
+	116,
+	101,
+	109,
+	32,
+	68,
+	97,
+	118,
+	105,
+	100,
+	99,
+	97,
+	110,
+	99,
+	101,
+	114,
+	116,
+	97,
+	98,
+	108,
+	101,
+	115,
+	112,
+	114,
+	111,
+	118,
+	101,
+	100,
+	65,
+	112,
+	114,
+	105,
+	108,
+	32,
+	114,
+	101,
+	97,
+	108,
+	108,
+	121,
+	100,
+	114,
+	105,
+	118,
+	101,
+	114,
+	105,
+	116,
+	101,
+	109,
+	34,
+	62,
+	109,
+	111,
+	114,
+	101,
+	34,
+	62,
+	98,
+	111,
+	97,
+	114,
+	100,
+	115,
+	99,
+	111,
+	108,
+	111,
+	114,
+	115,
+	99,
+	97,
+	109,
+	112,
+	117,
+	115,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	124,
+	124,
+	32,
+	91,
+	93,
+	59,
+	109,
+	101,
+	100,
+	105,
+	97,
+	46,
+	103,
+	117,
+	105,
+	116,
+	97,
+	114,
+	102,
+	105,
+	110,
+	105,
+	115,
+	104,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	115,
+	104,
+	111,
+	119,
+	101,
+	100,
+	79,
+	116,
+	104,
+	101,
+	114,
+	32,
+	46,
+	112,
+	104,
+	112,
+	34,
+	32,
+	97,
+	115,
+	115,
+	117,
+	109,
+	101,
+	108,
+	97,
+	121,
+	101,
+	114,
+	115,
+	119,
+	105,
+	108,
+	115,
+	111,
+	110,
+	115,
+	116,
+	111,
+	114,
+	101,
+	115,
+	114,
+	101,
+	108,
+	105,
+	101,
+	102,
+	115,
+	119,
+	101,
+	100,
+	101,
+	110,
+	67,
+	117,
+	115,
+	116,
+	111,
+	109,
+	101,
+	97,
+	115,
+	105,
+	108,
+	121,
+	32,
+	121,
+	111,
+	117,
+	114,
+	32,
+	83,
+	116,
+	114,
+	105,
+	110,
+	103,
+	10,
+	10,
+	87,
+	104,
+	105,
+	108,
+	116,
+	97,
+	121,
+	108,
+	111,
+	114,
+	99,
+	108,
+	101,
+	97,
+	114,
+	58,
+	114,
+	101,
+	115,
+	111,
+	114,
+	116,
+	102,
+	114,
+	101,
+	110,
+	99,
+	104,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	34,
+	41,
+	32,
+	43,
+	32,
+	34,
+	60,
+	98,
+	111,
+	100,
+	121,
+	62,
+	98,
+	117,
+	121,
+	105,
+	110,
+	103,
+	98,
+	114,
+	97,
+	110,
+	100,
+	115,
+	77,
+	101,
+	109,
+	98,
+	101,
+	114,
+	110,
+	97,
+	109,
+	101,
+	34,
+	62,
+	111,
+	112,
+	112,
+	105,
+	110,
+	103,
+	115,
+	101,
+	99,
+	116,
+	111,
+	114,
+	53,
+	112,
+	120,
+	59,
+	34,
+	62,
+	118,
+	115,
+	112,
+	97,
+	99,
+	101,
+	112,
+	111,
+	115,
+	116,
+	101,
+	114,
+	109,
+	97,
+	106,
+	111,
+	114,
+	32,
+	99,
+	111,
+	102,
+	102,
+	101,
+	101,
+	109,
+	97,
+	114,
+	116,
+	105,
+	110,
+	109,
+	97,
+	116,
+	117,
+	114,
+	101,
+	104,
+	97,
+	112,
+	112,
+	101,
+	110,
+	60,
+	47,
+	110,
+	97,
+	118,
+	62,
+	107,
+	97,
+	110,
+	115,
+	97,
+	115,
+	108,
+	105,
+	110,
+	107,
+	34,
+	62,
+	73,
+	109,
+	97,
+	103,
+	101,
+	115,
+	61,
+	102,
+	97,
+	108,
+	115,
+	101,
+	119,
+	104,
+	105,
+	108,
+	101,
+	32,
+	104,
+	115,
+	112,
+	97,
+	99,
+	101,
+	48,
+	38,
+	97,
+	109,
+	112,
+	59,
+	32,
+	10,
+	10,
+	73,
+	110,
+	32,
+	32,
+	112,
+	111,
+	119,
+	101,
+	114,
+	80,
+	111,
+	108,
+	115,
+	107,
+	105,
+	45,
+	99,
+	111,
+	108,
+	111,
+	114,
+	106,
+	111,
+	114,
+	100,
+	97,
+	110,
+	66,
+	111,
+	116,
+	116,
+	111,
+	109,
+	83,
+	116,
+	97,
+	114,
+	116,
+	32,
+	45,
+	99,
+	111,
+	117,
+	110,
+	116,
+	50,
+	46,
+	104,
+	116,
+	109,
+	108,
+	110,
+	101,
+	119,
+	115,
+	34,
+	62,
+	48,
+	49,
+	46,
+	106,
+	112,
+	103,
+	79,
+	110,
+	108,
+	105,
+	110,
+	101,
+	45,
+	114,
+	105,
+	103,
+	104,
+	116,
+	109,
+	105,
+	108,
+	108,
+	101,
+	114,
+	115,
+	101,
+	110,
+	105,
+	111,
+	114,
+	73,
+	83,
+	66,
+	78,
+	32,
+	48,
+	48,
+	44,
+	48,
+	48,
+	48,
+	32,
+	103,
+	117,
+	105,
+	100,
+	101,
+	115,
+	118,
+	97,
+	108,
+	117,
+	101,
+	41,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	112,
+	97,
+	105,
+	114,
+	46,
+	120,
+	109,
+	108,
+	34,
+	32,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	115,
+	46,
+	104,
+	116,
+	109,
+	108,
+	45,
+	98,
+	108,
+	111,
+	99,
+	107,
+	114,
+	101,
+	103,
+	69,
+	120,
+	112,
+	58,
+	104,
+	111,
+	118,
+	101,
+	114,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	118,
+	105,
+	114,
+	103,
+	105,
+	110,
+	112,
+	104,
+	111,
+	110,
+	101,
+	115,
+	60,
+	47,
+	116,
+	114,
+	62,
+	13,
+	117,
+	115,
+	105,
+	110,
+	103,
+	32,
+	10,
+	9,
+	118,
+	97,
+	114,
+	32,
+	62,
+	39,
+	41,
+	59,
+	10,
+	9,
+	60,
+	47,
+	116,
+	100,
+	62,
+	10,
+	60,
+	47,
+	116,
+	114,
+	62,
+	10,
+	98,
+	97,
+	104,
+	97,
+	115,
+	97,
+	98,
+	114,
+	97,
+	115,
+	105,
+	108,
+	103,
+	97,
+	108,
+	101,
+	103,
+	111,
+	109,
+	97,
+	103,
+	121,
+	97,
+	114,
+	112,
+	111,
+	108,
+	115,
+	107,
+	105,
+	115,
+	114,
+	112,
+	115,
+	107,
+	105,
+	216,
+	177,
+	216,
+	175,
+	217,
+	136,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	231,
+	174,
+	128,
+	228,
+	189,
+	147,
+	231,
+	185,
+	129,
+	233,
+	171,
+	148,
+	228,
+	191,
+	161,
+	230,
+	129,
+	175,
+	228,
+	184,
+	173,
+	229,
+	155,
+	189,
+	230,
+	136,
+	145,
+	228,
+	187,
+	172,
+	228,
+	184,
+	128,
+	228,
+	184,
+	170,
+	229,
+	133,
+	172,
+	229,
+	143,
+	184,
+	231,
+	174,
+	161,
+	231,
+	144,
+	134,
+	232,
+	174,
+	186,
+	229,
+	157,
+	155,
+	229,
+	143,
+	175,
+	228,
+	187,
+	165,
+	230,
+	156,
+	141,
+	229,
+	138,
+	161,
+	230,
+	151,
+	182,
+	233,
+	151,
+	180,
+	228,
+	184,
+	170,
+	228,
+	186,
+	186,
+	228,
+	186,
+	167,
+	229,
+	147,
+	129,
+	232,
+	135,
+	170,
+	229,
+	183,
+	177,
+	228,
+	188,
+	129,
+	228,
+	184,
+	154,
+	230,
+	159,
+	165,
+	231,
+	156,
+	139,
+	229,
+	183,
+	165,
+	228,
+	189,
+	156,
+	232,
+	129,
+	148,
+	231,
+	179,
+	187,
+	230,
+	178,
+	161,
+	230,
+	156,
+	137,
+	231,
+	189,
+	145,
+	231,
+	171,
+	153,
+	230,
+	137,
+	128,
+	230,
+	156,
+	137,
+	232,
+	175,
+	132,
+	232,
+	174,
+	186,
+	228,
+	184,
+	173,
+	229,
+	191,
+	131,
+	230,
+	150,
+	135,
+	231,
+	171,
+	160,
+	231,
+	148,
+	168,
+	230,
+	136,
+	183,
+	233,
+	166,
+	150,
+	233,
+	161,
+	181,
+	228,
+	189,
+	156,
+	232,
+	128,
+	133,
+	230,
+	138,
+	128,
+	230,
+	156,
+	175,
+	233,
+	151,
+	174,
+	233,
+	162,
+	152,
+	231,
+	155,
+	184,
+	229,
+	133,
+	179,
+	228,
+	184,
+	139,
+	232,
+	189,
+	189,
+	230,
+	144,
+	156,
+	231,
+	180,
+	162,
+	228,
+	189,
+	191,
+	231,
+	148,
+	168,
+	232,
+	189,
+	175,
+	228,
+	187,
+	182,
+	229,
+	156,
+	168,
+	231,
+	186,
+	191,
+	228,
+	184,
+	187,
+	233,
+	162,
+	152,
+	232,
+	181,
+	132,
+	230,
+	150,
+	153,
+	232,
+	167,
+	134,
+	233,
+	162,
+	145,
+	229,
+	155,
+	158,
+	229,
+	164,
+	141,
+	230,
+	179,
+	168,
+	229,
+	134,
+	140,
+	231,
+	189,
+	145,
+	231,
+	187,
+	156,
+	230,
+	148,
+	182,
+	232,
+	151,
+	143,
+	229,
+	134,
+	133,
+	229,
+	174,
+	185,
+	230,
+	142,
+	168,
+	232,
+	141,
+	144,
+	229,
+	184,
+	130,
+	229,
+	156,
+	186,
+	230,
+	182,
+	136,
+	230,
+	129,
+	175,
+	231,
+	169,
+	186,
+	233,
+	151,
+	180,
+	229,
+	143,
+	145,
+	229,
+	184,
+	131,
+	228,
+	187,
+	128,
+	228,
+	185,
+	136,
+	229,
+	165,
+	189,
+	229,
+	143,
+	139,
+	231,
+	148,
+	159,
+	230,
+	180,
+	187,
+	229,
+	155,
+	190,
+	231,
+	137,
+	135,
+	229,
+	143,
+	145,
+	229,
+	177,
+	149,
+	229,
+	166,
+	130,
+	230,
+	158,
+	156,
+	230,
+	137,
+	139,
+	230,
+	156,
+	186,
+	230,
+	150,
+	176,
+	233,
+	151,
+	187,
+	230,
+	156,
+	128,
+	230,
+	150,
+	176,
+	230,
+	150,
+	185,
+	229,
+	188,
+	143,
+	229,
+	140,
+	151,
+	228,
+	186,
+	172,
+	230,
+	143,
+	144,
+	228,
+	190,
+	155,
+	229,
+	133,
+	179,
+	228,
+	186,
+	142,
+	230,
+	155,
+	180,
+	229,
+	164,
+	154,
+	232,
+	191,
+	153,
+	228,
+	184,
+	170,
+	231,
+	179,
+	187,
+	231,
+	187,
+	159,
+	231,
+	159,
+	165,
+	233,
+	129,
+	147,
+	230,
+	184,
+	184,
+	230,
+	136,
+	143,
+	229,
+	185,
+	191,
+	229,
+	145,
+	138,
+	229,
+	133,
+	182,
+	228,
+	187,
+	150,
+	229,
+	143,
+	145,
+	232,
+	161,
+	168,
+	229,
+	174,
+	137,
+	229,
+	133,
+	168,
+	231,
+	172,
+	172,
+	228,
+	184,
+	128,
+	228,
+	188,
+	154,
+	229,
+	145,
+	152,
+	232,
+	191,
+	155,
+	232,
+	161,
+	140,
+	231,
+	130,
+	185,
+	229,
+	135,
+	187,
+	231,
+	137,
+	136,
+	230,
+	157,
+	131,
+	231,
+	148,
+	181,
+	229,
+	173,
+	144,
+	228,
+	184,
+	150,
+	231,
+	149,
+	140,
+	232,
+	174,
+	190,
+	232,
+	174,
+	161,
+	229,
+	133,
+	141,
+	232,
+	180,
+	185,
+	230,
+	149,
+	153,
+	232,
+	130,
+	178,
+	229,
+	138,
+	160,
+	229,
+	133,
+	165,
+	230,
+	180,
+	187,
+	229,
+	138,
+	168,
+	228,
+	187,
+	150,
+	228,
+	187,
+	172,
+	229,
+	149,
+	134,
+	229,
+	147,
+	129,
+	229,
+	141,
+	154,
+	229,
+	174,
+	162,
+	231,
+	142,
+	176,
+	229,
+	156,
+	168,
+	228,
+	184,
+	138,
+	230,
+	181,
+	183,
+	229,
+	166,
+	130,
+	228,
+	189,
+	149,
+	229,
+	183,
+	178,
+	231,
+	187,
+	143,
+	231,
+	149,
+	153,
+	232,
+	168,
+	128,
+	232,
+	175,
+	166,
+	231,
+	187,
+	134,
+	231,
+	164,
+	190,
+	229,
+	140,
+	186,
+	231,
+	153,
+	187,
+	229,
+	189,
+	149,
+	230,
+	156,
+	172,
+	231,
+	171,
+	153,
+	233,
+	156,
+	128,
+	232,
+	166,
+	129,
+	228,
+	187,
+	183,
+	230,
+	160,
+	188,
+	230,
+	148,
+	175,
+	230,
+	140,
+	129,
+	229,
+	155,
+	189,
+	233,
+	153,
+	133,
+	233,
+	147,
+	190,
+	230,
+	142,
+	165,
+	229,
+	155,
+	189,
+	229,
+	174,
+	182,
+	229,
+	187,
+	186,
+	232,
+	174,
+	190,
+	230,
+	156,
+	139,
+	229,
+	143,
+	139,
+	233,
+	152,
+	133,
+	232,
+	175,
+	187,
+	230,
+	179,
+	149,
+	229,
+	190,
+	139,
+	228,
+	189,
+	141,
+	231,
+	189,
+	174,
+	231,
+	187,
+	143,
+	230,
+	181,
+	142,
+	233,
+	128,
+	137,
+	230,
+	139,
+	169,
+	232,
+	191,
+	153,
+	230,
+	160,
+	183,
+	229,
+	189,
+	147,
+	229,
+	137,
+	141,
+	229,
+	136,
+	134,
+	231,
+	177,
+	187,
+	230,
+	142,
+	146,
+	232,
+	161,
+	140,
+	229,
+	155,
+	160,
+	228,
+	184,
+	186,
+	228,
+	186,
+	164,
+	230,
+	152,
+	147,
+	230,
+	156,
+	128,
+	229,
+	144,
+	142,
+	233,
+	159,
+	179,
+	228,
+	185,
+	144,
+	228,
+	184,
+	141,
+	232,
+	131,
+	189,
+	233,
+	128,
+	154,
+	232,
+	191,
+	135,
+	232,
+	161,
+	140,
+	228,
+	184,
+	154,
+	231,
+	167,
+	145,
+	230,
+	138,
+	128,
+	229,
+	143,
+	175,
+	232,
+	131,
+	189,
+	232,
+	174,
+	190,
+	229,
+	164,
+	135,
+	229,
+	144,
+	136,
+	228,
+	189,
+	156,
+	229,
+	164,
+	167,
+	229,
+	174,
+	182,
+	231,
+	164,
+	190,
+	228,
+	188,
+	154,
+	231,
+	160,
+	148,
+	231,
+	169,
+	182,
+	228,
+	184,
+	147,
+	228,
+	184,
+	154,
+	229,
+	133,
+	168,
+	233,
+	131,
+	168,
+	233,
+	161,
+	185,
+	231,
+	155,
+	174,
+	232,
+	191,
+	153,
+	233,
+	135,
+	140,
+	232,
+	191,
+	152,
+	230,
+	152,
+	175,
+	229,
+	188,
+	128,
+	229,
+	167,
+	139,
+	230,
+	131,
+	133,
+	229,
+	134,
+	181,
+	231,
+	148,
+	181,
+	232,
+	132,
+	145,
+	230,
+	150,
+	135,
+	228,
+	187,
+	182,
+	229,
+	147,
+	129,
+	231,
+	137,
+	140,
+	229,
+	184,
+	174,
+	229,
+	138,
+	169,
+	230,
+	150,
+	135,
+	229,
+	140,
+	150,
+	232,
+	181,
+	132,
+	230,
+	186,
+	144,
+	229,
+	164,
+	167,
+	229,
+	173,
+	166,
+	229,
+	173,
+	166,
+	228,
+	185,
+	160,
+	229,
+	156,
+	176,
+	229,
+	157,
+	128,
+	230,
+	181,
+	143,
+	232,
+	167,
+	136,
+	230,
+	138,
+	149,
+	232,
+	181,
+	132,
+	229,
+	183,
+	165,
+	231,
+	168,
+	139,
+	232,
+	166,
+	129,
+	230,
+	177,
+	130,
+	230,
+	128,
+	142,
+	228,
+	185,
+	136,
+	230,
+	151,
+	182,
+	229,
+	128,
+	153,
+	229,
+	138,
+	159,
+	232,
+	131,
+	189,
+	228,
+	184,
+	187,
+	232,
+	166,
+	129,
+	231,
+	155,
+	174,
+	229,
+	137,
+	141,
+	232,
+	181,
+	132,
+	232,
+	174,
+	175,
+	229,
+	159,
+	142,
+	229,
+	184,
+	130,
+	230,
+	150,
+	185,
+	230,
+	179,
+	149,
+	231,
+	148,
+	181,
+	229,
+	189,
+	177,
+	230,
+	139,
+	155,
+	232,
+	129,
+	152,
+	229,
+	163,
+	176,
+	230,
+	152,
+	142,
+	228,
+	187,
+	187,
+	228,
+	189,
+	149,
+	229,
+	129,
+	165,
+	229,
+	186,
+	183,
+	230,
+	149,
+	176,
+	230,
+	141,
+	174,
+	231,
+	190,
+	142,
+	229,
+	155,
+	189,
+	230,
+	177,
+	189,
+	232,
+	189,
+	166,
+	228,
+	187,
+	139,
+	231,
+	187,
+	141,
+	228,
+	189,
+	134,
+	230,
+	152,
+	175,
+	228,
+	186,
+	164,
+	230,
+	181,
+	129,
+	231,
+	148,
+	159,
+	228,
+	186,
+	167,
+	230,
+	137,
+	128,
+	228,
+	187,
+	165,
+	231,
+	148,
+	181,
+	232,
+	175,
+	157,
+	230,
+	152,
+	190,
+	231,
+	164,
+	186,
+	228,
+	184,
+	128,
+	228,
+	186,
+	155,
+	229,
+	141,
+	149,
+	228,
+	189,
+	141,
+	228,
+	186,
+	186,
+	229,
+	145,
+	152,
+	229,
+	136,
+	134,
+	230,
+	158,
+	144,
+	229,
+	156,
+	176,
+	229,
+	155,
+	190,
+	230,
+	151,
+	133,
+	230,
+	184,
+	184,
+	229,
+	183,
+	165,
+	229,
+	133,
+	183,
+	229,
+	173,
+	166,
+	231,
+	148,
+	159,
+	231,
+	179,
+	187,
+	229,
+	136,
+	151,
+	231,
+	189,
+	145,
+	229,
+	143,
+	139,
+	229,
+	184,
+	150,
+	229,
+	173,
+	144,
+	229,
+	175,
+	134,
+	231,
+	160,
+	129,
+	233,
+	162,
+	145,
+	233,
+	129,
+	147,
+	230,
+	142,
+	167,
+	229,
+	136,
+	182,
+	229,
+	156,
+	176,
+	229,
+	140,
+	186,
+	229,
+	159,
+	186,
+	230,
+	156,
+	172,
+	229,
+	133,
+	168,
+	229,
+	155,
+	189,
+	231,
+	189,
+	145,
+	228,
+	184,
+	138,
+	233,
+	135,
+	141,
+	232,
+	166,
+	129,
+	231,
+	172,
+	172,
+	228,
+	186,
+	140,
+	229,
+	150,
+	156,
+	230,
+	172,
+	162,
+	232,
+	191,
+	155,
+	229,
+	133,
+	165,
+	229,
+	143,
+	139,
+	230,
+	131,
+	133,
+	232,
+	191,
+	153,
+	228,
+	186,
+	155,
+	232,
+	128,
+	131,
+	232,
+	175,
+	149,
+	229,
+	143,
+	145,
+	231,
+	142,
+	176,
+	229,
+	159,
+	185,
+	232,
+	174,
+	173,
+	228,
+	187,
+	165,
+	228,
+	184,
+	138,
+	230,
+	148,
+	191,
+	229,
+	186,
+	156,
+	230,
+	136,
+	144,
+	228,
+	184,
+	186,
+	231,
+	142,
+	175,
+	229,
+	162,
+	131,
+	233,
+	166,
+	153,
+	230,
+	184,
+	175,
+	229,
+	144,
+	140,
+	230,
+	151,
+	182,
+	229,
+	168,
+	177,
+	228,
+	185,
+	144,
+	229,
+	143,
+	145,
+	233,
+	128,
+	129,
+	228,
+	184,
+	128,
+	229,
+	174,
+	154,
+	229,
+	188,
+	128,
+	229,
+	143,
+	145,
+	228,
+	189,
+	156,
+	229,
+	147,
+	129,
+	230,
+	160,
+	135,
+	229,
+	135,
+	134,
+	230,
+	172,
+	162,
+	232,
+	191,
+	142,
+	232,
+	167,
+	163,
+	229,
+	134,
+	179,
+	229,
+	156,
+	176,
+	230,
+	150,
+	185,
+	228,
+	184,
+	128,
+	228,
+	184,
+	139,
+	228,
+	187,
+	165,
+	229,
+	143,
+	138,
+	232,
+	180,
+	163,
+	228,
+	187,
+	187,
+	230,
+	136,
+	150,
+	232,
+	128,
+	133,
+	229,
+	174,
+	162,
+	230,
+	136,
+	183,
+	228,
+	187,
+	163,
+	232,
+	161,
+	168,
+	231,
+	167,
+	175,
+	229,
+	136,
+	134,
+	229,
+	165,
+	179,
+	228,
+	186,
+	186,
+	230,
+	149,
+	176,
+	231,
+	160,
+	129,
+	233,
+	148,
+	128,
+	229,
+	148,
+	174,
+	229,
+	135,
+	186,
+	231,
+	142,
+	176,
+	231,
+	166,
+	187,
+	231,
+	186,
+	191,
+	229,
+	186,
+	148,
+	231,
+	148,
+	168,
+	229,
+	136,
+	151,
+	232,
+	161,
+	168,
+	228,
+	184,
+	141,
+	229,
+	144,
+	140,
+	231,
+	188,
+	150,
+	232,
+	190,
+	145,
+	231,
+	187,
+	159,
+	232,
+	174,
+	161,
+	230,
+	159,
+	165,
+	232,
+	175,
+	162,
+	228,
+	184,
+	141,
+	232,
+	166,
+	129,
+	230,
+	156,
+	137,
+	229,
+	133,
+	179,
+	230,
+	156,
+	186,
+	230,
+	158,
+	132,
+	229,
+	190,
+	136,
+	229,
+	164,
+	154,
+	230,
+	146,
+	173,
+	230,
+	148,
+	190,
+	231,
+	187,
+	132,
+	231,
+	187,
+	135,
+	230,
+	148,
+	191,
+	231,
+	173,
+	150,
+	231,
+	155,
+	180,
+	230,
+	142,
+	165,
+	232,
+	131,
+	189,
+	229,
+	138,
+	155,
+	230,
+	157,
+	165,
+	230,
+	186,
+	144,
+	230,
+	153,
+	130,
+	233,
+	150,
+	147,
+	231,
+	156,
+	139,
+	229,
+	136,
+	176,
+	231,
+	131,
+	173,
+	233,
+	151,
+	168,
+	229,
+	133,
+	179,
+	233,
+	148,
+	174,
+	228,
+	184,
+	147,
+	229,
+	140,
+	186,
+	233,
+	157,
+	158,
+	229,
+	184,
+	184,
+	232,
+	139,
+	177,
+	232,
+	175,
+	173,
+	231,
+	153,
+	190,
+	229,
+	186,
+	166,
+	229,
+	184,
+	140,
+	230,
+	156,
+	155,
+	231,
+	190,
+	142,
+	229,
+	165,
+	179,
+	230,
+	175,
+	148,
+	232,
+	190,
+	131,
+	231,
+	159,
+	165,
+	232,
+	175,
+	134,
+	232,
+	167,
+	132,
+	229,
+	174,
+	154,
+	229,
+	187,
+	186,
+	232,
+	174,
+	174,
+	233,
+	131,
+	168,
+	233,
+	151,
+	168,
+	230,
+	132,
+	143,
+	232,
+	167,
+	129,
+	231,
+	178,
+	190,
+	229,
+	189,
+	169,
+	230,
+	151,
+	165,
+	230,
+	156,
+	172,
+	230,
+	143,
+	144,
+	233,
+	171,
+	152,
+	229,
+	143,
+	145,
+	232,
+	168,
+	128,
+	230,
+	150,
+	185,
+	233,
+	157,
+	162,
+	229,
+	159,
+	186,
+	233,
+	135,
+	145,
+	229,
+	164,
+	132,
+	231,
+	144,
+	134,
+	230,
+	157,
+	131,
+	233,
+	153,
+	144,
+	229,
+	189,
+	177,
+	231,
+	137,
+	135,
+	233,
+	147,
+	182,
+	232,
+	161,
+	140,
+	232,
+	191,
+	152,
+	230,
+	156,
+	137,
+	229,
+	136,
+	134,
+	228,
+	186,
+	171,
+	231,
+	137,
+	169,
+	229,
+	147,
+	129,
+	231,
+	187,
+	143,
+	232,
+	144,
+	165,
+	230,
+	183,
+	187,
+	229,
+	138,
+	160,
+	228,
+	184,
+	147,
+	229,
+	174,
+	182,
+	232,
+	191,
+	153,
+	231,
+	167,
+	141,
+	232,
+	175,
+	157,
+	233,
+	162,
+	152,
+	232,
+	181,
+	183,
+	230,
+	157,
+	165,
+	228,
+	184,
+	154,
+	229,
+	138,
+	161,
+	229,
+	133,
+	172,
+	229,
+	145,
+	138,
+	232,
+	174,
+	176,
+	229,
+	189,
+	149,
+	231,
+	174,
+	128,
+	228,
+	187,
+	139,
+	232,
+	180,
+	168,
+	233,
+	135,
+	143,
+	231,
+	148,
+	183,
+	228,
+	186,
+	186,
+	229,
+	189,
+	177,
+	229,
+	147,
+	141,
+	229,
+	188,
+	149,
+	231,
+	148,
+	168,
+	230,
+	138,
+	165,
+	229,
+	145,
+	138,
+	233,
+	131,
+	168,
+	229,
+	136,
+	134,
+	229,
+	191,
+	171,
+	233,
+	128,
+	159,
+	229,
+	146,
+	168,
+	232,
+	175,
+	162,
+	230,
+	151,
+	182,
+	229,
+	176,
+	154,
+	230,
+	179,
+	168,
+	230,
+	132,
+	143,
+	231,
+	148,
+	179,
+	232,
+	175,
+	183,
+	229,
+	173,
+	166,
+	230,
+	160,
+	161,
+	229,
+	186,
+	148,
+	232,
+	175,
+	165,
+	229,
+	142,
+	134,
+	229,
+	143,
+	178,
+	229,
+	143,
+	170,
+	230,
+	152,
+	175,
+	232,
+	191,
+	148,
+	229,
+	155,
+	158,
+	232,
+	180,
+	173,
+	228,
+	185,
+	176,
+	229,
+	144,
+	141,
+	231,
+	167,
+	176,
+	228,
+	184,
+	186,
+	228,
+	186,
+	134,
+	230,
+	136,
+	144,
+	229,
+	138,
+	159,
+	232,
+	175,
+	180,
+	230,
+	152,
+	142,
+	228,
+	190,
+	155,
+	229,
+	186,
+	148,
+	229,
+	173,
+	169,
+	229,
+	173,
+	144,
+	228,
+	184,
+	147,
+	233,
+	162,
+	152,
+	231,
+	168,
+	139,
+	229,
+	186,
+	143,
+	228,
+	184,
+	128,
+	232,
+	136,
+	172,
+	230,
+	156,
+	131,
+	229,
+	147,
+	161,
+	229,
+	143,
+	170,
+	230,
+	156,
+	137,
+	229,
+	133,
+	182,
+	229,
+	174,
+	131,
+	228,
+	191,
+	157,
+	230,
+	138,
+	164,
+	232,
+	128,
+	140,
+	228,
+	184,
+	148,
+	228,
+	187,
+	138,
+	229,
+	164,
+	169,
+	231,
+	170,
+	151,
+	229,
+	143,
+	163,
+	229,
+	138,
+	168,
+	230,
+	128,
+	129,
+	231,
+	138,
+	182,
+	230,
+	128,
+	129,
+	231,
+	137,
+	185,
+	229,
+	136,
+	171,
+	232,
+	174,
+	164,
+	228,
+	184,
+	186,
+	229,
+	191,
+	133,
+	233,
+	161,
+	187,
+	230,
+	155,
+	180,
+	230,
+	150,
+	176,
+	229,
+	176,
+	143,
+	232,
+	175,
+	180,
+	230,
+	136,
+	145,
+	229,
+	128,
+	145,
+	228,
+	189,
+	156,
+	228,
+	184,
+	186,
+	229,
+	170,
+	146,
+	228,
+	189,
+	147,
+	229,
+	140,
+	133,
+	230,
+	139,
+	172,
+	233,
+	130,
+	163,
+	228,
+	185,
+	136,
+	228,
+	184,
+	128,
+	230,
+	160,
+	183,
+	229,
+	155,
+	189,
+	229,
+	134,
+	133,
+	230,
+	152,
+	175,
+	229,
+	144,
+	166,
+	230,
+	160,
+	185,
+	230,
+	141,
+	174,
+	231,
+	148,
+	181,
+	232,
+	167,
+	134,
+	229,
+	173,
+	166,
+	233,
+	153,
+	162,
+	229,
+	133,
+	183,
+	230,
+	156,
+	137,
+	232,
+	191,
+	135,
+	231,
+	168,
+	139,
+	231,
+	148,
+	177,
+	228,
+	186,
+	142,
+	228,
+	186,
+	186,
+	230,
+	137,
+	141,
+	229,
+	135,
+	186,
+	230,
+	157,
+	165,
+	228,
+	184,
+	141,
+	232,
+	191,
+	135,
+	230,
+	173,
+	163,
+	229,
+	156,
+	168,
+	230,
+	152,
+	142,
+	230,
+	152,
+	159,
+	230,
+	149,
+	133,
+	228,
+	186,
+	139,
+	229,
+	133,
+	179,
+	231,
+	179,
+	187,
+	230,
+	160,
+	135,
+	233,
+	162,
+	152,
+	229,
+	149,
+	134,
+	229,
+	138,
+	161,
+	232,
+	190,
+	147,
+	229,
+	133,
+	165,
+	228,
+	184,
+	128,
+	231,
+	155,
+	180,
+	229,
+	159,
+	186,
+	231,
+	161,
+	128,
+	230,
+	149,
+	153,
+	229,
+	173,
+	166,
+	228,
+	186,
+	134,
+	232,
+	167,
+	163,
+	229,
+	187,
+	186,
+	231,
+	173,
+	145,
+	231,
+	187,
+	147,
+	230,
+	158,
+	156,
+	229,
+	133,
+	168,
+	231,
+	144,
+	131,
+	233,
+	128,
+	154,
+	231,
+	159,
+	165,
+	232,
+	174,
+	161,
+	229,
+	136,
+	146,
+	229,
+	175,
+	185,
+	228,
+	186,
+	142,
+	232,
+	137,
+	186,
+	230,
+	156,
+	175,
+	231,
+	155,
+	184,
+	229,
+	134,
+	140,
+	229,
+	143,
+	145,
+	231,
+	148,
+	159,
+	231,
+	156,
+	159,
+	231,
+	154,
+	132,
+	229,
+	187,
+	186,
+	231,
+	171,
+	139,
+	231,
+	173,
+	137,
+	231,
+	186,
+	167,
+	231,
+	177,
+	187,
+	229,
+	158,
+	139,
+	231,
+	187,
+	143,
+	233,
+	170,
+	140,
+	229,
+	174,
+	158,
+	231,
+	142,
+	176,
+	229,
+	136,
+	182,
+	228,
+	189,
+	156,
+	230,
+	157,
+	165,
+	232,
+	135,
+	170,
+	230,
+	160,
+	135,
+	231,
+	173,
+	190,
+	228,
+	187,
+	165,
+	228,
+	184,
+	139,
+	229,
+	142,
+	159,
+	229,
+	136,
+	155,
+	230,
+	151,
+	160,
+	230,
+	179,
+	149,
+	229,
+	133,
+	182,
+	228,
+	184,
+	173,
+	229,
+	128,
+	139,
+	228,
+	186,
+	186,
+	228,
+	184,
+	128,
+	229,
+	136,
+	135,
+	230,
+	140,
+	135,
+	229,
+	141,
+	151,
+	229,
+	133,
+	179,
+	233,
+	151,
+	173,
+	233,
+	155,
+	134,
+	229,
+	155,
+	162,
+	231,
+	172,
+	172,
+	228,
+	184,
+	137,
+	229,
+	133,
+	179,
+	230,
+	179,
+	168,
+	229,
+	155,
+	160,
+	230,
+	173,
+	164,
+	231,
+	133,
+	167,
+	231,
+	137,
+	135,
+	230,
+	183,
+	177,
+	229,
+	156,
+	179,
+	229,
+	149,
+	134,
+	228,
+	184,
+	154,
+	229,
+	185,
+	191,
+	229,
+	183,
+	158,
+	230,
+	151,
+	165,
+	230,
+	156,
+	159,
+	233,
+	171,
+	152,
+	231,
+	186,
+	167,
+	230,
+	156,
+	128,
+	232,
+	191,
+	145,
+	231,
+	187,
+	188,
+	229,
+	144,
+	136,
+	232,
+	161,
+	168,
+	231,
+	164,
+	186,
+	228,
+	184,
+	147,
+	232,
+	190,
+	145,
+	232,
+	161,
+	140,
+	228,
+	184,
+	186,
+	228,
+	186,
+	164,
+	233,
+	128,
+	154,
+	232,
+	175,
+	132,
+	228,
+	187,
+	183,
+	232,
+	167,
+	137,
+	229,
+	190,
+	151,
+	231,
+	178,
+	190,
+	229,
+	141,
+	142,
+	229,
+	174,
+	182,
+	229,
+	186,
+	173,
+	229,
+	174,
+	140,
+	230,
+	136,
+	144,
+	230,
+	132,
+	159,
+	232,
+	167,
+	137,
+	229,
+	174,
+	137,
+	232,
+	163,
+	133,
+	229,
+	190,
+	151,
+	229,
+	136,
+	176,
+	233,
+	130,
+	174,
+	228,
+	187,
+	182,
+	229,
+	136,
+	182,
+	229,
+	186,
+	166,
+	233,
+	163,
+	159,
+	229,
+	147,
+	129,
+	232,
+	153,
+	189,
+	231,
+	132,
+	182,
+	232,
+	189,
+	172,
+	232,
+	189,
+	189,
+	230,
+	138,
+	165,
+	228,
+	187,
+	183,
+	232,
+	174,
+	176,
+	232,
+	128,
+	133,
+	230,
+	150,
+	185,
+	230,
+	161,
+	136,
+	232,
+	161,
+	140,
+	230,
+	148,
+	191,
+	228,
+	186,
+	186,
+	230,
+	176,
+	145,
+	231,
+	148,
+	168,
+	229,
+	147,
+	129,
+	228,
+	184,
+	156,
+	232,
+	165,
+	191,
+	230,
+	143,
+	144,
+	229,
+	135,
+	186,
+	233,
+	133,
+	146,
+	229,
+	186,
+	151,
+	231,
+	132,
+	182,
+	229,
+	144,
+	142,
+	228,
+	187,
+	152,
+	230,
+	172,
+	190,
+	231,
+	131,
+	173,
+	231,
+	130,
+	185,
+	228,
+	187,
+	165,
+	229,
+	137,
+	141,
+	229,
+	174,
+	140,
+	229,
+	133,
+	168,
+	229,
+	143,
+	145,
+	229,
+	184,
+	150,
+	232,
+	174,
+	190,
+	231,
+	189,
+	174,
+	233,
+	162,
+	134,
+	229,
+	175,
+	188,
+	229,
+	183,
+	165,
+	228,
+	184,
+	154,
+	229,
+	140,
+	187,
+	233,
+	153,
+	162,
+	231,
+	156,
+	139,
+	231,
+	156,
+	139,
+	231,
+	187,
+	143,
+	229,
+	133,
+	184,
+	229,
+	142,
+	159,
+	229,
+	155,
+	160,
+	229,
+	185,
+	179,
+	229,
+	143,
+	176,
+	229,
+	144,
+	132,
+	231,
+	167,
+	141,
+	229,
+	162,
+	158,
+	229,
+	138,
+	160,
+	230,
+	157,
+	144,
+	230,
+	150,
+	153,
+	230,
+	150,
+	176,
+	229,
+	162,
+	158,
+	228,
+	185,
+	139,
+	229,
+	144,
+	142,
+	232,
+	129,
+	140,
+	228,
+	184,
+	154,
+	230,
+	149,
+	136,
+	230,
+	158,
+	156,
+	228,
+	187,
+	138,
+	229,
+	185,
+	180,
+	232,
+	174,
+	186,
+	230,
+	150,
+	135,
+	230,
+	136,
+	145,
+	229,
+	155,
+	189,
+	229,
+	145,
+	138,
+	232,
+	175,
+	137,
+	231,
+	137,
+	136,
+	228,
+	184,
+	187,
+	228,
+	191,
+	174,
+	230,
+	148,
+	185,
+	229,
+	143,
+	130,
+	228,
+	184,
+	142,
+	230,
+	137,
+	147,
+	229,
+	141,
+	176,
+	229,
+	191,
+	171,
+	228,
+	185,
+	144,
+	230,
+	156,
+	186,
+	230,
+	162,
+	176,
+	232,
+	167,
+	130,
+	231,
+	130,
+	185,
+	229,
+	173,
+	152,
+	229,
+	156,
+	168,
+	231,
+	178,
+	190,
+	231,
+	165,
+	158,
+	232,
+	142,
+	183,
+	229,
+	190,
+	151,
+	229,
+	136,
+	169,
+	231,
+	148,
+	168,
+	231,
+	187,
+	167,
+	231,
+	187,
+	173,
+	228,
+	189,
+	160,
+	228,
+	187,
+	172,
+	232,
+	191,
+	153,
+	228,
+	185,
+	136,
+	230,
+	168,
+	161,
+	229,
+	188,
+	143,
+	232,
+	175,
+	173,
+	232,
+	168,
+	128,
+	232,
+	131,
+	189,
+	229,
+	164,
+	159,
+	233,
+	155,
+	133,
+	232,
+	153,
+	142,
+	230,
+	147,
+	141,
+	228,
+	189,
+	156,
+	233,
+	163,
+	142,
+	230,
+	160,
+	188,
+	228,
+	184,
+	128,
+	232,
+	181,
+	183,
+	231,
+	167,
+	145,
+	229,
+	173,
+	166,
+	228,
+	189,
+	147,
+	232,
+	130,
+	178,
+	231,
+	159,
+	173,
+	228,
+	191,
+	161,
+	230,
+	157,
+	161,
+	228,
+	187,
+	182,
+	230,
+	178,
+	187,
+	231,
+	150,
+	151,
+	232,
+	191,
+	144,
+	229,
+	138,
+	168,
+	228,
+	186,
+	167,
+	228,
+	184,
+	154,
+	228,
+	188,
+	154,
+	232,
+	174,
+	174,
+	229,
+	175,
+	188,
+	232,
+	136,
+	170,
+	229,
+	133,
+	136,
+	231,
+	148,
+	159,
+	232,
+	129,
+	148,
+	231,
+	155,
+	159,
+	229,
+	143,
+	175,
+	230,
+	152,
+	175,
+	229,
+	149,
+	143,
+	233,
+	161,
+	140,
+	231,
+	187,
+	147,
+	230,
+	158,
+	132,
+	228,
+	189,
+	156,
+	231,
+	148,
+	168,
+	232,
+	176,
+	131,
+	230,
+	159,
+	165,
+	232,
+	179,
+	135,
+	230,
+	150,
+	153,
+	232,
+	135,
+	170,
+	229,
+	138,
+	168,
+	232,
+	180,
+	159,
+	232,
+	180,
+	163,
+	229,
+	134,
+	156,
+	228,
+	184,
+	154,
+	232,
+	174,
+	191,
+	233,
+	151,
+	174,
+	229,
+	174,
+	158,
+	230,
+	150,
+	189,
+	230,
+	142,
+	165,
+	229,
+	143,
+	151,
+	232,
+	174,
+	168,
+	232,
+	174,
+	186,
+	233,
+	130,
+	163,
+	228,
+	184,
+	170,
+	229,
+	143,
+	141,
+	233,
+	166,
+	136,
+	229,
+	138,
+	160,
+	229,
+	188,
+	186,
+	229,
+	165,
+	179,
+	230,
+	128,
+	167,
+	232,
+	140,
+	131,
+	229,
+	155,
+	180,
+	230,
+	156,
+	141,
+	229,
+	139,
+	153,
+	228,
+	188,
+	145,
+	233,
+	151,
+	178,
+	228,
+	187,
+	138,
+	230,
+	151,
+	165,
+	229,
+	174,
+	162,
+	230,
+	156,
+	141,
+	232,
+	167,
+	128,
+	231,
+	156,
+	139,
+	229,
+	143,
+	130,
+	229,
+	138,
+	160,
+	231,
+	154,
+	132,
+	232,
+	175,
+	157,
+	228,
+	184,
+	128,
+	231,
+	130,
+	185,
+	228,
+	191,
+	157,
+	232,
+	175,
+	129,
+	229,
+	155,
+	190,
+	228,
+	185,
+	166,
+	230,
+	156,
+	137,
+	230,
+	149,
+	136,
+	230,
+	181,
+	139,
+	232,
+	175,
+	149,
+	231,
+	167,
+	187,
+	229,
+	138,
+	168,
+	230,
+	137,
+	141,
+	232,
+	131,
+	189,
+	229,
+	134,
+	179,
+	229,
+	174,
+	154,
+	232,
+	130,
+	161,
+	231,
+	165,
+	168,
+	228,
+	184,
+	141,
+	230,
+	150,
+	173,
+	233,
+	156,
+	128,
+	230,
+	177,
+	130,
+	228,
+	184,
+	141,
+	229,
+	190,
+	151,
+	229,
+	138,
+	158,
+	230,
+	179,
+	149,
+	228,
+	185,
+	139,
+	233,
+	151,
+	180,
+	233,
+	135,
+	135,
+	231,
+	148,
+	168,
+	232,
+	144,
+	165,
+	233,
+	148,
+	128,
+	230,
+	138,
+	149,
+	232,
+	175,
+	137,
+	231,
+	155,
+	174,
+	230,
+	160,
+	135,
+	231,
+	136,
+	177,
+	230,
+	131,
+	133,
+	230,
+	145,
+	132,
+	229,
+	189,
+	177,
+	230,
+	156,
+	137,
+	228,
+	186,
+	155,
+	232,
+	164,
+	135,
+	232,
+	163,
+	189,
+	230,
+	150,
+	135,
+	229,
+	173,
+	166,
+	230,
+	156,
+	186,
+	228,
+	188,
+	154,
+	230,
+	149,
+	176,
+	229,
+	173,
+	151,
+	232,
+	163,
+	133,
+	228,
+	191,
+	174,
+	232,
+	180,
+	173,
+	231,
+	137,
+	169,
+	229,
+	134,
+	156,
+	230,
+	157,
+	145,
+	229,
+	133,
+	168,
+	233,
+	157,
+	162,
+	231,
+	178,
+	190,
+	229,
+	147,
+	129,
+	229,
+	133,
+	182,
+	229,
+	174,
+	158,
+	228,
+	186,
+	139,
+	230,
+	131,
+	133,
+	230,
+	176,
+	180,
+	229,
+	185,
+	179,
+	230,
+	143,
+	144,
+	231,
+	164,
+	186,
+	228,
+	184,
+	138,
+	229,
+	184,
+	130,
+	232,
+	176,
+	162,
+	232,
+	176,
+	162,
+	230,
+	153,
+	174,
+	233,
+	128,
+	154,
+	230,
+	149,
+	153,
+	229,
+	184,
+	136,
+	228,
+	184,
+	138,
+	228,
+	188,
+	160,
+	231,
+	177,
+	187,
+	229,
+	136,
+	171,
+	230,
+	173,
+	140,
+	230,
+	155,
+	178,
+	230,
+	139,
+	165,
+	230,
+	156,
+	137,
+	229,
+	136,
+	155,
+	230,
+	150,
+	176,
+	233,
+	133,
+	141,
+	228,
+	187,
+	182,
+	229,
+	143,
+	170,
+	232,
+	166,
+	129,
+	230,
+	151,
+	182,
+	228,
+	187,
+	163,
+	232,
+	179,
+	135,
+	232,
+	168,
+	138,
+	232,
+	190,
+	190,
+	229,
+	136,
+	176,
+	228,
+	186,
+	186,
+	231,
+	148,
+	159,
+	232,
+	174,
+	162,
+	233,
+	152,
+	133,
+	232,
+	128,
+	129,
+	229,
+	184,
+	136,
+	229,
+	177,
+	149,
+	231,
+	164,
+	186,
+	229,
+	191,
+	131,
+	231,
+	144,
+	134,
+	232,
+	180,
+	180,
+	229,
+	173,
+	144,
+	231,
+	182,
+	178,
+	231,
+	171,
+	153,
+	228,
+	184,
+	187,
+	233,
+	161,
+	140,
+	232,
+	135,
+	170,
+	231,
+	132,
+	182,
+	231,
+	186,
+	167,
+	229,
+	136,
+	171,
+	231,
+	174,
+	128,
+	229,
+	141,
+	149,
+	230,
+	148,
+	185,
+	233,
+	157,
+	169,
+	233,
+	130,
+	163,
+	228,
+	186,
+	155,
+	230,
+	157,
+	165,
+	232,
+	175,
+	180,
+	230,
+	137,
+	147,
+	229,
+	188,
+	128,
+	228,
+	187,
+	163,
+	231,
+	160,
+	129,
+	229,
+	136,
+	160,
+	233,
+	153,
+	164,
+	232,
+	175,
+	129,
+	229,
+	136,
+	184,
+	232,
+	138,
+	130,
+	231,
+	155,
+	174,
+	233,
+	135,
+	141,
+	231,
+	130,
+	185,
+	230,
+	172,
+	161,
+	230,
+	149,
+	184,
+	229,
+	164,
+	154,
+	229,
+	176,
+	145,
+	232,
+	167,
+	132,
+	229,
+	136,
+	146,
+	232,
+	181,
+	132,
+	233,
+	135,
+	145,
+	230,
+	137,
+	190,
+	229,
+	136,
+	176,
+	228,
+	187,
+	165,
+	229,
+	144,
+	142,
+	229,
+	164,
+	167,
+	229,
+	133,
+	168,
+	228,
+	184,
+	187,
+	233,
+	161,
+	181,
+	230,
+	156,
+	128,
+	228,
+	189,
+	179,
+	229,
+	155,
+	158,
+	231,
+	173,
+	148,
+	229,
+	164,
+	169,
+	228,
+	184,
+	139,
+	228,
+	191,
+	157,
+	233,
+	154,
+	156,
+	231,
+	142,
+	176,
+	228,
+	187,
+	163,
+	230,
+	163,
+	128,
+	230,
+	159,
+	165,
+	230,
+	138,
+	149,
+	231,
+	165,
+	168,
+	229,
+	176,
+	143,
+	230,
+	151,
+	182,
+	230,
+	178,
+	146,
+	230,
+	156,
+	137,
+	230,
+	173,
+	163,
+	229,
+	184,
+	184,
+	231,
+	148,
+	154,
+	232,
+	135,
+	179,
+	228,
+	187,
+	163,
+	231,
+	144,
+	134,
+	231,
+	155,
+	174,
+	229,
+	189,
+	149,
+	229,
+	133,
+	172,
+	229,
+	188,
+	128,
+	229,
+	164,
+	141,
+	229,
+	136,
+	182,
+	233,
+	135,
+	145,
+	232,
+	158,
+	141,
+	229,
+	185,
+	184,
+	231,
+	166,
+	143,
+	231,
+	137,
+	136,
+	230,
+	156,
+	172,
+	229,
+	189,
+	162,
+	230,
+	136,
+	144,
+	229,
+	135,
+	134,
+	229,
+	164,
+	135,
+	232,
+	161,
+	140,
+	230,
+	131,
+	133,
+	229,
+	155,
+	158,
+	229,
+	136,
+	176,
+	230,
+	128,
+	157,
+	230,
+	131,
+	179,
+	230,
+	128,
+	142,
+	230,
+	160,
+	183,
+	229,
+	141,
+	143,
+	232,
+	174,
+	174,
+	232,
+	174,
+	164,
+	232,
+	175,
+	129,
+	230,
+	156,
+	128,
+	229,
+	165,
+	189,
+	228,
+	186,
+	167,
+	231,
+	148,
+	159,
+	230,
+	140,
+	137,
+	231,
+	133,
+	167,
+	230,
+	156,
+	141,
+	232,
+	163,
+	133,
+	229,
+	185,
+	191,
+	228,
+	184,
+	156,
+	229,
+	138,
+	168,
+	230,
+	188,
+	171,
+	233,
+	135,
+	135,
+	232,
+	180,
+	173,
+	230,
+	150,
+	176,
+	230,
+	137,
+	139,
+	231,
+	187,
+	132,
+	229,
+	155,
+	190,
+	233,
+	157,
+	162,
+	230,
+	157,
+	191,
+	229,
+	143,
+	130,
+	232,
+	128,
+	131,
+	230,
+	148,
+	191,
+	230,
+	178,
+	187,
+	229,
+	174,
+	185,
+	230,
+	152,
+	147,
+	229,
+	164,
+	169,
+	229,
+	156,
+	176,
+	229,
+	138,
+	170,
+	229,
+	138,
+	155,
+	228,
+	186,
+	186,
+	228,
+	187,
+	172,
+	229,
+	141,
+	135,
+	231,
+	186,
+	167,
+	233,
+	128,
+	159,
+	229,
+	186,
+	166,
+	228,
+	186,
+	186,
+	231,
+	137,
+	169,
+	232,
+	176,
+	131,
+	230,
+	149,
+	180,
+	230,
+	181,
+	129,
+	232,
+	161,
+	140,
+	233,
+	128,
+	160,
+	230,
+	136,
+	144,
+	230,
+	150,
+	135,
+	229,
+	173,
+	151,
+	233,
+	159,
+	169,
+	229,
+	155,
+	189,
+	232,
+	180,
+	184,
+	230,
+	152,
+	147,
+	229,
+	188,
+	128,
+	229,
+	177,
+	149,
+	231,
+	155,
+	184,
+	233,
+	151,
+	156,
+	232,
+	161,
+	168,
+	231,
+	142,
+	176,
+	229,
+	189,
+	177,
+	232,
+	167,
+	134,
+	229,
+	166,
+	130,
+	230,
+	173,
+	164,
+	231,
+	190,
+	142,
+	229,
+	174,
+	185,
+	229,
+	164,
+	167,
+	229,
+	176,
+	143,
+	230,
+	138,
+	165,
+	233,
+	129,
+	147,
+	230,
+	157,
+	161,
+	230,
+	172,
+	190,
+	229,
+	191,
+	131,
+	230,
+	131,
+	133,
+	232,
+	174,
+	184,
+	229,
+	164,
+	154,
+	230,
+	179,
+	149,
+	232,
+	167,
+	132,
+	229,
+	174,
+	182,
+	229,
+	177,
+	133,
+	228,
+	185,
+	166,
+	229,
+	186,
+	151,
+	232,
+	191,
+	158,
+	230,
+	142,
+	165,
+	231,
+	171,
+	139,
+	229,
+	141,
+	179,
+	228,
+	184,
+	190,
+	230,
+	138,
+	165,
+	230,
+	138,
+	128,
+	229,
+	183,
+	167,
+	229,
+	165,
+	165,
+	232,
+	191,
+	144,
+	231,
+	153,
+	187,
+	229,
+	133,
+	165,
+	228,
+	187,
+	165,
+	230,
+	157,
+	165,
+	231,
+	144,
+	134,
+	232,
+	174,
+	186,
+	228,
+	186,
+	139,
+	228,
+	187,
+	182,
+	232,
+	135,
+	170,
+	231,
+	148,
+	177,
+	228,
+	184,
+	173,
+	229,
+	141,
+	142,
+	229,
+	138,
+	158,
+	229,
+	133,
+	172,
+	229,
+	166,
+	136,
+	229,
+	166,
+	136,
+	231,
+	156,
+	159,
+	230,
+	173,
+	163,
+	228,
+	184,
+	141,
+	233,
+	148,
+	153,
+	229,
+	133,
+	168,
+	230,
+	150,
+	135,
+	229,
+	144,
+	136,
+	229,
+	144,
+	140,
+	228,
+	187,
+	183,
+	229,
+	128,
+	188,
+	229,
+	136,
+	171,
+	228,
+	186,
+	186,
+	231,
+	155,
+	145,
+	231,
+	157,
+	163,
+	229,
+	133,
+	183,
+	228,
+	189,
+	147,
+	228,
+	184,
+	150,
+	231,
+	186,
+	170,
+	229,
+	155,
+	162,
+	233,
+	152,
+	159,
+	229,
+	136,
+	155,
+	228,
+	184,
+	154,
+	230,
+	137,
+	191,
+	230,
+	139,
+	133,
+	229,
+	162,
+	158,
+	233,
+	149,
+	191,
+	230,
+	156,
+	137,
+	228,
+	186,
+	186,
+	228,
+	191,
+	157,
+	230,
+	140,
+	129,
+	229,
+	149,
+	134,
+	229,
+	174,
+	182,
+	231,
+	187,
+	180,
+	228,
+	191,
+	174,
+	229,
+	143,
+	176,
+	230,
+	185,
+	190,
+	229,
+	183,
+	166,
+	229,
+	143,
+	179,
+	232,
+	130,
+	161,
+	228,
+	187,
+	189,
+	231,
+	173,
+	148,
+	230,
+	161,
+	136,
+	229,
+	174,
+	158,
+	233,
+	153,
+	133,
+	231,
+	148,
+	181,
+	228,
+	191,
+	161,
+	231,
+	187,
+	143,
+	231,
+	144,
+	134,
+	231,
+	148,
+	159,
+	229,
+	145,
+	189,
+	229,
+	174,
+	163,
+	228,
+	188,
+	160,
+	228,
+	187,
+	187,
+	229,
+	138,
+	161,
+	230,
+	173,
+	163,
+	229,
+	188,
+	143,
+	231,
+	137,
+	185,
+	232,
+	137,
+	178,
+	228,
+	184,
+	139,
+	230,
+	157,
+	165,
+	229,
+	141,
+	143,
+	228,
+	188,
+	154,
+	229,
+	143,
+	170,
+	232,
+	131,
+	189,
+	229,
+	189,
+	147,
+	231,
+	132,
+	182,
+	233,
+	135,
+	141,
+	230,
+	150,
+	176,
+	229,
+	133,
+	167,
+	229,
+	174,
+	185,
+	230,
+	140,
+	135,
+	229,
+	175,
+	188,
+	232,
+	191,
+	144,
+	232,
+	161,
+	140,
+	230,
+	151,
+	165,
+	229,
+	191,
+	151,
+	232,
+	179,
+	163,
+	229,
+	174,
+	182,
+	232,
+	182,
+	133,
+	232,
+	191,
+	135,
+	229,
+	156,
+	159,
+	229,
+	156,
+	176,
+	230,
+	181,
+	153,
+	230,
+	177,
+	159,
+	230,
+	148,
+	175,
+	228,
+	187,
+	152,
+	230,
+	142,
+	168,
+	229,
+	135,
+	186,
+	231,
+	171,
+	153,
+	233,
+	149,
+	191,
+	230,
+	157,
+	173,
+	229,
+	183,
+	158,
+	230,
+	137,
+	167,
+	232,
+	161,
+	140,
+	229,
+	136,
+	182,
+	233,
+	128,
+	160,
+	228,
+	185,
+	139,
+	228,
+	184,
+	128,
+	230,
+	142,
+	168,
+	229,
+	185,
+	191,
+	231,
+	142,
+	176,
+	229,
+	156,
+	186,
+	230,
+	143,
+	143,
+	232,
+	191,
+	176,
+	229,
+	143,
+	152,
+	229,
+	140,
+	150,
+	228,
+	188,
+	160,
+	231,
+	187,
+	159,
+	230,
+	173,
+	140,
+	230,
+	137,
+	139,
+	228,
+	191,
+	157,
+	233,
+	153,
+	169,
+	232,
+	175,
+	190,
+	231,
+	168,
+	139,
+	229,
+	140,
+	187,
+	231,
+	150,
+	151,
+	231,
+	187,
+	143,
+	232,
+	191,
+	135,
+	232,
+	191,
+	135,
+	229,
+	142,
+	187,
+	228,
+	185,
+	139,
+	229,
+	137,
+	141,
+	230,
+	148,
+	182,
+	229,
+	133,
+	165,
+	229,
+	185,
+	180,
+	229,
+	186,
+	166,
+	230,
+	157,
+	130,
+	229,
+	191,
+	151,
+	231,
+	190,
+	142,
+	228,
+	184,
+	189,
+	230,
+	156,
+	128,
+	233,
+	171,
+	152,
+	231,
+	153,
+	187,
+	233,
+	153,
+	134,
+	230,
+	156,
+	170,
+	230,
+	157,
+	165,
+	229,
+	138,
+	160,
+	229,
+	183,
+	165,
+	229,
+	133,
+	141,
+	232,
+	180,
+	163,
+	230,
+	149,
+	153,
+	231,
+	168,
+	139,
+	231,
+	137,
+	136,
+	229,
+	157,
+	151,
+	232,
+	186,
+	171,
+	228,
+	189,
+	147,
+	233,
+	135,
+	141,
+	229,
+	186,
+	134,
+	229,
+	135,
+	186,
+	229,
+	148,
+	174,
+	230,
+	136,
+	144,
+	230,
+	156,
+	172,
+	229,
+	189,
+	162,
+	229,
+	188,
+	143,
+	229,
+	156,
+	159,
+	232,
+	177,
+	134,
+	229,
+	135,
+	186,
+	229,
+	131,
+	185,
+	228,
+	184,
+	156,
+	230,
+	150,
+	185,
+	233,
+	130,
+	174,
+	231,
+	174,
+	177,
+	229,
+	141,
+	151,
+	228,
+	186,
+	172,
+	230,
+	177,
+	130,
+	232,
+	129,
+	140,
+	229,
+	143,
+	150,
+	229,
+	190,
+	151,
+	232,
+	129,
+	140,
+	228,
+	189,
+	141,
+	231,
+	155,
+	184,
+	228,
+	191,
+	161,
+	233,
+	161,
+	181,
+	233,
+	157,
+	162,
+	229,
+	136,
+	134,
+	233,
+	146,
+	159,
+	231,
+	189,
+	145,
+	233,
+	161,
+	181,
+	231,
+	161,
+	174,
+	229,
+	174,
+	154,
+	229,
+	155,
+	190,
+	228,
+	190,
+	139,
+	231,
+	189,
+	145,
+	229,
+	157,
+	128,
+	231,
+	167,
+	175,
+	230,
+	158,
+	129,
+	233,
+	148,
+	153,
+	232,
+	175,
+	175,
+	231,
+	155,
+	174,
+	231,
+	154,
+	132,
+	229,
+	174,
+	157,
+	232,
+	180,
+	157,
+	230,
+	156,
+	186,
+	229,
+	133,
+	179,
+	233,
+	163,
+	142,
+	233,
+	153,
+	169,
+	230,
+	142,
+	136,
+	230,
+	157,
+	131,
+	231,
+	151,
+	133,
+	230,
+	175,
+	146,
+	229,
+	174,
+	160,
+	231,
+	137,
+	169,
+	233,
+	153,
+	164,
+	228,
+	186,
+	134,
+	232,
+	169,
+	149,
+	232,
+	171,
+	150,
+	231,
+	150,
+	190,
+	231,
+	151,
+	133,
+	229,
+	143,
+	138,
+	230,
+	151,
+	182,
+	230,
+	177,
+	130,
+	232,
+	180,
+	173,
+	231,
+	171,
+	153,
+	231,
+	130,
+	185,
+	229,
+	132,
+	191,
+	231,
+	171,
+	165,
+	230,
+	175,
+	143,
+	229,
+	164,
+	169,
+	228,
+	184,
+	173,
+	229,
+	164,
+	174,
+	232,
+	174,
+	164,
+	232,
+	175,
+	134,
+	230,
+	175,
+	143,
+	228,
+	184,
+	170,
+	229,
+	164,
+	169,
+	230,
+	180,
+	165,
+	229,
+	173,
+	151,
+	228,
+	189,
+	147,
+	229,
+	143,
+	176,
+	231,
+	129,
+	163,
+	231,
+	187,
+	180,
+	230,
+	138,
+	164,
+	230,
+	156,
+	172,
+	233,
+	161,
+	181,
+	228,
+	184,
+	170,
+	230,
+	128,
+	167,
+	229,
+	174,
+	152,
+	230,
+	150,
+	185,
+	229,
+	184,
+	184,
+	232,
+	167,
+	129,
+	231,
+	155,
+	184,
+	230,
+	156,
+	186,
+	230,
+	136,
+	152,
+	231,
+	149,
+	165,
+	229,
+	186,
+	148,
+	229,
+	189,
+	147,
+	229,
+	190,
+	139,
+	229,
+	184,
+	136,
+	230,
+	150,
+	185,
+	228,
+	190,
+	191,
+	230,
+	160,
+	161,
+	229,
+	155,
+	173,
+	232,
+	130,
+	161,
+	229,
+	184,
+	130,
+	230,
+	136,
+	191,
+	229,
+	177,
+	139,
+	230,
+	160,
+	143,
+	231,
+	155,
+	174,
+	229,
+	145,
+	152,
+	229,
+	183,
+	165,
+	229,
+	175,
+	188,
+	232,
+	135,
+	180,
+	231,
+	170,
+	129,
+	231,
+	132,
+	182,
+	233,
+	129,
+	147,
+	229,
+	133,
+	183,
+	230,
+	156,
+	172,
+	231,
+	189,
+	145,
+	231,
+	187,
+	147,
+	229,
+	144,
+	136,
+	230,
+	161,
+	163,
+	230,
+	161,
+	136,
+	229,
+	138,
+	179,
+	229,
+	138,
+	168,
+	229,
+	143,
+	166,
+	229,
+	164,
+	150,
+	231,
+	190,
+	142,
+	229,
+	133,
+	131,
+	229,
+	188,
+	149,
+	232,
+	181,
+	183,
+	230,
+	148,
+	185,
+	229,
+	143,
+	152,
+	231,
+	172,
+	172,
+	229,
+	155,
+	155,
+	228,
+	188,
+	154,
+	232,
+	174,
+	161,
+	232,
+	170,
+	170,
+	230,
+	152,
+	142,
+	233,
+	154,
+	144,
+	231,
+	167,
+	129,
+	229,
+	174,
+	157,
+	229,
+	174,
+	157,
+	232,
+	167,
+	132,
+	232,
+	140,
+	131,
+	230,
+	182,
+	136,
+	232,
+	180,
+	185,
+	229,
+	133,
+	177,
+	229,
+	144,
+	140,
+	229,
+	191,
+	152,
+	232,
+	174,
+	176,
+	228,
+	189,
+	147,
+	231,
+	179,
+	187,
+	229,
+	184,
+	166,
+	230,
+	157,
+	165,
+	229,
+	144,
+	141,
+	229,
+	173,
+	151,
+	231,
+	153,
+	188,
+	232,
+	161,
+	168,
+	229,
+	188,
+	128,
+	230,
+	148,
+	190,
+	229,
+	138,
+	160,
+	231,
+	155,
+	159,
+	229,
+	143,
+	151,
+	229,
+	136,
+	176,
+	228,
+	186,
+	140,
+	230,
+	137,
+	139,
+	229,
+	164,
+	167,
+	233,
+	135,
+	143,
+	230,
+	136,
+	144,
+	228,
+	186,
+	186,
+	230,
+	149,
+	176,
+	233,
+	135,
+	143,
+	229,
+	133,
+	177,
+	228,
+	186,
+	171,
+	229,
+	140,
+	186,
+	229,
+	159,
+	159,
+	229,
+	165,
+	179,
+	229,
+	173,
+	169,
+	229,
+	142,
+	159,
+	229,
+	136,
+	153,
+	230,
+	137,
+	128,
+	229,
+	156,
+	168,
+	231,
+	187,
+	147,
+	230,
+	157,
+	159,
+	233,
+	128,
+	154,
+	228,
+	191,
+	161,
+	232,
+	182,
+	133,
+	231,
+	186,
+	167,
+	233,
+	133,
+	141,
+	231,
+	189,
+	174,
+	229,
+	189,
+	147,
+	230,
+	151,
+	182,
+	228,
+	188,
+	152,
+	231,
+	167,
+	128,
+	230,
+	128,
+	167,
+	230,
+	132,
+	159,
+	230,
+	136,
+	191,
+	228,
+	186,
+	167,
+	233,
+	129,
+	138,
+	230,
+	136,
+	178,
+	229,
+	135,
+	186,
+	229,
+	143,
+	163,
+	230,
+	143,
+	144,
+	228,
+	186,
+	164,
+	229,
+	176,
+	177,
+	228,
+	184,
+	154,
+	228,
+	191,
+	157,
+	229,
+	129,
+	165,
+	231,
+	168,
+	139,
+	229,
+	186,
+	166,
+	229,
+	143,
+	130,
+	230,
+	149,
+	176,
+	228,
+	186,
+	139,
+	228,
+	184,
+	154,
+	230,
+	149,
+	180,
+	228,
+	184,
+	170,
+	229,
+	177,
+	177,
+	228,
+	184,
+	156,
+	230,
+	131,
+	133,
+	230,
+	132,
+	159,
+	231,
+	137,
+	185,
+	230,
+	174,
+	138,
+	229,
+	136,
+	134,
+	233,
+	161,
+	158,
+	230,
+	144,
+	156,
+	229,
+	176,
+	139,
+	229,
+	177,
+	158,
+	228,
+	186,
+	142,
+	233,
+	151,
+	168,
+	230,
+	136,
+	183,
+	232,
+	180,
+	162,
+	229,
+	138,
+	161,
+	229,
+	163,
+	176,
+	233,
+	159,
+	179,
+	229,
+	143,
+	138,
+	229,
+	133,
+	182,
+	232,
+	180,
+	162,
+	231,
+	187,
+	143,
+	229,
+	157,
+	154,
+	230,
+	140,
+	129,
+	229,
+	185,
+	178,
+	233,
+	131,
+	168,
+	230,
+	136,
+	144,
+	231,
+	171,
+	139,
+	229,
+	136,
+	169,
+	231,
+	155,
+	138,
+	232,
+	128,
+	131,
+	232,
+	153,
+	145,
+	230,
+	136,
+	144,
+	233,
+	131,
+	189,
+	229,
+	140,
+	133,
+	232,
+	163,
+	133,
+	231,
+	148,
+	168,
+	230,
+	136,
+	182,
+	230,
+	175,
+	148,
+	232,
+	181,
+	155,
+	230,
+	150,
+	135,
+	230,
+	152,
+	142,
+	230,
+	139,
+	155,
+	229,
+	149,
+	134,
+	229,
+	174,
+	140,
+	230,
+	149,
+	180,
+	231,
+	156,
+	159,
+	230,
+	152,
+	175,
+	231,
+	156,
+	188,
+	231,
+	157,
+	155,
+	228,
+	188,
+	153,
+	228,
+	188,
+	180,
+	229,
+	168,
+	129,
+	230,
+	156,
+	155,
+	233,
+	162,
+	134,
+	229,
+	159,
+	159,
+	229,
+	141,
+	171,
+	231,
+	148,
+	159,
+	228,
+	188,
+	152,
+	230,
+	131,
+	160,
+	232,
+	171,
+	150,
+	229,
+	163,
+	135,
+	229,
+	133,
+	172,
+	229,
+	133,
+	177,
+	232,
+	137,
+	175,
+	229,
+	165,
+	189,
+	229,
+	133,
+	133,
+	229,
+	136,
+	134,
+	231,
+	172,
+	166,
+	229,
+	144,
+	136,
+	233,
+	153,
+	132,
+	228,
+	187,
+	182,
+	231,
+	137,
+	185,
+	231,
+	130,
+	185,
+	228,
+	184,
+	141,
+	229,
+	143,
+	175,
+	232,
+	139,
+	177,
+	230,
+	150,
+	135,
+	232,
+	181,
+	132,
+	228,
+	186,
+	167,
+	230,
+	160,
+	185,
+	230,
+	156,
+	172,
+	230,
+	152,
+	142,
+	230,
+	152,
+	190,
+	229,
+	175,
+	134,
+	231,
+	162,
+	188,
+	229,
+	133,
+	172,
+	228,
+	188,
+	151,
+	230,
+	176,
+	145,
+	230,
+	151,
+	143,
+	230,
+	155,
+	180,
+	229,
+	138,
+	160,
+	228,
+	186,
+	171,
+	229,
+	143,
+	151,
+	229,
+	144,
+	140,
+	229,
+	173,
+	166,
+	229,
+	144,
+	175,
+	229,
+	138,
+	168,
+	233,
+	128,
+	130,
+	229,
+	144,
+	136,
+	229,
+	142,
+	159,
+	230,
+	157,
+	165,
+	233,
+	151,
+	174,
+	231,
+	173,
+	148,
+	230,
+	156,
+	172,
+	230,
+	150,
+	135,
+	231,
+	190,
+	142,
+	233,
+	163,
+	159,
+	231,
+	187,
+	191,
+	232,
+	137,
+	178,
+	231,
+	168,
+	179,
+	229,
+	174,
+	154,
+	231,
+	187,
+	136,
+	228,
+	186,
+	142,
+	231,
+	148,
+	159,
+	231,
+	137,
+	169,
+	228,
+	190,
+	155,
+	230,
+	177,
+	130,
+	230,
+	144,
+	156,
+	231,
+	139,
+	144,
+	229,
+	138,
+	155,
+	233,
+	135,
+	143,
+	228,
+	184,
+	165,
+	233,
+	135,
+	141,
+	230,
+	176,
+	184,
+	232,
+	191,
+	156,
+	229,
+	134,
+	153,
+	231,
+	156,
+	159,
+	230,
+	156,
+	137,
+	233,
+	153,
+	144,
+	231,
+	171,
+	158,
+	228,
+	186,
+	137,
+	229,
+	175,
+	185,
+	232,
+	177,
+	161,
+	232,
+	180,
+	185,
+	231,
+	148,
+	168,
+	228,
+	184,
+	141,
+	229,
+	165,
+	189,
+	231,
+	187,
+	157,
+	229,
+	175,
+	185,
+	229,
+	141,
+	129,
+	229,
+	136,
+	134,
+	228,
+	191,
+	131,
+	232,
+	191,
+	155,
+	231,
+	130,
+	185,
+	232,
+	175,
+	132,
+	229,
+	189,
+	177,
+	233,
+	159,
+	179,
+	228,
+	188,
+	152,
+	229,
+	138,
+	191,
+	228,
+	184,
+	141,
+	229,
+	176,
+	145,
+	230,
+	172,
+	163,
+	232,
+	181,
+	143,
+	229,
+	185,
+	182,
+	228,
+	184,
+	148,
+	230,
+	156,
+	137,
+	231,
+	130,
+	185,
+	230,
+	150,
+	185,
+	229,
+	144,
+	145,
+	229,
+	133,
+	168,
+	230,
+	150,
+	176,
+	228,
+	191,
+	161,
+	231,
+	148,
+	168,
+	232,
+	174,
+	190,
+	230,
+	150,
+	189,
+	229,
+	189,
+	162,
+	232,
+	177,
+	161,
+	232,
+	181,
+	132,
+	230,
+	160,
+	188,
+	231,
+	170,
+	129,
+	231,
+	160,
+	180,
+	233,
+	154,
+	143,
+	231,
+	157,
+	128,
+	233,
+	135,
+	141,
+	229,
+	164,
+	167,
+	228,
+	186,
+	142,
+	230,
+	152,
+	175,
+	230,
+	175,
+	149,
+	228,
+	184,
+	154,
+	230,
+	153,
+	186,
+	232,
+	131,
+	189,
+	229,
+	140,
+	150,
+	229,
+	183,
+	165,
+	229,
+	174,
+	140,
+	231,
+	190,
+	142,
+	229,
+	149,
+	134,
+	229,
+	159,
+	142,
+	231,
+	187,
+	159,
+	228,
+	184,
+	128,
+	229,
+	135,
+	186,
+	231,
+	137,
+	136,
+	230,
+	137,
+	147,
+	233,
+	128,
+	160,
+	231,
+	148,
+	162,
+	229,
+	147,
+	129,
+	230,
+	166,
+	130,
+	229,
+	134,
+	181,
+	231,
+	148,
+	168,
+	228,
+	186,
+	142,
+	228,
+	191,
+	157,
+	231,
+	149,
+	153,
+	229,
+	155,
+	160,
+	231,
+	180,
+	160,
+	228,
+	184,
+	173,
+	229,
+	156,
+	139,
+	229,
+	173,
+	152,
+	229,
+	130,
+	168,
+	232,
+	180,
+	180,
+	229,
+	155,
+	190,
+	230,
+	156,
+	128,
+	230,
+	132,
+	155,
+	233,
+	149,
+	191,
+	230,
+	156,
+	159,
+	229,
+	143,
+	163,
+	228,
+	187,
+	183,
+	231,
+	144,
+	134,
+	232,
+	180,
+	162,
+	229,
+	159,
+	186,
+	229,
+	156,
+	176,
+	229,
+	174,
+	137,
+	230,
+	142,
+	146,
+	230,
+	173,
+	166,
+	230,
+	177,
+	137,
+	233,
+	135,
+	140,
+	233,
+	157,
+	162,
+	229,
+	136,
+	155,
+	229,
+	187,
+	186,
+	229,
+	164,
+	169,
+	231,
+	169,
+	186,
+	233,
+	166,
+	150,
+	229,
+	133,
+	136,
+	229,
+	174,
+	140,
+	229,
+	150,
+	132,
+	233,
+	169,
+	177,
+	229,
+	138,
+	168,
+	228,
+	184,
+	139,
+	233,
+	157,
+	162,
+	228,
+	184,
+	141,
+	229,
+	134,
+	141,
+	232,
+	175,
+	154,
+	228,
+	191,
+	161,
+	230,
+	132,
+	143,
+	228,
+	185,
+	137,
+	233,
+	152,
+	179,
+	229,
+	133,
+	137,
+	232,
+	139,
+	177,
+	229,
+	155,
+	189,
+	230,
+	188,
+	130,
+	228,
+	186,
+	174,
+	229,
+	134,
+	155,
+	228,
+	186,
+	139,
+	231,
+	142,
+	169,
+	229,
+	174,
+	182,
+	231,
+	190,
+	164,
+	228,
+	188,
+	151,
+	229,
+	134,
+	156,
+	230,
+	176,
+	145,
+	229,
+	141,
+	179,
+	229,
+	143,
+	175,
+	229,
+	144,
+	141,
+	231,
+	168,
+	177,
+	229,
+	174,
+	182,
+	229,
+	133,
+	183,
+	229,
+	138,
+	168,
+	231,
+	148,
+	187,
+	230,
+	131,
+	179,
+	229,
+	136,
+	176,
+	230,
+	179,
+	168,
+	230,
+	152,
+	142,
+	229,
+	176,
+	143,
+	229,
+	173,
+	166,
+	230,
+	128,
+	167,
+	232,
+	131,
+	189,
+	232,
+	128,
+	131,
+	231,
+	160,
+	148,
+	231,
+	161,
+	172,
+	228,
+	187,
+	182,
+	232,
+	167,
+	130,
+	231,
+	156,
+	139,
+	230,
+	184,
+	133,
+	230,
+	165,
+	154,
+	230,
+	144,
+	158,
+	231,
+	172,
+	145,
+	233,
+	166,
+	150,
+	233,
+	160,
+	129,
+	233,
+	187,
+	132,
+	233,
+	135,
+	145,
+	233,
+	128,
+	130,
+	231,
+	148,
+	168,
+	230,
+	177,
+	159,
+	232,
+	139,
+	143,
+	231,
+	156,
+	159,
+	229,
+	174,
+	158,
+	228,
+	184,
+	187,
+	231,
+	174,
+	161,
+	233,
+	152,
+	182,
+	230,
+	174,
+	181,
+	232,
+	168,
+	187,
+	229,
+	134,
+	138,
+	231,
+	191,
+	187,
+	232,
+	175,
+	145,
+	230,
+	157,
+	131,
+	229,
+	136,
+	169,
+	229,
+	129,
+	154,
+	229,
+	165,
+	189,
+	228,
+	188,
+	188,
+	228,
+	185,
+	142,
+	233,
+	128,
+	154,
+	232,
+	174,
+	175,
+	230,
+	150,
+	189,
+	229,
+	183,
+	165,
+	231,
+	139,
+	128,
+	230,
+	133,
+	139,
+	228,
+	185,
+	159,
+	232,
+	174,
+	184,
+	231,
+	142,
+	175,
+	228,
+	191,
+	157,
+	229,
+	159,
+	185,
+	229,
+	133,
+	187,
+	230,
+	166,
+	130,
+	229,
+	191,
+	181,
+	229,
+	164,
+	167,
+	229,
+	158,
+	139,
+	230,
+	156,
+	186,
+	231,
+	165,
+	168,
+	231,
+	144,
+	134,
+	232,
+	167,
+	163,
+	229,
+	140,
+	191,
+	229,
+	144,
+	141,
+	99,
+	117,
+	97,
+	110,
+	100,
+	111,
+	101,
+	110,
+	118,
+	105,
+	97,
+	114,
+	109,
+	97,
+	100,
+	114,
+	105,
+	100,
+	98,
+	117,
+	115,
+	99,
+	97,
+	114,
+	105,
+	110,
+	105,
+	99,
+	105,
+	111,
+	116,
+	105,
+	101,
+	109,
+	112,
+	111,
+	112,
+	111,
+	114,
+	113,
+	117,
+	101,
+	99,
+	117,
+	101,
+	110,
+	116,
+	97,
+	101,
+	115,
+	116,
+	97,
+	100,
+	111,
+	112,
+	117,
+	101,
+	100,
+	101,
+	110,
+	106,
+	117,
+	101,
+	103,
+	111,
+	115,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	101,
+	115,
+	116,
+	195,
+	161,
+	110,
+	110,
+	111,
+	109,
+	98,
+	114,
+	101,
+	116,
+	105,
+	101,
+	110,
+	101,
+	110,
+	112,
+	101,
+	114,
+	102,
+	105,
+	108,
+	109,
+	97,
+	110,
+	101,
+	114,
+	97,
+	97,
+	109,
+	105,
+	103,
+	111,
+	115,
+	99,
+	105,
+	117,
+	100,
+	97,
+	100,
+	99,
+	101,
+	110,
+	116,
+	114,
+	111,
+	97,
+	117,
+	110,
+	113,
+	117,
+	101,
+	112,
+	117,
+	101,
+	100,
+	101,
+	115,
+	100,
+	101,
+	110,
+	116,
+	114,
+	111,
+	112,
+	114,
+	105,
+	109,
+	101,
+	114,
+	112,
+	114,
+	101,
+	99,
+	105,
+	111,
+	115,
+	101,
+	103,
+	195,
+	186,
+	110,
+	98,
+	117,
+	101,
+	110,
+	111,
+	115,
+	118,
+	111,
+	108,
+	118,
+	101,
+	114,
+	112,
+	117,
+	110,
+	116,
+	111,
+	115,
+	115,
+	101,
+	109,
+	97,
+	110,
+	97,
+	104,
+	97,
+	98,
+	195,
+	173,
+	97,
+	97,
+	103,
+	111,
+	115,
+	116,
+	111,
+	110,
+	117,
+	101,
+	118,
+	111,
+	115,
+	117,
+	110,
+	105,
+	100,
+	111,
+	115,
+	99,
+	97,
+	114,
+	108,
+	111,
+	115,
+	101,
+	113,
+	117,
+	105,
+	112,
+	111,
+	110,
+	105,
+	195,
+	177,
+	111,
+	115,
+	109,
+	117,
+	99,
+	104,
+	111,
+	115,
+	97,
+	108,
+	103,
+	117,
+	110,
+	97,
+	99,
+	111,
+	114,
+	114,
+	101,
+	111,
+	105,
+	109,
+	97,
+	103,
+	101,
+	110,
+	112,
+	97,
+	114,
+	116,
+	105,
+	114,
+	97,
+	114,
+	114,
+	105,
+	98,
+	97,
+	109,
+	97,
+	114,
+	195,
+	173,
+	97,
+	104,
+	111,
+	109,
+	98,
+	114,
+	101,
+	101,
+	109,
+	112,
+	108,
+	101,
+	111,
+	118,
+	101,
+	114,
+	100,
+	97,
+	100,
+	99,
+	97,
+	109,
+	98,
+	105,
+	111,
+	109,
+	117,
+	99,
+	104,
+	97,
+	115,
+	102,
+	117,
+	101,
+	114,
+	111,
+	110,
+	112,
+	97,
+	115,
+	97,
+	100,
+	111,
+	108,
+	195,
+	173,
+	110,
+	101,
+	97,
+	112,
+	97,
+	114,
+	101,
+	99,
+	101,
+	110,
+	117,
+	101,
+	118,
+	97,
+	115,
+	99,
+	117,
+	114,
+	115,
+	111,
+	115,
+	101,
+	115,
+	116,
+	97,
+	98,
+	97,
+	113,
+	117,
+	105,
+	101,
+	114,
+	111,
+	108,
+	105,
+	98,
+	114,
+	111,
+	115,
+	99,
+	117,
+	97,
+	110,
+	116,
+	111,
+	97,
+	99,
+	99,
+	101,
+	115,
+	111,
+	109,
+	105,
+	103,
+	117,
+	101,
+	108,
+	118,
+	97,
+	114,
+	105,
+	111,
+	115,
+	99,
+	117,
+	97,
+	116,
+	114,
+	111,
+	116,
+	105,
+	101,
+	110,
+	101,
+	115,
+	103,
+	114,
+	117,
+	112,
+	111,
+	115,
+	115,
+	101,
+	114,
+	195,
+	161,
+	110,
+	101,
+	117,
+	114,
+	111,
+	112,
+	97,
+	109,
+	101,
+	100,
+	105,
+	111,
+	115,
+	102,
+	114,
+	101,
+	110,
+	116,
+	101,
+	97,
+	99,
+	101,
+	114,
+	99,
+	97,
+	100,
+	101,
+	109,
+	195,
+	161,
+	115,
+	111,
+	102,
+	101,
+	114,
+	116,
+	97,
+	99,
+	111,
+	99,
+	104,
+	101,
+	115,
+	109,
+	111,
+	100,
+	101,
+	108,
+	111,
+	105,
+	116,
+	97,
+	108,
+	105,
+	97,
+	108,
+	101,
+	116,
+	114,
+	97,
+	115,
+	97,
+	108,
+	103,
+	195,
+	186,
+	110,
+	99,
+	111,
+	109,
+	112,
+	114,
+	97,
+	99,
+	117,
+	97,
+	108,
+	101,
+	115,
+	101,
+	120,
+	105,
+	115,
+	116,
+	101,
+	99,
+	117,
+	101,
+	114,
+	112,
+	111,
+	115,
+	105,
+	101,
+	110,
+	100,
+	111,
+	112,
+	114,
+	101,
+	110,
+	115,
+	97,
+	108,
+	108,
+	101,
+	103,
+	97,
+	114,
+	118,
+	105,
+	97,
+	106,
+	101,
+	115,
+	100,
+	105,
+	110,
+	101,
+	114,
+	111,
+	109,
+	117,
+	114,
+	99,
+	105,
+	97,
+	112,
+	111,
+	100,
+	114,
+	195,
+	161,
+	112,
+	117,
+	101,
+	115,
+	116,
+	111,
+	100,
+	105,
+	97,
+	114,
+	105,
+	111,
+	112,
+	117,
+	101,
+	98,
+	108,
+	111,
+	113,
+	117,
+	105,
+	101,
+	114,
+	101,
+	109,
+	97,
+	110,
+	117,
+	101,
+	108,
+	112,
+	114,
+	111,
+	112,
+	105,
+	111,
+	99,
+	114,
+	105,
+	115,
+	105,
+	115,
+	99,
+	105,
+	101,
+	114,
+	116,
+	111,
+	115,
+	101,
+	103,
+	117,
+	114,
+	111,
+	109,
+	117,
+	101,
+	114,
+	116,
+	101,
+	102,
+	117,
+	101,
+	110,
+	116,
+	101,
+	99,
+	101,
+	114,
+	114,
+	97,
+	114,
+	103,
+	114,
+	97,
+	110,
+	100,
+	101,
+	101,
+	102,
+	101,
+	99,
+	116,
+	111,
+	112,
+	97,
+	114,
+	116,
+	101,
+	115,
+	109,
+	101,
+	100,
+	105,
+	100,
+	97,
+	112,
+	114,
+	111,
+	112,
+	105,
+	97,
+	111,
+	102,
+	114,
+	101,
+	99,
+	101,
+	116,
+	105,
+	101,
+	114,
+	114,
+	97,
+	101,
+	45,
+	109,
+	97,
+	105,
+	108,
+	118,
+	97,
+	114,
+	105,
+	97,
+	115,
+	102,
+	111,
+	114,
+	109,
+	97,
+	115,
+	102,
+	117,
+	116,
+	117,
+	114,
+	111,
+	111,
+	98,
+	106,
+	101,
+	116,
+	111,
+	115,
+	101,
+	103,
+	117,
+	105,
+	114,
+	114,
+	105,
+	101,
+	115,
+	103,
+	111,
+	110,
+	111,
+	114,
+	109,
+	97,
+	115,
+	109,
+	105,
+	115,
+	109,
+	111,
+	115,
+	195,
+	186,
+	110,
+	105,
+	99,
+	111,
+	99,
+	97,
+	109,
+	105,
+	110,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	115,
+	114,
+	97,
+	122,
+	195,
+	179,
+	110,
+	100,
+	101,
+	98,
+	105,
+	100,
+	111,
+	112,
+	114,
+	117,
+	101,
+	98,
+	97,
+	116,
+	111,
+	108,
+	101,
+	100,
+	111,
+	116,
+	101,
+	110,
+	195,
+	173,
+	97,
+	106,
+	101,
+	115,
+	195,
+	186,
+	115,
+	101,
+	115,
+	112,
+	101,
+	114,
+	111,
+	99,
+	111,
+	99,
+	105,
+	110,
+	97,
+	111,
+	114,
+	105,
+	103,
+	101,
+	110,
+	116,
+	105,
+	101,
+	110,
+	100,
+	97,
+	99,
+	105,
+	101,
+	110,
+	116,
+	111,
+	99,
+	195,
+	161,
+	100,
+	105,
+	122,
+	104,
+	97,
+	98,
+	108,
+	97,
+	114,
+	115,
+	101,
+	114,
+	195,
+	173,
+	97,
+	108,
+	97,
+	116,
+	105,
+	110,
+	97,
+	102,
+	117,
+	101,
+	114,
+	122,
+	97,
+	101,
+	115,
+	116,
+	105,
+	108,
+	111,
+	103,
+	117,
+	101,
+	114,
+	114,
+	97,
+	101,
+	110,
+	116,
+	114,
+	97,
+	114,
+	195,
+	169,
+	120,
+	105,
+	116,
+	111,
+	108,
+	195,
+	179,
+	112,
+	101,
+	122,
+	97,
+	103,
+	101,
+	110,
+	100,
+	97,
+	118,
+	195,
+	173,
+	100,
+	101,
+	111,
+	101,
+	118,
+	105,
+	116,
+	97,
+	114,
+	112,
+	97,
+	103,
+	105,
+	110,
+	97,
+	109,
+	101,
+	116,
+	114,
+	111,
+	115,
+	106,
+	97,
+	118,
+	105,
+	101,
+	114,
+	112,
+	97,
+	100,
+	114,
+	101,
+	115,
+	102,
+	195,
+	161,
+	99,
+	105,
+	108,
+	99,
+	97,
+	98,
+	101,
+	122,
+	97,
+	195,
+	161,
+	114,
+	101,
+	97,
+	115,
+	115,
+	97,
+	108,
+	105,
+	100,
+	97,
+	101,
+	110,
+	118,
+	195,
+	173,
+	111,
+	106,
+	97,
+	112,
+	195,
+	179,
+	110,
+	97,
+	98,
+	117,
+	115,
+	111,
+	115,
+	98,
+	105,
+	101,
+	110,
+	101,
+	115,
+	116,
+	101,
+	120,
+	116,
+	111,
+	115,
+	108,
+	108,
+	101,
+	118,
+	97,
+	114,
+	112,
+	117,
+	101,
+	100,
+	97,
+	110,
+	102,
+	117,
+	101,
+	114,
+	116,
+	101,
+	99,
+	111,
+	109,
+	195,
+	186,
+	110,
+	99,
+	108,
+	97,
+	115,
+	101,
+	115,
+	104,
+	117,
+	109,
+	97,
+	110,
+	111,
+	116,
+	101,
+	110,
+	105,
+	100,
+	111,
+	98,
+	105,
+	108,
+	98,
+	97,
+	111,
+	117,
+	110,
+	105,
+	100,
+	97,
+	100,
+	101,
+	115,
+	116,
+	195,
+	161,
+	115,
+	101,
+	100,
+	105,
+	116,
+	97,
+	114,
+	99,
+	114,
+	101,
+	97,
+	100,
+	111,
+	208,
+	180,
+	208,
+	187,
+	209,
+	143,
+	209,
+	135,
+	209,
+	130,
+	208,
+	190,
+	208,
+	186,
+	208,
+	176,
+	208,
+	186,
+	208,
+	184,
+	208,
+	187,
+	208,
+	184,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	208,
+	191,
+	209,
+	128,
+	208,
+	184,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	181,
+	209,
+	137,
+	208,
+	181,
+	209,
+	131,
+	208,
+	182,
+	208,
+	181,
+	208,
+	154,
+	208,
+	176,
+	208,
+	186,
+	208,
+	177,
+	208,
+	181,
+	208,
+	183,
+	208,
+	177,
+	209,
+	139,
+	208,
+	187,
+	208,
+	190,
+	208,
+	189,
+	208,
+	184,
+	208,
+	146,
+	209,
+	129,
+	208,
+	181,
+	208,
+	191,
+	208,
+	190,
+	208,
+	180,
+	208,
+	173,
+	209,
+	130,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	209,
+	135,
+	208,
+	181,
+	208,
+	188,
+	208,
+	189,
+	208,
+	181,
+	209,
+	130,
+	208,
+	187,
+	208,
+	181,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	190,
+	208,
+	189,
+	208,
+	176,
+	208,
+	179,
+	208,
+	180,
+	208,
+	181,
+	208,
+	188,
+	208,
+	189,
+	208,
+	181,
+	208,
+	148,
+	208,
+	187,
+	209,
+	143,
+	208,
+	159,
+	209,
+	128,
+	208,
+	184,
+	208,
+	189,
+	208,
+	176,
+	209,
+	129,
+	208,
+	189,
+	208,
+	184,
+	209,
+	133,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	208,
+	186,
+	209,
+	130,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	208,
+	178,
+	208,
+	190,
+	209,
+	130,
+	209,
+	130,
+	208,
+	176,
+	208,
+	188,
+	208,
+	161,
+	208,
+	168,
+	208,
+	144,
+	208,
+	188,
+	208,
+	176,
+	209,
+	143,
+	208,
+	167,
+	209,
+	130,
+	208,
+	190,
+	208,
+	178,
+	208,
+	176,
+	209,
+	129,
+	208,
+	178,
+	208,
+	176,
+	208,
+	188,
+	208,
+	181,
+	208,
+	188,
+	209,
+	131,
+	208,
+	162,
+	208,
+	176,
+	208,
+	186,
+	208,
+	180,
+	208,
+	178,
+	208,
+	176,
+	208,
+	189,
+	208,
+	176,
+	208,
+	188,
+	209,
+	141,
+	209,
+	130,
+	208,
+	184,
+	209,
+	141,
+	209,
+	130,
+	209,
+	131,
+	208,
+	146,
+	208,
+	176,
+	208,
+	188,
+	209,
+	130,
+	208,
+	181,
+	209,
+	133,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	209,
+	130,
+	209,
+	131,
+	209,
+	130,
+	208,
+	189,
+	208,
+	176,
+	208,
+	180,
+	208,
+	180,
+	208,
+	189,
+	209,
+	143,
+	208,
+	146,
+	208,
+	190,
+	209,
+	130,
+	209,
+	130,
+	209,
+	128,
+	208,
+	184,
+	208,
+	189,
+	208,
+	181,
+	208,
+	185,
+	208,
+	146,
+	208,
+	176,
+	209,
+	129,
+	208,
+	189,
+	208,
+	184,
+	208,
+	188,
+	209,
+	129,
+	208,
+	176,
+	208,
+	188,
+	209,
+	130,
+	208,
+	190,
+	209,
+	130,
+	209,
+	128,
+	209,
+	131,
+	208,
+	177,
+	208,
+	158,
+	208,
+	189,
+	208,
+	184,
+	208,
+	188,
+	208,
+	184,
+	209,
+	128,
+	208,
+	189,
+	208,
+	181,
+	208,
+	181,
+	208,
+	158,
+	208,
+	158,
+	208,
+	158,
+	208,
+	187,
+	208,
+	184,
+	209,
+	134,
+	209,
+	141,
+	209,
+	130,
+	208,
+	176,
+	208,
+	158,
+	208,
+	189,
+	208,
+	176,
+	208,
+	189,
+	208,
+	181,
+	208,
+	188,
+	208,
+	180,
+	208,
+	190,
+	208,
+	188,
+	208,
+	188,
+	208,
+	190,
+	208,
+	185,
+	208,
+	180,
+	208,
+	178,
+	208,
+	181,
+	208,
+	190,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	131,
+	208,
+	180,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	185,
+	224,
+	165,
+	136,
+	224,
+	164,
+	149,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	165,
+	139,
+	224,
+	164,
+	148,
+	224,
+	164,
+	176,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	143,
+	224,
+	164,
+	149,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	173,
+	224,
+	165,
+	128,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	165,
+	139,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	175,
+	224,
+	164,
+	185,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	149,
+	224,
+	164,
+	165,
+	224,
+	164,
+	190,
+	106,
+	97,
+	103,
+	114,
+	97,
+	110,
+	224,
+	164,
+	134,
+	224,
+	164,
+	156,
+	224,
+	164,
+	156,
+	224,
+	165,
+	139,
+	224,
+	164,
+	133,
+	224,
+	164,
+	172,
+	224,
+	164,
+	166,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	164,
+	136,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	151,
+	224,
+	164,
+	143,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	164,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	181,
+	224,
+	164,
+	185,
+	224,
+	164,
+	175,
+	224,
+	165,
+	135,
+	224,
+	164,
+	165,
+	224,
+	165,
+	135,
+	224,
+	164,
+	165,
+	224,
+	165,
+	128,
+	224,
+	164,
+	152,
+	224,
+	164,
+	176,
+	224,
+	164,
+	156,
+	224,
+	164,
+	172,
+	224,
+	164,
+	166,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	164,
+	136,
+	224,
+	164,
+	156,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	136,
+	224,
+	164,
+	168,
+	224,
+	164,
+	143,
+	224,
+	164,
+	185,
+	224,
+	164,
+	176,
+	224,
+	164,
+	137,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	174,
+	224,
+	164,
+	181,
+	224,
+	165,
+	139,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	172,
+	224,
+	164,
+	174,
+	224,
+	164,
+	136,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	147,
+	224,
+	164,
+	176,
+	224,
+	164,
+	134,
+	224,
+	164,
+	174,
+	224,
+	164,
+	172,
+	224,
+	164,
+	184,
+	224,
+	164,
+	173,
+	224,
+	164,
+	176,
+	224,
+	164,
+	172,
+	224,
+	164,
+	168,
+	224,
+	164,
+	154,
+	224,
+	164,
+	178,
+	224,
+	164,
+	174,
+	224,
+	164,
+	168,
+	224,
+	164,
+	134,
+	224,
+	164,
+	151,
+	224,
+	164,
+	184,
+	224,
+	165,
+	128,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	216,
+	185,
+	217,
+	132,
+	217,
+	137,
+	216,
+	165,
+	217,
+	132,
+	217,
+	137,
+	217,
+	135,
+	216,
+	176,
+	216,
+	167,
+	216,
+	162,
+	216,
+	174,
+	216,
+	177,
+	216,
+	185,
+	216,
+	175,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	217,
+	137,
+	217,
+	135,
+	216,
+	176,
+	217,
+	135,
+	216,
+	181,
+	217,
+	136,
+	216,
+	177,
+	216,
+	186,
+	217,
+	138,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	217,
+	134,
+	217,
+	136,
+	217,
+	132,
+	216,
+	167,
+	216,
+	168,
+	217,
+	138,
+	217,
+	134,
+	216,
+	185,
+	216,
+	177,
+	216,
+	182,
+	216,
+	176,
+	217,
+	132,
+	217,
+	131,
+	217,
+	135,
+	217,
+	134,
+	216,
+	167,
+	217,
+	138,
+	217,
+	136,
+	217,
+	133,
+	217,
+	130,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	216,
+	167,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	217,
+	134,
+	216,
+	173,
+	216,
+	170,
+	217,
+	137,
+	217,
+	130,
+	216,
+	168,
+	217,
+	132,
+	217,
+	136,
+	216,
+	173,
+	216,
+	169,
+	216,
+	167,
+	216,
+	174,
+	216,
+	177,
+	217,
+	129,
+	217,
+	130,
+	216,
+	183,
+	216,
+	185,
+	216,
+	168,
+	216,
+	175,
+	216,
+	177,
+	217,
+	131,
+	217,
+	134,
+	216,
+	165,
+	216,
+	176,
+	216,
+	167,
+	217,
+	131,
+	217,
+	133,
+	216,
+	167,
+	216,
+	167,
+	216,
+	173,
+	216,
+	175,
+	216,
+	165,
+	217,
+	132,
+	216,
+	167,
+	217,
+	129,
+	217,
+	138,
+	217,
+	135,
+	216,
+	168,
+	216,
+	185,
+	216,
+	182,
+	217,
+	131,
+	217,
+	138,
+	217,
+	129,
+	216,
+	168,
+	216,
+	173,
+	216,
+	171,
+	217,
+	136,
+	217,
+	133,
+	217,
+	134,
+	217,
+	136,
+	217,
+	135,
+	217,
+	136,
+	216,
+	163,
+	217,
+	134,
+	216,
+	167,
+	216,
+	172,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	217,
+	135,
+	216,
+	167,
+	216,
+	179,
+	217,
+	132,
+	217,
+	133,
+	216,
+	185,
+	217,
+	134,
+	216,
+	175,
+	217,
+	132,
+	217,
+	138,
+	216,
+	179,
+	216,
+	185,
+	216,
+	168,
+	216,
+	177,
+	216,
+	181,
+	217,
+	132,
+	217,
+	137,
+	217,
+	133,
+	217,
+	134,
+	216,
+	176,
+	216,
+	168,
+	217,
+	135,
+	216,
+	167,
+	216,
+	163,
+	217,
+	134,
+	217,
+	135,
+	217,
+	133,
+	216,
+	171,
+	217,
+	132,
+	217,
+	131,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	173,
+	217,
+	138,
+	216,
+	171,
+	217,
+	133,
+	216,
+	181,
+	216,
+	177,
+	216,
+	180,
+	216,
+	177,
+	216,
+	173,
+	216,
+	173,
+	217,
+	136,
+	217,
+	132,
+	217,
+	136,
+	217,
+	129,
+	217,
+	138,
+	216,
+	167,
+	216,
+	176,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	217,
+	132,
+	217,
+	133,
+	216,
+	177,
+	216,
+	169,
+	216,
+	167,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	129,
+	216,
+	163,
+	216,
+	168,
+	217,
+	136,
+	216,
+	174,
+	216,
+	167,
+	216,
+	181,
+	216,
+	163,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	217,
+	134,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	217,
+	138,
+	216,
+	185,
+	216,
+	182,
+	217,
+	136,
+	217,
+	136,
+	217,
+	130,
+	216,
+	175,
+	216,
+	167,
+	216,
+	168,
+	217,
+	134,
+	216,
+	174,
+	217,
+	138,
+	216,
+	177,
+	216,
+	168,
+	217,
+	134,
+	216,
+	170,
+	217,
+	132,
+	217,
+	131,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	161,
+	217,
+	136,
+	217,
+	135,
+	217,
+	138,
+	216,
+	167,
+	216,
+	168,
+	217,
+	136,
+	217,
+	130,
+	216,
+	181,
+	216,
+	181,
+	217,
+	136,
+	217,
+	133,
+	216,
+	167,
+	216,
+	177,
+	217,
+	130,
+	217,
+	133,
+	216,
+	163,
+	216,
+	173,
+	216,
+	175,
+	217,
+	134,
+	216,
+	173,
+	217,
+	134,
+	216,
+	185,
+	216,
+	175,
+	217,
+	133,
+	216,
+	177,
+	216,
+	163,
+	217,
+	138,
+	216,
+	167,
+	216,
+	173,
+	216,
+	169,
+	217,
+	131,
+	216,
+	170,
+	216,
+	168,
+	216,
+	175,
+	217,
+	136,
+	217,
+	134,
+	217,
+	138,
+	216,
+	172,
+	216,
+	168,
+	217,
+	133,
+	217,
+	134,
+	217,
+	135,
+	216,
+	170,
+	216,
+	173,
+	216,
+	170,
+	216,
+	172,
+	217,
+	135,
+	216,
+	169,
+	216,
+	179,
+	217,
+	134,
+	216,
+	169,
+	217,
+	138,
+	216,
+	170,
+	217,
+	133,
+	217,
+	131,
+	216,
+	177,
+	216,
+	169,
+	216,
+	186,
+	216,
+	178,
+	216,
+	169,
+	217,
+	134,
+	217,
+	129,
+	216,
+	179,
+	216,
+	168,
+	217,
+	138,
+	216,
+	170,
+	217,
+	132,
+	217,
+	132,
+	217,
+	135,
+	217,
+	132,
+	217,
+	134,
+	216,
+	167,
+	216,
+	170,
+	217,
+	132,
+	217,
+	131,
+	217,
+	130,
+	217,
+	132,
+	216,
+	168,
+	217,
+	132,
+	217,
+	133,
+	216,
+	167,
+	216,
+	185,
+	217,
+	134,
+	217,
+	135,
+	216,
+	163,
+	217,
+	136,
+	217,
+	132,
+	216,
+	180,
+	217,
+	138,
+	216,
+	161,
+	217,
+	134,
+	217,
+	136,
+	216,
+	177,
+	216,
+	163,
+	217,
+	133,
+	216,
+	167,
+	217,
+	129,
+	217,
+	138,
+	217,
+	131,
+	216,
+	168,
+	217,
+	131,
+	217,
+	132,
+	216,
+	176,
+	216,
+	167,
+	216,
+	170,
+	216,
+	177,
+	216,
+	170,
+	216,
+	168,
+	216,
+	168,
+	216,
+	163,
+	217,
+	134,
+	217,
+	135,
+	217,
+	133,
+	216,
+	179,
+	216,
+	167,
+	217,
+	134,
+	217,
+	131,
+	216,
+	168,
+	217,
+	138,
+	216,
+	185,
+	217,
+	129,
+	217,
+	130,
+	216,
+	175,
+	216,
+	173,
+	216,
+	179,
+	217,
+	134,
+	217,
+	132,
+	217,
+	135,
+	217,
+	133,
+	216,
+	180,
+	216,
+	185,
+	216,
+	177,
+	216,
+	163,
+	217,
+	135,
+	217,
+	132,
+	216,
+	180,
+	217,
+	135,
+	216,
+	177,
+	217,
+	130,
+	216,
+	183,
+	216,
+	177,
+	216,
+	183,
+	217,
+	132,
+	216,
+	168,
+	112,
+	114,
+	111,
+	102,
+	105,
+	108,
+	101,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	100,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	104,
+	105,
+	109,
+	115,
+	101,
+	108,
+	102,
+	100,
+	101,
+	116,
+	97,
+	105,
+	108,
+	115,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	115,
+	116,
+	97,
+	114,
+	116,
+	101,
+	100,
+	109,
+	101,
+	115,
+	115,
+	97,
+	103,
+	101,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	97,
+	115,
+	104,
+	105,
+	111,
+	110,
+	60,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	121,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	115,
+	116,
+	111,
+	114,
+	105,
+	101,
+	115,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	115,
+	114,
+	117,
+	110,
+	110,
+	105,
+	110,
+	103,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	119,
+	114,
+	105,
+	116,
+	105,
+	110,
+	103,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	115,
+	118,
+	105,
+	115,
+	105,
+	98,
+	108,
+	101,
+	119,
+	101,
+	108,
+	99,
+	111,
+	109,
+	101,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	117,
+	110,
+	107,
+	110,
+	111,
+	119,
+	110,
+	110,
+	101,
+	116,
+	119,
+	111,
+	114,
+	107,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	121,
+	100,
+	121,
+	110,
+	97,
+	109,
+	105,
+	99,
+	98,
+	114,
+	111,
+	119,
+	115,
+	101,
+	114,
+	112,
+	114,
+	105,
+	118,
+	97,
+	99,
+	121,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	83,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	114,
+	101,
+	113,
+	117,
+	101,
+	115,
+	116,
+	114,
+	101,
+	115,
+	101,
+	114,
+	118,
+	101,
+	119,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	102,
+	114,
+	105,
+	101,
+	110,
+	100,
+	115,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	119,
+	111,
+	114,
+	107,
+	105,
+	110,
+	103,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	109,
+	105,
+	108,
+	108,
+	105,
+	111,
+	110,
+	99,
+	104,
+	97,
+	110,
+	110,
+	101,
+	108,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	97,
+	100,
+	100,
+	114,
+	101,
+	115,
+	115,
+	118,
+	105,
+	115,
+	105,
+	116,
+	101,
+	100,
+	119,
+	101,
+	97,
+	116,
+	104,
+	101,
+	114,
+	99,
+	111,
+	114,
+	114,
+	101,
+	99,
+	116,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	101,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	102,
+	111,
+	114,
+	119,
+	97,
+	114,
+	100,
+	121,
+	111,
+	117,
+	32,
+	99,
+	97,
+	110,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	100,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	97,
+	114,
+	99,
+	104,
+	105,
+	118,
+	101,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	114,
+	101,
+	97,
+	100,
+	105,
+	110,
+	103,
+	108,
+	105,
+	98,
+	114,
+	97,
+	114,
+	121,
+	108,
+	105,
+	109,
+	105,
+	116,
+	101,
+	100,
+	109,
+	97,
+	110,
+	97,
+	103,
+	101,
+	114,
+	102,
+	117,
+	114,
+	116,
+	104,
+	101,
+	114,
+	115,
+	117,
+	109,
+	109,
+	97,
+	114,
+	121,
+	109,
+	97,
+	99,
+	104,
+	105,
+	110,
+	101,
+	109,
+	105,
+	110,
+	117,
+	116,
+	101,
+	115,
+	112,
+	114,
+	105,
+	118,
+	97,
+	116,
+	101,
+	99,
+	111,
+	110,
+	116,
+	101,
+	120,
+	116,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	115,
+	111,
+	99,
+	105,
+	101,
+	116,
+	121,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	115,
+	119,
+	114,
+	105,
+	116,
+	116,
+	101,
+	110,
+	101,
+	110,
+	97,
+	98,
+	108,
+	101,
+	100,
+	116,
+	114,
+	105,
+	103,
+	103,
+	101,
+	114,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	115,
+	108,
+	111,
+	97,
+	100,
+	105,
+	110,
+	103,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	112,
+	97,
+	114,
+	116,
+	110,
+	101,
+	114,
+	102,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	112,
+	101,
+	114,
+	102,
+	101,
+	99,
+	116,
+	109,
+	101,
+	97,
+	110,
+	105,
+	110,
+	103,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	115,
+	107,
+	101,
+	101,
+	112,
+	105,
+	110,
+	103,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	44,
+	106,
+	111,
+	117,
+	114,
+	110,
+	97,
+	108,
+	112,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	115,
+	117,
+	114,
+	102,
+	97,
+	99,
+	101,
+	115,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	101,
+	120,
+	112,
+	105,
+	114,
+	101,
+	115,
+	114,
+	101,
+	118,
+	105,
+	101,
+	119,
+	115,
+	98,
+	97,
+	108,
+	97,
+	110,
+	99,
+	101,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	67,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	80,
+	108,
+	101,
+	97,
+	115,
+	101,
+	32,
+	111,
+	112,
+	105,
+	110,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	97,
+	118,
+	101,
+	114,
+	97,
+	103,
+	101,
+	112,
+	114,
+	105,
+	109,
+	97,
+	114,
+	121,
+	118,
+	105,
+	108,
+	108,
+	97,
+	103,
+	101,
+	83,
+	112,
+	97,
+	110,
+	105,
+	115,
+	104,
+	103,
+	97,
+	108,
+	108,
+	101,
+	114,
+	121,
+	100,
+	101,
+	99,
+	108,
+	105,
+	110,
+	101,
+	109,
+	101,
+	101,
+	116,
+	105,
+	110,
+	103,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	113,
+	117,
+	97,
+	108,
+	105,
+	116,
+	121,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	115,
+	112,
+	101,
+	99,
+	105,
+	101,
+	115,
+	115,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	119,
+	114,
+	105,
+	116,
+	101,
+	114,
+	115,
+	99,
+	111,
+	117,
+	110,
+	116,
+	101,
+	114,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	108,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	115,
+	102,
+	105,
+	103,
+	117,
+	114,
+	101,
+	115,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	115,
+	104,
+	111,
+	108,
+	100,
+	105,
+	110,
+	103,
+	100,
+	105,
+	115,
+	112,
+	117,
+	116,
+	101,
+	101,
+	97,
+	114,
+	108,
+	105,
+	101,
+	114,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	100,
+	105,
+	103,
+	105,
+	116,
+	97,
+	108,
+	112,
+	105,
+	99,
+	116,
+	117,
+	114,
+	101,
+	65,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	109,
+	97,
+	114,
+	114,
+	105,
+	101,
+	100,
+	116,
+	114,
+	97,
+	102,
+	102,
+	105,
+	99,
+	108,
+	101,
+	97,
+	100,
+	105,
+	110,
+	103,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	100,
+	99,
+	101,
+	110,
+	116,
+	114,
+	97,
+	108,
+	118,
+	105,
+	99,
+	116,
+	111,
+	114,
+	121,
+	105,
+	109,
+	97,
+	103,
+	101,
+	115,
+	47,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	115,
+	115,
+	116,
+	117,
+	100,
+	105,
+	101,
+	115,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	108,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	109,
+	117,
+	115,
+	116,
+	32,
+	98,
+	101,
+	115,
+	99,
+	104,
+	111,
+	111,
+	108,
+	115,
+	86,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	117,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	101,
+	112,
+	105,
+	115,
+	111,
+	100,
+	101,
+	112,
+	108,
+	97,
+	121,
+	105,
+	110,
+	103,
+	103,
+	114,
+	111,
+	119,
+	105,
+	110,
+	103,
+	111,
+	98,
+	118,
+	105,
+	111,
+	117,
+	115,
+	111,
+	118,
+	101,
+	114,
+	108,
+	97,
+	121,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	60,
+	47,
+	117,
+	108,
+	62,
+	13,
+	10,
+	119,
+	114,
+	97,
+	112,
+	112,
+	101,
+	114,
+	97,
+	108,
+	114,
+	101,
+	97,
+	100,
+	121,
+	99,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	114,
+	101,
+	97,
+	108,
+	105,
+	116,
+	121,
+	115,
+	116,
+	111,
+	114,
+	97,
+	103,
+	101,
+	97,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	100,
+	101,
+	115,
+	107,
+	116,
+	111,
+	112,
+	111,
+	102,
+	102,
+	101,
+	114,
+	101,
+	100,
+	112,
+	97,
+	116,
+	116,
+	101,
+	114,
+	110,
+	117,
+	110,
+	117,
+	115,
+	117,
+	97,
+	108,
+	68,
+	105,
+	103,
+	105,
+	116,
+	97,
+	108,
+	99,
+	97,
+	112,
+	105,
+	116,
+	97,
+	108,
+	87,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	102,
+	97,
+	105,
+	108,
+	117,
+	114,
+	101,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	114,
+	101,
+	100,
+	117,
+	99,
+	101,
+	100,
+	65,
+	110,
+	100,
+	114,
+	111,
+	105,
+	100,
+	100,
+	101,
+	99,
+	97,
+	100,
+	101,
+	115,
+	114,
+	101,
+	103,
+	117,
+	108,
+	97,
+	114,
+	32,
+	38,
+	97,
+	109,
+	112,
+	59,
+	32,
+	97,
+	110,
+	105,
+	109,
+	97,
+	108,
+	115,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	65,
+	117,
+	116,
+	111,
+	109,
+	97,
+	116,
+	103,
+	101,
+	116,
+	116,
+	105,
+	110,
+	103,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	115,
+	110,
+	111,
+	116,
+	104,
+	105,
+	110,
+	103,
+	80,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	99,
+	97,
+	112,
+	116,
+	105,
+	111,
+	110,
+	108,
+	101,
+	116,
+	116,
+	101,
+	114,
+	115,
+	99,
+	97,
+	112,
+	116,
+	117,
+	114,
+	101,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	108,
+	105,
+	99,
+	101,
+	110,
+	115,
+	101,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	115,
+	69,
+	110,
+	103,
+	108,
+	97,
+	110,
+	100,
+	61,
+	49,
+	38,
+	97,
+	109,
+	112,
+	59,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	61,
+	32,
+	110,
+	101,
+	119,
+	32,
+	67,
+	101,
+	110,
+	116,
+	114,
+	97,
+	108,
+	117,
+	112,
+	100,
+	97,
+	116,
+	101,
+	100,
+	83,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	78,
+	101,
+	116,
+	119,
+	111,
+	114,
+	107,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	116,
+	119,
+	97,
+	114,
+	110,
+	105,
+	110,
+	103,
+	67,
+	111,
+	108,
+	108,
+	101,
+	103,
+	101,
+	116,
+	111,
+	111,
+	108,
+	98,
+	97,
+	114,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	115,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	68,
+	101,
+	117,
+	116,
+	115,
+	99,
+	104,
+	102,
+	105,
+	110,
+	97,
+	110,
+	99,
+	101,
+	119,
+	111,
+	114,
+	107,
+	101,
+	114,
+	115,
+	113,
+	117,
+	105,
+	99,
+	107,
+	108,
+	121,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	101,
+	120,
+	97,
+	99,
+	116,
+	108,
+	121,
+	115,
+	101,
+	116,
+	116,
+	105,
+	110,
+	103,
+	100,
+	105,
+	115,
+	101,
+	97,
+	115,
+	101,
+	83,
+	111,
+	99,
+	105,
+	101,
+	116,
+	121,
+	119,
+	101,
+	97,
+	112,
+	111,
+	110,
+	115,
+	101,
+	120,
+	104,
+	105,
+	98,
+	105,
+	116,
+	38,
+	108,
+	116,
+	59,
+	33,
+	45,
+	45,
+	67,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	99,
+	108,
+	97,
+	115,
+	115,
+	101,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	101,
+	100,
+	111,
+	117,
+	116,
+	108,
+	105,
+	110,
+	101,
+	97,
+	116,
+	116,
+	97,
+	99,
+	107,
+	115,
+	100,
+	101,
+	118,
+	105,
+	99,
+	101,
+	115,
+	40,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	112,
+	117,
+	114,
+	112,
+	111,
+	115,
+	101,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	77,
+	111,
+	98,
+	105,
+	108,
+	101,
+	32,
+	107,
+	105,
+	108,
+	108,
+	105,
+	110,
+	103,
+	115,
+	104,
+	111,
+	119,
+	105,
+	110,
+	103,
+	73,
+	116,
+	97,
+	108,
+	105,
+	97,
+	110,
+	100,
+	114,
+	111,
+	112,
+	112,
+	101,
+	100,
+	104,
+	101,
+	97,
+	118,
+	105,
+	108,
+	121,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	115,
+	45,
+	49,
+	39,
+	93,
+	41,
+	59,
+	10,
+	99,
+	111,
+	110,
+	102,
+	105,
+	114,
+	109,
+	67,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	97,
+	100,
+	118,
+	97,
+	110,
+	99,
+	101,
+	115,
+	104,
+	97,
+	114,
+	105,
+	110,
+	103,
+	111,
+	112,
+	101,
+	110,
+	105,
+	110,
+	103,
+	100,
+	114,
+	97,
+	119,
+	105,
+	110,
+	103,
+	98,
+	105,
+	108,
+	108,
+	105,
+	111,
+	110,
+	111,
+	114,
+	100,
+	101,
+	114,
+	101,
+	100,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	121,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	119,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	83,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	99,
+	97,
+	116,
+	97,
+	108,
+	111,
+	103,
+	65,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	115,
+	108,
+	97,
+	114,
+	103,
+	101,
+	115,
+	116,
+	117,
+	110,
+	105,
+	102,
+	111,
+	114,
+	109,
+	106,
+	111,
+	117,
+	114,
+	110,
+	101,
+	121,
+	115,
+	105,
+	100,
+	101,
+	98,
+	97,
+	114,
+	67,
+	104,
+	105,
+	99,
+	97,
+	103,
+	111,
+	104,
+	111,
+	108,
+	105,
+	100,
+	97,
+	121,
+	71,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	112,
+	97,
+	115,
+	115,
+	97,
+	103,
+	101,
+	44,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	97,
+	110,
+	105,
+	109,
+	97,
+	116,
+	101,
+	102,
+	101,
+	101,
+	108,
+	105,
+	110,
+	103,
+	97,
+	114,
+	114,
+	105,
+	118,
+	101,
+	100,
+	112,
+	97,
+	115,
+	115,
+	105,
+	110,
+	103,
+	110,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	114,
+	111,
+	117,
+	103,
+	104,
+	108,
+	121,
+	46,
+	10,
+	10,
+	84,
+	104,
+	101,
+	32,
+	98,
+	117,
+	116,
+	32,
+	110,
+	111,
+	116,
+	100,
+	101,
+	110,
+	115,
+	105,
+	116,
+	121,
+	66,
+	114,
+	105,
+	116,
+	97,
+	105,
+	110,
+	67,
+	104,
+	105,
+	110,
+	101,
+	115,
+	101,
+	108,
+	97,
+	99,
+	107,
+	32,
+	111,
+	102,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	73,
+	114,
+	101,
+	108,
+	97,
+	110,
+	100,
+	34,
+	32,
+	100,
+	97,
+	116,
+	97,
+	45,
+	102,
+	97,
+	99,
+	116,
+	111,
+	114,
+	115,
+	114,
+	101,
+	99,
+	101,
+	105,
+	118,
+	101,
+	116,
+	104,
+	97,
+	116,
+	32,
+	105,
+	115,
+	76,
+	105,
+	98,
+	114,
+	97,
+	114,
+	121,
+	104,
+	117,
+	115,
+	98,
+	97,
+	110,
+	100,
+	105,
+	110,
+	32,
+	102,
+	97,
+	99,
+	116,
+	97,
+	102,
+	102,
+	97,
+	105,
+	114,
+	115,
+	67,
+	104,
+	97,
+	114,
+	108,
+	101,
+	115,
+	114,
+	97,
+	100,
+	105,
+	99,
+	97,
+	108,
+	98,
+	114,
+	111,
+	117,
+	103,
+	104,
+	116,
+	102,
+	105,
+	110,
+	100,
+	105,
+	110,
+	103,
+	108,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	58,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	108,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	112,
+	108,
+	97,
+	110,
+	110,
+	101,
+	100,
+	112,
+	114,
+	101,
+	109,
+	105,
+	117,
+	109,
+	112,
+	97,
+	99,
+	107,
+	97,
+	103,
+	101,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	69,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	93,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	77,
+	101,
+	115,
+	115,
+	97,
+	103,
+	101,
+	110,
+	101,
+	101,
+	100,
+	32,
+	116,
+	111,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	120,
+	108,
+	111,
+	111,
+	107,
+	105,
+	110,
+	103,
+	115,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	115,
+	109,
+	97,
+	108,
+	108,
+	101,
+	114,
+	45,
+	109,
+	111,
+	98,
+	105,
+	108,
+	101,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	115,
+	119,
+	97,
+	110,
+	116,
+	32,
+	116,
+	111,
+	107,
+	105,
+	110,
+	100,
+	32,
+	111,
+	102,
+	70,
+	105,
+	114,
+	101,
+	102,
+	111,
+	120,
+	121,
+	111,
+	117,
+	32,
+	97,
+	114,
+	101,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	115,
+	116,
+	117,
+	100,
+	105,
+	101,
+	100,
+	109,
+	97,
+	120,
+	105,
+	109,
+	117,
+	109,
+	104,
+	101,
+	97,
+	100,
+	105,
+	110,
+	103,
+	114,
+	97,
+	112,
+	105,
+	100,
+	108,
+	121,
+	99,
+	108,
+	105,
+	109,
+	97,
+	116,
+	101,
+	107,
+	105,
+	110,
+	103,
+	100,
+	111,
+	109,
+	101,
+	109,
+	101,
+	114,
+	103,
+	101,
+	100,
+	97,
+	109,
+	111,
+	117,
+	110,
+	116,
+	115,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	112,
+	105,
+	111,
+	110,
+	101,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	117,
+	108,
+	97,
+	100,
+	121,
+	110,
+	97,
+	115,
+	116,
+	121,
+	104,
+	111,
+	119,
+	32,
+	116,
+	111,
+	32,
+	83,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	114,
+	101,
+	118,
+	101,
+	110,
+	117,
+	101,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	121,
+	82,
+	101,
+	115,
+	117,
+	108,
+	116,
+	115,
+	98,
+	114,
+	111,
+	116,
+	104,
+	101,
+	114,
+	115,
+	111,
+	108,
+	100,
+	105,
+	101,
+	114,
+	108,
+	97,
+	114,
+	103,
+	101,
+	108,
+	121,
+	99,
+	97,
+	108,
+	108,
+	105,
+	110,
+	103,
+	46,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	65,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	69,
+	100,
+	119,
+	97,
+	114,
+	100,
+	32,
+	115,
+	101,
+	103,
+	109,
+	101,
+	110,
+	116,
+	82,
+	111,
+	98,
+	101,
+	114,
+	116,
+	32,
+	101,
+	102,
+	102,
+	111,
+	114,
+	116,
+	115,
+	80,
+	97,
+	99,
+	105,
+	102,
+	105,
+	99,
+	108,
+	101,
+	97,
+	114,
+	110,
+	101,
+	100,
+	117,
+	112,
+	32,
+	119,
+	105,
+	116,
+	104,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	119,
+	101,
+	32,
+	104,
+	97,
+	118,
+	101,
+	65,
+	110,
+	103,
+	101,
+	108,
+	101,
+	115,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	95,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	97,
+	112,
+	112,
+	108,
+	105,
+	101,
+	100,
+	97,
+	99,
+	113,
+	117,
+	105,
+	114,
+	101,
+	109,
+	97,
+	115,
+	115,
+	105,
+	118,
+	101,
+	103,
+	114,
+	97,
+	110,
+	116,
+	101,
+	100,
+	58,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	116,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	98,
+	105,
+	103,
+	103,
+	101,
+	115,
+	116,
+	98,
+	101,
+	110,
+	101,
+	102,
+	105,
+	116,
+	100,
+	114,
+	105,
+	118,
+	105,
+	110,
+	103,
+	83,
+	116,
+	117,
+	100,
+	105,
+	101,
+	115,
+	109,
+	105,
+	110,
+	105,
+	109,
+	117,
+	109,
+	112,
+	101,
+	114,
+	104,
+	97,
+	112,
+	115,
+	109,
+	111,
+	114,
+	110,
+	105,
+	110,
+	103,
+	115,
+	101,
+	108,
+	108,
+	105,
+	110,
+	103,
+	105,
+	115,
+	32,
+	117,
+	115,
+	101,
+	100,
+	114,
+	101,
+	118,
+	101,
+	114,
+	115,
+	101,
+	118,
+	97,
+	114,
+	105,
+	97,
+	110,
+	116,
+	32,
+	114,
+	111,
+	108,
+	101,
+	61,
+	34,
+	109,
+	105,
+	115,
+	115,
+	105,
+	110,
+	103,
+	97,
+	99,
+	104,
+	105,
+	101,
+	118,
+	101,
+	112,
+	114,
+	111,
+	109,
+	111,
+	116,
+	101,
+	115,
+	116,
+	117,
+	100,
+	101,
+	110,
+	116,
+	115,
+	111,
+	109,
+	101,
+	111,
+	110,
+	101,
+	101,
+	120,
+	116,
+	114,
+	101,
+	109,
+	101,
+	114,
+	101,
+	115,
+	116,
+	111,
+	114,
+	101,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	58,
+	101,
+	118,
+	111,
+	108,
+	118,
+	101,
+	100,
+	97,
+	108,
+	108,
+	32,
+	116,
+	104,
+	101,
+	115,
+	105,
+	116,
+	101,
+	109,
+	97,
+	112,
+	101,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	119,
+	97,
+	121,
+	32,
+	116,
+	111,
+	32,
+	32,
+	65,
+	117,
+	103,
+	117,
+	115,
+	116,
+	115,
+	121,
+	109,
+	98,
+	111,
+	108,
+	115,
+	67,
+	111,
+	109,
+	112,
+	97,
+	110,
+	121,
+	109,
+	97,
+	116,
+	116,
+	101,
+	114,
+	115,
+	109,
+	117,
+	115,
+	105,
+	99,
+	97,
+	108,
+	97,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	115,
+	101,
+	114,
+	118,
+	105,
+	110,
+	103,
+	125,
+	41,
+	40,
+	41,
+	59,
+	13,
+	10,
+	112,
+	97,
+	121,
+	109,
+	101,
+	110,
+	116,
+	116,
+	114,
+	111,
+	117,
+	98,
+	108,
+	101,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	101,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	115,
+	112,
+	108,
+	97,
+	121,
+	101,
+	114,
+	115,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	115,
+	109,
+	111,
+	110,
+	105,
+	116,
+	111,
+	114,
+	32,
+	39,
+	39,
+	84,
+	104,
+	101,
+	32,
+	119,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	101,
+	120,
+	112,
+	108,
+	111,
+	114,
+	101,
+	97,
+	100,
+	97,
+	112,
+	116,
+	101,
+	100,
+	71,
+	97,
+	108,
+	108,
+	101,
+	114,
+	121,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	101,
+	110,
+	104,
+	97,
+	110,
+	99,
+	101,
+	99,
+	97,
+	114,
+	101,
+	101,
+	114,
+	115,
+	41,
+	46,
+	32,
+	84,
+	104,
+	101,
+	32,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	83,
+	101,
+	97,
+	114,
+	99,
+	104,
+	32,
+	97,
+	110,
+	99,
+	105,
+	101,
+	110,
+	116,
+	101,
+	120,
+	105,
+	115,
+	116,
+	101,
+	100,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	32,
+	104,
+	97,
+	110,
+	100,
+	108,
+	101,
+	114,
+	112,
+	114,
+	105,
+	110,
+	116,
+	101,
+	100,
+	99,
+	111,
+	110,
+	115,
+	111,
+	108,
+	101,
+	69,
+	97,
+	115,
+	116,
+	101,
+	114,
+	110,
+	101,
+	120,
+	112,
+	111,
+	114,
+	116,
+	115,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	67,
+	104,
+	97,
+	110,
+	110,
+	101,
+	108,
+	105,
+	108,
+	108,
+	101,
+	103,
+	97,
+	108,
+	110,
+	101,
+	117,
+	116,
+	114,
+	97,
+	108,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	95,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	105,
+	103,
+	110,
+	105,
+	110,
+	103,
+	46,
+	104,
+	116,
+	109,
+	108,
+	34,
+	62,
+	115,
+	101,
+	116,
+	116,
+	108,
+	101,
+	100,
+	119,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	99,
+	97,
+	117,
+	115,
+	105,
+	110,
+	103,
+	45,
+	119,
+	101,
+	98,
+	107,
+	105,
+	116,
+	99,
+	108,
+	97,
+	105,
+	109,
+	101,
+	100,
+	74,
+	117,
+	115,
+	116,
+	105,
+	99,
+	101,
+	99,
+	104,
+	97,
+	112,
+	116,
+	101,
+	114,
+	118,
+	105,
+	99,
+	116,
+	105,
+	109,
+	115,
+	84,
+	104,
+	111,
+	109,
+	97,
+	115,
+	32,
+	109,
+	111,
+	122,
+	105,
+	108,
+	108,
+	97,
+	112,
+	114,
+	111,
+	109,
+	105,
+	115,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	101,
+	115,
+	101,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	111,
+	117,
+	116,
+	115,
+	105,
+	100,
+	101,
+	58,
+	102,
+	97,
+	108,
+	115,
+	101,
+	44,
+	104,
+	117,
+	110,
+	100,
+	114,
+	101,
+	100,
+	79,
+	108,
+	121,
+	109,
+	112,
+	105,
+	99,
+	95,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	115,
+	114,
+	101,
+	97,
+	99,
+	104,
+	101,
+	100,
+	99,
+	104,
+	114,
+	111,
+	110,
+	105,
+	99,
+	100,
+	101,
+	109,
+	97,
+	110,
+	100,
+	115,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	115,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	97,
+	100,
+	111,
+	112,
+	116,
+	101,
+	100,
+	112,
+	114,
+	101,
+	112,
+	97,
+	114,
+	101,
+	110,
+	101,
+	105,
+	116,
+	104,
+	101,
+	114,
+	103,
+	114,
+	101,
+	97,
+	116,
+	108,
+	121,
+	103,
+	114,
+	101,
+	97,
+	116,
+	101,
+	114,
+	111,
+	118,
+	101,
+	114,
+	97,
+	108,
+	108,
+	105,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	99,
+	111,
+	109,
+	109,
+	97,
+	110,
+	100,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	46,
+	119,
+	111,
+	114,
+	115,
+	104,
+	105,
+	112,
+	102,
+	117,
+	110,
+	100,
+	105,
+	110,
+	103,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	116,
+	104,
+	105,
+	103,
+	104,
+	101,
+	115,
+	116,
+	105,
+	110,
+	115,
+	116,
+	101,
+	97,
+	100,
+	117,
+	116,
+	105,
+	108,
+	105,
+	116,
+	121,
+	113,
+	117,
+	97,
+	114,
+	116,
+	101,
+	114,
+	67,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	116,
+	101,
+	115,
+	116,
+	105,
+	110,
+	103,
+	99,
+	108,
+	101,
+	97,
+	114,
+	108,
+	121,
+	101,
+	120,
+	112,
+	111,
+	115,
+	101,
+	100,
+	66,
+	114,
+	111,
+	119,
+	115,
+	101,
+	114,
+	108,
+	105,
+	98,
+	101,
+	114,
+	97,
+	108,
+	125,
+	32,
+	99,
+	97,
+	116,
+	99,
+	104,
+	80,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	104,
+	105,
+	100,
+	101,
+	40,
+	41,
+	59,
+	70,
+	108,
+	111,
+	114,
+	105,
+	100,
+	97,
+	97,
+	110,
+	115,
+	119,
+	101,
+	114,
+	115,
+	97,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	69,
+	109,
+	112,
+	101,
+	114,
+	111,
+	114,
+	100,
+	101,
+	102,
+	101,
+	110,
+	115,
+	101,
+	115,
+	101,
+	114,
+	105,
+	111,
+	117,
+	115,
+	102,
+	114,
+	101,
+	101,
+	100,
+	111,
+	109,
+	83,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	45,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	70,
+	117,
+	114,
+	116,
+	104,
+	101,
+	114,
+	111,
+	117,
+	116,
+	32,
+	111,
+	102,
+	32,
+	33,
+	61,
+	32,
+	110,
+	117,
+	108,
+	108,
+	116,
+	114,
+	97,
+	105,
+	110,
+	101,
+	100,
+	68,
+	101,
+	110,
+	109,
+	97,
+	114,
+	107,
+	118,
+	111,
+	105,
+	100,
+	40,
+	48,
+	41,
+	47,
+	97,
+	108,
+	108,
+	46,
+	106,
+	115,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	82,
+	101,
+	113,
+	117,
+	101,
+	115,
+	116,
+	83,
+	116,
+	101,
+	112,
+	104,
+	101,
+	110,
+	10,
+	10,
+	87,
+	104,
+	101,
+	110,
+	32,
+	111,
+	98,
+	115,
+	101,
+	114,
+	118,
+	101,
+	60,
+	47,
+	104,
+	50,
+	62,
+	13,
+	10,
+	77,
+	111,
+	100,
+	101,
+	114,
+	110,
+	32,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	115,
+	46,
+	10,
+	10,
+	70,
+	111,
+	114,
+	32,
+	10,
+	10,
+	77,
+	97,
+	110,
+	121,
+	32,
+	97,
+	114,
+	116,
+	105,
+	115,
+	116,
+	115,
+	112,
+	111,
+	119,
+	101,
+	114,
+	101,
+	100,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	102,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	116,
+	121,
+	112,
+	101,
+	32,
+	111,
+	102,
+	109,
+	101,
+	100,
+	105,
+	99,
+	97,
+	108,
+	116,
+	105,
+	99,
+	107,
+	101,
+	116,
+	115,
+	111,
+	112,
+	112,
+	111,
+	115,
+	101,
+	100,
+	67,
+	111,
+	117,
+	110,
+	99,
+	105,
+	108,
+	119,
+	105,
+	116,
+	110,
+	101,
+	115,
+	115,
+	106,
+	117,
+	115,
+	116,
+	105,
+	99,
+	101,
+	71,
+	101,
+	111,
+	114,
+	103,
+	101,
+	32,
+	66,
+	101,
+	108,
+	103,
+	105,
+	117,
+	109,
+	46,
+	46,
+	46,
+	60,
+	47,
+	97,
+	62,
+	116,
+	119,
+	105,
+	116,
+	116,
+	101,
+	114,
+	110,
+	111,
+	116,
+	97,
+	98,
+	108,
+	121,
+	119,
+	97,
+	105,
+	116,
+	105,
+	110,
+	103,
+	119,
+	97,
+	114,
+	102,
+	97,
+	114,
+	101,
+	32,
+	79,
+	116,
+	104,
+	101,
+	114,
+	32,
+	114,
+	97,
+	110,
+	107,
+	105,
+	110,
+	103,
+	112,
+	104,
+	114,
+	97,
+	115,
+	101,
+	115,
+	109,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	115,
+	117,
+	114,
+	118,
+	105,
+	118,
+	101,
+	115,
+	99,
+	104,
+	111,
+	108,
+	97,
+	114,
+	60,
+	47,
+	112,
+	62,
+	13,
+	10,
+	32,
+	67,
+	111,
+	117,
+	110,
+	116,
+	114,
+	121,
+	105,
+	103,
+	110,
+	111,
+	114,
+	101,
+	100,
+	108,
+	111,
+	115,
+	115,
+	32,
+	111,
+	102,
+	106,
+	117,
+	115,
+	116,
+	32,
+	97,
+	115,
+	71,
+	101,
+	111,
+	114,
+	103,
+	105,
+	97,
+	115,
+	116,
+	114,
+	97,
+	110,
+	103,
+	101,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	60,
+	115,
+	116,
+	111,
+	112,
+	112,
+	101,
+	100,
+	49,
+	39,
+	93,
+	41,
+	59,
+	13,
+	10,
+	105,
+	115,
+	108,
+	97,
+	110,
+	100,
+	115,
+	110,
+	111,
+	116,
+	97,
+	98,
+	108,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	58,
+	108,
+	105,
+	115,
+	116,
+	32,
+	111,
+	102,
+	99,
+	97,
+	114,
+	114,
+	105,
+	101,
+	100,
+	49,
+	48,
+	48,
+	44,
+	48,
+	48,
+	48,
+	60,
+	47,
+	104,
+	51,
+	62,
+	10,
+	32,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	98,
+	101,
+	99,
+	111,
+	109,
+	101,
+	115,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	32,
+	119,
+	101,
+	100,
+	100,
+	105,
+	110,
+	103,
+	48,
+	48,
+	46,
+	104,
+	116,
+	109,
+	108,
+	109,
+	111,
+	110,
+	97,
+	114,
+	99,
+	104,
+	111,
+	102,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	101,
+	97,
+	99,
+	104,
+	101,
+	114,
+	104,
+	105,
+	103,
+	104,
+	108,
+	121,
+	32,
+	98,
+	105,
+	111,
+	108,
+	111,
+	103,
+	121,
+	108,
+	105,
+	102,
+	101,
+	32,
+	111,
+	102,
+	111,
+	114,
+	32,
+	101,
+	118,
+	101,
+	110,
+	114,
+	105,
+	115,
+	101,
+	32,
+	111,
+	102,
+	38,
+	114,
+	97,
+	113,
+	117,
+	111,
+	59,
+	112,
+	108,
+	117,
+	115,
+	111,
+	110,
+	101,
+	104,
+	117,
+	110,
+	116,
+	105,
+	110,
+	103,
+	40,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	68,
+	111,
+	117,
+	103,
+	108,
+	97,
+	115,
+	106,
+	111,
+	105,
+	110,
+	105,
+	110,
+	103,
+	99,
+	105,
+	114,
+	99,
+	108,
+	101,
+	115,
+	70,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	65,
+	110,
+	99,
+	105,
+	101,
+	110,
+	116,
+	86,
+	105,
+	101,
+	116,
+	110,
+	97,
+	109,
+	118,
+	101,
+	104,
+	105,
+	99,
+	108,
+	101,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	99,
+	114,
+	121,
+	115,
+	116,
+	97,
+	108,
+	118,
+	97,
+	108,
+	117,
+	101,
+	32,
+	61,
+	87,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	101,
+	110,
+	106,
+	111,
+	121,
+	101,
+	100,
+	97,
+	32,
+	115,
+	109,
+	97,
+	108,
+	108,
+	97,
+	115,
+	115,
+	117,
+	109,
+	101,
+	100,
+	60,
+	97,
+	32,
+	105,
+	100,
+	61,
+	34,
+	102,
+	111,
+	114,
+	101,
+	105,
+	103,
+	110,
+	32,
+	65,
+	108,
+	108,
+	32,
+	114,
+	105,
+	104,
+	111,
+	119,
+	32,
+	116,
+	104,
+	101,
+	68,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	114,
+	101,
+	116,
+	105,
+	114,
+	101,
+	100,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	59,
+	98,
+	97,
+	116,
+	116,
+	108,
+	101,
+	115,
+	115,
+	101,
+	101,
+	107,
+	105,
+	110,
+	103,
+	99,
+	97,
+	98,
+	105,
+	110,
+	101,
+	116,
+	119,
+	97,
+	115,
+	32,
+	110,
+	111,
+	116,
+	108,
+	111,
+	111,
+	107,
+	32,
+	97,
+	116,
+	99,
+	111,
+	110,
+	100,
+	117,
+	99,
+	116,
+	103,
+	101,
+	116,
+	32,
+	116,
+	104,
+	101,
+	74,
+	97,
+	110,
+	117,
+	97,
+	114,
+	121,
+	104,
+	97,
+	112,
+	112,
+	101,
+	110,
+	115,
+	116,
+	117,
+	114,
+	110,
+	105,
+	110,
+	103,
+	97,
+	58,
+	104,
+	111,
+	118,
+	101,
+	114,
+	79,
+	110,
+	108,
+	105,
+	110,
+	101,
+	32,
+	70,
+	114,
+	101,
+	110,
+	99,
+	104,
+	32,
+	108,
+	97,
+	99,
+	107,
+	105,
+	110,
+	103,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	101,
+	120,
+	116,
+	114,
+	97,
+	99,
+	116,
+	101,
+	110,
+	101,
+	109,
+	105,
+	101,
+	115,
+	101,
+	118,
+	101,
+	110,
+	32,
+	105,
+	102,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	100,
+	101,
+	99,
+	105,
+	100,
+	101,
+	100,
+	97,
+	114,
+	101,
+	32,
+	110,
+	111,
+	116,
+	47,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	98,
+	101,
+	108,
+	105,
+	101,
+	102,
+	115,
+	45,
+	105,
+	109,
+	97,
+	103,
+	101,
+	58,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	115,
+	116,
+	97,
+	116,
+	105,
+	99,
+	46,
+	108,
+	111,
+	103,
+	105,
+	110,
+	34,
+	62,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	116,
+	118,
+	105,
+	111,
+	108,
+	101,
+	110,
+	116,
+	101,
+	110,
+	116,
+	101,
+	114,
+	101,
+	100,
+	102,
+	105,
+	114,
+	115,
+	116,
+	34,
+	62,
+	99,
+	105,
+	114,
+	99,
+	117,
+	105,
+	116,
+	70,
+	105,
+	110,
+	108,
+	97,
+	110,
+	100,
+	99,
+	104,
+	101,
+	109,
+	105,
+	115,
+	116,
+	115,
+	104,
+	101,
+	32,
+	119,
+	97,
+	115,
+	49,
+	48,
+	112,
+	120,
+	59,
+	34,
+	62,
+	97,
+	115,
+	32,
+	115,
+	117,
+	99,
+	104,
+	100,
+	105,
+	118,
+	105,
+	100,
+	101,
+	100,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	119,
+	105,
+	108,
+	108,
+	32,
+	98,
+	101,
+	108,
+	105,
+	110,
+	101,
+	32,
+	111,
+	102,
+	97,
+	32,
+	103,
+	114,
+	101,
+	97,
+	116,
+	109,
+	121,
+	115,
+	116,
+	101,
+	114,
+	121,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	102,
+	97,
+	108,
+	108,
+	105,
+	110,
+	103,
+	100,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	114,
+	97,
+	105,
+	108,
+	119,
+	97,
+	121,
+	99,
+	111,
+	108,
+	108,
+	101,
+	103,
+	101,
+	109,
+	111,
+	110,
+	115,
+	116,
+	101,
+	114,
+	100,
+	101,
+	115,
+	99,
+	101,
+	110,
+	116,
+	105,
+	116,
+	32,
+	119,
+	105,
+	116,
+	104,
+	110,
+	117,
+	99,
+	108,
+	101,
+	97,
+	114,
+	74,
+	101,
+	119,
+	105,
+	115,
+	104,
+	32,
+	112,
+	114,
+	111,
+	116,
+	101,
+	115,
+	116,
+	66,
+	114,
+	105,
+	116,
+	105,
+	115,
+	104,
+	102,
+	108,
+	111,
+	119,
+	101,
+	114,
+	115,
+	112,
+	114,
+	101,
+	100,
+	105,
+	99,
+	116,
+	114,
+	101,
+	102,
+	111,
+	114,
+	109,
+	115,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	32,
+	119,
+	104,
+	111,
+	32,
+	119,
+	97,
+	115,
+	108,
+	101,
+	99,
+	116,
+	117,
+	114,
+	101,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	116,
+	115,
+	117,
+	105,
+	99,
+	105,
+	100,
+	101,
+	103,
+	101,
+	110,
+	101,
+	114,
+	105,
+	99,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	115,
+	109,
+	97,
+	114,
+	107,
+	101,
+	116,
+	115,
+	83,
+	111,
+	99,
+	105,
+	97,
+	108,
+	32,
+	102,
+	105,
+	115,
+	104,
+	105,
+	110,
+	103,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	101,
+	103,
+	114,
+	97,
+	112,
+	104,
+	105,
+	99,
+	119,
+	105,
+	110,
+	110,
+	101,
+	114,
+	115,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	60,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	78,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	80,
+	114,
+	105,
+	118,
+	97,
+	99,
+	121,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	115,
+	111,
+	117,
+	116,
+	99,
+	111,
+	109,
+	101,
+	114,
+	101,
+	115,
+	111,
+	108,
+	118,
+	101,
+	83,
+	119,
+	101,
+	100,
+	105,
+	115,
+	104,
+	98,
+	114,
+	105,
+	101,
+	102,
+	108,
+	121,
+	80,
+	101,
+	114,
+	115,
+	105,
+	97,
+	110,
+	115,
+	111,
+	32,
+	109,
+	117,
+	99,
+	104,
+	67,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	100,
+	101,
+	112,
+	105,
+	99,
+	116,
+	115,
+	99,
+	111,
+	108,
+	117,
+	109,
+	110,
+	115,
+	104,
+	111,
+	117,
+	115,
+	105,
+	110,
+	103,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	115,
+	110,
+	101,
+	120,
+	116,
+	32,
+	116,
+	111,
+	98,
+	101,
+	97,
+	114,
+	105,
+	110,
+	103,
+	109,
+	97,
+	112,
+	112,
+	105,
+	110,
+	103,
+	114,
+	101,
+	118,
+	105,
+	115,
+	101,
+	100,
+	106,
+	81,
+	117,
+	101,
+	114,
+	121,
+	40,
+	45,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	116,
+	105,
+	116,
+	108,
+	101,
+	34,
+	62,
+	116,
+	111,
+	111,
+	108,
+	116,
+	105,
+	112,
+	83,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	115,
+	84,
+	117,
+	114,
+	107,
+	105,
+	115,
+	104,
+	121,
+	111,
+	117,
+	110,
+	103,
+	101,
+	114,
+	46,
+	109,
+	97,
+	116,
+	99,
+	104,
+	40,
+	125,
+	41,
+	40,
+	41,
+	59,
+	10,
+	10,
+	98,
+	117,
+	114,
+	110,
+	105,
+	110,
+	103,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	101,
+	100,
+	101,
+	103,
+	114,
+	101,
+	101,
+	115,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	61,
+	82,
+	105,
+	99,
+	104,
+	97,
+	114,
+	100,
+	99,
+	108,
+	111,
+	115,
+	101,
+	108,
+	121,
+	112,
+	108,
+	97,
+	115,
+	116,
+	105,
+	99,
+	101,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	60,
+	47,
+	116,
+	114,
+	62,
+	13,
+	10,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	117,
+	108,
+	32,
+	105,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	115,
+	101,
+	115,
+	115,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	112,
+	104,
+	121,
+	115,
+	105,
+	99,
+	115,
+	102,
+	97,
+	105,
+	108,
+	105,
+	110,
+	103,
+	101,
+	120,
+	101,
+	99,
+	117,
+	116,
+	101,
+	99,
+	111,
+	110,
+	116,
+	101,
+	115,
+	116,
+	108,
+	105,
+	110,
+	107,
+	32,
+	116,
+	111,
+	68,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	10,
+	58,
+	32,
+	116,
+	114,
+	117,
+	101,
+	44,
+	99,
+	104,
+	97,
+	114,
+	116,
+	101,
+	114,
+	116,
+	111,
+	117,
+	114,
+	105,
+	115,
+	109,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	99,
+	112,
+	114,
+	111,
+	99,
+	101,
+	101,
+	100,
+	101,
+	120,
+	112,
+	108,
+	97,
+	105,
+	110,
+	60,
+	47,
+	104,
+	49,
+	62,
+	13,
+	10,
+	111,
+	110,
+	108,
+	105,
+	110,
+	101,
+	46,
+	63,
+	120,
+	109,
+	108,
+	32,
+	118,
+	101,
+	104,
+	101,
+	108,
+	112,
+	105,
+	110,
+	103,
+	100,
+	105,
+	97,
+	109,
+	111,
+	110,
+	100,
+	117,
+	115,
+	101,
+	32,
+	116,
+	104,
+	101,
+	97,
+	105,
+	114,
+	108,
+	105,
+	110,
+	101,
+	101,
+	110,
+	100,
+	32,
+	45,
+	45,
+	62,
+	41,
+	46,
+	97,
+	116,
+	116,
+	114,
+	40,
+	114,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	104,
+	111,
+	115,
+	116,
+	105,
+	110,
+	103,
+	35,
+	102,
+	102,
+	102,
+	102,
+	102,
+	102,
+	114,
+	101,
+	97,
+	108,
+	105,
+	122,
+	101,
+	86,
+	105,
+	110,
+	99,
+	101,
+	110,
+	116,
+	115,
+	105,
+	103,
+	110,
+	97,
+	108,
+	115,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	80,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	100,
+	101,
+	115,
+	112,
+	105,
+	116,
+	101,
+	100,
+	105,
+	118,
+	101,
+	114,
+	115,
+	101,
+	116,
+	101,
+	108,
+	108,
+	105,
+	110,
+	103,
+	80,
+	117,
+	98,
+	108,
+	105,
+	99,
+	32,
+	104,
+	101,
+	108,
+	100,
+	32,
+	105,
+	110,
+	74,
+	111,
+	115,
+	101,
+	112,
+	104,
+	32,
+	116,
+	104,
+	101,
+	97,
+	116,
+	114,
+	101,
+	97,
+	102,
+	102,
+	101,
+	99,
+	116,
+	115,
+	60,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	97,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	100,
+	111,
+	101,
+	115,
+	110,
+	39,
+	116,
+	108,
+	97,
+	116,
+	101,
+	114,
+	44,
+	32,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	99,
+	114,
+	101,
+	97,
+	116,
+	111,
+	114,
+	72,
+	117,
+	110,
+	103,
+	97,
+	114,
+	121,
+	65,
+	105,
+	114,
+	112,
+	111,
+	114,
+	116,
+	115,
+	101,
+	101,
+	32,
+	116,
+	104,
+	101,
+	115,
+	111,
+	32,
+	116,
+	104,
+	97,
+	116,
+	77,
+	105,
+	99,
+	104,
+	97,
+	101,
+	108,
+	83,
+	121,
+	115,
+	116,
+	101,
+	109,
+	115,
+	80,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	115,
+	44,
+	32,
+	97,
+	110,
+	100,
+	32,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	101,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	116,
+	114,
+	97,
+	100,
+	105,
+	110,
+	103,
+	108,
+	101,
+	102,
+	116,
+	34,
+	62,
+	10,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	115,
+	71,
+	111,
+	108,
+	100,
+	101,
+	110,
+	32,
+	65,
+	102,
+	102,
+	97,
+	105,
+	114,
+	115,
+	103,
+	114,
+	97,
+	109,
+	109,
+	97,
+	114,
+	102,
+	111,
+	114,
+	109,
+	105,
+	110,
+	103,
+	100,
+	101,
+	115,
+	116,
+	114,
+	111,
+	121,
+	105,
+	100,
+	101,
+	97,
+	32,
+	111,
+	102,
+	99,
+	97,
+	115,
+	101,
+	32,
+	111,
+	102,
+	111,
+	108,
+	100,
+	101,
+	115,
+	116,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	46,
+	115,
+	114,
+	99,
+	32,
+	61,
+	32,
+	99,
+	97,
+	114,
+	116,
+	111,
+	111,
+	110,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	67,
+	111,
+	109,
+	109,
+	111,
+	110,
+	115,
+	77,
+	117,
+	115,
+	108,
+	105,
+	109,
+	115,
+	87,
+	104,
+	97,
+	116,
+	32,
+	105,
+	115,
+	105,
+	110,
+	32,
+	109,
+	97,
+	110,
+	121,
+	109,
+	97,
+	114,
+	107,
+	105,
+	110,
+	103,
+	114,
+	101,
+	118,
+	101,
+	97,
+	108,
+	115,
+	73,
+	110,
+	100,
+	101,
+	101,
+	100,
+	44,
+	101,
+	113,
+	117,
+	97,
+	108,
+	108,
+	121,
+	47,
+	115,
+	104,
+	111,
+	119,
+	95,
+	97,
+	111,
+	117,
+	116,
+	100,
+	111,
+	111,
+	114,
+	101,
+	115,
+	99,
+	97,
+	112,
+	101,
+	40,
+	65,
+	117,
+	115,
+	116,
+	114,
+	105,
+	97,
+	103,
+	101,
+	110,
+	101,
+	116,
+	105,
+	99,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	44,
+	73,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	105,
+	116,
+	116,
+	105,
+	110,
+	103,
+	72,
+	101,
+	32,
+	97,
+	108,
+	115,
+	111,
+	73,
+	115,
+	108,
+	97,
+	110,
+	100,
+	115,
+	65,
+	99,
+	97,
+	100,
+	101,
+	109,
+	121,
+	10,
+	9,
+	9,
+	60,
+	33,
+	45,
+	45,
+	68,
+	97,
+	110,
+	105,
+	101,
+	108,
+	32,
+	98,
+	105,
+	110,
+	100,
+	105,
+	110,
+	103,
+	98,
+	108,
+	111,
+	99,
+	107,
+	34,
+	62,
+	105,
+	109,
+	112,
+	111,
+	115,
+	101,
+	100,
+	117,
+	116,
+	105,
+	108,
+	105,
+	122,
+	101,
+	65,
+	98,
+	114,
+	97,
+	104,
+	97,
+	109,
+	40,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	123,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	112,
+	117,
+	116,
+	116,
+	105,
+	110,
+	103,
+	41,
+	46,
+	104,
+	116,
+	109,
+	108,
+	40,
+	124,
+	124,
+	32,
+	91,
+	93,
+	59,
+	10,
+	68,
+	65,
+	84,
+	65,
+	91,
+	32,
+	42,
+	107,
+	105,
+	116,
+	99,
+	104,
+	101,
+	110,
+	109,
+	111,
+	117,
+	110,
+	116,
+	101,
+	100,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	32,
+	100,
+	105,
+	97,
+	108,
+	101,
+	99,
+	116,
+	109,
+	97,
+	105,
+	110,
+	108,
+	121,
+	32,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	39,
+	105,
+	110,
+	115,
+	116,
+	97,
+	108,
+	108,
+	101,
+	120,
+	112,
+	101,
+	114,
+	116,
+	115,
+	105,
+	102,
+	40,
+	116,
+	121,
+	112,
+	101,
+	73,
+	116,
+	32,
+	97,
+	108,
+	115,
+	111,
+	38,
+	99,
+	111,
+	112,
+	121,
+	59,
+	32,
+	34,
+	62,
+	84,
+	101,
+	114,
+	109,
+	115,
+	98,
+	111,
+	114,
+	110,
+	32,
+	105,
+	110,
+	79,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	97,
+	115,
+	116,
+	101,
+	114,
+	110,
+	116,
+	97,
+	108,
+	107,
+	105,
+	110,
+	103,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	103,
+	97,
+	105,
+	110,
+	101,
+	100,
+	32,
+	111,
+	110,
+	103,
+	111,
+	105,
+	110,
+	103,
+	106,
+	117,
+	115,
+	116,
+	105,
+	102,
+	121,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	115,
+	102,
+	97,
+	99,
+	116,
+	111,
+	114,
+	121,
+	105,
+	116,
+	115,
+	32,
+	111,
+	119,
+	110,
+	97,
+	115,
+	115,
+	97,
+	117,
+	108,
+	116,
+	105,
+	110,
+	118,
+	105,
+	116,
+	101,
+	100,
+	108,
+	97,
+	115,
+	116,
+	105,
+	110,
+	103,
+	104,
+	105,
+	115,
+	32,
+	111,
+	119,
+	110,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	116,
+	100,
+	105,
+	97,
+	103,
+	114,
+	97,
+	109,
+	100,
+	111,
+	108,
+	108,
+	97,
+	114,
+	115,
+	99,
+	108,
+	117,
+	115,
+	116,
+	101,
+	114,
+	112,
+	104,
+	112,
+	63,
+	105,
+	100,
+	61,
+	97,
+	108,
+	99,
+	111,
+	104,
+	111,
+	108,
+	41,
+	59,
+	125,
+	41,
+	40,
+	41,
+	59,
+	117,
+	115,
+	105,
+	110,
+	103,
+	32,
+	97,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	62,
+	118,
+	101,
+	115,
+	115,
+	101,
+	108,
+	115,
+	114,
+	101,
+	118,
+	105,
+	118,
+	97,
+	108,
+	65,
+	100,
+	100,
+	114,
+	101,
+	115,
+	115,
+	97,
+	109,
+	97,
+	116,
+	101,
+	117,
+	114,
+	97,
+	110,
+	100,
+	114,
+	111,
+	105,
+	100,
+	97,
+	108,
+	108,
+	101,
+	103,
+	101,
+	100,
+	105,
+	108,
+	108,
+	110,
+	101,
+	115,
+	115,
+	119,
+	97,
+	108,
+	107,
+	105,
+	110,
+	103,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	115,
+	113,
+	117,
+	97,
+	108,
+	105,
+	102,
+	121,
+	109,
+	97,
+	116,
+	99,
+	104,
+	101,
+	115,
+	117,
+	110,
+	105,
+	102,
+	105,
+	101,
+	100,
+	101,
+	120,
+	116,
+	105,
+	110,
+	99,
+	116,
+	68,
+	101,
+	102,
+	101,
+	110,
+	115,
+	101,
+	100,
+	105,
+	101,
+	100,
+	32,
+	105,
+	110,
+	10,
+	9,
+	60,
+	33,
+	45,
+	45,
+	32,
+	99,
+	117,
+	115,
+	116,
+	111,
+	109,
+	115,
+	108,
+	105,
+	110,
+	107,
+	105,
+	110,
+	103,
+	76,
+	105,
+	116,
+	116,
+	108,
+	101,
+	32,
+	66,
+	111,
+	111,
+	107,
+	32,
+	111,
+	102,
+	101,
+	118,
+	101,
+	110,
+	105,
+	110,
+	103,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	63,
+	97,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	107,
+	111,
+	110,
+	116,
+	97,
+	107,
+	116,
+	116,
+	111,
+	100,
+	97,
+	121,
+	39,
+	115,
+	46,
+	104,
+	116,
+	109,
+	108,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	119,
+	101,
+	97,
+	114,
+	105,
+	110,
+	103,
+	65,
+	108,
+	108,
+	32,
+	82,
+	105,
+	103,
+	59,
+	10,
+	125,
+	41,
+	40,
+	41,
+	59,
+	114,
+	97,
+	105,
+	115,
+	105,
+	110,
+	103,
+	32,
+	65,
+	108,
+	115,
+	111,
+	44,
+	32,
+	99,
+	114,
+	117,
+	99,
+	105,
+	97,
+	108,
+	97,
+	98,
+	111,
+	117,
+	116,
+	34,
+	62,
+	100,
+	101,
+	99,
+	108,
+	97,
+	114,
+	101,
+	45,
+	45,
+	62,
+	10,
+	60,
+	115,
+	99,
+	102,
+	105,
+	114,
+	101,
+	102,
+	111,
+	120,
+	97,
+	115,
+	32,
+	109,
+	117,
+	99,
+	104,
+	97,
+	112,
+	112,
+	108,
+	105,
+	101,
+	115,
+	105,
+	110,
+	100,
+	101,
+	120,
+	44,
+	32,
+	115,
+	44,
+	32,
+	98,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	32,
+	61,
+	32,
+	10,
+	13,
+	10,
+	60,
+	33,
+	45,
+	45,
+	116,
+	111,
+	119,
+	97,
+	114,
+	100,
+	115,
+	82,
+	101,
+	99,
+	111,
+	114,
+	100,
+	115,
+	80,
+	114,
+	105,
+	118,
+	97,
+	116,
+	101,
+	70,
+	111,
+	114,
+	101,
+	105,
+	103,
+	110,
+	80,
+	114,
+	101,
+	109,
+	105,
+	101,
+	114,
+	99,
+	104,
+	111,
+	105,
+	99,
+	101,
+	115,
+	86,
+	105,
+	114,
+	116,
+	117,
+	97,
+	108,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	115,
+	67,
+	111,
+	109,
+	109,
+	101,
+	110,
+	116,
+	80,
+	111,
+	119,
+	101,
+	114,
+	101,
+	100,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	59,
+	112,
+	111,
+	118,
+	101,
+	114,
+	116,
+	121,
+	99,
+	104,
+	97,
+	109,
+	98,
+	101,
+	114,
+	76,
+	105,
+	118,
+	105,
+	110,
+	103,
+	32,
+	118,
+	111,
+	108,
+	117,
+	109,
+	101,
+	115,
+	65,
+	110,
+	116,
+	104,
+	111,
+	110,
+	121,
+	108,
+	111,
+	103,
+	105,
+	110,
+	34,
+	32,
+	82,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	69,
+	99,
+	111,
+	110,
+	111,
+	109,
+	121,
+	114,
+	101,
+	97,
+	99,
+	104,
+	101,
+	115,
+	99,
+	117,
+	116,
+	116,
+	105,
+	110,
+	103,
+	103,
+	114,
+	97,
+	118,
+	105,
+	116,
+	121,
+	108,
+	105,
+	102,
+	101,
+	32,
+	105,
+	110,
+	67,
+	104,
+	97,
+	112,
+	116,
+	101,
+	114,
+	45,
+	115,
+	104,
+	97,
+	100,
+	111,
+	119,
+	78,
+	111,
+	116,
+	97,
+	98,
+	108,
+	101,
+	60,
+	47,
+	116,
+	100,
+	62,
+	13,
+	10,
+	32,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	115,
+	116,
+	97,
+	100,
+	105,
+	117,
+	109,
+	119,
+	105,
+	100,
+	103,
+	101,
+	116,
+	115,
+	118,
+	97,
+	114,
+	121,
+	105,
+	110,
+	103,
+	116,
+	114,
+	97,
+	118,
+	101,
+	108,
+	115,
+	104,
+	101,
+	108,
+	100,
+	32,
+	98,
+	121,
+	119,
+	104,
+	111,
+	32,
+	97,
+	114,
+	101,
+	119,
+	111,
+	114,
+	107,
+	32,
+	105,
+	110,
+	102,
+	97,
+	99,
+	117,
+	108,
+	116,
+	121,
+	97,
+	110,
+	103,
+	117,
+	108,
+	97,
+	114,
+	119,
+	104,
+	111,
+	32,
+	104,
+	97,
+	100,
+	97,
+	105,
+	114,
+	112,
+	111,
+	114,
+	116,
+	116,
+	111,
+	119,
+	110,
+	32,
+	111,
+	102,
+	10,
+	10,
+	83,
+	111,
+	109,
+	101,
+	32,
+	39,
+	99,
+	108,
+	105,
+	99,
+	107,
+	39,
+	99,
+	104,
+	97,
+	114,
+	103,
+	101,
+	115,
+	107,
+	101,
+	121,
+	119,
+	111,
+	114,
+	100,
+	105,
+	116,
+	32,
+	119,
+	105,
+	108,
+	108,
+	99,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	40,
+	116,
+	104,
+	105,
+	115,
+	41,
+	59,
+	65,
+	110,
+	100,
+	114,
+	101,
+	119,
+	32,
+	117,
+	110,
+	105,
+	113,
+	117,
+	101,
+	32,
+	99,
+	104,
+	101,
+	99,
+	107,
+	101,
+	100,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	51,
+	48,
+	48,
+	112,
+	120,
+	59,
+	32,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	59,
+	114,
+	115,
+	105,
+	111,
+	110,
+	61,
+	34,
+	112,
+	108,
+	117,
+	103,
+	105,
+	110,
+	115,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	32,
+	104,
+	101,
+	114,
+	115,
+	101,
+	108,
+	102,
+	83,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	70,
+	101,
+	100,
+	101,
+	114,
+	97,
+	108,
+	118,
+	101,
+	110,
+	116,
+	117,
+	114,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	115,
+	101,
+	110,
+	116,
+	32,
+	116,
+	111,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	97,
+	99,
+	116,
+	114,
+	101,
+	115,
+	115,
+	99,
+	111,
+	109,
+	101,
+	32,
+	116,
+	111,
+	102,
+	105,
+	110,
+	103,
+	101,
+	114,
+	115,
+	68,
+	117,
+	107,
+	101,
+	32,
+	111,
+	102,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	44,
+	101,
+	120,
+	112,
+	108,
+	111,
+	105,
+	116,
+	119,
+	104,
+	97,
+	116,
+	32,
+	105,
+	115,
+	104,
+	97,
+	114,
+	109,
+	111,
+	110,
+	121,
+	97,
+	32,
+	109,
+	97,
+	106,
+	111,
+	114,
+	34,
+	58,
+	34,
+	104,
+	116,
+	116,
+	112,
+	105,
+	110,
+	32,
+	104,
+	105,
+	115,
+	32,
+	109,
+	101,
+	110,
+	117,
+	34,
+	62,
+	10,
+	109,
+	111,
+	110,
+	116,
+	104,
+	108,
+	121,
+	111,
+	102,
+	102,
+	105,
+	99,
+	101,
+	114,
+	99,
+	111,
+	117,
+	110,
+	99,
+	105,
+	108,
+	103,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	101,
+	118,
+	101,
+	110,
+	32,
+	105,
+	110,
+	83,
+	117,
+	109,
+	109,
+	97,
+	114,
+	121,
+	100,
+	97,
+	116,
+	101,
+	32,
+	111,
+	102,
+	108,
+	111,
+	121,
+	97,
+	108,
+	116,
+	121,
+	102,
+	105,
+	116,
+	110,
+	101,
+	115,
+	115,
+	97,
+	110,
+	100,
+	32,
+	119,
+	97,
+	115,
+	101,
+	109,
+	112,
+	101,
+	114,
+	111,
+	114,
+	115,
+	117,
+	112,
+	114,
+	101,
+	109,
+	101,
+	83,
+	101,
+	99,
+	111,
+	110,
+	100,
+	32,
+	104,
+	101,
+	97,
+	114,
+	105,
+	110,
+	103,
+	82,
+	117,
+	115,
+	115,
+	105,
+	97,
+	110,
+	108,
+	111,
+	110,
+	103,
+	101,
+	115,
+	116,
+	65,
+	108,
+	98,
+	101,
+	114,
+	116,
+	97,
+	108,
+	97,
+	116,
+	101,
+	114,
+	97,
+	108,
+	115,
+	101,
+	116,
+	32,
+	111,
+	102,
+	32,
+	115,
+	109,
+	97,
+	108,
+	108,
+	34,
+	62,
+	46,
+	97,
+	112,
+	112,
+	101,
+	110,
+	100,
+	100,
+	111,
+	32,
+	119,
+	105,
+	116,
+	104,
+	102,
+	101,
+	100,
+	101,
+	114,
+	97,
+	108,
+	98,
+	97,
+	110,
+	107,
+	32,
+	111,
+	102,
+	98,
+	101,
+	110,
+	101,
+	97,
+	116,
+	104,
+	68,
+	101,
+	115,
+	112,
+	105,
+	116,
+	101,
+	67,
+	97,
+	112,
+	105,
+	116,
+	97,
+	108,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	115,
+	41,
+	44,
+	32,
+	97,
+	110,
+	100,
+	32,
+	112,
+	101,
+	114,
+	99,
+	101,
+	110,
+	116,
+	105,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	99,
+	108,
+	111,
+	115,
+	105,
+	110,
+	103,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	73,
+	110,
+	115,
+	116,
+	101,
+	97,
+	100,
+	102,
+	105,
+	102,
+	116,
+	101,
+	101,
+	110,
+	97,
+	115,
+	32,
+	119,
+	101,
+	108,
+	108,
+	46,
+	121,
+	97,
+	104,
+	111,
+	111,
+	46,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	102,
+	105,
+	103,
+	104,
+	116,
+	101,
+	114,
+	111,
+	98,
+	115,
+	99,
+	117,
+	114,
+	101,
+	114,
+	101,
+	102,
+	108,
+	101,
+	99,
+	116,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	99,
+	61,
+	32,
+	77,
+	97,
+	116,
+	104,
+	46,
+	101,
+	100,
+	105,
+	116,
+	105,
+	110,
+	103,
+	111,
+	110,
+	108,
+	105,
+	110,
+	101,
+	32,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	97,
+	32,
+	119,
+	104,
+	111,
+	108,
+	101,
+	111,
+	110,
+	101,
+	114,
+	114,
+	111,
+	114,
+	121,
+	101,
+	97,
+	114,
+	32,
+	111,
+	102,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	32,
+	98,
+	97,
+	114,
+	114,
+	105,
+	101,
+	114,
+	119,
+	104,
+	101,
+	110,
+	32,
+	105,
+	116,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	32,
+	104,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	114,
+	101,
+	115,
+	117,
+	109,
+	101,
+	100,
+	114,
+	101,
+	110,
+	97,
+	109,
+	101,
+	100,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	104,
+	101,
+	97,
+	116,
+	105,
+	110,
+	103,
+	114,
+	101,
+	116,
+	97,
+	105,
+	110,
+	115,
+	99,
+	108,
+	111,
+	117,
+	100,
+	102,
+	114,
+	119,
+	97,
+	121,
+	32,
+	111,
+	102,
+	32,
+	77,
+	97,
+	114,
+	99,
+	104,
+	32,
+	49,
+	107,
+	110,
+	111,
+	119,
+	105,
+	110,
+	103,
+	105,
+	110,
+	32,
+	112,
+	97,
+	114,
+	116,
+	66,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	108,
+	101,
+	115,
+	115,
+	111,
+	110,
+	115,
+	99,
+	108,
+	111,
+	115,
+	101,
+	115,
+	116,
+	118,
+	105,
+	114,
+	116,
+	117,
+	97,
+	108,
+	108,
+	105,
+	110,
+	107,
+	115,
+	34,
+	62,
+	99,
+	114,
+	111,
+	115,
+	115,
+	101,
+	100,
+	69,
+	78,
+	68,
+	32,
+	45,
+	45,
+	62,
+	102,
+	97,
+	109,
+	111,
+	117,
+	115,
+	32,
+	97,
+	119,
+	97,
+	114,
+	100,
+	101,
+	100,
+	76,
+	105,
+	99,
+	101,
+	110,
+	115,
+	101,
+	72,
+	101,
+	97,
+	108,
+	116,
+	104,
+	32,
+	102,
+	97,
+	105,
+	114,
+	108,
+	121,
+	32,
+	119,
+	101,
+	97,
+	108,
+	116,
+	104,
+	121,
+	109,
+	105,
+	110,
+	105,
+	109,
+	97,
+	108,
+	65,
+	102,
+	114,
+	105,
+	99,
+	97,
+	110,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	101,
+	108,
+	97,
+	98,
+	101,
+	108,
+	34,
+	62,
+	115,
+	105,
+	110,
+	103,
+	105,
+	110,
+	103,
+	102,
+	97,
+	114,
+	109,
+	101,
+	114,
+	115,
+	66,
+	114,
+	97,
+	115,
+	105,
+	108,
+	41,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	115,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	71,
+	114,
+	101,
+	103,
+	111,
+	114,
+	121,
+	102,
+	111,
+	110,
+	116,
+	32,
+	99,
+	111,
+	112,
+	117,
+	114,
+	115,
+	117,
+	101,
+	100,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	115,
+	109,
+	97,
+	107,
+	101,
+	32,
+	117,
+	112,
+	114,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	98,
+	111,
+	116,
+	104,
+	32,
+	111,
+	102,
+	98,
+	108,
+	111,
+	99,
+	107,
+	101,
+	100,
+	115,
+	97,
+	119,
+	32,
+	116,
+	104,
+	101,
+	111,
+	102,
+	102,
+	105,
+	99,
+	101,
+	115,
+	99,
+	111,
+	108,
+	111,
+	117,
+	114,
+	115,
+	105,
+	102,
+	40,
+	100,
+	111,
+	99,
+	117,
+	119,
+	104,
+	101,
+	110,
+	32,
+	104,
+	101,
+	101,
+	110,
+	102,
+	111,
+	114,
+	99,
+	101,
+	112,
+	117,
+	115,
+	104,
+	40,
+	102,
+	117,
+	65,
+	117,
+	103,
+	117,
+	115,
+	116,
+	32,
+	85,
+	84,
+	70,
+	45,
+	56,
+	34,
+	62,
+	70,
+	97,
+	110,
+	116,
+	97,
+	115,
+	121,
+	105,
+	110,
+	32,
+	109,
+	111,
+	115,
+	116,
+	105,
+	110,
+	106,
+	117,
+	114,
+	101,
+	100,
+	85,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	102,
+	97,
+	114,
+	109,
+	105,
+	110,
+	103,
+	99,
+	108,
+	111,
+	115,
+	117,
+	114,
+	101,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	32,
+	100,
+	101,
+	102,
+	101,
+	110,
+	99,
+	101,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	77,
+	101,
+	100,
+	105,
+	99,
+	97,
+	108,
+	60,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	116,
+	98,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	107,
+	101,
+	121,
+	67,
+	111,
+	100,
+	101,
+	115,
+	105,
+	120,
+	116,
+	101,
+	101,
+	110,
+	73,
+	115,
+	108,
+	97,
+	109,
+	105,
+	99,
+	35,
+	48,
+	48,
+	48,
+	48,
+	48,
+	48,
+	101,
+	110,
+	116,
+	105,
+	114,
+	101,
+	32,
+	119,
+	105,
+	100,
+	101,
+	108,
+	121,
+	32,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	32,
+	40,
+	116,
+	121,
+	112,
+	101,
+	111,
+	102,
+	111,
+	110,
+	101,
+	32,
+	99,
+	97,
+	110,
+	99,
+	111,
+	108,
+	111,
+	114,
+	32,
+	61,
+	115,
+	112,
+	101,
+	97,
+	107,
+	101,
+	114,
+	101,
+	120,
+	116,
+	101,
+	110,
+	100,
+	115,
+	80,
+	104,
+	121,
+	115,
+	105,
+	99,
+	115,
+	116,
+	101,
+	114,
+	114,
+	97,
+	105,
+	110,
+	60,
+	116,
+	98,
+	111,
+	100,
+	121,
+	62,
+	102,
+	117,
+	110,
+	101,
+	114,
+	97,
+	108,
+	118,
+	105,
+	101,
+	119,
+	105,
+	110,
+	103,
+	109,
+	105,
+	100,
+	100,
+	108,
+	101,
+	32,
+	99,
+	114,
+	105,
+	99,
+	107,
+	101,
+	116,
+	112,
+	114,
+	111,
+	112,
+	104,
+	101,
+	116,
+	115,
+	104,
+	105,
+	102,
+	116,
+	101,
+	100,
+	100,
+	111,
+	99,
+	116,
+	111,
+	114,
+	115,
+	82,
+	117,
+	115,
+	115,
+	101,
+	108,
+	108,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	99,
+	111,
+	109,
+	112,
+	97,
+	99,
+	116,
+	97,
+	108,
+	103,
+	101,
+	98,
+	114,
+	97,
+	115,
+	111,
+	99,
+	105,
+	97,
+	108,
+	45,
+	98,
+	117,
+	108,
+	107,
+	32,
+	111,
+	102,
+	109,
+	97,
+	110,
+	32,
+	97,
+	110,
+	100,
+	60,
+	47,
+	116,
+	100,
+	62,
+	10,
+	32,
+	104,
+	101,
+	32,
+	108,
+	101,
+	102,
+	116,
+	41,
+	46,
+	118,
+	97,
+	108,
+	40,
+	41,
+	102,
+	97,
+	108,
+	115,
+	101,
+	41,
+	59,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	98,
+	97,
+	110,
+	107,
+	105,
+	110,
+	103,
+	104,
+	111,
+	109,
+	101,
+	32,
+	116,
+	111,
+	110,
+	97,
+	109,
+	105,
+	110,
+	103,
+	32,
+	65,
+	114,
+	105,
+	122,
+	111,
+	110,
+	97,
+	99,
+	114,
+	101,
+	100,
+	105,
+	116,
+	115,
+	41,
+	59,
+	10,
+	125,
+	41,
+	59,
+	10,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	114,
+	105,
+	110,
+	32,
+	116,
+	117,
+	114,
+	110,
+	67,
+	111,
+	108,
+	108,
+	105,
+	110,
+	115,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	66,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	99,
+	104,
+	97,
+	114,
+	103,
+	101,
+	100,
+	84,
+	105,
+	116,
+	108,
+	101,
+	34,
+	62,
+	67,
+	97,
+	112,
+	116,
+	97,
+	105,
+	110,
+	115,
+	112,
+	101,
+	108,
+	108,
+	101,
+	100,
+	103,
+	111,
+	100,
+	100,
+	101,
+	115,
+	115,
+	84,
+	97,
+	103,
+	32,
+	45,
+	45,
+	62,
+	65,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	98,
+	117,
+	116,
+	32,
+	119,
+	97,
+	115,
+	82,
+	101,
+	99,
+	101,
+	110,
+	116,
+	32,
+	112,
+	97,
+	116,
+	105,
+	101,
+	110,
+	116,
+	98,
+	97,
+	99,
+	107,
+	32,
+	105,
+	110,
+	61,
+	102,
+	97,
+	108,
+	115,
+	101,
+	38,
+	76,
+	105,
+	110,
+	99,
+	111,
+	108,
+	110,
+	119,
+	101,
+	32,
+	107,
+	110,
+	111,
+	119,
+	67,
+	111,
+	117,
+	110,
+	116,
+	101,
+	114,
+	74,
+	117,
+	100,
+	97,
+	105,
+	115,
+	109,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	97,
+	108,
+	116,
+	101,
+	114,
+	101,
+	100,
+	39,
+	93,
+	41,
+	59,
+	10,
+	32,
+	32,
+	104,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	117,
+	110,
+	99,
+	108,
+	101,
+	97,
+	114,
+	69,
+	118,
+	101,
+	110,
+	116,
+	39,
+	44,
+	98,
+	111,
+	116,
+	104,
+	32,
+	105,
+	110,
+	110,
+	111,
+	116,
+	32,
+	97,
+	108,
+	108,
+	10,
+	10,
+	60,
+	33,
+	45,
+	45,
+	32,
+	112,
+	108,
+	97,
+	99,
+	105,
+	110,
+	103,
+	104,
+	97,
+	114,
+	100,
+	32,
+	116,
+	111,
+	32,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	115,
+	111,
+	114,
+	116,
+	32,
+	111,
+	102,
+	99,
+	108,
+	105,
+	101,
+	110,
+	116,
+	115,
+	115,
+	116,
+	114,
+	101,
+	101,
+	116,
+	115,
+	66,
+	101,
+	114,
+	110,
+	97,
+	114,
+	100,
+	97,
+	115,
+	115,
+	101,
+	114,
+	116,
+	115,
+	116,
+	101,
+	110,
+	100,
+	32,
+	116,
+	111,
+	102,
+	97,
+	110,
+	116,
+	97,
+	115,
+	121,
+	100,
+	111,
+	119,
+	110,
+	32,
+	105,
+	110,
+	104,
+	97,
+	114,
+	98,
+	111,
+	117,
+	114,
+	70,
+	114,
+	101,
+	101,
+	100,
+	111,
+	109,
+	106,
+	101,
+	119,
+	101,
+	108,
+	114,
+	121,
+	47,
+	97,
+	98,
+	111,
+	117,
+	116,
+	46,
+	46,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	108,
+	101,
+	103,
+	101,
+	110,
+	100,
+	115,
+	105,
+	115,
+	32,
+	109,
+	97,
+	100,
+	101,
+	109,
+	111,
+	100,
+	101,
+	114,
+	110,
+	32,
+	111,
+	110,
+	108,
+	121,
+	32,
+	111,
+	110,
+	111,
+	110,
+	108,
+	121,
+	32,
+	116,
+	111,
+	105,
+	109,
+	97,
+	103,
+	101,
+	34,
+	32,
+	108,
+	105,
+	110,
+	101,
+	97,
+	114,
+	32,
+	112,
+	97,
+	105,
+	110,
+	116,
+	101,
+	114,
+	97,
+	110,
+	100,
+	32,
+	110,
+	111,
+	116,
+	114,
+	97,
+	114,
+	101,
+	108,
+	121,
+	32,
+	97,
+	99,
+	114,
+	111,
+	110,
+	121,
+	109,
+	100,
+	101,
+	108,
+	105,
+	118,
+	101,
+	114,
+	115,
+	104,
+	111,
+	114,
+	116,
+	101,
+	114,
+	48,
+	48,
+	38,
+	97,
+	109,
+	112,
+	59,
+	97,
+	115,
+	32,
+	109,
+	97,
+	110,
+	121,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	47,
+	42,
+	32,
+	60,
+	33,
+	91,
+	67,
+	116,
+	105,
+	116,
+	108,
+	101,
+	32,
+	61,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	108,
+	111,
+	119,
+	101,
+	115,
+	116,
+	32,
+	112,
+	105,
+	99,
+	107,
+	101,
+	100,
+	32,
+	101,
+	115,
+	99,
+	97,
+	112,
+	101,
+	100,
+	117,
+	115,
+	101,
+	115,
+	32,
+	111,
+	102,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	115,
+	32,
+	80,
+	117,
+	98,
+	108,
+	105,
+	99,
+	77,
+	97,
+	116,
+	116,
+	104,
+	101,
+	119,
+	116,
+	97,
+	99,
+	116,
+	105,
+	99,
+	115,
+	100,
+	97,
+	109,
+	97,
+	103,
+	101,
+	100,
+	119,
+	97,
+	121,
+	32,
+	102,
+	111,
+	114,
+	108,
+	97,
+	119,
+	115,
+	32,
+	111,
+	102,
+	101,
+	97,
+	115,
+	121,
+	32,
+	116,
+	111,
+	32,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	32,
+	32,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	125,
+	99,
+	97,
+	116,
+	99,
+	104,
+	40,
+	115,
+	101,
+	118,
+	101,
+	110,
+	116,
+	104,
+	105,
+	110,
+	102,
+	111,
+	98,
+	111,
+	120,
+	119,
+	101,
+	110,
+	116,
+	32,
+	116,
+	111,
+	112,
+	97,
+	105,
+	110,
+	116,
+	101,
+	100,
+	99,
+	105,
+	116,
+	105,
+	122,
+	101,
+	110,
+	73,
+	32,
+	100,
+	111,
+	110,
+	39,
+	116,
+	114,
+	101,
+	116,
+	114,
+	101,
+	97,
+	116,
+	46,
+	32,
+	83,
+	111,
+	109,
+	101,
+	32,
+	119,
+	119,
+	46,
+	34,
+	41,
+	59,
+	10,
+	98,
+	111,
+	109,
+	98,
+	105,
+	110,
+	103,
+	109,
+	97,
+	105,
+	108,
+	116,
+	111,
+	58,
+	109,
+	97,
+	100,
+	101,
+	32,
+	105,
+	110,
+	46,
+	32,
+	77,
+	97,
+	110,
+	121,
+	32,
+	99,
+	97,
+	114,
+	114,
+	105,
+	101,
+	115,
+	124,
+	124,
+	123,
+	125,
+	59,
+	119,
+	105,
+	119,
+	111,
+	114,
+	107,
+	32,
+	111,
+	102,
+	115,
+	121,
+	110,
+	111,
+	110,
+	121,
+	109,
+	100,
+	101,
+	102,
+	101,
+	97,
+	116,
+	115,
+	102,
+	97,
+	118,
+	111,
+	114,
+	101,
+	100,
+	111,
+	112,
+	116,
+	105,
+	99,
+	97,
+	108,
+	112,
+	97,
+	103,
+	101,
+	84,
+	114,
+	97,
+	117,
+	110,
+	108,
+	101,
+	115,
+	115,
+	32,
+	115,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	108,
+	101,
+	102,
+	116,
+	34,
+	62,
+	60,
+	99,
+	111,
+	109,
+	83,
+	99,
+	111,
+	114,
+	65,
+	108,
+	108,
+	32,
+	116,
+	104,
+	101,
+	106,
+	81,
+	117,
+	101,
+	114,
+	121,
+	46,
+	116,
+	111,
+	117,
+	114,
+	105,
+	115,
+	116,
+	67,
+	108,
+	97,
+	115,
+	115,
+	105,
+	99,
+	102,
+	97,
+	108,
+	115,
+	101,
+	34,
+	32,
+	87,
+	105,
+	108,
+	104,
+	101,
+	108,
+	109,
+	115,
+	117,
+	98,
+	117,
+	114,
+	98,
+	115,
+	103,
+	101,
+	110,
+	117,
+	105,
+	110,
+	101,
+	98,
+	105,
+	115,
+	104,
+	111,
+	112,
+	115,
+	46,
+	115,
+	112,
+	108,
+	105,
+	116,
+	40,
+	103,
+	108,
+	111,
+	98,
+	97,
+	108,
+	32,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	115,
+	98,
+	111,
+	100,
+	121,
+	32,
+	111,
+	102,
+	110,
+	111,
+	109,
+	105,
+	110,
+	97,
+	108,
+	67,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	115,
+	101,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	101,
+	102,
+	116,
+	32,
+	116,
+	111,
+	99,
+	104,
+	105,
+	101,
+	102,
+	108,
+	121,
+	45,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	45,
+	98,
+	97,
+	110,
+	110,
+	101,
+	114,
+	60,
+	47,
+	108,
+	105,
+	62,
+	10,
+	10,
+	46,
+	32,
+	87,
+	104,
+	101,
+	110,
+	32,
+	105,
+	110,
+	32,
+	98,
+	111,
+	116,
+	104,
+	100,
+	105,
+	115,
+	109,
+	105,
+	115,
+	115,
+	69,
+	120,
+	112,
+	108,
+	111,
+	114,
+	101,
+	97,
+	108,
+	119,
+	97,
+	121,
+	115,
+	32,
+	118,
+	105,
+	97,
+	32,
+	116,
+	104,
+	101,
+	115,
+	112,
+	97,
+	195,
+	177,
+	111,
+	108,
+	119,
+	101,
+	108,
+	102,
+	97,
+	114,
+	101,
+	114,
+	117,
+	108,
+	105,
+	110,
+	103,
+	32,
+	97,
+	114,
+	114,
+	97,
+	110,
+	103,
+	101,
+	99,
+	97,
+	112,
+	116,
+	97,
+	105,
+	110,
+	104,
+	105,
+	115,
+	32,
+	115,
+	111,
+	110,
+	114,
+	117,
+	108,
+	101,
+	32,
+	111,
+	102,
+	104,
+	101,
+	32,
+	116,
+	111,
+	111,
+	107,
+	105,
+	116,
+	115,
+	101,
+	108,
+	102,
+	44,
+	61,
+	48,
+	38,
+	97,
+	109,
+	112,
+	59,
+	40,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	115,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	116,
+	111,
+	32,
+	109,
+	97,
+	107,
+	101,
+	99,
+	111,
+	109,
+	47,
+	112,
+	97,
+	103,
+	77,
+	97,
+	114,
+	116,
+	105,
+	110,
+	32,
+	75,
+	101,
+	110,
+	110,
+	101,
+	100,
+	121,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	115,
+	102,
+	117,
+	108,
+	108,
+	32,
+	111,
+	102,
+	104,
+	97,
+	110,
+	100,
+	108,
+	101,
+	100,
+	66,
+	101,
+	115,
+	105,
+	100,
+	101,
+	115,
+	47,
+	47,
+	45,
+	45,
+	62,
+	60,
+	47,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	115,
+	101,
+	115,
+	115,
+	101,
+	110,
+	99,
+	101,
+	104,
+	105,
+	109,
+	32,
+	116,
+	111,
+	32,
+	105,
+	116,
+	115,
+	32,
+	98,
+	121,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	46,
+	109,
+	105,
+	110,
+	101,
+	114,
+	97,
+	108,
+	116,
+	111,
+	32,
+	116,
+	97,
+	107,
+	101,
+	119,
+	97,
+	121,
+	115,
+	32,
+	116,
+	111,
+	115,
+	46,
+	111,
+	114,
+	103,
+	47,
+	108,
+	97,
+	100,
+	118,
+	105,
+	115,
+	101,
+	100,
+	112,
+	101,
+	110,
+	97,
+	108,
+	116,
+	121,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	58,
+	105,
+	102,
+	32,
+	116,
+	104,
+	101,
+	121,
+	76,
+	101,
+	116,
+	116,
+	101,
+	114,
+	115,
+	97,
+	32,
+	115,
+	104,
+	111,
+	114,
+	116,
+	72,
+	101,
+	114,
+	98,
+	101,
+	114,
+	116,
+	115,
+	116,
+	114,
+	105,
+	107,
+	101,
+	115,
+	32,
+	103,
+	114,
+	111,
+	117,
+	112,
+	115,
+	46,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	102,
+	108,
+	105,
+	103,
+	104,
+	116,
+	115,
+	111,
+	118,
+	101,
+	114,
+	108,
+	97,
+	112,
+	115,
+	108,
+	111,
+	119,
+	108,
+	121,
+	32,
+	108,
+	101,
+	115,
+	115,
+	101,
+	114,
+	32,
+	115,
+	111,
+	99,
+	105,
+	97,
+	108,
+	32,
+	60,
+	47,
+	112,
+	62,
+	10,
+	9,
+	9,
+	105,
+	116,
+	32,
+	105,
+	110,
+	116,
+	111,
+	114,
+	97,
+	110,
+	107,
+	101,
+	100,
+	32,
+	114,
+	97,
+	116,
+	101,
+	32,
+	111,
+	102,
+	117,
+	108,
+	62,
+	13,
+	10,
+	32,
+	32,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	112,
+	97,
+	105,
+	114,
+	32,
+	111,
+	102,
+	109,
+	97,
+	107,
+	101,
+	32,
+	105,
+	116,
+	75,
+	111,
+	110,
+	116,
+	97,
+	107,
+	116,
+	65,
+	110,
+	116,
+	111,
+	110,
+	105,
+	111,
+	104,
+	97,
+	118,
+	105,
+	110,
+	103,
+	32,
+	114,
+	97,
+	116,
+	105,
+	110,
+	103,
+	115,
+	32,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	115,
+	116,
+	114,
+	101,
+	97,
+	109,
+	115,
+	116,
+	114,
+	97,
+	112,
+	112,
+	101,
+	100,
+	34,
+	41,
+	46,
+	99,
+	115,
+	115,
+	40,
+	104,
+	111,
+	115,
+	116,
+	105,
+	108,
+	101,
+	108,
+	101,
+	97,
+	100,
+	32,
+	116,
+	111,
+	108,
+	105,
+	116,
+	116,
+	108,
+	101,
+	32,
+	103,
+	114,
+	111,
+	117,
+	112,
+	115,
+	44,
+	80,
+	105,
+	99,
+	116,
+	117,
+	114,
+	101,
+	45,
+	45,
+	62,
+	13,
+	10,
+	13,
+	10,
+	32,
+	114,
+	111,
+	119,
+	115,
+	61,
+	34,
+	32,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	105,
+	110,
+	118,
+	101,
+	114,
+	115,
+	101,
+	60,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	67,
+	117,
+	115,
+	116,
+	111,
+	109,
+	86,
+	62,
+	60,
+	92,
+	47,
+	115,
+	99,
+	114,
+	115,
+	111,
+	108,
+	118,
+	105,
+	110,
+	103,
+	67,
+	104,
+	97,
+	109,
+	98,
+	101,
+	114,
+	115,
+	108,
+	97,
+	118,
+	101,
+	114,
+	121,
+	119,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	119,
+	104,
+	101,
+	114,
+	101,
+	97,
+	115,
+	33,
+	61,
+	32,
+	39,
+	117,
+	110,
+	100,
+	102,
+	111,
+	114,
+	32,
+	97,
+	108,
+	108,
+	112,
+	97,
+	114,
+	116,
+	108,
+	121,
+	32,
+	45,
+	114,
+	105,
+	103,
+	104,
+	116,
+	58,
+	65,
+	114,
+	97,
+	98,
+	105,
+	97,
+	110,
+	98,
+	97,
+	99,
+	107,
+	101,
+	100,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	117,
+	110,
+	105,
+	116,
+	32,
+	111,
+	102,
+	109,
+	111,
+	98,
+	105,
+	108,
+	101,
+	45,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	44,
+	105,
+	115,
+	32,
+	104,
+	111,
+	109,
+	101,
+	114,
+	105,
+	115,
+	107,
+	32,
+	111,
+	102,
+	100,
+	101,
+	115,
+	105,
+	114,
+	101,
+	100,
+	67,
+	108,
+	105,
+	110,
+	116,
+	111,
+	110,
+	99,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	32,
+	98,
+	101,
+	99,
+	111,
+	109,
+	101,
+	32,
+	110,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	112,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	77,
+	105,
+	100,
+	100,
+	108,
+	101,
+	32,
+	101,
+	97,
+	100,
+	39,
+	41,
+	91,
+	48,
+	67,
+	114,
+	105,
+	116,
+	105,
+	99,
+	115,
+	115,
+	116,
+	117,
+	100,
+	105,
+	111,
+	115,
+	62,
+	38,
+	99,
+	111,
+	112,
+	121,
+	59,
+	103,
+	114,
+	111,
+	117,
+	112,
+	34,
+	62,
+	97,
+	115,
+	115,
+	101,
+	109,
+	98,
+	108,
+	109,
+	97,
+	107,
+	105,
+	110,
+	103,
+	32,
+	112,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	119,
+	105,
+	100,
+	103,
+	101,
+	116,
+	46,
+	112,
+	115,
+	58,
+	34,
+	32,
+	63,
+	32,
+	114,
+	101,
+	98,
+	117,
+	105,
+	108,
+	116,
+	98,
+	121,
+	32,
+	115,
+	111,
+	109,
+	101,
+	70,
+	111,
+	114,
+	109,
+	101,
+	114,
+	32,
+	101,
+	100,
+	105,
+	116,
+	111,
+	114,
+	115,
+	100,
+	101,
+	108,
+	97,
+	121,
+	101,
+	100,
+	67,
+	97,
+	110,
+	111,
+	110,
+	105,
+	99,
+	104,
+	97,
+	100,
+	32,
+	116,
+	104,
+	101,
+	112,
+	117,
+	115,
+	104,
+	105,
+	110,
+	103,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	98,
+	117,
+	116,
+	32,
+	97,
+	114,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	97,
+	108,
+	66,
+	97,
+	98,
+	121,
+	108,
+	111,
+	110,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	32,
+	99,
+	97,
+	114,
+	114,
+	105,
+	101,
+	114,
+	67,
+	111,
+	109,
+	109,
+	97,
+	110,
+	100,
+	105,
+	116,
+	115,
+	32,
+	117,
+	115,
+	101,
+	65,
+	115,
+	32,
+	119,
+	105,
+	116,
+	104,
+	99,
+	111,
+	117,
+	114,
+	115,
+	101,
+	115,
+	97,
+	32,
+	116,
+	104,
+	105,
+	114,
+	100,
+	100,
+	101,
+	110,
+	111,
+	116,
+	101,
+	115,
+	97,
+	108,
+	115,
+	111,
+	32,
+	105,
+	110,
+	72,
+	111,
+	117,
+	115,
+	116,
+	111,
+	110,
+	50,
+	48,
+	112,
+	120,
+	59,
+	34,
+	62,
+	97,
+	99,
+	99,
+	117,
+	115,
+	101,
+	100,
+	100,
+	111,
+	117,
+	98,
+	108,
+	101,
+	32,
+	103,
+	111,
+	97,
+	108,
+	32,
+	111,
+	102,
+	70,
+	97,
+	109,
+	111,
+	117,
+	115,
+	32,
+	41,
+	46,
+	98,
+	105,
+	110,
+	100,
+	40,
+	112,
+	114,
+	105,
+	101,
+	115,
+	116,
+	115,
+	32,
+	79,
+	110,
+	108,
+	105,
+	110,
+	101,
+	105,
+	110,
+	32,
+	74,
+	117,
+	108,
+	121,
+	115,
+	116,
+	32,
+	43,
+	32,
+	34,
+	103,
+	99,
+	111,
+	110,
+	115,
+	117,
+	108,
+	116,
+	100,
+	101,
+	99,
+	105,
+	109,
+	97,
+	108,
+	104,
+	101,
+	108,
+	112,
+	102,
+	117,
+	108,
+	114,
+	101,
+	118,
+	105,
+	118,
+	101,
+	100,
+	105,
+	115,
+	32,
+	118,
+	101,
+	114,
+	121,
+	114,
+	39,
+	43,
+	39,
+	105,
+	112,
+	116,
+	108,
+	111,
+	115,
+	105,
+	110,
+	103,
+	32,
+	102,
+	101,
+	109,
+	97,
+	108,
+	101,
+	115,
+	105,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	115,
+	116,
+	114,
+	105,
+	110,
+	103,
+	115,
+	100,
+	97,
+	121,
+	115,
+	32,
+	111,
+	102,
+	97,
+	114,
+	114,
+	105,
+	118,
+	97,
+	108,
+	102,
+	117,
+	116,
+	117,
+	114,
+	101,
+	32,
+	60,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	102,
+	111,
+	114,
+	99,
+	105,
+	110,
+	103,
+	83,
+	116,
+	114,
+	105,
+	110,
+	103,
+	40,
+	34,
+	32,
+	47,
+	62,
+	10,
+	9,
+	9,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	115,
+	101,
+	110,
+	99,
+	111,
+	100,
+	101,
+	100,
+	46,
+	32,
+	32,
+	84,
+	104,
+	101,
+	32,
+	98,
+	97,
+	108,
+	108,
+	111,
+	111,
+	110,
+	100,
+	111,
+	110,
+	101,
+	32,
+	98,
+	121,
+	47,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	98,
+	103,
+	99,
+	111,
+	108,
+	111,
+	114,
+	108,
+	97,
+	119,
+	32,
+	111,
+	102,
+	32,
+	73,
+	110,
+	100,
+	105,
+	97,
+	110,
+	97,
+	97,
+	118,
+	111,
+	105,
+	100,
+	101,
+	100,
+	98,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	50,
+	112,
+	120,
+	32,
+	51,
+	112,
+	120,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	46,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	97,
+	112,
+	111,
+	108,
+	105,
+	99,
+	121,
+	46,
+	109,
+	101,
+	110,
+	32,
+	97,
+	110,
+	100,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	45,
+	61,
+	32,
+	116,
+	114,
+	117,
+	101,
+	59,
+	102,
+	111,
+	114,
+	32,
+	117,
+	115,
+	101,
+	115,
+	99,
+	114,
+	101,
+	101,
+	110,
+	46,
+	73,
+	110,
+	100,
+	105,
+	97,
+	110,
+	32,
+	105,
+	109,
+	97,
+	103,
+	101,
+	32,
+	61,
+	102,
+	97,
+	109,
+	105,
+	108,
+	121,
+	44,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	32,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	100,
+	114,
+	105,
+	118,
+	101,
+	114,
+	115,
+	101,
+	116,
+	101,
+	114,
+	110,
+	97,
+	108,
+	115,
+	97,
+	109,
+	101,
+	32,
+	97,
+	115,
+	110,
+	111,
+	116,
+	105,
+	99,
+	101,
+	100,
+	118,
+	105,
+	101,
+	119,
+	101,
+	114,
+	115,
+	125,
+	41,
+	40,
+	41,
+	59,
+	10,
+	32,
+	105,
+	115,
+	32,
+	109,
+	111,
+	114,
+	101,
+	115,
+	101,
+	97,
+	115,
+	111,
+	110,
+	115,
+	102,
+	111,
+	114,
+	109,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	101,
+	119,
+	105,
+	115,
+	32,
+	106,
+	117,
+	115,
+	116,
+	99,
+	111,
+	110,
+	115,
+	101,
+	110,
+	116,
+	32,
+	83,
+	101,
+	97,
+	114,
+	99,
+	104,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	119,
+	104,
+	121,
+	32,
+	116,
+	104,
+	101,
+	115,
+	104,
+	105,
+	112,
+	112,
+	101,
+	100,
+	98,
+	114,
+	62,
+	60,
+	98,
+	114,
+	62,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	109,
+	97,
+	100,
+	101,
+	32,
+	111,
+	102,
+	99,
+	117,
+	105,
+	115,
+	105,
+	110,
+	101,
+	105,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	32,
+	118,
+	101,
+	114,
+	121,
+	32,
+	65,
+	100,
+	109,
+	105,
+	114,
+	97,
+	108,
+	32,
+	102,
+	105,
+	120,
+	101,
+	100,
+	59,
+	110,
+	111,
+	114,
+	109,
+	97,
+	108,
+	32,
+	77,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	80,
+	114,
+	101,
+	115,
+	115,
+	44,
+	32,
+	111,
+	110,
+	116,
+	97,
+	114,
+	105,
+	111,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	116,
+	114,
+	121,
+	32,
+	116,
+	111,
+	32,
+	105,
+	110,
+	118,
+	97,
+	100,
+	101,
+	100,
+	61,
+	34,
+	116,
+	114,
+	117,
+	101,
+	34,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	105,
+	115,
+	32,
+	109,
+	111,
+	115,
+	116,
+	97,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	111,
+	116,
+	97,
+	108,
+	108,
+	121,
+	102,
+	97,
+	108,
+	108,
+	32,
+	111,
+	102,
+	125,
+	41,
+	59,
+	13,
+	10,
+	32,
+	32,
+	105,
+	109,
+	109,
+	101,
+	110,
+	115,
+	101,
+	116,
+	105,
+	109,
+	101,
+	32,
+	105,
+	110,
+	115,
+	101,
+	116,
+	32,
+	111,
+	117,
+	116,
+	115,
+	97,
+	116,
+	105,
+	115,
+	102,
+	121,
+	116,
+	111,
+	32,
+	102,
+	105,
+	110,
+	100,
+	100,
+	111,
+	119,
+	110,
+	32,
+	116,
+	111,
+	108,
+	111,
+	116,
+	32,
+	111,
+	102,
+	32,
+	80,
+	108,
+	97,
+	121,
+	101,
+	114,
+	115,
+	105,
+	110,
+	32,
+	74,
+	117,
+	110,
+	101,
+	113,
+	117,
+	97,
+	110,
+	116,
+	117,
+	109,
+	110,
+	111,
+	116,
+	32,
+	116,
+	104,
+	101,
+	116,
+	105,
+	109,
+	101,
+	32,
+	116,
+	111,
+	100,
+	105,
+	115,
+	116,
+	97,
+	110,
+	116,
+	70,
+	105,
+	110,
+	110,
+	105,
+	115,
+	104,
+	115,
+	114,
+	99,
+	32,
+	61,
+	32,
+	40,
+	115,
+	105,
+	110,
+	103,
+	108,
+	101,
+	32,
+	104,
+	101,
+	108,
+	112,
+	32,
+	111,
+	102,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	32,
+	108,
+	97,
+	119,
+	32,
+	97,
+	110,
+	100,
+	108,
+	97,
+	98,
+	101,
+	108,
+	101,
+	100,
+	102,
+	111,
+	114,
+	101,
+	115,
+	116,
+	115,
+	99,
+	111,
+	111,
+	107,
+	105,
+	110,
+	103,
+	115,
+	112,
+	97,
+	99,
+	101,
+	34,
+	62,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	45,
+	119,
+	101,
+	108,
+	108,
+	32,
+	97,
+	115,
+	83,
+	116,
+	97,
+	110,
+	108,
+	101,
+	121,
+	98,
+	114,
+	105,
+	100,
+	103,
+	101,
+	115,
+	47,
+	103,
+	108,
+	111,
+	98,
+	97,
+	108,
+	67,
+	114,
+	111,
+	97,
+	116,
+	105,
+	97,
+	32,
+	65,
+	98,
+	111,
+	117,
+	116,
+	32,
+	91,
+	48,
+	93,
+	59,
+	10,
+	32,
+	32,
+	105,
+	116,
+	44,
+	32,
+	97,
+	110,
+	100,
+	103,
+	114,
+	111,
+	117,
+	112,
+	101,
+	100,
+	98,
+	101,
+	105,
+	110,
+	103,
+	32,
+	97,
+	41,
+	123,
+	116,
+	104,
+	114,
+	111,
+	119,
+	104,
+	101,
+	32,
+	109,
+	97,
+	100,
+	101,
+	108,
+	105,
+	103,
+	104,
+	116,
+	101,
+	114,
+	101,
+	116,
+	104,
+	105,
+	99,
+	97,
+	108,
+	70,
+	70,
+	70,
+	70,
+	70,
+	70,
+	34,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	34,
+	108,
+	105,
+	107,
+	101,
+	32,
+	97,
+	32,
+	101,
+	109,
+	112,
+	108,
+	111,
+	121,
+	115,
+	108,
+	105,
+	118,
+	101,
+	32,
+	105,
+	110,
+	97,
+	115,
+	32,
+	115,
+	101,
+	101,
+	110,
+	112,
+	114,
+	105,
+	110,
+	116,
+	101,
+	114,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	117,
+	98,
+	45,
+	108,
+	105,
+	110,
+	107,
+	114,
+	101,
+	106,
+	101,
+	99,
+	116,
+	115,
+	97,
+	110,
+	100,
+	32,
+	117,
+	115,
+	101,
+	105,
+	109,
+	97,
+	103,
+	101,
+	34,
+	62,
+	115,
+	117,
+	99,
+	99,
+	101,
+	101,
+	100,
+	102,
+	101,
+	101,
+	100,
+	105,
+	110,
+	103,
+	78,
+	117,
+	99,
+	108,
+	101,
+	97,
+	114,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	111,
+	32,
+	104,
+	101,
+	108,
+	112,
+	87,
+	111,
+	109,
+	101,
+	110,
+	39,
+	115,
+	78,
+	101,
+	105,
+	116,
+	104,
+	101,
+	114,
+	77,
+	101,
+	120,
+	105,
+	99,
+	97,
+	110,
+	112,
+	114,
+	111,
+	116,
+	101,
+	105,
+	110,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	98,
+	121,
+	32,
+	109,
+	97,
+	110,
+	121,
+	104,
+	101,
+	97,
+	108,
+	116,
+	104,
+	121,
+	108,
+	97,
+	119,
+	115,
+	117,
+	105,
+	116,
+	100,
+	101,
+	118,
+	105,
+	115,
+	101,
+	100,
+	46,
+	112,
+	117,
+	115,
+	104,
+	40,
+	123,
+	115,
+	101,
+	108,
+	108,
+	101,
+	114,
+	115,
+	115,
+	105,
+	109,
+	112,
+	108,
+	121,
+	32,
+	84,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	46,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	32,
+	73,
+	109,
+	97,
+	103,
+	101,
+	40,
+	111,
+	108,
+	100,
+	101,
+	114,
+	34,
+	62,
+	117,
+	115,
+	46,
+	106,
+	115,
+	34,
+	62,
+	32,
+	83,
+	105,
+	110,
+	99,
+	101,
+	32,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	108,
+	97,
+	114,
+	103,
+	101,
+	114,
+	32,
+	111,
+	112,
+	101,
+	110,
+	32,
+	116,
+	111,
+	33,
+	45,
+	45,
+	32,
+	101,
+	110,
+	100,
+	108,
+	105,
+	101,
+	115,
+	32,
+	105,
+	110,
+	39,
+	93,
+	41,
+	59,
+	13,
+	10,
+	32,
+	32,
+	109,
+	97,
+	114,
+	107,
+	101,
+	116,
+	119,
+	104,
+	111,
+	32,
+	105,
+	115,
+	32,
+	40,
+	34,
+	68,
+	79,
+	77,
+	67,
+	111,
+	109,
+	97,
+	110,
+	97,
+	103,
+	101,
+	100,
+	111,
+	110,
+	101,
+	32,
+	102,
+	111,
+	114,
+	116,
+	121,
+	112,
+	101,
+	111,
+	102,
+	32,
+	75,
+	105,
+	110,
+	103,
+	100,
+	111,
+	109,
+	112,
+	114,
+	111,
+	102,
+	105,
+	116,
+	115,
+	112,
+	114,
+	111,
+	112,
+	111,
+	115,
+	101,
+	116,
+	111,
+	32,
+	115,
+	104,
+	111,
+	119,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	59,
+	109,
+	97,
+	100,
+	101,
+	32,
+	105,
+	116,
+	100,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	119,
+	101,
+	114,
+	101,
+	32,
+	105,
+	110,
+	109,
+	105,
+	120,
+	116,
+	117,
+	114,
+	101,
+	112,
+	114,
+	101,
+	99,
+	105,
+	115,
+	101,
+	97,
+	114,
+	105,
+	115,
+	105,
+	110,
+	103,
+	115,
+	114,
+	99,
+	32,
+	61,
+	32,
+	39,
+	109,
+	97,
+	107,
+	101,
+	32,
+	97,
+	32,
+	115,
+	101,
+	99,
+	117,
+	114,
+	101,
+	100,
+	66,
+	97,
+	112,
+	116,
+	105,
+	115,
+	116,
+	118,
+	111,
+	116,
+	105,
+	110,
+	103,
+	32,
+	10,
+	9,
+	9,
+	118,
+	97,
+	114,
+	32,
+	77,
+	97,
+	114,
+	99,
+	104,
+	32,
+	50,
+	103,
+	114,
+	101,
+	119,
+	32,
+	117,
+	112,
+	67,
+	108,
+	105,
+	109,
+	97,
+	116,
+	101,
+	46,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	115,
+	107,
+	105,
+	108,
+	108,
+	101,
+	100,
+	119,
+	97,
+	121,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	102,
+	97,
+	99,
+	101,
+	32,
+	111,
+	102,
+	97,
+	99,
+	116,
+	105,
+	110,
+	103,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	116,
+	111,
+	32,
+	119,
+	111,
+	114,
+	107,
+	114,
+	101,
+	100,
+	117,
+	99,
+	101,
+	115,
+	104,
+	97,
+	115,
+	32,
+	104,
+	97,
+	100,
+	101,
+	114,
+	101,
+	99,
+	116,
+	101,
+	100,
+	115,
+	104,
+	111,
+	119,
+	40,
+	41,
+	59,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	98,
+	111,
+	111,
+	107,
+	32,
+	111,
+	102,
+	97,
+	110,
+	32,
+	97,
+	114,
+	101,
+	97,
+	61,
+	61,
+	32,
+	34,
+	104,
+	116,
+	116,
+	60,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	10,
+	60,
+	104,
+	116,
+	109,
+	108,
+	62,
+	99,
+	111,
+	110,
+	102,
+	111,
+	114,
+	109,
+	102,
+	97,
+	99,
+	105,
+	110,
+	103,
+	32,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	46,
+	114,
+	101,
+	108,
+	121,
+	32,
+	111,
+	110,
+	104,
+	111,
+	115,
+	116,
+	101,
+	100,
+	32,
+	46,
+	99,
+	117,
+	115,
+	116,
+	111,
+	109,
+	104,
+	101,
+	32,
+	119,
+	101,
+	110,
+	116,
+	98,
+	117,
+	116,
+	32,
+	102,
+	111,
+	114,
+	115,
+	112,
+	114,
+	101,
+	97,
+	100,
+	32,
+	70,
+	97,
+	109,
+	105,
+	108,
+	121,
+	32,
+	97,
+	32,
+	109,
+	101,
+	97,
+	110,
+	115,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	102,
+	111,
+	114,
+	117,
+	109,
+	115,
+	46,
+	102,
+	111,
+	111,
+	116,
+	97,
+	103,
+	101,
+	34,
+	62,
+	77,
+	111,
+	98,
+	105,
+	108,
+	67,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	34,
+	32,
+	105,
+	100,
+	61,
+	34,
+	97,
+	115,
+	32,
+	104,
+	105,
+	103,
+	104,
+	105,
+	110,
+	116,
+	101,
+	110,
+	115,
+	101,
+	45,
+	45,
+	62,
+	60,
+	33,
+	45,
+	45,
+	102,
+	101,
+	109,
+	97,
+	108,
+	101,
+	32,
+	105,
+	115,
+	32,
+	115,
+	101,
+	101,
+	110,
+	105,
+	109,
+	112,
+	108,
+	105,
+	101,
+	100,
+	115,
+	101,
+	116,
+	32,
+	116,
+	104,
+	101,
+	97,
+	32,
+	115,
+	116,
+	97,
+	116,
+	101,
+	97,
+	110,
+	100,
+	32,
+	104,
+	105,
+	115,
+	102,
+	97,
+	115,
+	116,
+	101,
+	115,
+	116,
+	98,
+	101,
+	115,
+	105,
+	100,
+	101,
+	115,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	95,
+	98,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	34,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	73,
+	110,
+	102,
+	111,
+	98,
+	111,
+	120,
+	101,
+	118,
+	101,
+	110,
+	116,
+	115,
+	44,
+	97,
+	32,
+	121,
+	111,
+	117,
+	110,
+	103,
+	97,
+	110,
+	100,
+	32,
+	97,
+	114,
+	101,
+	78,
+	97,
+	116,
+	105,
+	118,
+	101,
+	32,
+	99,
+	104,
+	101,
+	97,
+	112,
+	101,
+	114,
+	84,
+	105,
+	109,
+	101,
+	111,
+	117,
+	116,
+	97,
+	110,
+	100,
+	32,
+	104,
+	97,
+	115,
+	101,
+	110,
+	103,
+	105,
+	110,
+	101,
+	115,
+	119,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	40,
+	109,
+	111,
+	115,
+	116,
+	108,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	102,
+	105,
+	110,
+	100,
+	32,
+	97,
+	32,
+	45,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	80,
+	114,
+	105,
+	110,
+	99,
+	101,
+	32,
+	97,
+	114,
+	101,
+	97,
+	32,
+	111,
+	102,
+	109,
+	111,
+	114,
+	101,
+	32,
+	111,
+	102,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	95,
+	110,
+	97,
+	116,
+	117,
+	114,
+	101,
+	44,
+	108,
+	101,
+	103,
+	97,
+	108,
+	108,
+	121,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	44,
+	108,
+	97,
+	110,
+	100,
+	32,
+	111,
+	102,
+	111,
+	114,
+	32,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	100,
+	117,
+	99,
+	101,
+	100,
+	112,
+	114,
+	111,
+	118,
+	105,
+	110,
+	103,
+	109,
+	105,
+	115,
+	115,
+	105,
+	108,
+	101,
+	108,
+	111,
+	99,
+	97,
+	108,
+	108,
+	121,
+	65,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	116,
+	104,
+	101,
+	32,
+	119,
+	97,
+	121,
+	107,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	112,
+	120,
+	59,
+	34,
+	62,
+	13,
+	10,
+	112,
+	117,
+	115,
+	104,
+	101,
+	100,
+	32,
+	97,
+	98,
+	97,
+	110,
+	100,
+	111,
+	110,
+	110,
+	117,
+	109,
+	101,
+	114,
+	97,
+	108,
+	67,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	73,
+	110,
+	32,
+	116,
+	104,
+	105,
+	115,
+	109,
+	111,
+	114,
+	101,
+	32,
+	105,
+	110,
+	111,
+	114,
+	32,
+	115,
+	111,
+	109,
+	101,
+	110,
+	97,
+	109,
+	101,
+	32,
+	105,
+	115,
+	97,
+	110,
+	100,
+	44,
+	32,
+	105,
+	110,
+	99,
+	114,
+	111,
+	119,
+	110,
+	101,
+	100,
+	73,
+	83,
+	66,
+	78,
+	32,
+	48,
+	45,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	115,
+	79,
+	99,
+	116,
+	111,
+	98,
+	101,
+	114,
+	109,
+	97,
+	121,
+	32,
+	110,
+	111,
+	116,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	32,
+	108,
+	97,
+	116,
+	101,
+	32,
+	105,
+	110,
+	68,
+	101,
+	102,
+	101,
+	110,
+	99,
+	101,
+	101,
+	110,
+	97,
+	99,
+	116,
+	101,
+	100,
+	119,
+	105,
+	115,
+	104,
+	32,
+	116,
+	111,
+	98,
+	114,
+	111,
+	97,
+	100,
+	108,
+	121,
+	99,
+	111,
+	111,
+	108,
+	105,
+	110,
+	103,
+	111,
+	110,
+	108,
+	111,
+	97,
+	100,
+	61,
+	105,
+	116,
+	46,
+	32,
+	84,
+	104,
+	101,
+	114,
+	101,
+	99,
+	111,
+	118,
+	101,
+	114,
+	77,
+	101,
+	109,
+	98,
+	101,
+	114,
+	115,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	32,
+	97,
+	115,
+	115,
+	117,
+	109,
+	101,
+	115,
+	60,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	46,
+	105,
+	110,
+	32,
+	111,
+	110,
+	101,
+	32,
+	61,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	95,
+	97,
+	32,
+	103,
+	111,
+	111,
+	100,
+	32,
+	114,
+	101,
+	107,
+	108,
+	97,
+	109,
+	97,
+	111,
+	116,
+	104,
+	101,
+	114,
+	115,
+	44,
+	116,
+	111,
+	32,
+	116,
+	104,
+	105,
+	115,
+	95,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	112,
+	97,
+	110,
+	101,
+	108,
+	34,
+	62,
+	76,
+	111,
+	110,
+	100,
+	111,
+	110,
+	44,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	115,
+	99,
+	114,
+	117,
+	115,
+	104,
+	101,
+	100,
+	98,
+	97,
+	112,
+	116,
+	105,
+	115,
+	109,
+	99,
+	111,
+	97,
+	115,
+	116,
+	97,
+	108,
+	115,
+	116,
+	97,
+	116,
+	117,
+	115,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	34,
+	32,
+	109,
+	111,
+	118,
+	101,
+	32,
+	116,
+	111,
+	108,
+	111,
+	115,
+	116,
+	32,
+	105,
+	110,
+	98,
+	101,
+	116,
+	116,
+	101,
+	114,
+	32,
+	105,
+	109,
+	112,
+	108,
+	105,
+	101,
+	115,
+	114,
+	105,
+	118,
+	97,
+	108,
+	114,
+	121,
+	115,
+	101,
+	114,
+	118,
+	101,
+	114,
+	115,
+	32,
+	83,
+	121,
+	115,
+	116,
+	101,
+	109,
+	80,
+	101,
+	114,
+	104,
+	97,
+	112,
+	115,
+	101,
+	115,
+	32,
+	97,
+	110,
+	100,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	100,
+	102,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	108,
+	97,
+	115,
+	116,
+	101,
+	100,
+	32,
+	114,
+	105,
+	115,
+	101,
+	32,
+	105,
+	110,
+	71,
+	101,
+	110,
+	101,
+	115,
+	105,
+	115,
+	118,
+	105,
+	101,
+	119,
+	32,
+	111,
+	102,
+	114,
+	105,
+	115,
+	105,
+	110,
+	103,
+	32,
+	115,
+	101,
+	101,
+	109,
+	32,
+	116,
+	111,
+	98,
+	117,
+	116,
+	32,
+	105,
+	110,
+	32,
+	98,
+	97,
+	99,
+	107,
+	105,
+	110,
+	103,
+	104,
+	101,
+	32,
+	119,
+	105,
+	108,
+	108,
+	103,
+	105,
+	118,
+	101,
+	110,
+	32,
+	97,
+	103,
+	105,
+	118,
+	105,
+	110,
+	103,
+	32,
+	99,
+	105,
+	116,
+	105,
+	101,
+	115,
+	46,
+	102,
+	108,
+	111,
+	119,
+	32,
+	111,
+	102,
+	32,
+	76,
+	97,
+	116,
+	101,
+	114,
+	32,
+	97,
+	108,
+	108,
+	32,
+	98,
+	117,
+	116,
+	72,
+	105,
+	103,
+	104,
+	119,
+	97,
+	121,
+	111,
+	110,
+	108,
+	121,
+	32,
+	98,
+	121,
+	115,
+	105,
+	103,
+	110,
+	32,
+	111,
+	102,
+	104,
+	101,
+	32,
+	100,
+	111,
+	101,
+	115,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	115,
+	98,
+	97,
+	116,
+	116,
+	101,
+	114,
+	121,
+	38,
+	97,
+	109,
+	112,
+	59,
+	108,
+	97,
+	115,
+	105,
+	110,
+	103,
+	108,
+	101,
+	115,
+	116,
+	104,
+	114,
+	101,
+	97,
+	116,
+	115,
+	105,
+	110,
+	116,
+	101,
+	103,
+	101,
+	114,
+	116,
+	97,
+	107,
+	101,
+	32,
+	111,
+	110,
+	114,
+	101,
+	102,
+	117,
+	115,
+	101,
+	100,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	61,
+	85,
+	83,
+	38,
+	97,
+	109,
+	112,
+	83,
+	101,
+	101,
+	32,
+	116,
+	104,
+	101,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	115,
+	98,
+	121,
+	32,
+	116,
+	104,
+	105,
+	115,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	46,
+	104,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	58,
+	104,
+	111,
+	118,
+	101,
+	114,
+	44,
+	108,
+	101,
+	115,
+	98,
+	105,
+	97,
+	110,
+	115,
+	117,
+	114,
+	110,
+	97,
+	109,
+	101,
+	97,
+	110,
+	100,
+	32,
+	97,
+	108,
+	108,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	47,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	95,
+	95,
+	112,
+	97,
+	114,
+	97,
+	109,
+	115,
+	72,
+	97,
+	114,
+	118,
+	97,
+	114,
+	100,
+	47,
+	112,
+	105,
+	120,
+	101,
+	108,
+	46,
+	114,
+	101,
+	109,
+	111,
+	118,
+	97,
+	108,
+	115,
+	111,
+	32,
+	108,
+	111,
+	110,
+	103,
+	114,
+	111,
+	108,
+	101,
+	32,
+	111,
+	102,
+	106,
+	111,
+	105,
+	110,
+	116,
+	108,
+	121,
+	115,
+	107,
+	121,
+	115,
+	99,
+	114,
+	97,
+	85,
+	110,
+	105,
+	99,
+	111,
+	100,
+	101,
+	98,
+	114,
+	32,
+	47,
+	62,
+	13,
+	10,
+	65,
+	116,
+	108,
+	97,
+	110,
+	116,
+	97,
+	110,
+	117,
+	99,
+	108,
+	101,
+	117,
+	115,
+	67,
+	111,
+	117,
+	110,
+	116,
+	121,
+	44,
+	112,
+	117,
+	114,
+	101,
+	108,
+	121,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	34,
+	62,
+	101,
+	97,
+	115,
+	105,
+	108,
+	121,
+	32,
+	98,
+	117,
+	105,
+	108,
+	100,
+	32,
+	97,
+	111,
+	110,
+	99,
+	108,
+	105,
+	99,
+	107,
+	97,
+	32,
+	103,
+	105,
+	118,
+	101,
+	110,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	114,
+	104,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	101,
+	118,
+	101,
+	110,
+	116,
+	115,
+	32,
+	101,
+	108,
+	115,
+	101,
+	32,
+	123,
+	10,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	110,
+	111,
+	119,
+	32,
+	116,
+	104,
+	101,
+	44,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	109,
+	97,
+	110,
+	32,
+	119,
+	104,
+	111,
+	111,
+	114,
+	103,
+	47,
+	87,
+	101,
+	98,
+	111,
+	110,
+	101,
+	32,
+	97,
+	110,
+	100,
+	99,
+	97,
+	118,
+	97,
+	108,
+	114,
+	121,
+	72,
+	101,
+	32,
+	100,
+	105,
+	101,
+	100,
+	115,
+	101,
+	97,
+	116,
+	116,
+	108,
+	101,
+	48,
+	48,
+	44,
+	48,
+	48,
+	48,
+	32,
+	123,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	104,
+	97,
+	118,
+	101,
+	32,
+	116,
+	111,
+	105,
+	102,
+	40,
+	119,
+	105,
+	110,
+	100,
+	97,
+	110,
+	100,
+	32,
+	105,
+	116,
+	115,
+	115,
+	111,
+	108,
+	101,
+	108,
+	121,
+	32,
+	109,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	114,
+	101,
+	110,
+	101,
+	119,
+	101,
+	100,
+	68,
+	101,
+	116,
+	114,
+	111,
+	105,
+	116,
+	97,
+	109,
+	111,
+	110,
+	103,
+	115,
+	116,
+	101,
+	105,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	109,
+	32,
+	105,
+	110,
+	83,
+	101,
+	110,
+	97,
+	116,
+	111,
+	114,
+	85,
+	115,
+	60,
+	47,
+	97,
+	62,
+	60,
+	75,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	70,
+	114,
+	97,
+	110,
+	99,
+	105,
+	115,
+	45,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	104,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	97,
+	114,
+	116,
+	32,
+	97,
+	110,
+	100,
+	104,
+	105,
+	109,
+	32,
+	97,
+	110,
+	100,
+	117,
+	115,
+	101,
+	100,
+	32,
+	98,
+	121,
+	115,
+	99,
+	111,
+	114,
+	105,
+	110,
+	103,
+	97,
+	116,
+	32,
+	104,
+	111,
+	109,
+	101,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	102,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	66,
+	117,
+	102,
+	102,
+	97,
+	108,
+	111,
+	108,
+	105,
+	110,
+	107,
+	34,
+	62,
+	60,
+	119,
+	104,
+	97,
+	116,
+	32,
+	104,
+	101,
+	102,
+	114,
+	101,
+	101,
+	32,
+	116,
+	111,
+	67,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	99,
+	111,
+	109,
+	101,
+	32,
+	105,
+	110,
+	115,
+	101,
+	99,
+	116,
+	111,
+	114,
+	115,
+	99,
+	111,
+	117,
+	110,
+	116,
+	101,
+	100,
+	111,
+	110,
+	101,
+	32,
+	100,
+	97,
+	121,
+	110,
+	101,
+	114,
+	118,
+	111,
+	117,
+	115,
+	115,
+	113,
+	117,
+	97,
+	114,
+	101,
+	32,
+	125,
+	59,
+	105,
+	102,
+	40,
+	103,
+	111,
+	105,
+	110,
+	32,
+	119,
+	104,
+	97,
+	116,
+	105,
+	109,
+	103,
+	34,
+	32,
+	97,
+	108,
+	105,
+	115,
+	32,
+	111,
+	110,
+	108,
+	121,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	47,
+	116,
+	117,
+	101,
+	115,
+	100,
+	97,
+	121,
+	108,
+	111,
+	111,
+	115,
+	101,
+	108,
+	121,
+	83,
+	111,
+	108,
+	111,
+	109,
+	111,
+	110,
+	115,
+	101,
+	120,
+	117,
+	97,
+	108,
+	32,
+	45,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	109,
+	101,
+	100,
+	105,
+	117,
+	109,
+	34,
+	68,
+	79,
+	32,
+	78,
+	79,
+	84,
+	32,
+	70,
+	114,
+	97,
+	110,
+	99,
+	101,
+	44,
+	119,
+	105,
+	116,
+	104,
+	32,
+	97,
+	32,
+	119,
+	97,
+	114,
+	32,
+	97,
+	110,
+	100,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	32,
+	116,
+	97,
+	107,
+	101,
+	32,
+	97,
+	32,
+	62,
+	13,
+	10,
+	13,
+	10,
+	13,
+	10,
+	109,
+	97,
+	114,
+	107,
+	101,
+	116,
+	46,
+	104,
+	105,
+	103,
+	104,
+	119,
+	97,
+	121,
+	100,
+	111,
+	110,
+	101,
+	32,
+	105,
+	110,
+	99,
+	116,
+	105,
+	118,
+	105,
+	116,
+	121,
+	34,
+	108,
+	97,
+	115,
+	116,
+	34,
+	62,
+	111,
+	98,
+	108,
+	105,
+	103,
+	101,
+	100,
+	114,
+	105,
+	115,
+	101,
+	32,
+	116,
+	111,
+	34,
+	117,
+	110,
+	100,
+	101,
+	102,
+	105,
+	109,
+	97,
+	100,
+	101,
+	32,
+	116,
+	111,
+	32,
+	69,
+	97,
+	114,
+	108,
+	121,
+	32,
+	112,
+	114,
+	97,
+	105,
+	115,
+	101,
+	100,
+	105,
+	110,
+	32,
+	105,
+	116,
+	115,
+	32,
+	102,
+	111,
+	114,
+	32,
+	104,
+	105,
+	115,
+	97,
+	116,
+	104,
+	108,
+	101,
+	116,
+	101,
+	74,
+	117,
+	112,
+	105,
+	116,
+	101,
+	114,
+	89,
+	97,
+	104,
+	111,
+	111,
+	33,
+	32,
+	116,
+	101,
+	114,
+	109,
+	101,
+	100,
+	32,
+	115,
+	111,
+	32,
+	109,
+	97,
+	110,
+	121,
+	114,
+	101,
+	97,
+	108,
+	108,
+	121,
+	32,
+	115,
+	46,
+	32,
+	84,
+	104,
+	101,
+	32,
+	97,
+	32,
+	119,
+	111,
+	109,
+	97,
+	110,
+	63,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	32,
+	98,
+	105,
+	99,
+	121,
+	99,
+	108,
+	101,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	100,
+	97,
+	121,
+	32,
+	97,
+	110,
+	100,
+	115,
+	116,
+	97,
+	116,
+	105,
+	110,
+	103,
+	82,
+	97,
+	116,
+	104,
+	101,
+	114,
+	44,
+	104,
+	105,
+	103,
+	104,
+	101,
+	114,
+	32,
+	79,
+	102,
+	102,
+	105,
+	99,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	110,
+	111,
+	119,
+	116,
+	105,
+	109,
+	101,
+	115,
+	44,
+	32,
+	119,
+	104,
+	101,
+	110,
+	32,
+	97,
+	32,
+	112,
+	97,
+	121,
+	32,
+	102,
+	111,
+	114,
+	111,
+	110,
+	32,
+	116,
+	104,
+	105,
+	115,
+	45,
+	108,
+	105,
+	110,
+	107,
+	34,
+	62,
+	59,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	32,
+	97,
+	110,
+	110,
+	117,
+	97,
+	108,
+	32,
+	116,
+	104,
+	101,
+	32,
+	78,
+	101,
+	119,
+	112,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	46,
+	99,
+	111,
+	109,
+	34,
+	32,
+	116,
+	97,
+	107,
+	105,
+	110,
+	32,
+	116,
+	111,
+	97,
+	32,
+	98,
+	114,
+	105,
+	101,
+	102,
+	40,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	103,
+	114,
+	111,
+	117,
+	112,
+	115,
+	46,
+	59,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	101,
+	110,
+	122,
+	121,
+	109,
+	101,
+	115,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	108,
+	97,
+	116,
+	101,
+	123,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	116,
+	104,
+	101,
+	114,
+	97,
+	112,
+	121,
+	97,
+	32,
+	112,
+	111,
+	105,
+	110,
+	116,
+	98,
+	97,
+	110,
+	110,
+	105,
+	110,
+	103,
+	105,
+	110,
+	107,
+	115,
+	34,
+	62,
+	10,
+	40,
+	41,
+	59,
+	34,
+	32,
+	114,
+	101,
+	97,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	92,
+	117,
+	48,
+	48,
+	51,
+	67,
+	97,
+	97,
+	98,
+	111,
+	117,
+	116,
+	32,
+	97,
+	116,
+	114,
+	62,
+	13,
+	10,
+	9,
+	9,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	32,
+	103,
+	105,
+	118,
+	101,
+	115,
+	32,
+	97,
+	60,
+	83,
+	67,
+	82,
+	73,
+	80,
+	84,
+	82,
+	97,
+	105,
+	108,
+	119,
+	97,
+	121,
+	116,
+	104,
+	101,
+	109,
+	101,
+	115,
+	47,
+	116,
+	111,
+	111,
+	108,
+	98,
+	111,
+	120,
+	66,
+	121,
+	73,
+	100,
+	40,
+	34,
+	120,
+	104,
+	117,
+	109,
+	97,
+	110,
+	115,
+	44,
+	119,
+	97,
+	116,
+	99,
+	104,
+	101,
+	115,
+	105,
+	110,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	105,
+	102,
+	32,
+	40,
+	119,
+	105,
+	99,
+	111,
+	109,
+	105,
+	110,
+	103,
+	32,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	115,
+	32,
+	85,
+	110,
+	100,
+	101,
+	114,
+	32,
+	98,
+	117,
+	116,
+	32,
+	104,
+	97,
+	115,
+	104,
+	97,
+	110,
+	100,
+	101,
+	100,
+	32,
+	109,
+	97,
+	100,
+	101,
+	32,
+	98,
+	121,
+	116,
+	104,
+	97,
+	110,
+	32,
+	105,
+	110,
+	102,
+	101,
+	97,
+	114,
+	32,
+	111,
+	102,
+	100,
+	101,
+	110,
+	111,
+	116,
+	101,
+	100,
+	47,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	108,
+	101,
+	102,
+	116,
+	32,
+	105,
+	110,
+	118,
+	111,
+	108,
+	116,
+	97,
+	103,
+	101,
+	105,
+	110,
+	32,
+	101,
+	97,
+	99,
+	104,
+	97,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	98,
+	97,
+	115,
+	101,
+	32,
+	111,
+	102,
+	73,
+	110,
+	32,
+	109,
+	97,
+	110,
+	121,
+	117,
+	110,
+	100,
+	101,
+	114,
+	103,
+	111,
+	114,
+	101,
+	103,
+	105,
+	109,
+	101,
+	115,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	60,
+	47,
+	112,
+	62,
+	13,
+	10,
+	60,
+	117,
+	115,
+	116,
+	111,
+	109,
+	86,
+	97,
+	59,
+	38,
+	103,
+	116,
+	59,
+	60,
+	47,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	115,
+	111,
+	114,
+	32,
+	116,
+	104,
+	97,
+	116,
+	109,
+	111,
+	115,
+	116,
+	108,
+	121,
+	32,
+	38,
+	97,
+	109,
+	112,
+	59,
+	114,
+	101,
+	32,
+	115,
+	105,
+	122,
+	101,
+	61,
+	34,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	104,
+	97,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	112,
+	97,
+	115,
+	115,
+	105,
+	118,
+	101,
+	72,
+	111,
+	115,
+	116,
+	32,
+	61,
+	32,
+	87,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	102,
+	101,
+	114,
+	116,
+	105,
+	108,
+	101,
+	86,
+	97,
+	114,
+	105,
+	111,
+	117,
+	115,
+	61,
+	91,
+	93,
+	59,
+	40,
+	102,
+	117,
+	99,
+	97,
+	109,
+	101,
+	114,
+	97,
+	115,
+	47,
+	62,
+	60,
+	47,
+	116,
+	100,
+	62,
+	97,
+	99,
+	116,
+	115,
+	32,
+	97,
+	115,
+	73,
+	110,
+	32,
+	115,
+	111,
+	109,
+	101,
+	62,
+	13,
+	10,
+	13,
+	10,
+	60,
+	33,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	115,
+	32,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	66,
+	101,
+	105,
+	106,
+	105,
+	110,
+	103,
+	99,
+	97,
+	116,
+	97,
+	108,
+	195,
+	160,
+	100,
+	101,
+	117,
+	116,
+	115,
+	99,
+	104,
+	101,
+	117,
+	114,
+	111,
+	112,
+	101,
+	117,
+	101,
+	117,
+	115,
+	107,
+	97,
+	114,
+	97,
+	103,
+	97,
+	101,
+	105,
+	108,
+	103,
+	101,
+	115,
+	118,
+	101,
+	110,
+	115,
+	107,
+	97,
+	101,
+	115,
+	112,
+	97,
+	195,
+	177,
+	97,
+	109,
+	101,
+	110,
+	115,
+	97,
+	106,
+	101,
+	117,
+	115,
+	117,
+	97,
+	114,
+	105,
+	111,
+	116,
+	114,
+	97,
+	98,
+	97,
+	106,
+	111,
+	109,
+	195,
+	169,
+	120,
+	105,
+	99,
+	111,
+	112,
+	195,
+	161,
+	103,
+	105,
+	110,
+	97,
+	115,
+	105,
+	101,
+	109,
+	112,
+	114,
+	101,
+	115,
+	105,
+	115,
+	116,
+	101,
+	109,
+	97,
+	111,
+	99,
+	116,
+	117,
+	98,
+	114,
+	101,
+	100,
+	117,
+	114,
+	97,
+	110,
+	116,
+	101,
+	97,
+	195,
+	177,
+	97,
+	100,
+	105,
+	114,
+	101,
+	109,
+	112,
+	114,
+	101,
+	115,
+	97,
+	109,
+	111,
+	109,
+	101,
+	110,
+	116,
+	111,
+	110,
+	117,
+	101,
+	115,
+	116,
+	114,
+	111,
+	112,
+	114,
+	105,
+	109,
+	101,
+	114,
+	97,
+	116,
+	114,
+	97,
+	118,
+	195,
+	169,
+	115,
+	103,
+	114,
+	97,
+	99,
+	105,
+	97,
+	115,
+	110,
+	117,
+	101,
+	115,
+	116,
+	114,
+	97,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	111,
+	101,
+	115,
+	116,
+	97,
+	100,
+	111,
+	115,
+	99,
+	97,
+	108,
+	105,
+	100,
+	97,
+	100,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	110,
+	195,
+	186,
+	109,
+	101,
+	114,
+	111,
+	97,
+	99,
+	117,
+	101,
+	114,
+	100,
+	111,
+	109,
+	195,
+	186,
+	115,
+	105,
+	99,
+	97,
+	109,
+	105,
+	101,
+	109,
+	98,
+	114,
+	111,
+	111,
+	102,
+	101,
+	114,
+	116,
+	97,
+	115,
+	97,
+	108,
+	103,
+	117,
+	110,
+	111,
+	115,
+	112,
+	97,
+	195,
+	173,
+	115,
+	101,
+	115,
+	101,
+	106,
+	101,
+	109,
+	112,
+	108,
+	111,
+	100,
+	101,
+	114,
+	101,
+	99,
+	104,
+	111,
+	97,
+	100,
+	101,
+	109,
+	195,
+	161,
+	115,
+	112,
+	114,
+	105,
+	118,
+	97,
+	100,
+	111,
+	97,
+	103,
+	114,
+	101,
+	103,
+	97,
+	114,
+	101,
+	110,
+	108,
+	97,
+	99,
+	101,
+	115,
+	112,
+	111,
+	115,
+	105,
+	98,
+	108,
+	101,
+	104,
+	111,
+	116,
+	101,
+	108,
+	101,
+	115,
+	115,
+	101,
+	118,
+	105,
+	108,
+	108,
+	97,
+	112,
+	114,
+	105,
+	109,
+	101,
+	114,
+	111,
+	195,
+	186,
+	108,
+	116,
+	105,
+	109,
+	111,
+	101,
+	118,
+	101,
+	110,
+	116,
+	111,
+	115,
+	97,
+	114,
+	99,
+	104,
+	105,
+	118,
+	111,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	97,
+	109,
+	117,
+	106,
+	101,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	114,
+	97,
+	100,
+	97,
+	97,
+	110,
+	117,
+	110,
+	99,
+	105,
+	111,
+	101,
+	109,
+	98,
+	97,
+	114,
+	103,
+	111,
+	109,
+	101,
+	114,
+	99,
+	97,
+	100,
+	111,
+	103,
+	114,
+	97,
+	110,
+	100,
+	101,
+	115,
+	101,
+	115,
+	116,
+	117,
+	100,
+	105,
+	111,
+	109,
+	101,
+	106,
+	111,
+	114,
+	101,
+	115,
+	102,
+	101,
+	98,
+	114,
+	101,
+	114,
+	111,
+	100,
+	105,
+	115,
+	101,
+	195,
+	177,
+	111,
+	116,
+	117,
+	114,
+	105,
+	115,
+	109,
+	111,
+	99,
+	195,
+	179,
+	100,
+	105,
+	103,
+	111,
+	112,
+	111,
+	114,
+	116,
+	97,
+	100,
+	97,
+	101,
+	115,
+	112,
+	97,
+	99,
+	105,
+	111,
+	102,
+	97,
+	109,
+	105,
+	108,
+	105,
+	97,
+	97,
+	110,
+	116,
+	111,
+	110,
+	105,
+	111,
+	112,
+	101,
+	114,
+	109,
+	105,
+	116,
+	101,
+	103,
+	117,
+	97,
+	114,
+	100,
+	97,
+	114,
+	97,
+	108,
+	103,
+	117,
+	110,
+	97,
+	115,
+	112,
+	114,
+	101,
+	99,
+	105,
+	111,
+	115,
+	97,
+	108,
+	103,
+	117,
+	105,
+	101,
+	110,
+	115,
+	101,
+	110,
+	116,
+	105,
+	100,
+	111,
+	118,
+	105,
+	115,
+	105,
+	116,
+	97,
+	115,
+	116,
+	195,
+	173,
+	116,
+	117,
+	108,
+	111,
+	99,
+	111,
+	110,
+	111,
+	99,
+	101,
+	114,
+	115,
+	101,
+	103,
+	117,
+	110,
+	100,
+	111,
+	99,
+	111,
+	110,
+	115,
+	101,
+	106,
+	111,
+	102,
+	114,
+	97,
+	110,
+	99,
+	105,
+	97,
+	109,
+	105,
+	110,
+	117,
+	116,
+	111,
+	115,
+	115,
+	101,
+	103,
+	117,
+	110,
+	100,
+	97,
+	116,
+	101,
+	110,
+	101,
+	109,
+	111,
+	115,
+	101,
+	102,
+	101,
+	99,
+	116,
+	111,
+	115,
+	109,
+	195,
+	161,
+	108,
+	97,
+	103,
+	97,
+	115,
+	101,
+	115,
+	105,
+	195,
+	179,
+	110,
+	114,
+	101,
+	118,
+	105,
+	115,
+	116,
+	97,
+	103,
+	114,
+	97,
+	110,
+	97,
+	100,
+	97,
+	99,
+	111,
+	109,
+	112,
+	114,
+	97,
+	114,
+	105,
+	110,
+	103,
+	114,
+	101,
+	115,
+	111,
+	103,
+	97,
+	114,
+	99,
+	195,
+	173,
+	97,
+	97,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	101,
+	99,
+	117,
+	97,
+	100,
+	111,
+	114,
+	113,
+	117,
+	105,
+	101,
+	110,
+	101,
+	115,
+	105,
+	110,
+	99,
+	108,
+	117,
+	115,
+	111,
+	100,
+	101,
+	98,
+	101,
+	114,
+	195,
+	161,
+	109,
+	97,
+	116,
+	101,
+	114,
+	105,
+	97,
+	104,
+	111,
+	109,
+	98,
+	114,
+	101,
+	115,
+	109,
+	117,
+	101,
+	115,
+	116,
+	114,
+	97,
+	112,
+	111,
+	100,
+	114,
+	195,
+	173,
+	97,
+	109,
+	97,
+	195,
+	177,
+	97,
+	110,
+	97,
+	195,
+	186,
+	108,
+	116,
+	105,
+	109,
+	97,
+	101,
+	115,
+	116,
+	97,
+	109,
+	111,
+	115,
+	111,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	116,
+	97,
+	109,
+	98,
+	105,
+	101,
+	110,
+	110,
+	105,
+	110,
+	103,
+	195,
+	186,
+	110,
+	115,
+	97,
+	108,
+	117,
+	100,
+	111,
+	115,
+	112,
+	111,
+	100,
+	101,
+	109,
+	111,
+	115,
+	109,
+	101,
+	106,
+	111,
+	114,
+	97,
+	114,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	98,
+	117,
+	115,
+	105,
+	110,
+	101,
+	115,
+	115,
+	104,
+	111,
+	109,
+	101,
+	112,
+	97,
+	103,
+	101,
+	115,
+	101,
+	99,
+	117,
+	114,
+	105,
+	116,
+	121,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	115,
+	116,
+	97,
+	110,
+	100,
+	97,
+	114,
+	100,
+	99,
+	97,
+	109,
+	112,
+	97,
+	105,
+	103,
+	110,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	115,
+	99,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	121,
+	101,
+	120,
+	116,
+	101,
+	114,
+	110,
+	97,
+	108,
+	99,
+	104,
+	105,
+	108,
+	100,
+	114,
+	101,
+	110,
+	114,
+	101,
+	115,
+	101,
+	114,
+	118,
+	101,
+	100,
+	114,
+	101,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	101,
+	120,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	102,
+	97,
+	118,
+	111,
+	114,
+	105,
+	116,
+	101,
+	116,
+	101,
+	109,
+	112,
+	108,
+	97,
+	116,
+	101,
+	109,
+	105,
+	108,
+	105,
+	116,
+	97,
+	114,
+	121,
+	105,
+	110,
+	100,
+	117,
+	115,
+	116,
+	114,
+	121,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	109,
+	97,
+	116,
+	101,
+	114,
+	105,
+	97,
+	108,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	115,
+	122,
+	45,
+	105,
+	110,
+	100,
+	101,
+	120,
+	58,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	116,
+	115,
+	115,
+	111,
+	102,
+	116,
+	119,
+	97,
+	114,
+	101,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	99,
+	97,
+	108,
+	101,
+	110,
+	100,
+	97,
+	114,
+	112,
+	108,
+	97,
+	116,
+	102,
+	111,
+	114,
+	109,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	115,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	100,
+	109,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	113,
+	117,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	98,
+	117,
+	105,
+	108,
+	100,
+	105,
+	110,
+	103,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	115,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	114,
+	101,
+	108,
+	105,
+	103,
+	105,
+	111,
+	110,
+	112,
+	104,
+	121,
+	115,
+	105,
+	99,
+	97,
+	108,
+	102,
+	101,
+	101,
+	100,
+	98,
+	97,
+	99,
+	107,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	101,
+	114,
+	112,
+	105,
+	99,
+	116,
+	117,
+	114,
+	101,
+	115,
+	100,
+	105,
+	115,
+	97,
+	98,
+	108,
+	101,
+	100,
+	112,
+	114,
+	111,
+	116,
+	111,
+	99,
+	111,
+	108,
+	97,
+	117,
+	100,
+	105,
+	101,
+	110,
+	99,
+	101,
+	115,
+	101,
+	116,
+	116,
+	105,
+	110,
+	103,
+	115,
+	97,
+	99,
+	116,
+	105,
+	118,
+	105,
+	116,
+	121,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	108,
+	101,
+	97,
+	114,
+	110,
+	105,
+	110,
+	103,
+	97,
+	110,
+	121,
+	116,
+	104,
+	105,
+	110,
+	103,
+	97,
+	98,
+	115,
+	116,
+	114,
+	97,
+	99,
+	116,
+	112,
+	114,
+	111,
+	103,
+	114,
+	101,
+	115,
+	115,
+	111,
+	118,
+	101,
+	114,
+	118,
+	105,
+	101,
+	119,
+	109,
+	97,
+	103,
+	97,
+	122,
+	105,
+	110,
+	101,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	105,
+	99,
+	116,
+	114,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	112,
+	114,
+	101,
+	115,
+	115,
+	117,
+	114,
+	101,
+	118,
+	97,
+	114,
+	105,
+	111,
+	117,
+	115,
+	32,
+	60,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	121,
+	115,
+	104,
+	111,
+	112,
+	112,
+	105,
+	110,
+	103,
+	116,
+	111,
+	103,
+	101,
+	116,
+	104,
+	101,
+	114,
+	97,
+	100,
+	118,
+	97,
+	110,
+	99,
+	101,
+	100,
+	98,
+	101,
+	104,
+	97,
+	118,
+	105,
+	111,
+	114,
+	100,
+	111,
+	119,
+	110,
+	108,
+	111,
+	97,
+	100,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	100,
+	102,
+	111,
+	111,
+	116,
+	98,
+	97,
+	108,
+	108,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	76,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	100,
+	105,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	114,
+	101,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	116,
+	114,
+	97,
+	99,
+	107,
+	105,
+	110,
+	103,
+	112,
+	97,
+	115,
+	115,
+	119,
+	111,
+	114,
+	100,
+	109,
+	111,
+	100,
+	105,
+	102,
+	105,
+	101,
+	100,
+	115,
+	116,
+	117,
+	100,
+	101,
+	110,
+	116,
+	115,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	108,
+	121,
+	102,
+	105,
+	103,
+	104,
+	116,
+	105,
+	110,
+	103,
+	110,
+	111,
+	114,
+	116,
+	104,
+	101,
+	114,
+	110,
+	100,
+	97,
+	116,
+	97,
+	98,
+	97,
+	115,
+	101,
+	102,
+	101,
+	115,
+	116,
+	105,
+	118,
+	97,
+	108,
+	98,
+	114,
+	101,
+	97,
+	107,
+	105,
+	110,
+	103,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	101,
+	116,
+	100,
+	114,
+	111,
+	112,
+	100,
+	111,
+	119,
+	110,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	101,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	109,
+	97,
+	114,
+	114,
+	105,
+	97,
+	103,
+	101,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	101,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	115,
+	110,
+	101,
+	103,
+	97,
+	116,
+	105,
+	118,
+	101,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	115,
+	97,
+	110,
+	97,
+	108,
+	121,
+	115,
+	105,
+	115,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	100,
+	98,
+	97,
+	110,
+	110,
+	101,
+	114,
+	34,
+	62,
+	112,
+	117,
+	114,
+	99,
+	104,
+	97,
+	115,
+	101,
+	112,
+	111,
+	108,
+	105,
+	99,
+	105,
+	101,
+	115,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	97,
+	108,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	118,
+	101,
+	97,
+	114,
+	103,
+	117,
+	109,
+	101,
+	110,
+	116,
+	98,
+	111,
+	111,
+	107,
+	109,
+	97,
+	114,
+	107,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	114,
+	99,
+	104,
+	101,
+	109,
+	105,
+	99,
+	97,
+	108,
+	100,
+	105,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	99,
+	97,
+	108,
+	108,
+	98,
+	97,
+	99,
+	107,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	112,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	115,
+	99,
+	111,
+	110,
+	102,
+	108,
+	105,
+	99,
+	116,
+	104,
+	97,
+	114,
+	100,
+	119,
+	97,
+	114,
+	101,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	100,
+	101,
+	108,
+	105,
+	118,
+	101,
+	114,
+	121,
+	109,
+	111,
+	117,
+	110,
+	116,
+	97,
+	105,
+	110,
+	111,
+	98,
+	116,
+	97,
+	105,
+	110,
+	101,
+	100,
+	61,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	59,
+	102,
+	111,
+	114,
+	40,
+	118,
+	97,
+	114,
+	32,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	101,
+	100,
+	99,
+	97,
+	112,
+	97,
+	99,
+	105,
+	116,
+	121,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	116,
+	121,
+	97,
+	105,
+	114,
+	99,
+	114,
+	97,
+	102,
+	116,
+	101,
+	109,
+	112,
+	108,
+	111,
+	121,
+	101,
+	100,
+	112,
+	114,
+	111,
+	112,
+	111,
+	115,
+	101,
+	100,
+	100,
+	111,
+	109,
+	101,
+	115,
+	116,
+	105,
+	99,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	115,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	100,
+	104,
+	111,
+	115,
+	112,
+	105,
+	116,
+	97,
+	108,
+	118,
+	101,
+	114,
+	116,
+	105,
+	99,
+	97,
+	108,
+	99,
+	111,
+	108,
+	108,
+	97,
+	112,
+	115,
+	101,
+	97,
+	112,
+	112,
+	114,
+	111,
+	97,
+	99,
+	104,
+	112,
+	97,
+	114,
+	116,
+	110,
+	101,
+	114,
+	115,
+	108,
+	111,
+	103,
+	111,
+	34,
+	62,
+	60,
+	97,
+	100,
+	97,
+	117,
+	103,
+	104,
+	116,
+	101,
+	114,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	34,
+	32,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	97,
+	108,
+	102,
+	97,
+	109,
+	105,
+	108,
+	105,
+	101,
+	115,
+	47,
+	105,
+	109,
+	97,
+	103,
+	101,
+	115,
+	47,
+	97,
+	115,
+	115,
+	101,
+	109,
+	98,
+	108,
+	121,
+	112,
+	111,
+	119,
+	101,
+	114,
+	102,
+	117,
+	108,
+	116,
+	101,
+	97,
+	99,
+	104,
+	105,
+	110,
+	103,
+	102,
+	105,
+	110,
+	105,
+	115,
+	104,
+	101,
+	100,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	99,
+	103,
+	105,
+	45,
+	98,
+	105,
+	110,
+	47,
+	112,
+	117,
+	114,
+	112,
+	111,
+	115,
+	101,
+	115,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	99,
+	111,
+	109,
+	105,
+	110,
+	103,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	115,
+	97,
+	99,
+	97,
+	100,
+	101,
+	109,
+	105,
+	99,
+	101,
+	120,
+	101,
+	114,
+	99,
+	105,
+	115,
+	101,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	109,
+	101,
+	100,
+	105,
+	99,
+	105,
+	110,
+	101,
+	99,
+	111,
+	110,
+	115,
+	116,
+	97,
+	110,
+	116,
+	97,
+	99,
+	99,
+	105,
+	100,
+	101,
+	110,
+	116,
+	77,
+	97,
+	103,
+	97,
+	122,
+	105,
+	110,
+	101,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	115,
+	116,
+	97,
+	114,
+	116,
+	105,
+	110,
+	103,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	34,
+	62,
+	111,
+	98,
+	115,
+	101,
+	114,
+	118,
+	101,
+	100,
+	58,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	101,
+	120,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	112,
+	114,
+	101,
+	118,
+	105,
+	111,
+	117,
+	115,
+	83,
+	111,
+	102,
+	116,
+	119,
+	97,
+	114,
+	101,
+	99,
+	117,
+	115,
+	116,
+	111,
+	109,
+	101,
+	114,
+	100,
+	101,
+	99,
+	105,
+	115,
+	105,
+	111,
+	110,
+	115,
+	116,
+	114,
+	101,
+	110,
+	103,
+	116,
+	104,
+	100,
+	101,
+	116,
+	97,
+	105,
+	108,
+	101,
+	100,
+	115,
+	108,
+	105,
+	103,
+	104,
+	116,
+	108,
+	121,
+	112,
+	108,
+	97,
+	110,
+	110,
+	105,
+	110,
+	103,
+	116,
+	101,
+	120,
+	116,
+	97,
+	114,
+	101,
+	97,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	99,
+	121,
+	101,
+	118,
+	101,
+	114,
+	121,
+	111,
+	110,
+	101,
+	115,
+	116,
+	114,
+	97,
+	105,
+	103,
+	104,
+	116,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	101,
+	114,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	118,
+	101,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	104,
+	101,
+	114,
+	105,
+	116,
+	97,
+	103,
+	101,
+	115,
+	104,
+	105,
+	112,
+	112,
+	105,
+	110,
+	103,
+	97,
+	98,
+	115,
+	111,
+	108,
+	117,
+	116,
+	101,
+	114,
+	101,
+	99,
+	101,
+	105,
+	118,
+	101,
+	100,
+	114,
+	101,
+	108,
+	101,
+	118,
+	97,
+	110,
+	116,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	34,
+	32,
+	118,
+	105,
+	111,
+	108,
+	101,
+	110,
+	99,
+	101,
+	97,
+	110,
+	121,
+	119,
+	104,
+	101,
+	114,
+	101,
+	98,
+	101,
+	110,
+	101,
+	102,
+	105,
+	116,
+	115,
+	108,
+	97,
+	117,
+	110,
+	99,
+	104,
+	101,
+	100,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	108,
+	121,
+	97,
+	108,
+	108,
+	105,
+	97,
+	110,
+	99,
+	101,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	109,
+	117,
+	108,
+	116,
+	105,
+	112,
+	108,
+	101,
+	98,
+	117,
+	108,
+	108,
+	101,
+	116,
+	105,
+	110,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	100,
+	111,
+	99,
+	99,
+	117,
+	114,
+	114,
+	101,
+	100,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	108,
+	36,
+	40,
+	116,
+	104,
+	105,
+	115,
+	41,
+	46,
+	114,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	62,
+	60,
+	116,
+	114,
+	62,
+	60,
+	116,
+	100,
+	99,
+	111,
+	110,
+	103,
+	114,
+	101,
+	115,
+	115,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	101,
+	100,
+	117,
+	108,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	115,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	60,
+	117,
+	108,
+	32,
+	105,
+	100,
+	61,
+	34,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	72,
+	111,
+	109,
+	101,
+	60,
+	47,
+	97,
+	62,
+	119,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	115,
+	110,
+	101,
+	116,
+	119,
+	111,
+	114,
+	107,
+	115,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	101,
+	110,
+	116,
+	105,
+	114,
+	101,
+	108,
+	121,
+	109,
+	101,
+	109,
+	111,
+	114,
+	105,
+	97,
+	108,
+	109,
+	101,
+	115,
+	115,
+	97,
+	103,
+	101,
+	115,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	34,
+	62,
+	115,
+	111,
+	109,
+	101,
+	119,
+	104,
+	97,
+	116,
+	118,
+	105,
+	99,
+	116,
+	111,
+	114,
+	105,
+	97,
+	87,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	32,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	76,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	99,
+	116,
+	118,
+	105,
+	115,
+	105,
+	116,
+	111,
+	114,
+	115,
+	68,
+	111,
+	119,
+	110,
+	108,
+	111,
+	97,
+	100,
+	119,
+	105,
+	116,
+	104,
+	111,
+	117,
+	116,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	10,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	115,
+	119,
+	105,
+	100,
+	116,
+	104,
+	32,
+	61,
+	32,
+	118,
+	97,
+	114,
+	105,
+	97,
+	98,
+	108,
+	101,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	100,
+	118,
+	105,
+	114,
+	103,
+	105,
+	110,
+	105,
+	97,
+	110,
+	111,
+	114,
+	109,
+	97,
+	108,
+	108,
+	121,
+	104,
+	97,
+	112,
+	112,
+	101,
+	110,
+	101,
+	100,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	115,
+	115,
+	116,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	82,
+	101,
+	103,
+	105,
+	115,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	112,
+	97,
+	114,
+	101,
+	100,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	115,
+	97,
+	99,
+	99,
+	117,
+	114,
+	97,
+	116,
+	101,
+	98,
+	105,
+	114,
+	116,
+	104,
+	100,
+	97,
+	121,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	103,
+	121,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	103,
+	114,
+	97,
+	112,
+	104,
+	105,
+	99,
+	115,
+	99,
+	114,
+	105,
+	109,
+	105,
+	110,
+	97,
+	108,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	121,
+	99,
+	111,
+	110,
+	115,
+	117,
+	109,
+	101,
+	114,
+	80,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	115,
+	112,
+	101,
+	97,
+	107,
+	105,
+	110,
+	103,
+	118,
+	97,
+	108,
+	105,
+	100,
+	97,
+	116,
+	101,
+	97,
+	99,
+	104,
+	105,
+	101,
+	118,
+	101,
+	100,
+	46,
+	106,
+	112,
+	103,
+	34,
+	32,
+	47,
+	62,
+	109,
+	97,
+	99,
+	104,
+	105,
+	110,
+	101,
+	115,
+	60,
+	47,
+	104,
+	50,
+	62,
+	10,
+	32,
+	32,
+	107,
+	101,
+	121,
+	119,
+	111,
+	114,
+	100,
+	115,
+	102,
+	114,
+	105,
+	101,
+	110,
+	100,
+	108,
+	121,
+	98,
+	114,
+	111,
+	116,
+	104,
+	101,
+	114,
+	115,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	101,
+	100,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	99,
+	111,
+	109,
+	112,
+	111,
+	115,
+	101,
+	100,
+	101,
+	120,
+	112,
+	101,
+	99,
+	116,
+	101,
+	100,
+	97,
+	100,
+	101,
+	113,
+	117,
+	97,
+	116,
+	101,
+	112,
+	97,
+	107,
+	105,
+	115,
+	116,
+	97,
+	110,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	97,
+	98,
+	108,
+	101,
+	60,
+	47,
+	108,
+	97,
+	98,
+	101,
+	108,
+	62,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	98,
+	114,
+	105,
+	110,
+	103,
+	105,
+	110,
+	103,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	101,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	111,
+	114,
+	112,
+	108,
+	117,
+	103,
+	105,
+	110,
+	115,
+	47,
+	76,
+	105,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	72,
+	101,
+	97,
+	100,
+	101,
+	114,
+	34,
+	62,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	32,
+	40,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	103,
+	114,
+	97,
+	100,
+	117,
+	97,
+	116,
+	101,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	99,
+	111,
+	109,
+	109,
+	101,
+	114,
+	99,
+	101,
+	109,
+	97,
+	108,
+	97,
+	121,
+	115,
+	105,
+	97,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	109,
+	97,
+	105,
+	110,
+	116,
+	97,
+	105,
+	110,
+	59,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	115,
+	99,
+	104,
+	101,
+	100,
+	117,
+	108,
+	101,
+	99,
+	104,
+	97,
+	110,
+	103,
+	105,
+	110,
+	103,
+	98,
+	97,
+	99,
+	107,
+	32,
+	116,
+	111,
+	32,
+	99,
+	97,
+	116,
+	104,
+	111,
+	108,
+	105,
+	99,
+	112,
+	97,
+	116,
+	116,
+	101,
+	114,
+	110,
+	115,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	32,
+	35,
+	103,
+	114,
+	101,
+	97,
+	116,
+	101,
+	115,
+	116,
+	115,
+	117,
+	112,
+	112,
+	108,
+	105,
+	101,
+	115,
+	114,
+	101,
+	108,
+	105,
+	97,
+	98,
+	108,
+	101,
+	60,
+	47,
+	117,
+	108,
+	62,
+	10,
+	9,
+	9,
+	60,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	32,
+	99,
+	105,
+	116,
+	105,
+	122,
+	101,
+	110,
+	115,
+	99,
+	108,
+	111,
+	116,
+	104,
+	105,
+	110,
+	103,
+	119,
+	97,
+	116,
+	99,
+	104,
+	105,
+	110,
+	103,
+	60,
+	108,
+	105,
+	32,
+	105,
+	100,
+	61,
+	34,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	99,
+	99,
+	97,
+	114,
+	114,
+	121,
+	105,
+	110,
+	103,
+	115,
+	101,
+	110,
+	116,
+	101,
+	110,
+	99,
+	101,
+	60,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	62,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	115,
+	116,
+	116,
+	104,
+	105,
+	110,
+	107,
+	105,
+	110,
+	103,
+	99,
+	97,
+	116,
+	99,
+	104,
+	40,
+	101,
+	41,
+	115,
+	111,
+	117,
+	116,
+	104,
+	101,
+	114,
+	110,
+	77,
+	105,
+	99,
+	104,
+	97,
+	101,
+	108,
+	32,
+	109,
+	101,
+	114,
+	99,
+	104,
+	97,
+	110,
+	116,
+	99,
+	97,
+	114,
+	111,
+	117,
+	115,
+	101,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	105,
+	110,
+	116,
+	101,
+	114,
+	105,
+	111,
+	114,
+	46,
+	115,
+	112,
+	108,
+	105,
+	116,
+	40,
+	34,
+	108,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	79,
+	99,
+	116,
+	111,
+	98,
+	101,
+	114,
+	32,
+	41,
+	123,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	105,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	100,
+	45,
+	45,
+	38,
+	103,
+	116,
+	59,
+	10,
+	10,
+	99,
+	111,
+	118,
+	101,
+	114,
+	97,
+	103,
+	101,
+	99,
+	104,
+	97,
+	105,
+	114,
+	109,
+	97,
+	110,
+	46,
+	112,
+	110,
+	103,
+	34,
+	32,
+	47,
+	62,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	115,
+	82,
+	105,
+	99,
+	104,
+	97,
+	114,
+	100,
+	32,
+	119,
+	104,
+	97,
+	116,
+	101,
+	118,
+	101,
+	114,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	108,
+	121,
+	114,
+	101,
+	99,
+	111,
+	118,
+	101,
+	114,
+	121,
+	98,
+	97,
+	115,
+	101,
+	98,
+	97,
+	108,
+	108,
+	106,
+	117,
+	100,
+	103,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	46,
+	46,
+	99,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	32,
+	119,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	100,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	34,
+	47,
+	62,
+	60,
+	47,
+	97,
+	62,
+	13,
+	10,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	105,
+	99,
+	115,
+	99,
+	111,
+	116,
+	108,
+	97,
+	110,
+	100,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	111,
+	110,
+	113,
+	117,
+	97,
+	110,
+	116,
+	105,
+	116,
+	121,
+	46,
+	32,
+	73,
+	83,
+	66,
+	78,
+	32,
+	48,
+	100,
+	105,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	45,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	45,
+	34,
+	32,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	115,
+	112,
+	101,
+	97,
+	107,
+	101,
+	114,
+	115,
+	67,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	115,
+	97,
+	114,
+	99,
+	104,
+	105,
+	118,
+	101,
+	115,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	114,
+	101,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	100,
+	105,
+	115,
+	99,
+	111,
+	117,
+	110,
+	116,
+	73,
+	116,
+	97,
+	108,
+	105,
+	97,
+	110,
+	111,
+	99,
+	114,
+	105,
+	116,
+	101,
+	114,
+	105,
+	97,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	108,
+	121,
+	58,
+	32,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	39,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	99,
+	111,
+	118,
+	101,
+	114,
+	105,
+	110,
+	103,
+	111,
+	102,
+	102,
+	101,
+	114,
+	105,
+	110,
+	103,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	101,
+	100,
+	66,
+	114,
+	105,
+	116,
+	105,
+	115,
+	104,
+	32,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	121,
+	70,
+	97,
+	99,
+	101,
+	98,
+	111,
+	111,
+	107,
+	110,
+	117,
+	109,
+	101,
+	114,
+	111,
+	117,
+	115,
+	118,
+	101,
+	104,
+	105,
+	99,
+	108,
+	101,
+	115,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	115,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	104,
+	97,
+	110,
+	100,
+	108,
+	105,
+	110,
+	103,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	87,
+	105,
+	108,
+	108,
+	105,
+	97,
+	109,
+	32,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	114,
+	95,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	97,
+	99,
+	99,
+	117,
+	114,
+	97,
+	99,
+	121,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	110,
+	100,
+	101,
+	114,
+	115,
+	111,
+	110,
+	102,
+	108,
+	101,
+	120,
+	105,
+	98,
+	108,
+	101,
+	67,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	121,
+	108,
+	97,
+	119,
+	114,
+	101,
+	110,
+	99,
+	101,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	108,
+	97,
+	121,
+	111,
+	117,
+	116,
+	61,
+	34,
+	97,
+	112,
+	112,
+	114,
+	111,
+	118,
+	101,
+	100,
+	32,
+	109,
+	97,
+	120,
+	105,
+	109,
+	117,
+	109,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	34,
+	62,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	83,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	104,
+	97,
+	109,
+	105,
+	108,
+	116,
+	111,
+	110,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	32,
+	99,
+	97,
+	110,
+	97,
+	100,
+	105,
+	97,
+	110,
+	99,
+	104,
+	97,
+	110,
+	110,
+	101,
+	108,
+	115,
+	47,
+	116,
+	104,
+	101,
+	109,
+	101,
+	115,
+	47,
+	47,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	112,
+	111,
+	114,
+	116,
+	117,
+	103,
+	97,
+	108,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	34,
+	105,
+	110,
+	116,
+	101,
+	114,
+	118,
+	97,
+	108,
+	119,
+	105,
+	114,
+	101,
+	108,
+	101,
+	115,
+	115,
+	101,
+	110,
+	116,
+	105,
+	116,
+	108,
+	101,
+	100,
+	97,
+	103,
+	101,
+	110,
+	99,
+	105,
+	101,
+	115,
+	83,
+	101,
+	97,
+	114,
+	99,
+	104,
+	34,
+	32,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	100,
+	116,
+	104,
+	111,
+	117,
+	115,
+	97,
+	110,
+	100,
+	115,
+	112,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	38,
+	104,
+	101,
+	108,
+	108,
+	105,
+	112,
+	59,
+	110,
+	101,
+	119,
+	32,
+	68,
+	97,
+	116,
+	101,
+	34,
+	32,
+	115,
+	105,
+	122,
+	101,
+	61,
+	34,
+	112,
+	97,
+	103,
+	101,
+	78,
+	97,
+	109,
+	101,
+	109,
+	105,
+	100,
+	100,
+	108,
+	101,
+	34,
+	32,
+	34,
+	32,
+	47,
+	62,
+	60,
+	47,
+	97,
+	62,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	62,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	111,
+	118,
+	101,
+	114,
+	102,
+	108,
+	111,
+	119,
+	111,
+	112,
+	105,
+	110,
+	105,
+	111,
+	110,
+	115,
+	105,
+	108,
+	108,
+	105,
+	110,
+	111,
+	105,
+	115,
+	108,
+	105,
+	110,
+	107,
+	115,
+	34,
+	62,
+	10,
+	9,
+	60,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	115,
+	115,
+	97,
+	116,
+	117,
+	114,
+	100,
+	97,
+	121,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	97,
+	108,
+	105,
+	116,
+	101,
+	109,
+	112,
+	114,
+	111,
+	112,
+	101,
+	110,
+	103,
+	105,
+	110,
+	101,
+	101,
+	114,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	114,
+	112,
+	114,
+	111,
+	112,
+	111,
+	115,
+	97,
+	108,
+	61,
+	34,
+	102,
+	97,
+	108,
+	115,
+	101,
+	34,
+	69,
+	115,
+	112,
+	97,
+	195,
+	177,
+	111,
+	108,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	115,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	101,
+	114,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	121,
+	109,
+	112,
+	116,
+	111,
+	109,
+	115,
+	111,
+	114,
+	105,
+	101,
+	110,
+	116,
+	101,
+	100,
+	114,
+	101,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	60,
+	112,
+	108,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	115,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	46,
+	108,
+	101,
+	97,
+	118,
+	105,
+	110,
+	103,
+	32,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	115,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	34,
+	62,
+	46,
+	10,
+	10,
+	83,
+	111,
+	109,
+	101,
+	32,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	101,
+	100,
+	115,
+	117,
+	105,
+	116,
+	97,
+	98,
+	108,
+	101,
+	98,
+	117,
+	108,
+	103,
+	97,
+	114,
+	105,
+	97,
+	46,
+	115,
+	104,
+	111,
+	119,
+	40,
+	41,
+	59,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	71,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	32,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	115,
+	69,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	119,
+	105,
+	108,
+	108,
+	105,
+	97,
+	109,
+	115,
+	79,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	34,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	62,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	34,
+	62,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	111,
+	114,
+	114,
+	101,
+	113,
+	117,
+	101,
+	115,
+	116,
+	115,
+	97,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	97,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	68,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	114,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	46,
+	32,
+	10,
+	10,
+	84,
+	104,
+	101,
+	32,
+	121,
+	111,
+	117,
+	114,
+	115,
+	101,
+	108,
+	102,
+	67,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	32,
+	109,
+	105,
+	99,
+	104,
+	105,
+	103,
+	97,
+	110,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	99,
+	111,
+	108,
+	117,
+	109,
+	98,
+	105,
+	97,
+	112,
+	114,
+	105,
+	111,
+	114,
+	105,
+	116,
+	121,
+	112,
+	114,
+	105,
+	110,
+	116,
+	105,
+	110,
+	103,
+	100,
+	114,
+	105,
+	110,
+	107,
+	105,
+	110,
+	103,
+	102,
+	97,
+	99,
+	105,
+	108,
+	105,
+	116,
+	121,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	67,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	102,
+	105,
+	99,
+	101,
+	114,
+	115,
+	82,
+	117,
+	115,
+	115,
+	105,
+	97,
+	110,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	101,
+	45,
+	56,
+	56,
+	53,
+	57,
+	45,
+	49,
+	34,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	102,
+	97,
+	109,
+	105,
+	108,
+	105,
+	97,
+	114,
+	32,
+	113,
+	117,
+	97,
+	108,
+	105,
+	116,
+	121,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	48,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	118,
+	105,
+	101,
+	119,
+	112,
+	111,
+	114,
+	116,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	115,
+	45,
+	116,
+	105,
+	116,
+	108,
+	101,
+	34,
+	62,
+	112,
+	111,
+	114,
+	116,
+	97,
+	98,
+	108,
+	101,
+	46,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	32,
+	101,
+	108,
+	105,
+	103,
+	105,
+	98,
+	108,
+	101,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	115,
+	97,
+	116,
+	108,
+	97,
+	110,
+	116,
+	105,
+	99,
+	111,
+	110,
+	108,
+	111,
+	97,
+	100,
+	61,
+	34,
+	100,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	46,
+	115,
+	117,
+	112,
+	112,
+	108,
+	105,
+	101,
+	100,
+	112,
+	97,
+	121,
+	109,
+	101,
+	110,
+	116,
+	115,
+	103,
+	108,
+	111,
+	115,
+	115,
+	97,
+	114,
+	121,
+	10,
+	10,
+	65,
+	102,
+	116,
+	101,
+	114,
+	32,
+	103,
+	117,
+	105,
+	100,
+	97,
+	110,
+	99,
+	101,
+	60,
+	47,
+	116,
+	100,
+	62,
+	60,
+	116,
+	100,
+	101,
+	110,
+	99,
+	111,
+	100,
+	105,
+	110,
+	103,
+	109,
+	105,
+	100,
+	100,
+	108,
+	101,
+	34,
+	62,
+	99,
+	97,
+	109,
+	101,
+	32,
+	116,
+	111,
+	32,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	115,
+	115,
+	99,
+	111,
+	116,
+	116,
+	105,
+	115,
+	104,
+	106,
+	111,
+	110,
+	97,
+	116,
+	104,
+	97,
+	110,
+	109,
+	97,
+	106,
+	111,
+	114,
+	105,
+	116,
+	121,
+	119,
+	105,
+	100,
+	103,
+	101,
+	116,
+	115,
+	46,
+	99,
+	108,
+	105,
+	110,
+	105,
+	99,
+	97,
+	108,
+	116,
+	104,
+	97,
+	105,
+	108,
+	97,
+	110,
+	100,
+	116,
+	101,
+	97,
+	99,
+	104,
+	101,
+	114,
+	115,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	9,
+	97,
+	102,
+	102,
+	101,
+	99,
+	116,
+	101,
+	100,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	115,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	114,
+	59,
+	116,
+	111,
+	83,
+	116,
+	114,
+	105,
+	110,
+	103,
+	60,
+	47,
+	115,
+	109,
+	97,
+	108,
+	108,
+	62,
+	111,
+	107,
+	108,
+	97,
+	104,
+	111,
+	109,
+	97,
+	119,
+	105,
+	108,
+	108,
+	32,
+	98,
+	101,
+	32,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	111,
+	114,
+	48,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	104,
+	111,
+	108,
+	105,
+	100,
+	97,
+	121,
+	115,
+	82,
+	101,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	108,
+	105,
+	99,
+	101,
+	110,
+	115,
+	101,
+	100,
+	32,
+	40,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	46,
+	32,
+	65,
+	102,
+	116,
+	101,
+	114,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	118,
+	105,
+	115,
+	105,
+	116,
+	105,
+	110,
+	103,
+	101,
+	120,
+	112,
+	108,
+	111,
+	114,
+	101,
+	114,
+	112,
+	114,
+	105,
+	109,
+	97,
+	114,
+	121,
+	32,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	34,
+	32,
+	97,
+	110,
+	100,
+	114,
+	111,
+	105,
+	100,
+	34,
+	113,
+	117,
+	105,
+	99,
+	107,
+	108,
+	121,
+	32,
+	109,
+	101,
+	101,
+	116,
+	105,
+	110,
+	103,
+	115,
+	101,
+	115,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	59,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	59,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	97,
+	112,
+	112,
+	114,
+	111,
+	118,
+	97,
+	108,
+	44,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	99,
+	104,
+	101,
+	99,
+	107,
+	101,
+	100,
+	46,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	109,
+	97,
+	103,
+	110,
+	101,
+	116,
+	105,
+	99,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	104,
+	102,
+	111,
+	114,
+	101,
+	99,
+	97,
+	115,
+	116,
+	46,
+	32,
+	87,
+	104,
+	105,
+	108,
+	101,
+	32,
+	116,
+	104,
+	117,
+	114,
+	115,
+	100,
+	97,
+	121,
+	100,
+	118,
+	101,
+	114,
+	116,
+	105,
+	115,
+	101,
+	38,
+	101,
+	97,
+	99,
+	117,
+	116,
+	101,
+	59,
+	104,
+	97,
+	115,
+	67,
+	108,
+	97,
+	115,
+	115,
+	101,
+	118,
+	97,
+	108,
+	117,
+	97,
+	116,
+	101,
+	111,
+	114,
+	100,
+	101,
+	114,
+	105,
+	110,
+	103,
+	101,
+	120,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	112,
+	97,
+	116,
+	105,
+	101,
+	110,
+	116,
+	115,
+	32,
+	79,
+	110,
+	108,
+	105,
+	110,
+	101,
+	32,
+	99,
+	111,
+	108,
+	111,
+	114,
+	97,
+	100,
+	111,
+	79,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	34,
+	99,
+	97,
+	109,
+	112,
+	98,
+	101,
+	108,
+	108,
+	60,
+	33,
+	45,
+	45,
+	32,
+	101,
+	110,
+	100,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	13,
+	10,
+	95,
+	112,
+	111,
+	112,
+	117,
+	112,
+	115,
+	124,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	115,
+	44,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	113,
+	117,
+	97,
+	108,
+	105,
+	116,
+	121,
+	32,
+	87,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	32,
+	97,
+	115,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	60,
+	98,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	108,
+	101,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	32,
+	67,
+	111,
+	109,
+	112,
+	97,
+	110,
+	121,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	60,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	32,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	115,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	115,
+	109,
+	97,
+	114,
+	115,
+	104,
+	97,
+	108,
+	108,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	32,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	108,
+	121,
+	41,
+	46,
+	10,
+	10,
+	84,
+	104,
+	101,
+	32,
+	116,
+	97,
+	120,
+	111,
+	110,
+	111,
+	109,
+	121,
+	109,
+	117,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	10,
+	34,
+	32,
+	100,
+	97,
+	116,
+	97,
+	45,
+	115,
+	114,
+	116,
+	117,
+	103,
+	117,
+	195,
+	170,
+	115,
+	115,
+	99,
+	114,
+	111,
+	108,
+	108,
+	84,
+	111,
+	32,
+	112,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	97,
+	116,
+	116,
+	111,
+	114,
+	110,
+	101,
+	121,
+	101,
+	109,
+	112,
+	104,
+	97,
+	115,
+	105,
+	115,
+	115,
+	112,
+	111,
+	110,
+	115,
+	111,
+	114,
+	115,
+	102,
+	97,
+	110,
+	99,
+	121,
+	98,
+	111,
+	120,
+	119,
+	111,
+	114,
+	108,
+	100,
+	39,
+	115,
+	32,
+	119,
+	105,
+	108,
+	100,
+	108,
+	105,
+	102,
+	101,
+	99,
+	104,
+	101,
+	99,
+	107,
+	101,
+	100,
+	61,
+	115,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	112,
+	120,
+	59,
+	102,
+	111,
+	110,
+	116,
+	45,
+	32,
+	80,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	106,
+	111,
+	117,
+	114,
+	110,
+	97,
+	108,
+	115,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	100,
+	118,
+	97,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	104,
+	111,
+	109,
+	112,
+	115,
+	111,
+	110,
+	108,
+	105,
+	103,
+	104,
+	116,
+	105,
+	110,
+	103,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	48,
+	99,
+	104,
+	101,
+	99,
+	107,
+	105,
+	110,
+	103,
+	60,
+	47,
+	116,
+	98,
+	111,
+	100,
+	121,
+	62,
+	60,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	32,
+	67,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	99,
+	108,
+	101,
+	97,
+	114,
+	102,
+	105,
+	120,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	32,
+	60,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	102,
+	105,
+	110,
+	100,
+	105,
+	110,
+	103,
+	115,
+	114,
+	111,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	32,
+	32,
+	79,
+	99,
+	116,
+	111,
+	98,
+	101,
+	114,
+	119,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	32,
+	101,
+	120,
+	112,
+	111,
+	115,
+	117,
+	114,
+	101,
+	117,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	32,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	115,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	101,
+	100,
+	99,
+	108,
+	105,
+	99,
+	107,
+	105,
+	110,
+	103,
+	101,
+	110,
+	116,
+	101,
+	114,
+	105,
+	110,
+	103,
+	99,
+	111,
+	109,
+	109,
+	97,
+	110,
+	100,
+	115,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	115,
+	32,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	110,
+	103,
+	111,
+	110,
+	83,
+	117,
+	98,
+	109,
+	105,
+	116,
+	109,
+	97,
+	114,
+	121,
+	108,
+	97,
+	110,
+	100,
+	99,
+	111,
+	108,
+	108,
+	101,
+	103,
+	101,
+	115,
+	97,
+	110,
+	97,
+	108,
+	121,
+	116,
+	105,
+	99,
+	108,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	115,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	46,
+	108,
+	111,
+	103,
+	103,
+	101,
+	100,
+	73,
+	110,
+	97,
+	100,
+	118,
+	105,
+	115,
+	111,
+	114,
+	121,
+	115,
+	105,
+	98,
+	108,
+	105,
+	110,
+	103,
+	115,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	115,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	41,
+	115,
+	46,
+	32,
+	84,
+	104,
+	105,
+	115,
+	32,
+	112,
+	97,
+	99,
+	107,
+	97,
+	103,
+	101,
+	115,
+	99,
+	104,
+	101,
+	99,
+	107,
+	98,
+	111,
+	120,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	115,
+	112,
+	114,
+	101,
+	103,
+	110,
+	97,
+	110,
+	116,
+	116,
+	111,
+	109,
+	111,
+	114,
+	114,
+	111,
+	119,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	105,
+	99,
+	111,
+	110,
+	46,
+	112,
+	110,
+	103,
+	106,
+	97,
+	112,
+	97,
+	110,
+	101,
+	115,
+	101,
+	99,
+	111,
+	100,
+	101,
+	98,
+	97,
+	115,
+	101,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	34,
+	62,
+	103,
+	97,
+	109,
+	98,
+	108,
+	105,
+	110,
+	103,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	32,
+	44,
+	32,
+	119,
+	104,
+	105,
+	108,
+	101,
+	32,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	32,
+	109,
+	105,
+	115,
+	115,
+	111,
+	117,
+	114,
+	105,
+	115,
+	112,
+	111,
+	114,
+	116,
+	105,
+	110,
+	103,
+	116,
+	111,
+	112,
+	58,
+	49,
+	112,
+	120,
+	32,
+	46,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	50,
+	108,
+	97,
+	122,
+	121,
+	108,
+	111,
+	97,
+	100,
+	110,
+	111,
+	118,
+	101,
+	109,
+	98,
+	101,
+	114,
+	117,
+	115,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	62,
+	10,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	60,
+	116,
+	114,
+	62,
+	60,
+	116,
+	100,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	50,
+	47,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	121,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	32,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	34,
+	32,
+	38,
+	108,
+	116,
+	59,
+	33,
+	45,
+	45,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	34,
+	62,
+	60,
+	47,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	46,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	10,
+	40,
+	231,
+	174,
+	128,
+	228,
+	189,
+	147,
+	41,
+	40,
+	231,
+	185,
+	129,
+	233,
+	171,
+	148,
+	41,
+	104,
+	114,
+	118,
+	97,
+	116,
+	115,
+	107,
+	105,
+	105,
+	116,
+	97,
+	108,
+	105,
+	97,
+	110,
+	111,
+	114,
+	111,
+	109,
+	195,
+	162,
+	110,
+	196,
+	131,
+	116,
+	195,
+	188,
+	114,
+	107,
+	195,
+	167,
+	101,
+	216,
+	167,
+	216,
+	177,
+	216,
+	175,
+	217,
+	136,
+	116,
+	97,
+	109,
+	98,
+	105,
+	195,
+	169,
+	110,
+	110,
+	111,
+	116,
+	105,
+	99,
+	105,
+	97,
+	115,
+	109,
+	101,
+	110,
+	115,
+	97,
+	106,
+	101,
+	115,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	115,
+	100,
+	101,
+	114,
+	101,
+	99,
+	104,
+	111,
+	115,
+	110,
+	97,
+	99,
+	105,
+	111,
+	110,
+	97,
+	108,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	105,
+	111,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	111,
+	117,
+	115,
+	117,
+	97,
+	114,
+	105,
+	111,
+	115,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	97,
+	103,
+	111,
+	98,
+	105,
+	101,
+	114,
+	110,
+	111,
+	101,
+	109,
+	112,
+	114,
+	101,
+	115,
+	97,
+	115,
+	97,
+	110,
+	117,
+	110,
+	99,
+	105,
+	111,
+	115,
+	118,
+	97,
+	108,
+	101,
+	110,
+	99,
+	105,
+	97,
+	99,
+	111,
+	108,
+	111,
+	109,
+	98,
+	105,
+	97,
+	100,
+	101,
+	115,
+	112,
+	117,
+	195,
+	169,
+	115,
+	100,
+	101,
+	112,
+	111,
+	114,
+	116,
+	101,
+	115,
+	112,
+	114,
+	111,
+	121,
+	101,
+	99,
+	116,
+	111,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	111,
+	112,
+	195,
+	186,
+	98,
+	108,
+	105,
+	99,
+	111,
+	110,
+	111,
+	115,
+	111,
+	116,
+	114,
+	111,
+	115,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	97,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	109,
+	105,
+	108,
+	108,
+	111,
+	110,
+	101,
+	115,
+	109,
+	101,
+	100,
+	105,
+	97,
+	110,
+	116,
+	101,
+	112,
+	114,
+	101,
+	103,
+	117,
+	110,
+	116,
+	97,
+	97,
+	110,
+	116,
+	101,
+	114,
+	105,
+	111,
+	114,
+	114,
+	101,
+	99,
+	117,
+	114,
+	115,
+	111,
+	115,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	97,
+	115,
+	97,
+	110,
+	116,
+	105,
+	97,
+	103,
+	111,
+	110,
+	117,
+	101,
+	115,
+	116,
+	114,
+	111,
+	115,
+	111,
+	112,
+	105,
+	110,
+	105,
+	195,
+	179,
+	110,
+	105,
+	109,
+	112,
+	114,
+	105,
+	109,
+	105,
+	114,
+	109,
+	105,
+	101,
+	110,
+	116,
+	114,
+	97,
+	115,
+	97,
+	109,
+	195,
+	169,
+	114,
+	105,
+	99,
+	97,
+	118,
+	101,
+	110,
+	100,
+	101,
+	100,
+	111,
+	114,
+	115,
+	111,
+	99,
+	105,
+	101,
+	100,
+	97,
+	100,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	111,
+	114,
+	101,
+	97,
+	108,
+	105,
+	122,
+	97,
+	114,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	111,
+	112,
+	97,
+	108,
+	97,
+	98,
+	114,
+	97,
+	115,
+	105,
+	110,
+	116,
+	101,
+	114,
+	195,
+	169,
+	115,
+	101,
+	110,
+	116,
+	111,
+	110,
+	99,
+	101,
+	115,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	109,
+	105,
+	101,
+	109,
+	98,
+	114,
+	111,
+	115,
+	114,
+	101,
+	97,
+	108,
+	105,
+	100,
+	97,
+	100,
+	99,
+	195,
+	179,
+	114,
+	100,
+	111,
+	98,
+	97,
+	122,
+	97,
+	114,
+	97,
+	103,
+	111,
+	122,
+	97,
+	112,
+	195,
+	161,
+	103,
+	105,
+	110,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	108,
+	101,
+	115,
+	98,
+	108,
+	111,
+	113,
+	117,
+	101,
+	97,
+	114,
+	103,
+	101,
+	115,
+	116,
+	105,
+	195,
+	179,
+	110,
+	97,
+	108,
+	113,
+	117,
+	105,
+	108,
+	101,
+	114,
+	115,
+	105,
+	115,
+	116,
+	101,
+	109,
+	97,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	105,
+	97,
+	115,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	111,
+	118,
+	101,
+	114,
+	115,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	97,
+	101,
+	115,
+	116,
+	117,
+	100,
+	105,
+	111,
+	115,
+	112,
+	195,
+	186,
+	98,
+	108,
+	105,
+	99,
+	97,
+	111,
+	98,
+	106,
+	101,
+	116,
+	105,
+	118,
+	111,
+	97,
+	108,
+	105,
+	99,
+	97,
+	110,
+	116,
+	101,
+	98,
+	117,
+	115,
+	99,
+	97,
+	100,
+	111,
+	114,
+	99,
+	97,
+	110,
+	116,
+	105,
+	100,
+	97,
+	100,
+	101,
+	110,
+	116,
+	114,
+	97,
+	100,
+	97,
+	115,
+	97,
+	99,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	97,
+	114,
+	99,
+	104,
+	105,
+	118,
+	111,
+	115,
+	115,
+	117,
+	112,
+	101,
+	114,
+	105,
+	111,
+	114,
+	109,
+	97,
+	121,
+	111,
+	114,
+	195,
+	173,
+	97,
+	97,
+	108,
+	101,
+	109,
+	97,
+	110,
+	105,
+	97,
+	102,
+	117,
+	110,
+	99,
+	105,
+	195,
+	179,
+	110,
+	195,
+	186,
+	108,
+	116,
+	105,
+	109,
+	111,
+	115,
+	104,
+	97,
+	99,
+	105,
+	101,
+	110,
+	100,
+	111,
+	97,
+	113,
+	117,
+	101,
+	108,
+	108,
+	111,
+	115,
+	101,
+	100,
+	105,
+	99,
+	105,
+	195,
+	179,
+	110,
+	102,
+	101,
+	114,
+	110,
+	97,
+	110,
+	100,
+	111,
+	97,
+	109,
+	98,
+	105,
+	101,
+	110,
+	116,
+	101,
+	102,
+	97,
+	99,
+	101,
+	98,
+	111,
+	111,
+	107,
+	110,
+	117,
+	101,
+	115,
+	116,
+	114,
+	97,
+	115,
+	99,
+	108,
+	105,
+	101,
+	110,
+	116,
+	101,
+	115,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	111,
+	115,
+	98,
+	97,
+	115,
+	116,
+	97,
+	110,
+	116,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	97,
+	114,
+	99,
+	111,
+	110,
+	103,
+	114,
+	101,
+	115,
+	111,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	114,
+	99,
+	111,
+	109,
+	101,
+	114,
+	99,
+	105,
+	111,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	116,
+	111,
+	106,
+	195,
+	179,
+	118,
+	101,
+	110,
+	101,
+	115,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	116,
+	111,
+	116,
+	195,
+	169,
+	99,
+	110,
+	105,
+	99,
+	97,
+	99,
+	111,
+	110,
+	106,
+	117,
+	110,
+	116,
+	111,
+	101,
+	110,
+	101,
+	114,
+	103,
+	195,
+	173,
+	97,
+	116,
+	114,
+	97,
+	98,
+	97,
+	106,
+	97,
+	114,
+	97,
+	115,
+	116,
+	117,
+	114,
+	105,
+	97,
+	115,
+	114,
+	101,
+	99,
+	105,
+	101,
+	110,
+	116,
+	101,
+	117,
+	116,
+	105,
+	108,
+	105,
+	122,
+	97,
+	114,
+	98,
+	111,
+	108,
+	101,
+	116,
+	195,
+	173,
+	110,
+	115,
+	97,
+	108,
+	118,
+	97,
+	100,
+	111,
+	114,
+	99,
+	111,
+	114,
+	114,
+	101,
+	99,
+	116,
+	97,
+	116,
+	114,
+	97,
+	98,
+	97,
+	106,
+	111,
+	115,
+	112,
+	114,
+	105,
+	109,
+	101,
+	114,
+	111,
+	115,
+	110,
+	101,
+	103,
+	111,
+	99,
+	105,
+	111,
+	115,
+	108,
+	105,
+	98,
+	101,
+	114,
+	116,
+	97,
+	100,
+	100,
+	101,
+	116,
+	97,
+	108,
+	108,
+	101,
+	115,
+	112,
+	97,
+	110,
+	116,
+	97,
+	108,
+	108,
+	97,
+	112,
+	114,
+	195,
+	179,
+	120,
+	105,
+	109,
+	111,
+	97,
+	108,
+	109,
+	101,
+	114,
+	195,
+	173,
+	97,
+	97,
+	110,
+	105,
+	109,
+	97,
+	108,
+	101,
+	115,
+	113,
+	117,
+	105,
+	195,
+	169,
+	110,
+	101,
+	115,
+	99,
+	111,
+	114,
+	97,
+	122,
+	195,
+	179,
+	110,
+	115,
+	101,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	98,
+	117,
+	115,
+	99,
+	97,
+	110,
+	100,
+	111,
+	111,
+	112,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	101,
+	120,
+	116,
+	101,
+	114,
+	105,
+	111,
+	114,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	111,
+	116,
+	111,
+	100,
+	97,
+	118,
+	195,
+	173,
+	97,
+	103,
+	97,
+	108,
+	101,
+	114,
+	195,
+	173,
+	97,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	105,
+	114,
+	109,
+	101,
+	100,
+	105,
+	99,
+	105,
+	110,
+	97,
+	108,
+	105,
+	99,
+	101,
+	110,
+	99,
+	105,
+	97,
+	99,
+	111,
+	110,
+	115,
+	117,
+	108,
+	116,
+	97,
+	97,
+	115,
+	112,
+	101,
+	99,
+	116,
+	111,
+	115,
+	99,
+	114,
+	195,
+	173,
+	116,
+	105,
+	99,
+	97,
+	100,
+	195,
+	179,
+	108,
+	97,
+	114,
+	101,
+	115,
+	106,
+	117,
+	115,
+	116,
+	105,
+	99,
+	105,
+	97,
+	100,
+	101,
+	98,
+	101,
+	114,
+	195,
+	161,
+	110,
+	112,
+	101,
+	114,
+	195,
+	173,
+	111,
+	100,
+	111,
+	110,
+	101,
+	99,
+	101,
+	115,
+	105,
+	116,
+	97,
+	109,
+	97,
+	110,
+	116,
+	101,
+	110,
+	101,
+	114,
+	112,
+	101,
+	113,
+	117,
+	101,
+	195,
+	177,
+	111,
+	114,
+	101,
+	99,
+	105,
+	98,
+	105,
+	100,
+	97,
+	116,
+	114,
+	105,
+	98,
+	117,
+	110,
+	97,
+	108,
+	116,
+	101,
+	110,
+	101,
+	114,
+	105,
+	102,
+	101,
+	99,
+	97,
+	110,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	97,
+	110,
+	97,
+	114,
+	105,
+	97,
+	115,
+	100,
+	101,
+	115,
+	99,
+	97,
+	114,
+	103,
+	97,
+	100,
+	105,
+	118,
+	101,
+	114,
+	115,
+	111,
+	115,
+	109,
+	97,
+	108,
+	108,
+	111,
+	114,
+	99,
+	97,
+	114,
+	101,
+	113,
+	117,
+	105,
+	101,
+	114,
+	101,
+	116,
+	195,
+	169,
+	99,
+	110,
+	105,
+	99,
+	111,
+	100,
+	101,
+	98,
+	101,
+	114,
+	195,
+	173,
+	97,
+	118,
+	105,
+	118,
+	105,
+	101,
+	110,
+	100,
+	97,
+	102,
+	105,
+	110,
+	97,
+	110,
+	122,
+	97,
+	115,
+	97,
+	100,
+	101,
+	108,
+	97,
+	110,
+	116,
+	101,
+	102,
+	117,
+	110,
+	99,
+	105,
+	111,
+	110,
+	97,
+	99,
+	111,
+	110,
+	115,
+	101,
+	106,
+	111,
+	115,
+	100,
+	105,
+	102,
+	195,
+	173,
+	99,
+	105,
+	108,
+	99,
+	105,
+	117,
+	100,
+	97,
+	100,
+	101,
+	115,
+	97,
+	110,
+	116,
+	105,
+	103,
+	117,
+	97,
+	115,
+	97,
+	118,
+	97,
+	110,
+	122,
+	97,
+	100,
+	97,
+	116,
+	195,
+	169,
+	114,
+	109,
+	105,
+	110,
+	111,
+	117,
+	110,
+	105,
+	100,
+	97,
+	100,
+	101,
+	115,
+	115,
+	195,
+	161,
+	110,
+	99,
+	104,
+	101,
+	122,
+	99,
+	97,
+	109,
+	112,
+	97,
+	195,
+	177,
+	97,
+	115,
+	111,
+	102,
+	116,
+	111,
+	110,
+	105,
+	99,
+	114,
+	101,
+	118,
+	105,
+	115,
+	116,
+	97,
+	115,
+	99,
+	111,
+	110,
+	116,
+	105,
+	101,
+	110,
+	101,
+	115,
+	101,
+	99,
+	116,
+	111,
+	114,
+	101,
+	115,
+	109,
+	111,
+	109,
+	101,
+	110,
+	116,
+	111,
+	115,
+	102,
+	97,
+	99,
+	117,
+	108,
+	116,
+	97,
+	100,
+	99,
+	114,
+	195,
+	169,
+	100,
+	105,
+	116,
+	111,
+	100,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	115,
+	115,
+	117,
+	112,
+	117,
+	101,
+	115,
+	116,
+	111,
+	102,
+	97,
+	99,
+	116,
+	111,
+	114,
+	101,
+	115,
+	115,
+	101,
+	103,
+	117,
+	110,
+	100,
+	111,
+	115,
+	112,
+	101,
+	113,
+	117,
+	101,
+	195,
+	177,
+	97,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	208,
+	176,
+	208,
+	181,
+	209,
+	129,
+	208,
+	187,
+	208,
+	184,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	208,
+	177,
+	209,
+	139,
+	208,
+	187,
+	208,
+	190,
+	208,
+	177,
+	209,
+	139,
+	209,
+	130,
+	209,
+	140,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	208,
+	149,
+	209,
+	129,
+	208,
+	187,
+	208,
+	184,
+	209,
+	130,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	209,
+	143,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	209,
+	133,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	185,
+	208,
+	180,
+	208,
+	176,
+	208,
+	182,
+	208,
+	181,
+	208,
+	177,
+	209,
+	139,
+	208,
+	187,
+	208,
+	184,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	209,
+	131,
+	208,
+	180,
+	208,
+	181,
+	208,
+	189,
+	209,
+	140,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	209,
+	130,
+	208,
+	177,
+	209,
+	139,
+	208,
+	187,
+	208,
+	176,
+	209,
+	129,
+	208,
+	181,
+	208,
+	177,
+	209,
+	143,
+	208,
+	190,
+	208,
+	180,
+	208,
+	184,
+	208,
+	189,
+	209,
+	129,
+	208,
+	181,
+	208,
+	177,
+	208,
+	181,
+	208,
+	189,
+	208,
+	176,
+	208,
+	180,
+	208,
+	190,
+	209,
+	129,
+	208,
+	176,
+	208,
+	185,
+	209,
+	130,
+	209,
+	132,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	208,
+	189,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	184,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	185,
+	208,
+	184,
+	208,
+	179,
+	209,
+	128,
+	209,
+	139,
+	209,
+	130,
+	208,
+	190,
+	208,
+	182,
+	208,
+	181,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	188,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	209,
+	142,
+	208,
+	187,
+	208,
+	184,
+	209,
+	136,
+	209,
+	140,
+	209,
+	141,
+	209,
+	130,
+	208,
+	184,
+	209,
+	133,
+	208,
+	191,
+	208,
+	190,
+	208,
+	186,
+	208,
+	176,
+	208,
+	180,
+	208,
+	189,
+	208,
+	181,
+	208,
+	185,
+	208,
+	180,
+	208,
+	190,
+	208,
+	188,
+	208,
+	176,
+	208,
+	188,
+	208,
+	184,
+	209,
+	128,
+	208,
+	176,
+	208,
+	187,
+	208,
+	184,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	209,
+	131,
+	209,
+	133,
+	208,
+	190,
+	209,
+	130,
+	209,
+	143,
+	208,
+	180,
+	208,
+	178,
+	209,
+	131,
+	209,
+	133,
+	209,
+	129,
+	208,
+	181,
+	209,
+	130,
+	208,
+	184,
+	208,
+	187,
+	209,
+	142,
+	208,
+	180,
+	208,
+	184,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	190,
+	208,
+	188,
+	208,
+	184,
+	209,
+	128,
+	208,
+	181,
+	209,
+	130,
+	208,
+	181,
+	208,
+	177,
+	209,
+	143,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	181,
+	208,
+	178,
+	208,
+	184,
+	208,
+	180,
+	208,
+	181,
+	209,
+	135,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	141,
+	209,
+	130,
+	208,
+	184,
+	208,
+	188,
+	209,
+	129,
+	209,
+	135,
+	208,
+	181,
+	209,
+	130,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	209,
+	139,
+	209,
+	134,
+	208,
+	181,
+	208,
+	189,
+	209,
+	139,
+	209,
+	129,
+	209,
+	130,
+	208,
+	176,
+	208,
+	187,
+	208,
+	178,
+	208,
+	181,
+	208,
+	180,
+	209,
+	140,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	208,
+	181,
+	208,
+	178,
+	208,
+	190,
+	208,
+	180,
+	209,
+	139,
+	209,
+	130,
+	208,
+	181,
+	208,
+	177,
+	208,
+	181,
+	208,
+	178,
+	209,
+	139,
+	209,
+	136,
+	208,
+	181,
+	208,
+	189,
+	208,
+	176,
+	208,
+	188,
+	208,
+	184,
+	209,
+	130,
+	208,
+	184,
+	208,
+	191,
+	208,
+	176,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	209,
+	131,
+	208,
+	191,
+	209,
+	128,
+	208,
+	176,
+	208,
+	178,
+	208,
+	187,
+	208,
+	184,
+	209,
+	134,
+	208,
+	176,
+	208,
+	190,
+	208,
+	180,
+	208,
+	189,
+	208,
+	176,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	209,
+	139,
+	208,
+	183,
+	208,
+	189,
+	208,
+	176,
+	209,
+	142,
+	208,
+	188,
+	208,
+	190,
+	208,
+	179,
+	209,
+	131,
+	208,
+	180,
+	209,
+	128,
+	209,
+	131,
+	208,
+	179,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	185,
+	208,
+	184,
+	208,
+	180,
+	208,
+	181,
+	209,
+	130,
+	208,
+	186,
+	208,
+	184,
+	208,
+	189,
+	208,
+	190,
+	208,
+	190,
+	208,
+	180,
+	208,
+	189,
+	208,
+	190,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	176,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	181,
+	209,
+	129,
+	209,
+	128,
+	208,
+	190,
+	208,
+	186,
+	208,
+	184,
+	209,
+	142,
+	208,
+	189,
+	209,
+	143,
+	208,
+	178,
+	208,
+	181,
+	209,
+	129,
+	209,
+	140,
+	208,
+	149,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	176,
+	208,
+	189,
+	208,
+	176,
+	209,
+	136,
+	208,
+	184,
+	216,
+	167,
+	217,
+	132,
+	217,
+	132,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	217,
+	138,
+	216,
+	172,
+	217,
+	133,
+	217,
+	138,
+	216,
+	185,
+	216,
+	174,
+	216,
+	167,
+	216,
+	181,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	176,
+	217,
+	138,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	217,
+	135,
+	216,
+	172,
+	216,
+	175,
+	217,
+	138,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	162,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	175,
+	216,
+	170,
+	216,
+	173,
+	217,
+	131,
+	217,
+	133,
+	216,
+	181,
+	217,
+	129,
+	216,
+	173,
+	216,
+	169,
+	217,
+	131,
+	216,
+	167,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	132,
+	217,
+	138,
+	217,
+	138,
+	217,
+	131,
+	217,
+	136,
+	217,
+	134,
+	216,
+	180,
+	216,
+	168,
+	217,
+	131,
+	216,
+	169,
+	217,
+	129,
+	217,
+	138,
+	217,
+	135,
+	216,
+	167,
+	216,
+	168,
+	217,
+	134,
+	216,
+	167,
+	216,
+	170,
+	216,
+	173,
+	217,
+	136,
+	216,
+	167,
+	216,
+	161,
+	216,
+	163,
+	217,
+	131,
+	216,
+	171,
+	216,
+	177,
+	216,
+	174,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	173,
+	216,
+	168,
+	216,
+	175,
+	217,
+	132,
+	217,
+	138,
+	217,
+	132,
+	216,
+	175,
+	216,
+	177,
+	217,
+	136,
+	216,
+	179,
+	216,
+	167,
+	216,
+	182,
+	216,
+	186,
+	216,
+	183,
+	216,
+	170,
+	217,
+	131,
+	217,
+	136,
+	217,
+	134,
+	217,
+	135,
+	217,
+	134,
+	216,
+	167,
+	217,
+	131,
+	216,
+	179,
+	216,
+	167,
+	216,
+	173,
+	216,
+	169,
+	217,
+	134,
+	216,
+	167,
+	216,
+	175,
+	217,
+	138,
+	216,
+	167,
+	217,
+	132,
+	216,
+	183,
+	216,
+	168,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	217,
+	131,
+	216,
+	180,
+	217,
+	131,
+	216,
+	177,
+	216,
+	167,
+	217,
+	138,
+	217,
+	133,
+	217,
+	131,
+	217,
+	134,
+	217,
+	133,
+	217,
+	134,
+	217,
+	135,
+	216,
+	167,
+	216,
+	180,
+	216,
+	177,
+	217,
+	131,
+	216,
+	169,
+	216,
+	177,
+	216,
+	166,
+	217,
+	138,
+	216,
+	179,
+	217,
+	134,
+	216,
+	180,
+	217,
+	138,
+	216,
+	183,
+	217,
+	133,
+	216,
+	167,
+	216,
+	176,
+	216,
+	167,
+	216,
+	167,
+	217,
+	132,
+	217,
+	129,
+	217,
+	134,
+	216,
+	180,
+	216,
+	168,
+	216,
+	167,
+	216,
+	168,
+	216,
+	170,
+	216,
+	185,
+	216,
+	168,
+	216,
+	177,
+	216,
+	177,
+	216,
+	173,
+	217,
+	133,
+	216,
+	169,
+	217,
+	131,
+	216,
+	167,
+	217,
+	129,
+	216,
+	169,
+	217,
+	138,
+	217,
+	130,
+	217,
+	136,
+	217,
+	132,
+	217,
+	133,
+	216,
+	177,
+	217,
+	131,
+	216,
+	178,
+	217,
+	131,
+	217,
+	132,
+	217,
+	133,
+	216,
+	169,
+	216,
+	163,
+	216,
+	173,
+	217,
+	133,
+	216,
+	175,
+	217,
+	130,
+	217,
+	132,
+	216,
+	168,
+	217,
+	138,
+	217,
+	138,
+	216,
+	185,
+	217,
+	134,
+	217,
+	138,
+	216,
+	181,
+	217,
+	136,
+	216,
+	177,
+	216,
+	169,
+	216,
+	183,
+	216,
+	177,
+	217,
+	138,
+	217,
+	130,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	172,
+	217,
+	136,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	174,
+	216,
+	177,
+	217,
+	137,
+	217,
+	133,
+	216,
+	185,
+	217,
+	134,
+	216,
+	167,
+	216,
+	167,
+	216,
+	168,
+	216,
+	173,
+	216,
+	171,
+	216,
+	185,
+	216,
+	177,
+	217,
+	136,
+	216,
+	182,
+	216,
+	168,
+	216,
+	180,
+	217,
+	131,
+	217,
+	132,
+	217,
+	133,
+	216,
+	179,
+	216,
+	172,
+	217,
+	132,
+	216,
+	168,
+	217,
+	134,
+	216,
+	167,
+	217,
+	134,
+	216,
+	174,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	131,
+	216,
+	170,
+	216,
+	167,
+	216,
+	168,
+	217,
+	131,
+	217,
+	132,
+	217,
+	138,
+	216,
+	169,
+	216,
+	168,
+	216,
+	175,
+	217,
+	136,
+	217,
+	134,
+	216,
+	163,
+	217,
+	138,
+	216,
+	182,
+	216,
+	167,
+	217,
+	138,
+	217,
+	136,
+	216,
+	172,
+	216,
+	175,
+	217,
+	129,
+	216,
+	177,
+	217,
+	138,
+	217,
+	130,
+	217,
+	131,
+	216,
+	170,
+	216,
+	168,
+	216,
+	170,
+	216,
+	163,
+	217,
+	129,
+	216,
+	182,
+	217,
+	132,
+	217,
+	133,
+	216,
+	183,
+	216,
+	168,
+	216,
+	174,
+	216,
+	167,
+	217,
+	131,
+	216,
+	171,
+	216,
+	177,
+	216,
+	168,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	217,
+	129,
+	216,
+	182,
+	217,
+	132,
+	216,
+	167,
+	216,
+	173,
+	217,
+	132,
+	217,
+	137,
+	217,
+	134,
+	217,
+	129,
+	216,
+	179,
+	217,
+	135,
+	216,
+	163,
+	217,
+	138,
+	216,
+	167,
+	217,
+	133,
+	216,
+	177,
+	216,
+	175,
+	217,
+	136,
+	216,
+	175,
+	216,
+	163,
+	217,
+	134,
+	217,
+	135,
+	216,
+	167,
+	216,
+	175,
+	217,
+	138,
+	217,
+	134,
+	216,
+	167,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	134,
+	217,
+	133,
+	216,
+	185,
+	216,
+	177,
+	216,
+	182,
+	216,
+	170,
+	216,
+	185,
+	217,
+	132,
+	217,
+	133,
+	216,
+	175,
+	216,
+	167,
+	216,
+	174,
+	217,
+	132,
+	217,
+	133,
+	217,
+	133,
+	217,
+	131,
+	217,
+	134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1,
+	0,
+	1,
+	0,
+	1,
+	0,
+	1,
+	0,
+	2,
+	0,
+	2,
+	0,
+	2,
+	0,
+	2,
+	0,
+	4,
+	0,
+	4,
+	0,
+	4,
+	0,
+	4,
+	0,
+	0,
+	1,
+	2,
+	3,
+	4,
+	5,
+	6,
+	7,
+	7,
+	6,
+	5,
+	4,
+	3,
+	2,
+	1,
+	0,
+	8,
+	9,
+	10,
+	11,
+	12,
+	13,
+	14,
+	15,
+	15,
+	14,
+	13,
+	12,
+	11,
+	10,
+	9,
+	8,
+	16,
+	17,
+	18,
+	19,
+	20,
+	21,
+	22,
+	23,
+	23,
+	22,
+	21,
+	20,
+	19,
+	18,
+	17,
+	16,
+	24,
+	25,
+	26,
+	27,
+	28,
+	29,
+	30,
+	31,
+	31,
+	30,
+	29,
+	28,
+	27,
+	26,
+	25,
+	24,
+	255,
+	255,
+	255,
+	255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	255,
+	255,
+	255,
+	255,
+	1,
+	0,
+	0,
+	0,
+	2,
+	0,
+	0,
+	0,
+	2,
+	0,
+	0,
+	0,
+	1,
+	0,
+	0,
+	0,
+	1,
+	0,
+	0,
+	0,
+	3,
+	0,
+	0,
+	0,
+	255,
+	255,
+	0,
+	1,
+	0,
+	0,
+	0,
+	1,
+	0,
+	0,
+	255,
+	255,
+	0,
+	1,
+	0,
+	0,
+	0,
+	8,
+	0,
+	8,
+	0,
+	8,
+	0,
+	8,
+	0,
+	0,
+	0,
+	1,
+	0,
+	2,
+	0,
+	3,
+	0,
+	4,
+	0,
+	5,
+	0,
+	6,
+	0,
+	7,
+	114,
+	101,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	115,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	113,
+	117,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	105,
+	112,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	116,
+	121,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	104,
+	105,
+	103,
+	104,
+	108,
+	105,
+	103,
+	104,
+	116,
+	68,
+	84,
+	68,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	109,
+	97,
+	114,
+	107,
+	101,
+	116,
+	105,
+	110,
+	103,
+	107,
+	110,
+	111,
+	119,
+	108,
+	101,
+	100,
+	103,
+	101,
+	115,
+	111,
+	109,
+	101,
+	116,
+	104,
+	105,
+	110,
+	103,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	117,
+	98,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	97,
+	100,
+	118,
+	101,
+	114,
+	116,
+	105,
+	115,
+	101,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	60,
+	47,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	62,
+	65,
+	117,
+	115,
+	116,
+	114,
+	97,
+	108,
+	105,
+	97,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	105,
+	116,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	105,
+	116,
+	121,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	112,
+	114,
+	105,
+	109,
+	97,
+	114,
+	105,
+	108,
+	121,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	104,
+	97,
+	108,
+	108,
+	101,
+	110,
+	103,
+	101,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	97,
+	110,
+	111,
+	110,
+	121,
+	109,
+	111,
+	117,
+	115,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	105,
+	101,
+	115,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	97,
+	103,
+	114,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	34,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	112,
+	111,
+	116,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	101,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	103,
+	117,
+	109,
+	101,
+	110,
+	116,
+	115,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	97,
+	114,
+	121,
+	99,
+	111,
+	112,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	115,
+	101,
+	120,
+	99,
+	108,
+	117,
+	115,
+	105,
+	118,
+	101,
+	99,
+	111,
+	110,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	13,
+	10,
+	115,
+	116,
+	97,
+	116,
+	101,
+	109,
+	101,
+	110,
+	116,
+	97,
+	116,
+	116,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	66,
+	105,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	121,
+	125,
+	32,
+	101,
+	108,
+	115,
+	101,
+	32,
+	123,
+	10,
+	115,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	115,
+	119,
+	104,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	65,
+	110,
+	97,
+	108,
+	121,
+	116,
+	105,
+	99,
+	115,
+	116,
+	101,
+	109,
+	112,
+	108,
+	97,
+	116,
+	101,
+	115,
+	100,
+	97,
+	110,
+	103,
+	101,
+	114,
+	111,
+	117,
+	115,
+	115,
+	97,
+	116,
+	101,
+	108,
+	108,
+	105,
+	116,
+	101,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	115,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	114,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	112,
+	114,
+	111,
+	116,
+	111,
+	116,
+	121,
+	112,
+	101,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	38,
+	114,
+	97,
+	113,
+	117,
+	111,
+	59,
+	60,
+	47,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	111,
+	114,
+	109,
+	98,
+	101,
+	97,
+	117,
+	116,
+	105,
+	102,
+	117,
+	108,
+	116,
+	114,
+	97,
+	110,
+	115,
+	112,
+	111,
+	114,
+	116,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	122,
+	101,
+	100,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	112,
+	114,
+	111,
+	109,
+	105,
+	110,
+	101,
+	110,
+	116,
+	117,
+	110,
+	116,
+	105,
+	108,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	117,
+	109,
+	98,
+	110,
+	97,
+	105,
+	108,
+	78,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	46,
+	102,
+	111,
+	99,
+	117,
+	115,
+	40,
+	41,
+	59,
+	111,
+	118,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	105,
+	103,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	110,
+	110,
+	111,
+	117,
+	110,
+	99,
+	101,
+	100,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	34,
+	62,
+	10,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	108,
+	101,
+	115,
+	115,
+	32,
+	116,
+	104,
+	97,
+	110,
+	101,
+	120,
+	112,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	102,
+	114,
+	97,
+	109,
+	101,
+	119,
+	111,
+	114,
+	107,
+	116,
+	101,
+	114,
+	114,
+	105,
+	116,
+	111,
+	114,
+	121,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	99,
+	108,
+	97,
+	115,
+	115,
+	78,
+	97,
+	109,
+	101,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	105,
+	115,
+	109,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	101,
+	108,
+	115,
+	101,
+	119,
+	104,
+	101,
+	114,
+	101,
+	65,
+	108,
+	101,
+	120,
+	97,
+	110,
+	100,
+	101,
+	114,
+	97,
+	112,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	100,
+	109,
+	97,
+	116,
+	101,
+	114,
+	105,
+	97,
+	108,
+	115,
+	98,
+	114,
+	111,
+	97,
+	100,
+	99,
+	97,
+	115,
+	116,
+	109,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	101,
+	100,
+	97,
+	102,
+	102,
+	105,
+	108,
+	105,
+	97,
+	116,
+	101,
+	60,
+	47,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	62,
+	116,
+	114,
+	101,
+	97,
+	116,
+	109,
+	101,
+	110,
+	116,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	47,
+	100,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	46,
+	80,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	111,
+	110,
+	99,
+	108,
+	105,
+	99,
+	107,
+	61,
+	34,
+	98,
+	105,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	121,
+	111,
+	116,
+	104,
+	101,
+	114,
+	119,
+	105,
+	115,
+	101,
+	112,
+	101,
+	114,
+	109,
+	97,
+	110,
+	101,
+	110,
+	116,
+	70,
+	114,
+	97,
+	110,
+	195,
+	167,
+	97,
+	105,
+	115,
+	72,
+	111,
+	108,
+	108,
+	121,
+	119,
+	111,
+	111,
+	100,
+	101,
+	120,
+	112,
+	97,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	116,
+	97,
+	110,
+	100,
+	97,
+	114,
+	100,
+	115,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	10,
+	114,
+	101,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	68,
+	101,
+	99,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	112,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	67,
+	97,
+	109,
+	98,
+	114,
+	105,
+	100,
+	103,
+	101,
+	111,
+	112,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	115,
+	66,
+	117,
+	115,
+	105,
+	110,
+	101,
+	115,
+	115,
+	32,
+	99,
+	111,
+	110,
+	102,
+	117,
+	115,
+	105,
+	111,
+	110,
+	62,
+	10,
+	60,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	100,
+	101,
+	120,
+	112,
+	108,
+	97,
+	105,
+	110,
+	101,
+	100,
+	100,
+	111,
+	101,
+	115,
+	32,
+	110,
+	111,
+	116,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	119,
+	105,
+	100,
+	101,
+	105,
+	110,
+	116,
+	101,
+	114,
+	102,
+	97,
+	99,
+	101,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	110,
+	101,
+	119,
+	115,
+	112,
+	97,
+	112,
+	101,
+	114,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	10,
+	109,
+	111,
+	117,
+	110,
+	116,
+	97,
+	105,
+	110,
+	115,
+	108,
+	105,
+	107,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	115,
+	115,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	102,
+	105,
+	110,
+	97,
+	110,
+	99,
+	105,
+	97,
+	108,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	47,
+	97,
+	98,
+	97,
+	110,
+	100,
+	111,
+	110,
+	101,
+	100,
+	69,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	97,
+	114,
+	115,
+	101,
+	73,
+	110,
+	116,
+	40,
+	115,
+	116,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	117,
+	110,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	10,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	78,
+	111,
+	116,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	101,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	116,
+	119,
+	111,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	83,
+	105,
+	110,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	101,
+	102,
+	111,
+	114,
+	101,
+	119,
+	114,
+	97,
+	112,
+	112,
+	101,
+	114,
+	34,
+	62,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	101,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	101,
+	100,
+	66,
+	97,
+	116,
+	116,
+	108,
+	101,
+	32,
+	111,
+	102,
+	112,
+	101,
+	114,
+	99,
+	101,
+	105,
+	118,
+	101,
+	100,
+	116,
+	114,
+	121,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	110,
+	101,
+	99,
+	101,
+	115,
+	115,
+	97,
+	114,
+	121,
+	112,
+	111,
+	114,
+	116,
+	114,
+	97,
+	121,
+	101,
+	100,
+	101,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	69,
+	108,
+	105,
+	122,
+	97,
+	98,
+	101,
+	116,
+	104,
+	60,
+	47,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	62,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	121,
+	105,
+	110,
+	115,
+	117,
+	114,
+	97,
+	110,
+	99,
+	101,
+	115,
+	46,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	59,
+	108,
+	101,
+	103,
+	101,
+	110,
+	100,
+	97,
+	114,
+	121,
+	71,
+	101,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	121,
+	99,
+	97,
+	110,
+	100,
+	105,
+	100,
+	97,
+	116,
+	101,
+	99,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	101,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	46,
+	105,
+	110,
+	104,
+	101,
+	114,
+	105,
+	116,
+	101,
+	100,
+	60,
+	47,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	67,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	116,
+	121,
+	114,
+	101,
+	108,
+	105,
+	103,
+	105,
+	111,
+	117,
+	115,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	67,
+	111,
+	109,
+	109,
+	105,
+	116,
+	116,
+	101,
+	101,
+	98,
+	117,
+	105,
+	108,
+	100,
+	105,
+	110,
+	103,
+	115,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	110,
+	111,
+	32,
+	108,
+	111,
+	110,
+	103,
+	101,
+	114,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	99,
+	97,
+	110,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	121,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	105,
+	110,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	59,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	112,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	116,
+	101,
+	99,
+	104,
+	110,
+	105,
+	113,
+	117,
+	101,
+	116,
+	104,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	105,
+	116,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	101,
+	120,
+	105,
+	115,
+	116,
+	101,
+	110,
+	99,
+	101,
+	117,
+	110,
+	100,
+	101,
+	114,
+	108,
+	105,
+	110,
+	101,
+	116,
+	104,
+	105,
+	115,
+	32,
+	116,
+	105,
+	109,
+	101,
+	116,
+	101,
+	108,
+	101,
+	112,
+	104,
+	111,
+	110,
+	101,
+	105,
+	116,
+	101,
+	109,
+	115,
+	99,
+	111,
+	112,
+	101,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	101,
+	115,
+	97,
+	100,
+	118,
+	97,
+	110,
+	116,
+	97,
+	103,
+	101,
+	41,
+	59,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	70,
+	111,
+	114,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	105,
+	110,
+	103,
+	100,
+	101,
+	109,
+	111,
+	99,
+	114,
+	97,
+	99,
+	121,
+	98,
+	111,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	115,
+	117,
+	102,
+	102,
+	101,
+	114,
+	105,
+	110,
+	103,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	115,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	97,
+	108,
+	115,
+	97,
+	105,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	116,
+	32,
+	109,
+	97,
+	121,
+	32,
+	98,
+	101,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	60,
+	47,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	99,
+	104,
+	101,
+	100,
+	117,
+	108,
+	101,
+	100,
+	100,
+	111,
+	119,
+	110,
+	108,
+	111,
+	97,
+	100,
+	115,
+	60,
+	47,
+	108,
+	97,
+	98,
+	101,
+	108,
+	62,
+	10,
+	115,
+	117,
+	115,
+	112,
+	101,
+	99,
+	116,
+	101,
+	100,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	32,
+	48,
+	115,
+	112,
+	105,
+	114,
+	105,
+	116,
+	117,
+	97,
+	108,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	10,
+	109,
+	105,
+	99,
+	114,
+	111,
+	115,
+	111,
+	102,
+	116,
+	103,
+	114,
+	97,
+	100,
+	117,
+	97,
+	108,
+	108,
+	121,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	115,
+	101,
+	100,
+	104,
+	101,
+	32,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	101,
+	120,
+	101,
+	99,
+	117,
+	116,
+	105,
+	118,
+	101,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	46,
+	106,
+	115,
+	104,
+	111,
+	117,
+	115,
+	101,
+	104,
+	111,
+	108,
+	100,
+	99,
+	111,
+	110,
+	102,
+	105,
+	114,
+	109,
+	101,
+	100,
+	112,
+	117,
+	114,
+	99,
+	104,
+	97,
+	115,
+	101,
+	100,
+	108,
+	105,
+	116,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	100,
+	101,
+	115,
+	116,
+	114,
+	111,
+	121,
+	101,
+	100,
+	117,
+	112,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	118,
+	97,
+	114,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	105,
+	116,
+	32,
+	105,
+	115,
+	32,
+	110,
+	111,
+	116,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	105,
+	101,
+	115,
+	74,
+	97,
+	112,
+	97,
+	110,
+	101,
+	115,
+	101,
+	32,
+	97,
+	109,
+	111,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	100,
+	97,
+	108,
+	103,
+	111,
+	114,
+	105,
+	116,
+	104,
+	109,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	115,
+	114,
+	101,
+	98,
+	101,
+	108,
+	108,
+	105,
+	111,
+	110,
+	117,
+	110,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	101,
+	110,
+	99,
+	111,
+	117,
+	114,
+	97,
+	103,
+	101,
+	114,
+	101,
+	115,
+	105,
+	122,
+	97,
+	98,
+	108,
+	101,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	105,
+	110,
+	103,
+	115,
+	101,
+	110,
+	115,
+	105,
+	116,
+	105,
+	118,
+	101,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	108,
+	112,
+	114,
+	111,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	40,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	105,
+	110,
+	103,
+	99,
+	111,
+	110,
+	100,
+	117,
+	99,
+	116,
+	101,
+	100,
+	41,
+	44,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	100,
+	45,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	34,
+	62,
+	70,
+	101,
+	98,
+	114,
+	117,
+	97,
+	114,
+	121,
+	32,
+	110,
+	117,
+	109,
+	101,
+	114,
+	111,
+	117,
+	115,
+	32,
+	111,
+	118,
+	101,
+	114,
+	102,
+	108,
+	111,
+	119,
+	58,
+	99,
+	111,
+	109,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	102,
+	114,
+	97,
+	103,
+	109,
+	101,
+	110,
+	116,
+	115,
+	101,
+	120,
+	99,
+	101,
+	108,
+	108,
+	101,
+	110,
+	116,
+	99,
+	111,
+	108,
+	115,
+	112,
+	97,
+	110,
+	61,
+	34,
+	116,
+	101,
+	99,
+	104,
+	110,
+	105,
+	99,
+	97,
+	108,
+	110,
+	101,
+	97,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	65,
+	100,
+	118,
+	97,
+	110,
+	99,
+	101,
+	100,
+	32,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	32,
+	111,
+	102,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	72,
+	111,
+	110,
+	103,
+	32,
+	75,
+	111,
+	110,
+	103,
+	32,
+	70,
+	97,
+	99,
+	101,
+	98,
+	111,
+	111,
+	107,
+	109,
+	117,
+	108,
+	116,
+	105,
+	112,
+	108,
+	101,
+	32,
+	109,
+	101,
+	99,
+	104,
+	97,
+	110,
+	105,
+	115,
+	109,
+	101,
+	108,
+	101,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	102,
+	102,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	10,
+	9,
+	115,
+	112,
+	111,
+	110,
+	115,
+	111,
+	114,
+	101,
+	100,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	111,
+	114,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	116,
+	104,
+	111,
+	115,
+	101,
+	32,
+	119,
+	104,
+	111,
+	109,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	101,
+	115,
+	100,
+	105,
+	102,
+	102,
+	105,
+	99,
+	117,
+	108,
+	116,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	116,
+	101,
+	100,
+	114,
+	101,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	100,
+	99,
+	111,
+	110,
+	118,
+	105,
+	110,
+	99,
+	101,
+	100,
+	112,
+	114,
+	111,
+	109,
+	111,
+	116,
+	105,
+	110,
+	103,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	46,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	40,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	99,
+	97,
+	108,
+	99,
+	111,
+	97,
+	108,
+	105,
+	116,
+	105,
+	111,
+	110,
+	104,
+	105,
+	115,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	100,
+	101,
+	99,
+	105,
+	115,
+	105,
+	111,
+	110,
+	115,
+	97,
+	115,
+	115,
+	105,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	100,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	45,
+	119,
+	114,
+	97,
+	112,
+	112,
+	101,
+	114,
+	34,
+	101,
+	110,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	111,
+	97,
+	108,
+	111,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	108,
+	105,
+	118,
+	101,
+	114,
+	101,
+	100,
+	45,
+	45,
+	62,
+	13,
+	10,
+	60,
+	33,
+	45,
+	45,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	32,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	101,
+	100,
+	78,
+	111,
+	118,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	60,
+	102,
+	117,
+	114,
+	110,
+	105,
+	116,
+	117,
+	114,
+	101,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	101,
+	116,
+	32,
+	32,
+	111,
+	110,
+	98,
+	108,
+	117,
+	114,
+	61,
+	34,
+	115,
+	117,
+	115,
+	112,
+	101,
+	110,
+	100,
+	101,
+	100,
+	114,
+	101,
+	99,
+	105,
+	112,
+	105,
+	101,
+	110,
+	116,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	32,
+	77,
+	111,
+	114,
+	101,
+	111,
+	118,
+	101,
+	114,
+	44,
+	97,
+	98,
+	111,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	119,
+	101,
+	114,
+	101,
+	32,
+	109,
+	97,
+	100,
+	101,
+	101,
+	109,
+	111,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	101,
+	109,
+	101,
+	114,
+	103,
+	101,
+	110,
+	99,
+	121,
+	110,
+	97,
+	114,
+	114,
+	97,
+	116,
+	105,
+	118,
+	101,
+	97,
+	100,
+	118,
+	111,
+	99,
+	97,
+	116,
+	101,
+	115,
+	112,
+	120,
+	59,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	99,
+	111,
+	109,
+	109,
+	105,
+	116,
+	116,
+	101,
+	100,
+	100,
+	105,
+	114,
+	61,
+	34,
+	108,
+	116,
+	114,
+	34,
+	101,
+	109,
+	112,
+	108,
+	111,
+	121,
+	101,
+	101,
+	115,
+	114,
+	101,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	46,
+	32,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	111,
+	114,
+	99,
+	117,
+	115,
+	116,
+	111,
+	109,
+	101,
+	114,
+	115,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	101,
+	100,
+	83,
+	101,
+	112,
+	116,
+	101,
+	109,
+	98,
+	101,
+	114,
+	97,
+	100,
+	100,
+	67,
+	108,
+	97,
+	115,
+	115,
+	40,
+	70,
+	97,
+	99,
+	101,
+	98,
+	111,
+	111,
+	107,
+	32,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	101,
+	100,
+	97,
+	110,
+	100,
+	32,
+	108,
+	97,
+	116,
+	101,
+	114,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	110,
+	103,
+	101,
+	108,
+	97,
+	98,
+	111,
+	114,
+	97,
+	116,
+	101,
+	83,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	73,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	99,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	108,
+	121,
+	105,
+	110,
+	115,
+	116,
+	97,
+	108,
+	108,
+	101,
+	100,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	101,
+	114,
+	115,
+	74,
+	101,
+	114,
+	117,
+	115,
+	97,
+	108,
+	101,
+	109,
+	116,
+	104,
+	101,
+	121,
+	32,
+	104,
+	97,
+	118,
+	101,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	105,
+	110,
+	103,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	101,
+	100,
+	112,
+	114,
+	111,
+	118,
+	105,
+	110,
+	99,
+	101,
+	115,
+	103,
+	117,
+	97,
+	114,
+	97,
+	110,
+	116,
+	101,
+	101,
+	97,
+	114,
+	98,
+	105,
+	116,
+	114,
+	97,
+	114,
+	121,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	122,
+	101,
+	119,
+	97,
+	110,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	112,
+	120,
+	59,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	116,
+	104,
+	101,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	98,
+	101,
+	104,
+	97,
+	118,
+	105,
+	111,
+	117,
+	114,
+	87,
+	104,
+	105,
+	108,
+	101,
+	32,
+	116,
+	104,
+	101,
+	101,
+	115,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	100,
+	98,
+	101,
+	103,
+	97,
+	110,
+	32,
+	116,
+	111,
+	32,
+	105,
+	116,
+	32,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	109,
+	97,
+	103,
+	110,
+	105,
+	116,
+	117,
+	100,
+	101,
+	109,
+	117,
+	115,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	68,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	121,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	101,
+	99,
+	114,
+	101,
+	116,
+	97,
+	114,
+	121,
+	110,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	108,
+	121,
+	111,
+	99,
+	99,
+	117,
+	114,
+	114,
+	105,
+	110,
+	103,
+	118,
+	97,
+	114,
+	105,
+	97,
+	98,
+	108,
+	101,
+	115,
+	103,
+	105,
+	118,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	112,
+	108,
+	97,
+	116,
+	102,
+	111,
+	114,
+	109,
+	46,
+	60,
+	47,
+	108,
+	97,
+	98,
+	101,
+	108,
+	62,
+	60,
+	102,
+	97,
+	105,
+	108,
+	101,
+	100,
+	32,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	111,
+	117,
+	110,
+	100,
+	115,
+	107,
+	105,
+	110,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	115,
+	111,
+	99,
+	105,
+	101,
+	116,
+	105,
+	101,
+	115,
+	97,
+	108,
+	111,
+	110,
+	103,
+	115,
+	105,
+	100,
+	101,
+	32,
+	45,
+	45,
+	38,
+	103,
+	116,
+	59,
+	10,
+	10,
+	115,
+	111,
+	117,
+	116,
+	104,
+	119,
+	101,
+	115,
+	116,
+	116,
+	104,
+	101,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	114,
+	97,
+	100,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	97,
+	121,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	117,
+	110,
+	101,
+	115,
+	99,
+	97,
+	112,
+	101,
+	40,
+	115,
+	112,
+	111,
+	107,
+	101,
+	110,
+	32,
+	105,
+	110,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	101,
+	111,
+	110,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	109,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	121,
+	98,
+	117,
+	114,
+	105,
+	101,
+	100,
+	32,
+	105,
+	110,
+	97,
+	32,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	116,
+	104,
+	101,
+	121,
+	32,
+	119,
+	101,
+	114,
+	101,
+	60,
+	47,
+	102,
+	111,
+	110,
+	116,
+	62,
+	60,
+	47,
+	78,
+	111,
+	114,
+	119,
+	101,
+	103,
+	105,
+	97,
+	110,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	101,
+	100,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	105,
+	110,
+	103,
+	112,
+	97,
+	115,
+	115,
+	101,
+	110,
+	103,
+	101,
+	114,
+	40,
+	110,
+	101,
+	119,
+	32,
+	68,
+	97,
+	116,
+	101,
+	116,
+	101,
+	109,
+	112,
+	111,
+	114,
+	97,
+	114,
+	121,
+	102,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	65,
+	102,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	101,
+	113,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	100,
+	111,
+	119,
+	110,
+	108,
+	111,
+	97,
+	100,
+	46,
+	114,
+	101,
+	103,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	114,
+	97,
+	98,
+	111,
+	118,
+	101,
+	32,
+	116,
+	104,
+	101,
+	108,
+	105,
+	110,
+	107,
+	101,
+	100,
+	32,
+	116,
+	111,
+	112,
+	104,
+	101,
+	110,
+	111,
+	109,
+	101,
+	110,
+	97,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	32,
+	111,
+	102,
+	116,
+	111,
+	111,
+	108,
+	116,
+	105,
+	112,
+	34,
+	62,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	97,
+	117,
+	116,
+	111,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	115,
+	112,
+	101,
+	99,
+	116,
+	32,
+	111,
+	102,
+	65,
+	109,
+	111,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	101,
+	100,
+	101,
+	115,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	115,
+	65,
+	105,
+	114,
+	32,
+	70,
+	111,
+	114,
+	99,
+	101,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	32,
+	111,
+	102,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	105,
+	109,
+	109,
+	101,
+	100,
+	105,
+	97,
+	116,
+	101,
+	109,
+	97,
+	107,
+	105,
+	110,
+	103,
+	32,
+	105,
+	116,
+	112,
+	97,
+	105,
+	110,
+	116,
+	105,
+	110,
+	103,
+	115,
+	99,
+	111,
+	110,
+	113,
+	117,
+	101,
+	114,
+	101,
+	100,
+	97,
+	114,
+	101,
+	32,
+	115,
+	116,
+	105,
+	108,
+	108,
+	112,
+	114,
+	111,
+	99,
+	101,
+	100,
+	117,
+	114,
+	101,
+	103,
+	114,
+	111,
+	119,
+	116,
+	104,
+	32,
+	111,
+	102,
+	104,
+	101,
+	97,
+	100,
+	101,
+	100,
+	32,
+	98,
+	121,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	97,
+	110,
+	32,
+	100,
+	105,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	115,
+	109,
+	111,
+	108,
+	101,
+	99,
+	117,
+	108,
+	101,
+	115,
+	102,
+	114,
+	97,
+	110,
+	99,
+	104,
+	105,
+	115,
+	101,
+	105,
+	110,
+	116,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	97,
+	116,
+	116,
+	114,
+	97,
+	99,
+	116,
+	101,
+	100,
+	99,
+	104,
+	105,
+	108,
+	100,
+	104,
+	111,
+	111,
+	100,
+	97,
+	108,
+	115,
+	111,
+	32,
+	117,
+	115,
+	101,
+	100,
+	100,
+	101,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	100,
+	115,
+	105,
+	110,
+	103,
+	97,
+	112,
+	111,
+	114,
+	101,
+	100,
+	101,
+	103,
+	114,
+	101,
+	101,
+	32,
+	111,
+	102,
+	102,
+	97,
+	116,
+	104,
+	101,
+	114,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	102,
+	108,
+	105,
+	99,
+	116,
+	115,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	112,
+	62,
+	10,
+	99,
+	97,
+	109,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	119,
+	101,
+	114,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	110,
+	111,
+	116,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	114,
+	101,
+	99,
+	101,
+	105,
+	118,
+	105,
+	110,
+	103,
+	69,
+	120,
+	101,
+	99,
+	117,
+	116,
+	105,
+	118,
+	101,
+	101,
+	118,
+	101,
+	110,
+	32,
+	109,
+	111,
+	114,
+	101,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	32,
+	116,
+	111,
+	99,
+	111,
+	109,
+	109,
+	97,
+	110,
+	100,
+	101,
+	114,
+	80,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	109,
+	117,
+	115,
+	105,
+	99,
+	105,
+	97,
+	110,
+	115,
+	100,
+	101,
+	108,
+	105,
+	99,
+	105,
+	111,
+	117,
+	115,
+	112,
+	114,
+	105,
+	115,
+	111,
+	110,
+	101,
+	114,
+	115,
+	97,
+	100,
+	118,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	85,
+	84,
+	70,
+	45,
+	56,
+	34,
+	32,
+	47,
+	62,
+	60,
+	33,
+	91,
+	67,
+	68,
+	65,
+	84,
+	65,
+	91,
+	34,
+	62,
+	67,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	83,
+	111,
+	117,
+	116,
+	104,
+	101,
+	114,
+	110,
+	32,
+	98,
+	103,
+	99,
+	111,
+	108,
+	111,
+	114,
+	61,
+	34,
+	115,
+	101,
+	114,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	46,
+	32,
+	73,
+	116,
+	32,
+	119,
+	97,
+	115,
+	32,
+	105,
+	110,
+	32,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	112,
+	101,
+	114,
+	109,
+	105,
+	116,
+	116,
+	101,
+	100,
+	118,
+	97,
+	108,
+	105,
+	100,
+	97,
+	116,
+	101,
+	46,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	105,
+	110,
+	103,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	115,
+	115,
+	101,
+	114,
+	105,
+	111,
+	117,
+	115,
+	108,
+	121,
+	45,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	116,
+	101,
+	100,
+	101,
+	120,
+	116,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	108,
+	111,
+	110,
+	103,
+	45,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	102,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	117,
+	99,
+	104,
+	32,
+	116,
+	104,
+	97,
+	116,
+	103,
+	101,
+	116,
+	67,
+	111,
+	111,
+	107,
+	105,
+	101,
+	109,
+	97,
+	114,
+	107,
+	101,
+	100,
+	32,
+	98,
+	121,
+	60,
+	47,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	62,
+	105,
+	109,
+	112,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	98,
+	117,
+	116,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	101,
+	115,
+	100,
+	111,
+	119,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	105,
+	110,
+	103,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	45,
+	45,
+	62,
+	10,
+	60,
+	33,
+	45,
+	45,
+	32,
+	105,
+	110,
+	116,
+	101,
+	114,
+	118,
+	105,
+	101,
+	119,
+	87,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	112,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	115,
+	101,
+	110,
+	115,
+	117,
+	115,
+	119,
+	97,
+	115,
+	32,
+	98,
+	117,
+	105,
+	108,
+	116,
+	86,
+	101,
+	110,
+	101,
+	122,
+	117,
+	101,
+	108,
+	97,
+	40,
+	102,
+	111,
+	114,
+	109,
+	101,
+	114,
+	108,
+	121,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	97,
+	116,
+	101,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	110,
+	101,
+	108,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	103,
+	105,
+	99,
+	102,
+	97,
+	118,
+	111,
+	117,
+	114,
+	32,
+	111,
+	102,
+	105,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	87,
+	105,
+	107,
+	105,
+	112,
+	101,
+	100,
+	105,
+	97,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	101,
+	110,
+	116,
+	118,
+	105,
+	114,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	119,
+	97,
+	115,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	108,
+	101,
+	67,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	32,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	99,
+	97,
+	108,
+	115,
+	104,
+	111,
+	119,
+	32,
+	116,
+	104,
+	97,
+	116,
+	112,
+	114,
+	105,
+	109,
+	105,
+	116,
+	105,
+	118,
+	101,
+	97,
+	119,
+	97,
+	121,
+	32,
+	102,
+	114,
+	111,
+	109,
+	109,
+	111,
+	108,
+	101,
+	99,
+	117,
+	108,
+	97,
+	114,
+	112,
+	114,
+	101,
+	99,
+	105,
+	115,
+	101,
+	108,
+	121,
+	100,
+	105,
+	115,
+	115,
+	111,
+	108,
+	118,
+	101,
+	100,
+	85,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	61,
+	34,
+	62,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	73,
+	116,
+	32,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	84,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	32,
+	119,
+	105,
+	108,
+	108,
+	32,
+	104,
+	97,
+	118,
+	101,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	115,
+	109,
+	115,
+	115,
+	111,
+	109,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	70,
+	114,
+	105,
+	101,
+	100,
+	114,
+	105,
+	99,
+	104,
+	119,
+	97,
+	115,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	116,
+	104,
+	101,
+	32,
+	111,
+	110,
+	108,
+	121,
+	32,
+	102,
+	97,
+	99,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	102,
+	111,
+	114,
+	109,
+	32,
+	105,
+	100,
+	61,
+	34,
+	112,
+	114,
+	101,
+	99,
+	101,
+	100,
+	105,
+	110,
+	103,
+	84,
+	101,
+	99,
+	104,
+	110,
+	105,
+	99,
+	97,
+	108,
+	112,
+	104,
+	121,
+	115,
+	105,
+	99,
+	105,
+	115,
+	116,
+	111,
+	99,
+	99,
+	117,
+	114,
+	115,
+	32,
+	105,
+	110,
+	110,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	111,
+	114,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	34,
+	62,
+	115,
+	112,
+	97,
+	110,
+	32,
+	105,
+	100,
+	61,
+	34,
+	115,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	111,
+	98,
+	101,
+	108,
+	111,
+	119,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	114,
+	118,
+	105,
+	118,
+	105,
+	110,
+	103,
+	125,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	104,
+	105,
+	115,
+	32,
+	100,
+	101,
+	97,
+	116,
+	104,
+	97,
+	115,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	100,
+	32,
+	98,
+	121,
+	112,
+	97,
+	114,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	101,
+	120,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	32,
+	117,
+	115,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	119,
+	97,
+	115,
+	32,
+	103,
+	105,
+	118,
+	101,
+	110,
+	97,
+	32,
+	108,
+	105,
+	115,
+	116,
+	32,
+	111,
+	102,
+	108,
+	101,
+	118,
+	101,
+	108,
+	115,
+	32,
+	111,
+	102,
+	110,
+	111,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	79,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	32,
+	100,
+	105,
+	115,
+	109,
+	105,
+	115,
+	115,
+	101,
+	100,
+	115,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	115,
+	116,
+	114,
+	101,
+	115,
+	101,
+	109,
+	98,
+	108,
+	101,
+	115,
+	100,
+	117,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	101,
+	101,
+	120,
+	112,
+	108,
+	111,
+	115,
+	105,
+	118,
+	101,
+	114,
+	101,
+	99,
+	111,
+	118,
+	101,
+	114,
+	101,
+	100,
+	97,
+	108,
+	108,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	103,
+	97,
+	108,
+	108,
+	101,
+	114,
+	105,
+	101,
+	115,
+	123,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	111,
+	102,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	97,
+	100,
+	100,
+	114,
+	101,
+	115,
+	115,
+	101,
+	115,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	101,
+	105,
+	109,
+	103,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	105,
+	110,
+	32,
+	109,
+	111,
+	100,
+	101,
+	114,
+	110,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	32,
+	111,
+	102,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	105,
+	110,
+	103,
+	116,
+	105,
+	109,
+	101,
+	115,
+	116,
+	97,
+	109,
+	112,
+	110,
+	101,
+	101,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	116,
+	104,
+	101,
+	32,
+	71,
+	114,
+	101,
+	97,
+	116,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	105,
+	110,
+	103,
+	115,
+	101,
+	101,
+	109,
+	101,
+	100,
+	32,
+	116,
+	111,
+	118,
+	105,
+	101,
+	119,
+	101,
+	100,
+	32,
+	97,
+	115,
+	105,
+	109,
+	112,
+	97,
+	99,
+	116,
+	32,
+	111,
+	110,
+	105,
+	100,
+	101,
+	97,
+	32,
+	116,
+	104,
+	97,
+	116,
+	116,
+	104,
+	101,
+	32,
+	87,
+	111,
+	114,
+	108,
+	100,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	32,
+	111,
+	102,
+	101,
+	120,
+	112,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	84,
+	104,
+	101,
+	115,
+	101,
+	32,
+	97,
+	114,
+	101,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	34,
+	62,
+	99,
+	97,
+	114,
+	101,
+	102,
+	117,
+	108,
+	108,
+	121,
+	109,
+	97,
+	105,
+	110,
+	116,
+	97,
+	105,
+	110,
+	115,
+	99,
+	104,
+	97,
+	114,
+	103,
+	101,
+	32,
+	111,
+	102,
+	67,
+	108,
+	97,
+	115,
+	115,
+	105,
+	99,
+	97,
+	108,
+	97,
+	100,
+	100,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	112,
+	114,
+	101,
+	100,
+	105,
+	99,
+	116,
+	101,
+	100,
+	111,
+	119,
+	110,
+	101,
+	114,
+	115,
+	104,
+	105,
+	112,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	13,
+	10,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	108,
+	101,
+	97,
+	118,
+	101,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	62,
+	97,
+	114,
+	101,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	32,
+	125,
+	41,
+	40,
+	41,
+	59,
+	13,
+	10,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	108,
+	121,
+	32,
+	80,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	111,
+	114,
+	45,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	34,
+	32,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	101,
+	100,
+	115,
+	97,
+	121,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	104,
+	97,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	105,
+	110,
+	72,
+	117,
+	110,
+	103,
+	97,
+	114,
+	105,
+	97,
+	110,
+	115,
+	116,
+	97,
+	116,
+	117,
+	115,
+	32,
+	111,
+	102,
+	115,
+	101,
+	114,
+	118,
+	101,
+	115,
+	32,
+	97,
+	115,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	108,
+	101,
+	120,
+	101,
+	99,
+	117,
+	116,
+	105,
+	111,
+	110,
+	97,
+	103,
+	103,
+	114,
+	101,
+	103,
+	97,
+	116,
+	101,
+	102,
+	111,
+	114,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	105,
+	110,
+	102,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	103,
+	114,
+	101,
+	101,
+	100,
+	32,
+	116,
+	111,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	34,
+	62,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	111,
+	110,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	101,
+	108,
+	101,
+	99,
+	116,
+	111,
+	114,
+	97,
+	108,
+	115,
+	121,
+	109,
+	98,
+	111,
+	108,
+	32,
+	111,
+	102,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	116,
+	111,
+	97,
+	114,
+	99,
+	104,
+	105,
+	116,
+	101,
+	99,
+	116,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	105,
+	97,
+	110,
+	112,
+	114,
+	101,
+	118,
+	105,
+	111,
+	117,
+	115,
+	32,
+	108,
+	105,
+	118,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	101,
+	97,
+	115,
+	105,
+	101,
+	114,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	111,
+	114,
+	10,
+	38,
+	108,
+	116,
+	59,
+	33,
+	45,
+	45,
+	32,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	32,
+	111,
+	102,
+	97,
+	110,
+	97,
+	108,
+	121,
+	116,
+	105,
+	99,
+	115,
+	119,
+	97,
+	115,
+	32,
+	116,
+	97,
+	107,
+	101,
+	110,
+	119,
+	104,
+	101,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	111,
+	107,
+	32,
+	111,
+	118,
+	101,
+	114,
+	98,
+	101,
+	108,
+	105,
+	101,
+	102,
+	32,
+	105,
+	110,
+	65,
+	102,
+	114,
+	105,
+	107,
+	97,
+	97,
+	110,
+	115,
+	97,
+	115,
+	32,
+	102,
+	97,
+	114,
+	32,
+	97,
+	115,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	101,
+	100,
+	119,
+	111,
+	114,
+	107,
+	32,
+	119,
+	105,
+	116,
+	104,
+	97,
+	32,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	60,
+	102,
+	105,
+	101,
+	108,
+	100,
+	115,
+	101,
+	116,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	109,
+	97,
+	115,
+	82,
+	101,
+	116,
+	114,
+	105,
+	101,
+	118,
+	101,
+	100,
+	10,
+	10,
+	73,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	98,
+	97,
+	99,
+	107,
+	32,
+	105,
+	110,
+	116,
+	111,
+	110,
+	111,
+	114,
+	116,
+	104,
+	101,
+	97,
+	115,
+	116,
+	109,
+	97,
+	103,
+	97,
+	122,
+	105,
+	110,
+	101,
+	115,
+	62,
+	60,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	99,
+	111,
+	109,
+	109,
+	105,
+	116,
+	116,
+	101,
+	101,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	105,
+	110,
+	103,
+	103,
+	114,
+	111,
+	117,
+	112,
+	115,
+	32,
+	111,
+	102,
+	115,
+	116,
+	111,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	97,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	105,
+	116,
+	115,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	116,
+	104,
+	101,
+	105,
+	114,
+	32,
+	111,
+	119,
+	110,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	101,
+	100,
+	97,
+	110,
+	32,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	67,
+	97,
+	114,
+	105,
+	98,
+	98,
+	101,
+	97,
+	110,
+	97,
+	108,
+	108,
+	111,
+	119,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	115,
+	119,
+	105,
+	115,
+	99,
+	111,
+	110,
+	115,
+	105,
+	110,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	59,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	32,
+	105,
+	110,
+	104,
+	97,
+	98,
+	105,
+	116,
+	101,
+	100,
+	83,
+	111,
+	99,
+	105,
+	97,
+	108,
+	105,
+	115,
+	116,
+	74,
+	97,
+	110,
+	117,
+	97,
+	114,
+	121,
+	32,
+	49,
+	60,
+	47,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	62,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	108,
+	121,
+	99,
+	104,
+	111,
+	105,
+	99,
+	101,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	32,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	99,
+	32,
+	98,
+	117,
+	115,
+	105,
+	110,
+	101,
+	115,
+	115,
+	32,
+	84,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	46,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	59,
+	32,
+	100,
+	101,
+	115,
+	105,
+	114,
+	101,
+	32,
+	116,
+	111,
+	100,
+	101,
+	97,
+	108,
+	32,
+	119,
+	105,
+	116,
+	104,
+	115,
+	105,
+	110,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	117,
+	115,
+	101,
+	114,
+	65,
+	103,
+	101,
+	110,
+	116,
+	99,
+	111,
+	110,
+	99,
+	101,
+	105,
+	118,
+	101,
+	100,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	112,
+	104,
+	112,
+	97,
+	115,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	101,
+	110,
+	103,
+	97,
+	103,
+	101,
+	32,
+	105,
+	110,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	108,
+	121,
+	44,
+	102,
+	101,
+	119,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	119,
+	101,
+	114,
+	101,
+	32,
+	97,
+	108,
+	115,
+	111,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	101,
+	100,
+	105,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	114,
+	101,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	99,
+	105,
+	116,
+	105,
+	101,
+	115,
+	32,
+	105,
+	110,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	107,
+	101,
+	121,
+	99,
+	111,
+	110,
+	100,
+	101,
+	109,
+	110,
+	101,
+	100,
+	97,
+	108,
+	115,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	44,
+	102,
+	97,
+	109,
+	105,
+	108,
+	121,
+	32,
+	111,
+	102,
+	83,
+	99,
+	104,
+	111,
+	111,
+	108,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	116,
+	101,
+	100,
+	110,
+	97,
+	116,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	115,
+	60,
+	47,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	62,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	115,
+	32,
+	97,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	115,
+	97,
+	100,
+	118,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	84,
+	104,
+	101,
+	121,
+	32,
+	119,
+	101,
+	114,
+	101,
+	97,
+	110,
+	121,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	61,
+	101,
+	110,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	109,
+	117,
+	99,
+	104,
+	32,
+	109,
+	111,
+	114,
+	101,
+	114,
+	101,
+	102,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	119,
+	97,
+	115,
+	32,
+	110,
+	97,
+	109,
+	101,
+	100,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	32,
+	97,
+	32,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	119,
+	104,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	121,
+	101,
+	110,
+	103,
+	105,
+	110,
+	101,
+	101,
+	114,
+	115,
+	99,
+	111,
+	117,
+	108,
+	100,
+	32,
+	110,
+	111,
+	116,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	115,
+	119,
+	101,
+	100,
+	110,
+	101,
+	115,
+	100,
+	97,
+	121,
+	116,
+	104,
+	101,
+	32,
+	116,
+	104,
+	105,
+	114,
+	100,
+	32,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	115,
+	74,
+	97,
+	110,
+	117,
+	97,
+	114,
+	121,
+	32,
+	50,
+	119,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	121,
+	97,
+	32,
+	99,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	114,
+	101,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	111,
+	114,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	104,
+	105,
+	115,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	115,
+	116,
+	32,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	100,
+	34,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	116,
+	100,
+	62,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	32,
+	111,
+	110,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	34,
+	62,
+	10,
+	112,
+	105,
+	101,
+	99,
+	101,
+	115,
+	32,
+	111,
+	102,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	105,
+	110,
+	103,
+	82,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	116,
+	101,
+	110,
+	110,
+	101,
+	115,
+	115,
+	101,
+	101,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	104,
+	97,
+	115,
+	32,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	61,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	32,
+	60,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	62,
+	103,
+	105,
+	118,
+	101,
+	115,
+	32,
+	116,
+	104,
+	101,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	97,
+	110,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	34,
+	62,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	48,
+	118,
+	105,
+	101,
+	119,
+	32,
+	116,
+	104,
+	97,
+	116,
+	116,
+	111,
+	103,
+	101,
+	116,
+	104,
+	101,
+	114,
+	44,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	115,
+	117,
+	98,
+	115,
+	101,
+	116,
+	32,
+	111,
+	102,
+	97,
+	116,
+	116,
+	97,
+	99,
+	107,
+	32,
+	111,
+	110,
+	99,
+	104,
+	105,
+	108,
+	100,
+	114,
+	101,
+	110,
+	44,
+	112,
+	111,
+	105,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	32,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	97,
+	108,
+	108,
+	101,
+	103,
+	101,
+	100,
+	108,
+	121,
+	67,
+	108,
+	101,
+	118,
+	101,
+	108,
+	97,
+	110,
+	100,
+	119,
+	97,
+	115,
+	32,
+	108,
+	97,
+	116,
+	101,
+	114,
+	97,
+	110,
+	100,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	97,
+	114,
+	101,
+	32,
+	103,
+	105,
+	118,
+	101,
+	110,
+	119,
+	97,
+	115,
+	32,
+	115,
+	116,
+	105,
+	108,
+	108,
+	115,
+	99,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	32,
+	111,
+	102,
+	109,
+	97,
+	107,
+	101,
+	115,
+	32,
+	116,
+	104,
+	101,
+	109,
+	117,
+	99,
+	104,
+	32,
+	108,
+	101,
+	115,
+	115,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	115,
+	46,
+	10,
+	10,
+	65,
+	102,
+	116,
+	101,
+	114,
+	32,
+	44,
+	32,
+	98,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	77,
+	117,
+	115,
+	101,
+	117,
+	109,
+	32,
+	111,
+	102,
+	108,
+	111,
+	117,
+	105,
+	115,
+	105,
+	97,
+	110,
+	97,
+	40,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	109,
+	105,
+	110,
+	110,
+	101,
+	115,
+	111,
+	116,
+	97,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	115,
+	97,
+	32,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	68,
+	111,
+	109,
+	105,
+	110,
+	105,
+	99,
+	97,
+	110,
+	118,
+	111,
+	108,
+	117,
+	109,
+	101,
+	32,
+	111,
+	102,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	105,
+	110,
+	103,
+	100,
+	101,
+	102,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	48,
+	48,
+	112,
+	120,
+	124,
+	114,
+	105,
+	103,
+	104,
+	109,
+	97,
+	100,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	109,
+	111,
+	117,
+	115,
+	101,
+	111,
+	118,
+	101,
+	114,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	115,
+	116,
+	97,
+	116,
+	101,
+	115,
+	32,
+	111,
+	102,
+	40,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	105,
+	115,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	115,
+	70,
+	114,
+	97,
+	110,
+	99,
+	105,
+	115,
+	99,
+	111,
+	98,
+	117,
+	105,
+	108,
+	100,
+	105,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	111,
+	117,
+	116,
+	32,
+	97,
+	119,
+	105,
+	116,
+	104,
+	32,
+	115,
+	111,
+	109,
+	101,
+	119,
+	104,
+	111,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	97,
+	32,
+	102,
+	111,
+	114,
+	109,
+	32,
+	111,
+	102,
+	97,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	105,
+	116,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	32,
+	32,
+	83,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	110,
+	100,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	105,
+	110,
+	103,
+	97,
+	110,
+	100,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	112,
+	97,
+	112,
+	101,
+	114,
+	98,
+	97,
+	99,
+	107,
+	118,
+	97,
+	108,
+	117,
+	101,
+	115,
+	32,
+	111,
+	102,
+	13,
+	10,
+	60,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	61,
+	32,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	101,
+	114,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	112,
+	108,
+	97,
+	121,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	110,
+	100,
+	32,
+	101,
+	97,
+	114,
+	108,
+	121,
+	60,
+	47,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	62,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	105,
+	115,
+	116,
+	104,
+	101,
+	32,
+	116,
+	104,
+	114,
+	101,
+	101,
+	112,
+	111,
+	119,
+	101,
+	114,
+	32,
+	97,
+	110,
+	100,
+	111,
+	102,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	105,
+	110,
+	110,
+	101,
+	114,
+	72,
+	84,
+	77,
+	76,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	121,
+	58,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	59,
+	67,
+	104,
+	117,
+	114,
+	99,
+	104,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	101,
+	118,
+	101,
+	110,
+	116,
+	118,
+	101,
+	114,
+	121,
+	32,
+	104,
+	105,
+	103,
+	104,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	32,
+	45,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	47,
+	99,
+	103,
+	105,
+	45,
+	98,
+	105,
+	110,
+	47,
+	116,
+	111,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	97,
+	102,
+	114,
+	105,
+	107,
+	97,
+	97,
+	110,
+	115,
+	101,
+	115,
+	112,
+	101,
+	114,
+	97,
+	110,
+	116,
+	111,
+	102,
+	114,
+	97,
+	110,
+	195,
+	167,
+	97,
+	105,
+	115,
+	108,
+	97,
+	116,
+	118,
+	105,
+	101,
+	197,
+	161,
+	117,
+	108,
+	105,
+	101,
+	116,
+	117,
+	118,
+	105,
+	197,
+	179,
+	196,
+	140,
+	101,
+	197,
+	161,
+	116,
+	105,
+	110,
+	97,
+	196,
+	141,
+	101,
+	197,
+	161,
+	116,
+	105,
+	110,
+	97,
+	224,
+	185,
+	132,
+	224,
+	184,
+	151,
+	224,
+	184,
+	162,
+	230,
+	151,
+	165,
+	230,
+	156,
+	172,
+	232,
+	170,
+	158,
+	231,
+	174,
+	128,
+	228,
+	189,
+	147,
+	229,
+	173,
+	151,
+	231,
+	185,
+	129,
+	233,
+	171,
+	148,
+	229,
+	173,
+	151,
+	237,
+	149,
+	156,
+	234,
+	181,
+	173,
+	236,
+	150,
+	180,
+	228,
+	184,
+	186,
+	228,
+	187,
+	128,
+	228,
+	185,
+	136,
+	232,
+	174,
+	161,
+	231,
+	174,
+	151,
+	230,
+	156,
+	186,
+	231,
+	172,
+	148,
+	232,
+	174,
+	176,
+	230,
+	156,
+	172,
+	232,
+	168,
+	142,
+	232,
+	171,
+	150,
+	229,
+	141,
+	128,
+	230,
+	156,
+	141,
+	229,
+	138,
+	161,
+	229,
+	153,
+	168,
+	228,
+	186,
+	146,
+	232,
+	129,
+	148,
+	231,
+	189,
+	145,
+	230,
+	136,
+	191,
+	229,
+	156,
+	176,
+	228,
+	186,
+	167,
+	228,
+	191,
+	177,
+	228,
+	185,
+	144,
+	233,
+	131,
+	168,
+	229,
+	135,
+	186,
+	231,
+	137,
+	136,
+	231,
+	164,
+	190,
+	230,
+	142,
+	146,
+	232,
+	161,
+	140,
+	230,
+	166,
+	156,
+	233,
+	131,
+	168,
+	232,
+	144,
+	189,
+	230,
+	160,
+	188,
+	232,
+	191,
+	155,
+	228,
+	184,
+	128,
+	230,
+	173,
+	165,
+	230,
+	148,
+	175,
+	228,
+	187,
+	152,
+	229,
+	174,
+	157,
+	233,
+	170,
+	140,
+	232,
+	175,
+	129,
+	231,
+	160,
+	129,
+	229,
+	167,
+	148,
+	229,
+	145,
+	152,
+	228,
+	188,
+	154,
+	230,
+	149,
+	176,
+	230,
+	141,
+	174,
+	229,
+	186,
+	147,
+	230,
+	182,
+	136,
+	232,
+	180,
+	185,
+	232,
+	128,
+	133,
+	229,
+	138,
+	158,
+	229,
+	133,
+	172,
+	229,
+	174,
+	164,
+	232,
+	174,
+	168,
+	232,
+	174,
+	186,
+	229,
+	140,
+	186,
+	230,
+	183,
+	177,
+	229,
+	156,
+	179,
+	229,
+	184,
+	130,
+	230,
+	146,
+	173,
+	230,
+	148,
+	190,
+	229,
+	153,
+	168,
+	229,
+	140,
+	151,
+	228,
+	186,
+	172,
+	229,
+	184,
+	130,
+	229,
+	164,
+	167,
+	229,
+	173,
+	166,
+	231,
+	148,
+	159,
+	232,
+	182,
+	138,
+	230,
+	157,
+	165,
+	232,
+	182,
+	138,
+	231,
+	174,
+	161,
+	231,
+	144,
+	134,
+	229,
+	145,
+	152,
+	228,
+	191,
+	161,
+	230,
+	129,
+	175,
+	231,
+	189,
+	145,
+	115,
+	101,
+	114,
+	118,
+	105,
+	99,
+	105,
+	111,
+	115,
+	97,
+	114,
+	116,
+	195,
+	173,
+	99,
+	117,
+	108,
+	111,
+	97,
+	114,
+	103,
+	101,
+	110,
+	116,
+	105,
+	110,
+	97,
+	98,
+	97,
+	114,
+	99,
+	101,
+	108,
+	111,
+	110,
+	97,
+	99,
+	117,
+	97,
+	108,
+	113,
+	117,
+	105,
+	101,
+	114,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	100,
+	111,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	111,
+	115,
+	112,
+	111,
+	108,
+	195,
+	173,
+	116,
+	105,
+	99,
+	97,
+	114,
+	101,
+	115,
+	112,
+	117,
+	101,
+	115,
+	116,
+	97,
+	119,
+	105,
+	107,
+	105,
+	112,
+	101,
+	100,
+	105,
+	97,
+	115,
+	105,
+	103,
+	117,
+	105,
+	101,
+	110,
+	116,
+	101,
+	98,
+	195,
+	186,
+	115,
+	113,
+	117,
+	101,
+	100,
+	97,
+	99,
+	111,
+	109,
+	117,
+	110,
+	105,
+	100,
+	97,
+	100,
+	115,
+	101,
+	103,
+	117,
+	114,
+	105,
+	100,
+	97,
+	100,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	97,
+	108,
+	112,
+	114,
+	101,
+	103,
+	117,
+	110,
+	116,
+	97,
+	115,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	105,
+	100,
+	111,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	101,
+	114,
+	118,
+	101,
+	110,
+	101,
+	122,
+	117,
+	101,
+	108,
+	97,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	97,
+	115,
+	100,
+	105,
+	99,
+	105,
+	101,
+	109,
+	98,
+	114,
+	101,
+	114,
+	101,
+	108,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	110,
+	111,
+	118,
+	105,
+	101,
+	109,
+	98,
+	114,
+	101,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	101,
+	115,
+	112,
+	114,
+	111,
+	121,
+	101,
+	99,
+	116,
+	111,
+	115,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	97,
+	115,
+	105,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	111,
+	97,
+	99,
+	116,
+	105,
+	118,
+	105,
+	100,
+	97,
+	100,
+	101,
+	110,
+	99,
+	117,
+	101,
+	110,
+	116,
+	114,
+	97,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	195,
+	173,
+	97,
+	105,
+	109,
+	195,
+	161,
+	103,
+	101,
+	110,
+	101,
+	115,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	97,
+	114,
+	100,
+	101,
+	115,
+	99,
+	97,
+	114,
+	103,
+	97,
+	114,
+	110,
+	101,
+	99,
+	101,
+	115,
+	97,
+	114,
+	105,
+	111,
+	97,
+	116,
+	101,
+	110,
+	99,
+	105,
+	195,
+	179,
+	110,
+	116,
+	101,
+	108,
+	195,
+	169,
+	102,
+	111,
+	110,
+	111,
+	99,
+	111,
+	109,
+	105,
+	115,
+	105,
+	195,
+	179,
+	110,
+	99,
+	97,
+	110,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	99,
+	97,
+	112,
+	97,
+	99,
+	105,
+	100,
+	97,
+	100,
+	101,
+	110,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	114,
+	97,
+	110,
+	195,
+	161,
+	108,
+	105,
+	115,
+	105,
+	115,
+	102,
+	97,
+	118,
+	111,
+	114,
+	105,
+	116,
+	111,
+	115,
+	116,
+	195,
+	169,
+	114,
+	109,
+	105,
+	110,
+	111,
+	115,
+	112,
+	114,
+	111,
+	118,
+	105,
+	110,
+	99,
+	105,
+	97,
+	101,
+	116,
+	105,
+	113,
+	117,
+	101,
+	116,
+	97,
+	115,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	111,
+	115,
+	102,
+	117,
+	110,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	97,
+	100,
+	111,
+	99,
+	97,
+	114,
+	195,
+	161,
+	99,
+	116,
+	101,
+	114,
+	112,
+	114,
+	111,
+	112,
+	105,
+	101,
+	100,
+	97,
+	100,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	105,
+	111,
+	110,
+	101,
+	99,
+	101,
+	115,
+	105,
+	100,
+	97,
+	100,
+	109,
+	117,
+	110,
+	105,
+	99,
+	105,
+	112,
+	97,
+	108,
+	99,
+	114,
+	101,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	100,
+	101,
+	115,
+	99,
+	97,
+	114,
+	103,
+	97,
+	115,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	99,
+	105,
+	97,
+	99,
+	111,
+	109,
+	101,
+	114,
+	99,
+	105,
+	97,
+	108,
+	111,
+	112,
+	105,
+	110,
+	105,
+	111,
+	110,
+	101,
+	115,
+	101,
+	106,
+	101,
+	114,
+	99,
+	105,
+	99,
+	105,
+	111,
+	101,
+	100,
+	105,
+	116,
+	111,
+	114,
+	105,
+	97,
+	108,
+	115,
+	97,
+	108,
+	97,
+	109,
+	97,
+	110,
+	99,
+	97,
+	103,
+	111,
+	110,
+	122,
+	195,
+	161,
+	108,
+	101,
+	122,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	111,
+	112,
+	101,
+	108,
+	195,
+	173,
+	99,
+	117,
+	108,
+	97,
+	114,
+	101,
+	99,
+	105,
+	101,
+	110,
+	116,
+	101,
+	115,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	101,
+	115,
+	116,
+	97,
+	114,
+	114,
+	97,
+	103,
+	111,
+	110,
+	97,
+	112,
+	114,
+	195,
+	161,
+	99,
+	116,
+	105,
+	99,
+	97,
+	110,
+	111,
+	118,
+	101,
+	100,
+	97,
+	100,
+	101,
+	115,
+	112,
+	114,
+	111,
+	112,
+	117,
+	101,
+	115,
+	116,
+	97,
+	112,
+	97,
+	99,
+	105,
+	101,
+	110,
+	116,
+	101,
+	115,
+	116,
+	195,
+	169,
+	99,
+	110,
+	105,
+	99,
+	97,
+	115,
+	111,
+	98,
+	106,
+	101,
+	116,
+	105,
+	118,
+	111,
+	115,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	111,
+	115,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	143,
+	224,
+	164,
+	185,
+	224,
+	165,
+	136,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	165,
+	224,
+	164,
+	143,
+	224,
+	164,
+	181,
+	224,
+	164,
+	130,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	165,
+	139,
+	224,
+	164,
+	136,
+	224,
+	164,
+	149,
+	224,
+	165,
+	129,
+	224,
+	164,
+	155,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	149,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	173,
+	224,
+	165,
+	128,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	143,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	174,
+	224,
+	165,
+	136,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	100,
+	105,
+	112,
+	108,
+	111,
+	100,
+	111,
+	99,
+	115,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	164,
+	175,
+	224,
+	164,
+	176,
+	224,
+	165,
+	130,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	170,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	171,
+	224,
+	164,
+	191,
+	224,
+	164,
+	176,
+	224,
+	164,
+	148,
+	224,
+	164,
+	184,
+	224,
+	164,
+	164,
+	224,
+	164,
+	164,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	164,
+	178,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	134,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	136,
+	224,
+	164,
+	150,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	164,
+	175,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	181,
+	224,
+	165,
+	135,
+	224,
+	164,
+	172,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	168,
+	224,
+	164,
+	172,
+	224,
+	165,
+	128,
+	224,
+	164,
+	154,
+	224,
+	164,
+	174,
+	224,
+	165,
+	140,
+	224,
+	164,
+	164,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	156,
+	224,
+	165,
+	137,
+	224,
+	164,
+	172,
+	224,
+	164,
+	174,
+	224,
+	164,
+	166,
+	224,
+	164,
+	166,
+	224,
+	164,
+	164,
+	224,
+	164,
+	165,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	182,
+	224,
+	164,
+	185,
+	224,
+	164,
+	176,
+	224,
+	164,
+	133,
+	224,
+	164,
+	178,
+	224,
+	164,
+	151,
+	224,
+	164,
+	149,
+	224,
+	164,
+	173,
+	224,
+	165,
+	128,
+	224,
+	164,
+	168,
+	224,
+	164,
+	151,
+	224,
+	164,
+	176,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	143,
+	224,
+	164,
+	137,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	151,
+	224,
+	164,
+	175,
+	224,
+	165,
+	128,
+	224,
+	164,
+	185,
+	224,
+	165,
+	130,
+	224,
+	164,
+	129,
+	224,
+	164,
+	134,
+	224,
+	164,
+	151,
+	224,
+	165,
+	135,
+	224,
+	164,
+	159,
+	224,
+	165,
+	128,
+	224,
+	164,
+	174,
+	224,
+	164,
+	150,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	133,
+	224,
+	164,
+	173,
+	224,
+	165,
+	128,
+	224,
+	164,
+	151,
+	224,
+	164,
+	175,
+	224,
+	165,
+	135,
+	224,
+	164,
+	164,
+	224,
+	165,
+	129,
+	224,
+	164,
+	174,
+	224,
+	164,
+	181,
+	224,
+	165,
+	139,
+	224,
+	164,
+	159,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	133,
+	224,
+	164,
+	151,
+	224,
+	164,
+	176,
+	224,
+	164,
+	144,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	164,
+	178,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	138,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	164,
+	154,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	144,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	176,
+	224,
+	164,
+	156,
+	224,
+	164,
+	191,
+	224,
+	164,
+	184,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	172,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	164,
+	172,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	165,
+	130,
+	224,
+	164,
+	130,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	150,
+	224,
+	164,
+	156,
+	224,
+	165,
+	128,
+	224,
+	164,
+	164,
+	224,
+	164,
+	172,
+	224,
+	164,
+	159,
+	224,
+	164,
+	168,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	134,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	165,
+	129,
+	224,
+	164,
+	178,
+	224,
+	164,
+	178,
+	224,
+	165,
+	137,
+	224,
+	164,
+	151,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	151,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	164,
+	156,
+	224,
+	164,
+	151,
+	224,
+	164,
+	185,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	178,
+	224,
+	164,
+	151,
+	224,
+	165,
+	135,
+	224,
+	164,
+	170,
+	224,
+	165,
+	135,
+	224,
+	164,
+	156,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	165,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	160,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	129,
+	224,
+	164,
+	166,
+	224,
+	165,
+	130,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	164,
+	185,
+	224,
+	164,
+	164,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	134,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	149,
+	224,
+	165,
+	140,
+	224,
+	164,
+	168,
+	224,
+	164,
+	182,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	175,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	150,
+	224,
+	165,
+	129,
+	224,
+	164,
+	166,
+	224,
+	164,
+	178,
+	224,
+	164,
+	151,
+	224,
+	165,
+	128,
+	99,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	105,
+	101,
+	115,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	101,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	13,
+	10,
+	67,
+	111,
+	112,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	32,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	99,
+	111,
+	110,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	118,
+	101,
+	114,
+	121,
+	116,
+	104,
+	105,
+	110,
+	103,
+	60,
+	112,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	101,
+	99,
+	104,
+	110,
+	111,
+	108,
+	111,
+	103,
+	121,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	60,
+	97,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	97,
+	110,
+	97,
+	103,
+	101,
+	109,
+	101,
+	110,
+	116,
+	38,
+	99,
+	111,
+	112,
+	121,
+	59,
+	32,
+	50,
+	48,
+	49,
+	106,
+	97,
+	118,
+	97,
+	83,
+	99,
+	114,
+	105,
+	112,
+	116,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	115,
+	98,
+	114,
+	101,
+	97,
+	100,
+	99,
+	114,
+	117,
+	109,
+	98,
+	116,
+	104,
+	101,
+	109,
+	115,
+	101,
+	108,
+	118,
+	101,
+	115,
+	104,
+	111,
+	114,
+	105,
+	122,
+	111,
+	110,
+	116,
+	97,
+	108,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	67,
+	97,
+	108,
+	105,
+	102,
+	111,
+	114,
+	110,
+	105,
+	97,
+	97,
+	99,
+	116,
+	105,
+	118,
+	105,
+	116,
+	105,
+	101,
+	115,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	101,
+	100,
+	78,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	114,
+	97,
+	110,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	110,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	97,
+	110,
+	99,
+	101,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	60,
+	109,
+	99,
+	104,
+	101,
+	99,
+	107,
+	98,
+	111,
+	120,
+	34,
+	32,
+	116,
+	101,
+	99,
+	104,
+	110,
+	105,
+	113,
+	117,
+	101,
+	115,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	112,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	97,
+	115,
+	32,
+	119,
+	101,
+	108,
+	108,
+	32,
+	97,
+	115,
+	117,
+	110,
+	116,
+	39,
+	44,
+	32,
+	39,
+	85,
+	65,
+	45,
+	114,
+	101,
+	115,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	101,
+	108,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	101,
+	100,
+	87,
+	97,
+	115,
+	104,
+	105,
+	110,
+	103,
+	116,
+	111,
+	110,
+	110,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	111,
+	114,
+	46,
+	32,
+	61,
+	32,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	105,
+	109,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	38,
+	108,
+	116,
+	59,
+	98,
+	114,
+	38,
+	103,
+	116,
+	59,
+	108,
+	105,
+	116,
+	101,
+	114,
+	97,
+	116,
+	117,
+	114,
+	101,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	103,
+	99,
+	111,
+	108,
+	111,
+	114,
+	61,
+	34,
+	35,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	110,
+	101,
+	119,
+	115,
+	108,
+	101,
+	116,
+	116,
+	101,
+	114,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	105,
+	101,
+	115,
+	100,
+	101,
+	102,
+	105,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	108,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	104,
+	105,
+	112,
+	84,
+	101,
+	99,
+	104,
+	110,
+	111,
+	108,
+	111,
+	103,
+	121,
+	80,
+	97,
+	114,
+	108,
+	105,
+	97,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	105,
+	115,
+	111,
+	110,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	46,
+	105,
+	110,
+	100,
+	101,
+	120,
+	79,
+	102,
+	40,
+	34,
+	99,
+	111,
+	110,
+	99,
+	108,
+	117,
+	115,
+	105,
+	111,
+	110,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	115,
+	105,
+	111,
+	110,
+	99,
+	111,
+	109,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	115,
+	98,
+	105,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	82,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	95,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	111,
+	111,
+	100,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	112,
+	101,
+	114,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	101,
+	97,
+	99,
+	104,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	97,
+	116,
+	109,
+	111,
+	115,
+	112,
+	104,
+	101,
+	114,
+	101,
+	32,
+	111,
+	110,
+	102,
+	111,
+	99,
+	117,
+	115,
+	61,
+	34,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	105,
+	100,
+	61,
+	34,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	105,
+	110,
+	103,
+	116,
+	104,
+	105,
+	115,
+	46,
+	118,
+	97,
+	108,
+	117,
+	101,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	67,
+	111,
+	110,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	117,
+	98,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	119,
+	101,
+	108,
+	108,
+	45,
+	107,
+	110,
+	111,
+	119,
+	110,
+	118,
+	97,
+	114,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	114,
+	101,
+	112,
+	117,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	104,
+	101,
+	110,
+	111,
+	109,
+	101,
+	110,
+	111,
+	110,
+	100,
+	105,
+	115,
+	99,
+	105,
+	112,
+	108,
+	105,
+	110,
+	101,
+	108,
+	111,
+	103,
+	111,
+	46,
+	112,
+	110,
+	103,
+	34,
+	32,
+	40,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	44,
+	98,
+	111,
+	117,
+	110,
+	100,
+	97,
+	114,
+	105,
+	101,
+	115,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	101,
+	116,
+	116,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	66,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	111,
+	117,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	105,
+	115,
+	101,
+	40,
+	34,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	34,
+	32,
+	117,
+	110,
+	101,
+	115,
+	99,
+	97,
+	112,
+	101,
+	40,
+	34,
+	112,
+	97,
+	115,
+	115,
+	119,
+	111,
+	114,
+	100,
+	34,
+	32,
+	100,
+	101,
+	109,
+	111,
+	99,
+	114,
+	97,
+	116,
+	105,
+	99,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	119,
+	114,
+	97,
+	112,
+	112,
+	101,
+	114,
+	34,
+	62,
+	10,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	115,
+	104,
+	105,
+	112,
+	108,
+	105,
+	110,
+	103,
+	117,
+	105,
+	115,
+	116,
+	105,
+	99,
+	112,
+	120,
+	59,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	121,
+	97,
+	115,
+	115,
+	105,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	102,
+	97,
+	99,
+	105,
+	108,
+	105,
+	116,
+	105,
+	101,
+	115,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	122,
+	101,
+	100,
+	112,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	105,
+	102,
+	32,
+	40,
+	116,
+	121,
+	112,
+	101,
+	111,
+	102,
+	109,
+	97,
+	105,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	100,
+	118,
+	111,
+	99,
+	97,
+	98,
+	117,
+	108,
+	97,
+	114,
+	121,
+	104,
+	121,
+	112,
+	111,
+	116,
+	104,
+	101,
+	115,
+	105,
+	115,
+	46,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	40,
+	41,
+	59,
+	38,
+	97,
+	109,
+	112,
+	59,
+	110,
+	98,
+	115,
+	112,
+	59,
+	97,
+	110,
+	110,
+	111,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	104,
+	105,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	70,
+	111,
+	117,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	114,
+	34,
+	97,
+	115,
+	115,
+	117,
+	109,
+	112,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	99,
+	111,
+	114,
+	114,
+	117,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	115,
+	116,
+	115,
+	101,
+	120,
+	112,
+	108,
+	105,
+	99,
+	105,
+	116,
+	108,
+	121,
+	105,
+	110,
+	115,
+	116,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	100,
+	105,
+	109,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	110,
+	67,
+	108,
+	105,
+	99,
+	107,
+	61,
+	34,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	100,
+	101,
+	112,
+	97,
+	114,
+	116,
+	109,
+	101,
+	110,
+	116,
+	111,
+	99,
+	99,
+	117,
+	112,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	111,
+	111,
+	110,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	109,
+	101,
+	110,
+	116,
+	112,
+	114,
+	111,
+	110,
+	111,
+	117,
+	110,
+	99,
+	101,
+	100,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	101,
+	100,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	109,
+	101,
+	110,
+	116,
+	77,
+	97,
+	110,
+	97,
+	103,
+	101,
+	109,
+	101,
+	110,
+	116,
+	103,
+	101,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	105,
+	99,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	46,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	40,
+	47,
+	100,
+	101,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	112,
+	117,
+	110,
+	105,
+	115,
+	104,
+	109,
+	101,
+	110,
+	116,
+	101,
+	108,
+	105,
+	109,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	114,
+	101,
+	115,
+	105,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	97,
+	100,
+	97,
+	112,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	112,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	119,
+	101,
+	108,
+	108,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	115,
+	117,
+	112,
+	112,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	100,
+	104,
+	49,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	48,
+	112,
+	120,
+	59,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	109,
+	101,
+	99,
+	104,
+	97,
+	110,
+	105,
+	99,
+	97,
+	108,
+	115,
+	116,
+	97,
+	116,
+	105,
+	115,
+	116,
+	105,
+	99,
+	115,
+	99,
+	101,
+	108,
+	101,
+	98,
+	114,
+	97,
+	116,
+	101,
+	100,
+	71,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	10,
+	10,
+	68,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	114,
+	115,
+	97,
+	114,
+	116,
+	105,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	101,
+	113,
+	117,
+	105,
+	118,
+	97,
+	108,
+	101,
+	110,
+	116,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	67,
+	111,
+	109,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	116,
+	116,
+	97,
+	99,
+	104,
+	109,
+	101,
+	110,
+	116,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	105,
+	100,
+	61,
+	34,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	119,
+	101,
+	114,
+	101,
+	78,
+	101,
+	100,
+	101,
+	114,
+	108,
+	97,
+	110,
+	100,
+	115,
+	98,
+	101,
+	121,
+	111,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	101,
+	114,
+	101,
+	100,
+	106,
+	111,
+	117,
+	114,
+	110,
+	97,
+	108,
+	105,
+	115,
+	116,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	97,
+	108,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	101,
+	110,
+	34,
+	32,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	13,
+	10,
+	97,
+	98,
+	115,
+	111,
+	108,
+	117,
+	116,
+	101,
+	59,
+	32,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	105,
+	110,
+	103,
+	101,
+	120,
+	116,
+	114,
+	101,
+	109,
+	101,
+	108,
+	121,
+	32,
+	109,
+	97,
+	105,
+	110,
+	115,
+	116,
+	114,
+	101,
+	97,
+	109,
+	60,
+	47,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	105,
+	116,
+	121,
+	101,
+	109,
+	112,
+	108,
+	111,
+	121,
+	109,
+	101,
+	110,
+	116,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	13,
+	10,
+	32,
+	99,
+	111,
+	108,
+	115,
+	112,
+	97,
+	110,
+	61,
+	34,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	10,
+	32,
+	32,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	97,
+	98,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	47,
+	112,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	105,
+	110,
+	116,
+	101,
+	103,
+	114,
+	97,
+	116,
+	101,
+	100,
+	34,
+	32,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	101,
+	110,
+	80,
+	111,
+	114,
+	116,
+	117,
+	103,
+	117,
+	101,
+	115,
+	101,
+	115,
+	117,
+	98,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	105,
+	110,
+	100,
+	105,
+	118,
+	105,
+	100,
+	117,
+	97,
+	108,
+	105,
+	109,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	109,
+	117,
+	108,
+	116,
+	105,
+	109,
+	101,
+	100,
+	105,
+	97,
+	97,
+	108,
+	109,
+	111,
+	115,
+	116,
+	32,
+	97,
+	108,
+	108,
+	112,
+	120,
+	32,
+	115,
+	111,
+	108,
+	105,
+	100,
+	32,
+	35,
+	97,
+	112,
+	97,
+	114,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	32,
+	116,
+	111,
+	105,
+	110,
+	32,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	105,
+	122,
+	101,
+	100,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	32,
+	102,
+	111,
+	114,
+	103,
+	117,
+	105,
+	100,
+	101,
+	108,
+	105,
+	110,
+	101,
+	115,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	114,
+	101,
+	109,
+	97,
+	114,
+	107,
+	97,
+	98,
+	108,
+	101,
+	116,
+	104,
+	101,
+	32,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	104,
+	50,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	97,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	40,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	112,
+	97,
+	114,
+	97,
+	109,
+	101,
+	116,
+	101,
+	114,
+	115,
+	112,
+	114,
+	111,
+	104,
+	105,
+	98,
+	105,
+	116,
+	101,
+	100,
+	61,
+	32,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	100,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	112,
+	101,
+	114,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	102,
+	111,
+	117,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	120,
+	59,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	117,
+	108,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	101,
+	114,
+	115,
+	109,
+	105,
+	108,
+	108,
+	101,
+	110,
+	110,
+	105,
+	117,
+	109,
+	104,
+	105,
+	115,
+	32,
+	102,
+	97,
+	116,
+	104,
+	101,
+	114,
+	116,
+	104,
+	101,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	110,
+	111,
+	45,
+	114,
+	101,
+	112,
+	101,
+	97,
+	116,
+	59,
+	99,
+	111,
+	109,
+	109,
+	101,
+	114,
+	99,
+	105,
+	97,
+	108,
+	105,
+	110,
+	100,
+	117,
+	115,
+	116,
+	114,
+	105,
+	97,
+	108,
+	101,
+	110,
+	99,
+	111,
+	117,
+	114,
+	97,
+	103,
+	101,
+	100,
+	97,
+	109,
+	111,
+	117,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	117,
+	110,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	101,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	99,
+	121,
+	82,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	99,
+	111,
+	111,
+	114,
+	100,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	105,
+	115,
+	99,
+	108,
+	97,
+	105,
+	109,
+	101,
+	114,
+	101,
+	120,
+	112,
+	101,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	105,
+	110,
+	103,
+	99,
+	97,
+	108,
+	99,
+	117,
+	108,
+	97,
+	116,
+	101,
+	100,
+	115,
+	105,
+	109,
+	112,
+	108,
+	105,
+	102,
+	105,
+	101,
+	100,
+	108,
+	101,
+	103,
+	105,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	115,
+	117,
+	98,
+	115,
+	116,
+	114,
+	105,
+	110,
+	103,
+	40,
+	48,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	108,
+	121,
+	105,
+	108,
+	108,
+	117,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	102,
+	105,
+	118,
+	101,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	109,
+	101,
+	110,
+	116,
+	80,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	105,
+	110,
+	103,
+	49,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	112,
+	115,
+	121,
+	99,
+	104,
+	111,
+	108,
+	111,
+	103,
+	121,
+	99,
+	111,
+	110,
+	102,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	97,
+	98,
+	115,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	102,
+	111,
+	99,
+	117,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	106,
+	111,
+	105,
+	110,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	115,
+	112,
+	114,
+	101,
+	118,
+	105,
+	111,
+	117,
+	115,
+	108,
+	121,
+	62,
+	60,
+	47,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	62,
+	111,
+	110,
+	99,
+	101,
+	32,
+	97,
+	103,
+	97,
+	105,
+	110,
+	98,
+	117,
+	116,
+	32,
+	114,
+	97,
+	116,
+	104,
+	101,
+	114,
+	105,
+	109,
+	109,
+	105,
+	103,
+	114,
+	97,
+	110,
+	116,
+	115,
+	111,
+	102,
+	32,
+	99,
+	111,
+	117,
+	114,
+	115,
+	101,
+	44,
+	97,
+	32,
+	103,
+	114,
+	111,
+	117,
+	112,
+	32,
+	111,
+	102,
+	76,
+	105,
+	116,
+	101,
+	114,
+	97,
+	116,
+	117,
+	114,
+	101,
+	85,
+	110,
+	108,
+	105,
+	107,
+	101,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	97,
+	62,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	10,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	67,
+	111,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	97,
+	117,
+	116,
+	111,
+	109,
+	111,
+	98,
+	105,
+	108,
+	101,
+	80,
+	114,
+	111,
+	116,
+	101,
+	115,
+	116,
+	97,
+	110,
+	116,
+	97,
+	103,
+	103,
+	114,
+	101,
+	115,
+	115,
+	105,
+	118,
+	101,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	83,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	108,
+	121,
+	44,
+	34,
+	32,
+	47,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	13,
+	10,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	118,
+	105,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	116,
+	104,
+	101,
+	32,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	118,
+	111,
+	108,
+	117,
+	110,
+	116,
+	101,
+	101,
+	114,
+	115,
+	97,
+	116,
+	116,
+	114,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	104,
+	114,
+	101,
+	97,
+	116,
+	101,
+	110,
+	101,
+	100,
+	42,
+	60,
+	33,
+	91,
+	67,
+	68,
+	65,
+	84,
+	65,
+	91,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	99,
+	101,
+	105,
+	110,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	116,
+	116,
+	101,
+	114,
+	60,
+	47,
+	102,
+	111,
+	114,
+	109,
+	62,
+	10,
+	60,
+	47,
+	46,
+	105,
+	110,
+	100,
+	101,
+	120,
+	79,
+	102,
+	40,
+	39,
+	105,
+	32,
+	61,
+	32,
+	48,
+	59,
+	32,
+	105,
+	32,
+	60,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	100,
+	101,
+	118,
+	111,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	32,
+	102,
+	111,
+	114,
+	117,
+	108,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	108,
+	121,
+	116,
+	111,
+	117,
+	114,
+	110,
+	97,
+	109,
+	101,
+	110,
+	116,
+	97,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	115,
+	115,
+	111,
+	45,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	125,
+	10,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	101,
+	118,
+	97,
+	108,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	109,
+	112,
+	104,
+	97,
+	115,
+	105,
+	122,
+	101,
+	100,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	60,
+	47,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	62,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	111,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	77,
+	101,
+	97,
+	110,
+	119,
+	104,
+	105,
+	108,
+	101,
+	44,
+	105,
+	110,
+	100,
+	117,
+	115,
+	116,
+	114,
+	105,
+	101,
+	115,
+	60,
+	47,
+	97,
+	62,
+	60,
+	98,
+	114,
+	32,
+	47,
+	62,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	99,
+	111,
+	109,
+	101,
+	97,
+	115,
+	112,
+	101,
+	99,
+	116,
+	115,
+	32,
+	111,
+	102,
+	84,
+	101,
+	108,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	115,
+	117,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	98,
+	97,
+	115,
+	107,
+	101,
+	116,
+	98,
+	97,
+	108,
+	108,
+	98,
+	111,
+	116,
+	104,
+	32,
+	115,
+	105,
+	100,
+	101,
+	115,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	105,
+	110,
+	103,
+	97,
+	110,
+	32,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	60,
+	105,
+	109,
+	103,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	97,
+	100,
+	118,
+	101,
+	110,
+	116,
+	117,
+	114,
+	101,
+	115,
+	104,
+	105,
+	115,
+	32,
+	109,
+	111,
+	116,
+	104,
+	101,
+	114,
+	109,
+	97,
+	110,
+	99,
+	104,
+	101,
+	115,
+	116,
+	101,
+	114,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	108,
+	101,
+	115,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	121,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	115,
+	32,
+	111,
+	102,
+	100,
+	101,
+	99,
+	105,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	34,
+	62,
+	60,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	114,
+	115,
+	74,
+	111,
+	117,
+	114,
+	110,
+	97,
+	108,
+	32,
+	111,
+	102,
+	100,
+	105,
+	102,
+	102,
+	105,
+	99,
+	117,
+	108,
+	116,
+	121,
+	102,
+	97,
+	99,
+	105,
+	108,
+	105,
+	116,
+	97,
+	116,
+	101,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	97,
+	98,
+	108,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	46,
+	99,
+	115,
+	115,
+	34,
+	9,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	105,
+	110,
+	110,
+	111,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	62,
+	67,
+	111,
+	112,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	115,
+	105,
+	116,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	104,
+	97,
+	118,
+	101,
+	98,
+	117,
+	115,
+	105,
+	110,
+	101,
+	115,
+	115,
+	101,
+	115,
+	68,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	115,
+	116,
+	97,
+	116,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	112,
+	101,
+	114,
+	115,
+	105,
+	115,
+	116,
+	101,
+	110,
+	116,
+	105,
+	110,
+	32,
+	74,
+	97,
+	110,
+	117,
+	97,
+	114,
+	121,
+	99,
+	111,
+	109,
+	112,
+	114,
+	105,
+	115,
+	105,
+	110,
+	103,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	10,
+	9,
+	100,
+	105,
+	112,
+	108,
+	111,
+	109,
+	97,
+	116,
+	105,
+	99,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	105,
+	110,
+	103,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	109,
+	97,
+	121,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	32,
+	111,
+	102,
+	32,
+	111,
+	110,
+	99,
+	108,
+	105,
+	99,
+	107,
+	61,
+	34,
+	73,
+	116,
+	32,
+	105,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	102,
+	105,
+	110,
+	97,
+	110,
+	99,
+	105,
+	97,
+	108,
+	32,
+	109,
+	97,
+	107,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	76,
+	117,
+	120,
+	101,
+	109,
+	98,
+	111,
+	117,
+	114,
+	103,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	114,
+	101,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	101,
+	110,
+	103,
+	97,
+	103,
+	101,
+	100,
+	32,
+	105,
+	110,
+	34,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	41,
+	59,
+	98,
+	117,
+	116,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	111,
+	110,
+	105,
+	99,
+	111,
+	110,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	61,
+	34,
+	10,
+	60,
+	33,
+	45,
+	45,
+	32,
+	69,
+	110,
+	100,
+	32,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	105,
+	99,
+	97,
+	108,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	116,
+	111,
+	112,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	117,
+	110,
+	108,
+	105,
+	107,
+	101,
+	32,
+	116,
+	104,
+	101,
+	65,
+	117,
+	115,
+	116,
+	114,
+	97,
+	108,
+	105,
+	97,
+	110,
+	79,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	10,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	115,
+	101,
+	100,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	108,
+	105,
+	122,
+	101,
+	108,
+	105,
+	109,
+	105,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	65,
+	108,
+	101,
+	120,
+	97,
+	110,
+	100,
+	114,
+	105,
+	97,
+	114,
+	101,
+	116,
+	105,
+	114,
+	101,
+	109,
+	101,
+	110,
+	116,
+	65,
+	100,
+	118,
+	101,
+	110,
+	116,
+	117,
+	114,
+	101,
+	115,
+	102,
+	111,
+	117,
+	114,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	10,
+	10,
+	38,
+	108,
+	116,
+	59,
+	33,
+	45,
+	45,
+	32,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	105,
+	110,
+	103,
+	100,
+	101,
+	99,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	104,
+	51,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	115,
+	32,
+	111,
+	102,
+	111,
+	98,
+	108,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	103,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	102,
+	105,
+	101,
+	100,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	97,
+	100,
+	118,
+	97,
+	110,
+	116,
+	97,
+	103,
+	101,
+	115,
+	98,
+	101,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	97,
+	110,
+	115,
+	60,
+	98,
+	97,
+	115,
+	101,
+	32,
+	104,
+	114,
+	101,
+	102,
+	114,
+	101,
+	112,
+	101,
+	97,
+	116,
+	101,
+	100,
+	108,
+	121,
+	119,
+	105,
+	108,
+	108,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	97,
+	98,
+	108,
+	101,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	97,
+	116,
+	101,
+	100,
+	110,
+	111,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	110,
+	115,
+	105,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	118,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	105,
+	122,
+	101,
+	100,
+	114,
+	101,
+	102,
+	117,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	116,
+	97,
+	107,
+	101,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	97,
+	117,
+	116,
+	111,
+	110,
+	111,
+	109,
+	111,
+	117,
+	115,
+	99,
+	111,
+	109,
+	112,
+	114,
+	111,
+	109,
+	105,
+	115,
+	101,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	114,
+	101,
+	115,
+	116,
+	97,
+	117,
+	114,
+	97,
+	110,
+	116,
+	116,
+	119,
+	111,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	70,
+	101,
+	98,
+	114,
+	117,
+	97,
+	114,
+	121,
+	32,
+	50,
+	113,
+	117,
+	97,
+	108,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	115,
+	119,
+	102,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	46,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	110,
+	101,
+	97,
+	114,
+	108,
+	121,
+	32,
+	97,
+	108,
+	108,
+	119,
+	114,
+	105,
+	116,
+	116,
+	101,
+	110,
+	32,
+	98,
+	121,
+	105,
+	110,
+	116,
+	101,
+	114,
+	118,
+	105,
+	101,
+	119,
+	115,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	119,
+	105,
+	116,
+	104,
+	100,
+	114,
+	97,
+	119,
+	97,
+	108,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	108,
+	101,
+	102,
+	116,
+	105,
+	115,
+	32,
+	117,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	99,
+	97,
+	110,
+	100,
+	105,
+	100,
+	97,
+	116,
+	101,
+	115,
+	110,
+	101,
+	119,
+	115,
+	112,
+	97,
+	112,
+	101,
+	114,
+	115,
+	109,
+	121,
+	115,
+	116,
+	101,
+	114,
+	105,
+	111,
+	117,
+	115,
+	68,
+	101,
+	112,
+	97,
+	114,
+	116,
+	109,
+	101,
+	110,
+	116,
+	98,
+	101,
+	115,
+	116,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	112,
+	97,
+	114,
+	108,
+	105,
+	97,
+	109,
+	101,
+	110,
+	116,
+	115,
+	117,
+	112,
+	112,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	99,
+	111,
+	110,
+	118,
+	101,
+	110,
+	105,
+	101,
+	110,
+	116,
+	114,
+	101,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	101,
+	100,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	97,
+	116,
+	105,
+	99,
+	104,
+	97,
+	115,
+	32,
+	108,
+	101,
+	100,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	112,
+	97,
+	103,
+	97,
+	110,
+	100,
+	97,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	108,
+	101,
+	100,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	115,
+	99,
+	101,
+	114,
+	101,
+	109,
+	111,
+	110,
+	105,
+	97,
+	108,
+	112,
+	114,
+	111,
+	99,
+	108,
+	97,
+	105,
+	109,
+	101,
+	100,
+	80,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	83,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	99,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	110,
+	111,
+	45,
+	116,
+	114,
+	97,
+	100,
+	101,
+	109,
+	97,
+	114,
+	107,
+	115,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	119,
+	105,
+	100,
+	101,
+	115,
+	112,
+	114,
+	101,
+	97,
+	100,
+	76,
+	105,
+	98,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	111,
+	111,
+	107,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	97,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	32,
+	108,
+	111,
+	110,
+	103,
+	32,
+	97,
+	115,
+	105,
+	109,
+	112,
+	114,
+	105,
+	115,
+	111,
+	110,
+	101,
+	100,
+	65,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	109,
+	76,
+	97,
+	98,
+	111,
+	114,
+	97,
+	116,
+	111,
+	114,
+	121,
+	78,
+	111,
+	118,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	50,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	73,
+	110,
+	100,
+	117,
+	115,
+	116,
+	114,
+	105,
+	97,
+	108,
+	118,
+	97,
+	114,
+	105,
+	101,
+	116,
+	121,
+	32,
+	111,
+	102,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	32,
+	108,
+	101,
+	102,
+	68,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	115,
+	101,
+	115,
+	115,
+	109,
+	101,
+	110,
+	116,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	100,
+	101,
+	97,
+	108,
+	115,
+	32,
+	119,
+	105,
+	116,
+	104,
+	83,
+	116,
+	97,
+	116,
+	105,
+	115,
+	116,
+	105,
+	99,
+	115,
+	111,
+	99,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	99,
+	101,
+	47,
+	117,
+	108,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	99,
+	108,
+	101,
+	97,
+	114,
+	102,
+	105,
+	120,
+	34,
+	62,
+	116,
+	104,
+	101,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	109,
+	97,
+	110,
+	121,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	119,
+	101,
+	114,
+	101,
+	111,
+	118,
+	101,
+	114,
+	32,
+	116,
+	105,
+	109,
+	101,
+	44,
+	115,
+	121,
+	110,
+	111,
+	110,
+	121,
+	109,
+	111,
+	117,
+	115,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	62,
+	10,
+	112,
+	114,
+	101,
+	115,
+	117,
+	109,
+	97,
+	98,
+	108,
+	121,
+	104,
+	105,
+	115,
+	32,
+	102,
+	97,
+	109,
+	105,
+	108,
+	121,
+	117,
+	115,
+	101,
+	114,
+	65,
+	103,
+	101,
+	110,
+	116,
+	46,
+	117,
+	110,
+	101,
+	120,
+	112,
+	101,
+	99,
+	116,
+	101,
+	100,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	32,
+	99,
+	104,
+	97,
+	108,
+	108,
+	101,
+	110,
+	103,
+	101,
+	100,
+	97,
+	32,
+	109,
+	105,
+	110,
+	111,
+	114,
+	105,
+	116,
+	121,
+	117,
+	110,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	34,
+	98,
+	101,
+	108,
+	111,
+	110,
+	103,
+	115,
+	32,
+	116,
+	111,
+	116,
+	97,
+	107,
+	101,
+	110,
+	32,
+	102,
+	114,
+	111,
+	109,
+	105,
+	110,
+	32,
+	79,
+	99,
+	116,
+	111,
+	98,
+	101,
+	114,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	32,
+	115,
+	97,
+	105,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	114,
+	101,
+	108,
+	105,
+	103,
+	105,
+	111,
+	117,
+	115,
+	32,
+	70,
+	101,
+	100,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	114,
+	111,
+	119,
+	115,
+	112,
+	97,
+	110,
+	61,
+	34,
+	111,
+	110,
+	108,
+	121,
+	32,
+	97,
+	32,
+	102,
+	101,
+	119,
+	109,
+	101,
+	97,
+	110,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	108,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	45,
+	45,
+	62,
+	13,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	60,
+	102,
+	105,
+	101,
+	108,
+	100,
+	115,
+	101,
+	116,
+	62,
+	65,
+	114,
+	99,
+	104,
+	98,
+	105,
+	115,
+	104,
+	111,
+	112,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	110,
+	111,
+	98,
+	101,
+	105,
+	110,
+	103,
+	32,
+	117,
+	115,
+	101,
+	100,
+	97,
+	112,
+	112,
+	114,
+	111,
+	97,
+	99,
+	104,
+	101,
+	115,
+	112,
+	114,
+	105,
+	118,
+	105,
+	108,
+	101,
+	103,
+	101,
+	115,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	115,
+	32,
+	105,
+	110,
+	109,
+	97,
+	121,
+	32,
+	98,
+	101,
+	32,
+	116,
+	104,
+	101,
+	69,
+	97,
+	115,
+	116,
+	101,
+	114,
+	32,
+	101,
+	103,
+	103,
+	109,
+	101,
+	99,
+	104,
+	97,
+	110,
+	105,
+	115,
+	109,
+	115,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	97,
+	98,
+	108,
+	101,
+	80,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	67,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	34,
+	62,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	112,
+	104,
+	112,
+	97,
+	114,
+	114,
+	105,
+	118,
+	97,
+	108,
+	32,
+	111,
+	102,
+	45,
+	106,
+	115,
+	115,
+	100,
+	107,
+	39,
+	41,
+	41,
+	59,
+	109,
+	97,
+	110,
+	97,
+	103,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	110,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	99,
+	97,
+	115,
+	117,
+	97,
+	108,
+	116,
+	105,
+	101,
+	115,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	105,
+	111,
+	110,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	105,
+	97,
+	110,
+	115,
+	83,
+	101,
+	112,
+	116,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	97,
+	114,
+	105,
+	116,
+	104,
+	109,
+	101,
+	116,
+	105,
+	99,
+	112,
+	114,
+	111,
+	99,
+	101,
+	100,
+	117,
+	114,
+	101,
+	115,
+	109,
+	105,
+	103,
+	104,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	80,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	105,
+	116,
+	32,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	115,
+	80,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	121,
+	102,
+	114,
+	105,
+	101,
+	110,
+	100,
+	115,
+	104,
+	105,
+	112,
+	108,
+	101,
+	97,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	103,
+	105,
+	118,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	119,
+	97,
+	114,
+	100,
+	32,
+	116,
+	104,
+	101,
+	103,
+	117,
+	97,
+	114,
+	97,
+	110,
+	116,
+	101,
+	101,
+	100,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	101,
+	100,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	48,
+	48,
+	48,
+	118,
+	105,
+	100,
+	101,
+	111,
+	32,
+	103,
+	97,
+	109,
+	101,
+	99,
+	111,
+	109,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	114,
+	101,
+	102,
+	108,
+	101,
+	99,
+	116,
+	105,
+	110,
+	103,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	101,
+	100,
+	115,
+	97,
+	110,
+	115,
+	45,
+	115,
+	101,
+	114,
+	105,
+	102,
+	111,
+	110,
+	107,
+	101,
+	121,
+	112,
+	114,
+	101,
+	115,
+	115,
+	59,
+	32,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	72,
+	101,
+	32,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	117,
+	110,
+	100,
+	101,
+	114,
+	108,
+	121,
+	105,
+	110,
+	103,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	32,
+	44,
+	32,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	114,
+	99,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	105,
+	118,
+	101,
+	115,
+	105,
+	110,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	32,
+	110,
+	101,
+	116,
+	119,
+	111,
+	114,
+	107,
+	105,
+	110,
+	103,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	105,
+	110,
+	103,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	108,
+	111,
+	119,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	115,
+	104,
+	111,
+	119,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	10,
+	9,
+	9,
+	99,
+	111,
+	109,
+	112,
+	108,
+	97,
+	105,
+	110,
+	116,
+	115,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	111,
+	117,
+	115,
+	113,
+	117,
+	97,
+	110,
+	116,
+	105,
+	116,
+	105,
+	101,
+	115,
+	97,
+	115,
+	116,
+	114,
+	111,
+	110,
+	111,
+	109,
+	101,
+	114,
+	104,
+	101,
+	32,
+	100,
+	105,
+	100,
+	32,
+	110,
+	111,
+	116,
+	100,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	105,
+	116,
+	115,
+	97,
+	112,
+	112,
+	108,
+	105,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	110,
+	32,
+	97,
+	118,
+	101,
+	114,
+	97,
+	103,
+	101,
+	101,
+	102,
+	102,
+	111,
+	114,
+	116,
+	115,
+	32,
+	116,
+	111,
+	116,
+	104,
+	101,
+	32,
+	102,
+	117,
+	116,
+	117,
+	114,
+	101,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	32,
+	116,
+	111,
+	84,
+	104,
+	101,
+	114,
+	101,
+	102,
+	111,
+	114,
+	101,
+	44,
+	99,
+	97,
+	112,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	110,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	69,
+	108,
+	101,
+	99,
+	116,
+	114,
+	111,
+	110,
+	105,
+	99,
+	107,
+	105,
+	108,
+	111,
+	109,
+	101,
+	116,
+	101,
+	114,
+	115,
+	99,
+	104,
+	97,
+	108,
+	108,
+	101,
+	110,
+	103,
+	101,
+	115,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	105,
+	110,
+	103,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	114,
+	109,
+	101,
+	114,
+	105,
+	110,
+	100,
+	105,
+	103,
+	101,
+	110,
+	111,
+	117,
+	115,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	117,
+	98,
+	115,
+	105,
+	100,
+	105,
+	97,
+	114,
+	121,
+	99,
+	111,
+	110,
+	115,
+	112,
+	105,
+	114,
+	97,
+	99,
+	121,
+	100,
+	101,
+	116,
+	97,
+	105,
+	108,
+	115,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	97,
+	102,
+	102,
+	111,
+	114,
+	100,
+	97,
+	98,
+	108,
+	101,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	115,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	32,
+	102,
+	111,
+	114,
+	99,
+	111,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	105,
+	116,
+	101,
+	109,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	97,
+	98,
+	115,
+	111,
+	108,
+	117,
+	116,
+	101,
+	108,
+	121,
+	115,
+	117,
+	112,
+	112,
+	111,
+	115,
+	101,
+	100,
+	108,
+	121,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	101,
+	100,
+	32,
+	97,
+	97,
+	116,
+	116,
+	114,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	116,
+	114,
+	97,
+	118,
+	101,
+	108,
+	108,
+	105,
+	110,
+	103,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	108,
+	121,
+	102,
+	111,
+	99,
+	117,
+	115,
+	101,
+	115,
+	32,
+	111,
+	110,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	121,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	98,
+	108,
+	101,
+	102,
+	111,
+	117,
+	110,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	109,
+	97,
+	110,
+	117,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	115,
+	116,
+	97,
+	110,
+	100,
+	115,
+	32,
+	102,
+	111,
+	114,
+	32,
+	110,
+	111,
+	45,
+	114,
+	101,
+	112,
+	101,
+	97,
+	116,
+	40,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	67,
+	111,
+	109,
+	109,
+	101,
+	114,
+	99,
+	105,
+	97,
+	108,
+	105,
+	110,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	117,
+	110,
+	100,
+	101,
+	114,
+	116,
+	97,
+	107,
+	101,
+	110,
+	113,
+	117,
+	97,
+	114,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	97,
+	110,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	112,
+	104,
+	112,
+	63,
+	60,
+	47,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	62,
+	10,
+	112,
+	101,
+	114,
+	99,
+	101,
+	110,
+	116,
+	97,
+	103,
+	101,
+	98,
+	101,
+	115,
+	116,
+	45,
+	107,
+	110,
+	111,
+	119,
+	110,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	97,
+	34,
+	32,
+	100,
+	105,
+	114,
+	61,
+	34,
+	108,
+	116,
+	114,
+	76,
+	105,
+	101,
+	117,
+	116,
+	101,
+	110,
+	97,
+	110,
+	116,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	116,
+	104,
+	101,
+	121,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	109,
+	97,
+	100,
+	101,
+	32,
+	117,
+	112,
+	32,
+	111,
+	102,
+	110,
+	111,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	99,
+	108,
+	101,
+	97,
+	114,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	114,
+	103,
+	117,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	116,
+	111,
+	32,
+	97,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	99,
+	104,
+	105,
+	108,
+	100,
+	114,
+	101,
+	110,
+	39,
+	115,
+	112,
+	117,
+	114,
+	112,
+	111,
+	115,
+	101,
+	32,
+	111,
+	102,
+	102,
+	111,
+	114,
+	109,
+	117,
+	108,
+	97,
+	116,
+	101,
+	100,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	117,
+	112,
+	111,
+	110,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	32,
+	111,
+	102,
+	112,
+	97,
+	115,
+	115,
+	101,
+	110,
+	103,
+	101,
+	114,
+	115,
+	112,
+	111,
+	115,
+	115,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	46,
+	10,
+	10,
+	73,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	66,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	97,
+	102,
+	116,
+	101,
+	114,
+	119,
+	97,
+	114,
+	100,
+	115,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	32,
+	97,
+	99,
+	114,
+	111,
+	115,
+	115,
+	32,
+	116,
+	104,
+	101,
+	115,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	99,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	116,
+	121,
+	46,
+	99,
+	97,
+	112,
+	105,
+	116,
+	97,
+	108,
+	105,
+	115,
+	109,
+	105,
+	110,
+	32,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	45,
+	119,
+	105,
+	110,
+	103,
+	116,
+	104,
+	101,
+	32,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	83,
+	111,
+	99,
+	105,
+	101,
+	116,
+	121,
+	32,
+	111,
+	102,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	105,
+	97,
+	110,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	58,
+	119,
+	101,
+	110,
+	116,
+	32,
+	111,
+	110,
+	32,
+	116,
+	111,
+	114,
+	101,
+	109,
+	111,
+	118,
+	97,
+	108,
+	32,
+	111,
+	102,
+	32,
+	78,
+	101,
+	119,
+	32,
+	89,
+	111,
+	114,
+	107,
+	32,
+	97,
+	112,
+	97,
+	114,
+	116,
+	109,
+	101,
+	110,
+	116,
+	115,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	117,
+	110,
+	108,
+	101,
+	115,
+	115,
+	32,
+	116,
+	104,
+	101,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	99,
+	97,
+	108,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	97,
+	100,
+	101,
+	102,
+	105,
+	110,
+	105,
+	116,
+	105,
+	118,
+	101,
+	105,
+	110,
+	103,
+	114,
+	101,
+	100,
+	105,
+	101,
+	110,
+	116,
+	97,
+	116,
+	116,
+	101,
+	110,
+	100,
+	97,
+	110,
+	99,
+	101,
+	67,
+	101,
+	110,
+	116,
+	101,
+	114,
+	32,
+	102,
+	111,
+	114,
+	112,
+	114,
+	111,
+	109,
+	105,
+	110,
+	101,
+	110,
+	99,
+	101,
+	114,
+	101,
+	97,
+	100,
+	121,
+	83,
+	116,
+	97,
+	116,
+	101,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	103,
+	105,
+	101,
+	115,
+	98,
+	117,
+	116,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	99,
+	108,
+	97,
+	105,
+	109,
+	32,
+	116,
+	104,
+	97,
+	116,
+	108,
+	97,
+	98,
+	111,
+	114,
+	97,
+	116,
+	111,
+	114,
+	121,
+	99,
+	111,
+	109,
+	112,
+	97,
+	116,
+	105,
+	98,
+	108,
+	101,
+	102,
+	97,
+	105,
+	108,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	44,
+	32,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	32,
+	98,
+	101,
+	103,
+	97,
+	110,
+	32,
+	119,
+	105,
+	116,
+	104,
+	117,
+	115,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	111,
+	32,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	102,
+	114,
+	111,
+	109,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	47,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	103,
+	101,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	111,
+	102,
+	100,
+	101,
+	108,
+	105,
+	98,
+	101,
+	114,
+	97,
+	116,
+	101,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	32,
+	104,
+	111,
+	108,
+	100,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	110,
+	103,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	118,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	116,
+	111,
+	112,
+	116,
+	104,
+	101,
+	32,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	111,
+	117,
+	116,
+	115,
+	105,
+	100,
+	101,
+	32,
+	111,
+	102,
+	110,
+	101,
+	103,
+	111,
+	116,
+	105,
+	97,
+	116,
+	101,
+	100,
+	104,
+	105,
+	115,
+	32,
+	99,
+	97,
+	114,
+	101,
+	101,
+	114,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	100,
+	61,
+	34,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	119,
+	97,
+	115,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	117,
+	114,
+	116,
+	104,
+	114,
+	101,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	119,
+	104,
+	105,
+	108,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	44,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	105,
+	110,
+	103,
+	97,
+	99,
+	99,
+	117,
+	114,
+	97,
+	116,
+	101,
+	108,
+	121,
+	119,
+	101,
+	114,
+	101,
+	32,
+	98,
+	117,
+	105,
+	108,
+	116,
+	119,
+	97,
+	115,
+	32,
+	107,
+	105,
+	108,
+	108,
+	101,
+	100,
+	97,
+	103,
+	114,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	109,
+	117,
+	99,
+	104,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	68,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	32,
+	49,
+	48,
+	48,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	75,
+	105,
+	110,
+	103,
+	100,
+	111,
+	109,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	116,
+	105,
+	114,
+	101,
+	102,
+	97,
+	109,
+	111,
+	117,
+	115,
+	32,
+	102,
+	111,
+	114,
+	116,
+	111,
+	32,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	111,
+	98,
+	106,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	115,
+	116,
+	104,
+	101,
+	32,
+	70,
+	114,
+	101,
+	110,
+	99,
+	104,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	97,
+	110,
+	100,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	100,
+	34,
+	62,
+	105,
+	115,
+	32,
+	115,
+	97,
+	105,
+	100,
+	32,
+	116,
+	111,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	97,
+	108,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	100,
+	117,
+	109,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	97,
+	32,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	45,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	32,
+	79,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	119,
+	105,
+	100,
+	101,
+	46,
+	97,
+	114,
+	105,
+	97,
+	45,
+	108,
+	97,
+	98,
+	101,
+	108,
+	116,
+	104,
+	101,
+	32,
+	112,
+	108,
+	97,
+	110,
+	101,
+	116,
+	97,
+	110,
+	100,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	100,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	108,
+	111,
+	111,
+	107,
+	105,
+	110,
+	103,
+	32,
+	97,
+	116,
+	98,
+	101,
+	110,
+	101,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	97,
+	114,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	109,
+	111,
+	110,
+	105,
+	116,
+	111,
+	114,
+	105,
+	110,
+	103,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	108,
+	121,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	100,
+	101,
+	114,
+	110,
+	119,
+	111,
+	114,
+	107,
+	105,
+	110,
+	103,
+	32,
+	111,
+	110,
+	97,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	32,
+	116,
+	111,
+	119,
+	104,
+	101,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	110,
+	111,
+	118,
+	97,
+	116,
+	105,
+	118,
+	101,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	115,
+	111,
+	117,
+	110,
+	100,
+	116,
+	114,
+	97,
+	99,
+	107,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	70,
+	111,
+	114,
+	109,
+	116,
+	101,
+	110,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	105,
+	100,
+	61,
+	34,
+	111,
+	112,
+	101,
+	110,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	114,
+	101,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	101,
+	100,
+	97,
+	100,
+	111,
+	112,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	100,
+	100,
+	114,
+	101,
+	115,
+	115,
+	105,
+	110,
+	103,
+	116,
+	104,
+	101,
+	111,
+	108,
+	111,
+	103,
+	105,
+	97,
+	110,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	115,
+	32,
+	111,
+	102,
+	118,
+	97,
+	114,
+	105,
+	97,
+	110,
+	116,
+	32,
+	111,
+	102,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	105,
+	97,
+	110,
+	32,
+	118,
+	101,
+	114,
+	121,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	97,
+	117,
+	116,
+	111,
+	109,
+	111,
+	116,
+	105,
+	118,
+	101,
+	98,
+	121,
+	32,
+	102,
+	97,
+	114,
+	32,
+	116,
+	104,
+	101,
+	114,
+	97,
+	110,
+	103,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	112,
+	117,
+	114,
+	115,
+	117,
+	105,
+	116,
+	32,
+	111,
+	102,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	32,
+	116,
+	104,
+	101,
+	98,
+	114,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	111,
+	105,
+	110,
+	32,
+	69,
+	110,
+	103,
+	108,
+	97,
+	110,
+	100,
+	97,
+	103,
+	114,
+	101,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	99,
+	99,
+	117,
+	115,
+	101,
+	100,
+	32,
+	111,
+	102,
+	99,
+	111,
+	109,
+	101,
+	115,
+	32,
+	102,
+	114,
+	111,
+	109,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	105,
+	110,
+	103,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	104,
+	105,
+	115,
+	32,
+	111,
+	114,
+	32,
+	104,
+	101,
+	114,
+	116,
+	114,
+	101,
+	109,
+	101,
+	110,
+	100,
+	111,
+	117,
+	115,
+	102,
+	114,
+	101,
+	101,
+	100,
+	111,
+	109,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	105,
+	110,
+	103,
+	48,
+	32,
+	49,
+	101,
+	109,
+	32,
+	49,
+	101,
+	109,
+	59,
+	66,
+	97,
+	115,
+	107,
+	101,
+	116,
+	98,
+	97,
+	108,
+	108,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	46,
+	99,
+	115,
+	115,
+	97,
+	110,
+	32,
+	101,
+	97,
+	114,
+	108,
+	105,
+	101,
+	114,
+	101,
+	118,
+	101,
+	110,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	47,
+	34,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	46,
+	99,
+	111,
+	109,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	116,
+	97,
+	107,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	112,
+	105,
+	116,
+	116,
+	115,
+	98,
+	117,
+	114,
+	103,
+	104,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	62,
+	13,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	40,
+	102,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	32,
+	111,
+	117,
+	116,
+	104,
+	97,
+	118,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	13,
+	10,
+	32,
+	111,
+	99,
+	99,
+	97,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	105,
+	116,
+	115,
+	116,
+	97,
+	114,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	112,
+	104,
+	121,
+	115,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	32,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	67,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	44,
+	32,
+	98,
+	103,
+	99,
+	111,
+	108,
+	111,
+	114,
+	61,
+	34,
+	116,
+	97,
+	98,
+	105,
+	110,
+	100,
+	101,
+	120,
+	61,
+	34,
+	100,
+	105,
+	115,
+	97,
+	115,
+	116,
+	114,
+	111,
+	117,
+	115,
+	65,
+	110,
+	97,
+	108,
+	121,
+	116,
+	105,
+	99,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	104,
+	97,
+	115,
+	32,
+	97,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	60,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	62,
+	10,
+	60,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	115,
+	105,
+	110,
+	103,
+	101,
+	114,
+	32,
+	97,
+	110,
+	100,
+	46,
+	115,
+	114,
+	99,
+	32,
+	61,
+	32,
+	34,
+	47,
+	47,
+	118,
+	105,
+	111,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	104,
+	105,
+	115,
+	32,
+	112,
+	111,
+	105,
+	110,
+	116,
+	99,
+	111,
+	110,
+	115,
+	116,
+	97,
+	110,
+	116,
+	108,
+	121,
+	105,
+	115,
+	32,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	115,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	110,
+	101,
+	100,
+	101,
+	114,
+	108,
+	97,
+	110,
+	100,
+	115,
+	112,
+	111,
+	114,
+	116,
+	117,
+	103,
+	117,
+	195,
+	170,
+	115,
+	215,
+	162,
+	215,
+	145,
+	215,
+	168,
+	215,
+	153,
+	215,
+	170,
+	217,
+	129,
+	216,
+	167,
+	216,
+	177,
+	216,
+	179,
+	219,
+	140,
+	100,
+	101,
+	115,
+	97,
+	114,
+	114,
+	111,
+	108,
+	108,
+	111,
+	99,
+	111,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	105,
+	111,
+	101,
+	100,
+	117,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	115,
+	101,
+	112,
+	116,
+	105,
+	101,
+	109,
+	98,
+	114,
+	101,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	97,
+	100,
+	111,
+	100,
+	105,
+	114,
+	101,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	117,
+	98,
+	105,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	105,
+	100,
+	97,
+	100,
+	114,
+	101,
+	115,
+	112,
+	117,
+	101,
+	115,
+	116,
+	97,
+	115,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	97,
+	100,
+	111,
+	115,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	101,
+	114,
+	118,
+	97,
+	100,
+	111,
+	115,
+	97,
+	114,
+	116,
+	195,
+	173,
+	99,
+	117,
+	108,
+	111,
+	115,
+	100,
+	105,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	101,
+	115,
+	115,
+	105,
+	103,
+	117,
+	105,
+	101,
+	110,
+	116,
+	101,
+	115,
+	114,
+	101,
+	112,
+	195,
+	186,
+	98,
+	108,
+	105,
+	99,
+	97,
+	115,
+	105,
+	116,
+	117,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	105,
+	111,
+	112,
+	114,
+	105,
+	118,
+	97,
+	99,
+	105,
+	100,
+	97,
+	100,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	105,
+	111,
+	102,
+	111,
+	114,
+	109,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	111,
+	98,
+	108,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	101,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	105,
+	100,
+	111,
+	115,
+	97,
+	99,
+	99,
+	101,
+	115,
+	111,
+	114,
+	105,
+	111,
+	115,
+	116,
+	101,
+	99,
+	104,
+	110,
+	111,
+	114,
+	97,
+	116,
+	105,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	101,
+	115,
+	99,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	195,
+	173,
+	97,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	101,
+	115,
+	100,
+	105,
+	115,
+	112,
+	111,
+	110,
+	105,
+	98,
+	108,
+	101,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	105,
+	100,
+	97,
+	100,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	105,
+	97,
+	118,
+	97,
+	108,
+	108,
+	97,
+	100,
+	111,
+	108,
+	105,
+	100,
+	98,
+	105,
+	98,
+	108,
+	105,
+	111,
+	116,
+	101,
+	99,
+	97,
+	114,
+	101,
+	108,
+	97,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	99,
+	97,
+	108,
+	101,
+	110,
+	100,
+	97,
+	114,
+	105,
+	111,
+	112,
+	111,
+	108,
+	195,
+	173,
+	116,
+	105,
+	99,
+	97,
+	115,
+	97,
+	110,
+	116,
+	101,
+	114,
+	105,
+	111,
+	114,
+	101,
+	115,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	111,
+	115,
+	110,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	101,
+	122,
+	97,
+	109,
+	97,
+	116,
+	101,
+	114,
+	105,
+	97,
+	108,
+	101,
+	115,
+	100,
+	105,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	105,
+	97,
+	101,
+	99,
+	111,
+	110,
+	195,
+	179,
+	109,
+	105,
+	99,
+	97,
+	116,
+	114,
+	97,
+	110,
+	115,
+	112,
+	111,
+	114,
+	116,
+	101,
+	114,
+	111,
+	100,
+	114,
+	195,
+	173,
+	103,
+	117,
+	101,
+	122,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	114,
+	101,
+	110,
+	99,
+	117,
+	101,
+	110,
+	116,
+	114,
+	97,
+	110,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	105,
+	195,
+	179,
+	110,
+	101,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	97,
+	102,
+	117,
+	110,
+	100,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	102,
+	114,
+	101,
+	99,
+	117,
+	101,
+	110,
+	116,
+	101,
+	115,
+	112,
+	101,
+	114,
+	109,
+	97,
+	110,
+	101,
+	110,
+	116,
+	101,
+	116,
+	111,
+	116,
+	97,
+	108,
+	109,
+	101,
+	110,
+	116,
+	101,
+	208,
+	188,
+	208,
+	190,
+	208,
+	182,
+	208,
+	189,
+	208,
+	190,
+	208,
+	177,
+	209,
+	131,
+	208,
+	180,
+	208,
+	181,
+	209,
+	130,
+	208,
+	188,
+	208,
+	190,
+	208,
+	182,
+	208,
+	181,
+	209,
+	130,
+	208,
+	178,
+	209,
+	128,
+	208,
+	181,
+	208,
+	188,
+	209,
+	143,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	182,
+	208,
+	181,
+	209,
+	135,
+	209,
+	130,
+	208,
+	190,
+	208,
+	177,
+	209,
+	139,
+	208,
+	177,
+	208,
+	190,
+	208,
+	187,
+	208,
+	181,
+	208,
+	181,
+	208,
+	190,
+	209,
+	135,
+	208,
+	181,
+	208,
+	189,
+	209,
+	140,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	186,
+	208,
+	190,
+	208,
+	179,
+	208,
+	180,
+	208,
+	176,
+	208,
+	191,
+	208,
+	190,
+	209,
+	129,
+	208,
+	187,
+	208,
+	181,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	129,
+	208,
+	176,
+	208,
+	185,
+	209,
+	130,
+	208,
+	181,
+	209,
+	135,
+	208,
+	181,
+	209,
+	128,
+	208,
+	181,
+	208,
+	183,
+	208,
+	188,
+	208,
+	190,
+	208,
+	179,
+	209,
+	131,
+	209,
+	130,
+	209,
+	129,
+	208,
+	176,
+	208,
+	185,
+	209,
+	130,
+	208,
+	176,
+	208,
+	182,
+	208,
+	184,
+	208,
+	183,
+	208,
+	189,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	208,
+	182,
+	208,
+	180,
+	209,
+	131,
+	208,
+	177,
+	209,
+	131,
+	208,
+	180,
+	209,
+	131,
+	209,
+	130,
+	208,
+	159,
+	208,
+	190,
+	208,
+	184,
+	209,
+	129,
+	208,
+	186,
+	208,
+	183,
+	208,
+	180,
+	208,
+	181,
+	209,
+	129,
+	209,
+	140,
+	208,
+	178,
+	208,
+	184,
+	208,
+	180,
+	208,
+	181,
+	208,
+	190,
+	209,
+	129,
+	208,
+	178,
+	209,
+	143,
+	208,
+	183,
+	208,
+	184,
+	208,
+	189,
+	209,
+	131,
+	208,
+	182,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	181,
+	208,
+	185,
+	208,
+	187,
+	209,
+	142,
+	208,
+	180,
+	208,
+	181,
+	208,
+	185,
+	208,
+	191,
+	208,
+	190,
+	209,
+	128,
+	208,
+	189,
+	208,
+	190,
+	208,
+	188,
+	208,
+	189,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	208,
+	181,
+	209,
+	130,
+	208,
+	181,
+	208,
+	185,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	184,
+	209,
+	133,
+	208,
+	191,
+	209,
+	128,
+	208,
+	176,
+	208,
+	178,
+	208,
+	176,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	190,
+	208,
+	185,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	208,
+	181,
+	209,
+	130,
+	208,
+	182,
+	208,
+	184,
+	208,
+	183,
+	208,
+	189,
+	209,
+	140,
+	208,
+	190,
+	208,
+	180,
+	208,
+	189,
+	208,
+	190,
+	208,
+	185,
+	208,
+	187,
+	209,
+	131,
+	209,
+	135,
+	209,
+	136,
+	208,
+	181,
+	208,
+	191,
+	208,
+	181,
+	209,
+	128,
+	208,
+	181,
+	208,
+	180,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	209,
+	128,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	209,
+	139,
+	209,
+	133,
+	208,
+	191,
+	209,
+	128,
+	208,
+	176,
+	208,
+	178,
+	208,
+	190,
+	209,
+	129,
+	208,
+	190,
+	208,
+	177,
+	208,
+	190,
+	208,
+	185,
+	208,
+	191,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	208,
+	181,
+	208,
+	181,
+	209,
+	135,
+	208,
+	184,
+	209,
+	129,
+	208,
+	187,
+	208,
+	181,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	209,
+	139,
+	208,
+	181,
+	209,
+	131,
+	209,
+	129,
+	208,
+	187,
+	209,
+	131,
+	208,
+	179,
+	208,
+	190,
+	208,
+	186,
+	208,
+	190,
+	208,
+	187,
+	208,
+	190,
+	208,
+	189,
+	208,
+	176,
+	208,
+	183,
+	208,
+	176,
+	208,
+	180,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	190,
+	208,
+	181,
+	209,
+	130,
+	208,
+	190,
+	208,
+	179,
+	208,
+	180,
+	208,
+	176,
+	208,
+	191,
+	208,
+	190,
+	209,
+	135,
+	209,
+	130,
+	208,
+	184,
+	208,
+	159,
+	208,
+	190,
+	209,
+	129,
+	208,
+	187,
+	208,
+	181,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	184,
+	208,
+	181,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	209,
+	139,
+	208,
+	185,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	208,
+	184,
+	209,
+	130,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	184,
+	209,
+	133,
+	209,
+	129,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	209,
+	131,
+	208,
+	161,
+	208,
+	176,
+	208,
+	189,
+	208,
+	186,
+	209,
+	130,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	209,
+	131,
+	208,
+	188,
+	208,
+	154,
+	208,
+	190,
+	208,
+	179,
+	208,
+	180,
+	208,
+	176,
+	208,
+	186,
+	208,
+	189,
+	208,
+	184,
+	208,
+	179,
+	208,
+	184,
+	209,
+	129,
+	208,
+	187,
+	208,
+	190,
+	208,
+	178,
+	208,
+	176,
+	208,
+	189,
+	208,
+	176,
+	209,
+	136,
+	208,
+	181,
+	208,
+	185,
+	208,
+	189,
+	208,
+	176,
+	208,
+	185,
+	209,
+	130,
+	208,
+	184,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	184,
+	208,
+	188,
+	209,
+	129,
+	208,
+	178,
+	209,
+	143,
+	208,
+	183,
+	209,
+	140,
+	208,
+	187,
+	209,
+	142,
+	208,
+	177,
+	208,
+	190,
+	208,
+	185,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	209,
+	129,
+	209,
+	128,
+	208,
+	181,
+	208,
+	180,
+	208,
+	184,
+	208,
+	154,
+	209,
+	128,
+	208,
+	190,
+	208,
+	188,
+	208,
+	181,
+	208,
+	164,
+	208,
+	190,
+	209,
+	128,
+	209,
+	131,
+	208,
+	188,
+	209,
+	128,
+	209,
+	139,
+	208,
+	189,
+	208,
+	186,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	176,
+	208,
+	187,
+	208,
+	184,
+	208,
+	191,
+	208,
+	190,
+	208,
+	184,
+	209,
+	129,
+	208,
+	186,
+	209,
+	130,
+	209,
+	139,
+	209,
+	129,
+	209,
+	143,
+	209,
+	135,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	143,
+	209,
+	134,
+	209,
+	134,
+	208,
+	181,
+	208,
+	189,
+	209,
+	130,
+	209,
+	128,
+	209,
+	130,
+	209,
+	128,
+	209,
+	131,
+	208,
+	180,
+	208,
+	176,
+	209,
+	129,
+	208,
+	176,
+	208,
+	188,
+	209,
+	139,
+	209,
+	133,
+	209,
+	128,
+	209,
+	139,
+	208,
+	189,
+	208,
+	186,
+	208,
+	176,
+	208,
+	157,
+	208,
+	190,
+	208,
+	178,
+	209,
+	139,
+	208,
+	185,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	208,
+	190,
+	208,
+	178,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	176,
+	209,
+	132,
+	208,
+	184,
+	208,
+	187,
+	209,
+	140,
+	208,
+	188,
+	208,
+	188,
+	208,
+	176,
+	209,
+	128,
+	209,
+	130,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	208,
+	189,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	181,
+	209,
+	130,
+	208,
+	181,
+	208,
+	186,
+	209,
+	129,
+	209,
+	130,
+	208,
+	189,
+	208,
+	176,
+	209,
+	136,
+	208,
+	184,
+	209,
+	133,
+	208,
+	188,
+	208,
+	184,
+	208,
+	189,
+	209,
+	131,
+	209,
+	130,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	209,
+	142,
+	209,
+	130,
+	208,
+	189,
+	208,
+	190,
+	208,
+	188,
+	208,
+	181,
+	209,
+	128,
+	208,
+	179,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	180,
+	209,
+	129,
+	208,
+	176,
+	208,
+	188,
+	208,
+	190,
+	208,
+	188,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	209,
+	131,
+	208,
+	186,
+	208,
+	190,
+	208,
+	189,
+	209,
+	134,
+	208,
+	181,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	181,
+	208,
+	188,
+	208,
+	186,
+	208,
+	176,
+	208,
+	186,
+	208,
+	190,
+	208,
+	185,
+	208,
+	144,
+	209,
+	128,
+	209,
+	133,
+	208,
+	184,
+	208,
+	178,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	175,
+	217,
+	137,
+	216,
+	165,
+	216,
+	177,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	217,
+	133,
+	217,
+	131,
+	216,
+	170,
+	216,
+	168,
+	217,
+	135,
+	216,
+	167,
+	216,
+	168,
+	216,
+	177,
+	216,
+	167,
+	217,
+	133,
+	216,
+	172,
+	216,
+	167,
+	217,
+	132,
+	217,
+	138,
+	217,
+	136,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	217,
+	136,
+	216,
+	177,
+	216,
+	172,
+	216,
+	175,
+	217,
+	138,
+	216,
+	175,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	182,
+	217,
+	136,
+	216,
+	165,
+	216,
+	182,
+	216,
+	167,
+	217,
+	129,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	130,
+	216,
+	179,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	216,
+	168,
+	216,
+	170,
+	216,
+	173,
+	217,
+	133,
+	217,
+	138,
+	217,
+	132,
+	217,
+	133,
+	217,
+	132,
+	217,
+	129,
+	216,
+	167,
+	216,
+	170,
+	217,
+	133,
+	217,
+	132,
+	216,
+	170,
+	217,
+	130,
+	217,
+	137,
+	216,
+	170,
+	216,
+	185,
+	216,
+	175,
+	217,
+	138,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	180,
+	216,
+	185,
+	216,
+	177,
+	216,
+	163,
+	216,
+	174,
+	216,
+	168,
+	216,
+	167,
+	216,
+	177,
+	216,
+	170,
+	216,
+	183,
+	217,
+	136,
+	217,
+	138,
+	216,
+	177,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	217,
+	131,
+	217,
+	133,
+	216,
+	165,
+	216,
+	177,
+	217,
+	129,
+	216,
+	167,
+	217,
+	130,
+	216,
+	183,
+	217,
+	132,
+	216,
+	168,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	132,
+	216,
+	186,
+	216,
+	169,
+	216,
+	170,
+	216,
+	177,
+	216,
+	170,
+	217,
+	138,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	217,
+	134,
+	216,
+	167,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	180,
+	217,
+	138,
+	216,
+	174,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	175,
+	217,
+	138,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	177,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	217,
+	130,
+	216,
+	181,
+	216,
+	181,
+	216,
+	167,
+	217,
+	129,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	217,
+	135,
+	216,
+	167,
+	216,
+	170,
+	216,
+	173,
+	216,
+	175,
+	217,
+	138,
+	216,
+	171,
+	216,
+	167,
+	217,
+	132,
+	217,
+	132,
+	217,
+	135,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	217,
+	133,
+	217,
+	132,
+	217,
+	133,
+	217,
+	131,
+	216,
+	170,
+	216,
+	168,
+	216,
+	169,
+	217,
+	138,
+	217,
+	133,
+	217,
+	131,
+	217,
+	134,
+	217,
+	131,
+	216,
+	167,
+	217,
+	132,
+	216,
+	183,
+	217,
+	129,
+	217,
+	132,
+	217,
+	129,
+	217,
+	138,
+	216,
+	175,
+	217,
+	138,
+	217,
+	136,
+	216,
+	165,
+	216,
+	175,
+	216,
+	167,
+	216,
+	177,
+	216,
+	169,
+	216,
+	170,
+	216,
+	167,
+	216,
+	177,
+	217,
+	138,
+	216,
+	174,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	216,
+	173,
+	216,
+	169,
+	216,
+	170,
+	216,
+	179,
+	216,
+	172,
+	217,
+	138,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	217,
+	136,
+	217,
+	130,
+	216,
+	170,
+	216,
+	185,
+	217,
+	134,
+	216,
+	175,
+	217,
+	133,
+	216,
+	167,
+	217,
+	133,
+	216,
+	175,
+	217,
+	138,
+	217,
+	134,
+	216,
+	169,
+	216,
+	170,
+	216,
+	181,
+	217,
+	133,
+	217,
+	138,
+	217,
+	133,
+	216,
+	163,
+	216,
+	177,
+	216,
+	180,
+	217,
+	138,
+	217,
+	129,
+	216,
+	167,
+	217,
+	132,
+	216,
+	176,
+	217,
+	138,
+	217,
+	134,
+	216,
+	185,
+	216,
+	177,
+	216,
+	168,
+	217,
+	138,
+	216,
+	169,
+	216,
+	168,
+	217,
+	136,
+	216,
+	167,
+	216,
+	168,
+	216,
+	169,
+	216,
+	163,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	217,
+	129,
+	216,
+	177,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	217,
+	131,
+	217,
+	132,
+	216,
+	170,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	217,
+	137,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	217,
+	136,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	217,
+	134,
+	216,
+	169,
+	216,
+	172,
+	216,
+	167,
+	217,
+	133,
+	216,
+	185,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	216,
+	173,
+	217,
+	129,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	138,
+	217,
+	134,
+	217,
+	131,
+	217,
+	132,
+	217,
+	133,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	174,
+	216,
+	167,
+	216,
+	181,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	132,
+	217,
+	129,
+	216,
+	163,
+	216,
+	185,
+	216,
+	182,
+	216,
+	167,
+	216,
+	161,
+	217,
+	131,
+	216,
+	170,
+	216,
+	167,
+	216,
+	168,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	174,
+	217,
+	138,
+	216,
+	177,
+	216,
+	177,
+	216,
+	179,
+	216,
+	167,
+	216,
+	166,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	217,
+	130,
+	217,
+	132,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	175,
+	216,
+	168,
+	217,
+	133,
+	217,
+	130,
+	216,
+	167,
+	216,
+	183,
+	216,
+	185,
+	217,
+	133,
+	216,
+	177,
+	216,
+	167,
+	216,
+	179,
+	217,
+	132,
+	217,
+	133,
+	217,
+	134,
+	216,
+	183,
+	217,
+	130,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	216,
+	170,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	172,
+	217,
+	132,
+	216,
+	167,
+	216,
+	180,
+	216,
+	170,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	217,
+	132,
+	217,
+	130,
+	216,
+	175,
+	217,
+	133,
+	217,
+	138,
+	216,
+	185,
+	216,
+	183,
+	217,
+	138,
+	217,
+	131,
+	115,
+	66,
+	121,
+	84,
+	97,
+	103,
+	78,
+	97,
+	109,
+	101,
+	40,
+	46,
+	106,
+	112,
+	103,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	49,
+	112,
+	120,
+	32,
+	115,
+	111,
+	108,
+	105,
+	100,
+	32,
+	35,
+	46,
+	103,
+	105,
+	102,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	116,
+	114,
+	97,
+	110,
+	115,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	34,
+	32,
+	111,
+	110,
+	99,
+	108,
+	105,
+	99,
+	107,
+	61,
+	34,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	97,
+	100,
+	118,
+	101,
+	114,
+	116,
+	105,
+	115,
+	105,
+	110,
+	103,
+	46,
+	112,
+	110,
+	103,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	101,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	97,
+	110,
+	99,
+	101,
+	97,
+	112,
+	112,
+	114,
+	111,
+	112,
+	114,
+	105,
+	97,
+	116,
+	101,
+	38,
+	97,
+	109,
+	112,
+	59,
+	109,
+	100,
+	97,
+	115,
+	104,
+	59,
+	105,
+	109,
+	109,
+	101,
+	100,
+	105,
+	97,
+	116,
+	101,
+	108,
+	121,
+	60,
+	47,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	62,
+	60,
+	47,
+	114,
+	97,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	116,
+	101,
+	109,
+	112,
+	101,
+	114,
+	97,
+	116,
+	117,
+	114,
+	101,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	105,
+	116,
+	105,
+	111,
+	110,
+	112,
+	108,
+	97,
+	99,
+	101,
+	104,
+	111,
+	108,
+	100,
+	101,
+	114,
+	118,
+	105,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	58,
+	99,
+	111,
+	112,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	48,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	101,
+	118,
+	101,
+	110,
+	32,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	109,
+	101,
+	110,
+	116,
+	100,
+	101,
+	115,
+	116,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	67,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	60,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	65,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	100,
+	105,
+	118,
+	105,
+	100,
+	117,
+	97,
+	108,
+	115,
+	112,
+	101,
+	114,
+	115,
+	112,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	115,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	111,
+	117,
+	116,
+	40,
+	117,
+	114,
+	108,
+	40,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	109,
+	97,
+	116,
+	104,
+	101,
+	109,
+	97,
+	116,
+	105,
+	99,
+	115,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	116,
+	111,
+	112,
+	58,
+	101,
+	118,
+	101,
+	110,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	32,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	41,
+	32,
+	110,
+	111,
+	45,
+	114,
+	101,
+	112,
+	101,
+	97,
+	116,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	46,
+	74,
+	80,
+	71,
+	124,
+	116,
+	104,
+	117,
+	109,
+	98,
+	124,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	101,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	60,
+	98,
+	111,
+	100,
+	121,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	108,
+	101,
+	102,
+	116,
+	59,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	104,
+	117,
+	110,
+	100,
+	114,
+	101,
+	100,
+	115,
+	32,
+	111,
+	102,
+	10,
+	10,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	99,
+	111,
+	109,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	99,
+	108,
+	101,
+	97,
+	114,
+	58,
+	98,
+	111,
+	116,
+	104,
+	59,
+	99,
+	111,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	98,
+	101,
+	108,
+	32,
+	102,
+	111,
+	114,
+	61,
+	34,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	45,
+	116,
+	111,
+	112,
+	58,
+	78,
+	101,
+	119,
+	32,
+	90,
+	101,
+	97,
+	108,
+	97,
+	110,
+	100,
+	114,
+	101,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	100,
+	101,
+	100,
+	112,
+	104,
+	111,
+	116,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	121,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	105,
+	110,
+	103,
+	38,
+	108,
+	116,
+	59,
+	115,
+	117,
+	112,
+	38,
+	103,
+	116,
+	59,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	118,
+	101,
+	114,
+	115,
+	121,
+	78,
+	101,
+	116,
+	104,
+	101,
+	114,
+	108,
+	97,
+	110,
+	100,
+	115,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	109,
+	97,
+	120,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	61,
+	34,
+	115,
+	119,
+	105,
+	116,
+	122,
+	101,
+	114,
+	108,
+	97,
+	110,
+	100,
+	68,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	101,
+	115,
+	115,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	10,
+	10,
+	65,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	60,
+	47,
+	116,
+	101,
+	120,
+	116,
+	97,
+	114,
+	101,
+	97,
+	62,
+	116,
+	104,
+	117,
+	110,
+	100,
+	101,
+	114,
+	98,
+	105,
+	114,
+	100,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	100,
+	38,
+	97,
+	109,
+	112,
+	59,
+	110,
+	100,
+	97,
+	115,
+	104,
+	59,
+	115,
+	112,
+	101,
+	99,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	116,
+	105,
+	101,
+	115,
+	108,
+	101,
+	103,
+	105,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	111,
+	110,
+	105,
+	99,
+	115,
+	10,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	105,
+	108,
+	108,
+	117,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	100,
+	101,
+	110,
+	103,
+	105,
+	110,
+	101,
+	101,
+	114,
+	105,
+	110,
+	103,
+	116,
+	101,
+	114,
+	114,
+	105,
+	116,
+	111,
+	114,
+	105,
+	101,
+	115,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	105,
+	116,
+	105,
+	101,
+	115,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	100,
+	54,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	115,
+	97,
+	110,
+	115,
+	45,
+	115,
+	101,
+	114,
+	105,
+	102,
+	59,
+	99,
+	97,
+	112,
+	97,
+	98,
+	108,
+	101,
+	32,
+	111,
+	102,
+	32,
+	100,
+	105,
+	115,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	101,
+	100,
+	105,
+	110,
+	116,
+	101,
+	114,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	111,
+	111,
+	107,
+	105,
+	110,
+	103,
+	32,
+	102,
+	111,
+	114,
+	105,
+	116,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	65,
+	102,
+	103,
+	104,
+	97,
+	110,
+	105,
+	115,
+	116,
+	97,
+	110,
+	119,
+	97,
+	115,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	77,
+	97,
+	116,
+	104,
+	46,
+	102,
+	108,
+	111,
+	111,
+	114,
+	40,
+	115,
+	117,
+	114,
+	114,
+	111,
+	117,
+	110,
+	100,
+	105,
+	110,
+	103,
+	99,
+	97,
+	110,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	98,
+	101,
+	111,
+	98,
+	115,
+	101,
+	114,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	97,
+	105,
+	110,
+	116,
+	101,
+	110,
+	97,
+	110,
+	99,
+	101,
+	101,
+	110,
+	99,
+	111,
+	117,
+	110,
+	116,
+	101,
+	114,
+	101,
+	100,
+	60,
+	104,
+	50,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	111,
+	114,
+	101,
+	32,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	105,
+	116,
+	32,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	105,
+	110,
+	118,
+	97,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	41,
+	46,
+	103,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	40,
+	41,
+	102,
+	117,
+	110,
+	100,
+	97,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	68,
+	101,
+	115,
+	112,
+	105,
+	116,
+	101,
+	32,
+	116,
+	104,
+	101,
+	34,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	105,
+	110,
+	115,
+	112,
+	105,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	120,
+	97,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	114,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	120,
+	112,
+	108,
+	97,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	105,
+	100,
+	61,
+	34,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	109,
+	101,
+	110,
+	116,
+	115,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	32,
+	61,
+	32,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	68,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	32,
+	46,
+	115,
+	117,
+	98,
+	115,
+	116,
+	114,
+	105,
+	110,
+	103,
+	40,
+	101,
+	97,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	109,
+	101,
+	110,
+	116,
+	115,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	105,
+	110,
+	116,
+	101,
+	103,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	97,
+	110,
+	121,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	100,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	100,
+	111,
+	32,
+	110,
+	111,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	77,
+	105,
+	100,
+	100,
+	108,
+	101,
+	32,
+	69,
+	97,
+	115,
+	116,
+	60,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	99,
+	111,
+	112,
+	121,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	32,
+	112,
+	101,
+	114,
+	104,
+	97,
+	112,
+	115,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	32,
+	68,
+	101,
+	99,
+	101,
+	109,
+	98,
+	101,
+	114,
+	97,
+	114,
+	114,
+	97,
+	110,
+	103,
+	101,
+	109,
+	101,
+	110,
+	116,
+	109,
+	111,
+	115,
+	116,
+	32,
+	102,
+	97,
+	109,
+	111,
+	117,
+	115,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	105,
+	116,
+	121,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	108,
+	105,
+	109,
+	105,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	120,
+	99,
+	108,
+	117,
+	115,
+	105,
+	118,
+	101,
+	108,
+	121,
+	115,
+	111,
+	118,
+	101,
+	114,
+	101,
+	105,
+	103,
+	110,
+	116,
+	121,
+	45,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	62,
+	10,
+	60,
+	116,
+	100,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	117,
+	110,
+	100,
+	101,
+	114,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	112,
+	97,
+	114,
+	97,
+	108,
+	108,
+	101,
+	108,
+	32,
+	116,
+	111,
+	100,
+	111,
+	99,
+	116,
+	114,
+	105,
+	110,
+	101,
+	32,
+	111,
+	102,
+	111,
+	99,
+	99,
+	117,
+	112,
+	105,
+	101,
+	100,
+	32,
+	98,
+	121,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	111,
+	108,
+	111,
+	103,
+	121,
+	82,
+	101,
+	110,
+	97,
+	105,
+	115,
+	115,
+	97,
+	110,
+	99,
+	101,
+	97,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	102,
+	111,
+	114,
+	101,
+	120,
+	112,
+	108,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	112,
+	114,
+	101,
+	100,
+	101,
+	99,
+	101,
+	115,
+	115,
+	111,
+	114,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	60,
+	104,
+	49,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	97,
+	121,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	98,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	105,
+	122,
+	101,
+	100,
+	60,
+	47,
+	102,
+	105,
+	101,
+	108,
+	100,
+	115,
+	101,
+	116,
+	62,
+	112,
+	114,
+	111,
+	103,
+	114,
+	101,
+	115,
+	115,
+	105,
+	118,
+	101,
+	109,
+	105,
+	108,
+	108,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	115,
+	116,
+	97,
+	116,
+	101,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	101,
+	110,
+	102,
+	111,
+	114,
+	99,
+	101,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	110,
+	101,
+	32,
+	97,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	46,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	78,
+	111,
+	100,
+	101,
+	97,
+	103,
+	114,
+	105,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	65,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	114,
+	101,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	101,
+	114,
+	115,
+	116,
+	111,
+	119,
+	97,
+	114,
+	100,
+	115,
+	32,
+	116,
+	104,
+	101,
+	77,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	109,
+	97,
+	110,
+	121,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	40,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	60,
+	116,
+	100,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	59,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	49,
+	48,
+	48,
+	37,
+	105,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	60,
+	104,
+	51,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	32,
+	111,
+	110,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	61,
+	34,
+	41,
+	46,
+	97,
+	100,
+	100,
+	67,
+	108,
+	97,
+	115,
+	115,
+	40,
+	105,
+	110,
+	116,
+	101,
+	114,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	79,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	97,
+	117,
+	103,
+	104,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	111,
+	114,
+	105,
+	101,
+	115,
+	98,
+	114,
+	97,
+	110,
+	99,
+	104,
+	101,
+	115,
+	32,
+	111,
+	102,
+	13,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	115,
+	116,
+	100,
+	101,
+	99,
+	108,
+	97,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	103,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	73,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	121,
+	105,
+	110,
+	32,
+	111,
+	114,
+	100,
+	101,
+	114,
+	32,
+	116,
+	111,
+	34,
+	62,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	97,
+	99,
+	114,
+	111,
+	115,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	114,
+	105,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	41,
+	59,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	105,
+	109,
+	112,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	101,
+	100,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	115,
+	101,
+	101,
+	110,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	119,
+	97,
+	115,
+	32,
+	97,
+	100,
+	101,
+	109,
+	111,
+	110,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	34,
+	62,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	104,
+	101,
+	32,
+	66,
+	114,
+	105,
+	116,
+	105,
+	115,
+	104,
+	119,
+	97,
+	115,
+	32,
+	119,
+	114,
+	105,
+	116,
+	116,
+	101,
+	110,
+	33,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	59,
+	112,
+	120,
+	59,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	116,
+	111,
+	32,
+	99,
+	111,
+	109,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	101,
+	100,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	109,
+	109,
+	105,
+	103,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	115,
+	111,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	60,
+	104,
+	52,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	98,
+	121,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	115,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	78,
+	111,
+	118,
+	101,
+	109,
+	98,
+	101,
+	114,
+	119,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	112,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	97,
+	99,
+	113,
+	117,
+	105,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	101,
+	114,
+	115,
+	101,
+	99,
+	117,
+	116,
+	105,
+	111,
+	110,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	123,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	105,
+	103,
+	97,
+	116,
+	101,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	101,
+	100,
+	109,
+	111,
+	115,
+	116,
+	32,
+	108,
+	105,
+	107,
+	101,
+	108,
+	121,
+	119,
+	105,
+	100,
+	101,
+	108,
+	121,
+	32,
+	117,
+	115,
+	101,
+	100,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	32,
+	40,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	108,
+	121,
+	73,
+	116,
+	32,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	105,
+	116,
+	32,
+	100,
+	111,
+	101,
+	115,
+	32,
+	110,
+	111,
+	116,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	114,
+	121,
+	32,
+	116,
+	111,
+	105,
+	110,
+	104,
+	97,
+	98,
+	105,
+	116,
+	97,
+	110,
+	116,
+	115,
+	105,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	99,
+	104,
+	111,
+	108,
+	97,
+	114,
+	115,
+	104,
+	105,
+	112,
+	99,
+	111,
+	110,
+	115,
+	117,
+	109,
+	112,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	102,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	111,
+	110,
+	101,
+	32,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	112,
+	120,
+	59,
+	32,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	116,
+	104,
+	101,
+	32,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	97,
+	32,
+	115,
+	101,
+	114,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	97,
+	114,
+	101,
+	32,
+	117,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	114,
+	111,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	112,
+	114,
+	101,
+	118,
+	105,
+	111,
+	117,
+	115,
+	108,
+	121,
+	32,
+	100,
+	101,
+	114,
+	105,
+	118,
+	97,
+	116,
+	105,
+	118,
+	101,
+	115,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	101,
+	115,
+	99,
+	111,
+	108,
+	111,
+	114,
+	115,
+	99,
+	104,
+	101,
+	109,
+	101,
+	115,
+	116,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	99,
+	101,
+	114,
+	116,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	101,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	32,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	61,
+	34,
+	104,
+	105,
+	103,
+	104,
+	32,
+	115,
+	99,
+	104,
+	111,
+	111,
+	108,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	101,
+	32,
+	116,
+	111,
+	99,
+	111,
+	109,
+	102,
+	111,
+	114,
+	116,
+	97,
+	98,
+	108,
+	101,
+	97,
+	100,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	116,
+	104,
+	114,
+	101,
+	101,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	121,
+	105,
+	110,
+	32,
+	70,
+	101,
+	98,
+	114,
+	117,
+	97,
+	114,
+	121,
+	115,
+	111,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	119,
+	104,
+	111,
+	32,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	100,
+	32,
+	98,
+	121,
+	60,
+	112,
+	97,
+	114,
+	97,
+	109,
+	32,
+	110,
+	97,
+	109,
+	101,
+	97,
+	102,
+	102,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	105,
+	110,
+	32,
+	116,
+	101,
+	114,
+	109,
+	115,
+	32,
+	111,
+	102,
+	97,
+	112,
+	112,
+	111,
+	105,
+	110,
+	116,
+	109,
+	101,
+	110,
+	116,
+	73,
+	83,
+	79,
+	45,
+	56,
+	56,
+	53,
+	57,
+	45,
+	49,
+	34,
+	119,
+	97,
+	115,
+	32,
+	98,
+	111,
+	114,
+	110,
+	32,
+	105,
+	110,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	99,
+	97,
+	108,
+	32,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	101,
+	100,
+	32,
+	97,
+	115,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	109,
+	101,
+	110,
+	116,
+	105,
+	115,
+	32,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	32,
+	97,
+	110,
+	100,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	58,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	116,
+	99,
+	101,
+	108,
+	101,
+	98,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	114,
+	97,
+	110,
+	115,
+	109,
+	105,
+	116,
+	116,
+	101,
+	100,
+	47,
+	106,
+	115,
+	47,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	46,
+	105,
+	115,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	116,
+	104,
+	101,
+	111,
+	114,
+	101,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	116,
+	97,
+	98,
+	105,
+	110,
+	100,
+	101,
+	120,
+	61,
+	34,
+	105,
+	116,
+	32,
+	99,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	60,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	104,
+	97,
+	118,
+	105,
+	110,
+	103,
+	32,
+	98,
+	101,
+	101,
+	110,
+	13,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	60,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	84,
+	104,
+	101,
+	32,
+	99,
+	111,
+	109,
+	112,
+	105,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	104,
+	101,
+	32,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	32,
+	98,
+	121,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	101,
+	114,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	101,
+	100,
+	105,
+	110,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	109,
+	111,
+	110,
+	103,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	116,
+	111,
+	32,
+	115,
+	97,
+	121,
+	32,
+	116,
+	104,
+	97,
+	116,
+	69,
+	110,
+	103,
+	105,
+	110,
+	101,
+	101,
+	114,
+	105,
+	110,
+	103,
+	97,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	98,
+	101,
+	108,
+	105,
+	101,
+	102,
+	32,
+	116,
+	104,
+	97,
+	116,
+	112,
+	104,
+	111,
+	116,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	121,
+	105,
+	110,
+	103,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	32,
+	111,
+	102,
+	110,
+	101,
+	99,
+	101,
+	115,
+	115,
+	97,
+	114,
+	105,
+	108,
+	121,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	116,
+	101,
+	99,
+	104,
+	110,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	108,
+	101,
+	97,
+	118,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	97,
+	99,
+	117,
+	108,
+	97,
+	114,
+	102,
+	114,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	105,
+	99,
+	105,
+	116,
+	121,
+	104,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	115,
+	116,
+	97,
+	117,
+	114,
+	97,
+	110,
+	116,
+	115,
+	112,
+	97,
+	114,
+	116,
+	110,
+	101,
+	114,
+	115,
+	104,
+	105,
+	112,
+	101,
+	109,
+	112,
+	104,
+	97,
+	115,
+	105,
+	115,
+	32,
+	111,
+	110,
+	109,
+	111,
+	115,
+	116,
+	32,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	115,
+	104,
+	97,
+	114,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	115,
+	97,
+	121,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	102,
+	105,
+	108,
+	108,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	116,
+	32,
+	105,
+	115,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	34,
+	62,
+	60,
+	47,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	62,
+	97,
+	115,
+	32,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	115,
+	58,
+	109,
+	101,
+	114,
+	103,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	109,
+	101,
+	114,
+	99,
+	105,
+	97,
+	108,
+	32,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	100,
+	32,
+	111,
+	117,
+	116,
+	111,
+	112,
+	112,
+	111,
+	114,
+	116,
+	117,
+	110,
+	105,
+	116,
+	121,
+	118,
+	105,
+	101,
+	119,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	109,
+	101,
+	110,
+	116,
+	100,
+	105,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	105,
+	110,
+	103,
+	104,
+	101,
+	32,
+	114,
+	101,
+	99,
+	101,
+	105,
+	118,
+	101,
+	100,
+	115,
+	101,
+	116,
+	73,
+	110,
+	116,
+	101,
+	114,
+	118,
+	97,
+	108,
+	34,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	105,
+	110,
+	32,
+	78,
+	101,
+	119,
+	32,
+	89,
+	111,
+	114,
+	107,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	99,
+	111,
+	109,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	10,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	105,
+	110,
+	99,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	101,
+	59,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	97,
+	116,
+	116,
+	97,
+	99,
+	104,
+	69,
+	118,
+	101,
+	110,
+	116,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	99,
+	97,
+	114,
+	114,
+	105,
+	101,
+	100,
+	32,
+	111,
+	117,
+	116,
+	83,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	32,
+	97,
+	110,
+	100,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	32,
+	111,
+	102,
+	67,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	34,
+	62,
+	109,
+	97,
+	105,
+	110,
+	116,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	111,
+	112,
+	104,
+	101,
+	114,
+	77,
+	117,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	119,
+	114,
+	105,
+	116,
+	105,
+	110,
+	103,
+	115,
+	32,
+	111,
+	102,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	50,
+	115,
+	105,
+	122,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	109,
+	105,
+	120,
+	116,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	69,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	32,
+	111,
+	102,
+	101,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	105,
+	116,
+	105,
+	118,
+	101,
+	32,
+	111,
+	110,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	61,
+	34,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	32,
+	111,
+	102,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	99,
+	116,
+	105,
+	118,
+	101,
+	47,
+	68,
+	84,
+	68,
+	32,
+	88,
+	72,
+	84,
+	77,
+	76,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	116,
+	101,
+	110,
+	100,
+	101,
+	110,
+	99,
+	121,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	118,
+	105,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	100,
+	101,
+	115,
+	112,
+	105,
+	116,
+	101,
+	32,
+	116,
+	104,
+	101,
+	115,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	99,
+	32,
+	108,
+	101,
+	103,
+	105,
+	115,
+	108,
+	97,
+	116,
+	117,
+	114,
+	101,
+	46,
+	105,
+	110,
+	110,
+	101,
+	114,
+	72,
+	84,
+	77,
+	76,
+	32,
+	97,
+	108,
+	108,
+	101,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	65,
+	103,
+	114,
+	105,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	119,
+	97,
+	115,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	105,
+	110,
+	97,
+	112,
+	112,
+	114,
+	111,
+	97,
+	99,
+	104,
+	32,
+	116,
+	111,
+	105,
+	110,
+	116,
+	101,
+	108,
+	108,
+	105,
+	103,
+	101,
+	110,
+	116,
+	121,
+	101,
+	97,
+	114,
+	115,
+	32,
+	108,
+	97,
+	116,
+	101,
+	114,
+	44,
+	115,
+	97,
+	110,
+	115,
+	45,
+	115,
+	101,
+	114,
+	105,
+	102,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	105,
+	110,
+	103,
+	80,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	97,
+	110,
+	99,
+	101,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	97,
+	110,
+	99,
+	101,
+	115,
+	44,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	105,
+	115,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	97,
+	98,
+	98,
+	114,
+	101,
+	118,
+	105,
+	97,
+	116,
+	101,
+	100,
+	104,
+	105,
+	103,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	115,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	100,
+	105,
+	118,
+	105,
+	100,
+	117,
+	97,
+	108,
+	32,
+	99,
+	111,
+	109,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	111,
+	102,
+	115,
+	117,
+	112,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	99,
+	108,
+	97,
+	105,
+	109,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	49,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	99,
+	97,
+	108,
+	32,
+	104,
+	105,
+	115,
+	32,
+	98,
+	114,
+	111,
+	116,
+	104,
+	101,
+	114,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	97,
+	110,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	114,
+	121,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	101,
+	100,
+	32,
+	98,
+	121,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	117,
+	108,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	108,
+	121,
+	32,
+	105,
+	110,
+	110,
+	111,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	116,
+	32,
+	105,
+	115,
+	32,
+	115,
+	116,
+	105,
+	108,
+	108,
+	99,
+	97,
+	110,
+	32,
+	111,
+	110,
+	108,
+	121,
+	32,
+	98,
+	101,
+	100,
+	101,
+	102,
+	105,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	111,
+	71,
+	77,
+	84,
+	83,
+	116,
+	114,
+	105,
+	110,
+	103,
+	65,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	105,
+	109,
+	103,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	69,
+	118,
+	101,
+	110,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	44,
+	119,
+	97,
+	115,
+	32,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	100,
+	111,
+	99,
+	99,
+	117,
+	114,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	110,
+	101,
+	105,
+	103,
+	104,
+	98,
+	111,
+	114,
+	105,
+	110,
+	103,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	117,
+	105,
+	115,
+	104,
+	119,
+	104,
+	101,
+	110,
+	32,
+	104,
+	101,
+	32,
+	119,
+	97,
+	115,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	105,
+	110,
+	103,
+	116,
+	101,
+	114,
+	114,
+	101,
+	115,
+	116,
+	114,
+	105,
+	97,
+	108,
+	77,
+	97,
+	110,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	114,
+	103,
+	117,
+	101,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	110,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	99,
+	111,
+	110,
+	113,
+	117,
+	101,
+	115,
+	116,
+	32,
+	111,
+	102,
+	119,
+	105,
+	100,
+	101,
+	115,
+	112,
+	114,
+	101,
+	97,
+	100,
+	32,
+	119,
+	101,
+	114,
+	101,
+	32,
+	107,
+	105,
+	108,
+	108,
+	101,
+	100,
+	115,
+	99,
+	114,
+	101,
+	101,
+	110,
+	32,
+	97,
+	110,
+	100,
+	32,
+	73,
+	110,
+	32,
+	111,
+	114,
+	100,
+	101,
+	114,
+	32,
+	116,
+	111,
+	101,
+	120,
+	112,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	100,
+	101,
+	115,
+	99,
+	101,
+	110,
+	100,
+	97,
+	110,
+	116,
+	115,
+	97,
+	114,
+	101,
+	32,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	108,
+	101,
+	103,
+	105,
+	115,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	109,
+	111,
+	115,
+	116,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	121,
+	101,
+	97,
+	114,
+	115,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	115,
+	32,
+	110,
+	111,
+	116,
+	104,
+	101,
+	32,
+	104,
+	105,
+	103,
+	104,
+	101,
+	115,
+	116,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	100,
+	111,
+	32,
+	110,
+	111,
+	116,
+	97,
+	114,
+	103,
+	117,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	104,
+	111,
+	119,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	112,
+	114,
+	101,
+	100,
+	111,
+	109,
+	105,
+	110,
+	97,
+	110,
+	116,
+	116,
+	104,
+	101,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	105,
+	110,
+	103,
+	115,
+	104,
+	111,
+	114,
+	116,
+	45,
+	108,
+	105,
+	118,
+	101,
+	100,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	97,
+	62,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	118,
+	101,
+	114,
+	121,
+	32,
+	108,
+	105,
+	116,
+	116,
+	108,
+	101,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	104,
+	97,
+	100,
+	32,
+	97,
+	108,
+	114,
+	101,
+	97,
+	100,
+	121,
+	105,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	116,
+	101,
+	100,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	101,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	115,
+	32,
+	111,
+	102,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	44,
+	60,
+	47,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	101,
+	110,
+	116,
+	101,
+	114,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	51,
+	73,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	108,
+	97,
+	114,
+	103,
+	101,
+	45,
+	115,
+	99,
+	97,
+	108,
+	101,
+	46,
+	32,
+	65,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	115,
+	116,
+	97,
+	114,
+	116,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	116,
+	119,
+	111,
+	32,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	115,
+	117,
+	98,
+	111,
+	114,
+	100,
+	105,
+	110,
+	97,
+	116,
+	101,
+	108,
+	97,
+	114,
+	103,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	97,
+	110,
+	100,
+	60,
+	47,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	62,
+	13,
+	10,
+	67,
+	111,
+	110,
+	116,
+	105,
+	110,
+	101,
+	110,
+	116,
+	97,
+	108,
+	101,
+	108,
+	105,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	110,
+	103,
+	119,
+	105,
+	108,
+	108,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	101,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	102,
+	114,
+	111,
+	110,
+	116,
+	32,
+	111,
+	102,
+	115,
+	105,
+	116,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	110,
+	115,
+	117,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	116,
+	111,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	32,
+	97,
+	109,
+	105,
+	115,
+	115,
+	105,
+	115,
+	115,
+	105,
+	112,
+	112,
+	105,
+	112,
+	111,
+	116,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	111,
+	117,
+	116,
+	115,
+	116,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	98,
+	101,
+	116,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	119,
+	104,
+	97,
+	116,
+	32,
+	105,
+	115,
+	32,
+	110,
+	111,
+	119,
+	115,
+	105,
+	116,
+	117,
+	97,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	84,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	115,
+	84,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	114,
+	109,
+	32,
+	111,
+	102,
+	97,
+	116,
+	109,
+	111,
+	115,
+	112,
+	104,
+	101,
+	114,
+	105,
+	99,
+	105,
+	100,
+	101,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	101,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	105,
+	115,
+	101,
+	115,
+	99,
+	97,
+	108,
+	99,
+	117,
+	108,
+	97,
+	116,
+	105,
+	110,
+	103,
+	101,
+	97,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	109,
+	110,
+	97,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	112,
+	108,
+	117,
+	103,
+	105,
+	110,
+	115,
+	112,
+	97,
+	103,
+	101,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	112,
+	104,
+	112,
+	63,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	72,
+	101,
+	32,
+	119,
+	97,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	119,
+	97,
+	115,
+	32,
+	97,
+	108,
+	114,
+	101,
+	97,
+	100,
+	121,
+	115,
+	116,
+	97,
+	116,
+	105,
+	115,
+	116,
+	105,
+	99,
+	97,
+	108,
+	105,
+	110,
+	32,
+	102,
+	97,
+	118,
+	111,
+	114,
+	32,
+	111,
+	102,
+	77,
+	105,
+	110,
+	105,
+	115,
+	116,
+	114,
+	121,
+	32,
+	111,
+	102,
+	109,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	102,
+	111,
+	114,
+	109,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	115,
+	32,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	100,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	84,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	105,
+	122,
+	101,
+	100,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	100,
+	32,
+	105,
+	110,
+	97,
+	114,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	110,
+	100,
+	32,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	109,
+	97,
+	100,
+	101,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	101,
+	109,
+	115,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	108,
+	105,
+	107,
+	101,
+	108,
+	121,
+	32,
+	116,
+	104,
+	97,
+	116,
+	80,
+	97,
+	108,
+	101,
+	115,
+	116,
+	105,
+	110,
+	105,
+	97,
+	110,
+	110,
+	97,
+	109,
+	101,
+	100,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	105,
+	116,
+	32,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	109,
+	111,
+	115,
+	116,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	116,
+	111,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	32,
+	116,
+	111,
+	98,
+	117,
+	116,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	99,
+	111,
+	110,
+	115,
+	101,
+	99,
+	117,
+	116,
+	105,
+	118,
+	101,
+	116,
+	101,
+	109,
+	112,
+	111,
+	114,
+	97,
+	114,
+	105,
+	108,
+	121,
+	73,
+	110,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	44,
+	99,
+	111,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	97,
+	107,
+	101,
+	115,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	115,
+	117,
+	98,
+	100,
+	105,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	116,
+	101,
+	114,
+	114,
+	105,
+	116,
+	111,
+	114,
+	105,
+	97,
+	108,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	112,
+	101,
+	114,
+	109,
+	97,
+	110,
+	101,
+	110,
+	116,
+	108,
+	121,
+	119,
+	97,
+	115,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	108,
+	121,
+	111,
+	117,
+	116,
+	98,
+	114,
+	101,
+	97,
+	107,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	97,
+	115,
+	116,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	97,
+	32,
+	120,
+	109,
+	108,
+	110,
+	115,
+	58,
+	111,
+	103,
+	61,
+	34,
+	62,
+	60,
+	97,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	67,
+	111,
+	110,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	32,
+	109,
+	97,
+	121,
+	32,
+	98,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	109,
+	97,
+	110,
+	117,
+	102,
+	97,
+	99,
+	116,
+	117,
+	114,
+	101,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	98,
+	101,
+	105,
+	110,
+	103,
+	99,
+	108,
+	101,
+	97,
+	114,
+	102,
+	105,
+	120,
+	34,
+	62,
+	10,
+	113,
+	117,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	119,
+	97,
+	115,
+	32,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	116,
+	111,
+	32,
+	98,
+	101,
+	99,
+	111,
+	109,
+	101,
+	32,
+	97,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	105,
+	110,
+	115,
+	112,
+	105,
+	114,
+	101,
+	100,
+	32,
+	98,
+	121,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	117,
+	108,
+	32,
+	97,
+	32,
+	116,
+	105,
+	109,
+	101,
+	32,
+	119,
+	104,
+	101,
+	110,
+	109,
+	111,
+	114,
+	101,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	97,
+	109,
+	111,
+	110,
+	103,
+	115,
+	116,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	32,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	49,
+	48,
+	48,
+	37,
+	59,
+	116,
+	101,
+	99,
+	104,
+	110,
+	111,
+	108,
+	111,
+	103,
+	121,
+	44,
+	119,
+	97,
+	115,
+	32,
+	97,
+	100,
+	111,
+	112,
+	116,
+	101,
+	100,
+	116,
+	111,
+	32,
+	107,
+	101,
+	101,
+	112,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	116,
+	116,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	108,
+	105,
+	118,
+	101,
+	32,
+	98,
+	105,
+	114,
+	116,
+	104,
+	115,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	104,
+	116,
+	109,
+	108,
+	34,
+	67,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	105,
+	99,
+	117,
+	116,
+	97,
+	115,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	38,
+	97,
+	109,
+	112,
+	59,
+	116,
+	105,
+	109,
+	101,
+	115,
+	59,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	32,
+	102,
+	111,
+	114,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	114,
+	105,
+	103,
+	104,
+	116,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	121,
+	97,
+	108,
+	119,
+	97,
+	121,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	66,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	105,
+	115,
+	32,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	113,
+	34,
+	32,
+	99,
+	111,
+	110,
+	102,
+	105,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	32,
+	111,
+	102,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	34,
+	32,
+	47,
+	62,
+	105,
+	115,
+	32,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	69,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	13,
+	10,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	67,
+	111,
+	110,
+	118,
+	101,
+	114,
+	115,
+	101,
+	108,
+	121,
+	44,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	48,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	105,
+	115,
+	32,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	108,
+	121,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	99,
+	111,
+	109,
+	101,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	99,
+	105,
+	116,
+	105,
+	122,
+	101,
+	110,
+	115,
+	32,
+	111,
+	102,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	105,
+	97,
+	110,
+	115,
+	114,
+	101,
+	97,
+	99,
+	104,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	32,
+	101,
+	97,
+	114,
+	108,
+	121,
+	32,
+	97,
+	115,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	32,
+	111,
+	118,
+	101,
+	114,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	99,
+	101,
+	108,
+	108,
+	118,
+	97,
+	108,
+	105,
+	100,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	108,
+	121,
+	32,
+	116,
+	111,
+	111,
+	110,
+	109,
+	111,
+	117,
+	115,
+	101,
+	100,
+	111,
+	119,
+	110,
+	119,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	119,
+	104,
+	101,
+	110,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	115,
+	32,
+	111,
+	102,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	97,
+	99,
+	99,
+	111,
+	109,
+	109,
+	111,
+	100,
+	97,
+	116,
+	101,
+	97,
+	108,
+	111,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	73,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	116,
+	101,
+	116,
+	104,
+	101,
+	32,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	100,
+	101,
+	108,
+	105,
+	99,
+	105,
+	111,
+	117,
+	115,
+	34,
+	62,
+	116,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	32,
+	110,
+	111,
+	116,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	105,
+	102,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	97,
+	114,
+	101,
+	97,
+	110,
+	100,
+	32,
+	102,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	97,
+	32,
+	109,
+	97,
+	116,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	13,
+	10,
+	9,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	13,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	102,
+	97,
+	115,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	109,
+	97,
+	106,
+	111,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	97,
+	116,
+	105,
+	118,
+	101,
+	116,
+	111,
+	32,
+	109,
+	97,
+	105,
+	110,
+	116,
+	97,
+	105,
+	110,
+	105,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	32,
+	116,
+	104,
+	101,
+	97,
+	119,
+	97,
+	114,
+	100,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	101,
+	114,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	102,
+	114,
+	97,
+	109,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	114,
+	101,
+	115,
+	116,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	97,
+	110,
+	97,
+	108,
+	121,
+	115,
+	105,
+	115,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	105,
+	114,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	68,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	101,
+	110,
+	116,
+	97,
+	108,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	32,
+	119,
+	111,
+	114,
+	107,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	98,
+	101,
+	103,
+	105,
+	110,
+	115,
+	32,
+	119,
+	105,
+	116,
+	104,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	58,
+	99,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	101,
+	110,
+	116,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	101,
+	113,
+	117,
+	105,
+	108,
+	105,
+	98,
+	114,
+	105,
+	117,
+	109,
+	97,
+	115,
+	115,
+	117,
+	109,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	115,
+	32,
+	103,
+	105,
+	118,
+	101,
+	110,
+	32,
+	98,
+	121,
+	110,
+	101,
+	101,
+	100,
+	115,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	99,
+	111,
+	111,
+	114,
+	100,
+	105,
+	110,
+	97,
+	116,
+	101,
+	115,
+	116,
+	104,
+	101,
+	32,
+	118,
+	97,
+	114,
+	105,
+	111,
+	117,
+	115,
+	97,
+	114,
+	101,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	111,
+	110,
+	108,
+	121,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	105,
+	115,
+	32,
+	97,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	116,
+	104,
+	101,
+	111,
+	114,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	105,
+	101,
+	115,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	100,
+	103,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	114,
+	101,
+	110,
+	103,
+	116,
+	104,
+	32,
+	111,
+	102,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	105,
+	110,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	45,
+	100,
+	97,
+	121,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	108,
+	108,
+	121,
+	116,
+	111,
+	32,
+	102,
+	111,
+	114,
+	109,
+	32,
+	116,
+	104,
+	101,
+	98,
+	117,
+	116,
+	32,
+	105,
+	110,
+	115,
+	116,
+	101,
+	97,
+	100,
+	99,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	116,
+	116,
+	97,
+	99,
+	104,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	115,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	108,
+	121,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	115,
+	32,
+	102,
+	111,
+	114,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	116,
+	104,
+	101,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	109,
+	97,
+	100,
+	101,
+	119,
+	97,
+	115,
+	32,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	109,
+	101,
+	97,
+	110,
+	115,
+	98,
+	117,
+	116,
+	32,
+	100,
+	105,
+	100,
+	32,
+	110,
+	111,
+	116,
+	111,
+	110,
+	77,
+	111,
+	117,
+	115,
+	101,
+	79,
+	118,
+	101,
+	114,
+	97,
+	115,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	99,
+	111,
+	109,
+	105,
+	110,
+	103,
+	32,
+	102,
+	114,
+	111,
+	109,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	105,
+	109,
+	97,
+	114,
+	121,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	102,
+	111,
+	114,
+	32,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	97,
+	32,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	32,
+	111,
+	102,
+	97,
+	114,
+	101,
+	32,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	105,
+	116,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	104,
+	97,
+	118,
+	101,
+	109,
+	117,
+	99,
+	104,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	114,
+	10,
+	9,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	97,
+	100,
+	111,
+	112,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	121,
+	32,
+	111,
+	102,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	119,
+	97,
+	115,
+	32,
+	98,
+	114,
+	111,
+	117,
+	103,
+	104,
+	116,
+	99,
+	104,
+	105,
+	108,
+	100,
+	114,
+	101,
+	110,
+	32,
+	111,
+	102,
+	80,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	105,
+	110,
+	103,
+	108,
+	111,
+	110,
+	103,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	109,
+	97,
+	110,
+	117,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	115,
+	119,
+	97,
+	114,
+	32,
+	97,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	98,
+	121,
+	32,
+	109,
+	101,
+	97,
+	110,
+	115,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	112,
+	114,
+	111,
+	112,
+	114,
+	105,
+	101,
+	116,
+	97,
+	114,
+	121,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	116,
+	105,
+	110,
+	103,
+	112,
+	114,
+	101,
+	115,
+	116,
+	105,
+	103,
+	105,
+	111,
+	117,
+	115,
+	103,
+	114,
+	97,
+	109,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	108,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	101,
+	46,
+	116,
+	111,
+	32,
+	109,
+	97,
+	107,
+	101,
+	32,
+	116,
+	104,
+	101,
+	73,
+	116,
+	32,
+	119,
+	97,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	105,
+	115,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	32,
+	105,
+	110,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	105,
+	116,
+	111,
+	114,
+	115,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	85,
+	46,
+	83,
+	46,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	98,
+	114,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	104,
+	101,
+	99,
+	97,
+	108,
+	99,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	102,
+	97,
+	108,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	105,
+	110,
+	32,
+	104,
+	111,
+	110,
+	111,
+	114,
+	32,
+	111,
+	102,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	100,
+	32,
+	105,
+	110,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	97,
+	110,
+	100,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	107,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	49,
+	115,
+	116,
+	32,
+	69,
+	97,
+	114,
+	108,
+	32,
+	111,
+	102,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	32,
+	97,
+	110,
+	100,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	97,
+	108,
+	108,
+	121,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	10,
+	32,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	98,
+	97,
+	99,
+	107,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	104,
+	105,
+	115,
+	101,
+	120,
+	112,
+	111,
+	115,
+	117,
+	114,
+	101,
+	32,
+	116,
+	111,
+	97,
+	114,
+	101,
+	32,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	102,
+	111,
+	114,
+	109,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	100,
+	100,
+	70,
+	97,
+	118,
+	111,
+	114,
+	105,
+	116,
+	101,
+	99,
+	105,
+	116,
+	105,
+	122,
+	101,
+	110,
+	115,
+	104,
+	105,
+	112,
+	112,
+	97,
+	114,
+	116,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	32,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	101,
+	116,
+	111,
+	32,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	38,
+	97,
+	109,
+	112,
+	59,
+	109,
+	105,
+	110,
+	117,
+	115,
+	59,
+	97,
+	112,
+	112,
+	114,
+	111,
+	118,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	97,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	100,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	103,
+	112,
+	108,
+	97,
+	121,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	115,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	48,
+	34,
+	32,
+	105,
+	110,
+	32,
+	104,
+	105,
+	115,
+	32,
+	98,
+	111,
+	111,
+	107,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	97,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	115,
+	32,
+	116,
+	104,
+	101,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	99,
+	101,
+	32,
+	105,
+	110,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	116,
+	100,
+	62,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	115,
+	116,
+	116,
+	104,
+	101,
+	32,
+	105,
+	100,
+	101,
+	97,
+	32,
+	111,
+	102,
+	97,
+	32,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	119,
+	101,
+	114,
+	101,
+	32,
+	102,
+	111,
+	114,
+	99,
+	101,
+	100,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	98,
+	116,
+	110,
+	100,
+	97,
+	121,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	102,
+	101,
+	97,
+	116,
+	117,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	104,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	32,
+	105,
+	110,
+	105,
+	110,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	111,
+	102,
+	116,
+	117,
+	114,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	104,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	76,
+	111,
+	114,
+	100,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	104,
+	97,
+	115,
+	32,
+	105,
+	116,
+	115,
+	32,
+	111,
+	119,
+	110,
+	69,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	112,
+	112,
+	114,
+	111,
+	118,
+	97,
+	108,
+	32,
+	111,
+	102,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	97,
+	99,
+	104,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	44,
+	98,
+	101,
+	104,
+	97,
+	118,
+	105,
+	111,
+	114,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	32,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	97,
+	110,
+	100,
+	32,
+	97,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	101,
+	100,
+	32,
+	111,
+	110,
+	114,
+	101,
+	99,
+	111,
+	114,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	98,
+	108,
+	97,
+	99,
+	107,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	109,
+	97,
+	121,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	39,
+	115,
+	99,
+	97,
+	110,
+	32,
+	108,
+	101,
+	97,
+	100,
+	32,
+	116,
+	111,
+	114,
+	101,
+	102,
+	101,
+	114,
+	115,
+	32,
+	116,
+	111,
+	32,
+	97,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	32,
+	119,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	119,
+	104,
+	105,
+	108,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	87,
+	97,
+	115,
+	104,
+	105,
+	110,
+	103,
+	116,
+	111,
+	110,
+	44,
+	116,
+	104,
+	101,
+	32,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	99,
+	105,
+	116,
+	121,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	9,
+	9,
+	114,
+	101,
+	102,
+	108,
+	101,
+	99,
+	116,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	32,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	109,
+	111,
+	114,
+	101,
+	114,
+	97,
+	100,
+	105,
+	111,
+	97,
+	99,
+	116,
+	105,
+	118,
+	101,
+	114,
+	101,
+	106,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	119,
+	105,
+	116,
+	104,
+	111,
+	117,
+	116,
+	32,
+	97,
+	110,
+	121,
+	104,
+	105,
+	115,
+	32,
+	102,
+	97,
+	116,
+	104,
+	101,
+	114,
+	44,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	99,
+	111,
+	117,
+	108,
+	100,
+	99,
+	111,
+	112,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	32,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	97,
+	32,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	115,
+	119,
+	111,
+	114,
+	107,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	101,
+	114,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	111,
+	102,
+	32,
+	104,
+	105,
+	115,
+	32,
+	108,
+	105,
+	102,
+	101,
+	97,
+	99,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	105,
+	101,
+	100,
+	99,
+	108,
+	105,
+	101,
+	110,
+	116,
+	87,
+	105,
+	100,
+	116,
+	104,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	32,
+	116,
+	104,
+	101,
+	76,
+	101,
+	103,
+	105,
+	115,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	116,
+	111,
+	103,
+	101,
+	116,
+	104,
+	101,
+	114,
+	32,
+	105,
+	110,
+	104,
+	97,
+	115,
+	32,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	102,
+	111,
+	114,
+	32,
+	97,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	116,
+	101,
+	120,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	115,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	117,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	119,
+	104,
+	101,
+	114,
+	101,
+	119,
+	104,
+	101,
+	114,
+	101,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	62,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	34,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	116,
+	104,
+	101,
+	109,
+	115,
+	101,
+	108,
+	118,
+	101,
+	115,
+	44,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	104,
+	101,
+	116,
+	104,
+	97,
+	116,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	114,
+	111,
+	108,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	32,
+	97,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	67,
+	104,
+	105,
+	108,
+	100,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	32,
+	98,
+	121,
+	119,
+	101,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	83,
+	111,
+	109,
+	101,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	44,
+	115,
+	105,
+	100,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	110,
+	101,
+	119,
+	115,
+	108,
+	101,
+	116,
+	116,
+	101,
+	114,
+	115,
+	117,
+	115,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	100,
+	111,
+	119,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	108,
+	105,
+	118,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	115,
+	32,
+	116,
+	111,
+	111,
+	117,
+	116,
+	115,
+	105,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	105,
+	101,
+	115,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	105,
+	110,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	101,
+	114,
+	115,
+	97,
+	116,
+	32,
+	108,
+	101,
+	97,
+	115,
+	116,
+	32,
+	105,
+	110,
+	97,
+	112,
+	112,
+	114,
+	111,
+	120,
+	105,
+	109,
+	97,
+	116,
+	101,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	105,
+	116,
+	119,
+	97,
+	115,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	32,
+	118,
+	97,
+	114,
+	105,
+	111,
+	117,
+	115,
+	71,
+	111,
+	118,
+	101,
+	114,
+	110,
+	111,
+	114,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	116,
+	104,
+	101,
+	32,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	121,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	109,
+	111,
+	115,
+	116,
+	32,
+	119,
+	105,
+	100,
+	101,
+	108,
+	121,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	108,
+	97,
+	116,
+	101,
+	114,
+	97,
+	110,
+	100,
+	32,
+	112,
+	101,
+	114,
+	104,
+	97,
+	112,
+	115,
+	114,
+	105,
+	115,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	111,
+	99,
+	99,
+	117,
+	114,
+	115,
+	32,
+	119,
+	104,
+	101,
+	110,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	99,
+	111,
+	110,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	46,
+	116,
+	104,
+	101,
+	32,
+	119,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	116,
+	104,
+	101,
+	111,
+	114,
+	121,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	115,
+	32,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	99,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	104,
+	101,
+	115,
+	101,
+	101,
+	110,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	99,
+	101,
+	110,
+	116,
+	114,
+	97,
+	108,
+	98,
+	117,
+	105,
+	108,
+	100,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	109,
+	97,
+	110,
+	121,
+	32,
+	111,
+	102,
+	32,
+	104,
+	105,
+	115,
+	97,
+	114,
+	101,
+	97,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	110,
+	108,
+	121,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	109,
+	97,
+	110,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	87,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	84,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	115,
+	32,
+	110,
+	111,
+	101,
+	120,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	83,
+	116,
+	97,
+	116,
+	105,
+	115,
+	116,
+	105,
+	99,
+	97,
+	108,
+	99,
+	111,
+	108,
+	115,
+	112,
+	97,
+	110,
+	61,
+	50,
+	32,
+	124,
+	115,
+	104,
+	111,
+	114,
+	116,
+	32,
+	115,
+	116,
+	111,
+	114,
+	121,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	116,
+	111,
+	112,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	111,
+	102,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	32,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	105,
+	97,
+	110,
+	100,
+	101,
+	99,
+	105,
+	115,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	105,
+	115,
+	32,
+	101,
+	113,
+	117,
+	97,
+	108,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	115,
+	32,
+	111,
+	102,
+	84,
+	104,
+	105,
+	115,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	109,
+	101,
+	114,
+	99,
+	104,
+	97,
+	110,
+	100,
+	105,
+	115,
+	101,
+	102,
+	111,
+	114,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	110,
+	111,
+	32,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	101,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	32,
+	105,
+	110,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	46,
+	32,
+	84,
+	104,
+	101,
+	99,
+	111,
+	109,
+	47,
+	105,
+	109,
+	97,
+	103,
+	101,
+	115,
+	47,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	109,
+	97,
+	107,
+	101,
+	115,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	115,
+	32,
+	116,
+	104,
+	101,
+	108,
+	105,
+	116,
+	101,
+	114,
+	97,
+	116,
+	117,
+	114,
+	101,
+	44,
+	105,
+	115,
+	32,
+	97,
+	32,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	116,
+	104,
+	101,
+	32,
+	97,
+	110,
+	99,
+	105,
+	101,
+	110,
+	116,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	115,
+	32,
+	105,
+	110,
+	116,
+	105,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	102,
+	101,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	98,
+	111,
+	100,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	32,
+	102,
+	101,
+	119,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	109,
+	117,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	107,
+	32,
+	111,
+	102,
+	67,
+	97,
+	108,
+	105,
+	102,
+	111,
+	114,
+	110,
+	105,
+	97,
+	44,
+	115,
+	101,
+	114,
+	118,
+	101,
+	100,
+	32,
+	97,
+	115,
+	32,
+	97,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	46,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	115,
+	32,
+	111,
+	102,
+	109,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	32,
+	105,
+	110,
+	9,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	105,
+	116,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	97,
+	114,
+	101,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	32,
+	105,
+	110,
+	105,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	101,
+	120,
+	112,
+	108,
+	97,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	108,
+	101,
+	97,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	9,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	119,
+	97,
+	115,
+	32,
+	103,
+	114,
+	97,
+	110,
+	116,
+	101,
+	100,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	104,
+	97,
+	118,
+	101,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	97,
+	108,
+	108,
+	121,
+	119,
+	97,
+	115,
+	32,
+	115,
+	101,
+	101,
+	110,
+	32,
+	97,
+	115,
+	97,
+	110,
+	100,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	114,
+	111,
+	108,
+	101,
+	32,
+	111,
+	102,
+	112,
+	114,
+	111,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	98,
+	121,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	98,
+	101,
+	115,
+	116,
+	101,
+	97,
+	99,
+	104,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	46,
+	67,
+	111,
+	110,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	110,
+	101,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	100,
+	105,
+	97,
+	108,
+	101,
+	99,
+	116,
+	115,
+	32,
+	111,
+	102,
+	116,
+	111,
+	32,
+	114,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	119,
+	97,
+	115,
+	32,
+	114,
+	101,
+	110,
+	97,
+	109,
+	101,
+	100,
+	97,
+	32,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	108,
+	108,
+	97,
+	117,
+	110,
+	99,
+	104,
+	101,
+	100,
+	32,
+	105,
+	110,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	101,
+	115,
+	116,
+	119,
+	104,
+	101,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	97,
+	110,
+	100,
+	32,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	32,
+	116,
+	119,
+	111,
+	105,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	116,
+	104,
+	101,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	97,
+	110,
+	100,
+	99,
+	111,
+	110,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	44,
+	116,
+	104,
+	97,
+	116,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	101,
+	110,
+	116,
+	105,
+	116,
+	108,
+	101,
+	100,
+	32,
+	116,
+	111,
+	116,
+	104,
+	101,
+	109,
+	115,
+	101,
+	108,
+	118,
+	101,
+	115,
+	46,
+	113,
+	117,
+	97,
+	110,
+	116,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	114,
+	97,
+	110,
+	115,
+	112,
+	97,
+	114,
+	101,
+	110,
+	99,
+	121,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	32,
+	97,
+	115,
+	116,
+	111,
+	32,
+	106,
+	111,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	121,
+	32,
+	97,
+	110,
+	100,
+	116,
+	104,
+	105,
+	115,
+	32,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	84,
+	104,
+	105,
+	115,
+	32,
+	108,
+	101,
+	100,
+	32,
+	116,
+	111,
+	97,
+	32,
+	115,
+	116,
+	97,
+	116,
+	101,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	115,
+	116,
+	32,
+	116,
+	111,
+	108,
+	97,
+	115,
+	116,
+	73,
+	110,
+	100,
+	101,
+	120,
+	79,
+	102,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	32,
+	104,
+	105,
+	115,
+	105,
+	115,
+	32,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	116,
+	101,
+	114,
+	109,
+	32,
+	105,
+	115,
+	105,
+	115,
+	32,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	100,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	32,
+	116,
+	104,
+	101,
+	110,
+	103,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	84,
+	104,
+	101,
+	32,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	116,
+	104,
+	101,
+	32,
+	115,
+	105,
+	116,
+	101,
+	32,
+	111,
+	102,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	97,
+	108,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	101,
+	44,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	87,
+	101,
+	115,
+	116,
+	116,
+	104,
+	101,
+	121,
+	32,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	115,
+	108,
+	111,
+	118,
+	101,
+	110,
+	196,
+	141,
+	105,
+	110,
+	97,
+	99,
+	111,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	105,
+	111,
+	115,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	100,
+	97,
+	100,
+	99,
+	111,
+	110,
+	100,
+	105,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	97,
+	99,
+	116,
+	105,
+	118,
+	105,
+	100,
+	97,
+	100,
+	101,
+	115,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	105,
+	97,
+	116,
+	101,
+	99,
+	110,
+	111,
+	108,
+	111,
+	103,
+	195,
+	173,
+	97,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	117,
+	110,
+	116,
+	117,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	97,
+	112,
+	108,
+	105,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	115,
+	101,
+	195,
+	177,
+	97,
+	99,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	195,
+	173,
+	97,
+	115,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	97,
+	114,
+	115,
+	101,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	116,
+	114,
+	97,
+	116,
+	97,
+	109,
+	105,
+	101,
+	110,
+	116,
+	111,
+	114,
+	101,
+	103,
+	195,
+	173,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	115,
+	101,
+	99,
+	114,
+	101,
+	116,
+	97,
+	114,
+	195,
+	173,
+	97,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	97,
+	108,
+	101,
+	115,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	101,
+	115,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	99,
+	105,
+	97,
+	112,
+	111,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	100,
+	97,
+	100,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	97,
+	110,
+	116,
+	101,
+	99,
+	114,
+	101,
+	99,
+	105,
+	109,
+	105,
+	101,
+	110,
+	116,
+	111,
+	110,
+	101,
+	99,
+	101,
+	115,
+	105,
+	100,
+	97,
+	100,
+	101,
+	115,
+	115,
+	117,
+	115,
+	99,
+	114,
+	105,
+	98,
+	105,
+	114,
+	115,
+	101,
+	97,
+	115,
+	111,
+	99,
+	105,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	100,
+	105,
+	115,
+	112,
+	111,
+	110,
+	105,
+	98,
+	108,
+	101,
+	115,
+	101,
+	118,
+	97,
+	108,
+	117,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	101,
+	115,
+	116,
+	117,
+	100,
+	105,
+	97,
+	110,
+	116,
+	101,
+	115,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	97,
+	98,
+	108,
+	101,
+	114,
+	101,
+	115,
+	111,
+	108,
+	117,
+	99,
+	105,
+	195,
+	179,
+	110,
+	103,
+	117,
+	97,
+	100,
+	97,
+	108,
+	97,
+	106,
+	97,
+	114,
+	97,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	97,
+	100,
+	111,
+	115,
+	111,
+	112,
+	111,
+	114,
+	116,
+	117,
+	110,
+	105,
+	100,
+	97,
+	100,
+	99,
+	111,
+	109,
+	101,
+	114,
+	99,
+	105,
+	97,
+	108,
+	101,
+	115,
+	102,
+	111,
+	116,
+	111,
+	103,
+	114,
+	97,
+	102,
+	195,
+	173,
+	97,
+	97,
+	117,
+	116,
+	111,
+	114,
+	105,
+	100,
+	97,
+	100,
+	101,
+	115,
+	105,
+	110,
+	103,
+	101,
+	110,
+	105,
+	101,
+	114,
+	195,
+	173,
+	97,
+	116,
+	101,
+	108,
+	101,
+	118,
+	105,
+	115,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	101,
+	110,
+	99,
+	105,
+	97,
+	111,
+	112,
+	101,
+	114,
+	97,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	101,
+	99,
+	105,
+	100,
+	111,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	101,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	109,
+	101,
+	110,
+	116,
+	101,
+	110,
+	97,
+	118,
+	101,
+	103,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	110,
+	102,
+	111,
+	114,
+	109,
+	105,
+	100,
+	97,
+	100,
+	108,
+	105,
+	110,
+	101,
+	45,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	102,
+	111,
+	110,
+	116,
+	45,
+	102,
+	97,
+	109,
+	105,
+	108,
+	121,
+	58,
+	34,
+	32,
+	58,
+	32,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	108,
+	105,
+	110,
+	107,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	47,
+	47,
+	60,
+	33,
+	91,
+	67,
+	68,
+	65,
+	84,
+	65,
+	91,
+	10,
+	79,
+	114,
+	103,
+	97,
+	110,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	48,
+	112,
+	120,
+	59,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	112,
+	100,
+	101,
+	118,
+	105,
+	99,
+	101,
+	45,
+	119,
+	105,
+	100,
+	116,
+	104,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	108,
+	97,
+	98,
+	101,
+	108,
+	32,
+	102,
+	111,
+	114,
+	61,
+	34,
+	114,
+	101,
+	103,
+	105,
+	115,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	60,
+	47,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	104,
+	116,
+	109,
+	108,
+	34,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	111,
+	112,
+	101,
+	110,
+	40,
+	32,
+	33,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	59,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	47,
+	105,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	99,
+	101,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	103,
+	111,
+	111,
+	103,
+	108,
+	101,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	117,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	99,
+	111,
+	110,
+	115,
+	101,
+	114,
+	118,
+	97,
+	116,
+	105,
+	118,
+	101,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	105,
+	110,
+	116,
+	101,
+	108,
+	108,
+	101,
+	99,
+	116,
+	117,
+	97,
+	108,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	108,
+	101,
+	102,
+	116,
+	58,
+	49,
+	56,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	97,
+	110,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	105,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	105,
+	111,
+	110,
+	115,
+	97,
+	98,
+	98,
+	114,
+	101,
+	118,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	60,
+	105,
+	109,
+	103,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	115,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	105,
+	118,
+	105,
+	108,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	49,
+	57,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	97,
+	114,
+	99,
+	104,
+	105,
+	116,
+	101,
+	99,
+	116,
+	117,
+	114,
+	101,
+	105,
+	110,
+	99,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	101,
+	100,
+	50,
+	48,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	45,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	34,
+	62,
+	109,
+	111,
+	115,
+	116,
+	32,
+	110,
+	111,
+	116,
+	97,
+	98,
+	108,
+	121,
+	47,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	110,
+	111,
+	116,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	39,
+	117,
+	110,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	39,
+	41,
+	70,
+	117,
+	114,
+	116,
+	104,
+	101,
+	114,
+	109,
+	111,
+	114,
+	101,
+	44,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	110,
+	110,
+	101,
+	114,
+	72,
+	84,
+	77,
+	76,
+	32,
+	61,
+	32,
+	112,
+	114,
+	105,
+	111,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	100,
+	114,
+	97,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	110,
+	101,
+	103,
+	111,
+	116,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	101,
+	97,
+	100,
+	113,
+	117,
+	97,
+	114,
+	116,
+	101,
+	114,
+	115,
+	83,
+	111,
+	117,
+	116,
+	104,
+	32,
+	65,
+	102,
+	114,
+	105,
+	99,
+	97,
+	117,
+	110,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	117,
+	108,
+	80,
+	101,
+	110,
+	110,
+	115,
+	121,
+	108,
+	118,
+	97,
+	110,
+	105,
+	97,
+	65,
+	115,
+	32,
+	97,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	44,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	38,
+	108,
+	116,
+	59,
+	47,
+	115,
+	117,
+	112,
+	38,
+	103,
+	116,
+	59,
+	100,
+	101,
+	97,
+	108,
+	105,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	112,
+	104,
+	105,
+	108,
+	97,
+	100,
+	101,
+	108,
+	112,
+	104,
+	105,
+	97,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	41,
+	59,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	45,
+	116,
+	111,
+	112,
+	58,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	103,
+	101,
+	116,
+	65,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	101,
+	99,
+	104,
+	110,
+	111,
+	108,
+	111,
+	103,
+	105,
+	101,
+	115,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	61,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	115,
+	117,
+	98,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	108,
+	46,
+	100,
+	116,
+	100,
+	34,
+	62,
+	13,
+	10,
+	60,
+	104,
+	116,
+	103,
+	101,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	105,
+	99,
+	97,
+	108,
+	67,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	105,
+	111,
+	110,
+	39,
+	44,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	103,
+	114,
+	105,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	97,
+	108,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	32,
+	49,
+	97,
+	32,
+	118,
+	97,
+	114,
+	105,
+	101,
+	116,
+	121,
+	32,
+	111,
+	102,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	69,
+	110,
+	99,
+	121,
+	99,
+	108,
+	111,
+	112,
+	101,
+	100,
+	105,
+	97,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	100,
+	101,
+	109,
+	111,
+	110,
+	115,
+	116,
+	114,
+	97,
+	116,
+	101,
+	100,
+	97,
+	99,
+	99,
+	111,
+	109,
+	112,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	105,
+	101,
+	115,
+	68,
+	101,
+	109,
+	111,
+	103,
+	114,
+	97,
+	112,
+	104,
+	105,
+	99,
+	115,
+	41,
+	59,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	100,
+	101,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	107,
+	110,
+	111,
+	119,
+	108,
+	101,
+	100,
+	103,
+	101,
+	32,
+	111,
+	102,
+	115,
+	97,
+	116,
+	105,
+	115,
+	102,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	40,
+	85,
+	83,
+	41,
+	97,
+	112,
+	112,
+	101,
+	110,
+	100,
+	67,
+	104,
+	105,
+	108,
+	100,
+	40,
+	116,
+	114,
+	97,
+	110,
+	115,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	115,
+	46,
+	32,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	105,
+	110,
+	116,
+	101,
+	108,
+	108,
+	105,
+	103,
+	101,
+	110,
+	99,
+	101,
+	34,
+	32,
+	116,
+	97,
+	98,
+	105,
+	110,
+	100,
+	101,
+	120,
+	61,
+	34,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	114,
+	105,
+	103,
+	104,
+	116,
+	59,
+	67,
+	111,
+	109,
+	109,
+	111,
+	110,
+	119,
+	101,
+	97,
+	108,
+	116,
+	104,
+	114,
+	97,
+	110,
+	103,
+	105,
+	110,
+	103,
+	32,
+	102,
+	114,
+	111,
+	109,
+	105,
+	110,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	116,
+	104,
+	101,
+	97,
+	116,
+	32,
+	108,
+	101,
+	97,
+	115,
+	116,
+	32,
+	111,
+	110,
+	101,
+	114,
+	101,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	101,
+	110,
+	99,
+	121,
+	99,
+	108,
+	111,
+	112,
+	101,
+	100,
+	105,
+	97,
+	59,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	49,
+	106,
+	117,
+	114,
+	105,
+	115,
+	100,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	105,
+	109,
+	101,
+	34,
+	62,
+	60,
+	97,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	73,
+	110,
+	32,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	44,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	43,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	115,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	116,
+	97,
+	99,
+	116,
+	32,
+	119,
+	105,
+	116,
+	104,
+	105,
+	115,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	114,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	105,
+	110,
+	103,
+	38,
+	108,
+	116,
+	59,
+	109,
+	97,
+	116,
+	104,
+	38,
+	103,
+	116,
+	59,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	99,
+	99,
+	97,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	60,
+	105,
+	109,
+	103,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	110,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	34,
+	62,
+	99,
+	111,
+	109,
+	112,
+	101,
+	110,
+	115,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	104,
+	97,
+	109,
+	112,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	112,
+	109,
+	101,
+	100,
+	105,
+	97,
+	61,
+	34,
+	97,
+	108,
+	108,
+	34,
+	32,
+	118,
+	105,
+	111,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	32,
+	116,
+	111,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	116,
+	114,
+	117,
+	101,
+	59,
+	83,
+	116,
+	114,
+	105,
+	99,
+	116,
+	47,
+	47,
+	69,
+	78,
+	34,
+	32,
+	116,
+	114,
+	97,
+	110,
+	115,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	110,
+	116,
+	101,
+	114,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	118,
+	101,
+	114,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	73,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	100,
+	105,
+	102,
+	102,
+	105,
+	99,
+	117,
+	108,
+	116,
+	105,
+	101,
+	115,
+	67,
+	104,
+	97,
+	109,
+	112,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	112,
+	99,
+	97,
+	112,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	105,
+	101,
+	115,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	125,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	105,
+	97,
+	110,
+	105,
+	116,
+	121,
+	102,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	80,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	114,
+	101,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	119,
+	97,
+	115,
+	32,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	100,
+	40,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	67,
+	108,
+	97,
+	115,
+	115,
+	40,
+	117,
+	110,
+	101,
+	109,
+	112,
+	108,
+	111,
+	121,
+	109,
+	101,
+	110,
+	116,
+	116,
+	104,
+	101,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	47,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	104,
+	116,
+	109,
+	108,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	34,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	108,
+	111,
+	110,
+	103,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	99,
+	108,
+	97,
+	105,
+	109,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	99,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	115,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	71,
+	117,
+	105,
+	100,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	111,
+	118,
+	101,
+	114,
+	119,
+	104,
+	101,
+	108,
+	109,
+	105,
+	110,
+	103,
+	97,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	99,
+	101,
+	110,
+	116,
+	114,
+	97,
+	116,
+	101,
+	100,
+	44,
+	10,
+	46,
+	110,
+	111,
+	110,
+	116,
+	111,
+	117,
+	99,
+	104,
+	32,
+	111,
+	98,
+	115,
+	101,
+	114,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	60,
+	47,
+	97,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	102,
+	32,
+	40,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	58,
+	32,
+	49,
+	112,
+	120,
+	32,
+	123,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	49,
+	116,
+	114,
+	101,
+	97,
+	116,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	48,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	109,
+	111,
+	100,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	73,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	99,
+	101,
+	100,
+	105,
+	118,
+	105,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	103,
+	114,
+	101,
+	97,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	97,
+	99,
+	104,
+	105,
+	101,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	105,
+	110,
+	103,
+	74,
+	97,
+	118,
+	97,
+	83,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	110,
+	101,
+	118,
+	101,
+	114,
+	116,
+	104,
+	101,
+	108,
+	101,
+	115,
+	115,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	99,
+	101,
+	66,
+	114,
+	111,
+	97,
+	100,
+	99,
+	97,
+	115,
+	116,
+	105,
+	110,
+	103,
+	62,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	116,
+	100,
+	62,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	114,
+	34,
+	62,
+	10,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	97,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	115,
+	114,
+	99,
+	61,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	110,
+	97,
+	118,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	34,
+	32,
+	104,
+	97,
+	108,
+	102,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	97,
+	108,
+	32,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	97,
+	100,
+	118,
+	97,
+	110,
+	116,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	121,
+	32,
+	111,
+	102,
+	102,
+	117,
+	110,
+	100,
+	97,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	32,
+	109,
+	101,
+	116,
+	114,
+	111,
+	112,
+	111,
+	108,
+	105,
+	116,
+	97,
+	110,
+	116,
+	104,
+	101,
+	32,
+	111,
+	112,
+	112,
+	111,
+	115,
+	105,
+	116,
+	101,
+	34,
+	32,
+	120,
+	109,
+	108,
+	58,
+	108,
+	97,
+	110,
+	103,
+	61,
+	34,
+	100,
+	101,
+	108,
+	105,
+	98,
+	101,
+	114,
+	97,
+	116,
+	101,
+	108,
+	121,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	112,
+	114,
+	101,
+	115,
+	101,
+	114,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	109,
+	112,
+	114,
+	111,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	74,
+	101,
+	115,
+	117,
+	115,
+	32,
+	67,
+	104,
+	114,
+	105,
+	115,
+	116,
+	80,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	100,
+	105,
+	115,
+	97,
+	103,
+	114,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	114,
+	44,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	105,
+	116,
+	105,
+	101,
+	115,
+	98,
+	111,
+	100,
+	121,
+	62,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	105,
+	115,
+	32,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	97,
+	108,
+	112,
+	104,
+	97,
+	98,
+	101,
+	116,
+	105,
+	99,
+	97,
+	108,
+	105,
+	115,
+	32,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	47,
+	109,
+	97,
+	110,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	108,
+	111,
+	119,
+	58,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	59,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	32,
+	105,
+	110,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	32,
+	116,
+	104,
+	101,
+	101,
+	120,
+	105,
+	115,
+	116,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	97,
+	108,
+	108,
+	32,
+	111,
+	118,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	101,
+	116,
+	9,
+	60,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	110,
+	115,
+	116,
+	97,
+	108,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	110,
+	101,
+	105,
+	103,
+	104,
+	98,
+	111,
+	114,
+	104,
+	111,
+	111,
+	100,
+	97,
+	114,
+	109,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	99,
+	101,
+	115,
+	114,
+	101,
+	100,
+	117,
+	99,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	115,
+	32,
+	116,
+	111,
+	78,
+	111,
+	110,
+	101,
+	116,
+	104,
+	101,
+	108,
+	101,
+	115,
+	115,
+	44,
+	116,
+	101,
+	109,
+	112,
+	101,
+	114,
+	97,
+	116,
+	117,
+	114,
+	101,
+	115,
+	10,
+	9,
+	9,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	99,
+	108,
+	111,
+	115,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	32,
+	111,
+	102,
+	32,
+	105,
+	115,
+	32,
+	97,
+	98,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	40,
+	115,
+	101,
+	101,
+	32,
+	98,
+	101,
+	108,
+	111,
+	119,
+	41,
+	46,
+	34,
+	32,
+	105,
+	100,
+	61,
+	34,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	115,
+	32,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	116,
+	104,
+	101,
+	32,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	9,
+	9,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	10,
+	9,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	97,
+	99,
+	99,
+	101,
+	108,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	72,
+	97,
+	108,
+	108,
+	32,
+	111,
+	102,
+	32,
+	70,
+	97,
+	109,
+	101,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	110,
+	116,
+	101,
+	114,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	118,
+	101,
+	114,
+	121,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	123,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	101,
+	120,
+	112,
+	108,
+	111,
+	105,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	101,
+	109,
+	101,
+	114,
+	103,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	105,
+	111,
+	110,
+	65,
+	32,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	116,
+	32,
+	109,
+	97,
+	110,
+	117,
+	102,
+	97,
+	99,
+	116,
+	117,
+	114,
+	101,
+	100,
+	101,
+	120,
+	112,
+	101,
+	99,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	62,
+	60,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	104,
+	97,
+	115,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	101,
+	110,
+	110,
+	101,
+	105,
+	103,
+	104,
+	98,
+	111,
+	117,
+	114,
+	105,
+	110,
+	103,
+	119,
+	105,
+	116,
+	104,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	100,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	9,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	83,
+	111,
+	118,
+	105,
+	101,
+	116,
+	32,
+	85,
+	110,
+	105,
+	111,
+	110,
+	97,
+	99,
+	107,
+	110,
+	111,
+	119,
+	108,
+	101,
+	100,
+	103,
+	101,
+	100,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	110,
+	97,
+	109,
+	101,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	97,
+	116,
+	116,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	115,
+	32,
+	116,
+	111,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	115,
+	73,
+	110,
+	32,
+	102,
+	97,
+	99,
+	116,
+	44,
+	32,
+	116,
+	104,
+	101,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	97,
+	105,
+	109,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	117,
+	105,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	102,
+	111,
+	114,
+	109,
+	117,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	108,
+	111,
+	110,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	99,
+	97,
+	110,
+	99,
+	101,
+	108,
+	66,
+	117,
+	98,
+	98,
+	108,
+	101,
+	32,
+	73,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	111,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	115,
+	32,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	100,
+	114,
+	101,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	111,
+	114,
+	32,
+	108,
+	101,
+	115,
+	115,
+	105,
+	110,
+	32,
+	83,
+	101,
+	112,
+	116,
+	101,
+	109,
+	98,
+	101,
+	114,
+	73,
+	110,
+	116,
+	101,
+	108,
+	108,
+	105,
+	103,
+	101,
+	110,
+	99,
+	101,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	112,
+	120,
+	59,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	109,
+	97,
+	110,
+	117,
+	102,
+	97,
+	99,
+	116,
+	117,
+	114,
+	101,
+	114,
+	104,
+	117,
+	109,
+	97,
+	110,
+	32,
+	114,
+	105,
+	103,
+	104,
+	116,
+	115,
+	108,
+	105,
+	110,
+	107,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	112,
+	114,
+	111,
+	112,
+	111,
+	114,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	111,
+	117,
+	116,
+	115,
+	105,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	115,
+	116,
+	114,
+	111,
+	110,
+	111,
+	109,
+	105,
+	99,
+	97,
+	108,
+	104,
+	117,
+	109,
+	97,
+	110,
+	32,
+	98,
+	101,
+	105,
+	110,
+	103,
+	115,
+	110,
+	97,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	32,
+	105,
+	110,
+	97,
+	114,
+	101,
+	32,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	115,
+	109,
+	97,
+	108,
+	108,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	97,
+	32,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	32,
+	119,
+	104,
+	111,
+	101,
+	120,
+	112,
+	97,
+	110,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	97,
+	114,
+	103,
+	117,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	110,
+	111,
+	119,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	73,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	97,
+	114,
+	108,
+	121,
+	105,
+	110,
+	116,
+	101,
+	114,
+	109,
+	101,
+	100,
+	105,
+	97,
+	116,
+	101,
+	100,
+	101,
+	114,
+	105,
+	118,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	83,
+	99,
+	97,
+	110,
+	100,
+	105,
+	110,
+	97,
+	118,
+	105,
+	97,
+	110,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	32,
+	101,
+	115,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	78,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	112,
+	97,
+	103,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	99,
+	111,
+	109,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	101,
+	100,
+	97,
+	110,
+	97,
+	108,
+	111,
+	103,
+	111,
+	117,
+	115,
+	32,
+	116,
+	111,
+	97,
+	114,
+	101,
+	32,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	100,
+	47,
+	117,
+	108,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	119,
+	97,
+	115,
+	32,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	97,
+	110,
+	100,
+	32,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	97,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	116,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	34,
+	32,
+	119,
+	97,
+	115,
+	32,
+	99,
+	97,
+	112,
+	116,
+	117,
+	114,
+	101,
+	100,
+	110,
+	111,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	62,
+	13,
+	10,
+	60,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	60,
+	119,
+	101,
+	114,
+	101,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	100,
+	109,
+	111,
+	114,
+	101,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	73,
+	109,
+	112,
+	101,
+	114,
+	105,
+	97,
+	108,
+	99,
+	111,
+	109,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	111,
+	114,
+	116,
+	104,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	67,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	102,
+	111,
+	114,
+	32,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	105,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	109,
+	111,
+	114,
+	101,
+	32,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	120,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	32,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	32,
+	105,
+	116,
+	115,
+	32,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	105,
+	110,
+	116,
+	111,
+	32,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	116,
+	104,
+	105,
+	115,
+	32,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	97,
+	110,
+	32,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	121,
+	32,
+	97,
+	114,
+	101,
+	32,
+	110,
+	111,
+	116,
+	114,
+	101,
+	106,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	105,
+	115,
+	109,
+	32,
+	111,
+	102,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	105,
+	115,
+	32,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	73,
+	116,
+	32,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	97,
+	110,
+	32,
+	97,
+	103,
+	114,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	97,
+	99,
+	99,
+	105,
+	100,
+	101,
+	110,
+	116,
+	97,
+	108,
+	108,
+	121,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	115,
+	32,
+	102,
+	114,
+	111,
+	109,
+	65,
+	114,
+	99,
+	104,
+	105,
+	116,
+	101,
+	99,
+	116,
+	117,
+	114,
+	101,
+	98,
+	101,
+	116,
+	116,
+	101,
+	114,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	97,
+	114,
+	114,
+	97,
+	110,
+	103,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	110,
+	97,
+	116,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	116,
+	111,
+	115,
+	111,
+	117,
+	116,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	112,
+	97,
+	115,
+	115,
+	32,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	120,
+	109,
+	108,
+	34,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	98,
+	111,
+	108,
+	100,
+	59,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	110,
+	111,
+	110,
+	101,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	105,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	87,
+	111,
+	114,
+	108,
+	100,
+	32,
+	87,
+	97,
+	114,
+	32,
+	73,
+	73,
+	116,
+	101,
+	115,
+	116,
+	105,
+	109,
+	111,
+	110,
+	105,
+	97,
+	108,
+	115,
+	102,
+	111,
+	117,
+	110,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	97,
+	115,
+	32,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	97,
+	98,
+	108,
+	121,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	32,
+	98,
+	121,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	67,
+	111,
+	110,
+	115,
+	101,
+	114,
+	118,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	101,
+	100,
+	32,
+	111,
+	102,
+	114,
+	101,
+	102,
+	101,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	98,
+	97,
+	99,
+	107,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	115,
+	115,
+	34,
+	32,
+	109,
+	101,
+	100,
+	105,
+	97,
+	61,
+	34,
+	80,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	32,
+	111,
+	110,
+	112,
+	114,
+	111,
+	118,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	115,
+	34,
+	119,
+	97,
+	115,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	118,
+	97,
+	114,
+	105,
+	101,
+	116,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	108,
+	105,
+	107,
+	101,
+	108,
+	121,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	99,
+	111,
+	109,
+	112,
+	114,
+	105,
+	115,
+	101,
+	100,
+	32,
+	111,
+	102,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	104,
+	97,
+	110,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	117,
+	112,
+	108,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	32,
+	97,
+	110,
+	100,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	97,
+	110,
+	99,
+	101,
+	115,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	98,
+	101,
+	105,
+	110,
+	103,
+	108,
+	97,
+	116,
+	101,
+	114,
+	32,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	99,
+	97,
+	108,
+	99,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	109,
+	101,
+	97,
+	110,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	62,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	32,
+	102,
+	111,
+	114,
+	101,
+	120,
+	112,
+	108,
+	97,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	115,
+	34,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	97,
+	108,
+	108,
+	111,
+	119,
+	115,
+	73,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	32,
+	119,
+	105,
+	100,
+	101,
+	32,
+	114,
+	97,
+	110,
+	103,
+	101,
+	111,
+	110,
+	32,
+	98,
+	101,
+	104,
+	97,
+	108,
+	102,
+	32,
+	111,
+	102,
+	118,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	116,
+	111,
+	112,
+	34,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	108,
+	101,
+	32,
+	111,
+	102,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	44,
+	60,
+	47,
+	110,
+	111,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	115,
+	97,
+	105,
+	100,
+	32,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	119,
+	104,
+	105,
+	108,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	115,
+	104,
+	121,
+	112,
+	111,
+	116,
+	104,
+	101,
+	116,
+	105,
+	99,
+	97,
+	108,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	101,
+	114,
+	115,
+	112,
+	111,
+	119,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	101,
+	100,
+	32,
+	105,
+	110,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	101,
+	100,
+	32,
+	98,
+	121,
+	105,
+	110,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	116,
+	111,
+	119,
+	101,
+	114,
+	101,
+	32,
+	119,
+	114,
+	105,
+	116,
+	116,
+	101,
+	110,
+	115,
+	112,
+	97,
+	110,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	113,
+	117,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	114,
+	101,
+	106,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	109,
+	112,
+	108,
+	105,
+	101,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	110,
+	118,
+	101,
+	110,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	97,
+	110,
+	100,
+	97,
+	114,
+	100,
+	119,
+	97,
+	115,
+	32,
+	112,
+	114,
+	111,
+	98,
+	97,
+	98,
+	108,
+	121,
+	108,
+	105,
+	110,
+	107,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	111,
+	114,
+	32,
+	111,
+	102,
+	105,
+	110,
+	116,
+	101,
+	114,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	99,
+	104,
+	97,
+	110,
+	103,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	73,
+	110,
+	100,
+	105,
+	97,
+	110,
+	32,
+	79,
+	99,
+	101,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	108,
+	97,
+	115,
+	116,
+	119,
+	111,
+	114,
+	107,
+	105,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	121,
+	101,
+	97,
+	114,
+	115,
+	32,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	84,
+	104,
+	105,
+	115,
+	32,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	101,
+	110,
+	116,
+	101,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	109,
+	101,
+	97,
+	115,
+	117,
+	114,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	97,
+	110,
+	32,
+	101,
+	120,
+	116,
+	114,
+	101,
+	109,
+	101,
+	108,
+	121,
+	118,
+	97,
+	108,
+	117,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	10,
+	97,
+	110,
+	32,
+	101,
+	102,
+	102,
+	111,
+	114,
+	116,
+	32,
+	116,
+	111,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	101,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	111,
+	117,
+	116,
+	104,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	62,
+	115,
+	117,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	108,
+	121,
+	116,
+	104,
+	101,
+	32,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	97,
+	110,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	99,
+	108,
+	101,
+	97,
+	114,
+	84,
+	105,
+	109,
+	101,
+	111,
+	117,
+	116,
+	100,
+	105,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	99,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	101,
+	120,
+	116,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	105,
+	99,
+	32,
+	97,
+	110,
+	100,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	97,
+	114,
+	101,
+	32,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	97,
+	110,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	115,
+	117,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	103,
+	105,
+	118,
+	101,
+	110,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	101,
+	120,
+	112,
+	101,
+	110,
+	100,
+	105,
+	116,
+	117,
+	114,
+	101,
+	115,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	97,
+	62,
+	10,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	98,
+	97,
+	115,
+	105,
+	115,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	61,
+	105,
+	109,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	44,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	115,
+	115,
+	97,
+	115,
+	115,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	115,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	110,
+	111,
+	114,
+	116,
+	104,
+	119,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	34,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	32,
+	32,
+	99,
+	111,
+	110,
+	115,
+	117,
+	108,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	116,
+	32,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	110,
+	116,
+	115,
+	32,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	108,
+	101,
+	102,
+	116,
+	116,
+	104,
+	101,
+	32,
+	103,
+	114,
+	101,
+	97,
+	116,
+	101,
+	115,
+	116,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	115,
+	117,
+	112,
+	101,
+	114,
+	110,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	32,
+	111,
+	110,
+	105,
+	115,
+	32,
+	109,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	101,
+	100,
+	97,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	119,
+	97,
+	115,
+	32,
+	105,
+	110,
+	118,
+	101,
+	110,
+	116,
+	101,
+	100,
+	97,
+	99,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	121,
+	105,
+	110,
+	103,
+	104,
+	105,
+	115,
+	32,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	32,
+	97,
+	116,
+	115,
+	116,
+	117,
+	100,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	101,
+	120,
+	101,
+	99,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	72,
+	117,
+	109,
+	97,
+	110,
+	32,
+	82,
+	105,
+	103,
+	104,
+	116,
+	115,
+	116,
+	101,
+	114,
+	109,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	114,
+	101,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	32,
+	97,
+	110,
+	100,
+	115,
+	117,
+	99,
+	99,
+	101,
+	101,
+	100,
+	101,
+	100,
+	32,
+	98,
+	121,
+	100,
+	101,
+	102,
+	101,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	98,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	97,
+	114,
+	101,
+	99,
+	111,
+	109,
+	109,
+	97,
+	110,
+	100,
+	101,
+	114,
+	32,
+	111,
+	102,
+	115,
+	116,
+	97,
+	116,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	121,
+	101,
+	97,
+	114,
+	115,
+	32,
+	111,
+	102,
+	32,
+	97,
+	103,
+	101,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	117,
+	100,
+	121,
+	32,
+	111,
+	102,
+	60,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	119,
+	104,
+	101,
+	114,
+	101,
+	32,
+	104,
+	101,
+	32,
+	119,
+	97,
+	115,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	102,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	110,
+	111,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	104,
+	101,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	109,
+	105,
+	115,
+	115,
+	105,
+	111,
+	110,
+	101,
+	114,
+	102,
+	111,
+	110,
+	116,
+	45,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	116,
+	101,
+	114,
+	114,
+	105,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	101,
+	120,
+	116,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	115,
+	34,
+	62,
+	82,
+	111,
+	109,
+	97,
+	110,
+	32,
+	69,
+	109,
+	112,
+	105,
+	114,
+	101,
+	101,
+	113,
+	117,
+	97,
+	108,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	73,
+	110,
+	32,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	115,
+	116,
+	44,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	97,
+	110,
+	100,
+	105,
+	115,
+	32,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	97,
+	110,
+	100,
+	32,
+	104,
+	105,
+	115,
+	32,
+	119,
+	105,
+	102,
+	101,
+	40,
+	97,
+	108,
+	115,
+	111,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	62,
+	60,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	32,
+	101,
+	118,
+	111,
+	108,
+	118,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	115,
+	101,
+	101,
+	109,
+	32,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	119,
+	97,
+	115,
+	32,
+	110,
+	111,
+	97,
+	110,
+	32,
+	101,
+	120,
+	99,
+	101,
+	108,
+	108,
+	101,
+	110,
+	116,
+	97,
+	108,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	100,
+	32,
+	98,
+	121,
+	73,
+	110,
+	32,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	99,
+	101,
+	44,
+	98,
+	114,
+	111,
+	97,
+	100,
+	99,
+	97,
+	115,
+	116,
+	105,
+	110,
+	103,
+	99,
+	104,
+	97,
+	114,
+	103,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	114,
+	101,
+	102,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	109,
+	105,
+	108,
+	105,
+	116,
+	97,
+	114,
+	121,
+	32,
+	97,
+	110,
+	100,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	115,
+	101,
+	116,
+	84,
+	97,
+	114,
+	103,
+	101,
+	116,
+	105,
+	110,
+	103,
+	97,
+	114,
+	101,
+	32,
+	97,
+	99,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	118,
+	105,
+	99,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	118,
+	101,
+	114,
+	40,
+	41,
+	59,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	111,
+	117,
+	115,
+	108,
+	121,
+	114,
+	101,
+	113,
+	117,
+	105,
+	114,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	97,
+	110,
+	32,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	110,
+	111,
+	114,
+	116,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	44,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	119,
+	97,
+	115,
+	32,
+	102,
+	114,
+	111,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	111,
+	114,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	119,
+	105,
+	115,
+	101,
+	115,
+	111,
+	109,
+	101,
+	32,
+	102,
+	111,
+	114,
+	109,
+	32,
+	111,
+	102,
+	104,
+	97,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	101,
+	110,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	112,
+	101,
+	114,
+	109,
+	105,
+	116,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	115,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	44,
+	101,
+	110,
+	116,
+	101,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	101,
+	118,
+	105,
+	111,
+	117,
+	115,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	101,
+	110,
+	116,
+	108,
+	121,
+	97,
+	114,
+	101,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	101,
+	108,
+	100,
+	32,
+	111,
+	102,
+	116,
+	104,
+	105,
+	115,
+	32,
+	116,
+	121,
+	112,
+	101,
+	32,
+	111,
+	102,
+	103,
+	105,
+	118,
+	101,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	115,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	115,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	111,
+	114,
+	116,
+	104,
+	100,
+	117,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	105,
+	114,
+	97,
+	114,
+	101,
+	32,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	99,
+	111,
+	114,
+	112,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	109,
+	111,
+	114,
+	101,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	115,
+	117,
+	99,
+	99,
+	101,
+	101,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	105,
+	110,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	100,
+	111,
+	109,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	100,
+	101,
+	115,
+	105,
+	103,
+	110,
+	101,
+	100,
+	32,
+	102,
+	111,
+	114,
+	111,
+	119,
+	110,
+	101,
+	114,
+	115,
+	104,
+	105,
+	112,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	121,
+	115,
+	116,
+	97,
+	110,
+	100,
+	97,
+	114,
+	100,
+	105,
+	122,
+	101,
+	100,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	101,
+	84,
+	101,
+	120,
+	116,
+	119,
+	97,
+	115,
+	32,
+	105,
+	110,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	114,
+	101,
+	99,
+	101,
+	105,
+	118,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	115,
+	117,
+	109,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	114,
+	101,
+	97,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	112,
+	114,
+	105,
+	109,
+	97,
+	114,
+	105,
+	108,
+	121,
+	32,
+	105,
+	110,
+	116,
+	104,
+	101,
+	32,
+	98,
+	97,
+	115,
+	105,
+	115,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	101,
+	110,
+	115,
+	101,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	115,
+	32,
+	102,
+	111,
+	114,
+	100,
+	101,
+	115,
+	116,
+	114,
+	111,
+	121,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	116,
+	32,
+	108,
+	101,
+	97,
+	115,
+	116,
+	32,
+	116,
+	119,
+	111,
+	119,
+	97,
+	115,
+	32,
+	100,
+	101,
+	99,
+	108,
+	97,
+	114,
+	101,
+	100,
+	99,
+	111,
+	117,
+	108,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	83,
+	101,
+	99,
+	114,
+	101,
+	116,
+	97,
+	114,
+	121,
+	32,
+	111,
+	102,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	116,
+	111,
+	112,
+	58,
+	49,
+	47,
+	94,
+	92,
+	115,
+	43,
+	124,
+	92,
+	115,
+	43,
+	36,
+	47,
+	103,
+	101,
+	41,
+	123,
+	116,
+	104,
+	114,
+	111,
+	119,
+	32,
+	101,
+	125,
+	59,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	116,
+	119,
+	111,
+	32,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	32,
+	97,
+	110,
+	100,
+	119,
+	104,
+	111,
+	32,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	100,
+	101,
+	97,
+	116,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	97,
+	108,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	115,
+	9,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	112,
+	114,
+	111,
+	118,
+	105,
+	100,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	99,
+	111,
+	109,
+	112,
+	101,
+	116,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	101,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	40,
+	85,
+	75,
+	41,
+	101,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	40,
+	85,
+	83,
+	41,
+	208,
+	156,
+	208,
+	190,
+	208,
+	189,
+	208,
+	179,
+	208,
+	190,
+	208,
+	187,
+	208,
+	161,
+	209,
+	128,
+	208,
+	191,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	209,
+	129,
+	209,
+	128,
+	208,
+	191,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	209,
+	129,
+	209,
+	128,
+	208,
+	191,
+	209,
+	129,
+	208,
+	186,
+	208,
+	190,
+	217,
+	132,
+	216,
+	185,
+	216,
+	177,
+	216,
+	168,
+	217,
+	138,
+	216,
+	169,
+	230,
+	173,
+	163,
+	233,
+	171,
+	148,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	231,
+	174,
+	128,
+	228,
+	189,
+	147,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	231,
+	185,
+	129,
+	228,
+	189,
+	147,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	230,
+	156,
+	137,
+	233,
+	153,
+	144,
+	229,
+	133,
+	172,
+	229,
+	143,
+	184,
+	228,
+	186,
+	186,
+	230,
+	176,
+	145,
+	230,
+	148,
+	191,
+	229,
+	186,
+	156,
+	233,
+	152,
+	191,
+	233,
+	135,
+	140,
+	229,
+	183,
+	180,
+	229,
+	183,
+	180,
+	231,
+	164,
+	190,
+	228,
+	188,
+	154,
+	228,
+	184,
+	187,
+	228,
+	185,
+	137,
+	230,
+	147,
+	141,
+	228,
+	189,
+	156,
+	231,
+	179,
+	187,
+	231,
+	187,
+	159,
+	230,
+	148,
+	191,
+	231,
+	173,
+	150,
+	230,
+	179,
+	149,
+	232,
+	167,
+	132,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	104,
+	101,
+	114,
+	114,
+	97,
+	109,
+	105,
+	101,
+	110,
+	116,
+	97,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	195,
+	179,
+	110,
+	105,
+	99,
+	111,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	108,
+	97,
+	115,
+	105,
+	102,
+	105,
+	99,
+	97,
+	100,
+	111,
+	115,
+	99,
+	111,
+	110,
+	111,
+	99,
+	105,
+	109,
+	105,
+	101,
+	110,
+	116,
+	111,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	114,
+	101,
+	108,
+	97,
+	99,
+	105,
+	111,
+	110,
+	97,
+	100,
+	97,
+	115,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	195,
+	161,
+	116,
+	105,
+	99,
+	97,
+	114,
+	101,
+	108,
+	97,
+	99,
+	105,
+	111,
+	110,
+	97,
+	100,
+	111,
+	115,
+	100,
+	101,
+	112,
+	97,
+	114,
+	116,
+	97,
+	109,
+	101,
+	110,
+	116,
+	111,
+	116,
+	114,
+	97,
+	98,
+	97,
+	106,
+	97,
+	100,
+	111,
+	114,
+	101,
+	115,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	97,
+	109,
+	101,
+	110,
+	116,
+	101,
+	97,
+	121,
+	117,
+	110,
+	116,
+	97,
+	109,
+	105,
+	101,
+	110,
+	116,
+	111,
+	109,
+	101,
+	114,
+	99,
+	97,
+	100,
+	111,
+	76,
+	105,
+	98,
+	114,
+	101,
+	99,
+	111,
+	110,
+	116,
+	195,
+	161,
+	99,
+	116,
+	101,
+	110,
+	111,
+	115,
+	104,
+	97,
+	98,
+	105,
+	116,
+	97,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	99,
+	117,
+	109,
+	112,
+	108,
+	105,
+	109,
+	105,
+	101,
+	110,
+	116,
+	111,
+	114,
+	101,
+	115,
+	116,
+	97,
+	117,
+	114,
+	97,
+	110,
+	116,
+	101,
+	115,
+	100,
+	105,
+	115,
+	112,
+	111,
+	115,
+	105,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	110,
+	115,
+	101,
+	99,
+	117,
+	101,
+	110,
+	99,
+	105,
+	97,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	195,
+	179,
+	110,
+	105,
+	99,
+	97,
+	97,
+	112,
+	108,
+	105,
+	99,
+	97,
+	99,
+	105,
+	111,
+	110,
+	101,
+	115,
+	100,
+	101,
+	115,
+	99,
+	111,
+	110,
+	101,
+	99,
+	116,
+	97,
+	100,
+	111,
+	105,
+	110,
+	115,
+	116,
+	97,
+	108,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	114,
+	101,
+	97,
+	108,
+	105,
+	122,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	117,
+	116,
+	105,
+	108,
+	105,
+	122,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	101,
+	110,
+	99,
+	105,
+	99,
+	108,
+	111,
+	112,
+	101,
+	100,
+	105,
+	97,
+	101,
+	110,
+	102,
+	101,
+	114,
+	109,
+	101,
+	100,
+	97,
+	100,
+	101,
+	115,
+	105,
+	110,
+	115,
+	116,
+	114,
+	117,
+	109,
+	101,
+	110,
+	116,
+	111,
+	115,
+	101,
+	120,
+	112,
+	101,
+	114,
+	105,
+	101,
+	110,
+	99,
+	105,
+	97,
+	115,
+	105,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	101,
+	115,
+	115,
+	117,
+	98,
+	99,
+	97,
+	116,
+	101,
+	103,
+	111,
+	114,
+	105,
+	97,
+	209,
+	130,
+	208,
+	190,
+	208,
+	187,
+	209,
+	140,
+	208,
+	186,
+	208,
+	190,
+	208,
+	160,
+	208,
+	190,
+	209,
+	129,
+	209,
+	129,
+	208,
+	184,
+	208,
+	184,
+	209,
+	128,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	209,
+	139,
+	208,
+	177,
+	208,
+	190,
+	208,
+	187,
+	209,
+	140,
+	209,
+	136,
+	208,
+	181,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	208,
+	190,
+	208,
+	182,
+	208,
+	181,
+	209,
+	130,
+	208,
+	181,
+	208,
+	180,
+	209,
+	128,
+	209,
+	131,
+	208,
+	179,
+	208,
+	184,
+	209,
+	133,
+	209,
+	129,
+	208,
+	187,
+	209,
+	131,
+	209,
+	135,
+	208,
+	176,
+	208,
+	181,
+	209,
+	129,
+	208,
+	181,
+	208,
+	185,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	179,
+	208,
+	180,
+	208,
+	176,
+	208,
+	160,
+	208,
+	190,
+	209,
+	129,
+	209,
+	129,
+	208,
+	184,
+	209,
+	143,
+	208,
+	156,
+	208,
+	190,
+	209,
+	129,
+	208,
+	186,
+	208,
+	178,
+	208,
+	181,
+	208,
+	180,
+	209,
+	128,
+	209,
+	131,
+	208,
+	179,
+	208,
+	184,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	180,
+	208,
+	176,
+	208,
+	178,
+	208,
+	190,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	209,
+	129,
+	208,
+	180,
+	208,
+	176,
+	208,
+	189,
+	208,
+	189,
+	209,
+	139,
+	209,
+	133,
+	208,
+	180,
+	208,
+	190,
+	208,
+	187,
+	208,
+	182,
+	208,
+	189,
+	209,
+	139,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	208,
+	189,
+	208,
+	190,
+	208,
+	156,
+	208,
+	190,
+	209,
+	129,
+	208,
+	186,
+	208,
+	178,
+	209,
+	139,
+	209,
+	128,
+	209,
+	131,
+	208,
+	177,
+	208,
+	187,
+	208,
+	181,
+	208,
+	185,
+	208,
+	156,
+	208,
+	190,
+	209,
+	129,
+	208,
+	186,
+	208,
+	178,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	208,
+	189,
+	209,
+	139,
+	208,
+	189,
+	208,
+	184,
+	209,
+	135,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	128,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	208,
+	181,
+	208,
+	180,
+	208,
+	190,
+	208,
+	187,
+	208,
+	182,
+	208,
+	181,
+	208,
+	189,
+	209,
+	131,
+	209,
+	129,
+	208,
+	187,
+	209,
+	131,
+	208,
+	179,
+	208,
+	184,
+	209,
+	130,
+	208,
+	181,
+	208,
+	191,
+	208,
+	181,
+	209,
+	128,
+	209,
+	140,
+	208,
+	158,
+	208,
+	180,
+	208,
+	189,
+	208,
+	176,
+	208,
+	186,
+	208,
+	190,
+	208,
+	191,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	209,
+	131,
+	209,
+	128,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	209,
+	131,
+	208,
+	176,
+	208,
+	191,
+	209,
+	128,
+	208,
+	181,
+	208,
+	187,
+	209,
+	143,
+	208,
+	178,
+	208,
+	190,
+	208,
+	190,
+	208,
+	177,
+	209,
+	137,
+	208,
+	181,
+	208,
+	190,
+	208,
+	180,
+	208,
+	189,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	209,
+	129,
+	208,
+	178,
+	208,
+	190,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	184,
+	208,
+	180,
+	209,
+	128,
+	209,
+	131,
+	208,
+	179,
+	208,
+	190,
+	208,
+	185,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	209,
+	131,
+	208,
+	188,
+	208,
+	181,
+	209,
+	133,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	209,
+	136,
+	208,
+	190,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	209,
+	130,
+	208,
+	184,
+	208,
+	178,
+	209,
+	129,
+	209,
+	129,
+	209,
+	139,
+	208,
+	187,
+	208,
+	186,
+	208,
+	176,
+	208,
+	186,
+	208,
+	176,
+	208,
+	182,
+	208,
+	180,
+	209,
+	139,
+	208,
+	185,
+	208,
+	178,
+	208,
+	187,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	179,
+	209,
+	128,
+	209,
+	131,
+	208,
+	191,
+	208,
+	191,
+	209,
+	139,
+	208,
+	178,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	208,
+	176,
+	209,
+	129,
+	208,
+	186,
+	208,
+	176,
+	208,
+	183,
+	208,
+	176,
+	208,
+	187,
+	208,
+	191,
+	208,
+	181,
+	209,
+	128,
+	208,
+	178,
+	209,
+	139,
+	208,
+	185,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	180,
+	208,
+	181,
+	208,
+	189,
+	209,
+	140,
+	208,
+	179,
+	208,
+	184,
+	208,
+	191,
+	208,
+	181,
+	209,
+	128,
+	208,
+	184,
+	208,
+	190,
+	208,
+	180,
+	208,
+	177,
+	208,
+	184,
+	208,
+	183,
+	208,
+	189,
+	208,
+	181,
+	209,
+	129,
+	208,
+	190,
+	209,
+	129,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	208,
+	181,
+	208,
+	188,
+	208,
+	190,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	209,
+	130,
+	208,
+	186,
+	209,
+	131,
+	208,
+	191,
+	208,
+	184,
+	209,
+	130,
+	209,
+	140,
+	208,
+	180,
+	208,
+	190,
+	208,
+	187,
+	208,
+	182,
+	208,
+	189,
+	208,
+	176,
+	209,
+	128,
+	208,
+	176,
+	208,
+	188,
+	208,
+	186,
+	208,
+	176,
+	209,
+	133,
+	208,
+	189,
+	208,
+	176,
+	209,
+	135,
+	208,
+	176,
+	208,
+	187,
+	208,
+	190,
+	208,
+	160,
+	208,
+	176,
+	208,
+	177,
+	208,
+	190,
+	209,
+	130,
+	208,
+	176,
+	208,
+	162,
+	208,
+	190,
+	208,
+	187,
+	209,
+	140,
+	208,
+	186,
+	208,
+	190,
+	209,
+	129,
+	208,
+	190,
+	208,
+	178,
+	209,
+	129,
+	208,
+	181,
+	208,
+	188,
+	208,
+	178,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	185,
+	208,
+	189,
+	208,
+	176,
+	209,
+	135,
+	208,
+	176,
+	208,
+	187,
+	208,
+	176,
+	209,
+	129,
+	208,
+	191,
+	208,
+	184,
+	209,
+	129,
+	208,
+	190,
+	208,
+	186,
+	209,
+	129,
+	208,
+	187,
+	209,
+	131,
+	208,
+	182,
+	208,
+	177,
+	209,
+	139,
+	209,
+	129,
+	208,
+	184,
+	209,
+	129,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	208,
+	191,
+	208,
+	181,
+	209,
+	135,
+	208,
+	176,
+	209,
+	130,
+	208,
+	184,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	191,
+	208,
+	190,
+	208,
+	188,
+	208,
+	190,
+	209,
+	137,
+	208,
+	184,
+	209,
+	129,
+	208,
+	176,
+	208,
+	185,
+	209,
+	130,
+	208,
+	190,
+	208,
+	178,
+	208,
+	191,
+	208,
+	190,
+	209,
+	135,
+	208,
+	181,
+	208,
+	188,
+	209,
+	131,
+	208,
+	191,
+	208,
+	190,
+	208,
+	188,
+	208,
+	190,
+	209,
+	137,
+	209,
+	140,
+	208,
+	180,
+	208,
+	190,
+	208,
+	187,
+	208,
+	182,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	129,
+	209,
+	139,
+	208,
+	187,
+	208,
+	186,
+	208,
+	184,
+	208,
+	177,
+	209,
+	139,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	190,
+	208,
+	180,
+	208,
+	176,
+	208,
+	189,
+	208,
+	189,
+	209,
+	139,
+	208,
+	181,
+	208,
+	188,
+	208,
+	189,
+	208,
+	190,
+	208,
+	179,
+	208,
+	184,
+	208,
+	181,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	181,
+	208,
+	186,
+	209,
+	130,
+	208,
+	161,
+	208,
+	181,
+	208,
+	185,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	208,
+	188,
+	208,
+	190,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	184,
+	209,
+	130,
+	208,
+	176,
+	208,
+	186,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	208,
+	190,
+	208,
+	189,
+	208,
+	187,
+	208,
+	176,
+	208,
+	185,
+	208,
+	189,
+	208,
+	179,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	180,
+	208,
+	181,
+	208,
+	178,
+	208,
+	181,
+	209,
+	128,
+	209,
+	129,
+	208,
+	184,
+	209,
+	143,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	208,
+	189,
+	208,
+	181,
+	209,
+	132,
+	208,
+	184,
+	208,
+	187,
+	209,
+	140,
+	208,
+	188,
+	209,
+	139,
+	209,
+	131,
+	209,
+	128,
+	208,
+	190,
+	208,
+	178,
+	208,
+	189,
+	209,
+	143,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	189,
+	209,
+	139,
+	209,
+	133,
+	208,
+	184,
+	209,
+	129,
+	208,
+	186,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	189,
+	208,
+	181,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	209,
+	142,
+	209,
+	143,
+	208,
+	189,
+	208,
+	178,
+	208,
+	176,
+	209,
+	128,
+	209,
+	143,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	209,
+	140,
+	209,
+	136,
+	208,
+	181,
+	208,
+	188,
+	208,
+	189,
+	208,
+	190,
+	208,
+	179,
+	208,
+	184,
+	209,
+	133,
+	208,
+	180,
+	208,
+	176,
+	208,
+	189,
+	208,
+	189,
+	208,
+	190,
+	208,
+	185,
+	208,
+	183,
+	208,
+	189,
+	208,
+	176,
+	209,
+	135,
+	208,
+	184,
+	209,
+	130,
+	208,
+	189,
+	208,
+	181,
+	208,
+	187,
+	209,
+	140,
+	208,
+	183,
+	209,
+	143,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	209,
+	131,
+	208,
+	188,
+	208,
+	176,
+	208,
+	162,
+	208,
+	181,
+	208,
+	191,
+	208,
+	181,
+	209,
+	128,
+	209,
+	140,
+	208,
+	188,
+	208,
+	181,
+	209,
+	129,
+	209,
+	143,
+	209,
+	134,
+	208,
+	176,
+	208,
+	183,
+	208,
+	176,
+	209,
+	137,
+	208,
+	184,
+	209,
+	130,
+	209,
+	139,
+	208,
+	155,
+	209,
+	131,
+	209,
+	135,
+	209,
+	136,
+	208,
+	184,
+	208,
+	181,
+	224,
+	164,
+	168,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	130,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	133,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	133,
+	224,
+	164,
+	168,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	135,
+	224,
+	164,
+	161,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	184,
+	224,
+	165,
+	128,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	185,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	191,
+	224,
+	164,
+	130,
+	224,
+	164,
+	185,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	164,
+	133,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	172,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	164,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	135,
+	224,
+	164,
+	159,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	164,
+	159,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	175,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	129,
+	224,
+	164,
+	184,
+	224,
+	164,
+	172,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	183,
+	224,
+	164,
+	190,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	165,
+	130,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	152,
+	224,
+	164,
+	130,
+	224,
+	164,
+	159,
+	224,
+	165,
+	135,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	133,
+	224,
+	164,
+	167,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	133,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	174,
+	224,
+	165,
+	129,
+	224,
+	164,
+	157,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	163,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	165,
+	128,
+	224,
+	164,
+	175,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	159,
+	224,
+	164,
+	178,
+	224,
+	164,
+	182,
+	224,
+	164,
+	172,
+	224,
+	165,
+	141,
+	224,
+	164,
+	166,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	164,
+	168,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	165,
+	136,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	170,
+	224,
+	165,
+	130,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	165,
+	128,
+	224,
+	164,
+	137,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	165,
+	128,
+	224,
+	164,
+	172,
+	224,
+	165,
+	136,
+	224,
+	164,
+	160,
+	224,
+	164,
+	149,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	149,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	183,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	181,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	149,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	164,
+	164,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	165,
+	128,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	165,
+	130,
+	224,
+	164,
+	166,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	156,
+	224,
+	164,
+	184,
+	224,
+	165,
+	130,
+	224,
+	164,
+	154,
+	224,
+	165,
+	128,
+	224,
+	164,
+	170,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	164,
+	184,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	156,
+	224,
+	165,
+	136,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	184,
+	224,
+	164,
+	156,
+	224,
+	164,
+	168,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	152,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	178,
+	224,
+	164,
+	156,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	165,
+	128,
+	224,
+	164,
+	154,
+	224,
+	165,
+	135,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	170,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	151,
+	224,
+	165,
+	130,
+	224,
+	164,
+	151,
+	224,
+	164,
+	178,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	176,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	168,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	165,
+	129,
+	224,
+	164,
+	172,
+	224,
+	164,
+	185,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	185,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	172,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	165,
+	135,
+	224,
+	164,
+	152,
+	224,
+	164,
+	159,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	182,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	182,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	172,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	165,
+	128,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	136,
+	224,
+	164,
+	159,
+	224,
+	164,
+	182,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	166,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	170,
+	224,
+	164,
+	159,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	150,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	164,
+	149,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	137,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	178,
+	224,
+	164,
+	178,
+	224,
+	164,
+	151,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	150,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	133,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	156,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	185,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	224,
+	164,
+	168,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	174,
+	224,
+	164,
+	172,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	172,
+	224,
+	165,
+	136,
+	224,
+	164,
+	130,
+	224,
+	164,
+	149,
+	224,
+	164,
+	149,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	130,
+	224,
+	164,
+	149,
+	224,
+	164,
+	185,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	171,
+	224,
+	165,
+	128,
+	224,
+	164,
+	156,
+	224,
+	164,
+	172,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	164,
+	181,
+	224,
+	164,
+	185,
+	224,
+	165,
+	128,
+	224,
+	164,
+	130,
+	224,
+	164,
+	176,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	164,
+	188,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	224,
+	164,
+	134,
+	224,
+	164,
+	176,
+	224,
+	165,
+	139,
+	224,
+	164,
+	170,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	181,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	172,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	172,
+	224,
+	164,
+	170,
+	224,
+	165,
+	130,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	172,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	165,
+	140,
+	224,
+	164,
+	166,
+	224,
+	164,
+	190,
+	224,
+	164,
+	182,
+	224,
+	165,
+	135,
+	224,
+	164,
+	175,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	133,
+	224,
+	164,
+	149,
+	224,
+	164,
+	184,
+	224,
+	164,
+	176,
+	224,
+	164,
+	172,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	143,
+	224,
+	164,
+	181,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	178,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	149,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	183,
+	224,
+	164,
+	175,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	130,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	165,
+	130,
+	224,
+	164,
+	185,
+	224,
+	164,
+	165,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	216,
+	170,
+	216,
+	179,
+	216,
+	170,
+	216,
+	183,
+	217,
+	138,
+	216,
+	185,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	169,
+	216,
+	168,
+	217,
+	136,
+	216,
+	167,
+	216,
+	179,
+	216,
+	183,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	217,
+	129,
+	216,
+	173,
+	216,
+	169,
+	217,
+	133,
+	217,
+	136,
+	216,
+	167,
+	216,
+	182,
+	217,
+	138,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	174,
+	216,
+	167,
+	216,
+	181,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	178,
+	217,
+	138,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	217,
+	133,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	175,
+	217,
+	136,
+	216,
+	175,
+	216,
+	168,
+	216,
+	177,
+	217,
+	134,
+	216,
+	167,
+	217,
+	133,
+	216,
+	172,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	136,
+	217,
+	132,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	136,
+	217,
+	130,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	177,
+	216,
+	168,
+	217,
+	138,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	216,
+	177,
+	217,
+	138,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	217,
+	136,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	176,
+	217,
+	135,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	173,
+	217,
+	138,
+	216,
+	167,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	173,
+	217,
+	130,
+	217,
+	136,
+	217,
+	130,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	216,
+	177,
+	217,
+	138,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	177,
+	216,
+	167,
+	217,
+	130,
+	217,
+	133,
+	216,
+	173,
+	217,
+	129,
+	217,
+	136,
+	216,
+	184,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	171,
+	216,
+	167,
+	217,
+	134,
+	217,
+	138,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	217,
+	135,
+	216,
+	175,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	177,
+	216,
+	163,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	130,
+	216,
+	177,
+	216,
+	162,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	216,
+	180,
+	216,
+	168,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	173,
+	217,
+	136,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	216,
+	175,
+	217,
+	138,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	179,
+	216,
+	177,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	217,
+	132,
+	217,
+	136,
+	217,
+	133,
+	217,
+	133,
+	216,
+	172,
+	217,
+	133,
+	217,
+	136,
+	216,
+	185,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	173,
+	217,
+	133,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	217,
+	134,
+	217,
+	130,
+	216,
+	167,
+	216,
+	183,
+	217,
+	129,
+	217,
+	132,
+	216,
+	179,
+	216,
+	183,
+	217,
+	138,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	217,
+	136,
+	217,
+	138,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	134,
+	217,
+	138,
+	216,
+	167,
+	216,
+	168,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	217,
+	138,
+	216,
+	167,
+	216,
+	182,
+	216,
+	170,
+	216,
+	173,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	217,
+	138,
+	216,
+	168,
+	216,
+	170,
+	217,
+	136,
+	217,
+	130,
+	217,
+	138,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	217,
+	136,
+	217,
+	132,
+	217,
+	137,
+	216,
+	167,
+	217,
+	132,
+	216,
+	168,
+	216,
+	177,
+	217,
+	138,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	167,
+	216,
+	168,
+	216,
+	183,
+	216,
+	167,
+	217,
+	132,
+	216,
+	180,
+	216,
+	174,
+	216,
+	181,
+	217,
+	138,
+	216,
+	179,
+	217,
+	138,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	171,
+	216,
+	167,
+	217,
+	132,
+	216,
+	171,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	217,
+	132,
+	216,
+	167,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	173,
+	216,
+	175,
+	217,
+	138,
+	216,
+	171,
+	216,
+	167,
+	217,
+	132,
+	216,
+	178,
+	217,
+	136,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	216,
+	174,
+	217,
+	132,
+	217,
+	138,
+	216,
+	172,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	217,
+	133,
+	217,
+	138,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	217,
+	133,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	216,
+	167,
+	216,
+	185,
+	216,
+	169,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	217,
+	135,
+	216,
+	175,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	166,
+	217,
+	138,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	216,
+	174,
+	217,
+	136,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	217,
+	129,
+	217,
+	134,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	216,
+	170,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	136,
+	216,
+	177,
+	217,
+	138,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	216,
+	177,
+	217,
+	136,
+	216,
+	179,
+	216,
+	167,
+	216,
+	179,
+	216,
+	170,
+	216,
+	186,
+	216,
+	177,
+	217,
+	130,
+	216,
+	170,
+	216,
+	181,
+	216,
+	167,
+	217,
+	133,
+	217,
+	138,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	168,
+	217,
+	134,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	184,
+	217,
+	138,
+	217,
+	133,
+	101,
+	110,
+	116,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	109,
+	101,
+	110,
+	116,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	61,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	46,
+	106,
+	112,
+	103,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	99,
+	111,
+	110,
+	102,
+	105,
+	103,
+	117,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	112,
+	110,
+	103,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	60,
+	98,
+	111,
+	100,
+	121,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	77,
+	97,
+	116,
+	104,
+	46,
+	114,
+	97,
+	110,
+	100,
+	111,
+	109,
+	40,
+	41,
+	99,
+	111,
+	110,
+	116,
+	101,
+	109,
+	112,
+	111,
+	114,
+	97,
+	114,
+	121,
+	32,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	32,
+	83,
+	116,
+	97,
+	116,
+	101,
+	115,
+	99,
+	105,
+	114,
+	99,
+	117,
+	109,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	115,
+	46,
+	97,
+	112,
+	112,
+	101,
+	110,
+	100,
+	67,
+	104,
+	105,
+	108,
+	100,
+	40,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	34,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	117,
+	105,
+	115,
+	104,
+	101,
+	100,
+	116,
+	104,
+	111,
+	117,
+	115,
+	97,
+	110,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	108,
+	101,
+	97,
+	114,
+	34,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	46,
+	105,
+	99,
+	111,
+	34,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	114,
+	105,
+	103,
+	104,
+	116,
+	58,
+	98,
+	97,
+	115,
+	101,
+	100,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	77,
+	97,
+	115,
+	115,
+	97,
+	99,
+	104,
+	117,
+	115,
+	101,
+	116,
+	116,
+	115,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	108,
+	115,
+	111,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	112,
+	114,
+	111,
+	110,
+	117,
+	110,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	35,
+	102,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	45,
+	108,
+	101,
+	102,
+	116,
+	58,
+	70,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	32,
+	109,
+	105,
+	115,
+	99,
+	101,
+	108,
+	108,
+	97,
+	110,
+	101,
+	111,
+	117,
+	115,
+	38,
+	108,
+	116,
+	59,
+	47,
+	109,
+	97,
+	116,
+	104,
+	38,
+	103,
+	116,
+	59,
+	112,
+	115,
+	121,
+	99,
+	104,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	105,
+	110,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	101,
+	97,
+	114,
+	99,
+	104,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	102,
+	111,
+	114,
+	109,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	97,
+	115,
+	32,
+	111,
+	112,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	83,
+	117,
+	112,
+	114,
+	101,
+	109,
+	101,
+	32,
+	67,
+	111,
+	117,
+	114,
+	116,
+	111,
+	99,
+	99,
+	97,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	32,
+	65,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	44,
+	78,
+	111,
+	114,
+	116,
+	104,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	112,
+	120,
+	59,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	111,
+	112,
+	112,
+	111,
+	114,
+	116,
+	117,
+	110,
+	105,
+	116,
+	105,
+	101,
+	115,
+	69,
+	110,
+	116,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	109,
+	101,
+	110,
+	116,
+	46,
+	116,
+	111,
+	76,
+	111,
+	119,
+	101,
+	114,
+	67,
+	97,
+	115,
+	101,
+	40,
+	109,
+	97,
+	110,
+	117,
+	102,
+	97,
+	99,
+	116,
+	117,
+	114,
+	105,
+	110,
+	103,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	70,
+	111,
+	114,
+	32,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	44,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	34,
+	32,
+	109,
+	97,
+	120,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	61,
+	34,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	59,
+	99,
+	111,
+	110,
+	115,
+	99,
+	105,
+	111,
+	117,
+	115,
+	110,
+	101,
+	115,
+	115,
+	77,
+	101,
+	100,
+	105,
+	116,
+	101,
+	114,
+	114,
+	97,
+	110,
+	101,
+	97,
+	110,
+	101,
+	120,
+	116,
+	114,
+	97,
+	111,
+	114,
+	100,
+	105,
+	110,
+	97,
+	114,
+	121,
+	97,
+	115,
+	115,
+	97,
+	115,
+	115,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	117,
+	98,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	32,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	32,
+	99,
+	111,
+	109,
+	112,
+	114,
+	101,
+	104,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	114,
+	101,
+	102,
+	101,
+	114,
+	115,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	117,
+	108,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	105,
+	99,
+	97,
+	108,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	104,
+	114,
+	101,
+	102,
+	119,
+	97,
+	115,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	83,
+	97,
+	110,
+	32,
+	70,
+	114,
+	97,
+	110,
+	99,
+	105,
+	115,
+	99,
+	111,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	109,
+	97,
+	105,
+	110,
+	115,
+	111,
+	112,
+	104,
+	105,
+	115,
+	116,
+	105,
+	99,
+	97,
+	116,
+	101,
+	100,
+	109,
+	97,
+	116,
+	104,
+	101,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	99,
+	111,
+	110,
+	99,
+	101,
+	110,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	112,
+	115,
+	109,
+	97,
+	121,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	40,
+	102,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	84,
+	104,
+	105,
+	115,
+	32,
+	97,
+	114,
+	116,
+	105,
+	99,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	99,
+	97,
+	115,
+	101,
+	115,
+	112,
+	97,
+	114,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	101,
+	102,
+	105,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	71,
+	114,
+	101,
+	97,
+	116,
+	32,
+	66,
+	114,
+	105,
+	116,
+	97,
+	105,
+	110,
+	32,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	61,
+	101,
+	113,
+	117,
+	105,
+	118,
+	97,
+	108,
+	101,
+	110,
+	116,
+	32,
+	116,
+	111,
+	112,
+	108,
+	97,
+	99,
+	101,
+	104,
+	111,
+	108,
+	100,
+	101,
+	114,
+	61,
+	34,
+	59,
+	32,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	32,
+	106,
+	117,
+	115,
+	116,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	117,
+	102,
+	102,
+	101,
+	114,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	108,
+	101,
+	97,
+	100,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	32,
+	123,
+	97,
+	114,
+	101,
+	32,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	10,
+	9,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	32,
+	115,
+	114,
+	99,
+	61,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	99,
+	111,
+	110,
+	118,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	34,
+	32,
+	47,
+	62,
+	60,
+	47,
+	97,
+	114,
+	101,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	104,
+	97,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	98,
+	101,
+	101,
+	110,
+	109,
+	111,
+	115,
+	116,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	32,
+	99,
+	111,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	105,
+	110,
+	103,
+	99,
+	114,
+	101,
+	100,
+	105,
+	116,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	58,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	46,
+	103,
+	105,
+	102,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	60,
+	105,
+	102,
+	114,
+	97,
+	109,
+	101,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	45,
+	98,
+	108,
+	111,
+	99,
+	107,
+	59,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	111,
+	103,
+	101,
+	116,
+	104,
+	101,
+	114,
+	32,
+	119,
+	105,
+	116,
+	104,
+	97,
+	112,
+	112,
+	114,
+	111,
+	120,
+	105,
+	109,
+	97,
+	116,
+	101,
+	108,
+	121,
+	112,
+	97,
+	114,
+	108,
+	105,
+	97,
+	109,
+	101,
+	110,
+	116,
+	97,
+	114,
+	121,
+	109,
+	111,
+	114,
+	101,
+	32,
+	97,
+	110,
+	100,
+	32,
+	109,
+	111,
+	114,
+	101,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	112,
+	114,
+	101,
+	100,
+	111,
+	109,
+	105,
+	110,
+	97,
+	110,
+	116,
+	108,
+	121,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	124,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	32,
+	99,
+	101,
+	108,
+	108,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	111,
+	114,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	118,
+	101,
+	114,
+	115,
+	105,
+	97,
+	108,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	121,
+	61,
+	34,
+	111,
+	103,
+	58,
+	47,
+	120,
+	45,
+	115,
+	104,
+	111,
+	99,
+	107,
+	119,
+	97,
+	118,
+	101,
+	45,
+	100,
+	101,
+	109,
+	111,
+	110,
+	115,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	117,
+	114,
+	114,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	32,
+	98,
+	121,
+	78,
+	101,
+	118,
+	101,
+	114,
+	116,
+	104,
+	101,
+	108,
+	101,
+	115,
+	115,
+	44,
+	119,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	97,
+	98,
+	108,
+	101,
+	32,
+	65,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	108,
+	108,
+	97,
+	98,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	111,
+	117,
+	108,
+	100,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	112,
+	114,
+	111,
+	112,
+	111,
+	114,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	104,
+	111,
+	114,
+	116,
+	108,
+	121,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	102,
+	111,
+	114,
+	32,
+	105,
+	110,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	44,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	100,
+	32,
+	97,
+	115,
+	32,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	32,
+	115,
+	116,
+	97,
+	114,
+	116,
+	105,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	105,
+	110,
+	103,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	97,
+	99,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	100,
+	105,
+	115,
+	99,
+	117,
+	115,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	109,
+	105,
+	100,
+	100,
+	108,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	32,
+	105,
+	110,
+	100,
+	105,
+	118,
+	105,
+	100,
+	117,
+	97,
+	108,
+	100,
+	105,
+	102,
+	102,
+	105,
+	99,
+	117,
+	108,
+	116,
+	32,
+	116,
+	111,
+	32,
+	112,
+	111,
+	105,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	118,
+	105,
+	101,
+	119,
+	104,
+	111,
+	109,
+	111,
+	115,
+	101,
+	120,
+	117,
+	97,
+	108,
+	105,
+	116,
+	121,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	97,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	109,
+	97,
+	110,
+	117,
+	102,
+	97,
+	99,
+	116,
+	117,
+	114,
+	101,
+	114,
+	115,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	108,
+	121,
+	32,
+	117,
+	115,
+	101,
+	100,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	100,
+	101,
+	110,
+	111,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	32,
+	35,
+	108,
+	101,
+	110,
+	103,
+	116,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	32,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	116,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	62,
+	114,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	108,
+	101,
+	115,
+	32,
+	111,
+	102,
+	105,
+	115,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	119,
+	97,
+	115,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	73,
+	110,
+	100,
+	111,
+	45,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	97,
+	110,
+	118,
+	117,
+	108,
+	110,
+	101,
+	114,
+	97,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	97,
+	114,
+	101,
+	32,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	99,
+	108,
+	111,
+	115,
+	101,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	78,
+	101,
+	119,
+	32,
+	89,
+	111,
+	114,
+	107,
+	32,
+	67,
+	105,
+	116,
+	121,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	97,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	99,
+	111,
+	117,
+	114,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	109,
+	97,
+	116,
+	104,
+	101,
+	109,
+	97,
+	116,
+	105,
+	99,
+	105,
+	97,
+	110,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	116,
+	101,
+	99,
+	104,
+	110,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	46,
+	114,
+	101,
+	109,
+	111,
+	118,
+	101,
+	67,
+	108,
+	97,
+	115,
+	115,
+	40,
+	98,
+	114,
+	97,
+	110,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	118,
+	105,
+	100,
+	101,
+	110,
+	99,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	13,
+	10,
+	73,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	32,
+	111,
+	102,
+	32,
+	105,
+	110,
+	116,
+	111,
+	32,
+	97,
+	32,
+	115,
+	105,
+	110,
+	103,
+	108,
+	101,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	46,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	102,
+	111,
+	114,
+	101,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	105,
+	115,
+	32,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	84,
+	104,
+	101,
+	114,
+	101,
+	32,
+	105,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	97,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	32,
+	38,
+	97,
+	109,
+	112,
+	59,
+	110,
+	100,
+	97,
+	115,
+	104,
+	59,
+	32,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	115,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	108,
+	121,
+	101,
+	113,
+	117,
+	105,
+	112,
+	112,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	100,
+	111,
+	101,
+	115,
+	32,
+	110,
+	111,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	60,
+	47,
+	97,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	99,
+	111,
+	110,
+	102,
+	117,
+	115,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	84,
+	104,
+	101,
+	115,
+	101,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	108,
+	101,
+	115,
+	115,
+	32,
+	111,
+	102,
+	99,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	116,
+	105,
+	109,
+	101,
+	115,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	32,
+	116,
+	104,
+	101,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	105,
+	101,
+	115,
+	112,
+	101,
+	114,
+	99,
+	101,
+	110,
+	116,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	97,
+	99,
+	99,
+	101,
+	115,
+	115,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	32,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	32,
+	116,
+	111,
+	112,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	47,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	116,
+	119,
+	111,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	98,
+	101,
+	108,
+	111,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	109,
+	101,
+	110,
+	116,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	34,
+	32,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	32,
+	116,
+	104,
+	101,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	32,
+	102,
+	111,
+	114,
+	65,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	119,
+	105,
+	100,
+	101,
+	32,
+	114,
+	97,
+	110,
+	103,
+	101,
+	32,
+	111,
+	102,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	111,
+	114,
+	101,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	108,
+	121,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	115,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	116,
+	121,
+	119,
+	97,
+	115,
+	32,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	100,
+	32,
+	38,
+	97,
+	109,
+	112,
+	59,
+	109,
+	100,
+	97,
+	115,
+	104,
+	59,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	105,
+	111,
+	110,
+	116,
+	104,
+	101,
+	32,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	97,
+	110,
+	32,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	115,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	102,
+	97,
+	99,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	32,
+	111,
+	102,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	116,
+	108,
+	121,
+	111,
+	110,
+	109,
+	111,
+	117,
+	115,
+	101,
+	111,
+	118,
+	101,
+	114,
+	61,
+	34,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	116,
+	104,
+	101,
+	121,
+	32,
+	97,
+	115,
+	121,
+	110,
+	99,
+	32,
+	61,
+	32,
+	116,
+	114,
+	117,
+	101,
+	59,
+	112,
+	114,
+	111,
+	98,
+	108,
+	101,
+	109,
+	115,
+	32,
+	119,
+	105,
+	116,
+	104,
+	115,
+	101,
+	101,
+	109,
+	115,
+	32,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	32,
+	111,
+	102,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	102,
+	97,
+	109,
+	105,
+	108,
+	105,
+	97,
+	114,
+	32,
+	119,
+	105,
+	116,
+	104,
+	112,
+	111,
+	115,
+	115,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	40,
+	41,
+	32,
+	123,
+	116,
+	111,
+	111,
+	107,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	105,
+	110,
+	97,
+	110,
+	100,
+	32,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	60,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	105,
+	115,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	105,
+	110,
+	32,
+	97,
+	110,
+	32,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	103,
+	114,
+	101,
+	97,
+	116,
+	32,
+	100,
+	101,
+	97,
+	108,
+	32,
+	111,
+	102,
+	69,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	117,
+	108,
+	108,
+	121,
+	32,
+	118,
+	105,
+	114,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	32,
+	97,
+	108,
+	108,
+	50,
+	48,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	44,
+	112,
+	114,
+	111,
+	102,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	115,
+	110,
+	101,
+	99,
+	101,
+	115,
+	115,
+	97,
+	114,
+	121,
+	32,
+	116,
+	111,
+	32,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	100,
+	32,
+	98,
+	121,
+	99,
+	111,
+	109,
+	112,
+	97,
+	116,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	68,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	32,
+	111,
+	102,
+	109,
+	111,
+	100,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	84,
+	104,
+	101,
+	32,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	109,
+	97,
+	121,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	32,
+	116,
+	111,
+	58,
+	67,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	44,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	115,
+	111,
+	109,
+	101,
+	116,
+	104,
+	97,
+	116,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	98,
+	101,
+	119,
+	111,
+	114,
+	108,
+	100,
+	39,
+	115,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	102,
+	105,
+	101,
+	100,
+	32,
+	97,
+	115,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	40,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	108,
+	101,
+	102,
+	116,
+	34,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	108,
+	121,
+	98,
+	97,
+	115,
+	105,
+	115,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	102,
+	111,
+	117,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	99,
+	111,
+	110,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	115,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	111,
+	32,
+	114,
+	101,
+	100,
+	117,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	106,
+	117,
+	114,
+	105,
+	115,
+	100,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	97,
+	112,
+	112,
+	114,
+	111,
+	120,
+	105,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	110,
+	109,
+	111,
+	117,
+	115,
+	101,
+	111,
+	117,
+	116,
+	61,
+	34,
+	78,
+	101,
+	119,
+	32,
+	84,
+	101,
+	115,
+	116,
+	97,
+	109,
+	101,
+	110,
+	116,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	102,
+	105,
+	108,
+	109,
+	32,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	45,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	46,
+	100,
+	116,
+	100,
+	34,
+	62,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	105,
+	115,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	98,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	117,
+	110,
+	112,
+	114,
+	101,
+	99,
+	101,
+	100,
+	101,
+	110,
+	116,
+	101,
+	100,
+	105,
+	115,
+	32,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	32,
+	105,
+	110,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	98,
+	111,
+	108,
+	100,
+	59,
+	105,
+	115,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	114,
+	101,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	9,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	97,
+	114,
+	101,
+	32,
+	116,
+	121,
+	112,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	99,
+	111,
+	110,
+	102,
+	108,
+	105,
+	99,
+	116,
+	32,
+	119,
+	105,
+	116,
+	104,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	101,
+	32,
+	65,
+	110,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	32,
+	111,
+	102,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	113,
+	117,
+	97,
+	110,
+	116,
+	105,
+	116,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	114,
+	97,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	97,
+	99,
+	111,
+	110,
+	115,
+	116,
+	101,
+	108,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	110,
+	101,
+	99,
+	101,
+	115,
+	115,
+	97,
+	114,
+	121,
+	32,
+	102,
+	111,
+	114,
+	114,
+	101,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	97,
+	110,
+	100,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	32,
+	116,
+	111,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	32,
+	121,
+	101,
+	97,
+	114,
+	71,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	104,
+	97,
+	118,
+	101,
+	32,
+	110,
+	111,
+	116,
+	32,
+	98,
+	101,
+	101,
+	110,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	99,
+	111,
+	109,
+	109,
+	105,
+	116,
+	109,
+	101,
+	110,
+	116,
+	32,
+	116,
+	111,
+	9,
+	9,
+	60,
+	117,
+	108,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	118,
+	105,
+	115,
+	117,
+	97,
+	108,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	49,
+	57,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	44,
+	112,
+	114,
+	97,
+	99,
+	116,
+	105,
+	116,
+	105,
+	111,
+	110,
+	101,
+	114,
+	115,
+	116,
+	104,
+	97,
+	116,
+	32,
+	104,
+	101,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	97,
+	110,
+	100,
+	32,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	101,
+	100,
+	111,
+	99,
+	99,
+	117,
+	112,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	115,
+	32,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	32,
+	97,
+	115,
+	99,
+	101,
+	110,
+	116,
+	114,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	97,
+	109,
+	111,
+	117,
+	110,
+	116,
+	32,
+	111,
+	102,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	101,
+	113,
+	117,
+	105,
+	118,
+	97,
+	108,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	105,
+	97,
+	116,
+	101,
+	98,
+	114,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	97,
+	98,
+	111,
+	117,
+	116,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	108,
+	101,
+	102,
+	116,
+	58,
+	32,
+	97,
+	117,
+	116,
+	111,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	111,
+	102,
+	32,
+	97,
+	115,
+	83,
+	111,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	105,
+	115,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	100,
+	117,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	110,
+	100,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	100,
+	32,
+	98,
+	121,
+	114,
+	101,
+	112,
+	117,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	115,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	97,
+	99,
+	99,
+	111,
+	109,
+	109,
+	111,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	108,
+	97,
+	114,
+	103,
+	101,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	73,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	101,
+	32,
+	102,
+	111,
+	114,
+	116,
+	104,
+	101,
+	32,
+	115,
+	111,
+	45,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	97,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	73,
+	110,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	99,
+	97,
+	115,
+	101,
+	44,
+	119,
+	97,
+	115,
+	32,
+	97,
+	112,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	100,
+	99,
+	108,
+	97,
+	105,
+	109,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	105,
+	115,
+	68,
+	101,
+	112,
+	97,
+	114,
+	116,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	109,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	32,
+	100,
+	101,
+	97,
+	108,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	97,
+	108,
+	109,
+	111,
+	115,
+	116,
+	32,
+	97,
+	108,
+	119,
+	97,
+	121,
+	115,
+	97,
+	114,
+	101,
+	32,
+	99,
+	117,
+	114,
+	114,
+	101,
+	110,
+	116,
+	108,
+	121,
+	101,
+	120,
+	112,
+	114,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	121,
+	32,
+	111,
+	102,
+	102,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	99,
+	105,
+	118,
+	105,
+	108,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	115,
+	108,
+	97,
+	110,
+	100,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	73,
+	110,
+	100,
+	101,
+	120,
+	99,
+	97,
+	110,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	32,
+	105,
+	110,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	34,
+	32,
+	47,
+	62,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	32,
+	47,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	77,
+	97,
+	110,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	116,
+	114,
+	97,
+	99,
+	101,
+	100,
+	105,
+	115,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	105,
+	115,
+	32,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	108,
+	105,
+	118,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	111,
+	114,
+	101,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	70,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	82,
+	101,
+	118,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	121,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	32,
+	105,
+	110,
+	105,
+	115,
+	32,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	32,
+	105,
+	110,
+	115,
+	117,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	32,
+	116,
+	111,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	34,
+	62,
+	115,
+	104,
+	111,
+	114,
+	116,
+	32,
+	115,
+	116,
+	111,
+	114,
+	105,
+	101,
+	115,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	97,
+	115,
+	32,
+	116,
+	111,
+	32,
+	119,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	102,
+	111,
+	114,
+	32,
+	105,
+	116,
+	115,
+	119,
+	97,
+	115,
+	32,
+	105,
+	110,
+	105,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	98,
+	108,
+	111,
+	99,
+	107,
+	105,
+	115,
+	32,
+	97,
+	110,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	105,
+	110,
+	99,
+	105,
+	112,
+	97,
+	108,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	97,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	122,
+	101,
+	100,
+	32,
+	97,
+	115,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	97,
+	32,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	97,
+	108,
+	114,
+	101,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	101,
+	100,
+	104,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	32,
+	115,
+	116,
+	97,
+	116,
+	101,
+	114,
+	101,
+	115,
+	105,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	32,
+	116,
+	111,
+	117,
+	110,
+	100,
+	101,
+	114,
+	103,
+	114,
+	97,
+	100,
+	117,
+	97,
+	116,
+	101,
+	84,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	116,
+	119,
+	111,
+	103,
+	114,
+	97,
+	118,
+	105,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	114,
+	101,
+	32,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	100,
+	105,
+	110,
+	116,
+	101,
+	110,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	115,
+	101,
+	114,
+	118,
+	101,
+	100,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	111,
+	112,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	102,
+	117,
+	110,
+	100,
+	97,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	108,
+	121,
+	100,
+	111,
+	109,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	97,
+	108,
+	108,
+	105,
+	97,
+	110,
+	99,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	114,
+	99,
+	101,
+	100,
+	32,
+	116,
+	111,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	44,
+	97,
+	110,
+	100,
+	32,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	105,
+	110,
+	32,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	111,
+	102,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	50,
+	48,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	46,
+	97,
+	110,
+	100,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	108,
+	111,
+	97,
+	100,
+	67,
+	104,
+	97,
+	114,
+	116,
+	98,
+	101,
+	97,
+	116,
+	116,
+	111,
+	32,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	115,
+	116,
+	97,
+	116,
+	101,
+	115,
+	101,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	104,
+	97,
+	108,
+	102,
+	32,
+	111,
+	102,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	32,
+	97,
+	110,
+	100,
+	97,
+	114,
+	99,
+	104,
+	105,
+	116,
+	101,
+	99,
+	116,
+	117,
+	114,
+	97,
+	108,
+	98,
+	101,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	105,
+	122,
+	101,
+	100,
+	99,
+	108,
+	101,
+	97,
+	114,
+	73,
+	110,
+	116,
+	101,
+	114,
+	118,
+	97,
+	108,
+	97,
+	117,
+	116,
+	104,
+	111,
+	114,
+	105,
+	116,
+	97,
+	116,
+	105,
+	118,
+	101,
+	70,
+	101,
+	100,
+	101,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	119,
+	97,
+	115,
+	32,
+	115,
+	117,
+	99,
+	99,
+	101,
+	101,
+	100,
+	101,
+	100,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	97,
+	32,
+	99,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	116,
+	104,
+	101,
+	32,
+	80,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	97,
+	108,
+	115,
+	111,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	100,
+	102,
+	114,
+	101,
+	101,
+	32,
+	115,
+	111,
+	102,
+	116,
+	119,
+	97,
+	114,
+	101,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	119,
+	97,
+	115,
+	32,
+	100,
+	101,
+	115,
+	116,
+	114,
+	111,
+	121,
+	101,
+	100,
+	97,
+	119,
+	97,
+	121,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	121,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	97,
+	109,
+	111,
+	114,
+	101,
+	32,
+	112,
+	111,
+	119,
+	101,
+	114,
+	102,
+	117,
+	108,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	97,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	109,
+	97,
+	110,
+	121,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	115,
+	111,
+	109,
+	101,
+	105,
+	115,
+	32,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	116,
+	32,
+	116,
+	111,
+	117,
+	110,
+	116,
+	105,
+	108,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	100,
+	119,
+	97,
+	115,
+	32,
+	97,
+	110,
+	110,
+	111,
+	117,
+	110,
+	99,
+	101,
+	100,
+	97,
+	114,
+	101,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	97,
+	108,
+	115,
+	111,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	115,
+	62,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	116,
+	104,
+	101,
+	32,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	68,
+	79,
+	32,
+	78,
+	79,
+	84,
+	32,
+	65,
+	76,
+	84,
+	69,
+	82,
+	117,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	116,
+	104,
+	101,
+	109,
+	101,
+	115,
+	47,
+	63,
+	115,
+	111,
+	114,
+	116,
+	61,
+	116,
+	104,
+	97,
+	116,
+	32,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	116,
+	104,
+	101,
+	32,
+	98,
+	97,
+	115,
+	105,
+	115,
+	32,
+	102,
+	111,
+	114,
+	104,
+	97,
+	115,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	117,
+	109,
+	109,
+	101,
+	114,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	97,
+	116,
+	105,
+	118,
+	101,
+	108,
+	121,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	99,
+	104,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	111,
+	115,
+	101,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	105,
+	110,
+	103,
+	105,
+	115,
+	32,
+	105,
+	109,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	118,
+	97,
+	114,
+	105,
+	111,
+	117,
+	115,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	83,
+	111,
+	117,
+	116,
+	104,
+	32,
+	65,
+	102,
+	114,
+	105,
+	99,
+	97,
+	110,
+	104,
+	97,
+	118,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	110,
+	101,
+	115,
+	115,
+	105,
+	110,
+	32,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	99,
+	97,
+	115,
+	101,
+	59,
+	32,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	32,
+	97,
+	110,
+	100,
+	59,
+	32,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	98,
+	97,
+	104,
+	97,
+	115,
+	97,
+	32,
+	77,
+	101,
+	108,
+	97,
+	121,
+	117,
+	110,
+	111,
+	114,
+	115,
+	107,
+	32,
+	98,
+	111,
+	107,
+	109,
+	195,
+	165,
+	108,
+	110,
+	111,
+	114,
+	115,
+	107,
+	32,
+	110,
+	121,
+	110,
+	111,
+	114,
+	115,
+	107,
+	115,
+	108,
+	111,
+	118,
+	101,
+	110,
+	197,
+	161,
+	196,
+	141,
+	105,
+	110,
+	97,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	99,
+	105,
+	111,
+	110,
+	97,
+	108,
+	99,
+	97,
+	108,
+	105,
+	102,
+	105,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	99,
+	105,
+	195,
+	179,
+	110,
+	34,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	100,
+	105,
+	115,
+	97,
+	109,
+	98,
+	105,
+	103,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	68,
+	111,
+	109,
+	97,
+	105,
+	110,
+	78,
+	97,
+	109,
+	101,
+	39,
+	44,
+	32,
+	39,
+	97,
+	100,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	109,
+	117,
+	108,
+	116,
+	97,
+	110,
+	101,
+	111,
+	117,
+	115,
+	108,
+	121,
+	116,
+	114,
+	97,
+	110,
+	115,
+	112,
+	111,
+	114,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	58,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	10,
+	60,
+	47,
+	62,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	105,
+	109,
+	112,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	102,
+	114,
+	97,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	117,
+	114,
+	101,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	45,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	58,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	62,
+	61,
+	104,
+	116,
+	116,
+	112,
+	37,
+	51,
+	65,
+	37,
+	50,
+	70,
+	37,
+	50,
+	70,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	116,
+	34,
+	32,
+	47,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	46,
+	105,
+	99,
+	111,
+	34,
+	32,
+	125,
+	41,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	46,
+	115,
+	101,
+	116,
+	65,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	40,
+	65,
+	100,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	61,
+	32,
+	110,
+	101,
+	119,
+	32,
+	65,
+	114,
+	114,
+	97,
+	121,
+	40,
+	41,
+	59,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	13,
+	10,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	98,
+	108,
+	111,
+	99,
+	107,
+	59,
+	85,
+	110,
+	102,
+	111,
+	114,
+	116,
+	117,
+	110,
+	97,
+	116,
+	101,
+	108,
+	121,
+	44,
+	34,
+	62,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	47,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	46,
+	105,
+	99,
+	111,
+	34,
+	62,
+	61,
+	39,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	39,
+	32,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	44,
+	32,
+	102,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	97,
+	110,
+	32,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	97,
+	115,
+	32,
+	97,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	32,
+	111,
+	102,
+	112,
+	116,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	10,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	32,
+	123,
+	114,
+	101,
+	99,
+	111,
+	109,
+	109,
+	101,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	102,
+	111,
+	114,
+	109,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	47,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	46,
+	115,
+	116,
+	121,
+	108,
+	101,
+	46,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	32,
+	65,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	97,
+	108,
+	111,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	98,
+	111,
+	100,
+	121,
+	46,
+	97,
+	112,
+	112,
+	114,
+	111,
+	120,
+	105,
+	109,
+	97,
+	116,
+	101,
+	108,
+	121,
+	32,
+	67,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	112,
+	111,
+	115,
+	116,
+	34,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	109,
+	101,
+	97,
+	110,
+	105,
+	110,
+	103,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	45,
+	45,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	80,
+	114,
+	105,
+	109,
+	101,
+	32,
+	77,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	105,
+	115,
+	116,
+	105,
+	99,
+	60,
+	47,
+	97,
+	62,
+	32,
+	60,
+	97,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	116,
+	104,
+	101,
+	32,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	111,
+	110,
+	109,
+	111,
+	117,
+	115,
+	101,
+	111,
+	118,
+	101,
+	114,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	47,
+	47,
+	119,
+	97,
+	115,
+	32,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	119,
+	97,
+	115,
+	32,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	118,
+	101,
+	97,
+	114,
+	101,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	10,
+	10,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	115,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	32,
+	105,
+	110,
+	32,
+	99,
+	111,
+	110,
+	116,
+	114,
+	97,
+	115,
+	116,
+	32,
+	116,
+	111,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	104,
+	111,
+	108,
+	100,
+	101,
+	114,
+	61,
+	34,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	97,
+	115,
+	101,
+	32,
+	111,
+	102,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	99,
+	111,
+	110,
+	115,
+	116,
+	105,
+	116,
+	117,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	45,
+	58,
+	32,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	32,
+	123,
+	66,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	45,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	46,
+	100,
+	116,
+	100,
+	34,
+	62,
+	10,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	97,
+	99,
+	99,
+	111,
+	109,
+	112,
+	97,
+	110,
+	105,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	99,
+	99,
+	111,
+	117,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	47,
+	110,
+	97,
+	116,
+	117,
+	114,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	32,
+	105,
+	110,
+	32,
+	105,
+	110,
+	32,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	115,
+	41,
+	59,
+	32,
+	106,
+	115,
+	46,
+	105,
+	100,
+	32,
+	61,
+	32,
+	105,
+	100,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	48,
+	48,
+	37,
+	34,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	82,
+	111,
+	109,
+	97,
+	110,
+	32,
+	67,
+	97,
+	116,
+	104,
+	111,
+	108,
+	105,
+	99,
+	97,
+	110,
+	32,
+	105,
+	110,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	101,
+	110,
+	116,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	46,
+	103,
+	105,
+	102,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	100,
+	105,
+	115,
+	99,
+	114,
+	105,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	114,
+	99,
+	104,
+	97,
+	101,
+	111,
+	108,
+	111,
+	103,
+	105,
+	99,
+	97,
+	108,
+	112,
+	114,
+	105,
+	109,
+	101,
+	32,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	119,
+	46,
+	97,
+	116,
+	116,
+	97,
+	99,
+	104,
+	69,
+	118,
+	101,
+	110,
+	116,
+	40,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	116,
+	100,
+	62,
+	60,
+	47,
+	116,
+	114,
+	62,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	47,
+	47,
+	97,
+	73,
+	110,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	44,
+	32,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	108,
+	101,
+	102,
+	116,
+	34,
+	32,
+	67,
+	122,
+	101,
+	99,
+	104,
+	32,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	32,
+	75,
+	105,
+	110,
+	103,
+	100,
+	111,
+	109,
+	99,
+	111,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	101,
+	110,
+	99,
+	101,
+	99,
+	111,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	46,
+	104,
+	116,
+	109,
+	108,
+	34,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	40,
+	41,
+	32,
+	123,
+	99,
+	111,
+	109,
+	101,
+	115,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	39,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	60,
+	47,
+	97,
+	62,
+	10,
+	60,
+	47,
+	108,
+	105,
+	62,
+	10,
+	60,
+	108,
+	105,
+	118,
+	101,
+	114,
+	121,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	40,
+	97,
+	108,
+	115,
+	111,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	9,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	62,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	97,
+	115,
+	32,
+	118,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	116,
+	111,
+	112,
+	34,
+	62,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	116,
+	116,
+	101,
+	109,
+	112,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	99,
+	97,
+	114,
+	98,
+	111,
+	110,
+	32,
+	100,
+	105,
+	111,
+	120,
+	105,
+	100,
+	101,
+	10,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	45,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	111,
+	112,
+	112,
+	111,
+	114,
+	116,
+	117,
+	110,
+	105,
+	116,
+	121,
+	32,
+	116,
+	111,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	13,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	84,
+	105,
+	225,
+	186,
+	191,
+	110,
+	103,
+	32,
+	86,
+	105,
+	225,
+	187,
+	135,
+	116,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	115,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	45,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	48,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	119,
+	97,
+	115,
+	32,
+	100,
+	105,
+	115,
+	99,
+	111,
+	118,
+	101,
+	114,
+	101,
+	100,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	41,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	10,
+	68,
+	101,
+	112,
+	97,
+	114,
+	116,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	101,
+	99,
+	99,
+	108,
+	101,
+	115,
+	105,
+	97,
+	115,
+	116,
+	105,
+	99,
+	97,
+	108,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	105,
+	110,
+	103,
+	32,
+	102,
+	114,
+	111,
+	109,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	104,
+	97,
+	115,
+	32,
+	110,
+	101,
+	118,
+	101,
+	114,
+	32,
+	98,
+	101,
+	101,
+	110,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	116,
+	105,
+	109,
+	101,
+	105,
+	110,
+	32,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	101,
+	32,
+	116,
+	111,
+	97,
+	117,
+	116,
+	111,
+	109,
+	97,
+	116,
+	105,
+	99,
+	97,
+	108,
+	108,
+	121,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	119,
+	97,
+	115,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	112,
+	101,
+	114,
+	99,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	34,
+	32,
+	47,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	100,
+	101,
+	115,
+	99,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	115,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	45,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	116,
+	111,
+	32,
+	98,
+	101,
+	32,
+	99,
+	111,
+	110,
+	102,
+	117,
+	115,
+	101,
+	100,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	45,
+	114,
+	105,
+	103,
+	104,
+	116,
+	58,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	32,
+	111,
+	114,
+	32,
+	110,
+	111,
+	116,
+	84,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	97,
+	108,
+	115,
+	111,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	32,
+	109,
+	97,
+	110,
+	121,
+	97,
+	32,
+	115,
+	109,
+	97,
+	108,
+	108,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	112,
+	97,
+	114,
+	116,
+	115,
+	32,
+	111,
+	102,
+	105,
+	109,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	32,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	46,
+	32,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	101,
+	97,
+	110,
+	100,
+	32,
+	101,
+	118,
+	101,
+	110,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	65,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	32,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	105,
+	116,
+	115,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	115,
+	32,
+	116,
+	104,
+	101,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	116,
+	34,
+	105,
+	116,
+	32,
+	105,
+	115,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	109,
+	111,
+	114,
+	101,
+	32,
+	108,
+	105,
+	107,
+	101,
+	108,
+	121,
+	32,
+	116,
+	111,
+	97,
+	110,
+	32,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	101,
+	32,
+	105,
+	110,
+	104,
+	97,
+	118,
+	101,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	98,
+	101,
+	101,
+	110,
+	99,
+	111,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	115,
+	32,
+	116,
+	111,
+	97,
+	110,
+	110,
+	111,
+	117,
+	110,
+	99,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	62,
+	109,
+	97,
+	110,
+	121,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	102,
+	111,
+	114,
+	32,
+	109,
+	97,
+	110,
+	121,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	101,
+	97,
+	114,
+	108,
+	105,
+	101,
+	115,
+	116,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	105,
+	116,
+	32,
+	119,
+	97,
+	115,
+	112,
+	116,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	32,
+	118,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	116,
+	111,
+	112,
+	34,
+	32,
+	105,
+	110,
+	104,
+	97,
+	98,
+	105,
+	116,
+	97,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	121,
+	101,
+	97,
+	114,
+	13,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	105,
+	108,
+	108,
+	105,
+	111,
+	110,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	118,
+	101,
+	114,
+	115,
+	105,
+	97,
+	108,
+	32,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	97,
+	114,
+	103,
+	117,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	32,
+	97,
+	110,
+	100,
+	97,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	32,
+	116,
+	111,
+	116,
+	114,
+	97,
+	110,
+	115,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	98,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	98,
+	101,
+	115,
+	116,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	102,
+	111,
+	114,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	109,
+	117,
+	108,
+	116,
+	105,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	109,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	111,
+	110,
+	101,
+	32,
+	114,
+	101,
+	99,
+	111,
+	103,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	67,
+	111,
+	117,
+	110,
+	99,
+	105,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	101,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	32,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	69,
+	110,
+	116,
+	101,
+	114,
+	116,
+	97,
+	105,
+	110,
+	109,
+	101,
+	110,
+	116,
+	32,
+	97,
+	119,
+	97,
+	121,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	32,
+	59,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	114,
+	105,
+	103,
+	104,
+	116,
+	58,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	32,
+	111,
+	102,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	105,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	99,
+	111,
+	110,
+	110,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	97,
+	110,
+	100,
+	32,
+	109,
+	97,
+	110,
+	121,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	97,
+	108,
+	116,
+	104,
+	111,
+	117,
+	103,
+	104,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	100,
+	101,
+	115,
+	99,
+	101,
+	110,
+	100,
+	97,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	32,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	114,
+	105,
+	103,
+	104,
+	116,
+	34,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	32,
+	97,
+	115,
+	112,
+	101,
+	99,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	115,
+	32,
+	115,
+	105,
+	110,
+	99,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	97,
+	110,
+	32,
+	85,
+	110,
+	105,
+	111,
+	110,
+	114,
+	101,
+	109,
+	105,
+	110,
+	105,
+	115,
+	99,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	109,
+	111,
+	114,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	105,
+	99,
+	117,
+	108,
+	116,
+	86,
+	105,
+	99,
+	101,
+	32,
+	80,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	99,
+	111,
+	109,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	112,
+	97,
+	115,
+	115,
+	101,
+	100,
+	32,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	109,
+	111,
+	114,
+	101,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	49,
+	49,
+	112,
+	120,
+	101,
+	120,
+	112,
+	108,
+	97,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	32,
+	111,
+	102,
+	119,
+	114,
+	105,
+	116,
+	116,
+	101,
+	110,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	9,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	115,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	115,
+	101,
+	109,
+	98,
+	108,
+	97,
+	110,
+	99,
+	101,
+	32,
+	116,
+	111,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	115,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	115,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	109,
+	101,
+	97,
+	110,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	111,
+	117,
+	116,
+	115,
+	105,
+	100,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	40,
+	77,
+	97,
+	116,
+	104,
+	46,
+	114,
+	97,
+	110,
+	100,
+	111,
+	109,
+	40,
+	41,
+	109,
+	111,
+	115,
+	116,
+	32,
+	112,
+	114,
+	111,
+	109,
+	105,
+	110,
+	101,
+	110,
+	116,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	67,
+	111,
+	110,
+	115,
+	116,
+	97,
+	110,
+	116,
+	105,
+	110,
+	111,
+	112,
+	108,
+	101,
+	119,
+	101,
+	114,
+	101,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	101,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	115,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	49,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	34,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	115,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	104,
+	97,
+	100,
+	32,
+	98,
+	101,
+	101,
+	110,
+	100,
+	101,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	10,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	32,
+	117,
+	115,
+	101,
+	100,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	116,
+	101,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	99,
+	108,
+	101,
+	97,
+	114,
+	58,
+	98,
+	13,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	105,
+	110,
+	116,
+	101,
+	114,
+	118,
+	105,
+	101,
+	119,
+	32,
+	119,
+	105,
+	116,
+	104,
+	95,
+	105,
+	100,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	99,
+	97,
+	112,
+	105,
+	116,
+	97,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	13,
+	10,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	114,
+	101,
+	108,
+	101,
+	97,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	112,
+	111,
+	105,
+	110,
+	116,
+	32,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	97,
+	116,
+	120,
+	77,
+	76,
+	72,
+	116,
+	116,
+	112,
+	82,
+	101,
+	113,
+	117,
+	101,
+	115,
+	116,
+	97,
+	110,
+	100,
+	32,
+	115,
+	117,
+	98,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	115,
+	116,
+	118,
+	101,
+	114,
+	121,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	115,
+	112,
+	101,
+	99,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	117,
+	114,
+	102,
+	97,
+	99,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	112,
+	112,
+	108,
+	105,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	102,
+	111,
+	114,
+	101,
+	105,
+	103,
+	110,
+	32,
+	112,
+	111,
+	108,
+	105,
+	99,
+	121,
+	95,
+	115,
+	101,
+	116,
+	68,
+	111,
+	109,
+	97,
+	105,
+	110,
+	78,
+	97,
+	109,
+	101,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	32,
+	105,
+	110,
+	105,
+	115,
+	32,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	100,
+	32,
+	116,
+	111,
+	73,
+	110,
+	32,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	109,
+	101,
+	97,
+	110,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	110,
+	97,
+	109,
+	101,
+	100,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	116,
+	111,
+	32,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	100,
+	68,
+	101,
+	99,
+	108,
+	97,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	109,
+	111,
+	114,
+	101,
+	32,
+	101,
+	102,
+	102,
+	105,
+	99,
+	105,
+	101,
+	110,
+	116,
+	67,
+	108,
+	97,
+	115,
+	115,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	102,
+	111,
+	114,
+	109,
+	115,
+	32,
+	111,
+	102,
+	104,
+	101,
+	32,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	112,
+	101,
+	114,
+	102,
+	111,
+	114,
+	109,
+	97,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	32,
+	123,
+	13,
+	105,
+	102,
+	32,
+	97,
+	110,
+	100,
+	32,
+	111,
+	110,
+	108,
+	121,
+	32,
+	105,
+	102,
+	114,
+	101,
+	103,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	108,
+	101,
+	97,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	119,
+	105,
+	116,
+	104,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	32,
+	78,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	116,
+	104,
+	101,
+	121,
+	112,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	65,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	10,
+	60,
+	47,
+	104,
+	101,
+	97,
+	100,
+	62,
+	10,
+	60,
+	98,
+	111,
+	100,
+	121,
+	108,
+	111,
+	99,
+	97,
+	116,
+	101,
+	100,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	40,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	99,
+	101,
+	110,
+	116,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	100,
+	105,
+	118,
+	105,
+	100,
+	117,
+	97,
+	108,
+	97,
+	109,
+	111,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	116,
+	104,
+	97,
+	110,
+	32,
+	97,
+	110,
+	121,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	47,
+	62,
+	10,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	32,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	59,
+	116,
+	104,
+	101,
+	32,
+	112,
+	117,
+	114,
+	112,
+	111,
+	115,
+	101,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	116,
+	111,
+	59,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	102,
+	102,
+	102,
+	125,
+	10,
+	46,
+	10,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	115,
+	117,
+	98,
+	106,
+	101,
+	99,
+	116,
+	32,
+	111,
+	102,
+	100,
+	101,
+	102,
+	105,
+	110,
+	105,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	62,
+	13,
+	10,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	99,
+	108,
+	97,
+	105,
+	109,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	118,
+	101,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	99,
+	101,
+	108,
+	101,
+	98,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	70,
+	111,
+	108,
+	108,
+	111,
+	119,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	111,
+	32,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	103,
+	117,
+	105,
+	115,
+	104,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	98,
+	116,
+	97,
+	107,
+	101,
+	115,
+	32,
+	112,
+	108,
+	97,
+	99,
+	101,
+	32,
+	105,
+	110,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	97,
+	109,
+	101,
+	110,
+	111,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	62,
+	60,
+	33,
+	91,
+	101,
+	110,
+	100,
+	105,
+	102,
+	93,
+	45,
+	45,
+	62,
+	10,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	105,
+	110,
+	115,
+	116,
+	101,
+	97,
+	100,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	32,
+	111,
+	102,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	32,
+	105,
+	110,
+	101,
+	115,
+	116,
+	105,
+	109,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	119,
+	97,
+	115,
+	32,
+	101,
+	118,
+	101,
+	110,
+	116,
+	117,
+	97,
+	108,
+	108,
+	121,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	111,
+	117,
+	116,
+	32,
+	104,
+	105,
+	115,
+	116,
+	104,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	111,
+	109,
+	101,
+	116,
+	104,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	115,
+	105,
+	103,
+	110,
+	105,
+	102,
+	105,
+	99,
+	97,
+	110,
+	116,
+	108,
+	121,
+	32,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	13,
+	10,
+	101,
+	110,
+	118,
+	105,
+	114,
+	111,
+	110,
+	109,
+	101,
+	110,
+	116,
+	97,
+	108,
+	32,
+	116,
+	111,
+	32,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	32,
+	102,
+	111,
+	114,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	101,
+	115,
+	115,
+	101,
+	110,
+	116,
+	105,
+	97,
+	108,
+	108,
+	121,
+	119,
+	101,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	115,
+	116,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	109,
+	97,
+	100,
+	101,
+	34,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	105,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	116,
+	101,
+	100,
+	32,
+	97,
+	115,
+	115,
+	101,
+	99,
+	111,
+	110,
+	100,
+	32,
+	104,
+	97,
+	108,
+	102,
+	32,
+	111,
+	102,
+	99,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	61,
+	34,
+	110,
+	111,
+	34,
+	32,
+	105,
+	115,
+	32,
+	99,
+	111,
+	109,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	111,
+	102,
+	73,
+	73,
+	44,
+	32,
+	72,
+	111,
+	108,
+	121,
+	32,
+	82,
+	111,
+	109,
+	97,
+	110,
+	105,
+	115,
+	32,
+	101,
+	120,
+	112,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	104,
+	97,
+	118,
+	101,
+	32,
+	116,
+	104,
+	101,
+	105,
+	114,
+	32,
+	111,
+	119,
+	110,
+	100,
+	101,
+	102,
+	105,
+	110,
+	101,
+	100,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	97,
+	114,
+	101,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	117,
+	115,
+	101,
+	100,
+	116,
+	111,
+	32,
+	101,
+	110,
+	115,
+	117,
+	114,
+	101,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	103,
+	114,
+	101,
+	101,
+	109,
+	101,
+	110,
+	116,
+	32,
+	119,
+	105,
+	116,
+	104,
+	99,
+	111,
+	110,
+	116,
+	97,
+	105,
+	110,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	97,
+	114,
+	101,
+	32,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	110,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	32,
+	105,
+	115,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	32,
+	97,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	47,
+	117,
+	108,
+	62,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	97,
+	110,
+	100,
+	32,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	98,
+	117,
+	116,
+	116,
+	111,
+	110,
+	34,
+	32,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	100,
+	62,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	99,
+	97,
+	114,
+	114,
+	105,
+	101,
+	100,
+	32,
+	111,
+	117,
+	116,
+	32,
+	98,
+	121,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	105,
+	116,
+	32,
+	105,
+	115,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	99,
+	97,
+	112,
+	105,
+	116,
+	97,
+	108,
+	32,
+	111,
+	102,
+	119,
+	97,
+	115,
+	32,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	119,
+	104,
+	105,
+	99,
+	104,
+	32,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	116,
+	104,
+	101,
+	32,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	118,
+	101,
+	32,
+	116,
+	111,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	116,
+	111,
+	32,
+	115,
+	117,
+	112,
+	112,
+	111,
+	114,
+	116,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	111,
+	99,
+	101,
+	115,
+	115,
+	32,
+	32,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	97,
+	116,
+	105,
+	111,
+	110,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	104,
+	105,
+	115,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	116,
+	104,
+	101,
+	32,
+	117,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	111,
+	112,
+	112,
+	111,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	120,
+	116,
+	32,
+	111,
+	102,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	112,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	113,
+	34,
+	9,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	115,
+	99,
+	105,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	99,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	109,
+	97,
+	116,
+	104,
+	101,
+	109,
+	97,
+	116,
+	105,
+	99,
+	105,
+	97,
+	110,
+	115,
+	101,
+	108,
+	101,
+	99,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	97,
+	116,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	105,
+	110,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	44,
+	99,
+	111,
+	110,
+	118,
+	101,
+	114,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	41,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	112,
+	104,
+	105,
+	108,
+	111,
+	115,
+	111,
+	112,
+	104,
+	105,
+	99,
+	97,
+	108,
+	32,
+	115,
+	114,
+	112,
+	115,
+	107,
+	111,
+	104,
+	114,
+	118,
+	97,
+	116,
+	115,
+	107,
+	105,
+	116,
+	105,
+	225,
+	186,
+	191,
+	110,
+	103,
+	32,
+	86,
+	105,
+	225,
+	187,
+	135,
+	116,
+	208,
+	160,
+	209,
+	131,
+	209,
+	129,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	208,
+	185,
+	209,
+	128,
+	209,
+	131,
+	209,
+	129,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	208,
+	185,
+	105,
+	110,
+	118,
+	101,
+	115,
+	116,
+	105,
+	103,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	209,
+	139,
+	208,
+	181,
+	208,
+	190,
+	208,
+	177,
+	208,
+	187,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	209,
+	139,
+	208,
+	185,
+	209,
+	135,
+	208,
+	181,
+	208,
+	187,
+	208,
+	190,
+	208,
+	178,
+	208,
+	181,
+	208,
+	186,
+	209,
+	129,
+	208,
+	184,
+	209,
+	129,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	209,
+	139,
+	208,
+	157,
+	208,
+	190,
+	208,
+	178,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	209,
+	139,
+	209,
+	133,
+	208,
+	190,
+	208,
+	177,
+	208,
+	187,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	208,
+	178,
+	209,
+	128,
+	208,
+	181,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	176,
+	209,
+	143,
+	209,
+	129,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	208,
+	180,
+	208,
+	189,
+	209,
+	143,
+	209,
+	129,
+	208,
+	186,
+	208,
+	176,
+	209,
+	135,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	163,
+	208,
+	186,
+	209,
+	128,
+	208,
+	176,
+	208,
+	184,
+	208,
+	189,
+	209,
+	139,
+	208,
+	178,
+	208,
+	190,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	209,
+	129,
+	209,
+	139,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	185,
+	209,
+	129,
+	208,
+	180,
+	208,
+	181,
+	208,
+	187,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	191,
+	208,
+	190,
+	208,
+	188,
+	208,
+	190,
+	209,
+	137,
+	209,
+	140,
+	209,
+	142,
+	209,
+	129,
+	209,
+	128,
+	208,
+	181,
+	208,
+	180,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	190,
+	208,
+	177,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	190,
+	208,
+	188,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	189,
+	209,
+	139,
+	209,
+	131,
+	209,
+	135,
+	208,
+	176,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	181,
+	209,
+	130,
+	208,
+	181,
+	209,
+	135,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	208,
+	181,
+	208,
+	147,
+	208,
+	187,
+	208,
+	176,
+	208,
+	178,
+	208,
+	189,
+	208,
+	176,
+	209,
+	143,
+	208,
+	184,
+	209,
+	129,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	184,
+	208,
+	184,
+	209,
+	129,
+	208,
+	184,
+	209,
+	129,
+	209,
+	130,
+	208,
+	181,
+	208,
+	188,
+	208,
+	176,
+	209,
+	128,
+	208,
+	181,
+	209,
+	136,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	161,
+	208,
+	186,
+	208,
+	176,
+	209,
+	135,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	208,
+	191,
+	208,
+	190,
+	209,
+	141,
+	209,
+	130,
+	208,
+	190,
+	208,
+	188,
+	209,
+	131,
+	209,
+	129,
+	208,
+	187,
+	208,
+	181,
+	208,
+	180,
+	209,
+	131,
+	208,
+	181,
+	209,
+	130,
+	209,
+	129,
+	208,
+	186,
+	208,
+	176,
+	208,
+	183,
+	208,
+	176,
+	209,
+	130,
+	209,
+	140,
+	209,
+	130,
+	208,
+	190,
+	208,
+	178,
+	208,
+	176,
+	209,
+	128,
+	208,
+	190,
+	208,
+	178,
+	208,
+	186,
+	208,
+	190,
+	208,
+	189,
+	208,
+	181,
+	209,
+	135,
+	208,
+	189,
+	208,
+	190,
+	209,
+	128,
+	208,
+	181,
+	209,
+	136,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	208,
+	181,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	181,
+	208,
+	190,
+	209,
+	128,
+	208,
+	179,
+	208,
+	176,
+	208,
+	189,
+	208,
+	190,
+	208,
+	178,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	188,
+	208,
+	160,
+	208,
+	181,
+	208,
+	186,
+	208,
+	187,
+	208,
+	176,
+	208,
+	188,
+	208,
+	176,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	175,
+	217,
+	137,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	175,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	136,
+	216,
+	182,
+	217,
+	136,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	168,
+	216,
+	177,
+	216,
+	167,
+	217,
+	133,
+	216,
+	172,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	136,
+	216,
+	167,
+	217,
+	130,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	179,
+	216,
+	167,
+	216,
+	166,
+	217,
+	132,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	185,
+	216,
+	182,
+	216,
+	167,
+	216,
+	161,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	217,
+	138,
+	216,
+	167,
+	216,
+	182,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	181,
+	217,
+	133,
+	217,
+	138,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	185,
+	216,
+	182,
+	216,
+	167,
+	216,
+	161,
+	216,
+	167,
+	217,
+	132,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	216,
+	166,
+	216,
+	172,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	179,
+	216,
+	172,
+	217,
+	138,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	217,
+	130,
+	216,
+	179,
+	216,
+	167,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	182,
+	216,
+	186,
+	216,
+	183,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	129,
+	217,
+	138,
+	216,
+	175,
+	217,
+	138,
+	217,
+	136,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	177,
+	216,
+	173,
+	217,
+	138,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	216,
+	175,
+	217,
+	138,
+	216,
+	175,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	185,
+	217,
+	132,
+	217,
+	138,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	174,
+	216,
+	168,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	129,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	217,
+	129,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	167,
+	216,
+	177,
+	217,
+	138,
+	216,
+	174,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	217,
+	130,
+	217,
+	134,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	216,
+	174,
+	217,
+	136,
+	216,
+	167,
+	216,
+	183,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	172,
+	216,
+	170,
+	217,
+	133,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	175,
+	217,
+	138,
+	217,
+	131,
+	217,
+	136,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	217,
+	138,
+	216,
+	167,
+	216,
+	173,
+	216,
+	169,
+	216,
+	185,
+	216,
+	168,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	217,
+	132,
+	217,
+	135,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	177,
+	216,
+	168,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	217,
+	136,
+	216,
+	167,
+	216,
+	168,
+	216,
+	183,
+	216,
+	167,
+	217,
+	132,
+	216,
+	163,
+	216,
+	175,
+	216,
+	168,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	174,
+	216,
+	168,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	170,
+	216,
+	173,
+	216,
+	175,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	186,
+	216,
+	167,
+	217,
+	134,
+	217,
+	138,
+	99,
+	117,
+	114,
+	115,
+	111,
+	114,
+	58,
+	112,
+	111,
+	105,
+	110,
+	116,
+	101,
+	114,
+	59,
+	60,
+	47,
+	116,
+	105,
+	116,
+	108,
+	101,
+	62,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	34,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	118,
+	101,
+	114,
+	116,
+	105,
+	99,
+	97,
+	108,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	47,
+	97,
+	62,
+	32,
+	124,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	60,
+	33,
+	100,
+	111,
+	99,
+	116,
+	121,
+	112,
+	101,
+	32,
+	104,
+	116,
+	109,
+	108,
+	62,
+	109,
+	101,
+	100,
+	105,
+	97,
+	61,
+	34,
+	115,
+	99,
+	114,
+	101,
+	101,
+	110,
+	34,
+	32,
+	60,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	46,
+	105,
+	99,
+	111,
+	34,
+	32,
+	47,
+	62,
+	10,
+	9,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	105,
+	115,
+	116,
+	105,
+	99,
+	115,
+	34,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	103,
+	101,
+	116,
+	34,
+	32,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	115,
+	104,
+	111,
+	114,
+	116,
+	99,
+	117,
+	116,
+	32,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	119,
+	114,
+	105,
+	116,
+	101,
+	40,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	45,
+	98,
+	111,
+	116,
+	116,
+	111,
+	109,
+	58,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	97,
+	116,
+	105,
+	118,
+	101,
+	115,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	34,
+	32,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	32,
+	102,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	10,
+	32,
+	32,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	118,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	116,
+	111,
+	112,
+	34,
+	62,
+	60,
+	119,
+	97,
+	115,
+	32,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	41,
+	59,
+	13,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	59,
+	34,
+	62,
+	41,
+	46,
+	115,
+	116,
+	121,
+	108,
+	101,
+	46,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	98,
+	101,
+	99,
+	97,
+	117,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	99,
+	111,
+	111,
+	107,
+	105,
+	101,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	47,
+	125,
+	98,
+	111,
+	100,
+	121,
+	123,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	48,
+	59,
+	69,
+	110,
+	99,
+	121,
+	99,
+	108,
+	111,
+	112,
+	101,
+	100,
+	105,
+	97,
+	32,
+	111,
+	102,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	46,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	110,
+	97,
+	109,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	10,
+	97,
+	100,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	114,
+	97,
+	116,
+	105,
+	118,
+	101,
+	32,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	104,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	34,
+	62,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	112,
+	111,
+	114,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	115,
+	32,
+	112,
+	97,
+	114,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	34,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	73,
+	110,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	119,
+	111,
+	114,
+	100,
+	115,
+	44,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	32,
+	98,
+	108,
+	111,
+	99,
+	107,
+	59,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	47,
+	62,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	97,
+	115,
+	32,
+	119,
+	101,
+	108,
+	108,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	32,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	13,
+	10,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	9,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	105,
+	110,
+	115,
+	112,
+	105,
+	114,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	101,
+	110,
+	100,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	109,
+	112,
+	97,
+	116,
+	105,
+	98,
+	108,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	32,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	67,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	115,
+	116,
+	32,
+	80,
+	97,
+	114,
+	116,
+	121,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	101,
+	110,
+	116,
+	32,
+	119,
+	105,
+	116,
+	104,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	109,
+	97,
+	106,
+	111,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	34,
+	32,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	97,
+	110,
+	121,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	79,
+	114,
+	116,
+	104,
+	111,
+	100,
+	111,
+	120,
+	32,
+	67,
+	104,
+	117,
+	114,
+	99,
+	104,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	47,
+	62,
+	10,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	119,
+	97,
+	115,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	117,
+	110,
+	116,
+	105,
+	108,
+	32,
+	104,
+	105,
+	115,
+	32,
+	100,
+	101,
+	97,
+	116,
+	104,
+	125,
+	41,
+	40,
+	41,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	115,
+	99,
+	111,
+	109,
+	112,
+	97,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	112,
+	111,
+	114,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	78,
+	101,
+	116,
+	104,
+	101,
+	114,
+	108,
+	97,
+	110,
+	100,
+	115,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	117,
+	114,
+	108,
+	40,
+	97,
+	114,
+	103,
+	117,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	115,
+	99,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	61,
+	34,
+	110,
+	111,
+	34,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	78,
+	111,
+	114,
+	116,
+	104,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	110,
+	97,
+	109,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	116,
+	104,
+	101,
+	32,
+	116,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	102,
+	114,
+	101,
+	113,
+	117,
+	101,
+	110,
+	116,
+	108,
+	121,
+	32,
+	117,
+	115,
+	101,
+	100,
+	97,
+	32,
+	99,
+	111,
+	108,
+	108,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	118,
+	101,
+	114,
+	121,
+	32,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	115,
+	117,
+	114,
+	114,
+	111,
+	117,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	105,
+	115,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	34,
+	62,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	105,
+	109,
+	97,
+	103,
+	101,
+	95,
+	99,
+	97,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	61,
+	97,
+	116,
+	116,
+	97,
+	99,
+	104,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	111,
+	114,
+	109,
+	32,
+	111,
+	102,
+	32,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	105,
+	115,
+	32,
+	100,
+	101,
+	114,
+	105,
+	118,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	110,
+	97,
+	109,
+	101,
+	100,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	73,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	114,
+	101,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	110,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	32,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	117,
+	115,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	114,
+	101,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	109,
+	111,
+	115,
+	116,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	32,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	110,
+	100,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	108,
+	108,
+	97,
+	112,
+	115,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	84,
+	104,
+	105,
+	115,
+	32,
+	109,
+	101,
+	97,
+	110,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	101,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	119,
+	97,
+	115,
+	32,
+	114,
+	101,
+	112,
+	108,
+	97,
+	99,
+	101,
+	100,
+	32,
+	98,
+	121,
+	97,
+	110,
+	97,
+	108,
+	121,
+	115,
+	105,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	115,
+	112,
+	105,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	102,
+	111,
+	114,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	101,
+	100,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	109,
+	111,
+	115,
+	116,
+	32,
+	115,
+	117,
+	99,
+	99,
+	101,
+	115,
+	115,
+	102,
+	117,
+	108,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	97,
+	32,
+	99,
+	111,
+	109,
+	112,
+	114,
+	101,
+	104,
+	101,
+	110,
+	115,
+	105,
+	118,
+	101,
+	72,
+	105,
+	115,
+	116,
+	111,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	101,
+	114,
+	101,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	97,
+	114,
+	101,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	85,
+	110,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	100,
+	32,
+	105,
+	109,
+	97,
+	103,
+	101,
+	62,
+	10,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	111,
+	110,
+	115,
+	105,
+	115,
+	116,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	111,
+	112,
+	80,
+	114,
+	111,
+	112,
+	97,
+	103,
+	97,
+	116,
+	105,
+	111,
+	110,
+	105,
+	110,
+	116,
+	101,
+	114,
+	101,
+	115,
+	116,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	115,
+	32,
+	116,
+	111,
+	32,
+	104,
+	97,
+	118,
+	101,
+	101,
+	108,
+	101,
+	99,
+	116,
+	114,
+	111,
+	109,
+	97,
+	103,
+	110,
+	101,
+	116,
+	105,
+	99,
+	101,
+	110,
+	97,
+	98,
+	108,
+	101,
+	83,
+	101,
+	114,
+	118,
+	105,
+	99,
+	101,
+	115,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	73,
+	116,
+	32,
+	105,
+	115,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	118,
+	97,
+	114,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	97,
+	115,
+	32,
+	97,
+	32,
+	114,
+	101,
+	115,
+	117,
+	108,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	70,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	32,
+	105,
+	110,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	116,
+	34,
+	32,
+	119,
+	97,
+	115,
+	32,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	101,
+	100,
+	32,
+	98,
+	121,
+	38,
+	97,
+	109,
+	112,
+	59,
+	109,
+	100,
+	97,
+	115,
+	104,
+	59,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	117,
+	108,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	101,
+	97,
+	116,
+	104,
+	119,
+	105,
+	116,
+	104,
+	32,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	116,
+	32,
+	116,
+	111,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	105,
+	115,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	59,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	105,
+	115,
+	32,
+	100,
+	105,
+	118,
+	105,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	32,
+	40,
+	231,
+	174,
+	128,
+	228,
+	189,
+	147,
+	41,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	97,
+	98,
+	105,
+	108,
+	105,
+	100,
+	97,
+	100,
+	97,
+	100,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	114,
+	97,
+	99,
+	105,
+	195,
+	179,
+	110,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	99,
+	105,
+	111,
+	110,
+	97,
+	108,
+	101,
+	115,
+	99,
+	111,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	105,
+	101,
+	110,
+	116,
+	101,
+	224,
+	164,
+	137,
+	224,
+	164,
+	170,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	164,
+	170,
+	224,
+	165,
+	130,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	181,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	165,
+	129,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	181,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	164,
+	184,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	170,
+	224,
+	165,
+	129,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	184,
+	224,
+	164,
+	150,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	191,
+	224,
+	164,
+	143,
+	224,
+	164,
+	173,
+	224,
+	165,
+	135,
+	224,
+	164,
+	156,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	182,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	151,
+	224,
+	164,
+	176,
+	224,
+	164,
+	163,
+	224,
+	164,
+	172,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	165,
+	129,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	172,
+	224,
+	165,
+	141,
+	224,
+	164,
+	178,
+	224,
+	165,
+	137,
+	224,
+	164,
+	151,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	174,
+	224,
+	164,
+	185,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	165,
+	131,
+	224,
+	164,
+	183,
+	224,
+	165,
+	141,
+	224,
+	164,
+	160,
+	224,
+	164,
+	172,
+	224,
+	164,
+	162,
+	224,
+	164,
+	188,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	159,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	150,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	171,
+	224,
+	164,
+	166,
+	224,
+	165,
+	140,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	174,
+	224,
+	164,
+	164,
+	224,
+	164,
+	166,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	190,
+	224,
+	164,
+	185,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	170,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	129,
+	224,
+	164,
+	154,
+	224,
+	164,
+	172,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	170,
+	224,
+	164,
+	191,
+	224,
+	164,
+	155,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	182,
+	224,
+	165,
+	135,
+	224,
+	164,
+	183,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	137,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	164,
+	176,
+	224,
+	164,
+	174,
+	224,
+	165,
+	129,
+	224,
+	164,
+	130,
+	224,
+	164,
+	172,
+	224,
+	164,
+	136,
+	224,
+	164,
+	166,
+	224,
+	165,
+	139,
+	224,
+	164,
+	168,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	137,
+	224,
+	164,
+	170,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	163,
+	224,
+	164,
+	170,
+	224,
+	164,
+	162,
+	224,
+	164,
+	188,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	171,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	165,
+	141,
+	224,
+	164,
+	174,
+	224,
+	164,
+	174,
+	224,
+	165,
+	129,
+	224,
+	164,
+	150,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	133,
+	224,
+	164,
+	154,
+	224,
+	165,
+	141,
+	224,
+	164,
+	155,
+	224,
+	164,
+	190,
+	224,
+	164,
+	155,
+	224,
+	165,
+	130,
+	224,
+	164,
+	159,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	165,
+	128,
+	224,
+	164,
+	164,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	143,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	151,
+	224,
+	164,
+	152,
+	224,
+	164,
+	163,
+	224,
+	165,
+	141,
+	224,
+	164,
+	159,
+	224,
+	165,
+	135,
+	224,
+	164,
+	166,
+	224,
+	165,
+	130,
+	224,
+	164,
+	184,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	185,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	165,
+	135,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	184,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	167,
+	224,
+	165,
+	128,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	182,
+	224,
+	165,
+	141,
+	224,
+	164,
+	181,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	165,
+	136,
+	224,
+	164,
+	159,
+	224,
+	165,
+	141,
+	224,
+	164,
+	184,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	182,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	133,
+	224,
+	164,
+	166,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	164,
+	224,
+	164,
+	172,
+	224,
+	164,
+	191,
+	224,
+	164,
+	156,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	224,
+	164,
+	170,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	165,
+	130,
+	224,
+	164,
+	183,
+	224,
+	164,
+	185,
+	224,
+	164,
+	191,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	165,
+	128,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	129,
+	224,
+	164,
+	170,
+	224,
+	164,
+	175,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	165,
+	139,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	164,
+	174,
+	224,
+	165,
+	129,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	165,
+	131,
+	224,
+	164,
+	170,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	165,
+	139,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	159,
+	224,
+	164,
+	152,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	178,
+	224,
+	165,
+	130,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	154,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	184,
+	224,
+	165,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	165,
+	130,
+	224,
+	164,
+	178,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	150,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	185,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	149,
+	224,
+	165,
+	130,
+	224,
+	164,
+	178,
+	224,
+	164,
+	174,
+	224,
+	165,
+	136,
+	224,
+	164,
+	130,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	164,
+	224,
+	165,
+	136,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	156,
+	224,
+	164,
+	191,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	114,
+	115,
+	115,
+	43,
+	120,
+	109,
+	108,
+	34,
+	32,
+	116,
+	105,
+	116,
+	108,
+	101,
+	61,
+	34,
+	45,
+	116,
+	121,
+	112,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	116,
+	105,
+	116,
+	108,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	115,
+	97,
+	109,
+	101,
+	32,
+	116,
+	105,
+	109,
+	101,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	34,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	116,
+	34,
+	32,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	118,
+	101,
+	114,
+	116,
+	105,
+	99,
+	97,
+	108,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	116,
+	47,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	46,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	62,
+	46,
+	99,
+	108,
+	105,
+	99,
+	107,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	45,
+	125,
+	41,
+	40,
+	41,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	41,
+	59,
+	32,
+	114,
+	101,
+	116,
+	117,
+	114,
+	110,
+	32,
+	102,
+	97,
+	108,
+	115,
+	101,
+	59,
+	116,
+	101,
+	120,
+	116,
+	45,
+	100,
+	101,
+	99,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	58,
+	32,
+	115,
+	99,
+	114,
+	111,
+	108,
+	108,
+	105,
+	110,
+	103,
+	61,
+	34,
+	110,
+	111,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	45,
+	99,
+	111,
+	108,
+	108,
+	97,
+	112,
+	115,
+	101,
+	58,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	66,
+	97,
+	104,
+	97,
+	115,
+	97,
+	32,
+	73,
+	110,
+	100,
+	111,
+	110,
+	101,
+	115,
+	105,
+	97,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	60,
+	116,
+	101,
+	120,
+	116,
+	32,
+	120,
+	109,
+	108,
+	58,
+	115,
+	112,
+	97,
+	99,
+	101,
+	61,
+	46,
+	103,
+	105,
+	102,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	111,
+	118,
+	101,
+	114,
+	102,
+	108,
+	111,
+	119,
+	58,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	59,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	97,
+	100,
+	100,
+	69,
+	118,
+	101,
+	110,
+	116,
+	76,
+	105,
+	115,
+	116,
+	101,
+	110,
+	101,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	102,
+	111,
+	114,
+	32,
+	115,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	47,
+	102,
+	97,
+	118,
+	105,
+	99,
+	111,
+	110,
+	46,
+	105,
+	99,
+	111,
+	34,
+	32,
+	47,
+	62,
+	111,
+	112,
+	101,
+	114,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	115,
+	121,
+	115,
+	116,
+	101,
+	109,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	49,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	62,
+	83,
+	116,
+	97,
+	116,
+	101,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	108,
+	101,
+	102,
+	116,
+	59,
+	10,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	119,
+	114,
+	105,
+	116,
+	101,
+	40,
+	44,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	41,
+	59,
+	13,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	59,
+	111,
+	118,
+	101,
+	114,
+	102,
+	108,
+	111,
+	119,
+	58,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	109,
+	111,
+	114,
+	101,
+	32,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	110,
+	32,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	32,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	102,
+	111,
+	117,
+	110,
+	100,
+	32,
+	105,
+	110,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	9,
+	9,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	32,
+	110,
+	111,
+	110,
+	101,
+	59,
+	34,
+	62,
+	34,
+	32,
+	47,
+	62,
+	10,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	10,
+	32,
+	32,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	32,
+	123,
+	116,
+	104,
+	101,
+	32,
+	49,
+	53,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	46,
+	112,
+	114,
+	101,
+	118,
+	101,
+	110,
+	116,
+	68,
+	101,
+	102,
+	97,
+	117,
+	108,
+	116,
+	40,
+	108,
+	97,
+	114,
+	103,
+	101,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	66,
+	121,
+	122,
+	97,
+	110,
+	116,
+	105,
+	110,
+	101,
+	32,
+	69,
+	109,
+	112,
+	105,
+	114,
+	101,
+	46,
+	106,
+	112,
+	103,
+	124,
+	116,
+	104,
+	117,
+	109,
+	98,
+	124,
+	108,
+	101,
+	102,
+	116,
+	124,
+	118,
+	97,
+	115,
+	116,
+	32,
+	109,
+	97,
+	106,
+	111,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	109,
+	97,
+	106,
+	111,
+	114,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	32,
+	97,
+	108,
+	105,
+	103,
+	110,
+	61,
+	34,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	34,
+	62,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	80,
+	114,
+	101,
+	115,
+	115,
+	100,
+	111,
+	109,
+	105,
+	110,
+	97,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	83,
+	101,
+	99,
+	111,
+	110,
+	100,
+	32,
+	87,
+	111,
+	114,
+	108,
+	100,
+	32,
+	87,
+	97,
+	114,
+	100,
+	105,
+	115,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	115,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	105,
+	122,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	110,
+	111,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	34,
+	62,
+	100,
+	101,
+	114,
+	105,
+	118,
+	101,
+	115,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	114,
+	97,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	97,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	32,
+	99,
+	111,
+	109,
+	98,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	119,
+	105,
+	100,
+	116,
+	104,
+	58,
+	49,
+	48,
+	48,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	45,
+	115,
+	112,
+	101,
+	97,
+	107,
+	105,
+	110,
+	103,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	32,
+	115,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	116,
+	104,
+	101,
+	32,
+	101,
+	120,
+	105,
+	115,
+	116,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	68,
+	101,
+	109,
+	111,
+	99,
+	114,
+	97,
+	116,
+	105,
+	99,
+	32,
+	80,
+	97,
+	114,
+	116,
+	121,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	70,
+	111,
+	114,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	114,
+	101,
+	97,
+	115,
+	111,
+	110,
+	44,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	9,
+	115,
+	66,
+	121,
+	84,
+	97,
+	103,
+	78,
+	97,
+	109,
+	101,
+	40,
+	115,
+	41,
+	91,
+	48,
+	93,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	39,
+	32,
+	97,
+	108,
+	116,
+	61,
+	39,
+	39,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	39,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	47,
+	112,
+	97,
+	103,
+	101,
+	62,
+	10,
+	32,
+	32,
+	60,
+	112,
+	97,
+	103,
+	101,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	111,
+	110,
+	116,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	98,
+	97,
+	104,
+	97,
+	115,
+	97,
+	32,
+	73,
+	110,
+	100,
+	111,
+	110,
+	101,
+	115,
+	105,
+	97,
+	101,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	40,
+	115,
+	105,
+	109,
+	112,
+	108,
+	101,
+	41,
+	206,
+	149,
+	206,
+	187,
+	206,
+	187,
+	206,
+	183,
+	206,
+	189,
+	206,
+	185,
+	206,
+	186,
+	206,
+	172,
+	209,
+	133,
+	209,
+	128,
+	208,
+	178,
+	208,
+	176,
+	209,
+	130,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	208,
+	186,
+	208,
+	190,
+	208,
+	188,
+	208,
+	191,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	208,
+	184,
+	209,
+	143,
+	208,
+	178,
+	208,
+	187,
+	209,
+	143,
+	208,
+	181,
+	209,
+	130,
+	209,
+	129,
+	209,
+	143,
+	208,
+	148,
+	208,
+	190,
+	208,
+	177,
+	208,
+	176,
+	208,
+	178,
+	208,
+	184,
+	209,
+	130,
+	209,
+	140,
+	209,
+	135,
+	208,
+	181,
+	208,
+	187,
+	208,
+	190,
+	208,
+	178,
+	208,
+	181,
+	208,
+	186,
+	208,
+	176,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	178,
+	208,
+	184,
+	209,
+	130,
+	208,
+	184,
+	209,
+	143,
+	208,
+	152,
+	208,
+	189,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	208,
+	189,
+	208,
+	181,
+	209,
+	130,
+	208,
+	158,
+	209,
+	130,
+	208,
+	178,
+	208,
+	181,
+	209,
+	130,
+	208,
+	184,
+	209,
+	130,
+	209,
+	140,
+	208,
+	189,
+	208,
+	176,
+	208,
+	191,
+	209,
+	128,
+	208,
+	184,
+	208,
+	188,
+	208,
+	181,
+	209,
+	128,
+	208,
+	184,
+	208,
+	189,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	208,
+	189,
+	208,
+	181,
+	209,
+	130,
+	208,
+	186,
+	208,
+	190,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	190,
+	208,
+	179,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	209,
+	134,
+	209,
+	139,
+	208,
+	186,
+	208,
+	176,
+	209,
+	135,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	181,
+	209,
+	131,
+	209,
+	129,
+	208,
+	187,
+	208,
+	190,
+	208,
+	178,
+	208,
+	184,
+	209,
+	143,
+	209,
+	133,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	177,
+	208,
+	187,
+	208,
+	181,
+	208,
+	188,
+	209,
+	139,
+	208,
+	191,
+	208,
+	190,
+	208,
+	187,
+	209,
+	131,
+	209,
+	135,
+	208,
+	184,
+	209,
+	130,
+	209,
+	140,
+	209,
+	143,
+	208,
+	178,
+	208,
+	187,
+	209,
+	143,
+	209,
+	142,
+	209,
+	130,
+	209,
+	129,
+	209,
+	143,
+	208,
+	189,
+	208,
+	176,
+	208,
+	184,
+	208,
+	177,
+	208,
+	190,
+	208,
+	187,
+	208,
+	181,
+	208,
+	181,
+	208,
+	186,
+	208,
+	190,
+	208,
+	188,
+	208,
+	191,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	178,
+	208,
+	189,
+	208,
+	184,
+	208,
+	188,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	208,
+	181,
+	209,
+	129,
+	209,
+	128,
+	208,
+	181,
+	208,
+	180,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	176,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	136,
+	216,
+	167,
+	216,
+	182,
+	217,
+	138,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	166,
+	217,
+	138,
+	216,
+	179,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	134,
+	216,
+	170,
+	217,
+	130,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	217,
+	131,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	217,
+	138,
+	216,
+	167,
+	216,
+	177,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	131,
+	216,
+	170,
+	217,
+	136,
+	216,
+	168,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	179,
+	216,
+	185,
+	217,
+	136,
+	216,
+	175,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	216,
+	173,
+	216,
+	181,
+	216,
+	167,
+	216,
+	166,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	185,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	181,
+	217,
+	136,
+	216,
+	170,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	134,
+	216,
+	170,
+	216,
+	177,
+	217,
+	134,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	170,
+	216,
+	181,
+	216,
+	167,
+	217,
+	133,
+	217,
+	138,
+	217,
+	133,
+	216,
+	167,
+	217,
+	132,
+	216,
+	165,
+	216,
+	179,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	217,
+	138,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	177,
+	216,
+	166,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	114,
+	111,
+	98,
+	111,
+	116,
+	115,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	34,
+	62,
+	116,
+	104,
+	101,
+	32,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	32,
+	83,
+	116,
+	97,
+	116,
+	101,
+	115,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	46,
+	106,
+	112,
+	103,
+	124,
+	114,
+	105,
+	103,
+	104,
+	116,
+	124,
+	116,
+	104,
+	117,
+	109,
+	98,
+	124,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	112,
+	114,
+	111,
+	116,
+	111,
+	99,
+	111,
+	108,
+	102,
+	114,
+	97,
+	109,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	115,
+	34,
+	32,
+	47,
+	62,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	102,
+	111,
+	110,
+	116,
+	45,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	98,
+	111,
+	108,
+	100,
+	59,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	32,
+	97,
+	110,
+	100,
+	32,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	48,
+	59,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	58,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	110,
+	111,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	34,
+	32,
+	80,
+	114,
+	101,
+	115,
+	105,
+	100,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	119,
+	101,
+	110,
+	116,
+	105,
+	101,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	62,
+	10,
+	32,
+	32,
+	60,
+	47,
+	112,
+	97,
+	103,
+	101,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	101,
+	116,
+	32,
+	69,
+	120,
+	112,
+	108,
+	111,
+	114,
+	101,
+	114,
+	97,
+	46,
+	97,
+	115,
+	121,
+	110,
+	99,
+	32,
+	61,
+	32,
+	116,
+	114,
+	117,
+	101,
+	59,
+	13,
+	10,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	98,
+	111,
+	117,
+	116,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	34,
+	62,
+	34,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	47,
+	47,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	34,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	34,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	60,
+	100,
+	101,
+	114,
+	105,
+	118,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	10,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	65,
+	114,
+	105,
+	97,
+	108,
+	44,
+	32,
+	72,
+	101,
+	108,
+	118,
+	101,
+	116,
+	105,
+	99,
+	97,
+	44,
+	60,
+	47,
+	97,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	101,
+	115,
+	116,
+	100,
+	62,
+	60,
+	47,
+	116,
+	114,
+	62,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	60,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	105,
+	110,
+	116,
+	101,
+	114,
+	112,
+	114,
+	101,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	119,
+	114,
+	105,
+	116,
+	101,
+	40,
+	39,
+	60,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	34,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	62,
+	10,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	114,
+	101,
+	118,
+	101,
+	97,
+	108,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	116,
+	101,
+	108,
+	101,
+	118,
+	105,
+	115,
+	105,
+	111,
+	110,
+	32,
+	115,
+	101,
+	114,
+	105,
+	101,
+	115,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	110,
+	111,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	34,
+	62,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	62,
+	99,
+	108,
+	97,
+	105,
+	109,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	104,
+	116,
+	116,
+	112,
+	37,
+	51,
+	65,
+	37,
+	50,
+	70,
+	37,
+	50,
+	70,
+	119,
+	119,
+	119,
+	46,
+	109,
+	97,
+	110,
+	105,
+	102,
+	101,
+	115,
+	116,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	80,
+	114,
+	105,
+	109,
+	101,
+	32,
+	77,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	32,
+	111,
+	102,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	108,
+	101,
+	97,
+	114,
+	102,
+	105,
+	120,
+	34,
+	62,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	13,
+	10,
+	116,
+	104,
+	114,
+	101,
+	101,
+	45,
+	100,
+	105,
+	109,
+	101,
+	110,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	67,
+	104,
+	117,
+	114,
+	99,
+	104,
+	32,
+	111,
+	102,
+	32,
+	69,
+	110,
+	103,
+	108,
+	97,
+	110,
+	100,
+	111,
+	102,
+	32,
+	78,
+	111,
+	114,
+	116,
+	104,
+	32,
+	67,
+	97,
+	114,
+	111,
+	108,
+	105,
+	110,
+	97,
+	115,
+	113,
+	117,
+	97,
+	114,
+	101,
+	32,
+	107,
+	105,
+	108,
+	111,
+	109,
+	101,
+	116,
+	114,
+	101,
+	115,
+	46,
+	97,
+	100,
+	100,
+	69,
+	118,
+	101,
+	110,
+	116,
+	76,
+	105,
+	115,
+	116,
+	101,
+	110,
+	101,
+	114,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	99,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	108,
+	121,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	80,
+	104,
+	111,
+	110,
+	101,
+	116,
+	105,
+	99,
+	32,
+	65,
+	108,
+	112,
+	104,
+	97,
+	98,
+	101,
+	116,
+	100,
+	101,
+	99,
+	108,
+	97,
+	114,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	108,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	66,
+	101,
+	110,
+	106,
+	97,
+	109,
+	105,
+	110,
+	32,
+	70,
+	114,
+	97,
+	110,
+	107,
+	108,
+	105,
+	110,
+	114,
+	111,
+	108,
+	101,
+	45,
+	112,
+	108,
+	97,
+	121,
+	105,
+	110,
+	103,
+	32,
+	103,
+	97,
+	109,
+	101,
+	116,
+	104,
+	101,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	87,
+	101,
+	115,
+	116,
+	101,
+	114,
+	110,
+	32,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	112,
+	101,
+	114,
+	115,
+	111,
+	110,
+	97,
+	108,
+	32,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	80,
+	114,
+	111,
+	106,
+	101,
+	99,
+	116,
+	32,
+	71,
+	117,
+	116,
+	101,
+	110,
+	98,
+	101,
+	114,
+	103,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	108,
+	101,
+	115,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	112,
+	114,
+	111,
+	112,
+	111,
+	115,
+	101,
+	100,
+	116,
+	111,
+	103,
+	101,
+	116,
+	104,
+	101,
+	114,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	105,
+	110,
+	32,
+	115,
+	111,
+	109,
+	101,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	115,
+	47,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	110,
+	97,
+	116,
+	117,
+	114,
+	97,
+	108,
+	32,
+	114,
+	101,
+	115,
+	111,
+	117,
+	114,
+	99,
+	101,
+	115,
+	99,
+	108,
+	97,
+	115,
+	115,
+	105,
+	102,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	99,
+	97,
+	110,
+	32,
+	98,
+	101,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	113,
+	117,
+	97,
+	110,
+	116,
+	117,
+	109,
+	32,
+	109,
+	101,
+	99,
+	104,
+	97,
+	110,
+	105,
+	99,
+	115,
+	78,
+	101,
+	118,
+	101,
+	114,
+	116,
+	104,
+	101,
+	108,
+	101,
+	115,
+	115,
+	44,
+	32,
+	116,
+	104,
+	101,
+	109,
+	105,
+	108,
+	108,
+	105,
+	111,
+	110,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	32,
+	97,
+	103,
+	111,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	13,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	13,
+	206,
+	149,
+	206,
+	187,
+	206,
+	187,
+	206,
+	183,
+	206,
+	189,
+	206,
+	185,
+	206,
+	186,
+	206,
+	172,
+	10,
+	116,
+	97,
+	107,
+	101,
+	32,
+	97,
+	100,
+	118,
+	97,
+	110,
+	116,
+	97,
+	103,
+	101,
+	32,
+	111,
+	102,
+	97,
+	110,
+	100,
+	44,
+	32,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	97,
+	116,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	77,
+	105,
+	99,
+	114,
+	111,
+	115,
+	111,
+	102,
+	116,
+	32,
+	87,
+	105,
+	110,
+	100,
+	111,
+	119,
+	115,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	116,
+	114,
+	111,
+	108,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	115,
+	104,
+	111,
+	114,
+	116,
+	108,
+	121,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	110,
+	111,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	116,
+	101,
+	110,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	111,
+	117,
+	115,
+	97,
+	110,
+	100,
+	115,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	46,
+	114,
+	101,
+	97,
+	99,
+	104,
+	105,
+	110,
+	103,
+	32,
+	109,
+	105,
+	108,
+	105,
+	116,
+	97,
+	114,
+	121,
+	105,
+	115,
+	111,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	111,
+	112,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	79,
+	108,
+	100,
+	32,
+	84,
+	101,
+	115,
+	116,
+	97,
+	109,
+	101,
+	110,
+	116,
+	65,
+	102,
+	114,
+	105,
+	99,
+	97,
+	110,
+	32,
+	65,
+	109,
+	101,
+	114,
+	105,
+	99,
+	97,
+	110,
+	115,
+	105,
+	110,
+	115,
+	101,
+	114,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	115,
+	101,
+	112,
+	97,
+	114,
+	97,
+	116,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	109,
+	101,
+	116,
+	114,
+	111,
+	112,
+	111,
+	108,
+	105,
+	116,
+	97,
+	110,
+	32,
+	97,
+	114,
+	101,
+	97,
+	109,
+	97,
+	107,
+	101,
+	115,
+	32,
+	105,
+	116,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	97,
+	99,
+	107,
+	110,
+	111,
+	119,
+	108,
+	101,
+	100,
+	103,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	114,
+	103,
+	117,
+	97,
+	98,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	62,
+	10,
+	116,
+	104,
+	101,
+	32,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	65,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	10,
+	99,
+	111,
+	105,
+	110,
+	99,
+	105,
+	100,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	116,
+	119,
+	111,
+	45,
+	116,
+	104,
+	105,
+	114,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	68,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	116,
+	105,
+	109,
+	101,
+	44,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	97,
+	110,
+	110,
+	111,
+	117,
+	110,
+	99,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	97,
+	110,
+	100,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	108,
+	121,
+	98,
+	101,
+	108,
+	105,
+	101,
+	118,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	115,
+	99,
+	105,
+	111,
+	117,
+	115,
+	110,
+	101,
+	115,
+	115,
+	32,
+	97,
+	110,
+	100,
+	102,
+	111,
+	114,
+	109,
+	101,
+	114,
+	108,
+	121,
+	32,
+	107,
+	110,
+	111,
+	119,
+	110,
+	32,
+	97,
+	115,
+	115,
+	117,
+	114,
+	114,
+	111,
+	117,
+	110,
+	100,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	97,
+	112,
+	112,
+	101,
+	97,
+	114,
+	101,
+	100,
+	32,
+	105,
+	110,
+	111,
+	99,
+	99,
+	97,
+	115,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	32,
+	117,
+	115,
+	101,
+	100,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	97,
+	98,
+	115,
+	111,
+	108,
+	117,
+	116,
+	101,
+	59,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	32,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	118,
+	101,
+	59,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	59,
+	106,
+	97,
+	120,
+	47,
+	108,
+	105,
+	98,
+	115,
+	47,
+	106,
+	113,
+	117,
+	101,
+	114,
+	121,
+	47,
+	49,
+	46,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	35,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	47,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	80,
+	114,
+	105,
+	118,
+	97,
+	99,
+	121,
+	32,
+	80,
+	111,
+	108,
+	105,
+	99,
+	121,
+	60,
+	47,
+	97,
+	62,
+	101,
+	40,
+	34,
+	37,
+	51,
+	67,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	114,
+	99,
+	61,
+	39,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	62,
+	79,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	104,
+	97,
+	110,
+	100,
+	44,
+	46,
+	106,
+	112,
+	103,
+	124,
+	116,
+	104,
+	117,
+	109,
+	98,
+	124,
+	114,
+	105,
+	103,
+	104,
+	116,
+	124,
+	50,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	110,
+	105,
+	110,
+	101,
+	116,
+	101,
+	101,
+	110,
+	116,
+	104,
+	32,
+	99,
+	101,
+	110,
+	116,
+	117,
+	114,
+	121,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	13,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	13,
+	10,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	115,
+	59,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	102,
+	111,
+	110,
+	116,
+	45,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	32,
+	98,
+	111,
+	108,
+	100,
+	59,
+	32,
+	65,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	34,
+	32,
+	102,
+	114,
+	97,
+	109,
+	101,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	108,
+	105,
+	110,
+	107,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	104,
+	116,
+	109,
+	108,
+	52,
+	47,
+	108,
+	111,
+	111,
+	115,
+	101,
+	46,
+	100,
+	116,
+	100,
+	34,
+	62,
+	10,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	105,
+	115,
+	32,
+	112,
+	101,
+	114,
+	105,
+	111,
+	100,
+	60,
+	47,
+	116,
+	100,
+	62,
+	60,
+	47,
+	116,
+	114,
+	62,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	99,
+	108,
+	111,
+	115,
+	101,
+	108,
+	121,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	32,
+	116,
+	105,
+	109,
+	101,
+	59,
+	102,
+	111,
+	110,
+	116,
+	45,
+	119,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	98,
+	111,
+	108,
+	100,
+	59,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	102,
+	111,
+	110,
+	116,
+	45,
+	111,
+	110,
+	114,
+	101,
+	97,
+	100,
+	121,
+	115,
+	116,
+	97,
+	116,
+	101,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	9,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	108,
+	101,
+	97,
+	114,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	32,
+	70,
+	111,
+	114,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	44,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	32,
+	119,
+	105,
+	100,
+	101,
+	32,
+	118,
+	97,
+	114,
+	105,
+	101,
+	116,
+	121,
+	32,
+	111,
+	102,
+	32,
+	60,
+	33,
+	68,
+	79,
+	67,
+	84,
+	89,
+	80,
+	69,
+	32,
+	104,
+	116,
+	109,
+	108,
+	62,
+	13,
+	10,
+	60,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	34,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	108,
+	101,
+	102,
+	116,
+	59,
+	99,
+	111,
+	110,
+	99,
+	101,
+	114,
+	110,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	61,
+	104,
+	116,
+	116,
+	112,
+	37,
+	51,
+	65,
+	37,
+	50,
+	70,
+	37,
+	50,
+	70,
+	119,
+	119,
+	119,
+	46,
+	105,
+	110,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	114,
+	32,
+	99,
+	117,
+	108,
+	116,
+	117,
+	114,
+	101,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	105,
+	116,
+	32,
+	105,
+	115,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	116,
+	111,
+	32,
+	72,
+	97,
+	114,
+	118,
+	97,
+	114,
+	100,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	116,
+	104,
+	101,
+	32,
+	109,
+	97,
+	105,
+	110,
+	32,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	79,
+	120,
+	102,
+	111,
+	114,
+	100,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	107,
+	101,
+	121,
+	119,
+	111,
+	114,
+	100,
+	115,
+	34,
+	32,
+	99,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	116,
+	104,
+	101,
+	32,
+	85,
+	110,
+	105,
+	116,
+	101,
+	100,
+	32,
+	75,
+	105,
+	110,
+	103,
+	100,
+	111,
+	109,
+	102,
+	101,
+	100,
+	101,
+	114,
+	97,
+	108,
+	32,
+	103,
+	111,
+	118,
+	101,
+	114,
+	110,
+	109,
+	101,
+	110,
+	116,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	32,
+	100,
+	101,
+	112,
+	101,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	104,
+	101,
+	97,
+	100,
+	101,
+	114,
+	46,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	100,
+	101,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	108,
+	105,
+	103,
+	104,
+	116,
+	108,
+	121,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	105,
+	110,
+	32,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	97,
+	110,
+	99,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	116,
+	101,
+	108,
+	101,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	101,
+	115,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	115,
+	104,
+	111,
+	114,
+	116,
+	108,
+	121,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	97,
+	102,
+	116,
+	101,
+	114,
+	101,
+	115,
+	112,
+	101,
+	99,
+	105,
+	97,
+	108,
+	108,
+	121,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	97,
+	110,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	115,
+	116,
+	97,
+	116,
+	105,
+	99,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	34,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	97,
+	32,
+	108,
+	97,
+	114,
+	103,
+	101,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	84,
+	101,
+	108,
+	101,
+	99,
+	111,
+	109,
+	109,
+	117,
+	110,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	110,
+	111,
+	102,
+	111,
+	108,
+	108,
+	111,
+	119,
+	34,
+	32,
+	116,
+	72,
+	111,
+	108,
+	121,
+	32,
+	82,
+	111,
+	109,
+	97,
+	110,
+	32,
+	69,
+	109,
+	112,
+	101,
+	114,
+	111,
+	114,
+	97,
+	108,
+	109,
+	111,
+	115,
+	116,
+	32,
+	101,
+	120,
+	99,
+	108,
+	117,
+	115,
+	105,
+	118,
+	101,
+	108,
+	121,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	97,
+	108,
+	116,
+	61,
+	34,
+	83,
+	101,
+	99,
+	114,
+	101,
+	116,
+	97,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	83,
+	116,
+	97,
+	116,
+	101,
+	99,
+	117,
+	108,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	67,
+	73,
+	65,
+	32,
+	87,
+	111,
+	114,
+	108,
+	100,
+	32,
+	70,
+	97,
+	99,
+	116,
+	98,
+	111,
+	111,
+	107,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	115,
+	116,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	97,
+	110,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	97,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	60,
+	108,
+	105,
+	62,
+	60,
+	101,
+	109,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	116,
+	104,
+	101,
+	32,
+	65,
+	116,
+	108,
+	97,
+	110,
+	116,
+	105,
+	99,
+	32,
+	79,
+	99,
+	101,
+	97,
+	110,
+	115,
+	116,
+	114,
+	105,
+	99,
+	116,
+	108,
+	121,
+	32,
+	115,
+	112,
+	101,
+	97,
+	107,
+	105,
+	110,
+	103,
+	44,
+	115,
+	104,
+	111,
+	114,
+	116,
+	108,
+	121,
+	32,
+	98,
+	101,
+	102,
+	111,
+	114,
+	101,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	115,
+	32,
+	111,
+	102,
+	116,
+	104,
+	101,
+	32,
+	79,
+	116,
+	116,
+	111,
+	109,
+	97,
+	110,
+	32,
+	69,
+	109,
+	112,
+	105,
+	114,
+	101,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	65,
+	110,
+	32,
+	73,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	99,
+	111,
+	110,
+	115,
+	101,
+	113,
+	117,
+	101,
+	110,
+	99,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	100,
+	101,
+	112,
+	97,
+	114,
+	116,
+	117,
+	114,
+	101,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	67,
+	111,
+	110,
+	102,
+	101,
+	100,
+	101,
+	114,
+	97,
+	116,
+	101,
+	32,
+	83,
+	116,
+	97,
+	116,
+	101,
+	115,
+	105,
+	110,
+	100,
+	105,
+	103,
+	101,
+	110,
+	111,
+	117,
+	115,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	115,
+	80,
+	114,
+	111,
+	99,
+	101,
+	101,
+	100,
+	105,
+	110,
+	103,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	111,
+	114,
+	105,
+	101,
+	115,
+	32,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	105,
+	110,
+	118,
+	111,
+	108,
+	118,
+	101,
+	109,
+	101,
+	110,
+	116,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	100,
+	105,
+	118,
+	105,
+	100,
+	101,
+	100,
+	32,
+	105,
+	110,
+	116,
+	111,
+	32,
+	116,
+	104,
+	114,
+	101,
+	101,
+	97,
+	100,
+	106,
+	97,
+	99,
+	101,
+	110,
+	116,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	105,
+	115,
+	32,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	102,
+	111,
+	114,
+	100,
+	105,
+	115,
+	115,
+	111,
+	108,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	108,
+	108,
+	97,
+	98,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	119,
+	105,
+	116,
+	104,
+	119,
+	105,
+	100,
+	101,
+	108,
+	121,
+	32,
+	114,
+	101,
+	103,
+	97,
+	114,
+	100,
+	101,
+	100,
+	32,
+	97,
+	115,
+	104,
+	105,
+	115,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	109,
+	112,
+	111,
+	114,
+	97,
+	114,
+	105,
+	101,
+	115,
+	102,
+	111,
+	117,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	109,
+	101,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	68,
+	111,
+	109,
+	105,
+	110,
+	105,
+	99,
+	97,
+	110,
+	32,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	32,
+	97,
+	99,
+	99,
+	101,
+	112,
+	116,
+	101,
+	100,
+	116,
+	104,
+	101,
+	32,
+	112,
+	111,
+	115,
+	115,
+	105,
+	98,
+	105,
+	108,
+	105,
+	116,
+	121,
+	32,
+	111,
+	102,
+	97,
+	114,
+	101,
+	32,
+	97,
+	108,
+	115,
+	111,
+	32,
+	97,
+	118,
+	97,
+	105,
+	108,
+	97,
+	98,
+	108,
+	101,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	114,
+	101,
+	115,
+	116,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	32,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	105,
+	115,
+	32,
+	97,
+	108,
+	109,
+	111,
+	115,
+	116,
+	32,
+	101,
+	110,
+	116,
+	105,
+	114,
+	101,
+	108,
+	121,
+	112,
+	97,
+	115,
+	115,
+	101,
+	115,
+	32,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	101,
+	100,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	32,
+	97,
+	110,
+	100,
+	32,
+	118,
+	105,
+	100,
+	101,
+	111,
+	71,
+	101,
+	114,
+	109,
+	97,
+	110,
+	105,
+	99,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	115,
+	32,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	115,
+	104,
+	111,
+	114,
+	116,
+	108,
+	121,
+	32,
+	97,
+	102,
+	116,
+	101,
+	114,
+	119,
+	97,
+	114,
+	100,
+	115,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	115,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	114,
+	101,
+	99,
+	101,
+	110,
+	116,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	66,
+	111,
+	97,
+	114,
+	100,
+	32,
+	111,
+	102,
+	32,
+	68,
+	105,
+	114,
+	101,
+	99,
+	116,
+	111,
+	114,
+	115,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	115,
+	101,
+	97,
+	114,
+	99,
+	104,
+	124,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	73,
+	110,
+	32,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	44,
+	32,
+	116,
+	104,
+	101,
+	77,
+	117,
+	108,
+	116,
+	105,
+	112,
+	108,
+	101,
+	32,
+	102,
+	111,
+	111,
+	116,
+	110,
+	111,
+	116,
+	101,
+	115,
+	111,
+	114,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	115,
+	117,
+	98,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	116,
+	104,
+	111,
+	117,
+	115,
+	97,
+	110,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	121,
+	101,
+	97,
+	114,
+	115,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	13,
+	10,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	105,
+	110,
+	100,
+	101,
+	120,
+	46,
+	112,
+	104,
+	112,
+	119,
+	97,
+	115,
+	32,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	101,
+	100,
+	32,
+	105,
+	110,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	101,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	97,
+	32,
+	115,
+	116,
+	114,
+	111,
+	110,
+	103,
+	32,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	116,
+	111,
+	112,
+	58,
+	114,
+	101,
+	112,
+	114,
+	101,
+	115,
+	101,
+	110,
+	116,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	103,
+	114,
+	97,
+	100,
+	117,
+	97,
+	116,
+	101,
+	100,
+	32,
+	102,
+	114,
+	111,
+	109,
+	32,
+	116,
+	104,
+	101,
+	84,
+	114,
+	97,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	108,
+	121,
+	44,
+	32,
+	116,
+	104,
+	101,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	34,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	41,
+	59,
+	72,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	115,
+	105,
+	110,
+	99,
+	101,
+	32,
+	116,
+	104,
+	101,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	108,
+	101,
+	102,
+	116,
+	59,
+	32,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	108,
+	101,
+	102,
+	116,
+	58,
+	112,
+	114,
+	111,
+	116,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	97,
+	103,
+	97,
+	105,
+	110,
+	115,
+	116,
+	48,
+	59,
+	32,
+	118,
+	101,
+	114,
+	116,
+	105,
+	99,
+	97,
+	108,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	85,
+	110,
+	102,
+	111,
+	114,
+	116,
+	117,
+	110,
+	97,
+	116,
+	101,
+	108,
+	121,
+	44,
+	32,
+	116,
+	104,
+	101,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	47,
+	120,
+	45,
+	105,
+	99,
+	111,
+	110,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	108,
+	101,
+	97,
+	114,
+	102,
+	105,
+	120,
+	34,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	102,
+	111,
+	111,
+	116,
+	101,
+	114,
+	9,
+	9,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	9,
+	9,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	116,
+	104,
+	101,
+	32,
+	109,
+	111,
+	116,
+	105,
+	111,
+	110,
+	32,
+	112,
+	105,
+	99,
+	116,
+	117,
+	114,
+	101,
+	208,
+	145,
+	209,
+	138,
+	208,
+	187,
+	208,
+	179,
+	208,
+	176,
+	209,
+	128,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	208,
+	177,
+	209,
+	138,
+	208,
+	187,
+	208,
+	179,
+	208,
+	176,
+	209,
+	128,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	208,
+	164,
+	208,
+	181,
+	208,
+	180,
+	208,
+	181,
+	209,
+	128,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	208,
+	184,
+	208,
+	189,
+	208,
+	181,
+	209,
+	129,
+	208,
+	186,
+	208,
+	190,
+	208,
+	187,
+	209,
+	140,
+	208,
+	186,
+	208,
+	190,
+	209,
+	129,
+	208,
+	190,
+	208,
+	190,
+	208,
+	177,
+	209,
+	137,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	208,
+	181,
+	209,
+	129,
+	208,
+	190,
+	208,
+	190,
+	208,
+	177,
+	209,
+	137,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	179,
+	209,
+	128,
+	208,
+	176,
+	208,
+	188,
+	208,
+	188,
+	209,
+	139,
+	208,
+	158,
+	209,
+	130,
+	208,
+	191,
+	209,
+	128,
+	208,
+	176,
+	208,
+	178,
+	208,
+	184,
+	209,
+	130,
+	209,
+	140,
+	208,
+	177,
+	208,
+	181,
+	209,
+	129,
+	208,
+	191,
+	208,
+	187,
+	208,
+	176,
+	209,
+	130,
+	208,
+	189,
+	208,
+	190,
+	208,
+	188,
+	208,
+	176,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	208,
+	184,
+	208,
+	176,
+	208,
+	187,
+	209,
+	139,
+	208,
+	191,
+	208,
+	190,
+	208,
+	183,
+	208,
+	178,
+	208,
+	190,
+	208,
+	187,
+	209,
+	143,
+	208,
+	181,
+	209,
+	130,
+	208,
+	191,
+	208,
+	190,
+	209,
+	129,
+	208,
+	187,
+	208,
+	181,
+	208,
+	180,
+	208,
+	189,
+	208,
+	184,
+	208,
+	181,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	187,
+	208,
+	184,
+	209,
+	135,
+	208,
+	189,
+	209,
+	139,
+	209,
+	133,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	180,
+	209,
+	131,
+	208,
+	186,
+	209,
+	134,
+	208,
+	184,
+	208,
+	184,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	179,
+	209,
+	128,
+	208,
+	176,
+	208,
+	188,
+	208,
+	188,
+	208,
+	176,
+	208,
+	191,
+	208,
+	190,
+	208,
+	187,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	209,
+	142,
+	208,
+	189,
+	208,
+	176,
+	209,
+	133,
+	208,
+	190,
+	208,
+	180,
+	208,
+	184,
+	209,
+	130,
+	209,
+	129,
+	209,
+	143,
+	208,
+	184,
+	208,
+	183,
+	208,
+	177,
+	209,
+	128,
+	208,
+	176,
+	208,
+	189,
+	208,
+	189,
+	208,
+	190,
+	208,
+	181,
+	208,
+	189,
+	208,
+	176,
+	209,
+	129,
+	208,
+	181,
+	208,
+	187,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	184,
+	208,
+	183,
+	208,
+	188,
+	208,
+	181,
+	208,
+	189,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	186,
+	208,
+	176,
+	209,
+	130,
+	208,
+	181,
+	208,
+	179,
+	208,
+	190,
+	209,
+	128,
+	208,
+	184,
+	208,
+	184,
+	208,
+	144,
+	208,
+	187,
+	208,
+	181,
+	208,
+	186,
+	209,
+	129,
+	208,
+	176,
+	208,
+	189,
+	208,
+	180,
+	209,
+	128,
+	224,
+	164,
+	166,
+	224,
+	165,
+	141,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	165,
+	136,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	133,
+	224,
+	164,
+	178,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	166,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	175,
+	224,
+	164,
+	133,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	185,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	165,
+	141,
+	224,
+	164,
+	166,
+	224,
+	165,
+	128,
+	224,
+	164,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	161,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	191,
+	224,
+	164,
+	178,
+	224,
+	165,
+	141,
+	224,
+	164,
+	178,
+	224,
+	165,
+	128,
+	224,
+	164,
+	133,
+	224,
+	164,
+	167,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	181,
+	224,
+	165,
+	128,
+	224,
+	164,
+	161,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	154,
+	224,
+	164,
+	191,
+	224,
+	164,
+	159,
+	224,
+	165,
+	141,
+	224,
+	164,
+	160,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	154,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	156,
+	224,
+	164,
+	130,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	182,
+	224,
+	164,
+	168,
+	224,
+	164,
+	166,
+	224,
+	165,
+	129,
+	224,
+	164,
+	168,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	151,
+	224,
+	164,
+	133,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	145,
+	224,
+	164,
+	168,
+	224,
+	164,
+	178,
+	224,
+	164,
+	190,
+	224,
+	164,
+	135,
+	224,
+	164,
+	168,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	159,
+	224,
+	165,
+	128,
+	224,
+	164,
+	182,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	178,
+	224,
+	165,
+	139,
+	224,
+	164,
+	149,
+	224,
+	164,
+	184,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	171,
+	224,
+	164,
+	188,
+	224,
+	165,
+	141,
+	224,
+	164,
+	178,
+	224,
+	165,
+	136,
+	224,
+	164,
+	182,
+	224,
+	164,
+	182,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	178,
+	224,
+	165,
+	135,
+	224,
+	164,
+	175,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	166,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	191,
+	224,
+	164,
+	137,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	170,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	165,
+	141,
+	224,
+	164,
+	185,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	154,
+	224,
+	164,
+	191,
+	224,
+	164,
+	159,
+	224,
+	165,
+	141,
+	224,
+	164,
+	160,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	156,
+	224,
+	165,
+	139,
+	224,
+	164,
+	161,
+	224,
+	164,
+	188,
+	224,
+	165,
+	135,
+	224,
+	164,
+	130,
+	224,
+	164,
+	133,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	166,
+	224,
+	164,
+	182,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	163,
+	224,
+	165,
+	128,
+	224,
+	164,
+	182,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	183,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	185,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	164,
+	191,
+	224,
+	164,
+	163,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	172,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	161,
+	224,
+	164,
+	172,
+	224,
+	164,
+	154,
+	224,
+	165,
+	141,
+	224,
+	164,
+	154,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	137,
+	224,
+	164,
+	170,
+	224,
+	164,
+	178,
+	224,
+	164,
+	172,
+	224,
+	165,
+	141,
+	224,
+	164,
+	167,
+	224,
+	164,
+	174,
+	224,
+	164,
+	130,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	149,
+	224,
+	164,
+	137,
+	224,
+	164,
+	174,
+	224,
+	165,
+	141,
+	224,
+	164,
+	174,
+	224,
+	165,
+	128,
+	224,
+	164,
+	166,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	167,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	174,
+	224,
+	164,
+	184,
+	224,
+	164,
+	185,
+	224,
+	164,
+	190,
+	224,
+	164,
+	175,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	182,
+	224,
+	164,
+	172,
+	224,
+	165,
+	141,
+	224,
+	164,
+	166,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	174,
+	224,
+	165,
+	128,
+	224,
+	164,
+	161,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	134,
+	224,
+	164,
+	136,
+	224,
+	164,
+	170,
+	224,
+	165,
+	128,
+	224,
+	164,
+	143,
+	224,
+	164,
+	178,
+	224,
+	164,
+	174,
+	224,
+	165,
+	139,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	135,
+	224,
+	164,
+	178,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	150,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	134,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	168,
+	224,
+	164,
+	133,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	172,
+	224,
+	164,
+	130,
+	224,
+	164,
+	167,
+	224,
+	164,
+	172,
+	224,
+	164,
+	190,
+	224,
+	164,
+	156,
+	224,
+	164,
+	188,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	164,
+	181,
+	224,
+	165,
+	128,
+	224,
+	164,
+	168,
+	224,
+	164,
+	164,
+	224,
+	164,
+	174,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	174,
+	224,
+	165,
+	129,
+	224,
+	164,
+	150,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	182,
+	224,
+	165,
+	141,
+	224,
+	164,
+	168,
+	224,
+	164,
+	170,
+	224,
+	164,
+	176,
+	224,
+	164,
+	191,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	165,
+	129,
+	224,
+	164,
+	149,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	184,
+	224,
+	164,
+	174,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	168,
+	224,
+	164,
+	134,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	156,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	184,
+	224,
+	165,
+	139,
+	224,
+	164,
+	174,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	216,
+	177,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	217,
+	134,
+	216,
+	170,
+	216,
+	175,
+	217,
+	138,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	131,
+	217,
+	133,
+	216,
+	168,
+	217,
+	138,
+	217,
+	136,
+	216,
+	170,
+	216,
+	177,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	180,
+	216,
+	167,
+	217,
+	135,
+	216,
+	175,
+	216,
+	167,
+	216,
+	170,
+	216,
+	185,
+	216,
+	175,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	178,
+	217,
+	136,
+	216,
+	167,
+	216,
+	177,
+	216,
+	185,
+	216,
+	175,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	177,
+	216,
+	175,
+	217,
+	136,
+	216,
+	175,
+	216,
+	167,
+	217,
+	132,
+	216,
+	165,
+	216,
+	179,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	217,
+	129,
+	217,
+	136,
+	216,
+	170,
+	217,
+	136,
+	216,
+	180,
+	217,
+	136,
+	216,
+	168,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	179,
+	216,
+	167,
+	216,
+	168,
+	217,
+	130,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	185,
+	217,
+	132,
+	217,
+	136,
+	217,
+	133,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	179,
+	217,
+	132,
+	216,
+	179,
+	217,
+	132,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	172,
+	216,
+	177,
+	216,
+	167,
+	217,
+	129,
+	217,
+	138,
+	217,
+	131,
+	216,
+	179,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	179,
+	217,
+	132,
+	216,
+	167,
+	217,
+	133,
+	217,
+	138,
+	216,
+	169,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	170,
+	216,
+	181,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	170,
+	107,
+	101,
+	121,
+	119,
+	111,
+	114,
+	100,
+	115,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	119,
+	51,
+	46,
+	111,
+	114,
+	103,
+	47,
+	49,
+	57,
+	57,
+	57,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	34,
+	62,
+	60,
+	97,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	32,
+	116,
+	101,
+	120,
+	116,
+	47,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	62,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	61,
+	34,
+	97,
+	117,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	61,
+	34,
+	111,
+	102,
+	102,
+	34,
+	32,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	32,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	59,
+	116,
+	111,
+	32,
+	108,
+	97,
+	115,
+	116,
+	32,
+	118,
+	101,
+	114,
+	115,
+	105,
+	111,
+	110,
+	32,
+	98,
+	121,
+	32,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	99,
+	111,
+	108,
+	111,
+	114,
+	58,
+	32,
+	35,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	105,
+	100,
+	61,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	35,
+	34,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	34,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	47,
+	47,
+	69,
+	78,
+	34,
+	32,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	119,
+	101,
+	110,
+	99,
+	111,
+	100,
+	101,
+	85,
+	82,
+	73,
+	67,
+	111,
+	109,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	40,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	58,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	119,
+	114,
+	105,
+	116,
+	101,
+	40,
+	39,
+	60,
+	115,
+	99,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	32,
+	97,
+	98,
+	115,
+	111,
+	108,
+	117,
+	116,
+	101,
+	59,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	45,
+	116,
+	111,
+	112,
+	58,
+	46,
+	109,
+	105,
+	110,
+	46,
+	106,
+	115,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	119,
+	51,
+	46,
+	111,
+	114,
+	103,
+	47,
+	49,
+	57,
+	57,
+	57,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	34,
+	32,
+	10,
+	13,
+	10,
+	60,
+	47,
+	98,
+	111,
+	100,
+	121,
+	62,
+	13,
+	10,
+	60,
+	47,
+	104,
+	116,
+	109,
+	108,
+	62,
+	100,
+	105,
+	115,
+	116,
+	105,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	47,
+	34,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	62,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	101,
+	110,
+	99,
+	111,
+	100,
+	105,
+	110,
+	103,
+	61,
+	34,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	63,
+	62,
+	10,
+	119,
+	46,
+	97,
+	100,
+	100,
+	69,
+	118,
+	101,
+	110,
+	116,
+	76,
+	105,
+	115,
+	116,
+	101,
+	110,
+	101,
+	114,
+	63,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	58,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	10,
+	109,
+	101,
+	116,
+	97,
+	32,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	121,
+	61,
+	34,
+	111,
+	103,
+	58,
+	116,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	45,
+	97,
+	108,
+	105,
+	103,
+	110,
+	58,
+	116,
+	104,
+	101,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	117,
+	116,
+	102,
+	45,
+	56,
+	105,
+	115,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	98,
+	101,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	48,
+	48,
+	37,
+	34,
+	32,
+	73,
+	110,
+	32,
+	97,
+	100,
+	100,
+	105,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	99,
+	111,
+	110,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	99,
+	101,
+	115,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	73,
+	116,
+	32,
+	105,
+	115,
+	32,
+	105,
+	109,
+	112,
+	111,
+	114,
+	116,
+	97,
+	110,
+	116,
+	32,
+	116,
+	111,
+	32,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	102,
+	111,
+	110,
+	116,
+	45,
+	115,
+	105,
+	122,
+	101,
+	58,
+	49,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	105,
+	100,
+	61,
+	103,
+	98,
+	76,
+	105,
+	98,
+	114,
+	97,
+	114,
+	121,
+	32,
+	111,
+	102,
+	32,
+	67,
+	111,
+	110,
+	103,
+	114,
+	101,
+	115,
+	115,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	105,
+	109,
+	69,
+	110,
+	103,
+	108,
+	105,
+	115,
+	104,
+	32,
+	116,
+	114,
+	97,
+	110,
+	115,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	65,
+	99,
+	97,
+	100,
+	101,
+	109,
+	121,
+	32,
+	111,
+	102,
+	32,
+	83,
+	99,
+	105,
+	101,
+	110,
+	99,
+	101,
+	115,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	99,
+	111,
+	110,
+	115,
+	116,
+	114,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	66,
+	121,
+	73,
+	100,
+	40,
+	105,
+	100,
+	41,
+	105,
+	110,
+	32,
+	99,
+	111,
+	110,
+	106,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	119,
+	105,
+	116,
+	104,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	39,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	41,
+	59,
+	32,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	121,
+	61,
+	34,
+	111,
+	103,
+	58,
+	208,
+	145,
+	209,
+	138,
+	208,
+	187,
+	208,
+	179,
+	208,
+	176,
+	209,
+	128,
+	209,
+	129,
+	208,
+	186,
+	208,
+	184,
+	10,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	62,
+	80,
+	114,
+	105,
+	118,
+	97,
+	99,
+	121,
+	32,
+	80,
+	111,
+	108,
+	105,
+	99,
+	121,
+	60,
+	47,
+	97,
+	62,
+	97,
+	100,
+	109,
+	105,
+	110,
+	105,
+	115,
+	116,
+	101,
+	114,
+	101,
+	100,
+	32,
+	98,
+	121,
+	32,
+	116,
+	104,
+	101,
+	101,
+	110,
+	97,
+	98,
+	108,
+	101,
+	83,
+	105,
+	110,
+	103,
+	108,
+	101,
+	82,
+	101,
+	113,
+	117,
+	101,
+	115,
+	116,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	109,
+	97,
+	114,
+	103,
+	105,
+	110,
+	58,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	105,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	38,
+	113,
+	117,
+	111,
+	116,
+	59,
+	102,
+	108,
+	111,
+	97,
+	116,
+	58,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	97,
+	115,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	111,
+	116,
+	97,
+	108,
+	32,
+	112,
+	111,
+	112,
+	117,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	110,
+	32,
+	87,
+	97,
+	115,
+	104,
+	105,
+	110,
+	103,
+	116,
+	111,
+	110,
+	44,
+	32,
+	68,
+	46,
+	67,
+	46,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	97,
+	109,
+	111,
+	110,
+	103,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	32,
+	116,
+	104,
+	105,
+	110,
+	103,
+	115,
+	44,
+	111,
+	114,
+	103,
+	97,
+	110,
+	105,
+	122,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	101,
+	100,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	116,
+	114,
+	111,
+	100,
+	117,
+	99,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	105,
+	100,
+	101,
+	110,
+	116,
+	105,
+	102,
+	105,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	102,
+	105,
+	99,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	99,
+	104,
+	97,
+	114,
+	97,
+	99,
+	116,
+	101,
+	114,
+	32,
+	79,
+	120,
+	102,
+	111,
+	114,
+	100,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	32,
+	109,
+	105,
+	115,
+	117,
+	110,
+	100,
+	101,
+	114,
+	115,
+	116,
+	97,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	84,
+	104,
+	101,
+	114,
+	101,
+	32,
+	97,
+	114,
+	101,
+	44,
+	32,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	47,
+	67,
+	111,
+	108,
+	117,
+	109,
+	98,
+	105,
+	97,
+	32,
+	85,
+	110,
+	105,
+	118,
+	101,
+	114,
+	115,
+	105,
+	116,
+	121,
+	101,
+	120,
+	112,
+	97,
+	110,
+	100,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	117,
+	115,
+	117,
+	97,
+	108,
+	108,
+	121,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	105,
+	110,
+	100,
+	105,
+	99,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	118,
+	101,
+	32,
+	115,
+	117,
+	103,
+	103,
+	101,
+	115,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	97,
+	102,
+	102,
+	105,
+	108,
+	105,
+	97,
+	116,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	114,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	32,
+	111,
+	102,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	62,
+	60,
+	47,
+	116,
+	100,
+	62,
+	60,
+	47,
+	116,
+	114,
+	62,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	32,
+	111,
+	102,
+	32,
+	73,
+	114,
+	101,
+	108,
+	97,
+	110,
+	100,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	105,
+	110,
+	102,
+	108,
+	117,
+	101,
+	110,
+	99,
+	101,
+	99,
+	111,
+	110,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	79,
+	102,
+	102,
+	105,
+	99,
+	105,
+	97,
+	108,
+	32,
+	119,
+	101,
+	98,
+	115,
+	105,
+	116,
+	101,
+	32,
+	111,
+	102,
+	104,
+	101,
+	97,
+	100,
+	113,
+	117,
+	97,
+	114,
+	116,
+	101,
+	114,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	99,
+	101,
+	110,
+	116,
+	101,
+	114,
+	101,
+	100,
+	32,
+	97,
+	114,
+	111,
+	117,
+	110,
+	100,
+	32,
+	116,
+	104,
+	101,
+	105,
+	109,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	104,
+	97,
+	118,
+	101,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	70,
+	101,
+	100,
+	101,
+	114,
+	97,
+	108,
+	32,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	32,
+	111,
+	102,
+	98,
+	101,
+	99,
+	97,
+	109,
+	101,
+	32,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	105,
+	110,
+	103,
+	108,
+	121,
+	99,
+	111,
+	110,
+	116,
+	105,
+	110,
+	117,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	78,
+	111,
+	116,
+	101,
+	44,
+	32,
+	104,
+	111,
+	119,
+	101,
+	118,
+	101,
+	114,
+	44,
+	32,
+	116,
+	104,
+	97,
+	116,
+	115,
+	105,
+	109,
+	105,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	97,
+	116,
+	32,
+	111,
+	102,
+	32,
+	99,
+	97,
+	112,
+	97,
+	98,
+	105,
+	108,
+	105,
+	116,
+	105,
+	101,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	97,
+	99,
+	99,
+	111,
+	114,
+	100,
+	97,
+	110,
+	99,
+	101,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	110,
+	116,
+	115,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	102,
+	117,
+	114,
+	116,
+	104,
+	101,
+	114,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	117,
+	110,
+	100,
+	101,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	105,
+	111,
+	110,
+	105,
+	115,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	104,
+	105,
+	115,
+	32,
+	121,
+	111,
+	117,
+	110,
+	103,
+	101,
+	114,
+	32,
+	98,
+	114,
+	111,
+	116,
+	104,
+	101,
+	114,
+	60,
+	47,
+	116,
+	100,
+	62,
+	60,
+	47,
+	116,
+	114,
+	62,
+	60,
+	47,
+	116,
+	97,
+	98,
+	108,
+	101,
+	62,
+	60,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	88,
+	45,
+	85,
+	65,
+	45,
+	112,
+	104,
+	121,
+	115,
+	105,
+	99,
+	97,
+	108,
+	32,
+	112,
+	114,
+	111,
+	112,
+	101,
+	114,
+	116,
+	105,
+	101,
+	115,
+	111,
+	102,
+	32,
+	66,
+	114,
+	105,
+	116,
+	105,
+	115,
+	104,
+	32,
+	67,
+	111,
+	108,
+	117,
+	109,
+	98,
+	105,
+	97,
+	104,
+	97,
+	115,
+	32,
+	98,
+	101,
+	101,
+	110,
+	32,
+	99,
+	114,
+	105,
+	116,
+	105,
+	99,
+	105,
+	122,
+	101,
+	100,
+	40,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	113,
+	117,
+	101,
+	115,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	97,
+	98,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	112,
+	97,
+	115,
+	115,
+	105,
+	110,
+	103,
+	32,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	32,
+	116,
+	104,
+	101,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	116,
+	104,
+	111,
+	117,
+	115,
+	97,
+	110,
+	100,
+	115,
+	32,
+	111,
+	102,
+	32,
+	112,
+	101,
+	111,
+	112,
+	108,
+	101,
+	114,
+	101,
+	100,
+	105,
+	114,
+	101,
+	99,
+	116,
+	115,
+	32,
+	104,
+	101,
+	114,
+	101,
+	46,
+	32,
+	70,
+	111,
+	114,
+	104,
+	97,
+	118,
+	101,
+	32,
+	99,
+	104,
+	105,
+	108,
+	100,
+	114,
+	101,
+	110,
+	32,
+	117,
+	110,
+	100,
+	101,
+	114,
+	37,
+	51,
+	69,
+	37,
+	51,
+	67,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	37,
+	51,
+	69,
+	34,
+	41,
+	41,
+	59,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	115,
+	105,
+	116,
+	101,
+	95,
+	110,
+	97,
+	109,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	45,
+	100,
+	101,
+	99,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	58,
+	110,
+	111,
+	110,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	32,
+	110,
+	111,
+	110,
+	101,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	88,
+	45,
+	110,
+	101,
+	119,
+	32,
+	68,
+	97,
+	116,
+	101,
+	40,
+	41,
+	46,
+	103,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	40,
+	41,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	47,
+	120,
+	45,
+	105,
+	99,
+	111,
+	110,
+	34,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	119,
+	105,
+	110,
+	100,
+	111,
+	119,
+	46,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	104,
+	114,
+	101,
+	102,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	58,
+	45,
+	45,
+	62,
+	13,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	39,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	104,
+	111,
+	114,
+	116,
+	99,
+	117,
+	116,
+	32,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	13,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	116,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	47,
+	97,
+	62,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	32,
+	97,
+	108,
+	108,
+	111,
+	119,
+	84,
+	114,
+	97,
+	110,
+	115,
+	112,
+	97,
+	114,
+	101,
+	110,
+	99,
+	121,
+	61,
+	34,
+	88,
+	45,
+	85,
+	65,
+	45,
+	67,
+	111,
+	109,
+	112,
+	97,
+	116,
+	105,
+	98,
+	108,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	104,
+	105,
+	112,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	47,
+	117,
+	108,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	101,
+	100,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	105,
+	110,
+	103,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	60,
+	47,
+	97,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	102,
+	111,
+	114,
+	109,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	60,
+	100,
+	105,
+	118,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	113,
+	34,
+	60,
+	116,
+	97,
+	98,
+	108,
+	101,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	48,
+	48,
+	37,
+	34,
+	32,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	112,
+	111,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	58,
+	34,
+	32,
+	98,
+	111,
+	114,
+	100,
+	101,
+	114,
+	61,
+	34,
+	48,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	104,
+	111,
+	114,
+	116,
+	99,
+	117,
+	116,
+	32,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	104,
+	54,
+	62,
+	60,
+	117,
+	108,
+	62,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	32,
+	32,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	99,
+	115,
+	115,
+	34,
+	32,
+	109,
+	101,
+	100,
+	105,
+	97,
+	61,
+	34,
+	115,
+	99,
+	114,
+	101,
+	101,
+	110,
+	34,
+	32,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	115,
+	105,
+	98,
+	108,
+	101,
+	32,
+	102,
+	111,
+	114,
+	32,
+	116,
+	104,
+	101,
+	32,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	97,
+	112,
+	112,
+	108,
+	105,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	47,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	32,
+	97,
+	108,
+	108,
+	111,
+	119,
+	116,
+	114,
+	97,
+	110,
+	115,
+	112,
+	97,
+	114,
+	101,
+	110,
+	99,
+	121,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	13,
+	10,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	62,
+	60,
+	47,
+	115,
+	112,
+	97,
+	110,
+	62,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	62,
+	59,
+	10,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	111,
+	109,
+	101,
+	116,
+	105,
+	109,
+	101,
+	115,
+	32,
+	99,
+	97,
+	108,
+	108,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	100,
+	111,
+	101,
+	115,
+	32,
+	110,
+	111,
+	116,
+	32,
+	110,
+	101,
+	99,
+	101,
+	115,
+	115,
+	97,
+	114,
+	105,
+	108,
+	121,
+	70,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	32,
+	60,
+	33,
+	68,
+	79,
+	67,
+	84,
+	89,
+	80,
+	69,
+	32,
+	104,
+	116,
+	109,
+	108,
+	62,
+	60,
+	104,
+	116,
+	109,
+	108,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	108,
+	121,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	58,
+	118,
+	111,
+	105,
+	100,
+	40,
+	48,
+	41,
+	59,
+	34,
+	101,
+	102,
+	102,
+	101,
+	99,
+	116,
+	105,
+	118,
+	101,
+	110,
+	101,
+	115,
+	115,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	97,
+	117,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	61,
+	34,
+	111,
+	102,
+	102,
+	34,
+	32,
+	103,
+	101,
+	110,
+	101,
+	114,
+	97,
+	108,
+	108,
+	121,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	62,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	34,
+	62,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	13,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	116,
+	104,
+	114,
+	111,
+	117,
+	103,
+	104,
+	111,
+	117,
+	116,
+	32,
+	116,
+	104,
+	101,
+	32,
+	119,
+	111,
+	114,
+	108,
+	100,
+	99,
+	111,
+	109,
+	109,
+	111,
+	110,
+	32,
+	109,
+	105,
+	115,
+	99,
+	111,
+	110,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	97,
+	115,
+	115,
+	111,
+	99,
+	105,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	100,
+	117,
+	114,
+	105,
+	110,
+	103,
+	32,
+	104,
+	105,
+	115,
+	32,
+	108,
+	105,
+	102,
+	101,
+	116,
+	105,
+	109,
+	101,
+	44,
+	99,
+	111,
+	114,
+	114,
+	101,
+	115,
+	112,
+	111,
+	110,
+	100,
+	105,
+	110,
+	103,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	47,
+	120,
+	45,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	97,
+	110,
+	32,
+	105,
+	110,
+	99,
+	114,
+	101,
+	97,
+	115,
+	105,
+	110,
+	103,
+	32,
+	110,
+	117,
+	109,
+	98,
+	101,
+	114,
+	100,
+	105,
+	112,
+	108,
+	111,
+	109,
+	97,
+	116,
+	105,
+	99,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	105,
+	111,
+	110,
+	115,
+	97,
+	114,
+	101,
+	32,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	109,
+	101,
+	116,
+	97,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	34,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	32,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	101,
+	120,
+	97,
+	109,
+	112,
+	108,
+	101,
+	115,
+	32,
+	105,
+	110,
+	99,
+	108,
+	117,
+	100,
+	101,
+	32,
+	116,
+	104,
+	101,
+	34,
+	62,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	105,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	116,
+	104,
+	101,
+	32,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	38,
+	97,
+	109,
+	112,
+	59,
+	110,
+	98,
+	115,
+	112,
+	59,
+	38,
+	97,
+	109,
+	112,
+	59,
+	110,
+	98,
+	115,
+	112,
+	59,
+	116,
+	111,
+	32,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	101,
+	32,
+	119,
+	104,
+	101,
+	116,
+	104,
+	101,
+	114,
+	113,
+	117,
+	105,
+	116,
+	101,
+	32,
+	100,
+	105,
+	102,
+	102,
+	101,
+	114,
+	101,
+	110,
+	116,
+	32,
+	102,
+	114,
+	111,
+	109,
+	109,
+	97,
+	114,
+	107,
+	101,
+	100,
+	32,
+	116,
+	104,
+	101,
+	32,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	100,
+	105,
+	115,
+	116,
+	97,
+	110,
+	99,
+	101,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	116,
+	114,
+	105,
+	98,
+	117,
+	116,
+	105,
+	111,
+	110,
+	115,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	99,
+	111,
+	110,
+	102,
+	108,
+	105,
+	99,
+	116,
+	32,
+	98,
+	101,
+	116,
+	119,
+	101,
+	101,
+	110,
+	32,
+	116,
+	104,
+	101,
+	119,
+	105,
+	100,
+	101,
+	108,
+	121,
+	32,
+	99,
+	111,
+	110,
+	115,
+	105,
+	100,
+	101,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	119,
+	97,
+	115,
+	32,
+	111,
+	110,
+	101,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	102,
+	105,
+	114,
+	115,
+	116,
+	119,
+	105,
+	116,
+	104,
+	32,
+	118,
+	97,
+	114,
+	121,
+	105,
+	110,
+	103,
+	32,
+	100,
+	101,
+	103,
+	114,
+	101,
+	101,
+	115,
+	104,
+	97,
+	118,
+	101,
+	32,
+	115,
+	112,
+	101,
+	99,
+	117,
+	108,
+	97,
+	116,
+	101,
+	100,
+	32,
+	116,
+	104,
+	97,
+	116,
+	40,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	112,
+	97,
+	114,
+	116,
+	105,
+	99,
+	105,
+	112,
+	97,
+	116,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	32,
+	116,
+	104,
+	101,
+	111,
+	114,
+	105,
+	103,
+	105,
+	110,
+	97,
+	108,
+	108,
+	121,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	101,
+	100,
+	101,
+	116,
+	97,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	34,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	62,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	47,
+	62,
+	10,
+	105,
+	110,
+	116,
+	101,
+	114,
+	99,
+	104,
+	97,
+	110,
+	103,
+	101,
+	97,
+	98,
+	108,
+	121,
+	32,
+	119,
+	105,
+	116,
+	104,
+	109,
+	111,
+	114,
+	101,
+	32,
+	99,
+	108,
+	111,
+	115,
+	101,
+	108,
+	121,
+	32,
+	114,
+	101,
+	108,
+	97,
+	116,
+	101,
+	100,
+	115,
+	111,
+	99,
+	105,
+	97,
+	108,
+	32,
+	97,
+	110,
+	100,
+	32,
+	112,
+	111,
+	108,
+	105,
+	116,
+	105,
+	99,
+	97,
+	108,
+	116,
+	104,
+	97,
+	116,
+	32,
+	119,
+	111,
+	117,
+	108,
+	100,
+	32,
+	111,
+	116,
+	104,
+	101,
+	114,
+	119,
+	105,
+	115,
+	101,
+	112,
+	101,
+	114,
+	112,
+	101,
+	110,
+	100,
+	105,
+	99,
+	117,
+	108,
+	97,
+	114,
+	32,
+	116,
+	111,
+	32,
+	116,
+	104,
+	101,
+	115,
+	116,
+	121,
+	108,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	102,
+	97,
+	109,
+	105,
+	108,
+	105,
+	101,
+	115,
+	32,
+	114,
+	101,
+	115,
+	105,
+	100,
+	105,
+	110,
+	103,
+	32,
+	105,
+	110,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	105,
+	110,
+	103,
+	32,
+	99,
+	111,
+	117,
+	110,
+	116,
+	114,
+	105,
+	101,
+	115,
+	99,
+	111,
+	109,
+	112,
+	117,
+	116,
+	101,
+	114,
+	32,
+	112,
+	114,
+	111,
+	103,
+	114,
+	97,
+	109,
+	109,
+	105,
+	110,
+	103,
+	101,
+	99,
+	111,
+	110,
+	111,
+	109,
+	105,
+	99,
+	32,
+	100,
+	101,
+	118,
+	101,
+	108,
+	111,
+	112,
+	109,
+	101,
+	110,
+	116,
+	100,
+	101,
+	116,
+	101,
+	114,
+	109,
+	105,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	102,
+	111,
+	114,
+	32,
+	109,
+	111,
+	114,
+	101,
+	32,
+	105,
+	110,
+	102,
+	111,
+	114,
+	109,
+	97,
+	116,
+	105,
+	111,
+	110,
+	111,
+	110,
+	32,
+	115,
+	101,
+	118,
+	101,
+	114,
+	97,
+	108,
+	32,
+	111,
+	99,
+	99,
+	97,
+	115,
+	105,
+	111,
+	110,
+	115,
+	112,
+	111,
+	114,
+	116,
+	117,
+	103,
+	117,
+	195,
+	170,
+	115,
+	32,
+	40,
+	69,
+	117,
+	114,
+	111,
+	112,
+	101,
+	117,
+	41,
+	208,
+	163,
+	208,
+	186,
+	209,
+	128,
+	208,
+	176,
+	209,
+	151,
+	208,
+	189,
+	209,
+	129,
+	209,
+	140,
+	208,
+	186,
+	208,
+	176,
+	209,
+	131,
+	208,
+	186,
+	209,
+	128,
+	208,
+	176,
+	209,
+	151,
+	208,
+	189,
+	209,
+	129,
+	209,
+	140,
+	208,
+	186,
+	208,
+	176,
+	208,
+	160,
+	208,
+	190,
+	209,
+	129,
+	209,
+	129,
+	208,
+	184,
+	208,
+	185,
+	209,
+	129,
+	208,
+	186,
+	208,
+	190,
+	208,
+	185,
+	208,
+	188,
+	208,
+	176,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	208,
+	184,
+	208,
+	176,
+	208,
+	187,
+	208,
+	190,
+	208,
+	178,
+	208,
+	184,
+	208,
+	189,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	208,
+	188,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	208,
+	184,
+	209,
+	131,
+	208,
+	191,
+	209,
+	128,
+	208,
+	176,
+	208,
+	178,
+	208,
+	187,
+	208,
+	181,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	208,
+	189,
+	208,
+	181,
+	208,
+	190,
+	208,
+	177,
+	209,
+	133,
+	208,
+	190,
+	208,
+	180,
+	208,
+	184,
+	208,
+	188,
+	208,
+	190,
+	208,
+	184,
+	208,
+	189,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	208,
+	188,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	209,
+	143,
+	208,
+	152,
+	208,
+	189,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	208,
+	188,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	209,
+	143,
+	208,
+	160,
+	208,
+	181,
+	209,
+	129,
+	208,
+	191,
+	209,
+	131,
+	208,
+	177,
+	208,
+	187,
+	208,
+	184,
+	208,
+	186,
+	208,
+	184,
+	208,
+	186,
+	208,
+	190,
+	208,
+	187,
+	208,
+	184,
+	209,
+	135,
+	208,
+	181,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	190,
+	208,
+	184,
+	208,
+	189,
+	209,
+	132,
+	208,
+	190,
+	209,
+	128,
+	208,
+	188,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	209,
+	142,
+	209,
+	130,
+	208,
+	181,
+	209,
+	128,
+	209,
+	128,
+	208,
+	184,
+	209,
+	130,
+	208,
+	190,
+	209,
+	128,
+	208,
+	184,
+	208,
+	184,
+	208,
+	180,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	176,
+	209,
+	130,
+	208,
+	190,
+	209,
+	135,
+	208,
+	189,
+	208,
+	190,
+	216,
+	167,
+	217,
+	132,
+	217,
+	133,
+	216,
+	170,
+	217,
+	136,
+	216,
+	167,
+	216,
+	172,
+	216,
+	175,
+	217,
+	136,
+	217,
+	134,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	216,
+	180,
+	216,
+	170,
+	216,
+	177,
+	216,
+	167,
+	217,
+	131,
+	216,
+	167,
+	216,
+	170,
+	216,
+	167,
+	217,
+	132,
+	216,
+	167,
+	217,
+	130,
+	216,
+	170,
+	216,
+	177,
+	216,
+	167,
+	216,
+	173,
+	216,
+	167,
+	216,
+	170,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	85,
+	84,
+	70,
+	45,
+	56,
+	34,
+	32,
+	115,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	111,
+	117,
+	116,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	105,
+	110,
+	108,
+	105,
+	110,
+	101,
+	45,
+	98,
+	108,
+	111,
+	99,
+	107,
+	59,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	115,
+	117,
+	98,
+	109,
+	105,
+	116,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	32,
+	61,
+	32,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	60,
+	105,
+	109,
+	103,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	34,
+	32,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	119,
+	51,
+	46,
+	111,
+	114,
+	103,
+	47,
+	115,
+	104,
+	111,
+	114,
+	116,
+	99,
+	117,
+	116,
+	32,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	34,
+	32,
+	97,
+	117,
+	116,
+	111,
+	99,
+	111,
+	109,
+	112,
+	108,
+	101,
+	116,
+	101,
+	61,
+	34,
+	111,
+	102,
+	102,
+	34,
+	32,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	10,
+	60,
+	108,
+	105,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	34,
+	99,
+	115,
+	115,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	60,
+	102,
+	111,
+	114,
+	109,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	101,
+	34,
+	32,
+	13,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	32,
+	111,
+	110,
+	99,
+	108,
+	105,
+	99,
+	107,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	58,
+	40,
+	110,
+	101,
+	119,
+	32,
+	68,
+	97,
+	116,
+	101,
+	41,
+	46,
+	103,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	40,
+	41,
+	125,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	34,
+	32,
+	80,
+	101,
+	111,
+	112,
+	108,
+	101,
+	39,
+	115,
+	32,
+	82,
+	101,
+	112,
+	117,
+	98,
+	108,
+	105,
+	99,
+	32,
+	111,
+	102,
+	32,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	116,
+	101,
+	120,
+	116,
+	45,
+	100,
+	101,
+	99,
+	111,
+	114,
+	97,
+	116,
+	105,
+	111,
+	110,
+	58,
+	117,
+	110,
+	100,
+	101,
+	114,
+	116,
+	104,
+	101,
+	32,
+	98,
+	101,
+	103,
+	105,
+	110,
+	110,
+	105,
+	110,
+	103,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	10,
+	101,
+	115,
+	116,
+	97,
+	98,
+	108,
+	105,
+	115,
+	104,
+	109,
+	101,
+	110,
+	116,
+	32,
+	111,
+	102,
+	32,
+	116,
+	104,
+	101,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	35,
+	118,
+	105,
+	101,
+	119,
+	112,
+	111,
+	114,
+	116,
+	123,
+	109,
+	105,
+	110,
+	45,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	58,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	115,
+	114,
+	99,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	62,
+	60,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	111,
+	102,
+	116,
+	101,
+	110,
+	32,
+	114,
+	101,
+	102,
+	101,
+	114,
+	114,
+	101,
+	100,
+	32,
+	116,
+	111,
+	32,
+	97,
+	115,
+	32,
+	47,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	62,
+	10,
+	60,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	118,
+	97,
+	108,
+	117,
+	60,
+	33,
+	68,
+	79,
+	67,
+	84,
+	89,
+	80,
+	69,
+	32,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	60,
+	33,
+	45,
+	45,
+	91,
+	73,
+	110,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	32,
+	65,
+	105,
+	114,
+	112,
+	111,
+	114,
+	116,
+	62,
+	10,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	60,
+	47,
+	97,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	224,
+	184,
+	160,
+	224,
+	184,
+	178,
+	224,
+	184,
+	169,
+	224,
+	184,
+	178,
+	224,
+	185,
+	132,
+	224,
+	184,
+	151,
+	224,
+	184,
+	162,
+	225,
+	131,
+	165,
+	225,
+	131,
+	144,
+	225,
+	131,
+	160,
+	225,
+	131,
+	151,
+	225,
+	131,
+	163,
+	225,
+	131,
+	154,
+	225,
+	131,
+	152,
+	230,
+	173,
+	163,
+	233,
+	171,
+	148,
+	228,
+	184,
+	173,
+	230,
+	150,
+	135,
+	32,
+	40,
+	231,
+	185,
+	129,
+	233,
+	171,
+	148,
+	41,
+	224,
+	164,
+	168,
+	224,
+	164,
+	191,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	166,
+	224,
+	165,
+	135,
+	224,
+	164,
+	182,
+	224,
+	164,
+	161,
+	224,
+	164,
+	190,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	164,
+	178,
+	224,
+	165,
+	139,
+	224,
+	164,
+	161,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	183,
+	224,
+	165,
+	135,
+	224,
+	164,
+	164,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	156,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	172,
+	224,
+	164,
+	130,
+	224,
+	164,
+	167,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	165,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	164,
+	190,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	181,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	184,
+	224,
+	164,
+	130,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	149,
+	224,
+	164,
+	176,
+	224,
+	164,
+	163,
+	224,
+	164,
+	184,
+	224,
+	164,
+	190,
+	224,
+	164,
+	174,
+	224,
+	164,
+	151,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	128,
+	224,
+	164,
+	154,
+	224,
+	164,
+	191,
+	224,
+	164,
+	159,
+	224,
+	165,
+	141,
+	224,
+	164,
+	160,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	156,
+	224,
+	165,
+	141,
+	224,
+	164,
+	158,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	133,
+	224,
+	164,
+	174,
+	224,
+	165,
+	135,
+	224,
+	164,
+	176,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	173,
+	224,
+	164,
+	191,
+	224,
+	164,
+	168,
+	224,
+	165,
+	141,
+	224,
+	164,
+	168,
+	224,
+	164,
+	151,
+	224,
+	164,
+	190,
+	224,
+	164,
+	161,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	190,
+	224,
+	164,
+	129,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	175,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	149,
+	224,
+	164,
+	191,
+	224,
+	164,
+	184,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	183,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	185,
+	224,
+	165,
+	129,
+	224,
+	164,
+	129,
+	224,
+	164,
+	154,
+	224,
+	164,
+	164,
+	224,
+	165,
+	128,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	172,
+	224,
+	164,
+	130,
+	224,
+	164,
+	167,
+	224,
+	164,
+	168,
+	224,
+	164,
+	159,
+	224,
+	164,
+	191,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	170,
+	224,
+	164,
+	163,
+	224,
+	165,
+	128,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	165,
+	135,
+	224,
+	164,
+	159,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	130,
+	224,
+	164,
+	173,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	176,
+	224,
+	164,
+	171,
+	224,
+	164,
+	188,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	164,
+	168,
+	224,
+	164,
+	191,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	174,
+	224,
+	164,
+	190,
+	224,
+	164,
+	163,
+	224,
+	164,
+	178,
+	224,
+	164,
+	191,
+	224,
+	164,
+	174,
+	224,
+	164,
+	191,
+	224,
+	164,
+	159,
+	224,
+	165,
+	135,
+	224,
+	164,
+	161,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	108,
+	111,
+	99,
+	97,
+	116,
+	105,
+	111,
+	110,
+	46,
+	112,
+	114,
+	111,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	66,
+	121,
+	84,
+	97,
+	103,
+	78,
+	97,
+	109,
+	101,
+	40,
+	60,
+	33,
+	68,
+	79,
+	67,
+	84,
+	89,
+	80,
+	69,
+	32,
+	104,
+	116,
+	109,
+	108,
+	62,
+	10,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	34,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	62,
+	58,
+	117,
+	114,
+	108,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	46,
+	99,
+	115,
+	115,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	62,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	119,
+	51,
+	46,
+	111,
+	114,
+	103,
+	47,
+	49,
+	57,
+	57,
+	57,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	34,
+	32,
+	120,
+	109,
+	108,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	103,
+	101,
+	116,
+	34,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	32,
+	61,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	105,
+	109,
+	97,
+	103,
+	101,
+	47,
+	120,
+	45,
+	105,
+	99,
+	111,
+	110,
+	34,
+	32,
+	47,
+	62,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	100,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	115,
+	112,
+	46,
+	99,
+	115,
+	115,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	60,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	34,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	34,
+	34,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	34,
+	62,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	101,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	97,
+	112,
+	112,
+	108,
+	105,
+	45,
+	47,
+	47,
+	87,
+	51,
+	67,
+	47,
+	47,
+	68,
+	84,
+	68,
+	32,
+	88,
+	72,
+	84,
+	77,
+	76,
+	32,
+	49,
+	46,
+	48,
+	32,
+	101,
+	108,
+	108,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	112,
+	97,
+	100,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	61,
+	34,
+	47,
+	97,
+	62,
+	38,
+	110,
+	98,
+	115,
+	112,
+	59,
+	60,
+	115,
+	112,
+	97,
+	110,
+	32,
+	114,
+	111,
+	108,
+	101,
+	61,
+	34,
+	115,
+	10,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	74,
+	97,
+	118,
+	97,
+	83,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	66,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	109,
+	101,
+	100,
+	105,
+	97,
+	61,
+	34,
+	116,
+	121,
+	112,
+	101,
+	61,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	119,
+	105,
+	116,
+	104,
+	32,
+	116,
+	104,
+	101,
+	32,
+	101,
+	120,
+	99,
+	101,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	111,
+	102,
+	32,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	32,
+	104,
+	101,
+	105,
+	103,
+	104,
+	116,
+	61,
+	34,
+	49,
+	34,
+	32,
+	119,
+	105,
+	100,
+	116,
+	104,
+	61,
+	34,
+	49,
+	34,
+	32,
+	61,
+	39,
+	43,
+	101,
+	110,
+	99,
+	111,
+	100,
+	101,
+	85,
+	82,
+	73,
+	67,
+	111,
+	109,
+	112,
+	111,
+	110,
+	101,
+	110,
+	116,
+	40,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	97,
+	108,
+	116,
+	101,
+	114,
+	110,
+	97,
+	116,
+	101,
+	34,
+	32,
+	10,
+	98,
+	111,
+	100,
+	121,
+	44,
+	32,
+	116,
+	114,
+	44,
+	32,
+	105,
+	110,
+	112,
+	117,
+	116,
+	44,
+	32,
+	116,
+	101,
+	120,
+	116,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	114,
+	111,
+	98,
+	111,
+	116,
+	115,
+	34,
+	32,
+	99,
+	111,
+	110,
+	109,
+	101,
+	116,
+	104,
+	111,
+	100,
+	61,
+	34,
+	112,
+	111,
+	115,
+	116,
+	34,
+	32,
+	97,
+	99,
+	116,
+	105,
+	111,
+	110,
+	61,
+	34,
+	62,
+	10,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	99,
+	115,
+	115,
+	34,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	62,
+	97,
+	114,
+	105,
+	97,
+	45,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	61,
+	34,
+	116,
+	114,
+	117,
+	101,
+	34,
+	62,
+	194,
+	183,
+	60,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	108,
+	61,
+	48,
+	59,
+	125,
+	41,
+	40,
+	41,
+	59,
+	10,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	111,
+	110,
+	40,
+	41,
+	123,
+	98,
+	97,
+	99,
+	107,
+	103,
+	114,
+	111,
+	117,
+	110,
+	100,
+	45,
+	105,
+	109,
+	97,
+	103,
+	101,
+	58,
+	32,
+	117,
+	114,
+	108,
+	40,
+	47,
+	97,
+	62,
+	60,
+	47,
+	108,
+	105,
+	62,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	9,
+	9,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	97,
+	116,
+	111,
+	114,
+	34,
+	32,
+	97,
+	114,
+	105,
+	97,
+	45,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	61,
+	34,
+	116,
+	114,
+	117,
+	62,
+	32,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	108,
+	97,
+	110,
+	103,
+	117,
+	97,
+	103,
+	101,
+	61,
+	34,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	34,
+	32,
+	47,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	62,
+	10,
+	60,
+	111,
+	112,
+	116,
+	105,
+	111,
+	110,
+	32,
+	118,
+	97,
+	108,
+	117,
+	101,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	47,
+	100,
+	105,
+	118,
+	62,
+	60,
+	100,
+	105,
+	118,
+	32,
+	99,
+	108,
+	97,
+	115,
+	115,
+	61,
+	114,
+	97,
+	116,
+	111,
+	114,
+	34,
+	32,
+	97,
+	114,
+	105,
+	97,
+	45,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	61,
+	34,
+	116,
+	114,
+	101,
+	61,
+	40,
+	110,
+	101,
+	119,
+	32,
+	68,
+	97,
+	116,
+	101,
+	41,
+	46,
+	103,
+	101,
+	116,
+	84,
+	105,
+	109,
+	101,
+	40,
+	41,
+	112,
+	111,
+	114,
+	116,
+	117,
+	103,
+	117,
+	195,
+	170,
+	115,
+	32,
+	40,
+	100,
+	111,
+	32,
+	66,
+	114,
+	97,
+	115,
+	105,
+	108,
+	41,
+	208,
+	190,
+	209,
+	128,
+	208,
+	179,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	208,
+	183,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	208,
+	184,
+	208,
+	178,
+	208,
+	190,
+	208,
+	183,
+	208,
+	188,
+	208,
+	190,
+	208,
+	182,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	209,
+	140,
+	208,
+	190,
+	208,
+	177,
+	209,
+	128,
+	208,
+	176,
+	208,
+	183,
+	208,
+	190,
+	208,
+	178,
+	208,
+	176,
+	208,
+	189,
+	208,
+	184,
+	209,
+	143,
+	209,
+	128,
+	208,
+	181,
+	208,
+	179,
+	208,
+	184,
+	209,
+	129,
+	209,
+	130,
+	209,
+	128,
+	208,
+	176,
+	209,
+	134,
+	208,
+	184,
+	208,
+	184,
+	208,
+	178,
+	208,
+	190,
+	208,
+	183,
+	208,
+	188,
+	208,
+	190,
+	208,
+	182,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	208,
+	190,
+	208,
+	177,
+	209,
+	143,
+	208,
+	183,
+	208,
+	176,
+	209,
+	130,
+	208,
+	181,
+	208,
+	187,
+	209,
+	140,
+	208,
+	189,
+	208,
+	176,
+	60,
+	33,
+	68,
+	79,
+	67,
+	84,
+	89,
+	80,
+	69,
+	32,
+	104,
+	116,
+	109,
+	108,
+	32,
+	80,
+	85,
+	66,
+	76,
+	73,
+	67,
+	32,
+	34,
+	110,
+	116,
+	45,
+	84,
+	121,
+	112,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	67,
+	111,
+	110,
+	116,
+	101,
+	114,
+	97,
+	110,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	47,
+	47,
+	69,
+	78,
+	34,
+	32,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	120,
+	109,
+	108,
+	110,
+	115,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	45,
+	47,
+	47,
+	87,
+	51,
+	67,
+	47,
+	47,
+	68,
+	84,
+	68,
+	32,
+	88,
+	72,
+	84,
+	77,
+	76,
+	32,
+	49,
+	46,
+	48,
+	32,
+	84,
+	68,
+	84,
+	68,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	49,
+	45,
+	116,
+	114,
+	97,
+	110,
+	115,
+	105,
+	116,
+	105,
+	111,
+	110,
+	97,
+	108,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	119,
+	51,
+	46,
+	111,
+	114,
+	103,
+	47,
+	84,
+	82,
+	47,
+	120,
+	104,
+	116,
+	109,
+	108,
+	49,
+	47,
+	112,
+	101,
+	32,
+	61,
+	32,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	59,
+	60,
+	109,
+	101,
+	116,
+	97,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	100,
+	101,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	105,
+	111,
+	110,
+	112,
+	97,
+	114,
+	101,
+	110,
+	116,
+	78,
+	111,
+	100,
+	101,
+	46,
+	105,
+	110,
+	115,
+	101,
+	114,
+	116,
+	66,
+	101,
+	102,
+	111,
+	114,
+	101,
+	60,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	110,
+	97,
+	106,
+	115,
+	34,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	40,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	41,
+	46,
+	114,
+	101,
+	97,
+	100,
+	121,
+	40,
+	102,
+	117,
+	110,
+	99,
+	116,
+	105,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	105,
+	109,
+	97,
+	103,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	85,
+	65,
+	45,
+	67,
+	111,
+	109,
+	112,
+	97,
+	116,
+	105,
+	98,
+	108,
+	101,
+	34,
+	32,
+	99,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	61,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	32,
+	47,
+	62,
+	10,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	104,
+	111,
+	114,
+	116,
+	99,
+	117,
+	116,
+	32,
+	105,
+	99,
+	111,
+	110,
+	60,
+	108,
+	105,
+	110,
+	107,
+	32,
+	114,
+	101,
+	108,
+	61,
+	34,
+	115,
+	116,
+	121,
+	108,
+	101,
+	115,
+	104,
+	101,
+	101,
+	116,
+	34,
+	32,
+	60,
+	47,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	62,
+	10,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	61,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	60,
+	97,
+	32,
+	116,
+	97,
+	114,
+	103,
+	101,
+	116,
+	61,
+	34,
+	95,
+	98,
+	108,
+	97,
+	110,
+	107,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	32,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	66,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	97,
+	46,
+	116,
+	121,
+	112,
+	101,
+	32,
+	61,
+	32,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	32,
+	47,
+	62,
+	100,
+	116,
+	100,
+	34,
+	62,
+	10,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	120,
+	109,
+	108,
+	110,
+	115,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	45,
+	47,
+	47,
+	87,
+	51,
+	67,
+	47,
+	47,
+	68,
+	84,
+	68,
+	32,
+	72,
+	84,
+	77,
+	76,
+	32,
+	52,
+	46,
+	48,
+	49,
+	32,
+	84,
+	101,
+	110,
+	116,
+	115,
+	66,
+	121,
+	84,
+	97,
+	103,
+	78,
+	97,
+	109,
+	101,
+	40,
+	39,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	41,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	104,
+	105,
+	100,
+	100,
+	101,
+	110,
+	34,
+	32,
+	110,
+	97,
+	109,
+	60,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	34,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	34,
+	62,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	66,
+	121,
+	73,
+	100,
+	40,
+	61,
+	100,
+	111,
+	99,
+	117,
+	109,
+	101,
+	110,
+	116,
+	46,
+	99,
+	114,
+	101,
+	97,
+	116,
+	101,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	40,
+	39,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	105,
+	110,
+	112,
+	117,
+	116,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	34,
+	32,
+	110,
+	97,
+	109,
+	101,
+	61,
+	34,
+	100,
+	46,
+	103,
+	101,
+	116,
+	69,
+	108,
+	101,
+	109,
+	101,
+	110,
+	116,
+	115,
+	66,
+	121,
+	84,
+	97,
+	103,
+	78,
+	97,
+	109,
+	101,
+	40,
+	115,
+	110,
+	105,
+	99,
+	97,
+	108,
+	34,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	67,
+	47,
+	47,
+	68,
+	84,
+	68,
+	32,
+	72,
+	84,
+	77,
+	76,
+	32,
+	52,
+	46,
+	48,
+	49,
+	32,
+	84,
+	114,
+	97,
+	110,
+	115,
+	105,
+	116,
+	60,
+	115,
+	116,
+	121,
+	108,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	62,
+	10,
+	10,
+	60,
+	115,
+	116,
+	121,
+	108,
+	101,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	34,
+	116,
+	101,
+	120,
+	116,
+	47,
+	99,
+	115,
+	115,
+	34,
+	62,
+	105,
+	111,
+	110,
+	97,
+	108,
+	46,
+	100,
+	116,
+	100,
+	34,
+	62,
+	10,
+	60,
+	104,
+	116,
+	109,
+	108,
+	32,
+	120,
+	109,
+	108,
+	110,
+	115,
+	61,
+	104,
+	116,
+	116,
+	112,
+	45,
+	101,
+	113,
+	117,
+	105,
+	118,
+	61,
+	34,
+	67,
+	111,
+	110,
+	116,
+	101,
+	110,
+	116,
+	45,
+	84,
+	121,
+	112,
+	101,
+	100,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	32,
+	99,
+	101,
+	108,
+	108,
+	115,
+	112,
+	97,
+	99,
+	105,
+	110,
+	103,
+	61,
+	34,
+	48,
+	34,
+	104,
+	116,
+	109,
+	108,
+	59,
+	32,
+	99,
+	104,
+	97,
+	114,
+	115,
+	101,
+	116,
+	61,
+	117,
+	116,
+	102,
+	45,
+	56,
+	34,
+	32,
+	47,
+	62,
+	10,
+	32,
+	115,
+	116,
+	121,
+	108,
+	101,
+	61,
+	34,
+	100,
+	105,
+	115,
+	112,
+	108,
+	97,
+	121,
+	58,
+	110,
+	111,
+	110,
+	101,
+	59,
+	34,
+	62,
+	60,
+	60,
+	108,
+	105,
+	62,
+	60,
+	97,
+	32,
+	104,
+	114,
+	101,
+	102,
+	61,
+	34,
+	104,
+	116,
+	116,
+	112,
+	58,
+	47,
+	47,
+	119,
+	119,
+	119,
+	46,
+	32,
+	116,
+	121,
+	112,
+	101,
+	61,
+	39,
+	116,
+	101,
+	120,
+	116,
+	47,
+	106,
+	97,
+	118,
+	97,
+	115,
+	99,
+	114,
+	105,
+	112,
+	116,
+	39,
+	62,
+	208,
+	180,
+	208,
+	181,
+	209,
+	143,
+	209,
+	130,
+	208,
+	181,
+	208,
+	187,
+	209,
+	140,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	209,
+	129,
+	208,
+	190,
+	208,
+	190,
+	209,
+	130,
+	208,
+	178,
+	208,
+	181,
+	209,
+	130,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	184,
+	208,
+	184,
+	208,
+	191,
+	209,
+	128,
+	208,
+	190,
+	208,
+	184,
+	208,
+	183,
+	208,
+	178,
+	208,
+	190,
+	208,
+	180,
+	209,
+	129,
+	209,
+	130,
+	208,
+	178,
+	208,
+	176,
+	208,
+	177,
+	208,
+	181,
+	208,
+	183,
+	208,
+	190,
+	208,
+	191,
+	208,
+	176,
+	209,
+	129,
+	208,
+	189,
+	208,
+	190,
+	209,
+	129,
+	209,
+	130,
+	208,
+	184,
+	224,
+	164,
+	170,
+	224,
+	165,
+	129,
+	224,
+	164,
+	184,
+	224,
+	165,
+	141,
+	224,
+	164,
+	164,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	165,
+	135,
+	224,
+	164,
+	184,
+	224,
+	164,
+	137,
+	224,
+	164,
+	168,
+	224,
+	165,
+	141,
+	224,
+	164,
+	185,
+	224,
+	165,
+	139,
+	224,
+	164,
+	130,
+	224,
+	164,
+	168,
+	224,
+	165,
+	135,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	167,
+	224,
+	164,
+	190,
+	224,
+	164,
+	168,
+	224,
+	164,
+	184,
+	224,
+	164,
+	173,
+	224,
+	164,
+	190,
+	224,
+	164,
+	171,
+	224,
+	164,
+	191,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	184,
+	224,
+	164,
+	191,
+	224,
+	164,
+	130,
+	224,
+	164,
+	151,
+	224,
+	164,
+	184,
+	224,
+	165,
+	129,
+	224,
+	164,
+	176,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	183,
+	224,
+	164,
+	191,
+	224,
+	164,
+	164,
+	224,
+	164,
+	149,
+	224,
+	165,
+	137,
+	224,
+	164,
+	170,
+	224,
+	165,
+	128,
+	224,
+	164,
+	176,
+	224,
+	164,
+	190,
+	224,
+	164,
+	135,
+	224,
+	164,
+	159,
+	224,
+	164,
+	181,
+	224,
+	164,
+	191,
+	224,
+	164,
+	156,
+	224,
+	165,
+	141,
+	224,
+	164,
+	158,
+	224,
+	164,
+	190,
+	224,
+	164,
+	170,
+	224,
+	164,
+	168,
+	224,
+	164,
+	149,
+	224,
+	164,
+	190,
+	224,
+	164,
+	176,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	181,
+	224,
+	164,
+	190,
+	224,
+	164,
+	136,
+	224,
+	164,
+	184,
+	224,
+	164,
+	149,
+	224,
+	165,
+	141,
+	224,
+	164,
+	176,
+	224,
+	164,
+	191,
+	224,
+	164,
+	175,
+	224,
+	164,
+	164,
+	224,
+	164,
+	190,
+}
+
+var kBrotliDictionary = dictionary{
+	/* size_bits_by_length */
+	[32]byte{
+		0,
+		0,
+		0,
+		0,
+		10,
+		10,
+		11,
+		11,
+		10,
+		10,
+		10,
+		10,
+		10,
+		9,
+		9,
+		8,
+		7,
+		7,
+		8,
+		7,
+		7,
+		6,
+		6,
+		5,
+		5,
+		0,
+		0,
+		0,
+		0,
+		0,
+		0,
+		0,
+	},
+
+	/* offsets_by_length */
+	[32]uint32{
+		0,
+		0,
+		0,
+		0,
+		0,
+		4096,
+		9216,
+		21504,
+		35840,
+		44032,
+		53248,
+		63488,
+		74752,
+		87040,
+		93696,
+		100864,
+		104704,
+		106752,
+		108928,
+		113536,
+		115968,
+		118528,
+		119872,
+		121280,
+		122016,
+		122784,
+		122784,
+		122784,
+		122784,
+		122784,
+		122784,
+		122784,
+	},
+
+	/* data_size ==  sizeof(kBrotliDictionaryData) */
+	122784,
+
+	/* data */
+	kBrotliDictionaryData,
+}
+
+func getDictionary() *dictionary {
+	return &kBrotliDictionary
+}
@@ -0,0 +1,32779 @@
+package brotli
+
+/* Copyright 2015 Google Inc. All Rights Reserved.
+
+   Distributed under MIT license.
+   See file LICENSE for detail or copy at https://opensource.org/licenses/MIT
+*/
+
+/* Hash table on the 4-byte prefixes of static dictionary words. */
+var kStaticDictionaryHash = [32768]uint16{
+	32072,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21860,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40486,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45798,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1292,
+	0,
+	0,
+	0,
+	0,
+	4964,
+	278,
+	23717,
+	0,
+	19972,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2126,
+	16102,
+	0,
+	0,
+	0,
+	14437,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26727,
+	2253,
+	0,
+	0,
+	17252,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3622,
+	0,
+	0,
+	0,
+	0,
+	22984,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16647,
+	0,
+	34247,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48486,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2511,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19532,
+	0,
+	0,
+	24004,
+	0,
+	0,
+	0,
+	9828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30853,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20650,
+	2404,
+	0,
+	20773,
+	1677,
+	9031,
+	0,
+	6404,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51879,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6761,
+	7206,
+	0,
+	0,
+	21992,
+	22983,
+	0,
+	0,
+	3529,
+	0,
+	1864,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11046,
+	0,
+	0,
+	9641,
+	0,
+	0,
+	0,
+	6507,
+	0,
+	0,
+	36934,
+	21576,
+	62375,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8294,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40807,
+	0,
+	0,
+	0,
+	39398,
+	8136,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8875,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7941,
+	0,
+	0,
+	25609,
+	0,
+	0,
+	0,
+	936,
+	3716,
+	3213,
+	15687,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52519,
+	0,
+	17381,
+	0,
+	0,
+	0,
+	0,
+	1320,
+	5797,
+	0,
+	21029,
+	0,
+	0,
+	6472,
+	807,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13545,
+	0,
+	0,
+	0,
+	3624,
+	0,
+	0,
+	0,
+	29674,
+	30820,
+	0,
+	31237,
+	0,
+	6596,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22278,
+	0,
+	37446,
+	0,
+	0,
+	0,
+	0,
+	7240,
+	423,
+	0,
+	24612,
+	21705,
+	17636,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1833,
+	0,
+	0,
+	0,
+	328,
+	6021,
+	0,
+	0,
+	0,
+	19974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62119,
+	4178,
+	0,
+	0,
+	0,
+	0,
+	12100,
+	8617,
+	0,
+	0,
+	16900,
+	0,
+	36678,
+	0,
+	0,
+	0,
+	35366,
+	0,
+	51718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20998,
+	0,
+	62086,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5542,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14629,
+	10952,
+	25927,
+	0,
+	0,
+	0,
+	0,
+	19849,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30952,
+	3046,
+	14314,
+	12998,
+	0,
+	0,
+	0,
+	15268,
+	0,
+	40582,
+	30216,
+	62118,
+	0,
+	0,
+	0,
+	20132,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12005,
+	0,
+	0,
+	0,
+	52358,
+	0,
+	0,
+	0,
+	0,
+	24778,
+	0,
+	44,
+	33095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3781,
+	0,
+	0,
+	17928,
+	9479,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32297,
+	28613,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47174,
+	11723,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2536,
+	55143,
+	0,
+	0,
+	6410,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56294,
+	11914,
+	0,
+	529,
+	0,
+	30184,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8261,
+	0,
+	0,
+	28808,
+	58854,
+	22633,
+	965,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64135,
+	0,
+	0,
+	331,
+	3684,
+	0,
+	1605,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16650,
+	37,
+	0,
+	23622,
+	3144,
+	15429,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22443,
+	69,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17832,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11113,
+	0,
+	0,
+	0,
+	0,
+	18309,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26630,
+	0,
+	0,
+	25512,
+	25895,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16901,
+	0,
+	0,
+	0,
+	27558,
+	0,
+	0,
+	9418,
+	0,
+	0,
+	0,
+	3508,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37990,
+	9289,
+	8517,
+	0,
+	0,
+	0,
+	0,
+	1578,
+	1604,
+	23944,
+	0,
+	0,
+	14916,
+	12781,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12105,
+	0,
+	16617,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21348,
+	11240,
+	28870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5772,
+	0,
+	0,
+	27812,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8324,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16748,
+	1157,
+	0,
+	0,
+	18794,
+	16324,
+	25898,
+	935,
+	8333,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18246,
+	0,
+	18086,
+	0,
+	46854,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	339,
+	0,
+	0,
+	25188,
+	12780,
+	12166,
+	6409,
+	0,
+	0,
+	0,
+	0,
+	16516,
+	0,
+	27012,
+	28395,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1420,
+	0,
+	0,
+	0,
+	9768,
+	52967,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25163,
+	324,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64998,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21893,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47366,
+	0,
+	0,
+	0,
+	870,
+	0,
+	0,
+	0,
+	12646,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26020,
+	16360,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1809,
+	0,
+	0,
+	0,
+	6601,
+	15878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29092,
+	0,
+	28516,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21988,
+	0,
+	0,
+	0,
+	42950,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5133,
+	1318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54982,
+	24904,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51526,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3685,
+	0,
+	0,
+	0,
+	0,
+	10062,
+	9412,
+	0,
+	0,
+	0,
+	31460,
+	5708,
+	6181,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5575,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27144,
+	57478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7084,
+	0,
+	21993,
+	53126,
+	0,
+	0,
+	0,
+	0,
+	8397,
+	0,
+	0,
+	5733,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2116,
+	0,
+	24742,
+	0,
+	11271,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1133,
+	0,
+	4873,
+	0,
+	0,
+	38310,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17932,
+	0,
+	0,
+	18053,
+	0,
+	0,
+	0,
+	25510,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17798,
+	0,
+	26214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23016,
+	17415,
+	20392,
+	164,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3239,
+	0,
+	46119,
+	0,
+	0,
+	0,
+	28580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7621,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41478,
+	0,
+	0,
+	31016,
+	55334,
+	10056,
+	1924,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36614,
+	0,
+	36711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13994,
+	59303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26501,
+	0,
+	5639,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13897,
+	1253,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5095,
+	0,
+	0,
+	0,
+	28869,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8646,
+	0,
+	0,
+	0,
+	0,
+	25641,
+	17796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13316,
+	620,
+	6309,
+	11819,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	904,
+	1095,
+	0,
+	24229,
+	0,
+	0,
+	28744,
+	49703,
+	0,
+	23077,
+	0,
+	0,
+	0,
+	0,
+	32392,
+	0,
+	0,
+	0,
+	0,
+	35271,
+	0,
+	28740,
+	5866,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4361,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7917,
+	8869,
+	0,
+	0,
+	0,
+	13924,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41958,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6766,
+	13989,
+	0,
+	0,
+	0,
+	903,
+	0,
+	0,
+	24010,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64390,
+	0,
+	22468,
+	0,
+	25861,
+	0,
+	0,
+	0,
+	0,
+	23656,
+	5317,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23017,
+	5445,
+	16009,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48006,
+	10473,
+	0,
+	0,
+	14404,
+	0,
+	0,
+	0,
+	42183,
+	0,
+	0,
+	0,
+	51270,
+	0,
+	0,
+	10602,
+	24132,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43782,
+	0,
+	0,
+	17834,
+	0,
+	0,
+	0,
+	25576,
+	27205,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29066,
+	0,
+	0,
+	0,
+	0,
+	0,
+	626,
+	1988,
+	14700,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44710,
+	0,
+	0,
+	0,
+	0,
+	3848,
+	7623,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19272,
+	6436,
+	0,
+	0,
+	5256,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19685,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39783,
+	0,
+	0,
+	0,
+	0,
+	30984,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28230,
+	0,
+	0,
+	0,
+	29028,
+	10538,
+	3205,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5636,
+	840,
+	295,
+	0,
+	0,
+	8488,
+	8198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4074,
+	19526,
+	0,
+	0,
+	0,
+	0,
+	31144,
+	64038,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16716,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17706,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50630,
+	0,
+	50503,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25446,
+	0,
+	0,
+	0,
+	13831,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2696,
+	4039,
+	0,
+	0,
+	0,
+	0,
+	25288,
+	0,
+	12076,
+	2054,
+	0,
+	48934,
+	0,
+	0,
+	0,
+	0,
+	16969,
+	59431,
+	17259,
+	35335,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31275,
+	0,
+	0,
+	0,
+	1097,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	776,
+	839,
+	0,
+	0,
+	29386,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5864,
+	12134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25349,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61447,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24678,
+	0,
+	0,
+	0,
+	63335,
+	0,
+	28836,
+	8142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14088,
+	1188,
+	0,
+	16260,
+	0,
+	0,
+	0,
+	16421,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	276,
+	0,
+	0,
+	17060,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24076,
+	29445,
+	0,
+	33543,
+	0,
+	4901,
+	0,
+	0,
+	12522,
+	0,
+	0,
+	62471,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4046,
+	0,
+	0,
+	0,
+	0,
+	20486,
+	0,
+	15460,
+	2217,
+	51719,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23495,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15370,
+	0,
+	15849,
+	0,
+	15113,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27972,
+	7337,
+	0,
+	0,
+	0,
+	0,
+	30342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32299,
+	23940,
+	0,
+	17766,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6184,
+	0,
+	20904,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31492,
+	0,
+	0,
+	0,
+	5509,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2669,
+	50182,
+	0,
+	0,
+	12299,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5257,
+	28167,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11750,
+	3890,
+	0,
+	0,
+	26500,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10981,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17961,
+	1831,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29638,
+	0,
+	0,
+	0,
+	0,
+	26473,
+	0,
+	6216,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28683,
+	39975,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51654,
+	0,
+	0,
+	0,
+	27527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30859,
+	3268,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28772,
+	0,
+	18212,
+	0,
+	0,
+	0,
+	0,
+	25448,
+	65446,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3337,
+	1670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19332,
+	0,
+	0,
+	0,
+	0,
+	24936,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1043,
+	0,
+	0,
+	0,
+	0,
+	15814,
+	0,
+	21670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16263,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32454,
+	0,
+	30630,
+	0,
+	0,
+	20170,
+	9926,
+	0,
+	0,
+	0,
+	18247,
+	0,
+	0,
+	14376,
+	0,
+	2056,
+	17191,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7812,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22474,
+	52806,
+	1588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10825,
+	0,
+	0,
+	0,
+	0,
+	40934,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28677,
+	0,
+	0,
+	5714,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25865,
+	22246,
+	0,
+	0,
+	0,
+	0,
+	17256,
+	35751,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8236,
+	0,
+	32108,
+	0,
+	0,
+	0,
+	43,
+	14342,
+	0,
+	16517,
+	0,
+	0,
+	30732,
+	0,
+	4012,
+	133,
+	0,
+	40583,
+	971,
+	23942,
+	0,
+	0,
+	27275,
+	0,
+	0,
+	0,
+	204,
+	0,
+	0,
+	27140,
+	7564,
+	44327,
+	27592,
+	57958,
+	0,
+	0,
+	0,
+	0,
+	22344,
+	25701,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19524,
+	31755,
+	0,
+	0,
+	28102,
+	0,
+	59111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12261,
+	0,
+	44934,
+	0,
+	0,
+	0,
+	0,
+	31560,
+	0,
+	11114,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18953,
+	18311,
+	0,
+	45159,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2059,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19399,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22411,
+	23943,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11690,
+	0,
+	0,
+	4069,
+	0,
+	0,
+	2668,
+	6342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27658,
+	1766,
+	0,
+	0,
+	0,
+	0,
+	23240,
+	56070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34119,
+	0,
+	24453,
+	0,
+	0,
+	0,
+	0,
+	21867,
+	0,
+	17610,
+	9894,
+	0,
+	0,
+	27976,
+	38790,
+	0,
+	0,
+	0,
+	43654,
+	0,
+	31559,
+	12202,
+	23142,
+	0,
+	0,
+	0,
+	50343,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49895,
+	0,
+	0,
+	0,
+	0,
+	15786,
+	4263,
+	0,
+	0,
+	0,
+	0,
+	4746,
+	3814,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17192,
+	453,
+	17323,
+	0,
+	20328,
+	4036,
+	0,
+	0,
+	0,
+	15844,
+	0,
+	0,
+	0,
+	0,
+	27561,
+	31940,
+	32296,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11499,
+	11782,
+	0,
+	0,
+	0,
+	0,
+	9738,
+	50471,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35430,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29734,
+	0,
+	0,
+	0,
+	36551,
+	0,
+	0,
+	0,
+	0,
+	9257,
+	5606,
+	0,
+	13829,
+	0,
+	7015,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25127,
+	0,
+	0,
+	19051,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2572,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29797,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42342,
+	0,
+	0,
+	0,
+	0,
+	9293,
+	0,
+	17896,
+	56038,
+	4077,
+	0,
+	0,
+	0,
+	29899,
+	37351,
+	0,
+	30823,
+	0,
+	8326,
+	0,
+	0,
+	0,
+	18342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18569,
+	54054,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37254,
+	0,
+	0,
+	31433,
+	61510,
+	0,
+	2022,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25381,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2149,
+	25289,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12516,
+	14185,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8676,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36486,
+	0,
+	0,
+	0,
+	0,
+	10889,
+	9607,
+	0,
+	28711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28490,
+	0,
+	0,
+	0,
+	0,
+	26181,
+	10283,
+	1701,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14980,
+	0,
+	7783,
+	0,
+	27846,
+	0,
+	0,
+	0,
+	56486,
+	3892,
+	0,
+	0,
+	0,
+	5770,
+	16583,
+	0,
+	26309,
+	13422,
+	20292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28742,
+	0,
+	0,
+	0,
+	0,
+	14536,
+	1158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25801,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42438,
+	0,
+	3332,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8327,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17353,
+	1447,
+	0,
+	0,
+	8427,
+	48518,
+	1359,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14986,
+	0,
+	32168,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9230,
+	2791,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16073,
+	31623,
+	4269,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4519,
+	0,
+	0,
+	27912,
+	58950,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8361,
+	19812,
+	0,
+	0,
+	0,
+	0,
+	6056,
+	7877,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21701,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9128,
+	1125,
+	0,
+	16548,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17292,
+	6854,
+	21352,
+	0,
+	2380,
+	0,
+	0,
+	4007,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24357,
+	4202,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10664,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42823,
+	3022,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14373,
+	0,
+	20677,
+	3304,
+	2759,
+	20522,
+	64903,
+	0,
+	0,
+	0,
+	38,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27814,
+	2802,
+	8870,
+	3758,
+	1255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30027,
+	9510,
+	0,
+	0,
+	0,
+	0,
+	17864,
+	14855,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23404,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45734,
+	0,
+	0,
+	23467,
+	32327,
+	0,
+	0,
+	10826,
+	52999,
+	0,
+	0,
+	0,
+	33222,
+	31336,
+	64326,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32166,
+	0,
+	0,
+	3891,
+	0,
+	0,
+	0,
+	7017,
+	645,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27915,
+	46087,
+	0,
+	0,
+	0,
+	21863,
+	0,
+	34246,
+	0,
+	0,
+	16715,
+	0,
+	0,
+	0,
+	0,
+	14052,
+	21416,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39846,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38982,
+	0,
+	0,
+	17512,
+	7460,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15428,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28356,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25445,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11879,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19911,
+	0,
+	20007,
+	0,
+	0,
+	0,
+	10855,
+	943,
+	0,
+	0,
+	10821,
+	0,
+	0,
+	0,
+	0,
+	4170,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9836,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	65415,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9865,
+	24646,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40519,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12804,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22091,
+	23655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31686,
+	0,
+	0,
+	0,
+	58599,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19620,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24421,
+	0,
+	28100,
+	0,
+	0,
+	0,
+	31268,
+	0,
+	3204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14822,
+	0,
+	0,
+	0,
+	0,
+	19947,
+	10182,
+	0,
+	0,
+	9480,
+	14821,
+	4398,
+	0,
+	0,
+	14532,
+	0,
+	0,
+	0,
+	48871,
+	1873,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	589,
+	1541,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23333,
+	0,
+	0,
+	0,
+	14149,
+	0,
+	0,
+	0,
+	0,
+	1296,
+	14374,
+	0,
+	27300,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5164,
+	1765,
+	0,
+	14405,
+	0,
+	37574,
+	1994,
+	0,
+	6636,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27815,
+	0,
+	0,
+	0,
+	0,
+	2568,
+	6820,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11336,
+	26247,
+	0,
+	0,
+	23912,
+	0,
+	0,
+	0,
+	30536,
+	0,
+	0,
+	34342,
+	0,
+	17799,
+	0,
+	0,
+	0,
+	22149,
+	0,
+	6118,
+	0,
+	25732,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26600,
+	5190,
+	0,
+	0,
+	1142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3085,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4786,
+	0,
+	0,
+	0,
+	28873,
+	6532,
+	0,
+	0,
+	26664,
+	0,
+	9193,
+	11719,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31752,
+	64646,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11397,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25094,
+	0,
+	0,
+	18153,
+	20167,
+	0,
+	0,
+	0,
+	17254,
+	0,
+	0,
+	878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24166,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26059,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31592,
+	0,
+	0,
+	8167,
+	24362,
+	6212,
+	0,
+	34758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32520,
+	0,
+	0,
+	44679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17989,
+	8681,
+	29222,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10251,
+	4902,
+	1452,
+	15207,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22822,
+	0,
+	10469,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19337,
+	17670,
+	107,
+	11494,
+	0,
+	0,
+	0,
+	0,
+	27305,
+	2565,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64518,
+	200,
+	28389,
+	0,
+	0,
+	0,
+	0,
+	31208,
+	0,
+	30762,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29321,
+	60518,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3209,
+	3237,
+	12490,
+	22663,
+	0,
+	0,
+	0,
+	18789,
+	31464,
+	16391,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20646,
+	0,
+	0,
+	0,
+	27238,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15940,
+	4488,
+	6951,
+	0,
+	0,
+	0,
+	46342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28965,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20584,
+	3367,
+	0,
+	25350,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1814,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17125,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55943,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24133,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2929,
+	0,
+	0,
+	50086,
+	0,
+	2918,
+	25356,
+	30052,
+	115,
+	11846,
+	0,
+	0,
+	0,
+	0,
+	3056,
+	0,
+	0,
+	0,
+	0,
+	17639,
+	239,
+	19815,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21479,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28420,
+	11786,
+	4772,
+	0,
+	0,
+	3368,
+	36295,
+	0,
+	31463,
+	0,
+	0,
+	14665,
+	996,
+	0,
+	20582,
+	0,
+	0,
+	0,
+	9988,
+	0,
+	23685,
+	0,
+	0,
+	0,
+	52551,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7556,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1895,
+	2186,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27755,
+	25447,
+	0,
+	0,
+	0,
+	0,
+	31052,
+	63270,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36742,
+	0,
+	24804,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31048,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21290,
+	2276,
+	0,
+	0,
+	0,
+	0,
+	26475,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15332,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3176,
+	19431,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62726,
+	0,
+	0,
+	0,
+	25380,
+	0,
+	0,
+	27883,
+	1316,
+	0,
+	0,
+	7724,
+	3015,
+	0,
+	0,
+	0,
+	0,
+	6697,
+	0,
+	0,
+	47910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3141,
+	0,
+	0,
+	0,
+	14820,
+	0,
+	0,
+	0,
+	0,
+	9326,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31493,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6569,
+	1348,
+	0,
+	25638,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20324,
+	0,
+	0,
+	17067,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11876,
+	0,
+	41030,
+	0,
+	0,
+	0,
+	26405,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11431,
+	28137,
+	14950,
+	0,
+	10151,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29574,
+	0,
+	0,
+	0,
+	0,
+	27176,
+	57446,
+	0,
+	0,
+	0,
+	0,
+	28650,
+	57574,
+	1387,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58247,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16805,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3526,
+	0,
+	15781,
+	0,
+	5572,
+	13352,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18665,
+	23463,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15405,
+	6885,
+	0,
+	0,
+	0,
+	0,
+	15272,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9861,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9512,
+	4037,
+	0,
+	0,
+	11563,
+	49639,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27880,
+	57830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41831,
+	0,
+	21924,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25509,
+	0,
+	27462,
+	0,
+	18085,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13898,
+	8068,
+	26441,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25316,
+	0,
+	0,
+	0,
+	0,
+	16298,
+	7397,
+	5706,
+	19239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1392,
+	50919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53863,
+	0,
+	0,
+	0,
+	0,
+	1451,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35847,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17801,
+	15813,
+	0,
+	12740,
+	0,
+	0,
+	0,
+	32967,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31143,
+	0,
+	20548,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51686,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12109,
+	19015,
+	0,
+	34983,
+	0,
+	21732,
+	3600,
+	0,
+	0,
+	0,
+	0,
+	47750,
+	17288,
+	43975,
+	22857,
+	47559,
+	0,
+	0,
+	0,
+	0,
+	26408,
+	48358,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30470,
+	0,
+	0,
+	23560,
+	4581,
+	0,
+	22404,
+	0,
+	49286,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49831,
+	0,
+	0,
+	0,
+	27525,
+	31691,
+	7,
+	0,
+	0,
+	25835,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4201,
+	16485,
+	0,
+	20676,
+	0,
+	0,
+	0,
+	0,
+	3753,
+	23303,
+	16264,
+	3878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11434,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7589,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22820,
+	11146,
+	49158,
+	0,
+	23623,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13893,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11722,
+	60071,
+	1258,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18564,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27945,
+	0,
+	0,
+	0,
+	0,
+	5479,
+	0,
+	20006,
+	17608,
+	3431,
+	10988,
+	30180,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24581,
+	14,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25572,
+	0,
+	0,
+	0,
+	28612,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8710,
+	0,
+	14116,
+	0,
+	0,
+	116,
+	292,
+	0,
+	0,
+	0,
+	37831,
+	0,
+	43078,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21832,
+	0,
+	0,
+	32134,
+	783,
+	0,
+	0,
+	30982,
+	0,
+	0,
+	0,
+	68,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5932,
+	0,
+	0,
+	0,
+	18505,
+	15175,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3630,
+	16965,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17797,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	520,
+	42150,
+	0,
+	0,
+	3122,
+	0,
+	0,
+	0,
+	22506,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28550,
+	0,
+	0,
+	0,
+	50278,
+	0,
+	0,
+	13641,
+	5958,
+	0,
+	35238,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29993,
+	18724,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20619,
+	9319,
+	0,
+	0,
+	0,
+	0,
+	23977,
+	0,
+	5193,
+	0,
+	0,
+	12196,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20105,
+	677,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29419,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20266,
+	0,
+	0,
+	0,
+	0,
+	10631,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26628,
+	12744,
+	0,
+	20648,
+	0,
+	0,
+	0,
+	432,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	646,
+	0,
+	25604,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63782,
+	0,
+	0,
+	0,
+	0,
+	24616,
+	0,
+	0,
+	0,
+	21291,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45638,
+	0,
+	0,
+	0,
+	0,
+	1931,
+	0,
+	0,
+	0,
+	20521,
+	59975,
+	0,
+	20614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56231,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29991,
+	0,
+	52871,
+	0,
+	20934,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16871,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7237,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47558,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10406,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43046,
+	0,
+	0,
+	2930,
+	0,
+	12936,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31141,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37639,
+	0,
+	17572,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31240,
+	0,
+	0,
+	0,
+	0,
+	0,
+	688,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1648,
+	0,
+	0,
+	0,
+	0,
+	10055,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	146,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6345,
+	199,
+	0,
+	34982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56839,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48902,
+	0,
+	13412,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2441,
+	4420,
+	0,
+	0,
+	0,
+	0,
+	20428,
+	933,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45383,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54726,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17036,
+	741,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27589,
+	0,
+	0,
+	30282,
+	18950,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2248,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25993,
+	0,
+	0,
+	0,
+	2443,
+	0,
+	0,
+	31622,
+	0,
+	14150,
+	0,
+	0,
+	0,
+	28679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15464,
+	0,
+	0,
+	0,
+	0,
+	54694,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3827,
+	0,
+	0,
+	0,
+	3756,
+	0,
+	9897,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19082,
+	31239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27625,
+	0,
+	0,
+	0,
+	784,
+	4647,
+	32652,
+	0,
+	0,
+	63494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3404,
+	58470,
+	0,
+	32325,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18634,
+	2789,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8548,
+	0,
+	0,
+	0,
+	22501,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15881,
+	0,
+	0,
+	0,
+	0,
+	35879,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7978,
+	17956,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24324,
+	0,
+	0,
+	4937,
+	0,
+	0,
+	0,
+	8168,
+	0,
+	13420,
+	10340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11780,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16712,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17640,
+	17991,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2953,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9100,
+	16806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30667,
+	0,
+	0,
+	19013,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	205,
+	15334,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1969,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26248,
+	52518,
+	0,
+	49798,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9668,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4742,
+	0,
+	0,
+	21641,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5707,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3018,
+	12454,
+	0,
+	0,
+	0,
+	0,
+	2920,
+	262,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3593,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55879,
+	0,
+	0,
+	0,
+	0,
+	0,
+	775,
+	0,
+	43270,
+	5066,
+	48967,
+	0,
+	0,
+	22986,
+	4165,
+	8971,
+	44838,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62279,
+	272,
+	0,
+	0,
+	0,
+	0,
+	51430,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28234,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13349,
+	0,
+	0,
+	0,
+	51111,
+	20265,
+	13861,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	585,
+	7494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21768,
+	62407,
+	0,
+	0,
+	0,
+	0,
+	7979,
+	166,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38918,
+	0,
+	56742,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16296,
+	5767,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32068,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23916,
+	30183,
+	0,
+	58791,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20518,
+	0,
+	0,
+	0,
+	0,
+	8969,
+	0,
+	0,
+	0,
+	183,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2314,
+	17445,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23748,
+	0,
+	0,
+	8139,
+	4839,
+	27914,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29478,
+	0,
+	0,
+	16552,
+	26663,
+	0,
+	53767,
+	0,
+	0,
+	13960,
+	8039,
+	18696,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	782,
+	16005,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6258,
+	56806,
+	16456,
+	12455,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23780,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9355,
+	0,
+	0,
+	0,
+	7273,
+	41063,
+	24780,
+	57766,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3820,
+	2597,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29225,
+	61126,
+	0,
+	0,
+	0,
+	58439,
+	15691,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37190,
+	22408,
+	967,
+	0,
+	0,
+	0,
+	23078,
+	26858,
+	0,
+	0,
+	0,
+	19753,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5416,
+	13702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52742,
+	20394,
+	38567,
+	0,
+	0,
+	0,
+	51079,
+	0,
+	0,
+	136,
+	8516,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	531,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8936,
+	5031,
+	12520,
+	19334,
+	0,
+	0,
+	22827,
+	30247,
+	28074,
+	31140,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27497,
+	18148,
+	20104,
+	59079,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24389,
+	0,
+	0,
+	6125,
+	0,
+	0,
+	0,
+	0,
+	9541,
+	0,
+	0,
+	24553,
+	29095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25444,
+	0,
+	0,
+	9643,
+	0,
+	0,
+	63047,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39558,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20620,
+	11815,
+	499,
+	0,
+	5128,
+	2278,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46310,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23530,
+	40166,
+	2440,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26922,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51911,
+	0,
+	0,
+	23532,
+	0,
+	0,
+	0,
+	0,
+	51238,
+	25737,
+	44486,
+	12622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3078,
+	0,
+	9253,
+	0,
+	0,
+	1128,
+	22023,
+	0,
+	0,
+	0,
+	21350,
+	0,
+	16420,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	65094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22532,
+	0,
+	48774,
+	0,
+	34503,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9797,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13797,
+	0,
+	38279,
+	0,
+	0,
+	1738,
+	0,
+	489,
+	46343,
+	0,
+	45382,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6220,
+	56550,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26885,
+	0,
+	28806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45958,
+	0,
+	0,
+	0,
+	0,
+	20553,
+	49927,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3019,
+	12358,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26571,
+	13319,
+	0,
+	0,
+	653,
+	23399,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22316,
+	0,
+	0,
+	21188,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27556,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27878,
+	21483,
+	27653,
+	0,
+	29701,
+	237,
+	0,
+	10632,
+	0,
+	0,
+	0,
+	0,
+	33766,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31563,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1416,
+	2439,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9611,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5611,
+	16581,
+	26601,
+	35462,
+	0,
+	0,
+	0,
+	26756,
+	0,
+	59271,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26984,
+	57734,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7882,
+	0,
+	0,
+	0,
+	19528,
+	6469,
+	0,
+	0,
+	1161,
+	0,
+	0,
+	0,
+	7688,
+	20935,
+	425,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12519,
+	0,
+	12902,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2411,
+	0,
+	11725,
+	26086,
+	0,
+	0,
+	20201,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11045,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30471,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21541,
+	1141,
+	21190,
+	0,
+	9188,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	184,
+	1093,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4842,
+	0,
+	13672,
+	0,
+	0,
+	12230,
+	0,
+	0,
+	0,
+	10532,
+	0,
+	0,
+	8937,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28996,
+	0,
+	0,
+	11720,
+	26982,
+	0,
+	46182,
+	0,
+	43911,
+	31754,
+	0,
+	1160,
+	3940,
+	0,
+	20772,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24549,
+	0,
+	32582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31845,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2310,
+	11788,
+	0,
+	0,
+	43047,
+	0,
+	0,
+	0,
+	18853,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63622,
+	0,
+	0,
+	7048,
+	17318,
+	0,
+	0,
+	0,
+	21957,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1039,
+	6279,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12197,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46470,
+	0,
+	0,
+	24,
+	19719,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39335,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21353,
+	3846,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11268,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9382,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29926,
+	0,
+	33606,
+	0,
+	4708,
+	2828,
+	0,
+	0,
+	29543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29893,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3663,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10920,
+	7111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9384,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20388,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37094,
+	0,
+	0,
+	0,
+	27110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21865,
+	0,
+	27753,
+	30214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57895,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12648,
+	5446,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19784,
+	17124,
+	0,
+	52007,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24900,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1476,
+	0,
+	65031,
+	0,
+	0,
+	1205,
+	46663,
+	0,
+	30023,
+	11625,
+	1094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10058,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28455,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14788,
+	0,
+	0,
+	0,
+	0,
+	16808,
+	0,
+	0,
+	742,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21636,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15944,
+	23207,
+	0,
+	0,
+	0,
+	0,
+	247,
+	0,
+	0,
+	0,
+	0,
+	24743,
+	0,
+	0,
+	0,
+	5252,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29961,
+	18660,
+	21099,
+	46791,
+	0,
+	7045,
+	0,
+	0,
+	0,
+	0,
+	25707,
+	0,
+	0,
+	17412,
+	3828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5803,
+	5637,
+	0,
+	38151,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	853,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30215,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8741,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27366,
+	0,
+	0,
+	0,
+	0,
+	171,
+	4070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24073,
+	7366,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2184,
+	5189,
+	0,
+	20932,
+	1545,
+	4996,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6313,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30826,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27463,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21640,
+	63303,
+	0,
+	0,
+	3275,
+	31111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11556,
+	0,
+	14756,
+	0,
+	0,
+	0,
+	15108,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23914,
+	28966,
+	0,
+	0,
+	0,
+	4965,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10216,
+	5223,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27142,
+	0,
+	0,
+	1173,
+	20198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4612,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11822,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17769,
+	7910,
+	0,
+	0,
+	31880,
+	0,
+	0,
+	6055,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8970,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16840,
+	23879,
+	0,
+	0,
+	11051,
+	0,
+	0,
+	0,
+	32552,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20842,
+	13701,
+	0,
+	0,
+	0,
+	37191,
+	7373,
+	10471,
+	17482,
+	25348,
+	0,
+	0,
+	0,
+	38502,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21509,
+	6058,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3173,
+	0,
+	0,
+	0,
+	9543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17768,
+	12708,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12748,
+	48743,
+	0,
+	11718,
+	0,
+	0,
+	25194,
+	0,
+	0,
+	0,
+	9033,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5028,
+	0,
+	30118,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42759,
+	0,
+	0,
+	3720,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5450,
+	5125,
+	0,
+	58086,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27716,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22052,
+	0,
+	0,
+	0,
+	0,
+	26249,
+	0,
+	15947,
+	3460,
+	0,
+	0,
+	0,
+	35814,
+	0,
+	0,
+	0,
+	7813,
+	19500,
+	32167,
+	0,
+	18597,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28644,
+	0,
+	0,
+	0,
+	60743,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29636,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17220,
+	15885,
+	9414,
+	9642,
+	0,
+	0,
+	0,
+	593,
+	0,
+	0,
+	24228,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40422,
+	0,
+	26244,
+	0,
+	23109,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64902,
+	0,
+	0,
+	0,
+	0,
+	3979,
+	60007,
+	0,
+	0,
+	0,
+	28199,
+	0,
+	0,
+	0,
+	43142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29158,
+	0,
+	30532,
+	0,
+	0,
+	0,
+	0,
+	13256,
+	0,
+	0,
+	0,
+	0,
+	16549,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26116,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22825,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1065,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18985,
+	4805,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3468,
+	0,
+	0,
+	0,
+	0,
+	13447,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56871,
+	0,
+	0,
+	1776,
+	15780,
+	0,
+	0,
+	2603,
+	0,
+	10280,
+	31366,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11592,
+	3591,
+	0,
+	2372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20004,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12072,
+	518,
+	0,
+	0,
+	1960,
+	8999,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7178,
+	32999,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1641,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6764,
+	9893,
+	490,
+	4005,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25258,
+	5541,
+	0,
+	14053,
+	306,
+	20743,
+	0,
+	0,
+	9422,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11977,
+	260,
+	0,
+	35175,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18405,
+	0,
+	0,
+	0,
+	16582,
+	0,
+	0,
+	0,
+	22470,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2792,
+	0,
+	0,
+	0,
+	14026,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14858,
+	3909,
+	0,
+	0,
+	0,
+	57671,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15979,
+	0,
+	0,
+	0,
+	2794,
+	15239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26884,
+	9070,
+	0,
+	0,
+	0,
+	0,
+	51846,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19499,
+	37127,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19205,
+	10350,
+	11910,
+	0,
+	0,
+	0,
+	0,
+	15083,
+	23108,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	169,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15274,
+	41735,
+	0,
+	56774,
+	0,
+	0,
+	2825,
+	0,
+	14025,
+	389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21482,
+	31910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20456,
+	710,
+	0,
+	0,
+	25032,
+	21797,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32427,
+	21252,
+	0,
+	30150,
+	0,
+	43174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11403,
+	0,
+	0,
+	1029,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6892,
+	9252,
+	0,
+	63206,
+	3496,
+	14406,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22568,
+	0,
+	0,
+	21253,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39623,
+	0,
+	0,
+	10189,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30729,
+	59910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3305,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7660,
+	24871,
+	0,
+	838,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12013,
+	13252,
+	0,
+	551,
+	0,
+	0,
+	0,
+	43207,
+	0,
+	30567,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28394,
+	30724,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22665,
+	22725,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29414,
+	0,
+	0,
+	0,
+	0,
+	16074,
+	8966,
+	245,
+	1445,
+	0,
+	0,
+	0,
+	0,
+	24872,
+	0,
+	0,
+	0,
+	0,
+	13124,
+	0,
+	35527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13259,
+	10917,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25191,
+	0,
+	0,
+	0,
+	13956,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54631,
+	19625,
+	12070,
+	3083,
+	0,
+	0,
+	0,
+	0,
+	14436,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21766,
+	0,
+	15463,
+	29322,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29990,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23653,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2643,
+	0,
+	0,
+	21223,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4114,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34790,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	297,
+	3620,
+	3338,
+	10372,
+	0,
+	14727,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29924,
+	22473,
+	13895,
+	15529,
+	32455,
+	30378,
+	13540,
+	0,
+	28807,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64582,
+	18380,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38598,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32710,
+	0,
+	0,
+	0,
+	0,
+	4590,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64935,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16744,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20005,
+	0,
+	0,
+	13608,
+	1191,
+	0,
+	0,
+	0,
+	62183,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24484,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17643,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5380,
+	0,
+	0,
+	32328,
+	0,
+	0,
+	63814,
+	0,
+	0,
+	0,
+	2919,
+	0,
+	0,
+	0,
+	0,
+	17034,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60295,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7690,
+	486,
+	0,
+	0,
+	0,
+	39270,
+	0,
+	49094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12555,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20967,
+	17993,
+	12647,
+	0,
+	0,
+	0,
+	16036,
+	32616,
+	0,
+	0,
+	0,
+	0,
+	16294,
+	8555,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30346,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14797,
+	3652,
+	0,
+	0,
+	8268,
+	12934,
+	0,
+	54950,
+	0,
+	0,
+	0,
+	0,
+	2632,
+	33959,
+	0,
+	23175,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36262,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32684,
+	26918,
+	0,
+	32676,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15625,
+	11943,
+	1206,
+	0,
+	0,
+	0,
+	0,
+	18052,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16422,
+	0,
+	0,
+	0,
+	26404,
+	0,
+	0,
+	28777,
+	0,
+	0,
+	24902,
+	0,
+	0,
+	408,
+	45351,
+	0,
+	35719,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3658,
+	17446,
+	0,
+	165,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6151,
+	0,
+	0,
+	24424,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24170,
+	24293,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11847,
+	0,
+	39591,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9549,
+	2788,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1010,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26055,
+	31724,
+	0,
+	24233,
+	1828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17284,
+	0,
+	0,
+	0,
+	0,
+	19464,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32452,
+	0,
+	0,
+	0,
+	28871,
+	0,
+	0,
+	0,
+	0,
+	17704,
+	53383,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17892,
+	1938,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16362,
+	0,
+	0,
+	21605,
+	0,
+	0,
+	5003,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22693,
+	0,
+	22342,
+	0,
+	0,
+	0,
+	55846,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22853,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6600,
+	263,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24836,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33894,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13000,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30308,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5386,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27844,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17740,
+	0,
+	0,
+	0,
+	0,
+	25093,
+	29064,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12680,
+	11462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	84,
+	7303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27044,
+	457,
+	0,
+	22924,
+	58246,
+	19016,
+	0,
+	2606,
+	45703,
+	0,
+	5157,
+	0,
+	25028,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2065,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31946,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33382,
+	0,
+	47878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25004,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26153,
+	35654,
+	0,
+	58055,
+	30668,
+	0,
+	0,
+	0,
+	0,
+	25988,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4456,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7560,
+	20583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37510,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42822,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1733,
+	0,
+	0,
+	0,
+	8196,
+	0,
+	0,
+	11241,
+	0,
+	30572,
+	60326,
+	0,
+	15013,
+	0,
+	0,
+	0,
+	40646,
+	0,
+	23812,
+	0,
+	10022,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12874,
+	31015,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1608,
+	0,
+	0,
+	0,
+	0,
+	18308,
+	0,
+	0,
+	0,
+	0,
+	27114,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7944,
+	1382,
+	0,
+	11813,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24517,
+	0,
+	11621,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21702,
+	0,
+	0,
+	13100,
+	8262,
+	2644,
+	7973,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1033,
+	12581,
+	0,
+	25221,
+	0,
+	0,
+	0,
+	40998,
+	16301,
+	62983,
+	0,
+	0,
+	0,
+	0,
+	1263,
+	9318,
+	0,
+	0,
+	0,
+	18854,
+	0,
+	0,
+	1741,
+	33895,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26377,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32165,
+	0,
+	51143,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29412,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1674,
+	4230,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10502,
+	0,
+	0,
+	0,
+	0,
+	5545,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2099,
+	45158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14157,
+	0,
+	26955,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17096,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27050,
+	6726,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28554,
+	0,
+	0,
+	7142,
+	0,
+	0,
+	0,
+	0,
+	16936,
+	0,
+	0,
+	0,
+	25833,
+	0,
+	4399,
+	6980,
+	0,
+	46214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10630,
+	21164,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2446,
+	48551,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13381,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15400,
+	12135,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4774,
+	586,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23751,
+	9736,
+	4548,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25577,
+	29607,
+	6250,
+	1637,
+	0,
+	0,
+	0,
+	0,
+	22024,
+	0,
+	0,
+	0,
+	0,
+	22308,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37414,
+	24044,
+	0,
+	0,
+	0,
+	14474,
+	29735,
+	0,
+	7077,
+	0,
+	45990,
+	0,
+	0,
+	0,
+	0,
+	30568,
+	40039,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6150,
+	0,
+	4228,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27687,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24548,
+	21513,
+	1350,
+	0,
+	0,
+	0,
+	33607,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11784,
+	1414,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18244,
+	940,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7270,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16709,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48935,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23660,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53350,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4236,
+	16358,
+	0,
+	4422,
+	6665,
+	32644,
+	0,
+	0,
+	744,
+	18084,
+	0,
+	11014,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29508,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7686,
+	0,
+	0,
+	13289,
+	5478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12872,
+	0,
+	0,
+	24134,
+	1005,
+	22916,
+	0,
+	31429,
+	23400,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28424,
+	0,
+	0,
+	0,
+	25706,
+	27109,
+	0,
+	0,
+	26345,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25126,
+	0,
+	0,
+	88,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17032,
+	0,
+	0,
+	21799,
+	0,
+	0,
+	10060,
+	0,
+	12296,
+	21892,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20777,
+	14311,
+	0,
+	58182,
+	32232,
+	0,
+	10282,
+	0,
+	2121,
+	11527,
+	0,
+	0,
+	0,
+	12325,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28804,
+	2344,
+	8133,
+	0,
+	0,
+	0,
+	0,
+	21864,
+	62695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2771,
+	0,
+	0,
+	23204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6278,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26597,
+	0,
+	0,
+	0,
+	0,
+	23144,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31816,
+	20070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24456,
+	2118,
+	0,
+	0,
+	0,
+	0,
+	6570,
+	1156,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30406,
+	0,
+	0,
+	0,
+	28388,
+	3572,
+	0,
+	0,
+	26599,
+	12426,
+	5286,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4967,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24970,
+	24167,
+	0,
+	0,
+	0,
+	0,
+	28745,
+	4678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1444,
+	236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19428,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2092,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2827,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19881,
+	19204,
+	0,
+	11749,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17958,
+	0,
+	17894,
+	0,
+	18726,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21510,
+	5033,
+	0,
+	0,
+	0,
+	0,
+	22855,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14598,
+	0,
+	29605,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	617,
+	0,
+	0,
+	0,
+	0,
+	47142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3627,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2225,
+	14823,
+	0,
+	0,
+	2637,
+	6182,
+	78,
+	15078,
+	0,
+	0,
+	0,
+	0,
+	20264,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36743,
+	4140,
+	44551,
+	17352,
+	25703,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14024,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28004,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2087,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18028,
+	0,
+	0,
+	0,
+	300,
+	14212,
+	0,
+	0,
+	1386,
+	40327,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31082,
+	0,
+	0,
+	22374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26532,
+	7756,
+	0,
+	0,
+	18982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6440,
+	1159,
+	7180,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45766,
+	0,
+	57798,
+	0,
+	16740,
+	0,
+	0,
+	6802,
+	60454,
+	0,
+	0,
+	0,
+	26470,
+	0,
+	0,
+	0,
+	0,
+	0,
+	65382,
+	4362,
+	7750,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9096,
+	4743,
+	334,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39974,
+	0,
+	0,
+	0,
+	25828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3027,
+	0,
+	0,
+	0,
+	15816,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48327,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16168,
+	41799,
+	0,
+	0,
+	24458,
+	8581,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54503,
+	0,
+	0,
+	0,
+	0,
+	5097,
+	30852,
+	18664,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16484,
+	0,
+	0,
+	27337,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35942,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4356,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57030,
+	0,
+	0,
+	1417,
+	41191,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23429,
+	0,
+	0,
+	0,
+	0,
+	10024,
+	21735,
+	0,
+	0,
+	10126,
+	0,
+	0,
+	0,
+	0,
+	19046,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24105,
+	4710,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4394,
+	0,
+	0,
+	0,
+	0,
+	13253,
+	0,
+	56391,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55974,
+	0,
+	0,
+	0,
+	52070,
+	0,
+	15620,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2660,
+	0,
+	0,
+	0,
+	0,
+	21644,
+	0,
+	0,
+	52455,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8902,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3116,
+	0,
+	464,
+	34726,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25003,
+	12423,
+	0,
+	27172,
+	1896,
+	7335,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35686,
+	0,
+	0,
+	0,
+	0,
+	3472,
+	0,
+	0,
+	0,
+	0,
+	22406,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45254,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21124,
+	23594,
+	33127,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16684,
+	22087,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8714,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55814,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4109,
+	23460,
+	0,
+	0,
+	8874,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	147,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29960,
+	63398,
+	1302,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9799,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31333,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19557,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5701,
+	0,
+	0,
+	0,
+	63014,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21254,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12484,
+	0,
+	0,
+	0,
+	48326,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15783,
+	0,
+	0,
+	1202,
+	0,
+	0,
+	0,
+	0,
+	23174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3086,
+	49191,
+	0,
+	0,
+	5387,
+	15141,
+	0,
+	0,
+	0,
+	3365,
+	0,
+	0,
+	0,
+	0,
+	20076,
+	14021,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	376,
+	40198,
+	0,
+	0,
+	0,
+	52039,
+	0,
+	24932,
+	0,
+	0,
+	0,
+	0,
+	808,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9860,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23719,
+	0,
+	21476,
+	0,
+	0,
+	0,
+	0,
+	20776,
+	4807,
+	0,
+	0,
+	3177,
+	16678,
+	0,
+	0,
+	110,
+	10853,
+	0,
+	0,
+	0,
+	17382,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7500,
+	4966,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52102,
+	0,
+	24516,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26535,
+	0,
+	0,
+	0,
+	46247,
+	0,
+	0,
+	0,
+	15557,
+	0,
+	0,
+	0,
+	0,
+	76,
+	52327,
+	0,
+	0,
+	0,
+	0,
+	17866,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19173,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44038,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2985,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14310,
+	0,
+	0,
+	2125,
+	45831,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9838,
+	0,
+	13227,
+	19492,
+	0,
+	0,
+	0,
+	29764,
+	0,
+	0,
+	0,
+	0,
+	686,
+	30053,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30789,
+	139,
+	20837,
+	0,
+	0,
+	0,
+	0,
+	502,
+	18533,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31396,
+	0,
+	0,
+	0,
+	17444,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25610,
+	550,
+	0,
+	0,
+	561,
+	0,
+	29034,
+	0,
+	0,
+	0,
+	3528,
+	0,
+	0,
+	0,
+	1715,
+	14661,
+	18,
+	63463,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14186,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29578,
+	59014,
+	0,
+	39430,
+	0,
+	0,
+	0,
+	0,
+	2250,
+	16612,
+	0,
+	31780,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	462,
+	16967,
+	0,
+	29029,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1768,
+	0,
+	6025,
+	16998,
+	1804,
+	0,
+	0,
+	54182,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14124,
+	0,
+	6154,
+	29702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7716,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48807,
+	0,
+	8292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16389,
+	5933,
+	0,
+	14857,
+	51303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35623,
+	9097,
+	23047,
+	0,
+	0,
+	23112,
+	0,
+	0,
+	0,
+	0,
+	0,
+	438,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	151,
+	9254,
+	1390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54215,
+	0,
+	0,
+	0,
+	0,
+	6187,
+	0,
+	0,
+	0,
+	0,
+	13095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9866,
+	0,
+	0,
+	59622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25286,
+	0,
+	0,
+	23848,
+	32069,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9255,
+	2187,
+	15270,
+	437,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19493,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11748,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16902,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22212,
+	1865,
+	17543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21996,
+	0,
+	0,
+	0,
+	0,
+	55975,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32138,
+	21156,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14249,
+	0,
+	0,
+	0,
+	2388,
+	0,
+	0,
+	0,
+	0,
+	6823,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26694,
+	0,
+	0,
+	6059,
+	53511,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49542,
+	6159,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1036,
+	24036,
+	0,
+	2501,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17419,
+	51271,
+	3377,
+	15142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5007,
+	62374,
+	0,
+	56935,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24422,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	942,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28263,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15622,
+	0,
+	19749,
+	0,
+	0,
+	1611,
+	0,
+	22219,
+	48583,
+	25129,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17476,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	721,
+	0,
+	0,
+	0,
+	0,
+	32518,
+	0,
+	0,
+	0,
+	18469,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5896,
+	29927,
+	3657,
+	23046,
+	0,
+	0,
+	3214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	112,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3048,
+	455,
+	0,
+	31012,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23270,
+	0,
+	32677,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38086,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4900,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25541,
+	0,
+	18788,
+	0,
+	0,
+	22248,
+	1351,
+	0,
+	61734,
+	4524,
+	30629,
+	0,
+	14887,
+	242,
+	29063,
+	0,
+	0,
+	14408,
+	4741,
+	0,
+	0,
+	0,
+	37318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8106,
+	0,
+	32107,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1481,
+	0,
+	0,
+	28132,
+	0,
+	25798,
+	0,
+	59783,
+	0,
+	0,
+	0,
+	0,
+	0,
+	59078,
+	0,
+	0,
+	0,
+	23366,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30887,
+	0,
+	0,
+	0,
+	0,
+	16200,
+	0,
+	0,
+	0,
+	335,
+	0,
+	0,
+	0,
+	714,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30730,
+	9478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18790,
+	0,
+	0,
+	0,
+	0,
+	663,
+	0,
+	0,
+	0,
+	1034,
+	31431,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30120,
+	0,
+	0,
+	0,
+	0,
+	13925,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2280,
+	13414,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22028,
+	23687,
+	3017,
+	11047,
+	0,
+	0,
+	21738,
+	18630,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30246,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17257,
+	0,
+	21896,
+	63783,
+	0,
+	0,
+	0,
+	21094,
+	0,
+	18662,
+	0,
+	25700,
+	0,
+	22533,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6341,
+	5800,
+	11111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15399,
+	12970,
+	6501,
+	0,
+	0,
+	3179,
+	26438,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15750,
+	0,
+	13062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	142,
+	0,
+	0,
+	0,
+	0,
+	21284,
+	11177,
+	4391,
+	0,
+	0,
+	0,
+	0,
+	19595,
+	40647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11877,
+	0,
+	0,
+	0,
+	26439,
+	0,
+	0,
+	0,
+	0,
+	695,
+	49126,
+	27467,
+	11972,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9961,
+	0,
+	0,
+	0,
+	31722,
+	62982,
+	0,
+	0,
+	0,
+	0,
+	15817,
+	52710,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20550,
+	0,
+	0,
+	5034,
+	3942,
+	0,
+	0,
+	0,
+	45927,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2548,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45606,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3405,
+	12582,
+	15563,
+	54087,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24202,
+	5893,
+	0,
+	0,
+	0,
+	44230,
+	0,
+	0,
+	0,
+	5605,
+	0,
+	47782,
+	0,
+	32230,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7014,
+	0,
+	0,
+	0,
+	0,
+	16488,
+	3175,
+	0,
+	27237,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40902,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32004,
+	31434,
+	0,
+	24392,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29130,
+	58214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29002,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55366,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37926,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1290,
+	0,
+	0,
+	0,
+	4713,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20812,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1803,
+	966,
+	0,
+	17700,
+	0,
+	0,
+	654,
+	19109,
+	0,
+	51655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10470,
+	1584,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2506,
+	0,
+	0,
+	25159,
+	4303,
+	0,
+	0,
+	0,
+	395,
+	15879,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1352,
+	6535,
+	0,
+	19652,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4722,
+	7909,
+	0,
+	0,
+	0,
+	0,
+	30152,
+	0,
+	0,
+	64742,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2153,
+	9125,
+	0,
+	0,
+	279,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41894,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1328,
+	17030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54151,
+	0,
+	0,
+	0,
+	0,
+	1775,
+	54535,
+	0,
+	0,
+	0,
+	0,
+	31624,
+	0,
+	0,
+	0,
+	7150,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1840,
+	35943,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56455,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64486,
+	0,
+	0,
+	0,
+	51174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17092,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12,
+	16134,
+	19883,
+	39943,
+	10281,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14125,
+	2407,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26921,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22188,
+	0,
+	20810,
+	10053,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29220,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28170,
+	0,
+	15208,
+	0,
+	0,
+	32517,
+	5736,
+	19271,
+	3562,
+	10534,
+	0,
+	0,
+	0,
+	59655,
+	0,
+	0,
+	0,
+	0,
+	27084,
+	60422,
+	0,
+	0,
+	24969,
+	0,
+	0,
+	0,
+	2636,
+	0,
+	0,
+	0,
+	0,
+	26277,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30596,
+	3594,
+	0,
+	0,
+	0,
+	8362,
+	14565,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10793,
+	12326,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5906,
+	59686,
+	0,
+	0,
+	23081,
+	517,
+	0,
+	15556,
+	0,
+	0,
+	0,
+	8486,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19877,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7497,
+	0,
+	0,
+	26085,
+	0,
+	0,
+	23784,
+	63591,
+	6568,
+	6310,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10054,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7018,
+	14470,
+	18858,
+	0,
+	5641,
+	10660,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35526,
+	1515,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27656,
+	0,
+	0,
+	9606,
+	0,
+	39590,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53926,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	232,
+	4327,
+	12649,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20199,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26730,
+	0,
+	0,
+	0,
+	19400,
+	14695,
+	0,
+	31334,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19589,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5064,
+	11908,
+	0,
+	27333,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47751,
+	0,
+	0,
+	0,
+	26662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6245,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23368,
+	63911,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8520,
+	24037,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26279,
+	0,
+	0,
+	0,
+	22886,
+	0,
+	0,
+	0,
+	27782,
+	0,
+	30694,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33703,
+	0,
+	0,
+	0,
+	30405,
+	0,
+	34598,
+	0,
+	51047,
+	0,
+	0,
+	0,
+	0,
+	1908,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1511,
+	21897,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51398,
+	0,
+	24870,
+	0,
+	32647,
+	0,
+	0,
+	0,
+	35015,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7758,
+	57991,
+	0,
+	0,
+	0,
+	30949,
+	0,
+	0,
+	22,
+	15140,
+	9162,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25540,
+	20136,
+	7108,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16427,
+	10789,
+	9805,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4680,
+	0,
+	0,
+	52679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14884,
+	0,
+	0,
+	0,
+	16804,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9578,
+	5287,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34054,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19076,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7627,
+	55719,
+	0,
+	39463,
+	14446,
+	58374,
+	0,
+	0,
+	0,
+	0,
+	23465,
+	15845,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38534,
+	0,
+	0,
+	0,
+	17893,
+	10922,
+	0,
+	7176,
+	678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3113,
+	46279,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23334,
+	0,
+	0,
+	18088,
+	23268,
+	0,
+	62342,
+	0,
+	0,
+	0,
+	16613,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38182,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10344,
+	71,
+	6446,
+	0,
+	0,
+	1893,
+	0,
+	0,
+	1106,
+	0,
+	28680,
+	30756,
+	0,
+	41126,
+	0,
+	0,
+	1492,
+	0,
+	15341,
+	0,
+	0,
+	0,
+	0,
+	17575,
+	0,
+	21220,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25060,
+	2088,
+	21828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	358,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16708,
+	0,
+	0,
+	0,
+	1668,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12260,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4078,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12713,
+	6215,
+	0,
+	0,
+	20329,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3732,
+	0,
+	1646,
+	0,
+	0,
+	27460,
+	0,
+	34406,
+	17128,
+	14341,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6120,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8551,
+	21546,
+	10212,
+	3020,
+	2951,
+	0,
+	17638,
+	0,
+	0,
+	6985,
+	44999,
+	2218,
+	8197,
+	0,
+	0,
+	30472,
+	63366,
+	0,
+	26660,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1265,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2610,
+	0,
+	0,
+	0,
+	11278,
+	20295,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19780,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2353,
+	10852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5421,
+	24292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34407,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15432,
+	20774,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12360,
+	10757,
+	0,
+	0,
+	0,
+	33126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29573,
+	0,
+	2343,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63079,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43015,
+	0,
+	16038,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1480,
+	25573,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8839,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24645,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5063,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	823,
+	0,
+	0,
+	64039,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15300,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2924,
+	46759,
+	6760,
+	19268,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34182,
+	0,
+	0,
+	3977,
+	18149,
+	0,
+	0,
+	0,
+	32199,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23524,
+	25994,
+	0,
+	0,
+	10343,
+	0,
+	0,
+	0,
+	9733,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4740,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16741,
+	0,
+	0,
+	4626,
+	23367,
+	0,
+	0,
+	31400,
+	0,
+	0,
+	3557,
+	0,
+	0,
+	4234,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28486,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14213,
+	0,
+	57191,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	240,
+	0,
+	0,
+	0,
+	0,
+	65318,
+	29832,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29989,
+	0,
+	31846,
+	0,
+	0,
+	8170,
+	0,
+	0,
+	4421,
+	27626,
+	30884,
+	0,
+	0,
+	20204,
+	0,
+	0,
+	0,
+	0,
+	44614,
+	534,
+	20868,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28710,
+	0,
+	10277,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29511,
+	0,
+	19813,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27020,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53094,
+	0,
+	35207,
+	0,
+	0,
+	0,
+	37542,
+	0,
+	61766,
+	8584,
+	8037,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12488,
+	22757,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23814,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19973,
+	0,
+	0,
+	0,
+	63943,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36006,
+	0,
+	0,
+	0,
+	19012,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18021,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	80,
+	1254,
+	0,
+	0,
+	0,
+	42630,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16262,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2124,
+	25479,
+	0,
+	0,
+	0,
+	0,
+	16873,
+	0,
+	0,
+	0,
+	0,
+	3142,
+	0,
+	0,
+	18443,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3917,
+	0,
+	8841,
+	1190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20645,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9284,
+	0,
+	0,
+	24394,
+	41351,
+	0,
+	0,
+	0,
+	42087,
+	0,
+	62566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6728,
+	4199,
+	0,
+	0,
+	0,
+	0,
+	25515,
+	0,
+	1231,
+	0,
+	374,
+	15623,
+	0,
+	29956,
+	0,
+	14118,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19047,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20900,
+	0,
+	16743,
+	0,
+	0,
+	0,
+	28902,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2578,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13838,
+	0,
+	0,
+	10052,
+	0,
+	0,
+	0,
+	0,
+	7432,
+	43783,
+	17097,
+	0,
+	0,
+	0,
+	0,
+	0,
+	873,
+	0,
+	0,
+	0,
+	398,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8459,
+	23559,
+	0,
+	53030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35750,
+	0,
+	4071,
+	0,
+	0,
+	0,
+	38662,
+	0,
+	41414,
+	0,
+	0,
+	0,
+	0,
+	11656,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4011,
+	42695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25353,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27177,
+	22372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30980,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46278,
+	3976,
+	12711,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20517,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4072,
+	11078,
+	0,
+	0,
+	16553,
+	2405,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32998,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47046,
+	0,
+	30533,
+	0,
+	0,
+	11050,
+	9734,
+	13129,
+	0,
+	0,
+	0,
+	0,
+	23494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58310,
+	0,
+	0,
+	0,
+	57543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	454,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5163,
+	59687,
+	2220,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29510,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17316,
+	0,
+	20069,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5319,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22949,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19208,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20933,
+	0,
+	0,
+	6026,
+	8742,
+	0,
+	0,
+	0,
+	17380,
+	0,
+	13127,
+	2797,
+	0,
+	0,
+	30116,
+	0,
+	0,
+	5963,
+	8004,
+	0,
+	57126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42854,
+	14792,
+	30759,
+	0,
+	24964,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16933,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15176,
+	40839,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	788,
+	30341,
+	0,
+	0,
+	0,
+	0,
+	21036,
+	24102,
+	0,
+	0,
+	0,
+	0,
+	30123,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22597,
+	31531,
+	26789,
+	0,
+	59559,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9352,
+	29863,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24551,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20516,
+	0,
+	0,
+	0,
+	39462,
+	3665,
+	0,
+	28265,
+	0,
+	8778,
+	64262,
+	0,
+	57414,
+	9132,
+	0,
+	0,
+	18276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26344,
+	30725,
+	524,
+	19751,
+	0,
+	13796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18155,
+	0,
+	12841,
+	0,
+	74,
+	24998,
+	13579,
+	1061,
+	0,
+	64199,
+	0,
+	0,
+	8776,
+	0,
+	0,
+	60231,
+	0,
+	25412,
+	0,
+	0,
+	0,
+	59143,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14344,
+	1510,
+	0,
+	0,
+	0,
+	38374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13353,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9446,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32613,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19844,
+	0,
+	0,
+	0,
+	0,
+	14859,
+	0,
+	0,
+	0,
+	0,
+	6662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14154,
+	0,
+	29770,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16520,
+	2182,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36102,
+	3340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25189,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15720,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22758,
+	0,
+	0,
+	304,
+	0,
+	3243,
+	14117,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5130,
+	12679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21733,
+	10441,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23590,
+	0,
+	57479,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10824,
+	18372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35078,
+	15722,
+	12967,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34599,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53639,
+	0,
+	38630,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31017,
+	11333,
+	0,
+	0,
+	0,
+	0,
+	19144,
+	0,
+	9513,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56711,
+	24042,
+	0,
+	1197,
+	0,
+	0,
+	58502,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8230,
+	6121,
+	18628,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25290,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1514,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14378,
+	9798,
+	32363,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9577,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26788,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	330,
+	10533,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42246,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5074,
+	21028,
+	0,
+	38119,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	248,
+	0,
+	31176,
+	62054,
+	0,
+	53287,
+	0,
+	0,
+	0,
+	0,
+	271,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9224,
+	2117,
+	0,
+	0,
+	0,
+	0,
+	15818,
+	5607,
+	0,
+	52582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18248,
+	24005,
+	23018,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	427,
+	0,
+	0,
+	39910,
+	0,
+	0,
+	7080,
+	11399,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22220,
+	57894,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13156,
+	0,
+	1413,
+	1007,
+	0,
+	0,
+	0,
+	0,
+	21415,
+	0,
+	21543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41702,
+	22538,
+	9573,
+	0,
+	0,
+	0,
+	8806,
+	0,
+	0,
+	6920,
+	56359,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42215,
+	0,
+	0,
+	13708,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16453,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1582,
+	1764,
+	3282,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11653,
+	0,
+	0,
+	0,
+	0,
+	12139,
+	0,
+	29482,
+	31076,
+	1673,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40262,
+	0,
+	0,
+	0,
+	33862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20996,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4615,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43943,
+	333,
+	19367,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26821,
+	0,
+	32389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4936,
+	11687,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10885,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25926,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15851,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8360,
+	0,
+	17130,
+	7942,
+	0,
+	11460,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18150,
+	14248,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22310,
+	0,
+	0,
+	0,
+	42758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29354,
+	5574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31109,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9156,
+	0,
+	0,
+	1801,
+	14023,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62406,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23620,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31018,
+	65510,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26182,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27717,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46950,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31108,
+	0,
+	11366,
+	0,
+	0,
+	0,
+	3717,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8552,
+	6054,
+	3339,
+	0,
+	0,
+	0,
+	0,
+	51622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28358,
+	0,
+	2756,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1462,
+	0,
+	0,
+	27622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62502,
+	14410,
+	56743,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12206,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36550,
+	0,
+	38054,
+	0,
+	0,
+	0,
+	21221,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27077,
+	0,
+	0,
+	16906,
+	0,
+	12587,
+	12101,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10414,
+	28775,
+	21769,
+	60167,
+	0,
+	56646,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20740,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5931,
+	5351,
+	0,
+	65478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7977,
+	52647,
+	0,
+	4868,
+	0,
+	0,
+	0,
+	55463,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32197,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13445,
+	0,
+	0,
+	0,
+	26631,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11237,
+	0,
+	0,
+	0,
+	0,
+	209,
+	1285,
+	0,
+	0,
+	1928,
+	0,
+	0,
+	0,
+	0,
+	43334,
+	23849,
+	23172,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24712,
+	62439,
+	8811,
+	3463,
+	20457,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16008,
+	56263,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60358,
+	22761,
+	6565,
+	0,
+	0,
+	30888,
+	27686,
+	0,
+	0,
+	0,
+	17093,
+	0,
+	0,
+	0,
+	0,
+	22121,
+	0,
+	0,
+	0,
+	7593,
+	14182,
+	0,
+	28103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31844,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18500,
+	0,
+	0,
+	0,
+	0,
+	28202,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26308,
+	0,
+	29541,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29572,
+	0,
+	0,
+	0,
+	21285,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60839,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30407,
+	15949,
+	2981,
+	0,
+	0,
+	0,
+	46439,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23911,
+	26505,
+	25222,
+	12811,
+	5895,
+	0,
+	6343,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31815,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19688,
+	10245,
+	0,
+	0,
+	0,
+	31301,
+	26985,
+	28964,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27208,
+	31172,
+	0,
+	0,
+	0,
+	0,
+	216,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8743,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6438,
+	0,
+	0,
+	0,
+	33319,
+	0,
+	0,
+	0,
+	33286,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22181,
+	7499,
+	24774,
+	0,
+	10756,
+	0,
+	44775,
+	724,
+	0,
+	25768,
+	25669,
+	24873,
+	5349,
+	25257,
+	0,
+	0,
+	54566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	327,
+	439,
+	357,
+	0,
+	0,
+	6536,
+	8452,
+	0,
+	0,
+	1802,
+	0,
+	0,
+	61350,
+	0,
+	15045,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38343,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32491,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22885,
+	0,
+	0,
+	32073,
+	0,
+	0,
+	0,
+	9546,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27748,
+	0,
+	0,
+	23176,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34118,
+	0,
+	0,
+	0,
+	0,
+	2158,
+	0,
+	5586,
+	30340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24452,
+	0,
+	0,
+	0,
+	0,
+	2409,
+	4390,
+	0,
+	24196,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32264,
+	26948,
+	20587,
+	0,
+	0,
+	0,
+	2155,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4328,
+	26276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23564,
+	0,
+	12458,
+	11367,
+	0,
+	0,
+	25162,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	65414,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32966,
+	0,
+	0,
+	0,
+	34662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39238,
+	0,
+	0,
+	0,
+	0,
+	11400,
+	10214,
+	266,
+	12452,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15173,
+	0,
+	0,
+	0,
+	13668,
+	0,
+	13222,
+	0,
+	23364,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11941,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25575,
+	0,
+	0,
+	0,
+	57383,
+	0,
+	0,
+	0,
+	10308,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2865,
+	9287,
+	75,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21508,
+	22380,
+	59526,
+	0,
+	0,
+	0,
+	23589,
+	0,
+	0,
+	0,
+	51590,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4645,
+	3980,
+	28295,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12388,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21734,
+	0,
+	17607,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41767,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18436,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21958,
+	0,
+	19430,
+	0,
+	0,
+	1204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3240,
+	55239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30660,
+	0,
+	0,
+	0,
+	28901,
+	0,
+	0,
+	0,
+	0,
+	4716,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11754,
+	0,
+	0,
+	0,
+	0,
+	22086,
+	0,
+	22564,
+	8749,
+	0,
+	0,
+	28391,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2886,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40358,
+	0,
+	0,
+	15916,
+	39526,
+	0,
+	13735,
+	0,
+	0,
+	0,
+	0,
+	28938,
+	0,
+	407,
+	4006,
+	0,
+	0,
+	0,
+	26916,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27526,
+	30280,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24586,
+	0,
+	24649,
+	5126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23019,
+	0,
+	22377,
+	18599,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27593,
+	9735,
+	0,
+	20196,
+	0,
+	0,
+	0,
+	0,
+	28168,
+	48423,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31399,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13892,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17606,
+	0,
+	0,
+	15242,
+	29767,
+	26378,
+	17701,
+	0,
+	0,
+	14472,
+	0,
+	4840,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24708,
+	0,
+	9349,
+	4330,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16137,
+	0,
+	0,
+	34854,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25063,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6603,
+	12583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7433,
+	29188,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31270,
+	0,
+	0,
+	22920,
+	3143,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23461,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	618,
+	0,
+	0,
+	0,
+	0,
+	21381,
+	0,
+	11524,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21004,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	312,
+	23239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2313,
+	0,
+	0,
+	40614,
+	0,
+	0,
+	14825,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46535,
+	0,
+	41190,
+	7853,
+	0,
+	31656,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3433,
+	5255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33958,
+	0,
+	0,
+	0,
+	0,
+	72,
+	15493,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14724,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29828,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18822,
+	20008,
+	0,
+	0,
+	0,
+	0,
+	2438,
+	2952,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24420,
+	0,
+	19908,
+	0,
+	0,
+	0,
+	8101,
+	0,
+	17479,
+	0,
+	0,
+	3530,
+	0,
+	8202,
+	29319,
+	0,
+	0,
+	1132,
+	6789,
+	0,
+	0,
+	23881,
+	0,
+	0,
+	0,
+	4810,
+	0,
+	0,
+	46918,
+	0,
+	0,
+	0,
+	41574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39334,
+	0,
+	0,
+	0,
+	26117,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5100,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23496,
+	27813,
+	4045,
+	54918,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6473,
+	7428,
+	0,
+	0,
+	0,
+	0,
+	6792,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3560,
+	32103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54790,
+	0,
+	0,
+	6926,
+	0,
+	0,
+	0,
+	0,
+	16518,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20806,
+	0,
+	0,
+	0,
+	0,
+	1841,
+	3174,
+	0,
+	0,
+	0,
+	0,
+	9612,
+	18374,
+	0,
+	0,
+	0,
+	0,
+	32744,
+	0,
+	0,
+	9671,
+	0,
+	59879,
+	0,
+	23300,
+	8073,
+	0,
+	0,
+	14758,
+	0,
+	0,
+	0,
+	10342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24808,
+	14759,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5515,
+	0,
+	0,
+	14852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2354,
+	23271,
+	0,
+	32740,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18472,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8972,
+	21669,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25574,
+	0,
+	0,
+	0,
+	0,
+	5096,
+	0,
+	14283,
+	55367,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12644,
+	0,
+	0,
+	0,
+	0,
+	4651,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	661,
+	0,
+	0,
+	13638,
+	19466,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31273,
+	0,
+	8010,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3211,
+	0,
+	0,
+	0,
+	0,
+	63430,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15237,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19018,
+	2437,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14312,
+	0,
+	0,
+	0,
+	0,
+	16836,
+	0,
+	0,
+	471,
+	35975,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6023,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11593,
+	9639,
+	0,
+	0,
+	0,
+	55783,
+	0,
+	5700,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27908,
+	0,
+	26598,
+	0,
+	0,
+	6667,
+	6470,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62534,
+	0,
+	0,
+	0,
+	0,
+	16522,
+	27911,
+	0,
+	0,
+	10025,
+	7172,
+	0,
+	0,
+	779,
+	0,
+	360,
+	17477,
+	0,
+	0,
+	0,
+	61991,
+	7752,
+	7717,
+	1494,
+	0,
+	0,
+	0,
+	26569,
+	40742,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26406,
+	10474,
+	32196,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50567,
+	16521,
+	11716,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55558,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61926,
+	0,
+	26436,
+	0,
+	0,
+	0,
+	0,
+	4459,
+	10598,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9223,
+	0,
+	29318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60423,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47078,
+	0,
+	50246,
+	0,
+	12612,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61799,
+	0,
+	55015,
+	0,
+	21060,
+	7309,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11976,
+	0,
+	0,
+	0,
+	0,
+	23527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10347,
+	15942,
+	0,
+	34023,
+	0,
+	0,
+	0,
+	0,
+	4969,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28997,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36454,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3466,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19716,
+	28872,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22152,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26342,
+	0,
+	0,
+	0,
+	9764,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21798,
+	0,
+	0,
+	0,
+	0,
+	13,
+	6853,
+	32136,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	750,
+	0,
+	0,
+	54502,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46183,
+	0,
+	0,
+	625,
+	22854,
+	0,
+	0,
+	0,
+	0,
+	2061,
+	23588,
+	0,
+	0,
+	11049,
+	56262,
+	0,
+	0,
+	18538,
+	1509,
+	0,
+	0,
+	17258,
+	4453,
+	0,
+	0,
+	0,
+	0,
+	12429,
+	0,
+	0,
+	0,
+	0,
+	8102,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8074,
+	0,
+	23852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16136,
+	3428,
+	0,
+	27876,
+	0,
+	0,
+	0,
+	7332,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28900,
+	0,
+	0,
+	2284,
+	0,
+	0,
+	17573,
+	201,
+	1508,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31365,
+	27688,
+	22565,
+	0,
+	0,
+	0,
+	5159,
+	0,
+	0,
+	0,
+	0,
+	4584,
+	42599,
+	0,
+	0,
+	0,
+	44422,
+	1068,
+	23173,
+	0,
+	0,
+	0,
+	613,
+	0,
+	0,
+	0,
+	12645,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27076,
+	6732,
+	0,
+	0,
+	0,
+	3913,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22244,
+	29992,
+	15911,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50598,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5161,
+	1574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19108,
+	0,
+	0,
+	0,
+	35014,
+	0,
+	0,
+	0,
+	25956,
+	29067,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47079,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1356,
+	61927,
+	0,
+	0,
+	0,
+	64455,
+	2122,
+	64231,
+	0,
+	0,
+	18763,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	907,
+	34471,
+	0,
+	0,
+	0,
+	39078,
+	0,
+	0,
+	1995,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56518,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	822,
+	0,
+	15978,
+	44423,
+	0,
+	0,
+	3112,
+	325,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15397,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1193,
+	4294,
+	4968,
+	15559,
+	0,
+	46150,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18917,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9928,
+	37543,
+	0,
+	0,
+	0,
+	0,
+	13097,
+	36999,
+	0,
+	0,
+	0,
+	15430,
+	0,
+	0,
+	8424,
+	29639,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25734,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40487,
+	0,
+	13284,
+	0,
+	11141,
+	0,
+	0,
+	0,
+	32388,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5298,
+	57702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13060,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8233,
+	42278,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36422,
+	0,
+	0,
+	0,
+	7972,
+	0,
+	0,
+	0,
+	18437,
+	0,
+	0,
+	0,
+	0,
+	7406,
+	0,
+	0,
+	0,
+	9225,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13865,
+	47591,
+	18220,
+	53703,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24940,
+	17223,
+	0,
+	0,
+	0,
+	13221,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15848,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6122,
+	1735,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16968,
+	18151,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26089,
+	19494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28809,
+	0,
+	0,
+	0,
+	0,
+	42727,
+	0,
+	55174,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20485,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58598,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15172,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35302,
+	0,
+	48135,
+	20972,
+	33094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9765,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39559,
+	0,
+	0,
+	13736,
+	6950,
+	0,
+	0,
+	0,
+	0,
+	23658,
+	8903,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58886,
+	7468,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64550,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47622,
+	0,
+	0,
+	0,
+	50886,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57606,
+	912,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1449,
+	0,
+	1169,
+	0,
+	718,
+	46151,
+	12104,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48230,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1259,
+	0,
+	0,
+	33734,
+	23208,
+	62567,
+	0,
+	65158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28684,
+	59878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25769,
+	0,
+	0,
+	0,
+	0,
+	65479,
+	0,
+	0,
+	0,
+	0,
+	555,
+	22789,
+	0,
+	19748,
+	1769,
+	10246,
+	8680,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14250,
+	0,
+	5899,
+	3303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21097,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21638,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10795,
+	0,
+	0,
+	0,
+	16204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26986,
+	2469,
+	0,
+	14660,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45447,
+	12234,
+	3494,
+	4555,
+	10566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2801,
+	0,
+	0,
+	0,
+	15755,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39654,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6763,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33574,
+	0,
+	10279,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63527,
+	0,
+	0,
+	3912,
+	0,
+	0,
+	7492,
+	0,
+	0,
+	0,
+	35142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17576,
+	8103,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16713,
+	4198,
+	0,
+	0,
+	4782,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16228,
+	0,
+	0,
+	0,
+	0,
+	25961,
+	20166,
+	0,
+	0,
+	0,
+	10980,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14340,
+	18922,
+	14567,
+	0,
+	44199,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18406,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37606,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20902,
+	0,
+	0,
+	0,
+	56358,
+	0,
+	38342,
+	0,
+	0,
+	0,
+	0,
+	9514,
+	36071,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21700,
+	0,
+	0,
+	5266,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1134,
+	0,
+	1453,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3882,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4004,
+	0,
+	0,
+	0,
+	51910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23076,
+	4648,
+	0,
+	0,
+	0,
+	31051,
+	25351,
+	0,
+	0,
+	0,
+	22884,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63975,
+	0,
+	0,
+	2376,
+	16997,
+	0,
+	0,
+	2096,
+	0,
+	0,
+	0,
+	3373,
+	7046,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30726,
+	0,
+	0,
+	0,
+	0,
+	20,
+	0,
+	13707,
+	614,
+	0,
+	0,
+	12840,
+	3079,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51046,
+	3729,
+	0,
+	32680,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24008,
+	62759,
+	0,
+	0,
+	4745,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2414,
+	0,
+	0,
+	44262,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24937,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19140,
+	0,
+	13575,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39110,
+	0,
+	0,
+	0,
+	28036,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4261,
+	0,
+	0,
+	0,
+	0,
+	5992,
+	0,
+	264,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13739,
+	0,
+	21928,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4232,
+	15110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30022,
+	0,
+	0,
+	27977,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24776,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2962,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26564,
+	22441,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13640,
+	11205,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19305,
+	1894,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14119,
+	5224,
+	135,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7470,
+	0,
+	0,
+	0,
+	0,
+	63815,
+	0,
+	55654,
+	0,
+	0,
+	12584,
+	0,
+	1524,
+	33223,
+	0,
+	0,
+	0,
+	9895,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11624,
+	0,
+	0,
+	0,
+	5614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21320,
+	0,
+	0,
+	53607,
+	0,
+	51206,
+	0,
+	0,
+	0,
+	25863,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8964,
+	1740,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13476,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7975,
+	0,
+	0,
+	3306,
+	8134,
+	0,
+	8389,
+	48,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25766,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52166,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21477,
+	31112,
+	31652,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28452,
+	0,
+	0,
+	0,
+	44231,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24805,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12428,
+	6471,
+	0,
+	0,
+	0,
+	0,
+	525,
+	17926,
+	0,
+	0,
+	0,
+	26919,
+	0,
+	0,
+	18120,
+	0,
+	0,
+	0,
+	30024,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29189,
+	0,
+	0,
+	0,
+	43559,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19787,
+	7557,
+	0,
+	59334,
+	0,
+	0,
+	10184,
+	6085,
+	0,
+	44039,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11175,
+	0,
+	0,
+	0,
+	0,
+	30440,
+	63110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11017,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27204,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	622,
+	0,
+	5226,
+	2727,
+	0,
+	15588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4650,
+	0,
+	2675,
+	0,
+	0,
+	32420,
+	0,
+	0,
+	0,
+	61511,
+	0,
+	0,
+	5419,
+	17829,
+	2123,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38183,
+	2640,
+	0,
+	11274,
+	14533,
+	1842,
+	0,
+	0,
+	42663,
+	12681,
+	3430,
+	0,
+	11845,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6533,
+	0,
+	0,
+	0,
+	0,
+	0,
+	54598,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12616,
+	38535,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32229,
+	0,
+	0,
+	0,
+	54279,
+	0,
+	48614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31401,
+	0,
+	0,
+	0,
+	0,
+	34310,
+	0,
+	0,
+	0,
+	22788,
+	0,
+	52134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23302,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40678,
+	0,
+	0,
+	0,
+	51463,
+	535,
+	0,
+	0,
+	0,
+	0,
+	15525,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4904,
+	869,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	63718,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1678,
+	0,
+	692,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26216,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29355,
+	0,
+	0,
+	0,
+	0,
+	25095,
+	0,
+	0,
+	0,
+	0,
+	4335,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14538,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27273,
+	55014,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27271,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30468,
+	0,
+	0,
+	0,
+	0,
+	18186,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14345,
+	0,
+	0,
+	0,
+	2152,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58438,
+	21034,
+	0,
+	23339,
+	21318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21412,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12869,
+	0,
+	0,
+	4875,
+	0,
+	0,
+	0,
+	0,
+	29191,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1640,
+	10247,
+	0,
+	14244,
+	0,
+	0,
+	0,
+	0,
+	9867,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12363,
+	0,
+	0,
+	7653,
+	0,
+	0,
+	4168,
+	2663,
+	0,
+	4580,
+	0,
+	11143,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6724,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13764,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	234,
+	6821,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25639,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29958,
+	0,
+	3461,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28324,
+	18795,
+	7013,
+	12746,
+	11655,
+	0,
+	37287,
+	0,
+	0,
+	10953,
+	7718,
+	9705,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8137,
+	17988,
+	0,
+	25156,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41415,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15784,
+	6918,
+	0,
+	0,
+	0,
+	0,
+	7019,
+	10919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4171,
+	55495,
+	4940,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22440,
+	19333,
+	0,
+	0,
+	28136,
+	0,
+	6249,
+	21317,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53414,
+	0,
+	0,
+	0,
+	57318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19940,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2698,
+	3911,
+	0,
+	0,
+	0,
+	26790,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32424,
+	0,
+	0,
+	18470,
+	0,
+	0,
+	0,
+	14726,
+	29834,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1000,
+	4197,
+	0,
+	0,
+	0,
+	19366,
+	0,
+	0,
+	0,
+	39878,
+	0,
+	0,
+	0,
+	0,
+	2185,
+	8901,
+	5288,
+	9829,
+	25000,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35622,
+	0,
+	0,
+	23048,
+	62503,
+	6506,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13609,
+	10438,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7723,
+	42119,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13317,
+	0,
+	0,
+	0,
+	41606,
+	0,
+	27111,
+	0,
+	0,
+	21194,
+	11461,
+	0,
+	0,
+	0,
+	0,
+	26856,
+	58342,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20940,
+	48710,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5227,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10061,
+	31300,
+	0,
+	0,
+	0,
+	19236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30277,
+	13896,
+	0,
+	0,
+	0,
+	12876,
+	13159,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	428,
+	46951,
+	13134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21668,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2249,
+	0,
+	0,
+	0,
+	0,
+	44967,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3465,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24868,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23909,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16164,
+	0,
+	10437,
+	0,
+	0,
+	5263,
+	20102,
+	20938,
+	0,
+	0,
+	0,
+	1192,
+	1030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21385,
+	4870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18596,
+	0,
+	0,
+	0,
+	0,
+	1422,
+	4038,
+	2858,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48998,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6508,
+	37350,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17001,
+	39431,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30182,
+	0,
+	21445,
+	7403,
+	28164,
+	0,
+	51750,
+	0,
+	0,
+	0,
+	62631,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31206,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7751,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13477,
+	0,
+	0,
+	456,
+	26693,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14890,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26697,
+	22022,
+	13225,
+	27364,
+	0,
+	0,
+	0,
+	18884,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3659,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1448,
+	5413,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6340,
+	0,
+	0,
+	18091,
+	18725,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22118,
+	0,
+	0,
+	0,
+	18981,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29223,
+	3724,
+	0,
+	0,
+	0,
+	0,
+	43526,
+	0,
+	0,
+	0,
+	25668,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21545,
+	9862,
+	0,
+	22692,
+	32201,
+	60646,
+	0,
+	7300,
+	0,
+	0,
+	0,
+	58887,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19460,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50342,
+	0,
+	65255,
+	4360,
+	17286,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28708,
+	0,
+	0,
+	30025,
+	60102,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47014,
+	0,
+	31973,
+	0,
+	9572,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18501,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14597,
+	0,
+	0,
+	0,
+	53735,
+	5228,
+	22183,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1554,
+	24164,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10827,
+	0,
+	0,
+	0,
+	0,
+	34918,
+	0,
+	0,
+	0,
+	0,
+	22252,
+	0,
+	0,
+	46855,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31207,
+	0,
+	0,
+	10733,
+	0,
+	0,
+	63334,
+	0,
+	0,
+	0,
+	0,
+	8616,
+	50119,
+	20169,
+	12678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58087,
+	20298,
+	5,
+	0,
+	0,
+	30920,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	296,
+	13190,
+	0,
+	30663,
+	0,
+	0,
+	18536,
+	12228,
+	0,
+	6788,
+	0,
+	0,
+	0,
+	0,
+	30890,
+	21796,
+	0,
+	0,
+	526,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20965,
+	0,
+	0,
+	0,
+	0,
+	2161,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24038,
+	0,
+	0,
+	0,
+	0,
+	13544,
+	7398,
+	0,
+	0,
+	32522,
+	9605,
+	0,
+	0,
+	0,
+	0,
+	3208,
+	7590,
+	0,
+	0,
+	0,
+	43846,
+	0,
+	0,
+	0,
+	38663,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39014,
+	4142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6373,
+	0,
+	0,
+	13676,
+	0,
+	0,
+	0,
+	0,
+	30374,
+	21288,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22791,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37958,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9452,
+	9990,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4841,
+	0,
+	0,
+	0,
+	0,
+	18820,
+	152,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13260,
+	3334,
+	0,
+	0,
+	24234,
+	8422,
+	0,
+	17957,
+	0,
+	0,
+	0,
+	10244,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7204,
+	0,
+	0,
+	0,
+	0,
+	1201,
+	26151,
+	0,
+	31173,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64838,
+	4203,
+	7525,
+	521,
+	0,
+	18888,
+	37031,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7082,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4490,
+	12487,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36615,
+	0,
+	0,
+	0,
+	14854,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6539,
+	13029,
+	9704,
+	38983,
+	0,
+	0,
+	0,
+	0,
+	168,
+	10405,
+	0,
+	0,
+	0,
+	0,
+	394,
+	25607,
+	0,
+	57063,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16141,
+	19878,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29446,
+	0,
+	12036,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6982,
+	18572,
+	0,
+	24584,
+	14535,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16,
+	0,
+	21642,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5254,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3853,
+	9126,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7241,
+	10982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	950,
+	0,
+	0,
+	57990,
+	0,
+	0,
+	277,
+	0,
+	0,
+	0,
+	694,
+	36007,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42470,
+	0,
+	0,
+	0,
+	0,
+	18409,
+	51142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28646,
+	0,
+	0,
+	0,
+	30693,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56295,
+	5544,
+	0,
+	0,
+	0,
+	0,
+	8518,
+	8366,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45670,
+	0,
+	0,
+	9608,
+	33062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18694,
+	0,
+	0,
+	0,
+	0,
+	1672,
+	23493,
+	0,
+	0,
+	6955,
+	7655,
+	0,
+	36134,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23432,
+	647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13382,
+	0,
+	0,
+	0,
+	19621,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20228,
+	0,
+	0,
+	2728,
+	31495,
+	0,
+	0,
+	0,
+	0,
+	29096,
+	22213,
+	235,
+	35495,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5348,
+	0,
+	0,
+	0,
+	0,
+	8968,
+	1989,
+	0,
+	0,
+	1066,
+	0,
+	0,
+	11492,
+	5965,
+	31367,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18727,
+	0,
+	0,
+	0,
+	6757,
+	0,
+	0,
+	10765,
+	4646,
+	0,
+	36166,
+	0,
+	27943,
+	0,
+	0,
+	26888,
+	8420,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29316,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4975,
+	0,
+	0,
+	0,
+	14762,
+	3111,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43399,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18980,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44550,
+	0,
+	0,
+	0,
+	0,
+	4051,
+	0,
+	0,
+	0,
+	0,
+	37734,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5188,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24486,
+	0,
+	5989,
+	0,
+	41159,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20326,
+	0,
+	0,
+	747,
+	6884,
+	0,
+	0,
+	0,
+	41798,
+	0,
+	0,
+	3117,
+	22919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21032,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4302,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21068,
+	34630,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64071,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26667,
+	7943,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52934,
+	0,
+	0,
+	17002,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20294,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27301,
+	18347,
+	7974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16874,
+	0,
+	0,
+	0,
+	0,
+	45414,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	648,
+	1575,
+	0,
+	0,
+	0,
+	31749,
+	0,
+	0,
+	0,
+	23301,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15912,
+	50535,
+	0,
+	0,
+	0,
+	0,
+	1993,
+	8582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38438,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15850,
+	6183,
+	0,
+	0,
+	0,
+	0,
+	3402,
+	0,
+	0,
+	27494,
+	0,
+	0,
+	749,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26025,
+	29606,
+	0,
+	0,
+	7144,
+	19622,
+	30504,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21316,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21444,
+	0,
+	0,
+	1289,
+	6919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8299,
+	0,
+	0,
+	0,
+	14090,
+	35655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2377,
+	15206,
+	0,
+	0,
+	6028,
+	4452,
+	0,
+	25508,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50310,
+	0,
+	0,
+	0,
+	0,
+	1269,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51014,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9286,
+	0,
+	7429,
+	0,
+	0,
+	28393,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16680,
+	452,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23718,
+	0,
+	0,
+	0,
+	31750,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3568,
+	0,
+	0,
+	13604,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25255,
+	0,
+	0,
+	0,
+	50982,
+	0,
+	56582,
+	0,
+	0,
+	7467,
+	0,
+	0,
+	0,
+	0,
+	30181,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30564,
+	7208,
+	7845,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7726,
+	0,
+	0,
+	0,
+	0,
+	62182,
+	0,
+	0,
+	0,
+	41094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17736,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50054,
+	0,
+	0,
+	0,
+	14180,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23332,
+	0,
+	0,
+	0,
+	11140,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24262,
+	27145,
+	9540,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26537,
+	45510,
+	6062,
+	3879,
+	0,
+	0,
+	20233,
+	25991,
+	0,
+	0,
+	17803,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13962,
+	5508,
+	16971,
+	27013,
+	7437,
+	31494,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4714,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17189,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27492,
+	0,
+	0,
+	26953,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41319,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47430,
+	19596,
+	12549,
+	0,
+	0,
+	0,
+	8390,
+	1006,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24100,
+	17577,
+	4,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22277,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26692,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24676,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29477,
+	0,
+	0,
+	0,
+	21573,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9864,
+	14214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25771,
+	5766,
+	0,
+	0,
+	8909,
+	8679,
+	0,
+	0,
+	6861,
+	16166,
+	0,
+	38887,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12392,
+	8678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52646,
+	1354,
+	2950,
+	0,
+	14692,
+	0,
+	0,
+	10572,
+	49830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3626,
+	582,
+	0,
+	0,
+	0,
+	55750,
+	0,
+	0,
+	0,
+	30885,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5830,
+	0,
+	0,
+	2090,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31142,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10503,
+	0,
+	0,
+	18825,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57158,
+	0,
+	0,
+	30792,
+	63526,
+	0,
+	0,
+	0,
+	9863,
+	16267,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18824,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19653,
+	25388,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36358,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25480,
+	23015,
+	0,
+	0,
+	10440,
+	6725,
+	0,
+	0,
+	0,
+	22436,
+	24265,
+	15109,
+	0,
+	0,
+	0,
+	62311,
+	8906,
+	34534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15913,
+	1319,
+	0,
+	0,
+	20296,
+	1477,
+	30760,
+	0,
+	25928,
+	16772,
+	0,
+	0,
+	1069,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17029,
+	0,
+	31909,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41638,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41542,
+	0,
+	21478,
+	0,
+	0,
+	0,
+	9796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22187,
+	58343,
+	0,
+	0,
+	0,
+	24295,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61831,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2833,
+	5829,
+	0,
+	0,
+	0,
+	62855,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16676,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13577,
+	27431,
+	0,
+	0,
+	0,
+	0,
+	21480,
+	10501,
+	0,
+	16932,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22918,
+	0,
+	48294,
+	2574,
+	2150,
+	0,
+	0,
+	0,
+	0,
+	1897,
+	4518,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25064,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47942,
+	0,
+	0,
+	0,
+	0,
+	10990,
+	13767,
+	25705,
+	37863,
+	21672,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43430,
+	1712,
+	0,
+	0,
+	0,
+	0,
+	18886,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10535,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15012,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14734,
+	0,
+	0,
+	55782,
+	0,
+	0,
+	30824,
+	10886,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51302,
+	0,
+	0,
+	8012,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20680,
+	6981,
+	0,
+	57415,
+	11,
+	0,
+	0,
+	18277,
+	0,
+	14564,
+	0,
+	0,
+	0,
+	32390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19113,
+	5158,
+	0,
+	11172,
+	0,
+	16774,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10315,
+	13830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10410,
+	7141,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18116,
+	0,
+	0,
+	0,
+	44615,
+	15403,
+	13958,
+	0,
+	1540,
+	14632,
+	19525,
+	24201,
+	19781,
+	0,
+	0,
+	0,
+	24165,
+	0,
+	38951,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6308,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17416,
+	15749,
+	3438,
+	13255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32228,
+	0,
+	0,
+	0,
+	0,
+	176,
+	0,
+	0,
+	50566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21540,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5284,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25897,
+	28326,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15945,
+	0,
+	0,
+	0,
+	9804,
+	293,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13988,
+	23082,
+	4677,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6405,
+	0,
+	30692,
+	0,
+	0,
+	0,
+	61702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45926,
+	0,
+	15398,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4554,
+	2692,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32485,
+	0,
+	0,
+	0,
+	10084,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24297,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22790,
+	0,
+	0,
+	0,
+	55110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7112,
+	0,
+	31530,
+	45255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40743,
+	17226,
+	22599,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56999,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13799,
+	3114,
+	21287,
+	1353,
+	7591,
+	0,
+	0,
+	0,
+	8455,
+	0,
+	0,
+	6824,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14569,
+	0,
+	0,
+	0,
+	29000,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19979,
+	0,
+	18376,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11332,
+	0,
+	0,
+	0,
+	49863,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2191,
+	7527,
+	23148,
+	58022,
+	0,
+	0,
+	0,
+	30631,
+	0,
+	26565,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	754,
+	0,
+	0,
+	15877,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17510,
+	7657,
+	2821,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41927,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2569,
+	34439,
+	0,
+	0,
+	3790,
+	0,
+	0,
+	0,
+	15339,
+	8775,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15908,
+	0,
+	0,
+	21419,
+	8359,
+	0,
+	0,
+	0,
+	0,
+	424,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25318,
+	8008,
+	20551,
+	0,
+	0,
+	0,
+	45735,
+	30058,
+	30372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26180,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31432,
+	10567,
+	0,
+	0,
+	0,
+	0,
+	17450,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30310,
+	0,
+	38022,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28932,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43910,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22180,
+	12075,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22247,
+	0,
+	0,
+	22826,
+	12359,
+	0,
+	0,
+	0,
+	0,
+	4105,
+	50407,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13581,
+	28583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28936,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17673,
+	10310,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	905,
+	57862,
+	1580,
+	0,
+	0,
+	0,
+	0,
+	58630,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13479,
+	0,
+	0,
+	14153,
+	13286,
+	0,
+	0,
+	9259,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6606,
+	3524,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6567,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	631,
+	49255,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42886,
+	0,
+	38215,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17580,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55046,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10213,
+	0,
+	0,
+	0,
+	0,
+	3604,
+	37767,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30950,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62087,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23528,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28715,
+	4229,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1226,
+	26820,
+	0,
+	0,
+	0,
+	12133,
+	6984,
+	261,
+	21130,
+	32548,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3565,
+	12390,
+	20713,
+	28071,
+	0,
+	0,
+	1706,
+	25287,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30534,
+	0,
+	0,
+	0,
+	12615,
+	0,
+	43750,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28228,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1224,
+	3975,
+	10954,
+	6375,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23180,
+	20100,
+	0,
+	0,
+	0,
+	0,
+	25736,
+	8519,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6663,
+	0,
+	2534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23720,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19398,
+	0,
+	47814,
+	26281,
+	49702,
+	0,
+	0,
+	4332,
+	12965,
+	0,
+	0,
+	5704,
+	3206,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15396,
+	0,
+	0,
+	0,
+	44102,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25317,
+	1064,
+	39271,
+	27433,
+	0,
+	14952,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14308,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2763,
+	4100,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18792,
+	0,
+	0,
+	0,
+	22154,
+	32583,
+	0,
+	6244,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21894,
+	0,
+	0,
+	11048,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11685,
+	0,
+	53862,
+	0,
+	0,
+	15114,
+	0,
+	13870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	919,
+	0,
+	0,
+	0,
+	31916,
+	0,
+	22570,
+	101,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7333,
+	0,
+	0,
+	0,
+	0,
+	3272,
+	0,
+	0,
+	0,
+	0,
+	27718,
+	32712,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1782,
+	0,
+	3688,
+	0,
+	0,
+	0,
+	0,
+	29862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22469,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55302,
+	850,
+	15492,
+	0,
+	0,
+	0,
+	5927,
+	19786,
+	13350,
+	0,
+	25702,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20260,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15335,
+	8394,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26566,
+	0,
+	0,
+	0,
+	0,
+	843,
+	2245,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6959,
+	0,
+	20488,
+	1638,
+	0,
+	0,
+	11533,
+	50759,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20871,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24519,
+	0,
+	0,
+	0,
+	0,
+	9544,
+	23591,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20969,
+	7109,
+	29001,
+	0,
+	0,
+	32422,
+	31720,
+	64294,
+	0,
+	0,
+	0,
+	0,
+	16106,
+	0,
+	0,
+	0,
+	6930,
+	4933,
+	0,
+	0,
+	0,
+	22917,
+	0,
+	27015,
+	0,
+	0,
+	0,
+	0,
+	19880,
+	8070,
+	0,
+	0,
+	0,
+	0,
+	23945,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3310,
+	0,
+	87,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18439,
+	0,
+	0,
+	0,
+	20742,
+	0,
+	0,
+	0,
+	10597,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20236,
+	0,
+	0,
+	0,
+	16584,
+	3429,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27241,
+	0,
+	0,
+	0,
+	0,
+	16132,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	244,
+	28261,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29509,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2921,
+	31781,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6408,
+	4196,
+	344,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11689,
+	45863,
+	0,
+	0,
+	0,
+	0,
+	906,
+	3301,
+	0,
+	0,
+	25544,
+	32421,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1260,
+	61607,
+	0,
+	27302,
+	0,
+	0,
+	8682,
+	16614,
+	0,
+	0,
+	0,
+	0,
+	10830,
+	0,
+	0,
+	9604,
+	15049,
+	13413,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26761,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61990,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12580,
+	0,
+	0,
+	11432,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22507,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2408,
+	22661,
+	14507,
+	43239,
+	0,
+	9700,
+	0,
+	0,
+	24714,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23972,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34086,
+	0,
+	0,
+	22955,
+	7238,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28485,
+	13806,
+	20038,
+	0,
+	0,
+	0,
+	0,
+	22602,
+	0,
+	0,
+	0,
+	1645,
+	22340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26502,
+	0,
+	0,
+	554,
+	0,
+	0,
+	0,
+	0,
+	61735,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2694,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	883,
+	27879,
+	15948,
+	0,
+	3242,
+	57382,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13930,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30922,
+	0,
+	4137,
+	52615,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31911,
+	16072,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26340,
+	0,
+	61671,
+	0,
+	0,
+	0,
+	0,
+	3145,
+	56199,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	280,
+	0,
+	5131,
+	33479,
+	0,
+	15751,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4136,
+	1446,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11304,
+	17863,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25125,
+	0,
+	0,
+	0,
+	36646,
+	6057,
+	0,
+	0,
+	0,
+	855,
+	11301,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64774,
+	0,
+	0,
+	0,
+	19397,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1040,
+	27367,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64358,
+	0,
+	0,
+	178,
+	132,
+	0,
+	0,
+	14763,
+	24455,
+	0,
+	0,
+	0,
+	46374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46246,
+	0,
+	0,
+	0,
+	37382,
+	0,
+	0,
+	0,
+	7462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8166,
+	0,
+	0,
+	6921,
+	0,
+	0,
+	0,
+	9163,
+	0,
+	0,
+	0,
+	119,
+	0,
+	0,
+	0,
+	23146,
+	17156,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9127,
+	0,
+	0,
+	0,
+	17927,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22084,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39879,
+	0,
+	0,
+	2035,
+	0,
+	1067,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16652,
+	59591,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20171,
+	0,
+	0,
+	0,
+	0,
+	17733,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32037,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14277,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42022,
+	0,
+	0,
+	26793,
+	20358,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8907,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27780,
+	0,
+	0,
+	0,
+	0,
+	32330,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39399,
+	0,
+	9732,
+	0,
+	16199,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	854,
+	0,
+	2984,
+	45063,
+	25418,
+	26980,
+	22539,
+	0,
+	9133,
+	3653,
+	15528,
+	28743,
+	4649,
+	0,
+	616,
+	65127,
+	0,
+	0,
+	0,
+	61863,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55303,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23880,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31848,
+	62854,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49606,
+	0,
+	27974,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32580,
+	0,
+	0,
+	0,
+	26052,
+	4043,
+	0,
+	0,
+	40454,
+	0,
+	0,
+	26056,
+	30565,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31398,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29288,
+	1797,
+	0,
+	0,
+	3220,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20427,
+	0,
+	0,
+	0,
+	0,
+	23621,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24261,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35591,
+	0,
+	0,
+	6862,
+	0,
+	0,
+	0,
+	4265,
+	0,
+	6285,
+	5383,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36870,
+	0,
+	39847,
+	0,
+	0,
+	17224,
+	5414,
+	27882,
+	58118,
+	0,
+	0,
+	13224,
+	4262,
+	0,
+	0,
+	0,
+	31302,
+	0,
+	0,
+	1388,
+	2982,
+	11881,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16837,
+	809,
+	0,
+	24140,
+	10724,
+	0,
+	0,
+	0,
+	0,
+	5835,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1256,
+	19237,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5796,
+	11848,
+	0,
+	0,
+	52870,
+	11464,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5645,
+	9158,
+	0,
+	25223,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39142,
+	24968,
+	8135,
+	32104,
+	28678,
+	0,
+	0,
+	0,
+	46311,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23820,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4050,
+	0,
+	1323,
+	25220,
+	0,
+	0,
+	0,
+	20133,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9381,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13444,
+	1198,
+	60806,
+	0,
+	0,
+	0,
+	0,
+	17356,
+	50247,
+	30632,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11944,
+	999,
+	0,
+	0,
+	0,
+	0,
+	4010,
+	10404,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10346,
+	0,
+	0,
+	49510,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6351,
+	60966,
+	20137,
+	487,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	655,
+	2406,
+	17387,
+	43303,
+	0,
+	0,
+	0,
+	17063,
+	0,
+	0,
+	213,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17221,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10820,
+	0,
+	0,
+	0,
+	0,
+	369,
+	6,
+	0,
+	0,
+	9098,
+	21093,
+	0,
+	31653,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27143,
+	0,
+	0,
+	16234,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6020,
+	31723,
+	28293,
+	0,
+	0,
+	0,
+	0,
+	1936,
+	30695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52902,
+	0,
+	0,
+	29512,
+	10791,
+	0,
+	20420,
+	0,
+	0,
+	16010,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5324,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13383,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24328,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24648,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7786,
+	2852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44678,
+	0,
+	17925,
+	0,
+	0,
+	105,
+	53062,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18762,
+	0,
+	0,
+	40679,
+	0,
+	0,
+	0,
+	16165,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62310,
+	1322,
+	14247,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1832,
+	6052,
+	0,
+	0,
+	11882,
+	0,
+	0,
+	0,
+	0,
+	17668,
+	0,
+	28262,
+	0,
+	29542,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28007,
+	0,
+	0,
+	0,
+	57223,
+	1585,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21162,
+	0,
+	0,
+	62247,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25414,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36326,
+	0,
+	0,
+	0,
+	23845,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2693,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13125,
+	0,
+	31236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22502,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5994,
+	10309,
+	0,
+	0,
+	0,
+	7269,
+	0,
+	0,
+	0,
+	0,
+	17929,
+	0,
+	1011,
+	44647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14919,
+	0,
+	0,
+	0,
+	0,
+	20586,
+	5350,
+	0,
+	0,
+	0,
+	45702,
+	0,
+	13189,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35782,
+	17992,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8203,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38087,
+	4233,
+	0,
+	2127,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10148,
+	0,
+	0,
+	0,
+	2021,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47206,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9220,
+	0,
+	0,
+	0,
+	0,
+	19465,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	39206,
+	0,
+	38055,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46982,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22054,
+	3850,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55,
+	0,
+	10542,
+	0,
+	0,
+	0,
+	0,
+	7239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	59367,
+	0,
+	0,
+	14761,
+	0,
+	0,
+	0,
+	0,
+	43079,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2726,
+	0,
+	0,
+	9582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	37478,
+	0,
+	0,
+	0,
+	31364,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20393,
+	8933,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9380,
+	0,
+	0,
+	0,
+	0,
+	16905,
+	549,
+	0,
+	0,
+	0,
+	0,
+	182,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1558,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19242,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24933,
+	0,
+	6276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42310,
+	23595,
+	24068,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13191,
+	6158,
+	2567,
+	0,
+	0,
+	268,
+	47047,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27940,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26726,
+	0,
+	0,
+	0,
+	0,
+	8200,
+	1222,
+	31562,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2922,
+	8231,
+	8904,
+	29157,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23976,
+	4836,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31658,
+	0,
+	0,
+	31685,
+	0,
+	0,
+	2889,
+	6213,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13605,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24772,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20684,
+	26468,
+	24075,
+	0,
+	0,
+	0,
+	21193,
+	0,
+	715,
+	679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3050,
+	7654,
+	0,
+	0,
+	0,
+	13798,
+	0,
+	0,
+	0,
+	0,
+	15,
+	27973,
+	0,
+	0,
+	8491,
+	2086,
+	0,
+	0,
+	0,
+	43206,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	60391,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25892,
+	0,
+	22276,
+	0,
+	34374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20165,
+	0,
+	0,
+	25672,
+	0,
+	0,
+	0,
+	1811,
+	24839,
+	0,
+	31044,
+	0,
+	0,
+	25513,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12810,
+	0,
+	0,
+	62438,
+	0,
+	0,
+	1325,
+	0,
+	364,
+	3782,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8042,
+	19687,
+	0,
+	0,
+	0,
+	33415,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7205,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11844,
+	0,
+	0,
+	0,
+	0,
+	3341,
+	1543,
+	6698,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29766,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41158,
+	0,
+	24294,
+	0,
+	3844,
+	12329,
+	0,
+	0,
+	0,
+	13738,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26245,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6378,
+	0,
+	343,
+	4838,
+	0,
+	0,
+	0,
+	24358,
+	11688,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1489,
+	34759,
+	0,
+	0,
+	0,
+	0,
+	363,
+	51974,
+	1878,
+	11013,
+	0,
+	0,
+	32265,
+	59782,
+	0,
+	0,
+	0,
+	28421,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22756,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14089,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29257,
+	61222,
+	0,
+	30661,
+	0,
+	28327,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27108,
+	8843,
+	0,
+	9673,
+	2084,
+	0,
+	0,
+	0,
+	16327,
+	0,
+	48455,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4876,
+	9316,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9035,
+	18852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4164,
+	0,
+	0,
+	14827,
+	1349,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11909,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21765,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31272,
+	63910,
+	0,
+	0,
+	0,
+	25924,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44487,
+	0,
+	0,
+	0,
+	20612,
+	0,
+	0,
+	27754,
+	31428,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17287,
+	0,
+	3943,
+	0,
+	0,
+	0,
+	63302,
+	0,
+	0,
+	0,
+	0,
+	25256,
+	19942,
+	0,
+	55142,
+	0,
+	39046,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15367,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28422,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9576,
+	63847,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25226,
+	5734,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13801,
+	4997,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43942,
+	1270,
+	2566,
+	6284,
+	0,
+	0,
+	16230,
+	0,
+	0,
+	0,
+	20678,
+	0,
+	0,
+	0,
+	0,
+	0,
+	38855,
+	0,
+	0,
+	0,
+	0,
+	29643,
+	0,
+	0,
+	0,
+	41,
+	3655,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15686,
+	0,
+	0,
+	0,
+	15718,
+	0,
+	50694,
+	0,
+	0,
+	16232,
+	8007,
+	0,
+	0,
+	0,
+	5060,
+	329,
+	11591,
+	51,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13065,
+	7302,
+	27530,
+	15366,
+	0,
+	24934,
+	0,
+	0,
+	0,
+	17828,
+	0,
+	0,
+	4552,
+	6311,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47686,
+	368,
+	12103,
+	10122,
+	33830,
+	0,
+	0,
+	599,
+	18534,
+	9579,
+	49479,
+	0,
+	5668,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13157,
+	0,
+	0,
+	0,
+	0,
+	23274,
+	14055,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48903,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1871,
+	0,
+	15434,
+	0,
+	0,
+	0,
+	16174,
+	62470,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7749,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30501,
+	0,
+	0,
+	0,
+	0,
+	25675,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9285,
+	0,
+	0,
+	25323,
+	1669,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32902,
+	0,
+	0,
+	4426,
+	0,
+	0,
+	0,
+	0,
+	57959,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29898,
+	58278,
+	0,
+	0,
+	0,
+	0,
+	11880,
+	1220,
+	0,
+	0,
+	0,
+	41479,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23141,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42566,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40167,
+	9484,
+	3493,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21126,
+	0,
+	0,
+	0,
+	0,
+	8649,
+	18918,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34886,
+	2601,
+	0,
+	0,
+	0,
+	0,
+	12518,
+	0,
+	0,
+	0,
+	0,
+	7976,
+	10311,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45190,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16842,
+	20229,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7528,
+	4614,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30086,
+	0,
+	0,
+	0,
+	1671,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9896,
+	6277,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61191,
+	0,
+	41287,
+	0,
+	21956,
+	0,
+	0,
+	20010,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13195,
+	0,
+	0,
+	0,
+	0,
+	1381,
+	0,
+	0,
+	0,
+	0,
+	365,
+	30951,
+	24268,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7044,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27944,
+	359,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28487,
+	0,
+	0,
+	77,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	56775,
+	12586,
+	8421,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26185,
+	14599,
+	0,
+	0,
+	8040,
+	5702,
+	12585,
+	3109,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21574,
+	5388,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5106,
+	52454,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1907,
+	29895,
+	0,
+	6116,
+	0,
+	0,
+	0,
+	0,
+	11081,
+	5285,
+	0,
+	28069,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4104,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41511,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5262,
+	0,
+	0,
+	0,
+	503,
+	4231,
+	7720,
+	34343,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7304,
+	10374,
+	1718,
+	0,
+	0,
+	29127,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23497,
+	22567,
+	6952,
+	2340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20360,
+	12453,
+	0,
+	45094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28582,
+	0,
+	0,
+	0,
+	0,
+	680,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23084,
+	0,
+	0,
+	0,
+	30696,
+	0,
+	0,
+	0,
+	0,
+	45862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5580,
+	6053,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	712,
+	70,
+	0,
+	0,
+	26091,
+	11335,
+	0,
+	0,
+	0,
+	0,
+	13612,
+	0,
+	13160,
+	1926,
+	435,
+	51559,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47302,
+	19083,
+	0,
+	0,
+	12742,
+	0,
+	1607,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6155,
+	37095,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18948,
+	7146,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7848,
+	2055,
+	0,
+	0,
+	0,
+	0,
+	8910,
+	0,
+	19336,
+	0,
+	0,
+	48070,
+	8490,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9932,
+	56423,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4133,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43398,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16173,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32011,
+	0,
+	0,
+	30918,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26854,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20389,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18889,
+	0,
+	0,
+	0,
+	0,
+	8965,
+	0,
+	0,
+	0,
+	44358,
+	0,
+	0,
+	0,
+	8997,
+	0,
+	34055,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29350,
+	0,
+	0,
+	501,
+	17767,
+	0,
+	0,
+	32457,
+	60262,
+	0,
+	0,
+	0,
+	30886,
+	0,
+	0,
+	3757,
+	1063,
+	0,
+	0,
+	0,
+	25637,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28068,
+	0,
+	26374,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24779,
+	229,
+	0,
+	13766,
+	0,
+	0,
+	7402,
+	11525,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26313,
+	23686,
+	0,
+	0,
+	29736,
+	47527,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27268,
+	0,
+	0,
+	0,
+	0,
+	553,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32038,
+	0,
+	0,
+	0,
+	0,
+	1135,
+	26596,
+	0,
+	0,
+	12300,
+	14631,
+	0,
+	0,
+	0,
+	43238,
+	0,
+	871,
+	0,
+	0,
+	31496,
+	0,
+	8457,
+	17669,
+	0,
+	12836,
+	0,
+	0,
+	0,
+	22726,
+	0,
+	38758,
+	0,
+	0,
+	375,
+	6564,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	170,
+	18535,
+	0,
+	22948,
+	0,
+	0,
+	32360,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25764,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15652,
+	0,
+	0,
+	0,
+	32774,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28551,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15145,
+	0,
+	0,
+	0,
+	21100,
+	27654,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4874,
+	26215,
+	0,
+	1639,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4169,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7336,
+	0,
+	0,
+	0,
+	0,
+	21572,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24644,
+	1675,
+	2533,
+	0,
+	0,
+	0,
+	53318,
+	0,
+	13094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6246,
+	0,
+	22020,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28453,
+	5576,
+	5124,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27910,
+	0,
+	29382,
+	18216,
+	8583,
+	0,
+	0,
+	0,
+	39174,
+	0,
+	43558,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11973,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23397,
+	0,
+	0,
+	0,
+	0,
+	6091,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6474,
+	16197,
+	14217,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26728,
+	0,
+	567,
+	48839,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15271,
+	0,
+	0,
+	31818,
+	43974,
+	2450,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11368,
+	9191,
+	0,
+	44454,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14568,
+	12293,
+	0,
+	0,
+	0,
+	8453,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32040,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	902,
+	0,
+	0,
+	0,
+	27236,
+	5612,
+	11495,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9194,
+	23684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27430,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26217,
+	44870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5581,
+	7173,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52775,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20968,
+	18340,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4107,
+	11239,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29381,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21990,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48806,
+	0,
+	0,
+	0,
+	32292,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10884,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27562,
+	0,
+	5643,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3089,
+	31525,
+	0,
+	19684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	61415,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7908,
+	0,
+	0,
+	0,
+	0,
+	872,
+	743,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1229,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32484,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34822,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50726,
+	0,
+	0,
+	0,
+	0,
+	7274,
+	0,
+	0,
+	0,
+	15304,
+	11526,
+	0,
+	0,
+	0,
+	3047,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22376,
+	0,
+	0,
+	0,
+	846,
+	0,
+	0,
+	0,
+	0,
+	35815,
+	0,
+	0,
+	0,
+	23652,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23721,
+	2148,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14856,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1358,
+	0,
+	3082,
+	0,
+	0,
+	0,
+	848,
+	10949,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6504,
+	0,
+	0,
+	14372,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8201,
+	9958,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24266,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26469,
+	0,
+	0,
+	0,
+	0,
+	18604,
+	2053,
+	0,
+	33511,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9222,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44006,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	41895,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12044,
+	390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4935,
+	0,
+	48646,
+	0,
+	56102,
+	3052,
+	16070,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8612,
+	9320,
+	38311,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	500,
+	0,
+	0,
+	0,
+	0,
+	42918,
+	0,
+	32550,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27434,
+	57926,
+	17064,
+	0,
+	0,
+	46502,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26760,
+	6756,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	624,
+	0,
+	5000,
+	0,
+	0,
+	0,
+	0,
+	32293,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26246,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23,
+	7301,
+	0,
+	0,
+	0,
+	36199,
+	0,
+	40838,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27178,
+	57350,
+	0,
+	0,
+	12457,
+	9317,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16810,
+	0,
+	0,
+	0,
+	14510,
+	0,
+	0,
+	0,
+	0,
+	21319,
+	0,
+	0,
+	0,
+	13508,
+	17,
+	11365,
+	0,
+	0,
+	0,
+	0,
+	5291,
+	0,
+	8329,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27685,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	52006,
+	0,
+	0,
+	0,
+	7493,
+	0,
+	44263,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9800,
+	0,
+	0,
+	0,
+	25676,
+	61478,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5773,
+	0,
+	0,
+	0,
+	0,
+	41991,
+	26057,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22629,
+	0,
+	0,
+	0,
+	47783,
+	362,
+	1959,
+	23468,
+	0,
+	0,
+	0,
+	10921,
+	0,
+	0,
+	0,
+	3150,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32456,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4559,
+	3270,
+	0,
+	0,
+	983,
+	0,
+	0,
+	26343,
+	0,
+	33446,
+	0,
+	0,
+	0,
+	61767,
+	0,
+	48390,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2790,
+	0,
+	39782,
+	7849,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1544,
+	2183,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4040,
+	2471,
+	20009,
+	30020,
+	0,
+	0,
+	11242,
+	0,
+	0,
+	0,
+	5578,
+	53382,
+	0,
+	22631,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12901,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	215,
+	0,
+	0,
+	9030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	265,
+	1412,
+	0,
+	0,
+	11626,
+	3687,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17449,
+	24359,
+	0,
+	0,
+	26729,
+	40134,
+	0,
+	0,
+	0,
+	0,
+	29768,
+	61958,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20908,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11016,
+	0,
+	0,
+	47462,
+	21547,
+	5926,
+	0,
+	0,
+	14728,
+	2983,
+	24104,
+	15301,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32645,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3300,
+	0,
+	0,
+	0,
+	15972,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6634,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3076,
+	0,
+	30983,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	106,
+	0,
+	0,
+	0,
+	0,
+	12775,
+	0,
+	0,
+	0,
+	0,
+	7177,
+	18022,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49894,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27560,
+	0,
+	0,
+	0,
+	0,
+	30278,
+	10668,
+	23877,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29124,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	44582,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48454,
+	0,
+	0,
+	0,
+	0,
+	6442,
+	0,
+	16330,
+	22951,
+	0,
+	0,
+	16904,
+	0,
+	0,
+	0,
+	0,
+	644,
+	0,
+	0,
+	0,
+	40038,
+	0,
+	0,
+	0,
+	37222,
+	0,
+	0,
+	0,
+	9830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34919,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13733,
+	0,
+	0,
+	0,
+	28196,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19876,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23558,
+	0,
+	11142,
+	0,
+	27781,
+	0,
+	0,
+	0,
+	0,
+	13864,
+	0,
+	0,
+	0,
+	24682,
+	47847,
+	0,
+	0,
+	0,
+	0,
+	6890,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3981,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1772,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3603,
+	1991,
+	0,
+	27396,
+	8652,
+	0,
+	18312,
+	0,
+	0,
+	0,
+	0,
+	30054,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11270,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20708,
+	0,
+	0,
+	0,
+	0,
+	338,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7050,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14862,
+	0,
+	0,
+	3492,
+	0,
+	0,
+	0,
+	55878,
+	0,
+	0,
+	0,
+	16486,
+	0,
+	0,
+	0,
+	18119,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2154,
+	1284,
+	0,
+	0,
+	23113,
+	31751,
+	0,
+	0,
+	29547,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18183,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7913,
+	0,
+	0,
+	0,
+	0,
+	20644,
+	0,
+	0,
+	10508,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43622,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40966,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	246,
+	901,
+	11529,
+	5191,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24454,
+	0,
+	0,
+	26665,
+	27590,
+	0,
+	27397,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23562,
+	2949,
+	0,
+	0,
+	30344,
+	62214,
+	0,
+	47334,
+	2026,
+	18885,
+	0,
+	0,
+	0,
+	48678,
+	0,
+	0,
+	0,
+	22694,
+	0,
+	0,
+	1972,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15465,
+	0,
+	0,
+	0,
+	0,
+	38822,
+	0,
+	0,
+	0,
+	0,
+	945,
+	32708,
+	0,
+	54791,
+	0,
+	14918,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23396,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5486,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7722,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	30856,
+	64166,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35206,
+	0,
+	0,
+	0,
+	30535,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62663,
+	0,
+	0,
+	1096,
+	17574,
+	31820,
+	0,
+	0,
+	14375,
+	4402,
+	27207,
+	0,
+	0,
+	21448,
+	4676,
+	0,
+	0,
+	0,
+	0,
+	16585,
+	5094,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4845,
+	0,
+	0,
+	32870,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	31466,
+	0,
+	0,
+	0,
+	0,
+	31783,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4522,
+	16039,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14469,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7464,
+	4773,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18636,
+	0,
+	0,
+	0,
+	25640,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2244,
+	0,
+	0,
+	11818,
+	0,
+	1168,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	6540,
+	23079,
+	13770,
+	7719,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58150,
+	528,
+	34502,
+	32682,
+	0,
+	0,
+	12997,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58567,
+	0,
+	0,
+	0,
+	26375,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26437,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26121,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32005,
+	22952,
+	59047,
+	0,
+	13543,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16328,
+	0,
+	0,
+	33542,
+	0,
+	0,
+	0,
+	19782,
+	0,
+	0,
+	0,
+	16644,
+	0,
+	0,
+	0,
+	0,
+	31688,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10276,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36327,
+	0,
+	0,
+	29480,
+	0,
+	0,
+	0,
+	777,
+	12709,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27398,
+	0,
+	0,
+	0,
+	4455,
+	9037,
+	31397,
+	0,
+	9221,
+	0,
+	0,
+	0,
+	60487,
+	20840,
+	1796,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8364,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19752,
+	44902,
+	0,
+	38566,
+	0,
+	0,
+	18027,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11812,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19910,
+	0,
+	0,
+	0,
+	45030,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19978,
+	5127,
+	0,
+	11620,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19817,
+	0,
+	5579,
+	9350,
+	0,
+	0,
+	21002,
+	19718,
+	0,
+	0,
+	0,
+	21926,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20711,
+	0,
+	0,
+	0,
+	20197,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	40550,
+	0,
+	0,
+	0,
+	57510,
+	0,
+	0,
+	0,
+	53895,
+	0,
+	0,
+	15017,
+	0,
+	17000,
+	39367,
+	2347,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3273,
+	17862,
+	3498,
+	2085,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19048,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11978,
+	58631,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	523,
+	0,
+	12969,
+	198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28197,
+	0,
+	47846,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4549,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	687,
+	14917,
+	748,
+	8229,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2476,
+	12935,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22792,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27528,
+	59142,
+	0,
+	0,
+	20876,
+	20134,
+	0,
+	0,
+	0,
+	0,
+	440,
+	12068,
+	0,
+	58951,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48038,
+	0,
+	0,
+	0,
+	60999,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15716,
+	7498,
+	5476,
+	0,
+	0,
+	0,
+	0,
+	20202,
+	37959,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29801,
+	0,
+	5451,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50790,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24485,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13573,
+	0,
+	0,
+	22856,
+	0,
+	0,
+	0,
+	0,
+	21927,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9130,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13732,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2282,
+	583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3726,
+	26503,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9258,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21604,
+	0,
+	0,
+	0,
+	45574,
+	0,
+	0,
+	0,
+	0,
+	0,
+	20710,
+	0,
+	0,
+	0,
+	42694,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1163,
+	6694,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10948,
+	0,
+	0,
+	0,
+	29700,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58823,
+	3796,
+	27399,
+	20939,
+	10180,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19,
+	29287,
+	28649,
+	14534,
+	0,
+	0,
+	16428,
+	45607,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25322,
+	0,
+	4908,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25476,
+	29097,
+	14246,
+	11053,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18502,
+	0,
+	0,
+	0,
+	44390,
+	0,
+	0,
+	0,
+	17765,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24520,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17319,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28166,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48198,
+	0,
+	0,
+	31467,
+	0,
+	24585,
+	0,
+	0,
+	0,
+	0,
+	18692,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23596,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7236,
+	968,
+	13637,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3763,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14791,
+	0,
+	0,
+	0,
+	12324,
+	0,
+	12741,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11108,
+	0,
+	0,
+	0,
+	0,
+	4009,
+	40295,
+	20616,
+	4357,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15015,
+	0,
+	0,
+	0,
+	0,
+	0,
+	43751,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23013,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45542,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23974,
+	0,
+	0,
+	0,
+	0,
+	17480,
+	20647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8876,
+	0,
+	0,
+	40806,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14502,
+	17160,
+	17764,
+	0,
+	0,
+	31594,
+	35431,
+	0,
+	0,
+	2890,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27524,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8228,
+	0,
+	56583,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	34278,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2662,
+	0,
+	26724,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22281,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3049,
+	54983,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	837,
+	0,
+	17604,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28838,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26312,
+	0,
+	0,
+	3910,
+	0,
+	0,
+	0,
+	25830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8391,
+	0,
+	19845,
+	19240,
+	1092,
+	0,
+	0,
+	5449,
+	0,
+	0,
+	0,
+	0,
+	17188,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10629,
+	0,
+	0,
+	6671,
+	61094,
+	5832,
+	8358,
+	0,
+	0,
+	0,
+	55078,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29860,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51494,
+	0,
+	28647,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25989,
+	0,
+	0,
+	30153,
+	61318,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24903,
+	0,
+	0,
+	0,
+	4388,
+	0,
+	42054,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	53158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50918,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26251,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5929,
+	2853,
+	0,
+	37126,
+	7372,
+	197,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2027,
+	934,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55686,
+	0,
+	0,
+	5672,
+	5447,
+	0,
+	62758,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2923,
+	0,
+	556,
+	1415,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	8645,
+	0,
+	9477,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	48742,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24235,
+	228,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16970,
+	18823,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18567,
+	20072,
+	2823,
+	14313,
+	1830,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27048,
+	23526,
+	0,
+	0,
+	0,
+	0,
+	0,
+	997,
+	492,
+	0,
+	14730,
+	16677,
+	396,
+	13574,
+	0,
+	0,
+	0,
+	41671,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19045,
+	0,
+	0,
+	0,
+	421,
+	17545,
+	3110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	47111,
+	14475,
+	56551,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3697,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49382,
+	0,
+	35559,
+	0,
+	0,
+	0,
+	0,
+	40,
+	0,
+	11496,
+	15621,
+	0,
+	8550,
+	0,
+	0,
+	0,
+	63462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	36966,
+	0,
+	50406,
+	0,
+	46022,
+	1001,
+	0,
+	0,
+	12069,
+	3249,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15241,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	64743,
+	0,
+	0,
+	0,
+	0,
+	0,
+	58759,
+	0,
+	0,
+	0,
+	0,
+	1136,
+	26981,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17732,
+	0,
+	0,
+	0,
+	17157,
+	20011,
+	6629,
+	0,
+	43879,
+	0,
+	0,
+	0,
+	13572,
+	25128,
+	10759,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28676,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	875,
+	24007,
+	0,
+	0,
+	0,
+	0,
+	7628,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12268,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	19300,
+	23210,
+	356,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49670,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21764,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13931,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	45799,
+	0,
+	0,
+	436,
+	3589,
+	0,
+	0,
+	11402,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	62822,
+	0,
+	0,
+	0,
+	39814,
+	588,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27750,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1609,
+	22660,
+	2346,
+	18951,
+	0,
+	16068,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5162,
+	11110,
+	0,
+	0,
+	0,
+	0,
+	15048,
+	1060,
+	0,
+	7879,
+	18280,
+	326,
+	0,
+	14886,
+	19656,
+	0,
+	7594,
+	0,
+	0,
+	0,
+	0,
+	0,
+	781,
+	581,
+	0,
+	16198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1078,
+	9892,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4489,
+	0,
+	0,
+	0,
+	0,
+	33798,
+	0,
+	0,
+	0,
+	54534,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	33158,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	42086,
+	13834,
+	2757,
+	8456,
+	16773,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3434,
+	0,
+	0,
+	0,
+	0,
+	0,
+	3946,
+	29668,
+	0,
+	0,
+	30634,
+	36775,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24901,
+	0,
+	16069,
+	6280,
+	0,
+	0,
+	0,
+	0,
+	41990,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27365,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1450,
+	44807,
+	0,
+	0,
+	0,
+	32100,
+	0,
+	0,
+	0,
+	0,
+	0,
+	35110,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17448,
+	19591,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1739,
+	0,
+	0,
+	0,
+	0,
+	5511,
+	0,
+	0,
+	0,
+	32934,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18180,
+	0,
+	0,
+	0,
+	23428,
+	19754,
+	0,
+	0,
+	31174,
+	3021,
+	31655,
+	23464,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	57255,
+	0,
+	0,
+	21292,
+	64487,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	25802,
+	9189,
+	0,
+	0,
+	0,
+	0,
+	0,
+	49254,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	5837,
+	50023,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15495,
+	0,
+	0,
+	0,
+	0,
+	0,
+	51942,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28104,
+	58662,
+	0,
+	50214,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2988,
+	0,
+	22888,
+	31812,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2020,
+	0,
+	18916,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23973,
+	0,
+	0,
+	0,
+	0,
+	17516,
+	11717,
+	0,
+	0,
+	0,
+	55911,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2855,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46822,
+	0,
+	24710,
+	28586,
+	0,
+	0,
+	0,
+	1556,
+	0,
+	0,
+	30117,
+	0,
+	0,
+	22090,
+	57127,
+	3403,
+	14087,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1041,
+	0,
+	10633,
+	6916,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27269,
+	0,
+	0,
+	13322,
+	18055,
+	0,
+	29380,
+	0,
+	56454,
+	0,
+	0,
+	120,
+	0,
+	0,
+	8773,
+	0,
+	0,
+	0,
+	0,
+	16040,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27242,
+	23781,
+	0,
+	1572,
+	0,
+	28134,
+	0,
+	0,
+	1512,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27684,
+	0,
+	38470,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1513,
+	8709,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46566,
+	0,
+	0,
+	0,
+	0,
+	28521,
+	61159,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24356,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13028,
+	0,
+	5863,
+	0,
+	0,
+	15693,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1131,
+	23398,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26212,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18404,
+	0,
+	0,
+	0,
+	0,
+	1457,
+	26183,
+	0,
+	0,
+	2475,
+	7110,
+	0,
+	0,
+	0,
+	0,
+	27180,
+	60166,
+	0,
+	0,
+	0,
+	20262,
+	0,
+	41862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2762,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26148,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28229,
+	0,
+	0,
+	0,
+	29254,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27690,
+	0,
+	0,
+	13636,
+	12776,
+	1862,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	17225,
+	3271,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28039,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4457,
+	18117,
+	0,
+	2023,
+	402,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	104,
+	3654,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	18440,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	29861,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	22150,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24074,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12004,
+	0,
+	32358,
+	0,
+	0,
+	3081,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	4749,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	10792,
+	1799,
+	21322,
+	0,
+	7880,
+	12613,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	13993,
+	0,
+	0,
+	0,
+	16202,
+	0,
+	0,
+	0,
+	0,
+	32102,
+	0,
+	37223,
+	0,
+	10500,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	32008,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23816,
+	3236,
+	0,
+	0,
+	0,
+	0,
+	0,
+	23237,
+	0,
+	0,
+	5642,
+	0,
+	4684,
+	294,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	26852,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7148,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	7890,
+	61798,
+	939,
+	0,
+	0,
+	56679,
+	0,
+	0,
+	0,
+	0,
+	0,
+	27078,
+	202,
+	5029,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	28005,
+	0,
+	0,
+	15273,
+	24741,
+	5676,
+	20452,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	55910,
+	0,
+	0,
+	0,
+	0,
+	5069,
+	27942,
+	0,
+	21092,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12517,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	21384,
+	28260,
+	0,
+	2502,
+	20108,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	46726,
+	0,
+	30790,
+	0,
+	0,
+	0,
+	0,
+	0,
+	14725,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1099,
+	6372,
+	0,
+	0,
+	0,
+	12422,
+	15182,
+	0,
+	8683,
+	0,
+	10665,
+	19462,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	1590,
+	0,
+	31628,
+	0,
+	22632,
+	19750,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24198,
+	0,
+	0,
+	0,
+	0,
+	0,
+	50662,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	9131,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	11015,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	16490,
+	54695,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	12937,
+	0,
+	0,
+	0,
+	0,
+	16004,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	2181,
+	6923,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	15624,
+	11302,
+	0,
+	0,
+	5673,
+	7559,
+	0,
+	0,
+	14668,
+	15684,
+	0,
+	0,
+	0,
+	0,
+	0,
+	0,
+	24204,
+	48134,
+	0,
+	24230,
+	0,
+	55527,
+	0,
+	0,
+	3464,
+	19141,
+	0,
+	0,
+	0,
+	0,
+}
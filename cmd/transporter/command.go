@@ -1,10 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 
 	"github.com/compose/transporter/pkg/adaptor"
+	"github.com/compose/transporter/pkg/transporter"
 	"github.com/mitchellh/cli"
 )
 
@@ -25,6 +27,12 @@ var subCommandFactory = map[string]cli.CommandFactory{
 	"about": func() (cli.Command, error) {
 		return &aboutCommand{}, nil
 	},
+	"schema": func() (cli.Command, error) {
+		return &schemaCommand{}, nil
+	},
+	"check": func() (cli.Command, error) {
+		return &checkCommand{}, nil
+	},
 }
 
 // listCommand loads the config, and lists the configured nodes
@@ -46,7 +54,7 @@ func (c *listCommand) Run(args []string) int {
 	var configFilename string
 	cmdFlags := flag.NewFlagSet("list", flag.ContinueOnError)
 	cmdFlags.Usage = func() { c.Help() }
-	cmdFlags.StringVar(&configFilename, "config", "", "config file")
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
 	cmdFlags.Parse(args)
 
 	config, err := LoadConfig(configFilename)
@@ -88,7 +96,7 @@ func (c *runCommand) Run(args []string) int {
 	var configFilename string
 	cmdFlags := flag.NewFlagSet("run", flag.ContinueOnError)
 	cmdFlags.Usage = func() { c.Help() }
-	cmdFlags.StringVar(&configFilename, "config", "", "config file")
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
 	cmdFlags.Parse(args)
 
 	config, err := LoadConfig(configFilename)
@@ -138,7 +146,7 @@ func (c *testCommand) Run(args []string) int {
 	var configFilename string
 	cmdFlags := flag.NewFlagSet("test", flag.ContinueOnError)
 	cmdFlags.Usage = func() { c.Help() }
-	cmdFlags.StringVar(&configFilename, "config", "", "config file")
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
 	cmdFlags.Parse(args)
 
 	config, err := LoadConfig(configFilename)
@@ -184,7 +192,7 @@ func (c *evalCommand) Run(args []string) int {
 	var configFilename string
 	cmdFlags := flag.NewFlagSet("run", flag.ContinueOnError)
 	cmdFlags.Usage = func() { c.Help() }
-	cmdFlags.StringVar(&configFilename, "config", "", "config file")
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
 	cmdFlags.Parse(args)
 
 	config, err := LoadConfig(configFilename)
@@ -247,3 +255,142 @@ func (c *aboutCommand) Run(args []string) int {
 	fmt.Print(a.About())
 	return 0
 }
+
+// schemaCommand samples documents from a configured node and reports the fields they contain
+type schemaCommand struct{}
+
+func (c *schemaCommand) Help() string {
+	return `Usage: transporter schema infer [--config file] [--samples N] [--namespace namespace] <node>
+
+Sample documents from the named source node, and report the observed field paths,
+their types, and how often they're present or null as json.  Nothing is written to any sink`
+}
+
+func (c *schemaCommand) Synopsis() string {
+	return "Infer the schema of the documents in a source"
+}
+
+func (c *schemaCommand) Run(args []string) int {
+	if len(args) == 0 || args[0] != "infer" {
+		fmt.Println(c.Help())
+		return 1
+	}
+
+	var (
+		configFilename string
+		namespace      string
+		samples        int
+	)
+	cmdFlags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	cmdFlags.Usage = func() { c.Help() }
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
+	cmdFlags.StringVar(&namespace, "namespace", "", "the namespace to sample, overriding the node's configured namespace")
+	cmdFlags.IntVar(&samples, "samples", 1000, "the number of documents to sample")
+	cmdFlags.Parse(args[1:])
+
+	config, err := LoadConfig(configFilename)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if len(cmdFlags.Args()) == 0 {
+		fmt.Println("Error: A node name is required")
+		return 1
+	}
+	name := cmdFlags.Args()[0]
+
+	options, ok := config.Nodes[name]
+	if !ok {
+		fmt.Printf("Error: unable to find node '%s'\n", name)
+		return 1
+	}
+	extra := adaptor.Config{}
+	for k, v := range options {
+		extra[k] = v
+	}
+	if namespace != "" {
+		extra["namespace"] = namespace
+	}
+	kind, _ := extra["type"].(string)
+
+	schema, err := transporter.InferSchema(transporter.NewNode(name, kind, extra), samples)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	ba, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Println(string(ba))
+	return 0
+}
+
+// checkCommand connects to a configured node and checks that it works, without running a pipeline
+type checkCommand struct{}
+
+func (c *checkCommand) Help() string {
+	return `Usage: transporter check [--config file] [--namespace namespace] [--write] <node>
+
+Connect to the named node and perform a trivial operation, i.e. ping the cluster or list the
+collections, reporting each step and why it failed.  With --write, a node used as a sink also
+writes a canary document and deletes it again.  No pipeline is run`
+}
+
+func (c *checkCommand) Synopsis() string {
+	return "Check that a node can connect, without running a pipeline"
+}
+
+func (c *checkCommand) Run(args []string) int {
+	var (
+		configFilename string
+		namespace      string
+		write          bool
+	)
+	cmdFlags := flag.NewFlagSet("check", flag.ContinueOnError)
+	cmdFlags.Usage = func() { c.Help() }
+	cmdFlags.StringVar(&configFilename, "config", "", "config file, or directory")
+	cmdFlags.StringVar(&namespace, "namespace", "", "the namespace to check, overriding the node's configured namespace")
+	cmdFlags.BoolVar(&write, "write", false, "write and delete a canary document")
+	cmdFlags.Parse(args)
+
+	config, err := LoadConfig(configFilename)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if len(cmdFlags.Args()) == 0 {
+		fmt.Println("Error: A node name is required")
+		return 1
+	}
+	name := cmdFlags.Args()[0]
+
+	options, ok := config.Nodes[name]
+	if !ok {
+		fmt.Printf("Error: unable to find node '%s'\n", name)
+		return 1
+	}
+	extra := adaptor.Config{}
+	for k, v := range options {
+		extra[k] = v
+	}
+	if namespace != "" {
+		extra["namespace"] = namespace
+	}
+	kind, _ := extra["type"].(string)
+
+	status := 0
+	for _, step := range transporter.CheckNode(transporter.NewNode(name, kind, extra), write) {
+		if step.Err != nil {
+			fmt.Printf("FAIL %s\n     %s\n", step.Name, step.Err.Error())
+			status = 1
+			continue
+		}
+		fmt.Printf("ok   %s\n", step.Name)
+	}
+	return status
+}
@@ -6,7 +6,9 @@ import (
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -26,10 +28,20 @@ type Config struct {
 		SessionInterval string `json:"interval" yaml:"interval"` // how often to persist the sesion states
 		Type            string `json:"type" yaml:"type"`         // the type of SessionStore to use
 	} `json:"sessions" yaml:"sessions"`
-	Nodes map[string]map[string]interface{}
+	Ready struct {
+		Timeout string `json:"timeout" yaml:"timeout"` // how long to wait for the sinks to be reachable before starting the source, unset or 0 skips the check
+	} `json:"ready" yaml:"ready"`
+	Checkpoints struct {
+		URI      string `json:"uri" yaml:"uri"`           // where to keep the sources' positions, a file://, redis:// or mongodb:// uri
+		Interval string `json:"interval" yaml:"interval"` // how often to save the positions, defaults to 10s
+		Key      string `json:"key" yaml:"key"`           // what the positions are kept under along with each source's name, so transporters sharing a store keep their own
+	} `json:"checkpoints" yaml:"checkpoints"`
+	Nodes   map[string]map[string]interface{}
+	Include []string `json:"include" yaml:"include"` // in a config directory's config.yaml, the node files to read
 }
 
-// LoadConfig loads a config yaml from a file on disk.
+// LoadConfig loads a config yaml from a file on disk, from a directory of them (see loadConfigDir), or
+// from an http(s):// or s3:// url (see loadRemoteConfig).
 // if the pid is not set in the yaml, pull it from the environment TRANSPORTER_PID.
 // if that env var isn't present, then generate a pid
 func LoadConfig(filename string) (config Config, err error) {
@@ -40,27 +52,115 @@ func LoadConfig(filename string) (config Config, err error) {
 		filename = "config.yaml"
 	}
 
+	if isRemoteConfig(filename) {
+		config, err = loadRemoteConfig(filename)
+	} else if info, statErr := os.Stat(filename); statErr == nil && info.IsDir() {
+		config, err = loadConfigDir(filename)
+	} else {
+		config, err = loadConfigFile(filename)
+	}
+	if err != nil {
+		return
+	}
+
+	if len(config.API.Pid) < 1 {
+		config.API.Pid = os.Getenv("TRANSPORTER_PID")
+	}
+
+	if len(config.API.Pid) < 1 {
+		hostname, _ := os.Hostname()
+		config.API.Pid = fmt.Sprintf("%s@%d", hostname, time.Now().Unix())
+	}
+
+	return
+}
+
+// loadConfigFile reads a single config yaml
+func loadConfigFile(filename string) (config Config, err error) {
 	ba, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return
 	}
+	return parseConfig(ba, filename)
+}
 
+// parseConfig parses a config yaml read from filename
+func parseConfig(ba []byte, filename string) (config Config, err error) {
 	// configs can have environment variables, replace these before continuing
 	ba = setConfigEnvironment(ba)
 
-	err = yaml.Unmarshal(ba, &config)
+	if err = yaml.Unmarshal(ba, &config); err != nil {
+		return config, fmt.Errorf("can't parse %s (%s)", filename, err.Error())
+	}
+	return
+}
 
-	for k, v := range config.Nodes {
-		config.Nodes[k] = v
+// loadConfigDir reads a config split across a directory, so that large pipelines can keep each node in
+// a file of its own.  The directory's config.yaml, if there is one, holds everything but the nodes, and
+// any nodes that aren't in files of their own.  Each of the other yaml files in the directory holds the
+// options of a single node, which is named after the file, i.e. localmongo.yaml is the node localmongo.
+// If config.yaml lists the node files to include, relative to the directory, only those are read, and each
+// must exist, i.e. include: ["nodes/*.yaml"].  A node can only be defined once
+func loadConfigDir(dir string) (config Config, err error) {
+	index := filepath.Join(dir, "config.yaml")
+	if _, statErr := os.Stat(index); statErr == nil {
+		if config, err = loadConfigFile(index); err != nil {
+			return
+		}
+	}
+	if config.Nodes == nil {
+		config.Nodes = make(map[string]map[string]interface{})
 	}
 
-	if len(config.API.Pid) < 1 {
-		config.API.Pid = os.Getenv("TRANSPORTER_PID")
+	var files []string
+	if len(config.Include) > 0 {
+		for _, include := range config.Include {
+			matches, err := filepath.Glob(filepath.Join(dir, include))
+			if err != nil {
+				return config, fmt.Errorf("bad include (%s)", err.Error())
+			}
+			if len(matches) == 0 {
+				return config, fmt.Errorf("the included node file %s doesn't exist", include)
+			}
+			files = append(files, matches...)
+		}
+	} else {
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, m := range matches {
+				if m != index {
+					files = append(files, m)
+				}
+			}
+		}
 	}
 
-	if len(config.API.Pid) < 1 {
-		hostname, _ := os.Hostname()
-		config.API.Pid = fmt.Sprintf("%s@%d", hostname, time.Now().Unix())
+	defined := make(map[string]string) // where each node came from, for reporting duplicates
+	for name := range config.Nodes {
+		defined[name] = index
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if other, ok := defined[name]; ok {
+			if other == file { // included twice
+				continue
+			}
+			return config, fmt.Errorf("the node %s is defined in both %s and %s", name, other, file)
+		}
+
+		ba, err := ioutil.ReadFile(file)
+		if err != nil {
+			return config, err
+		}
+		var node map[string]interface{}
+		if err := yaml.Unmarshal(setConfigEnvironment(ba), &node); err != nil {
+			return config, fmt.Errorf("can't parse %s (%s)", file, err.Error())
+		}
+		if node == nil {
+			return config, fmt.Errorf("the node file %s is empty", file)
+		}
+		config.Nodes[name] = node
+		defined[name] = file
 	}
 
 	return
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const singleFileConfig = `
+api:
+  interval: 60s
+  pid: pipeline@host
+nodes:
+  localmongo:
+    type: mongo
+    uri: mongodb://localhost/boom
+    namespace: boom.foo
+  es:
+    type: elasticsearch
+    uri: http://localhost:9200/index
+    mapping:
+      users: people
+  stdout:
+    type: file
+    uri: stdout://
+`
+
+// writeConfigFiles creates the files, keyed by their path relative to the returned directory
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfigDir(t *testing.T) {
+	single := writeConfigFiles(t, map[string]string{"config.yaml": singleFileConfig})
+	defer os.RemoveAll(single)
+	expected, err := LoadConfig(filepath.Join(single, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	nodes := map[string]string{
+		"localmongo": "type: mongo\nuri: mongodb://localhost/boom\nnamespace: boom.foo\n",
+		"es":         "type: elasticsearch\nuri: http://localhost:9200/index\nmapping:\n  users: people\n",
+	}
+	data := []map[string]string{
+		// by convention, each yaml file beside config.yaml is a node
+		{
+			"config.yaml":     "api:\n  interval: 60s\n  pid: pipeline@host\nnodes:\n  stdout:\n    type: file\n    uri: stdout://\n",
+			"localmongo.yaml": nodes["localmongo"],
+			"es.yml":          nodes["es"],
+			"README.md":       "not a node",
+		},
+		// or config.yaml lists the node files
+		{
+			"config.yaml":           "api:\n  interval: 60s\n  pid: pipeline@host\ninclude:\n  - nodes/*.yaml\n  - stdout.yaml\n",
+			"nodes/localmongo.yaml": nodes["localmongo"],
+			"nodes/es.yaml":         nodes["es"],
+			"stdout.yaml":           "type: file\nuri: stdout://\n",
+			"unused.yaml":           "type: file\nuri: file:///tmp/unused\n",
+		},
+	}
+	for _, files := range data {
+		dir := writeConfigFiles(t, files)
+		defer os.RemoveAll(dir)
+
+		config, err := LoadConfig(dir)
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+			continue
+		}
+		config.Include = nil
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("expected %+v, got %+v", expected, config)
+		}
+	}
+}
+
+func TestLoadConfigDirErrors(t *testing.T) {
+	data := []struct {
+		files map[string]string
+		err   string
+	}{
+		{
+			map[string]string{"config.yaml": "nodes:\n  es:\n    type: elasticsearch\n", "es.yaml": "type: elasticsearch\n"},
+			"the node es is defined in both",
+		},
+		{
+			map[string]string{"config.yaml": "include: [\"a/*.yaml\", \"b/*.yaml\"]\n", "a/es.yaml": "type: elasticsearch\n", "b/es.yaml": "type: elasticsearch\n"},
+			"the node es is defined in both",
+		},
+		{
+			map[string]string{"config.yaml": "include: [\"missing.yaml\"]\n"},
+			"the included node file missing.yaml doesn't exist",
+		},
+		{
+			map[string]string{"es.yaml": "type: [elasticsearch\n"},
+			"can't parse",
+		},
+		{
+			map[string]string{"es.yaml": ""},
+			"is empty",
+		},
+	}
+
+	for _, v := range data {
+		dir := writeConfigFiles(t, v.files)
+		defer os.RemoveAll(dir)
+
+		if _, err := LoadConfig(dir); err == nil || !strings.Contains(err.Error(), v.err) {
+			t.Errorf("%v: expected an error containing %q, got %v", v.files, v.err, err)
+		}
+	}
+}
+
+func TestLoadRemoteConfig(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"config.yaml": singleFileConfig})
+	defer os.RemoveAll(dir)
+	expected, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch {
+		case r.URL.Path == "/bucket/pipelines/config.yaml" && strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"):
+			w.Write([]byte(singleFileConfig))
+		case r.URL.Path == "/config.yaml" && auth == "Bearer secret":
+			w.Write([]byte(singleFileConfig))
+		case r.URL.Path == "/bad.yaml":
+			w.Write([]byte("nodes: [unclosed"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	for k, v := range map[string]string{
+		"TRANSPORTER_CONFIG_AUTH":        "Bearer secret",
+		"TRANSPORTER_CONFIG_CACHE":       filepath.Join(dir, "cached.yaml"),
+		"TRANSPORTER_CONFIG_S3_ENDPOINT": server.URL,
+		"AWS_ACCESS_KEY_ID":              "AKID",
+		"AWS_SECRET_ACCESS_KEY":          "SECRET",
+		"AWS_REGION":                     "eu-west-1",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	for _, location := range []string{server.URL + "/config.yaml", "s3://bucket/pipelines/config.yaml"} {
+		config, err := LoadConfig(location)
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", location, err)
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("%s: expected %+v, got %+v", location, expected, config)
+		}
+	}
+	if !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("expected the s3 request to be signed for the region, got %s", auth)
+	}
+
+	// a config that can't be parsed is an error, and doesn't replace the cached copy
+	if _, err := LoadConfig(server.URL + "/bad.yaml"); err == nil || !strings.Contains(err.Error(), "can't parse") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+
+	// once the server is gone the cached copy is used, without it the fetch fails
+	server.Close()
+	config, err := LoadConfig(server.URL + "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected the cached %+v, got %+v", expected, config)
+	}
+	os.Unsetenv("TRANSPORTER_CONFIG_CACHE")
+	if _, err := LoadConfig(server.URL + "/config.yaml?signature=abc"); err == nil || !strings.Contains(err.Error(), "can't fetch the config") || strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected a fetch error without the query, got %v", err)
+	}
+}
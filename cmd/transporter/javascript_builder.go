@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/compose/transporter/pkg/adaptor"
 	"github.com/compose/transporter/pkg/events"
 	"github.com/compose/transporter/pkg/state"
 	"github.com/compose/transporter/pkg/transporter"
@@ -96,6 +97,8 @@ func (js *JavascriptBuilder) save(token string, node Node, call otto.FunctionCal
 
 	if node.UUID == root.UUID { // save is being called on a root node
 		root.Add(&thisNode)
+	} else if saved := root.find(node.UUID); saved != nil {
+		saved.Add(&thisNode) // this node has already been saved to, i.e. a router with a sink per route
 	} else {
 		node.Add(&thisNode) // add the generated not to the `this`
 		root.Add(&node)     // add the result to the root
@@ -113,13 +116,16 @@ func (js *JavascriptBuilder) transform(token string, node Node, call otto.Functi
 		return node, fmt.Errorf("save error, %s", err.Error())
 	}
 
-	filename := transformer.Extra.GetString("filename")
-	if filename == "" {
-		return node, fmt.Errorf("transformer config must contain a valid filename key")
-	}
+	// declarative transformers are configured inline, only javascript transformers need a file
+	if transformer.Type == "transformer" {
+		filename := transformer.Extra.GetString("filename")
+		if filename == "" {
+			return node, fmt.Errorf("transformer config must contain a valid filename key")
+		}
 
-	if !filepath.IsAbs(filename) {
-		transformer.Extra["filename"] = filepath.Join(js.path, filename)
+		if !filepath.IsAbs(filename) {
+			transformer.Extra["filename"] = filepath.Join(js.path, filename)
+		}
 	}
 
 	node.Add(&transformer)
@@ -209,7 +215,10 @@ func (js *JavascriptBuilder) findNode(token string, in otto.Value) (n Node, err
 
 	if token == "transform" {
 		// this is a little bit of magic so that transformers (which are added by the transform fn get the right kind)
-		givenOptions["type"] = "transformer"
+		// declarative transformers, i.e. {type: "replace"}, keep their own kind
+		if kind, _ := givenOptions["type"].(string); !adaptor.IsTransformer(kind) {
+			givenOptions["type"] = "transformer"
+		}
 	}
 
 	kind, ok := givenOptions["type"].(string)
@@ -271,6 +280,35 @@ func (js *JavascriptBuilder) Build() error {
 		}
 	}
 
+	// the readiness check is only run when it's configured, so a pipeline doesn't start failing on its sinks by default
+	var readyTimeout time.Duration
+	if js.config.Ready.Timeout != "" {
+		readyTimeout, err = time.ParseDuration(js.config.Ready.Timeout)
+		if err != nil {
+			return fmt.Errorf("can't parse ready timeout (%s)", err.Error())
+		}
+	}
+
+	var checkpoints state.CheckpointStore
+	checkpointInterval := 10 * time.Second
+	if js.config.Checkpoints.URI != "" {
+		// the positions are kept under the source node's name, not the pid, which changes each run unless it's set
+		prefix := ""
+		if js.config.Checkpoints.Key != "" {
+			prefix = js.config.Checkpoints.Key + "-"
+		}
+		checkpoints, err = state.NewCheckpointStore(js.config.Checkpoints.URI, prefix)
+		if err != nil {
+			return err
+		}
+	}
+	if js.config.Checkpoints.Interval != "" {
+		checkpointInterval, err = time.ParseDuration(js.config.Checkpoints.Interval)
+		if err != nil {
+			return fmt.Errorf("can't parse checkpoint interval (%s)", err.Error())
+		}
+	}
+
 	// build each pipeline
 	for _, node := range js.nodes {
 		n := node.CreateTransporterNode()
@@ -278,6 +316,9 @@ func (js *JavascriptBuilder) Build() error {
 		if err != nil {
 			return err
 		}
+		pipeline.ReadyTimeout = readyTimeout
+		pipeline.Checkpoints = checkpoints
+		pipeline.CheckpointInterval = checkpointInterval
 		js.pipelines = append(js.pipelines, pipeline) // remember this pipeline
 	}
 
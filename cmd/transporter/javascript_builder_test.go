@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const checkpointConfig = `
+checkpoints:
+  uri: file://%s
+nodes:
+  in:
+    type: file
+    uri: file://%s
+  out:
+    type: file
+    uri: file://%s
+`
+
+// runFilePipeline loads the config, as a fresh transporter would, and runs the file to file pipeline
+func runFilePipeline(t *testing.T, configFile string) {
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("can't load the config, %s", err)
+	}
+	builder, err := NewJavascriptBuilder(config, "", `Source({name: "in"}).save({name: "out"})`)
+	if err != nil {
+		t.Fatalf("can't create the builder, %s", err)
+	}
+	if err := builder.Build(); err != nil {
+		t.Fatalf("can't build the pipeline, %s", err)
+	}
+	if err := builder.Run(); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+}
+
+// readIDs returns the _id of each document in the file
+func readIDs(t *testing.T, file string) []interface{} {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var ids []interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatalf("can't decode %s, %s", scanner.Text(), err)
+		}
+		ids = append(ids, doc["_id"])
+	}
+	return ids
+}
+
+func TestCheckpointsResumeAcrossRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.json"), filepath.Join(dir, "out.json")
+	configFile := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf(checkpointConfig, filepath.Join(dir, "checkpoints.json"), in, out)
+	if err := ioutil.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(in, []byte("{\"_id\": \"1\"}\n{\"_id\": \"2\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runFilePipeline(t, configFile)
+	if ids := readIDs(t, out); !reflect.DeepEqual(ids, []interface{}{"1", "2"}) {
+		t.Fatalf("expected the first run to write 1 and 2, got %v", ids)
+	}
+
+	// documents appended while it was stopped, the next run, with a new pid, carries on after the first two
+	f, err := os.OpenFile(in, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{\"_id\": \"3\"}\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	os.Setenv("TRANSPORTER_PID", "restarted@host")
+	defer os.Unsetenv("TRANSPORTER_PID")
+	runFilePipeline(t, configFile)
+	if ids := readIDs(t, out); !reflect.DeepEqual(ids, []interface{}{"3"}) {
+		t.Errorf("expected the restarted run to resume after 2 and write 3, got %v", ids)
+	}
+}
+
+func TestReadyTimeoutIsOptIn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.json"), filepath.Join(dir, "out.json")
+	configFile := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf("nodes:\n  in:\n    type: file\n    uri: file://%s\n  out:\n    type: file\n    uri: file://%s\n", in, out)
+	if err := ioutil.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for timeout, expected := range map[string]time.Duration{"": 0, "0": 0, "5s": 5 * time.Second} {
+		config, err := LoadConfig(configFile)
+		if err != nil {
+			t.Fatalf("can't load the config, %s", err)
+		}
+		config.Ready.Timeout = timeout
+		builder, err := NewJavascriptBuilder(config, "", `Source({name: "in"}).save({name: "out"})`)
+		if err != nil {
+			t.Fatalf("can't create the builder, %s", err)
+		}
+		if err := builder.Build(); err != nil {
+			t.Fatalf("[%q] can't build the pipeline, %s", timeout, err)
+		}
+		if got := builder.pipelines[0].ReadyTimeout; got != expected {
+			t.Errorf("[%q] expected a ready timeout of %s, got %s", timeout, expected, got)
+		}
+	}
+}
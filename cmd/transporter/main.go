@@ -17,11 +17,13 @@ func main() {
 
 	c.Args = os.Args[1:]
 	c.Commands = map[string]cli.CommandFactory{
-		"list":  subCommandFactory["list"],
-		"run":   subCommandFactory["run"],
-		"eval":  subCommandFactory["eval"],
-		"test":  subCommandFactory["test"],
-		"about": subCommandFactory["about"],
+		"list":   subCommandFactory["list"],
+		"run":    subCommandFactory["run"],
+		"eval":   subCommandFactory["eval"],
+		"test":   subCommandFactory["test"],
+		"about":  subCommandFactory["about"],
+		"schema": subCommandFactory["schema"],
+		"check":  subCommandFactory["check"],
 	}
 
 	exitStatus, err := c.Run()
@@ -63,6 +63,19 @@ func (n *Node) Add(node *Node) {
 	n.Children = append(n.Children, node)
 }
 
+// find returns the node with the given uuid from this node's descendants, or nil if there isn't one
+func (n *Node) find(uuid string) *Node {
+	for _, child := range n.Children {
+		if child.UUID == uuid {
+			return child
+		}
+		if found := child.find(uuid); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
 // CreateTransporterNode will turn this node into a transporter.Node.
 // will recurse down the tree and transform each child
 func (n *Node) CreateTransporterNode() *transporter.Node {
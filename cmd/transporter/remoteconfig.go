@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/compose/transporter/pkg/adaptor"
+)
+
+// configClient fetches remote configs, it's swapped out in tests
+var configClient = &http.Client{Timeout: 30 * time.Second}
+
+// isRemoteConfig returns true if the config is named by a url, rather than a path on disk
+func isRemoteConfig(filename string) bool {
+	for _, scheme := range []string{"http://", "https://", "s3://"} {
+		if strings.HasPrefix(filename, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRemoteConfig fetches a config yaml from an http(s) url or an s3 object, and parses it as it would
+// a file on disk.  A remote config is a single yaml, it can't include node files.
+//
+// Http requests send TRANSPORTER_CONFIG_AUTH as their Authorization header, if it's set.  S3 requests, to
+// s3://bucket/key, are signed with the credentials found in the environment or the shared credentials file,
+// for the region in AWS_REGION or AWS_DEFAULT_REGION.  TRANSPORTER_CONFIG_S3_ENDPOINT names an s3
+// compatible endpoint to use instead of AWS, its buckets are addressed by path.
+//
+// If TRANSPORTER_CONFIG_CACHE names a file, each config that's fetched and parsed is saved to it, and
+// when a fetch fails the saved config is used instead, so that a restart doesn't depend on the config
+// server being up
+func loadRemoteConfig(location string) (config Config, err error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return config, fmt.Errorf("bad config url (%s)", err.Error())
+	}
+	// presigned urls hold their credentials in the query, so it's left out of errors
+	name := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+
+	cache := os.Getenv("TRANSPORTER_CONFIG_CACHE")
+	ba, err := fetchConfig(u)
+	if err != nil {
+		if cache == "" {
+			return config, fmt.Errorf("can't fetch the config from %s (%s)", name, err.Error())
+		}
+		cached, cacheErr := ioutil.ReadFile(cache)
+		if cacheErr != nil {
+			return config, fmt.Errorf("can't fetch the config from %s (%s), and there's no cached copy (%s)", name, err.Error(), cacheErr.Error())
+		}
+		log.Printf("can't fetch the config from %s (%s), using the cached copy in %s", name, err.Error(), cache)
+		return parseConfig(cached, cache)
+	}
+
+	if config, err = parseConfig(ba, name); err != nil {
+		return
+	}
+	if cache != "" {
+		if cacheErr := writeConfigCache(cache, ba); cacheErr != nil {
+			log.Printf("can't cache the config in %s (%s)", cache, cacheErr.Error())
+		}
+	}
+	return
+}
+
+// fetchConfig returns the body of the config at the url
+func fetchConfig(u *url.URL) ([]byte, error) {
+	client, s3 := configClient, u.Scheme == "s3"
+	if s3 {
+		var err error
+		if u, client, err = s3ConfigRequest(u); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// s3 requests are signed instead
+	if auth := os.Getenv("TRANSPORTER_CONFIG_AUTH"); auth != "" && !s3 {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := client.Do(req)
+	if e, ok := err.(*url.Error); ok {
+		return nil, e.Err // the caller names the url, without its query
+	} else if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	ba, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return ba, nil
+}
+
+// s3ConfigRequest returns the https url of the s3 object, and a client that signs requests to it
+func s3ConfigRequest(u *url.URL) (*url.URL, *http.Client, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, nil, fmt.Errorf("an s3 config url must name a bucket and key, i.e. s3://bucket/config.yaml")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	object := &url.URL{Scheme: "https", Host: fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, region), Path: "/" + key}
+	if endpoint := os.Getenv("TRANSPORTER_CONFIG_S3_ENDPOINT"); endpoint != "" {
+		e, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad TRANSPORTER_CONFIG_S3_ENDPOINT (%s)", err.Error())
+		}
+		object = &url.URL{Scheme: e.Scheme, Host: e.Host, Path: strings.TrimSuffix(e.Path, "/") + "/" + bucket + "/" + key}
+	}
+
+	signer, err := adaptor.NewSigV4Transport(configClient.Transport, region, "s3")
+	if err != nil {
+		return nil, nil, err
+	}
+	return object, &http.Client{Timeout: configClient.Timeout, Transport: signer}, nil
+}
+
+// writeConfigCache saves the config, replacing the file all at once so that a failed write leaves the
+// last copy as it was
+func writeConfigCache(file string, ba []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(ba); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
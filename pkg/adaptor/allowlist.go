@@ -0,0 +1,167 @@
+package adaptor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewAllowList creates a transformer that checks fields against a set of allowed values, i.e. country_code
+// against the ISO codes, to catch dirty data before it's written.  Each field's values are read from a file,
+// one per line, and may be given in the config too.  Values are compared as strings, so the allowed value 840
+// matches the number or the string, and every element of an array must be allowed.  A field whose value isn't
+// allowed is handled by its policy, which drops the message, errors, or flags it by adding the field's path to
+// the flag field and sending it on
+func NewAllowList(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf AllowListConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "allowlist config must contain at least one field", nil)
+	}
+
+	al := &allowList{flagField: conf.FlagField}
+	if al.flagField == "" {
+		al.flagField = "_invalid"
+	}
+	fields := make([]string, 0, len(conf.Fields))
+	for field := range conf.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // so the fields are always checked, and flagged, in the same order
+	for _, field := range fields {
+		fc := conf.Fields[field]
+		f := allowListField{path: field, allowed: make(map[string]bool), policy: fc.Policy, missing: fc.Missing}
+		switch f.policy {
+		case "":
+			f.policy = "error"
+		case "drop", "error", "flag":
+		default:
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown policy for %s (%s), must be drop, error or flag", field, f.policy), nil)
+		}
+		switch f.missing {
+		case "":
+			f.missing = "pass"
+		case "pass", "drop", "error", "flag":
+		default:
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy for %s (%s), must be pass, drop, error or flag", field, f.missing), nil)
+		}
+
+		for _, v := range fc.Values {
+			f.allowed[v] = true
+		}
+		if fc.File != "" {
+			if err := readAllowList(fc.File, f.allowed); err != nil {
+				return nil, NewError(CRITICAL, path, fmt.Sprintf("can't read the allow list for %s (%s)", field, err.Error()), nil)
+			}
+		}
+		if len(f.allowed) == 0 {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("allowlist field %s must have at least one allowed value", field), nil)
+		}
+		al.fields = append(al.fields, f)
+	}
+
+	return newDocTransformer("allowlist", p, path, extra, al.apply)
+}
+
+// AllowListConfig provides configuration options for the allowlist transformer
+type AllowListConfig struct {
+	Namespace string                    `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string]AllowListField `json:"fields" doc:"the allowed values of each field, keyed by dotted field path"`
+	FlagField string                    `json:"flagfield" doc:"the field that lists the paths of the fields whose values aren't allowed, for the flag policy, defaults to _invalid"`
+}
+
+// AllowListField configures the allowed values of a single field
+type AllowListField struct {
+	File    string   `json:"file" doc:"the path of a file of allowed values, one per line, blank lines and lines starting with # are ignored"`
+	Values  []string `json:"values" doc:"allowed values, along with those in the file"`
+	Policy  string   `json:"policy" doc:"what to do when the value isn't allowed, error (the default), drop or flag"`
+	Missing string   `json:"missing" doc:"what to do when the field is missing or null, pass (the default), drop, error or flag"`
+}
+
+type allowListField struct {
+	path    string
+	allowed map[string]bool
+	policy  string
+	missing string
+}
+
+type allowList struct {
+	fields    []allowListField
+	flagField string
+}
+
+// readAllowList adds the values in the file to allowed
+func readAllowList(file string, allowed map[string]bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[line] = true
+	}
+	return scanner.Err()
+}
+
+// apply checks each configured field in turn, the first that drops or errors decides the message's fate
+func (al *allowList) apply(msg *message.Msg, doc map[string]interface{}) error {
+	var flagged []interface{}
+	for _, f := range al.fields {
+		policy, reason := "", ""
+		v, ok := getField(doc, f.path)
+		if !ok || v == nil {
+			policy, reason = f.missing, "is missing"
+		} else if value, allowed := f.check(v); !allowed {
+			policy, reason = f.policy, fmt.Sprintf("has %s, which isn't allowed", value)
+		}
+
+		switch policy {
+		case "drop":
+			msg.Op = message.Noop
+			return nil
+		case "error":
+			return fmt.Errorf("%s %s", f.path, reason)
+		case "flag":
+			flagged = append(flagged, f.path)
+		}
+	}
+
+	if len(flagged) > 0 {
+		if existing, ok := asSlice(doc[al.flagField]); ok {
+			flagged = append(existing, flagged...)
+		}
+		return setField(doc, al.flagField, flagged)
+	}
+	return nil
+}
+
+// check returns true if the value, or every element of it if it's an array, is allowed.  If it isn't, the
+// first value that isn't allowed is returned too
+func (f allowListField) check(v interface{}) (string, bool) {
+	if _, isBytes := v.([]byte); !isBytes {
+		if a, ok := asSlice(v); ok {
+			for _, e := range a {
+				if value, allowed := f.check(e); !allowed {
+					return value, false
+				}
+			}
+			return "", true
+		}
+	}
+	value := lookupKey(v)
+	return value, f.allowed[value]
+}
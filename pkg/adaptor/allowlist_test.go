@@ -0,0 +1,135 @@
+package adaptor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestAllowList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allowlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	countries := filepath.Join(dir, "countries.txt")
+	ioutil.WriteFile(countries, []byte("# ISO 3166 codes\nCA\n\n  US  \n840\n"), 0644)
+
+	country := func(policy, missing string) map[string]interface{} {
+		return map[string]interface{}{"file": countries, "policy": policy, "missing": missing}
+	}
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+		err  bool
+	}{
+		{
+			// allowed values, from the file and the config, as strings or numbers or in arrays
+			Config{"fields": map[string]interface{}{"country": country("", ""), "tags": map[string]interface{}{"values": []interface{}{"a", "b"}}}},
+			map[string]interface{}{"_id": "1", "country": "US", "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"_id": "1", "country": "US", "tags": []interface{}{"a", "b"}},
+			false,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("", "")}},
+			map[string]interface{}{"_id": "1", "country": 840.0},
+			map[string]interface{}{"_id": "1", "country": 840.0},
+			false,
+		},
+		{
+			// disallowed values are errors by default, or are dropped, or flagged
+			Config{"fields": map[string]interface{}{"country": country("", "")}},
+			map[string]interface{}{"_id": "1", "country": "XX"},
+			nil,
+			true,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("drop", "")}},
+			map[string]interface{}{"_id": "1", "country": "XX"},
+			nil,
+			false,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("flag", ""), "tags": map[string]interface{}{"values": []interface{}{"a"}, "policy": "flag"}}},
+			map[string]interface{}{"_id": "1", "country": "XX", "tags": []interface{}{"a", "z"}},
+			map[string]interface{}{"_id": "1", "country": "XX", "tags": []interface{}{"a", "z"}, "_invalid": []interface{}{"country", "tags"}},
+			false,
+		},
+		{
+			Config{"flagfield": "meta.bad", "fields": map[string]interface{}{"country": country("flag", "")}},
+			map[string]interface{}{"_id": "1", "country": "ca"},
+			map[string]interface{}{"_id": "1", "country": "ca", "meta": map[string]interface{}{"bad": []interface{}{"country"}}},
+			false,
+		},
+		{
+			// missing fields pass by default
+			Config{"fields": map[string]interface{}{"country": country("", "")}},
+			map[string]interface{}{"_id": "1", "country": nil},
+			map[string]interface{}{"_id": "1", "country": nil},
+			false,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("", "drop")}},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			false,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("", "error")}},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			true,
+		},
+		{
+			Config{"fields": map[string]interface{}{"country": country("", "flag")}},
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1", "_invalid": []interface{}{"country"}},
+			false,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "allowlist", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if v.err {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestAllowListConfigErrors(t *testing.T) {
+	data := []Config{
+		{"namespace": "database.collection"},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"country": map[string]interface{}{}}},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"country": map[string]interface{}{"file": "/does/not/exist"}}},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"country": map[string]interface{}{"values": []interface{}{"US"}, "policy": "skip"}}},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"country": map[string]interface{}{"values": []interface{}{"US"}, "missing": "skip"}}},
+	}
+
+	for _, v := range data {
+		if _, err := NewAllowList(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
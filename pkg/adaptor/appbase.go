@@ -1,19 +1,47 @@
+//go:build !slim || appbase || opensearch
+// +build !slim appbase opensearch
+
 package adaptor
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net"
+	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/compose/transporter/pkg/events"
 	"github.com/compose/transporter/pkg/message"
 	"github.com/compose/transporter/pkg/pipe"
 	"github.com/olivere/elastic"
+	"gopkg.in/mgo.v2/bson"
 )
 
+func init() {
+	Register("appbase", "an appbase sink adaptor", NewAppbase, AppbaseConfig{})
+}
+
 const (
 	APPBASE_BUFFER_LEN int = 2000
+
+	// dataStreamTimestampField is the field elasticsearch requires on every data stream document
+	dataStreamTimestampField = "@timestamp"
+
+	// how long to wait before retrying a rate limited bulk when appbase doesn't send a Retry-After,
+	// doubled for each consecutive rate limited attempt up to appbaseMaxRateLimitBackoff
+	appbaseRateLimitBackoff    = time.Second
+	appbaseMaxRateLimitBackoff = time.Minute
+
+	// the most bulk requests, for different indexes, waiting on the cluster at once, unless maxinflightbatches says otherwise
+	appbaseMaxInflightBatches = 4
 )
 
 // Appbase is an adaptor to connect a pipeline to
@@ -25,22 +53,79 @@ type Appbase struct {
 	appName   string
 	typename  string
 	typeMatch *regexp.Regexp
+	mapping   *namespaceMapping
+	breaker   *circuitBreaker
 
 	pipe *pipe.Pipe
 	path string
 
-	client      *elastic.Client
-	bulkService *elastic.BulkService
-	bulkMutex   *sync.Mutex
+	client    *elastic.Client
+	bulks     map[string]*appbaseBulk // the pending bulk for each index
+	bulkMutex *sync.Mutex             // guards the bulks, the counts and stopping the pipe while bulks are committed at once
 	//timerDoneChan chan struct{}
-	count    int
+	counts   map[string]int // the documents sent to each index
 	username string
 	password string
 	debug    bool
 	bulkSize int
 
-	running      bool
-	bulkBodySize int
+	running bool
+
+	dataStream      bool
+	injectTimestamp bool
+
+	compress  bool
+	rateLimit *rateLimitTransport
+	refresh   string        // false, true or wait_for
+	pipeline  string        // when set, the ingest pipeline the cluster runs documents through before they're indexed
+	inflight  chan struct{} // a slot for each index's bulk that can be committing at once
+	signer    *sigV4Transport
+	reindex   *appbaseReindex
+
+	waitForActiveShards string // when set, all or the number of shard copies that must be active before a bulk is written
+
+	indexField *indexField // when each document's index is read from one of its fields
+
+	idPrefix []templatePart // when set, the template each _id is prefixed with, so sources sharing an index don't collide
+
+	hashField string         // when set, inserts and updates whose content hash is already stored are skipped
+	unchanged map[string]int // the unchanged documents each index skipped
+
+	softDelete *SoftDeleteMarkConfig // when set, deletes are sent as updates marking the document deleted
+
+	retryOnConflict int // how many times the cluster retries an update that conflicts with a concurrent write
+
+	defaultAction  string         // index or create, the bulk action for inserts
+	createConflict string         // skip or error, when a create finds the document already exists
+	conflicts      map[string]int // the creates each index rejected because the document already existed
+
+	postFlush *postFlushHook // run after each bulk is committed
+
+	maxFailureRatio float64 // when set, the share of a bulk's documents that can fail before the whole bulk is treated as failed
+
+	flushTimeout time.Duration // when set, how long a bulk request can take before it's given up on and retried
+	slowFlush    time.Duration // when set, bulk requests taking longer are reported
+
+	flushRate *tokenBucket // when set, limits how many bulk requests are sent a second
+
+	version      string // oplog or field, when documents are written with external versions
+	versionField string
+	stale        map[string]int // the stale changes each index rejected
+
+	onError *errorPolicy
+
+	sinkCounts
+}
+
+// appbaseBulk holds the pending actions for one index.  Each index is committed on its own, so a failure
+// writing to one index is reported for that index alone, and doesn't hold up or discard the others
+type appbaseBulk struct {
+	index   string
+	service *elastic.BulkService
+	size    int
+	pending []interface{}     // the documents in the bulk, for the error policy
+	hashes  map[string]string // the content hash of each document in the bulk, "" if it's deleted
+	failed  int               // the documents of the last request that failed on their own, see itemFailures
 }
 
 // NewAppbase creates a new Appbase adaptor.
@@ -51,7 +136,7 @@ func NewAppbase(p *pipe.Pipe, path string, extra Config) (StopStartListener, err
 		err  error
 	)
 	if err = extra.Construct(&conf); err != nil {
-		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
 	}
 
 	if conf.URI == "" {
@@ -59,23 +144,37 @@ func NewAppbase(p *pipe.Pipe, path string, extra Config) (StopStartListener, err
 	}
 
 	if conf.Namespace == "" {
-		return nil, fmt.Errorf("namespace required, but missing ")
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "namespace required, but missing", nil)
 	}
 
 	if conf.UserName == "" || conf.Password == "" {
-		return nil, fmt.Errorf("both username and password required, but missing ")
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "both username and password required, but missing", nil)
 	}
 
+	return newAppbase(p, path, extra, conf, nil)
+}
+
+// newAppbase creates an Appbase adaptor from a validated config, the requests it sends go through signer if
+// it's set.  The opensearch sink is an Appbase adaptor that may sign its requests
+func newAppbase(p *pipe.Pipe, path string, extra Config, conf AppbaseConfig, signer *sigV4Transport) (StopStartListener, error) {
 	u, err := url.Parse(conf.URI)
 	if err != nil {
-		return nil, err
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+	if conf.UserName != "" {
+		u.User = url.UserPassword(conf.UserName, conf.Password)
 	}
-	u.User = url.UserPassword(conf.UserName, conf.Password)
 
 	if conf.BulkSize == 0 {
 		conf.BulkSize = 512000 //500kb
 	}
 
+	if conf.MaxInflightBatches < 0 {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (maxinflightbatches must be positive, got %d)", conf.MaxInflightBatches), nil)
+	} else if conf.MaxInflightBatches == 0 {
+		conf.MaxInflightBatches = appbaseMaxInflightBatches
+	}
+
 	appbase := &Appbase{
 		uri:       u,
 		pipe:      p,
@@ -85,14 +184,167 @@ func NewAppbase(p *pipe.Pipe, path string, extra Config) (StopStartListener, err
 		debug:    conf.Debug,
 		username: conf.UserName,
 		password: conf.Password,
+
+		dataStream:      conf.DataStream,
+		injectTimestamp: conf.InjectTimestamp,
+
+		compress: conf.CompressRequests,
+		inflight: make(chan struct{}, conf.MaxInflightBatches),
+		signer:   signer,
+
+		hashField: conf.SkipUnchanged,
+	}
+
+	appbase.refresh, err = appbaseRefresh(conf.Refresh)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	appbase.version, appbase.versionField = conf.Version, conf.VersionField
+	switch {
+	case appbase.version == "":
+	case appbase.version != "oplog" && appbase.version != "field":
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unknown version (%s), must be oplog or field)", appbase.version), nil)
+	case appbase.version == "field" && appbase.versionField == "":
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (versionfield required when the version is field)", nil)
+	case appbase.dataStream:
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (data streams can't be versioned)", nil)
+	}
+
+	if conf.Reindex != nil {
+		if appbase.dataStream || len(conf.Mapping) > 0 {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (a reindex writes to a single index, it can't be used with datastream or mapping)", nil)
+		}
+		if appbase.reindex, err = newAppbaseReindex(conf.Reindex); err != nil {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+		}
+	}
+
+	if conf.RetryOnConflict < 0 {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (retryonconflict must be positive, got %d)", conf.RetryOnConflict), nil)
+	}
+	appbase.retryOnConflict = conf.RetryOnConflict
+
+	appbase.defaultAction, appbase.createConflict = conf.DefaultAction, conf.CreateConflict
+	switch appbase.defaultAction {
+	case "":
+		appbase.defaultAction = "index"
+	case "index":
+	case "create":
+		if appbase.dataStream || appbase.version != "" {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (defaultaction can't be used with datastream or version, they choose their own actions)", nil)
+		}
+	default:
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unknown defaultaction (%s), must be index or create)", appbase.defaultAction), nil)
+	}
+	switch appbase.createConflict {
+	case "":
+		appbase.createConflict = "skip"
+	case "skip", "error":
+	default:
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unknown createconflict (%s), must be skip or error)", appbase.createConflict), nil)
+	}
+
+	if conf.SoftDelete != nil {
+		if appbase.dataStream || appbase.version != "" {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (soft deletes are partial updates, they can't be used with datastream or version)", nil)
+		}
+		appbase.softDelete = conf.SoftDelete
+		if appbase.softDelete.Field == "" {
+			appbase.softDelete.Field = "deleted"
+		}
+	}
+
+	if appbase.hashField != "" && appbase.dataStream {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (data streams are append-only, skipunchanged can't be used with datastream)", nil)
+	}
+
+	if conf.IndexField != "" {
+		if appbase.reindex != nil {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (a reindex writes to a single index, it can't be used with indexfield)", nil)
+		}
+		if appbase.indexField, err = newIndexField(conf.IndexField, conf.InvalidIndex); err != nil {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+		}
 	}
 
 	appbase.debugLog("Appbase conf: %#v", conf)
 
+	appbase.mapping, err = newNamespaceMapping(conf.Mapping)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	appbase.breaker, err = newCircuitBreaker(conf.Breaker)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Pipeline != nil {
+		if strings.TrimSpace(*conf.Pipeline) == "" {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (pipeline can't be empty)", nil)
+		}
+		appbase.pipeline = *conf.Pipeline
+	}
+
+	appbase.waitForActiveShards, err = appbaseWaitForActiveShards(conf.WaitForActiveShards)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.MaxFailureRatio < 0 || conf.MaxFailureRatio > 1 {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (maxfailureratio must be between 0 and 1, got %v)", conf.MaxFailureRatio), nil)
+	}
+	appbase.maxFailureRatio = conf.MaxFailureRatio
+
+	if conf.FlushTimeout != "" {
+		if appbase.flushTimeout, err = time.ParseDuration(conf.FlushTimeout); err != nil || appbase.flushTimeout <= 0 {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unable to parse flushtimeout %s)", conf.FlushTimeout), nil)
+		}
+	}
+	if conf.SlowFlushThreshold != "" {
+		if appbase.slowFlush, err = time.ParseDuration(conf.SlowFlushThreshold); err != nil || appbase.slowFlush <= 0 {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unable to parse slowflushthreshold %s)", conf.SlowFlushThreshold), nil)
+		}
+	}
+
+	if conf.MaxFlushesPerSecond < 0 {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (maxflushespersecond must be positive, got %v)", conf.MaxFlushesPerSecond), nil)
+	} else if conf.MaxFlushesPerSecond > 0 {
+		appbase.flushRate = newTokenBucket(conf.MaxFlushesPerSecond, 1)
+	}
+
+	if conf.SizeHistogram {
+		p.DocSizes = events.NewSizeHistogram()
+	}
+
+	if conf.IDPrefix != "" {
+		if appbase.idPrefix, err = parseIDPrefix(conf.IDPrefix); err != nil {
+			return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+		}
+	}
+
+	appbase.postFlush, err = newPostFlushHook(conf.PostFlush)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	// with a breaker, the documents it turns away were dropped before they went to the error policy, so
+	// skipping them is the default
+	onErrorDefault := onErrorFail
+	if appbase.breaker != nil {
+		onErrorDefault = onErrorSkip
+	}
+	appbase.onError, err = newErrorPolicy(p, path, extra, onErrorDefault)
+	if err != nil {
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+	appbase.onError.counts = &appbase.sinkCounts
+
 	appbase.appName, appbase.typename, err = extra.splitNamespace()
 	appbase.typeMatch = regexp.MustCompile(".*")
 	if err != nil {
-		return appbase, NewError(CRITICAL, path, fmt.Sprintf("can't split namespace into app name and type (%s)", err.Error()), nil)
+		return appbase, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("can't split namespace into app name and type (%s)", err.Error()), nil)
 	}
 
 	return appbase, nil
@@ -107,8 +359,18 @@ func (a *Appbase) Start() error {
 func (a *Appbase) Listen() error {
 	defer a.Stop()
 
-	if err := a.setupClient(); err != nil {
-		a.pipe.Err <- NewError(ERROR, a.path, fmt.Sprintf("appbase error (%s)", err), "")
+	if a.client == nil {
+		if err := a.setupClient(); err != nil {
+			a.pipe.Err <- NewCategorizedError(TRANSIENT, ERROR, a.path, fmt.Sprintf("appbase error (%s)", err), "")
+		}
+	}
+
+	if a.reindex != nil && a.client != nil {
+		if err := a.reindex.create(a.client); err != nil {
+			err = NewCategorizedError(appbaseErrorCategory(err), CRITICAL, a.path, fmt.Sprintf("appbase error (%s)", err), nil)
+			a.pipe.Err <- err
+			return err
+		}
 	}
 
 	a.running = true
@@ -116,38 +378,192 @@ func (a *Appbase) Listen() error {
 	return a.pipe.Listen(a.addBulkCommand, a.typeMatch)
 }
 
+// Finish moves the reindex alias to the new index if the run was complete, or cleans up the new index if it wasn't
+func (a *Appbase) Finish(result error) error {
+	if a.reindex == nil || a.client == nil {
+		return nil
+	}
+	return a.reindex.finish(a.client, result)
+}
+
+// Ping checks that the appbase cluster is reachable, the client is kept for Listen
+func (a *Appbase) Ping() error {
+	if a.client == nil {
+		if err := a.setupClient(); err != nil {
+			a.client = nil
+			return err
+		}
+	}
+	_, _, err := a.client.Ping().URL(a.uri.String()).Do()
+	return err
+}
+
+// Check pings the cluster, which must answer with a success, and with write, indexes a canary document in the
+// app's index and deletes it again.  Data streams and reindexes don't take the canary, a data stream can't
+// delete it and the new index doesn't exist until the run starts
+func (a *Appbase) Check(write bool) []CheckStep {
+	steps := checkSteps{}
+	if !steps.run(fmt.Sprintf("connect to %s", a.uri.Host), func() error {
+		if a.client != nil {
+			return nil
+		}
+		if err := a.setupClient(); err != nil {
+			a.client = nil
+			// the client only says there's no node available, so ask the cluster why itself
+			res, getErr := (&http.Client{Transport: a.rateLimit, Timeout: 10 * time.Second}).Get(a.uri.String())
+			if getErr != nil {
+				return fmt.Errorf("%s, %s", err.Error(), getErr.Error())
+			}
+			res.Body.Close()
+			return fmt.Errorf("%s, the cluster answered %s", err.Error(), res.Status)
+		}
+		return nil
+	}) {
+		return steps
+	}
+	if !steps.run("ping the cluster", func() error {
+		_, code, err := a.client.Ping().URL(a.uri.String()).Do()
+		if err != nil {
+			return err
+		}
+		if code < 200 || code > 299 {
+			return fmt.Errorf("the cluster answered %d %s", code, http.StatusText(code))
+		}
+		return nil
+	}) {
+		return steps
+	}
+	if !write || a.dataStream || a.reindex != nil {
+		return steps
+	}
+
+	id := canaryID()
+	if !steps.run(fmt.Sprintf("write canary document %s to %s", id, a.appName), func() error {
+		_, err := a.client.Index().Index(a.appName).Type(a.typename).Id(id).BodyJson(map[string]interface{}{"check": true}).Do()
+		return err
+	}) {
+		return steps
+	}
+	steps.run(fmt.Sprintf("delete canary document %s", id), func() error {
+		_, err := a.client.Delete().Index(a.appName).Type(a.typename).Id(id).Do()
+		return err
+	})
+	return steps
+}
+
 // Stop the adaptor
 func (a *Appbase) Stop() error {
 	if a.running {
 		a.running = false
 		a.pipe.Stop()
 		a.commitBulk(true)
-		a.debugLog("Documents sent: %d", a.count)
+		for index, count := range a.counts {
+			a.debugLog("Documents sent to %s: %d", index, count)
+		}
+		for index, count := range a.stale {
+			a.debugLog("Stale changes skipped by %s: %d", index, count)
+		}
+		for index, count := range a.conflicts {
+			a.debugLog("Creates of existing documents rejected by %s: %d", index, count)
+		}
+		for index, count := range a.unchanged {
+			a.debugLog("Unchanged documents skipped by %s: %d", index, count)
+		}
+	}
+	return nil
+}
+
+// Flush commits every pending bulk when the checkpoint's marker arrives.  Documents kept to retry, until
+// the breaker opens, aren't written yet, so the marker isn't acknowledged while there are any
+func (a *Appbase) Flush() error {
+	a.commitBulk(true)
+	for _, index := range sortedBulkIndexes(a.bulks) {
+		if n := a.bulks[index].service.NumberOfActions(); n > 0 {
+			return fmt.Errorf("%d documents for %s are waiting to be retried", n, index)
+		}
+	}
+	if a.pipe.Stopped {
+		return fmt.Errorf("the sink has stopped")
 	}
 	return nil
 }
 
 func (a *Appbase) addBulkCommand(msg *message.Msg) (*message.Msg, error) {
-	id, err := msg.IDString("_id")
-	if err != nil {
-		id = ""
+	// indexes aren't deleted along with the source's collections
+	if command := msg.DDLCommand(); command != "" {
+		a.debugLog("Appbase: skipping the %s of %s", command, msg.Namespace)
+		return msg, nil
 	}
 
-	switch msg.Op {
-	case message.Delete:
-		bulkRequest := elastic.NewBulkDeleteRequest().Index(a.appName).Type(a.typename).Id(id)
-		a.AddBulkRequestSize(bulkRequest)
-		a.bulkService.Add(bulkRequest)
+	var err error
+	id := a.docID(msg)
+
+	index := a.mapping.resolve(msg.Namespace, a.appName)
+	if a.reindex != nil {
+		index = a.reindex.Index
+	}
+	if a.indexField != nil && msg.Op != message.Command && msg.IsMap() {
+		if index, err = a.indexField.resolve(msg.Map(), index); err != nil {
+			a.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, a.path, fmt.Sprintf("appbase error (%s)", err.Error()), msg.Data)
+			return msg, nil
+		}
+	}
+
+	if hash := a.msgHash(msg); hash != "" && id != "" {
+		unchanged, err := a.isUnchanged(index, id, hash)
+		if err != nil {
+			a.pipe.Err <- NewCategorizedError(appbaseErrorCategory(err), WARNING, a.path, fmt.Sprintf("appbase error (can't read the stored hash of %s, writing it, %s)", id, err.Error()), nil)
+		} else if unchanged {
+			a.unchanged[index]++
+			return msg, nil
+		}
+	}
+
+	if a.dataStream {
+		bulkRequest, err := a.dataStreamRequest(msg, index, id)
+		if err != nil {
+			a.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, a.path, fmt.Sprintf("appbase error (%s)", err.Error()), msg.Data)
+			return msg, nil
+		}
+		a.addBulkRequest(index, bulkRequest, msg)
+		a.commitBulk(false)
+		return msg, nil
+	}
+
+	if a.version != "" {
+		bulkRequest, err := a.versionedRequest(msg, index, id)
+		if err != nil {
+			a.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, a.path, fmt.Sprintf("appbase error (%s)", err.Error()), msg.Data)
+			return msg, nil
+		}
+		a.addBulkRequest(index, bulkRequest, msg)
+		a.commitBulk(false)
+		return msg, nil
+	}
+
+	switch {
+	case msg.Op == message.Delete && a.softDelete != nil:
+		marks := a.softDelete.marks(msg)
+		if a.pipe.OpField != "" {
+			marks[a.pipe.OpField] = msg.Op.String() // the op field is given to the document the delete marks
+		}
+		bulkRequest := a.updateRequest(index, id).Doc(marks)
+		a.addBulkRequest(index, bulkRequest, msg)
+		break
+	case msg.Op == message.Delete:
+		bulkRequest := elastic.NewBulkDeleteRequest().Index(index).Type(a.typename).Id(id)
+		a.addBulkRequest(index, bulkRequest, msg)
 		break
-	case message.Update:
-		bulkRequest := elastic.NewBulkUpdateRequest().Index(a.appName).Type(a.typename).Id(id).Doc(msg.Data)
-		a.AddBulkRequestSize(bulkRequest)
-		a.bulkService.Add(bulkRequest)
+	case msg.Op == message.Update:
+		bulkRequest := a.updateRequest(index, id).Doc(msg.Data)
+		a.addBulkRequest(index, bulkRequest, msg)
 		break
 	default:
-		bulkRequest := elastic.NewBulkIndexRequest().Index(a.appName).Type(a.typename).Id(id).Doc(msg.Data)
-		a.AddBulkRequestSize(bulkRequest)
-		a.bulkService.Add(bulkRequest)
+		bulkRequest := elastic.NewBulkIndexRequest().Index(index).Type(a.typename).Id(id).Doc(msg.Data)
+		if a.defaultAction == "create" {
+			bulkRequest.OpType("create") // fails, rather than overwriting, if the document already exists
+		}
+		a.addBulkRequest(index, bulkRequest, msg)
 		break
 	}
 
@@ -156,64 +572,721 @@ func (a *Appbase) addBulkCommand(msg *message.Msg) (*message.Msg, error) {
 	return msg, nil
 }
 
+// docID returns the _id to write the message's document to, with the id prefix if there is one, or "" if
+// the message doesn't have an _id
+func (a *Appbase) docID(msg *message.Msg) string {
+	id, err := msg.IDString("_id")
+	if err != nil || id == "" {
+		return ""
+	}
+	if a.idPrefix == nil {
+		return id
+	}
+
+	database, collection := msg.Namespace, ""
+	if i := strings.Index(msg.Namespace, "."); i >= 0 {
+		database, collection = msg.Namespace[:i], msg.Namespace[i+1:]
+	}
+	vars := map[string]string{"namespace": msg.Namespace, "database": database, "collection": collection}
+	var prefix []string
+	for _, part := range a.idPrefix {
+		if part.field == "" {
+			prefix = append(prefix, part.text)
+			continue
+		}
+		s := vars[part.field]
+		for _, filter := range part.filters {
+			s = filter(s)
+		}
+		prefix = append(prefix, s)
+	}
+	return strings.Join(prefix, "") + id
+}
+
+// parseIDPrefix parses the id prefix template, which can only reference the message's namespace, or the
+// database and collection within it, as deletes don't carry the document's other fields
+func parseIDPrefix(text string) ([]templatePart, error) {
+	parts, err := parseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		if part.field != "" && part.field != "namespace" && part.field != "database" && part.field != "collection" {
+			return nil, fmt.Errorf("idprefix can only reference namespace, database or collection, not %s", part.field)
+		}
+	}
+	return parts, nil
+}
+
+// updateRequest returns a bulk update of the document, which the cluster retries if it conflicts with a
+// concurrent write and retryonconflict is set
+func (a *Appbase) updateRequest(index, id string) *elastic.BulkUpdateRequest {
+	r := elastic.NewBulkUpdateRequest().Index(index).Type(a.typename).Id(id)
+	if a.retryOnConflict > 0 {
+		r.RetryOnConflict(a.retryOnConflict)
+	}
+	return r
+}
+
+// msgHash returns the content hash of the message's document, "" if it doesn't have one or it's a delete
+func (a *Appbase) msgHash(msg *message.Msg) string {
+	if a.hashField == "" || msg.Op == message.Delete || msg.Op == message.Command || !msg.IsMap() {
+		return ""
+	}
+	hash, _ := getField(msg.Map(), a.hashField)
+	s, _ := hash.(string)
+	return s
+}
+
+// isUnchanged returns whether the hash is the document's hash as it will be once the pending bulk is written,
+// or if it's not in the bulk, as it's stored.  A document that isn't stored, or doesn't have a hash, has changed
+func (a *Appbase) isUnchanged(index, id, hash string) (bool, error) {
+	if b, ok := a.bulks[index]; ok {
+		if pending, ok := b.hashes[id]; ok {
+			return pending == hash, nil
+		}
+	}
+
+	res, err := a.client.Get().Index(index).Type(a.typename).Id(id).FetchSourceContext(elastic.NewFetchSourceContext(true).Include(a.hashField)).Do()
+	if err != nil {
+		return false, err
+	}
+	if !res.Found || res.Source == nil {
+		return false, nil
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(*res.Source, &stored); err != nil {
+		return false, err
+	}
+	v, _ := getField(stored, a.hashField)
+	return v == hash, nil
+}
+
+// dataStreamRequest builds the bulk request appending msg to a data stream.
+// data streams are append-only, so only inserts are accepted and they're sent as create actions,
+// and every document needs an @timestamp field
+func (a *Appbase) dataStreamRequest(msg *message.Msg, index, id string) (elastic.BulkableRequest, error) {
+	if msg.Op != message.Insert {
+		return nil, fmt.Errorf("data streams are append-only, can't apply %s to %s", msg.Op, index)
+	}
+	if !msg.IsMap() {
+		return nil, fmt.Errorf("document must be a json document, got %T instead", msg.Data)
+	}
+
+	doc := msg.Map()
+	if _, ok := doc[dataStreamTimestampField]; !ok {
+		if !a.injectTimestamp {
+			return nil, fmt.Errorf("document is missing the %s field required by data streams", dataStreamTimestampField)
+		}
+		stamped := make(map[string]interface{}, len(doc)+1)
+		for k, v := range doc {
+			stamped[k] = v
+		}
+		stamped[dataStreamTimestampField] = time.Unix(msg.Timestamp, 0).UTC().Format(time.RFC3339)
+		doc = stamped
+	}
+
+	return elastic.NewBulkIndexRequest().OpType("create").Index(index).Id(id).Doc(doc), nil
+}
+
+// versionedRequest builds the bulk request writing msg with an external version, so that elasticsearch rejects
+// it with a 409 if the document already has a later version, and a change that arrives late can't overwrite
+// a newer one or bring back a deleted document.  elasticsearch can't version partial updates, so updates are
+// sent as the whole document, which needs a source that sends the whole document with each update
+func (a *Appbase) versionedRequest(msg *message.Msg, index, id string) (elastic.BulkableRequest, error) {
+	version, err := a.msgVersion(msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Op == message.Delete {
+		return elastic.NewBulkDeleteRequest().Index(index).Type(a.typename).Id(id).Version(version).VersionType("external"), nil
+	}
+	return elastic.NewBulkIndexRequest().Index(index).Type(a.typename).Id(id).Version(version).VersionType("external").Doc(msg.Data), nil
+}
+
+// msgVersion returns the external version of the message, its oplog timestamp or the value of the version field
+func (a *Appbase) msgVersion(msg *message.Msg) (int64, error) {
+	if a.version == "oplog" {
+		if msg.Version <= 0 {
+			return 0, fmt.Errorf("document has no oplog version")
+		}
+		return msg.Version, nil
+	}
+
+	v, ok := getField(msg.Map(), a.versionField)
+	if !ok || v == nil {
+		return 0, fmt.Errorf("document is missing the version field %s", a.versionField)
+	}
+	var version int64
+	switch n := v.(type) {
+	case int:
+		version = int64(n)
+	case int32:
+		version = int64(n)
+	case int64:
+		version = n
+	case float64:
+		version = int64(n)
+	case bson.MongoTimestamp:
+		version = int64(n)
+	case time.Time:
+		version = n.UnixNano()
+	default:
+		return 0, fmt.Errorf("version field %s is a %T, not a number or a date", a.versionField, v)
+	}
+	if version <= 0 {
+		return 0, fmt.Errorf("version field %s must be positive, got %d", a.versionField, version)
+	}
+	return version, nil
+}
+
 func (a *Appbase) setupClient() error {
 	var err error
-	a.client, err = elastic.NewClient(
+	options := []elastic.ClientOptionFunc{
 		elastic.SetURL(a.uri.String()),
 		elastic.SetSniff(false),
-	)
+	}
+	// requests are signed last, after anything that changes them
+	if a.signer != nil {
+		a.rateLimit = newRateLimitTransport(a.signer)
+	} else {
+		a.rateLimit = newRateLimitTransport(nil)
+	}
+	var transport http.RoundTripper = a.rateLimit
+	if a.compress {
+		transport = newGzipTransport(a.rateLimit, func() {
+			a.pipe.Err <- NewError(WARNING, a.path, "appbase error (the server doesn't accept compressed requests, sending them uncompressed)", nil)
+		})
+	}
+	params := url.Values{}
+	if a.refresh == "wait_for" {
+		params.Set("refresh", a.refresh)
+	}
+	if a.pipeline != "" {
+		params.Set("pipeline", a.pipeline)
+	}
+	if a.waitForActiveShards != "" {
+		params.Set("wait_for_active_shards", a.waitForActiveShards)
+	}
+	if len(params) > 0 {
+		transport = &bulkParamsTransport{next: transport, params: params}
+	}
+	if a.flushTimeout > 0 {
+		transport = &bulkTimeoutTransport{next: transport, timeout: a.flushTimeout}
+	}
+	options = append(options, elastic.SetHttpClient(&http.Client{Transport: transport}))
+	a.client, err = elastic.NewClient(options...)
 
 	if err != nil {
 		return err
 	}
 
-	a.bulkService = a.client.Bulk().Index(a.appName).Type(a.typename)
+	a.resetBulk()
 
 	return nil
 
 }
 
+// resetBulk discards any pending bulk actions
+func (a *Appbase) resetBulk() {
+	a.bulks = make(map[string]*appbaseBulk)
+	if a.counts == nil {
+		a.counts = make(map[string]int)
+		a.stale = make(map[string]int)
+		a.conflicts = make(map[string]int)
+		a.unchanged = make(map[string]int)
+	}
+}
+
+// bulk returns the pending bulk for the index
+func (a *Appbase) bulk(index string) *appbaseBulk {
+	b, ok := a.bulks[index]
+	if !ok {
+		b = &appbaseBulk{index: index, service: a.client.Bulk().Index(index), hashes: make(map[string]string)}
+		if !a.dataStream { // data streams don't have types
+			b.service.Type(a.typename)
+		}
+		if a.refresh != "wait_for" { // wait_for is set by the bulkParamsTransport
+			b.service.Refresh(a.refresh == "true")
+		}
+		a.bulks[index] = b
+	}
+	return b
+}
+
+// commitBulk commits the bulk for each index that's full, or every bulk if commitNow is set.  The bulks for
+// different indexes are committed at once, each as soon as it has an inflight slot, so no more than
+// maxinflightbatches are waiting on the cluster.  commitBulk returns once they're all committed, so each
+// index's bulks are still written in order, and no documents are added while they're in flight
 func (a *Appbase) commitBulk(commitNow bool) {
-	//
-	if a.bulkBodySize >= a.bulkSize || a.bulkService.NumberOfActions() >= APPBASE_BUFFER_LEN || commitNow {
-		a.debugLog("Appbase: Sending %d documents.", a.bulkService.NumberOfActions())
-		a.count += a.bulkService.NumberOfActions()
-		a.debugLog("Appbase request size: %d", a.bulkBodySize)
+	// the bulks are picked before any are committed, as a commit that fails removes its bulk from the map
+	var bulks []*appbaseBulk
+	for _, index := range sortedBulkIndexes(a.bulks) {
+		b := a.bulks[index]
+		if b.size < a.bulkSize && b.service.NumberOfActions() < APPBASE_BUFFER_LEN && !commitNow {
+			continue
+		}
+		bulks = append(bulks, b)
+	}
 
-		_, err := a.bulkService.Do()
-		if err != nil {
-			a.pipe.Err <- NewError(CRITICAL, a.path, fmt.Sprintf("appbase error (%s)", err), nil)
-			a.pipe.Stop()
+	var wg sync.WaitGroup
+	for _, b := range bulks {
+		if a.inflight == nil {
+			a.commitIndex(b)
+			continue
 		}
-		a.bulkBodySize = 0
-		//		if bulkResponse.Errors {
-		//			for _, item := range bulkResponse.Failed() {
-		//				a.pipe.Err <- NewError(ERROR, a.path, fmt.Sprintf("appbase bulk error id:%s (%s)", item.Id, item.Error), nil)
-		//			}
-		//		}
+		a.inflight <- struct{}{}
+		wg.Add(1)
+		go func(b *appbaseBulk) {
+			defer wg.Done()
+			defer func() { <-a.inflight }()
+			a.commitIndex(b)
+		}(b)
 	}
+	wg.Wait()
+}
+
+// commitIndex sends the index's bulk, a failure is handled by the breaker or the error policy as for a single index
+func (a *Appbase) commitIndex(b *appbaseBulk) {
+	if b.service.NumberOfActions() == 0 {
+		return
+	}
+
+	// while the breaker is open, or another index's bulk is its trial write, we don't bother the cluster,
+	// the pending documents go to the error policy
+	if !a.breaker.allow() {
+		a.failBulk(b, TRANSIENT, fmt.Sprintf("appbase error (circuit breaker %s, dropping %d documents for %s)", a.breaker.status(), b.service.NumberOfActions(), b.index))
+		return
+	}
+
+	a.debugLog("Appbase: Sending %d documents to %s.", b.service.NumberOfActions(), b.index)
+	a.debugLog("Appbase request size: %d", b.size)
+
+	sent := b.service.NumberOfActions()
+	err := a.doBulk(b)
+	switch {
+	case err == nil:
+		if a.breaker.success() {
+			a.pipe.Err <- NewError(NOTICE, a.path, fmt.Sprintf("appbase circuit breaker %s", a.breaker.status()), nil)
+		}
+	case a.breaker != nil && !a.breaker.failure():
+		// until the breaker opens, the failed actions stay in the bulk service and are retried with the next commit
+		a.pipe.Err <- NewCategorizedError(appbaseErrorCategory(err), ERROR, a.path, fmt.Sprintf("appbase error (%s: %s)", b.index, err), nil)
+	default:
+		if a.breaker != nil {
+			a.pipe.Err <- NewError(WARNING, a.path, fmt.Sprintf("appbase circuit breaker %s", a.breaker.status()), nil)
+		}
+		a.failBulk(b, appbaseErrorCategory(err), fmt.Sprintf("appbase error (%s: %s)", b.index, err))
+	}
+	if err == nil {
+		a.bulkMutex.Lock()
+		a.counts[b.index] += sent
+		a.bulkMutex.Unlock()
+		a.wrote(sent - b.failed)
+		b.failed = 0
+		b.pending = nil
+		b.hashes = make(map[string]string)
+		b.size = 0 // a failed bulk keeps its actions, and their size, to be retried
+		a.runPostFlush(b.index, sent)
+	}
+	//		if bulkResponse.Errors {
+	//			for _, item := range bulkResponse.Failed() {
+	//				a.pipe.Err <- NewError(ERROR, a.path, fmt.Sprintf("appbase bulk error id:%s (%s)", item.Id, item.Error), nil)
+	//			}
+	//		}
+}
+
+// failBulk hands the bulk's documents to the error policy.  The bulk is discarded once they're skipped or dead
+// lettered, if the policy fails the sink they're kept, as the sink is stopping
+func (a *Appbase) failBulk(b *appbaseBulk, category ErrorCategory, msg string) {
+	if err := a.onError.handleCategorized(category, msg, b.pending...); err != nil {
+		a.pipe.Err <- err
+		a.stopPipe()
+		a.lostDocuments()
+		return
+	}
+	if a.breaker != nil {
+		a.lostDocuments() // the breaker's drops leave a reindex incomplete, whatever the policy does with them
+	}
+	a.bulkMutex.Lock()
+	delete(a.bulks, b.index)
+	a.bulkMutex.Unlock()
+}
+
+// lostDocuments records that documents couldn't be written, so a reindex is incomplete
+func (a *Appbase) lostDocuments() {
+	a.bulkMutex.Lock()
+	defer a.bulkMutex.Unlock()
+	if a.reindex != nil {
+		a.reindex.failed = true
+	}
+}
+
+// stopPipe stops the pipe once a bulk's failure should stop the sink, the bulks for several indexes can fail at once
+func (a *Appbase) stopPipe() {
+	a.bulkMutex.Lock()
+	defer a.bulkMutex.Unlock()
+	a.pipe.Stop()
+}
+
+// sortedBulkIndexes returns the indexes with pending bulks, in order, so their commits always start in the same order
+func sortedBulkIndexes(bulks map[string]*appbaseBulk) []string {
+	indexes := make([]string, 0, len(bulks))
+	for index := range bulks {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+	return indexes
+}
+
+// appbaseErrorCategory categorizes a failed bulk.  Failed connections, rate limits and server errors are
+// transient, any other rejected request is permanent
+func appbaseErrorCategory(err error) ErrorCategory {
+	switch e := err.(type) {
+	case *elastic.Error:
+		if e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError {
+			return TRANSIENT
+		}
+		return PERMANENT
+	case *url.Error, net.Error:
+		return TRANSIENT
+	case *appbaseBulkFailure:
+		return PERMANENT
+	case *appbaseFlushTimeout:
+		return TRANSIENT
+	}
+	return UNCATEGORIZED
+}
+
+// doBulk sends the pending bulk.  rate limited bulks are retried, after waiting as long as appbase
+// asks, until they're accepted or fail for some other reason.  A versioned change rejected because the
+// document has a later version is stale, it's counted and otherwise ignored.  A create rejected because the
+// document already exists is counted, and reported under the error createconflict policy.  Any other failed
+// actions are checked against maxfailureratio
+func (a *Appbase) doBulk(b *appbaseBulk) error {
+	backoff := appbaseRateLimitBackoff
+	revived := false
+	for {
+		res, err := a.sendBulk(b)
+		if err == nil {
+			a.bulkMutex.Lock()
+			for _, item := range res.Failed() {
+				if item.Status == http.StatusConflict && a.version != "" {
+					a.stale[b.index]++
+				}
+			}
+			a.bulkMutex.Unlock()
+			if a.defaultAction == "create" {
+				a.createConflicts(b.index, res)
+			}
+			return a.itemFailures(b, res)
+		}
+		if err == elastic.ErrNoClient && !revived {
+			// the client gives up on the node after a failed request, i.e. a timeout, and the next request revives
+			// it with a health check, but fails anyway, so that one's sent again at once
+			revived = true
+			continue
+		}
+		if a.rateLimit == nil {
+			return err
+		}
+		wait, limited := a.rateLimit.take()
+		if e, ok := err.(*elastic.Error); ok && e.Status == http.StatusTooManyRequests {
+			limited = true // another index's bulk, in flight at the same time, may have taken the Retry-After
+		}
+		if !limited {
+			return err
+		}
+		if wait <= 0 {
+			wait = backoff
+			if backoff *= 2; backoff > appbaseMaxRateLimitBackoff {
+				backoff = appbaseMaxRateLimitBackoff
+			}
+		}
+
+		a.pipe.Err <- NewCategorizedError(TRANSIENT, WARNING, a.path, fmt.Sprintf("appbase rate limited, retrying %d documents for %s in %s", b.service.NumberOfActions(), b.index, wait), nil)
+		time.Sleep(wait)
+	}
+}
+
+// appbaseBulkFailure is returned when more of a bulk's documents failed than maxfailureratio allows, which
+// suggests something's wrong with the index, i.e. a mapping change, rather than with the documents
+type appbaseBulkFailure struct {
+	failed, total int
+	ratio         float64
+	reason        string // the first failure's
+}
+
+func (e *appbaseBulkFailure) Error() string {
+	return fmt.Sprintf("%d of %d documents failed, more than the maxfailureratio of %v allows, i.e. %s", e.failed, e.total, e.ratio, e.reason)
+}
+
+// appbaseFlushTimeout is returned when a bulk took longer than flushtimeout.  It's transient, so the bulk is
+// retried by the breaker, the cluster may have written some of it, which writing it again overwrites
+type appbaseFlushTimeout struct {
+	documents int
+	timeout   time.Duration
+}
+
+func (e *appbaseFlushTimeout) Error() string {
+	return fmt.Sprintf("%d documents timed out after %s", e.documents, e.timeout)
+}
+
+// itemFailures checks the actions of the bulk that failed when maxfailureratio is set, leaving out the stale
+// changes and create conflicts, which are expected.  When too many failed the bulk is treated as failed, and
+// handled by the error policy as a whole, otherwise each failed document is reported, or dead lettered
+func (a *Appbase) itemFailures(b *appbaseBulk, res *elastic.BulkResponse) error {
+	if a.maxFailureRatio == 0 || res == nil {
+		return nil
+	}
+
+	type failure struct {
+		item *elastic.BulkResponseItem
+		doc  interface{}
+	}
+	var failures []failure
+	for i, action := range res.Items {
+		for op, item := range action {
+			if item.Status >= 200 && item.Status <= 299 {
+				continue
+			}
+			if item.Status == http.StatusConflict && (a.version != "" || op == "create" && a.defaultAction == "create") {
+				continue
+			}
+			f := failure{item: item}
+			if len(b.pending) == len(res.Items) {
+				f.doc = b.pending[i]
+			}
+			failures = append(failures, f)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if float64(len(failures))/float64(len(res.Items)) > a.maxFailureRatio {
+		return &appbaseBulkFailure{failed: len(failures), total: len(res.Items), ratio: a.maxFailureRatio, reason: failures[0].item.Error}
+	}
+	b.failed = len(failures)
+	for _, f := range failures {
+		msg := fmt.Sprintf("appbase error (%s: can't write %s, %s)", b.index, f.item.Id, f.item.Error)
+		if a.onError.action != onErrorDeadLetter {
+			a.failedToWrite(1)
+			a.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, a.path, msg, f.doc)
+			continue
+		}
+		if err := a.onError.handleCategorized(PERMANENT, msg, f.doc); err != nil {
+			a.pipe.Err <- err
+			a.stopPipe()
+			a.lostDocuments()
+			return nil
+		}
+	}
+	return nil
+}
+
+// runPostFlush runs the post flush command, if there is one, once a bulk of documents has been committed to the index
+func (a *Appbase) runPostFlush(index string, documents int) {
+	err := a.postFlush.run(a.path, index, documents)
+	if err == nil {
+		return
+	}
+	if a.postFlush.onFailure == "stop" {
+		a.pipe.Err <- NewError(CRITICAL, a.path, fmt.Sprintf("appbase error (%s: %s)", index, err.Error()), nil)
+		a.stopPipe()
+		return
+	}
+	a.pipe.Err <- NewError(WARNING, a.path, fmt.Sprintf("appbase error (%s: %s)", index, err.Error()), nil)
+}
+
+// createConflicts counts the creates in the bulk response that failed because the document already exists
+func (a *Appbase) createConflicts(index string, res *elastic.BulkResponse) {
+	for _, item := range res.Created() {
+		if item.Status != http.StatusConflict {
+			continue
+		}
+		a.bulkMutex.Lock()
+		a.conflicts[index]++
+		a.bulkMutex.Unlock()
+		if a.createConflict == "error" {
+			a.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, a.path, fmt.Sprintf("appbase error (%s: can't create %s, it already exists)", index, item.Id), nil)
+		}
+	}
+}
+
+// sendBulk sends the bulk.  A bulk that takes longer than slowflushthreshold is reported, so a struggling
+// cluster is noticed before it stalls.  With maxflushespersecond set, each bulk also waits its turn, the
+// documents added meanwhile wait with it
+func (a *Appbase) sendBulk(b *appbaseBulk) (*elastic.BulkResponse, error) {
+	if a.flushRate != nil {
+		if waited := a.flushRate.wait(); waited > 0 {
+			a.debugLog("Appbase: waited %s to send %d documents to %s, for maxflushespersecond", waited, b.service.NumberOfActions(), b.index)
+		}
+	}
+
+	documents := b.service.NumberOfActions()
+	start := time.Now()
+	res, err := b.service.Do()
+	took := time.Since(start)
+	if e, ok := err.(*url.Error); ok && e.Timeout() && a.flushTimeout > 0 {
+		return nil, &appbaseFlushTimeout{documents: documents, timeout: a.flushTimeout}
+	}
+	if a.slowFlush > 0 && took > a.slowFlush {
+		a.pipe.Err <- NewError(WARNING, a.path, fmt.Sprintf("appbase slow flush (%s: %d documents took %s, more than the slowflushthreshold of %s)", b.index, documents, took, a.slowFlush), nil)
+	}
+	return res, err
 }
 
 func (a *Appbase) debugLog(format string, v ...interface{}) {
 	if a.debug {
-		log.Printf(format, v)
+		log.Printf(format, v...)
 	}
 }
 
-func (a *Appbase) AddBulkRequestSize(bulkRequest elastic.BulkableRequest) {
+// addBulkRequest adds the request to the index's pending bulk.  If the request would push the bulk past bulkSize
+// the pending bulk is committed first, so a bulk only goes over bulkSize when it holds a single oversized request
+func (a *Appbase) addBulkRequest(index string, bulkRequest elastic.BulkableRequest, msg *message.Msg) {
+	size := bulkRequestSize(bulkRequest)
+	if a.pipe.DocSizes != nil {
+		a.pipe.DocSizes.Observe(size)
+	}
+	b := a.bulk(index)
+	if b.service.NumberOfActions() > 0 && b.size+size > a.bulkSize {
+		a.commitIndex(b)
+		b = a.bulk(index) // the bulk is replaced if its documents were skipped
+	}
+	b.size += size
+	b.service.Add(bulkRequest)
+	b.pending = append(b.pending, msg.Data)
+	if a.hashField != "" {
+		if id := a.docID(msg); id != "" {
+			b.hashes[id] = a.msgHash(msg)
+		}
+	}
+}
+
+// bulkRequestSize returns the number of bytes the request adds to a bulk body
+func bulkRequestSize(bulkRequest elastic.BulkableRequest) int {
+	size := 0
 	source, err := bulkRequest.Source()
 	if err == nil {
 		for _, line := range source {
-			a.bulkBodySize += len(fmt.Sprintf("%s\n", line))
+			size += len(line) + 1
 		}
 	}
+	return size
 }
 
 type AppbaseConfig struct {
-	URI       string `json:"uri" doc:"the uri to connect to, in the form https://scalr.api.appbase.io`
-	UserName  string `json:"username" doc:"appbase application username`
-	Password  string `json:"password" doc:"appbase application password`
-	Namespace string `json:"namespace" doc:"appbase application name and type to write"`
-	Debug     bool   `json:"debug" doc:"display debug information"`
-	BulkSize  int    `json:"bulksize" doc:"Define the size of the buffer to bulk operations"`
+	URI       string          `json:"uri" doc:"the uri to connect to, in the form https://scalr.api.appbase.io"`
+	UserName  string          `json:"username" doc:"appbase application username"`
+	Password  string          `json:"password" doc:"appbase application password"`
+	Namespace string          `json:"namespace" doc:"appbase application name and type to write"`
+	Debug     bool            `json:"debug" doc:"display debug information"`
+	BulkSize  int             `json:"bulksize" doc:"Define the size of the buffer to bulk operations"`
+	Mapping   []NamespaceRule `json:"mapping" doc:"rules translating source namespaces into the appbase index to write to"`
+	Breaker   *BreakerConfig  `json:"breaker,omitempty" doc:"circuit breaker options, when set failed writes are retried until the breaker opens, then they and the writes it turns away go to onerror, which defaults to skip"`
+
+	DataStream      bool `json:"datastream" doc:"write to a data stream, inserts are sent as create actions and updates and deletes are rejected"`
+	InjectTimestamp bool `json:"injecttimestamp" doc:"when writing to a data stream, set a missing @timestamp from the message timestamp"`
+
+	CompressRequests bool `json:"compressrequests" doc:"gzip the body of bulk requests, falling back to uncompressed requests if the server doesn't accept them"`
+
+	Refresh interface{} `json:"refresh" doc:"whether each bulk refreshes the index so its documents are searchable at once, false (the default, for throughput), true or wait_for"`
+
+	Version      string `json:"version" doc:"write documents with external versions, so stale changes arriving out of order are skipped, oplog (the timestamp of the mongo change) or field (the value of versionfield)"`
+	VersionField string `json:"versionfield" doc:"the dotted path of the number or date field holding the document's version, when the version is field"`
+
+	MaxInflightBatches int `json:"maxinflightbatches" doc:"the most bulk requests, each for a different index, waiting on the cluster at once, defaults to 4"`
+
+	Reindex *ReindexConfig `json:"reindex,omitempty" doc:"write to a new index, and move an alias to it once the backfill is complete"`
+
+	SkipUnchanged string `json:"skipunchanged" doc:"the field holding the content hash set by the contenthash transformer, inserts and updates whose hash matches the stored document's are skipped, at the cost of reading the stored hash"`
+
+	IndexField   string `json:"indexfield" doc:"the dotted path of a field holding the index to write each document to, i.e. tenant_id, deletes carrying only an _id don't have it"`
+	InvalidIndex string `json:"invalidindex" doc:"what to do when the index field is missing or isn't a valid index name, error (the default), sanitize (lowercase it and replace the characters that aren't allowed) or default (write to the namespace's index)"`
+
+	SoftDelete *SoftDeleteMarkConfig `json:"softdelete,omitempty" doc:"mark deleted documents with a field, rather than removing them"`
+
+	RetryOnConflict int `json:"retryonconflict" doc:"how many times the cluster retries an update that conflicts with a concurrent write, defaults to 0"`
+
+	DefaultAction  string `json:"defaultaction" doc:"the bulk action for inserts, index (the default) overwrites an existing document, create fails if the document already exists, to catch id collisions"`
+	CreateConflict string `json:"createconflict" doc:"what to do when a create finds the document already exists, skip (count it and move on, the default) or error"`
+
+	PostFlush *PostFlushConfig `json:"postflush,omitempty" doc:"a command to run after each bulk is committed, with the index and the number of documents"`
+
+	Pipeline *string `json:"pipeline,omitempty" doc:"the ingest pipeline the cluster runs each indexed document through, to enrich or transform it server side"`
+
+	MaxFailureRatio float64 `json:"maxfailureratio" doc:"when set, the share of a bulk's documents, between 0 and 1, that can fail before the whole bulk is handled by onerror, failures below it are reported, or dead lettered, one by one"`
+
+	IDPrefix string `json:"idprefix" doc:"a prefix for every _id written, deleted or updated, so that sources sharing an index don't overwrite each other, which may reference {{namespace}}, {{database}} or {{collection}}, i.e. mongo:{{collection}}:"`
+
+	FlushTimeout       string `json:"flushtimeout" doc:"how long a bulk request can take before it's given up on, format must be parsable by time.ParseDuration. a timeout is a transient error, retried when the breaker is set, unset waits forever"`
+	SlowFlushThreshold string `json:"slowflushthreshold" doc:"report a warning with the duration and number of documents of any bulk request taking longer than this, format must be parsable by time.ParseDuration"`
+
+	MaxFlushesPerSecond float64 `json:"maxflushespersecond" doc:"the most bulk requests sent a second, i.e. to stay under a plan's request limit. a bulk that's due waits its turn, and adding documents waits with it, unset doesn't limit them"`
+
+	SizeHistogram bool `json:"sizehistogram" doc:"count the size of each document's bulk request in a histogram, sent with the metrics as a sizes event holding the min, max, p50, p95 and p99 sizes and the power of two buckets, to help tune bulksize and find outsized documents"`
+
+	WaitForActiveShards interface{} `json:"waitforactiveshards" doc:"the shard copies that must be active before a bulk is written, all or a number, i.e. 2 for the primary and one replica, unset uses the index's own setting"`
+}
+
+// SoftDeleteMarkConfig configures a sink's soft deletes, which mark a document deleted rather than removing
+// it, so that it stays searchable with a filter.  It's the reverse of a source's SoftDeleteConfig
+type SoftDeleteMarkConfig struct {
+	Field          string `json:"field" doc:"the field set to true on deleted documents, defaults to deleted"`
+	TimestampField string `json:"timestampfield" doc:"if set, a field to set to the time of the delete"`
+}
+
+// marks returns the partial document that marks the message's document deleted
+func (c *SoftDeleteMarkConfig) marks(msg *message.Msg) map[string]interface{} {
+	doc := map[string]interface{}{}
+	setField(doc, c.Field, true)
+	if c.TimestampField != "" {
+		setField(doc, c.TimestampField, time.Unix(msg.Timestamp, 0).UTC().Format(time.RFC3339))
+	}
+	return doc
+}
+
+// appbaseRefresh returns the refresh parameter for the configured value, which may be a bool or a string
+func appbaseRefresh(v interface{}) (string, error) {
+	switch r := v.(type) {
+	case nil:
+		return "false", nil
+	case bool:
+		return fmt.Sprintf("%t", r), nil
+	case string:
+		switch r {
+		case "":
+			return "false", nil
+		case "false", "true", "wait_for":
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown refresh (%v), must be false, true or wait_for", v)
+}
+
+// appbaseWaitForActiveShards validates the waitforactiveshards option, returning it as the bulk request's
+// wait_for_active_shards parameter, or "" when it's unset
+func appbaseWaitForActiveShards(v interface{}) (string, error) {
+	switch w := v.(type) {
+	case nil:
+		return "", nil
+	case int:
+		if w > 0 {
+			return strconv.Itoa(w), nil
+		}
+	case float64:
+		if w > 0 && w == math.Trunc(w) {
+			return strconv.Itoa(int(w)), nil
+		}
+	case string:
+		if w == "" || w == "all" {
+			return w, nil
+		}
+		if n, err := strconv.Atoi(w); err == nil && n > 0 {
+			return w, nil
+		}
+	}
+	return "", fmt.Errorf("unknown waitforactiveshards (%v), must be all or a number of shard copies above 0", v)
 }
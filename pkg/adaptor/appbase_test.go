@@ -0,0 +1,1747 @@
+//go:build !slim || appbase || opensearch
+// +build !slim appbase opensearch
+
+package adaptor
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/events"
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"github.com/olivere/elastic"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// testAppbaseCluster stands in for an appbase cluster.  It records the body of each bulk request
+// and replies to them with the configured status
+type testAppbaseCluster struct {
+	*httptest.Server
+	sync.Mutex
+
+	bulks      []string
+	times      []time.Time
+	encodings  []string
+	refreshes  []string // the refresh parameter of each bulk
+	pipelines  []string // the ingest pipeline parameter of each bulk
+	shards     []string // the wait_for_active_shards parameter of each bulk
+	status     int
+	rejectGzip bool
+	failIndex  string // bulks sent to this index fail
+
+	// the number of bulk requests to reject with 429 Too Many Requests, and the Retry-After to send with them
+	rateLimited int
+	retryAfter  string
+
+	// when set, the cluster applies externally versioned actions as elasticsearch does, keeping the version
+	// and source of each document, and rejecting stale actions with 409 Conflict
+	versions map[string]int64
+	docs     map[string]string
+
+	// when set, the ids of the documents that exist, the cluster applies create actions and rejects creates
+	// of these with 409 Conflict
+	existing map[string]bool
+
+	// when set, the ids of the documents the cluster rejects as unparseable, it applies the rest
+	rejected map[string]bool
+
+	// when set, the source of the documents the cluster serves to gets, by index/type/id, and the number of gets
+	stored map[string]string
+	gets   int
+
+	// how long each bulk takes, and the number of bulks being handled now and at most, kept outside of the
+	// lock so that concurrent bulks can be seen
+	delay     int64
+	active    int32
+	maxActive int32
+}
+
+func newTestAppbaseCluster() *testAppbaseCluster {
+	c := &testAppbaseCluster{status: http.StatusOK}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && strings.Count(strings.Trim(r.URL.Path, "/"), "/") == 2 {
+			c.get(w, strings.Trim(r.URL.Path, "/"))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+			return // health checks
+		}
+		active := atomic.AddInt32(&c.active, 1)
+		defer atomic.AddInt32(&c.active, -1)
+		for max := atomic.LoadInt32(&c.maxActive); active > max && !atomic.CompareAndSwapInt32(&c.maxActive, max, active); {
+			max = atomic.LoadInt32(&c.maxActive)
+		}
+		time.Sleep(time.Duration(atomic.LoadInt64(&c.delay)))
+
+		c.Lock()
+		defer c.Unlock()
+
+		c.refreshes = append(c.refreshes, r.URL.Query().Get("refresh"))
+		c.pipelines = append(c.pipelines, r.URL.Query().Get("pipeline"))
+		c.shards = append(c.shards, r.URL.Query().Get("wait_for_active_shards"))
+		encoding := r.Header.Get("Content-Encoding")
+		c.encodings = append(c.encodings, encoding)
+		if encoding == "gzip" && c.rejectGzip {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var body []byte
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ = ioutil.ReadAll(zr)
+		} else {
+			body, _ = ioutil.ReadAll(r.Body)
+		}
+		c.bulks = append(c.bulks, string(body))
+		c.times = append(c.times, time.Now())
+		if c.rateLimited > 0 {
+			c.rateLimited--
+			if c.retryAfter != "" {
+				w.Header().Set("Retry-After", c.retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"status":429,"error":"too many requests"}`)
+			return
+		}
+		status := c.status
+		if c.failIndex != "" && strings.HasPrefix(r.URL.Path, "/"+c.failIndex+"/") {
+			status = http.StatusInternalServerError
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			fmt.Fprintf(w, `{"status":%d,"error":"bulk failed"}`, status)
+			return
+		}
+		if c.versions != nil {
+			c.applyVersioned(w, string(body))
+			return
+		}
+		if c.existing != nil {
+			c.applyCreates(w, string(body))
+			return
+		}
+		if c.rejected != nil {
+			c.applyRejects(w, string(body))
+			return
+		}
+		fmt.Fprint(w, `{"took":1,"errors":false,"items":[]}`)
+	}))
+	return c
+}
+
+// applyVersioned applies the bulk's index and delete actions, which must carry external versions
+func (c *testAppbaseCluster) applyVersioned(w http.ResponseWriter, body string) {
+	var items []map[string]interface{}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i := 0; i < len(lines); i++ {
+		var action map[string]map[string]interface{}
+		json.Unmarshal([]byte(lines[i]), &action)
+		for op, meta := range action {
+			id := meta["_id"].(string)
+			version := int64(meta["_version"].(float64))
+			status := http.StatusOK
+			if meta["_version_type"] != "external" || version <= c.versions[id] {
+				status = http.StatusConflict
+			} else {
+				c.versions[id] = version
+			}
+			if op == "index" {
+				i++ // the document
+				if status == http.StatusOK {
+					c.docs[id] = lines[i]
+				}
+			} else if status == http.StatusOK {
+				delete(c.docs, id)
+			}
+			items = append(items, map[string]interface{}{op: map[string]interface{}{"_id": id, "status": status}})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": true, "items": items})
+}
+
+// applyCreates applies the bulk's actions, each followed by its document, so creates fail for existing documents
+func (c *testAppbaseCluster) applyCreates(w http.ResponseWriter, body string) {
+	var items []map[string]interface{}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i := 0; i < len(lines); i += 2 {
+		var action map[string]map[string]interface{}
+		json.Unmarshal([]byte(lines[i]), &action)
+		for op, meta := range action {
+			id := meta["_id"].(string)
+			status := http.StatusCreated
+			if op == "create" && c.existing[id] {
+				status = http.StatusConflict
+			}
+			c.existing[id] = true
+			items = append(items, map[string]interface{}{op: map[string]interface{}{"_id": id, "status": status}})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": true, "items": items})
+}
+
+// applyRejects applies the bulk's index actions, each followed by its document, rejecting the documents
+// whose ids are rejected
+func (c *testAppbaseCluster) applyRejects(w http.ResponseWriter, body string) {
+	var items []map[string]interface{}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i := 0; i < len(lines); i += 2 {
+		var action map[string]map[string]interface{}
+		json.Unmarshal([]byte(lines[i]), &action)
+		for op, meta := range action {
+			item := map[string]interface{}{"_id": meta["_id"], "status": http.StatusCreated}
+			if c.rejected[meta["_id"].(string)] {
+				item["status"], item["error"] = http.StatusBadRequest, "mapper_parsing_exception"
+			}
+			items = append(items, map[string]interface{}{op: item})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": true, "items": items})
+}
+
+// get serves a stored document, which is index/type/id
+func (c *testAppbaseCluster) get(w http.ResponseWriter, doc string) {
+	c.Lock()
+	defer c.Unlock()
+	c.gets++
+	parts := strings.Split(doc, "/")
+	source, ok := c.stored[doc]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"_index":%q,"_type":%q,"_id":%q,"found":false}`, parts[0], parts[1], parts[2])
+		return
+	}
+	fmt.Fprintf(w, `{"_index":%q,"_type":%q,"_id":%q,"_version":1,"found":true,"_source":%s}`, parts[0], parts[1], parts[2], source)
+}
+
+func (c *testAppbaseCluster) setStatus(status int) {
+	c.Lock()
+	defer c.Unlock()
+	c.status = status
+}
+
+func (c *testAppbaseCluster) bulkCount() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.bulks)
+}
+
+// newTestAppbase returns an Appbase adaptor writing to app/type on the given cluster, and a channel
+// that collects the errors the adaptor sends down its pipe
+func newTestAppbase(t *testing.T, cluster *testAppbaseCluster) (*Appbase, chan error) {
+	u, err := url.Parse(cluster.URL)
+	if err != nil {
+		t.Fatalf("can't parse test cluster url, %s", err)
+	}
+
+	a := &Appbase{
+		uri:       u,
+		appName:   "app",
+		typename:  "type",
+		typeMatch: regexp.MustCompile(".*"),
+		pipe:      pipe.NewPipe(nil, "path"),
+		path:      "path",
+		bulkMutex: &sync.Mutex{},
+		bulkSize:  512000,
+	}
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{}, onErrorFail)
+	if err := a.setupClient(); err != nil {
+		t.Fatalf("can't connect to test cluster, %s", err)
+	}
+
+	errs := make(chan error, 100)
+	go func(p *pipe.Pipe) {
+		for err := range p.Err {
+			errs <- err
+		}
+	}(a.pipe)
+	return a, errs
+}
+
+func TestAppbaseCircuitBreaker(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.setStatus(http.StatusServiceUnavailable)
+
+	a, _ := newTestAppbase(t, cluster)
+	now := time.Unix(0, 0)
+	a.breaker, _ = newCircuitBreaker(&BreakerConfig{Threshold: 2, Cooldown: "1m"})
+	a.breaker.now = func() time.Time { return now }
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{}, onErrorSkip) // the default with a breaker
+
+	send := func() {
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "name": "nick"}, "app.type"))
+		a.commitBulk(true)
+	}
+
+	// a failed bulk keeps its documents to retry them, and their size, so it's still committed once it's full
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "name": "nick"}, "app.type"))
+	size := a.bulk("app").size
+	a.commitBulk(true)
+	if b := a.bulk("app"); b.service.NumberOfActions() != 1 || b.size != size {
+		t.Errorf("expected the failed bulk to keep 1 action of %d bytes, got %d of %d bytes", size, b.service.NumberOfActions(), b.size)
+	}
+
+	// two failures trip the breaker without stopping the pipe
+	send()
+	if a.breaker.status() != "open" || cluster.bulkCount() != 2 {
+		t.Fatalf("expected an open breaker after 2 bulk requests, got %s after %d", a.breaker.status(), cluster.bulkCount())
+	}
+	if a.pipe.Stopped {
+		t.Errorf("expected the pipe to keep running with a breaker configured")
+	}
+
+	// while open, the cluster isn't contacted
+	send()
+	if cluster.bulkCount() != 2 {
+		t.Errorf("expected the open breaker to fast fail, but the cluster received %d bulk requests", cluster.bulkCount())
+	}
+
+	// after the cooldown a trial request is let through, and closes the breaker
+	cluster.setStatus(http.StatusOK)
+	now = now.Add(time.Minute)
+	send()
+	if a.breaker.status() != "closed" || cluster.bulkCount() != 3 {
+		t.Errorf("expected a closed breaker after 3 bulk requests, got %s after %d", a.breaker.status(), cluster.bulkCount())
+	}
+}
+
+func TestAppbaseCircuitBreakerTrial(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	atomic.StoreInt64(&cluster.delay, int64(20*time.Millisecond))
+	a, _ := newTestAppbase(t, cluster)
+	a.inflight = make(chan struct{}, 4)
+	now := time.Unix(0, 0)
+	a.breaker, _ = newCircuitBreaker(&BreakerConfig{Threshold: 1, Cooldown: "1m"})
+	a.breaker.now = func() time.Time { return now }
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{}, onErrorSkip)
+	a.breaker.failure()
+	now = now.Add(time.Minute)
+
+	// the bulks of 4 indexes are committed at once, only one of them is the half-open breaker's trial
+	for i := 0; i < 4; i++ {
+		index := fmt.Sprintf("app%d", i)
+		doc := map[string]interface{}{"_id": "1"}
+		a.addBulkRequest(index, elastic.NewBulkIndexRequest().Index(index).Type("type").Id("1").Doc(doc), message.NewMsg(message.Insert, doc, index+".type"))
+	}
+	a.commitBulk(true)
+
+	if cluster.bulkCount() != 1 || a.breaker.status() != "closed" {
+		t.Errorf("expected a single trial bulk to close the breaker, got %d bulks and a %s breaker", cluster.bulkCount(), a.breaker.status())
+	}
+}
+
+func TestAppbaseFlush(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.setStatus(http.StatusServiceUnavailable)
+	a, _ := newTestAppbase(t, cluster)
+	a.breaker, _ = newCircuitBreaker(&BreakerConfig{Threshold: 2, Cooldown: "1m"})
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{}, onErrorSkip)
+
+	// the failed document is kept to retry, so it isn't written yet
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	if err := a.Flush(); err == nil {
+		t.Errorf("expected an error while the document waits to be retried")
+	}
+
+	cluster.setStatus(http.StatusOK)
+	if err := a.Flush(); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if c := a.Counts(); c.Written != 1 {
+		t.Errorf("expected the document to be written, got %+v", c)
+	}
+}
+
+func TestAppbaseBreakerOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []struct {
+		conf    Config
+		stopped bool
+		dead    []interface{} // the documents expected in the dead letter file
+	}{
+		{Config{"onerror": "skip"}, false, nil},
+		{Config{"onerror": "deadletter", "deadletter": filepath.Join(dir, "dead.json")}, false, []interface{}{"1", "2", "3"}},
+		{Config{"onerror": "fail"}, true, nil},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		cluster.setStatus(http.StatusServiceUnavailable)
+		a, _ := newTestAppbase(t, cluster)
+		a.breaker, _ = newCircuitBreaker(&BreakerConfig{Threshold: 2, Cooldown: "1m"})
+		a.onError, _ = newErrorPolicy(a.pipe, "path", v.conf, onErrorSkip)
+		a.onError.counts = &a.sinkCounts
+
+		// the first failure is retried, the second opens the breaker and hands both documents to the policy
+		for _, id := range []string{"1", "2"} {
+			a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": id}, "app.type"))
+			a.commitBulk(true)
+		}
+		if a.pipe.Stopped != v.stopped {
+			t.Errorf("%v: expected stopped to be %t once the breaker opened", v.conf, v.stopped)
+		}
+		if v.stopped {
+			cluster.Close()
+			continue
+		}
+
+		// and while it's open, the documents it turns away go to the policy too
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "3"}, "app.type"))
+		a.commitBulk(true)
+		cluster.Close()
+
+		if n := a.bulk("app").service.NumberOfActions(); n != 0 {
+			t.Errorf("%v: expected the failed documents to be discarded, %d actions are pending", v.conf, n)
+		}
+		if c := a.Counts(); c.Failed != 3 || c.Written != 0 {
+			t.Errorf("%v: expected 3 failed documents, got %+v", v.conf, c)
+		}
+		if v.dead == nil {
+			continue
+		}
+		ba, err := ioutil.ReadFile(v.conf.GetString("deadletter"))
+		if err != nil {
+			t.Fatalf("%v: can't read the dead letter file, %s", v.conf, err)
+		}
+		var ids []interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(ba)), "\n") {
+			var record deadLetterRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("%v: can't decode the dead letter file, %s", v.conf, err)
+			}
+			ids = append(ids, record.Doc.(map[string]interface{})["_id"])
+		}
+		if !reflect.DeepEqual(ids, v.dead) {
+			t.Errorf("%v: expected documents %v in the dead letter file, got %v", v.conf, v.dead, ids)
+		}
+	}
+
+	// a breaker makes skip the default, as it did before the breaker's drops went to the policy
+	for conf, expected := range map[string]string{"": onErrorFail, "breaker": onErrorSkip} {
+		c := Config{"namespace": "app.type", "username": "u", "password": "p"}
+		if conf != "" {
+			c[conf] = map[string]interface{}{"threshold": 2}
+		}
+		a, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", c)
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", c, err)
+		}
+		if action := a.(*Appbase).onError.action; action != expected {
+			t.Errorf("%v: expected onerror to default to %s, got %s", c, expected, action)
+		}
+	}
+}
+
+func TestAppbaseStopsWithoutBreaker(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.setStatus(http.StatusServiceUnavailable)
+
+	a, errs := newTestAppbase(t, cluster)
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	a.commitBulk(true)
+
+	if !a.pipe.Stopped {
+		t.Errorf("expected a failed bulk request to stop the pipe")
+	}
+	if err := <-errs; err.(Error).Lvl != CRITICAL {
+		t.Errorf("expected a CRITICAL error, got %v", err)
+	}
+}
+
+func TestAppbaseDataStream(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+
+	data := []struct {
+		inject bool
+		in     *message.Msg
+		action string // the expected bulk action line, or empty if the message is rejected
+		doc    string
+	}{
+		{
+			false,
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "@timestamp": "2015-09-01T00:00:00Z"}, "app.type"),
+			`{"create":{"_id":"1","_index":"app"}}`,
+			`{"@timestamp":"2015-09-01T00:00:00Z","_id":"1"}`,
+		},
+		{
+			false,
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "2"}, "app.type"),
+			"",
+			"",
+		},
+		{
+			true,
+			&message.Msg{Op: message.Insert, Data: map[string]interface{}{"_id": "3"}, Namespace: "app.type", Timestamp: 1441065600},
+			`{"create":{"_id":"3","_index":"app"}}`,
+			`{"@timestamp":"2015-09-01T00:00:00Z","_id":"3"}`,
+		},
+		{
+			true,
+			message.NewMsg(message.Update, map[string]interface{}{"_id": "4", "@timestamp": "2015-09-01T00:00:00Z"}, "app.type"),
+			"",
+			"",
+		},
+		{
+			true,
+			message.NewMsg(message.Delete, map[string]interface{}{"_id": "5"}, "app.type"),
+			"",
+			"",
+		},
+	}
+
+	for _, v := range data {
+		a, errs := newTestAppbase(t, cluster)
+		a.dataStream = true
+		a.injectTimestamp = v.inject
+		a.resetBulk()
+		before := cluster.bulkCount()
+
+		a.addBulkCommand(v.in)
+		a.commitBulk(true)
+
+		if v.action == "" {
+			select {
+			case err := <-errs:
+				if err.(Error).Lvl != ERROR {
+					t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("%+v: expected the message to be rejected", v.in)
+			}
+			if cluster.bulkCount() != before {
+				t.Errorf("%+v: expected no bulk request for a rejected message", v.in)
+			}
+			continue
+		}
+
+		cluster.Lock()
+		body := cluster.bulks[len(cluster.bulks)-1]
+		cluster.Unlock()
+		if expected := v.action + "\n" + v.doc + "\n"; body != expected {
+			t.Errorf("%+v: expected bulk body\n%s\ngot\n%s", v.in, expected, body)
+		}
+	}
+}
+
+func TestAppbaseFlushesBeforeOversizedDocument(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+
+	a, _ := newTestAppbase(t, cluster)
+	a.bulkSize = 200
+
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "name": "nick"}, "app.type"))
+	if cluster.bulkCount() != 0 {
+		t.Fatalf("expected a small document to be buffered, got %d bulk requests", cluster.bulkCount())
+	}
+
+	// the large document doesn't fit in what's left of the batch, so the batch is flushed first
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "2", "name": strings.Repeat("x", 180)}, "app.type"))
+	if cluster.bulkCount() < 1 {
+		t.Fatalf("expected the pending batch to be flushed before the large document was added")
+	}
+
+	cluster.Lock()
+	first := cluster.bulks[0]
+	cluster.Unlock()
+	if strings.Contains(first, `"_id":"2"`) || !strings.Contains(first, `"_id":"1"`) {
+		t.Errorf("expected the first bulk to hold only the small document, got\n%s", first)
+	}
+
+	a.commitBulk(true)
+	cluster.Lock()
+	defer cluster.Unlock()
+	if len(cluster.bulks) != 2 || !strings.Contains(cluster.bulks[1], `"_id":"2"`) {
+		t.Errorf("expected the large document to be sent in its own bulk, got %v", cluster.bulks)
+	}
+}
+
+func TestAppbaseCompressRequests(t *testing.T) {
+	data := []struct {
+		compress   bool
+		rejectGzip bool
+		encodings  []string
+		warning    bool
+	}{
+		{false, false, []string{"", ""}, false},
+		{true, false, []string{"gzip", "gzip"}, false},
+		// the first request is retried uncompressed, after which compression is off
+		{true, true, []string{"gzip", "", ""}, true},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		cluster.rejectGzip = v.rejectGzip
+
+		a, errs := newTestAppbase(t, cluster)
+		a.compress = v.compress
+		if err := a.setupClient(); err != nil {
+			t.Fatalf("can't connect to test cluster, %s", err)
+		}
+		for i := 0; i < 2; i++ {
+			a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": fmt.Sprintf("%d", i), "name": "nick"}, "app.type"))
+			a.commitBulk(true)
+		}
+
+		cluster.Lock()
+		if !reflect.DeepEqual(cluster.encodings, v.encodings) {
+			t.Errorf("compress %v, reject %v: expected encodings %q, got %q", v.compress, v.rejectGzip, v.encodings, cluster.encodings)
+		}
+		for i, bulk := range cluster.bulks {
+			if !strings.Contains(bulk, fmt.Sprintf(`"_id":"%d"`, i)) {
+				t.Errorf("compress %v, reject %v: bulk %d doesn't contain its document, %q", v.compress, v.rejectGzip, i, bulk)
+			}
+		}
+		cluster.Unlock()
+
+		if v.warning {
+			select {
+			case err := <-errs:
+				if err.(Error).Lvl != WARNING {
+					t.Errorf("compress %v, reject %v: expected a WARNING about the fallback, got %v", v.compress, v.rejectGzip, err)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("compress %v, reject %v: expected a warning about the fallback", v.compress, v.rejectGzip)
+			}
+		}
+		select {
+		case err := <-errs:
+			t.Errorf("compress %v, reject %v: unexpected error %v", v.compress, v.rejectGzip, err)
+		default:
+		}
+		cluster.Close()
+	}
+}
+
+func TestAppbaseRefresh(t *testing.T) {
+	data := []struct {
+		refresh  interface{}
+		expected string
+	}{
+		{nil, "false"},
+		{false, "false"},
+		{true, "true"},
+		{"true", "true"},
+		{"wait_for", "wait_for"},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		a, _ := newTestAppbase(t, cluster)
+		var err error
+		if a.refresh, err = appbaseRefresh(v.refresh); err != nil {
+			t.Fatalf("[%v] unexpected error, %s", v.refresh, err)
+		}
+		if err := a.setupClient(); err != nil {
+			t.Fatalf("can't connect to test cluster, %s", err)
+		}
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+		a.commitBulk(true)
+
+		cluster.Lock()
+		if len(cluster.refreshes) != 1 || cluster.refreshes[0] != v.expected {
+			t.Errorf("[%v] expected the bulk to be sent with refresh=%s, got %q", v.refresh, v.expected, cluster.refreshes)
+		}
+		cluster.Unlock()
+		cluster.Close()
+	}
+
+	for _, refresh := range []interface{}{"now", 1.0} {
+		if _, err := appbaseRefresh(refresh); err == nil {
+			t.Errorf("[%v] expected an error", refresh)
+		}
+	}
+}
+
+func TestAppbasePipeline(t *testing.T) {
+	data := []struct {
+		pipeline, refresh string
+	}{
+		{"", "false"},
+		{"geoip", "false"},
+		{"geoip", "wait_for"},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		a, _ := newTestAppbase(t, cluster)
+		a.pipeline, a.refresh = v.pipeline, v.refresh
+		if err := a.setupClient(); err != nil {
+			t.Fatalf("can't connect to test cluster, %s", err)
+		}
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+		a.commitBulk(true)
+
+		cluster.Lock()
+		if len(cluster.pipelines) != 1 || cluster.pipelines[0] != v.pipeline || cluster.refreshes[0] != v.refresh {
+			t.Errorf("%+v: expected the bulk to be sent with pipeline=%s and refresh=%s, got %q and %q", v, v.pipeline, v.refresh, cluster.pipelines, cluster.refreshes)
+		}
+		cluster.Unlock()
+		cluster.Close()
+	}
+}
+
+func TestAppbaseWaitForActiveShards(t *testing.T) {
+	data := []struct {
+		conf   interface{}
+		shards string
+	}{
+		{nil, ""},
+		{"all", "all"},
+		{float64(2), "2"},
+		{"3", "3"},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		a, _ := newTestAppbase(t, cluster)
+		shards, err := appbaseWaitForActiveShards(v.conf)
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		a.waitForActiveShards = shards
+		if err := a.setupClient(); err != nil {
+			t.Fatalf("can't connect to test cluster, %s", err)
+		}
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+		a.commitBulk(true)
+
+		cluster.Lock()
+		if len(cluster.shards) != 1 || cluster.shards[0] != v.shards {
+			t.Errorf("%v: expected the bulk to be sent with wait_for_active_shards=%s, got %q", v.conf, v.shards, cluster.shards)
+		}
+		cluster.Unlock()
+		cluster.Close()
+	}
+}
+
+func TestAppbaseRateLimited(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.rateLimited = 1
+	cluster.retryAfter = "1"
+
+	a, errs := newTestAppbase(t, cluster)
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "name": "nick"}, "app.type"))
+	a.commitBulk(true)
+
+	if a.pipe.Stopped {
+		t.Errorf("expected a rate limited bulk not to stop the pipe")
+	}
+	cluster.Lock()
+	defer cluster.Unlock()
+	if len(cluster.bulks) != 2 || cluster.bulks[0] != cluster.bulks[1] {
+		t.Fatalf("expected the rate limited bulk to be retried, got %q", cluster.bulks)
+	}
+	if elapsed := cluster.times[1].Sub(cluster.times[0]); elapsed < time.Second {
+		t.Errorf("expected the retry to wait for the Retry-After, it came after %s", elapsed)
+	}
+	if n := a.bulk("app").service.NumberOfActions(); n != 0 {
+		t.Errorf("expected the retried bulk to be sent, %d actions are pending", n)
+	}
+
+	select {
+	case err := <-errs:
+		if e := err.(Error); e.Lvl != WARNING || !strings.Contains(e.Error(), "1s") {
+			t.Errorf("expected a WARNING with the backoff, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected a warning about the rate limit")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)
+	data := []struct {
+		in  string
+		out time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{" 30 ", 30 * time.Second},
+		{"-1", 0},
+		{"Fri, 14 Jul 2017 02:40:10 GMT", 10 * time.Second},
+		{"Fri, 14 Jul 2017 02:39:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, v := range data {
+		if out := parseRetryAfter(v.in, now); out != v.out {
+			t.Errorf("%q: expected %s, got %s", v.in, v.out, out)
+		}
+	}
+}
+
+func TestAppbaseOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []struct {
+		conf    Config
+		stopped bool
+		lvl     ErrorLevel
+		dead    int // the number of documents expected in the dead letter file
+	}{
+		{Config{}, true, CRITICAL, 0},
+		{Config{"onerror": "fail"}, true, CRITICAL, 0},
+		{Config{"onerror": "skip"}, false, ERROR, 0},
+		{Config{"onerror": "deadletter", "deadletter": filepath.Join(dir, "dead.json")}, false, WARNING, 2},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		cluster.setStatus(http.StatusBadRequest)
+
+		a, errs := newTestAppbase(t, cluster)
+		a.onError, err = newErrorPolicy(a.pipe, "path", v.conf, onErrorFail)
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "2"}, "app.type"))
+		a.commitBulk(true)
+		cluster.Close()
+
+		if a.pipe.Stopped != v.stopped {
+			t.Errorf("%v: expected stopped to be %t", v.conf, v.stopped)
+		}
+		select {
+		case err := <-errs:
+			if err.(Error).Lvl != v.lvl {
+				t.Errorf("%v: expected a %d level error, got %v", v.conf, v.lvl, err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%v: expected an error", v.conf)
+		}
+		if n := a.bulk("app").service.NumberOfActions(); !v.stopped && n != 0 {
+			t.Errorf("%v: expected the failed bulk to be discarded, %d actions are pending", v.conf, n)
+		}
+
+		if v.dead == 0 {
+			continue
+		}
+		f, err := os.Open(v.conf.GetString("deadletter"))
+		if err != nil {
+			t.Fatalf("%v: can't open the dead letter file, %s", v.conf, err)
+		}
+		dec := json.NewDecoder(f)
+		var ids []interface{}
+		for dec.More() {
+			var record deadLetterRecord
+			if err := dec.Decode(&record); err != nil {
+				t.Fatalf("%v: can't decode the dead letter file, %s", v.conf, err)
+			}
+			if record.Path != "path" || !strings.Contains(record.Error, "appbase error") {
+				t.Errorf("%v: unexpected dead letter record %+v", v.conf, record)
+			}
+			ids = append(ids, record.Doc.(map[string]interface{})["_id"])
+		}
+		f.Close()
+		if !reflect.DeepEqual(ids, []interface{}{"1", "2"}) {
+			t.Errorf("%v: expected documents 1 and 2 in the dead letter file, got %v", v.conf, ids)
+		}
+	}
+}
+
+func TestAppbaseIndexIsolation(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.failIndex = "logs-2024.01.01"
+
+	a, errs := newTestAppbase(t, cluster)
+	a.mapping, _ = newNamespaceMapping([]NamespaceRule{{From: "/^app\\.(.*)$/", To: "logs-$1"}})
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{"onerror": "skip"}, onErrorFail)
+
+	for i, day := range []string{"2024.01.01", "2024.01.02", "2024.01.01", "2024.01.02"} {
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": fmt.Sprintf("%d", i)}, "app."+day))
+	}
+	a.commitBulk(true)
+
+	// the failure is reported for its own index, and the other index's documents are written
+	select {
+	case err := <-errs:
+		if err.(Error).Lvl != ERROR || !strings.Contains(err.Error(), "logs-2024.01.01") {
+			t.Errorf("expected an ERROR for logs-2024.01.01, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected an error")
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("expected a single error, got %v", err)
+	default:
+	}
+
+	cluster.Lock()
+	defer cluster.Unlock()
+	if len(cluster.bulks) != 2 {
+		t.Fatalf("expected a bulk for each index, got %v", cluster.bulks)
+	}
+	written := cluster.bulks[1]
+	if !strings.Contains(written, `"_id":"1"`) || !strings.Contains(written, `"_id":"3"`) || strings.Contains(written, `"_id":"0"`) {
+		t.Errorf("expected the logs-2024.01.02 bulk to hold only its own documents, got\n%s", written)
+	}
+	if a.counts["logs-2024.01.02"] != 2 || a.counts["logs-2024.01.01"] != 0 {
+		t.Errorf("expected 2 documents sent to logs-2024.01.02 and none to logs-2024.01.01, got %v", a.counts)
+	}
+}
+
+func TestAppbaseIndexField(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+
+	a, errs := newTestAppbase(t, cluster)
+	a.indexField, _ = newIndexField("tenant_id", "")
+
+	data := []map[string]interface{}{
+		{"_id": "1", "tenant_id": "acme"},
+		{"_id": "2", "tenant_id": "globex"},
+		{"_id": "3", "tenant_id": "acme"},
+		{"_id": "4"},
+		{"_id": "5", "tenant_id": "Globex"},
+	}
+	for _, doc := range data {
+		a.addBulkCommand(message.NewMsg(message.Insert, doc, "app.type"))
+	}
+	a.commitBulk(true)
+
+	// documents without a valid index aren't written
+	for _, id := range []string{"4", "5"} {
+		select {
+		case err := <-errs:
+			if err.(Error).Lvl != ERROR || CategoryOf(err) != PERMANENT {
+				t.Errorf("%s: expected a PERMANENT ERROR, got %v", id, err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: expected an error", id)
+		}
+	}
+
+	cluster.Lock()
+	defer cluster.Unlock()
+	if len(cluster.bulks) != 2 {
+		t.Fatalf("expected a bulk for each tenant, got %v", cluster.bulks)
+	}
+	acme, globex := cluster.bulks[0], cluster.bulks[1]
+	if strings.Count(acme, `"_index":"acme"`) != 2 || !strings.Contains(acme, `"_id":"1"`) || !strings.Contains(acme, `"_id":"3"`) {
+		t.Errorf("expected documents 1 and 3 in acme, got\n%s", acme)
+	}
+	if strings.Count(globex, `"_index":"globex"`) != 1 || !strings.Contains(globex, `"_id":"2"`) {
+		t.Errorf("expected document 2 in globex, got\n%s", globex)
+	}
+	if a.counts["acme"] != 2 || a.counts["globex"] != 1 || a.counts["app"] != 0 {
+		t.Errorf("expected 2 documents sent to acme and 1 to globex, got %v", a.counts)
+	}
+}
+
+func TestAppbaseSkipUnchanged(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.stored = map[string]string{
+		"app/type/1": `{"content_hash":"h1"}`,
+		"app/type/2": `{"content_hash":"old"}`,
+	}
+
+	a, errs := newTestAppbase(t, cluster)
+	a.hashField = "content_hash"
+
+	data := []*message.Msg{
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "content_hash": "h1"}, "app.type"), // stored as it is
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "2", "content_hash": "h2"}, "app.type"), // changed
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "3", "content_hash": "h3"}, "app.type"), // not stored
+		message.NewMsg(message.Update, map[string]interface{}{"_id": "3", "content_hash": "h3"}, "app.type"), // as it is in the bulk
+		message.NewMsg(message.Delete, map[string]interface{}{"_id": "3"}, "app.type"),
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "3", "content_hash": "h3"}, "app.type"), // deleted in the bulk
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "4"}, "app.type"),                       // no hash
+	}
+	for _, msg := range data {
+		a.addBulkCommand(msg)
+	}
+	a.commitBulk(true)
+
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected error, %s", err)
+	default:
+	}
+
+	cluster.Lock()
+	defer cluster.Unlock()
+	if cluster.gets != 3 {
+		t.Errorf("expected the stored hashes of 1, 2 and 3 to be read, got %d gets", cluster.gets)
+	}
+	if len(cluster.bulks) != 1 {
+		t.Fatalf("expected a single bulk, got %v", cluster.bulks)
+	}
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(cluster.bulks[0]), "\n") {
+		var action map[string]map[string]interface{}
+		json.Unmarshal([]byte(line), &action)
+		for op, meta := range action {
+			if id, ok := meta["_id"].(string); ok && (op == "index" || op == "update" || op == "delete") {
+				ids = append(ids, op+" "+id)
+			}
+		}
+	}
+	expected := []string{"index 2", "index 3", "delete 3", "index 3", "index 4"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	if a.unchanged["app"] != 2 {
+		t.Errorf("expected 2 unchanged documents, got %v", a.unchanged)
+	}
+}
+
+func TestAppbaseSoftDelete(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+
+	a, _ := newTestAppbase(t, cluster)
+	a.softDelete = &SoftDeleteMarkConfig{Field: "meta.deleted", TimestampField: "deleted_at"}
+	a.pipe.OpField = "__op" // the marked document is given the op too
+
+	msg := message.NewMsg(message.Delete, map[string]interface{}{"_id": "1"}, "app.type")
+	msg.Timestamp = 1456831800
+	a.addBulkCommand(msg)
+	a.commitBulk(true)
+
+	cluster.Lock()
+	defer cluster.Unlock()
+	if len(cluster.bulks) != 1 {
+		t.Fatalf("expected a single bulk, got %v", cluster.bulks)
+	}
+	lines := strings.Split(strings.TrimSpace(cluster.bulks[0]), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected an update action and its document, got\n%s", cluster.bulks[0])
+	}
+	var action map[string]map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &action)
+	if meta, ok := action["update"]; !ok || meta["_id"] != "1" {
+		t.Errorf("expected an update of 1, got %s", lines[0])
+	}
+	var body map[string]interface{}
+	json.Unmarshal([]byte(lines[1]), &body)
+	expected := map[string]interface{}{"doc": map[string]interface{}{"meta": map[string]interface{}{"deleted": true}, "deleted_at": "2016-03-01T11:30:00Z", "__op": "delete"}}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected %v, got %v", expected, body)
+	}
+}
+
+func TestAppbaseRetryOnConflict(t *testing.T) {
+	for _, retries := range []int{0, 3} {
+		a := &Appbase{typename: "type", retryOnConflict: retries, softDelete: &SoftDeleteMarkConfig{Field: "deleted"}}
+		requests := map[string]interface{}{
+			"update":     a.updateRequest("app", "1").Doc(map[string]interface{}{"a": 1}),
+			"softdelete": a.updateRequest("app", "1").Doc(a.softDelete.marks(message.NewMsg(message.Delete, map[string]interface{}{"_id": "1"}, "app.type"))),
+		}
+		for kind, r := range requests {
+			source, err := r.(elastic.BulkableRequest).Source()
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			var action map[string]map[string]interface{}
+			json.Unmarshal([]byte(source[0]), &action)
+			v, ok := action["update"]["_retry_on_conflict"]
+			if retries == 0 && ok {
+				t.Errorf("%s: expected no _retry_on_conflict by default, got %s", kind, source[0])
+			}
+			if retries > 0 && v != float64(retries) {
+				t.Errorf("%s: expected _retry_on_conflict %d, got %s", kind, retries, source[0])
+			}
+		}
+	}
+
+	if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "u", "password": "p", "retryonconflict": -1}); CategoryOf(err) != CONFIG {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestAppbaseIDPrefix(t *testing.T) {
+	data := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", []string{"index 1", "update 1", "delete 1"}},
+		{"mongo:", []string{"index mongo:1", "update mongo:1", "delete mongo:1"}},
+		{"mongo:{{collection}}:", []string{"index mongo:users:1", "update mongo:users:1", "delete mongo:users:1"}},
+		{"{{ database | upper }}-{{namespace}}/", []string{"index DB-db.users/1", "update DB-db.users/1", "delete DB-db.users/1"}},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		a, _ := newTestAppbase(t, cluster)
+		var err error
+		if v.prefix != "" {
+			if a.idPrefix, err = parseIDPrefix(v.prefix); err != nil {
+				t.Fatalf("%s: unexpected error, %s", v.prefix, err)
+			}
+		}
+
+		for _, msg := range []*message.Msg{
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "a": 1}, "db.users"),
+			message.NewMsg(message.Update, map[string]interface{}{"_id": "1", "a": 2}, "db.users"),
+			message.NewMsg(message.Delete, map[string]interface{}{"_id": "1"}, "db.users"),
+		} {
+			a.addBulkCommand(msg)
+		}
+		a.commitBulk(true)
+
+		cluster.Lock()
+		var actions []string
+		for _, line := range strings.Split(strings.TrimSpace(cluster.bulks[0]), "\n") {
+			var action map[string]map[string]interface{}
+			if json.Unmarshal([]byte(line), &action) == nil {
+				for op, meta := range action {
+					if op == "index" || op == "update" || op == "delete" {
+						actions = append(actions, fmt.Sprintf("%s %v", op, meta["_id"]))
+					}
+				}
+			}
+		}
+		cluster.Unlock()
+		cluster.Close()
+		if !reflect.DeepEqual(actions, v.expected) {
+			t.Errorf("%s: expected %v, got %v", v.prefix, v.expected, actions)
+		}
+	}
+
+	for _, prefix := range []string{"{{tenant}}:", "{{collection | shout}}:"} {
+		if _, err := parseIDPrefix(prefix); err == nil {
+			t.Errorf("%s: expected an error", prefix)
+		}
+	}
+}
+
+func TestAppbaseMaxFailureRatio(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appbase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []struct {
+		ratio       float64
+		onerror     string
+		rejected    []string
+		errors      []string // the errors reported
+		deadLetters int
+		stopped     bool
+		counts      Counts
+	}{
+		// failures are ignored unless there's a ratio
+		{0, "skip", []string{"1"}, nil, 0, false, Counts{4, 0}},
+		// below it they're reported one by one
+		{0.5, "skip", []string{"1"}, []string{"ERROR appbase error (app: can't write 1, mapper_parsing_exception)"}, 0, false, Counts{3, 1}},
+		{0.5, "fail", []string{"1", "2"}, []string{"ERROR appbase error (app: can't write 1, mapper_parsing_exception)", "ERROR appbase error (app: can't write 2, mapper_parsing_exception)"}, 0, false, Counts{2, 2}},
+		{0.5, "deadletter", []string{"1"}, []string{"WARNING appbase error (app: can't write 1, mapper_parsing_exception), 1 documents sent to "}, 1, false, Counts{3, 1}},
+		// above it the whole bulk's handled by the error policy
+		{0.5, "skip", []string{"1", "2", "3"}, []string{"ERROR appbase error (app: 3 of 4 documents failed, more than the maxfailureratio of 0.5 allows, i.e. mapper_parsing_exception)"}, 0, false, Counts{0, 4}},
+		{0.5, "deadletter", []string{"1", "2", "3"}, []string{"WARNING appbase error (app: 3 of 4 documents failed, more than the maxfailureratio of 0.5 allows, i.e. mapper_parsing_exception), 4 documents sent to "}, 4, false, Counts{0, 4}},
+		{0.5, "fail", []string{"1", "2", "3"}, []string{"CRITICAL appbase error (app: 3 of 4 documents failed, more than the maxfailureratio of 0.5 allows, i.e. mapper_parsing_exception)"}, 0, true, Counts{0, 4}},
+	}
+
+	for i, v := range data {
+		cluster := newTestAppbaseCluster()
+		cluster.rejected = make(map[string]bool)
+		for _, id := range v.rejected {
+			cluster.rejected[id] = true
+		}
+		a, errs := newTestAppbase(t, cluster)
+		a.maxFailureRatio = v.ratio
+		deadLetter := filepath.Join(dir, fmt.Sprintf("deadletter%d.json", i))
+		a.onError, _ = newErrorPolicy(a.pipe, "path", Config{"onerror": v.onerror, "deadletter": deadLetter}, onErrorFail)
+		a.onError.counts = &a.sinkCounts
+
+		for _, id := range []string{"1", "2", "3", "4"} {
+			a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": id}, "app.type"))
+		}
+		a.commitBulk(true)
+		cluster.Close()
+
+		var reported []string
+		for done := false; !done; {
+			select {
+			case err := <-errs:
+				e := err.(Error)
+				reported = append(reported, strings.SplitAfter(fmt.Sprintf("%s %s", levelToString(e.Lvl), e.Str), "sent to ")[0])
+			case <-time.After(100 * time.Millisecond):
+				done = true
+			}
+		}
+		if !reflect.DeepEqual(reported, v.errors) {
+			t.Errorf("%+v: expected the errors %q, got %q", v, v.errors, reported)
+		}
+
+		var deadLetters int
+		if ba, err := ioutil.ReadFile(deadLetter); err == nil {
+			deadLetters = strings.Count(string(ba), "\n")
+		}
+		if deadLetters != v.deadLetters || a.pipe.Stopped != v.stopped {
+			t.Errorf("%+v: expected %d dead letters and stopped %v, got %d and %v", v, v.deadLetters, v.stopped, deadLetters, a.pipe.Stopped)
+		}
+		if counts := a.Counts(); counts != v.counts {
+			t.Errorf("%+v: expected the counts %+v, got %+v", v, v.counts, counts)
+		}
+	}
+
+	if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "user", "password": "pass", "maxfailureratio": 1.5}); CategoryOf(err) != CONFIG {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestAppbaseDefaultAction(t *testing.T) {
+	for _, policy := range []string{"skip", "error"} {
+		cluster := newTestAppbaseCluster()
+		cluster.existing = map[string]bool{"1": true}
+		a, errs := newTestAppbase(t, cluster)
+		a.defaultAction, a.createConflict = "create", policy
+
+		// an update and a delete aren't creates, only the inserts are
+		for _, msg := range []*message.Msg{
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"),
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "2"}, "app.type"),
+			message.NewMsg(message.Update, map[string]interface{}{"_id": "2", "a": 1}, "app.type"),
+			message.NewMsg(message.Insert, map[string]interface{}{"_id": "2"}, "app.type"),
+		} {
+			a.addBulkCommand(msg)
+		}
+		a.commitBulk(true)
+
+		cluster.Lock()
+		var actions []string
+		for _, line := range strings.Split(strings.TrimSpace(cluster.bulks[0]), "\n") {
+			var action map[string]map[string]interface{}
+			if json.Unmarshal([]byte(line), &action) == nil {
+				for op := range action {
+					if op == "create" || op == "index" || op == "update" || op == "delete" {
+						actions = append(actions, op)
+					}
+				}
+			}
+		}
+		cluster.Unlock()
+		if !reflect.DeepEqual(actions, []string{"create", "create", "update", "create"}) {
+			t.Errorf("%s: expected the inserts to be created, got %v", policy, actions)
+		}
+		if a.conflicts["app"] != 2 {
+			t.Errorf("%s: expected 2 conflicts, got %d", policy, a.conflicts["app"])
+		}
+
+		var reported []error
+		for done := false; !done; {
+			select {
+			case err := <-errs:
+				reported = append(reported, err)
+			case <-time.After(100 * time.Millisecond):
+				done = true
+			}
+		}
+		if policy == "skip" && len(reported) != 0 {
+			t.Errorf("skip: expected the conflicts to be skipped, got %v", reported)
+		}
+		if policy == "error" && (len(reported) != 2 || reported[0].(Error).Lvl != ERROR || !strings.Contains(reported[0].Error(), "can't create 1")) {
+			t.Errorf("error: expected an error for each conflict, got %v", reported)
+		}
+		cluster.Close()
+	}
+
+	for _, conf := range []Config{
+		{"defaultaction": "upsert"},
+		{"createconflict": "retry"},
+		{"defaultaction": "create", "version": "oplog"},
+		{"defaultaction": "create", "datastream": true},
+	} {
+		conf["namespace"], conf["username"], conf["password"] = "app.type", "u", "p"
+		if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("%v: expected a config error, got %v", conf, err)
+		}
+	}
+}
+
+func TestAppbasePostFlush(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	a, errs := newTestAppbase(t, cluster)
+	hook, out, cleanup := newTestPostFlush(t, "ok", PostFlushConfig{})
+	defer cleanup()
+	a.postFlush = hook
+	a.onError, _ = newErrorPolicy(a.pipe, "path", Config{"onerror": "skip"}, onErrorFail)
+
+	for _, id := range []string{"1", "2", "3"} {
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": id}, "app.type"))
+	}
+	a.commitBulk(true)
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "4"}, "app.type"))
+	a.commitBulk(true)
+
+	// a failed bulk isn't followed by the command
+	cluster.setStatus(http.StatusInternalServerError)
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "5"}, "app.type"))
+	a.commitBulk(true)
+	<-errs
+
+	runs := postFlushRuns(t, out)
+	if len(runs) != 2 || runs[0].Stdin.Documents != 3 || runs[1].Stdin.Documents != 1 || runs[0].Stdin.Index != "app" || runs[0].Stdin.Path != "path" {
+		t.Errorf("expected a run for each successful bulk, got %+v", runs)
+	}
+
+	// a failing command is a warning, unless it should stop the sink
+	cluster.setStatus(http.StatusOK)
+	for _, policy := range []string{"warn", "stop"} {
+		a.postFlush, _, cleanup = newTestPostFlush(t, "fail", PostFlushConfig{OnFailure: policy})
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "6"}, "app.type"))
+		a.commitBulk(true)
+		expected := WARNING
+		if policy == "stop" {
+			expected = CRITICAL
+		}
+		select {
+		case err := <-errs:
+			if err.(Error).Lvl != expected {
+				t.Errorf("%s: expected a %v, got %v", policy, expected, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: expected an error", policy)
+		}
+		if a.pipe.Stopped != (policy == "stop") {
+			t.Errorf("%s: expected the sink to be stopped only under the stop policy", policy)
+		}
+		cleanup()
+	}
+
+	if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "u", "password": "p", "postflush": map[string]interface{}{}}); CategoryOf(err) != CONFIG {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestAppbaseErrorCategories(t *testing.T) {
+	data := []struct {
+		status   int
+		expected ErrorCategory
+	}{
+		{http.StatusInternalServerError, TRANSIENT},
+		{http.StatusServiceUnavailable, TRANSIENT},
+		{http.StatusBadRequest, PERMANENT},
+	}
+
+	for _, v := range data {
+		cluster := newTestAppbaseCluster()
+		cluster.setStatus(v.status)
+		a, errs := newTestAppbase(t, cluster)
+		a.onError, _ = newErrorPolicy(a.pipe, "path", Config{"onerror": "skip"}, onErrorFail)
+
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+		a.commitBulk(true)
+		select {
+		case err := <-errs:
+			if c := CategoryOf(err); c != v.expected {
+				t.Errorf("[%d] expected a %s error, got %s (%v)", v.status, v.expected, c, err)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("[%d] expected an error", v.status)
+		}
+		cluster.Close()
+	}
+
+	// a cluster that can't be reached is transient too
+	cluster := newTestAppbaseCluster()
+	a, errs := newTestAppbase(t, cluster)
+	cluster.Close()
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	a.commitBulk(true)
+	select {
+	case err := <-errs:
+		if c := CategoryOf(err); c != TRANSIENT || err.(Error).Lvl != CRITICAL {
+			t.Errorf("expected a CRITICAL transient error, got %s (%v)", c, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected an error")
+	}
+
+	for _, conf := range []Config{
+		{"namespace": "app.type"},
+		{"namespace": "app.type", "username": "user", "password": "pass", "refresh": "now"},
+		{"namespace": "app.type", "username": "user", "password": "pass", "pipeline": " "},
+		{"namespace": "app.type", "username": "user", "password": "pass", "waitforactiveshards": "some"},
+		{"namespace": "app.type", "username": "user", "password": "pass", "waitforactiveshards": 0},
+		{"namespace": "app.type", "username": "user", "password": "pass", "waitforactiveshards": 1.5},
+		{"namespace": "app.type", "username": "user", "password": "pass", "mapping": []interface{}{map[string]interface{}{"from": "/(/", "to": "x"}}},
+		{"namespace": "app.type", "username": "user", "password": "pass", "indexfield": "tenant_id", "invalidindex": "skip"},
+		{"namespace": "app.type", "username": "user", "password": "pass", "version": "oplog", "softdelete": map[string]interface{}{}},
+		{"namespace": "app.type", "username": "user", "password": "pass", "indexfield": "tenant_id", "reindex": map[string]interface{}{"alias": "products"}},
+	} {
+		if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("[%v] expected a config error, got %v", conf, err)
+		}
+	}
+}
+
+func TestAppbaseVersion(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	cluster.versions = make(map[string]int64)
+	cluster.docs = make(map[string]string)
+	a, errs := newTestAppbase(t, cluster)
+	a.version = "oplog"
+
+	versioned := func(op message.OpType, doc map[string]interface{}, ts bson.MongoTimestamp) *message.Msg {
+		msg := message.NewMsg(op, doc, "app.type")
+		msg.Version = int64(ts)
+		return msg
+	}
+	// the changes to two documents, with older changes replayed after a reconnect
+	data := []*message.Msg{
+		versioned(message.Insert, map[string]interface{}{"_id": "1", "v": 1}, newMongoTimestamp(1, 0)),
+		versioned(message.Update, map[string]interface{}{"_id": "1", "v": 2}, newMongoTimestamp(1, 1)),
+		versioned(message.Insert, map[string]interface{}{"_id": "2", "v": 1}, newMongoTimestamp(2, 0)),
+		versioned(message.Delete, map[string]interface{}{"_id": "2"}, newMongoTimestamp(3, 0)),
+		versioned(message.Insert, map[string]interface{}{"_id": "1", "v": 1}, newMongoTimestamp(1, 0)),
+		versioned(message.Insert, map[string]interface{}{"_id": "2", "v": 1}, newMongoTimestamp(2, 0)),
+	}
+	for _, msg := range data {
+		a.addBulkCommand(msg)
+		a.commitBulk(true)
+	}
+
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected error, %s", err)
+	default:
+	}
+	cluster.Lock()
+	defer cluster.Unlock()
+	if doc := cluster.docs["1"]; doc != `{"_id":"1","v":2}` {
+		t.Errorf("expected the latest version of 1, got %s", doc)
+	}
+	if doc, ok := cluster.docs["2"]; ok {
+		t.Errorf("expected 2 to stay deleted, got %s", doc)
+	}
+	if a.stale["app"] != 2 {
+		t.Errorf("expected 2 stale changes, got %d", a.stale["app"])
+	}
+}
+
+func TestAppbaseVersionField(t *testing.T) {
+	a := &Appbase{version: "field", versionField: "meta.version"}
+	when := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := []struct {
+		doc      map[string]interface{}
+		expected int64 // 0 if there's no version
+	}{
+		{map[string]interface{}{"meta": map[string]interface{}{"version": 3.0}}, 3},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": int64(4)}}, 4},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": when}}, when.UnixNano()},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": "5"}}, 0},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": 0}}, 0},
+		{map[string]interface{}{"_id": 1}, 0},
+	}
+	for _, v := range data {
+		version, err := a.msgVersion(message.NewMsg(message.Insert, v.doc, "app.type"))
+		if v.expected == 0 {
+			if err == nil {
+				t.Errorf("%v: expected an error, got %d", v.doc, version)
+			}
+			continue
+		}
+		if err != nil || version != v.expected {
+			t.Errorf("%v: expected %d, got %d (%v)", v.doc, v.expected, version, err)
+		}
+	}
+
+	for _, conf := range []Config{
+		{"namespace": "app.type", "username": "u", "password": "p", "version": "ts"},
+		{"namespace": "app.type", "username": "u", "password": "p", "version": "field"},
+		{"namespace": "app.type", "username": "u", "password": "p", "version": "oplog", "datastream": true},
+	} {
+		if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", conf); err == nil {
+			t.Errorf("%v: expected an error", conf)
+		}
+	}
+}
+
+func TestAppbaseMaxInflightBatches(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	atomic.StoreInt64(&cluster.delay, int64(20*time.Millisecond))
+	a, _ := newTestAppbase(t, cluster)
+	a.inflight = make(chan struct{}, 2)
+
+	// a document for each of 8 indexes, their bulks are committed at once, 2 at a time
+	for i := 0; i < 8; i++ {
+		index := fmt.Sprintf("app%d", i)
+		doc := map[string]interface{}{"_id": "1"}
+		a.addBulkRequest(index, elastic.NewBulkIndexRequest().Index(index).Type("type").Id("1").Doc(doc), message.NewMsg(message.Insert, doc, index+".type"))
+	}
+	a.commitBulk(true)
+
+	if cluster.bulkCount() != 8 {
+		t.Errorf("expected 8 bulks, got %d", cluster.bulkCount())
+	}
+	if max := atomic.LoadInt32(&cluster.maxActive); max != 2 {
+		t.Errorf("expected 2 bulks in flight at once, got %d", max)
+	}
+	for i := 0; i < 8; i++ {
+		if n := a.bulk(fmt.Sprintf("app%d", i)).service.NumberOfActions(); n != 0 {
+			t.Errorf("app%d: expected the bulk to be committed, %d actions are pending", i, n)
+		}
+	}
+	if c := a.Counts(); c.Written != 8 {
+		t.Errorf("expected 8 documents written, got %+v", c)
+	}
+
+	if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "u", "password": "p", "maxinflightbatches": -1}); err == nil {
+		t.Errorf("expected an error")
+	}
+	a2, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "u", "password": "p"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if cap(a2.(*Appbase).inflight) != appbaseMaxInflightBatches {
+		t.Errorf("expected %d inflight batches by default, got %d", appbaseMaxInflightBatches, cap(a2.(*Appbase).inflight))
+	}
+}
+
+func TestAppbaseCheck(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	cluster := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "u" || pass != "p" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"AuthenticationException","status":401}`))
+			return
+		}
+		mu.Lock()
+		requests = append(requests, r.Method+" "+strings.Replace(r.URL.Path, "//", "/", -1))
+		mu.Unlock()
+		switch r.Method {
+		case "PUT", "POST":
+			w.Write([]byte(`{"_index":"app","_type":"type","_id":"1","_version":1,"created":true}`))
+		case "DELETE":
+			w.Write([]byte(`{"found":true,"_index":"app","_type":"type","_id":"1","_version":2}`))
+		default:
+			w.Write([]byte(`{"status":200,"name":"test","version":{"number":"1.7.0"}}`))
+		}
+	}))
+	defer cluster.Close()
+
+	data := []struct {
+		password string
+		write    bool
+		steps    int
+		failed   bool
+	}{
+		{"p", false, 2, false},
+		{"p", true, 4, false},
+		{"wrong", true, 1, true}, // the cluster refuses the client, so nothing is written
+	}
+	for _, v := range data {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		a, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"uri": cluster.URL, "namespace": "app.type", "username": "u", "password": v.password})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		steps := a.(*Appbase).Check(v.write)
+		if len(steps) != v.steps {
+			t.Errorf("%+v: expected %d steps, got %+v", v, v.steps, steps)
+			continue
+		}
+		if failed := steps[len(steps)-1].Err != nil; failed != v.failed {
+			t.Errorf("%+v: expected failed to be %v, got %+v", v, v.failed, steps)
+		} else if failed && !strings.Contains(steps[len(steps)-1].Err.Error(), "401 Unauthorized") {
+			t.Errorf("%+v: expected the failure to say why, got %s", v, steps[len(steps)-1].Err)
+		}
+		for _, step := range steps[:len(steps)-1] {
+			if step.Err != nil {
+				t.Errorf("%+v: expected only the last step to fail, got %+v", v, steps)
+			}
+		}
+
+		mu.Lock()
+		var writes []string
+		for _, r := range requests {
+			if !strings.HasPrefix(r, "GET") && !strings.HasPrefix(r, "HEAD") {
+				writes = append(writes, r)
+			}
+		}
+		mu.Unlock()
+		if v.write && !v.failed && (len(writes) != 2 || !strings.HasPrefix(writes[0], "PUT /app/type/transporter-check-") || !strings.HasPrefix(writes[1], "DELETE /app/type/transporter-check-")) {
+			t.Errorf("%+v: expected a canary to be written and deleted, got %v", v, writes)
+		}
+		if (!v.write || v.failed) && len(writes) != 0 {
+			t.Errorf("%+v: expected nothing to be written, got %v", v, writes)
+		}
+	}
+}
+
+func TestAppbaseSlowFlush(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	atomic.StoreInt64(&cluster.delay, int64(30*time.Millisecond))
+	a, errs := newTestAppbase(t, cluster)
+	a.slowFlush = 10 * time.Millisecond
+
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "2"}, "app.type"))
+	a.commitBulk(true)
+
+	select {
+	case err := <-errs:
+		if e := err.(Error); e.Lvl != WARNING || !strings.HasPrefix(e.Str, "appbase slow flush (app: 2 documents took ") {
+			t.Errorf("expected a slow flush warning, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a slow flush warning")
+	}
+	if a.counts["app"] != 2 {
+		t.Errorf("expected the slow bulk to be written, got %d documents", a.counts["app"])
+	}
+
+	// a quick flush isn't reported
+	atomic.StoreInt64(&cluster.delay, 0)
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "3"}, "app.type"))
+	a.commitBulk(true)
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected error, %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestAppbaseFlushTimeout(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	atomic.StoreInt64(&cluster.delay, int64(200*time.Millisecond))
+	a, errs := newTestAppbase(t, cluster)
+	a.flushTimeout = 20 * time.Millisecond
+	if err := a.setupClient(); err != nil {
+		t.Fatalf("can't connect to test cluster, %s", err)
+	}
+	a.breaker, _ = newCircuitBreaker(&BreakerConfig{Threshold: 5, Cooldown: "1m"})
+
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	a.commitBulk(true)
+
+	select {
+	case err := <-errs:
+		if e := err.(Error); e.Category != TRANSIENT || e.Str != "appbase error (app: 1 documents timed out after 20ms)" {
+			t.Errorf("expected a transient timeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a timeout")
+	}
+	if a.pipe.Stopped || a.counts["app"] != 0 || a.bulks["app"].service.NumberOfActions() != 1 {
+		t.Fatalf("expected the timed out bulk to be kept for a retry")
+	}
+
+	// once the cluster recovers the bulk is retried with the next commit
+	atomic.StoreInt64(&cluster.delay, 0)
+	a.commitBulk(true)
+	if a.counts["app"] != 1 || a.breaker.status() != "closed" {
+		t.Errorf("expected the retried bulk to be written, got %d documents and a %s breaker", a.counts["app"], a.breaker.status())
+	}
+
+	for _, conf := range []Config{
+		{"namespace": "app.type", "username": "u", "password": "p", "flushtimeout": "soon"},
+		{"namespace": "app.type", "username": "u", "password": "p", "slowflushthreshold": "-1s"},
+	} {
+		if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("%v: expected a CONFIG error, got %v", conf, err)
+		}
+	}
+}
+
+func TestAppbaseMaxFlushesPerSecond(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	a, _ := newTestAppbase(t, cluster)
+	a.flushRate = newTokenBucket(20, 1)
+
+	// a burst of full bulks, each committed as soon as it's full
+	a.bulkSize = 1
+	for i := 0; i < 6; i++ {
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": fmt.Sprintf("%d", i)}, "app.type"))
+	}
+	a.commitBulk(true)
+
+	cluster.Lock()
+	times := append([]time.Time(nil), cluster.times...)
+	cluster.Unlock()
+	if len(times) != 6 {
+		t.Fatalf("expected 6 bulks, got %d", len(times))
+	}
+	// at 20 a second the bulks are at least 50ms apart, so no more than 3 fit in any 100ms
+	for i := 3; i < len(times); i++ {
+		if d := times[i].Sub(times[i-3]); d < 140*time.Millisecond {
+			t.Errorf("expected bulks %d to %d to be about 150ms apart, they were %s", i-3, i, d)
+		}
+	}
+
+	if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{"namespace": "app.type", "username": "u", "password": "p", "maxflushespersecond": -1}); CategoryOf(err) != CONFIG {
+		t.Errorf("expected a CONFIG error, got %v", err)
+	}
+}
+
+func TestAppbaseSizeHistogram(t *testing.T) {
+	cluster := newTestAppbaseCluster()
+	defer cluster.Close()
+	a, _ := newTestAppbase(t, cluster)
+	a.pipe.DocSizes = events.NewSizeHistogram()
+
+	// each request is a 52 byte action line and the document, 75, 100 and 274 bytes in all
+	for _, body := range []string{"a", "abcdefghijklmnopqrstuvwxyz", strings.Repeat("x", 200)} {
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "body": body}, "app.type"))
+	}
+	a.commitBulk(true)
+
+	e := a.pipe.DocSizes.Event(12345, "path")
+	if e == nil {
+		t.Fatal("expected the sizes to be counted")
+	}
+	if e.Count != 3 || e.Min != 75 || e.Max != 274 || e.P50 != 128 || e.P99 != 274 {
+		t.Errorf("expected 3 sizes from 75 to 274, got %+v", e)
+	}
+	if expected := []events.SizeBucket{{Le: 128, Count: 2}, {Le: 512, Count: 1}}; !reflect.DeepEqual(e.Buckets, expected) {
+		t.Errorf("expected buckets %v, got %v", expected, e.Buckets)
+	}
+
+	p := pipe.NewPipe(nil, "path")
+	if _, err := NewAppbase(p, "path", Config{"namespace": "app.type", "username": "u", "password": "p", "sizehistogram": true}); err != nil || p.DocSizes == nil {
+		t.Errorf("expected sizehistogram to track the pipe's sizes, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// arithExpr is a compiled arithmetic expression over a document's numeric fields, i.e. price * quantity.
+// Expressions are parsed once, when the transformer is created, and never run any code from the config
+type arithExpr interface {
+	// eval returns the value of the expression, and whether it's a whole number computed only from whole numbers
+	eval(doc map[string]interface{}) (float64, bool, error)
+}
+
+var errDivideByZero = fmt.Errorf("division by zero")
+
+type arithNumber struct {
+	v     float64
+	whole bool
+}
+
+func (n arithNumber) eval(map[string]interface{}) (float64, bool, error) {
+	return n.v, n.whole, nil
+}
+
+type arithField string
+
+func (f arithField) eval(doc map[string]interface{}) (float64, bool, error) {
+	v, ok := getField(doc, string(f))
+	if !ok || v == nil {
+		return 0, false, fmt.Errorf("%s is missing", string(f))
+	}
+	n, whole, ok := arithValue(v)
+	if !ok {
+		return 0, false, fmt.Errorf("%s is not a number (%T)", string(f), v)
+	}
+	return n, whole, nil
+}
+
+type arithNeg struct {
+	x arithExpr
+}
+
+func (n arithNeg) eval(doc map[string]interface{}) (float64, bool, error) {
+	v, whole, err := n.x.eval(doc)
+	return -v, whole, err
+}
+
+type arithOp struct {
+	op   byte
+	l, r arithExpr
+}
+
+func (o arithOp) eval(doc map[string]interface{}) (float64, bool, error) {
+	l, lwhole, err := o.l.eval(doc)
+	if err != nil {
+		return 0, false, err
+	}
+	r, rwhole, err := o.r.eval(doc)
+	if err != nil {
+		return 0, false, err
+	}
+
+	whole := lwhole && rwhole
+	switch o.op {
+	case '+':
+		return l + r, whole, nil
+	case '-':
+		return l - r, whole, nil
+	case '*':
+		return l * r, whole, nil
+	case '/':
+		if r == 0 {
+			return 0, false, errDivideByZero
+		}
+		return l / r, false, nil // 7 / 2 is 3.5, whatever the operands
+	case '%':
+		if r == 0 {
+			return 0, false, errDivideByZero
+		}
+		return math.Mod(l, r), whole, nil
+	}
+	return 0, false, fmt.Errorf("unknown operator %c", o.op)
+}
+
+// arithValue converts the numeric types a document may hold into a float64
+func arithValue(v interface{}) (float64, bool, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true, true
+	case int32:
+		return float64(n), true, true
+	case int64:
+		return float64(n), true, true
+	case float32:
+		return float64(n), false, true
+	case float64:
+		return n, false, true
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return float64(i), true, true
+		}
+		f, err := n.Float64()
+		return f, false, err == nil
+	}
+	return 0, false, false
+}
+
+// compileArith parses an expression of numbers, dotted field paths, + - * / %, unary minus and parentheses,
+// with the usual precedence
+func compileArith(text string) (arithExpr, error) {
+	p := &arithParser{text: text}
+	expr, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.text) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.text[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+type arithParser struct {
+	text string
+	pos  int
+}
+
+func (p *arithParser) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non space character, or 0 at the end of the expression
+func (p *arithParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *arithParser) parseSum() (arithExpr, error) {
+	l, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		r, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l = arithOp{c, l, r}
+	}
+	return l, nil
+}
+
+func (p *arithParser) parseProduct() (arithExpr, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '*' || c == '/' || c == '%'; c = p.peek() {
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = arithOp{c, l, r}
+	}
+	return l, nil
+}
+
+func (p *arithParser) parseUnary() (arithExpr, error) {
+	switch c := p.peek(); {
+	case c == '-':
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return arithNeg{x}, nil
+	case c == '(':
+		p.pos++
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at %d", p.pos)
+		}
+		p.pos++
+		return x, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.text) && (p.text[p.pos] >= '0' && p.text[p.pos] <= '9' || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %s", p.text[start:p.pos])
+		}
+		return arithNumber{v, !strings.Contains(p.text[start:p.pos], ".")}, nil
+	case isArithFieldChar(c):
+		start := p.pos
+		for p.pos < len(p.text) && (isArithFieldChar(p.text[p.pos]) || p.text[p.pos] >= '0' && p.text[p.pos] <= '9' || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		return arithField(p.text[start:p.pos]), nil
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", p.text[p.pos], p.pos)
+}
+
+// isArithFieldChar returns true for the characters a field name may start with
+func isArithFieldChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$'
+}
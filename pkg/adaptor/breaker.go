@@ -0,0 +1,145 @@
+package adaptor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breakerState is the state of a circuitBreaker
+type breakerState int
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures the circuit breaker that guards a sink's writes.
+type BreakerConfig struct {
+	Threshold int    `json:"threshold" doc:"the number of consecutive failed writes that trips the breaker open"`
+	Cooldown  string `json:"cooldown" doc:"how long the breaker stays open before allowing a trial write, format must be parsable by time.ParseDuration and defaults to 30s"`
+}
+
+// circuitBreaker stops a sink from hammering a persistently failing database.
+// After threshold consecutive failures the breaker opens, and allow() returns false until
+// the cooldown has elapsed.  The breaker is then half-open, and allows a single trial write, rejecting
+// any others until it's done, a success closes the breaker again while a failure re-opens it.
+type circuitBreaker struct {
+	sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+	trial    bool // a half-open breaker's trial write is in flight
+}
+
+// newCircuitBreaker creates a circuitBreaker from the given config.
+// a nil config returns a nil breaker, which always allows writes
+func newCircuitBreaker(conf *BreakerConfig) (*circuitBreaker, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	if conf.Threshold < 1 {
+		return nil, fmt.Errorf("breaker threshold must be at least 1, got %d", conf.Threshold)
+	}
+
+	b := &circuitBreaker{threshold: conf.Threshold, cooldown: 30 * time.Second, now: time.Now}
+	if conf.Cooldown != "" {
+		cooldown, err := time.ParseDuration(conf.Cooldown)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse breaker cooldown (%s), %s", conf.Cooldown, err.Error())
+		}
+		b.cooldown = cooldown
+	}
+	return b, nil
+}
+
+// allow reports whether a write should be attempted
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.trial = true
+		return true
+	case breakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success records a successful write, and returns true if this success closed an open breaker
+func (b *circuitBreaker) success() bool {
+	if b == nil {
+		return false
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	closed := b.state != breakerClosed
+	b.trial = false
+	b.failures = 0
+	b.state = breakerClosed
+	return closed
+}
+
+// failure records a failed write, and returns true if this failure opened the breaker
+func (b *circuitBreaker) failure() bool {
+	if b == nil {
+		return false
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	b.trial = false
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		return true
+	}
+	return false
+}
+
+// status returns the current state of the breaker as a string
+func (b *circuitBreaker) status() string {
+	if b == nil {
+		return breakerClosed.String()
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	return b.state.String()
+}
@@ -0,0 +1,130 @@
+package adaptor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Unix(0, 0)
+	b, err := newCircuitBreaker(&BreakerConfig{Threshold: 3, Cooldown: "10s"})
+	if err != nil {
+		t.Fatalf("unexpected error creating breaker, %s", err)
+	}
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if b.failure() {
+			t.Errorf("breaker tripped after %d failures, expected 3", i+1)
+		}
+	}
+	if !b.allow() || b.status() != "closed" {
+		t.Fatalf("expected a closed breaker, got %s", b.status())
+	}
+
+	if !b.failure() {
+		t.Errorf("expected the third failure to trip the breaker")
+	}
+	if b.allow() || b.status() != "open" {
+		t.Fatalf("expected an open breaker, got %s", b.status())
+	}
+
+	// still cooling down
+	now = now.Add(9 * time.Second)
+	if b.allow() {
+		t.Errorf("expected the breaker to fast fail during the cooldown")
+	}
+
+	// a failed trial write re-opens the breaker
+	now = now.Add(1 * time.Second)
+	if !b.allow() || b.status() != "half-open" {
+		t.Fatalf("expected a half-open breaker, got %s", b.status())
+	}
+	if !b.failure() || b.status() != "open" {
+		t.Fatalf("expected a failed trial to re-open the breaker, got %s", b.status())
+	}
+
+	// a successful trial write closes it
+	now = now.Add(10 * time.Second)
+	if !b.allow() || b.status() != "half-open" {
+		t.Fatalf("expected a half-open breaker, got %s", b.status())
+	}
+	if !b.success() || b.status() != "closed" {
+		t.Fatalf("expected a successful trial to close the breaker, got %s", b.status())
+	}
+	if b.success() {
+		t.Errorf("expected success on a closed breaker to report no transition")
+	}
+}
+
+func TestCircuitBreakerSingleTrial(t *testing.T) {
+	now := time.Unix(0, 0)
+	b, _ := newCircuitBreaker(&BreakerConfig{Threshold: 1, Cooldown: "10s"})
+	b.now = func() time.Time { return now }
+	b.failure()
+	now = now.Add(10 * time.Second)
+
+	// two writers reach the half-open breaker at once, only one of them gets the trial
+	var (
+		wg      sync.WaitGroup
+		allowed int32
+		start   = make(chan struct{})
+	)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if b.allow() {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if allowed != 1 || b.status() != "half-open" {
+		t.Fatalf("expected a single trial through a half-open breaker, got %d and a %s breaker", allowed, b.status())
+	}
+
+	// the trial's outcome ends it, a failure re-opens the breaker until the next cooldown
+	if !b.failure() || b.allow() {
+		t.Errorf("expected a failed trial to re-open the breaker, got %s", b.status())
+	}
+	now = now.Add(10 * time.Second)
+	if !b.allow() || b.allow() {
+		t.Errorf("expected a single trial after the next cooldown")
+	}
+	if !b.success() || !b.allow() || !b.allow() {
+		t.Errorf("expected a successful trial to close the breaker, got %s", b.status())
+	}
+}
+
+func TestNewCircuitBreaker(t *testing.T) {
+	data := []struct {
+		in       *BreakerConfig
+		cooldown time.Duration
+		err      bool
+	}{
+		{&BreakerConfig{Threshold: 1}, 30 * time.Second, false},
+		{&BreakerConfig{Threshold: 5, Cooldown: "1m"}, time.Minute, false},
+		{&BreakerConfig{Threshold: 0}, 0, true},
+		{&BreakerConfig{Threshold: 5, Cooldown: "soon"}, 0, true},
+	}
+
+	for _, v := range data {
+		b, err := newCircuitBreaker(v.in)
+		if (err != nil) != v.err {
+			t.Errorf("%+v: expected error %t, got %v", v.in, v.err, err)
+			continue
+		}
+		if err == nil && b.cooldown != v.cooldown {
+			t.Errorf("%+v: expected cooldown %s, got %s", v.in, v.cooldown, b.cooldown)
+		}
+	}
+
+	if b, _ := newCircuitBreaker(nil); b != nil || !b.allow() {
+		t.Errorf("expected a nil config to produce a nil breaker that always allows writes")
+	}
+}
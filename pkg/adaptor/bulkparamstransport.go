@@ -0,0 +1,33 @@
+package adaptor
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// bulkParamsTransport is an http.RoundTripper that sets parameters on bulk requests that the elastic client
+// can't, refresh=wait_for, as the client's Refresh only takes a bool, the ingest pipeline and
+// wait_for_active_shards
+type bulkParamsTransport struct {
+	next   http.RoundTripper
+	params url.Values
+}
+
+func (t *bulkParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/_bulk") {
+		return t.next.RoundTrip(req)
+	}
+
+	// a RoundTripper mustn't change the request it's given, so the parameters are set on a copy
+	r := new(http.Request)
+	*r = *req
+	u := *req.URL
+	q := u.Query()
+	for k := range t.params {
+		q.Set(k, t.params.Get(k))
+	}
+	u.RawQuery = q.Encode()
+	r.URL = &u
+	return t.next.RoundTrip(r)
+}
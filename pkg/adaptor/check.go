@@ -0,0 +1,27 @@
+package adaptor
+
+import (
+	"strconv"
+	"time"
+)
+
+// CheckStep is one step of an adaptor's Check, Err is set if the step failed
+type CheckStep struct {
+	Name string
+	Err  error
+}
+
+// checkSteps collects the steps of a check as they're run
+type checkSteps []CheckStep
+
+// run runs fn as the named step, and returns whether it succeeded
+func (s *checkSteps) run(name string, fn func() error) bool {
+	err := fn()
+	*s = append(*s, CheckStep{Name: name, Err: err})
+	return err == nil
+}
+
+// canaryID returns a new id for a canary document, which is written and deleted by a check
+func canaryID() string {
+	return "transporter-check-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
@@ -0,0 +1,72 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewCoalesce creates a transformer that sets a field to the first of a list of fields holding a value, i.e.
+// a display_name from the name, or else the username, or else the email, for search friendly documents.
+// Missing and null fields are passed over, as are empty strings, arrays and documents, and the default is
+// the last resort
+func NewCoalesce(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf CoalesceConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Target == "" {
+		return nil, NewError(CRITICAL, path, "coalesce config must contain a target", nil)
+	}
+	if len(conf.From) == 0 {
+		return nil, NewError(CRITICAL, path, "coalesce config must contain at least one field to coalesce from", nil)
+	}
+
+	// as with the defaults transformer, whole numbers are written as integers
+	if n, ok := conf.Default.(float64); ok && n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+		conf.Default = int64(n)
+	}
+
+	c := &coalesce{
+		target:  conf.Target,
+		from:    conf.From,
+		def:     conf.Default,
+		isEmpty: (&prune{strings: true, arrays: true, objects: true}).isEmpty,
+	}
+	return newDocTransformer("coalesce", p, path, extra, c.apply)
+}
+
+// CoalesceConfig provides configuration options for the coalesce transformer
+type CoalesceConfig struct {
+	Namespace string      `json:"namespace" doc:"the set of namespaces to transform"`
+	Target    string      `json:"target" doc:"the dotted path of the field to set, i.e. display_name"`
+	From      []string    `json:"from" doc:"the dotted paths of the fields to take the value from, the first holding one wins, i.e. [name, username, email]"`
+	Default   interface{} `json:"default" doc:"the value to set when none of the fields hold one, unset leaves the target as it is"`
+}
+
+type coalesce struct {
+	target  string
+	from    []string
+	def     interface{}
+	isEmpty func(interface{}) bool
+}
+
+// apply sets the target to the first value found.  deletes are left alone, they only need the document's id
+func (c *coalesce) apply(msg *message.Msg, doc map[string]interface{}) error {
+	if msg.Op == message.Delete {
+		return nil
+	}
+
+	for _, field := range c.from {
+		if v, ok := getField(doc, field); ok && !c.isEmpty(v) {
+			return setField(doc, c.target, v)
+		}
+	}
+	if c.def != nil {
+		return setField(doc, c.target, c.def)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestCoalesce(t *testing.T) {
+	names := Config{"target": "display_name", "from": []interface{}{"name", "profile.username", "email"}}
+	withDefault := Config{"target": "display_name", "from": []interface{}{"name", "email"}, "default": "anonymous"}
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{}
+	}{
+		// the first field holding a value wins
+		{
+			names,
+			map[string]interface{}{"_id": "1", "name": "Ada", "profile": map[string]interface{}{"username": "ada"}, "email": "ada@example.com"},
+			map[string]interface{}{"_id": "1", "name": "Ada", "profile": map[string]interface{}{"username": "ada"}, "email": "ada@example.com", "display_name": "Ada"},
+		},
+		// missing, null and empty fields are passed over
+		{
+			names,
+			map[string]interface{}{"_id": "1", "name": nil, "profile": map[string]interface{}{"username": "ada"}},
+			map[string]interface{}{"_id": "1", "name": nil, "profile": map[string]interface{}{"username": "ada"}, "display_name": "ada"},
+		},
+		{
+			names,
+			map[string]interface{}{"_id": "1", "name": "", "profile": map[string]interface{}{}, "email": "ada@example.com"},
+			map[string]interface{}{"_id": "1", "name": "", "profile": map[string]interface{}{}, "email": "ada@example.com", "display_name": "ada@example.com"},
+		},
+		// without a value or a default the target is left as it is
+		{
+			names,
+			map[string]interface{}{"_id": "1", "display_name": "kept"},
+			map[string]interface{}{"_id": "1", "display_name": "kept"},
+		},
+		// the default is the last resort
+		{
+			withDefault,
+			map[string]interface{}{"_id": "1", "name": []interface{}{}},
+			map[string]interface{}{"_id": "1", "name": []interface{}{}, "display_name": "anonymous"},
+		},
+		{
+			withDefault,
+			map[string]interface{}{"_id": "1", "email": "ada@example.com"},
+			map[string]interface{}{"_id": "1", "email": "ada@example.com", "display_name": "ada@example.com"},
+		},
+		// values aren't only strings, and the target can be nested
+		{
+			Config{"target": "search.score", "from": []interface{}{"score", "rank"}, "default": 0},
+			map[string]interface{}{"_id": "1", "rank": 3, "score": false},
+			map[string]interface{}{"_id": "1", "rank": 3, "score": false, "search": map[string]interface{}{"score": false}},
+		},
+		{
+			Config{"target": "search.score", "from": []interface{}{"score", "rank"}, "default": 0},
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1", "search": map[string]interface{}{"score": int64(0)}},
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "coalesce", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.in, err)
+		}
+		if !reflect.DeepEqual(out.Map(), v.out) {
+			t.Errorf("%v: expected %v, got %v", v.in, v.out, out.Map())
+		}
+	}
+}
+
+func TestCoalesceBadConfig(t *testing.T) {
+	data := []Config{
+		{"from": []interface{}{"name"}},
+		{"target": "display_name"},
+		{"target": "display_name", "from": []interface{}{}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewCoalesce(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewCompute creates a transformer that sets numeric fields from arithmetic expressions over other fields,
+// i.e. {"total": "price * quantity"} or {"avg": "(a + b) / 2"}, without needing a javascript transformer.
+// Every expression is evaluated against the document as it arrived, so one expression can't see the output of another
+func NewCompute(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ComputeConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "compute config must contain at least one field", nil)
+	}
+
+	c := &compute{fields: make(map[string]arithExpr), missing: conf.Missing}
+	switch c.missing {
+	case "":
+		c.missing = "null"
+	case "null", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be null or error", conf.Missing), nil)
+	}
+
+	for field, text := range conf.Fields {
+		expr, err := compileArith(text)
+		if err != nil {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("can't parse expression for %s (%s)", field, err.Error()), nil)
+		}
+		c.fields[field] = expr
+	}
+
+	return newDocTransformer("compute", p, path, extra, c.apply)
+}
+
+// ComputeConfig provides configuration options for the compute transformer
+type ComputeConfig struct {
+	Namespace string            `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string]string `json:"fields" doc:"the expressions, keyed by the dotted path of the field to set. expressions use numbers, dotted field paths, + - * / %, and parentheses"`
+	Missing   string            `json:"missing" doc:"what to do when an operand is missing, null or not a number, or a divisor is zero, null (set the field to null, the default) or error"`
+}
+
+type compute struct {
+	fields  map[string]arithExpr
+	missing string
+}
+
+// apply evaluates every expression before setting any of them.  whole numbers computed from whole numbers
+// are set as integers, everything else as a float.  deletes are left alone, they only need the document's id
+func (c *compute) apply(msg *message.Msg, doc map[string]interface{}) error {
+	if msg.Op == message.Delete {
+		return nil
+	}
+
+	computed := make(map[string]interface{}, len(c.fields))
+	for field, expr := range c.fields {
+		v, whole, err := expr.eval(doc)
+		if err != nil {
+			if c.missing == "error" {
+				return fmt.Errorf("can't compute %s (%s)", field, err.Error())
+			}
+			computed[field] = nil
+			continue
+		}
+		if whole && math.Abs(v) < 1<<53 {
+			computed[field] = int64(v)
+		} else {
+			computed[field] = v
+		}
+	}
+
+	for field, v := range computed {
+		if err := setField(doc, field, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCompute(t *testing.T) {
+	data := []struct {
+		conf Config
+		op   message.OpType
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+	}{
+		{
+			// whole numbers stay whole, anything with a float or a division is a float
+			Config{"fields": map[string]interface{}{"total": "price * quantity", "count": "quantity * 2 + 1", "avg": "(a + b) / 2", "half": "quantity / 2"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "price": 2.5, "quantity": 4, "a": int64(3), "b": int32(4)},
+			map[string]interface{}{"_id": "1", "price": 2.5, "quantity": 4, "a": int64(3), "b": int32(4), "total": 10.0, "count": int64(9), "avg": 3.5, "half": 2.0},
+		},
+		{
+			// precedence, unary minus, modulo and dotted paths
+			Config{"fields": map[string]interface{}{"order.net": "-order.discount + order.price - order.price % 3 * 2"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "order": bson.M{"price": 10, "discount": 1}},
+			map[string]interface{}{"_id": "1", "order": bson.M{"price": 10, "discount": 1, "net": int64(7)}},
+		},
+		{
+			// missing, null and non numeric operands and division by zero give null by default
+			Config{"fields": map[string]interface{}{"x": "a * missing", "y": "a + b", "z": "a + c", "ratio": "a / zero"}},
+			message.Update,
+			map[string]interface{}{"_id": "1", "a": 1, "b": nil, "c": "2", "zero": 0},
+			map[string]interface{}{"_id": "1", "a": 1, "b": nil, "c": "2", "zero": 0, "x": nil, "y": nil, "z": nil, "ratio": nil},
+		},
+		{
+			// or drop the message
+			Config{"missing": "error", "fields": map[string]interface{}{"ratio": "a / zero"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "a": 1, "zero": 0},
+			nil,
+		},
+		{
+			Config{"missing": "error", "fields": map[string]interface{}{"total": "price * quantity"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "price": 2.5},
+			nil,
+		},
+		{
+			// expressions see the document as it arrived
+			Config{"fields": map[string]interface{}{"a": "a + 1", "b": "a * 10"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "a": 1},
+			map[string]interface{}{"_id": "1", "a": int64(2), "b": int64(10)},
+		},
+		{
+			// deletes are untouched
+			Config{"fields": map[string]interface{}{"total": "price * quantity"}},
+			message.Delete,
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1"},
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "compute", v.conf)
+		out, err := tr.transformOne(message.NewMsg(v.op, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestComputeBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"fields": map[string]interface{}{"total": "price *"}},
+		{"fields": map[string]interface{}{"total": "(price * quantity"}},
+		{"fields": map[string]interface{}{"total": "price quantity"}},
+		{"fields": map[string]interface{}{"total": "price ^ 2"}},
+		{"fields": map[string]interface{}{"total": "1.2.3"}},
+		{"missing": "zero", "fields": map[string]interface{}{"total": "price"}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewCompute(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package adaptor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewContentHash creates a transformer that sets a field to the sha256 of the document's content, or of the
+// configured fields, so that a sink can tell an unchanged document from a changed one.  The hash is of the
+// canonical json of the content, see contentHash, so documents that are equal hash the same whatever the
+// order of their keys
+func NewContentHash(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ContentHashConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	h := &contentHasher{fields: conf.Fields, target: conf.Target}
+	if h.target == "" {
+		h.target = "content_hash"
+	}
+	for _, field := range h.fields {
+		if field == h.target {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("the target (%s) can't be one of the hashed fields", h.target), nil)
+		}
+	}
+
+	return newDocTransformer("contenthash", p, path, extra, h.apply)
+}
+
+// ContentHashConfig provides configuration options for the contenthash transformer
+type ContentHashConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    []string `json:"fields" doc:"the dotted paths of the fields to hash, defaults to the whole document other than the target"`
+	Target    string   `json:"target" doc:"the dotted path of the field to store the hash in, defaults to content_hash"`
+}
+
+type contentHasher struct {
+	fields []string
+	target string
+}
+
+func (h *contentHasher) apply(msg *message.Msg, doc map[string]interface{}) error {
+	var content interface{}
+	if len(h.fields) == 0 {
+		// the target is left out, so a document that's been hashed before hashes the same again
+		content = withoutField(doc, strings.Split(h.target, "."))
+	} else {
+		// missing fields are left out, and a null field is null, so the two hash differently
+		selected := make(map[string]interface{}, len(h.fields))
+		for _, field := range h.fields {
+			if v, ok := getField(doc, field); ok {
+				selected[field] = v
+			}
+		}
+		content = selected
+	}
+
+	hash, err := contentHash(content)
+	if err != nil {
+		return err
+	}
+	return setField(doc, h.target, hash)
+}
+
+// contentHash returns the hex sha256 of the canonical serialization of v, which is its json with the keys of
+// every document sorted and numbers written the same whatever their type, so 1 and 1.0 hash the same.  Other
+// values are written as they are written to a json sink, i.e. object ids as their hex and dates as rfc3339
+func contentHash(v interface{}) (string, error) {
+	ba, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("can't serialize the content to hash (%s)", err.Error())
+	}
+	sum := sha256.Sum256(ba)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// withoutField returns a copy of the document without the field at the path, copying only the documents
+// on the path so that the original is left as it is.  Documents left empty are removed too, as they were
+// only there to hold the field
+func withoutField(doc map[string]interface{}, keys []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		out[k] = v
+	}
+	if len(keys) == 1 {
+		delete(out, keys[0])
+	} else if next, ok := asMap(doc[keys[0]]); ok {
+		if rest := withoutField(next, keys[1:]); len(rest) > 0 {
+			out[keys[0]] = rest
+		} else {
+			delete(out, keys[0])
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestContentHash(t *testing.T) {
+	hash := func(conf Config, doc map[string]interface{}) string {
+		tr, _ := newTestDocTransformer(t, "contenthash", conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, doc, "database.collection"))
+		if err != nil || out == nil {
+			t.Fatalf("%v: unexpected error, %v", doc, err)
+		}
+		v, _ := getField(out.Map(), conf.GetString("target"))
+		if conf.GetString("target") == "" {
+			v = out.Map()["content_hash"]
+		}
+		h, _ := v.(string)
+		if len(h) != 64 {
+			t.Fatalf("%v: expected a sha256, got %v", doc, v)
+		}
+		return h
+	}
+
+	// the same documents, with their keys in a different order, and numbers and documents of different types
+	a := map[string]interface{}{"_id": "1", "name": "a", "n": 1, "tags": []interface{}{"x", "y"}, "address": map[string]interface{}{"city": "c", "zip": "z"}}
+	b := map[string]interface{}{"address": bson.M{"zip": "z", "city": "c"}, "tags": []interface{}{"x", "y"}, "n": 1.0, "name": "a", "_id": "1"}
+	if hash(Config{}, a) != hash(Config{}, b) {
+		t.Errorf("expected identical documents to hash the same")
+	}
+
+	// a hashed document hashes the same again
+	c := map[string]interface{}{"_id": "1", "name": "a"}
+	first := hash(Config{"target": "meta.hash"}, c)
+	if again := hash(Config{"target": "meta.hash"}, c); again != first {
+		t.Errorf("expected a hashed document to hash the same, got %s and %s", first, again)
+	}
+
+	different := []map[string]interface{}{
+		{"_id": "1", "name": "b", "n": 1, "tags": []interface{}{"x", "y"}, "address": map[string]interface{}{"city": "c", "zip": "z"}},
+		{"_id": "1", "name": "a", "n": 1, "tags": []interface{}{"y", "x"}, "address": map[string]interface{}{"city": "c", "zip": "z"}}, // arrays are ordered
+		{"_id": "1", "name": "a", "n": 1, "tags": []interface{}{"x", "y"}, "address": map[string]interface{}{"city": "c"}},
+	}
+	for _, doc := range different {
+		if hash(Config{}, doc) == hash(Config{}, a) {
+			t.Errorf("%v: expected a different hash", doc)
+		}
+	}
+
+	// only the selected fields are hashed, and a missing field isn't a null one
+	fields := Config{"fields": []interface{}{"name", "address.city"}}
+	if hash(fields, a) != hash(fields, map[string]interface{}{"_id": "2", "name": "a", "address": map[string]interface{}{"city": "c"}, "other": 1}) {
+		t.Errorf("expected documents with the same selected fields to hash the same")
+	}
+	if hash(fields, map[string]interface{}{"name": "a"}) == hash(fields, map[string]interface{}{"name": "a", "address": map[string]interface{}{"city": nil}}) {
+		t.Errorf("expected a missing field to hash differently from a null one")
+	}
+}
+
+func TestContentHashLeavesNestedDocuments(t *testing.T) {
+	meta := map[string]interface{}{"hash": "old", "source": "s"}
+	doc := map[string]interface{}{"_id": "1", "meta": meta}
+	tr, _ := newTestDocTransformer(t, "contenthash", Config{"target": "meta.hash"})
+	if _, err := tr.transformOne(message.NewMsg(message.Insert, doc, "database.collection")); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if meta["source"] != "s" || meta["hash"] == "old" {
+		t.Errorf("expected only the hash to change, got %v", meta)
+	}
+}
+
+func TestContentHashBadConfig(t *testing.T) {
+	if _, err := NewContentHash(nil, "path", Config{"fields": []interface{}{"a", "content_hash"}}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,35 @@
+package adaptor
+
+import "sync/atomic"
+
+// Counts are the documents a sink has written, and those it failed to write, whether they were skipped,
+// dead lettered or stopped the sink
+type Counts struct {
+	Written int64 `json:"written"`
+	Failed  int64 `json:"failed"`
+}
+
+// Counter is implemented by the sinks that count their documents, so that tests and tooling can check how
+// many a run wrote without scraping the logs.  Counts is safe to call while the sink is running
+type Counter interface {
+	Counts() Counts
+}
+
+// sinkCounts is embedded by the sinks implementing Counter.  Failures handed to the sink's errorPolicy are
+// counted once the policy is given the counts, other failures and the writes are counted by the sink
+type sinkCounts struct {
+	written, failed int64
+}
+
+func (c *sinkCounts) wrote(n int) {
+	atomic.AddInt64(&c.written, int64(n))
+}
+
+func (c *sinkCounts) failedToWrite(n int) {
+	atomic.AddInt64(&c.failed, int64(n))
+}
+
+// Counts returns the documents written, and failed to write, so far
+func (c *sinkCounts) Counts() Counts {
+	return Counts{Written: atomic.LoadInt64(&c.written), Failed: atomic.LoadInt64(&c.failed)}
+}
@@ -0,0 +1,174 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewDedupe creates a transformer that removes repeated elements from array fields, keeping the first of each,
+// i.e. {"tags": ["a", "b", "a"]} becomes {"tags": ["a", "b"]}.  Elements are the same if they're equal, or
+// for arrays of documents, if they have the same value for the key field.  Duplicate documents can be merged
+// rather than dropped, and elements without the key, or that aren't documents, are kept, dropped or errored
+func NewDedupe(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf DedupeConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "dedupe config must contain fields", nil)
+	}
+
+	d := &dedupe{fields: conf.Fields, nonArray: conf.NonArray, merge: conf.Merge, unkeyed: conf.Unkeyed}
+	for field := range d.fields {
+		d.order = append(d.order, field)
+	}
+	sort.Strings(d.order)
+	switch d.nonArray {
+	case "":
+		d.nonArray = "pass"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown nonarray policy (%s), must be pass, drop or error", d.nonArray), nil)
+	}
+	switch d.merge {
+	case "":
+		d.merge = "first"
+	case "first", "last", "fields":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown merge (%s), must be first, last or fields", d.merge), nil)
+	}
+	switch d.unkeyed {
+	case "":
+		d.unkeyed = "keep"
+	case "keep", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unkeyed policy (%s), must be keep, drop or error", d.unkeyed), nil)
+	}
+
+	return newDocTransformer("dedupe", p, path, extra, d.apply)
+}
+
+// DedupeConfig provides configuration options for the dedupe transformer
+type DedupeConfig struct {
+	Namespace string            `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string]string `json:"fields" doc:"the dotted paths of the arrays to dedupe, each mapped to the key field that identifies the documents it holds, or to \"\" to compare whole elements"`
+	NonArray  string            `json:"nonarray" doc:"what to do when a field is set but isn't an array, pass (leave it as it is, the default), drop or error"`
+	Merge     string            `json:"merge" doc:"what's kept of documents with the same key, first (the default), last, or fields, the fields of all of them merged with later values winning. the kept document stays where the key was first seen"`
+	Unkeyed   string            `json:"unkeyed" doc:"what to do with the elements of an array deduped by a key that aren't documents or don't have the key, keep (the default), drop or error"`
+}
+
+type dedupe struct {
+	fields   map[string]string
+	order    []string // the fields, sorted so that errors are reported in the same order every time
+	nonArray string
+	merge    string // first, last or fields
+	unkeyed  string // keep, drop or error
+}
+
+func (d *dedupe) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for _, field := range d.order {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+		elems, ok := asSlice(v)
+		if !ok {
+			switch d.nonArray {
+			case "drop":
+				msg.Op = message.Noop
+				return nil
+			case "error":
+				return fmt.Errorf("%s is a %T, not an array", field, v)
+			}
+			continue
+		}
+
+		key := d.fields[field]
+		seen := make(map[string]int, len(elems)) // where each element was kept in out
+		out := make([]interface{}, 0, len(elems))
+		for _, elem := range elems {
+			id := elem
+			if key != "" {
+				m, keyed := asMap(elem)
+				if keyed {
+					id, keyed = getField(m, key)
+				}
+				if !keyed {
+					switch d.unkeyed {
+					case "drop":
+						continue
+					case "error":
+						return fmt.Errorf("%s holds an element without the %s key (%v)", field, key, elem)
+					}
+					out = append(out, elem)
+					continue
+				}
+			}
+			k := dedupeKey(id)
+			i, dup := seen[k]
+			if !dup {
+				seen[k] = len(out)
+				out = append(out, elem)
+				continue
+			}
+			switch d.merge {
+			case "last":
+				out[i] = elem
+			case "fields":
+				out[i] = mergeFields(out[i], elem)
+			}
+		}
+		if err := setField(doc, field, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeFields returns a new document holding the fields of both documents, b's winning where they both have one
+func mergeFields(a, b interface{}) map[string]interface{} {
+	am, _ := asMap(a)
+	bm, _ := asMap(b)
+	merged := make(map[string]interface{}, len(am)+len(bm))
+	for k, v := range am {
+		merged[k] = v
+	}
+	for k, v := range bm {
+		merged[k] = v
+	}
+	return merged
+}
+
+// asSlice returns the value as a []interface{} if it's an array of any kind, other than a []byte
+func asSlice(v interface{}) ([]interface{}, bool) {
+	switch s := v.(type) {
+	case []interface{}:
+		return s, true
+	case []byte:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	s := make([]interface{}, rv.Len())
+	for i := range s {
+		s[i] = rv.Index(i).Interface()
+	}
+	return s, true
+}
+
+// dedupeKey returns a string that's the same for equal values.  json sorts the keys of documents, and
+// writes numbers the same whatever their type, so 1 and 1.0 are the same
+func dedupeKey(v interface{}) string {
+	if ba, err := json.Marshal(v); err == nil {
+		return string(ba)
+	}
+	return fmt.Sprintf("%#v", v)
+}
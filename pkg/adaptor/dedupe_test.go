@@ -0,0 +1,161 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestDedupe(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			// primitives keep the first of each, in order, and numbers are equal whatever their type
+			Config{"fields": map[string]interface{}{"tags": "", "scores": ""}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"b", "a", "b", "c", "a"}, "scores": []interface{}{1, 2.0, int64(1), 2}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"b", "a", "c"}, "scores": []interface{}{1, 2.0}},
+			false,
+		},
+		{
+			// documents by a key field, documents without the key and non documents are all kept
+			Config{"fields": map[string]interface{}{"order.items": "sku"}},
+			map[string]interface{}{"_id": "1", "order": bson.M{"items": []interface{}{
+				map[string]interface{}{"sku": "x", "qty": 1},
+				bson.M{"sku": "y", "qty": 2},
+				map[string]interface{}{"sku": "x", "qty": 3},
+				map[string]interface{}{"qty": 4},
+				map[string]interface{}{"qty": 4},
+				"loose",
+			}}},
+			map[string]interface{}{"_id": "1", "order": bson.M{"items": []interface{}{
+				map[string]interface{}{"sku": "x", "qty": 1},
+				bson.M{"sku": "y", "qty": 2},
+				map[string]interface{}{"qty": 4},
+				map[string]interface{}{"qty": 4},
+				"loose",
+			}}},
+			false,
+		},
+		{
+			// the last duplicate wins, where the first was
+			Config{"merge": "last", "fields": map[string]interface{}{"tags": "id"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{
+				map[string]interface{}{"id": 1, "name": "go"},
+				map[string]interface{}{"id": 2, "name": "search"},
+				map[string]interface{}{"id": 1.0, "name": "golang"},
+			}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{
+				map[string]interface{}{"id": 1.0, "name": "golang"},
+				map[string]interface{}{"id": 2, "name": "search"},
+			}},
+			false,
+		},
+		{
+			// or the duplicates' fields are merged, later values winning
+			Config{"merge": "fields", "fields": map[string]interface{}{"tags": "id"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{
+				map[string]interface{}{"id": 1, "name": "go", "score": 1},
+				map[string]interface{}{"id": 2, "name": "search"},
+				bson.M{"id": 1, "score": 2, "lang": "en"},
+				map[string]interface{}{"id": 1, "name": "golang"},
+			}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{
+				map[string]interface{}{"id": 1, "name": "golang", "score": 2, "lang": "en"},
+				map[string]interface{}{"id": 2, "name": "search"},
+			}},
+			false,
+		},
+		{
+			// elements without the key, and non documents, can be dropped
+			Config{"unkeyed": "drop", "fields": map[string]interface{}{"tags": "id"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"name": "go"}, "loose", map[string]interface{}{"id": 1}}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{map[string]interface{}{"id": 1}}},
+			false,
+		},
+		{
+			Config{"unkeyed": "error", "fields": map[string]interface{}{"tags": "id"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{map[string]interface{}{"id": 1}, "loose"}},
+			nil,
+			true,
+		},
+		{
+			// whole documents compare by value
+			Config{"fields": map[string]interface{}{"points": ""}},
+			map[string]interface{}{"_id": "1", "points": []interface{}{map[string]interface{}{"x": 1, "y": 2}, bson.M{"y": 2, "x": 1}, map[string]interface{}{"x": 2}}},
+			map[string]interface{}{"_id": "1", "points": []interface{}{map[string]interface{}{"x": 1, "y": 2}, map[string]interface{}{"x": 2}}},
+			false,
+		},
+		{
+			// typed slices are deduped too, missing and null fields are left alone
+			Config{"fields": map[string]interface{}{"tags": "", "missing": "", "null": ""}},
+			map[string]interface{}{"_id": "1", "tags": []string{"a", "a"}, "null": nil},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a"}, "null": nil},
+			false,
+		},
+		{
+			// non arrays pass by default
+			Config{"fields": map[string]interface{}{"tags": ""}},
+			map[string]interface{}{"_id": "1", "tags": "a"},
+			map[string]interface{}{"_id": "1", "tags": "a"},
+			false,
+		},
+		{
+			Config{"nonarray": "drop", "fields": map[string]interface{}{"tags": ""}},
+			map[string]interface{}{"_id": "1", "tags": "a"},
+			nil,
+			false,
+		},
+		{
+			Config{"nonarray": "error", "fields": map[string]interface{}{"tags": ""}},
+			map[string]interface{}{"_id": "1", "tags": "a"},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "dedupe", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestDedupeBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"fields": map[string]interface{}{}},
+		{"nonarray": "skip", "fields": map[string]interface{}{"tags": ""}},
+		{"merge": "deep", "fields": map[string]interface{}{"tags": "id"}},
+		{"unkeyed": "pass", "fields": map[string]interface{}{"tags": "id"}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewDedupe(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
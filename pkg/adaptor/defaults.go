@@ -0,0 +1,70 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewDefaults creates a transformer that fills in a default value for each configured field
+// that is missing or null, so that sinks with non-nullable columns or strict mappings can
+// handle sparse documents.  Fields are dotted paths, and missing intermediate documents are created
+func NewDefaults(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf DefaultsConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "defaults config must contain at least one field", nil)
+	}
+
+	// numbers arrive as float64s from the config, whole numbers are written as integers so that
+	// they match integer columns and mappings
+	for field, v := range conf.Fields {
+		switch n := v.(type) {
+		case string, bool:
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+				conf.Fields[field] = int64(n)
+			}
+		default:
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("the default for %s must be a string, number or bool, got %T", field, v), nil)
+		}
+	}
+
+	d := &defaults{fields: conf.Fields, emptyString: conf.EmptyString}
+	return newDocTransformer("defaults", p, path, extra, d.apply)
+}
+
+// DefaultsConfig provides configuration options for the defaults transformer
+type DefaultsConfig struct {
+	Namespace   string                 `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields      map[string]interface{} `json:"fields" doc:"the default values, keyed by dotted field path"`
+	EmptyString bool                   `json:"emptystring" doc:"if true, a field holding an empty string is treated as missing"`
+}
+
+type defaults struct {
+	fields      map[string]interface{}
+	emptyString bool
+}
+
+// apply sets each missing field to its default.  deletes are left alone, they only need the document's id
+func (d *defaults) apply(msg *message.Msg, doc map[string]interface{}) error {
+	if msg.Op == message.Delete {
+		return nil
+	}
+
+	for field, value := range d.fields {
+		v, ok := getField(doc, field)
+		if ok && v != nil && !(d.emptyString && v == "") {
+			continue
+		}
+		if err := setField(doc, field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
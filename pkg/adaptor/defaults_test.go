@@ -0,0 +1,96 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestDefaults(t *testing.T) {
+	data := []struct {
+		conf Config
+		op   message.OpType
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+	}{
+		{
+			// missing and null fields get their default, typed as configured
+			Config{"fields": map[string]interface{}{"status": "new", "count": 0, "score": 1.5, "active": true}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "status": nil},
+			map[string]interface{}{"_id": "1", "status": "new", "count": int64(0), "score": 1.5, "active": true},
+		},
+		{
+			// present fields are left alone, including false, zero and empty strings
+			Config{"fields": map[string]interface{}{"status": "new", "count": 10, "active": true}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "status": "", "count": 0, "active": false},
+			map[string]interface{}{"_id": "1", "status": "", "count": 0, "active": false},
+		},
+		{
+			// empty strings count as missing when asked
+			Config{"emptystring": true, "fields": map[string]interface{}{"status": "new"}},
+			message.Update,
+			map[string]interface{}{"_id": "1", "status": ""},
+			map[string]interface{}{"_id": "1", "status": "new"},
+		},
+		{
+			// dotted paths, creating documents as needed
+			Config{"fields": map[string]interface{}{"address.country": "NZ", "meta.source.name": "legacy"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "address": bson.M{"city": "Wellington"}},
+			map[string]interface{}{"_id": "1", "address": bson.M{"city": "Wellington", "country": "NZ"}, "meta": map[string]interface{}{"source": map[string]interface{}{"name": "legacy"}}},
+		},
+		{
+			// deletes are untouched
+			Config{"fields": map[string]interface{}{"status": "new"}},
+			message.Delete,
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1"},
+		},
+		{
+			// a path through a field that isn't a document is an error
+			Config{"fields": map[string]interface{}{"address.country": "NZ"}},
+			message.Insert,
+			map[string]interface{}{"_id": "1", "address": "somewhere"},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "defaults", v.conf)
+		out, err := tr.transformOne(message.NewMsg(v.op, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestDefaultsBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "database./.*/"},
+		{"namespace": "database./.*/", "fields": map[string]interface{}{"tags": []interface{}{"a"}}},
+	}
+
+	for _, v := range data {
+		if _, err := NewDefaults(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
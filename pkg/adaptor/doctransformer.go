@@ -0,0 +1,95 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// docTransformer is the common plumbing for the declarative transformers, i.e. transformers
+// that are configured entirely in the config file and use a native go function rather than a javascript one.
+// Command messages are passed through untouched, as are messages whose data isn't a document.
+// If the transform function returns an error, an ERROR is reported and the message is dropped.
+// A transformer has either fn, which modifies the document in place, or split, which replaces it with several messages
+type docTransformer struct {
+	kind string
+
+	pipe *pipe.Pipe
+	path string
+	ns   *regexp.Regexp
+
+	fn    func(*message.Msg, map[string]interface{}) error
+	split func(*message.Msg, map[string]interface{}) ([]*message.Msg, error)
+}
+
+// newDocTransformer returns a docTransformer that applies fn to every document matching the node's namespace
+func newDocTransformer(kind string, p *pipe.Pipe, path string, extra Config, fn func(*message.Msg, map[string]interface{}) error) (*docTransformer, error) {
+	t := &docTransformer{kind: kind, pipe: p, path: path, fn: fn}
+
+	_, ns, err := extra.compileNamespace()
+	if err != nil {
+		return t, NewError(CRITICAL, path, fmt.Sprintf("can't split %s namespace (%s)", kind, err.Error()), nil)
+	}
+	t.ns = ns
+
+	return t, nil
+}
+
+// newDocSplitter returns a docTransformer that replaces each document with the messages returned by split,
+// which may be none at all
+func newDocSplitter(kind string, p *pipe.Pipe, path string, extra Config, split func(*message.Msg, map[string]interface{}) ([]*message.Msg, error)) (*docTransformer, error) {
+	t, err := newDocTransformer(kind, p, path, extra, nil)
+	t.split = split
+	return t, err
+}
+
+// Start the adaptor as a source (not implemented for transformers)
+func (t *docTransformer) Start() error {
+	return fmt.Errorf("transformers can't be used as a source")
+}
+
+// Listen starts the transformer's listener, applying the transform to each message and
+// emitting the result to this adaptor's children
+func (t *docTransformer) Listen() error {
+	return t.pipe.Listen(t.transformOne, t.ns)
+}
+
+// Stop the adaptor
+func (t *docTransformer) Stop() error {
+	t.pipe.Stop()
+	return nil
+}
+
+func (t *docTransformer) transformOne(msg *message.Msg) (*message.Msg, error) {
+	if msg.Op == message.Command || !msg.IsMap() {
+		return msg, nil
+	}
+
+	if t.split != nil {
+		return t.splitOne(msg)
+	}
+
+	if err := t.fn(msg, msg.Map()); err != nil {
+		t.pipe.Err <- NewError(ERROR, t.path, fmt.Sprintf("%s error (%s)", t.kind, err.Error()), msg.Data)
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// splitOne sends all but the last of the split messages itself, and returns the last for the pipe to send
+func (t *docTransformer) splitOne(msg *message.Msg) (*message.Msg, error) {
+	msgs, err := t.split(msg, msg.Map())
+	if err != nil {
+		t.pipe.Err <- NewError(ERROR, t.path, fmt.Sprintf("%s error (%s)", t.kind, err.Error()), msg.Data)
+		return nil, nil
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	for _, m := range msgs[:len(msgs)-1] {
+		t.pipe.Send(m)
+	}
+	return msgs[len(msgs)-1], nil
+}
@@ -0,0 +1,62 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// newTestDocTransformer creates the named declarative transformer from the given config, and returns
+// it along with a channel that collects the errors it sends down its pipe
+func newTestDocTransformer(t *testing.T, kind string, conf Config) (*docTransformer, chan error) {
+	p := pipe.NewPipe(nil, "path")
+	errs := make(chan error, 100)
+	go func(p *pipe.Pipe) {
+		for err := range p.Err {
+			errs <- err
+		}
+	}(p)
+
+	if _, ok := conf["namespace"]; !ok {
+		conf["namespace"] = "database./.*/"
+	}
+	a, err := Adaptors[kind].Constructor(p, "path", conf)
+	if err != nil {
+		t.Fatalf("unexpected error creating %s transformer, %s", kind, err)
+	}
+	return a.(*docTransformer), errs
+}
+
+func TestDocTransformerPassThrough(t *testing.T) {
+	calls := 0
+	tr, err := newDocTransformer("test", pipe.NewPipe(nil, "path"), "path", Config{"namespace": "database.collection"}, func(msg *message.Msg, doc map[string]interface{}) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	data := []*message.Msg{
+		message.NewMsg(message.Command, map[string]interface{}{"flush": true}, "database.collection"),
+		message.NewMsg(message.Insert, "not a document", "database.collection"),
+	}
+
+	for _, v := range data {
+		out, err := tr.transformOne(v)
+		if err != nil || !reflect.DeepEqual(out, v) {
+			t.Errorf("%+v: expected the message to pass through untouched, got %+v (%v)", v, out, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected the transform not to run, but it ran %d times", calls)
+	}
+}
+
+func TestDocTransformerBadNamespace(t *testing.T) {
+	if _, err := newDocTransformer("test", pipe.NewPipe(nil, "path"), "path", Config{"namespace": "nodot"}, nil); err == nil {
+		t.Errorf("expected an error for a malformed namespace")
+	}
+}
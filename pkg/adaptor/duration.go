@@ -0,0 +1,132 @@
+package adaptor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// the units a duration can be written in
+var durationUnits = map[string]time.Duration{
+	"milliseconds": time.Millisecond,
+	"seconds":      time.Second,
+	"minutes":      time.Minute,
+	"hours":        time.Hour,
+	"days":         24 * time.Hour,
+	"weeks":        7 * 24 * time.Hour,
+}
+
+// NewDuration creates a transformer that writes the time between two timestamp fields, or between a field
+// and now, into a field in the given units, i.e. order_age_days from created_at.  The timestamps are parsed
+// as the timeparse transformer parses them, and the duration is positive when from is before to
+func NewDuration(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf DurationConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.From == "" || conf.Target == "" {
+		return nil, NewError(CRITICAL, path, "duration config must contain a from and a target field", nil)
+	}
+
+	d := &duration{from: conf.From, to: conf.To, target: conf.Target, formats: conf.Formats, truncate: conf.Truncate, missing: conf.Missing, unparseable: conf.Unparseable, now: time.Now}
+	if len(d.formats) == 0 {
+		d.formats = defaultTimeFormats
+	}
+	if conf.Unit == "" {
+		conf.Unit = "seconds"
+	}
+	unit, ok := durationUnits[conf.Unit]
+	if !ok {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unit (%s), must be milliseconds, seconds, minutes, hours, days or weeks", conf.Unit), nil)
+	}
+	d.unit = unit
+	switch d.missing {
+	case "":
+		d.missing = "pass"
+	case "pass", "null", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be pass, null, drop or error", d.missing), nil)
+	}
+	switch d.unparseable {
+	case "":
+		d.unparseable = "error"
+	case "pass", "null", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unparseable policy (%s), must be error, null, pass or drop", d.unparseable), nil)
+	}
+
+	return newDocTransformer("duration", p, path, extra, d.apply)
+}
+
+// DurationConfig provides configuration options for the duration transformer.
+// formats are either go time layouts or one of epoch, epoch_seconds, epoch_millis or rfc3339
+type DurationConfig struct {
+	Namespace   string   `json:"namespace" doc:"the set of namespaces to transform"`
+	From        string   `json:"from" doc:"the dotted path of the timestamp the duration starts at, i.e. created_at"`
+	To          string   `json:"to" doc:"the dotted path of the timestamp the duration ends at, unset for now"`
+	Target      string   `json:"target" doc:"the dotted path of the field to write the duration to, i.e. order_age_days"`
+	Unit        string   `json:"unit" doc:"the unit of the duration, milliseconds, seconds (the default), minutes, hours, days or weeks"`
+	Truncate    bool     `json:"truncate" doc:"truncate the duration to a whole number of units, written as an integer"`
+	Formats     []string `json:"formats" doc:"the formats of the timestamps, tried in order, defaults to those of the timeparse transformer"`
+	Missing     string   `json:"missing" doc:"what to do when a timestamp is missing or null, pass (leave the target unset, the default), null (set the target to null), drop or error"`
+	Unparseable string   `json:"unparseable" doc:"what to do when a timestamp matches none of the formats, error (the default), null, pass or drop"`
+}
+
+type duration struct {
+	from        string
+	to          string
+	target      string
+	formats     []string
+	unit        time.Duration
+	truncate    bool
+	missing     string
+	unparseable string
+	now         func() time.Time
+}
+
+func (d *duration) apply(msg *message.Msg, doc map[string]interface{}) error {
+	from, policy, err := d.timestamp(doc, d.from)
+	if policy == "" {
+		to := d.now()
+		if d.to != "" {
+			to, policy, err = d.timestamp(doc, d.to)
+		}
+		if policy == "" {
+			return setField(doc, d.target, d.value(to.Sub(from)))
+		}
+	}
+
+	switch policy {
+	case "null":
+		return setField(doc, d.target, nil)
+	case "drop":
+		msg.Op = message.Noop
+	case "error":
+		return err
+	}
+	return nil
+}
+
+// timestamp returns the time in the field, or the policy that applies if there isn't a time there
+func (d *duration) timestamp(doc map[string]interface{}, field string) (time.Time, string, error) {
+	v, ok := getField(doc, field)
+	if !ok || v == nil {
+		return time.Time{}, d.missing, fmt.Errorf("%s is missing", field)
+	}
+	t, ok := parseTime(v, d.formats)
+	if !ok {
+		return time.Time{}, d.unparseable, fmt.Errorf("can't parse %s (%v) as a time", field, v)
+	}
+	return t, "", nil
+}
+
+// value returns the duration in units
+func (d *duration) value(elapsed time.Duration) interface{} {
+	if d.truncate {
+		return int64(elapsed / d.unit)
+	}
+	return float64(elapsed) / float64(d.unit)
+}
@@ -0,0 +1,175 @@
+package adaptor
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestDuration(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"from": "created_at", "to": "shipped_at", "target": "took"},
+			map[string]interface{}{"created_at": "2017-07-14T02:40:00Z", "shipped_at": "2017-07-14T02:41:30Z"},
+			map[string]interface{}{"created_at": "2017-07-14T02:40:00Z", "shipped_at": "2017-07-14T02:41:30Z", "took": 90.0},
+			false,
+		},
+		{
+			// timestamps in different formats, and nested fields
+			Config{"from": "order.created", "to": "order.shipped", "target": "order.days", "unit": "days"},
+			map[string]interface{}{"order": map[string]interface{}{"created": 1500000000, "shipped": "2017-07-15T14:40:00Z"}},
+			map[string]interface{}{"order": map[string]interface{}{"created": 1500000000, "shipped": "2017-07-15T14:40:00Z", "days": 1.5}},
+			false,
+		},
+		{
+			Config{"from": "created_at", "to": "shipped_at", "target": "took", "unit": "hours", "truncate": true},
+			map[string]interface{}{"created_at": int64(1500000000000), "shipped_at": time.Unix(1500000000+3*3600+59*60, 0)},
+			map[string]interface{}{"created_at": int64(1500000000000), "shipped_at": time.Unix(1500000000+3*3600+59*60, 0), "took": int64(3)},
+			false,
+		},
+		{
+			// to before from is a negative duration
+			Config{"from": "a", "to": "b", "target": "took", "unit": "milliseconds"},
+			map[string]interface{}{"a": "2017-07-14 02:40:01", "b": "2017-07-14 02:40:00"},
+			map[string]interface{}{"a": "2017-07-14 02:40:01", "b": "2017-07-14 02:40:00", "took": -1000.0},
+			false,
+		},
+		{
+			// the formats are configurable
+			Config{"from": "a", "to": "b", "target": "took", "unit": "weeks", "formats": []string{"02/01/2006"}},
+			map[string]interface{}{"a": "01/07/2017", "b": "15/07/2017"},
+			map[string]interface{}{"a": "01/07/2017", "b": "15/07/2017", "took": 2.0},
+			false,
+		},
+		// missing timestamps leave the target unset by default
+		{
+			Config{"from": "a", "to": "b", "target": "took"},
+			map[string]interface{}{"a": "2017-07-14T02:40:00Z", "b": nil},
+			map[string]interface{}{"a": "2017-07-14T02:40:00Z", "b": nil},
+			false,
+		},
+		{
+			Config{"from": "a", "to": "b", "target": "took", "missing": "null"},
+			map[string]interface{}{"b": "2017-07-14T02:40:00Z"},
+			map[string]interface{}{"b": "2017-07-14T02:40:00Z", "took": nil},
+			false,
+		},
+		{
+			Config{"from": "a", "target": "took", "missing": "drop"},
+			map[string]interface{}{},
+			nil,
+			false,
+		},
+		{
+			Config{"from": "a", "target": "took", "missing": "error"},
+			map[string]interface{}{},
+			nil,
+			true,
+		},
+		// unparseable timestamps are errors by default
+		{
+			Config{"from": "a", "to": "b", "target": "took"},
+			map[string]interface{}{"a": "yesterday", "b": "2017-07-14T02:40:00Z"},
+			nil,
+			true,
+		},
+		{
+			Config{"from": "a", "to": "b", "target": "took", "unparseable": "null"},
+			map[string]interface{}{"a": "2017-07-14T02:40:00Z", "b": true},
+			map[string]interface{}{"a": "2017-07-14T02:40:00Z", "b": true, "took": nil},
+			false,
+		},
+		{
+			Config{"from": "a", "to": "b", "target": "took", "unparseable": "pass"},
+			map[string]interface{}{"a": "yesterday", "b": "2017-07-14T02:40:00Z"},
+			map[string]interface{}{"a": "yesterday", "b": "2017-07-14T02:40:00Z"},
+			false,
+		},
+		{
+			Config{"from": "a", "target": "took", "unparseable": "drop"},
+			map[string]interface{}{"a": "yesterday"},
+			nil,
+			false,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "duration", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.conf, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%v: expected an ERROR, got %v", v.conf, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestDurationToNow(t *testing.T) {
+	created := time.Now().Add(-(3*24 + 6) * time.Hour)
+	data := []struct {
+		conf     Config
+		expected float64
+	}{
+		{Config{"from": "created_at", "target": "order_age_days", "unit": "days", "truncate": true}, 3},
+		{Config{"from": "created_at", "target": "order_age_days", "unit": "days"}, 3.25},
+		{Config{"from": "created_at", "target": "order_age_hours", "unit": "hours"}, 78},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "duration", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"created_at": created.Format(time.RFC3339Nano)}, "database.collection"))
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		var got float64
+		switch age := out.Map()[v.conf["target"].(string)].(type) {
+		case int64:
+			got = float64(age)
+		case float64:
+			got = age
+		default:
+			t.Fatalf("%v: expected a number, got %T", v.conf, age)
+		}
+		if math.Abs(got-v.expected) > 0.01 {
+			t.Errorf("%v: expected about %v, got %v", v.conf, v.expected, got)
+		}
+	}
+}
+
+func TestDurationBadConfig(t *testing.T) {
+	data := []Config{
+		{"target": "took"},
+		{"from": "a"},
+		{"from": "a", "target": "took", "unit": "fortnights"},
+		{"from": "a", "target": "took", "missing": "skip"},
+		{"from": "a", "target": "took", "unparseable": "keep"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewDuration(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
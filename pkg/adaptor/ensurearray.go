@@ -0,0 +1,92 @@
+package adaptor
+
+import (
+	"fmt"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewEnsureArray creates a transformer that makes fields consistently arrays, wrapping a scalar in a single
+// element array, i.e. {"tags": "a"} becomes {"tags": ["a"]}, and leaving arrays as they are.  With the unwrap
+// mode it does the reverse, replacing single element arrays with their element.  Missing and null fields are
+// handled by the missing policy
+func NewEnsureArray(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf EnsureArrayConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "ensurearray config must contain fields", nil)
+	}
+
+	e := &ensureArray{fields: conf.Fields, mode: conf.Mode, missing: conf.Missing}
+	switch e.mode {
+	case "":
+		e.mode = "wrap"
+	case "wrap", "unwrap":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown mode (%s), must be wrap or unwrap", e.mode), nil)
+	}
+	switch e.missing {
+	case "":
+		e.missing = "pass"
+	case "pass", "empty", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be pass, empty, drop or error", e.missing), nil)
+	}
+	if e.missing == "empty" && e.mode == "unwrap" {
+		return nil, NewError(CRITICAL, path, "the empty missing policy can only be used with the wrap mode", nil)
+	}
+
+	return newDocTransformer("ensurearray", p, path, extra, e.apply)
+}
+
+// EnsureArrayConfig provides configuration options for the ensurearray transformer
+type EnsureArrayConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    []string `json:"fields" doc:"the dotted paths of the fields to make arrays"`
+	Mode      string   `json:"mode" doc:"wrap (wrap scalars in an array, the default) or unwrap (replace single element arrays with their element)"`
+	Missing   string   `json:"missing" doc:"what to do when a field is missing or null, pass (leave it as it is, the default), empty (set it to an empty array, only when wrapping), drop or error"`
+}
+
+type ensureArray struct {
+	fields  []string
+	mode    string
+	missing string
+}
+
+func (e *ensureArray) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for _, field := range e.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			switch e.missing {
+			case "empty":
+				if err := setField(doc, field, []interface{}{}); err != nil {
+					return err
+				}
+			case "drop":
+				msg.Op = message.Noop
+				return nil
+			case "error":
+				return fmt.Errorf("%s is missing", field)
+			}
+			continue
+		}
+
+		elems, isArray := asSlice(v)
+		switch {
+		case e.mode == "wrap" && !isArray:
+			v = []interface{}{v}
+		case e.mode == "unwrap" && isArray && len(elems) == 1:
+			v = elems[0]
+		default:
+			continue
+		}
+		if err := setField(doc, field, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestEnsureArray(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			// scalars and documents are wrapped, arrays are left alone
+			Config{"fields": []interface{}{"tags", "owner", "address.city", "ids"}},
+			map[string]interface{}{"_id": "1", "tags": "a", "owner": bson.M{"name": "nick"}, "address": bson.M{"city": "toronto"}, "ids": []interface{}{1, 2}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a"}, "owner": []interface{}{bson.M{"name": "nick"}}, "address": bson.M{"city": []interface{}{"toronto"}}, "ids": []interface{}{1, 2}},
+			false,
+		},
+		{
+			// typed and empty arrays are arrays too
+			Config{"fields": []interface{}{"tags", "empty"}},
+			map[string]interface{}{"_id": "1", "tags": []string{"a"}, "empty": []interface{}{}},
+			map[string]interface{}{"_id": "1", "tags": []string{"a"}, "empty": []interface{}{}},
+			false,
+		},
+		{
+			// missing and null fields pass by default
+			Config{"fields": []interface{}{"tags", "missing"}},
+			map[string]interface{}{"_id": "1", "tags": nil},
+			map[string]interface{}{"_id": "1", "tags": nil},
+			false,
+		},
+		{
+			Config{"missing": "empty", "fields": []interface{}{"tags", "nested.missing"}},
+			map[string]interface{}{"_id": "1", "tags": nil},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{}, "nested": map[string]interface{}{"missing": []interface{}{}}},
+			false,
+		},
+		{
+			Config{"missing": "drop", "fields": []interface{}{"tags"}},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			false,
+		},
+		{
+			Config{"missing": "error", "fields": []interface{}{"tags"}},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			true,
+		},
+		{
+			// unwrap replaces single element arrays, and leaves everything else
+			Config{"mode": "unwrap", "fields": []interface{}{"tags", "many", "empty", "scalar", "typed"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a"}, "many": []interface{}{1, 2}, "empty": []interface{}{}, "scalar": "b", "typed": []string{"c"}},
+			map[string]interface{}{"_id": "1", "tags": "a", "many": []interface{}{1, 2}, "empty": []interface{}{}, "scalar": "b", "typed": "c"},
+			false,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "ensurearray", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestEnsureArrayBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"mode": "flatten", "fields": []interface{}{"tags"}},
+		{"missing": "skip", "fields": []interface{}{"tags"}},
+		{"mode": "unwrap", "missing": "empty", "fields": []interface{}{"tags"}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewEnsureArray(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
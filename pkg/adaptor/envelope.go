@@ -0,0 +1,158 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// the message metadata an envelope can carry
+var envelopeMetaFields = []string{"op", "namespace", "timestamp", "sequence"}
+
+// NewWrap creates a transformer that wraps each document in an envelope, i.e.
+// {"meta": {"op": "insert", "namespace": "db.coll", "timestamp": 1420000000, "sequence": 1}, "payload": {...}},
+// for sinks whose consumers expect every record in the same shape.  The sequence counts the messages wrapped
+func NewWrap(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf EnvelopeConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	e, err := newEnvelope(conf)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, err.Error(), nil)
+	}
+	return newDocTransformer("wrap", p, path, extra, e.wrap)
+}
+
+// NewUnwrap creates a transformer that undoes wrap, replacing each document with its payload.  The op,
+// namespace and timestamp in the envelope's metadata, if there are any, are restored to the message
+func NewUnwrap(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf EnvelopeConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	e, err := newEnvelope(conf)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, err.Error(), nil)
+	}
+	return newDocTransformer("unwrap", p, path, extra, e.unwrap)
+}
+
+// EnvelopeConfig provides configuration options for the wrap and unwrap transformers
+type EnvelopeConfig struct {
+	Namespace  string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Payload    string   `json:"payload" doc:"the field holding the document, defaults to payload"`
+	Meta       string   `json:"meta" doc:"the field holding the metadata, defaults to meta"`
+	MetaFields []string `json:"metafields" doc:"the metadata to add when wrapping, any of op, namespace, timestamp and sequence, defaults to all of them"`
+	KeepID     bool     `json:"keepid" doc:"if true, the document's _id is copied to the top of the envelope, for sinks that need one"`
+}
+
+type envelope struct {
+	payload    string
+	meta       string
+	metaFields map[string]bool
+	keepID     bool
+	sequence   uint64
+}
+
+func newEnvelope(conf EnvelopeConfig) (*envelope, error) {
+	e := &envelope{payload: conf.Payload, meta: conf.Meta, keepID: conf.KeepID, metaFields: make(map[string]bool)}
+	if e.payload == "" {
+		e.payload = "payload"
+	}
+	if e.meta == "" {
+		e.meta = "meta"
+	}
+	if e.payload == e.meta {
+		return nil, fmt.Errorf("the payload and meta fields must be different, both are %s", e.payload)
+	}
+
+	if len(conf.MetaFields) == 0 {
+		conf.MetaFields = envelopeMetaFields
+	}
+	for _, field := range conf.MetaFields {
+		known := false
+		for _, f := range envelopeMetaFields {
+			known = known || f == field
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown metadata (%s), must be op, namespace, timestamp or sequence", field)
+		}
+		e.metaFields[field] = true
+	}
+	return e, nil
+}
+
+// wrap moves the document under the payload field
+func (e *envelope) wrap(msg *message.Msg, doc map[string]interface{}) error {
+	payload := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		payload[k] = v
+		delete(doc, k)
+	}
+
+	meta := make(map[string]interface{}, len(e.metaFields))
+	if e.metaFields["op"] {
+		meta["op"] = msg.Op.String()
+	}
+	if e.metaFields["namespace"] {
+		meta["namespace"] = msg.Namespace
+	}
+	if e.metaFields["timestamp"] {
+		meta["timestamp"] = msg.Timestamp
+	}
+	if e.metaFields["sequence"] {
+		meta["sequence"] = int64(atomic.AddUint64(&e.sequence, 1))
+	}
+
+	if id, ok := payload["_id"]; ok && e.keepID {
+		doc["_id"] = id
+	}
+	doc[e.payload] = payload
+	if len(meta) > 0 {
+		doc[e.meta] = meta
+	}
+	return nil
+}
+
+// unwrap replaces the document with its payload
+func (e *envelope) unwrap(msg *message.Msg, doc map[string]interface{}) error {
+	payload, ok := asMap(doc[e.payload])
+	if !ok {
+		return fmt.Errorf("%s isn't a document", e.payload)
+	}
+
+	if meta, ok := asMap(doc[e.meta]); ok {
+		if op, ok := meta["op"].(string); ok {
+			msg.Op = message.OpTypeFromString(op)
+		}
+		if ns, ok := meta["namespace"].(string); ok {
+			msg.Namespace = ns
+		}
+		switch ts := meta["timestamp"].(type) {
+		case int64:
+			msg.Timestamp = ts
+		case int:
+			msg.Timestamp = int64(ts)
+		case float64:
+			msg.Timestamp = int64(ts)
+		case json.Number:
+			if n, err := ts.Int64(); err == nil {
+				msg.Timestamp = n
+			}
+		}
+	}
+
+	for k := range doc {
+		delete(doc, k)
+	}
+	for k, v := range payload {
+		doc[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestWrap(t *testing.T) {
+	ids, names := []string{"1", "2"}, []string{"a", "b"}
+	data := []struct {
+		conf Config
+		out  []map[string]interface{}
+	}{
+		{
+			Config{},
+			[]map[string]interface{}{
+				{"payload": map[string]interface{}{"_id": "1", "name": "a"}, "meta": map[string]interface{}{"op": "insert", "namespace": "database.collection", "timestamp": int64(1420000000), "sequence": int64(1)}},
+				{"payload": map[string]interface{}{"_id": "2", "name": "b"}, "meta": map[string]interface{}{"op": "insert", "namespace": "database.collection", "timestamp": int64(1420000000), "sequence": int64(2)}},
+			},
+		},
+		{
+			Config{"payload": "data", "meta": "_meta", "metafields": []interface{}{"op", "sequence"}, "keepid": true},
+			[]map[string]interface{}{
+				{"_id": "1", "data": map[string]interface{}{"_id": "1", "name": "a"}, "_meta": map[string]interface{}{"op": "insert", "sequence": int64(1)}},
+				{"_id": "2", "data": map[string]interface{}{"_id": "2", "name": "b"}, "_meta": map[string]interface{}{"op": "insert", "sequence": int64(2)}},
+			},
+		},
+	}
+
+	for _, v := range data {
+		wrap, _ := newTestDocTransformer(t, "wrap", v.conf)
+		for i, out := range v.out {
+			msg := message.NewMsg(message.Insert, map[string]interface{}{"_id": ids[i], "name": names[i]}, "database.collection")
+			msg.Timestamp = 1420000000
+
+			wrapped, err := wrap.transformOne(msg)
+			if err != nil || wrapped == nil {
+				t.Fatalf("%+v: unexpected error, %v", v.conf, err)
+			}
+			if !reflect.DeepEqual(wrapped.Data, out) {
+				t.Errorf("%+v: expected %+v, got %+v", v.conf, out, wrapped.Data)
+			}
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	wrap, _ := newTestDocTransformer(t, "wrap", Config{})
+	unwrap, errs := newTestDocTransformer(t, "unwrap", Config{})
+
+	// a round trip gives back the original message
+	doc := map[string]interface{}{"_id": "1", "name": "a", "nested": map[string]interface{}{"b": 1}}
+	msg := message.NewMsg(message.Update, map[string]interface{}{"_id": "1", "name": "a", "nested": map[string]interface{}{"b": 1}}, "database.collection")
+	msg.Timestamp = 1420000000
+	wrapped, _ := wrap.transformOne(msg)
+
+	// as it would be after a trip through a sink and source that don't know about the envelope
+	wrapped.Op = message.Insert
+	wrapped.Namespace = "database.other"
+	wrapped.Timestamp = 0
+
+	out, err := unwrap.transformOne(wrapped)
+	if err != nil || out == nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if !reflect.DeepEqual(out.Data, doc) {
+		t.Errorf("expected %+v, got %+v", doc, out.Data)
+	}
+	if out.Op != message.Update || out.Namespace != "database.collection" || out.Timestamp != 1420000000 {
+		t.Errorf("expected the message's metadata to be restored, got %s %s %d", out.Op, out.Namespace, out.Timestamp)
+	}
+
+	// timestamps read back from json are floats, and the metadata is optional
+	msg = message.NewMsg(message.Insert, map[string]interface{}{"payload": map[string]interface{}{"_id": "2"}, "meta": map[string]interface{}{"timestamp": 1420000000.0}}, "database.collection")
+	if out, _ := unwrap.transformOne(msg); out.Timestamp != 1420000000 || out.Op != message.Insert || !reflect.DeepEqual(out.Data, map[string]interface{}{"_id": "2"}) {
+		t.Errorf("expected the payload with the timestamp restored, got %+v", out)
+	}
+
+	// a document without a payload is dropped
+	out, _ = unwrap.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": "3"}, "database.collection"))
+	if out != nil {
+		t.Errorf("expected the message to be dropped, got %+v", out)
+	}
+	if err := <-errs; err.(Error).Lvl != ERROR {
+		t.Errorf("expected an ERROR, got %v", err)
+	}
+}
+
+func TestEnvelopeBadConfig(t *testing.T) {
+	data := []Config{
+		{"payload": "doc", "meta": "doc"},
+		{"metafields": []interface{}{"op", "source"}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewWrap(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -38,14 +38,45 @@ func levelToString(lvl ErrorLevel) string {
 	}
 }
 
+// Adaptor errors can also be categorized by their cause, so that whatever handles them (reconnecting,
+// a circuit breaker, a dead letter file) can tell them apart without matching on their messages.
+// TRANSIENT errors may go away if the operation is retried, i.e. a dropped connection or a rate limited request.
+//
+// PERMANENT errors won't, i.e. a document the database rejects.
+//
+// CONFIG errors mean the adaptor is misconfigured, and nothing will work until the config is changed
+const (
+	UNCATEGORIZED ErrorCategory = iota
+	TRANSIENT
+	PERMANENT
+	CONFIG
+)
+
+// ErrorCategory indicates the cause of the error
+type ErrorCategory int
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case TRANSIENT:
+		return "transient"
+	case PERMANENT:
+		return "permanent"
+	case CONFIG:
+		return "config"
+	default:
+		return "uncategorized"
+	}
+}
+
 // Error is an error that happened during an adaptor's operation.
 // Error's include both an indication of the severity, Level, as well as
 // a reference to the Record that was in process when the error occured
 type Error struct {
-	Lvl    ErrorLevel
-	Str    string
-	Path   string
-	Record interface{}
+	Lvl      ErrorLevel
+	Category ErrorCategory
+	Str      string
+	Path     string
+	Record   interface{}
 }
 
 // NewError creates an Error type with the specificed level, path, message and record
@@ -53,6 +84,28 @@ func NewError(lvl ErrorLevel, path, str string, record interface{}) Error {
 	return Error{Lvl: lvl, Path: path, Str: str, Record: record}
 }
 
+// NewCategorizedError creates an Error type with the specified category, level, path, message and record
+func NewCategorizedError(category ErrorCategory, lvl ErrorLevel, path, str string, record interface{}) Error {
+	return Error{Lvl: lvl, Category: category, Path: path, Str: str, Record: record}
+}
+
+// CategoryOf returns the category of the error, errors that aren't adaptor Errors are uncategorized
+func CategoryOf(err error) ErrorCategory {
+	if e, ok := err.(Error); ok {
+		return e.Category
+	}
+	return UNCATEGORIZED
+}
+
+// categorize sets the category of an adaptor Error, other errors are returned as they are
+func categorize(err error, category ErrorCategory) error {
+	if e, ok := err.(Error); ok {
+		e.Category = category
+		return e
+	}
+	return err
+}
+
 // Error returns the error as a string
 func (t Error) Error() string {
 	return fmt.Sprintf("%s: %s", levelToString(t.Lvl), t.Str)
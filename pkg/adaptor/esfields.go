@@ -0,0 +1,163 @@
+package adaptor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewESFields creates a transformer that rewrites field names elasticsearch would reject, at every
+// level of the document.  Dots are replaced, leading underscores are stripped or prefixed and,
+// optionally, names starting with a digit are prefixed.  The top level _id is left alone
+func NewESFields(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ESFieldsConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	f := &esFields{
+		dots:         conf.Dots,
+		underscore:   conf.Underscore,
+		digit:        conf.Digit,
+		prefix:       conf.Prefix,
+		mappingField: conf.MappingField,
+	}
+	if f.dots == "" {
+		f.dots = "_"
+	}
+	if strings.Contains(f.dots, ".") {
+		return nil, NewError(CRITICAL, path, "the dots replacement can't contain a dot", nil)
+	}
+	if f.prefix == "" {
+		f.prefix = "f_"
+	}
+	if f.prefix[0] == '_' || (f.prefix[0] >= '0' && f.prefix[0] <= '9') || strings.Contains(f.prefix, ".") {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("the prefix (%s) must itself be a valid field name", f.prefix), nil)
+	}
+	switch f.underscore {
+	case "":
+		f.underscore = "strip"
+	case "strip", "prefix", "keep":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown underscore policy (%s), must be strip, prefix or keep", conf.Underscore), nil)
+	}
+	switch f.digit {
+	case "":
+		f.digit = "keep"
+	case "keep", "prefix":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown digit policy (%s), must be keep or prefix", conf.Digit), nil)
+	}
+
+	return newDocTransformer("esfields", p, path, extra, f.apply)
+}
+
+// ESFieldsConfig provides configuration options for the esfields transformer
+type ESFieldsConfig struct {
+	Namespace    string `json:"namespace" doc:"the set of namespaces to transform"`
+	Dots         string `json:"dots" doc:"the replacement for dots in field names, defaults to _"`
+	Underscore   string `json:"underscore" doc:"what to do with names starting with an underscore, strip (the default), prefix or keep"`
+	Digit        string `json:"digit" doc:"what to do with names starting with a digit, keep (the default) or prefix"`
+	Prefix       string `json:"prefix" doc:"the prefix added by the prefix policies, defaults to f_"`
+	MappingField string `json:"mappingfield" doc:"if set, a top level field to record the renamed fields in, as a document of original name to new name"`
+}
+
+type esFields struct {
+	dots         string
+	underscore   string
+	digit        string
+	prefix       string
+	mappingField string
+}
+
+// apply renames the fields of the document in place.  two fields that end up with the same name
+// is an error, rather than one silently overwriting the other
+func (f *esFields) apply(msg *message.Msg, doc map[string]interface{}) error {
+	renamed := make(map[string]interface{})
+	out, err := f.sanitizeDoc(doc, true, renamed)
+	if err != nil {
+		return err
+	}
+
+	for k := range doc {
+		delete(doc, k)
+	}
+	for k, v := range out {
+		doc[k] = v
+	}
+	if f.mappingField != "" && len(renamed) > 0 {
+		doc[f.mappingField] = renamed
+	}
+	return nil
+}
+
+func (f *esFields) sanitizeDoc(doc map[string]interface{}, top bool, renamed map[string]interface{}) (map[string]interface{}, error) {
+	// visit the keys in order, so that a collision is reported the same way every time
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(doc))
+	from := make(map[string]string, len(doc))
+	for _, k := range keys {
+		name := k
+		if !top || k != "_id" {
+			name = f.sanitizeName(k)
+		}
+		if other, ok := from[name]; ok {
+			return nil, fmt.Errorf("%s and %s would both be renamed %s", other, k, name)
+		}
+		from[name] = k
+		if name != k {
+			renamed[k] = name
+		}
+
+		v, err := f.sanitizeValue(doc[k], renamed)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// sanitizeValue sanitizes the documents held in v, including those in arrays
+func (f *esFields) sanitizeValue(v interface{}, renamed map[string]interface{}) (interface{}, error) {
+	if m, ok := asMap(v); ok {
+		return f.sanitizeDoc(m, false, renamed)
+	}
+	if a, ok := v.([]interface{}); ok {
+		out := make([]interface{}, len(a))
+		for i, e := range a {
+			s, err := f.sanitizeValue(e, renamed)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func (f *esFields) sanitizeName(name string) string {
+	name = strings.Replace(name, ".", f.dots, -1)
+
+	if strings.HasPrefix(name, "_") {
+		switch f.underscore {
+		case "strip":
+			name = strings.TrimLeft(name, "_")
+		case "prefix":
+			name = f.prefix + name
+		}
+	}
+	if name == "" || (f.digit == "prefix" && name[0] >= '0' && name[0] <= '9') {
+		name = f.prefix + name
+	}
+	return name
+}
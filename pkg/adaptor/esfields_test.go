@@ -0,0 +1,94 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestESFields(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+	}{
+		{
+			// dotted keys
+			Config{},
+			map[string]interface{}{"_id": "1", "user.name": "nick", "a.b.c": 1},
+			map[string]interface{}{"_id": "1", "user_name": "nick", "a_b_c": 1},
+		},
+		{
+			// leading underscores, the top level _id is kept
+			Config{},
+			map[string]interface{}{"_id": "1", "_rev": "2", "__v": 0, "meta": bson.M{"_id": "x"}},
+			map[string]interface{}{"_id": "1", "rev": "2", "v": 0, "meta": map[string]interface{}{"id": "x"}},
+		},
+		{
+			Config{"underscore": "prefix", "digit": "prefix", "prefix": "x_", "dots": "-"},
+			map[string]interface{}{"_id": "1", "_rev": "2", "1st": true, "a.b": 1},
+			map[string]interface{}{"_id": "1", "x__rev": "2", "x_1st": true, "a-b": 1},
+		},
+		{
+			// nested documents, and documents in arrays
+			Config{"mappingfield": "renamed"},
+			map[string]interface{}{"_id": "1", "meta": bson.M{"geo.lat": 1.5, "tags": []interface{}{bson.M{"_key": "a"}, "b"}}},
+			map[string]interface{}{
+				"_id":     "1",
+				"meta":    map[string]interface{}{"geo_lat": 1.5, "tags": []interface{}{map[string]interface{}{"key": "a"}, "b"}},
+				"renamed": map[string]interface{}{"geo.lat": "geo_lat", "_key": "key"},
+			},
+		},
+		{
+			// nothing to rename
+			Config{"mappingfield": "renamed"},
+			map[string]interface{}{"_id": "1", "name": "nick"},
+			map[string]interface{}{"_id": "1", "name": "nick"},
+		},
+		{
+			// collisions are errors
+			Config{},
+			map[string]interface{}{"_id": "1", "a.b": 1, "a_b": 2},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "esfields", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestESFieldsBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "database./.*/", "dots": "."},
+		{"namespace": "database./.*/", "prefix": "_"},
+		{"namespace": "database./.*/", "underscore": "drop"},
+		{"namespace": "database./.*/", "digit": "strip"},
+	}
+
+	for _, v := range data {
+		if _, err := NewESFields(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package adaptor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"github.com/compose/mejson"
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// Exec is a transformer that hands each message to an external process, so that transformers can be written
+// in any language.  The process reads messages from stdin and writes its replies to stdout, one json document
+// per line.  Each message is written as {"op": "insert", "ns": "database.collection", "ts": 1234, "data": {...}},
+// with the data in mongo extended json, and the process replies to each message in turn, with either the
+// message as it should be sent on, in the same form, or false (or null) to drop it.  Anything written to
+// stderr is passed through to transporter's stderr.
+//
+// If the process exits, or doesn't reply in time, the message is dropped with an ERROR and the process is
+// restarted for the next message.  After MaxRestarts failures in a row, the transformer gives up
+type Exec struct {
+	command     string
+	args        []string
+	timeout     time.Duration
+	maxRestarts int
+
+	pipe *pipe.Pipe
+	path string
+	ns   *regexp.Regexp
+
+	proc     *execProcess
+	failures int // consecutive failed messages
+}
+
+// NewExec creates a new exec transformer
+func NewExec(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ExecConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Command == "" {
+		return nil, NewError(CRITICAL, path, "no command specified", nil)
+	}
+	if _, err := exec.LookPath(conf.Command); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't find command (%s)", err.Error()), nil)
+	}
+
+	e := &Exec{command: conf.Command, args: conf.Args, timeout: 10 * time.Second, maxRestarts: 3, pipe: p, path: path}
+	if conf.Timeout != "" {
+		timeout, err := time.ParseDuration(conf.Timeout)
+		if err != nil {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("bad timeout (%s)", err.Error()), nil)
+		}
+		e.timeout = timeout
+	}
+	if conf.MaxRestarts != nil {
+		e.maxRestarts = *conf.MaxRestarts
+	}
+
+	var err error
+	if _, e.ns, err = extra.compileNamespace(); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't split exec namespace (%s)", err.Error()), nil)
+	}
+
+	return e, nil
+}
+
+// ExecConfig provides configuration options for the exec transformer
+type ExecConfig struct {
+	Namespace   string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Command     string   `json:"command" doc:"the transformer to run, messages are written to its stdin and read back from its stdout as lines of json"`
+	Args        []string `json:"args" doc:"the command's arguments"`
+	Timeout     string   `json:"timeout" doc:"how long to wait for the reply to each message, defaults to 10s"`
+	MaxRestarts *int     `json:"maxrestarts" doc:"how many messages in a row can fail, restarting the command each time, before the transformer gives up. defaults to 3"`
+}
+
+// execProcess is a running transformer command, lines holds the replies it writes to stdout
+type execProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan []byte
+}
+
+// execEnvelope is the form a message is sent to, and read back from, the command
+type execEnvelope struct {
+	Op   string      `json:"op"`
+	Ns   string      `json:"ns"`
+	Ts   int64       `json:"ts"`
+	Data interface{} `json:"data"`
+}
+
+// Start the adaptor as a source (not implemented for transformers)
+func (e *Exec) Start() error {
+	return fmt.Errorf("transformers can't be used as a source")
+}
+
+// Listen starts the transformer's listener, sending each message through the command
+func (e *Exec) Listen() error {
+	return e.pipe.Listen(e.transformOne, e.ns)
+}
+
+// Stop the adaptor, and the command with it
+func (e *Exec) Stop() error {
+	e.pipe.Stop()
+	e.kill()
+	return nil
+}
+
+func (e *Exec) transformOne(msg *message.Msg) (*message.Msg, error) {
+	if msg.Op == message.Command {
+		return msg, nil
+	}
+
+	out := execEnvelope{Op: msg.Op.String(), Ns: msg.Namespace, Ts: msg.Timestamp, Data: msg.Data}
+	if msg.IsMap() {
+		data, err := mejson.Marshal(msg.Data)
+		if err != nil {
+			e.pipe.Err <- NewError(ERROR, e.path, fmt.Sprintf("exec error (%s)", err.Error()), msg.Data)
+			return nil, nil
+		}
+		out.Data = data
+	}
+	ba, err := json.Marshal(out)
+	if err != nil {
+		e.pipe.Err <- NewError(ERROR, e.path, fmt.Sprintf("exec error (%s)", err.Error()), msg.Data)
+		return nil, nil
+	}
+
+	reply, err := e.call(append(ba, '\n'))
+	if err != nil {
+		// the message may well be what broke the command, so rather than retrying it, it's dropped
+		e.kill()
+		e.failures++
+		if e.failures > e.maxRestarts {
+			return nil, NewError(CRITICAL, e.path, fmt.Sprintf("exec error (%s failed %d times in a row, last with %s)", e.command, e.failures, err.Error()), msg.Data)
+		}
+		e.pipe.Err <- NewError(ERROR, e.path, fmt.Sprintf("exec error (%s, restarting %s)", err.Error(), e.command), msg.Data)
+		return nil, nil
+	}
+	e.failures = 0
+
+	if err := e.toMsg(reply, msg); err != nil {
+		e.pipe.Err <- NewError(ERROR, e.path, fmt.Sprintf("exec error (%s)", err.Error()), msg.Data)
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// call writes the line to the command, starting it if need be, and waits for the reply
+func (e *Exec) call(line []byte) ([]byte, error) {
+	if e.proc == nil {
+		if err := e.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := e.proc.stdin.Write(line); err != nil {
+		return nil, fmt.Errorf("can't write to %s, %s", e.command, err.Error())
+	}
+
+	select {
+	case reply, ok := <-e.proc.lines:
+		if !ok {
+			return nil, fmt.Errorf("%s exited", e.command)
+		}
+		return reply, nil
+	case <-time.After(e.timeout):
+		return nil, fmt.Errorf("%s didn't reply within %s", e.command, e.timeout)
+	}
+}
+
+func (e *Exec) start() error {
+	cmd := exec.Command(e.command, e.args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("can't start %s, %s", e.command, err.Error())
+	}
+
+	proc := &execProcess{cmd: cmd, stdin: stdin, lines: make(chan []byte)}
+	go func() {
+		defer close(proc.lines)
+		r := bufio.NewReader(stdout)
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(line) > 0 {
+				proc.lines <- line
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	e.proc = proc
+	return nil
+}
+
+// kill stops the command, if it's running.  Its reader drains anything it wrote, so it's never left blocked
+func (e *Exec) kill() {
+	if e.proc == nil {
+		return
+	}
+	proc := e.proc
+	e.proc = nil
+
+	proc.stdin.Close()
+	proc.cmd.Process.Kill()
+	go func() {
+		for range proc.lines {
+		}
+		proc.cmd.Wait()
+	}()
+}
+
+// toMsg copies the command's reply into the message, or turns it into a noop if the command dropped it.
+// fields missing from the reply are left as they were
+func (e *Exec) toMsg(reply []byte, msg *message.Msg) error {
+	var v interface{}
+	if err := json.Unmarshal(reply, &v); err != nil {
+		return fmt.Errorf("can't parse reply from %s, %s", e.command, err.Error())
+	}
+	if v == nil || v == false {
+		msg.Op = message.Noop
+		return nil
+	}
+
+	var in execEnvelope
+	if err := json.Unmarshal(reply, &in); err != nil {
+		return fmt.Errorf("reply from %s must be a message or false, %s", e.command, err.Error())
+	}
+	if in.Op != "" {
+		msg.Op = message.OpTypeFromString(in.Op)
+	}
+	if in.Ns != "" {
+		msg.Namespace = in.Ns
+	}
+	if in.Ts != 0 {
+		msg.Timestamp = in.Ts
+	}
+	switch data := in.Data.(type) {
+	case nil:
+	case map[string]interface{}:
+		d, err := mejson.Unmarshal(data)
+		if err != nil {
+			return err
+		}
+		msg.Data = map[string]interface{}(d)
+	default:
+		msg.Data = data
+	}
+	return nil
+}
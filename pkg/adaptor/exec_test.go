@@ -0,0 +1,147 @@
+package adaptor
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// TestExecHelperProcess isn't a real test, it's the external transformer run by the exec tests.
+// it tags each document with the pid that saw it, drops documents with _id "drop", exits on "crash",
+// and ignores "hang"
+func TestExecHelperProcess(t *testing.T) {
+	if args := flag.Args(); len(args) == 0 || args[0] != "exec-helper" {
+		return
+	}
+
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		var msg map[string]interface{}
+		json.Unmarshal(s.Bytes(), &msg)
+		data := msg["data"].(map[string]interface{})
+		switch data["_id"] {
+		case "drop":
+			fmt.Println("false")
+			continue
+		case "crash":
+			os.Exit(1)
+		case "hang":
+			continue
+		}
+		data["pid"] = os.Getpid()
+		msg["ns"] = "database.transformed"
+		ba, _ := json.Marshal(msg)
+		fmt.Println(string(ba))
+	}
+	os.Exit(0)
+}
+
+func newTestExec(t *testing.T, maxRestarts int) (*Exec, chan error) {
+	p := pipe.NewPipe(nil, "path")
+	errs := make(chan error, 100)
+	go func(p *pipe.Pipe) {
+		for err := range p.Err {
+			errs <- err
+		}
+	}(p)
+
+	a, err := NewExec(p, "path", Config{
+		"namespace":   "database./.*/",
+		"command":     os.Args[0],
+		"args":        []interface{}{"-test.run=TestExecHelperProcess", "--", "exec-helper"},
+		"timeout":     "500ms",
+		"maxrestarts": maxRestarts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating exec transformer, %s", err)
+	}
+	return a.(*Exec), errs
+}
+
+func TestExec(t *testing.T) {
+	e, errs := newTestExec(t, 3)
+	defer e.kill()
+
+	data := []struct {
+		id      string
+		dropped bool
+		level   ErrorLevel // of the error reported, if any
+	}{
+		{"1", false, 0},
+		{"drop", true, 0},
+		{"2", false, 0},
+		{"crash", true, ERROR},
+		{"3", false, 0}, // the transformer is restarted
+		{"hang", true, ERROR},
+		{"4", false, 0},
+	}
+
+	var pids []interface{}
+	for _, v := range data {
+		out, err := e.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": v.id}, "database.collection"))
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", v.id, err)
+		}
+
+		if v.dropped {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%s: expected the message to be dropped, got %+v", v.id, out)
+			}
+		} else {
+			if out == nil || out.Namespace != "database.transformed" || out.Map()["_id"] != v.id {
+				t.Errorf("%s: expected the transformed message, got %+v", v.id, out)
+				continue
+			}
+			pids = append(pids, out.Map()["pid"])
+		}
+
+		if v.level != 0 {
+			select {
+			case err := <-errs:
+				if err.(Error).Lvl != v.level {
+					t.Errorf("%s: expected error level %d, got %d", v.id, v.level, err.(Error).Lvl)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("%s: expected an error", v.id)
+			}
+		}
+	}
+
+	// 1 and 2 went to the first process, 3 and 4 to the ones restarted after the crash and the hang
+	if len(pids) != 4 || pids[0] != pids[1] || pids[1] == pids[2] || pids[2] == pids[3] {
+		t.Errorf("expected the transformer to be restarted after each failure, got pids %v", pids)
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("unexpected error, %s", err)
+	default:
+	}
+}
+
+func TestExecGivesUp(t *testing.T) {
+	e, errs := newTestExec(t, 1)
+	defer e.kill()
+
+	for i, level := range []ErrorLevel{ERROR, CRITICAL} {
+		_, err := e.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": "crash"}, "database.collection"))
+		if level == CRITICAL {
+			if err == nil || err.(Error).Lvl != CRITICAL {
+				t.Errorf("%d: expected a CRITICAL error, got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error, %s", i, err)
+		}
+		if err := <-errs; err.(Error).Lvl != level {
+			t.Errorf("%d: expected error level %d, got %d", i, level, err.(Error).Lvl)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package adaptor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewExpiry creates a transformer that writes the time a document expires, now or a timestamp field plus
+// the ttl, into a field, for sinks with TTL indexes or index lifecycle policies to act on, i.e. expires_at from
+// created_at and 30d.  The timestamp is parsed as the timeparse transformer parses it, and the expiry is written
+// in any of the timeparse transformer's output formats
+func NewExpiry(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ExpiryConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.TTL == "" || conf.Target == "" {
+		return nil, NewError(CRITICAL, path, "expiry config must contain a ttl and a target field", nil)
+	}
+
+	e := &expiry{from: conf.From, target: conf.Target, formats: conf.Formats, output: conf.Output, missing: conf.Missing, unparseable: conf.Unparseable, now: time.Now}
+	ttl, err := parseTTL(conf.TTL)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+	e.ttl = ttl
+	if len(e.formats) == 0 {
+		e.formats = defaultTimeFormats
+	}
+	switch e.output {
+	case "":
+		e.output = timeFormatRFC3339
+	case timeFormatEpoch:
+		return nil, NewError(CRITICAL, path, "the output format must be epoch_seconds or epoch_millis, not epoch", nil)
+	}
+	switch e.missing {
+	case "":
+		e.missing = "pass"
+	case "pass", "now", "null", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be pass, now, null, drop or error", e.missing), nil)
+	}
+	switch e.unparseable {
+	case "":
+		e.unparseable = "error"
+	case "pass", "null", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unparseable policy (%s), must be error, null, pass or drop", e.unparseable), nil)
+	}
+
+	return newDocTransformer("expiry", p, path, extra, e.apply)
+}
+
+// ExpiryConfig provides configuration options for the expiry transformer.
+// formats are either go time layouts or one of epoch, epoch_seconds, epoch_millis or rfc3339
+type ExpiryConfig struct {
+	Namespace   string   `json:"namespace" doc:"the set of namespaces to transform"`
+	From        string   `json:"from" doc:"the dotted path of the timestamp the ttl is counted from, i.e. created_at, unset for now"`
+	TTL         string   `json:"ttl" doc:"how long documents are kept, format must be parsable by time.ParseDuration, or a whole number of days or weeks, i.e. 30d or 2w"`
+	Target      string   `json:"target" doc:"the dotted path of the field to write the expiry to, i.e. expires_at"`
+	Formats     []string `json:"formats" doc:"the formats of the from timestamp, tried in order, defaults to those of the timeparse transformer"`
+	Output      string   `json:"output" doc:"the format of the expiry, rfc3339 (the default), epoch_seconds, epoch_millis or a go time layout"`
+	Missing     string   `json:"missing" doc:"what to do when the from timestamp is missing or null, pass (leave the target unset, the default), now (count the ttl from now), null (set the target to null), drop or error"`
+	Unparseable string   `json:"unparseable" doc:"what to do when the from timestamp matches none of the formats, error (the default), null, pass or drop"`
+}
+
+type expiry struct {
+	from        string
+	ttl         time.Duration
+	target      string
+	formats     []string
+	output      string
+	missing     string
+	unparseable string
+	now         func() time.Time
+}
+
+func (e *expiry) apply(msg *message.Msg, doc map[string]interface{}) error {
+	start, policy, err := e.start(doc)
+	switch policy {
+	case "":
+		return setField(doc, e.target, formatTime(start.Add(e.ttl), e.output))
+	case "null":
+		return setField(doc, e.target, nil)
+	case "drop":
+		msg.Op = message.Noop
+	case "error":
+		return err
+	}
+	return nil
+}
+
+// start returns the time the ttl is counted from, or the policy that applies if there isn't one
+func (e *expiry) start(doc map[string]interface{}) (time.Time, string, error) {
+	if e.from == "" {
+		return e.now(), "", nil
+	}
+	v, ok := getField(doc, e.from)
+	if !ok || v == nil {
+		if e.missing == "now" {
+			return e.now(), "", nil
+		}
+		return time.Time{}, e.missing, fmt.Errorf("%s is missing", e.from)
+	}
+	t, ok := parseTime(v, e.formats)
+	if !ok {
+		return time.Time{}, e.unparseable, fmt.Errorf("can't parse %s (%v) as a time", e.from, v)
+	}
+	return t, "", nil
+}
+
+// parseTTL parses a duration as time.ParseDuration does, or a whole number of days or weeks, which
+// time.ParseDuration doesn't accept, but retention periods are usually given in
+func parseTTL(text string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(text, "d"):
+		unit = durationUnits["days"]
+	case strings.HasSuffix(text, "w"):
+		unit = durationUnits["weeks"]
+	default:
+		ttl, err := time.ParseDuration(text)
+		if err != nil || ttl <= 0 {
+			return 0, fmt.Errorf("unable to parse ttl %s", text)
+		}
+		return ttl, nil
+	}
+	n, err := strconv.Atoi(text[:len(text)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("unable to parse ttl %s", text)
+	}
+	return time.Duration(n) * unit, nil
+}
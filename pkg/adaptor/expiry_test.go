@@ -0,0 +1,147 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestExpiry(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"from": "created_at", "ttl": "30d", "target": "expires_at"},
+			map[string]interface{}{"created_at": "2017-07-14T02:40:00Z"},
+			map[string]interface{}{"created_at": "2017-07-14T02:40:00Z", "expires_at": "2017-08-13T02:40:00Z"},
+			false,
+		},
+		{
+			// timestamps in other formats, nested fields and epoch output
+			Config{"from": "order.created", "ttl": "90m", "target": "order.expires", "output": "epoch_seconds"},
+			map[string]interface{}{"order": map[string]interface{}{"created": 1500000000}},
+			map[string]interface{}{"order": map[string]interface{}{"created": 1500000000, "expires": int64(1500005400)}},
+			false,
+		},
+		{
+			Config{"from": "a", "ttl": "2w", "target": "expires", "output": "epoch_millis", "formats": []string{"02/01/2006"}},
+			map[string]interface{}{"a": "01/07/2017"},
+			map[string]interface{}{"a": "01/07/2017", "expires": int64(1500076800000)},
+			false,
+		},
+		// missing timestamps leave the target unset by default
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires"},
+			map[string]interface{}{"a": nil},
+			map[string]interface{}{"a": nil},
+			false,
+		},
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires", "missing": "null"},
+			map[string]interface{}{},
+			map[string]interface{}{"expires": nil},
+			false,
+		},
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires", "missing": "drop"},
+			map[string]interface{}{},
+			nil,
+			false,
+		},
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires", "missing": "error"},
+			map[string]interface{}{},
+			nil,
+			true,
+		},
+		// unparseable timestamps are errors by default
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires"},
+			map[string]interface{}{"a": "yesterday"},
+			nil,
+			true,
+		},
+		{
+			Config{"from": "a", "ttl": "1h", "target": "expires", "unparseable": "pass"},
+			map[string]interface{}{"a": true},
+			map[string]interface{}{"a": true},
+			false,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "expiry", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.conf, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%v: expected an ERROR, got %v", v.conf, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestExpiryFromNow(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		ttl  time.Duration
+	}{
+		{Config{"ttl": "720h", "target": "expires_at"}, map[string]interface{}{}, 720 * time.Hour},
+		// documents without the from timestamp can count from now instead
+		{Config{"from": "created_at", "ttl": "7d", "target": "expires_at", "missing": "now"}, map[string]interface{}{}, 7 * 24 * time.Hour},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "expiry", v.conf)
+		before := time.Now()
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		expires, err := time.Parse(time.RFC3339Nano, out.Map()["expires_at"].(string))
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		if d := expires.Sub(before.Add(v.ttl)); d < 0 || d > time.Second {
+			t.Errorf("%v: expected about %s, got %s", v.conf, before.Add(v.ttl), expires)
+		}
+	}
+}
+
+func TestExpiryBadConfig(t *testing.T) {
+	data := []Config{
+		{"target": "expires"},
+		{"ttl": "1h"},
+		{"ttl": "soon", "target": "expires"},
+		{"ttl": "-1h", "target": "expires"},
+		{"ttl": "1.5d", "target": "expires"},
+		{"ttl": "1h", "target": "expires", "output": "epoch"},
+		{"ttl": "1h", "target": "expires", "missing": "skip"},
+		{"ttl": "1h", "target": "expires", "unparseable": "keep"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewExpiry(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package adaptor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewExplode creates a transformer that pivots a map valued field into one message per entry, i.e.
+// {"_id": 1, "metrics": {"cpu": 1, "mem": 2}} becomes {"_id": "1-cpu", "key": "cpu", "value": 1} and
+// {"_id": "1-mem", "key": "mem", "value": 2}.  Each message's _id is derived from the document's _id and the key,
+// so that the messages are distinct and replays overwrite rather than duplicate them
+func NewExplode(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ExplodeConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Field == "" {
+		return nil, NewError(CRITICAL, path, "explode config must contain a field", nil)
+	}
+
+	e := &explode{
+		field:      conf.Field,
+		keyField:   conf.KeyField,
+		valueField: conf.ValueField,
+		include:    conf.Include,
+		missing:    conf.Missing,
+		nonMap:     conf.NonMap,
+	}
+	if e.keyField == "" {
+		e.keyField = "key"
+	}
+	if e.valueField == "" {
+		e.valueField = "value"
+	}
+	for _, policy := range []*string{&e.missing, &e.nonMap} {
+		switch *policy {
+		case "":
+			*policy = "pass"
+		case "pass", "drop", "error":
+		default:
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown policy (%s), must be pass, drop or error", *policy), nil)
+		}
+	}
+
+	return newDocSplitter("explode", p, path, extra, e.apply)
+}
+
+// ExplodeConfig provides configuration options for the explode transformer
+type ExplodeConfig struct {
+	Namespace  string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Field      string   `json:"field" doc:"the dotted path of the map to explode"`
+	KeyField   string   `json:"keyfield" doc:"the field to put each entry's key in, defaults to key"`
+	ValueField string   `json:"valuefield" doc:"the field to put each entry's value in, defaults to value"`
+	Include    []string `json:"include" doc:"the dotted paths of the parent fields to copy into each message, * copies every other top level field"`
+	Missing    string   `json:"missing" doc:"what to do when the field is missing or null, pass (send the document on untouched, the default), drop or error"`
+	NonMap     string   `json:"nonmap" doc:"what to do when the field isn't a map, pass (the default), drop or error"`
+}
+
+type explode struct {
+	field      string
+	keyField   string
+	valueField string
+	include    []string
+	missing    string
+	nonMap     string
+}
+
+// apply returns a message per entry of the map, in key order.  an empty map produces no messages
+func (e *explode) apply(msg *message.Msg, doc map[string]interface{}) ([]*message.Msg, error) {
+	v, ok := getField(doc, e.field)
+	if !ok || v == nil {
+		return e.invalid(msg, e.missing, fmt.Errorf("%s is missing", e.field))
+	}
+	entries, ok := asMap(v)
+	if !ok {
+		return e.invalid(msg, e.nonMap, fmt.Errorf("%s is a %T, not a map", e.field, v))
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	id, hasID := doc["_id"]
+	var msgs []*message.Msg
+	for _, k := range keys {
+		out := make(map[string]interface{})
+		if err := e.copyParent(doc, out); err != nil {
+			return nil, err
+		}
+		if hasID {
+			out["_id"] = fmt.Sprintf("%s-%s", templateString(id), k)
+		}
+		if err := setField(out, e.keyField, k); err != nil {
+			return nil, err
+		}
+		if err := setField(out, e.valueField, entries[k]); err != nil {
+			return nil, err
+		}
+
+		m := message.NewMsg(msg.Op, out, msg.Namespace)
+		m.Timestamp = msg.Timestamp
+		m.Version = msg.Version
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+// copyParent copies the included fields of the document, other than the exploded field, into out
+func (e *explode) copyParent(doc, out map[string]interface{}) error {
+	for _, path := range e.include {
+		if path == "*" {
+			for k, v := range doc {
+				if k != e.field && k != "_id" {
+					out[k] = v
+				}
+			}
+			continue
+		}
+		if v, ok := getField(doc, path); ok && path != e.field {
+			if err := setField(out, path, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *explode) invalid(msg *message.Msg, policy string, err error) ([]*message.Msg, error) {
+	switch policy {
+	case "drop":
+		return nil, nil
+	case "error":
+		return nil, err
+	}
+	return []*message.Msg{msg}, nil
+}
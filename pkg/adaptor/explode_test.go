@@ -0,0 +1,139 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// explodeOne runs a single message through the transformer, returning every message it emits in order
+func explodeOne(tr *docTransformer, in map[string]interface{}) ([]*message.Msg, error) {
+	child := pipe.NewPipe(tr.pipe, "path/child")
+	sent := make(chan []*message.Msg)
+	go func() {
+		var msgs []*message.Msg
+		for m := range child.In {
+			msgs = append(msgs, m)
+		}
+		sent <- msgs
+	}()
+
+	out, err := tr.transformOne(message.NewMsg(message.Insert, in, "database.collection"))
+	close(child.In)
+	msgs := <-sent
+	if out != nil {
+		msgs = append(msgs, out)
+	}
+	return msgs, err
+}
+
+func TestExplode(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  []map[string]interface{}
+	}{
+		{
+			// a message per entry, in key order
+			Config{"field": "metrics"},
+			map[string]interface{}{"_id": "1", "host": "a", "metrics": bson.M{"mem": 2, "cpu": 1}},
+			[]map[string]interface{}{
+				{"_id": "1-cpu", "key": "cpu", "value": 1},
+				{"_id": "1-mem", "key": "mem", "value": 2},
+			},
+		},
+		{
+			// copying parent fields, and renaming the key and value
+			Config{"field": "stats.metrics", "keyfield": "name", "valuefield": "reading.value", "include": []interface{}{"host", "stats.at"}},
+			map[string]interface{}{"_id": bson.ObjectIdHex("5936fc2e4e19c1c4a358e0a5"), "host": "a", "stats": bson.M{"at": 10, "metrics": bson.M{"cpu": 1}}},
+			[]map[string]interface{}{
+				{"_id": "5936fc2e4e19c1c4a358e0a5-cpu", "host": "a", "stats": map[string]interface{}{"at": 10}, "name": "cpu", "reading": map[string]interface{}{"value": 1}},
+			},
+		},
+		{
+			// or every other field
+			Config{"field": "metrics", "include": []interface{}{"*"}},
+			map[string]interface{}{"_id": "1", "host": "a", "metrics": bson.M{"cpu": 1, "mem": 2}},
+			[]map[string]interface{}{
+				{"_id": "1-cpu", "host": "a", "key": "cpu", "value": 1},
+				{"_id": "1-mem", "host": "a", "key": "mem", "value": 2},
+			},
+		},
+		{
+			// an empty map produces nothing
+			Config{"field": "metrics"},
+			map[string]interface{}{"_id": "1", "metrics": bson.M{}},
+			nil,
+		},
+		{
+			// missing and non map fields pass through by default
+			Config{"field": "metrics"},
+			map[string]interface{}{"_id": "1"},
+			[]map[string]interface{}{{"_id": "1"}},
+		},
+		{
+			Config{"field": "metrics"},
+			map[string]interface{}{"_id": "1", "metrics": []interface{}{1, 2}},
+			[]map[string]interface{}{{"_id": "1", "metrics": []interface{}{1, 2}}},
+		},
+		{
+			// or are dropped
+			Config{"field": "metrics", "missing": "drop", "nonmap": "drop"},
+			map[string]interface{}{"_id": "1", "metrics": nil},
+			nil,
+		},
+		{
+			Config{"field": "metrics", "missing": "drop", "nonmap": "drop"},
+			map[string]interface{}{"_id": "1", "metrics": "cpu"},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "explode", v.conf)
+		msgs, err := explodeOne(tr, v.in)
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		var out []map[string]interface{}
+		for _, m := range msgs {
+			if m.Op != message.Insert || m.Namespace != "database.collection" {
+				t.Errorf("%+v: expected an insert to database.collection, got %s to %s", v.in, m.Op, m.Namespace)
+			}
+			out = append(out, m.Map())
+		}
+		if !reflect.DeepEqual(out, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out)
+		}
+	}
+}
+
+func TestExplodeError(t *testing.T) {
+	tr, errs := newTestDocTransformer(t, "explode", Config{"field": "metrics", "nonmap": "error"})
+	msgs, err := explodeOne(tr, map[string]interface{}{"_id": "1", "metrics": 1})
+	if err != nil || len(msgs) != 0 {
+		t.Fatalf("expected the message to be dropped, got %+v (%v)", msgs, err)
+	}
+	if err := <-errs; err.(Error).Lvl != ERROR {
+		t.Errorf("expected an ERROR, got %v", err)
+	}
+}
+
+func TestExplodeBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "database./.*/"},
+		{"namespace": "database./.*/", "field": "metrics", "missing": "ignore"},
+		{"namespace": "database./.*/", "field": "metrics", "nonmap": "skip"},
+	}
+
+	for _, v := range data {
+		if _, err := NewExplode(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
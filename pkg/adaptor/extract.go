@@ -0,0 +1,107 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewExtract creates a transformer that parses fields out of a string field with a regex, writing each named
+// group it captures to a field of the same name, i.e. the pattern
+// ^(?P<ip>\S+) (?P<method>[A-Z]+) (?P<path>\S+) (?P<status>\d{3})$ splits an access log line into ip, method,
+// path and status.  Groups are written under target if it's set, and groups that don't take part in the match
+// aren't written.  Missing, null and non string fields don't match
+func NewExtract(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ExtractConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Field == "" || conf.Pattern == "" {
+		return nil, NewError(CRITICAL, path, "extract config must contain a field and a pattern", nil)
+	}
+
+	e := &extract{field: conf.Field, target: conf.Target, defaults: conf.Defaults, noMatch: conf.NoMatch}
+	var err error
+	if e.pattern, err = regexp.Compile(conf.Pattern); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (can't compile pattern, %s)", err.Error()), nil)
+	}
+	named := false
+	for _, name := range e.pattern.SubexpNames() {
+		named = named || name != ""
+	}
+	if !named {
+		return nil, NewError(CRITICAL, path, "bad config (the pattern has no named groups, i.e. (?P<status>\\d+))", nil)
+	}
+	switch e.noMatch {
+	case "":
+		e.noMatch = "pass"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown nomatch policy (%s), must be pass, drop or error", e.noMatch), nil)
+	}
+
+	return newDocTransformer("extract", p, path, extra, e.apply)
+}
+
+// ExtractConfig provides configuration options for the extract transformer
+type ExtractConfig struct {
+	Namespace string                 `json:"namespace" doc:"the set of namespaces to transform"`
+	Field     string                 `json:"field" doc:"the dotted path of the string field to parse, i.e. message"`
+	Pattern   string                 `json:"pattern" doc:"the regex to match the field against, each named group, i.e. (?P<status>\\d+), is written to a field"`
+	Target    string                 `json:"target" doc:"the dotted path of the document to write the groups into, defaults to the top of the document"`
+	NoMatch   string                 `json:"nomatch" doc:"what to do when the field doesn't match, pass (leave the document as it is, apart from the defaults, the default), drop or error"`
+	Defaults  map[string]interface{} `json:"defaults" doc:"values to write, keyed by group name, for the groups of a field that doesn't match, when the nomatch policy is pass"`
+}
+
+type extract struct {
+	field    string
+	pattern  *regexp.Regexp
+	target   string
+	defaults map[string]interface{}
+	noMatch  string
+}
+
+func (e *extract) apply(msg *message.Msg, doc map[string]interface{}) error {
+	v, _ := getField(doc, e.field)
+	str, ok := v.(string)
+	var match []int
+	if ok {
+		match = e.pattern.FindStringSubmatchIndex(str)
+	}
+	if match != nil {
+		for i, name := range e.pattern.SubexpNames() {
+			if name == "" || match[2*i] < 0 {
+				continue
+			}
+			if err := setField(doc, e.path(name), str[match[2*i]:match[2*i+1]]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch e.noMatch {
+	case "drop":
+		msg.Op = message.Noop
+		return nil
+	case "error":
+		return fmt.Errorf("%s (%v) doesn't match the pattern", e.field, v)
+	}
+	for name, value := range e.defaults {
+		if err := setField(doc, e.path(name), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path returns the dotted path a group is written to
+func (e *extract) path(name string) string {
+	if e.target == "" {
+		return name
+	}
+	return e.target + "." + name
+}
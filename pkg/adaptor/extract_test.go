@@ -0,0 +1,104 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestExtract(t *testing.T) {
+	access := `^(?P<ip>\S+) (?P<method>[A-Z]+) (?P<path>\S+) (?P<status>\d{3})(?: (?P<ms>\d+)ms)?$`
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"field": "message", "pattern": access},
+			map[string]interface{}{"_id": "1", "message": "10.0.0.1 GET /orders 200 12ms"},
+			map[string]interface{}{"_id": "1", "message": "10.0.0.1 GET /orders 200 12ms", "ip": "10.0.0.1", "method": "GET", "path": "/orders", "status": "200", "ms": "12"},
+			false,
+		},
+		{
+			// groups that don't take part in the match aren't written, and the groups can go under a target
+			Config{"field": "log.line", "pattern": access, "target": "http"},
+			map[string]interface{}{"_id": "1", "log": map[string]interface{}{"line": "10.0.0.2 POST /login 401"}},
+			map[string]interface{}{"_id": "1", "log": map[string]interface{}{"line": "10.0.0.2 POST /login 401"}, "http": map[string]interface{}{"ip": "10.0.0.2", "method": "POST", "path": "/login", "status": "401"}},
+			false,
+		},
+		// lines that don't match are passed on as they are by default
+		{
+			Config{"field": "message", "pattern": access},
+			map[string]interface{}{"_id": "1", "message": "server started"},
+			map[string]interface{}{"_id": "1", "message": "server started"},
+			false,
+		},
+		{
+			Config{"field": "message", "pattern": access, "defaults": map[string]interface{}{"status": "unknown"}},
+			map[string]interface{}{"_id": "1", "message": "server started"},
+			map[string]interface{}{"_id": "1", "message": "server started", "status": "unknown"},
+			false,
+		},
+		{
+			Config{"field": "message", "pattern": access, "nomatch": "drop"},
+			map[string]interface{}{"_id": "1", "message": "server started"},
+			nil,
+			false,
+		},
+		{
+			Config{"field": "message", "pattern": access, "nomatch": "error"},
+			map[string]interface{}{"_id": "1", "message": "server started"},
+			nil,
+			true,
+		},
+		{
+			// missing and non string fields don't match
+			Config{"field": "message", "pattern": access, "nomatch": "error"},
+			map[string]interface{}{"_id": "1", "message": 200},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "extract", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.conf, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%v: expected an ERROR, got %v", v.conf, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestExtractBadConfig(t *testing.T) {
+	data := []Config{
+		{"pattern": "(?P<a>.*)"},
+		{"field": "message"},
+		{"field": "message", "pattern": "(?P<a>"},
+		{"field": "message", "pattern": "(.*)"},
+		{"field": "message", "pattern": "(?P<a>.*)", "nomatch": "null"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewExtract(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package adaptor
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// asMap returns the value as a map[string]interface{} if it's a document, documents
+// from mongo come through as bson.M so those are handled here as well
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case bson.M:
+		return map[string]interface{}(m), true
+	default:
+		return nil, false
+	}
+}
+
+// getField returns the value stored in the document at the given dotted path, i.e. "address.city".
+// the second return value is false if any element of the path doesn't exist
+func getField(doc map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := asMap(doc[key])
+		if !ok {
+			return nil, false
+		}
+		doc = next
+	}
+
+	v, ok := doc[keys[len(keys)-1]]
+	return v, ok
+}
+
+// setField stores the value in the document at the given dotted path, creating any
+// missing intermediate documents.  An error is returned if an intermediate value exists
+// but isn't a document
+func setField(doc map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	for i, key := range keys[:len(keys)-1] {
+		v, exists := doc[key]
+		if !exists || v == nil {
+			next := make(map[string]interface{})
+			doc[key] = next
+			doc = next
+			continue
+		}
+		next, ok := asMap(v)
+		if !ok {
+			return fmt.Errorf("can't set %s, %s is a %T, not a document", path, strings.Join(keys[:i+1], "."), v)
+		}
+		doc = next
+	}
+
+	doc[keys[len(keys)-1]] = value
+	return nil
+}
+
+// deleteField removes the value at the given dotted path from the document, if it exists
+func deleteField(doc map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := asMap(doc[key])
+		if !ok {
+			return
+		}
+		doc = next
+	}
+
+	delete(doc, keys[len(keys)-1])
+}
+
+// newMongoTimestamp returns the oplog timestamp of the i'th operation in second s
+func newMongoTimestamp(s, i int) bson.MongoTimestamp {
+	return bson.MongoTimestamp(int64(s)<<32 + int64(i))
+}
@@ -0,0 +1,65 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestGetField(t *testing.T) {
+	doc := map[string]interface{}{
+		"name":    "nick",
+		"address": bson.M{"city": "nyc", "geo": map[string]interface{}{"lat": 40.7}},
+	}
+
+	data := []struct {
+		path  string
+		value interface{}
+		ok    bool
+	}{
+		{"name", "nick", true},
+		{"address.city", "nyc", true},
+		{"address.geo.lat", 40.7, true},
+		{"address.zip", nil, false},
+		{"name.first", nil, false},
+		{"missing.field", nil, false},
+	}
+
+	for _, v := range data {
+		value, ok := getField(doc, v.path)
+		if ok != v.ok || !reflect.DeepEqual(value, v.value) {
+			t.Errorf("%s: expected %v (%t), got %v (%t)", v.path, v.value, v.ok, value, ok)
+		}
+	}
+}
+
+func TestSetField(t *testing.T) {
+	doc := map[string]interface{}{"name": "nick", "address": bson.M{"city": "nyc"}}
+
+	if err := setField(doc, "address.zip", "10001"); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if err := setField(doc, "geo.lat", 40.7); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if err := setField(doc, "name.first", "nick"); err == nil {
+		t.Errorf("expected an error setting a field below a string")
+	}
+
+	expected := map[string]interface{}{
+		"name":    "nick",
+		"address": bson.M{"city": "nyc", "zip": "10001"},
+		"geo":     map[string]interface{}{"lat": 40.7},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("expected %+v, got %+v", expected, doc)
+	}
+
+	deleteField(doc, "address.city")
+	deleteField(doc, "name.first")
+	deleteField(doc, "missing")
+	if _, ok := getField(doc, "address.city"); ok {
+		t.Errorf("expected address.city to be deleted")
+	}
+}
@@ -1,15 +1,21 @@
 package adaptor
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/compose/transporter/pkg/message"
 	"github.com/compose/transporter/pkg/pipe"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 )
 
 // File is an adaptor that can be used as a
@@ -19,6 +25,22 @@ type File struct {
 	pipe       *pipe.Pipe
 	path       string
 	filehandle *os.File
+
+	compression string
+	writer      io.Writer      // the filehandle, or the encoder writing to it
+	encoder     io.WriteCloser // the compressing writer, if any, closed before the file so it's flushed
+	closeLock   sync.Mutex
+
+	format     string
+	serializer Serializer
+
+	onError *errorPolicy
+	limit   *sourceLimit
+
+	checkpoint   int64 // the offset just past the last document sent, read by Checkpoint while the source runs
+	resumeOffset int64 // set by Resume, reading starts here rather than at the top of the file
+
+	sinkCounts
 }
 
 // NewFile returns a File Adaptor
@@ -31,11 +53,65 @@ func NewFile(p *pipe.Pipe, path string, extra Config) (StopStartListener, error)
 		return nil, NewError(CRITICAL, path, fmt.Sprintf("Can't configure adaptor (%s)", err.Error()), nil)
 	}
 
-	return &File{
-		uri:  conf.URI,
-		pipe: p,
-		path: path,
-	}, nil
+	onError, err := newErrorPolicy(p, path, extra, onErrorSkip)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("Can't configure adaptor (%s)", err.Error()), nil)
+	}
+
+	limit, err := newSourceLimit(conf.Skip, conf.Limit)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("Can't configure adaptor (%s)", err.Error()), nil)
+	}
+
+	compression, err := fileCompression(conf.URI, conf.Compression)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("Can't configure adaptor (%s)", err.Error()), nil)
+	}
+
+	serializer, err := newSerializer(conf.Format)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("Can't configure adaptor (%s)", err.Error()), nil)
+	}
+
+	f := &File{
+		uri:         conf.URI,
+		pipe:        p,
+		path:        path,
+		onError:     onError,
+		limit:       limit,
+		compression: compression,
+		format:      conf.Format,
+		serializer:  serializer,
+	}
+	onError.counts = &f.sinkCounts
+	return f, nil
+}
+
+// fileCompression returns the compression to use, the one configured or else the one implied by the file's extension
+func fileCompression(uri, compression string) (string, error) {
+	if compression == "" {
+		switch {
+		case strings.HasSuffix(uri, ".gz"):
+			compression = "gzip"
+		case strings.HasSuffix(uri, ".sz"):
+			compression = "snappy"
+		case strings.HasSuffix(uri, ".zst"):
+			compression = "zstd"
+		default:
+			compression = "none"
+		}
+	}
+
+	switch compression {
+	case "none":
+	case "gzip", "snappy", "zstd":
+		if !strings.HasPrefix(uri, "file://") {
+			return "", fmt.Errorf("%s compression is only supported for files", compression)
+		}
+	default:
+		return "", fmt.Errorf("unknown compression (%s), must be none, gzip, snappy or zstd", compression)
+	}
+	return compression, nil
 }
 
 // Start the file adaptor
@@ -61,14 +137,77 @@ func (d *File) Listen() (err error) {
 			d.pipe.Err <- NewError(CRITICAL, d.path, fmt.Sprintf("Can't open output file (%s)", err.Error()), nil)
 			return err
 		}
+		d.writer = d.filehandle
+		switch d.compression {
+		case "gzip":
+			d.encoder = gzip.NewWriter(d.filehandle)
+		case "snappy":
+			d.encoder = snappy.NewBufferedWriter(d.filehandle)
+		case "zstd":
+			if d.encoder, err = zstd.NewWriter(d.filehandle); err != nil {
+				d.pipe.Err <- NewError(CRITICAL, d.path, fmt.Sprintf("Can't open output file (%s)", err.Error()), nil)
+				return err
+			}
+		}
+		if d.encoder != nil {
+			d.writer = d.encoder
+		}
 	}
 
 	return d.pipe.Listen(d.dumpMessage, regexp.MustCompile(`.*`))
 }
 
-// Stop the adaptor
+// Checkpoint returns the offset of the end of the last document sent, checkpoints are only kept for
+// uncompressed files, which can be read from an offset
+func (d *File) Checkpoint() string {
+	offset := atomic.LoadInt64(&d.checkpoint)
+	if offset == 0 || d.compression != "none" {
+		return ""
+	}
+	return strconv.FormatInt(offset, 10)
+}
+
+// Resume reads the file from the checkpoint, rather than from the top
+func (d *File) Resume(token string) error {
+	if !strings.HasPrefix(d.uri, "file://") || d.compression != "none" {
+		return fmt.Errorf("can only resume an uncompressed file")
+	}
+	offset, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || offset <= 0 {
+		return fmt.Errorf("bad file offset (%s)", token)
+	}
+	d.resumeOffset = offset
+	return nil
+}
+
+// Stop the adaptor, once the pipe has stopped nothing else is written so the file can be closed
 func (d *File) Stop() error {
 	d.pipe.Stop()
+
+	d.closeLock.Lock()
+	defer d.closeLock.Unlock()
+	if d.encoder != nil {
+		if err := d.encoder.Close(); err != nil {
+			d.pipe.Err <- NewError(ERROR, d.path, fmt.Sprintf("Error writing to file (%s)", err.Error()), nil)
+		}
+		d.encoder = nil
+	}
+	if d.filehandle != nil {
+		d.filehandle.Close()
+		d.filehandle = nil
+	}
+	return nil
+}
+
+// Flush flushes the compressing writer, if there is one, when the checkpoint's marker arrives
+func (d *File) Flush() error {
+	d.closeLock.Lock()
+	defer d.closeLock.Unlock()
+	if f, ok := d.encoder.(interface {
+		Flush() error
+	}); ok {
+		return f.Flush()
+	}
 	return nil
 }
 
@@ -81,16 +220,62 @@ func (d *File) readFile() (err error) {
 		return err
 	}
 
-	decoder := json.NewDecoder(d.filehandle)
+	if d.resumeOffset > 0 {
+		info, err := d.filehandle.Stat()
+		if err == nil && info.Size() < d.resumeOffset {
+			err = fmt.Errorf("the file is only %d bytes, it's been replaced or truncated", info.Size())
+		}
+		if err == nil {
+			_, err = d.filehandle.Seek(d.resumeOffset, io.SeekStart)
+		}
+		if err != nil {
+			d.pipe.Err <- NewError(CRITICAL, d.path, fmt.Sprintf("Can't resume input file from %d (%s)", d.resumeOffset, err.Error()), nil)
+			return err
+		}
+	}
+	atomic.StoreInt64(&d.checkpoint, d.resumeOffset)
+
+	var r io.Reader = d.filehandle
+	switch d.compression {
+	case "gzip":
+		if r, err = gzip.NewReader(d.filehandle); err != nil {
+			d.pipe.Err <- NewError(CRITICAL, d.path, fmt.Sprintf("Can't read input file (%s)", err.Error()), nil)
+			return err
+		}
+	case "snappy":
+		r = snappy.NewReader(d.filehandle)
+	case "zstd":
+		dec, err := zstd.NewReader(d.filehandle)
+		if err != nil {
+			d.pipe.Err <- NewError(CRITICAL, d.path, fmt.Sprintf("Can't read input file (%s)", err.Error()), nil)
+			return err
+		}
+		defer dec.Close()
+		r = dec
+	}
+
+	decoder := json.NewDecoder(r)
 	for {
 		var doc map[string]interface{}
 		if err := decoder.Decode(&doc); err == io.EOF {
 			break
+		} else if err == io.ErrUnexpectedEOF && d.compression == "none" {
+			// the file is likely still being written, the checkpoint is left at the end of the last
+			// whole document so that the next run reads this one once it's complete
+			d.pipe.Err <- NewError(WARNING, d.path, "The last document in the input file is incomplete, it's left for the next run", nil)
+			break
 		} else if err != nil {
 			d.pipe.Err <- NewError(ERROR, d.path, fmt.Sprintf("Can't marshal document (%s)", err.Error()), nil)
 			return err
 		}
-		d.pipe.Send(message.NewMsg(message.Insert, doc, fmt.Sprintf("file.%s", filename)))
+		more := d.limit.send(d.pipe, message.NewMsg(message.Insert, doc, fmt.Sprintf("file.%s", filename)))
+		if d.pipe.Stopped { // there's no knowing whether the document was sent, so it's sent again on resume
+			break
+		}
+		atomic.StoreInt64(&d.checkpoint, d.resumeOffset+decoder.InputOffset())
+		if !more {
+			break
+		}
 	}
 	return nil
 }
@@ -99,29 +284,37 @@ func (d *File) readFile() (err error) {
  * dump each message to the file
  */
 func (d *File) dumpMessage(msg *message.Msg) (*message.Msg, error) {
-	var line string
+	var (
+		ba  []byte
+		err error
+	)
 
-	if msg.IsMap() {
-		ba, err := json.Marshal(msg.Map())
-		if err != nil {
-			d.pipe.Err <- NewError(ERROR, d.path, fmt.Sprintf("Can't unmarshal document (%s)", err.Error()), msg.Data)
-			return msg, nil
-		}
-		line = string(ba)
-	} else {
-		line = fmt.Sprintf("%v", msg.Data)
+	// json is written a document per line, so the file can be read back, which makes it the same as ndjson
+	text := d.format == "" || d.format == "json" || d.format == "ndjson"
+	switch {
+	case text && !msg.IsMap():
+		ba = []byte(fmt.Sprintf("%v\n", msg.Data))
+	case text:
+		ba, err = ndjsonSerializer{}.Serialize(msg)
+	default:
+		ba, err = d.serializer.Serialize(msg)
+	}
+	if err != nil {
+		d.failedToWrite(1)
+		d.pipe.Err <- NewError(ERROR, d.path, fmt.Sprintf("Can't unmarshal document (%s)", err.Error()), msg.Data)
+		return msg, nil
 	}
 
 	if strings.HasPrefix(d.uri, "stdout://") {
-		fmt.Println(line)
+		os.Stdout.Write(ba)
 	} else {
-		_, err := fmt.Fprintln(d.filehandle, line)
+		_, err := d.writer.Write(ba)
 		if err != nil {
-			d.pipe.Err <- NewError(ERROR, d.path, fmt.Sprintf("Error writing to file (%s)", err.Error()), msg.Data)
-			return msg, nil
+			return msg, d.onError.handle(fmt.Sprintf("Error writing to file (%s)", err.Error()), msg.Data)
 		}
 	}
 
+	d.wrote(1)
 	return msg, nil
 }
 
@@ -129,4 +322,10 @@ func (d *File) dumpMessage(msg *message.Msg) (*message.Msg, error) {
 type FileConfig struct {
 	// URI pointing to the resource.  We only recognize file:// and stdout:// currently
 	URI string `json:"uri" doc:"the uri to connect to, ie stdout://, file:///tmp/output"`
+
+	Format      string `json:"format" doc:"as a sink, the format to write, json (a document per line), ndjson, msgpack or bson. defaults to json, which is the only format the file source reads"`
+	Compression string `json:"compression" doc:"none, gzip, snappy (the framing format) or zstd, defaults to the one implied by the file's extension, .gz, .sz or .zst"`
+
+	Skip  int `json:"skip" doc:"as a source, the number of documents to skip before sending any"`
+	Limit int `json:"limit" doc:"as a source, stop after sending this many documents"`
 }
@@ -0,0 +1,292 @@
+package adaptor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+func TestFileSourceLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "in.json")
+	var lines string
+	for i := 0; i < 10; i++ {
+		lines += fmt.Sprintf("{\"_id\": %d}\n", i)
+	}
+	if err := ioutil.WriteFile(filename, []byte(lines), 0644); err != nil {
+		t.Fatalf("can't write the input file, %s", err)
+	}
+
+	data := []struct {
+		conf Config
+		ids  []float64
+	}{
+		{Config{"limit": 5}, []float64{0, 1, 2, 3, 4}},
+		{Config{"skip": 3, "limit": 2}, []float64{3, 4}},
+		{Config{"skip": 8}, []float64{8, 9}},
+		{Config{"limit": 20}, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+
+	for _, v := range data {
+		v.conf["uri"] = "file://" + filename
+		p := pipe.NewPipe(nil, "path")
+		out := pipe.NewPipe(p, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(p)
+
+		f, err := NewFile(p, "path", v.conf)
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		done := make(chan error)
+		go func() { done <- f.Start() }()
+
+		var ids []float64
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				ids = append(ids, msg.Map()["_id"].(float64))
+			case err := <-done:
+				if err != nil {
+					t.Errorf("%v: unexpected error, %s", v.conf, err)
+				}
+				break A
+			case <-time.After(time.Second):
+				t.Fatalf("%v: expected the source to stop", v.conf)
+			}
+		}
+
+		if !reflect.DeepEqual(ids, v.ids) {
+			t.Errorf("%v: expected %v, got %v", v.conf, v.ids, ids)
+		}
+		if !p.Stopped {
+			t.Errorf("%v: expected the pipe to be stopped", v.conf)
+		}
+	}
+}
+
+// readTestFile runs a file source, resuming from the token if there is one, and returns the ids it sent,
+// its checkpoint and the errors it reported
+func readTestFile(t *testing.T, conf Config, token string) ([]float64, string, []error) {
+	p := pipe.NewPipe(nil, "path")
+	p.Err = make(chan error, 10) // buffered, so every error reported is in hand once the source returns
+	out := pipe.NewPipe(p, "out")
+
+	a, err := NewFile(p, "path", conf)
+	if err != nil {
+		t.Fatalf("%v: unexpected error, %s", conf, err)
+	}
+	f := a.(*File)
+	if token != "" {
+		if err := f.Resume(token); err != nil {
+			t.Fatalf("%v: can't resume from %s, %s", conf, token, err)
+		}
+	}
+	done := make(chan error)
+	go func() { done <- f.Start() }()
+
+	var ids []float64
+	for {
+		select {
+		case msg := <-out.In:
+			ids = append(ids, msg.Map()["_id"].(float64))
+			continue
+		case err := <-done:
+			if err != nil {
+				t.Errorf("%v: unexpected error, %s", conf, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v: expected the source to stop", conf)
+		}
+		break
+	}
+
+	var reported []error
+	for len(p.Err) > 0 {
+		reported = append(reported, <-p.Err)
+	}
+	return ids, f.Checkpoint(), reported
+}
+
+func TestFileSourceResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "in.json")
+	// the last line is still being written
+	lines := "{\"_id\": 0}\n{\"_id\": 1}\n{\"_id\": 2}\n{\"_id\": 3}\n{\"_id\": 4}\n{\"_id\": 5, \"na"
+	if err := ioutil.WriteFile(filename, []byte(lines), 0644); err != nil {
+		t.Fatalf("can't write the input file, %s", err)
+	}
+
+	// the first run stops, as though it crashed, after 3 lines
+	ids, token, _ := readTestFile(t, Config{"uri": "file://" + filename, "limit": 3}, "")
+	if !reflect.DeepEqual(ids, []float64{0, 1, 2}) || token != "32" {
+		t.Fatalf("expected ids [0 1 2] and a checkpoint just past the third document, got %v and %q", ids, token)
+	}
+
+	// the next carries on from line 4, leaving the incomplete last line
+	ids, token, errs := readTestFile(t, Config{"uri": "file://" + filename}, token)
+	if !reflect.DeepEqual(ids, []float64{3, 4}) || token != "54" {
+		t.Errorf("expected ids [3 4] and a checkpoint just past the fifth document, got %v and %q", ids, token)
+	}
+	if len(errs) != 1 || errs[0].(Error).Lvl != WARNING {
+		t.Errorf("expected a warning for the incomplete line, got %v", errs)
+	}
+
+	// and once the line is complete, it's read by the run after that
+	f, _ := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("me\": \"six\"}\n{\"_id\": 6}\n")
+	f.Close()
+	if ids, _, errs = readTestFile(t, Config{"uri": "file://" + filename}, token); !reflect.DeepEqual(ids, []float64{5, 6}) || len(errs) != 0 {
+		t.Errorf("expected ids [5 6], got %v and %v", ids, errs)
+	}
+
+	// a file that's shorter than the checkpoint has been replaced
+	p := pipe.NewPipe(nil, "path")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(p)
+	a, _ := NewFile(p, "path", Config{"uri": "file://" + filename})
+	a.(*File).Resume("1000")
+	if err := a.Start(); err == nil {
+		t.Errorf("expected an error resuming past the end of the file")
+	}
+	// and compressed files can't be resumed
+	a, _ = NewFile(p, "path", Config{"uri": "file://" + filename + ".gz"})
+	if err := a.(*File).Resume("33"); err == nil {
+		t.Errorf("expected an error resuming a compressed file")
+	}
+}
+
+func TestSourceLimitBadConfig(t *testing.T) {
+	data := []Config{
+		{"uri": "file:///tmp/in.json", "limit": -1},
+		{"uri": "file:///tmp/in.json", "skip": -1},
+	}
+
+	for _, v := range data {
+		if _, err := NewFile(nil, "path", v); err == nil {
+			t.Errorf("%v: expected an error", v)
+		}
+	}
+}
+
+func TestFileCompression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []struct {
+		filename string
+		conf     Config
+		magic    string // the start of the written file
+	}{
+		{"out.json", Config{}, `{"_id":0`},
+		{"out.json.gz", Config{}, "\x1f\x8b"},
+		{"out.json.sz", Config{}, "\xff\x06\x00\x00sNaPpY"},
+		{"out.json", Config{"compression": "snappy"}, "\xff\x06\x00\x00sNaPpY"},
+		{"out.gzip", Config{"compression": "gzip"}, "\x1f\x8b"},
+		{"out.json.zst", Config{}, "\x28\xb5\x2f\xfd"},
+		{"out.json", Config{"compression": "zstd"}, "\x28\xb5\x2f\xfd"},
+	}
+
+	for _, v := range data {
+		filename := filepath.Join(dir, v.filename)
+		v.conf["uri"] = "file://" + filename
+
+		// write enough similar documents to span several snappy chunks
+		sink := pipe.NewPipe(pipe.NewPipe(nil, "source"), "path")
+		f, err := NewFile(sink, "path", v.conf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", v.filename, err)
+		}
+		out := f.(*File)
+		go out.Listen()
+		for i := 0; i < 5000; i++ {
+			sink.In <- message.NewMsg(message.Insert, map[string]interface{}{"_id": i, "name": fmt.Sprintf("document %d", i)}, "db.coll")
+		}
+		out.Stop()
+
+		ba, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("%s: can't read the output file, %s", v.filename, err)
+		}
+		if !strings.HasPrefix(string(ba), v.magic) {
+			t.Errorf("%s: expected the file to start with %q, got %q", v.filename, v.magic, ba[:10])
+		}
+
+		// and read them back
+		source := pipe.NewPipe(nil, "path")
+		in := pipe.NewPipe(source, "in")
+		go func(p *pipe.Pipe) {
+			for err := range p.Err {
+				t.Errorf("%s: unexpected error, %s", v.filename, err)
+			}
+		}(source)
+		f, err = NewFile(source, "path", v.conf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", v.filename, err)
+		}
+		done := make(chan error)
+		go func() { done <- f.Start() }()
+
+		count := 0
+	A:
+		for {
+			select {
+			case msg := <-in.In:
+				if msg.Map()["_id"] != float64(count) || msg.Map()["name"] != fmt.Sprintf("document %d", count) {
+					t.Fatalf("%s: expected document %d, got %v", v.filename, count, msg.Data)
+				}
+				count++
+			case err := <-done:
+				if err != nil {
+					t.Errorf("%s: unexpected error, %s", v.filename, err)
+				}
+				break A
+			}
+		}
+		if count != 5000 {
+			t.Errorf("%s: expected 5000 documents, got %d", v.filename, count)
+		}
+	}
+}
+
+func TestFileCompressionBadConfig(t *testing.T) {
+	data := []Config{
+		{"uri": "file:///tmp/out.json", "compression": "lz4"},
+		{"uri": "stdout://", "compression": "gzip"},
+		{"uri": "stdout://", "compression": "zstd"},
+	}
+
+	for _, v := range data {
+		if _, err := NewFile(nil, "path", v); err == nil {
+			t.Errorf("%v: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package adaptor
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// the networks that can't be located, private, loopback, link local, shared and unique local addresses
+var geoIPPrivateNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12", "192.168.0.0/16", "::1/128", "fc00::/7", "fe80::/10"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// NewGeoIP creates a transformer that locates the address in a field using a MaxMind GeoLite2 or GeoIP2 city
+// or country database, i.e. {"ip": "8.8.8.8"} becomes {"ip": "8.8.8.8", "geoip": {"country_code": "US",
+// "country_name": "United States", "city_name": ..., "location": {"lat": 37.75, "lon": -97.82}}}.  The location
+// is in elasticsearch's geo_point shape.  Addresses that can't be located are handled by the unresolved policy
+func NewGeoIP(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf GeoIPConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Field == "" {
+		return nil, NewError(CRITICAL, path, "geoip config must contain a field", nil)
+	}
+	if conf.Database == "" {
+		return nil, NewError(CRITICAL, path, "geoip config must contain a database", nil)
+	}
+
+	g := &geoIP{field: conf.Field, target: conf.Target, unresolved: conf.Unresolved}
+	if g.target == "" {
+		g.target = "geoip"
+	}
+	switch g.unresolved {
+	case "":
+		g.unresolved = "skip"
+	case "skip", "null":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unresolved policy (%s), must be skip or null", g.unresolved), nil)
+	}
+
+	db, err := maxminddb.Open(conf.Database)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't open geoip database (%s)", err.Error()), nil)
+	}
+	g.db = db
+
+	return newDocTransformer("geoip", p, path, extra, g.apply)
+}
+
+// GeoIPConfig provides configuration options for the geoip transformer
+type GeoIPConfig struct {
+	Namespace  string `json:"namespace" doc:"the set of namespaces to transform"`
+	Field      string `json:"field" doc:"the dotted path of the field holding the ip address"`
+	Target     string `json:"target" doc:"the dotted path of the field to write the location to, defaults to geoip"`
+	Database   string `json:"database" doc:"the path of the MaxMind GeoLite2 or GeoIP2 database (.mmdb), loaded at startup"`
+	Unresolved string `json:"unresolved" doc:"what to do when the address is missing, invalid, private or not in the database, skip (leave the document as it is, the default) or null (set the target to null)"`
+}
+
+type geoIP struct {
+	field      string
+	target     string
+	unresolved string
+	db         *maxminddb.Reader
+}
+
+func (g *geoIP) apply(msg *message.Msg, doc map[string]interface{}) error {
+	location, err := g.locate(doc)
+	if err != nil {
+		return err
+	}
+	if location == nil {
+		if g.unresolved == "skip" {
+			return nil
+		}
+		return setField(doc, g.target, nil)
+	}
+	return setField(doc, g.target, location)
+}
+
+// locate returns the location of the document's address, or nil if it can't be located
+func (g *geoIP) locate(doc map[string]interface{}) (map[string]interface{}, error) {
+	v, ok := getField(doc, g.field)
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, nil
+	}
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, nil
+	}
+	for _, network := range geoIPPrivateNetworks {
+		if network.Contains(ip) {
+			return nil, nil
+		}
+	}
+
+	if ip.To4() == nil && g.db.Metadata.IPVersion == 4 {
+		return nil, nil // an ipv4 database can't locate ipv6 addresses
+	}
+
+	var r map[string]interface{}
+	_, found, err := g.db.LookupNetwork(ip, &r)
+	if err != nil {
+		return nil, fmt.Errorf("geoip lookup of %s failed (%s)", ip, err.Error())
+	}
+	if !found || r == nil {
+		return nil, nil
+	}
+
+	location := make(map[string]interface{})
+	if v, ok := getField(r, "country.iso_code"); ok {
+		location["country_code"] = v
+	}
+	if v, ok := getField(r, "country.names.en"); ok {
+		location["country_name"] = v
+	}
+	if subdivisions, ok := r["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		if s, ok := subdivisions[0].(map[string]interface{}); ok {
+			if v, ok := getField(s, "names.en"); ok {
+				location["region_name"] = v
+			}
+		}
+	}
+	if v, ok := getField(r, "city.names.en"); ok {
+		location["city_name"] = v
+	}
+	if v, ok := getField(r, "postal.code"); ok {
+		location["postal_code"] = v
+	}
+	lat, latOK := getField(r, "location.latitude")
+	lon, lonOK := getField(r, "location.longitude")
+	if latOK && lonOK {
+		location["location"] = map[string]interface{}{"lat": lat, "lon": lon}
+	}
+	if len(location) == 0 {
+		return nil, nil
+	}
+	return location, nil
+}
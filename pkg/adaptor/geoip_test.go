@@ -0,0 +1,363 @@
+package adaptor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// the MaxMind DB data types (https://maxmind.github.io/MaxMind-DB/) the test databases are written with
+const (
+	mmdbPointer = 1
+	mmdbString  = 2
+	mmdbDouble  = 3
+	mmdbUint16  = 5
+	mmdbUint32  = 6
+	mmdbMap     = 7
+	mmdbUint64  = 9
+	mmdbArray   = 11
+	mmdbBool    = 14
+)
+
+// mmdbMetadataMarker starts the metadata at the end of a MaxMind database
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// testMMDBPointer is a value written as a pointer to the shared value with that index
+type testMMDBPointer int
+
+// writeTestMMDB builds a MaxMind database holding a record for each network, the networks mustn't overlap.
+// The shared values are written first, for the records to point to
+func writeTestMMDB(t *testing.T, ipVersion, recordSize int, networks map[string]interface{}, shared ...interface{}) []byte {
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+
+	// the data section, the shared values and then a record for each network in order
+	var data bytes.Buffer
+	pointers := make([]int, len(shared))
+	for i, v := range shared {
+		pointers[i] = data.Len()
+		writeTestMMDBValue(t, &data, v, nil)
+	}
+	offsets := make([]int, len(cidrs))
+	for i, cidr := range cidrs {
+		offsets[i] = data.Len()
+		writeTestMMDBValue(t, &data, networks[cidr], pointers)
+	}
+
+	// the tree, each record is the index of a node, -1 when empty, or -2-i for the data of network i
+	nodes := [][2]int{{-1, -1}}
+	for i, cidr := range cidrs {
+		ip, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("bad test network %s, %s", cidr, err)
+		}
+		ones, _ := network.Mask.Size()
+		bits := []byte(ip.To16())
+		if v4 := ip.To4(); v4 != nil {
+			if ipVersion == 6 {
+				bits = append(make([]byte, 12), v4...)
+				ones += 96
+			} else {
+				bits = v4
+			}
+		}
+		node := 0
+		for b := 0; b < ones; b++ {
+			bit := int(bits[b/8]>>uint(7-b%8)) & 1
+			if b == ones-1 {
+				nodes[node][bit] = -2 - i
+				break
+			}
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{-1, -1})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	var buf bytes.Buffer
+	count := len(nodes)
+	record := func(r int) uint32 {
+		switch {
+		case r >= 0:
+			return uint32(r)
+		case r == -1:
+			return uint32(count)
+		}
+		return uint32(count + 16 + offsets[-2-r])
+	}
+	for _, n := range nodes {
+		l, r := record(n[0]), record(n[1])
+		switch recordSize {
+		case 24:
+			buf.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(r >> 16), byte(r >> 8), byte(r)})
+		case 28:
+			buf.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(l>>24)<<4 | byte(r>>24), byte(r >> 16), byte(r >> 8), byte(r)})
+		default:
+			binary.Write(&buf, binary.BigEndian, [2]uint32{l, r})
+		}
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(data.Bytes())
+	buf.Write(mmdbMetadataMarker)
+	writeTestMMDBValue(t, &buf, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"node_count":                  uint32(count),
+		"record_size":                 uint16(recordSize),
+		"ip_version":                  uint16(ipVersion),
+		"database_type":               "Test-City",
+		"description":                 map[string]interface{}{"en": "a test database"},
+		"languages":                   []interface{}{"en"},
+	}, nil)
+	return buf.Bytes()
+}
+
+func writeTestMMDBValue(t *testing.T, buf *bytes.Buffer, v interface{}, pointers []int) {
+	ctrl := func(typ, size int) {
+		first := byte(typ << 5)
+		if typ > 7 {
+			first = 0
+		}
+		switch {
+		case size < 29:
+			buf.WriteByte(first | byte(size))
+		case size < 285:
+			buf.WriteByte(first | 29)
+		default:
+			buf.WriteByte(first | 30)
+		}
+		if typ > 7 {
+			buf.WriteByte(byte(typ - 7))
+		}
+		switch {
+		case size >= 285:
+			buf.Write([]byte{byte((size - 285) >> 8), byte(size - 285)})
+		case size >= 29:
+			buf.WriteByte(byte(size - 29))
+		}
+	}
+	uint := func(typ int, n uint64) {
+		var b []byte
+		for ; n > 0; n >>= 8 {
+			b = append([]byte{byte(n)}, b...)
+		}
+		ctrl(typ, len(b))
+		buf.Write(b)
+	}
+
+	switch v := v.(type) {
+	case string:
+		ctrl(mmdbString, len(v))
+		buf.WriteString(v)
+	case float64:
+		ctrl(mmdbDouble, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		uint(mmdbUint16, uint64(v))
+	case uint32:
+		uint(mmdbUint32, uint64(v))
+	case uint64:
+		uint(mmdbUint64, v)
+	case bool:
+		ctrl(mmdbBool, map[bool]int{false: 0, true: 1}[v])
+	case []interface{}:
+		ctrl(mmdbArray, len(v))
+		for _, elem := range v {
+			writeTestMMDBValue(t, buf, elem, pointers)
+		}
+	case map[string]interface{}:
+		ctrl(mmdbMap, len(v))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeTestMMDBValue(t, buf, k, pointers)
+			writeTestMMDBValue(t, buf, v[k], pointers)
+		}
+	case testMMDBPointer:
+		// the 11 bit form, the shared values are at the start of the data
+		offset := pointers[v]
+		buf.Write([]byte{byte(mmdbPointer<<5 | offset>>8&7), byte(offset)})
+	default:
+		t.Fatalf("can't write a %T to a test database", v)
+	}
+}
+
+var testGeoIPCountryUS = map[string]interface{}{"iso_code": "US", "names": map[string]interface{}{"en": "United States"}}
+
+var testGeoIPNetworks = map[string]interface{}{
+	"1.1.1.0/24": map[string]interface{}{
+		"country": map[string]interface{}{"iso_code": "AU", "names": map[string]interface{}{"en": "Australia"}},
+	},
+	"8.8.8.0/24": map[string]interface{}{
+		"city":         map[string]interface{}{"names": map[string]interface{}{"en": "Mountain View", "de": "Mountain View"}},
+		"country":      testMMDBPointer(0),
+		"subdivisions": []interface{}{map[string]interface{}{"names": map[string]interface{}{"en": "California"}}},
+		"postal":       map[string]interface{}{"code": "94043"},
+		"location":     map[string]interface{}{"latitude": 37.386, "longitude": -122.0838, "accuracy_radius": uint16(1000)},
+	},
+	"2001:4860::/32": map[string]interface{}{
+		"country":  testMMDBPointer(0),
+		"location": map[string]interface{}{"latitude": 37.751, "longitude": -97.822},
+	},
+}
+
+// TestMMDBLookup checks the test databases with maxminddb's reader, for each record size
+func TestMMDBLookup(t *testing.T) {
+	for _, recordSize := range []int{24, 28, 32} {
+		r, err := maxminddb.FromBytes(writeTestMMDB(t, 6, recordSize, testGeoIPNetworks, testGeoIPCountryUS))
+		if err != nil {
+			t.Fatalf("[%d] unexpected error, %s", recordSize, err)
+		}
+		if r.Metadata.DatabaseType != "Test-City" {
+			t.Errorf("[%d] expected a Test-City database, got %s", recordSize, r.Metadata.DatabaseType)
+		}
+
+		var record map[string]interface{}
+		if _, found, err := r.LookupNetwork(net.ParseIP("8.8.8.8"), &record); err != nil || !found {
+			t.Fatalf("[%d] expected to find 8.8.8.8, got %v, %v", recordSize, found, err)
+		}
+		if city, _ := getField(record, "city.names.en"); city != "Mountain View" {
+			t.Errorf("[%d] expected Mountain View, got %v", recordSize, city)
+		}
+		if radius, _ := getField(record, "location.accuracy_radius"); radius != uint64(1000) {
+			t.Errorf("[%d] expected an accuracy radius of 1000, got %v", recordSize, radius)
+		}
+
+		// the country is a pointer to a shared value
+		record = nil
+		if _, found, err := r.LookupNetwork(net.ParseIP("2001:4860:4860::8888"), &record); err != nil || !found {
+			t.Fatalf("[%d] expected to find 2001:4860:4860::8888, got %v, %v", recordSize, found, err)
+		}
+		if !reflect.DeepEqual(record["country"], testGeoIPCountryUS) {
+			t.Errorf("[%d] expected the shared country, got %v", recordSize, record)
+		}
+
+		for _, ip := range []string{"8.8.9.1", "9.9.9.9", "2001:4861::1"} {
+			var record interface{}
+			if _, found, err := r.LookupNetwork(net.ParseIP(ip), &record); err != nil || found {
+				t.Errorf("[%d] expected %s not to be found, got %v, %v", recordSize, ip, found, err)
+			}
+		}
+	}
+}
+
+func TestGeoIP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	v6 := filepath.Join(dir, "city.mmdb")
+	ioutil.WriteFile(v6, writeTestMMDB(t, 6, 28, testGeoIPNetworks, testGeoIPCountryUS), 0644)
+	v4 := filepath.Join(dir, "city-v4.mmdb")
+	ioutil.WriteFile(v4, writeTestMMDB(t, 4, 24, map[string]interface{}{"8.8.8.0/24": testGeoIPNetworks["8.8.8.0/24"]}, testGeoIPCountryUS), 0644)
+
+	mountainView := map[string]interface{}{
+		"country_code": "US",
+		"country_name": "United States",
+		"region_name":  "California",
+		"city_name":    "Mountain View",
+		"postal_code":  "94043",
+		"location":     map[string]interface{}{"lat": 37.386, "lon": -122.0838},
+	}
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{}
+	}{
+		{
+			Config{"database": v6, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "8.8.8.8"},
+			map[string]interface{}{"_id": "1", "ip": "8.8.8.8", "geoip": mountainView},
+		},
+		{
+			Config{"database": v4, "field": "client.ip", "target": "client.geo"},
+			map[string]interface{}{"_id": "1", "client": map[string]interface{}{"ip": " 8.8.8.8"}},
+			map[string]interface{}{"_id": "1", "client": map[string]interface{}{"ip": " 8.8.8.8", "geo": mountainView}},
+		},
+		{
+			// only the parts the database has are set
+			Config{"database": v6, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "1.1.1.1"},
+			map[string]interface{}{"_id": "1", "ip": "1.1.1.1", "geoip": map[string]interface{}{"country_code": "AU", "country_name": "Australia"}},
+		},
+		{
+			Config{"database": v6, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "2001:4860::1"},
+			map[string]interface{}{"_id": "1", "ip": "2001:4860::1", "geoip": map[string]interface{}{"country_code": "US", "country_name": "United States", "location": map[string]interface{}{"lat": 37.751, "lon": -97.822}}},
+		},
+		{
+			// private, invalid, unknown and missing addresses are skipped by default
+			Config{"database": v6, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "192.168.1.10"},
+			map[string]interface{}{"_id": "1", "ip": "192.168.1.10"},
+		},
+		{
+			Config{"database": v6, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "not an ip"},
+			map[string]interface{}{"_id": "1", "ip": "not an ip"},
+		},
+		{
+			Config{"database": v4, "field": "ip"},
+			map[string]interface{}{"_id": "1", "ip": "2001:4860::1"},
+			map[string]interface{}{"_id": "1", "ip": "2001:4860::1"},
+		},
+		{
+			Config{"database": v6, "field": "ip", "unresolved": "null"},
+			map[string]interface{}{"_id": "1", "ip": "9.9.9.9"},
+			map[string]interface{}{"_id": "1", "ip": "9.9.9.9", "geoip": nil},
+		},
+		{
+			Config{"database": v6, "field": "ip", "unresolved": "null"},
+			map[string]interface{}{"_id": "1", "ip": "::1", "geoip": "stale"},
+			map[string]interface{}{"_id": "1", "ip": "::1", "geoip": nil},
+		},
+		{
+			Config{"database": v6, "field": "ip", "unresolved": "null"},
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1", "geoip": nil},
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "geoip", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil || out == nil {
+			t.Errorf("%+v: unexpected error, %v", v.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+
+	for _, conf := range []Config{
+		{"field": "ip"},
+		{"database": v6},
+		{"database": filepath.Join(dir, "missing.mmdb"), "field": "ip"},
+		{"database": v6, "field": "ip", "unresolved": "drop"},
+	} {
+		if _, err := NewGeoIP(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package adaptor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipTransport is an http.RoundTripper that gzips the body of bulk requests.
+// a server that doesn't accept compressed bodies replies with 415 Unsupported Media Type, in which
+// case the request is retried uncompressed, compression is turned off for the rest of the run
+// and fallback is called so the adaptor can report it
+type gzipTransport struct {
+	next     http.RoundTripper
+	fallback func()
+
+	sync.Mutex
+	disabled bool
+}
+
+func newGzipTransport(next http.RoundTripper, fallback func()) *gzipTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &gzipTransport{next: next, fallback: fallback}
+}
+
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !strings.HasSuffix(req.URL.Path, "/_bulk") || t.isDisabled() {
+		return t.next.RoundTrip(req)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(withBody(req, buf.Bytes(), "gzip"))
+	if err != nil || res.StatusCode != http.StatusUnsupportedMediaType {
+		return res, err
+	}
+	res.Body.Close()
+
+	t.Lock()
+	t.disabled = true
+	t.Unlock()
+	if t.fallback != nil {
+		t.fallback()
+	}
+	return t.next.RoundTrip(withBody(req, body, ""))
+}
+
+func (t *gzipTransport) isDisabled() bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.disabled
+}
+
+// withBody returns a copy of req with the given body and content encoding,
+// RoundTrippers mustn't modify the request they're given
+func withBody(req *http.Request, body []byte, encoding string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	if encoding != "" {
+		r.Header.Set("Content-Encoding", encoding)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return r
+}
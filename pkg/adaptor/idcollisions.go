@@ -0,0 +1,116 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewIDCollisions creates a transformer that reports inserts whose id has already been seen with a different
+// document, which usually means the id field, or the way a compound key is flattened into it, doesn't identify
+// documents uniquely and the sink is quietly overwriting them.  Updates and deletes are expected to reuse ids,
+// and an insert repeating a document exactly (i.e. a replayed copy) isn't a collision.
+// A hash of each document is kept for the last maxids ids seen, so memory use is bounded
+func NewIDCollisions(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf IDCollisionsConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	c := &idCollisions{pipe: p, path: path, field: conf.Field, drop: conf.Drop, max: conf.MaxIDs, seen: make(map[string]uint64)}
+	if c.field == "" {
+		c.field = "_id"
+	}
+	switch {
+	case c.max == 0:
+		c.max = 1000000
+	case c.max < 0:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("maxids (%d) can't be negative", c.max), nil)
+	}
+
+	return newDocTransformer("idcollisions", p, path, extra, c.apply)
+}
+
+// IDCollisionsConfig provides configuration options for the idcollisions transformer
+type IDCollisionsConfig struct {
+	Namespace string `json:"namespace" doc:"the set of namespaces to check"`
+	Field     string `json:"field" doc:"the dotted path of the id the sink writes by, defaults to _id"`
+	MaxIDs    int    `json:"maxids" doc:"the most ids to remember, the oldest are forgotten first. defaults to 1000000"`
+	Drop      bool   `json:"drop" doc:"if true, a colliding insert is dropped with an ERROR rather than passed on with a WARNING, so the first document wins"`
+}
+
+type idCollisions struct {
+	pipe  *pipe.Pipe
+	path  string
+	field string
+	drop  bool
+	max   int
+
+	seen  map[string]uint64 // id to document hash
+	order []string          // the ids in seen, oldest first, so the oldest can be forgotten
+}
+
+func (c *idCollisions) apply(msg *message.Msg, doc map[string]interface{}) error {
+	v, ok := getField(doc, c.field)
+	if !ok || v == nil {
+		return nil
+	}
+	id := templateString(v)
+
+	switch msg.Op {
+	case message.Delete:
+		delete(c.seen, id) // a later insert of the id is a new document
+		return nil
+	case message.Insert:
+		h := documentHash(doc)
+		if prev, ok := c.seen[id]; ok && prev != h {
+			if c.drop {
+				return fmt.Errorf("%s %s has already been inserted with a different document", c.field, id)
+			}
+			c.pipe.Err <- NewError(WARNING, c.path, fmt.Sprintf("idcollisions error (%s %s has already been inserted with a different document)", c.field, id), msg.Data)
+		}
+		c.remember(id, h)
+	default:
+		if _, ok := c.seen[id]; ok {
+			c.seen[id] = documentHash(doc)
+		}
+	}
+	return nil
+}
+
+// remember records the id's document hash, forgetting the oldest id if there are too many
+func (c *idCollisions) remember(id string, h uint64) {
+	if _, ok := c.seen[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.seen[id] = h
+
+	for len(c.seen) > c.max && len(c.order) > 0 {
+		delete(c.seen, c.order[0])
+		c.order = c.order[1:]
+	}
+	// deleted ids leave stale entries in order, drop them before they pile up
+	if len(c.order) > 2*c.max {
+		order := make([]string, 0, len(c.seen))
+		for _, id := range c.order {
+			if _, ok := c.seen[id]; ok {
+				order = append(order, id)
+			}
+		}
+		c.order = order
+	}
+}
+
+// documentHash hashes the document's json, whose keys are sorted, so equal documents always hash the same
+func documentHash(doc map[string]interface{}) uint64 {
+	h := fnv.New64a()
+	if ba, err := json.Marshal(doc); err == nil {
+		h.Write(ba)
+	} else {
+		fmt.Fprintf(h, "%v", doc)
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,107 @@
+package adaptor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestIDCollisions(t *testing.T) {
+	type in struct {
+		op  message.OpType
+		doc map[string]interface{}
+	}
+	data := []struct {
+		conf    Config
+		msgs    []in
+		dropped []bool
+		lvls    []ErrorLevel // the errors reported, in order
+	}{
+		{
+			// a different document with the same id is reported, the same document again isn't
+			Config{},
+			[]in{
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "a"}},
+				{message.Insert, map[string]interface{}{"_id": "2", "name": "b"}},
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "a"}},
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "c"}},
+			},
+			[]bool{false, false, false, false},
+			[]ErrorLevel{WARNING},
+		},
+		{
+			// updates and deletes reuse ids, and a deleted id can be inserted again
+			Config{},
+			[]in{
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "a"}},
+				{message.Update, map[string]interface{}{"_id": "1", "name": "b"}},
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "b"}},
+				{message.Delete, map[string]interface{}{"_id": "1"}},
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "c"}},
+			},
+			[]bool{false, false, false, false, false},
+			nil,
+		},
+		{
+			// a dotted id field, and dropping the collision
+			Config{"field": "key.id", "drop": true},
+			[]in{
+				{message.Insert, map[string]interface{}{"_id": "1", "key": map[string]interface{}{"id": 10}}},
+				{message.Insert, map[string]interface{}{"_id": "2", "key": map[string]interface{}{"id": 10}}},
+			},
+			[]bool{false, true},
+			[]ErrorLevel{ERROR},
+		},
+		{
+			// only the most recent ids are remembered
+			Config{"maxids": 2},
+			[]in{
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "a"}},
+				{message.Insert, map[string]interface{}{"_id": "2", "name": "b"}},
+				{message.Insert, map[string]interface{}{"_id": "3", "name": "c"}},
+				{message.Insert, map[string]interface{}{"_id": "1", "name": "d"}},
+				{message.Insert, map[string]interface{}{"_id": "3", "name": "e"}},
+			},
+			[]bool{false, false, false, false, false},
+			[]ErrorLevel{WARNING},
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "idcollisions", v.conf)
+		for i, m := range v.msgs {
+			out, err := tr.transformOne(message.NewMsg(m.op, m.doc, "database.collection"))
+			if err != nil {
+				t.Fatalf("%v: unexpected error, %s", v.conf, err)
+			}
+			if (out == nil) != v.dropped[i] {
+				t.Errorf("%v: message %d, expected dropped to be %t", v.conf, i, v.dropped[i])
+			}
+		}
+
+		var lvls []ErrorLevel
+	A:
+		for {
+			select {
+			case err := <-errs:
+				if !strings.Contains(err.Error(), "already been inserted") {
+					t.Errorf("%v: unexpected error, %s", v.conf, err)
+				}
+				lvls = append(lvls, err.(Error).Lvl)
+			case <-time.After(50 * time.Millisecond):
+				break A
+			}
+		}
+		if len(lvls) != len(v.lvls) {
+			t.Errorf("%v: expected errors %v, got %v", v.conf, v.lvls, lvls)
+			continue
+		}
+		for i := range lvls {
+			if lvls[i] != v.lvls[i] {
+				t.Errorf("%v: expected errors %v, got %v", v.conf, v.lvls, lvls)
+			}
+		}
+	}
+}
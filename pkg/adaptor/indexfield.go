@@ -0,0 +1,115 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// indexField reads the index a document is written to from one of its fields, so that a single sink can
+// write each tenant, or the like, to an index of its own.  When the field is missing or doesn't hold a valid
+// index name, the invalid policy either rejects the document (error), sanitizes the name (sanitize), or writes
+// the document to the index it would have been written to without an index field (default)
+type indexField struct {
+	field   string
+	invalid string
+}
+
+func newIndexField(field, invalid string) (*indexField, error) {
+	f := &indexField{field: field, invalid: invalid}
+	switch f.invalid {
+	case "":
+		f.invalid = "error"
+	case "error", "sanitize", "default":
+	default:
+		return nil, fmt.Errorf("unknown invalidindex policy (%s), must be error, sanitize or default", f.invalid)
+	}
+	return f, nil
+}
+
+// resolve returns the index named by the document's field, fallback is the index used by the default policy
+func (f *indexField) resolve(doc map[string]interface{}, fallback string) (string, error) {
+	v, ok := getField(doc, f.field)
+	if !ok || v == nil {
+		if f.invalid == "default" {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("the index field %s is missing", f.field)
+	}
+
+	var name string
+	switch n := v.(type) {
+	case string:
+		name = n
+	case int:
+		name = strconv.Itoa(n)
+	case int32:
+		name = strconv.FormatInt(int64(n), 10)
+	case int64:
+		name = strconv.FormatInt(n, 10)
+	case float64:
+		if n != math.Trunc(n) {
+			return f.rejected(fallback, fmt.Errorf("the index field %s holds %v, not a whole number", f.field, n))
+		}
+		name = strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return f.rejected(fallback, fmt.Errorf("the index field %s is a %T, not a string or a number", f.field, v))
+	}
+
+	if f.invalid == "sanitize" {
+		name = sanitizeIndexName(name)
+	}
+	if err := validIndexName(name); err != nil {
+		return f.rejected(fallback, fmt.Errorf("the index field %s holds %q, %s", f.field, name, err.Error()))
+	}
+	return name, nil
+}
+
+func (f *indexField) rejected(fallback string, err error) (string, error) {
+	if f.invalid == "default" {
+		return fallback, nil
+	}
+	return "", err
+}
+
+// indexNameInvalidChars can't appear anywhere in an elasticsearch index name
+const indexNameInvalidChars = `\/*?"<>| ,#:`
+
+// validIndexName checks the name against elasticsearch's rules for index names
+func validIndexName(name string) error {
+	switch {
+	case name == "" || name == "." || name == "..":
+		return fmt.Errorf("which isn't a valid index name")
+	case len(name) > 255:
+		return fmt.Errorf("index names can't be longer than 255 bytes")
+	case strings.ToLower(name) != name:
+		return fmt.Errorf("index names must be lowercase")
+	case strings.IndexAny(name, indexNameInvalidChars) >= 0:
+		return fmt.Errorf("index names can't contain any of %s", indexNameInvalidChars)
+	case strings.IndexAny(name[:1], "-_+") >= 0:
+		return fmt.Errorf("index names can't start with -, _ or +")
+	}
+	return nil
+}
+
+// sanitizeIndexName lowercases the name, replaces the characters elasticsearch rejects with _, and strips
+// the characters it can't start with.  The result may still be invalid, i.e. it's empty
+func sanitizeIndexName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(indexNameInvalidChars, r) {
+			return '_'
+		}
+		return r
+	}, strings.ToLower(name))
+	name = strings.TrimLeft(name, "-_+")
+	if len(name) > 255 {
+		end := 255
+		for !utf8.RuneStart(name[end]) {
+			end--
+		}
+		name = name[:end]
+	}
+	return name
+}
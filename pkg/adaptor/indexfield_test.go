@@ -0,0 +1,72 @@
+package adaptor
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestIndexField(t *testing.T) {
+	data := []struct {
+		invalid string
+		doc     map[string]interface{}
+		index   string // "" if the document is rejected
+	}{
+		{"", map[string]interface{}{"tenant": "acme"}, "acme"},
+		{"", map[string]interface{}{"org": bson.M{"tenant": "acme-2"}}, "acme-2"},
+		{"", map[string]interface{}{"tenant": 42.0}, "42"},
+		{"", map[string]interface{}{"tenant": int64(42)}, "42"},
+		{"", map[string]interface{}{}, ""},
+		{"", map[string]interface{}{"tenant": nil}, ""},
+		{"", map[string]interface{}{"tenant": "Acme"}, ""},
+		{"", map[string]interface{}{"tenant": "acme/eu"}, ""},
+		{"", map[string]interface{}{"tenant": "_acme"}, ""},
+		{"", map[string]interface{}{"tenant": 4.2}, ""},
+		{"", map[string]interface{}{"tenant": true}, ""},
+		{"sanitize", map[string]interface{}{"tenant": "_Acme Corp/EU"}, "acme_corp_eu"},
+		{"sanitize", map[string]interface{}{"tenant": "acme"}, "acme"},
+		{"sanitize", map[string]interface{}{"tenant": "___"}, ""},
+		{"sanitize", map[string]interface{}{}, ""},
+		{"default", map[string]interface{}{"tenant": "Acme"}, "fallback"},
+		{"default", map[string]interface{}{}, "fallback"},
+		{"default", map[string]interface{}{"tenant": "acme"}, "acme"},
+	}
+
+	for _, v := range data {
+		field := "tenant"
+		if _, ok := v.doc["org"]; ok {
+			field = "org.tenant"
+		}
+		f, err := newIndexField(field, v.invalid)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		index, err := f.resolve(v.doc, "fallback")
+		if v.index == "" {
+			if err == nil {
+				t.Errorf("%s %v: expected an error, got %s", v.invalid, v.doc, index)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: unexpected error, %s", v.invalid, v.doc, err)
+		} else if index != v.index {
+			t.Errorf("%s %v: expected %s, got %s", v.invalid, v.doc, v.index, index)
+		}
+	}
+
+	if _, err := newIndexField("tenant", "drop"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestSanitizeIndexName(t *testing.T) {
+	long := strings.Repeat("a", 254) + "é"
+	if name := sanitizeIndexName(long); name != strings.Repeat("a", 254) {
+		t.Errorf("expected a long name to be cut before the rune that doesn't fit, got %d bytes", len(name))
+	}
+	if err := validIndexName(sanitizeIndexName(`ACME\*?"<>|,#:x`)); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+}
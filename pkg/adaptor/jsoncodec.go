@@ -0,0 +1,121 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewJSONEncode creates a transformer that replaces document and array fields with their json, i.e.
+// {"address": {"city": "toronto"}} becomes {"address": "{\"city\":\"toronto\"}"}, for sinks that can't store
+// nested values, like relational columns or csv.  Other values are handled by the invalid policy
+func NewJSONEncode(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	c, err := newJSONCodec(path, extra)
+	if err != nil {
+		return nil, err
+	}
+	return newDocTransformer("jsonencode", p, path, extra, c.encode)
+}
+
+// NewJSONDecode creates a transformer that undoes jsonencode, replacing string fields holding a json document
+// or array with the value they hold.  Other values, and strings that aren't json, are handled by the invalid policy
+func NewJSONDecode(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	c, err := newJSONCodec(path, extra)
+	if err != nil {
+		return nil, err
+	}
+	return newDocTransformer("jsondecode", p, path, extra, c.decode)
+}
+
+// JSONCodecConfig provides configuration options for the jsonencode and jsondecode transformers
+type JSONCodecConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    []string `json:"fields" doc:"the dotted paths of the fields to encode or decode"`
+	Invalid   string   `json:"invalid" doc:"what to do when a field can't be encoded or decoded, pass (leave it as it is, the default), drop or error"`
+}
+
+type jsonCodec struct {
+	fields  []string
+	invalid string
+}
+
+func newJSONCodec(path string, extra Config) (*jsonCodec, error) {
+	var conf JSONCodecConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "json config must contain fields", nil)
+	}
+
+	c := &jsonCodec{fields: conf.Fields, invalid: conf.Invalid}
+	switch c.invalid {
+	case "":
+		c.invalid = "pass"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown invalid policy (%s), must be pass, drop or error", c.invalid), nil)
+	}
+	return c, nil
+}
+
+func (c *jsonCodec) encode(msg *message.Msg, doc map[string]interface{}) error {
+	return c.apply(msg, doc, func(v interface{}) (interface{}, error) {
+		if _, isDoc := asMap(v); !isDoc {
+			if _, isArray := asSlice(v); !isArray {
+				return nil, fmt.Errorf("is a %T, not a document or an array", v)
+			}
+		}
+		ba, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(ba), nil
+	})
+}
+
+func (c *jsonCodec) decode(msg *message.Msg, doc map[string]interface{}) error {
+	return c.apply(msg, doc, func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("is a %T, not a string", v)
+		}
+		if trimmed := strings.TrimSpace(s); !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return nil, fmt.Errorf("doesn't hold a json document or array")
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			return nil, fmt.Errorf("isn't valid json (%s)", err.Error())
+		}
+		return decoded, nil
+	})
+}
+
+// apply replaces each field with the value returned by fn, missing and null fields are left alone
+func (c *jsonCodec) apply(msg *message.Msg, doc map[string]interface{}, fn func(interface{}) (interface{}, error)) error {
+	for _, field := range c.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+		out, err := fn(v)
+		if err != nil {
+			switch c.invalid {
+			case "drop":
+				msg.Op = message.Noop
+				return nil
+			case "error":
+				return fmt.Errorf("%s %s", field, err.Error())
+			}
+			continue
+		}
+		if err := setField(doc, field, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
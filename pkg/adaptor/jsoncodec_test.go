@@ -0,0 +1,154 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestJSONEncode(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			// documents and arrays are encoded, with the keys of documents sorted
+			Config{"fields": []interface{}{"address", "tags", "order.items", "missing", "null"}},
+			map[string]interface{}{"_id": "1", "address": bson.M{"street": "main", "city": "toronto"}, "tags": []string{"a", "b"}, "order": map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "x"}}}, "null": nil},
+			map[string]interface{}{"_id": "1", "address": `{"city":"toronto","street":"main"}`, "tags": `["a","b"]`, "order": map[string]interface{}{"items": `[{"sku":"x"}]`}, "null": nil},
+			false,
+		},
+		{
+			// other values pass by default
+			Config{"fields": []interface{}{"name"}},
+			map[string]interface{}{"_id": "1", "name": "nick"},
+			map[string]interface{}{"_id": "1", "name": "nick"},
+			false,
+		},
+		{
+			Config{"invalid": "drop", "fields": []interface{}{"name"}},
+			map[string]interface{}{"_id": "1", "name": "nick"},
+			nil,
+			false,
+		},
+		{
+			Config{"invalid": "error", "fields": []interface{}{"count"}},
+			map[string]interface{}{"_id": "1", "count": 1},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		testJSONCodec(t, "jsonencode", v.conf, v.in, v.out, v.errored)
+	}
+}
+
+func TestJSONDecode(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"fields": []interface{}{"address", "tags", "missing", "null"}},
+			map[string]interface{}{"_id": "1", "address": ` {"city":"toronto","floor":3}`, "tags": `["a","b"]`, "null": nil},
+			map[string]interface{}{"_id": "1", "address": map[string]interface{}{"city": "toronto", "floor": 3.0}, "tags": []interface{}{"a", "b"}, "null": nil},
+			false,
+		},
+		{
+			// strings that don't hold a document or array, bad json and other values pass by default
+			Config{"fields": []interface{}{"name", "number", "bad", "count"}},
+			map[string]interface{}{"_id": "1", "name": "nick", "number": "12", "bad": `{"city":`, "count": 1},
+			map[string]interface{}{"_id": "1", "name": "nick", "number": "12", "bad": `{"city":`, "count": 1},
+			false,
+		},
+		{
+			Config{"invalid": "drop", "fields": []interface{}{"bad"}},
+			map[string]interface{}{"_id": "1", "bad": `{"city":`},
+			nil,
+			false,
+		},
+		{
+			Config{"invalid": "error", "fields": []interface{}{"bad"}},
+			map[string]interface{}{"_id": "1", "bad": `[1, 2`},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		testJSONCodec(t, "jsondecode", v.conf, v.in, v.out, v.errored)
+	}
+}
+
+func testJSONCodec(t *testing.T, kind string, conf Config, in, expected map[string]interface{}, errored bool) {
+	tr, errs := newTestDocTransformer(t, kind, conf)
+	out, err := tr.transformOne(message.NewMsg(message.Insert, in, "database.collection"))
+	if err != nil {
+		t.Errorf("[%s] %+v: unexpected error, %s", kind, in, err)
+		return
+	}
+
+	if expected == nil {
+		if out != nil && out.Op != message.Noop {
+			t.Errorf("[%s] %+v: expected the message to be dropped, got %+v", kind, in, out)
+		}
+		if errored {
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("[%s] %+v: expected an ERROR, got %v", kind, in, err)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(out.Data, expected) {
+		t.Errorf("[%s] %+v: expected %+v, got %+v", kind, in, expected, out.Data)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	conf := Config{"fields": []interface{}{"profile"}}
+	profile := map[string]interface{}{
+		"name":    "nick",
+		"age":     30.0,
+		"tags":    []interface{}{"a", map[string]interface{}{"b": true}},
+		"address": map[string]interface{}{"city": "toronto", "geo": []interface{}{43.7, -79.4}},
+	}
+
+	encode, _ := newTestDocTransformer(t, "jsonencode", conf)
+	decode, _ := newTestDocTransformer(t, "jsondecode", conf)
+	msg, err := encode.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "profile": profile}, "database.collection"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if _, ok := msg.Map()["profile"].(string); !ok {
+		t.Fatalf("expected profile to be encoded, got %T", msg.Map()["profile"])
+	}
+	if msg, err = decode.transformOne(msg); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(msg.Map()["profile"], profile) {
+		t.Errorf("expected %+v, got %+v", profile, msg.Map()["profile"])
+	}
+}
+
+func TestJSONCodecBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"invalid": "skip", "fields": []interface{}{"profile"}},
+	}
+
+	for _, conf := range data {
+		if _, err := NewJSONEncode(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+		if _, err := NewJSONDecode(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
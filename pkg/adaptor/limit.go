@@ -0,0 +1,65 @@
+package adaptor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// sourceLimit implements the skip and limit options of the sources, which let a pipeline be tried out
+// against a slice of the real data.  A nil sourceLimit sends everything
+type sourceLimit struct {
+	sync.Mutex
+	skip  int
+	limit int // 0 is unlimited
+	seen  int
+	sent  int
+}
+
+func newSourceLimit(skip, limit int) (*sourceLimit, error) {
+	if skip < 0 || limit < 0 {
+		return nil, fmt.Errorf("skip (%d) and limit (%d) can't be negative", skip, limit)
+	}
+	if skip == 0 && limit == 0 {
+		return nil, nil
+	}
+	return &sourceLimit{skip: skip, limit: limit}, nil
+}
+
+// send sends the message down the pipe, unless it's one of the first skip messages.  It returns false
+// once limit messages have been sent, at which point the source should stop
+func (l *sourceLimit) send(p *pipe.Pipe, msg *message.Msg) bool {
+	if l == nil {
+		p.Send(msg)
+		return true
+	}
+
+	l.Lock()
+	if l.limit > 0 && l.sent >= l.limit {
+		l.Unlock()
+		return false
+	}
+	l.seen++
+	if l.seen <= l.skip {
+		l.Unlock()
+		return true
+	}
+	l.sent++
+	more := l.limit == 0 || l.sent < l.limit
+	l.Unlock()
+
+	p.Send(msg)
+	return more
+}
+
+// done returns true once the limit has been reached
+func (l *sourceLimit) done() bool {
+	if l == nil {
+		return false
+	}
+	l.Lock()
+	defer l.Unlock()
+	return l.limit > 0 && l.sent >= l.limit
+}
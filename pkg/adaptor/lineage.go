@@ -0,0 +1,113 @@
+package adaptor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// TransporterVersion is the version the lineage transformer stamps documents with, the transporter package
+// sets it to its VERSION
+var TransporterVersion string
+
+// the metadata the lineage transformer can stamp
+var lineageFields = []string{"pipeline", "host", "version", "ingested"}
+
+// NewLineage creates a transformer that stamps each document with where it was processed, i.e.
+// {"_transporter": {"pipeline": "orders", "host": "worker-1", "version": "0.1.1", "ingested": "2024-01-01T00:00:00Z"}},
+// to trace which pipeline, and which instance of it, wrote a document to a shared index.  The pipeline is
+// named by the source node unless it's configured, and the host is the machine's hostname
+func NewLineage(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf LineageConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	l := &lineage{prefix: conf.Prefix, pipeline: conf.Pipeline, output: conf.Output, version: TransporterVersion, fields: make(map[string]bool), now: time.Now}
+	if l.prefix == "" {
+		l.prefix = "_transporter"
+	}
+	if l.pipeline == "" {
+		l.pipeline = strings.SplitN(path, "/", 2)[0]
+	}
+	if l.output == "" {
+		l.output = timeFormatRFC3339
+	} else if l.output == timeFormatEpoch {
+		return nil, NewError(CRITICAL, path, "the output format must be epoch_seconds or epoch_millis, not epoch", nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		conf.Fields = lineageFields
+	}
+	for _, field := range conf.Fields {
+		known := false
+		for _, f := range lineageFields {
+			known = known || f == field
+		}
+		if !known {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown lineage field (%s), must be one of %s", field, strings.Join(lineageFields, ", ")), nil)
+		}
+		l.fields[field] = true
+	}
+
+	if l.fields["host"] {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("can't read the hostname (%s)", err.Error()), nil)
+		}
+		l.host = host
+	}
+
+	return newDocTransformer("lineage", p, path, extra, l.apply)
+}
+
+// LineageConfig provides configuration options for the lineage transformer
+type LineageConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Prefix    string   `json:"prefix" doc:"the dotted path of the document the metadata is written to, defaults to _transporter"`
+	Fields    []string `json:"fields" doc:"the metadata to stamp, any of pipeline, host, version and ingested, defaults to all of them"`
+	Pipeline  string   `json:"pipeline" doc:"the pipeline name to stamp, defaults to the name of the source node"`
+	Output    string   `json:"output" doc:"the format of the ingested time, rfc3339 (the default), epoch_seconds, epoch_millis or a go time layout"`
+}
+
+type lineage struct {
+	prefix   string
+	fields   map[string]bool
+	pipeline string
+	host     string
+	version  string
+	output   string
+	now      func() time.Time
+}
+
+// apply stamps the document.  deletes are left alone, they only need the document's id
+func (l *lineage) apply(msg *message.Msg, doc map[string]interface{}) error {
+	if msg.Op == message.Delete {
+		return nil
+	}
+
+	for _, field := range lineageFields {
+		if !l.fields[field] {
+			continue
+		}
+		var v interface{}
+		switch field {
+		case "pipeline":
+			v = l.pipeline
+		case "host":
+			v = l.host
+		case "version":
+			v = l.version
+		case "ingested":
+			v = formatTime(l.now(), l.output)
+		}
+		if err := setField(doc, l.prefix+"."+field, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
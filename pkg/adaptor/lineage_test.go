@@ -0,0 +1,91 @@
+package adaptor
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestLineage(t *testing.T) {
+	defer func(version string) { TransporterVersion = version }(TransporterVersion)
+	TransporterVersion = "1.2.3"
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		conf     Config
+		meta     map[string]interface{} // without the ingested time, which is checked separately
+		path     string
+		ingested bool
+	}{
+		// everything, under the default prefix, with the source node's name for the pipeline
+		{Config{}, map[string]interface{}{"pipeline": "path", "host": host, "version": "1.2.3"}, "_transporter", true},
+		{
+			Config{"prefix": "meta.lineage", "pipeline": "orders", "fields": []interface{}{"pipeline", "version"}},
+			map[string]interface{}{"pipeline": "orders", "version": "1.2.3"},
+			"meta.lineage",
+			false,
+		},
+		{Config{"fields": []interface{}{"host"}}, map[string]interface{}{"host": host}, "_transporter", false},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "lineage", v.conf)
+		before := time.Now()
+		out, err := tr.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "database.collection"))
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+		if out.Map()["_id"] != "1" {
+			t.Errorf("%v: expected the document to be kept, got %v", v.conf, out.Map())
+		}
+		stamped, ok := getField(out.Map(), v.path)
+		if !ok {
+			t.Fatalf("%v: expected the metadata at %s, got %v", v.conf, v.path, out.Map())
+		}
+		meta := stamped.(map[string]interface{})
+
+		if v.ingested {
+			ingested, err := time.Parse(time.RFC3339Nano, meta["ingested"].(string))
+			if err != nil {
+				t.Fatalf("%v: unexpected error, %s", v.conf, err)
+			}
+			if d := ingested.Sub(before); d < 0 || d > time.Second {
+				t.Errorf("%v: expected an ingested time of about %s, got %s", v.conf, before, ingested)
+			}
+			delete(meta, "ingested")
+		}
+		if !reflect.DeepEqual(meta, v.meta) {
+			t.Errorf("%v: expected %v, got %v", v.conf, v.meta, meta)
+		}
+	}
+}
+
+func TestLineageSkipsDeletes(t *testing.T) {
+	tr, _ := newTestDocTransformer(t, "lineage", Config{})
+	out, err := tr.transformOne(message.NewMsg(message.Delete, map[string]interface{}{"_id": "1"}, "database.collection"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !reflect.DeepEqual(out.Map(), map[string]interface{}{"_id": "1"}) {
+		t.Errorf("expected the delete to be left alone, got %v", out.Map())
+	}
+}
+
+func TestLineageBadConfig(t *testing.T) {
+	data := []Config{
+		{"fields": []interface{}{"pipeline", "user"}},
+		{"output": "epoch"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewLineage(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
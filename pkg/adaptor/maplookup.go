@@ -0,0 +1,85 @@
+package adaptor
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewMapLookup creates a transformer that translates coded field values through a lookup table, i.e. status 1
+// becomes "active".  Each field has its own table, keyed by the values as strings, so the code 1 and the code
+// "1" are looked up alike.  Values that aren't in the table are replaced with the field's default if it has
+// one, or else left untouched, and missing or null fields are ignored
+func NewMapLookup(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf MapLookupConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "maplookup config must contain at least one field", nil)
+	}
+	for field, lookup := range conf.Fields {
+		if len(lookup.Values) == 0 {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("maplookup field %s must have at least one value", field), nil)
+		}
+	}
+
+	m := &mapLookup{fields: conf.Fields}
+	return newDocTransformer("maplookup", p, path, extra, m.apply)
+}
+
+// MapLookupConfig provides configuration options for the maplookup transformer
+type MapLookupConfig struct {
+	Namespace string                    `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string]MapLookupTable `json:"fields" doc:"the lookup table for each field, keyed by dotted field path"`
+}
+
+// MapLookupTable translates the values of a single field
+type MapLookupTable struct {
+	Values  map[string]interface{} `json:"values" doc:"the value to write for each code, keyed by the code as a string, i.e. {\"1\": \"active\", \"2\": \"suspended\"}"`
+	Default interface{}            `json:"default" doc:"the value to write for codes that aren't in values, when it's not set they're left untouched"`
+	Target  string                 `json:"target" doc:"the dotted path of the field to write the translation to, defaults to the field itself so the code is replaced"`
+}
+
+type mapLookup struct {
+	fields map[string]MapLookupTable
+}
+
+// apply translates each configured field that's in the document
+func (m *mapLookup) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for field, lookup := range m.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+
+		out, ok := lookup.Values[lookupKey(v)]
+		if !ok {
+			if lookup.Default == nil {
+				continue
+			}
+			out = lookup.Default
+		}
+
+		target := lookup.Target
+		if target == "" {
+			target = field
+		}
+		if err := setField(doc, target, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupKey returns the value as a lookup table key, whole floats are written without a decimal point so
+// that the code 1 read from json matches the key "1"
+func lookupKey(v interface{}) string {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return templateString(v)
+}
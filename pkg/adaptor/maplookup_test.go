@@ -0,0 +1,82 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestMapLookup(t *testing.T) {
+	status := map[string]interface{}{"values": map[string]interface{}{"1": "active", "2": "suspended"}}
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{}
+	}{
+		{
+			// codes are mapped whether they're ints, floats or strings
+			Config{"fields": map[string]interface{}{"status": status}},
+			map[string]interface{}{"_id": "1", "status": 1},
+			map[string]interface{}{"_id": "1", "status": "active"},
+		},
+		{
+			Config{"fields": map[string]interface{}{"status": status}},
+			map[string]interface{}{"_id": "1", "status": 2.0},
+			map[string]interface{}{"_id": "1", "status": "suspended"},
+		},
+		{
+			Config{"fields": map[string]interface{}{"status": status}},
+			map[string]interface{}{"_id": "1", "status": "1"},
+			map[string]interface{}{"_id": "1", "status": "active"},
+		},
+		{
+			// unmapped codes are left untouched, unless there's a default
+			Config{"fields": map[string]interface{}{"status": status}},
+			map[string]interface{}{"_id": "1", "status": 9},
+			map[string]interface{}{"_id": "1", "status": 9},
+		},
+		{
+			Config{"fields": map[string]interface{}{"status": map[string]interface{}{"values": map[string]interface{}{"1": "active"}, "default": "unknown"}}},
+			map[string]interface{}{"_id": "1", "status": 9},
+			map[string]interface{}{"_id": "1", "status": "unknown"},
+		},
+		{
+			// several fields, nested fields, a target field, and a missing field
+			Config{"fields": map[string]interface{}{
+				"status":       status,
+				"address.ctry": map[string]interface{}{"values": map[string]interface{}{"ca": "Canada", "us": "United States"}, "target": "address.country"},
+				"tier":         map[string]interface{}{"values": map[string]interface{}{"g": map[string]interface{}{"name": "gold", "rank": 1}}},
+				"missing":      status,
+			}},
+			map[string]interface{}{"_id": "1", "status": 2, "address": bson.M{"ctry": "ca"}, "tier": "g"},
+			map[string]interface{}{"_id": "1", "status": "suspended", "address": bson.M{"ctry": "ca", "country": "Canada"}, "tier": map[string]interface{}{"name": "gold", "rank": float64(1)}},
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "maplookup", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestMapLookupConfigErrors(t *testing.T) {
+	data := []Config{
+		{"namespace": "database.collection"},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"status": map[string]interface{}{"default": "unknown"}}},
+	}
+
+	for _, v := range data {
+		if _, err := NewMapLookup(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
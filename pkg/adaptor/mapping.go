@@ -0,0 +1,87 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamespaceRule maps a source namespace onto a sink target (an index, table or collection).
+// From is either an exact namespace, i.e. "mydb.users", or a regular expression wrapped
+// in '/', i.e. "/mydb\.events_(.*)/".  Regular expressions must match the whole namespace,
+// and To may reference their capture groups, i.e. "events_$1"
+type NamespaceRule struct {
+	From string `json:"from" doc:"the source namespace, or a /regex/ matching the source namespace"`
+	To   string `json:"to" doc:"the sink target, may reference regex capture groups with $1"`
+}
+
+// namespaceMapping holds the compiled rules from a sink's mapping config.
+// rules are tried in order, and the first matching rule wins
+type namespaceMapping struct {
+	rules []namespaceMatcher
+}
+
+type namespaceMatcher struct {
+	exact string
+	re    *regexp.Regexp
+	to    string
+}
+
+// newNamespaceMapping compiles the given rules.  A nil mapping is returned when no rules are given,
+// which is safe to call resolve on
+func newNamespaceMapping(rules []NamespaceRule) (*namespaceMapping, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	m := &namespaceMapping{rules: make([]namespaceMatcher, 0, len(rules))}
+	for _, rule := range rules {
+		if rule.From == "" || rule.To == "" {
+			return nil, fmt.Errorf("malformed mapping, both from and to are required (%s -> %s)", rule.From, rule.To)
+		}
+
+		matcher, err := newNamespaceMatcher(rule.From, rule.To)
+		if err != nil {
+			return nil, fmt.Errorf("malformed mapping, %s", err.Error())
+		}
+		m.rules = append(m.rules, matcher)
+	}
+	return m, nil
+}
+
+// newNamespaceMatcher compiles a namespace, or a /regex/ that must match the whole namespace
+func newNamespaceMatcher(from, to string) (namespaceMatcher, error) {
+	if len(from) > 1 && strings.HasPrefix(from, "/") && strings.HasSuffix(from, "/") {
+		re, err := regexp.Compile("^(?:" + strings.Trim(from, "/") + ")$")
+		if err != nil {
+			return namespaceMatcher{}, fmt.Errorf("can't compile %s (%s)", from, err.Error())
+		}
+		return namespaceMatcher{re: re, to: to}, nil
+	}
+	return namespaceMatcher{exact: from, to: to}, nil
+}
+
+// match returns the target for the namespace, with any capture groups expanded, if the namespace matches
+func (rule namespaceMatcher) match(namespace string) (string, bool) {
+	if rule.re == nil {
+		return rule.to, rule.exact == namespace
+	}
+	if match := rule.re.FindStringSubmatchIndex(namespace); match != nil {
+		return string(rule.re.ExpandString(nil, rule.to, namespace, match)), true
+	}
+	return "", false
+}
+
+// resolve returns the sink target for the given namespace, or the fallback if no rule matches
+func (m *namespaceMapping) resolve(namespace, fallback string) string {
+	if m == nil {
+		return fallback
+	}
+
+	for _, rule := range m.rules {
+		if to, ok := rule.match(namespace); ok {
+			return to
+		}
+	}
+	return fallback
+}
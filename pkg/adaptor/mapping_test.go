@@ -0,0 +1,78 @@
+package adaptor
+
+import (
+	"testing"
+)
+
+func TestNamespaceMapping(t *testing.T) {
+	rules := []NamespaceRule{
+		{From: "mydb.users", To: "search_users"},
+		{From: `/mydb\.events_.*/`, To: "events"},
+		{From: `/(\w+)\.logs_(\d{4})/`, To: "$1-logs-$2"},
+	}
+
+	mapping, err := newNamespaceMapping(rules)
+	if err != nil {
+		t.Fatalf("unexpected error compiling mapping, %s", err)
+	}
+
+	data := []struct {
+		in  string
+		out string
+	}{
+		{"mydb.users", "search_users"},
+		{"mydb.users_archive", "fallback"}, // exact rules don't match prefixes
+		{"mydb.events_2015", "events"},
+		{"mydb.events_", "events"},
+		{"otherdb.events_2015", "fallback"}, // regex rules must match the whole namespace
+		{"app.logs_2015", "app-logs-2015"},
+		{"app.logs_15", "fallback"},
+	}
+
+	for _, v := range data {
+		if got := mapping.resolve(v.in, "fallback"); got != v.out {
+			t.Errorf("%s: expected %s, got %s", v.in, v.out, got)
+		}
+	}
+}
+
+func TestNamespaceMappingRuleOrder(t *testing.T) {
+	mapping, err := newNamespaceMapping([]NamespaceRule{
+		{From: "mydb.events_special", To: "special"},
+		{From: `/mydb\.events_.*/`, To: "events"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error compiling mapping, %s", err)
+	}
+
+	if got := mapping.resolve("mydb.events_special", ""); got != "special" {
+		t.Errorf("expected the first matching rule to win, got %s", got)
+	}
+}
+
+func TestNamespaceMappingErrors(t *testing.T) {
+	data := []struct {
+		in  []NamespaceRule
+		err bool
+	}{
+		{nil, false},
+		{[]NamespaceRule{{From: "a.b", To: "c"}}, false},
+		{[]NamespaceRule{{From: "a.b"}}, true},
+		{[]NamespaceRule{{To: "c"}}, true},
+		{[]NamespaceRule{{From: "/a.(b/", To: "c"}}, true},
+	}
+
+	for _, v := range data {
+		_, err := newNamespaceMapping(v.in)
+		if (err != nil) != v.err {
+			t.Errorf("%+v: expected error %t, got %v", v.in, v.err, err)
+		}
+	}
+}
+
+func TestNilNamespaceMapping(t *testing.T) {
+	var mapping *namespaceMapping
+	if got := mapping.resolve("mydb.users", "users"); got != "users" {
+		t.Errorf("expected fallback from a nil mapping, got %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewMaxDepth creates a transformer that bounds how deeply documents nest, so that documents with dynamic keys
+// can't grow an elasticsearch mapping without limit.  The top level of a document is depth 1, and a field at the
+// limit holding a document, or an array of documents, is collapsed, either encoded as a json string or dropped.
+// Arrays don't add a level, the documents they hold are at the same depth as the array.  The top level _id is left alone
+func NewMaxDepth(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf MaxDepthConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Depth < 1 {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("maxdepth config must contain a depth of at least 1, got %d", conf.Depth), nil)
+	}
+
+	d := &maxDepth{depth: conf.Depth, action: conf.Action, countField: conf.CountField}
+	switch d.action {
+	case "":
+		d.action = "encode"
+	case "encode", "drop":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown action (%s), must be encode or drop", d.action), nil)
+	}
+
+	return newDocTransformer("maxdepth", p, path, extra, d.apply)
+}
+
+// MaxDepthConfig provides configuration options for the maxdepth transformer
+type MaxDepthConfig struct {
+	Namespace  string `json:"namespace" doc:"the set of namespaces to transform"`
+	Depth      int    `json:"depth" doc:"the levels of nesting to keep, the top level of the document is 1"`
+	Action     string `json:"action" doc:"what to do with fields nested too deeply, encode (replace them with their json, the default) or drop"`
+	CountField string `json:"countfield" doc:"if set, a top level field to record the number of collapsed fields in, when there are any"`
+}
+
+type maxDepth struct {
+	depth      int
+	action     string
+	countField string
+}
+
+func (d *maxDepth) apply(msg *message.Msg, doc map[string]interface{}) error {
+	collapsed, err := d.limitDoc(doc, 1)
+	if err != nil {
+		return err
+	}
+	if d.countField != "" && collapsed > 0 {
+		doc[d.countField] = collapsed
+	}
+	return nil
+}
+
+// limitDoc collapses the fields of a document at the given depth that nest too deeply, and returns how many it collapsed
+func (d *maxDepth) limitDoc(doc map[string]interface{}, depth int) (int, error) {
+	collapsed := 0
+	for k, v := range doc {
+		if depth < d.depth {
+			n, err := d.limitValue(v, depth+1)
+			if err != nil {
+				return 0, fmt.Errorf("%s.%s", k, err.Error())
+			}
+			collapsed += n
+			continue
+		}
+		if !nests(v) || (depth == 1 && k == "_id") {
+			continue
+		}
+		collapsed++
+		if d.action == "drop" {
+			delete(doc, k)
+			continue
+		}
+		ba, err := json.Marshal(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s can't be encoded (%s)", k, err.Error())
+		}
+		doc[k] = string(ba)
+	}
+	return collapsed, nil
+}
+
+// limitValue limits the documents held by v, including those in arrays, which are at the same depth
+func (d *maxDepth) limitValue(v interface{}, depth int) (int, error) {
+	if m, ok := asMap(v); ok {
+		return d.limitDoc(m, depth)
+	}
+	elems, ok := asSlice(v)
+	if !ok {
+		return 0, nil
+	}
+	collapsed := 0
+	for _, e := range elems {
+		n, err := d.limitValue(e, depth)
+		if err != nil {
+			return 0, err
+		}
+		collapsed += n
+	}
+	return collapsed, nil
+}
+
+// nests returns whether v is a document, or an array holding a document at any level
+func nests(v interface{}) bool {
+	if _, ok := asMap(v); ok {
+		return true
+	}
+	elems, ok := asSlice(v)
+	if !ok {
+		return false
+	}
+	for _, e := range elems {
+		if nests(e) {
+			return true
+		}
+	}
+	return false
+}
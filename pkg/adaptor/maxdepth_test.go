@@ -0,0 +1,97 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestMaxDepth(t *testing.T) {
+	nested := func() map[string]interface{} {
+		return map[string]interface{}{
+			"_id":  "1",
+			"name": "a",
+			"l1": map[string]interface{}{
+				"n": 1,
+				"l2": bson.M{
+					"n":  2,
+					"l3": map[string]interface{}{"l4": map[string]interface{}{"l5": "deep"}},
+				},
+			},
+		}
+	}
+
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{}
+	}{
+		{
+			Config{"depth": 2},
+			nested(),
+			map[string]interface{}{
+				"_id":  "1",
+				"name": "a",
+				"l1":   map[string]interface{}{"n": 1, "l2": `{"l3":{"l4":{"l5":"deep"}},"n":2}`},
+			},
+		},
+		{
+			Config{"depth": 2, "action": "drop", "countfield": "collapsed"},
+			nested(),
+			map[string]interface{}{"_id": "1", "name": "a", "l1": map[string]interface{}{"n": 1}, "collapsed": 1},
+		},
+		{
+			// a document deep enough already is left alone, and doesn't get a count
+			Config{"depth": 5, "countfield": "collapsed"},
+			nested(),
+			nested(),
+		},
+		{
+			// documents in arrays are at the depth of the array, arrays of scalars aren't collapsed
+			Config{"depth": 2, "countfield": "collapsed"},
+			map[string]interface{}{
+				"_id": bson.M{"tenant": "t", "n": 1},
+				"items": []interface{}{
+					map[string]interface{}{"sku": "x", "attrs": map[string]interface{}{"color": "red"}},
+					map[string]interface{}{"sku": "y", "tags": []interface{}{"a", "b"}, "sizes": []interface{}{map[string]interface{}{"s": 1}}},
+				},
+			},
+			map[string]interface{}{
+				"_id": bson.M{"tenant": "t", "n": 1},
+				"items": []interface{}{
+					map[string]interface{}{"sku": "x", "attrs": `{"color":"red"}`},
+					map[string]interface{}{"sku": "y", "tags": []interface{}{"a", "b"}, "sizes": `[{"s":1}]`},
+				},
+				"collapsed": 2,
+			},
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "maxdepth", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestMaxDepthBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"depth": 0},
+		{"depth": 2, "action": "truncate"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewMaxDepth(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,1215 @@
+//go:build !slim || mongo
+// +build !slim mongo
+
+package adaptor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// testMongoIter replays a fixed set of documents or oplog entries, and then fails with err
+type testMongoIter struct {
+	docs []interface{}
+	err  error
+}
+
+func (i *testMongoIter) Next(result interface{}) bool {
+	if len(i.docs) == 0 {
+		return false
+	}
+	reflect.ValueOf(result).Elem().Set(reflect.ValueOf(i.docs[0]))
+	i.docs = i.docs[1:]
+	return true
+}
+
+func (i *testMongoIter) Timeout() bool { return i.err == nil }
+func (i *testMongoIter) Err() error    { return i.err }
+func (i *testMongoIter) Close() error  { return nil }
+
+func TestMongodbTailReconnects(t *testing.T) {
+	var (
+		queries   []bson.MongoTimestamp
+		refreshes int
+	)
+
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		oplogTime:       newMongoTimestamp(1, 0),
+		refresh:         func() { refreshes++ },
+		ping:            func() error { return nil },
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	// the connection drops after the second entry, the reissued tail picks up from there
+	iters := []*testMongoIter{
+		{docs: []interface{}{
+			oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 1}},
+			oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 2}},
+		}, err: io.EOF},
+		{docs: []interface{}{
+			oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "d", Ns: "db.coll", O: bson.M{"_id": 1}},
+		}, err: io.EOF},
+	}
+	m.oplogTail = func(ts bson.MongoTimestamp) mongoIter {
+		queries = append(queries, ts)
+		if len(iters) == 0 {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		iter := iters[0]
+		iters = iters[1:]
+		return iter
+	}
+
+	done := make(chan error)
+	go func() { done <- m.tailData() }()
+
+	for _, id := range []int{1, 2, 1} {
+		msg := <-out.In
+		if msg.Map()["_id"] != id {
+			t.Errorf("expected _id %d, got %v", id, msg.Map()["_id"])
+		}
+	}
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error tailing the oplog, %s", err)
+	}
+
+	expected := []bson.MongoTimestamp{newMongoTimestamp(1, 0), newMongoTimestamp(3, 0), newMongoTimestamp(4, 0)}
+	if len(queries) != len(expected) {
+		t.Fatalf("expected %d oplog queries, got %d", len(expected), len(queries))
+	}
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Errorf("query %d: expected to resume from %d, got %d", i, expected[i], queries[i])
+		}
+	}
+	if refreshes != 2 {
+		t.Errorf("expected the session to be refreshed twice, got %d", refreshes)
+	}
+}
+
+func TestMongodbTailGivesUp(t *testing.T) {
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		refresh:         func() {},
+		oplogTail:       func(bson.MongoTimestamp) mongoIter { return &testMongoIter{err: io.EOF} },
+	}
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	err := m.tailData()
+	if e, ok := err.(Error); !ok || e.Lvl != CRITICAL {
+		t.Errorf("expected a CRITICAL error after %d reconnects, got %v", mongoReconnectAttempts, err)
+	}
+}
+
+func TestIsReconnectable(t *testing.T) {
+	data := []struct {
+		in  error
+		out bool
+	}{
+		{io.EOF, true},
+		{&netTimeoutError{}, true},
+		{errors.New("no reachable servers"), true},
+		{errors.New("bad query"), false},
+	}
+
+	for _, v := range data {
+		if got := isReconnectable(v.in); got != v.out {
+			t.Errorf("%v: expected %t, got %t", v.in, v.out, got)
+		}
+	}
+}
+
+type netTimeoutError struct{}
+
+func (e *netTimeoutError) Error() string   { return "i/o timeout" }
+func (e *netTimeoutError) Timeout() bool   { return true }
+func (e *netTimeoutError) Temporary() bool { return true }
+
+func TestMongodbSoftDelete(t *testing.T) {
+	docs := []interface{}{
+		bson.M{"_id": 1, "deleted_at": nil},
+		bson.M{"_id": 2, "deleted_at": "2015-09-01T00:00:00Z"},
+		bson.M{"_id": 3},
+		bson.M{"_id": 4, "meta": bson.M{"deleted": false}},
+		bson.M{"_id": 5, "meta": bson.M{"deleted": true}},
+	}
+
+	data := []struct {
+		conf *SoftDeleteConfig
+		tail bool
+		ids  []int
+		ops  []message.OpType
+	}{
+		{&SoftDeleteConfig{Field: "deleted_at", Policy: "delete"}, false, []int{1, 2, 3, 4, 5}, []message.OpType{message.Insert, message.Delete, message.Insert, message.Insert, message.Insert}},
+		{&SoftDeleteConfig{Field: "deleted_at", Policy: "skip"}, false, []int{1, 3, 4, 5}, []message.OpType{message.Insert, message.Insert, message.Insert, message.Insert}},
+		{&SoftDeleteConfig{Field: "meta.deleted", Policy: "delete"}, true, []int{1, 2, 3, 4, 5}, []message.OpType{message.Insert, message.Insert, message.Insert, message.Insert, message.Delete}},
+		{&SoftDeleteConfig{Field: "deleted_at", Policy: "skip"}, true, []int{1, 3, 4, 5}, []message.OpType{message.Insert, message.Insert, message.Insert, message.Insert}},
+		{nil, true, []int{1, 2, 3, 4, 5}, []message.OpType{message.Insert, message.Insert, message.Insert, message.Insert, message.Insert}},
+	}
+
+	for _, v := range data {
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			softDelete:      v.conf,
+			refresh:         func() {},
+			collectionNames: func() ([]string, error) { return []string{"coll"}, nil },
+			copyQuery:       func(string) mongoIter { return &testMongoIter{docs: docs} },
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(m.pipe)
+
+		run := m.catData
+		if v.tail {
+			entries := make([]interface{}, len(docs))
+			for i, doc := range docs {
+				entries[i] = oplogDoc{Ts: newMongoTimestamp(i+1, 0), Op: "i", Ns: "db.coll", O: doc.(bson.M)}
+			}
+			tailed := false
+			m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+				if tailed {
+					m.pipe.Stop()
+					return &testMongoIter{}
+				}
+				tailed = true
+				return &testMongoIter{docs: entries, err: io.EOF}
+			}
+			run = m.tailData
+		}
+
+		done := make(chan error)
+		go func() { done <- run() }()
+
+		var (
+			ids []int
+			ops []message.OpType
+		)
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				ids = append(ids, msg.Map()["_id"].(int))
+				ops = append(ops, msg.Op)
+			case err := <-done:
+				if err != nil {
+					t.Errorf("%+v: unexpected error, %s", v.conf, err)
+				}
+				break A
+			}
+		}
+
+		if !reflect.DeepEqual(ids, v.ids) || !reflect.DeepEqual(ops, v.ops) {
+			t.Errorf("%+v (tail %t): expected %v %v, got %v %v", v.conf, v.tail, v.ids, v.ops, ids, ops)
+		}
+	}
+}
+
+func TestMongoSafe(t *testing.T) {
+	data := []struct {
+		conf MongodbConfig
+		safe *mgo.Safe
+		err  bool
+	}{
+		{MongodbConfig{}, &mgo.Safe{}, false},
+		{MongodbConfig{Wc: 2, FSync: true}, &mgo.Safe{W: 2, FSync: true}, false},
+		{MongodbConfig{Wc: 2, WriteConcern: "3"}, &mgo.Safe{W: 3}, false},
+		{MongodbConfig{WriteConcern: "majority", Journal: true, WTimeout: "5s"}, &mgo.Safe{WMode: "majority", J: true, WTimeout: 5000}, false},
+		{MongodbConfig{WriteConcern: "0"}, nil, false},
+		{MongodbConfig{WriteConcern: "-1"}, nil, true},
+		{MongodbConfig{WriteConcern: "majority", WTimeout: "soon"}, nil, true},
+	}
+
+	for _, v := range data {
+		safe, err := mongoSafe(v.conf)
+		if (err != nil) != v.err {
+			t.Errorf("%+v: expected error %v, got %v", v.conf, v.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(safe, v.safe) {
+			t.Errorf("%+v: expected %+v, got %+v", v.conf, v.safe, safe)
+		}
+	}
+}
+
+func TestMongodbTailFilter(t *testing.T) {
+	filter, err := compileFilter(map[string]interface{}{"status": "active"})
+	if err != nil {
+		t.Fatalf("unexpected error compiling the filter, %s", err)
+	}
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		filter:          filter,
+		refresh:         func() {},
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	entries := []interface{}{
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 1, "status": "active"}},
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 2, "status": "archived"}},
+		oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 3}},
+		oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "d", Ns: "db.coll", O: bson.M{"_id": 2}},
+		oplogDoc{Ts: newMongoTimestamp(5, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 4, "status": "archived"}},
+	}
+	tailed := false
+	m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+		if tailed {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		tailed = true
+		return &testMongoIter{docs: entries, err: io.EOF}
+	}
+
+	done := make(chan error)
+	go func() { done <- m.tailData() }()
+
+	var (
+		ids      []int
+		versions []bson.MongoTimestamp
+	)
+A:
+	for {
+		select {
+		case msg := <-out.In:
+			ids = append(ids, msg.Map()["_id"].(int))
+			versions = append(versions, bson.MongoTimestamp(msg.Version))
+		case err := <-done:
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+			break A
+		}
+	}
+
+	// the delete is sent even though it can't be matched
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("expected ids [1 2], got %v", ids)
+	}
+	// each message is versioned by its oplog entry
+	if expected := []bson.MongoTimestamp{newMongoTimestamp(1, 0), newMongoTimestamp(4, 0)}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("expected versions %v, got %v", expected, versions)
+	}
+	// filtered entries still move the oplog position on
+	if m.oplogTime != newMongoTimestamp(5, 0) {
+		t.Errorf("expected to resume from %d, got %d", newMongoTimestamp(5, 0), m.oplogTime)
+	}
+}
+
+func TestMongodbTailOrigin(t *testing.T) {
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		refresh:         func() {},
+		fetchFullDoc:    true,
+		origin:          &OriginConfig{Field: "_origin", Name: "b", Skip: []string{"a"}},
+	}
+	// every document was last written by the other pipeline's sink
+	m.fetchDocs = func(collection string, ids []interface{}) ([]bson.M, error) {
+		var docs []bson.M
+		for _, id := range ids {
+			docs = append(docs, bson.M{"_id": id, "_origin": "a"})
+		}
+		return docs, nil
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	entries := []interface{}{
+		// the sink's own writes, echoed back by the oplog
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 1, "_origin": "a"}},
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "u", Ns: "db.coll", O: bson.M{"_id": 2, "_origin": "a"}, O2: bson.M{"_id": 2}},
+		oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "u", Ns: "db.coll", O: bson.M{"$v": 2, "diff": bson.M{"u": bson.M{"_origin": "a"}}}, O2: bson.M{"_id": 3}},
+		// local changes, including to documents the sink wrote
+		oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 4}},
+		oplogDoc{Ts: newMongoTimestamp(5, 0), Op: "u", Ns: "db.coll", O: bson.M{"$set": bson.M{"name": "x"}}, O2: bson.M{"_id": 1}},
+		oplogDoc{Ts: newMongoTimestamp(6, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 5, "_origin": "c"}},
+		oplogDoc{Ts: newMongoTimestamp(7, 0), Op: "u", Ns: "db.coll", O: bson.M{"$set": bson.M{"_origin": "a"}}, O2: bson.M{"_id": 6}},
+	}
+	tailed := false
+	m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+		if tailed {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		tailed = true
+		return &testMongoIter{docs: entries, err: io.EOF}
+	}
+
+	done := make(chan error)
+	go func() { done <- m.tailData() }()
+
+	var ids []int
+A:
+	for {
+		select {
+		case msg := <-out.In:
+			ids = append(ids, msg.Map()["_id"].(int))
+		case err := <-done:
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+			break A
+		}
+	}
+
+	// the update to 1 is sent whole, origin and all, even though the document came from a
+	if !reflect.DeepEqual(ids, []int{4, 1, 5}) {
+		t.Errorf("expected ids [4 1 5], got %v", ids)
+	}
+	// skipped entries still move the oplog position on
+	if m.oplogTime != newMongoTimestamp(7, 0) {
+		t.Errorf("expected to resume from %d, got %d", newMongoTimestamp(7, 0), m.oplogTime)
+	}
+
+	// a sink marks a copy, the pipeline's other sinks see the document unchanged
+	doc := map[string]interface{}{"_id": 1}
+	if marked := m.origin.mark(doc); !reflect.DeepEqual(marked, map[string]interface{}{"_id": 1, "_origin": "b"}) {
+		t.Errorf("expected the document to be marked with b, got %v", marked)
+	}
+	if _, ok := doc["_origin"]; ok {
+		t.Errorf("expected the message's document to be left unmarked, got %v", doc)
+	}
+}
+
+func TestMongodbTailDDL(t *testing.T) {
+	entries := []interface{}{
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 1}},
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "c", Ns: "db.$cmd", O: bson.M{"drop": "coll"}},
+		oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "c", Ns: "db.$cmd", O: bson.M{"drop": "system.profile"}},
+		oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "c", Ns: "other.$cmd", O: bson.M{"drop": "coll"}},
+		oplogDoc{Ts: newMongoTimestamp(5, 0), Op: "c", Ns: "admin.$cmd", O: bson.M{"renameCollection": "db.coll", "to": "db.archive", "stayTemp": false}},
+		oplogDoc{Ts: newMongoTimestamp(6, 0), Op: "c", Ns: "db.$cmd", O: bson.M{"create": "coll"}},
+		oplogDoc{Ts: newMongoTimestamp(7, 0), Op: "c", Ns: "db.$cmd", O: bson.M{"dropDatabase": 1}},
+	}
+
+	tail := func(ddl bool) []*message.Msg {
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			refresh:         func() {},
+			ddl:             ddl,
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(m.pipe)
+		tailed := false
+		m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+			if tailed {
+				m.pipe.Stop()
+				return &testMongoIter{}
+			}
+			tailed = true
+			return &testMongoIter{docs: entries, err: io.EOF}
+		}
+
+		done := make(chan error)
+		go func() { done <- m.tailData() }()
+
+		var msgs []*message.Msg
+		for {
+			select {
+			case msg := <-out.In:
+				msgs = append(msgs, msg)
+			case err := <-done:
+				if err != nil {
+					t.Errorf("unexpected error, %s", err)
+				}
+				if m.oplogTime != newMongoTimestamp(7, 0) {
+					t.Errorf("expected to resume from %d, got %d", newMongoTimestamp(7, 0), m.oplogTime)
+				}
+				return msgs
+			}
+		}
+	}
+
+	// without ddl commands are passed over
+	if msgs := tail(false); len(msgs) != 1 || msgs[0].Op != message.Insert {
+		t.Errorf("expected just the insert, got %v", msgs)
+	}
+
+	expected := []struct {
+		command   string
+		data      map[string]interface{}
+		namespace string
+		version   bson.MongoTimestamp
+	}{
+		{message.DropCommand, map[string]interface{}{"drop": "coll"}, "db.coll", newMongoTimestamp(2, 0)},
+		{message.RenameCommand, map[string]interface{}{"renameCollection": "db.coll", "to": "db.archive"}, "db.coll", newMongoTimestamp(5, 0)},
+		{message.DropDatabaseCommand, map[string]interface{}{"dropDatabase": 1}, "db.$cmd", newMongoTimestamp(7, 0)},
+	}
+	msgs := tail(true)
+	if len(msgs) != len(expected)+1 {
+		t.Fatalf("expected the insert and %d commands, got %v", len(expected), msgs)
+	}
+	for i, v := range expected {
+		msg := msgs[i+1]
+		if msg.Op != message.Command || msg.DDLCommand() != v.command || msg.Namespace != v.namespace || bson.MongoTimestamp(msg.Version) != v.version {
+			t.Errorf("expected a %s of %s at %d, got %s %s %v at %d", v.command, v.namespace, v.version, msg.Op, msg.DDLCommand(), msg.Namespace, msg.Version)
+		}
+		if !reflect.DeepEqual(msg.Map(), v.data) {
+			t.Errorf("expected %v, got %v", v.data, msg.Map())
+		}
+	}
+}
+
+func TestMongodbLimit(t *testing.T) {
+	docs := []interface{}{bson.M{"_id": 1}, bson.M{"_id": 2}, bson.M{"_id": 3}}
+	entries := []interface{}{
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 4}},
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 5}},
+	}
+
+	data := []struct {
+		skip, limit int
+		ids         []int
+	}{
+		{0, 2, []int{1, 2}},
+		{1, 3, []int{2, 3, 4}},
+		{4, 0, []int{5}},
+	}
+
+	for _, v := range data {
+		limit, _ := newSourceLimit(v.skip, v.limit)
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			tail:            true,
+			limit:           limit,
+			refresh:         func() {},
+			collectionNames: func() ([]string, error) { return []string{"coll"}, nil },
+			copyQuery:       func(string) mongoIter { return &testMongoIter{docs: docs} },
+		}
+		tailed := false
+		m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+			if tailed {
+				m.pipe.Stop()
+				return &testMongoIter{}
+			}
+			tailed = true
+			return &testMongoIter{docs: entries, err: io.EOF}
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(m.pipe)
+
+		done := make(chan error)
+		go func() { done <- m.Start() }()
+
+		var ids []int
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				ids = append(ids, msg.Map()["_id"].(int))
+			case err := <-done:
+				if err != nil {
+					t.Errorf("skip %d, limit %d: unexpected error, %s", v.skip, v.limit, err)
+				}
+				break A
+			}
+		}
+		if !reflect.DeepEqual(ids, v.ids) {
+			t.Errorf("skip %d, limit %d: expected %v, got %v", v.skip, v.limit, v.ids, ids)
+		}
+	}
+}
+
+func TestMongodbResume(t *testing.T) {
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		refresh:         func() {},
+		collectionNames: func() ([]string, error) { return []string{"coll"}, nil },
+		copyQuery:       func(string) mongoIter { return &testMongoIter{docs: []interface{}{bson.M{"_id": 1}}} },
+	}
+	if err := m.Resume("8589934592"); err == nil {
+		t.Errorf("expected an error resuming a source that doesn't tail")
+	}
+	m.tail = true
+	if err := m.Resume("yesterday"); err == nil {
+		t.Errorf("expected an error resuming from a bad token")
+	}
+	if err := m.Resume("8589934592"); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if m.Checkpoint() != "" {
+		t.Errorf("expected no checkpoint before tailing, got %s", m.Checkpoint())
+	}
+
+	var queries []bson.MongoTimestamp
+	m.oplogTail = func(ts bson.MongoTimestamp) mongoIter {
+		queries = append(queries, ts)
+		if len(queries) > 1 {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		return &testMongoIter{docs: []interface{}{
+			oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 2}},
+		}, err: io.EOF}
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	done := make(chan error)
+	go func() { done <- m.Start() }()
+
+	// the copy is skipped, the first document is the one from the oplog
+	if msg := <-out.In; msg.Map()["_id"] != 2 {
+		t.Errorf("expected _id 2, got %v", msg.Map()["_id"])
+	}
+	<-done
+	if queries[0] != newMongoTimestamp(2, 0) {
+		t.Errorf("expected to tail from %d, got %d", newMongoTimestamp(2, 0), queries[0])
+	}
+	if m.Checkpoint() != strconv.FormatInt(int64(newMongoTimestamp(3, 0)), 10) {
+		t.Errorf("expected checkpoint %d, got %s", newMongoTimestamp(3, 0), m.Checkpoint())
+	}
+}
+
+func TestMongodbTailTransactions(t *testing.T) {
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile(".*"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		refresh:         func() {},
+		fetchFullDoc:    true,
+		fetchDocs: func(collection string, ids []interface{}) ([]bson.M, error) {
+			return []bson.M{{"_id": ids[0], "updated": true}}, nil
+		},
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	lsid := bson.M{"id": "session"}
+	entries := []interface{}{
+		// a transaction committed in one entry
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "c", Ns: "admin.$cmd", Lsid: lsid, TxnNumber: 1, O: bson.M{"applyOps": []interface{}{
+			bson.M{"op": "i", "ns": "db.coll", "o": bson.M{"_id": 1}},
+			bson.M{"op": "u", "ns": "db.coll", "o": bson.M{"$set": bson.M{"updated": true}}, "o2": bson.M{"_id": 2}},
+		}}},
+		// a prepared transaction, committed later
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "c", Ns: "admin.$cmd", Lsid: lsid, TxnNumber: 2, O: bson.M{"applyOps": []interface{}{
+			bson.M{"op": "i", "ns": "db.coll", "o": bson.M{"_id": 3}},
+		}, "prepare": true}},
+		// a large transaction that's aborted
+		oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "c", Ns: "admin.$cmd", Lsid: lsid, TxnNumber: 3, O: bson.M{"applyOps": []interface{}{
+			bson.M{"op": "i", "ns": "db.coll", "o": bson.M{"_id": 4}},
+		}, "partialTxn": true}},
+		oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 5}},
+		oplogDoc{Ts: newMongoTimestamp(5, 0), Op: "c", Ns: "admin.$cmd", Lsid: lsid, TxnNumber: 3, O: bson.M{"abortTransaction": 1}},
+		oplogDoc{Ts: newMongoTimestamp(6, 0), Op: "c", Ns: "admin.$cmd", Lsid: lsid, TxnNumber: 2, O: bson.M{"commitTransaction": 1}},
+		// other commands are skipped
+		oplogDoc{Ts: newMongoTimestamp(7, 0), Op: "c", Ns: "db.$cmd", O: bson.M{"create": "other"}},
+	}
+	tailed := false
+	m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+		if tailed {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		tailed = true
+		return &testMongoIter{docs: entries, err: io.EOF}
+	}
+
+	done := make(chan error)
+	go func() { done <- m.tailData() }()
+
+	var msgs []string
+A:
+	for {
+		select {
+		case msg := <-out.In:
+			msgs = append(msgs, fmt.Sprintf("%s %v %v", msg.Op, msg.Map()["_id"], msg.Map()["updated"]))
+		case err := <-done:
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+			break A
+		}
+	}
+
+	expected := []string{"insert 1 <nil>", "update 2 true", "insert 5 <nil>", "insert 3 <nil>"}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %v, got %v", expected, msgs)
+	}
+	if m.oplogTime != newMongoTimestamp(7, 0) {
+		t.Errorf("expected to resume from %d, got %d", newMongoTimestamp(7, 0), m.oplogTime)
+	}
+}
+
+func TestMongodbFetchFullDoc(t *testing.T) {
+	entries := []interface{}{
+		oplogDoc{Ts: newMongoTimestamp(1, 0), Op: "u", Ns: "db.coll", O: bson.M{"$set": bson.M{"a": 2}}, O2: bson.M{"_id": 1}},
+		oplogDoc{Ts: newMongoTimestamp(2, 0), Op: "u", Ns: "db.other", O: bson.M{"$set": bson.M{"b": 2}}, O2: bson.M{"_id": 1}},
+		oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "u", Ns: "db.coll", O: bson.M{"$unset": bson.M{"a": 1}}, O2: bson.M{"_id": 2}},
+		oplogDoc{Ts: newMongoTimestamp(4, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 3}},
+		oplogDoc{Ts: newMongoTimestamp(5, 0), Op: "u", Ns: "db.coll", O: bson.M{"$set": bson.M{"a": 3}}, O2: bson.M{"_id": 3}},
+	}
+	current := map[string][]bson.M{
+		"coll":  {{"_id": 1, "a": 2, "c": 1}, {"_id": 3, "a": 3}}, // 2 has since been deleted
+		"other": {{"_id": 1, "b": 2}},
+	}
+
+	data := []struct {
+		fetchFullDoc bool
+		msgs         []string
+		fetches      []string
+	}{
+		{
+			true,
+			[]string{"update db.coll map[_id:1 a:2 c:1]", "update db.other map[_id:1 b:2]", "delete db.coll map[_id:2]", "insert db.coll map[_id:3]", "update db.coll map[_id:3 a:3]"},
+			[]string{"coll [1 2]", "coll [3]", "other [1]"}, // the updates before the insert are fetched together
+		},
+		{
+			false,
+			[]string{"update db.coll map[$set:map[a:2] _id:1]", "update db.other map[$set:map[b:2] _id:1]", "update db.coll map[$unset:map[a:1] _id:2]", "insert db.coll map[_id:3]", "update db.coll map[$set:map[a:3] _id:3]"},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		var fetches []string
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			refresh:         func() {},
+			fetchFullDoc:    v.fetchFullDoc,
+			fetchDocs: func(collection string, ids []interface{}) ([]bson.M, error) {
+				fetches = append(fetches, fmt.Sprintf("%s %v", collection, ids))
+				return current[collection], nil
+			},
+		}
+		tailed := false
+		m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+			if tailed {
+				m.pipe.Stop()
+				return &testMongoIter{}
+			}
+			tailed = true
+			return &testMongoIter{docs: entries, err: io.EOF}
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(m.pipe)
+
+		done := make(chan error)
+		go func() { done <- m.tailData() }()
+
+		var msgs []string
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				msgs = append(msgs, fmt.Sprintf("%s %s %v", msg.Op, msg.Namespace, msg.Data))
+			case err := <-done:
+				if err != nil {
+					t.Errorf("fetchfulldoc %v: unexpected error, %s", v.fetchFullDoc, err)
+				}
+				break A
+			}
+		}
+
+		sort.Strings(fetches)
+		if !reflect.DeepEqual(msgs, v.msgs) {
+			t.Errorf("fetchfulldoc %v: expected %v, got %v", v.fetchFullDoc, v.msgs, msgs)
+		}
+		if !reflect.DeepEqual(fetches, v.fetches) {
+			t.Errorf("fetchfulldoc %v: expected fetches %v, got %v", v.fetchFullDoc, v.fetches, fetches)
+		}
+	}
+}
+
+func TestMongodbErrorCategories(t *testing.T) {
+	data := []struct {
+		err      error
+		expected ErrorCategory
+	}{
+		{io.EOF, TRANSIENT},
+		{errors.New("no reachable servers"), TRANSIENT},
+		{&mgo.LastError{Code: 11000, Err: "E11000 duplicate key error"}, PERMANENT},
+		{&mgo.QueryError{Code: 10334, Message: "BSONObj size is invalid"}, PERMANENT},
+		{mgo.ErrNotFound, PERMANENT},
+		{errors.New("something else"), UNCATEGORIZED},
+	}
+	for _, v := range data {
+		if c := mongoErrorCategory(v.err); c != v.expected {
+			t.Errorf("[%v] expected %s, got %s", v.err, v.expected, c)
+		}
+	}
+
+	for _, conf := range []Config{
+		{"namespace": "test.coll"},
+		{"uri": "mongodb://localhost/test", "namespace": "test.coll", "softdelete": map[string]interface{}{"field": "deleted", "policy": "hide"}},
+		{"uri": "mongodb://localhost/test", "namespace": "test.coll", "filter": map[string]interface{}{"$where": "1"}},
+		{"uri": "mongodb://localhost/test", "namespace": "test.coll", "writeconcern": "-1"},
+		{"uri": "mongodb://localhost/test", "namespace": "test.coll", "origin": map[string]interface{}{"field": "_origin"}},
+		{"uri": "mongodb://localhost/test", "namespace": "test.coll", "origin": map[string]interface{}{"field": "meta.origin", "name": "a"}},
+	} {
+		if _, err := NewMongodb(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("[%v] expected a config error, got %v", conf, err)
+		}
+	}
+
+	// nothing's listening on port 1, so the dial fails
+	_, err := NewMongodb(pipe.NewPipe(nil, "path"), "path", Config{"uri": "mongodb://127.0.0.1:1/test", "namespace": "test.coll", "timeout": "100ms"})
+	if CategoryOf(err) != TRANSIENT {
+		t.Errorf("expected a transient error, got %v", err)
+	}
+}
+
+func TestMongodbOplogTimestamp(t *testing.T) {
+	changed := time.Date(2016, 3, 1, 12, 30, 0, 0, time.UTC)
+	data := []struct {
+		conf     *OplogTimestampConfig
+		copied   interface{} // the field in the copied document, nil if it's not set
+		expected interface{} // the field in the tailed documents
+	}{
+		{&OplogTimestampConfig{Field: "changed_at", Format: "rfc3339"}, nil, "2016-03-01T12:30:00Z"},
+		{&OplogTimestampConfig{Field: "meta.changed", Format: "epoch", Default: 0}, 0, changed.Unix()},
+		{&OplogTimestampConfig{Field: "changed_at", Format: "date", Default: "unknown"}, "unknown", changed},
+	}
+
+	for _, v := range data {
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			oplogTimestamp:  v.conf,
+			refresh:         func() {},
+			collectionNames: func() ([]string, error) { return []string{"coll"}, nil },
+			copyQuery:       func(string) mongoIter { return &testMongoIter{docs: []interface{}{bson.M{"_id": 1}}} },
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+		go func(p *pipe.Pipe) {
+			for range p.Err {
+				// noop
+			}
+		}(m.pipe)
+
+		entries := []interface{}{
+			oplogDoc{Ts: bson.MongoTimestamp(changed.Unix()<<32 + 1), Op: "i", Ns: "db.coll", O: bson.M{"_id": 2}},
+			oplogDoc{Ts: bson.MongoTimestamp(changed.Unix()<<32 + 2), Op: "d", Ns: "db.coll", O: bson.M{"_id": 2}},
+		}
+		tailed := false
+		m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+			if tailed {
+				m.pipe.Stop()
+				return &testMongoIter{}
+			}
+			tailed = true
+			return &testMongoIter{docs: entries, err: io.EOF}
+		}
+
+		done := make(chan error)
+		go func() {
+			if err := m.catData(); err != nil {
+				done <- err
+				return
+			}
+			done <- m.tailData()
+		}()
+
+		var docs []map[string]interface{}
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				docs = append(docs, msg.Map())
+			case err := <-done:
+				if err != nil {
+					t.Errorf("%+v: unexpected error, %s", v.conf, err)
+				}
+				break A
+			}
+		}
+
+		if len(docs) != 3 {
+			t.Fatalf("%+v: expected 3 documents, got %v", v.conf, docs)
+		}
+		copied, ok := getField(docs[0], v.conf.Field)
+		if v.copied == nil && ok || v.copied != nil && !reflect.DeepEqual(copied, v.copied) {
+			t.Errorf("%+v: expected the copied document's field to be %v, got %v", v.conf, v.copied, copied)
+		}
+		// the insert and the delete both carry the time of the change
+		for _, doc := range docs[1:] {
+			if ts, _ := getField(doc, v.conf.Field); !reflect.DeepEqual(ts, v.expected) {
+				t.Errorf("%+v: expected %v (%T), got %v (%T)", v.conf, v.expected, v.expected, ts, ts)
+			}
+		}
+	}
+
+	for _, conf := range []map[string]interface{}{{"format": "epoch"}, {"field": "ts", "format": "unix"}} {
+		_, err := NewMongodb(pipe.NewPipe(nil, "path"), "path", Config{"uri": "mongodb://localhost", "namespace": "db.coll", "oplogtimestamp": conf})
+		if e, ok := err.(Error); !ok || e.Category != CONFIG {
+			t.Errorf("%v: expected a config error, got %v", conf, err)
+		}
+	}
+}
+
+func TestMongodbNamespaceField(t *testing.T) {
+	m := &Mongodb{
+		database:        "db",
+		collectionMatch: regexp.MustCompile("^(orders|users)$"),
+		pipe:            pipe.NewPipe(nil, "path"),
+		path:            "path",
+		namespaceField:  "source.ns",
+		refresh:         func() {},
+		collectionNames: func() ([]string, error) { return []string{"orders", "users"}, nil },
+		copyQuery: func(collection string) mongoIter {
+			return &testMongoIter{docs: []interface{}{bson.M{"_id": collection}}}
+		},
+	}
+	out := pipe.NewPipe(m.pipe, "out")
+	errs := make(chan error, 10)
+	go func(p *pipe.Pipe) {
+		for err := range p.Err {
+			errs <- err
+		}
+	}(m.pipe)
+
+	entries := []interface{}{
+		oplogDoc{Ts: bson.MongoTimestamp(1<<32 + 1), Op: "i", Ns: "db.users", O: bson.M{"_id": 2}},
+		oplogDoc{Ts: bson.MongoTimestamp(1<<32 + 2), Op: "i", Ns: "db.orders", O: bson.M{"_id": 3, "source": bson.M{"ns": "mine"}}},
+	}
+	tailed := false
+	m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+		if tailed {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		tailed = true
+		return &testMongoIter{docs: entries, err: io.EOF}
+	}
+
+	done := make(chan error)
+	go func() {
+		if err := m.catData(); err != nil {
+			done <- err
+			return
+		}
+		done <- m.tailData()
+	}()
+
+	var docs []map[string]interface{}
+A:
+	for {
+		select {
+		case msg := <-out.In:
+			docs = append(docs, msg.Map())
+		case err := <-done:
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+			break A
+		}
+	}
+
+	// the document that already has the field keeps its value
+	expected := []interface{}{"db.orders", "db.users", "db.users", "mine"}
+	if len(docs) != len(expected) {
+		t.Fatalf("expected %d documents, got %v", len(expected), docs)
+	}
+	for i, doc := range docs {
+		if ns, _ := getField(doc, "source.ns"); ns != expected[i] {
+			t.Errorf("%v: expected the namespace %v, got %v", doc, expected[i], ns)
+		}
+	}
+	select {
+	case err := <-errs:
+		if e, ok := err.(Error); !ok || e.Lvl != WARNING {
+			t.Errorf("expected a warning, got %v", err)
+		}
+	default:
+		t.Errorf("expected a warning for the document that already has the field")
+	}
+}
+
+func TestMongodbSSHTunnel(t *testing.T) {
+	// the database isn't mongo, so the dial fails, but not before it's been reached through the tunnel.  mgo
+	// resolves the servers itself, so the test uses an address rather than a name only the bastion knows
+	db, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	reached := make(chan bool, 100)
+	go func() {
+		for {
+			conn, err := db.Accept()
+			if err != nil {
+				return
+			}
+			reached <- true
+			conn.Close()
+		}
+	}()
+	server := newTestSSHServer(t, map[string]string{"10.1.2.3:27017": db.Addr().String()})
+	defer server.Close()
+
+	_, err = NewMongodb(pipe.NewPipe(nil, "path"), "path", Config{
+		"uri":       "mongodb://10.1.2.3:27017/test",
+		"namespace": "test.coll",
+		"timeout":   "500ms",
+		"sshtunnel": map[string]interface{}{"host": server.Addr().String(), "user": "u", "password": "secret", "insecureignorehostkey": true},
+	})
+	if CategoryOf(err) != TRANSIENT {
+		t.Errorf("expected a transient error, got %v", err)
+	}
+	if events := server.recorded(); len(events) < 2 || events[0] != "auth u password" || events[1] != "open 10.1.2.3:27017" {
+		t.Errorf("expected the tunnel to be opened to the server in the uri, got %v", events)
+	}
+	select {
+	case <-reached:
+	default:
+		t.Errorf("expected the database to be reached through the tunnel")
+	}
+
+	_, err = NewMongodb(pipe.NewPipe(nil, "path"), "path", Config{"uri": "mongodb://10.1.2.3:27017/test", "namespace": "test.coll", "sshtunnel": map[string]interface{}{"host": "bastion", "user": "u"}})
+	if CategoryOf(err) != CONFIG {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestMongodbCopyCompleteMarker(t *testing.T) {
+	m := &Mongodb{
+		database:           "db",
+		collectionMatch:    regexp.MustCompile(".*"),
+		pipe:               pipe.NewPipe(nil, "path"),
+		path:               "path",
+		tail:               true,
+		copyCompleteMarker: true,
+		refresh:            func() {},
+		collectionNames:    func() ([]string, error) { return []string{"coll"}, nil },
+		copyQuery:          func(string) mongoIter { return &testMongoIter{docs: []interface{}{bson.M{"_id": 1}}} },
+	}
+	tailed := false
+	m.oplogTail = func(bson.MongoTimestamp) mongoIter {
+		if tailed {
+			m.pipe.Stop()
+			return &testMongoIter{}
+		}
+		tailed = true
+		return &testMongoIter{docs: []interface{}{
+			oplogDoc{Ts: newMongoTimestamp(3, 0), Op: "i", Ns: "db.coll", O: bson.M{"_id": 2}},
+		}, err: io.EOF}
+	}
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(m.pipe)
+
+	// the sink's listener is given the documents, and told when the copy's complete
+	events := make(chan string, 10)
+	sink := pipe.NewPipe(m.pipe, "sink")
+	sink.OnCopyComplete = func() { events <- "copy complete" }
+	go sink.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		events <- fmt.Sprintf("%s %v", msg.Op, msg.Map()["_id"])
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	var seen []string
+	for timeout := time.After(time.Second); len(seen) < 3; {
+		select {
+		case e := <-events:
+			seen = append(seen, e)
+		case <-timeout:
+			t.Fatalf("expected 3 messages and markers, got %v", seen)
+		}
+	}
+	sink.Stop()
+
+	expected := []string{"insert 1", "copy complete", "insert 2"}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected %v, got %v", expected, seen)
+	}
+	if m.pipe.MessageCount != 2 || sink.MessageCount != 2 || sink.CopyComplete.IsZero() {
+		t.Errorf("expected the marker to reach the sink without being counted, got %d and %d messages", m.pipe.MessageCount, sink.MessageCount)
+	}
+}
+
+func TestMongodbCopyWorkers(t *testing.T) {
+	// most of the _ids are bunched at the end, the splits still share the documents out evenly
+	var ids []int
+	for i := 1; i <= 10; i++ {
+		ids = append(ids, i)
+	}
+	for i := 1000; i < 1090; i++ {
+		ids = append(ids, i)
+	}
+
+	for _, workers := range []int{2, 3, 7, 150} {
+		var (
+			lock   sync.Mutex
+			ranges [][2]interface{}
+		)
+		m := &Mongodb{
+			database:        "db",
+			collectionMatch: regexp.MustCompile(".*"),
+			pipe:            pipe.NewPipe(nil, "path"),
+			path:            "path",
+			copyWorkers:     workers,
+			limit:           &sourceLimit{},
+			collectionNames: func() ([]string, error) { return []string{"coll"}, nil },
+			copySplits: func(collection string, n int) ([]interface{}, error) {
+				return splitPoints(len(ids), n, func(skip int) (interface{}, error) { return ids[skip], nil })
+			},
+			copyRange: func(collection string, min, max interface{}) mongoIter {
+				lock.Lock()
+				ranges = append(ranges, [2]interface{}{min, max})
+				lock.Unlock()
+				var docs []interface{}
+				for _, id := range ids {
+					if (min == nil || id >= min.(int)) && (max == nil || id < max.(int)) {
+						docs = append(docs, bson.M{"_id": id})
+					}
+				}
+				return &testMongoIter{docs: docs}
+			},
+		}
+		out := pipe.NewPipe(m.pipe, "out")
+
+		done := make(chan error)
+		go func() { done <- m.catData() }()
+
+		copied := map[int]int{}
+	A:
+		for {
+			select {
+			case msg := <-out.In:
+				copied[msg.Map()["_id"].(int)]++
+			case err := <-done:
+				if err != nil {
+					t.Errorf("%d workers: unexpected error, %s", workers, err)
+				}
+				break A
+			}
+		}
+
+		for _, id := range ids {
+			if copied[id] != 1 {
+				t.Errorf("%d workers: expected %d to be copied once, got %d", workers, id, copied[id])
+			}
+		}
+		if len(copied) != len(ids) {
+			t.Errorf("%d workers: expected %d documents, got %d", workers, len(ids), len(copied))
+		}
+		expected := workers
+		if expected > len(ids) {
+			expected = len(ids)
+		}
+		if len(ranges) != expected {
+			t.Errorf("%d workers: expected %d ranges, got %v", workers, expected, ranges)
+		}
+	}
+}
+
+func TestSplitPoints(t *testing.T) {
+	ids := []interface{}{1, 2, 3, 50, 51, 52, 53, 54, 55, 1000}
+	nth := func(skip int) (interface{}, error) { return ids[skip], nil }
+
+	data := []struct {
+		n        int
+		expected []interface{}
+	}{
+		{1, nil},
+		{2, []interface{}{52}},
+		{4, []interface{}{3, 52, 54}},
+		{20, ids[1:]},
+	}
+	for _, v := range data {
+		splits, err := splitPoints(len(ids), v.n, nth)
+		if err != nil {
+			t.Errorf("%d: unexpected error, %s", v.n, err)
+		}
+		if !reflect.DeepEqual(splits, v.expected) {
+			t.Errorf("%d: expected %v, got %v", v.n, v.expected, splits)
+		}
+	}
+
+	mixed := []interface{}{1, 2, "a", "b"}
+	if _, err := splitPoints(len(mixed), 4, func(skip int) (interface{}, error) { return mixed[skip], nil }); err == nil {
+		t.Errorf("expected splits of different types to fail")
+	}
+}
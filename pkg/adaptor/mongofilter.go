@@ -0,0 +1,239 @@
+package adaptor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// docFilter reports whether a document matches a query
+type docFilter func(doc map[string]interface{}) bool
+
+// compileFilter compiles a mongo style query into a docFilter, so that oplog entries can be matched
+// without a round trip to the server.  only a subset of the query language is understood,
+// field equality, $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin and $exists, combined with $and, $or and $nor.
+// as in mongo, a condition on an array field matches if any of its elements do
+func compileFilter(query map[string]interface{}) (docFilter, error) {
+	var filters []docFilter
+	for key, v := range query {
+		switch key {
+		case "$and", "$or", "$nor":
+			clauses, ok := v.([]interface{})
+			if !ok || len(clauses) == 0 {
+				return nil, fmt.Errorf("%s must be a non empty array of queries", key)
+			}
+			var subs []docFilter
+			for _, clause := range clauses {
+				q, ok := asMap(clause)
+				if !ok {
+					return nil, fmt.Errorf("%s must be a non empty array of queries", key)
+				}
+				sub, err := compileFilter(q)
+				if err != nil {
+					return nil, err
+				}
+				subs = append(subs, sub)
+			}
+			filters = append(filters, combineFilters(key, subs))
+		default:
+			if strings.HasPrefix(key, "$") {
+				return nil, fmt.Errorf("unsupported query operator %s", key)
+			}
+			f, err := compileCondition(key, v)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+	}
+	return combineFilters("$and", filters), nil
+}
+
+func combineFilters(op string, filters []docFilter) docFilter {
+	return func(doc map[string]interface{}) bool {
+		for _, f := range filters {
+			matched := f(doc)
+			switch {
+			case op == "$and" && !matched:
+				return false
+			case op == "$or" && matched:
+				return true
+			case op == "$nor" && matched:
+				return false
+			}
+		}
+		return op != "$or"
+	}
+}
+
+// compileCondition compiles the condition on a single field, either a value to compare against
+// or a document of operators
+func compileCondition(path string, cond interface{}) (docFilter, error) {
+	ops, ok := asMap(cond)
+	if !ok || len(ops) == 0 || !isOperatorDoc(ops) {
+		return fieldFilter(path, func(v interface{}) bool { return filterEqual(v, cond) }), nil
+	}
+
+	var filters []docFilter
+	for op, arg := range ops {
+		var f docFilter
+		switch op {
+		case "$eq":
+			arg := arg
+			f = fieldFilter(path, func(v interface{}) bool { return filterEqual(v, arg) })
+		case "$ne":
+			arg := arg
+			f = negateFilter(fieldFilter(path, func(v interface{}) bool { return filterEqual(v, arg) }))
+		case "$gt", "$gte", "$lt", "$lte":
+			op, arg := op, arg
+			f = fieldFilter(path, func(v interface{}) bool {
+				c, ok := filterCompare(v, arg)
+				if !ok {
+					return false
+				}
+				switch op {
+				case "$gt":
+					return c > 0
+				case "$gte":
+					return c >= 0
+				case "$lt":
+					return c < 0
+				}
+				return c <= 0
+			})
+		case "$in", "$nin":
+			values, ok := arg.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s for %s must be an array", op, path)
+			}
+			f = fieldFilter(path, func(v interface{}) bool {
+				for _, value := range values {
+					if filterEqual(v, value) {
+						return true
+					}
+				}
+				return false
+			})
+			if op == "$nin" {
+				f = negateFilter(f)
+			}
+		case "$exists":
+			exists, ok := arg.(bool)
+			if !ok {
+				return nil, fmt.Errorf("$exists for %s must be true or false", path)
+			}
+			f = func(doc map[string]interface{}) bool {
+				_, ok := getField(doc, path)
+				return ok == exists
+			}
+		default:
+			return nil, fmt.Errorf("unsupported query operator %s for %s", op, path)
+		}
+		filters = append(filters, f)
+	}
+	return combineFilters("$and", filters), nil
+}
+
+func isOperatorDoc(doc map[string]interface{}) bool {
+	for k := range doc {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldFilter matches documents where the value at path, or any element of it if it's an array, passes the test
+func fieldFilter(path string, test func(interface{}) bool) docFilter {
+	return func(doc map[string]interface{}) bool {
+		v, ok := getField(doc, path)
+		if !ok {
+			return false
+		}
+		if test(v) {
+			return true
+		}
+		if values, ok := v.([]interface{}); ok {
+			for _, value := range values {
+				if test(value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func negateFilter(f docFilter) docFilter {
+	return func(doc map[string]interface{}) bool { return !f(doc) }
+}
+
+// filterEqual compares a document value to a query value, numbers of any type are compared by value
+func filterEqual(v, q interface{}) bool {
+	if c, ok := filterCompare(v, q); ok {
+		return c == 0
+	}
+	if vm, ok := asMap(v); ok {
+		if qm, ok := asMap(q); ok {
+			return reflect.DeepEqual(vm, qm)
+		}
+	}
+	return reflect.DeepEqual(v, q)
+}
+
+// filterCompare orders two numbers, strings or times, the second return value is false if
+// the values can't be ordered against each other
+func filterCompare(v, q interface{}) (int, bool) {
+	if a, ok := filterNumber(v); ok {
+		b, ok := filterNumber(q)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch a := v.(type) {
+	case string:
+		b, ok := q.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	case time.Time:
+		b, ok := q.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a.Before(b):
+			return -1, true
+		case a.After(b):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func filterNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package adaptor
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCompileFilter(t *testing.T) {
+	doc := map[string]interface{}{
+		"_id":    1,
+		"status": "active",
+		"age":    42,
+		"tags":   []interface{}{"a", "b"},
+		"meta":   bson.M{"score": 1.5, "at": time.Date(2017, 7, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	data := []struct {
+		query   map[string]interface{}
+		matched bool
+	}{
+		{map[string]interface{}{}, true},
+		{map[string]interface{}{"status": "active"}, true},
+		{map[string]interface{}{"status": "archived"}, false},
+		// numbers from the config are float64
+		{map[string]interface{}{"age": float64(42)}, true},
+		{map[string]interface{}{"status": "active", "age": float64(41)}, false},
+		{map[string]interface{}{"meta.score": map[string]interface{}{"$gt": float64(1), "$lte": 1.5}}, true},
+		{map[string]interface{}{"meta.score": map[string]interface{}{"$lt": float64(1)}}, false},
+		{map[string]interface{}{"meta.at": map[string]interface{}{"$gte": time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}}, true},
+		{map[string]interface{}{"status": map[string]interface{}{"$gt": float64(1)}}, false},
+		{map[string]interface{}{"status": map[string]interface{}{"$ne": "archived"}}, true},
+		{map[string]interface{}{"missing": map[string]interface{}{"$ne": "archived"}}, true},
+		{map[string]interface{}{"status": map[string]interface{}{"$in": []interface{}{"new", "active"}}}, true},
+		{map[string]interface{}{"status": map[string]interface{}{"$nin": []interface{}{"new", "active"}}}, false},
+		// arrays match if any element does
+		{map[string]interface{}{"tags": "b"}, true},
+		{map[string]interface{}{"tags": map[string]interface{}{"$in": []interface{}{"c", "a"}}}, true},
+		{map[string]interface{}{"tags": map[string]interface{}{"$ne": "a"}}, false},
+		{map[string]interface{}{"meta.score": map[string]interface{}{"$exists": true}}, true},
+		{map[string]interface{}{"meta.deleted": map[string]interface{}{"$exists": true}}, false},
+		{map[string]interface{}{"$or": []interface{}{map[string]interface{}{"status": "new"}, map[string]interface{}{"age": float64(42)}}}, true},
+		{map[string]interface{}{"$and": []interface{}{map[string]interface{}{"status": "active"}, map[string]interface{}{"age": float64(1)}}}, false},
+		{map[string]interface{}{"$nor": []interface{}{map[string]interface{}{"status": "new"}, map[string]interface{}{"age": float64(1)}}}, true},
+	}
+
+	for _, v := range data {
+		filter, err := compileFilter(v.query)
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.query, err)
+			continue
+		}
+		if matched := filter(doc); matched != v.matched {
+			t.Errorf("%v: expected %t, got %t", v.query, v.matched, matched)
+		}
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	data := []map[string]interface{}{
+		{"$where": "this.a > 1"},
+		{"status": map[string]interface{}{"$regex": "^a"}},
+		{"status": map[string]interface{}{"$in": "active"}},
+		{"status": map[string]interface{}{"$exists": float64(1)}},
+		{"$or": []interface{}{}},
+		{"$or": []interface{}{"status"}},
+	}
+
+	for _, v := range data {
+		if _, err := compileFilter(v); err == nil {
+			t.Errorf("%v: expected an error", v)
+		}
+	}
+}
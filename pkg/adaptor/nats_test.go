@@ -0,0 +1,263 @@
+//go:build !slim || nats
+// +build !slim nats
+
+package adaptor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"github.com/nats-io/nats.go"
+)
+
+type testNatsPublish struct {
+	subject string
+	header  nats.Header
+	data    string
+}
+
+// testNatsConn records what's published, and delivers whatever is sent on msgs to the subscriber
+type testNatsConn struct {
+	sync.Mutex
+
+	published []testNatsPublish
+	consumers []string
+	msgs      chan *nats.Msg
+}
+
+func (c *testNatsConn) PublishMsg(m *nats.Msg) error {
+	c.Lock()
+	defer c.Unlock()
+	c.published = append(c.published, testNatsPublish{m.Subject, m.Header, string(m.Data)})
+	return nil
+}
+
+func (c *testNatsConn) Subscribe(subject, queue string) (natsSubscription, error) {
+	return testNatsSubscription(c.msgs), nil
+}
+
+func (c *testNatsConn) AddConsumer(stream string, conf *nats.ConsumerConfig) error {
+	c.Lock()
+	defer c.Unlock()
+	c.consumers = append(c.consumers, fmt.Sprintf("%s %s %s", stream, conf.Durable, conf.DeliverSubject))
+	return nil
+}
+
+func (c *testNatsConn) Flush() error { return nil }
+
+func (c *testNatsConn) Close() {}
+
+func (c *testNatsConn) publishes() []testNatsPublish {
+	c.Lock()
+	defer c.Unlock()
+	return append([]testNatsPublish(nil), c.published...)
+}
+
+type testNatsSubscription chan *nats.Msg
+
+func (s testNatsSubscription) NextMsg(timeout time.Duration) (*nats.Msg, error) {
+	select {
+	case m := <-s:
+		return m, nil
+	case <-time.After(timeout):
+		return nil, nats.ErrTimeout
+	}
+}
+
+func TestNatsPublish(t *testing.T) {
+	conn := &testNatsConn{}
+	rules, _ := newNamespaceMapping([]NamespaceRule{{From: "db.orders", To: "orders.events"}})
+	n := &Nats{subject: "db.events", pipe: pipe.NewPipe(nil, "path"), path: "path", conn: conn, mapping: rules, serializer: jsonSerializer{}}
+
+	data := []*message.Msg{
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "db.users"),
+		message.NewMsg(message.Delete, map[string]interface{}{"_id": "2"}, "db.orders"),
+		message.NewMsg(message.Command, map[string]interface{}{"flush": true}, "db.users"),
+	}
+	for _, v := range data {
+		n.publish(v)
+	}
+
+	expected := []testNatsPublish{
+		{"db.events", nats.Header{natsOpHeader: {"insert"}, natsNamespaceHeader: {"db.users"}}, `{"_id":"1"}`},
+		{"orders.events", nats.Header{natsOpHeader: {"delete"}, natsNamespaceHeader: {"db.orders"}}, `{"_id":"2"}`},
+	}
+	if got := conn.publishes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestNatsStreamAcks(t *testing.T) {
+	conn := &testNatsConn{msgs: make(chan *nats.Msg, 10)}
+	n := &Nats{subject: "events", stream: "EVENTS", durable: "transporter", database: "db", pipe: pipe.NewPipe(nil, "path"), path: "path", conn: conn, timeout: time.Second, stop: make(chan struct{}), done: make(chan struct{})}
+	out := pipe.NewPipe(n.pipe, "out")
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+			// noop
+		}
+	}(n.pipe)
+
+	conn.msgs <- &nats.Msg{Subject: "events", Reply: "$JS.ACK.EVENTS.transporter.1.1.1", Data: []byte(`{"_id":"1"}`)}
+	conn.msgs <- &nats.Msg{Subject: "events", Reply: "$JS.ACK.EVENTS.transporter.1.2.2", Data: []byte(`not json`)}
+	conn.msgs <- &nats.Msg{Subject: "events", Reply: "$JS.ACK.EVENTS.transporter.1.3.3", Header: nats.Header{natsOpHeader: {"delete"}, natsNamespaceHeader: {"db.users"}}, Data: []byte(`{"_id":"3"}`)}
+
+	done := make(chan error)
+	go func() { done <- n.Start() }()
+
+	// the first message is only acknowledged after it's accepted
+	time.Sleep(10 * time.Millisecond)
+	if got := conn.publishes(); len(got) != 0 {
+		t.Errorf("expected no acks before the message is accepted, got %+v", got)
+	}
+	msg := <-out.In
+	if msg.Op != message.Insert || msg.Namespace != "db.events" || msg.Map()["_id"] != "1" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	msg = <-out.In
+	if msg.Op != message.Delete || msg.Namespace != "db.users" || msg.Map()["_id"] != "3" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	for i := 0; i < 100 && len(conn.publishes()) < 3; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	n.Stop()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+
+	conn.Lock()
+	if expected := []string{"EVENTS transporter transporter.deliver.EVENTS.transporter"}; !reflect.DeepEqual(conn.consumers, expected) {
+		t.Errorf("expected the durable consumer %v to be created, got %v", expected, conn.consumers)
+	}
+	conn.Unlock()
+	expected := []testNatsPublish{
+		{"$JS.ACK.EVENTS.transporter.1.1.1", nil, ""},
+		{"$JS.ACK.EVENTS.transporter.1.2.2", nil, "+TERM"},
+		{"$JS.ACK.EVENTS.transporter.1.3.3", nil, ""},
+	}
+	if got := conn.publishes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected acks %+v, got %+v", expected, got)
+	}
+}
+
+// TestNatsClient runs the client against a scripted server
+func TestNatsClient(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen, %s", err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("INFO {\"server_id\":\"test\",\"headers\":true,\"max_payload\":1048576}\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT"):
+				var options map[string]interface{}
+				json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &options)
+				received <- "CONNECT " + options["user"].(string)
+			case strings.HasPrefix(line, "PING"):
+				conn.Write([]byte("PONG\r\n"))
+			case strings.HasPrefix(line, "SUB"):
+				received <- strings.TrimSpace(line)
+				conn.Write([]byte("PING\r\nMSG events 1 5\r\nhello\r\n"))
+				conn.Write([]byte("HMSG events 1 reply.to 31 36\r\nNATS/1.0\r\nTransporter-Op: d\r\n\r\nworld\r\n"))
+			case strings.HasPrefix(line, "HPUB"):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				body := make([]byte, size+2)
+				io.ReadFull(r, body)
+				received <- strings.TrimSpace(line) + " " + strings.TrimSpace(string(body))
+			case strings.HasPrefix(line, "PONG"):
+				received <- "PONG"
+			}
+		}
+	}()
+
+	c, err := dialNats("nats://nick:secret@"+l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatalf("can't connect, %s", err)
+	}
+	defer c.Close()
+
+	if got := <-received; got != "CONNECT nick" {
+		t.Errorf("expected credentials in the CONNECT, got %s", got)
+	}
+
+	sub, err := c.Subscribe("events", "workers")
+	if err != nil {
+		t.Fatalf("can't subscribe, %s", err)
+	}
+	if got := <-received; got != "SUB events workers 1" {
+		t.Errorf("unexpected SUB, %s", got)
+	}
+	if got := <-received; got != "PONG" {
+		t.Errorf("expected the client to answer a PING, got %s", got)
+	}
+
+	if msg, err := sub.NextMsg(time.Second); err != nil || msg.Subject != "events" || string(msg.Data) != "hello" {
+		t.Errorf("unexpected MSG %+v, %v", msg, err)
+	}
+	msg, err := sub.NextMsg(time.Second)
+	if err != nil || msg.Reply != "reply.to" || msg.Header.Get(natsOpHeader) != "d" || string(msg.Data) != "world" {
+		t.Errorf("unexpected HMSG %+v, %v", msg, err)
+	}
+
+	if err := c.PublishMsg(&nats.Msg{Subject: "out", Header: nats.Header{natsOpHeader: {"i"}}, Data: []byte("doc")}); err != nil {
+		t.Fatalf("can't publish, %s", err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("can't flush, %s", err)
+	}
+	if got := <-received; got != "HPUB out 31 34 NATS/1.0\r\nTransporter-Op: i\r\n\r\ndoc" {
+		t.Errorf("unexpected HPUB %q", got)
+	}
+}
+
+func TestNewNats(t *testing.T) {
+	data := []struct {
+		in  Config
+		err bool
+	}{
+		{Config{"uri": "nats://localhost:4222", "namespace": "db.events", "subject": "events"}, false},
+		{Config{"uri": "nats://localhost:4222", "namespace": "db.events"}, true},
+		{Config{"uri": "nats://localhost:4222", "subject": "events"}, true},
+		{Config{"uri": "nats://localhost:4222", "namespace": "db.events", "subject": "events", "timeout": "soon"}, true},
+	}
+
+	for _, v := range data {
+		if _, err := NewNats(nil, "path", v.in); (err != nil) != v.err {
+			t.Errorf("%+v: expected error %t, got %v", v.in, v.err, err)
+		}
+	}
+
+	a, _ := NewNats(nil, "path", Config{"uri": "nats://localhost:4222", "namespace": "db./.*/", "subject": "events"})
+	if n := a.(*Nats); n.durable != "transporter" || n.database != "db" || !reflect.DeepEqual(n.nsMatch, regexp.MustCompile(".*")) {
+		t.Errorf("unexpected defaults %+v", n)
+	}
+}
@@ -0,0 +1,111 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// the ways a sink can react to a failed write, set per node with the onerror option
+const (
+	onErrorFail       = "fail"       // stop the adaptor
+	onErrorSkip       = "skip"       // report the error and carry on without the document
+	onErrorDeadLetter = "deadletter" // append the document to the deadletter file and carry on
+)
+
+// errorPolicy decides what a sink does with documents it failed to write.
+// every sink reads the onerror and deadletter options from its node's config, the default
+// is whatever the sink did before the option existed
+type errorPolicy struct {
+	pipe   *pipe.Pipe
+	path   string
+	action string
+
+	deadLetter string
+	sync.Mutex // serializes writes to the dead letter file
+
+	counts *sinkCounts // if set, the documents the policy is given are counted as failed
+}
+
+func newErrorPolicy(p *pipe.Pipe, path string, extra Config, def string) (*errorPolicy, error) {
+	e := &errorPolicy{pipe: p, path: path, action: extra.GetString("onerror"), deadLetter: extra.GetString("deadletter")}
+	switch e.action {
+	case "":
+		e.action = def
+	case onErrorFail, onErrorSkip:
+	case onErrorDeadLetter:
+		if e.deadLetter == "" {
+			return nil, fmt.Errorf("onerror deadletter requires a deadletter file")
+		}
+	default:
+		return nil, fmt.Errorf("unknown onerror policy (%s), must be fail, skip or deadletter", e.action)
+	}
+	return e, nil
+}
+
+// handle applies the policy to the documents of a failed write.  It returns a CRITICAL Error when
+// the adaptor should stop, which is either the policy, or because the dead letter file can't be written
+func (e *errorPolicy) handle(msg string, docs ...interface{}) error {
+	return e.handleCategorized(UNCATEGORIZED, msg, docs...)
+}
+
+// handleCategorized applies the policy as handle does, the errors it reports have the given category
+func (e *errorPolicy) handleCategorized(category ErrorCategory, msg string, docs ...interface{}) error {
+	if e.counts != nil {
+		e.counts.failedToWrite(len(docs))
+	}
+
+	var record interface{}
+	if len(docs) == 1 {
+		record = docs[0]
+	} else if len(docs) > 1 {
+		record = docs
+	}
+
+	switch e.action {
+	case onErrorSkip:
+		e.pipe.Err <- NewCategorizedError(category, ERROR, e.path, msg, record)
+	case onErrorDeadLetter:
+		if err := e.writeDeadLetters(msg, docs); err != nil {
+			return NewCategorizedError(category, CRITICAL, e.path, fmt.Sprintf("%s, and can't write to the dead letter file (%s)", msg, err.Error()), record)
+		}
+		e.pipe.Err <- NewCategorizedError(category, WARNING, e.path, fmt.Sprintf("%s, %d documents sent to %s", msg, len(docs), e.deadLetter), nil)
+	default:
+		return NewCategorizedError(category, CRITICAL, e.path, msg, record)
+	}
+	return nil
+}
+
+// deadLetterRecord is a line of the dead letter file
+type deadLetterRecord struct {
+	Path  string      `json:"path"`
+	Error string      `json:"error"`
+	Time  string      `json:"time"`
+	Doc   interface{} `json:"doc"`
+}
+
+// writeDeadLetters appends a line to the dead letter file for each document.  failures should be rare,
+// so the file is opened for each write rather than held open for the life of the adaptor
+func (e *errorPolicy) writeDeadLetters(msg string, docs []interface{}) error {
+	e.Lock()
+	defer e.Unlock()
+
+	f, err := os.OpenFile(e.deadLetter, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	enc := json.NewEncoder(f)
+	for _, doc := range docs {
+		if err := enc.Encode(deadLetterRecord{Path: e.path, Error: msg, Time: now, Doc: doc}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
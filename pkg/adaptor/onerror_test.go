@@ -0,0 +1,83 @@
+package adaptor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+func TestErrorPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []struct {
+		conf     Config
+		def      string
+		critical bool       // handle returns a CRITICAL error
+		lvl      ErrorLevel // otherwise the level of the error sent down the pipe
+	}{
+		{Config{}, onErrorSkip, false, ERROR},
+		{Config{}, onErrorFail, true, 0},
+		{Config{"onerror": "fail"}, onErrorSkip, true, 0},
+		{Config{"onerror": "skip"}, onErrorFail, false, ERROR},
+		{Config{"onerror": "deadletter", "deadletter": filepath.Join(dir, "dead.json")}, onErrorFail, false, WARNING},
+		// a dead letter file that can't be written stops the adaptor rather than lose the document
+		{Config{"onerror": "deadletter", "deadletter": filepath.Join(dir, "missing", "dead.json")}, onErrorSkip, true, 0},
+	}
+
+	for _, v := range data {
+		p := pipe.NewPipe(nil, "path")
+		p.Err = make(chan error, 10)
+		e, err := newErrorPolicy(p, "path", v.conf, v.def)
+		if err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.conf, err)
+		}
+
+		err = e.handle("sink error (write failed)", map[string]interface{}{"_id": "1"})
+		if v.critical {
+			if err == nil || err.(Error).Lvl != CRITICAL {
+				t.Errorf("%v: expected a CRITICAL error, got %v", v.conf, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+		}
+		select {
+		case err := <-p.Err:
+			if err.(Error).Lvl != v.lvl {
+				t.Errorf("%v: expected a %d level error, got %v", v.conf, v.lvl, err)
+			}
+		default:
+			t.Errorf("%v: expected an error on the pipe", v.conf)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "dead.json"))
+	if err != nil {
+		t.Fatalf("can't read the dead letter file, %s", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"doc":{"_id":"1"}`) || !strings.Contains(lines[0], `"error":"sink error (write failed)"`) {
+		t.Errorf("unexpected dead letter file %q", b)
+	}
+}
+
+func TestErrorPolicyBadConfig(t *testing.T) {
+	data := []Config{
+		{"onerror": "ignore"},
+		{"onerror": "deadletter"},
+	}
+
+	for _, v := range data {
+		if _, err := newErrorPolicy(nil, "path", v, onErrorFail); err == nil {
+			t.Errorf("%v: expected an error", v)
+		}
+	}
+}
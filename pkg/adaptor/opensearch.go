@@ -0,0 +1,155 @@
+//go:build !slim || opensearch
+// +build !slim opensearch
+
+package adaptor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+func init() {
+	Register("opensearch", "an opensearch sink adaptor, with basic auth or AWS SigV4 signing", NewOpenSearch, OpenSearchConfig{})
+}
+
+// NewOpenSearch creates an adaptor that writes to an OpenSearch cluster, or to an Elasticsearch compatible
+// cluster that isn't appbase.  It accumulates bulks as the appbase sink does, authenticates with basic auth
+// or, for AWS managed domains, signs its requests with SigV4.
+// OpenSearch adaptors cannot be used as a source
+func NewOpenSearch(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf OpenSearchConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.URI == "" {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "uri required, but missing", nil)
+	}
+	if conf.Namespace == "" {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "namespace required, but missing", nil)
+	}
+	if (conf.UserName == "") != (conf.Password == "") {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "both username and password required for basic auth", nil)
+	}
+
+	var signer *sigV4Transport
+	if conf.SigV4 {
+		if conf.UserName != "" {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, "bad config (sigv4 and basic auth can't be used together)", nil)
+		}
+		region := conf.Region
+		for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+			if region == "" {
+				region = os.Getenv(env)
+			}
+		}
+		if region == "" {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, "region required for sigv4, but missing", nil)
+		}
+		service := conf.Service
+		if service == "" {
+			service = "es"
+		}
+		credentials, err := resolveAWSCredentials(awsCredentials{
+			AccessKeyID:     conf.AccessKeyID,
+			SecretAccessKey: conf.SecretAccessKey,
+			SessionToken:    conf.SessionToken,
+		}, conf.Profile)
+		if err != nil {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+		}
+		signer = newSigV4Transport(nil, credentials, region, service)
+	}
+
+	return newAppbase(p, path, extra, AppbaseConfig{
+		URI:                 conf.URI,
+		UserName:            conf.UserName,
+		Password:            conf.Password,
+		Namespace:           conf.Namespace,
+		Debug:               conf.Debug,
+		BulkSize:            conf.BulkSize,
+		Mapping:             conf.Mapping,
+		Breaker:             conf.Breaker,
+		DataStream:          conf.DataStream,
+		InjectTimestamp:     conf.InjectTimestamp,
+		CompressRequests:    conf.CompressRequests,
+		Refresh:             conf.Refresh,
+		Version:             conf.Version,
+		VersionField:        conf.VersionField,
+		MaxInflightBatches:  conf.MaxInflightBatches,
+		Reindex:             conf.Reindex,
+		IndexField:          conf.IndexField,
+		SkipUnchanged:       conf.SkipUnchanged,
+		SoftDelete:          conf.SoftDelete,
+		RetryOnConflict:     conf.RetryOnConflict,
+		PostFlush:           conf.PostFlush,
+		Pipeline:            conf.Pipeline,
+		IDPrefix:            conf.IDPrefix,
+		MaxFailureRatio:     conf.MaxFailureRatio,
+		FlushTimeout:        conf.FlushTimeout,
+		SlowFlushThreshold:  conf.SlowFlushThreshold,
+		MaxFlushesPerSecond: conf.MaxFlushesPerSecond,
+		SizeHistogram:       conf.SizeHistogram,
+		WaitForActiveShards: conf.WaitForActiveShards,
+		InvalidIndex:        conf.InvalidIndex,
+	}, signer)
+}
+
+// OpenSearchConfig provides configuration options for the opensearch sink, the bulk options are the appbase sink's
+type OpenSearchConfig struct {
+	URI       string          `json:"uri" doc:"the uri to connect to, in the form https://search-domain.us-east-1.es.amazonaws.com"`
+	UserName  string          `json:"username" doc:"the username for basic auth"`
+	Password  string          `json:"password" doc:"the password for basic auth"`
+	Namespace string          `json:"namespace" doc:"the index and type to write"`
+	Debug     bool            `json:"debug" doc:"display debug information"`
+	BulkSize  int             `json:"bulksize" doc:"Define the size of the buffer to bulk operations"`
+	Mapping   []NamespaceRule `json:"mapping" doc:"rules translating source namespaces into the index to write to"`
+	Breaker   *BreakerConfig  `json:"breaker,omitempty" doc:"circuit breaker options, when set failed writes are retried until the breaker opens, then they and the writes it turns away go to onerror, which defaults to skip"`
+
+	DataStream       bool        `json:"datastream" doc:"write to a data stream, inserts are sent as create actions and updates and deletes are rejected"`
+	InjectTimestamp  bool        `json:"injecttimestamp" doc:"when writing to a data stream, set a missing @timestamp from the message timestamp"`
+	CompressRequests bool        `json:"compressrequests" doc:"gzip the body of bulk requests, falling back to uncompressed requests if the server doesn't accept them"`
+	Refresh          interface{} `json:"refresh" doc:"whether each bulk refreshes the index so its documents are searchable at once, false (the default, for throughput), true or wait_for"`
+
+	Version            string `json:"version" doc:"write documents with external versions, so stale changes arriving out of order are skipped, oplog (the timestamp of the mongo change) or field (the value of versionfield)"`
+	VersionField       string `json:"versionfield" doc:"the dotted path of the number or date field holding the document's version, when the version is field"`
+	MaxInflightBatches int    `json:"maxinflightbatches" doc:"the most bulk requests, each for a different index, waiting on the cluster at once, defaults to 4"`
+
+	Reindex *ReindexConfig `json:"reindex,omitempty" doc:"write to a new index, and move an alias to it once the backfill is complete"`
+
+	SkipUnchanged string `json:"skipunchanged" doc:"the field holding the content hash set by the contenthash transformer, inserts and updates whose hash matches the stored document's are skipped, at the cost of reading the stored hash"`
+
+	IndexField   string `json:"indexfield" doc:"the dotted path of a field holding the index to write each document to, i.e. tenant_id, deletes carrying only an _id don't have it"`
+	InvalidIndex string `json:"invalidindex" doc:"what to do when the index field is missing or isn't a valid index name, error (the default), sanitize (lowercase it and replace the characters that aren't allowed) or default (write to the namespace's index)"`
+
+	SoftDelete *SoftDeleteMarkConfig `json:"softdelete,omitempty" doc:"mark deleted documents with a field, rather than removing them"`
+
+	RetryOnConflict int `json:"retryonconflict" doc:"how many times the cluster retries an update that conflicts with a concurrent write, defaults to 0"`
+
+	PostFlush *PostFlushConfig `json:"postflush,omitempty" doc:"a command to run after each bulk is committed, with the index and the number of documents"`
+
+	Pipeline *string `json:"pipeline,omitempty" doc:"the ingest pipeline the cluster runs each indexed document through, to enrich or transform it server side"`
+
+	MaxFailureRatio float64 `json:"maxfailureratio" doc:"when set, the share of a bulk's documents, between 0 and 1, that can fail before the whole bulk is handled by onerror, failures below it are reported, or dead lettered, one by one"`
+
+	IDPrefix string `json:"idprefix" doc:"a prefix for every _id written, deleted or updated, so that sources sharing an index don't overwrite each other, which may reference {{namespace}}, {{database}} or {{collection}}, i.e. mongo:{{collection}}:"`
+
+	FlushTimeout       string `json:"flushtimeout" doc:"how long a bulk request can take before it's given up on, format must be parsable by time.ParseDuration. a timeout is a transient error, retried when the breaker is set, unset waits forever"`
+	SlowFlushThreshold string `json:"slowflushthreshold" doc:"report a warning with the duration and number of documents of any bulk request taking longer than this, format must be parsable by time.ParseDuration"`
+
+	MaxFlushesPerSecond float64 `json:"maxflushespersecond" doc:"the most bulk requests sent a second, i.e. to stay under a plan's request limit. a bulk that's due waits its turn, and adding documents waits with it, unset doesn't limit them"`
+
+	SizeHistogram bool `json:"sizehistogram" doc:"count the size of each document's bulk request in a histogram, sent with the metrics as a sizes event holding the min, max, p50, p95 and p99 sizes and the power of two buckets, to help tune bulksize and find outsized documents"`
+
+	WaitForActiveShards interface{} `json:"waitforactiveshards" doc:"the shard copies that must be active before a bulk is written, all or a number, i.e. 2 for the primary and one replica, unset uses the index's own setting"`
+
+	SigV4           bool   `json:"sigv4" doc:"sign requests with AWS SigV4, as AWS managed domains require"`
+	Region          string `json:"region" doc:"the AWS region of the domain, defaults to AWS_REGION or AWS_DEFAULT_REGION"`
+	Service         string `json:"service" doc:"the AWS service the domain belongs to, es (the default) or aoss for serverless collections"`
+	AccessKeyID     string `json:"accesskeyid" doc:"the AWS access key to sign with, by default one is found in the environment or shared credentials file"`
+	SecretAccessKey string `json:"secretaccesskey" doc:"the AWS secret key to sign with"`
+	SessionToken    string `json:"sessiontoken" doc:"the AWS session token, for temporary credentials"`
+	Profile         string `json:"profile" doc:"the shared credentials file profile, defaults to AWS_PROFILE or default"`
+}
@@ -0,0 +1,170 @@
+//go:build !slim || opensearch
+// +build !slim opensearch
+
+package adaptor
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+func TestOpenSearchSigV4(t *testing.T) {
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+
+	// the server checks each bulk's signature by signing the request it received again
+	var (
+		mu    sync.Mutex
+		auths []string
+		bad   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+			return // health checks
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		auth := r.Header.Get("Authorization")
+		auths = append(auths, auth)
+
+		// only the headers the client signed are copied, the transport adds others after signing
+		when, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), bytes.NewReader(body))
+		check.ContentLength = int64(len(body))
+		if i := strings.Index(auth, "SignedHeaders="); i >= 0 {
+			for _, k := range strings.Split(strings.SplitN(auth[i+len("SignedHeaders="):], ",", 2)[0], ";") {
+				if k != "host" && k != "content-length" && k != "x-amz-date" && k != "x-amz-security-token" {
+					check.Header.Set(k, r.Header.Get(k))
+				}
+			}
+		}
+		if err == nil {
+			err = v4.NewSigner().SignHTTP(context.Background(), aws.Credentials{
+				AccessKeyID:     creds.AccessKeyID,
+				SecretAccessKey: creds.SecretAccessKey,
+				SessionToken:    creds.SessionToken,
+			}, check, hashHex(body), "es", "us-east-1", when)
+		}
+		if err != nil || check.Header.Get("Authorization") != auth {
+			bad = append(bad, auth)
+		}
+		if r.Header.Get("X-Amz-Content-Sha256") != hashHex(body) || r.Header.Get("X-Amz-Security-Token") != "token" {
+			bad = append(bad, auth)
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	for _, compress := range []bool{false, true} {
+		auths, bad = nil, nil
+		node, err := NewOpenSearch(pipe.NewPipe(nil, "path"), "path", Config{
+			"uri":              server.URL,
+			"namespace":        "app.type",
+			"sigv4":            true,
+			"region":           "us-east-1",
+			"accesskeyid":      creds.AccessKeyID,
+			"secretaccesskey":  creds.SecretAccessKey,
+			"sessiontoken":     creds.SessionToken,
+			"compressrequests": compress,
+			"refresh":          "wait_for",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		a := node.(*Appbase)
+		if err := a.setupClient(); err != nil {
+			t.Fatalf("can't connect to test server, %s", err)
+		}
+		a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "name": "nick"}, "app.type"))
+		a.commitBulk(true)
+
+		mu.Lock()
+		if len(auths) != 1 || !strings.HasPrefix(auths[0], "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auths[0], "/us-east-1/es/aws4_request") {
+			t.Errorf("[compress %t] expected the bulk to be signed with SigV4, got %q", compress, auths)
+		}
+		if len(bad) > 0 {
+			t.Errorf("[compress %t] expected valid signatures, got %q", compress, bad)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestOpenSearchBasicAuth(t *testing.T) {
+	auths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_bulk") {
+			auths <- r.Header.Get("Authorization")
+			w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	node, err := NewOpenSearch(pipe.NewPipe(nil, "path"), "path", Config{"uri": server.URL, "namespace": "app.type", "username": "admin", "password": "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	a := node.(*Appbase)
+	if err := a.setupClient(); err != nil {
+		t.Fatalf("can't connect to test server, %s", err)
+	}
+	a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type"))
+	a.commitBulk(true)
+
+	if auth := <-auths; !strings.HasPrefix(auth, "Basic ") {
+		t.Errorf("expected basic auth, got %q", auth)
+	}
+}
+
+func TestOpenSearchBadConfig(t *testing.T) {
+	for _, k := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Unsetenv(k)
+	}
+
+	keys := Config{"uri": "https://search.example.com", "namespace": "app.type", "sigv4": true, "accesskeyid": "AKID", "secretaccesskey": "secret"}
+	with := func(extra Config) Config {
+		conf := Config{}
+		for k, v := range keys {
+			conf[k] = v
+		}
+		for k, v := range extra {
+			conf[k] = v
+		}
+		return conf
+	}
+	data := []Config{
+		{"namespace": "app.type"},
+		{"uri": "https://search.example.com"},
+		{"uri": "https://search.example.com", "namespace": "app.type", "username": "admin"},
+		with(nil), // no region
+		with(Config{"region": "us-east-1", "username": "admin", "password": "secret"}),
+		with(Config{"region": "us-east-1", "secretaccesskey": ""}),
+	}
+	for _, conf := range data {
+		if _, err := NewOpenSearch(pipe.NewPipe(nil, "path"), "path", conf); err == nil {
+			t.Errorf("[%v] expected an error", conf)
+		}
+	}
+
+	os.Setenv("AWS_REGION", "eu-west-1")
+	node, err := NewOpenSearch(pipe.NewPipe(nil, "path"), "path", keys)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if signer := node.(*Appbase).signer; signer.region != "eu-west-1" || signer.service != "es" {
+		t.Errorf("expected to sign for es in eu-west-1, got %s in %s", signer.service, signer.region)
+	}
+}
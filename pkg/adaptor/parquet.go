@@ -0,0 +1,421 @@
+//go:build !slim || parquet
+// +build !slim parquet
+
+package adaptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"github.com/parquet-go/parquet-go"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	Register("parquet", "a parquet file sink adaptor", NewParquet, ParquetConfig{})
+}
+
+// the column types understood by the parquet sink
+var parquetKinds = map[string]parquet.Kind{
+	"string": parquet.ByteArray,
+	"long":   parquet.Int64,
+	"double": parquet.Double,
+	"bool":   parquet.Boolean,
+}
+
+// Parquet is a sink adaptor that buffers documents and writes them to a directory as parquet files, deletes are ignored.
+// Each file is written once its batch is complete, a batch is complete when it reaches maxrows or
+// maxbytes, when interval has passed, or when the adaptor is stopped.
+// Columns are either configured, in which case any other fields are ignored, or inferred from the first batch.
+// When an inferred schema meets a document with new fields, the schema policy decides whether to start
+// a new file with the extra columns (rotate) or to reject the document (error)
+type Parquet struct {
+	dir      string
+	prefix   string
+	maxRows  int
+	maxBytes int
+	interval time.Duration
+	policy   string
+
+	nsMatch *regexp.Regexp
+
+	pipe *pipe.Pipe
+	path string
+
+	sync.Mutex
+	inferred bool // columns are inferred, rather than configured
+	fixed    bool // the first batch has been written, and the schema only changes by rotating
+	columns  []*parquetColumn
+	names    map[string]*parquetColumn
+	rows     int
+	size     int
+	seq      int
+	now      func() time.Time
+
+	listening bool
+	quit      chan chan bool
+
+	onError *errorPolicy
+}
+
+// ParquetConfig provides configuration options for the parquet sink
+type ParquetConfig struct {
+	URI       string                `json:"uri" doc:"the directory to write to, i.e. file:///data/lake"`
+	Namespace string                `json:"namespace" doc:"the set of namespaces to write"`
+	Prefix    string                `json:"prefix" doc:"the prefix of each file name, defaults to transporter"`
+	Columns   []ParquetColumnConfig `json:"columns" doc:"the columns to write, if unset the columns are inferred from the first batch"`
+	Schema    string                `json:"schema" doc:"what to do when a document adds fields to an inferred schema, rotate (start a new file, the default) or error"`
+	MaxRows   int                   `json:"maxrows" doc:"the maximum number of rows in a file, defaults to 100000"`
+	MaxBytes  int                   `json:"maxbytes" doc:"the approximate maximum size of a file in bytes, defaults to 64MB"`
+	Interval  string                `json:"interval" doc:"the maximum time to buffer documents before writing a file, format must be parsable by time.ParseDuration and defaults to 1m"`
+}
+
+// ParquetColumnConfig configures a single column of the parquet sink
+type ParquetColumnConfig struct {
+	Name string `json:"name" doc:"the field to write"`
+	Type string `json:"type" doc:"one of string, long, double or bool"`
+}
+
+// NewParquet creates a new Parquet sink adaptor
+func NewParquet(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var (
+		conf ParquetConfig
+		err  error
+	)
+	if err = extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if !strings.HasPrefix(conf.URI, "file://") {
+		return nil, fmt.Errorf("parquet uri must be a directory, in the form file:///path/to/dir")
+	}
+
+	q := &Parquet{
+		dir:      strings.TrimPrefix(conf.URI, "file://"),
+		prefix:   conf.Prefix,
+		maxRows:  conf.MaxRows,
+		maxBytes: conf.MaxBytes,
+		interval: time.Minute,
+		policy:   conf.Schema,
+		pipe:     p,
+		path:     path,
+		inferred: len(conf.Columns) == 0,
+		names:    make(map[string]*parquetColumn),
+		now:      time.Now,
+		quit:     make(chan chan bool),
+	}
+	if q.prefix == "" {
+		q.prefix = "transporter"
+	}
+	if q.maxRows <= 0 {
+		q.maxRows = 100000
+	}
+	if q.maxBytes <= 0 {
+		q.maxBytes = 64 << 20
+	}
+
+	switch q.policy {
+	case "":
+		q.policy = "rotate"
+	case "rotate", "error":
+	default:
+		return nil, fmt.Errorf("unknown schema policy (%s), must be rotate or error", conf.Schema)
+	}
+
+	if conf.Interval != "" {
+		if q.interval, err = time.ParseDuration(conf.Interval); err != nil {
+			return nil, fmt.Errorf("unable to parse interval (%s), %s", conf.Interval, err.Error())
+		}
+	}
+
+	for _, c := range conf.Columns {
+		kind, ok := parquetKinds[c.Type]
+		if !ok {
+			return nil, fmt.Errorf("column %s has an unknown type (%s), must be one of string, long, double or bool", c.Name, c.Type)
+		}
+		q.addColumn(c.Name, kind)
+	}
+
+	_, q.nsMatch, err = extra.compileNamespace()
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't split namespace (%s)", err.Error()), nil)
+	}
+
+	q.onError, err = newErrorPolicy(p, path, extra, onErrorSkip)
+	if err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	return q, nil
+}
+
+// Start the adaptor as a source (not implemented)
+func (q *Parquet) Start() error {
+	return fmt.Errorf("parquet can't function as a source")
+}
+
+// Listen starts the listener, and writes a file each interval
+func (q *Parquet) Listen() error {
+	if err := os.MkdirAll(q.dir, 0755); err != nil {
+		q.pipe.Err <- NewError(CRITICAL, q.path, fmt.Sprintf("parquet error (can't create %s, %s)", q.dir, err.Error()), nil)
+		return err
+	}
+
+	q.listening = true
+	go q.flusher()
+	return q.pipe.Listen(q.writeMessage, q.nsMatch)
+}
+
+// Stop the adaptor, writing any buffered documents
+func (q *Parquet) Stop() error {
+	q.pipe.Stop()
+
+	if q.listening {
+		c := make(chan bool)
+		q.quit <- c
+		<-c
+	} else {
+		q.flush()
+	}
+	return nil
+}
+
+// flusher writes a file each interval, until it's asked to quit
+func (q *Parquet) flusher() {
+	for {
+		select {
+		case <-time.After(q.interval):
+			q.flush()
+		case c := <-q.quit:
+			q.flush()
+			c <- true
+			return
+		}
+	}
+}
+
+func (q *Parquet) writeMessage(msg *message.Msg) (*message.Msg, error) {
+	switch {
+	case msg.Op == message.Command:
+		q.flush()
+		return msg, nil
+	case msg.Op == message.Delete:
+		return msg, nil // parquet files are append only
+	case !msg.IsMap():
+		q.pipe.Err <- NewError(ERROR, q.path, fmt.Sprintf("parquet error (document must be a json document, got %T instead)", msg.Data), msg.Data)
+		return msg, nil
+	}
+
+	if err := q.addRow(msg.Map()); err != nil {
+		return msg, q.onError.handle(fmt.Sprintf("parquet error (%s)", err.Error()), msg.Data)
+	}
+	return msg, nil
+}
+
+// addRow adds the document to the batch, extending the schema if need be
+func (q *Parquet) addRow(doc map[string]interface{}) error {
+	q.Lock()
+	defer q.Unlock()
+
+	// find any new columns first, so that the document is either written whole or not at all
+	added := make(map[string]parquet.Kind)
+	if q.inferred {
+		for k, v := range doc {
+			if _, ok := q.names[k]; !ok && v != nil {
+				added[k] = parquetKindOf(v)
+			}
+		}
+	}
+	if len(added) > 0 && q.fixed {
+		if q.policy == "error" {
+			return fmt.Errorf("document adds fields that aren't in the schema")
+		}
+		if err := q.writeFile(); err != nil {
+			return err
+		}
+	}
+
+	values := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		kind, ok := added[k]
+		if c, exists := q.names[k]; exists {
+			kind, ok = c.kind, true
+		}
+		if !ok {
+			continue // not in a configured schema
+		}
+		value, err := parquetValue(kind, v)
+		if err != nil {
+			return fmt.Errorf("can't write %s, %s", k, err.Error())
+		}
+		values[k] = value
+	}
+
+	names := make([]string, 0, len(added))
+	for k := range added {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		q.addColumn(k, added[k])
+	}
+	for _, c := range q.columns {
+		c.values = append(c.values, values[c.name])
+		if v, ok := values[c.name].(string); ok {
+			q.size += len(v)
+		} else {
+			q.size += 8
+		}
+	}
+	q.rows++
+
+	if q.rows >= q.maxRows || q.size >= q.maxBytes {
+		return q.writeFile()
+	}
+	return nil
+}
+
+// addColumn adds a column to the schema, any existing rows are null
+func (q *Parquet) addColumn(name string, kind parquet.Kind) {
+	c := &parquetColumn{name: name, kind: kind, values: make([]interface{}, q.rows)}
+	q.columns = append(q.columns, c)
+	q.names[name] = c
+}
+
+// Flush writes the current batch to a file when the checkpoint's marker arrives, so the checkpoint
+// never covers documents that are only buffered
+func (q *Parquet) Flush() error {
+	return q.flush()
+}
+
+// flush writes the current batch to a file, if there is one
+func (q *Parquet) flush() error {
+	q.Lock()
+	defer q.Unlock()
+
+	err := q.writeFile()
+	if err != nil {
+		q.pipe.Err <- NewError(ERROR, q.path, fmt.Sprintf("parquet error (%s)", err.Error()), nil)
+	}
+	return err
+}
+
+// writeFile writes the batch to a new file, and resets the batch.  The file is written under a
+// temporary name and renamed once it's complete, so readers never see partial files
+func (q *Parquet) writeFile() error {
+	if q.rows == 0 {
+		return nil
+	}
+
+	q.seq++
+	name := filepath.Join(q.dir, fmt.Sprintf("%s-%d-%04d.parquet", q.prefix, q.now().Unix(), q.seq))
+	f, err := os.Create(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err = writeParquet(f, q.columns); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+
+	for _, c := range q.columns {
+		c.values = c.values[:0]
+	}
+	q.rows, q.size, q.fixed = 0, 0, true
+	return nil
+}
+
+// parquetKindOf infers the parquet type of a value, anything other than numbers and
+// bools is written as a string
+func parquetKindOf(v interface{}) parquet.Kind {
+	switch v.(type) {
+	case bool:
+		return parquet.Boolean
+	case int, int32, int64:
+		return parquet.Int64
+	case float32, float64:
+		return parquet.Double
+	default:
+		return parquet.ByteArray
+	}
+}
+
+// parquetValue converts v to the go type used to encode the given parquet type
+func parquetValue(kind parquet.Kind, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case parquet.Boolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case parquet.Int64:
+		switch n := v.(type) {
+		case int:
+			return int64(n), nil
+		case int32:
+			return int64(n), nil
+		case int64:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) {
+				return int64(n), nil
+			}
+		}
+	case parquet.Double:
+		switch n := v.(type) {
+		case int:
+			return float64(n), nil
+		case int32:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float32:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case parquet.ByteArray:
+		switch s := v.(type) {
+		case string:
+			return s, nil
+		case bson.ObjectId:
+			return s.Hex(), nil
+		case time.Time:
+			return s.UTC().Format(time.RFC3339Nano), nil
+		case map[string]interface{}, bson.M, []interface{}:
+			ba, err := json.Marshal(s)
+			return string(ba), err
+		default:
+			return fmt.Sprintf("%v", s), nil
+		}
+	}
+	return nil, fmt.Errorf("a %T doesn't fit a %s column", v, parquetKindName(kind))
+}
+
+func parquetKindName(kind parquet.Kind) string {
+	for name, k := range parquetKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,238 @@
+//go:build !slim || parquet
+// +build !slim parquet
+
+package adaptor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"github.com/parquet-go/parquet-go"
+)
+
+type parquetFile struct {
+	rows    int64
+	columns []string // name:type
+	values  map[string][]interface{}
+}
+
+// readParquet reads back a file written by the parquet sink
+func readParquet(t *testing.T, name string) *parquetFile {
+	r, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("can't read %s, %s", name, err)
+	}
+	defer r.Close()
+	info, err := r.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := parquet.OpenFile(r, info.Size())
+	if err != nil {
+		t.Fatalf("%s isn't a parquet file, %s", name, err)
+	}
+
+	f := &parquetFile{rows: pf.NumRows(), values: make(map[string][]interface{})}
+	fields := pf.Schema().Fields()
+	for _, field := range fields {
+		f.columns = append(f.columns, fmt.Sprintf("%s:%s", field.Name(), parquetKindName(field.Type().Kind())))
+	}
+
+	rows := make([]parquet.Row, f.rows)
+	reader := parquet.NewReader(pf)
+	defer reader.Close()
+	if n, err := reader.ReadRows(rows); int64(n) != f.rows {
+		t.Fatalf("can't read the rows of %s, %v", name, err)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			var v interface{}
+			if !value.IsNull() {
+				switch value.Kind() {
+				case parquet.Boolean:
+					v = value.Boolean()
+				case parquet.Int64:
+					v = value.Int64()
+				case parquet.Double:
+					v = value.Double()
+				case parquet.ByteArray:
+					v = string(value.ByteArray())
+				}
+			}
+			f.values[fields[i].Name()] = append(f.values[fields[i].Name()], v)
+		}
+	}
+	return f
+}
+
+func newTestParquet(t *testing.T, conf map[string]interface{}) (*Parquet, string, chan error) {
+	dir, err := ioutil.TempDir("", "parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra := Config{"uri": "file://" + dir, "namespace": "db.things"}
+	for k, v := range conf {
+		extra[k] = v
+	}
+
+	p := pipe.NewPipe(nil, "path")
+	p.Err = make(chan error, 10)
+
+	q, err := NewParquet(p, "path", extra)
+	if err != nil {
+		t.Fatalf("can't create parquet sink, %s", err)
+	}
+	return q.(*Parquet), dir, p.Err
+}
+
+func parquetFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestParquetWritesDocuments(t *testing.T) {
+	data := []struct {
+		conf    map[string]interface{}
+		columns []string
+		values  map[string][]interface{}
+	}{
+		{
+			// inferred from the documents, columns are sorted by name
+			nil,
+			[]string{"_id:string", "count:long", "name:string", "ok:bool", "score:double"},
+			map[string][]interface{}{
+				"_id":   {"1", "2", "3"},
+				"count": {int64(10), nil, int64(30)},
+				"name":  {"alice", "bob", nil},
+				"ok":    {true, false, true},
+				"score": {1.5, 2.0, 3.25},
+			},
+		},
+		{
+			// configured, any other field is ignored
+			map[string]interface{}{"columns": []interface{}{
+				map[string]interface{}{"name": "name", "type": "string"},
+				map[string]interface{}{"name": "count", "type": "double"},
+			}},
+			[]string{"name:string", "count:double"},
+			map[string][]interface{}{
+				"name":  {"alice", "bob", nil},
+				"count": {10.0, nil, 30.0},
+			},
+		},
+	}
+
+	docs := []map[string]interface{}{
+		{"_id": "1", "name": "alice", "count": 10, "ok": true, "score": 1.5},
+		{"_id": "2", "name": "bob", "ok": false, "score": 2.0},
+		{"_id": "3", "count": int64(30), "ok": true, "score": 3.25},
+	}
+
+	for _, v := range data {
+		q, dir, errs := newTestParquet(t, v.conf)
+		defer os.RemoveAll(dir)
+
+		for _, doc := range docs {
+			q.writeMessage(message.NewMsg(message.Insert, doc, "db.things"))
+		}
+		q.writeMessage(message.NewMsg(message.Delete, map[string]interface{}{"_id": "1"}, "db.things"))
+		if files := parquetFiles(t, dir); len(files) != 0 {
+			t.Errorf("expected nothing to be written before the batch is complete, got %v", files)
+		}
+		q.Stop()
+
+		select {
+		case err := <-errs:
+			t.Errorf("unexpected error %s", err)
+		default:
+		}
+
+		files := parquetFiles(t, dir)
+		if len(files) != 1 {
+			t.Errorf("expected 1 file, got %v", files)
+			continue
+		}
+		f := readParquet(t, files[0])
+		if f.rows != 3 {
+			t.Errorf("expected 3 rows, got %d", f.rows)
+		}
+		if !reflect.DeepEqual(f.columns, v.columns) {
+			t.Errorf("expected columns %v, got %v", v.columns, f.columns)
+		}
+		if !reflect.DeepEqual(f.values, v.values) {
+			t.Errorf("expected values %v, got %v", v.values, f.values)
+		}
+	}
+}
+
+func TestParquetMaxRows(t *testing.T) {
+	q, dir, _ := newTestParquet(t, map[string]interface{}{"maxrows": 2})
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 5; i++ {
+		q.writeMessage(message.NewMsg(message.Insert, map[string]interface{}{"i": i}, "db.things"))
+	}
+	if files := parquetFiles(t, dir); len(files) != 2 {
+		t.Errorf("expected 2 full files, got %v", files)
+	}
+	q.Stop()
+
+	var rows []int64
+	for _, name := range parquetFiles(t, dir) {
+		rows = append(rows, readParquet(t, name).rows)
+	}
+	if !reflect.DeepEqual(rows, []int64{2, 2, 1}) {
+		t.Errorf("expected files of 2, 2 and 1 rows, got %v", rows)
+	}
+}
+
+func TestParquetSchemaChange(t *testing.T) {
+	data := []struct {
+		policy  string
+		columns [][]string
+		err     bool
+	}{
+		{"rotate", [][]string{{"a:long"}, {"a:long", "b:string"}}, false},
+		{"error", [][]string{{"a:long"}}, true},
+	}
+
+	for _, v := range data {
+		q, dir, errs := newTestParquet(t, map[string]interface{}{"schema": v.policy})
+		defer os.RemoveAll(dir)
+
+		q.writeMessage(message.NewMsg(message.Insert, map[string]interface{}{"a": 1}, "db.things"))
+		q.writeMessage(message.NewMsg(message.Command, map[string]interface{}{"flush": true}, "db.things"))
+		q.writeMessage(message.NewMsg(message.Insert, map[string]interface{}{"a": 2, "b": "new"}, "db.things"))
+		q.Stop()
+
+		var columns [][]string
+		for _, name := range parquetFiles(t, dir) {
+			columns = append(columns, readParquet(t, name).columns)
+		}
+		if !reflect.DeepEqual(columns, v.columns) {
+			t.Errorf("%s: expected columns %v, got %v", v.policy, v.columns, columns)
+		}
+
+		select {
+		case err := <-errs:
+			if !v.err {
+				t.Errorf("%s: unexpected error %s", v.policy, err)
+			}
+		default:
+			if v.err {
+				t.Errorf("%s: expected an error, got none", v.policy)
+			}
+		}
+	}
+}
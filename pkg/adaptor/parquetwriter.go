@@ -0,0 +1,86 @@
+//go:build !slim || parquet
+// +build !slim parquet
+
+package adaptor
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetColumn is a single column of a parquet file, values holds one entry per row, nil for nulls
+type parquetColumn struct {
+	name   string
+	kind   parquet.Kind
+	values []interface{}
+}
+
+// parquetGroup is the schema's root, it keeps the columns in the order they were added
+// rather than sorting them by name as a parquet.Group does
+type parquetGroup struct {
+	parquet.Group
+	columns []*parquetColumn
+}
+
+func (g parquetGroup) Fields() []parquet.Field {
+	byName := make(map[string]parquet.Field, len(g.Group))
+	for _, f := range g.Group.Fields() {
+		byName[f.Name()] = f
+	}
+	fields := make([]parquet.Field, len(g.columns))
+	for i, c := range g.columns {
+		fields[i] = byName[c.name]
+	}
+	return fields
+}
+
+// writeParquet writes the columns as a parquet file with a single row group, every column is optional
+// and must have the same number of values
+func writeParquet(w io.Writer, columns []*parquetColumn) error {
+	var numRows int
+	if len(columns) > 0 {
+		numRows = len(columns[0].values)
+	}
+
+	group := parquetGroup{Group: make(parquet.Group, len(columns)), columns: columns}
+	for _, c := range columns {
+		if len(c.values) != numRows {
+			return fmt.Errorf("column %s has %d values, expected %d", c.name, len(c.values), numRows)
+		}
+		group.Group[c.name] = parquet.Optional(parquetNode(c.kind))
+	}
+
+	rows := make([]parquet.Row, numRows)
+	for i := range rows {
+		rows[i] = make(parquet.Row, len(columns))
+		for j, c := range columns {
+			if c.values[i] == nil {
+				rows[i][j] = parquet.NullValue().Level(0, 0, j)
+			} else {
+				rows[i][j] = parquet.ValueOf(c.values[i]).Level(0, 1, j)
+			}
+		}
+	}
+
+	pw := parquet.NewWriter(w, parquet.NewSchema("transporter", group))
+	if _, err := pw.WriteRows(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// parquetNode returns the schema node of a column of the given kind, strings are annotated as UTF8
+func parquetNode(kind parquet.Kind) parquet.Node {
+	switch kind {
+	case parquet.Boolean:
+		return parquet.Leaf(parquet.BooleanType)
+	case parquet.Int64:
+		return parquet.Int(64)
+	case parquet.Double:
+		return parquet.Leaf(parquet.DoubleType)
+	default:
+		return parquet.String()
+	}
+}
@@ -0,0 +1,114 @@
+package adaptor
+
+import (
+	"fmt"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewPivotLong creates a transformer that pivots a wide row of metrics into one message per metric, as
+// time-series sinks prefer, i.e. {"ts": 1, "host": "a", "cpu": 0.5, "mem": 12} becomes {"ts": 1, "host": "a",
+// "metric": "cpu", "value": 0.5} and {"ts": 1, "host": "a", "metric": "mem", "value": 12}.  Each message keeps
+// the timestamp and the dimension fields, and its _id is derived from the row's _id and the metric.  Metrics
+// that are missing or null are left out, so a row that has none of them produces no messages
+func NewPivotLong(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf PivotLongConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Timestamp == "" || len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "pivot-long config must contain a timestamp and at least one field", nil)
+	}
+
+	pl := &pivotLong{
+		timestamp:   conf.Timestamp,
+		fields:      conf.Fields,
+		dimensions:  conf.Dimensions,
+		metricField: conf.MetricField,
+		valueField:  conf.ValueField,
+		missing:     conf.Missing,
+	}
+	if pl.metricField == "" {
+		pl.metricField = "metric"
+	}
+	if pl.valueField == "" {
+		pl.valueField = "value"
+	}
+	switch pl.missing {
+	case "":
+		pl.missing = "error"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be pass, drop or error", pl.missing), nil)
+	}
+
+	return newDocSplitter("pivot-long", p, path, extra, pl.apply)
+}
+
+// PivotLongConfig provides configuration options for the pivot-long transformer
+type PivotLongConfig struct {
+	Namespace   string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Timestamp   string   `json:"timestamp" doc:"the dotted path of the timestamp shared by the row's metrics, it's copied into each message"`
+	Fields      []string `json:"fields" doc:"the dotted paths of the metrics, a message is sent for each, named by its path"`
+	Dimensions  []string `json:"dimensions" doc:"the dotted paths of the dimension or tag fields to copy into each message, i.e. host"`
+	MetricField string   `json:"metricfield" doc:"the field to put each metric's name in, defaults to metric"`
+	ValueField  string   `json:"valuefield" doc:"the field to put each metric's value in, defaults to value"`
+	Missing     string   `json:"missing" doc:"what to do when the timestamp is missing or null, error (the default), pass (send the row on untouched) or drop"`
+}
+
+type pivotLong struct {
+	timestamp   string
+	fields      []string
+	dimensions  []string
+	metricField string
+	valueField  string
+	missing     string
+}
+
+// apply returns a message per metric, in the order of the fields
+func (pl *pivotLong) apply(msg *message.Msg, doc map[string]interface{}) ([]*message.Msg, error) {
+	ts, ok := getField(doc, pl.timestamp)
+	if !ok || ts == nil {
+		switch pl.missing {
+		case "drop":
+			return nil, nil
+		case "error":
+			return nil, fmt.Errorf("%s is missing", pl.timestamp)
+		}
+		return []*message.Msg{msg}, nil
+	}
+
+	id, hasID := doc["_id"]
+	var msgs []*message.Msg
+	for _, field := range pl.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+
+		out := make(map[string]interface{})
+		for _, dim := range pl.dimensions {
+			if d, ok := getField(doc, dim); ok {
+				if err := setField(out, dim, d); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if hasID {
+			out["_id"] = fmt.Sprintf("%s-%s", templateString(id), field)
+		}
+		for path, value := range map[string]interface{}{pl.timestamp: ts, pl.metricField: field, pl.valueField: v} {
+			if err := setField(out, path, value); err != nil {
+				return nil, err
+			}
+		}
+
+		m := message.NewMsg(msg.Op, out, msg.Namespace)
+		m.Timestamp = msg.Timestamp
+		m.Version = msg.Version
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
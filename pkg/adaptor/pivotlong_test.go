@@ -0,0 +1,102 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestPivotLong(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  []map[string]interface{}
+	}{
+		{
+			// a wide row becomes a message per metric, keeping the timestamp and dimensions
+			Config{"timestamp": "ts", "fields": []interface{}{"cpu", "mem", "disk"}, "dimensions": []interface{}{"host", "tags.region"}},
+			map[string]interface{}{"_id": "1", "ts": 10, "host": "a", "tags": map[string]interface{}{"region": "eu", "rack": 4}, "cpu": 0.5, "mem": 12, "disk": 80, "other": true},
+			[]map[string]interface{}{
+				{"_id": "1-cpu", "ts": 10, "host": "a", "tags": map[string]interface{}{"region": "eu"}, "metric": "cpu", "value": 0.5},
+				{"_id": "1-mem", "ts": 10, "host": "a", "tags": map[string]interface{}{"region": "eu"}, "metric": "mem", "value": 12},
+				{"_id": "1-disk", "ts": 10, "host": "a", "tags": map[string]interface{}{"region": "eu"}, "metric": "disk", "value": 80},
+			},
+		},
+		{
+			// nested metrics and renamed output fields
+			Config{"timestamp": "at.time", "fields": []interface{}{"stats.cpu"}, "metricfield": "name", "valuefield": "reading.v"},
+			map[string]interface{}{"at": map[string]interface{}{"time": "now"}, "stats": map[string]interface{}{"cpu": 1}},
+			[]map[string]interface{}{
+				{"at": map[string]interface{}{"time": "now"}, "name": "stats.cpu", "reading": map[string]interface{}{"v": 1}},
+			},
+		},
+		{
+			// missing and null metrics are left out
+			Config{"timestamp": "ts", "fields": []interface{}{"cpu", "mem", "disk"}},
+			map[string]interface{}{"_id": "1", "ts": 10, "cpu": nil, "mem": 12},
+			[]map[string]interface{}{{"_id": "1-mem", "ts": 10, "metric": "mem", "value": 12}},
+		},
+		{
+			Config{"timestamp": "ts", "fields": []interface{}{"cpu"}},
+			map[string]interface{}{"_id": "1", "ts": 10},
+			nil,
+		},
+		{
+			// rows without a timestamp pass through, or are dropped
+			Config{"timestamp": "ts", "fields": []interface{}{"cpu"}, "missing": "pass"},
+			map[string]interface{}{"_id": "1", "cpu": 1},
+			[]map[string]interface{}{{"_id": "1", "cpu": 1}},
+		},
+		{
+			Config{"timestamp": "ts", "fields": []interface{}{"cpu"}, "missing": "drop"},
+			map[string]interface{}{"_id": "1", "ts": nil, "cpu": 1},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "pivot-long", v.conf)
+		msgs, err := explodeOne(tr, v.in)
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		var out []map[string]interface{}
+		for _, m := range msgs {
+			if m.Op != message.Insert || m.Namespace != "database.collection" {
+				t.Errorf("%+v: expected an insert to database.collection, got %s to %s", v.in, m.Op, m.Namespace)
+			}
+			out = append(out, m.Map())
+		}
+		if !reflect.DeepEqual(out, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out)
+		}
+	}
+}
+
+func TestPivotLongMissingTimestamp(t *testing.T) {
+	tr, errs := newTestDocTransformer(t, "pivot-long", Config{"timestamp": "ts", "fields": []interface{}{"cpu"}})
+	msgs, err := explodeOne(tr, map[string]interface{}{"_id": "1", "cpu": 1})
+	if err != nil || len(msgs) != 0 {
+		t.Fatalf("expected the message to be dropped, got %+v (%v)", msgs, err)
+	}
+	if err := <-errs; err.(Error).Lvl != ERROR {
+		t.Errorf("expected an ERROR, got %v", err)
+	}
+}
+
+func TestPivotLongBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "database./.*/", "fields": []interface{}{"cpu"}},
+		{"namespace": "database./.*/", "timestamp": "ts"},
+		{"namespace": "database./.*/", "timestamp": "ts", "fields": []interface{}{"cpu"}, "missing": "skip"},
+	}
+
+	for _, v := range data {
+		if _, err := NewPivotLong(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package adaptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// PostFlushConfig configures a command a sink runs after each successful flush, to trigger whatever
+// depends on the documents being written, i.e. invalidating a cache or calling a webhook
+type PostFlushConfig struct {
+	Command   string   `json:"command" doc:"the command to run, it's given the flush's stats as a json document on stdin, and in the TRANSPORTER_PATH, TRANSPORTER_INDEX and TRANSPORTER_DOCUMENTS environment variables"`
+	Args      []string `json:"args" doc:"the command's arguments"`
+	Timeout   string   `json:"timeout" doc:"how long the command can run before it's killed, format must be parsable by time.ParseDuration and defaults to 10s"`
+	OnFailure string   `json:"onfailure" doc:"what to do when the command fails or times out, warn (send a warning and carry on, the default) or stop (stop the sink)"`
+}
+
+// postFlushStats is what a post flush command is told about the flush, on stdin
+type postFlushStats struct {
+	Path      string `json:"path"`
+	Index     string `json:"index"`
+	Documents int    `json:"documents"`
+	Ts        int64  `json:"ts"`
+}
+
+// postFlushHook runs the post flush command.  The flush waits for the command, so it's killed once the
+// timeout passes, which bounds how long each flush can be held up
+type postFlushHook struct {
+	command   string
+	args      []string
+	timeout   time.Duration
+	onFailure string
+}
+
+// newPostFlushHook creates a postFlushHook from the given config.
+// a nil config returns a nil hook, which runs nothing
+func newPostFlushHook(conf *PostFlushConfig) (*postFlushHook, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	if conf.Command == "" {
+		return nil, fmt.Errorf("postflush requires a command")
+	}
+	if _, err := exec.LookPath(conf.Command); err != nil {
+		return nil, fmt.Errorf("can't find the postflush command (%s)", err.Error())
+	}
+
+	h := &postFlushHook{command: conf.Command, args: conf.Args, timeout: 10 * time.Second, onFailure: conf.OnFailure}
+	if conf.Timeout != "" {
+		timeout, err := time.ParseDuration(conf.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse the postflush timeout (%s)", err.Error())
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("the postflush timeout must be positive, got %s", timeout)
+		}
+		h.timeout = timeout
+	}
+	switch h.onFailure {
+	case "":
+		h.onFailure = "warn"
+	case "warn", "stop":
+	default:
+		return nil, fmt.Errorf("unknown postflush onfailure (%s), must be warn or stop", h.onFailure)
+	}
+	return h, nil
+}
+
+// run runs the command for a flush of documents to the index, it's safe to call on a nil hook
+func (h *postFlushHook) run(path, index string, documents int) error {
+	if h == nil {
+		return nil
+	}
+
+	stats := postFlushStats{Path: path, Index: index, Documents: documents, Ts: time.Now().Unix()}
+	ba, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, h.command, h.args...)
+	cmd.Env = append(os.Environ(),
+		"TRANSPORTER_PATH="+path,
+		"TRANSPORTER_INDEX="+index,
+		"TRANSPORTER_DOCUMENTS="+strconv.Itoa(documents),
+	)
+	cmd.Stdin = bytes.NewReader(append(ba, '\n'))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = time.Second // don't wait on output held open by the command's children once it's killed
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("postflush command timed out after %s", h.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("postflush command failed (%s)", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package adaptor
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPostFlushHelperProcess isn't a real test, it's the post flush command run by the postflush tests.
+// it appends the stats it's given on stdin and in its environment to the file named by POSTFLUSH_OUT,
+// or fails or hangs if asked to
+func TestPostFlushHelperProcess(t *testing.T) {
+	args := flag.Args()
+	if len(args) < 2 || args[0] != "postflush-helper" {
+		return
+	}
+
+	switch args[1] {
+	case "fail":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+	var stats postFlushStats
+	if err := json.NewDecoder(os.Stdin).Decode(&stats); err != nil {
+		os.Exit(2)
+	}
+	f, err := os.OpenFile(os.Getenv("POSTFLUSH_OUT"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		os.Exit(3)
+	}
+	ba, _ := json.Marshal(map[string]interface{}{
+		"stdin": stats,
+		"env":   []string{os.Getenv("TRANSPORTER_PATH"), os.Getenv("TRANSPORTER_INDEX"), os.Getenv("TRANSPORTER_DOCUMENTS")},
+	})
+	f.Write(append(ba, '\n'))
+	f.Close()
+	os.Exit(0)
+}
+
+// postFlushRun is a run of the helper process, as it recorded it
+type postFlushRun struct {
+	Stdin postFlushStats `json:"stdin"`
+	Env   []string       `json:"env"`
+}
+
+// newTestPostFlush returns a hook running the helper process in the given mode, and the file it records its runs to
+func newTestPostFlush(t *testing.T, mode string, conf PostFlushConfig) (*postFlushHook, string, func()) {
+	dir, err := ioutil.TempDir("", "postflush")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "runs")
+	os.Setenv("POSTFLUSH_OUT", out)
+
+	conf.Command = os.Args[0]
+	conf.Args = []string{"-test.run=TestPostFlushHelperProcess", "--", "postflush-helper", mode}
+	h, err := newPostFlushHook(&conf)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	return h, out, func() {
+		os.Unsetenv("POSTFLUSH_OUT")
+		os.RemoveAll(dir)
+	}
+}
+
+// postFlushRuns reads the runs the helper process recorded
+func postFlushRuns(t *testing.T, out string) []postFlushRun {
+	ba, err := ioutil.ReadFile(out)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []postFlushRun
+	for _, line := range strings.Split(strings.TrimSpace(string(ba)), "\n") {
+		var run postFlushRun
+		if err := json.Unmarshal([]byte(line), &run); err != nil {
+			t.Fatalf("can't parse %s, %s", line, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func TestPostFlushHook(t *testing.T) {
+	h, out, cleanup := newTestPostFlush(t, "ok", PostFlushConfig{})
+	defer cleanup()
+	if err := h.run("source/sink", "products", 12); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	runs := postFlushRuns(t, out)
+	if len(runs) != 1 {
+		t.Fatalf("expected a single run, got %+v", runs)
+	}
+	if s := runs[0].Stdin; s.Path != "source/sink" || s.Index != "products" || s.Documents != 12 || s.Ts == 0 {
+		t.Errorf("expected the stats on stdin, got %+v", s)
+	}
+	if env := strings.Join(runs[0].Env, ","); env != "source/sink,products,12" {
+		t.Errorf("expected the stats in the environment, got %s", env)
+	}
+
+	// a nil hook runs nothing
+	var none *postFlushHook
+	if err := none.run("source/sink", "products", 12); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+
+	for _, mode := range []string{"fail", "hang"} {
+		h, _, cleanup := newTestPostFlush(t, mode, PostFlushConfig{Timeout: "200ms"})
+		began := time.Now()
+		if err := h.run("source/sink", "products", 1); err == nil {
+			t.Errorf("%s: expected an error", mode)
+		}
+		if d := time.Since(began); d > 5*time.Second {
+			t.Errorf("%s: expected the command to be killed after the timeout, it took %s", mode, d)
+		}
+		cleanup()
+	}
+}
+
+func TestPostFlushBadConfig(t *testing.T) {
+	data := []*PostFlushConfig{
+		{},
+		{Command: "transporter-no-such-command"},
+		{Command: os.Args[0], Timeout: "soon"},
+		{Command: os.Args[0], Timeout: "0s"},
+		{Command: os.Args[0], OnFailure: "retry"},
+	}
+	for _, conf := range data {
+		if _, err := newPostFlushHook(conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package adaptor
+
+import (
+	"fmt"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewPrune creates a transformer that removes null fields from documents before they're written, so sparse
+// documents don't bloat the sink or create needless mappings.  Empty strings, arrays and documents can be
+// removed too, and documents emptied by the pruning are then removed in turn.  Documents inside arrays are
+// pruned, but the arrays keep all of their elements, and the _id is never removed
+func NewPrune(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf PruneConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	pr := &prune{keep: map[string]bool{"_id": true}}
+	for _, empty := range conf.Empty {
+		switch empty {
+		case "strings":
+			pr.strings = true
+		case "arrays":
+			pr.arrays = true
+		case "objects":
+			pr.objects = true
+		default:
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown empty value (%s), must be strings, arrays or objects", empty), nil)
+		}
+	}
+	for _, field := range conf.Keep {
+		pr.keep[field] = true
+	}
+
+	return newDocTransformer("prune", p, path, extra, pr.apply)
+}
+
+// PruneConfig provides configuration options for the prune transformer
+type PruneConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Empty     []string `json:"empty" doc:"the empty values to remove along with nulls, strings, arrays or objects"`
+	Keep      []string `json:"keep" doc:"the dotted paths of fields that are never removed, even when they're null or empty"`
+}
+
+type prune struct {
+	strings, arrays, objects bool
+	keep                     map[string]bool
+}
+
+func (pr *prune) apply(msg *message.Msg, doc map[string]interface{}) error {
+	pr.prune(doc, "")
+	return nil
+}
+
+// prune removes the null and empty fields from the document, whose path is prefix
+func (pr *prune) prune(doc map[string]interface{}, prefix string) {
+	for k, v := range doc {
+		path := prefix + k
+		if m, ok := asMap(v); ok {
+			pr.prune(m, path+".")
+		} else if a, ok := asSlice(v); ok {
+			for _, e := range a {
+				if m, ok := asMap(e); ok {
+					pr.prune(m, path+".")
+				}
+			}
+		}
+		if !pr.keep[path] && pr.isEmpty(v) {
+			delete(doc, k)
+		}
+	}
+}
+
+// isEmpty returns true if the value is null, or is one of the empty values being removed
+func (pr *prune) isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if s, ok := v.(string); ok {
+		return pr.strings && s == ""
+	}
+	if m, ok := asMap(v); ok {
+		return pr.objects && len(m) == 0
+	}
+	if _, ok := v.([]byte); ok {
+		return false
+	}
+	if a, ok := asSlice(v); ok {
+		return pr.arrays && len(a) == 0
+	}
+	return false
+}
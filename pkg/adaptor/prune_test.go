@@ -0,0 +1,118 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestPrune(t *testing.T) {
+	sparse := func() map[string]interface{} {
+		return map[string]interface{}{
+			"_id":     nil,
+			"name":    "a",
+			"email":   nil,
+			"nick":    "",
+			"tags":    []interface{}{},
+			"meta":    map[string]interface{}{},
+			"address": bson.M{"city": "x", "zip": nil, "extra": bson.M{"note": nil}},
+			"orders":  []interface{}{map[string]interface{}{"id": 1, "coupon": nil}, nil, ""},
+			"count":   0,
+			"ok":      false,
+		}
+	}
+	data := []struct {
+		conf Config
+		out  map[string]interface{}
+	}{
+		{
+			// nulls are removed everywhere but from arrays, and the _id is kept
+			Config{},
+			map[string]interface{}{
+				"_id":     nil,
+				"name":    "a",
+				"nick":    "",
+				"tags":    []interface{}{},
+				"meta":    map[string]interface{}{},
+				"address": bson.M{"city": "x", "extra": bson.M{}},
+				"orders":  []interface{}{map[string]interface{}{"id": 1}, nil, ""},
+				"count":   0,
+				"ok":      false,
+			},
+		},
+		{
+			Config{"empty": []interface{}{"strings"}},
+			map[string]interface{}{
+				"_id":     nil,
+				"name":    "a",
+				"tags":    []interface{}{},
+				"meta":    map[string]interface{}{},
+				"address": bson.M{"city": "x", "extra": bson.M{}},
+				"orders":  []interface{}{map[string]interface{}{"id": 1}, nil, ""},
+				"count":   0,
+				"ok":      false,
+			},
+		},
+		{
+			Config{"empty": []interface{}{"arrays"}},
+			map[string]interface{}{
+				"_id":     nil,
+				"name":    "a",
+				"nick":    "",
+				"meta":    map[string]interface{}{},
+				"address": bson.M{"city": "x", "extra": bson.M{}},
+				"orders":  []interface{}{map[string]interface{}{"id": 1}, nil, ""},
+				"count":   0,
+				"ok":      false,
+			},
+		},
+		{
+			// documents emptied by the pruning are removed too
+			Config{"empty": []interface{}{"objects"}},
+			map[string]interface{}{
+				"_id":     nil,
+				"name":    "a",
+				"nick":    "",
+				"tags":    []interface{}{},
+				"address": bson.M{"city": "x"},
+				"orders":  []interface{}{map[string]interface{}{"id": 1}, nil, ""},
+				"count":   0,
+				"ok":      false,
+			},
+		},
+		{
+			// the fields to keep are left alone
+			Config{"empty": []interface{}{"strings", "arrays", "objects"}, "keep": []interface{}{"email", "address.extra.note", "tags"}},
+			map[string]interface{}{
+				"_id":     nil,
+				"name":    "a",
+				"email":   nil,
+				"tags":    []interface{}{},
+				"address": bson.M{"city": "x", "extra": bson.M{"note": nil}},
+				"orders":  []interface{}{map[string]interface{}{"id": 1}, nil, ""},
+				"count":   0,
+				"ok":      false,
+			},
+		},
+	}
+
+	for _, v := range data {
+		tr, _ := newTestDocTransformer(t, "prune", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, sparse(), "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.conf, err)
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestPruneConfigErrors(t *testing.T) {
+	if _, err := NewPrune(nil, "path", Config{"namespace": "database.collection", "empty": []interface{}{"zeros"}}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,347 @@
+//go:build !slim || pubsub
+// +build !slim pubsub
+
+package adaptor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+func init() {
+	Register("pubsub", "a google pub/sub sink adaptor", NewPubSub, PubSubConfig{})
+}
+
+const (
+	// the attributes carrying the message's op, namespace and id, so subscribers can route on them with a
+	// filter and a delete can be told apart from an insert
+	pubsubOpAttribute        = "op"
+	pubsubNamespaceAttribute = "namespace"
+	pubsubIDAttribute        = "id"
+
+	// the limits of a single publish request are 1000 messages and 10MB
+	pubsubMaxBatchSize  = 1000
+	pubsubMaxBatchBytes = 10 * 1000 * 1000
+
+	// how long to wait before retrying a failed publish, doubled for each attempt up to pubsubMaxRetryBackoff
+	pubsubRetryBackoff    = 100 * time.Millisecond
+	pubsubMaxRetryBackoff = time.Minute
+)
+
+// PubSub is a sink adaptor that publishes each message as json to a Google Pub/Sub topic.  The message's
+// op, namespace and id are carried in attributes, and when orderingkey is set the value of that field is the
+// message's ordering key, so that a subscription with ordering enabled receives the changes to a document
+// in order.  Messages are published in batches, sent when full, when the delay has passed, or when the
+// pipeline stops, and a batch that fails transiently is retried with a backoff
+type PubSub struct {
+	// pull these in from the node
+	topic       string
+	orderingKey string
+	batchSize   int
+	batchBytes  int
+	delay       time.Duration
+	retries     int
+	backoff     time.Duration
+
+	nsMatch *regexp.Regexp
+
+	//
+	pipe *pipe.Pipe
+	path string
+
+	publisher  pubsubPublisher
+	serializer Serializer
+	onError    *errorPolicy
+
+	sync.Mutex // guards the pending batch, which is also flushed by the delay timer
+	batch      []pubsubMessage
+	docs       []interface{} // the documents of the pending batch, for the error policy
+	size       int
+	done       chan struct{}
+	running    bool
+}
+
+// PubSubConfig provides configuration options for a pubsub sink
+type PubSubConfig struct {
+	Project     string `json:"project" doc:"the google cloud project the topic belongs to"`
+	Topic       string `json:"topic" doc:"the topic to publish to"`
+	Namespace   string `json:"namespace" doc:"the set of namespaces to publish"`
+	OrderingKey string `json:"orderingkey" doc:"the dotted path of the field whose value is each message's ordering key, messages without the field aren't ordered"`
+	Credentials string `json:"credentials" doc:"the service account key file to authenticate with, defaults to GOOGLE_APPLICATION_CREDENTIALS"`
+	Token       string `json:"token" doc:"an oauth access token to authenticate with instead of a service account"`
+	Endpoint    string `json:"endpoint" doc:"the pubsub api endpoint, defaults to https://pubsub.googleapis.com, or the emulator at PUBSUB_EMULATOR_HOST without authentication"`
+	Format      string `json:"format" doc:"the format messages are published in, json, ndjson, msgpack or bson. defaults to json"`
+	BatchSize   int    `json:"batchsize" doc:"the most messages published in a request, defaults to and can't be more than 1000"`
+	BatchBytes  int    `json:"batchbytes" doc:"the most bytes published in a request, defaults to 1000000 and can't be more than 10000000"`
+	Delay       string `json:"delay" doc:"the longest a message waits for its batch to fill, format must be parsable by time.ParseDuration and defaults to 1s"`
+	Retries     *int   `json:"retries" doc:"the times a batch that fails transiently is retried before the error policy applies, defaults to 5"`
+	Timeout     string `json:"timeout" doc:"timeout for each publish request, format must be parsable by time.ParseDuration and defaults to 30s"`
+}
+
+// NewPubSub creates a new PubSub adaptor
+func NewPubSub(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var (
+		conf PubSubConfig
+		err  error
+	)
+	if err = extra.Construct(&conf); err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Project == "" || conf.Topic == "" {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, "both project and topic required, but missing", nil)
+	}
+
+	ps := &PubSub{
+		topic:       fmt.Sprintf("projects/%s/topics/%s", conf.Project, conf.Topic),
+		orderingKey: conf.OrderingKey,
+		batchSize:   conf.BatchSize,
+		batchBytes:  conf.BatchBytes,
+		delay:       time.Second,
+		retries:     5,
+		backoff:     pubsubRetryBackoff,
+		pipe:        p,
+		path:        path,
+	}
+	if ps.batchSize == 0 {
+		ps.batchSize = pubsubMaxBatchSize
+	}
+	if ps.batchBytes == 0 {
+		ps.batchBytes = 1000 * 1000
+	}
+	if ps.batchSize < 0 || ps.batchSize > pubsubMaxBatchSize || ps.batchBytes < 0 || ps.batchBytes > pubsubMaxBatchBytes {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (batchsize must be 1 to %d and batchbytes 1 to %d)", pubsubMaxBatchSize, pubsubMaxBatchBytes), nil)
+	}
+	if conf.Retries != nil {
+		if ps.retries = *conf.Retries; ps.retries < 0 {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (retries can't be negative, got %d)", ps.retries), nil)
+		}
+	}
+	if conf.Delay != "" {
+		if ps.delay, err = time.ParseDuration(conf.Delay); err != nil || ps.delay <= 0 {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unable to parse delay %s)", conf.Delay), nil)
+		}
+	}
+	timeout := 30 * time.Second
+	if conf.Timeout != "" {
+		if timeout, err = time.ParseDuration(conf.Timeout); err != nil {
+			return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (unable to parse timeout %s)", conf.Timeout), nil)
+		}
+	}
+
+	_, ps.nsMatch, err = extra.compileNamespace()
+	if err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("can't split namespace into database/collection (%s)", err.Error()), nil)
+	}
+
+	ps.serializer, err = newSerializer(conf.Format)
+	if err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	ps.onError, err = newErrorPolicy(p, path, extra, onErrorSkip)
+	if err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	client, err := newPubSubClient(ps.topic, conf, &http.Client{Timeout: timeout})
+	if err != nil {
+		return nil, NewCategorizedError(CONFIG, CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+	ps.publisher = client
+
+	return ps, nil
+}
+
+// newPubSubClient returns the client for the configured endpoint and credentials.  The emulator doesn't
+// authenticate, anything else is sent the token, or tokens for the service account
+func newPubSubClient(topic string, conf PubSubConfig, client *http.Client) (*pubsubClient, error) {
+	c := &pubsubClient{endpoint: strings.TrimSuffix(conf.Endpoint, "/"), topic: topic, client: client}
+	if c.endpoint == "" {
+		if host := os.Getenv("PUBSUB_EMULATOR_HOST"); host != "" {
+			c.endpoint = "http://" + host
+			return c, nil
+		}
+		c.endpoint = pubsubEndpoint
+	}
+
+	tokens, err := newGoogleTokenSource(conf, client)
+	if err != nil {
+		return nil, err
+	}
+	c.client = &http.Client{Timeout: client.Timeout, Transport: &oauth2.Transport{Base: client.Transport, Source: tokens}}
+	return c, nil
+}
+
+// newGoogleTokenSource returns the configured token as it is, i.e. from gcloud auth print-access-token,
+// or tokens for the service account, which are reused until shortly before they expire
+func newGoogleTokenSource(conf PubSubConfig, client *http.Client) (oauth2.TokenSource, error) {
+	if conf.Token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: conf.Token}), nil
+	}
+	file := conf.Credentials
+	if file == "" {
+		file = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if file == "" {
+		return nil, fmt.Errorf("no credentials, set credentials, token, or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	ba, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't read the credentials (%s)", err.Error())
+	}
+	// tokens are fetched with the same client, and so the same timeout, as publishes
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	creds, err := google.CredentialsFromJSONWithType(ctx, ba, google.ServiceAccount, pubsubScope)
+	if err != nil {
+		return nil, fmt.Errorf("can't read the credentials %s (%s)", file, err.Error())
+	}
+	return creds.TokenSource, nil
+}
+
+// Start the adaptor as a source (not implemented)
+func (ps *PubSub) Start() error {
+	return fmt.Errorf("pubsub can't function as a source")
+}
+
+// Listen starts the listener, and the timer that publishes batches that haven't filled within the delay
+func (ps *PubSub) Listen() error {
+	defer ps.Stop()
+
+	ps.Lock()
+	ps.running = true
+	ps.done = make(chan struct{})
+	ps.Unlock()
+	go ps.flushEvery(ps.delay, ps.done)
+
+	return ps.pipe.Listen(ps.publish, ps.nsMatch)
+}
+
+// Stop the adaptor, publishing the pending batch
+func (ps *PubSub) Stop() error {
+	ps.Lock()
+	defer ps.Unlock()
+	if ps.running {
+		ps.running = false
+		close(ps.done)
+		ps.pipe.Stop()
+		ps.flush()
+	}
+	return nil
+}
+
+// Flush publishes the pending batch when the checkpoint's marker arrives
+func (ps *PubSub) Flush() error {
+	ps.Lock()
+	defer ps.Unlock()
+	ps.flush()
+	if ps.pipe.Stopped {
+		return fmt.Errorf("the sink has stopped")
+	}
+	return nil
+}
+
+func (ps *PubSub) flushEvery(delay time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(delay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ps.Lock()
+			ps.flush()
+			ps.Unlock()
+		}
+	}
+}
+
+// publish adds the message to the pending batch, which is published first if the message won't fit
+func (ps *PubSub) publish(msg *message.Msg) (*message.Msg, error) {
+	if msg.Op == message.Command {
+		return msg, nil
+	}
+
+	if !msg.IsMap() {
+		ps.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, ps.path, fmt.Sprintf("pubsub error (document must be a json document, got %T instead)", msg.Data), msg.Data)
+		return msg, nil
+	}
+
+	ba, err := ps.serializer.Serialize(msg)
+	if err != nil {
+		ps.pipe.Err <- NewCategorizedError(PERMANENT, ERROR, ps.path, fmt.Sprintf("pubsub error (can't marshal document, %s)", err.Error()), msg.Data)
+		return msg, nil
+	}
+
+	m := pubsubMessage{Data: ba, Attributes: map[string]string{pubsubOpAttribute: msg.Op.String(), pubsubNamespaceAttribute: msg.Namespace}}
+	if id, err := msg.IDString("_id"); err == nil {
+		m.Attributes[pubsubIDAttribute] = id
+	}
+	if ps.orderingKey != "" {
+		if v, ok := getField(msg.Map(), ps.orderingKey); ok && v != nil {
+			m.OrderingKey = fmt.Sprintf("%v", v)
+		}
+	}
+
+	ps.Lock()
+	defer ps.Unlock()
+	size := pubsubMessageSize(m)
+	if len(ps.batch) > 0 && (len(ps.batch) >= ps.batchSize || ps.size+size > ps.batchBytes) {
+		ps.flush()
+	}
+	ps.batch = append(ps.batch, m)
+	ps.docs = append(ps.docs, msg.Data)
+	ps.size += size
+	if len(ps.batch) >= ps.batchSize {
+		ps.flush()
+	}
+	return msg, nil
+}
+
+// flush publishes the pending batch, retrying transient failures.  The batch is retried as a whole so that
+// messages with the same ordering key stay in order.  The lock must be held
+func (ps *PubSub) flush() {
+	if len(ps.batch) == 0 {
+		return
+	}
+
+	backoff := ps.backoff
+	err := ps.publisher.Publish(ps.batch)
+	for attempt := 0; err != nil && attempt < ps.retries && pubsubErrorCategory(err) == TRANSIENT; attempt++ {
+		ps.pipe.Err <- NewCategorizedError(TRANSIENT, WARNING, ps.path, fmt.Sprintf("pubsub error (%s), retrying %d messages in %s", err.Error(), len(ps.batch), backoff), nil)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > pubsubMaxRetryBackoff {
+			backoff = pubsubMaxRetryBackoff
+		}
+		err = ps.publisher.Publish(ps.batch)
+	}
+	if err != nil {
+		if err := ps.onError.handleCategorized(pubsubErrorCategory(err), fmt.Sprintf("pubsub error (%s: %s)", ps.topic, err.Error()), ps.docs...); err != nil {
+			ps.pipe.Err <- err
+			ps.pipe.Stop()
+		}
+	}
+
+	ps.batch, ps.docs, ps.size = nil, nil, 0
+}
+
+// pubsubMessageSize estimates the size of the message in a publish request, the data is base64 encoded
+func pubsubMessageSize(m pubsubMessage) int {
+	size := (len(m.Data)+2)/3*4 + len(m.OrderingKey)
+	for k, v := range m.Attributes {
+		size += len(k) + len(v)
+	}
+	return size
+}
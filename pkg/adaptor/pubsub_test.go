@@ -0,0 +1,294 @@
+//go:build !slim || pubsub
+// +build !slim pubsub
+
+package adaptor
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// testPubSubPublisher records each batch published, and fails the first publishes with the given errors
+type testPubSubPublisher struct {
+	sync.Mutex
+
+	batches [][]pubsubMessage
+	errs    []error
+	calls   int
+}
+
+func (p *testPubSubPublisher) Publish(msgs []pubsubMessage) error {
+	p.Lock()
+	defer p.Unlock()
+	p.calls++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return err
+	}
+	p.batches = append(p.batches, append([]pubsubMessage(nil), msgs...))
+	return nil
+}
+
+func newTestPubSub(t *testing.T, publisher pubsubPublisher, extra Config) (*PubSub, chan error) {
+	conf := Config{"project": "proj", "topic": "events", "namespace": "db./.*/", "token": "t"}
+	for k, v := range extra {
+		conf[k] = v
+	}
+	s, err := NewPubSub(pipe.NewPipe(nil, "path"), "path", conf)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	ps := s.(*PubSub)
+	ps.publisher = publisher
+	ps.backoff = time.Millisecond
+
+	errs := make(chan error, 100)
+	go func(p *pipe.Pipe) {
+		for err := range p.Err {
+			errs <- err
+		}
+	}(ps.pipe)
+	return ps, errs
+}
+
+func TestPubSubPublish(t *testing.T) {
+	publisher := &testPubSubPublisher{}
+	ps, _ := newTestPubSub(t, publisher, Config{"orderingkey": "customer.id", "batchsize": 2})
+
+	data := []*message.Msg{
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "customer": map[string]interface{}{"id": 7}}, "db.orders"),
+		message.NewMsg(message.Command, map[string]interface{}{"flush": true}, "db.orders"),
+		message.NewMsg(message.Delete, map[string]interface{}{"_id": "2"}, "db.users"),
+		message.NewMsg(message.Update, map[string]interface{}{"_id": "3", "customer": map[string]interface{}{"id": "c"}}, "db.orders"),
+	}
+	for _, v := range data {
+		ps.publish(v)
+	}
+	ps.running, ps.done = true, make(chan struct{})
+	ps.Stop()
+
+	expected := [][]pubsubMessage{
+		{
+			{Data: []byte(`{"_id":"1","customer":{"id":7}}`), Attributes: map[string]string{"op": "insert", "namespace": "db.orders", "id": "1"}, OrderingKey: "7"},
+			{Data: []byte(`{"_id":"2"}`), Attributes: map[string]string{"op": "delete", "namespace": "db.users", "id": "2"}},
+		},
+		{
+			{Data: []byte(`{"_id":"3","customer":{"id":"c"}}`), Attributes: map[string]string{"op": "update", "namespace": "db.orders", "id": "3"}, OrderingKey: "c"},
+		},
+	}
+	if !reflect.DeepEqual(publisher.batches, expected) {
+		t.Errorf("expected %+v, got %+v", expected, publisher.batches)
+	}
+}
+
+func TestPubSubBatchBytes(t *testing.T) {
+	publisher := &testPubSubPublisher{}
+	// each message is 10 bytes of json, 16 encoded, and 24 bytes of attributes
+	ps, _ := newTestPubSub(t, publisher, Config{"batchbytes": 80})
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		ps.publish(message.NewMsg(message.Insert, map[string]interface{}{"_id": id}, "db.a"))
+	}
+	ps.flush()
+
+	var sizes []int
+	for _, b := range publisher.batches {
+		sizes = append(sizes, len(b))
+	}
+	if !reflect.DeepEqual(sizes, []int{2, 2, 1}) {
+		t.Errorf("expected batches of [2 2 1], got %v", sizes)
+	}
+}
+
+func TestPubSubDelay(t *testing.T) {
+	publisher := &testPubSubPublisher{}
+	ps, _ := newTestPubSub(t, publisher, Config{"delay": "10ms"})
+	ps.running, ps.done = true, make(chan struct{})
+	go ps.flushEvery(ps.delay, ps.done)
+	defer ps.Stop()
+
+	ps.publish(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "db.a"))
+	for i := 0; i < 100; i++ {
+		publisher.Lock()
+		n := len(publisher.batches)
+		publisher.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("expected the batch to be published once the delay passed")
+}
+
+func TestPubSubRetry(t *testing.T) {
+	data := []struct {
+		errs      []error
+		calls     int
+		published int
+		level     ErrorLevel
+	}{
+		{[]error{&pubsubError{Status: 503, Message: "unavailable"}, &pubsubError{Status: 429, Message: "quota"}}, 3, 1, WARNING},
+		// permanent failures aren't retried, and the batch is skipped
+		{[]error{&pubsubError{Status: 404, Message: "topic not found"}}, 1, 0, ERROR},
+		// nor are transient failures once the retries are used up
+		{[]error{&pubsubError{Status: 503}, &pubsubError{Status: 503}, &pubsubError{Status: 503}}, 3, 0, ERROR},
+	}
+
+	for _, v := range data {
+		publisher := &testPubSubPublisher{errs: v.errs}
+		ps, errs := newTestPubSub(t, publisher, Config{"retries": 2})
+		ps.publish(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "db.a"))
+		ps.flush()
+
+		if publisher.calls != v.calls || len(publisher.batches) != v.published {
+			t.Errorf("%v: expected %d calls and %d batches, got %d and %d", v.errs, v.calls, v.published, publisher.calls, len(publisher.batches))
+		}
+		var last Error
+		for i := 0; i < len(v.errs) && i < v.calls; i++ {
+			select {
+			case err := <-errs:
+				last = err.(Error)
+			case <-time.After(time.Second):
+				t.Fatalf("%v: expected an error", v.errs)
+			}
+		}
+		if last.Lvl != v.level {
+			t.Errorf("%v: expected the last error to be %v, got %v", v.errs, v.level, last)
+		}
+	}
+}
+
+func TestPubSubClient(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		tokens    int
+		published pubsubRequest
+		auth      string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			if len(parts) != 3 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig) != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			tokens++
+			w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+		case "/v1/projects/proj/topics/events:publish":
+			auth = r.Header.Get("Authorization")
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &published)
+			if strings.Contains(string(body), `"orderingKey":"bad"`) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"code":400,"message":"bad message","status":"INVALID_ARGUMENT"}}`))
+				return
+			}
+			w.Write([]byte(`{"messageIds":["1"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pubsub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	account, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "transporter@proj.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":    server.URL + "/token",
+	})
+	file := filepath.Join(dir, "key.json")
+	ioutil.WriteFile(file, account, 0600)
+
+	c, err := newPubSubClient("projects/proj/topics/events", PubSubConfig{Endpoint: server.URL, Credentials: file}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	msg := pubsubMessage{Data: []byte(`{"_id":"1"}`), Attributes: map[string]string{"op": "insert"}, OrderingKey: "k"}
+	for i := 0; i < 2; i++ {
+		if err := c.Publish([]pubsubMessage{msg}); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+	}
+	if tokens != 1 || auth != "Bearer access" {
+		t.Errorf("expected a single token to be used, got %d tokens and %q", tokens, auth)
+	}
+	if !reflect.DeepEqual(published.Messages, []pubsubMessage{msg}) {
+		t.Errorf("expected %+v, got %+v", msg, published.Messages)
+	}
+
+	err = c.Publish([]pubsubMessage{{Data: []byte("{}"), OrderingKey: "bad"}})
+	if e, ok := err.(*pubsubError); !ok || e.Status != 400 || e.Message != "bad message" || pubsubErrorCategory(err) != PERMANENT {
+		t.Errorf("expected a permanent error, got %v", err)
+	}
+
+	// a key the token server doesn't know isn't retried
+	other, _ := rsa.GenerateKey(rand.Reader, 1024)
+	account, _ = json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "transporter@proj.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(other)})),
+		"token_uri":    server.URL + "/token",
+	})
+	ioutil.WriteFile(file, account, 0600)
+	c, err = newPubSubClient("projects/proj/topics/events", PubSubConfig{Endpoint: server.URL, Credentials: file}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if err := c.Publish([]pubsubMessage{msg}); pubsubErrorCategory(err) != PERMANENT {
+		t.Errorf("expected a refused token to be a permanent error, got %v", err)
+	}
+}
+
+func TestPubSubBadConfig(t *testing.T) {
+	data := []Config{
+		{"topic": "events", "token": "t"},
+		{"project": "proj", "token": "t"},
+		{"project": "proj", "topic": "events", "token": "t", "batchsize": 1001},
+		{"project": "proj", "topic": "events", "token": "t", "retries": -1},
+		{"project": "proj", "topic": "events", "token": "t", "delay": "soon"},
+		{"project": "proj", "topic": "events", "token": "t", "onerror": "retry"},
+		{"project": "proj", "topic": "events", "credentials": "/does/not/exist.json"},
+	}
+	for _, conf := range data {
+		if _, err := NewPubSub(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("%v: expected a CONFIG error, got %v", conf, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+//go:build !slim || pubsub
+// +build !slim pubsub
+
+package adaptor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	pubsubEndpoint = "https://pubsub.googleapis.com"
+	pubsubScope    = "https://www.googleapis.com/auth/pubsub"
+)
+
+// pubsubMessage is a message as the Pub/Sub publish api takes it, the data is base64 encoded by encoding/json
+type pubsubMessage struct {
+	Data        []byte            `json:"data"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// pubsubPublisher publishes a batch of messages to a topic.  It's satisfied by pubsubClient, and is an
+// interface so that the adaptor can be tested without Pub/Sub
+type pubsubPublisher interface {
+	Publish(msgs []pubsubMessage) error
+}
+
+// pubsubError is an error response from the publish api
+type pubsubError struct {
+	Status  int
+	Message string
+}
+
+func (e *pubsubError) Error() string {
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// pubsubErrorCategory categorizes a failed publish.  Failed connections, rate limits, and server errors are
+// transient, as are the statuses google asks to be retried.  Any other rejected request is permanent, as is
+// a token the oauth server refuses
+func pubsubErrorCategory(err error) ErrorCategory {
+	var token *oauth2.RetrieveError
+	if errors.As(err, &token) && token.Response != nil {
+		err = &pubsubError{Status: token.Response.StatusCode, Message: token.Error()}
+	}
+	switch e := err.(type) {
+	case *pubsubError:
+		if e.Status == http.StatusTooManyRequests || e.Status == http.StatusRequestTimeout || e.Status >= http.StatusInternalServerError {
+			return TRANSIENT
+		}
+		return PERMANENT
+	case *url.Error, net.Error:
+		return TRANSIENT
+	}
+	return UNCATEGORIZED
+}
+
+// pubsubClient is a minimal client for the Pub/Sub rest api (https://cloud.google.com/pubsub/docs/reference/rest),
+// it only publishes.  The http client adds the credentials, see newPubSubClient
+type pubsubClient struct {
+	endpoint string
+	topic    string // projects/<project>/topics/<topic>
+	client   *http.Client
+}
+
+// pubsubRequest is the body of a publish request
+type pubsubRequest struct {
+	Messages []pubsubMessage `json:"messages"`
+}
+
+// Publish sends the messages in a single request
+func (c *pubsubClient) Publish(msgs []pubsubMessage) error {
+	ba, err := json.Marshal(pubsubRequest{Messages: msgs})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/%s:publish", c.endpoint, c.topic), bytes.NewReader(ba))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		var result struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &result) == nil && result.Error.Message != "" {
+			return &pubsubError{Status: resp.StatusCode, Message: result.Error.Message}
+		}
+		return &pubsubError{Status: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package adaptor
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport is an http.RoundTripper that watches for 429 Too Many Requests responses.
+// the elastic client only hands back an error, so the Retry-After header is kept here until
+// the adaptor asks for it with take
+type rateLimitTransport struct {
+	next http.RoundTripper
+
+	sync.Mutex
+	limited    bool
+	retryAfter time.Duration
+}
+
+func newRateLimitTransport(next http.RoundTripper) *rateLimitTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &rateLimitTransport{next: next}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusTooManyRequests {
+		return res, err
+	}
+
+	t.Lock()
+	t.limited = true
+	t.retryAfter = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	t.Unlock()
+	return res, err
+}
+
+// take reports whether a request was rate limited since the last call, and how long the server asked
+// us to wait.  the wait is 0 when the server didn't say
+func (t *rateLimitTransport) take() (time.Duration, bool) {
+	t.Lock()
+	defer t.Unlock()
+	wait, limited := t.retryAfter, t.limited
+	t.limited, t.retryAfter = false, 0
+	return wait, limited
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an http date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -13,13 +13,40 @@ var (
 )
 
 func init() {
-	Register("mongo", "a mongodb adaptor that functions as both a source and a sink", NewMongodb, MongodbConfig{})
 	Register("file", "an adaptor that reads / writes files", NewFile, FileConfig{})
-	Register("elasticsearch", "an elasticsearch sink adaptor", NewElasticsearch, dbConfig{})
-	Register("appbase", "an appbase sink adaptor", NewAppbase, AppbaseConfig{})
 	// Register("influx", "an InfluxDB sink adaptor", NewInfluxdb, dbConfig{})
-	Register("transformer", "an adaptor that transforms documents using a javascript function", NewTransformer, TransformerConfig{})
-	Register("rethinkdb", "a rethinkdb sink adaptor", NewRethinkdb, rethinkDbConfig{})
+	RegisterTransformer("transformer", "an adaptor that transforms documents using a javascript function", NewTransformer, TransformerConfig{})
+	RegisterTransformer("exec", "an adaptor that transforms documents by piping them through an external command", NewExec, ExecConfig{})
+	RegisterTransformer("replace", "a transformer that applies regex substitutions to string fields", NewReplace, ReplaceConfig{})
+	RegisterTransformer("defaults", "a transformer that fills in default values for missing or null fields", NewDefaults, DefaultsConfig{})
+	RegisterTransformer("timeparse", "a transformer that converts timestamps into a single format", NewTimeParse, TimeParseConfig{})
+	RegisterTransformer("template", "a transformer that builds fields from templates referencing other fields", NewTemplate, TemplateConfig{})
+	RegisterTransformer("explode", "a transformer that explodes a map field into a message per entry", NewExplode, ExplodeConfig{})
+	RegisterTransformer("esfields", "a transformer that renames fields elasticsearch would reject", NewESFields, ESFieldsConfig{})
+	RegisterTransformer("compute", "a transformer that sets numeric fields from arithmetic expressions over other fields", NewCompute, ComputeConfig{})
+	RegisterTransformer("wrap", "a transformer that wraps documents in an envelope with the message metadata", NewWrap, EnvelopeConfig{})
+	RegisterTransformer("unwrap", "a transformer that replaces enveloped documents with their payload", NewUnwrap, EnvelopeConfig{})
+	RegisterTransformer("idcollisions", "a transformer that reports inserts reusing the id of a different document", NewIDCollisions, IDCollisionsConfig{})
+	RegisterTransformer("router", "a transformer that sends each message to the one child whose route it matches", NewRouter, RouterConfig{})
+	RegisterTransformer("dedupe", "a transformer that removes, or merges, repeated elements of array fields", NewDedupe, DedupeConfig{})
+	RegisterTransformer("ensurearray", "a transformer that makes fields consistently arrays, or unwraps single element arrays", NewEnsureArray, EnsureArrayConfig{})
+	RegisterTransformer("jsonencode", "a transformer that replaces document and array fields with their json", NewJSONEncode, JSONCodecConfig{})
+	RegisterTransformer("jsondecode", "a transformer that replaces fields holding json with the value they hold", NewJSONDecode, JSONCodecConfig{})
+	RegisterTransformer("geoip", "a transformer that locates ip addresses with a MaxMind database", NewGeoIP, GeoIPConfig{})
+	RegisterTransformer("maxdepth", "a transformer that collapses fields nested deeper than a limit, to keep mappings bounded", NewMaxDepth, MaxDepthConfig{})
+	RegisterTransformer("contenthash", "a transformer that sets a field to the hash of the document's content, for change detection", NewContentHash, ContentHashConfig{})
+	RegisterTransformer("split", "a transformer that splits a string field on a delimiter into an array", NewSplit, SplitConfig{})
+	RegisterTransformer("stringnorm", "a transformer that normalizes string fields with lower, upper, trim and collapse-whitespace", NewStringNorm, StringNormConfig{})
+	RegisterTransformer("duration", "a transformer that writes the time between two timestamp fields, or a field and now, in the given units", NewDuration, DurationConfig{})
+	RegisterTransformer("pivot-long", "a transformer that pivots a wide row of metrics into a message per metric, with the shared timestamp and dimensions", NewPivotLong, PivotLongConfig{})
+	RegisterTransformer("maplookup", "a transformer that translates coded field values, i.e. status 1 to active, through a lookup table per field", NewMapLookup, MapLookupConfig{})
+	RegisterTransformer("prune", "a transformer that removes null fields, and optionally empty strings, arrays and objects, before documents are written", NewPrune, PruneConfig{})
+	RegisterTransformer("allowlist", "a transformer that checks fields against allow lists of values, dropping, erroring or flagging messages with values that aren't allowed", NewAllowList, AllowListConfig{})
+	RegisterTransformer("expiry", "a transformer that writes the time documents expire, now or a timestamp field plus a ttl, for sinks with TTL indexes or lifecycle policies", NewExpiry, ExpiryConfig{})
+	RegisterTransformer("reorder", "a transformer that holds messages for a short window to release them in the order of a sequence field", NewReorder, ReorderConfig{})
+	RegisterTransformer("extract", "a transformer that parses fields out of a string field with the named groups of a regex, i.e. for log lines", NewExtract, ExtractConfig{})
+	RegisterTransformer("coalesce", "a transformer that sets a field to the first of a list of fields holding a value, or a default", NewCoalesce, CoalesceConfig{})
+	RegisterTransformer("lineage", "a transformer that stamps documents with the pipeline, host and transporter version that processed them, and when", NewLineage, LineageConfig{})
 }
 
 // Register registers an adaptor (database adaptor) for use with Transporter
@@ -34,6 +61,20 @@ func Register(name, desc string, fn func(*pipe.Pipe, string, Config) (StopStartL
 	}
 }
 
+// RegisterTransformer registers an adaptor that transforms documents rather than reading or writing them.
+// Transformers can only be used with Listen(), and must have children in a node tree
+func RegisterTransformer(name, desc string, fn func(*pipe.Pipe, string, Config) (StopStartListener, error), config interface{}) {
+	Register(name, desc, fn, config)
+	entry := Adaptors[name]
+	entry.Transformer = true
+	Adaptors[name] = entry
+}
+
+// IsTransformer returns true if the named adaptor was registered as a transformer
+func IsTransformer(name string) bool {
+	return Adaptors[name].Transformer
+}
+
 // Registry maps the adaptor's name to the RegistryEntry
 type Registry map[string]RegistryEntry
 
@@ -43,6 +84,7 @@ type RegistryEntry struct {
 	Description string
 	Constructor func(*pipe.Pipe, string, Config) (StopStartListener, error)
 	Config      interface{}
+	Transformer bool
 }
 
 // About inspects the  RegistryEntry's Config object, and uses
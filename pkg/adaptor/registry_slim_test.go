@@ -0,0 +1,21 @@
+//go:build slim && !mongo
+// +build slim,!mongo
+
+package adaptor
+
+import (
+	"testing"
+)
+
+func TestRegistrySlim(t *testing.T) {
+	if _, ok := Adaptors["mongo"]; ok {
+		t.Errorf("expected mongo to be left out of a slim build without the mongo tag")
+	}
+
+	// the file adaptor and the transformers are always compiled in
+	for _, name := range []string{"file", "transformer", "replace"} {
+		if _, ok := Adaptors[name]; !ok {
+			t.Errorf("expected %s to be registered", name)
+		}
+	}
+}
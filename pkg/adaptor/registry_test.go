@@ -0,0 +1,17 @@
+//go:build !slim
+// +build !slim
+
+package adaptor
+
+import (
+	"testing"
+)
+
+func TestRegistryAll(t *testing.T) {
+	// without the slim tag every adaptor is compiled in
+	for _, name := range []string{"mongo", "file", "elasticsearch", "appbase", "opensearch", "rethinkdb", "parquet", "nats", "pubsub"} {
+		if _, ok := Adaptors[name]; !ok {
+			t.Errorf("expected %s to be registered", name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+//go:build !slim || appbase || opensearch
+// +build !slim appbase opensearch
+
+package adaptor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/olivere/elastic"
+)
+
+// ReindexConfig configures a zero downtime reindex.  The sink creates a new index, writes everything it's
+// sent to it, and once the run is over, moves the alias from the indexes it's on to the new one in a single
+// step.  The alias only moves if the source finished on its own, i.e. copied its collections without tailing,
+// and every bulk was written
+type ReindexConfig struct {
+	Alias     string                 `json:"alias" doc:"the alias readers and writers use, moved to the new index when the backfill is complete"`
+	Index     string                 `json:"index" doc:"the name of the new index, defaults to the alias followed by the time, i.e. products_20160301123000"`
+	Body      map[string]interface{} `json:"body" doc:"the settings and mappings to create the new index with"`
+	OnFailure string                 `json:"onfailure" doc:"what to do with the new index when the backfill fails, delete (the default) or keep"`
+	DeleteOld bool                   `json:"deleteold" doc:"delete the indexes the alias was moved from"`
+}
+
+// appbaseReindex tracks a reindex through the run
+type appbaseReindex struct {
+	ReindexConfig
+	created bool // the new index exists
+	failed  bool // documents were lost, so the new index is incomplete
+}
+
+func newAppbaseReindex(conf *ReindexConfig) (*appbaseReindex, error) {
+	r := &appbaseReindex{ReindexConfig: *conf}
+	if r.Alias == "" {
+		return nil, fmt.Errorf("reindex requires an alias")
+	}
+	if r.Index == "" {
+		r.Index = fmt.Sprintf("%s_%s", r.Alias, time.Now().UTC().Format("20060102150405"))
+	}
+	if r.Index == r.Alias {
+		return nil, fmt.Errorf("the reindex index and alias must be different, both are %s", r.Index)
+	}
+	switch r.OnFailure {
+	case "":
+		r.OnFailure = "delete"
+	case "delete", "keep":
+	default:
+		return nil, fmt.Errorf("unknown reindex onfailure (%s), must be delete or keep", r.OnFailure)
+	}
+	return r, nil
+}
+
+// create creates the new index, it's an error for it to exist already
+func (r *appbaseReindex) create(client *elastic.Client) error {
+	service := client.CreateIndex(r.Index)
+	if r.Body != nil {
+		service.BodyJson(r.Body)
+	}
+	if _, err := service.Do(); err != nil {
+		return fmt.Errorf("can't create index %s (%s)", r.Index, err.Error())
+	}
+	r.created = true
+	return nil
+}
+
+// finish moves the alias to the new index after a complete run, or cleans up after an incomplete one
+func (r *appbaseReindex) finish(client *elastic.Client, result error) error {
+	if !r.created {
+		return nil
+	}
+	if result != nil || r.failed {
+		if r.OnFailure == "keep" {
+			return nil
+		}
+		if _, err := client.DeleteIndex(r.Index).Do(); err != nil {
+			return fmt.Errorf("can't delete the incomplete index %s (%s)", r.Index, err.Error())
+		}
+		return nil
+	}
+
+	aliases, err := client.Aliases().Do()
+	if err != nil {
+		return fmt.Errorf("can't get the indexes of alias %s (%s)", r.Alias, err.Error())
+	}
+	old := aliases.IndicesByAlias(r.Alias)
+	sort.Strings(old)
+
+	service := client.Alias()
+	for _, index := range old {
+		service.Remove(index, r.Alias)
+	}
+	if _, err := service.Add(r.Index, r.Alias).Do(); err != nil {
+		return fmt.Errorf("can't move alias %s to %s (%s)", r.Alias, r.Index, err.Error())
+	}
+
+	if r.DeleteOld {
+		for _, index := range old {
+			if _, err := client.DeleteIndex(index).Do(); err != nil {
+				return fmt.Errorf("can't delete the old index %s (%s)", index, err.Error())
+			}
+		}
+	}
+	return nil
+}
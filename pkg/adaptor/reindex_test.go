@@ -0,0 +1,249 @@
+//go:build !slim || appbase || opensearch
+// +build !slim appbase opensearch
+
+package adaptor
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// testReindexCluster keeps the indexes and aliases of a cluster, and the requests made to it
+type testReindexCluster struct {
+	*httptest.Server
+	sync.Mutex
+
+	indexes    map[string][]string // each index's aliases
+	requests   []string            // the method and path of each request, other than health checks
+	bulkStatus int
+}
+
+func newTestReindexCluster(indexes map[string][]string) *testReindexCluster {
+	c := &testReindexCluster{indexes: indexes, bulkStatus: http.StatusOK}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Lock()
+		defer c.Unlock()
+
+		p := path.Clean(r.URL.Path) // the client doubles some slashes
+		if p == "/" {
+			return // health checks
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		c.requests = append(c.requests, r.Method+" "+p)
+
+		switch {
+		case strings.HasSuffix(p, "/_bulk"):
+			w.WriteHeader(c.bulkStatus)
+			w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+		case p == "/_aliases" && r.Method == "GET":
+			out := map[string]interface{}{}
+			for index, aliases := range c.indexes {
+				m := map[string]interface{}{}
+				for _, alias := range aliases {
+					m[alias] = map[string]interface{}{}
+				}
+				out[index] = map[string]interface{}{"aliases": m}
+			}
+			json.NewEncoder(w).Encode(out)
+		case p == "/_aliases" && r.Method == "POST":
+			var actions struct {
+				Actions []map[string]struct {
+					Index string `json:"index"`
+					Alias string `json:"alias"`
+				} `json:"actions"`
+			}
+			json.Unmarshal(body, &actions)
+			for _, action := range actions.Actions {
+				for kind, a := range action {
+					c.requests = append(c.requests, kind+" "+a.Index+" "+a.Alias)
+					if kind == "add" {
+						c.indexes[a.Index] = append(c.indexes[a.Index], a.Alias)
+						continue
+					}
+					var kept []string
+					for _, alias := range c.indexes[a.Index] {
+						if alias != a.Alias {
+							kept = append(kept, alias)
+						}
+					}
+					c.indexes[a.Index] = kept
+				}
+			}
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == "PUT":
+			index := strings.Trim(p, "/")
+			if _, ok := c.indexes[index]; ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"IndexAlreadyExistsException","status":400}`))
+				return
+			}
+			c.indexes[index] = nil
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == "DELETE":
+			delete(c.indexes, strings.Trim(p, "/"))
+			w.Write([]byte(`{"acknowledged":true}`))
+		}
+	}))
+	return c
+}
+
+func (c *testReindexCluster) state() ([]string, map[string][]string) {
+	c.Lock()
+	defer c.Unlock()
+	indexes := make(map[string][]string, len(c.indexes))
+	for index, aliases := range c.indexes {
+		indexes[index] = append([]string(nil), aliases...)
+	}
+	return append([]string(nil), c.requests...), indexes
+}
+
+func newTestReindexAppbase(t *testing.T, cluster *testReindexCluster, reindex map[string]interface{}) *Appbase {
+	a, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", Config{
+		"uri":       cluster.URL,
+		"namespace": "app.type",
+		"username":  "u",
+		"password":  "p",
+		"reindex":   reindex,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	go func(p *pipe.Pipe) {
+		for range p.Err {
+		}
+	}(a.(*Appbase).pipe)
+	if err := a.(*Appbase).setupClient(); err != nil {
+		t.Fatalf("can't connect to test cluster, %s", err)
+	}
+	return a.(*Appbase)
+}
+
+func TestAppbaseReindex(t *testing.T) {
+	data := []struct {
+		reindex   map[string]interface{}
+		result    error
+		bulkFails bool
+		requests  []string // after the index is created
+		indexes   map[string][]string
+	}{
+		{
+			// a complete run moves the alias
+			map[string]interface{}{"alias": "products", "index": "products_new"},
+			nil,
+			false,
+			[]string{"POST /products_new/type/_bulk", "GET /_aliases", "POST /_aliases", "remove products_old products", "add products_new products"},
+			map[string][]string{"products_old": nil, "products_new": {"products"}, "other": {"others"}},
+		},
+		{
+			map[string]interface{}{"alias": "products", "index": "products_new", "deleteold": true},
+			nil,
+			false,
+			[]string{"POST /products_new/type/_bulk", "GET /_aliases", "POST /_aliases", "remove products_old products", "add products_new products", "DELETE /products_old"},
+			map[string][]string{"products_new": {"products"}, "other": {"others"}},
+		},
+		{
+			// an interrupted run deletes the new index
+			map[string]interface{}{"alias": "products", "index": "products_new"},
+			errors.New("stopped"),
+			false,
+			[]string{"POST /products_new/type/_bulk", "DELETE /products_new"},
+			map[string][]string{"products_old": {"products"}, "other": {"others"}},
+		},
+		{
+			map[string]interface{}{"alias": "products", "index": "products_new", "onfailure": "keep"},
+			errors.New("stopped"),
+			false,
+			[]string{"POST /products_new/type/_bulk"},
+			map[string][]string{"products_old": {"products"}, "products_new": nil, "other": {"others"}},
+		},
+		{
+			// so does a run that lost documents, even though the source finished
+			map[string]interface{}{"alias": "products", "index": "products_new"},
+			nil,
+			true,
+			[]string{"POST /products_new/type/_bulk", "DELETE /products_new"},
+			map[string][]string{"products_old": {"products"}, "other": {"others"}},
+		},
+	}
+
+	for _, v := range data {
+		cluster := newTestReindexCluster(map[string][]string{"products_old": {"products"}, "other": {"others"}})
+		if v.bulkFails {
+			cluster.bulkStatus = http.StatusBadRequest
+		}
+		a := newTestReindexAppbase(t, cluster, v.reindex)
+
+		if err := a.reindex.create(a.client); err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.reindex, err)
+		}
+		if _, err := a.addBulkCommand(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1"}, "app.type")); err != nil {
+			t.Fatalf("%v: unexpected error, %s", v.reindex, err)
+		}
+		a.commitBulk(true)
+		if err := a.Finish(v.result); err != nil {
+			t.Errorf("%v: unexpected error, %s", v.reindex, err)
+		}
+
+		requests, indexes := cluster.state()
+		if requests[0] != "PUT /products_new" {
+			t.Errorf("%v: expected the index to be created first, got %v", v.reindex, requests)
+		} else if !reflect.DeepEqual(requests[1:], v.requests) {
+			t.Errorf("%v: expected requests %v, got %v", v.reindex, v.requests, requests[1:])
+		}
+		if !reflect.DeepEqual(indexes, v.indexes) {
+			t.Errorf("%v: expected indexes %v, got %v", v.reindex, v.indexes, indexes)
+		}
+		cluster.Close()
+	}
+}
+
+func TestAppbaseReindexExistingIndex(t *testing.T) {
+	cluster := newTestReindexCluster(map[string][]string{"products_new": nil})
+	defer cluster.Close()
+	a := newTestReindexAppbase(t, cluster, map[string]interface{}{"alias": "products", "index": "products_new"})
+
+	if err := a.reindex.create(a.client); err == nil {
+		t.Fatalf("expected an error")
+	}
+	// the index wasn't ours, so it's left alone
+	if err := a.Finish(errors.New("stopped")); err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if _, indexes := cluster.state(); !reflect.DeepEqual(indexes, map[string][]string{"products_new": nil}) {
+		t.Errorf("expected the existing index to be kept, got %v", indexes)
+	}
+}
+
+func TestAppbaseReindexBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "app.type", "username": "u", "password": "p", "reindex": map[string]interface{}{}},
+		{"namespace": "app.type", "username": "u", "password": "p", "reindex": map[string]interface{}{"alias": "products", "index": "products"}},
+		{"namespace": "app.type", "username": "u", "password": "p", "reindex": map[string]interface{}{"alias": "products", "onfailure": "ignore"}},
+		{"namespace": "app.type", "username": "u", "password": "p", "datastream": true, "reindex": map[string]interface{}{"alias": "products"}},
+		{"namespace": "app.type", "username": "u", "password": "p", "mapping": []interface{}{map[string]interface{}{"from": "db.a", "to": "a"}}, "reindex": map[string]interface{}{"alias": "products"}},
+	}
+	for _, conf := range data {
+		if _, err := NewAppbase(pipe.NewPipe(nil, "path"), "path", conf); CategoryOf(err) != CONFIG {
+			t.Errorf("%v: expected a CONFIG error, got %v", conf, err)
+		}
+	}
+
+	r, err := newAppbaseReindex(&ReindexConfig{Alias: "products"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !strings.HasPrefix(r.Index, "products_") || len(r.Index) != len("products_20160301123000") {
+		t.Errorf("expected a timestamped index, got %s", r.Index)
+	}
+}
@@ -0,0 +1,213 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// Reorder is a transformer that smooths out minor reordering in a stream, holding each message for up to a
+// window and releasing them in the order of a sequence number in their documents.  A message is released as
+// soon as it's the next in sequence.  When a gap doesn't fill within the window, or more messages are held
+// than the buffer allows, the held messages are released anyway, in order, with a WARNING naming the missing
+// sequence numbers.  Until the first message is released the next sequence number isn't known, so the first
+// messages are always held for the window.  A message arriving after later ones have been released is sent on
+// at once with a WARNING, as are messages whose documents have no sequence number, and command messages
+type Reorder struct {
+	field     string
+	window    time.Duration
+	maxBuffer int
+
+	pipe *pipe.Pipe
+	path string
+	ns   *regexp.Regexp
+
+	sync.Mutex // guards the held messages, which are released as messages arrive and as the window passes
+	held       []heldMsg
+	next       int64 // the sequence number expected next, once a message has been released
+	released   bool
+	now        func() time.Time
+	done       chan struct{}
+}
+
+// heldMsg is a message waiting in the reorder buffer, the buffer is kept sorted by sequence number
+type heldMsg struct {
+	seq     int64
+	msg     *message.Msg
+	arrived time.Time
+}
+
+// ReorderConfig provides configuration options for the reorder transformer
+type ReorderConfig struct {
+	Namespace string `json:"namespace" doc:"the set of namespaces to reorder"`
+	Field     string `json:"field" doc:"the dotted path of the whole number that orders the messages, i.e. seq"`
+	Window    string `json:"window" doc:"the longest a message is held waiting for those before it, format must be parsable by time.ParseDuration and defaults to 1s"`
+	MaxBuffer int    `json:"maxbuffer" doc:"the most messages held at once, the earliest are released when there are more, defaults to 1000"`
+}
+
+// NewReorder creates a new reorder transformer
+func NewReorder(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ReorderConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Field == "" {
+		return nil, NewError(CRITICAL, path, "reorder config must contain a sequence field", nil)
+	}
+
+	r := &Reorder{field: conf.Field, window: time.Second, maxBuffer: 1000, pipe: p, path: path, now: time.Now, done: make(chan struct{})}
+	if conf.Window != "" {
+		window, err := time.ParseDuration(conf.Window)
+		if err != nil || window <= 0 {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (unable to parse window %s)", conf.Window), nil)
+		}
+		r.window = window
+	}
+	switch {
+	case conf.MaxBuffer < 0:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (maxbuffer must be positive, got %d)", conf.MaxBuffer), nil)
+	case conf.MaxBuffer > 0:
+		r.maxBuffer = conf.MaxBuffer
+	}
+
+	var err error
+	if _, r.ns, err = extra.compileNamespace(); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't split reorder namespace (%s)", err.Error()), nil)
+	}
+
+	return r, nil
+}
+
+// Start the adaptor as a source (not implemented for transformers)
+func (r *Reorder) Start() error {
+	return fmt.Errorf("transformers can't be used as a source")
+}
+
+// Listen starts the transformer's listener, and the timer that releases messages once they've been held for the window
+func (r *Reorder) Listen() error {
+	go r.releaseEvery(r.window/4, r.done)
+	return r.pipe.Listen(r.transformOne, r.ns)
+}
+
+// Stop the adaptor, the held messages are released, in order, first
+func (r *Reorder) Stop() error {
+	r.Lock()
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+		r.releaseTo(len(r.held) - 1)
+	}
+	r.Unlock()
+	r.pipe.Stop()
+	return nil
+}
+
+// Flush only lets the checkpoint's marker be acknowledged when nothing is held.  Releasing the held messages
+// would undo the reordering, so while messages keep arriving the checkpoint is saved when the pipeline stops
+func (r *Reorder) Flush() error {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.held) > 0 {
+		return fmt.Errorf("%d messages are held", len(r.held))
+	}
+	return nil
+}
+
+// transformOne holds the message, and releases any messages that are ready.  The messages are all sent here,
+// rather than returned to the pipe, so they're never sent by the listener and the timer at once
+func (r *Reorder) transformOne(msg *message.Msg) (*message.Msg, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	seq, ok := r.sequence(msg)
+	if !ok {
+		r.pipe.Send(msg)
+		return nil, nil
+	}
+	if r.released && seq < r.next {
+		r.pipe.Err <- NewError(WARNING, r.path, fmt.Sprintf("reorder error (%d arrived after %d was released, sending it now)", seq, r.next-1), nil)
+		r.pipe.Send(msg)
+		return nil, nil
+	}
+
+	i := sort.Search(len(r.held), func(i int) bool { return r.held[i].seq > seq })
+	r.held = append(r.held, heldMsg{})
+	copy(r.held[i+1:], r.held[i:])
+	r.held[i] = heldMsg{seq: seq, msg: msg, arrived: r.now()}
+	r.release()
+	return nil, nil
+}
+
+// sequence returns the message's sequence number, if it has one
+func (r *Reorder) sequence(msg *message.Msg) (int64, bool) {
+	if msg.Op == message.Command || !msg.IsMap() {
+		return 0, false
+	}
+	v, ok := getField(msg.Map(), r.field)
+	if !ok {
+		return 0, false
+	}
+	n, ok := epochValue(v)
+	if !ok || n != math.Trunc(n) {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+// releaseEvery releases the messages that have been held for the window, every interval until done is closed
+func (r *Reorder) releaseEvery(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			r.Lock()
+			r.release()
+			r.Unlock()
+		}
+	}
+}
+
+// release sends the held messages that are ready, those next in sequence, and all those up to the last that's
+// been held for the window, or that the buffer has no room for
+func (r *Reorder) release() {
+	last := len(r.held) - r.maxBuffer - 1
+	now := r.now()
+	for i, h := range r.held {
+		if now.Sub(h.arrived) >= r.window && i > last {
+			last = i
+		}
+	}
+	r.releaseTo(last)
+}
+
+// releaseTo sends the held messages up to index last, warning of any gaps, and then those that follow on in sequence
+func (r *Reorder) releaseTo(last int) {
+	n := 0
+	for ; n < len(r.held); n++ {
+		h := r.held[n]
+		if n > last && (!r.released || h.seq != r.next) {
+			break
+		}
+		if r.released && h.seq > r.next {
+			missing := fmt.Sprintf("%d", r.next)
+			if h.seq-1 > r.next {
+				missing = fmt.Sprintf("%d to %d", r.next, h.seq-1)
+			}
+			r.pipe.Err <- NewError(WARNING, r.path, fmt.Sprintf("reorder error (released %d without %s, which didn't arrive in time)", h.seq, missing), nil)
+		}
+		r.pipe.Send(h.msg)
+		r.next, r.released = h.seq+1, true
+	}
+	r.held = r.held[n:]
+}
@@ -0,0 +1,177 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// newTestReorder returns a reorder transformer whose clock is now, along with channels collecting the
+// sequence numbers it sends on and the errors it reports
+func newTestReorder(t *testing.T, conf Config, now *time.Time) (*Reorder, chan interface{}, chan error) {
+	p := pipe.NewPipe(pipe.NewPipe(nil, "source"), "source/reorder")
+	conf["namespace"] = "db./.*/"
+	a, err := NewReorder(p, "source/reorder", conf)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	r := a.(*Reorder)
+	r.now = func() time.Time { return *now }
+
+	out := make(chan interface{}, 100)
+	child := pipe.NewPipe(p, "source/reorder/sink")
+	go func() {
+		for msg := range child.In {
+			out <- msg.Map()["seq"]
+		}
+	}()
+	errs := make(chan error, 100)
+	go func() {
+		for err := range p.Err {
+			errs <- err
+		}
+	}()
+	return r, out, errs
+}
+
+// received returns the sequence numbers sent on so far
+func received(out chan interface{}) []interface{} {
+	var seqs []interface{}
+	for {
+		select {
+		case seq := <-out:
+			seqs = append(seqs, seq)
+		case <-time.After(20 * time.Millisecond):
+			return seqs
+		}
+	}
+}
+
+func TestReorder(t *testing.T) {
+	now := time.Unix(0, 0)
+	r, out, errs := newTestReorder(t, Config{"field": "seq", "window": "1s"}, &now)
+	send := func(seqs ...interface{}) {
+		for _, seq := range seqs {
+			r.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"seq": seq}, "db.events"))
+		}
+	}
+	release := func() {
+		r.Lock()
+		r.release()
+		r.Unlock()
+	}
+
+	// the first messages are held for the window, and released in order
+	send(3, 1, 2)
+	if got := received(out); got != nil {
+		t.Fatalf("expected the first messages to be held, got %v", got)
+	}
+	now = now.Add(time.Second)
+	release()
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+
+	// then each is released as soon as it's next in sequence
+	send(5, "4", 6.0)
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{"4", 5, 6.0}) {
+		t.Errorf("expected [4 5 6], got %v", got)
+	}
+
+	// a gap that doesn't fill within the window is skipped, with a warning
+	send(9, 10)
+	now = now.Add(500 * time.Millisecond)
+	send(8)
+	release()
+	if got := received(out); got != nil {
+		t.Errorf("expected the messages after the gap to be held, got %v", got)
+	}
+	now = now.Add(500 * time.Millisecond)
+	release()
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{8, 9, 10}) {
+		t.Errorf("expected [8 9 10], got %v", got)
+	}
+	if err := <-errs; err.(Error).Lvl != WARNING || err.(Error).Str != "reorder error (released 8 without 7, which didn't arrive in time)" {
+		t.Errorf("expected a warning about the gap, got %v", err)
+	}
+
+	// a message arriving too late is sent on at once, as are those without a sequence number
+	send(7, nil)
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{7, nil}) {
+		t.Errorf("expected [7 <nil>], got %v", got)
+	}
+	if err := <-errs; err.(Error).Str != "reorder error (7 arrived after 10 was released, sending it now)" {
+		t.Errorf("expected a warning about the late message, got %v", err)
+	}
+
+	// the held messages are released when the transformer stops
+	send(13, 12)
+	r.Stop()
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{12, 13}) {
+		t.Errorf("expected [12 13], got %v", got)
+	}
+	if err := <-errs; err.(Error).Str != "reorder error (released 12 without 11, which didn't arrive in time)" {
+		t.Errorf("expected a warning about the gap, got %v", err)
+	}
+}
+
+func TestReorderWindow(t *testing.T) {
+	source := pipe.NewPipe(nil, "source")
+	a, err := NewReorder(pipe.NewPipe(source, "source/reorder"), "source/reorder", Config{"namespace": "db./.*/", "field": "seq", "window": "20ms"})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	r := a.(*Reorder)
+	child := pipe.NewPipe(r.pipe, "source/reorder/sink")
+	go r.Listen()
+	defer r.Stop()
+
+	for _, seq := range []int{2, 1} {
+		source.Send(message.NewMsg(message.Insert, map[string]interface{}{"seq": seq}, "db.events"))
+	}
+	// with no more messages arriving, the timer releases them once the window has passed
+	var seqs []interface{}
+	for len(seqs) < 2 {
+		select {
+		case msg := <-child.In:
+			seqs = append(seqs, msg.Map()["seq"])
+		case <-time.After(time.Second):
+			t.Fatalf("expected the held messages to be released, got %v", seqs)
+		}
+	}
+	if !reflect.DeepEqual(seqs, []interface{}{1, 2}) {
+		t.Errorf("expected [1 2], got %v", seqs)
+	}
+}
+
+func TestReorderMaxBuffer(t *testing.T) {
+	now := time.Unix(0, 0)
+	r, out, _ := newTestReorder(t, Config{"field": "seq", "maxbuffer": 3}, &now)
+	for _, seq := range []int{4, 2, 3, 1, 6} {
+		r.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"seq": seq}, "db.events"))
+	}
+	// once the buffer overflows the earliest are released, taking those that follow on in sequence with them
+	if got := received(out); !reflect.DeepEqual(got, []interface{}{1, 2, 3, 4}) {
+		t.Errorf("expected [1 2 3 4], got %v", got)
+	}
+	if len(r.held) != 1 {
+		t.Errorf("expected 6 to be held, got %v", r.held)
+	}
+}
+
+func TestReorderBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"field": "seq", "window": "soon"},
+		{"field": "seq", "window": "-1s"},
+		{"field": "seq", "maxbuffer": -1},
+	}
+	for _, conf := range data {
+		if _, err := NewReorder(nil, "path", conf); err == nil {
+			t.Errorf("%v: expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewReplace creates a transformer that applies regex substitutions to string fields.
+// each field may have several rules, which are applied in order, and the replacement
+// may reference the pattern's capture groups, i.e. $1 or ${name}
+func NewReplace(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf ReplaceConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "replace config must contain at least one field", nil)
+	}
+
+	r := &replace{fields: make(map[string][]replaceRule), nonString: conf.NonString}
+	switch r.nonString {
+	case "":
+		r.nonString = "skip"
+	case "skip", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown nonstring policy (%s), must be skip or error", conf.NonString), nil)
+	}
+
+	for field, rules := range conf.Fields {
+		for _, rule := range rules {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, NewError(CRITICAL, path, fmt.Sprintf("can't compile pattern for %s (%s)", field, err.Error()), nil)
+			}
+			r.fields[field] = append(r.fields[field], replaceRule{re: re, replacement: rule.Replacement})
+		}
+	}
+
+	return newDocTransformer("replace", p, path, extra, r.apply)
+}
+
+// ReplaceConfig provides configuration options for the replace transformer
+type ReplaceConfig struct {
+	Namespace string                   `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string][]ReplaceRule `json:"fields" doc:"the substitutions to make, keyed by dotted field path"`
+	NonString string                   `json:"nonstring" doc:"what to do when a field isn't a string, skip (leave it untouched, the default) or error"`
+}
+
+// ReplaceRule is a single substitution, every match of Pattern is replaced with Replacement
+type ReplaceRule struct {
+	Pattern     string `json:"pattern" doc:"the regular expression to match"`
+	Replacement string `json:"replacement" doc:"the replacement text, which may contain capture group references such as $1"`
+}
+
+type replaceRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+type replace struct {
+	fields    map[string][]replaceRule
+	nonString string
+}
+
+// apply runs the field's rules against each configured field in the document, missing fields are ignored
+func (r *replace) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for field, rules := range r.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			if r.nonString == "error" {
+				return fmt.Errorf("%s is a %T, not a string", field, v)
+			}
+			continue
+		}
+
+		for _, rule := range rules {
+			s = rule.re.ReplaceAllString(s, rule.replacement)
+		}
+		if err := setField(doc, field, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
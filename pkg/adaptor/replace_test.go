@@ -0,0 +1,98 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestReplace(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+	}{
+		{
+			// capture groups
+			Config{"fields": map[string]interface{}{
+				"phone": []interface{}{map[string]interface{}{"pattern": `^(\d{3})(\d{3})(\d{4})$`, "replacement": "($1) $2-$3"}},
+			}},
+			map[string]interface{}{"_id": "1", "phone": "5555551234"},
+			map[string]interface{}{"_id": "1", "phone": "(555) 555-1234"},
+		},
+		{
+			// named capture groups, in a nested field
+			Config{"fields": map[string]interface{}{
+				"name.full": []interface{}{map[string]interface{}{"pattern": `(?P<first>\w+) (?P<last>\w+)`, "replacement": "${last}, ${first}"}},
+			}},
+			map[string]interface{}{"_id": "1", "name": bson.M{"full": "nick smith"}},
+			map[string]interface{}{"_id": "1", "name": bson.M{"full": "smith, nick"}},
+		},
+		{
+			// multiple rules apply in order
+			Config{"fields": map[string]interface{}{
+				"body": []interface{}{
+					map[string]interface{}{"pattern": `<[^>]+>`, "replacement": ""},
+					map[string]interface{}{"pattern": `\s+`, "replacement": " "},
+				},
+			}},
+			map[string]interface{}{"_id": "1", "body": "<p>hello\n\n  <b>world</b></p>"},
+			map[string]interface{}{"_id": "1", "body": "hello world"},
+		},
+		{
+			// non-strings and missing fields are left alone by default
+			Config{"fields": map[string]interface{}{
+				"count":   []interface{}{map[string]interface{}{"pattern": `\d`, "replacement": "x"}},
+				"missing": []interface{}{map[string]interface{}{"pattern": `\d`, "replacement": "x"}},
+			}},
+			map[string]interface{}{"_id": "1", "count": 10},
+			map[string]interface{}{"_id": "1", "count": 10},
+		},
+		{
+			// non-strings are rejected with the error policy
+			Config{"nonstring": "error", "fields": map[string]interface{}{
+				"count": []interface{}{map[string]interface{}{"pattern": `\d`, "replacement": "x"}},
+			}},
+			map[string]interface{}{"_id": "1", "count": 10},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "replace", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestReplaceConfigErrors(t *testing.T) {
+	data := []Config{
+		{"namespace": "database.collection"},
+		{"namespace": "database.collection", "fields": map[string]interface{}{"a": []interface{}{map[string]interface{}{"pattern": "(a"}}}},
+		{"namespace": "database.collection", "nonstring": "explode", "fields": map[string]interface{}{"a": []interface{}{map[string]interface{}{"pattern": "a"}}}},
+	}
+
+	for _, v := range data {
+		if _, err := NewReplace(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !slim || rethinkdb
+// +build !slim rethinkdb
+
 package adaptor
 
 import (
@@ -15,6 +18,10 @@ import (
 	gorethink "gopkg.in/dancannon/gorethink.v1"
 )
 
+func init() {
+	Register("rethinkdb", "a rethinkdb sink adaptor", NewRethinkdb, rethinkDbConfig{})
+}
+
 // Rethinkdb is an adaptor that writes metrics to rethinkdb (http://rethinkdb.com/)
 // An open-source distributed database
 type Rethinkdb struct {
@@ -24,9 +31,11 @@ type Rethinkdb struct {
 	// save time by setting these once
 	database   string
 	tableMatch *regexp.Regexp
+	mapping    *namespaceMapping
 
-	debug bool
-	tail  bool
+	debug      bool
+	tail       bool
+	durability string
 
 	//
 	pipe *pipe.Pipe
@@ -34,6 +43,8 @@ type Rethinkdb struct {
 
 	// rethinkdb connection and options
 	client *gorethink.Session
+
+	onError *errorPolicy
 }
 
 // rethinkDbConfig provides custom configuration options for the RethinkDB adapter
@@ -42,6 +53,10 @@ type rethinkDbConfig struct {
 	Namespace string `json:"namespace" doc:"rethink namespace to read/write"`
 	Debug     bool   `json:"debug" doc:"if true, verbose debugging information is displayed"`
 	Tail      bool   `json:"tail" doc:"if true, the RethinkDB table will be monitored for changes after copying the namespace"`
+
+	Durability string `json:"durability" doc:"the durability of writes, hard (acknowledged once written to disk, the default) or soft (acknowledged once in memory)"`
+
+	Mapping []NamespaceRule `json:"mapping" doc:"rules translating source namespaces into the table to write to"`
 }
 
 type rethinkDbChangeNotification struct {
@@ -86,12 +101,30 @@ func NewRethinkdb(p *pipe.Pipe, path string, extra Config) (StopStartListener, e
 		pipe: p,
 		path: path,
 		tail: conf.Tail,
+
+		durability: conf.Durability,
+	}
+
+	switch r.durability {
+	case "", "hard", "soft":
+	default:
+		return r, fmt.Errorf("unknown durability (%s), must be hard or soft", conf.Durability)
 	}
 
 	r.database, r.tableMatch, err = extra.compileNamespace()
 	if err != nil {
 		return r, err
 	}
+
+	r.mapping, err = newNamespaceMapping(conf.Mapping)
+	if err != nil {
+		return r, err
+	}
+
+	r.onError, err = newErrorPolicy(p, path, extra, onErrorSkip)
+	if err != nil {
+		return r, err
+	}
 	r.debug = conf.Debug
 	if r.debug {
 		fmt.Printf("tableMatch: %+v\n", r.tableMatch)
@@ -177,9 +210,8 @@ func (r *Rethinkdb) Start() error {
 	defer tables.Close()
 
 	var (
-		wg     sync.WaitGroup
-		outerr error
-		table  string
+		wg    sync.WaitGroup
+		table string
 	)
 	for tables.Next(&table) {
 		if match := r.tableMatch.MatchString(table); !match {
@@ -198,7 +230,6 @@ func (r *Rethinkdb) Start() error {
 
 			if err := r.sendAllDocuments(table); err != nil {
 				r.pipe.Err <- err
-				outerr = err
 			} else if r.tail {
 				start <- true
 			}
@@ -338,38 +369,52 @@ func (r *Rethinkdb) applyOp(msg *message.Msg) (*message.Msg, error) {
 		r.pipe.Err <- NewError(ERROR, r.path, fmt.Sprintf("rethinkdb error (msg namespace improperly formatted, must be database.table, got %s)", msg.Namespace), msg.Data)
 		return msg, nil
 	}
+	msgTable = r.mapping.resolve(msg.Namespace, msgTable)
+
 	if !msg.IsMap() {
 		r.pipe.Err <- NewError(ERROR, r.path, "rethinkdb error (document must be a json document)", msg.Data)
 		return msg, nil
 	}
-	doc := msg.Map()
 
-	switch msg.Op {
-	case message.Delete:
-		id, err := msg.IDString("id")
-		if err != nil {
-			r.pipe.Err <- NewError(ERROR, r.path, "rethinkdb error (cannot delete an object with a nil id)", msg.Data)
-			return msg, nil
-		}
-		resp, err = gorethink.Table(msgTable).Get(id).Delete().RunWrite(r.client)
-	case message.Insert:
-		resp, err = gorethink.Table(msgTable).Insert(doc).RunWrite(r.client)
-	case message.Update:
-		resp, err = gorethink.Table(msgTable).Insert(doc, gorethink.InsertOpts{Conflict: "replace"}).RunWrite(r.client)
+	if msg.Op != message.Insert && msg.Op != message.Update && msg.Op != message.Delete {
+		return msg, nil
 	}
+	term, err := r.writeTerm(msg, msgTable)
 	if err != nil {
-		r.pipe.Err <- NewError(ERROR, r.path, "rethinkdb error (%s)", err)
+		r.pipe.Err <- NewError(ERROR, r.path, fmt.Sprintf("rethinkdb error (%s)", err.Error()), msg.Data)
 		return msg, nil
 	}
-
-	err = r.handleResponse(&resp)
+	resp, err = term.RunWrite(r.client)
+	if err == nil {
+		err = r.handleResponse(&resp)
+	}
 	if err != nil {
-		r.pipe.Err <- NewError(ERROR, r.path, "rethinkdb error (%s)", err)
+		return msg, r.onError.handle(fmt.Sprintf("rethinkdb error (%s)", err.Error()), msg.Data)
 	}
 
 	return msg, nil
 }
 
+// writeTerm builds the query applying op to the table, with the configured durability
+func (r *Rethinkdb) writeTerm(msg *message.Msg, table string) (gorethink.Term, error) {
+	var durability interface{}
+	if r.durability != "" {
+		durability = r.durability
+	}
+
+	switch msg.Op {
+	case message.Delete:
+		id, err := msg.IDString("id")
+		if err != nil {
+			return gorethink.Term{}, errors.New("cannot delete an object with a nil id")
+		}
+		return gorethink.Table(table).Get(id).Delete(gorethink.DeleteOpts{Durability: durability}), nil
+	case message.Update:
+		return gorethink.Table(table).Insert(msg.Map(), gorethink.InsertOpts{Conflict: "replace", Durability: durability}), nil
+	}
+	return gorethink.Table(table).Insert(msg.Map(), gorethink.InsertOpts{Durability: durability}), nil
+}
+
 // handleresponse takes the rethink response and turn it into something we can consume elsewhere
 func (r *Rethinkdb) handleResponse(resp *gorethink.WriteResponse) error {
 	if resp.Errors != 0 {
@@ -0,0 +1,36 @@
+//go:build !slim || rethinkdb
+// +build !slim rethinkdb
+
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestRethinkdbWriteTerm(t *testing.T) {
+	data := []struct {
+		durability string
+		op         message.OpType
+		out        string
+	}{
+		{"", message.Insert, `r.Table("table").Insert({id="1"})`},
+		{"soft", message.Insert, `r.Table("table").Insert({id="1"}, durability="soft")`},
+		{"hard", message.Insert, `r.Table("table").Insert({id="1"}, durability="hard")`},
+		{"", message.Update, `r.Table("table").Insert({id="1"}, conflict="replace")`},
+		{"soft", message.Delete, `r.Table("table").Get("1").Delete(durability="soft")`},
+	}
+
+	for _, v := range data {
+		r := &Rethinkdb{durability: v.durability}
+		term, err := r.writeTerm(message.NewMsg(v.op, map[string]interface{}{"id": "1"}, "database.table"), "table")
+		if err != nil {
+			t.Errorf("%s %s: unexpected error, %s", v.durability, v.op, err)
+			continue
+		}
+		if term.String() != v.out {
+			t.Errorf("%s %s: expected %s, got %s", v.durability, v.op, v.out, term.String())
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// Router is a transformer that sends each message to just one of its children, the first whose route the
+// message matches, rather than to all of them.  A route can match the message's op, its namespace and a mongo
+// style query on its document, i.e. {"to": "users", "match": {"type": "user"}}.  Messages matching no route
+// go to the default child, or are dropped if there isn't one.  Command messages go to every child
+type Router struct {
+	pipe *pipe.Pipe
+	path string
+	ns   *regexp.Regexp
+
+	routes []route
+	def    string
+}
+
+// RouterConfig provides configuration options for the router transformer
+type RouterConfig struct {
+	Namespace string      `json:"namespace" doc:"the set of namespaces to route, messages outside it aren't sent to any child"`
+	Routes    []RouteRule `json:"routes" doc:"the routes, tried in order, the first a message matches decides where it's sent"`
+	Default   string      `json:"default" doc:"the name of the child to send messages matching no route to, if unset they're dropped"`
+}
+
+// RouteRule sends the messages matching all of its conditions to the named child
+type RouteRule struct {
+	To        string                 `json:"to" doc:"the name of the child node to send matching messages to"`
+	Op        string                 `json:"op" doc:"the op messages must have, insert, update or delete"`
+	Namespace string                 `json:"namespace" doc:"the namespace messages must have, or a /regex/ matching it"`
+	Match     map[string]interface{} `json:"match" doc:"a mongo style query documents must match, i.e. {\"type\": \"user\"}"`
+}
+
+type route struct {
+	to string
+	op message.OpType
+	ns *namespaceMatcher
+	fn docFilter
+}
+
+// NewRouter creates a new router transformer
+func NewRouter(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf RouterConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Routes) == 0 {
+		return nil, NewError(CRITICAL, path, "no routes specified", nil)
+	}
+
+	r := &Router{pipe: p, path: path, def: conf.Default}
+	for i, rule := range conf.Routes {
+		if rule.To == "" {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("route %d has no child to send to", i), nil)
+		}
+		rt := route{to: rule.To, op: message.Noop}
+		if rule.Op != "" {
+			if rt.op = message.OpTypeFromString(rule.Op); rt.op == message.Unknown {
+				return nil, NewError(CRITICAL, path, fmt.Sprintf("route %d has an unknown op (%s)", i, rule.Op), nil)
+			}
+		}
+		if rule.Namespace != "" {
+			ns, err := newNamespaceMatcher(rule.Namespace, "")
+			if err != nil {
+				return nil, NewError(CRITICAL, path, fmt.Sprintf("route %d has a bad namespace, %s", i, err.Error()), nil)
+			}
+			rt.ns = &ns
+		}
+		if len(rule.Match) > 0 {
+			fn, err := compileFilter(rule.Match)
+			if err != nil {
+				return nil, NewError(CRITICAL, path, fmt.Sprintf("route %d has a bad match (%s)", i, err.Error()), nil)
+			}
+			rt.fn = fn
+		}
+		r.routes = append(r.routes, rt)
+	}
+
+	var err error
+	if _, r.ns, err = extra.compileNamespace(); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("can't split router namespace (%s)", err.Error()), nil)
+	}
+
+	return r, nil
+}
+
+// Start the adaptor as a source (not implemented for transformers)
+func (r *Router) Start() error {
+	return fmt.Errorf("transformers can't be used as a source")
+}
+
+// Listen starts the router's listener, sending each message on to the child it's routed to
+func (r *Router) Listen() error {
+	return r.pipe.Listen(r.routeOne, r.ns)
+}
+
+// Stop the adaptor
+func (r *Router) Stop() error {
+	r.pipe.Stop()
+	return nil
+}
+
+func (r *Router) routeOne(msg *message.Msg) (*message.Msg, error) {
+	if msg.Op == message.Command {
+		return msg, nil
+	}
+
+	to := r.def
+	for _, rt := range r.routes {
+		if rt.matches(msg) {
+			to = rt.to
+			break
+		}
+	}
+	if to == "" {
+		return nil, nil
+	}
+
+	// children are only created after their parent, so they can't be checked for until messages arrive
+	if !r.pipe.SendTo(r.path+"/"+to, msg) {
+		return nil, NewError(CRITICAL, r.path, fmt.Sprintf("router error (there's no child named %s)", to), msg.Data)
+	}
+	return nil, nil
+}
+
+func (rt route) matches(msg *message.Msg) bool {
+	if rt.op != message.Noop && rt.op != msg.Op {
+		return false
+	}
+	if rt.ns != nil {
+		if _, ok := rt.ns.match(msg.Namespace); !ok {
+			return false
+		}
+	}
+	if rt.fn != nil {
+		return msg.IsMap() && rt.fn(msg.Map())
+	}
+	return true
+}
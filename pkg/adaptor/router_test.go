@@ -0,0 +1,157 @@
+package adaptor
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// testRouterChild collects the ids of the messages a child of the router receives
+type testRouterChild struct {
+	sync.Mutex
+	pipe *pipe.Pipe
+	ids  []interface{}
+}
+
+func (c *testRouterChild) received() []interface{} {
+	c.Lock()
+	defer c.Unlock()
+	return append([]interface{}(nil), c.ids...)
+}
+
+func newTestRouter(t *testing.T, conf Config, children ...string) (*pipe.Pipe, *Router, map[string]*testRouterChild) {
+	source := pipe.NewPipe(nil, "source")
+	p := pipe.NewPipe(source, "source/router")
+	r, err := NewRouter(p, "source/router", conf)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	collected := make(map[string]*testRouterChild)
+	for _, name := range children {
+		c := &testRouterChild{pipe: pipe.NewPipe(p, "source/router/"+name)}
+		go c.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+			c.Lock()
+			defer c.Unlock()
+			if msg.Op == message.Command {
+				c.ids = append(c.ids, "command")
+			} else {
+				c.ids = append(c.ids, msg.Map()["_id"])
+			}
+			return msg, nil
+		}, regexp.MustCompile(`.*`))
+		collected[name] = c
+	}
+	go r.Listen()
+	return source, r.(*Router), collected
+}
+
+func TestRouter(t *testing.T) {
+	source, r, children := newTestRouter(t, Config{
+		"namespace": "db./.*/",
+		"routes": []interface{}{
+			map[string]interface{}{"to": "users", "match": map[string]interface{}{"type": "user"}},
+			map[string]interface{}{"to": "orders", "match": map[string]interface{}{"type": "order"}},
+			map[string]interface{}{"to": "deletes", "op": "delete"},
+			map[string]interface{}{"to": "audit", "namespace": "/db\\.audit_.*/"},
+		},
+		"default": "others",
+	}, "users", "orders", "deletes", "audit", "others")
+
+	data := []*message.Msg{
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": 1, "type": "user"}, "db.things"),
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": 2, "type": "order"}, "db.things"),
+		message.NewMsg(message.Delete, map[string]interface{}{"_id": 3, "type": "user"}, "db.things"), // the first match wins
+		message.NewMsg(message.Delete, map[string]interface{}{"_id": 4}, "db.things"),
+		message.NewMsg(message.Update, map[string]interface{}{"_id": 5}, "db.audit_2016"),
+		message.NewMsg(message.Insert, map[string]interface{}{"_id": 6, "type": "other"}, "db.things"),
+		message.NewMsg(message.Command, map[string]interface{}{"flush": true}, "db.things"),
+	}
+	for _, msg := range data {
+		source.Send(msg)
+	}
+
+	expected := map[string][]interface{}{
+		"users":   {1, 3, "command"},
+		"orders":  {2, "command"},
+		"deletes": {4, "command"},
+		"audit":   {5, "command"},
+		"others":  {6, "command"},
+	}
+	for name, ids := range expected {
+		for i := 0; i < 100 && len(children[name].received()) < len(ids); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	r.Stop()
+	for name, ids := range expected {
+		got := children[name].received()
+		if len(got) != len(ids) {
+			t.Errorf("[%s] expected %v, got %v", name, ids, got)
+			continue
+		}
+		for i := range ids {
+			if got[i] != ids[i] {
+				t.Errorf("[%s] expected %v, got %v", name, ids, got)
+				break
+			}
+		}
+	}
+}
+
+func TestRouterDropsUnmatched(t *testing.T) {
+	source, r, children := newTestRouter(t, Config{
+		"namespace": "db./.*/",
+		"routes":    []interface{}{map[string]interface{}{"to": "users", "match": map[string]interface{}{"type": "user"}}},
+	}, "users", "others")
+
+	source.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": 1, "type": "order"}, "db.things"))
+	source.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": 2, "type": "user"}, "db.things"))
+	for i := 0; i < 100 && len(children["users"].received()) < 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Stop()
+
+	if got := children["users"].received(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected [2], got %v", got)
+	}
+	if got := children["others"].received(); len(got) != 0 {
+		t.Errorf("expected nothing to be sent to others, got %v", got)
+	}
+}
+
+func TestRouterMissingChild(t *testing.T) {
+	source := pipe.NewPipe(nil, "source")
+	p := pipe.NewPipe(source, "source/router")
+	r, err := NewRouter(p, "source/router", Config{
+		"namespace": "db./.*/",
+		"routes":    []interface{}{map[string]interface{}{"to": "nowhere"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	_, err = r.(*Router).routeOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": 1}, "db.things"))
+	if e, ok := err.(Error); !ok || e.Lvl != CRITICAL {
+		t.Errorf("expected a CRITICAL error, got %v", err)
+	}
+}
+
+func TestRouterBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "db./.*/"},
+		{"namespace": "db./.*/", "routes": []interface{}{map[string]interface{}{"op": "insert"}}},
+		{"namespace": "db./.*/", "routes": []interface{}{map[string]interface{}{"to": "a", "op": "bogus"}}},
+		{"namespace": "db./.*/", "routes": []interface{}{map[string]interface{}{"to": "a", "namespace": "/db.(/"}}},
+		{"namespace": "db./.*/", "routes": []interface{}{map[string]interface{}{"to": "a", "match": map[string]interface{}{"$where": "1"}}}},
+	}
+	for _, conf := range data {
+		if _, err := NewRouter(pipe.NewPipe(nil, "path"), "path", conf); err == nil {
+			t.Errorf("[%v] expected an error", conf)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package adaptor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Serializer turns a message's document into the bytes a sink writes
+type Serializer interface {
+	Serialize(*message.Msg) ([]byte, error)
+}
+
+// newSerializer returns the serializer for the format, json if the format isn't set
+func newSerializer(format string) (Serializer, error) {
+	switch format {
+	case "", "json":
+		return jsonSerializer{}, nil
+	case "ndjson":
+		return ndjsonSerializer{}, nil
+	case "msgpack":
+		return msgpackSerializer{}, nil
+	case "bson":
+		return bsonSerializer{}, nil
+	}
+	return nil, fmt.Errorf("unknown format (%s), must be json, ndjson, msgpack or bson", format)
+}
+
+// jsonSerializer writes the document as a json document
+type jsonSerializer struct{}
+
+func (jsonSerializer) Serialize(msg *message.Msg) ([]byte, error) {
+	return json.Marshal(msg.Data)
+}
+
+// ndjsonSerializer writes the document as a line of json, so that a stream of them is newline delimited json
+type ndjsonSerializer struct{}
+
+func (ndjsonSerializer) Serialize(msg *message.Msg) ([]byte, error) {
+	ba, err := json.Marshal(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	return append(ba, '\n'), nil
+}
+
+// bsonSerializer writes the document as bson, which like mongodump's files can simply be concatenated
+type bsonSerializer struct{}
+
+func (bsonSerializer) Serialize(msg *message.Msg) ([]byte, error) {
+	if !msg.IsMap() {
+		return nil, fmt.Errorf("bson can only hold documents, got %T", msg.Data)
+	}
+	return bson.Marshal(msg.Map())
+}
+
+// msgpackSerializer writes the document as MessagePack.  A document's keys are sorted, so it always serializes
+// the same way.  Times use the timestamp extension, object ids are written as their hex strings
+type msgpackSerializer struct{}
+
+func init() {
+	msgpack.Register(bson.ObjectId(""), func(e *msgpack.Encoder, v reflect.Value) error {
+		return e.EncodeString(v.Interface().(bson.ObjectId).Hex())
+	}, nil)
+	msgpack.Register(json.Number(""), func(e *msgpack.Encoder, v reflect.Value) error {
+		n := v.Interface().(json.Number)
+		if i, err := n.Int64(); err == nil {
+			return e.EncodeInt(i)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return err
+		}
+		return e.EncodeFloat64(f)
+	}, nil)
+}
+
+func (msgpackSerializer) Serialize(msg *message.Msg) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(msg.Data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,191 @@
+package adaptor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	serializerTime = time.Date(2016, 2, 29, 12, 30, 15, 500000000, time.UTC)
+
+	serializerDoc = map[string]interface{}{
+		"_id":     bson.ObjectIdHex("56d43c4b3d2d53f1f7a68202"),
+		"name":    "transporter",
+		"count":   int64(42),
+		"neg":     int64(-1000),
+		"big":     int64(1) << 40,
+		"price":   9.99,
+		"active":  true,
+		"missing": nil,
+		"tags":    []interface{}{"a", "b"},
+		"nested":  map[string]interface{}{"level": int64(2)},
+		"created": serializerTime,
+	}
+)
+
+func TestJSONSerializers(t *testing.T) {
+	for _, format := range []string{"", "json", "ndjson"} {
+		s, err := newSerializer(format)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error, %s", format, err)
+		}
+		ba, err := s.Serialize(message.NewMsg(message.Insert, serializerDoc, "db.coll"))
+		if err != nil {
+			t.Fatalf("[%s] unexpected error, %s", format, err)
+		}
+		if newline := bytes.HasSuffix(ba, []byte("\n")); newline != (format == "ndjson") {
+			t.Errorf("[%s] expected a trailing newline only for ndjson, got %q", format, ba)
+		}
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(ba, &got); err != nil {
+			t.Fatalf("[%s] can't unmarshal %s, %s", format, ba, err)
+		}
+		expected := map[string]interface{}{
+			"_id": "56d43c4b3d2d53f1f7a68202", "name": "transporter", "count": float64(42), "neg": float64(-1000),
+			"big": float64(1 << 40), "price": 9.99, "active": true, "missing": nil, "tags": []interface{}{"a", "b"},
+			"nested": map[string]interface{}{"level": float64(2)}, "created": "2016-02-29T12:30:15.5Z",
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("[%s] expected %v, got %v", format, expected, got)
+		}
+	}
+}
+
+func TestBSONSerializer(t *testing.T) {
+	s, _ := newSerializer("bson")
+	ba, err := s.Serialize(message.NewMsg(message.Insert, serializerDoc, "db.coll"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	var got bson.M
+	if err := bson.Unmarshal(ba, &got); err != nil {
+		t.Fatalf("can't unmarshal, %s", err)
+	}
+	for k, v := range serializerDoc {
+		g := got[k]
+		switch g := g.(type) {
+		case bson.M:
+			got[k] = map[string]interface{}(g)
+		case time.Time:
+			got[k] = g.UTC()
+		}
+		if !reflect.DeepEqual(got[k], v) {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", k, v, v, g, g)
+		}
+	}
+
+	if _, err := s.Serialize(message.NewMsg(message.Insert, "not a document", "db.coll")); err == nil {
+		t.Error("expected an error serializing a string as bson")
+	}
+}
+
+func TestMsgpackSerializer(t *testing.T) {
+	s, _ := newSerializer("msgpack")
+	ba, err := s.Serialize(message.NewMsg(message.Insert, serializerDoc, "db.coll"))
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	got, err := msgpackDecode(ba)
+	if err != nil {
+		t.Fatalf("can't decode %x, %s", ba, err)
+	}
+	if doc, ok := got.(map[string]interface{}); ok {
+		doc["created"] = doc["created"].(time.Time).UTC()
+	}
+	expected := map[string]interface{}{}
+	for k, v := range serializerDoc {
+		expected[k] = v
+	}
+	expected["_id"] = "56d43c4b3d2d53f1f7a68202"
+	expected["big"] = uint64(1) << 40
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	again, _ := s.Serialize(message.NewMsg(message.Insert, serializerDoc, "db.coll"))
+	if !bytes.Equal(ba, again) {
+		t.Error("expected the same document to serialize the same way every time")
+	}
+}
+
+func TestMsgpackValues(t *testing.T) {
+	s, _ := newSerializer("msgpack")
+	data := []struct {
+		in       interface{}
+		expected interface{}
+	}{
+		{0, int64(0)},
+		{127, int64(127)},
+		{-32, int64(-32)},
+		{-33, int64(-33)},
+		{int32(200), uint64(200)}, // positive integers past the fixint range are written unsigned
+		{-40000, int64(-40000)},
+		{int64(math.MaxInt64), uint64(math.MaxInt64)},
+		{int64(math.MinInt64), int64(math.MinInt64)},
+		{float32(1.5), 1.5},
+		{json.Number("12"), int64(12)},
+		{json.Number("1.25"), 1.25},
+		{[]string{"x", "y"}, []interface{}{"x", "y"}},
+		{map[string]int{"a": 1}, map[string]interface{}{"a": int64(1)}},
+		{[]byte{1, 2, 3}, "\x01\x02\x03"}, // the loose decoder reads binary as a string
+		{string(make([]byte, 300)), string(make([]byte, 300))},
+		{time.Unix(-1, 0).UTC(), time.Unix(-1, 0).UTC()},
+	}
+
+	for _, v := range data {
+		ba, err := s.Serialize(message.NewMsg(message.Insert, v.in, "db.coll"))
+		if err != nil {
+			t.Errorf("[%v] unexpected error, %s", v.in, err)
+			continue
+		}
+		got, err := msgpackDecode(ba)
+		if err != nil {
+			t.Errorf("[%v] can't decode %x, %v", v.in, ba, err)
+			continue
+		}
+		if tm, ok := got.(time.Time); ok {
+			got = tm.UTC()
+		}
+		if !reflect.DeepEqual(got, v.expected) {
+			t.Errorf("[%v] expected %v (%T), got %v (%T)", v.in, v.expected, v.expected, got, got)
+		}
+	}
+
+	if _, err := s.Serialize(message.NewMsg(message.Insert, make(chan int), "db.coll")); err == nil {
+		t.Error("expected an error serializing a channel")
+	}
+}
+
+func TestNewSerializerBadFormat(t *testing.T) {
+	if _, err := newSerializer("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+// msgpackDecode reads the document back with the msgpack library's loose decoder, which reads numbers as
+// int64, uint64 or float64
+func msgpackDecode(b []byte) (interface{}, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(b))
+	dec.UseLooseInterfaceDecoding(true)
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.DecodeInterface(); err != io.EOF {
+		return nil, fmt.Errorf("expected the whole document to be decoded, got %v", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,96 @@
+//go:build !slim || mongo
+// +build !slim mongo
+
+package adaptor
+
+import (
+	"strings"
+	"sync"
+
+	"gopkg.in/mgo.v2"
+)
+
+// mongoSessions holds the sessions dialed by mongo nodes, so that nodes connecting with the same parameters,
+// i.e. a pipeline with a source or sink per collection, share one connection pool rather than each dialing
+// their own.  Each node works with a copy of the shared session, so its write concern and batch settings are
+// its own, and the shared session is closed when the last node using it stops
+var mongoSessions = newSessionPool()
+
+// sessionCloser is a connection that can be shared, *mgo.Session is one
+type sessionCloser interface {
+	Close()
+}
+
+// sessionPool reference counts shared connections by key
+type sessionPool struct {
+	sync.Mutex
+	sessions map[string]*pooledSession
+}
+
+type pooledSession struct {
+	session sessionCloser
+	refs    int
+}
+
+func newSessionPool() *sessionPool {
+	return &sessionPool{sessions: make(map[string]*pooledSession)}
+}
+
+// acquire returns the session for the key, calling dial to connect if no one holds it.  Every successful
+// acquire must be matched by a release
+func (p *sessionPool) acquire(key string, dial func() (sessionCloser, error)) (sessionCloser, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if s, ok := p.sessions[key]; ok {
+		s.refs++
+		return s.session, nil
+	}
+
+	// dialing with the lock held means nodes starting together wait for the one connection
+	session, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	p.sessions[key] = &pooledSession{session: session, refs: 1}
+	return session, nil
+}
+
+// release gives up a reference to the key's session, closing it if that was the last one
+func (p *sessionPool) release(key string) {
+	p.Lock()
+	defer p.Unlock()
+
+	s, ok := p.sessions[key]
+	if !ok {
+		return
+	}
+	if s.refs--; s.refs == 0 {
+		delete(p.sessions, key)
+		s.session.Close()
+	}
+}
+
+// mongoSessionKey identifies the parameters a mongo session is dialed with, everything else is set per node
+func mongoSessionKey(conf MongodbConfig) string {
+	key := []string{conf.URI, conf.Timeout}
+	if conf.Ssl != nil {
+		key = append(key, "ssl")
+		key = append(key, conf.Ssl.CaCerts...)
+	}
+	if t := conf.SSHTunnel; t != nil {
+		key = append(key, "sshtunnel", t.Host, t.User, t.Key, t.Remote)
+	}
+	return strings.Join(key, "\x00")
+}
+
+// tunneledSession is a session whose servers are dialed through an ssh tunnel, which is closed with it
+type tunneledSession struct {
+	*mgo.Session
+	tunnel *sshTunnel
+}
+
+func (s *tunneledSession) Close() {
+	s.Session.Close()
+	s.tunnel.Close()
+}
@@ -0,0 +1,86 @@
+//go:build !slim || mongo
+// +build !slim mongo
+
+package adaptor
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testSession struct {
+	closed int
+}
+
+func (s *testSession) Close() {
+	s.closed++
+}
+
+func TestSessionPoolShares(t *testing.T) {
+	p := newSessionPool()
+	dials := 0
+	dial := func() (sessionCloser, error) {
+		dials++
+		return &testSession{}, nil
+	}
+
+	key := mongoSessionKey(MongodbConfig{URI: "mongodb://localhost/test", Namespace: "test.a"})
+	first, err := p.acquire(key, dial)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	other := mongoSessionKey(MongodbConfig{URI: "mongodb://localhost/test", Namespace: "test.b"})
+	second, err := p.acquire(other, dial)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if dials != 1 || first != second {
+		t.Fatalf("expected nodes with the same uri to share one session, dialed %d times", dials)
+	}
+
+	session := first.(*testSession)
+	p.release(key)
+	if session.closed != 0 {
+		t.Error("expected the session to stay open while a node is using it")
+	}
+	p.release(other)
+	if session.closed != 1 {
+		t.Errorf("expected the session to be closed once after the last node stopped, closed %d times", session.closed)
+	}
+	p.release(key) // releasing again does nothing
+	if session.closed != 1 {
+		t.Errorf("expected the session to be closed once, closed %d times", session.closed)
+	}
+
+	if third, _ := p.acquire(key, dial); dials != 2 || third == first {
+		t.Error("expected a new session to be dialed after the last one was closed")
+	}
+}
+
+func TestSessionPoolDialError(t *testing.T) {
+	p := newSessionPool()
+	if _, err := p.acquire("key", func() (sessionCloser, error) { return nil, fmt.Errorf("no reachable servers") }); err == nil {
+		t.Fatal("expected the dial error")
+	}
+	dialed := false
+	if _, err := p.acquire("key", func() (sessionCloser, error) { dialed = true; return &testSession{}, nil }); err != nil || !dialed {
+		t.Errorf("expected a failed dial not to be shared, got %v", err)
+	}
+}
+
+func TestMongoSessionKey(t *testing.T) {
+	data := []struct {
+		a, b MongodbConfig
+		same bool
+	}{
+		{MongodbConfig{URI: "mongodb://a/db"}, MongodbConfig{URI: "mongodb://a/db", Bulk: true, Tail: true}, true},
+		{MongodbConfig{URI: "mongodb://a/db"}, MongodbConfig{URI: "mongodb://b/db"}, false},
+		{MongodbConfig{URI: "mongodb://a/db"}, MongodbConfig{URI: "mongodb://a/db", Timeout: "1s"}, false},
+		{MongodbConfig{URI: "mongodb://a/db"}, MongodbConfig{URI: "mongodb://a/db", Ssl: &SslConfig{}}, false},
+	}
+	for _, v := range data {
+		if same := mongoSessionKey(v.a) == mongoSessionKey(v.b); same != v.same {
+			t.Errorf("[%+v, %+v] expected same to be %t", v.a, v.b, v.same)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package adaptor
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// awsCredentials are the keys requests are signed with, the session token is only set for temporary credentials
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// sigV4Transport is an http.RoundTripper that signs requests with AWS Signature Version 4, as AWS managed
+// OpenSearch and Elasticsearch domains require
+type sigV4Transport struct {
+	next        http.RoundTripper
+	credentials awsCredentials
+	region      string
+	service     string
+	signer      *v4.Signer
+	now         func() time.Time // swapped out in tests
+}
+
+func newSigV4Transport(next http.RoundTripper, credentials awsCredentials, region, service string) *sigV4Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	// s3 signs the path as it's sent, every other service escapes it a second time
+	signer := v4.NewSigner(func(o *v4.SignerOptions) {
+		o.DisableURIPathEscaping = service == "s3"
+	})
+	return &sigV4Transport{next: next, credentials: credentials, region: region, service: service, signer: signer, now: time.Now}
+}
+
+// NewSigV4Transport returns a RoundTripper signing requests to the AWS service in the region with the
+// credentials found the way resolveAWSCredentials finds them, for requests made outside the adaptors,
+// i.e. to read the config from s3
+func NewSigV4Transport(next http.RoundTripper, region, service string) (http.RoundTripper, error) {
+	credentials, err := resolveAWSCredentials(awsCredentials{}, "")
+	if err != nil {
+		return nil, err
+	}
+	return newSigV4Transport(next, credentials, region, service), nil
+}
+
+func (t *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the signature covers the body, so it's read here and replaced
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	// a RoundTripper mustn't change the request it's given, so the headers are set, and the query
+	// re-encoded, on a copy
+	r := new(http.Request)
+	*r = *req
+	u := *req.URL
+	r.URL = &u
+	r.Header = make(http.Header, len(req.Header)+4)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	// the signer sets X-Amz-Date, X-Amz-Security-Token and Authorization, the payload hash header is
+	// only required by s3 but the other services accept it too
+	payloadHash := hashHex(body)
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	credentials := aws.Credentials{
+		AccessKeyID:     t.credentials.AccessKeyID,
+		SecretAccessKey: t.credentials.SecretAccessKey,
+		SessionToken:    t.credentials.SessionToken,
+	}
+	if err := t.signer.SignHTTP(r.Context(), credentials, r, payloadHash, t.service, t.region, t.now()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(r)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveAWSCredentials finds the credentials to sign with, the way the AWS tools do: the configured keys,
+// then the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables, then the
+// profile (or AWS_PROFILE, or default) in the shared credentials file.  Instance roles aren't looked up
+func resolveAWSCredentials(configured awsCredentials, profile string) (awsCredentials, error) {
+	if configured.AccessKeyID != "" || configured.SecretAccessKey != "" {
+		if configured.AccessKeyID == "" || configured.SecretAccessKey == "" {
+			return configured, fmt.Errorf("both the access key id and secret access key must be set")
+		}
+		return configured, nil
+	}
+
+	env := awsCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if env.AccessKeyID != "" && env.SecretAccessKey != "" {
+		return env, nil
+	}
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		file = filepath.Join(os.Getenv("HOME"), ".aws", "credentials")
+	}
+	shared, err := readSharedCredentials(file, profile)
+	if err != nil {
+		return shared, fmt.Errorf("no aws credentials found (%s)", err.Error())
+	}
+	return shared, nil
+}
+
+// readSharedCredentials reads the profile's keys from an ini formatted credentials file
+func readSharedCredentials(file, profile string) (awsCredentials, error) {
+	var creds awsCredentials
+	f, err := os.Open(file)
+	if err != nil {
+		return creds, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+		case section == profile:
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v := strings.TrimSpace(kv[1])
+			switch strings.TrimSpace(kv[0]) {
+			case "aws_access_key_id":
+				creds.AccessKeyID = v
+			case "aws_secret_access_key":
+				creds.SecretAccessKey = v
+			case "aws_session_token":
+				creds.SessionToken = v
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return creds, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return creds, fmt.Errorf("profile %s in %s has no keys", profile, file)
+	}
+	return creds, nil
+}
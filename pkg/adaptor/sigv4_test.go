@@ -0,0 +1,106 @@
+package adaptor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper calling the function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestSigV4Transport(t *testing.T) {
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", SessionToken: "token"}
+	var sent *http.Request
+	var body string
+	signer := newSigV4Transport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent = r
+		ba, _ := ioutil.ReadAll(r.Body)
+		body = string(ba)
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}), creds, "us-east-1", "es")
+	signer.now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+
+	req, _ := http.NewRequest("POST", "https://example.amazonaws.com/app/_bulk?refresh=wait_for", strings.NewReader("{}\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if _, err := signer.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/es/aws4_request, SignedHeaders=content-length;content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature="
+	if auth := sent.Header.Get("Authorization"); !strings.HasPrefix(auth, expected) {
+		t.Errorf("expected %s..., got %s", expected, auth)
+	}
+	for k, v := range map[string]string{"X-Amz-Date": "20150830T123600Z", "X-Amz-Security-Token": "token", "X-Amz-Content-Sha256": hashHex([]byte("{}\n"))} {
+		if sent.Header.Get(k) != v {
+			t.Errorf("expected %s %s, got %s", k, v, sent.Header.Get(k))
+		}
+	}
+	if body != "{}\n" {
+		t.Errorf("expected the body to be sent, got %q", body)
+	}
+	if req.Header.Get("Authorization") != "" || req.URL.RawQuery != "refresh=wait_for" {
+		t.Errorf("expected the request to be left alone, got %v %s", req.Header, req.URL)
+	}
+}
+
+func TestResolveAWSCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transporter")
+	if err != nil {
+		t.Fatalf("can't create a temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "credentials")
+	ioutil.WriteFile(file, []byte("[default]\naws_access_key_id = DEFAULTKEY\naws_secret_access_key = defaultsecret\n\n# another\n[other]\naws_access_key_id=OTHERKEY\naws_secret_access_key=othersecret\naws_session_token=othertoken\n"), 0600)
+
+	for _, k := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE"} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Unsetenv(k)
+	}
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", file)
+
+	data := []struct {
+		configured awsCredentials
+		profile    string
+		env        map[string]string
+		expected   awsCredentials
+	}{
+		{awsCredentials{AccessKeyID: "CONFKEY", SecretAccessKey: "confsecret"}, "", map[string]string{"AWS_ACCESS_KEY_ID": "ENVKEY", "AWS_SECRET_ACCESS_KEY": "envsecret"}, awsCredentials{AccessKeyID: "CONFKEY", SecretAccessKey: "confsecret"}},
+		{awsCredentials{}, "", map[string]string{"AWS_ACCESS_KEY_ID": "ENVKEY", "AWS_SECRET_ACCESS_KEY": "envsecret", "AWS_SESSION_TOKEN": "envtoken"}, awsCredentials{AccessKeyID: "ENVKEY", SecretAccessKey: "envsecret", SessionToken: "envtoken"}},
+		{awsCredentials{}, "", nil, awsCredentials{AccessKeyID: "DEFAULTKEY", SecretAccessKey: "defaultsecret"}},
+		{awsCredentials{}, "other", nil, awsCredentials{AccessKeyID: "OTHERKEY", SecretAccessKey: "othersecret", SessionToken: "othertoken"}},
+		{awsCredentials{}, "", map[string]string{"AWS_PROFILE": "other"}, awsCredentials{AccessKeyID: "OTHERKEY", SecretAccessKey: "othersecret", SessionToken: "othertoken"}},
+	}
+	for _, v := range data {
+		for k, val := range v.env {
+			os.Setenv(k, val)
+		}
+		creds, err := resolveAWSCredentials(v.configured, v.profile)
+		if err != nil || creds != v.expected {
+			t.Errorf("[%v %s %v] expected %+v, got %+v (%v)", v.configured, v.profile, v.env, v.expected, creds, err)
+		}
+		for k := range v.env {
+			os.Unsetenv(k)
+		}
+	}
+
+	for _, v := range []struct {
+		configured awsCredentials
+		profile    string
+	}{
+		{awsCredentials{AccessKeyID: "CONFKEY"}, ""},
+		{awsCredentials{}, "missing"},
+	} {
+		if _, err := resolveAWSCredentials(v.configured, v.profile); err == nil {
+			t.Errorf("[%v %s] expected an error", v.configured, v.profile)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package adaptor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// NewSplit creates a transformer that splits a string field on a delimiter into an array, i.e. {"tags": "a,b,c"}
+// becomes {"tags": ["a", "b", "c"]}, optionally trimming the whitespace around each element and dropping the
+// empty ones.  A field that's already an array is left as it is, other values are handled by the nonstring
+// policy, and missing and null fields by the missing policy
+func NewSplit(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf SplitConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if conf.Field == "" {
+		return nil, NewError(CRITICAL, path, "split config must contain a field", nil)
+	}
+
+	s := &splitter{field: conf.Field, target: conf.Target, delimiter: conf.Delimiter, trim: conf.Trim, dropEmpty: conf.DropEmpty, nonString: conf.NonString, missing: conf.Missing}
+	if s.target == "" {
+		s.target = s.field
+	}
+	if s.delimiter == "" {
+		s.delimiter = ","
+	}
+	switch s.nonString {
+	case "":
+		s.nonString = "pass"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown nonstring policy (%s), must be pass, drop or error", s.nonString), nil)
+	}
+	switch s.missing {
+	case "":
+		s.missing = "pass"
+	case "pass", "empty", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be pass, empty, drop or error", s.missing), nil)
+	}
+
+	return newDocTransformer("split", p, path, extra, s.apply)
+}
+
+// SplitConfig provides configuration options for the split transformer
+type SplitConfig struct {
+	Namespace string `json:"namespace" doc:"the set of namespaces to transform"`
+	Field     string `json:"field" doc:"the dotted path of the string field to split"`
+	Target    string `json:"target" doc:"the dotted path of the field to set to the array, defaults to the field itself"`
+	Delimiter string `json:"delimiter" doc:"the delimiter to split on, defaults to ,"`
+	Trim      bool   `json:"trim" doc:"trim the whitespace around each element"`
+	DropEmpty bool   `json:"dropempty" doc:"drop the empty elements, after trimming"`
+	NonString string `json:"nonstring" doc:"what to do when the field is neither a string or an array, pass (leave it as it is, the default), drop or error"`
+	Missing   string `json:"missing" doc:"what to do when the field is missing or null, pass (leave it as it is, the default), empty (set the target to an empty array), drop or error"`
+}
+
+type splitter struct {
+	field     string
+	target    string
+	delimiter string
+	trim      bool
+	dropEmpty bool
+	nonString string
+	missing   string
+}
+
+func (s *splitter) apply(msg *message.Msg, doc map[string]interface{}) error {
+	v, ok := getField(doc, s.field)
+	if !ok || v == nil {
+		switch s.missing {
+		case "empty":
+			return setField(doc, s.target, []interface{}{})
+		case "drop":
+			msg.Op = message.Noop
+		case "error":
+			return fmt.Errorf("%s is missing", s.field)
+		}
+		return nil
+	}
+
+	str, isString := v.(string)
+	if !isString {
+		if _, isArray := asSlice(v); isArray {
+			return nil // already split
+		}
+		switch s.nonString {
+		case "drop":
+			msg.Op = message.Noop
+		case "error":
+			return fmt.Errorf("%s is a %T, not a string", s.field, v)
+		}
+		return nil
+	}
+
+	elems := []interface{}{}
+	for _, e := range strings.Split(str, s.delimiter) {
+		if s.trim {
+			e = strings.TrimSpace(e)
+		}
+		if s.dropEmpty && e == "" {
+			continue
+		}
+		elems = append(elems, e)
+	}
+	return setField(doc, s.target, elems)
+}
@@ -0,0 +1,137 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestSplit(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"field": "tags"},
+			map[string]interface{}{"_id": "1", "tags": "a,b,c"},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a", "b", "c"}},
+			false,
+		},
+		{
+			// whitespace and empty elements are kept unless asked otherwise
+			Config{"field": "tags", "delimiter": ";"},
+			map[string]interface{}{"_id": "1", "tags": " a; b;;c,d "},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{" a", " b", "", "c,d "}},
+			false,
+		},
+		{
+			Config{"field": "tags", "delimiter": ";", "trim": true, "dropempty": true},
+			map[string]interface{}{"_id": "1", "tags": " a; b;  ;c,d "},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a", "b", "c,d"}},
+			false,
+		},
+		{
+			Config{"field": "tags", "dropempty": true},
+			map[string]interface{}{"_id": "1", "tags": ""},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{}},
+			false,
+		},
+		{
+			// a multi character delimiter, into another field
+			Config{"field": "meta.path", "delimiter": " > ", "target": "meta.crumbs"},
+			map[string]interface{}{"_id": "1", "meta": map[string]interface{}{"path": "home > shoes > boots"}},
+			map[string]interface{}{"_id": "1", "meta": map[string]interface{}{"path": "home > shoes > boots", "crumbs": []interface{}{"home", "shoes", "boots"}}},
+			false,
+		},
+		{
+			// arrays have already been split, and other values pass by default
+			Config{"field": "tags"},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a", "b"}},
+			false,
+		},
+		{
+			Config{"field": "tags"},
+			map[string]interface{}{"_id": "1", "tags": 7},
+			map[string]interface{}{"_id": "1", "tags": 7},
+			false,
+		},
+		{
+			Config{"field": "tags", "nonstring": "drop"},
+			map[string]interface{}{"_id": "1", "tags": 7},
+			nil,
+			false,
+		},
+		{
+			Config{"field": "tags", "nonstring": "error"},
+			map[string]interface{}{"_id": "1", "tags": 7},
+			nil,
+			true,
+		},
+		{
+			Config{"field": "tags"},
+			map[string]interface{}{"_id": "1", "tags": nil},
+			map[string]interface{}{"_id": "1", "tags": nil},
+			false,
+		},
+		{
+			Config{"field": "tags", "missing": "empty"},
+			map[string]interface{}{"_id": "1"},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{}},
+			false,
+		},
+		{
+			Config{"field": "tags", "missing": "drop"},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			false,
+		},
+		{
+			Config{"field": "tags", "missing": "error"},
+			map[string]interface{}{"_id": "1"},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "split", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.conf, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%v: expected an ERROR, got %v", v.conf, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestSplitBadConfig(t *testing.T) {
+	data := []Config{
+		{},
+		{"field": "tags", "nonstring": "stringify"},
+		{"field": "tags", "missing": "skip"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewSplit(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
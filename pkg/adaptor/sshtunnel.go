@@ -0,0 +1,194 @@
+package adaptor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHTunnelConfig configures an ssh tunnel through a bastion, for databases that can't be reached directly
+type SSHTunnelConfig struct {
+	Host                  string `json:"host" doc:"the bastion to tunnel through, as host:port, the port defaults to 22"`
+	User                  string `json:"user" doc:"the user to log in to the bastion as"`
+	Key                   string `json:"key" doc:"the private key file to authenticate with, an unencrypted key in openssh or pem format"`
+	Password              string `json:"password" doc:"the password to authenticate with, tried after the key if both are set"`
+	HostKey               string `json:"hostkey" doc:"the bastion's host key, either its SHA256 fingerprint as ssh-keygen -l shows it, or its public key as it appears in known_hosts"`
+	InsecureIgnoreHostKey bool   `json:"insecureignorehostkey" doc:"accept whatever host key the bastion presents, leaving the tunnel open to a man in the middle"`
+	Remote                string `json:"remote" doc:"the host:port the bastion connects to, defaults to the address the database client dials"`
+}
+
+// sshTunnel dials connections through an ssh server.  The ssh connection is made by the first Dial, and
+// made again by a Dial after it's been lost, so a client that reconnects to its database reconnects the tunnel
+type sshTunnel struct {
+	addr   string
+	remote string
+	conf   *ssh.ClientConfig
+
+	sync.Mutex
+	client *ssh.Client
+	closed bool
+}
+
+// newSSHTunnel checks the tunnel's config and reads its key, nothing is dialed until the tunnel is used
+func newSSHTunnel(c *SSHTunnelConfig, timeout time.Duration) (*sshTunnel, error) {
+	if c.Host == "" || c.User == "" {
+		return nil, fmt.Errorf("an ssh tunnel requires both a host and a user")
+	}
+	if c.Key == "" && c.Password == "" {
+		return nil, fmt.Errorf("an ssh tunnel requires a key or a password")
+	}
+
+	t := &sshTunnel{addr: c.Host, remote: c.Remote, conf: &ssh.ClientConfig{User: c.User, Timeout: timeout}}
+	if _, _, err := net.SplitHostPort(t.addr); err != nil {
+		t.addr = net.JoinHostPort(t.addr, "22")
+	}
+	if t.remote != "" {
+		if _, _, err := net.SplitHostPort(t.remote); err != nil {
+			return nil, fmt.Errorf("the ssh tunnel's remote must be host:port, got %s", t.remote)
+		}
+	}
+
+	switch {
+	case c.HostKey != "":
+		check, err := sshHostKeyCheck(c.HostKey)
+		if err != nil {
+			return nil, err
+		}
+		t.conf.HostKeyCallback = check
+	case c.InsecureIgnoreHostKey:
+		t.conf.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("an ssh tunnel requires the bastion's hostkey, or insecureignorehostkey")
+	}
+
+	if c.Key != "" {
+		ba, err := ioutil.ReadFile(c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("can't read the ssh key (%s)", err.Error())
+		}
+		signer, err := ssh.ParsePrivateKey(ba)
+		if err != nil {
+			return nil, fmt.Errorf("can't read the ssh key %s (%s)", c.Key, err.Error())
+		}
+		t.conf.Auth = append(t.conf.Auth, ssh.PublicKeys(signer))
+	}
+	if c.Password != "" {
+		t.conf.Auth = append(t.conf.Auth, ssh.Password(c.Password))
+	}
+	return t, nil
+}
+
+// Dial connects to addr, or to the tunnel's remote if it has one, through the ssh server
+func (t *sshTunnel) Dial(addr string) (net.Conn, error) {
+	client, err := t.connect()
+	if err != nil {
+		return nil, fmt.Errorf("can't reach the ssh tunnel %s (%s)", t.addr, err.Error())
+	}
+	if t.remote != "" {
+		addr = t.remote
+	}
+	channel, err := client.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("can't reach %s through the ssh tunnel %s (%s)", addr, t.addr, err.Error())
+	}
+	return newSSHConn(channel), nil
+}
+
+func (t *sshTunnel) connect() (*ssh.Client, error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.closed {
+		return nil, fmt.Errorf("the tunnel is closed")
+	}
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := ssh.Dial("tcp", t.addr, t.conf)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+	go func() {
+		client.Wait()
+		t.Lock()
+		if t.client == client {
+			t.client = nil
+		}
+		t.Unlock()
+	}()
+	return client, nil
+}
+
+// Close the ssh connection, and every connection made through it
+func (t *sshTunnel) Close() {
+	t.Lock()
+	defer t.Unlock()
+	t.closed = true
+	if t.client != nil {
+		t.client.Close()
+		t.client = nil
+	}
+}
+
+// sshConn is a connection through the tunnel.  ssh channels don't support deadlines, which the database
+// drivers time out with, so the channel is copied through a net.Pipe, which does
+type sshConn struct {
+	net.Conn
+	channel net.Conn
+}
+
+func newSSHConn(channel net.Conn) *sshConn {
+	local, remote := net.Pipe()
+	go func() {
+		io.Copy(channel, remote)
+		channel.Close()
+	}()
+	go func() {
+		io.Copy(remote, channel)
+		remote.Close()
+	}()
+	return &sshConn{Conn: local, channel: channel}
+}
+
+func (c *sshConn) Close() error {
+	c.channel.Close()
+	return c.Conn.Close()
+}
+
+func (c *sshConn) LocalAddr() net.Addr  { return c.channel.LocalAddr() }
+func (c *sshConn) RemoteAddr() net.Addr { return c.channel.RemoteAddr() }
+
+// sshHostKeyCheck returns a check that the server presents the configured key, which is a fingerprint or
+// a public key in authorized_keys or known_hosts format
+func sshHostKeyCheck(configured string) (ssh.HostKeyCallback, error) {
+	configured = strings.TrimSpace(configured)
+	if strings.HasPrefix(configured, "SHA256:") {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if got := ssh.FingerprintSHA256(key); got != strings.TrimRight(configured, "=") {
+				return fmt.Errorf("the server's host key %s isn't %s", got, configured)
+			}
+			return nil
+		}, nil
+	}
+
+	want, _, _, _, err := ssh.ParseAuthorizedKey([]byte(configured))
+	if err != nil {
+		_, _, want, _, _, err = ssh.ParseKnownHosts([]byte(configured))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the hostkey must be a SHA256 fingerprint or a public key, got %s", configured)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if !bytes.Equal(key.Marshal(), want.Marshal()) {
+			return fmt.Errorf("the server's host key %s isn't %s", ssh.FingerprintSHA256(key), ssh.FingerprintSHA256(want))
+		}
+		return nil
+	}, nil
+}
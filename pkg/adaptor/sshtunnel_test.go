@@ -0,0 +1,325 @@
+package adaptor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer is an ssh server that accepts a password, or the keys it's given, and forwards direct-tcpip
+// channels to the addresses in targets.  It records what each client does in events
+type testSSHServer struct {
+	net.Listener
+	hostKey ssh.Signer
+	conf    *ssh.ServerConfig
+	keys    []ssh.PublicKey
+	targets map[string]string
+
+	sync.Mutex
+	events []string
+}
+
+func newTestSSHServer(t *testing.T, targets map[string]string) *testSSHServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSSHServer{Listener: l, hostKey: hostKey, targets: targets}
+	s.conf = &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, fmt.Errorf("wrong password")
+			}
+			s.event("auth %s password", c.User())
+			return nil, nil
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			for _, k := range s.keys {
+				if bytes.Equal(k.Marshal(), key.Marshal()) {
+					s.event("auth %s publickey", c.User())
+					return nil, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	// rekey every MB, so that long transfers go through new key exchanges
+	s.conf.RekeyThreshold = 1 << 20
+	s.conf.AddHostKey(hostKey)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testSSHServer) event(format string, v ...interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, fmt.Sprintf(format, v...))
+}
+
+func (s *testSSHServer) recorded() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func (s *testSSHServer) serve(conn net.Conn) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, s.conf)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for ch := range channels {
+		if ch.ChannelType() != "direct-tcpip" {
+			ch.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		var open struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(ch.ExtraData(), &open); err != nil {
+			ch.Reject(ssh.ConnectionFailed, "bad request")
+			continue
+		}
+		addr := net.JoinHostPort(open.Host, fmt.Sprint(open.Port))
+		s.event("open %s", addr)
+		target, ok := s.targets[addr]
+		if !ok {
+			ch.Reject(ssh.ConnectionFailed, "no route to "+addr)
+			continue
+		}
+		go s.forward(ch, target)
+	}
+}
+
+// forward copies between the channel and the target, until either closes
+func (s *testSSHServer) forward(ch ssh.NewChannel, addr string) {
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		ch.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := ch.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		io.Copy(target, channel)
+		target.Close()
+	}()
+	io.Copy(channel, target)
+	channel.Close()
+}
+
+// newTestEchoServer echoes whatever it's sent
+func newTestEchoServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return l
+}
+
+// publicKey returns the ssh public key of a private key
+func publicKey(t *testing.T, key interface{}) ssh.PublicKey {
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.PublicKey()
+}
+
+func TestSSHTunnel(t *testing.T) {
+	echo := newTestEchoServer(t)
+	defer echo.Close()
+	server := newTestSSHServer(t, map[string]string{"db.internal:27017": echo.Addr().String()})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "sshtunnel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// an ed25519 key in the openssh format, as ssh-keygen writes it, and an rsa key in pem format
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(edKey, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "id_ed25519"), pem.EncodeToMemory(block), 0600)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ioutil.WriteFile(filepath.Join(dir, "id_rsa"), pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), 0600)
+	server.keys = []ssh.PublicKey{publicKey(t, edKey), publicKey(t, rsaKey)}
+
+	hostKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(server.hostKey.PublicKey())))
+	data := []struct {
+		conf  SSHTunnelConfig
+		event string
+	}{
+		{SSHTunnelConfig{User: "u", Password: "secret", HostKey: ssh.FingerprintSHA256(server.hostKey.PublicKey())}, "auth u password"},
+		{SSHTunnelConfig{User: "u", Key: filepath.Join(dir, "id_ed25519"), HostKey: "bastion " + hostKey}, "auth u publickey"},
+		{SSHTunnelConfig{User: "u", Key: filepath.Join(dir, "id_rsa"), HostKey: hostKey}, "auth u publickey"},
+	}
+
+	for _, v := range data {
+		server.Lock()
+		server.events = nil
+		server.Unlock()
+		v.conf.Host = server.Addr().String()
+		tunnel, err := newSSHTunnel(&v.conf, 5*time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", v.event, err)
+		}
+
+		conn, err := tunnel.Dial("db.internal:27017")
+		if err != nil {
+			t.Fatalf("%s: unexpected error, %s", v.event, err)
+		}
+		conn.Write([]byte("ping"))
+		buf := make([]byte, 4)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+			t.Errorf("%s: expected the echo of ping, got %q, %v", v.event, buf, err)
+		}
+		conn.Close()
+		tunnel.Close()
+
+		expected := []string{v.event, "open db.internal:27017"}
+		if got := server.recorded(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected events %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestSSHTunnelTransfer(t *testing.T) {
+	echo := newTestEchoServer(t)
+	defer echo.Close()
+	server := newTestSSHServer(t, map[string]string{"db.internal:27017": echo.Addr().String()})
+	defer server.Close()
+
+	tunnel, err := newSSHTunnel(&SSHTunnelConfig{Host: server.Addr().String(), User: "u", Password: "secret", InsecureIgnoreHostKey: true}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer tunnel.Close()
+	conn, err := tunnel.Dial("db.internal:27017")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	defer conn.Close()
+
+	// more than a window's worth, through several key exchanges
+	sent := make([]byte, 5<<20)
+	rand.Read(sent)
+	go conn.Write(sent)
+
+	conn.SetReadDeadline(time.Now().Add(20 * time.Second))
+	got := make([]byte, len(sent))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+	if !bytes.Equal(got, sent) {
+		t.Errorf("expected the echo to match what was sent")
+	}
+
+	// a deadline that's passed times out the read
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := conn.Read(got); err == nil || !err.(net.Error).Timeout() {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestSSHTunnelRejected(t *testing.T) {
+	server := newTestSSHServer(t, map[string]string{})
+	defer server.Close()
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	data := []struct {
+		conf SSHTunnelConfig
+		err  string
+	}{
+		{SSHTunnelConfig{User: "u", Password: "wrong", InsecureIgnoreHostKey: true}, "unable to authenticate"},
+		{SSHTunnelConfig{User: "u", Password: "secret", HostKey: ssh.FingerprintSHA256(publicKey(t, otherKey))}, "isn't"},
+		{SSHTunnelConfig{User: "u", Password: "secret", InsecureIgnoreHostKey: true}, "connect failed"},
+	}
+	for _, v := range data {
+		v.conf.Host = server.Addr().String()
+		tunnel, err := newSSHTunnel(&v.conf, 5*time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if _, err := tunnel.Dial("db.internal:27017"); err == nil || !strings.Contains(err.Error(), v.err) {
+			t.Errorf("expected an error containing %q, got %v", v.err, err)
+		}
+		tunnel.Close()
+	}
+}
+
+func TestSSHTunnelBadConfig(t *testing.T) {
+	data := []SSHTunnelConfig{
+		{User: "u", Password: "p", InsecureIgnoreHostKey: true},
+		{Host: "bastion", Password: "p", InsecureIgnoreHostKey: true},
+		{Host: "bastion", User: "u", InsecureIgnoreHostKey: true},
+		{Host: "bastion", User: "u", Password: "p"},
+		{Host: "bastion", User: "u", Password: "p", HostKey: "not a key"},
+		{Host: "bastion", User: "u", Password: "p", InsecureIgnoreHostKey: true, Remote: "db"},
+		{Host: "bastion", User: "u", Key: "/does/not/exist", InsecureIgnoreHostKey: true},
+	}
+	for _, conf := range data {
+		if _, err := newSSHTunnel(&conf, time.Second); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+
+	tunnel, err := newSSHTunnel(&SSHTunnelConfig{Host: "bastion", User: "u", Password: "p", InsecureIgnoreHostKey: true}, time.Second)
+	if err != nil || tunnel.addr != "bastion:22" {
+		t.Errorf("expected the port to default to 22, got %v, %v", tunnel, err)
+	}
+}
@@ -0,0 +1,127 @@
+package adaptor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// the operations understood by stringnorm
+var stringNormOps = map[string]func(string) string{
+	"lower":               strings.ToLower,
+	"upper":               strings.ToUpper,
+	"trim":                strings.TrimSpace,
+	"collapse-whitespace": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}
+
+// NewStringNorm creates a transformer that normalizes string fields with a list of operations applied in
+// order, i.e. ops: ["trim", "lower"] for email addresses, so that values are indexed consistently.  Missing
+// and null fields are ignored, other values are handled by the nonstring policy, or have the strings
+// inside them normalized if recurse is set
+func NewStringNorm(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf StringNormConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "stringnorm config must contain at least one field", nil)
+	}
+	if len(conf.Ops) == 0 {
+		return nil, NewError(CRITICAL, path, "stringnorm config must contain at least one op", nil)
+	}
+
+	sn := &stringNorm{fields: conf.Fields, recurse: conf.Recurse, nonString: conf.NonString}
+	for _, op := range conf.Ops {
+		fn, ok := stringNormOps[op]
+		if !ok {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown op (%s), must be lower, upper, trim or collapse-whitespace", op), nil)
+		}
+		sn.ops = append(sn.ops, fn)
+	}
+	switch sn.nonString {
+	case "":
+		sn.nonString = "pass"
+	case "pass", "drop", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown nonstring policy (%s), must be pass, drop or error", sn.nonString), nil)
+	}
+
+	return newDocTransformer("stringnorm", p, path, extra, sn.apply)
+}
+
+// StringNormConfig provides configuration options for the stringnorm transformer
+type StringNormConfig struct {
+	Namespace string   `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    []string `json:"fields" doc:"the dotted paths of the string fields to normalize"`
+	Ops       []string `json:"ops" doc:"the operations to apply, in order, lower, upper, trim or collapse-whitespace"`
+	Recurse   bool     `json:"recurse" doc:"normalize the strings inside a field that holds an array or a document, rather than treating it as a non string"`
+	NonString string   `json:"nonstring" doc:"what to do when a field isn't a string, pass (leave it as it is, the default), drop or error"`
+}
+
+type stringNorm struct {
+	fields    []string
+	ops       []func(string) string
+	recurse   bool
+	nonString string
+}
+
+func (sn *stringNorm) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for _, field := range sn.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+
+		out, ok := sn.normalize(v)
+		if !ok {
+			switch sn.nonString {
+			case "drop":
+				msg.Op = message.Noop
+				return nil
+			case "error":
+				return fmt.Errorf("%s is a %T, not a string", field, v)
+			}
+			continue
+		}
+		if err := setField(doc, field, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalize returns the value with the ops applied, and false if it's not a string.  When recursing, the
+// strings in arrays and documents are normalized, and the values inside them that aren't strings are left
+// as they are
+func (sn *stringNorm) normalize(v interface{}) (interface{}, bool) {
+	if s, ok := v.(string); ok {
+		for _, op := range sn.ops {
+			s = op(s)
+		}
+		return s, true
+	}
+	if !sn.recurse {
+		return v, false
+	}
+
+	if m, ok := asMap(v); ok {
+		for k, e := range m {
+			if out, ok := sn.normalize(e); ok {
+				m[k] = out
+			}
+		}
+		return v, true
+	}
+	if a, ok := asSlice(v); ok {
+		for i, e := range a {
+			if out, ok := sn.normalize(e); ok {
+				a[i] = out
+			}
+		}
+		return a, true
+	}
+	return v, false
+}
@@ -0,0 +1,119 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestStringNorm(t *testing.T) {
+	data := []struct {
+		conf    Config
+		in      map[string]interface{}
+		out     map[string]interface{} // nil if the message is dropped
+		errored bool
+	}{
+		{
+			Config{"fields": []string{"email"}, "ops": []string{"lower"}},
+			map[string]interface{}{"_id": "1", "email": "Bob@Example.COM"},
+			map[string]interface{}{"_id": "1", "email": "bob@example.com"},
+			false,
+		},
+		{
+			Config{"fields": []string{"code"}, "ops": []string{"upper"}},
+			map[string]interface{}{"_id": "1", "code": "gb-eng"},
+			map[string]interface{}{"_id": "1", "code": "GB-ENG"},
+			false,
+		},
+		{
+			Config{"fields": []string{"name"}, "ops": []string{"trim"}},
+			map[string]interface{}{"_id": "1", "name": "\t Ada  Lovelace \n"},
+			map[string]interface{}{"_id": "1", "name": "Ada  Lovelace"},
+			false,
+		},
+		{
+			Config{"fields": []string{"name"}, "ops": []string{"collapse-whitespace"}},
+			map[string]interface{}{"_id": "1", "name": "\t Ada  \n Lovelace \n"},
+			map[string]interface{}{"_id": "1", "name": "Ada Lovelace"},
+			false,
+		},
+		{
+			// ops are chained in order, over each of the fields
+			Config{"fields": []string{"email", "user.handle", "missing"}, "ops": []string{"trim", "lower"}},
+			map[string]interface{}{"_id": "1", "email": " Bob@Example.COM ", "user": map[string]interface{}{"handle": "  BoB"}},
+			map[string]interface{}{"_id": "1", "email": "bob@example.com", "user": map[string]interface{}{"handle": "bob"}},
+			false,
+		},
+		{
+			Config{"fields": []string{"name"}, "ops": []string{"lower", "upper"}},
+			map[string]interface{}{"_id": "1", "name": "Ada"},
+			map[string]interface{}{"_id": "1", "name": "ADA"},
+			false,
+		},
+		{
+			// other values pass by default
+			Config{"fields": []string{"tags", "count"}, "ops": []string{"lower"}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"A"}, "count": 7, "null": nil},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"A"}, "count": 7, "null": nil},
+			false,
+		},
+		{
+			Config{"fields": []string{"tags", "meta"}, "ops": []string{"lower"}, "recurse": true},
+			map[string]interface{}{"_id": "1", "tags": []string{"A", "B"}, "meta": map[string]interface{}{"x": "X", "n": 1, "deep": []interface{}{"Y", 2}}},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"a", "b"}, "meta": map[string]interface{}{"x": "x", "n": 1, "deep": []interface{}{"y", 2}}},
+			false,
+		},
+		{
+			Config{"fields": []string{"count"}, "ops": []string{"lower"}, "recurse": true, "nonstring": "drop"},
+			map[string]interface{}{"_id": "1", "count": 7},
+			nil,
+			false,
+		},
+		{
+			Config{"fields": []string{"tags"}, "ops": []string{"lower"}, "nonstring": "error"},
+			map[string]interface{}{"_id": "1", "tags": []interface{}{"A"}},
+			nil,
+			true,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "stringnorm", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.conf, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil && out.Op != message.Noop {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.conf, out)
+			}
+			if v.errored {
+				if err := <-errs; err.(Error).Lvl != ERROR {
+					t.Errorf("%v: expected an ERROR, got %v", v.conf, err)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%v: expected %+v, got %+v", v.conf, v.out, out.Data)
+		}
+	}
+}
+
+func TestStringNormBadConfig(t *testing.T) {
+	data := []Config{
+		{"ops": []string{"lower"}},
+		{"fields": []string{"email"}},
+		{"fields": []string{"email"}, "ops": []string{"titlecase"}},
+		{"fields": []string{"email"}, "ops": []string{"lower"}, "nonstring": "stringify"},
+	}
+
+	for _, conf := range data {
+		if _, err := NewStringNorm(nil, "path", conf); err == nil {
+			t.Errorf("%+v: expected an error", conf)
+		}
+	}
+}
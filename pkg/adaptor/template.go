@@ -0,0 +1,162 @@
+package adaptor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// a reference to a field, with optional filters, i.e. {{title}} or {{ title | slug }}
+var templateRef = regexp.MustCompile(`\{\{\s*([^}|\s]+)\s*((?:\|\s*\w+\s*)*)\}\}`)
+
+var templateFilters = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"slug":  slugify,
+}
+
+// NewTemplate creates a transformer that builds fields from templates referencing other fields,
+// i.e. {"full_name": "{{first}} {{last}}"} or {"url": "/posts/{{title | slug}}"}.
+// Every template is evaluated against the document as it arrived, so one template can't see the output of another
+func NewTemplate(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf TemplateConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "template config must contain at least one field", nil)
+	}
+
+	tm := &template{fields: make(map[string][]templatePart), missing: conf.Missing}
+	switch tm.missing {
+	case "":
+		tm.missing = "empty"
+	case "empty", "skip", "error":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown missing policy (%s), must be empty, skip or error", conf.Missing), nil)
+	}
+
+	for field, text := range conf.Fields {
+		parts, err := parseTemplate(text)
+		if err != nil {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("can't parse template for %s (%s)", field, err.Error()), nil)
+		}
+		tm.fields[field] = parts
+	}
+
+	return newDocTransformer("template", p, path, extra, tm.apply)
+}
+
+// TemplateConfig provides configuration options for the template transformer
+type TemplateConfig struct {
+	Namespace string            `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields    map[string]string `json:"fields" doc:"the templates, keyed by the dotted path of the field to set. fields are referenced as {{path}}, and may be filtered with lower, upper, trim or slug, i.e. {{title | slug}}"`
+	Missing   string            `json:"missing" doc:"what to do when a referenced field is missing or null, empty (use an empty string, the default), skip (don't set the field) or error"`
+}
+
+// templatePart is either literal text, or a reference to a field
+type templatePart struct {
+	text    string
+	field   string
+	filters []func(string) string
+}
+
+func parseTemplate(text string) ([]templatePart, error) {
+	var (
+		parts []templatePart
+		last  int
+	)
+	for _, m := range templateRef.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > last {
+			parts = append(parts, templatePart{text: text[last:m[0]]})
+		}
+		part := templatePart{field: text[m[2]:m[3]]}
+		for _, name := range strings.Split(text[m[4]:m[5]], "|")[1:] {
+			name = strings.TrimSpace(name)
+			filter, ok := templateFilters[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown filter %s", name)
+			}
+			part.filters = append(part.filters, filter)
+		}
+		parts = append(parts, part)
+		last = m[1]
+	}
+	if last < len(text) {
+		parts = append(parts, templatePart{text: text[last:]})
+	}
+	return parts, nil
+}
+
+type template struct {
+	fields  map[string][]templatePart
+	missing string
+}
+
+// apply renders every template before setting any of them, so the order of the templates doesn't matter
+func (tm *template) apply(msg *message.Msg, doc map[string]interface{}) error {
+	rendered := make(map[string]string, len(tm.fields))
+
+FIELDS:
+	for field, parts := range tm.fields {
+		var out []string
+		for _, part := range parts {
+			if part.field == "" {
+				out = append(out, part.text)
+				continue
+			}
+
+			v, ok := getField(doc, part.field)
+			if !ok || v == nil {
+				switch tm.missing {
+				case "error":
+					return fmt.Errorf("%s references %s, which is missing", field, part.field)
+				case "skip":
+					continue FIELDS
+				}
+				v = ""
+			}
+
+			s := templateString(v)
+			for _, filter := range part.filters {
+				s = filter(s)
+			}
+			out = append(out, s)
+		}
+		rendered[field] = strings.Join(out, "")
+	}
+
+	for field, s := range rendered {
+		if err := setField(doc, field, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateString formats a field's value for a template
+func templateString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case bson.ObjectId:
+		return s.Hex()
+	case time.Time:
+		return s.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify lower cases s, and replaces each run of anything other than letters and digits with a single dash
+func slugify(s string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
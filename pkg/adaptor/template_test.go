@@ -0,0 +1,100 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestTemplate(t *testing.T) {
+	data := []struct {
+		conf Config
+		in   map[string]interface{}
+		out  map[string]interface{} // nil if the message is dropped
+	}{
+		{
+			// several fields, literal text and nested references
+			Config{"fields": map[string]interface{}{
+				"full_name":  "{{name.first}} {{name.last}}",
+				"meta.title": "{{name.last}}, {{name.first}} ({{age}})",
+			}},
+			map[string]interface{}{"_id": "1", "name": bson.M{"first": "Nick", "last": "Smith"}, "age": 42},
+			map[string]interface{}{"_id": "1", "name": bson.M{"first": "Nick", "last": "Smith"}, "age": 42,
+				"full_name": "Nick Smith", "meta": map[string]interface{}{"title": "Smith, Nick (42)"}},
+		},
+		{
+			// filters
+			Config{"fields": map[string]interface{}{
+				"slug":  "/posts/{{ title | slug }}",
+				"shout": "{{title|trim|upper}}!",
+			}},
+			map[string]interface{}{"_id": "1", "title": "  Hello, World: Part 2 "},
+			map[string]interface{}{"_id": "1", "title": "  Hello, World: Part 2 ", "slug": "/posts/hello-world-part-2", "shout": "HELLO, WORLD: PART 2!"},
+		},
+		{
+			// templates see the original document, and may overwrite fields
+			Config{"fields": map[string]interface{}{
+				"name":  "{{first}} {{last}}",
+				"label": "{{name}}",
+			}},
+			map[string]interface{}{"_id": "1", "first": "Nick", "last": "Smith", "name": "nick"},
+			map[string]interface{}{"_id": "1", "first": "Nick", "last": "Smith", "name": "Nick Smith", "label": "nick"},
+		},
+		{
+			// missing and null fields are empty by default
+			Config{"fields": map[string]interface{}{"full_name": "{{first}} {{middle}} {{last}}"}},
+			map[string]interface{}{"_id": "1", "first": "Nick", "middle": nil},
+			map[string]interface{}{"_id": "1", "first": "Nick", "middle": nil, "full_name": "Nick  "},
+		},
+		{
+			// or skip the field
+			Config{"missing": "skip", "fields": map[string]interface{}{"full_name": "{{first}} {{last}}", "greeting": "hi {{first}}"}},
+			map[string]interface{}{"_id": "1", "first": "Nick"},
+			map[string]interface{}{"_id": "1", "first": "Nick", "greeting": "hi Nick"},
+		},
+		{
+			// or drop the document
+			Config{"missing": "error", "fields": map[string]interface{}{"full_name": "{{first}} {{last}}"}},
+			map[string]interface{}{"_id": "1", "first": "Nick"},
+			nil,
+		},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "template", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, v.in, "database.collection"))
+		if err != nil {
+			t.Errorf("%+v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.out == nil {
+			if out != nil {
+				t.Errorf("%+v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%+v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out.Data, v.out) {
+			t.Errorf("%+v: expected %+v, got %+v", v.in, v.out, out.Data)
+		}
+	}
+}
+
+func TestTemplateBadConfig(t *testing.T) {
+	data := []Config{
+		{"namespace": "database./.*/"},
+		{"namespace": "database./.*/", "fields": map[string]interface{}{"slug": "{{title | reverse}}"}},
+		{"namespace": "database./.*/", "missing": "ignore", "fields": map[string]interface{}{"slug": "{{title}}"}},
+	}
+
+	for _, v := range data {
+		if _, err := NewTemplate(nil, "path", v); err == nil {
+			t.Errorf("%+v: expected an error", v)
+		}
+	}
+}
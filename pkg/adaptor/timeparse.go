@@ -0,0 +1,176 @@
+package adaptor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+)
+
+// the named formats understood by timeparse, anything else is a go time layout, i.e. 02/01/2006
+const (
+	timeFormatEpoch        = "epoch" // seconds or milliseconds, decided by magnitude
+	timeFormatEpochSeconds = "epoch_seconds"
+	timeFormatEpochMillis  = "epoch_millis"
+	timeFormatRFC3339      = "rfc3339"
+)
+
+// epoch values at least this large are taken to be milliseconds, as seconds they'd be after the year 5000
+const epochMillisThreshold = 1e11
+
+var defaultTimeFormats = []string{timeFormatEpoch, timeFormatRFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// NewTimeParse creates a transformer that converts timestamp fields from any of a list of input
+// formats into a single output format, so that sinks receive consistent dates
+func NewTimeParse(p *pipe.Pipe, path string, extra Config) (StopStartListener, error) {
+	var conf TimeParseConfig
+	if err := extra.Construct(&conf); err != nil {
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("bad config (%s)", err.Error()), nil)
+	}
+
+	if len(conf.Fields) == 0 {
+		return nil, NewError(CRITICAL, path, "timeparse config must contain at least one field", nil)
+	}
+
+	tp := &timeParse{fields: make(map[string]TimeParseField), unparseable: conf.Unparseable}
+	switch tp.unparseable {
+	case "":
+		tp.unparseable = "error"
+	case "error", "null", "keep":
+	default:
+		return nil, NewError(CRITICAL, path, fmt.Sprintf("unknown unparseable policy (%s), must be error, null or keep", conf.Unparseable), nil)
+	}
+
+	for field, f := range conf.Fields {
+		if len(f.Formats) == 0 {
+			f.Formats = defaultTimeFormats
+		}
+		if f.Output == "" {
+			f.Output = timeFormatRFC3339
+		}
+		if f.Output == timeFormatEpoch {
+			return nil, NewError(CRITICAL, path, fmt.Sprintf("the output format for %s must be epoch_seconds or epoch_millis, not epoch", field), nil)
+		}
+		tp.fields[field] = f
+	}
+
+	return newDocTransformer("timeparse", p, path, extra, tp.apply)
+}
+
+// TimeParseConfig provides configuration options for the timeparse transformer
+type TimeParseConfig struct {
+	Namespace   string                    `json:"namespace" doc:"the set of namespaces to transform"`
+	Fields      map[string]TimeParseField `json:"fields" doc:"the timestamps to convert, keyed by dotted field path"`
+	Unparseable string                    `json:"unparseable" doc:"what to do with a value that matches none of the formats, error (report it and drop the document, the default), null or keep"`
+}
+
+// TimeParseField configures the conversion of a single field.
+// formats are either go time layouts or one of epoch, epoch_seconds, epoch_millis or rfc3339
+type TimeParseField struct {
+	Formats []string `json:"formats" doc:"the input formats, tried in order"`
+	Output  string   `json:"output" doc:"the output format, defaults to rfc3339"`
+}
+
+type timeParse struct {
+	fields      map[string]TimeParseField
+	unparseable string
+}
+
+// apply converts each configured field in the document, missing and null fields are ignored
+func (tp *timeParse) apply(msg *message.Msg, doc map[string]interface{}) error {
+	for field, f := range tp.fields {
+		v, ok := getField(doc, field)
+		if !ok || v == nil {
+			continue
+		}
+
+		t, ok := parseTime(v, f.Formats)
+		if !ok {
+			switch tp.unparseable {
+			case "error":
+				return fmt.Errorf("can't parse %s (%v) as a time", field, v)
+			case "null":
+				if err := setField(doc, field, nil); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := setField(doc, field, formatTime(t, f.Output)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTime parses v with the first of the formats that accepts it
+func parseTime(v interface{}, formats []string) (time.Time, bool) {
+	if t, ok := v.(time.Time); ok {
+		return t, true
+	}
+
+	for _, format := range formats {
+		switch format {
+		case timeFormatEpoch, timeFormatEpochSeconds, timeFormatEpochMillis:
+			n, ok := epochValue(v)
+			if !ok {
+				continue
+			}
+			if format == timeFormatEpochMillis || (format == timeFormatEpoch && math.Abs(n) >= epochMillisThreshold) {
+				sec := math.Floor(n / 1000)
+				return time.Unix(int64(sec), int64(math.Round((n-sec*1000)*1e6))).UTC(), true
+			}
+			sec := math.Floor(n)
+			return time.Unix(int64(sec), int64(math.Round((n-sec)*1e9))).UTC(), true
+		default:
+			s, ok := v.(string)
+			if !ok {
+				continue
+			}
+			layout := format
+			if format == timeFormatRFC3339 {
+				layout = time.RFC3339Nano
+			}
+			if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// epochValue returns v as a number, numeric strings are accepted
+func epochValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatTime(t time.Time, output string) interface{} {
+	t = t.UTC()
+	switch output {
+	case timeFormatEpochSeconds:
+		return t.Unix()
+	case timeFormatEpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	case timeFormatRFC3339:
+		return t.Format(time.RFC3339Nano)
+	}
+	return t.Format(output)
+}
@@ -0,0 +1,90 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+func TestTimeParse(t *testing.T) {
+	fields := func(formats []interface{}, output string) Config {
+		f := map[string]interface{}{}
+		if formats != nil {
+			f["formats"] = formats
+		}
+		if output != "" {
+			f["output"] = output
+		}
+		return Config{"fields": map[string]interface{}{"at": f}}
+	}
+
+	data := []struct {
+		conf Config
+		in   interface{}
+		out  interface{}
+		drop bool
+	}{
+		// epoch seconds and millis are told apart by magnitude
+		{fields(nil, ""), 1500000000, "2017-07-14T02:40:00Z", false},
+		{fields(nil, ""), int64(1500000000123), "2017-07-14T02:40:00.123Z", false},
+		{fields(nil, ""), 1500000000.5, "2017-07-14T02:40:00.5Z", false},
+		{fields(nil, ""), "1500000000", "2017-07-14T02:40:00Z", false},
+		// or forced
+		{fields([]interface{}{"epoch_millis"}, ""), 1500000000, "1970-01-18T08:40:00Z", false},
+		{fields([]interface{}{"epoch_seconds"}, ""), 1500000000, "2017-07-14T02:40:00Z", false},
+		// iso strings, with and without zones
+		{fields(nil, ""), "2017-07-14T14:40:00+12:00", "2017-07-14T02:40:00Z", false},
+		{fields(nil, ""), "2017-07-14 02:40:00", "2017-07-14T02:40:00Z", false},
+		{fields(nil, ""), "2017-07-14", "2017-07-14T00:00:00Z", false},
+		// go layouts, tried in order
+		{fields([]interface{}{"01/02/2006", "02/01/2006"}, ""), "25/12/2017", "2017-12-25T00:00:00Z", false},
+		// times from the source
+		{fields(nil, ""), time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC), "2017-07-14T02:40:00Z", false},
+		// output formats
+		{fields(nil, "epoch_seconds"), "2017-07-14T02:40:00Z", int64(1500000000), false},
+		{fields(nil, "epoch_millis"), "2017-07-14T02:40:00.123Z", int64(1500000000123), false},
+		{fields(nil, "02 Jan 2006"), "2017-07-14T02:40:00Z", "14 Jul 2017", false},
+		// unparseable values
+		{fields(nil, ""), "yesterday", nil, true},
+		{Config{"unparseable": "null", "fields": map[string]interface{}{"at": map[string]interface{}{}}}, "yesterday", nil, false},
+		{Config{"unparseable": "keep", "fields": map[string]interface{}{"at": map[string]interface{}{}}}, "yesterday", "yesterday", false},
+		{fields([]interface{}{"2006-01-02"}, ""), 1500000000, nil, true},
+	}
+
+	for _, v := range data {
+		tr, errs := newTestDocTransformer(t, "timeparse", v.conf)
+		out, err := tr.transformOne(message.NewMsg(message.Insert, map[string]interface{}{"_id": "1", "at": v.in}, "database.collection"))
+		if err != nil {
+			t.Errorf("%v: unexpected error, %s", v.in, err)
+			continue
+		}
+
+		if v.drop {
+			if out != nil {
+				t.Errorf("%v: expected the message to be dropped, got %+v", v.in, out)
+			}
+			if err := <-errs; err.(Error).Lvl != ERROR {
+				t.Errorf("%v: expected an ERROR, got %v", v.in, err)
+			}
+			continue
+		}
+
+		if got := out.Map()["at"]; !reflect.DeepEqual(got, v.out) {
+			t.Errorf("%v: expected %#v, got %#v", v.in, v.out, got)
+		}
+	}
+}
+
+func TestTimeParseMissingField(t *testing.T) {
+	tr, _ := newTestDocTransformer(t, "timeparse", Config{"fields": map[string]interface{}{"at": map[string]interface{}{}, "meta.at": map[string]interface{}{}}})
+	in := map[string]interface{}{"_id": "1", "other": "2017-07-14"}
+	out, err := tr.transformOne(message.NewMsg(message.Insert, in, "database.collection"))
+	if err != nil || out == nil {
+		t.Fatalf("expected the message to pass, got %+v (%v)", out, err)
+	}
+	if !reflect.DeepEqual(out.Data, map[string]interface{}{"_id": "1", "other": "2017-07-14"}) {
+		t.Errorf("expected missing fields to be left alone, got %+v", out.Data)
+	}
+}
@@ -0,0 +1,48 @@
+package adaptor
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket limits how often something happens to rate times a second.  The bucket holds up to burst
+// tokens, refilled at rate a second, and each time takes one, waiting for it if the bucket's empty
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// newTokenBucket returns a full bucket
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now, sleep: time.Sleep}
+}
+
+// wait takes a token, blocking until there is one, and returns how long it waited.  Waiting callers are
+// served one at a time
+func (b *tokenBucket) wait() time.Duration {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		if b.tokens += now.Sub(b.last).Seconds() * b.rate; b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	var waited time.Duration
+	if b.tokens < 1 {
+		waited = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.sleep(waited)
+		b.tokens, b.last = 1, b.now()
+	}
+	b.tokens--
+	return waited
+}
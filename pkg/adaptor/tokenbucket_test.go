@@ -0,0 +1,37 @@
+package adaptor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	var waits []time.Duration
+	b := newTokenBucket(4, 2)
+	b.now = func() time.Time { return now }
+	b.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		now = now.Add(d)
+	}
+
+	// the full bucket allows a burst, after which each token is a quarter second apart
+	for i := 0; i < 4; i++ {
+		b.wait()
+	}
+	expected := []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}
+	if !reflect.DeepEqual(waits, expected) {
+		t.Errorf("expected waits of %v, got %v", expected, waits)
+	}
+
+	// idle time refills the bucket, up to the burst
+	now = now.Add(time.Minute)
+	waits = nil
+	for i := 0; i < 3; i++ {
+		b.wait()
+	}
+	if !reflect.DeepEqual(waits, []time.Duration{250 * time.Millisecond}) {
+		t.Errorf("expected a single wait of 250ms, got %v", waits)
+	}
+}
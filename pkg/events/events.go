@@ -8,7 +8,9 @@ import (
 // Event is an interface that describes data which is produced periodically by the running transporter.
 //
 // Events come in multiple kinds.  baseEvents are emitted when the transporter starts and stops,
-// metricsEvents are emittied by each pipe and include a measure of how many messages have been processed
+// metricsEvents are emittied by each pipe and include a measure of how many messages have been processed,
+// lagEvents by each pipe that's seen an event time, with its watermark and lag, and sizesEvents
+// by each sink that tracks the sizes of the documents it writes
 type Event interface {
 	Emit() ([]byte, error)
 	String() string
@@ -88,6 +90,79 @@ func (e *MetricsEvent) String() string {
 	return msg
 }
 
+// LagEvent is an event that reports how far behind the events it's processing a pipe is
+type LagEvent struct {
+	Ts   int64  `json:"ts"`
+	Kind string `json:"name"`
+	Path string `json:"path"`
+
+	// Watermark is the latest event time the pipe has seen, in seconds since the epoch
+	Watermark int64 `json:"watermark"`
+
+	// Lag is how far the last message's event time was behind the time it was processed, in milliseconds
+	Lag int64 `json:"lag"`
+}
+
+// NewLagEvent creates a new lag event
+func NewLagEvent(ts int64, path string, watermark int64, lag int64) *LagEvent {
+	e := &LagEvent{
+		Ts:        ts,
+		Kind:      "lag",
+		Path:      path,
+		Watermark: watermark,
+		Lag:       lag,
+	}
+	return e
+}
+
+// Emit prepares the event to be emitted and marshalls the event into an json
+func (e *LagEvent) Emit() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *LagEvent) String() string {
+	msg := fmt.Sprintf("%s %s", e.Kind, e.Path)
+	msg += fmt.Sprintf(" watermark: %d, lag: %dms", e.Watermark, e.Lag)
+	return msg
+}
+
+// SizesEvent is an event that reports the sizes of the documents a sink has written, in bytes
+type SizesEvent struct {
+	Ts   int64  `json:"ts"`
+	Kind string `json:"name"`
+	Path string `json:"path"`
+
+	// Count is the number of documents, Min and Max the smallest and largest sizes
+	Count int `json:"count"`
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+
+	// the percentiles, from the histogram's buckets
+	P50 int `json:"p50"`
+	P95 int `json:"p95"`
+	P99 int `json:"p99"`
+
+	// Buckets holds the histogram's buckets that aren't empty, smallest first
+	Buckets []SizeBucket `json:"buckets"`
+}
+
+// SizeBucket counts the documents of up to Le bytes, and larger than the bucket before
+type SizeBucket struct {
+	Le    int `json:"le"`
+	Count int `json:"count"`
+}
+
+// Emit prepares the event to be emitted and marshalls the event into an json
+func (e *SizesEvent) Emit() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *SizesEvent) String() string {
+	msg := fmt.Sprintf("%s %s", e.Kind, e.Path)
+	msg += fmt.Sprintf(" count: %d, min: %d, p50: %d, p95: %d, p99: %d, max: %d", e.Count, e.Min, e.P50, e.P95, e.P99, e.Max)
+	return msg
+}
+
 // ErrorEvent is an event that indicates an error occured
 // during the processing of a pipeline
 type ErrorEvent struct {
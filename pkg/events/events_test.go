@@ -24,6 +24,14 @@ func TestEvent(t *testing.T) {
 			NewMetricsEvent(12345, "nick/yay", 1),
 			[]byte("{\"ts\":12345,\"name\":\"metrics\",\"path\":\"nick/yay\",\"records\":1}"),
 		},
+		{
+			NewLagEvent(12345, "nick/yay", 12300, 45000),
+			[]byte("{\"ts\":12345,\"name\":\"lag\",\"path\":\"nick/yay\",\"watermark\":12300,\"lag\":45000}"),
+		},
+		{
+			&SizesEvent{Ts: 12345, Kind: "sizes", Path: "nick/yay", Count: 2, Min: 3, Max: 5, P50: 4, P95: 5, P99: 5, Buckets: []SizeBucket{{Le: 4, Count: 1}, {Le: 8, Count: 1}}},
+			[]byte("{\"ts\":12345,\"name\":\"sizes\",\"path\":\"nick/yay\",\"count\":2,\"min\":3,\"max\":5,\"p50\":4,\"p95\":5,\"p99\":5,\"buckets\":[{\"le\":4,\"count\":1},{\"le\":8,\"count\":1}]}"),
+		},
 	}
 
 	for _, d := range data {
@@ -38,3 +46,49 @@ func TestEvent(t *testing.T) {
 		}
 	}
 }
+
+func TestSizeHistogram(t *testing.T) {
+	h := NewSizeHistogram()
+	if e := h.Event(12345, "nick/yay"); e != nil {
+		t.Errorf("expected no event before any sizes are observed, got %v", e)
+	}
+
+	// 90 small documents, 9 larger ones, and an outlier
+	for i := 0; i < 90; i++ {
+		h.Observe(100)
+	}
+	for i := 0; i < 9; i++ {
+		h.Observe(1000)
+	}
+	h.Observe(5000)
+	h.Observe(0)
+
+	want := &SizesEvent{
+		Ts:    12345,
+		Kind:  "sizes",
+		Path:  "nick/yay",
+		Count: 101,
+		Min:   0,
+		Max:   5000,
+		P50:   128,
+		P95:   1024,
+		P99:   1024,
+		Buckets: []SizeBucket{
+			{Le: 1, Count: 1},
+			{Le: 128, Count: 90},
+			{Le: 1024, Count: 9},
+			{Le: 8192, Count: 1},
+		},
+	}
+	if got := h.Event(12345, "nick/yay"); !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %+v, got: %+v", want, got)
+	}
+
+	// the percentiles don't go past the largest size
+	h = NewSizeHistogram()
+	h.Observe(100)
+	h.Observe(90)
+	if e := h.Event(12345, "nick/yay"); e.P50 != 100 || e.P99 != 100 || e.Min != 90 {
+		t.Errorf("expected the percentiles to be 100, got %+v", e)
+	}
+}
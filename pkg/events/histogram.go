@@ -0,0 +1,85 @@
+package events
+
+import "sync"
+
+// SizeHistogram counts document sizes in power of two buckets, the first holding sizes up to 1 byte,
+// the next up to 2, then 4 and so on.  The smallest and largest sizes are kept exactly, the percentiles
+// are the upper bounds of the buckets they fall in.  It's safe for a sink to observe sizes while the
+// pipeline reads its event
+type SizeHistogram struct {
+	sync.Mutex
+	count, min, max int
+	buckets         []int
+}
+
+// NewSizeHistogram creates an empty size histogram
+func NewSizeHistogram() *SizeHistogram {
+	return &SizeHistogram{}
+}
+
+// Observe counts a document of size bytes
+func (h *SizeHistogram) Observe(size int) {
+	h.Lock()
+	defer h.Unlock()
+	if h.count == 0 || size < h.min {
+		h.min = size
+	}
+	if size > h.max {
+		h.max = size
+	}
+	h.count++
+
+	i := 0
+	for le := 1; le < size; le <<= 1 {
+		i++
+	}
+	for len(h.buckets) <= i {
+		h.buckets = append(h.buckets, 0)
+	}
+	h.buckets[i]++
+}
+
+// Event returns a sizes event holding the sizes observed so far, or nil if there haven't been any
+func (h *SizeHistogram) Event(ts int64, path string) *SizesEvent {
+	h.Lock()
+	defer h.Unlock()
+	if h.count == 0 {
+		return nil
+	}
+
+	e := &SizesEvent{
+		Ts:    ts,
+		Kind:  "sizes",
+		Path:  path,
+		Count: h.count,
+		Min:   h.min,
+		Max:   h.max,
+		P50:   h.percentile(50),
+		P95:   h.percentile(95),
+		P99:   h.percentile(99),
+	}
+	for i, le := 0, 1; i < len(h.buckets); i, le = i+1, le<<1 {
+		if h.buckets[i] > 0 {
+			e.Buckets = append(e.Buckets, SizeBucket{Le: le, Count: h.buckets[i]})
+		}
+	}
+	return e
+}
+
+// percentile returns the upper bound of the bucket holding the pth percentile, or the largest size if
+// that's smaller
+func (h *SizeHistogram) percentile(p int) int {
+	rank := (h.count*p + 99) / 100
+	seen := 0
+	for i, le := 0, 1; i < len(h.buckets); i, le = i+1, le<<1 {
+		seen += h.buckets[i]
+		if seen < rank {
+			continue
+		}
+		if le > h.max {
+			return h.max
+		}
+		return le
+	}
+	return h.max
+}
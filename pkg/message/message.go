@@ -20,6 +20,7 @@ import (
 // being transported.
 type Msg struct {
 	Timestamp int64
+	Version   int64 // orders the changes to a document, i.e. the oplog timestamp of a mongo change, 0 if unknown
 	Op        OpType
 	Data      interface{}
 	Namespace string
@@ -38,6 +39,65 @@ func NewMsg(op OpType, data interface{}, namespace string) *Msg {
 	return m
 }
 
+// CopyCompleteField is the field of the marker a source sends once it has copied its collections, before it
+// starts tailing them
+const CopyCompleteField = "__copycomplete"
+
+// NewCopyCompleteMsg returns the marker a source sends between its copy and its tail.  It's a Noop, so, like a
+// heartbeat, it passes through every node without being transformed, counted or written, but sinks can be
+// told it arrived, i.e. to switch an alias to an index once it holds every document
+func NewCopyCompleteMsg() *Msg {
+	return NewMsg(Noop, map[string]interface{}{CopyCompleteField: true}, "")
+}
+
+// IsCopyComplete returns true if the message is the copy complete marker
+func (m *Msg) IsCopyComplete() bool {
+	return m.Op == Noop && m.IsMap() && m.Map()[CopyCompleteField] == true
+}
+
+// CheckpointField is the field of the marker the pipeline sends after a source's documents, holding the
+// source's checkpoint once they were sent
+const CheckpointField = "__checkpoint"
+
+// NewCheckpointMsg returns the marker for a source's checkpoint.  It's a Noop, like the copy complete marker,
+// and it reaches each sink after the documents the checkpoint covers, so once every sink has handled it the
+// checkpoint can be saved
+func NewCheckpointMsg(token string) *Msg {
+	return NewMsg(Noop, map[string]interface{}{CheckpointField: token}, "")
+}
+
+// Checkpoint returns the checkpoint the marker holds, or an empty string if the message isn't a checkpoint marker
+func (m *Msg) Checkpoint() string {
+	if m.Op != Noop || !m.IsMap() {
+		return ""
+	}
+	token, _ := m.Map()[CheckpointField].(string)
+	return token
+}
+
+// The DDL commands a source sends, as Command messages, when the collections it reads are dropped or renamed.
+// Their documents hold the command as mongo logs it.  A drop is {"drop": "orders"}, in the dropped collection's
+// namespace, a rename is {"renameCollection": "db.orders", "to": "db.archived_orders"}, in the old name's
+// namespace, and a database drop is {"dropDatabase": 1}, in the namespace db.$cmd
+const (
+	DropCommand         = "drop"
+	RenameCommand       = "renameCollection"
+	DropDatabaseCommand = "dropDatabase"
+)
+
+// DDLCommand returns the DDL command the message holds, or "" if it isn't one
+func (m *Msg) DDLCommand() string {
+	if m.Op != Command || !m.IsMap() {
+		return ""
+	}
+	for _, command := range []string{DropCommand, RenameCommand, DropDatabaseCommand} {
+		if _, ok := m.Map()[command]; ok {
+			return command
+		}
+	}
+	return ""
+}
+
 func (m *Msg) MatchNamespace(nsFilter *regexp.Regexp) (bool, error) {
 	_, ns, err := m.SplitNamespace()
 	if err != nil {
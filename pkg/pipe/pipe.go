@@ -7,17 +7,23 @@
 package pipe
 
 import (
+	"math"
 	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/compose/transporter/pkg/events"
 	"github.com/compose/transporter/pkg/message"
+	"gopkg.in/mgo.v2/bson"
 )
 
 type messageChan chan *message.Msg
 
-func newMessageChan() messageChan {
-	return make(chan *message.Msg)
+// newMessageChan returns a channel that holds up to size messages, unbuffered if size is zero
+func newMessageChan(size int) messageChan {
+	return make(chan *message.Msg, size)
 }
 
 // Pipe provides a set of methods to let transporter nodes communicate with each other.
@@ -32,13 +38,38 @@ type Pipe struct {
 	Event   chan events.Event
 	Stopped bool // has the pipe been stopped?
 
-	MessageCount int
-	LastMsg      *message.Msg
-	ExtraState   map[string]interface{}
+	MessageCount  int
+	LastMsg       *message.Msg
+	ExtraState    map[string]interface{}
+	lastHeartbeat atomic.Value // the time.Time this pipe last saw a heartbeat
+	OpField       string       // if set, the field of each document that's given the message's op before fn sees it
+	ReadBatch     int          // if set, the number of messages each Out channel chained from this pipe holds, so that a source can read ahead of its children
 
-	path      string // the path of this pipe (for events and errors)
+	EventTimeField string // if set, the field of each document that holds its event time, rather than the message's timestamp
+	lagLock        sync.Mutex
+	watermark      time.Time     // the latest event time this pipe has seen
+	lag            time.Duration // how far behind now the last message's event time was when this pipe saw it
+
+	Tee func(*message.Msg) // if set, called with each message given to Send or SendTo before it's sent
+
+	CopyComplete   time.Time // when this pipe saw the source's copy complete marker
+	OnCopyComplete func()    // if set, called by the listening loop when the copy complete marker arrives, before it's passed on
+
+	// if set, called by the listening loop when a checkpoint marker arrives, before it's passed on, so that a
+	// node holding messages, i.e. a sink's pending bulk, writes them.  The marker isn't acknowledged if it fails
+	OnCheckpoint func() error
+	acknowledged atomic.Value // the checkpoint of the last marker acknowledged
+
+	DocSizes *events.SizeHistogram // if set, the sink counts the size of each document it writes into it
+
+	path      string   // the path of this pipe (for events and errors)
+	outPaths  []string // the path of the pipe listening on each Out channel
 	chStop    chan chan bool
-	listening bool
+	chIdle    chan chan bool // answered by the listening loop between messages, with whether the In chan is empty
+	exited    chan struct{}  // closed once the listening loop has returned
+	done      chan struct{}  // closed by Stop, ends the heartbeats
+	stopOnce  sync.Once
+	listening int32
 }
 
 // NewPipe creates a new Pipe.  If the pipe that is passed in is nil, then this pipe will be treaded as a source pipe that just serves to emit messages.
@@ -50,10 +81,14 @@ func NewPipe(pipe *Pipe, path string) *Pipe {
 		Out:    make([]messageChan, 0),
 		path:   path,
 		chStop: make(chan chan bool),
+		chIdle: make(chan chan bool),
+		exited: make(chan struct{}),
+		done:   make(chan struct{}),
 	}
 
 	if pipe != nil {
-		pipe.Out = append(pipe.Out, newMessageChan())
+		pipe.Out = append(pipe.Out, newMessageChan(pipe.ReadBatch))
+		pipe.outPaths = append(pipe.outPaths, path)
 		p.In = pipe.Out[len(pipe.Out)-1] // use the last out channel
 		p.Err = pipe.Err
 		p.Event = pipe.Event
@@ -72,9 +107,10 @@ func (m *Pipe) Listen(fn func(*message.Msg) (*message.Msg, error), nsFilter *reg
 	if m.In == nil {
 		return nil
 	}
-	m.listening = true
+	atomic.StoreInt32(&m.listening, 1)
 	defer func() {
 		m.Stopped = true
+		close(m.exited)
 	}()
 	for {
 		// check for stop
@@ -87,6 +123,23 @@ func (m *Pipe) Listen(fn func(*message.Msg) (*message.Msg, error), nsFilter *reg
 
 		select {
 		case msg := <-m.In:
+			if msg.Op == message.Noop {
+				// heartbeats and markers skip fn, so they're never written, and go straight on to our children
+				if msg.IsCopyComplete() {
+					m.CopyComplete = time.Now()
+					if m.OnCopyComplete != nil {
+						m.OnCopyComplete()
+					}
+				} else if token := msg.Checkpoint(); token != "" {
+					if m.OnCheckpoint == nil || m.OnCheckpoint() == nil {
+						m.acknowledged.Store(token)
+					}
+				} else {
+					m.lastHeartbeat.Store(time.Now())
+				}
+				m.send(msg, false)
+				break
+			}
 			if match, err := msg.MatchNamespace(nsFilter); !match || err != nil {
 				if err != nil {
 					m.Err <- err
@@ -94,7 +147,7 @@ func (m *Pipe) Listen(fn func(*message.Msg) (*message.Msg, error), nsFilter *reg
 				}
 
 			} else {
-				outmsg, err := fn(msg)
+				outmsg, err := fn(withOp(msg, m.OpField))
 				if err != nil {
 					m.Err <- err
 					return err
@@ -106,22 +159,33 @@ func (m *Pipe) Listen(fn func(*message.Msg) (*message.Msg, error), nsFilter *reg
 					m.Send(outmsg)
 				} else {
 					m.MessageCount++ // update the count anyway
+					m.observe(outmsg)
 				}
 			}
 			m.LastMsg = msg
+		case c := <-m.chIdle:
+			c <- len(m.In) == 0
 		case <-time.After(100 * time.Millisecond):
 			// NOP, just breath
 		}
 	}
 }
 
+// Acknowledged returns the checkpoint of the last checkpoint marker the listening loop acknowledged, by then
+// every message sent before it has been handled, or an empty string if there hasn't been one
+func (m *Pipe) Acknowledged() string {
+	token, _ := m.acknowledged.Load().(string)
+	return token
+}
+
 // Stop terminates the channels listening loop, and allows any timeouts in send to fail
 func (m *Pipe) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
 	if !m.Stopped {
 		m.Stopped = true
 
 		// we only worry about the stop channel if we're in a listening loop
-		if m.listening {
+		if atomic.LoadInt32(&m.listening) == 1 {
 			c := make(chan bool)
 			m.chStop <- c
 			<-c
@@ -129,28 +193,222 @@ func (m *Pipe) Stop() {
 	}
 }
 
+// Drain waits, for up to timeout, until the listening loop is idle, with no message being handled and none
+// waiting on the In chan, so that a pipe stopped after its parent stops sending doesn't drop what it was already
+// sent.  It returns false if the loop was still busy at the timeout.  A pipe that isn't listening, or has
+// stopped, has nothing to drain
+func (m *Pipe) Drain(timeout time.Duration) bool {
+	if atomic.LoadInt32(&m.listening) == 0 {
+		return true
+	}
+	deadline := time.After(timeout)
+	for {
+		c := make(chan bool)
+		select {
+		case m.chIdle <- c:
+			if <-c {
+				return true
+			}
+		case <-m.exited:
+			return true
+		case <-deadline:
+			return false
+		}
+
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-m.exited:
+			return true
+		case <-deadline:
+			return false
+		}
+	}
+}
+
 // Send emits the given message on the 'Out' channel.  the send Timesout after 100 ms in order to chaeck of the Pipe has stopped and we've been asked to exit.
 // If the Pipe has been stopped, the send will fail and there is no guarantee of either success or failure
 func (m *Pipe) Send(msg *message.Msg) {
+	m.observe(msg)
+	if m.Tee != nil {
+		m.Tee(msg)
+	}
+	m.send(msg, true)
+}
+
+// Heartbeat emits a Noop message every interval until the pipe is stopped.  Heartbeats keep an idle
+// pipeline active, they pass through every node without being counted, transformed or written
+func (m *Pipe) Heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+		m.lastHeartbeat.Store(time.Now())
+		m.send(message.NewMsg(message.Noop, nil, ""), false)
+	}
+}
+
+// LastHeartbeat returns when this pipe last sent or saw a heartbeat, the zero time if it hasn't
+func (m *Pipe) LastHeartbeat() time.Time {
+	t, _ := m.lastHeartbeat.Load().(time.Time)
+	return t
+}
+
+// Mark emits a marker, a Noop message such as message.NewCopyCompleteMsg, on every Out channel.  Like
+// heartbeats, markers aren't counted
+func (m *Pipe) Mark(msg *message.Msg) {
+	if msg.IsCopyComplete() {
+		m.CopyComplete = time.Now()
+	}
+	m.send(msg, false)
+}
+
+// SendTo emits the given message only on the Out channel of the child pipe with the given path, rather than
+// on every Out channel as Send does.  It returns false if there's no such child
+func (m *Pipe) SendTo(path string, msg *message.Msg) bool {
+	for i, p := range m.outPaths {
+		if p == path {
+			m.observe(msg)
+			if m.Tee != nil {
+				m.Tee(msg)
+			}
+			m.sendOn(m.Out[i], msg, true)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Pipe) send(msg *message.Msg, count bool) {
 	for _, ch := range m.Out {
+		if !m.sendOn(ch, msg, count) {
+			return
+		}
+	}
+}
 
-	A:
-		for {
-			select {
-			case ch <- msg:
+// sendOn emits the message on a single channel, it returns false if the pipe was stopped before it was sent
+func (m *Pipe) sendOn(ch messageChan, msg *message.Msg, count bool) bool {
+	for {
+		select {
+		case ch <- msg:
+			if count {
 				m.MessageCount++
 				m.LastMsg = msg
-				break A
-			case <-time.After(100 * time.Millisecond):
-				if m.Stopped {
-					// return, with no guarantee
-					return
-				}
+			}
+			return true
+		case <-time.After(100 * time.Millisecond):
+			if m.Stopped {
+				// return, with no guarantee
+				return false
 			}
 		}
 	}
 }
 
+// observe moves the watermark on to the message's event time, if it's later, and records the lag.  Messages
+// without an event time are ignored
+func (m *Pipe) observe(msg *message.Msg) {
+	t, ok := eventTime(msg, m.EventTimeField)
+	if !ok {
+		return
+	}
+	m.lagLock.Lock()
+	defer m.lagLock.Unlock()
+	if t.After(m.watermark) {
+		m.watermark = t
+	}
+	m.lag = time.Since(t)
+}
+
+// Lag returns the latest event time this pipe has seen, and how far behind now the last message's event
+// time was when it was seen.  The watermark is zero until a message with an event time arrives
+func (m *Pipe) Lag() (time.Time, time.Duration) {
+	m.lagLock.Lock()
+	defer m.lagLock.Unlock()
+	return m.watermark, m.lag
+}
+
+// epoch values at least this large are taken to be milliseconds, as seconds they'd be after the year 5000
+const epochMillisThreshold = 1e11
+
+// eventTime returns the time the message's event happened, from the document's field if one's given,
+// which may be a time, epoch seconds or milliseconds, or an RFC3339 string, or else the message's timestamp
+func eventTime(msg *message.Msg, field string) (time.Time, bool) {
+	if field == "" {
+		if msg.Timestamp <= 0 {
+			return time.Time{}, false
+		}
+		return time.Unix(msg.Timestamp, 0), true
+	}
+	if !msg.IsMap() {
+		return time.Time{}, false
+	}
+
+	var v interface{} = msg.Map()
+	for _, key := range strings.Split(field, ".") {
+		doc, ok := v.(map[string]interface{})
+		if !ok {
+			if d, isBson := v.(bson.M); isBson {
+				doc, ok = d, true
+			}
+		}
+		if !ok {
+			return time.Time{}, false
+		}
+		v = doc[key]
+	}
+
+	var n float64
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	case int:
+		n = float64(t)
+	case int32:
+		n = float64(t)
+	case int64:
+		n = float64(t)
+	case float64:
+		n = t
+	default:
+		return time.Time{}, false
+	}
+	if n >= epochMillisThreshold {
+		return time.Unix(0, int64(n)*int64(time.Millisecond)), true
+	}
+	sec := math.Floor(n)
+	return time.Unix(int64(sec), int64((n-sec)*1e9)), true
+}
+
+// withOp returns a copy of the message whose document has the op in the field.  The message is shared with
+// the other children of the parent pipe, so neither it or its document are changed.  Messages that don't
+// hold a document, and commands, are returned as they are
+func withOp(msg *message.Msg, field string) *message.Msg {
+	if field == "" || msg.Op == message.Command || !msg.IsMap() {
+		return msg
+	}
+	doc := make(map[string]interface{}, len(msg.Map())+1)
+	for k, v := range msg.Map() {
+		doc[k] = v
+	}
+	doc[field] = msg.Op.String()
+
+	out := *msg
+	if _, ok := msg.Data.(bson.M); ok {
+		out.Data = bson.M(doc)
+	} else {
+		out.Data = doc
+	}
+	return &out
+}
+
 // skipMsg returns true if the message should be skipped and not send on to any listening nodes
 func skipMsg(msg *message.Msg) bool {
 	return msg == nil || msg.Op == message.Noop
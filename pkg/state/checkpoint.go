@@ -0,0 +1,110 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CheckpointStore persists the position of resumable sources, i.e. the last oplog timestamp,
+// so that a restarted pipeline carries on where it left off.  Each source encodes its own
+// position as a token, the store only keeps the latest token for each key
+type CheckpointStore interface {
+	Save(key, token string) error
+	// Load returns the token saved for the key, or an empty string if there isn't one
+	Load(key string) (string, error)
+}
+
+// NewCheckpointStore returns the CheckpointStore for the uri's scheme, one of file://, redis:// or mongodb://.
+// Keys are prefixed with prefix, so that pipelines can share a store
+func NewCheckpointStore(uri, prefix string) (CheckpointStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint uri (%s), %s", uri, err.Error())
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewFileCheckpoints(strings.TrimPrefix(uri, "file://"), prefix), nil
+	case "redis":
+		return NewRedisCheckpoints(uri, prefix)
+	case "mongodb":
+		return NewMongoCheckpoints(uri, prefix)
+	}
+	return nil, fmt.Errorf("unsupported checkpoint store (%s), must be file://, redis:// or mongodb://", uri)
+}
+
+// fileCheckpoints keeps the checkpoints as a json document in a file
+type fileCheckpoints struct {
+	sync.Mutex
+	filename string
+	prefix   string
+}
+
+// NewFileCheckpoints returns a CheckpointStore that keeps its checkpoints in a local file
+func NewFileCheckpoints(filename, prefix string) CheckpointStore {
+	return &fileCheckpoints{filename: filename, prefix: prefix}
+}
+
+// Save writes every checkpoint to a temporary file and renames it over the old one,
+// so a crash mid write never loses the previous checkpoints
+func (f *fileCheckpoints) Save(key, token string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	checkpoints, err := f.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[f.prefix+key] = token
+
+	ba, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f.filename), filepath.Base(f.filename))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(ba); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.filename)
+}
+
+func (f *fileCheckpoints) Load(key string) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	checkpoints, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	return checkpoints[f.prefix+key], nil
+}
+
+// read returns the checkpoints in the file, a missing file has none
+func (f *fileCheckpoints) read() (map[string]string, error) {
+	checkpoints := make(map[string]string)
+	ba, err := ioutil.ReadFile(f.filename)
+	if os.IsNotExist(err) {
+		return checkpoints, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ba, &checkpoints); err != nil {
+		return nil, fmt.Errorf("can't read checkpoints from %s, %s", f.filename, err.Error())
+	}
+	return checkpoints, nil
+}
@@ -0,0 +1,133 @@
+package state
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatalf("can't create temp dir, %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewCheckpointStore("file://"+filepath.Join(dir, "checkpoints"), "orders-")
+	if err != nil {
+		t.Fatalf("can't create checkpoint store, %s", err)
+	}
+	testCheckpointStore(t, store)
+
+	// a second store on the same file sees the saved checkpoints, other prefixes don't
+	if token, _ := NewFileCheckpoints(filepath.Join(dir, "checkpoints"), "orders-").Load("source"); token != "2" {
+		t.Errorf("expected the checkpoint to survive a restart, got %q", token)
+	}
+	if token, _ := NewFileCheckpoints(filepath.Join(dir, "checkpoints"), "other-").Load("source"); token != "" {
+		t.Errorf("expected no checkpoint for another prefix, got %q", token)
+	}
+}
+
+func TestRedisCheckpoints(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen, %s", err)
+	}
+	defer l.Close()
+	server := &fakeRedis{values: make(map[string]string)}
+	go server.serve(l)
+
+	store, err := NewCheckpointStore("redis://:secret@"+l.Addr().String()+"/2", "")
+	if err != nil {
+		t.Fatalf("can't create checkpoint store, %s", err)
+	}
+	testCheckpointStore(t, store)
+
+	server.Lock()
+	defer server.Unlock()
+	if len(server.commands) < 2 || server.commands[0] != "AUTH secret" || server.commands[1] != "SELECT 2" {
+		t.Errorf("expected to authenticate and select the database, got %v", server.commands)
+	}
+	if server.values["source"] != "2" {
+		t.Errorf("expected the checkpoint to be keyed on the node's name, got %v", server.values)
+	}
+}
+
+func TestNewCheckpointStoreBadURI(t *testing.T) {
+	for _, uri := range []string{"s3://bucket/checkpoints", "redis://localhost/db"} {
+		if _, err := NewCheckpointStore(uri, ""); err == nil {
+			t.Errorf("%s: expected an error", uri)
+		}
+	}
+}
+
+func testCheckpointStore(t *testing.T, store CheckpointStore) {
+	if token, err := store.Load("source"); err != nil || token != "" {
+		t.Errorf("expected no checkpoint, got %q, %v", token, err)
+	}
+	for _, token := range []string{"1", "2"} {
+		if err := store.Save("source", token); err != nil {
+			t.Fatalf("can't save checkpoint, %s", err)
+		}
+		if got, err := store.Load("source"); err != nil || got != token {
+			t.Errorf("expected checkpoint %q, got %q, %v", token, got, err)
+		}
+	}
+}
+
+// fakeRedis answers AUTH, SELECT, SET and GET, it keeps every command it's sent
+type fakeRedis struct {
+	sync.Mutex
+	commands []string
+	values   map[string]string
+}
+
+func (f *fakeRedis) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			r.ReadString('\n') // the length, the arguments here never contain a newline
+			arg, _ := r.ReadString('\n')
+			args[i] = strings.TrimSuffix(arg, "\r\n")
+		}
+
+		f.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		reply := "+OK\r\n"
+		switch args[0] {
+		case "SET":
+			f.values[args[1]] = args[2]
+		case "GET":
+			reply = "$-1\r\n"
+			if v, ok := f.values[args[1]]; ok {
+				reply = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+			}
+		}
+		f.Unlock()
+		conn.Write([]byte(reply))
+	}
+}
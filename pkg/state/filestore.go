@@ -14,7 +14,7 @@ import (
 
 // listen for signals, and send stops to the generator
 var (
-	chQuit = make(chan os.Signal)
+	chQuit = make(chan os.Signal, 1)
 )
 
 type filestore struct {
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// redisCheckpoints keeps the checkpoints as redis strings
+type redisCheckpoints struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisCheckpoints returns a CheckpointStore that keeps its checkpoints in redis,
+// the uri is in the form redis://:password@host:6379/db
+func NewRedisCheckpoints(uri, prefix string) (CheckpointStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse redis uri (%s), %s", uri, err.Error())
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("redis database must be a number, got %s", db)
+		}
+	}
+
+	// a connection that fails is dropped by the pool, and the next command dials a new one
+	timeout := 10 * time.Second
+	pool := &redis.Pool{
+		MaxIdle:     1,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(uri,
+				redis.DialConnectTimeout(timeout),
+				redis.DialReadTimeout(timeout),
+				redis.DialWriteTimeout(timeout),
+			)
+		},
+	}
+	return &redisCheckpoints{pool: pool, prefix: prefix}, nil
+}
+
+func (r *redisCheckpoints) Save(key, token string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", r.prefix+key, token)
+	return err
+}
+
+func (r *redisCheckpoints) Load(key string) (string, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+	token, err := redis.String(conn.Do("GET", r.prefix+key))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return token, err
+}
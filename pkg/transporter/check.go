@@ -0,0 +1,26 @@
+package transporter
+
+import (
+	"fmt"
+
+	"github.com/compose/transporter/pkg/adaptor"
+)
+
+// CheckNode creates the node's adaptor and checks that it can connect, without running a pipeline.  Adaptors
+// that are Checkers run their own checks, with write passed on to them, Pingers are pinged and the rest are
+// only created.  The first step is always creating the adaptor, which is where most adaptors dial their database
+func CheckNode(node *Node, write bool) []adaptor.CheckStep {
+	steps := []adaptor.CheckStep{{Name: fmt.Sprintf("create the %s node %s", node.Type, node.Name)}}
+	if steps[0].Err = node.Init(0); steps[0].Err != nil {
+		return steps
+	}
+	defer node.adaptor.Stop()
+
+	switch a := node.adaptor.(type) {
+	case adaptor.Checker:
+		steps = append(steps, a.Check(write)...)
+	case adaptor.Pinger:
+		steps = append(steps, adaptor.CheckStep{Name: "ping", Err: a.Ping()})
+	}
+	return steps
+}
@@ -0,0 +1,42 @@
+//go:build !slim || appbase
+// +build !slim appbase
+
+package transporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/compose/transporter/pkg/adaptor"
+)
+
+func TestCheckNode(t *testing.T) {
+	cluster := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer cluster.Close()
+
+	data := []struct {
+		node   *Node
+		steps  int
+		failed bool
+	}{
+		// bad credentials
+		{NewNode("search", "appbase", adaptor.Config{"uri": cluster.URL, "namespace": "app.type", "username": "u", "password": "wrong"}), 2, true},
+		// a node that can't be created
+		{NewNode("search", "appbase", adaptor.Config{"uri": cluster.URL, "namespace": "app.type"}), 1, true},
+		// a node with nothing to check is only created
+		{NewNode("out", "file", adaptor.Config{"uri": "stdout://"}), 1, false},
+	}
+	for _, v := range data {
+		steps := CheckNode(v.node, true)
+		if len(steps) != v.steps {
+			t.Errorf("%s: expected %d steps, got %+v", v.node.Type, v.steps, steps)
+			continue
+		}
+		if failed := steps[len(steps)-1].Err != nil; failed != v.failed {
+			t.Errorf("%s: expected failed to be %v, got %+v", v.node.Type, v.failed, steps)
+		}
+	}
+}
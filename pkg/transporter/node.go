@@ -8,9 +8,12 @@ package transporter
 
 import (
 	"fmt"
+	"log"
+	"math/rand"
 	"time"
 
 	"github.com/compose/transporter/pkg/adaptor"
+	"github.com/compose/transporter/pkg/message"
 	"github.com/compose/transporter/pkg/pipe"
 )
 
@@ -31,8 +34,14 @@ type Node struct {
 	Children []*Node        `json:"children"` // the nodes are set up as a tree, this is an array of this nodes children
 	Parent   *Node          `json:"parent"`   // this node's parent node, if this is nil, this is a 'source' node
 
-	adaptor adaptor.StopStartListener
-	pipe    *pipe.Pipe
+	adaptor   adaptor.StopStartListener
+	pipe      *pipe.Pipe
+	heartbeat time.Duration
+
+	startupDelay  time.Duration // how long to wait before the adaptor starts
+	startupJitter time.Duration // up to how much longer, at random, to wait on top of the delay
+
+	tee *teeFile // when set, the file every message the source sends is copied to
 }
 
 // NewNode creates a new Node struct
@@ -54,7 +63,7 @@ func (n *Node) String() string {
 		namespace = n.Extra.GetString("namespace")
 		depth     = n.depth()
 	)
-	if n.Type == "transformer" {
+	if adaptor.IsTransformer(n.Type) {
 		uri = n.Extra.GetString("filename")
 	} else {
 		uri = n.Extra.GetString("uri")
@@ -67,7 +76,7 @@ func (n *Node) String() string {
 		prefix = fmt.Sprintf(prefixformatter, " ", "- Source: ")
 	} else if len(n.Children) == 0 {
 		prefix = fmt.Sprintf(prefixformatter, " ", "- Sink: ")
-	} else if n.Type == "transformer" {
+	} else if adaptor.IsTransformer(n.Type) {
 		prefix = fmt.Sprintf(prefixformatter, " ", "- Transformer: ")
 	}
 
@@ -116,14 +125,68 @@ func (n *Node) Init(interval time.Duration) (err error) {
 	path := n.Path()
 	if n.Parent == nil { // we don't have a parent, we're the source
 		n.pipe = pipe.NewPipe(nil, path)
+
+		// any source can send heartbeats while it's idle, i.e. heartbeat: 30s
+		if s := n.Extra.GetString("heartbeat"); s != "" {
+			if n.heartbeat, err = time.ParseDuration(s); err != nil {
+				return fmt.Errorf("can't parse heartbeat (%s)", err.Error())
+			}
+		}
+
+		// any source can read ahead of its children, handing them batches of messages rather than one at a
+		// time, i.e. readbatch: 500
+		if n.pipe.ReadBatch, err = intOption(n.Extra, "readbatch"); err != nil {
+			return err
+		}
+
+		// any source can copy every message it sends to a file, for replaying through the file source and
+		// the unwrap transformer, rotating the file once it holds teemaxbytes, i.e. tee: /tmp/capture.json
+		if err = n.initTee(path); err != nil {
+			return err
+		}
 	} else { // we have a parent, so pass in the parent's pipe here
 		n.pipe = pipe.NewPipe(n.Parent.pipe, path)
+		for _, key := range []string{"readbatch", "tee", "teemaxbytes"} {
+			if _, ok := n.Extra[key]; ok {
+				return fmt.Errorf("%s can only be set on a source", key)
+			}
+		}
+	}
+
+	// any sink can record each document's op in a field before writing it, i.e. opfield: __op
+	if s := n.Extra.GetString("opfield"); s != "" {
+		if n.Parent == nil || adaptor.IsTransformer(n.Type) {
+			return fmt.Errorf("opfield can only be set on a sink")
+		}
+		n.pipe.OpField = s
 	}
 
+	// any node can wait before its adaptor starts, so that many transporters starting at once don't all
+	// hit the same database together, i.e. startupdelay: 5s, startupjitter: 10s
+	if s := n.Extra.GetString("startupdelay"); s != "" {
+		if n.startupDelay, err = time.ParseDuration(s); err != nil {
+			return fmt.Errorf("can't parse startupdelay (%s)", err.Error())
+		}
+	}
+	if s := n.Extra.GetString("startupjitter"); s != "" {
+		if n.startupJitter, err = time.ParseDuration(s); err != nil {
+			return fmt.Errorf("can't parse startupjitter (%s)", err.Error())
+		}
+	}
+	if n.startupDelay < 0 || n.startupJitter < 0 {
+		return fmt.Errorf("startupdelay and startupjitter can't be negative")
+	}
+
+	// any node can read event times for its lag metrics from a field rather than the message timestamp, i.e. eventtimefield: created_at
+	n.pipe.EventTimeField = n.Extra.GetString("eventtimefield")
+
 	n.adaptor, err = adaptor.Createadaptor(n.Type, path, n.Extra, n.pipe)
 	if err != nil {
 		return err
 	}
+	if flusher, ok := n.adaptor.(adaptor.Flusher); ok {
+		n.pipe.OnCheckpoint = flusher.Flush
+	}
 
 	for _, child := range n.Children {
 		err = child.Init(interval) // init each child
@@ -135,12 +198,23 @@ func (n *Node) Init(interval time.Duration) (err error) {
 	return nil
 }
 
-// Stop this node's adaptor, and sends a stop to each child of this node
+// drainTimeout is how long a stopping node waits for each child to take the messages already sent to it
+var drainTimeout = 30 * time.Second
+
+// Stop this node's adaptor, and then each child of this node.  The adaptor stops first, so that a source
+// stops producing, and each child takes what it was already sent before it's stopped in turn, so that a
+// sink's final batch is in hand when it flushes on stopping.  The tee is closed last
 func (n *Node) Stop() {
+	n.adaptor.Stop()
 	for _, node := range n.Children {
+		if !node.pipe.Drain(drainTimeout) {
+			log.Printf("%s didn't take the messages sent to it within %s, stopping it anyway", node.Path(), drainTimeout)
+		}
 		node.Stop()
 	}
-	n.adaptor.Stop()
+	if n.tee != nil {
+		n.tee.Close()
+	}
 }
 
 // Start starts the nodes children in a go routine, and then runs either Start() or Listen()
@@ -154,13 +228,80 @@ func (n *Node) Start() error {
 		}(child)
 	}
 
+	if delay := n.startDelay(); delay > 0 {
+		time.Sleep(delay)
+		if n.pipe.Stopped { // we were stopped while we waited
+			return nil
+		}
+	}
+
 	if n.Parent == nil {
+		if n.heartbeat > 0 {
+			go n.pipe.Heartbeat(n.heartbeat)
+		}
 		return n.adaptor.Start()
 	}
 
 	return n.adaptor.Listen()
 }
 
+// intOption returns the config's value for the key as a positive int, zero if it isn't set
+func intOption(extra adaptor.Config, key string) (int, error) {
+	var n int
+	switch t := extra[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		n = t
+	case float64:
+		n = int(t)
+		if float64(n) != t {
+			return 0, fmt.Errorf("%s must be a whole number, got %v", key, t)
+		}
+	default:
+		return 0, fmt.Errorf("%s must be a number, got %v", key, t)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s can't be negative", key)
+	}
+	return n, nil
+}
+
+// initTee opens the source's tee file, if it has one, and copies the messages sent on its pipe to it.  Failed
+// writes are reported as warnings, they don't stop the pipeline
+func (n *Node) initTee(path string) error {
+	maxBytes, err := intOption(n.Extra, "teemaxbytes")
+	if err != nil {
+		return err
+	}
+	file := n.Extra.GetString("tee")
+	if file == "" {
+		if maxBytes > 0 {
+			return fmt.Errorf("teemaxbytes can only be set with tee")
+		}
+		return nil
+	}
+
+	if n.tee, err = newTeeFile(file, int64(maxBytes)); err != nil {
+		return fmt.Errorf("can't open tee file (%s)", err.Error())
+	}
+	n.pipe.Tee = func(msg *message.Msg) {
+		if err := n.tee.write(msg); err != nil {
+			n.pipe.Err <- adaptor.NewError(adaptor.WARNING, path, fmt.Sprintf("tee error (%s)", err.Error()), msg.Data)
+		}
+	}
+	return nil
+}
+
+// startDelay returns how long to wait before starting the adaptor, the startup delay plus a random part
+// of the jitter
+func (n *Node) startDelay() time.Duration {
+	if n.startupJitter <= 0 {
+		return n.startupDelay
+	}
+	return n.startupDelay + time.Duration(rand.Int63n(int64(n.startupJitter)))
+}
+
 // Validate ensures that the node tree conforms to a proper structure.
 // Node trees must have at least one source, and one sink.
 // dangling transformers are forbidden.  Validate only knows about default adaptors
@@ -170,7 +311,7 @@ func (n *Node) Validate() bool {
 		return false
 	}
 
-	if n.Type == "transformer" && len(n.Children) == 0 { // transformers need children
+	if adaptor.IsTransformer(n.Type) && len(n.Children) == 0 { // transformers need children
 		return false
 	}
 
@@ -182,6 +323,16 @@ func (n *Node) Validate() bool {
 	return true
 }
 
+// Counts returns the documents the node's adaptor has written and failed to write, if it's a sink that
+// counts them (see adaptor.Counter).  It's safe to call while the pipeline runs
+func (n *Node) Counts() (adaptor.Counts, bool) {
+	counter, ok := n.adaptor.(adaptor.Counter)
+	if !ok {
+		return adaptor.Counts{}, false
+	}
+	return counter.Counts(), true
+}
+
 // Endpoints recurses down the node tree and accumulates a map associating node name with node type
 // this is primarly used with the boot event
 func (n *Node) Endpoints() map[string]string {
@@ -1,9 +1,23 @@
 package transporter
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/compose/transporter/pkg/adaptor"
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
 )
 
 func TestNodeString(t *testing.T) {
@@ -92,3 +106,443 @@ func TestPath(t *testing.T) {
 		}
 	}
 }
+
+// heartbeatTestSink counts the messages its listener is given
+type heartbeatTestSink struct {
+	pipe   *pipe.Pipe
+	writes int
+}
+
+func (s *heartbeatTestSink) Start() error { return nil }
+
+func (s *heartbeatTestSink) Listen() error {
+	return s.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		s.writes++
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+}
+
+func (s *heartbeatTestSink) Stop() error {
+	s.pipe.Stop()
+	return nil
+}
+
+func TestNodeHeartbeat(t *testing.T) {
+	sink := &heartbeatTestSink{}
+	adaptor.Register("heartbeatsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("heartbeatsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sink.pipe = p
+		return sink, nil
+	}, struct{}{})
+
+	if err := NewNode("source", "heartbeatsource", adaptor.Config{"heartbeat": "soon"}).Add(NewNode("sink", "heartbeatsink", adaptor.Config{})).Init(time.Second); err == nil {
+		t.Errorf("expected an error for a bad heartbeat")
+	}
+
+	sinkNode := NewNode("sink", "heartbeatsink", adaptor.Config{})
+	source := NewNode("source", "heartbeatsource", adaptor.Config{"heartbeat": "10ms"}).Add(sinkNode)
+	if err := source.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+
+	// the source is idle, Testadaptor.Start returns straight away
+	source.Start()
+	time.Sleep(200 * time.Millisecond)
+	source.Stop()
+	source.pipe.Stop() // Testadaptor doesn't stop its pipe, which ends the heartbeats
+
+	if sinkNode.pipe.LastHeartbeat().IsZero() {
+		t.Errorf("expected heartbeats to reach the sink")
+	}
+	if sink.writes != 0 {
+		t.Errorf("expected the sink not to write heartbeats, but it wrote %d messages", sink.writes)
+	}
+	if source.pipe.MessageCount != 0 || sinkNode.pipe.MessageCount != 0 || sinkNode.pipe.LastMsg != nil {
+		t.Errorf("expected heartbeats not to be counted, got %d and %d", source.pipe.MessageCount, sinkNode.pipe.MessageCount)
+	}
+}
+
+// opFieldTestSink records the documents its listener is given
+type opFieldTestSink struct {
+	pipe *pipe.Pipe
+	docs chan map[string]interface{}
+}
+
+func (s *opFieldTestSink) Start() error { return nil }
+
+func (s *opFieldTestSink) Listen() error {
+	return s.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		s.docs <- msg.Map()
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+}
+
+func (s *opFieldTestSink) Stop() error {
+	s.pipe.Stop()
+	return nil
+}
+
+func TestNodeOpField(t *testing.T) {
+	sinks := map[string]*opFieldTestSink{}
+	adaptor.Register("opfieldsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("opfieldsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sinks[path] = &opFieldTestSink{pipe: p, docs: make(chan map[string]interface{}, 10)}
+		return sinks[path], nil
+	}, struct{}{})
+
+	if err := NewNode("source", "opfieldsource", adaptor.Config{"opfield": "__op"}).Add(NewNode("sink", "opfieldsink", adaptor.Config{})).Init(time.Second); err == nil {
+		t.Errorf("expected an error for an opfield on a source")
+	}
+
+	source := NewNode("source", "opfieldsource", adaptor.Config{}).
+		Add(NewNode("audit", "opfieldsink", adaptor.Config{"opfield": "__op"})).
+		Add(NewNode("plain", "opfieldsink", adaptor.Config{}))
+	if err := source.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	source.Start()
+	defer source.Stop()
+
+	ops := []message.OpType{message.Insert, message.Update, message.Delete}
+	for i, op := range ops {
+		source.pipe.Send(message.NewMsg(op, map[string]interface{}{"_id": i}, "db.coll"))
+	}
+
+	for i, op := range ops {
+		if doc := <-sinks["source/audit"].docs; doc["__op"] != op.String() || doc["_id"] != i {
+			t.Errorf("expected the op %s to be written, got %v", op, doc)
+		}
+		// the documents are shared, the other sink's are left alone
+		if doc := <-sinks["source/plain"].docs; len(doc) != 1 {
+			t.Errorf("expected the document to be unchanged, got %v", doc)
+		}
+	}
+}
+
+// startupTestSource records when it was started
+type startupTestSource struct {
+	started chan time.Time
+}
+
+func (s *startupTestSource) Start() error {
+	s.started <- time.Now()
+	return nil
+}
+
+func (s *startupTestSource) Listen() error { return nil }
+
+func (s *startupTestSource) Stop() error { return nil }
+
+func TestNodeStartupDelay(t *testing.T) {
+	adaptor.Register("startupsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &startupTestSource{started: make(chan time.Time, 1)}, nil
+	}, struct{}{})
+
+	for _, conf := range []adaptor.Config{{"startupdelay": "soon"}, {"startupjitter": "-1s"}} {
+		if err := NewNode("source", "startupsource", conf).Add(NewNode("sink", "startupsource", adaptor.Config{})).Init(time.Second); err == nil {
+			t.Errorf("%v: expected an error", conf)
+		}
+	}
+
+	data := []struct {
+		conf     adaptor.Config
+		min, max time.Duration
+	}{
+		{adaptor.Config{}, 0, 50 * time.Millisecond},
+		{adaptor.Config{"startupdelay": "100ms"}, 100 * time.Millisecond, 150 * time.Millisecond},
+		{adaptor.Config{"startupdelay": "50ms", "startupjitter": "100ms"}, 50 * time.Millisecond, 200 * time.Millisecond},
+	}
+	for _, v := range data {
+		source := NewNode("source", "startupsource", v.conf).Add(NewNode("sink", "startupsource", adaptor.Config{}))
+		if err := source.Init(time.Second); err != nil {
+			t.Fatalf("can't init nodes, %s", err)
+		}
+		began := time.Now()
+		source.Start()
+		if waited := (<-source.adaptor.(*startupTestSource).started).Sub(began); waited < v.min || waited > v.max {
+			t.Errorf("%v: expected the adaptor to wait between %s and %s, it waited %s", v.conf, v.min, v.max, waited)
+		}
+	}
+}
+
+func TestNodeReadBatch(t *testing.T) {
+	sinks := map[string]*opFieldTestSink{}
+	adaptor.Register("readbatchsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("readbatchsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sinks[path] = &opFieldTestSink{pipe: p, docs: make(chan map[string]interface{}, 100)}
+		return sinks[path], nil
+	}, struct{}{})
+
+	bad := []struct {
+		source, sink adaptor.Config
+	}{
+		{adaptor.Config{"readbatch": -1}, adaptor.Config{}},
+		{adaptor.Config{"readbatch": 1.5}, adaptor.Config{}},
+		{adaptor.Config{"readbatch": "lots"}, adaptor.Config{}},
+		{adaptor.Config{}, adaptor.Config{"readbatch": 10}},
+	}
+	for _, v := range bad {
+		if err := NewNode("source", "readbatchsource", v.source).Add(NewNode("sink", "readbatchsink", v.sink)).Init(time.Second); err == nil {
+			t.Errorf("%v, %v: expected an error", v.source, v.sink)
+		}
+	}
+
+	source := NewNode("source", "readbatchsource", adaptor.Config{"readbatch": float64(10)}).Add(NewNode("sink", "readbatchsink", adaptor.Config{}))
+	if err := source.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	if cap(source.pipe.Out[0]) != 10 {
+		t.Errorf("expected the source to buffer 10 messages, got %d", cap(source.pipe.Out[0]))
+	}
+
+	// the source can send a batch before the sink has started
+	for i := 0; i < 10; i++ {
+		source.pipe.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": i}, "db.coll"))
+	}
+	source.Start()
+	defer source.Stop()
+	for i := 10; i < 50; i++ {
+		source.pipe.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": i}, "db.coll"))
+	}
+
+	for i := 0; i < 50; i++ {
+		if doc := <-sinks["source/sink"].docs; doc["_id"] != i {
+			t.Fatalf("expected the messages in order, got %v for %d", doc, i)
+		}
+	}
+}
+
+// BenchmarkNodeReadBatch sends messages from a source to a sink, one at a time and in batches
+func BenchmarkNodeReadBatch(b *testing.B) {
+	adaptor.Register("readbatchbenchsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("readbatchbenchsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &heartbeatTestSink{pipe: p}, nil
+	}, struct{}{})
+
+	for _, size := range []int{0, 100, 1000} {
+		b.Run(fmt.Sprintf("readbatch=%d", size), func(b *testing.B) {
+			sink := NewNode("sink", "readbatchbenchsink", adaptor.Config{})
+			source := NewNode("source", "readbatchbenchsource", adaptor.Config{"readbatch": size}).Add(sink)
+			if err := source.Init(time.Second); err != nil {
+				b.Fatalf("can't init nodes, %s", err)
+			}
+			source.Start()
+			msg := message.NewMsg(message.Insert, map[string]interface{}{"_id": 1}, "db.coll")
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				source.pipe.Send(msg)
+			}
+			for sink.pipe.MessageCount < b.N {
+				runtime.Gosched()
+			}
+			b.StopTimer()
+			source.Stop()
+		})
+	}
+}
+
+type stopTestSource struct {
+	stopped bool
+}
+
+func (s *stopTestSource) Start() error  { return nil }
+func (s *stopTestSource) Listen() error { return nil }
+
+func (s *stopTestSource) Stop() error {
+	s.stopped = true
+	return nil
+}
+
+// stopTestSink batches the messages it's sent, and flushes the batch when it's stopped, as the appbase sink does
+type stopTestSink struct {
+	pipe    *pipe.Pipe
+	source  *stopTestSource
+	started chan struct{}
+	once    sync.Once
+
+	batch         []*message.Msg
+	flushed       int
+	sourceStopped bool // had the source stopped when the sink was stopped
+}
+
+func (s *stopTestSink) Start() error { return nil }
+
+func (s *stopTestSink) Listen() error {
+	return s.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		s.once.Do(func() { close(s.started) })
+		time.Sleep(5 * time.Millisecond) // a slow write, so that the source gets ahead
+		s.batch = append(s.batch, msg)
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+}
+
+func (s *stopTestSink) Stop() error {
+	s.sourceStopped = s.source.stopped
+	s.pipe.Stop()
+	s.flushed, s.batch = len(s.batch), nil
+	return nil
+}
+
+func TestNodeStop(t *testing.T) {
+	source := &stopTestSource{}
+	sink := &stopTestSink{source: source, started: make(chan struct{})}
+	adaptor.Register("stopsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return source, nil
+	}, struct{}{})
+	adaptor.Register("stopsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sink.pipe = p
+		return sink, nil
+	}, struct{}{})
+
+	node := NewNode("source", "stopsource", adaptor.Config{"readbatch": 20}).Add(NewNode("sink", "stopsink", adaptor.Config{}))
+	if err := node.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	node.Start()
+	for i := 0; i < 20; i++ {
+		node.pipe.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": i}, "db.coll"))
+	}
+	<-sink.started
+
+	// most of the messages are still waiting on the sink when it's stopped
+	node.Stop()
+	if !sink.sourceStopped {
+		t.Errorf("expected the source to be stopped before the sink")
+	}
+	if sink.flushed != 20 {
+		t.Errorf("expected the sink to flush all 20 messages when it's stopped, it flushed %d", sink.flushed)
+	}
+}
+
+// drainTestSink writes slowly, and records whether it was stopped in the middle of a write
+type drainTestSink struct {
+	pipe           *pipe.Pipe
+	writing        int32
+	written        int32
+	stoppedWriting bool
+}
+
+func (s *drainTestSink) Start() error { return nil }
+
+func (s *drainTestSink) Listen() error {
+	return s.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		atomic.StoreInt32(&s.writing, 1)
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&s.written, 1)
+		atomic.StoreInt32(&s.writing, 0)
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+}
+
+func (s *drainTestSink) Stop() error {
+	s.stoppedWriting = atomic.LoadInt32(&s.writing) == 1
+	s.pipe.Stop()
+	return nil
+}
+
+func TestNodeStopDrainsUnbufferedPipes(t *testing.T) {
+	sink := &drainTestSink{}
+	adaptor.Register("drainsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &stopTestSource{}, nil
+	}, struct{}{})
+	adaptor.Register("drainsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sink.pipe = p
+		return sink, nil
+	}, struct{}{})
+
+	// without a readbatch the pipe is unbuffered, the second send returns as soon as the sink takes the message
+	node := NewNode("source", "drainsource", adaptor.Config{}).Add(NewNode("sink", "drainsink", adaptor.Config{}))
+	if err := node.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	node.Start()
+	for i := 0; i < 2; i++ {
+		node.pipe.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": i}, "db.coll"))
+	}
+
+	node.Stop()
+	if sink.stoppedWriting || atomic.LoadInt32(&sink.written) != 2 {
+		t.Errorf("expected both writes to finish before the sink was stopped, %d finished", atomic.LoadInt32(&sink.written))
+	}
+}
+
+func TestNodeTee(t *testing.T) {
+	adaptor.Register("teesource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("teesink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &heartbeatTestSink{pipe: p}, nil
+	}, struct{}{})
+
+	dir, err := ioutil.TempDir("", "tee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "capture.json")
+
+	bad := []struct {
+		source, sink adaptor.Config
+	}{
+		{adaptor.Config{"teemaxbytes": 100}, adaptor.Config{}},
+		{adaptor.Config{"tee": file, "teemaxbytes": -1}, adaptor.Config{}},
+		{adaptor.Config{"tee": filepath.Join(dir, "missing", "capture.json")}, adaptor.Config{}},
+		{adaptor.Config{}, adaptor.Config{"tee": file}},
+	}
+	for _, v := range bad {
+		if err := NewNode("source", "teesource", v.source).Add(NewNode("sink", "teesink", v.sink)).Init(time.Second); err == nil {
+			t.Errorf("%v, %v: expected an error", v.source, v.sink)
+		}
+	}
+
+	// each record is about 100 bytes, so the file's rotated every couple of messages
+	source := NewNode("source", "teesource", adaptor.Config{"tee": file, "teemaxbytes": 250}).Add(NewNode("sink", "teesink", adaptor.Config{}))
+	if err := source.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	source.Start()
+	ops := []message.OpType{message.Insert, message.Update, message.Delete, message.Insert, message.Command}
+	for i, op := range ops {
+		msg := message.NewMsg(op, map[string]interface{}{"_id": float64(i), "name": "doc"}, fmt.Sprintf("db.coll%d", i))
+		msg.Timestamp = int64(1420000000 + i)
+		source.pipe.Send(msg)
+	}
+	source.Stop()
+
+	var records []map[string]interface{}
+	for _, name := range []string{"capture.1.json", "capture.2.json", "capture.json"} {
+		ba, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected the tee file %s, %s", name, err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(ba)), "\n") {
+			var record map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("%s: can't parse %q, %s", name, line, err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	if len(records) != len(ops) {
+		t.Fatalf("expected %d records, got %d", len(ops), len(records))
+	}
+	for i, op := range ops {
+		expected := map[string]interface{}{
+			"meta":    map[string]interface{}{"op": op.String(), "namespace": fmt.Sprintf("db.coll%d", i), "timestamp": float64(1420000000 + i)},
+			"payload": map[string]interface{}{"_id": float64(i), "name": "doc"},
+		}
+		if !reflect.DeepEqual(records[i], expected) {
+			t.Errorf("expected %v, got %v", expected, records[i])
+		}
+	}
+}
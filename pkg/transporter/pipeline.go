@@ -1,11 +1,14 @@
 package transporter
 
 import (
+	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/compose/transporter/pkg/adaptor"
 	"github.com/compose/transporter/pkg/events"
+	"github.com/compose/transporter/pkg/message"
 	"github.com/compose/transporter/pkg/state"
 )
 
@@ -14,6 +17,10 @@ const (
 	VERSION = "0.1.1"
 )
 
+func init() {
+	adaptor.TransporterVersion = VERSION
+}
+
 // A Pipeline is a the end to end description of a transporter data flow.
 // including the source, sink, and all the transformers along the way
 type Pipeline struct {
@@ -27,6 +34,18 @@ type Pipeline struct {
 	// the transporter is running
 	Err           error
 	sessionTicker *time.Ticker
+
+	// ReadyTimeout is how long Run waits for the sinks to become reachable before it starts the source.
+	// sinks whose adaptor implements adaptor.Pinger are pinged every readyInterval, zero skips the check
+	ReadyTimeout  time.Duration
+	readyInterval time.Duration
+
+	// Checkpoints, if set, keeps the position of a source that implements adaptor.Checkpointer.  It's saved
+	// every CheckpointInterval, once the sinks have acknowledged it, and when the pipeline stops
+	Checkpoints        state.CheckpointStore
+	CheckpointInterval time.Duration
+
+	stopped int32 // set by Stop, so that Run can tell a source that finished from one that was stopped
 }
 
 // NewDefaultPipeline returns a new Transporter Pipeline with the given node tree, and
@@ -62,6 +81,9 @@ func NewPipeline(source *Node, emitter events.Emitter, interval time.Duration, s
 		source:        source,
 		emitter:       emitter,
 		metricsTicker: time.NewTicker(interval),
+		readyInterval: time.Second,
+
+		CheckpointInterval: 10 * time.Second,
 	}
 
 	if sessionStore != nil {
@@ -100,6 +122,7 @@ func (pipeline *Pipeline) String() string {
 // the node's database adaptors are expected to clean up after themselves, and stop will block until
 // all nodes have stopped successfully
 func (pipeline *Pipeline) Stop() {
+	atomic.StoreInt32(&pipeline.stopped, 1)
 	pipeline.source.Stop()
 	pipeline.emitter.Stop()
 	if pipeline.sessionStore != nil {
@@ -108,17 +131,45 @@ func (pipeline *Pipeline) Stop() {
 	pipeline.metricsTicker.Stop()
 }
 
+// Counts returns the documents each sink that counts them has written and failed to write, keyed by
+// the node's path.  It's safe to call while the pipeline runs
+func (pipeline *Pipeline) Counts() map[string]adaptor.Counts {
+	counts := make(map[string]adaptor.Counts)
+	frontier := append([]*Node{}, pipeline.source.Children...)
+	for len(frontier) > 0 {
+		node := frontier[0]
+		frontier = append(frontier[1:], node.Children...)
+		if c, ok := node.Counts(); ok {
+			counts[node.Path()] = c
+		}
+	}
+	return counts
+}
+
 // Run the pipeline
 func (pipeline *Pipeline) Run() error {
 	endpoints := pipeline.source.Endpoints()
 	// send a boot event
 	pipeline.source.pipe.Event <- events.NewBootEvent(time.Now().Unix(), VERSION, endpoints)
 
-	// start the source
-	err := pipeline.source.Start()
+	// don't start the source until the sinks can accept its data
+	err := pipeline.waitForSinks()
+	if err == nil {
+		err = pipeline.resume()
+	}
+	var saverDone chan struct{}
+	if err == nil {
+		// start the source
+		done := make(chan struct{})
+		saverDone = make(chan struct{})
+		go pipeline.startCheckpointSaver(done, saverDone)
+		err = pipeline.source.Start()
+		close(done)
+	}
 	if err != nil && pipeline.Err == nil {
 		pipeline.Err = err // only set it if it hasn't been set already.
 	}
+	interrupted := atomic.LoadInt32(&pipeline.stopped) == 1
 
 	// pipeline has stopped, emit one last round of metrics and send the exit event
 	pipeline.emitMetrics()
@@ -130,9 +181,91 @@ func (pipeline *Pipeline) Run() error {
 	// the source has exited, stop all the other nodes
 	pipeline.Stop()
 
+	// once every node has stopped, and the sinks have flushed, what the source sent has been written, so its
+	// checkpoint is saved.  Unless the run failed, then the last checkpoint the sinks acknowledged is kept
+	if saverDone != nil {
+		pipeline.source.pipe.Stop() // a marker still being sent is given up on
+		<-saverDone
+		if pipeline.Err == nil {
+			pipeline.saveCheckpoint(pipeline.checkpoint())
+		}
+	}
+
+	// and then let the sinks finish up, they only do what depends on a complete run if the source finished
+	// on its own and nothing went wrong
+	result := pipeline.Err
+	if result == nil && interrupted {
+		result = fmt.Errorf("the pipeline was stopped before the source finished")
+	}
+	pipeline.finish(result)
+
 	return pipeline.Err
 }
 
+// finish calls Finish on each sink whose adaptor implements adaptor.Finisher, with the result of the run
+func (pipeline *Pipeline) finish(result error) {
+	frontier := append([]*Node{}, pipeline.source.Children...)
+	for len(frontier) > 0 {
+		node := frontier[0]
+		frontier = append(frontier[1:], node.Children...)
+
+		finisher, ok := node.adaptor.(adaptor.Finisher)
+		if !ok {
+			continue
+		}
+		if err := finisher.Finish(result); err != nil {
+			log.Printf("can't finish %s (%s)", node.Path(), err.Error())
+			if pipeline.Err == nil {
+				pipeline.Err = fmt.Errorf("can't finish %s (%s)", node.Path(), err.Error())
+			}
+		}
+	}
+}
+
+// waitForSinks pings each sink until they're all reachable, or ReadyTimeout has passed
+func (pipeline *Pipeline) waitForSinks() error {
+	if pipeline.ReadyTimeout <= 0 {
+		return nil
+	}
+	deadline := time.Now().Add(pipeline.ReadyTimeout)
+
+	frontier := append([]*Node{}, pipeline.source.Children...)
+	for len(frontier) > 0 {
+		node := frontier[0]
+		frontier = append(frontier[1:], node.Children...)
+
+		pinger, ok := node.adaptor.(adaptor.Pinger)
+		if !ok {
+			continue
+		}
+		for {
+			err := ping(pinger, deadline.Sub(time.Now()))
+			if err == nil {
+				break
+			}
+			if time.Now().Add(pipeline.readyInterval).After(deadline) {
+				return fmt.Errorf("sink %s isn't reachable after %s (%s)", node.Path(), pipeline.ReadyTimeout, err.Error())
+			}
+			time.Sleep(pipeline.readyInterval)
+		}
+	}
+	return nil
+}
+
+// ping calls Ping, giving up if it hasn't returned within the timeout
+func ping(pinger adaptor.Pinger, timeout time.Duration) error {
+	c := make(chan error, 1)
+	go func() {
+		c <- pinger.Ping()
+	}()
+	select {
+	case err := <-c:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out")
+	}
+}
+
 // start error listener consumes all the events on the pipe's Err channel, and stops the pipeline
 // when it receives one
 func (pipeline *Pipeline) startErrorListener(cherr chan error) {
@@ -170,6 +303,14 @@ func (pipeline *Pipeline) emitMetrics() {
 
 		// do something with the node
 		pipeline.source.pipe.Event <- events.NewMetricsEvent(time.Now().Unix(), node.Path(), node.pipe.MessageCount)
+		if watermark, lag := node.pipe.Lag(); !watermark.IsZero() {
+			pipeline.source.pipe.Event <- events.NewLagEvent(time.Now().Unix(), node.Path(), watermark.Unix(), int64(lag/time.Millisecond))
+		}
+		if node.pipe.DocSizes != nil {
+			if e := node.pipe.DocSizes.Event(time.Now().Unix(), node.Path()); e != nil {
+				pipeline.source.pipe.Event <- e
+			}
+		}
 
 		// add this nodes children to the frontier
 		for _, child := range node.Children {
@@ -183,6 +324,110 @@ func (pipeline *Pipeline) emitMetrics() {
 	}
 }
 
+// resume hands the source the last checkpoint saved for it
+func (pipeline *Pipeline) resume() error {
+	c, ok := pipeline.source.adaptor.(adaptor.Checkpointer)
+	if !ok || pipeline.Checkpoints == nil {
+		return nil
+	}
+
+	token, err := pipeline.Checkpoints.Load(pipeline.source.Path())
+	if err != nil {
+		return fmt.Errorf("can't load the checkpoint for %s (%s)", pipeline.source.Path(), err.Error())
+	}
+	if token == "" {
+		return nil
+	}
+	if err := c.Resume(token); err != nil {
+		return fmt.Errorf("can't resume %s from %s (%s)", pipeline.source.Path(), token, err.Error())
+	}
+	return nil
+}
+
+// checkpoint returns the source's checkpoint, or an empty string if it doesn't have one
+func (pipeline *Pipeline) checkpoint() string {
+	c, ok := pipeline.source.adaptor.(adaptor.Checkpointer)
+	if !ok || pipeline.Checkpoints == nil {
+		return ""
+	}
+	return c.Checkpoint()
+}
+
+// saveCheckpoint saves the source's checkpoint, failures are logged and retried on the next save
+func (pipeline *Pipeline) saveCheckpoint(token string) {
+	if token == "" {
+		return
+	}
+	if err := pipeline.Checkpoints.Save(pipeline.source.Path(), token); err != nil {
+		log.Printf("can't save the checkpoint for %s (%s)", pipeline.source.Path(), err.Error())
+	}
+}
+
+// acknowledged returns the index of the newest of the marked checkpoints that every sink has acknowledged, or -1
+// if there isn't one.  The markers reach each sink in order, so a sink's last acknowledgement covers those before it
+func (pipeline *Pipeline) acknowledged(marked []string) int {
+	newest := len(marked) - 1
+	frontier := append([]*Node{}, pipeline.source.Children...)
+	for len(frontier) > 0 {
+		node := frontier[0]
+		frontier = append(frontier[1:], node.Children...)
+		if len(node.Children) > 0 {
+			continue
+		}
+		n, acked := -1, node.pipe.Acknowledged()
+		for i, token := range marked {
+			if token == acked {
+				n = i
+			}
+		}
+		if n < newest {
+			newest = n
+		}
+	}
+	return newest
+}
+
+// maxMarkedCheckpoints is how many checkpoints the saver waits on the sinks to acknowledge, the oldest are given
+// up on when a sink goes that long without acknowledging any
+const maxMarkedCheckpoints = 64
+
+// startCheckpointSaver saves the source's checkpoint each CheckpointInterval, as the source runs.  Each interval a
+// marker holding the checkpoint is sent after the documents it covers, and the newest checkpoint whose marker
+// every sink has handled is saved, so a checkpoint is never ahead of what the sinks have written.  saverDone is
+// closed once it's stopped
+func (pipeline *Pipeline) startCheckpointSaver(done, saverDone chan struct{}) {
+	defer close(saverDone)
+	if _, ok := pipeline.source.adaptor.(adaptor.Checkpointer); !ok || pipeline.Checkpoints == nil || pipeline.CheckpointInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pipeline.CheckpointInterval)
+	defer ticker.Stop()
+	var (
+		saved  string   // the last checkpoint saved
+		marked []string // the checkpoints marked and not yet saved, oldest first
+	)
+	for {
+		select {
+		case <-ticker.C:
+			if n := pipeline.acknowledged(marked); n >= 0 {
+				pipeline.saveCheckpoint(marked[n])
+				saved, marked = marked[n], marked[n+1:]
+			}
+			// a checkpoint is marked again until it's saved, in case a sink couldn't flush when its marker arrived
+			if token := pipeline.checkpoint(); token != "" && token != saved {
+				pipeline.source.pipe.Mark(message.NewCheckpointMsg(token))
+				marked = append(marked, token)
+				if len(marked) > maxMarkedCheckpoints {
+					marked = marked[1:]
+				}
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 func (pipeline *Pipeline) startStateSaver() {
 	for _ = range pipeline.sessionTicker.C {
 		pipeline.setState()
@@ -199,7 +444,7 @@ func (pipeline *Pipeline) setState() {
 		frontier = frontier[1:]
 
 		// do something with the node
-		if node.Type != "transformer" && node.pipe.LastMsg != nil {
+		if !adaptor.IsTransformer(node.Type) && node.pipe.LastMsg != nil {
 			pipeline.sessionStore.Set(node.Path(), &state.MsgState{Msg: node.pipe.LastMsg, Extra: node.pipe.ExtraState})
 		}
 
@@ -225,7 +470,7 @@ func (pipeline *Pipeline) initState() {
 		frontier = frontier[1:]
 
 		// do something with the node
-		if node.Type != "transformer" {
+		if !adaptor.IsTransformer(node.Type) {
 			nodeState, _ := pipeline.sessionStore.Get(node.Path())
 			if nodeState != nil {
 				node.pipe.LastMsg = nodeState.Msg
@@ -2,10 +2,20 @@ package transporter
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/compose/transporter/pkg/adaptor"
+	"github.com/compose/transporter/pkg/events"
+	"github.com/compose/transporter/pkg/message"
 	"github.com/compose/transporter/pkg/pipe"
 )
 
@@ -73,3 +83,405 @@ func TestPipelineString(t *testing.T) {
 		}
 	}
 }
+
+// readyTestSource records whether it was started, readyTestSink is reachable once it has been pinged failPings times
+type readyTestSource struct {
+	Testadaptor
+	started bool
+}
+
+func (s *readyTestSource) Start() error {
+	s.started = true
+	return nil
+}
+
+type readyTestSink struct {
+	Testadaptor
+	failPings int
+	pings     int
+}
+
+func (s *readyTestSink) Ping() error {
+	s.pings++
+	if s.pings <= s.failPings {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPipelineWaitsForSinks(t *testing.T) {
+	data := []struct {
+		failPings int
+		started   bool
+	}{
+		{0, true},
+		{2, true},   // reachable after a couple of retries
+		{50, false}, // never reachable within the timeout
+	}
+
+	for _, v := range data {
+		source := &readyTestSource{}
+		sink := &readyTestSink{failPings: v.failPings}
+		adaptor.Register("readysource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			return source, nil
+		}, struct{}{})
+		adaptor.Register("readysink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			return sink, nil
+		}, struct{}{})
+
+		node := NewNode("source", "readysource", adaptor.Config{}).Add(NewNode("sink", "readysink", adaptor.Config{}))
+		p, err := NewPipeline(node, events.NewNoopEmitter(), time.Minute, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("can't create pipeline, got %s", err.Error())
+		}
+		p.ReadyTimeout = 100 * time.Millisecond
+		p.readyInterval = 10 * time.Millisecond
+
+		err = p.Run()
+		if source.started != v.started {
+			t.Errorf("%d failed pings: expected started to be %v, got %v", v.failPings, v.started, source.started)
+		}
+		if v.started && err != nil {
+			t.Errorf("%d failed pings: unexpected error %s", v.failPings, err)
+		}
+		if !v.started && (err == nil || !strings.Contains(err.Error(), "source/sink isn't reachable")) {
+			t.Errorf("%d failed pings: expected an unreachable sink error, got %v", v.failPings, err)
+		}
+	}
+}
+
+// checkpointTestSource checkpoints at the last token it was resumed from plus one
+type checkpointTestSource struct {
+	Testadaptor
+	resumed string
+}
+
+func (s *checkpointTestSource) Checkpoint() string { return s.resumed + "+" }
+
+func (s *checkpointTestSource) Resume(token string) error {
+	s.resumed = token
+	return nil
+}
+
+type memoryCheckpoints map[string]string
+
+func (m memoryCheckpoints) Save(key, token string) error {
+	m[key] = token
+	return nil
+}
+
+func (m memoryCheckpoints) Load(key string) (string, error) { return m[key], nil }
+
+func TestPipelineCheckpoints(t *testing.T) {
+	source := &checkpointTestSource{}
+	adaptor.Register("checkpointsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return source, nil
+	}, struct{}{})
+	adaptor.Register("checkpointsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+
+	checkpoints := memoryCheckpoints{}
+	for _, expected := range []string{"+", "++"} {
+		node := NewNode("source", "checkpointsource", adaptor.Config{}).Add(NewNode("sink", "checkpointsink", adaptor.Config{}))
+		p, err := NewPipeline(node, events.NewNoopEmitter(), time.Minute, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("can't create pipeline, got %s", err.Error())
+		}
+		p.Checkpoints = checkpoints
+
+		if err := p.Run(); err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if checkpoints["source"] != expected {
+			t.Errorf("expected checkpoint %q, got %q", expected, checkpoints["source"])
+		}
+	}
+}
+
+// ackTestSource sends a document for each token it's given, and checkpoints at the token once it's sent.  Start
+// returns err once the tokens are closed
+type ackTestSource struct {
+	Testadaptor
+	pipe       *pipe.Pipe
+	tokens     chan string
+	checkpoint atomic.Value
+	err        error
+}
+
+func (s *ackTestSource) Start() error {
+	for token := range s.tokens {
+		s.pipe.Send(message.NewMsg(message.Insert, map[string]interface{}{"_id": token}, "database.collection"))
+		s.checkpoint.Store(token)
+	}
+	return s.err
+}
+
+func (s *ackTestSource) Checkpoint() string {
+	token, _ := s.checkpoint.Load().(string)
+	return token
+}
+
+func (s *ackTestSource) Resume(token string) error { return nil }
+
+// ackTestSink holds the documents it's sent until it's flushed, which fails until it's allowed
+type ackTestSink struct {
+	pipe *pipe.Pipe
+
+	sync.Mutex
+	allowed bool
+	held    int
+}
+
+func (s *ackTestSink) Start() error { return nil }
+
+func (s *ackTestSink) Listen() error {
+	return s.pipe.Listen(func(msg *message.Msg) (*message.Msg, error) {
+		s.Lock()
+		s.held++
+		s.Unlock()
+		return msg, nil
+	}, regexp.MustCompile(".*"))
+}
+
+func (s *ackTestSink) Flush() error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.allowed {
+		return errors.New("can't write")
+	}
+	s.held = 0
+	return nil
+}
+
+func (s *ackTestSink) Stop() error {
+	s.pipe.Stop()
+	return nil
+}
+
+// savedCheckpoints sends each checkpoint saved on the channel
+type savedCheckpoints chan string
+
+func (c savedCheckpoints) Save(key, token string) error {
+	c <- token
+	return nil
+}
+
+func (c savedCheckpoints) Load(key string) (string, error) { return "", nil }
+
+func TestPipelineCheckpointsWaitForTheSinks(t *testing.T) {
+	for _, sourceErr := range []error{nil, errors.New("lost the connection")} {
+		source := &ackTestSource{tokens: make(chan string), err: sourceErr}
+		sink := &ackTestSink{}
+		adaptor.Register("acksource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			source.pipe = p
+			return source, nil
+		}, struct{}{})
+		adaptor.Register("acksink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			sink.pipe = p
+			return sink, nil
+		}, struct{}{})
+
+		node := NewNode("source", "acksource", adaptor.Config{}).Add(NewNode("sink", "acksink", adaptor.Config{}))
+		p, err := NewPipeline(node, events.NewNoopEmitter(), time.Minute, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("can't create pipeline, got %s", err.Error())
+		}
+		saved := make(savedCheckpoints, 100)
+		p.Checkpoints = saved
+		p.CheckpointInterval = 10 * time.Millisecond
+		ran := make(chan struct{})
+		go func() {
+			p.Run()
+			close(ran)
+		}()
+
+		// the sink is sent the document, but until it's written the checkpoint isn't saved
+		source.tokens <- "1"
+		select {
+		case token := <-saved:
+			t.Fatalf("%v: expected nothing to be saved before the sink flushed, got %s", sourceErr, token)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		sink.Lock()
+		sink.allowed = true
+		sink.Unlock()
+		select {
+		case token := <-saved:
+			if token != "1" {
+				t.Errorf("%v: expected checkpoint 1 to be saved once the sink flushed, got %s", sourceErr, token)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v: expected checkpoint 1 to be saved once the sink flushed", sourceErr)
+		}
+
+		// the last checkpoint is saved once the sink has stopped, unless the run failed
+		sink.Lock()
+		sink.allowed = false
+		sink.Unlock()
+		source.tokens <- "2"
+		close(source.tokens)
+		<-ran
+		var last string
+		for len(saved) > 0 {
+			last = <-saved
+		}
+		if sourceErr == nil && last != "2" {
+			t.Errorf("expected checkpoint 2 to be saved once the pipeline stopped, got %q", last)
+		}
+		if sourceErr != nil && last != "" {
+			t.Errorf("%v: expected no checkpoint to be saved after the run failed, got %s", sourceErr, last)
+		}
+	}
+}
+
+// finishTestSource returns err from Start, finishTestSink records what it was finished with
+type finishTestSource struct {
+	Testadaptor
+	err error
+}
+
+func (s *finishTestSource) Start() error { return s.err }
+
+type finishTestSink struct {
+	Testadaptor
+	finished bool
+	result   error
+}
+
+func (s *finishTestSink) Finish(result error) error {
+	s.finished, s.result = true, result
+	return nil
+}
+
+func TestPipelineFinishesSinks(t *testing.T) {
+	for _, sourceErr := range []error{nil, errors.New("lost the connection")} {
+		source := &finishTestSource{err: sourceErr}
+		sink := &finishTestSink{}
+		adaptor.Register("finishsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			return source, nil
+		}, struct{}{})
+		adaptor.Register("finishsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+			return sink, nil
+		}, struct{}{})
+
+		node := NewNode("source", "finishsource", adaptor.Config{}).Add(NewNode("sink", "finishsink", adaptor.Config{}))
+		p, err := NewPipeline(node, events.NewNoopEmitter(), time.Minute, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("can't create pipeline, got %s", err.Error())
+		}
+
+		p.Run()
+		if !sink.finished {
+			t.Errorf("%v: expected the sink to be finished", sourceErr)
+		}
+		if sink.result != sourceErr {
+			t.Errorf("expected the sink to be finished with %v, got %v", sourceErr, sink.result)
+		}
+	}
+}
+
+func TestPipelineReportsLag(t *testing.T) {
+	sinks := map[string]*opFieldTestSink{}
+	adaptor.Register("lagsource", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		return &Testadaptor{}, nil
+	}, struct{}{})
+	adaptor.Register("lagsink", "description", func(p *pipe.Pipe, path string, extra adaptor.Config) (adaptor.StopStartListener, error) {
+		sinks[path] = &opFieldTestSink{pipe: p, docs: make(chan map[string]interface{}, 10)}
+		return sinks[path], nil
+	}, struct{}{})
+
+	source := NewNode("source", "lagsource", adaptor.Config{}).
+		Add(NewNode("plain", "lagsink", adaptor.Config{})).
+		Add(NewNode("created", "lagsink", adaptor.Config{"eventtimefield": "created_at"}))
+	if err := source.Init(time.Second); err != nil {
+		t.Fatalf("can't init nodes, %s", err)
+	}
+	source.Start()
+
+	now := time.Now()
+	// the second message is late, it holds the lag but not the watermark
+	first := message.NewMsg(message.Insert, map[string]interface{}{"_id": 1, "created_at": now.Add(-20*time.Minute).UnixNano() / int64(time.Millisecond)}, "db.coll")
+	first.Timestamp = now.Add(-time.Minute).Unix()
+	second := message.NewMsg(message.Insert, map[string]interface{}{"_id": 2, "created_at": now.Add(-30 * time.Minute).Format(time.RFC3339)}, "db.coll")
+	second.Timestamp = now.Add(-2 * time.Minute).Unix()
+	for _, msg := range []*message.Msg{first, second} {
+		source.pipe.Send(msg)
+	}
+	for i := 0; i < 2; i++ {
+		<-sinks["source/plain"].docs
+		<-sinks["source/created"].docs
+	}
+	source.Stop()
+
+	lags := map[string]*events.LagEvent{}
+	done := make(chan struct{})
+	go func() {
+		(&Pipeline{source: source}).emitMetrics()
+		close(done)
+	}()
+	for {
+		select {
+		case e := <-source.pipe.Event:
+			if lag, ok := e.(*events.LagEvent); ok {
+				lags[lag.Path] = lag
+			}
+			continue
+		case <-done:
+		}
+		break
+	}
+
+	data := []struct {
+		path      string
+		watermark time.Time
+		lag       time.Duration
+	}{
+		{"source", now.Add(-time.Minute), 2 * time.Minute},
+		{"source/plain", now.Add(-time.Minute), 2 * time.Minute},
+		{"source/created", now.Add(-20 * time.Minute), 30 * time.Minute},
+	}
+	for _, v := range data {
+		lag, ok := lags[v.path]
+		if !ok {
+			t.Errorf("%s: expected a lag event", v.path)
+			continue
+		}
+		if lag.Watermark != v.watermark.Unix() {
+			t.Errorf("%s: expected a watermark of %d, got %d", v.path, v.watermark.Unix(), lag.Watermark)
+		}
+		// the timestamps are whole seconds, and the messages took a moment to get through
+		if got := time.Duration(lag.Lag) * time.Millisecond; got < v.lag-time.Second || got > v.lag+2*time.Second {
+			t.Errorf("%s: expected a lag of about %s, got %s", v.path, v.lag, got)
+		}
+	}
+}
+
+func TestPipelineCounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "counts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in.json"), filepath.Join(dir, "out.json")
+	if err := ioutil.WriteFile(in, []byte("{\"_id\":1}\n{\"_id\":2}\n{\"_id\":3}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	node := NewNode("source", "file", adaptor.Config{"uri": "file://" + in}).
+		Add(NewNode("sink", "file", adaptor.Config{"uri": "file://" + out}))
+	p, err := NewPipeline(node, events.NewNoopEmitter(), time.Minute, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("can't create pipeline, got %s", err.Error())
+	}
+	if err := p.Run(); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	// the source isn't a sink, so only the sink is counted
+	expected := map[string]adaptor.Counts{"source/sink": {Written: 3}}
+	if counts := p.Counts(); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, counts)
+	}
+}
@@ -0,0 +1,189 @@
+package transporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/compose/transporter/pkg/adaptor"
+	"github.com/compose/transporter/pkg/message"
+	"github.com/compose/transporter/pkg/pipe"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Schema describes the fields observed in a sample of documents.  Nested documents are
+// reported with dotted paths, i.e. "address.city", and the elements of arrays as "tags[]"
+type Schema struct {
+	Samples int
+	fields  map[string]*FieldSchema
+}
+
+// FieldSchema describes a single field of a Schema
+type FieldSchema struct {
+	Path string `json:"path"`
+
+	// Types maps each observed type to the number of times it was seen
+	Types map[string]int `json:"types"`
+
+	// Present is the number of documents containing the field, Nulls is the number of null values
+	Present   int     `json:"present"`
+	Nulls     int     `json:"nulls"`
+	Frequency float64 `json:"frequency"`
+}
+
+// NewSchema returns an empty Schema
+func NewSchema() *Schema {
+	return &Schema{fields: make(map[string]*FieldSchema)}
+}
+
+// Add records the fields of the given message's document.  Messages that aren't documents are ignored
+func (s *Schema) Add(msg *message.Msg) {
+	if !msg.IsMap() {
+		return
+	}
+	s.Samples++
+
+	seen := make(map[string]bool)
+	s.addDoc("", msg.Map(), seen)
+	for path := range seen {
+		s.fields[path].Present++
+	}
+}
+
+func (s *Schema) addDoc(prefix string, doc map[string]interface{}, seen map[string]bool) {
+	for k, v := range doc {
+		s.addValue(prefix+k, v, seen)
+	}
+}
+
+func (s *Schema) addValue(path string, value interface{}, seen map[string]bool) {
+	f, ok := s.fields[path]
+	if !ok {
+		f = &FieldSchema{Path: path, Types: make(map[string]int)}
+		s.fields[path] = f
+	}
+	seen[path] = true
+
+	if value == nil {
+		f.Nulls++
+		return
+	}
+	f.Types[typeName(value)]++
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.addDoc(path+".", v, seen)
+	case bson.M:
+		s.addDoc(path+".", v, seen)
+	case []interface{}:
+		for _, e := range v {
+			s.addValue(path+"[]", e, seen)
+		}
+	}
+}
+
+// Fields returns the observed fields sorted by path
+func (s *Schema) Fields() []*FieldSchema {
+	fields := make([]*FieldSchema, 0, len(s.fields))
+	for _, f := range s.fields {
+		if s.Samples > 0 {
+			f.Frequency = float64(f.Present) / float64(s.Samples)
+		}
+		fields = append(fields, f)
+	}
+	sort.Sort(byPath(fields))
+	return fields
+}
+
+// MarshalJSON reports the schema as {"samples": N, "fields": [...]}
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Samples int            `json:"samples"`
+		Fields  []*FieldSchema `json:"fields"`
+	}{s.Samples, s.Fields()})
+}
+
+type byPath []*FieldSchema
+
+func (p byPath) Len() int           { return len(p) }
+func (p byPath) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p byPath) Less(i, j int) bool { return p[i].Path < p[j].Path }
+
+// typeName returns the bson name of the value's type, which is what users of
+// mongo will be familiar with.  Numbers decoded from json are always doubles
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int32:
+		return "int"
+	case int64:
+		return "long"
+	case float32, float64:
+		return "double"
+	case map[string]interface{}, bson.M:
+		return "object"
+	case []interface{}:
+		return "array"
+	case bson.ObjectId:
+		return "objectId"
+	case time.Time:
+		return "date"
+	case bson.Binary, []byte:
+		return "binData"
+	case bson.MongoTimestamp:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// InferSchema samples up to samples documents from the source node and returns the schema they describe.
+// Nothing is written anywhere, the source is stopped as soon as enough documents have been read,
+// or once it runs out of documents
+func InferSchema(source *Node, samples int) (*Schema, error) {
+	if err := source.Init(0); err != nil {
+		return nil, err
+	}
+
+	var (
+		schema = NewSchema()
+		out    = pipe.NewPipe(source.pipe, source.Path()+"/schema")
+		done   = make(chan error, 1)
+		errs   = make(chan error, 1)
+	)
+
+	go func() {
+		for err := range source.pipe.Err {
+			if e, ok := err.(adaptor.Error); ok && e.Lvl != adaptor.CRITICAL {
+				continue
+			}
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+	}()
+
+	go func() {
+		done <- source.adaptor.Start()
+	}()
+
+	for schema.Samples < samples {
+		select {
+		case msg := <-out.In:
+			schema.Add(msg)
+		case err := <-errs:
+			source.adaptor.Stop()
+			return schema, err
+		case err := <-done:
+			return schema, err
+		}
+	}
+
+	source.adaptor.Stop()
+	return schema, nil
+}
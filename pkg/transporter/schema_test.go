@@ -0,0 +1,86 @@
+package transporter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose/transporter/pkg/adaptor"
+)
+
+func TestInferSchema(t *testing.T) {
+	fixture, err := filepath.Abs("testdata/schema.json")
+	if err != nil {
+		t.Fatalf("can't find fixture, %s", err)
+	}
+
+	data := []struct {
+		samples int
+		seen    int
+		fields  map[string]FieldSchema
+	}{
+		{
+			10,
+			4,
+			map[string]FieldSchema{
+				"_id":          {Types: map[string]int{"string": 4}, Present: 4, Frequency: 1},
+				"admin":        {Types: map[string]int{"bool": 1}, Present: 1, Frequency: 0.25},
+				"age":          {Types: map[string]int{"double": 2, "string": 1}, Present: 4, Nulls: 1, Frequency: 1},
+				"address":      {Types: map[string]int{"object": 3}, Present: 3, Frequency: 0.75},
+				"address.city": {Types: map[string]int{"string": 3}, Present: 3, Frequency: 0.75},
+				"address.zip":  {Types: map[string]int{"string": 1, "double": 1}, Present: 2, Frequency: 0.5},
+				"name":         {Types: map[string]int{"string": 4}, Present: 4, Frequency: 1},
+				"tags":         {Types: map[string]int{"array": 2}, Present: 2, Frequency: 0.5},
+				"tags[]":       {Types: map[string]int{"string": 2}, Present: 1, Frequency: 0.25},
+			},
+		},
+		{
+			// the source is stopped after the first document
+			1,
+			1,
+			map[string]FieldSchema{
+				"_id":          {Types: map[string]int{"string": 1}, Present: 1, Frequency: 1},
+				"age":          {Types: map[string]int{"double": 1}, Present: 1, Frequency: 1},
+				"address":      {Types: map[string]int{"object": 1}, Present: 1, Frequency: 1},
+				"address.city": {Types: map[string]int{"string": 1}, Present: 1, Frequency: 1},
+				"address.zip":  {Types: map[string]int{"string": 1}, Present: 1, Frequency: 1},
+				"name":         {Types: map[string]int{"string": 1}, Present: 1, Frequency: 1},
+				"tags":         {Types: map[string]int{"array": 1}, Present: 1, Frequency: 1},
+				"tags[]":       {Types: map[string]int{"string": 2}, Present: 1, Frequency: 1},
+			},
+		},
+	}
+
+	for _, v := range data {
+		schema, err := InferSchema(NewNode("source", "file", adaptor.Config{"uri": "file://" + fixture}), v.samples)
+		if err != nil {
+			t.Errorf("%d samples: unexpected error, %s", v.samples, err)
+			continue
+		}
+		if schema.Samples != v.seen {
+			t.Errorf("%d samples: expected %d documents, got %d", v.samples, v.seen, schema.Samples)
+		}
+
+		fields := schema.Fields()
+		if len(fields) != len(v.fields) {
+			t.Errorf("%d samples: expected %d fields, got %d", v.samples, len(v.fields), len(fields))
+		}
+		for _, f := range fields {
+			expected, ok := v.fields[f.Path]
+			if !ok {
+				t.Errorf("%d samples: unexpected field %s", v.samples, f.Path)
+				continue
+			}
+			expected.Path = f.Path
+			if !reflect.DeepEqual(*f, expected) {
+				t.Errorf("%d samples: expected %+v, got %+v", v.samples, expected, *f)
+			}
+		}
+	}
+}
+
+func TestInferSchemaMissingSource(t *testing.T) {
+	if _, err := InferSchema(NewNode("source", "file", adaptor.Config{"uri": "file:///does/not/exist.json"}), 10); err == nil {
+		t.Errorf("expected an error for a missing source file")
+	}
+}
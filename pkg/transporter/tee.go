@@ -0,0 +1,114 @@
+package transporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/compose/transporter/pkg/message"
+)
+
+// teeFile writes each message a source sends to a file, a json document per line, so that a run can be
+// captured and replayed through the file source.  Each line is an envelope in the wrap transformer's format,
+// {"meta": {"op": "insert", "namespace": "db.coll", "timestamp": 1420000000}, "payload": {...}}, which the
+// unwrap transformer turns back into the original message.  Once the file holds maxBytes it's rotated, moved
+// aside to the next free numbered name, capture.1.json, capture.2.json and so on, and a new file's started
+type teeFile struct {
+	sync.Mutex
+
+	path     string
+	maxBytes int64
+
+	f    *os.File
+	size int64
+	next int // the number of the next rotated file
+}
+
+// newTeeFile opens the tee file, appending to it if it exists
+func newTeeFile(path string, maxBytes int64) (*teeFile, error) {
+	t := &teeFile{path: path, maxBytes: maxBytes, next: 1}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *teeFile) open() (err error) {
+	if t.f, err = os.OpenFile(t.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+		return err
+	}
+	info, err := t.f.Stat()
+	if err != nil {
+		t.f.Close()
+		return err
+	}
+	t.size = info.Size()
+	return nil
+}
+
+// write adds the message to the file, rotating the file first if the message would take it past maxBytes
+func (t *teeFile) write(msg *message.Msg) error {
+	payload := msg.Data
+	if msg.IsMap() {
+		payload = msg.Map()
+	}
+	line, err := json.Marshal(map[string]interface{}{
+		"meta":    map[string]interface{}{"op": msg.Op.String(), "namespace": msg.Namespace, "timestamp": msg.Timestamp},
+		"payload": payload,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	t.Lock()
+	defer t.Unlock()
+	if t.f == nil {
+		return fmt.Errorf("the tee file is closed")
+	}
+	if t.maxBytes > 0 && t.size > 0 && t.size+int64(len(line)) > t.maxBytes {
+		if err := t.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := t.f.Write(line)
+	t.size += int64(n)
+	return err
+}
+
+// rotate moves the full file aside, and opens a new one in its place
+func (t *teeFile) rotate() error {
+	if err := t.f.Close(); err != nil {
+		return err
+	}
+	t.f = nil
+
+	ext := filepath.Ext(t.path)
+	base := strings.TrimSuffix(t.path, ext)
+	for {
+		rotated := fmt.Sprintf("%s.%d%s", base, t.next, ext)
+		t.next++
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			if err := os.Rename(t.path, rotated); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return t.open()
+}
+
+// Close closes the file, later writes fail
+func (t *teeFile) Close() error {
+	t.Lock()
+	defer t.Unlock()
+	if t.f == nil {
+		return nil
+	}
+	err := t.f.Close()
+	t.f = nil
+	return err
+}